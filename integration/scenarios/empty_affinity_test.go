@@ -0,0 +1,155 @@
+//go:build docker
+
+package scenarios
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"shingo/integration/harness"
+	"shingo/protocol"
+	"shingo/protocol/router"
+
+	"shingocore/dispatch"
+	"shingocore/fulfillment"
+	coremessaging "shingocore/messaging"
+	"shingocore/service"
+	corebins "shingocore/store/bins"
+	corenodes "shingocore/store/nodes"
+	coreharness "shingocore/testharness"
+
+	edgeharness "shingoedge/testharness"
+)
+
+// TestScenario_EmptyAffinityKeepsALinesEmptyForItself is TC-39 end to end.
+//
+// LINE-A produces empties and owns them (empty_affinity=own on its group).
+// An empty has just been cleared at A-1. LINE-B, next door, asks for an
+// empty:
+//
+//  1. Line B's retrieve_empty rides the wire from Edge's outbox, through
+//     Core's ingestor and dispatcher, and queues.
+//  2. The scanner runs with a 10-minute grace: the only empty in the plant is
+//     inside LINE-A's grace, so Line B's order stays queued — and its queue
+//     reason says why, naming LINE-A, instead of claiming there are none.
+//  3. Line A asks for an empty to A-2. The scanner hands it the A-1 carrier.
+//
+// What this catches that the store tests miss: the grace reaching the
+// scanner's finder, empty_since being stamped by the real clear path, and the
+// persisted sentence an operator reads on Line B's HMI.
+func TestScenario_EmptyAffinityKeepsALinesEmptyForItself(t *testing.T) {
+	// ── Core setup ─────────────────────────────────────────────────
+	coreDB := coreharness.OpenDB(t)
+	sd := coreharness.SetupStandardData(t, coreDB)
+
+	lineA := &corenodes.Node{Name: "TC39-LINE-A", Enabled: true}
+	if err := coreDB.CreateNode(lineA); err != nil {
+		t.Fatalf("create LINE-A: %v", err)
+	}
+	if err := coreDB.SetNodeProperty(lineA.ID, corenodes.PropEmptyAffinity, corenodes.EmptyAffinityOwn); err != nil {
+		t.Fatalf("set affinity: %v", err)
+	}
+	node := func(name string, parent *int64) *corenodes.Node {
+		t.Helper()
+		n := &corenodes.Node{Name: name, Enabled: true, ParentID: parent}
+		if err := coreDB.CreateNode(n); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		return n
+	}
+	a1 := node("TC39-A-1", &lineA.ID)
+	node("TC39-A-2", &lineA.ID)
+	node("TC39-B-1", nil)
+
+	// The carrier Line A just emptied, cleared through the service so
+	// empty_since is stamped the way production stamps it.
+	bin := &corebins.Bin{BinTypeID: sd.BinType.ID, Label: "TC39-BIN", NodeID: &a1.ID, Status: "available"}
+	if err := coreDB.CreateBin(bin); err != nil {
+		t.Fatalf("create bin: %v", err)
+	}
+	binManifest := service.NewBinManifestService(coreDB, service.EpochAnnounce{})
+	if _, err := binManifest.ClearForReuse(bin.ID, nil); err != nil {
+		t.Fatalf("clear bin: %v", err)
+	}
+
+	grace := func() time.Duration { return 10 * time.Minute }
+	backend := coreharness.NewTrackingBackend()
+	dispatcher := dispatch.NewDispatcher(coreDB, backend, &noopEmitter{}, "core", "shingo.dispatch", nil)
+	dispatcher.SetEmptyAffinityGrace(grace)
+	finder := dispatch.NewSourceFinder(coreDB, nil, nil)
+	finder.SetEmptyAffinityGrace(grace)
+	scanner := fulfillment.NewScanner(coreDB, dispatcher, dispatcher.Lifecycle(), finder, binManifest,
+		func(string, string, any) error { return nil },
+		func(orderID int64, code, detail string) { t.Errorf("order %d failed: %s %s", orderID, code, detail) },
+		t.Logf, nil)
+
+	coreHandler := coremessaging.NewCoreHandler(coreDB, nil, "core", "shingo.dispatch", dispatcher)
+	coreIngestor := protocol.NewIngestor(nil)
+	coreRouter := router.New[string]()
+	router.Register(coreRouter, protocol.TypeOrderRequest, coreHandler.HandleOrderRequest)
+	coreIngestor.Dispatch = func(env *protocol.Envelope) {
+		coreRouter.Dispatch(env, env.Type)
+	}
+
+	// ── Edge + bus ─────────────────────────────────────────────────
+	edgeDB := edgeharness.OpenDB(t)
+	bus := harness.NewBus(t,
+		harness.EdgeSide{EdgeStore: edgeDB, EdgeIngestor: protocol.NewIngestor(nil)},
+		harness.CoreSide{CoreStore: coreDB, CoreIngestor: coreIngestor},
+	)
+	askForEmpty := func(uuid, station, dest string) {
+		t.Helper()
+		env, err := protocol.NewEnvelope(protocol.TypeOrderRequest,
+			protocol.Address{Role: protocol.RoleEdge, Station: station},
+			protocol.Address{Role: protocol.RoleCore},
+			&protocol.OrderRequest{
+				OrderUUID: uuid, OrderType: dispatch.OrderTypeRetrieve, RetrieveEmpty: true,
+				PayloadCode: sd.Payload.Code, Quantity: 1, DeliveryNode: dest,
+			})
+		if err != nil {
+			t.Fatalf("build envelope: %v", err)
+		}
+		encoded, err := env.Encode()
+		if err != nil {
+			t.Fatalf("encode envelope: %v", err)
+		}
+		if _, err := edgeDB.EnqueueOutbox(encoded, protocol.TypeOrderRequest); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		if n := bus.PumpEdgeOutbox(); n != 1 {
+			t.Fatalf("delivered = %d, want 1", n)
+		}
+		scanner.RunOnce()
+	}
+
+	// ── Line B asks inside Line A's grace ──────────────────────────
+	askForEmpty("tc39-line-b", "edge.line-b", "TC39-B-1")
+	orderB, err := coreDB.GetOrderByUUID("tc39-line-b")
+	if err != nil {
+		t.Fatalf("get Line B's order: %v", err)
+	}
+	if orderB.Status != dispatch.StatusQueued {
+		t.Fatalf("Line B's order is %s, want queued — the only empty is Line A's, inside its grace", orderB.Status)
+	}
+	if dispatch.QueueCause(orderB.QueueCause) != dispatch.CauseFinderEmptyAffinity {
+		t.Errorf("Line B's cause = %q, want %q", orderB.QueueCause, dispatch.CauseFinderEmptyAffinity)
+	}
+	if !strings.Contains(orderB.QueueReason, "held for TC39-LINE-A") {
+		t.Errorf("Line B's queue reason = %q, want it to name TC39-LINE-A", orderB.QueueReason)
+	}
+
+	// ── Line A asks: it gets its own empty ─────────────────────────
+	askForEmpty("tc39-line-a", "edge.line-a", "TC39-A-2")
+	orderA, err := coreDB.GetOrderByUUID("tc39-line-a")
+	if err != nil {
+		t.Fatalf("get Line A's order: %v", err)
+	}
+	got, err := coreDB.GetBin(bin.ID)
+	if err != nil {
+		t.Fatalf("get bin: %v", err)
+	}
+	if got.ClaimedBy == nil || *got.ClaimedBy != orderA.ID {
+		t.Errorf("bin claimed by %v, want Line A's order %d", got.ClaimedBy, orderA.ID)
+	}
+}
@@ -93,6 +93,10 @@ const (
 	// point checks the level: an operator can request on a node the system
 	// considers fine.
 	EpisodeTriggerOperator = "operator"
+	// EpisodeTriggerPLC is a PLC pushbutton or state bit bound to the request
+	// action (Edge plc_triggers). Like the operator's button it does not
+	// check the level; unlike it, nobody was standing at the screen.
+	EpisodeTriggerPLC = "plc"
 )
 
 // THE STATION IS SCOPE FOR ONE KIND AND FRAGMENTATION FOR THE OTHER TWO.
@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberStyle is how a language writes a number.
+type numberStyle struct {
+	group, decimal string
+	// minGroup is the fewest integer digits that get grouped: Spanish writes
+	// 1234 but 12.345.
+	minGroup int
+}
+
+var numberStyles = map[string]numberStyle{
+	"en": {",", ".", 4},
+	"es": {".", ",", 5},
+	"ja": {",", ".", 4},
+}
+
+// FormatNumber writes v with decimals places in lang's separators. A language
+// with no style of its own is written as English.
+func FormatNumber(lang string, v float64, decimals int) string {
+	st, ok := numberStyles[lang]
+	if !ok {
+		st = numberStyles[English]
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(whole) >= st.minGroup {
+		var b strings.Builder
+		for i, d := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteString(st.group)
+			}
+			b.WriteRune(d)
+		}
+		whole = b.String()
+	}
+	if frac != "" {
+		return sign + whole + st.decimal + frac
+	}
+	return sign + whole
+}
+
+var spanishMonths = [...]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"}
+
+// FormatDate writes t's calendar date in loc the way lang writes one.
+func FormatDate(lang string, t time.Time, loc *time.Location) string {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	switch lang {
+	case "es":
+		return strconv.Itoa(t.Day()) + " " + spanishMonths[t.Month()-1] + " " + strconv.Itoa(t.Year())
+	case "ja":
+		return t.Format("2006年1月2日")
+	default:
+		return t.Format("Jan 2, 2006")
+	}
+}
+
+// FormatTime writes t as date and 24-hour time in loc — the plant's zone, not
+// the server's or the browser's, since a shift is a plant-local thing.
+func FormatTime(lang string, t time.Time, loc *time.Location) string {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return FormatDate(lang, t, nil) + " " + t.Format("15:04")
+}
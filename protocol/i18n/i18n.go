@@ -0,0 +1,260 @@
+// Package i18n is the message catalog behind every operator-facing string Core
+// and Edge render: template text, and the sentences the server composes for a
+// person (changeover blockers, refusal errors).
+//
+// A catalog is one JSON file per language — en.json, es.json, ja.json — each a
+// flat map from key to text. English is the reference: a key English does not
+// have is a typo and fails Load, and a key another language lacks falls back
+// to English rather than to nothing. Arguments are named, {node} and not %s,
+// because word order is the first thing a translation changes: Japanese puts
+// the node before the verb, and a positional argument cannot follow it there.
+//
+// Each module owns its catalog (shingocore/locales, shingoedge/locales). The
+// two surfaces share no strings and have no reason to change them in lockstep;
+// what they share is this machinery, which is why it lives here and not in
+// shared/.
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// English is the reference language: every key exists in it.
+const English = "en"
+
+// Catalog holds the messages of every language a module ships.
+type Catalog struct {
+	langs []string                     // English first, then sorted
+	msgs  map[string]map[string]string // by language, then key
+}
+
+var placeholder = regexp.MustCompile(`\{([a-z][a-z0-9_]*)\}`)
+
+// Load reads every <lang>.json at the root of fsys. en.json is required. A key
+// another language has and English does not, or a translation whose {names}
+// differ from English's, is an error: both are mistakes no fallback can hide.
+func Load(fsys fs.FS) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("i18n: %w", err)
+	}
+	c := &Catalog{msgs: map[string]map[string]string{}}
+	for _, f := range files {
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: read %s: %w", f, err)
+		}
+		var m map[string]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("i18n: parse %s: %w", f, err)
+		}
+		c.msgs[strings.TrimSuffix(path.Base(f), ".json")] = m
+	}
+	en, ok := c.msgs[English]
+	if !ok {
+		return nil, fmt.Errorf("i18n: no %s.json", English)
+	}
+	for lang, m := range c.msgs {
+		if lang != English {
+			c.langs = append(c.langs, lang)
+		}
+		for key, text := range m {
+			ref, ok := en[key]
+			if !ok {
+				return nil, fmt.Errorf("i18n: %s.json: key %q is not in %s.json", lang, key, English)
+			}
+			if got, want := names(text), names(ref); got != want {
+				return nil, fmt.Errorf("i18n: %s.json: %q uses {%s}, %s uses {%s}", lang, key, got, English, want)
+			}
+		}
+	}
+	sort.Strings(c.langs)
+	c.langs = append([]string{English}, c.langs...)
+	return c, nil
+}
+
+// MustLoad is Load for an embedded catalog, where an error is a build defect.
+func MustLoad(fsys fs.FS) *Catalog {
+	c, err := Load(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// names is a text's placeholder names, sorted and comma-joined.
+func names(text string) string {
+	var out []string
+	for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+		out = append(out, m[1])
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+// Languages returns the languages the catalog ships, English first.
+func (c *Catalog) Languages() []string { return append([]string(nil), c.langs...) }
+
+// Supports reports whether the catalog ships a language.
+func (c *Catalog) Supports(lang string) bool {
+	_, ok := c.msgs[lang]
+	return ok
+}
+
+// Has reports whether a language has its own text for key, not a fallback.
+func (c *Catalog) Has(lang, key string) bool {
+	_, ok := c.msgs[lang][key]
+	return ok
+}
+
+// Keys returns every key, sorted.
+func (c *Catalog) Keys() []string {
+	out := make([]string, 0, len(c.msgs[English]))
+	for k := range c.msgs[English] {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Missing returns the keys a language falls back to English for, sorted.
+func (c *Catalog) Missing(lang string) []string {
+	var out []string
+	for _, k := range c.Keys() {
+		if !c.Has(lang, k) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// T renders key in lang. args are name/value pairs filling the text's {name}
+// placeholders. A language the catalog lacks, or a key that language lacks,
+// renders English; a key English lacks renders as the key itself, so a
+// mistake shows on screen instead of as a blank.
+func (c *Catalog) T(lang, key string, args ...any) string {
+	text, ok := c.msgs[lang][key]
+	if !ok {
+		if text, ok = c.msgs[English][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	vals := make(map[string]string, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		vals[fmt.Sprint(args[i])] = fmt.Sprint(args[i+1])
+	}
+	return placeholder.ReplaceAllStringFunc(text, func(m string) string {
+		if v, ok := vals[m[1:len(m)-1]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// Messages returns every key in lang, English where lang has none: what a page
+// hands its scripts so they render the same catalog the server does.
+func (c *Catalog) Messages(lang string) map[string]string {
+	out := make(map[string]string, len(c.msgs[English]))
+	for k, v := range c.msgs[English] {
+		out[k] = v
+	}
+	for k, v := range c.msgs[lang] {
+		out[k] = v
+	}
+	return out
+}
+
+// Match returns the first supported language among candidates, or "" when
+// none is. A candidate is a language tag ("es", "es-MX", "ja_JP") or a whole
+// Accept-Language header, whose entries are tried by quality.
+func (c *Catalog) Match(candidates ...string) string {
+	for _, cand := range candidates {
+		for _, tag := range acceptTags(cand) {
+			base := strings.ToLower(tag)
+			if i := strings.IndexAny(base, "-_"); i >= 0 {
+				base = base[:i]
+			}
+			if c.Supports(base) {
+				return base
+			}
+		}
+	}
+	return ""
+}
+
+// acceptTags splits an Accept-Language value into its tags, highest quality
+// first. A plain tag is a one-entry header.
+func acceptTags(header string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if _, err := fmt.Sscanf(v, "%g", &q); err != nil {
+				q = 0
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{tag, q})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.tag
+	}
+	return out
+}
+
+// Message is a sentence kept as its key and arguments until the reader's
+// language is known.
+type Message struct {
+	Key  string
+	Args []any
+}
+
+// Render renders m in lang.
+func (c *Catalog) Render(lang string, m Message) string { return c.T(lang, m.Key, m.Args...) }
+
+// Error is an error whose text is a catalog message. Error() is the English,
+// for logs and for any caller that does not localize; Localize renders it for
+// a reader.
+type Error struct {
+	Message
+	cat *Catalog
+}
+
+func (e *Error) Error() string { return e.cat.Render(English, e.Message) }
+
+// Errorf returns an error carrying key and its name/value args.
+func (c *Catalog) Errorf(key string, args ...any) error {
+	return &Error{Message: Message{Key: key, Args: args}, cat: c}
+}
+
+// Localize renders err for a reader of lang: a catalog error in that language,
+// anything else as its own text.
+func (c *Catalog) Localize(lang string, err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return c.Render(lang, e.Message)
+	}
+	return err.Error()
+}
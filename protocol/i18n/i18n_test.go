@@ -0,0 +1,154 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	c, err := Load(fstest.MapFS{
+		"en.json": {Data: []byte(`{"blocker.order": "order {order} in {status}", "ok": "OK", "only.en": "English only"}`)},
+		"es.json": {Data: []byte(`{"blocker.order": "pedido {order} en {status}", "ok": "Aceptar"}`)},
+		"ja.json": {Data: []byte(`{"blocker.order": "{status} の注文 {order}", "ok": "OK"}`)},
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return c
+}
+
+func TestT_NamedArgsFollowTheTranslationsWordOrder(t *testing.T) {
+	c := testCatalog(t)
+	cases := []struct{ lang, want string }{
+		{"en", "order 703 in in_transit"},
+		{"es", "pedido 703 en in_transit"},
+		{"ja", "in_transit の注文 703"},
+	}
+	for _, tc := range cases {
+		if got := c.T(tc.lang, "blocker.order", "order", 703, "status", "in_transit"); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.lang, got, tc.want)
+		}
+	}
+}
+
+func TestT_FallsBackToEnglishThenTheKey(t *testing.T) {
+	c := testCatalog(t)
+	if got := c.T("es", "only.en"); got != "English only" {
+		t.Errorf("key es lacks: got %q, want the English", got)
+	}
+	if got := c.T("fr", "ok"); got != "OK" {
+		t.Errorf("language the catalog lacks: got %q, want the English", got)
+	}
+	if got := c.T("es", "no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key: got %q, want the key", got)
+	}
+	if got := c.Missing("es"); len(got) != 1 || got[0] != "only.en" {
+		t.Errorf("Missing(es) = %v, want [only.en]", got)
+	}
+}
+
+func TestLoad_Rejects(t *testing.T) {
+	cases := []struct {
+		name string
+		fs   fstest.MapFS
+		want string
+	}{
+		{"no English", fstest.MapFS{"es.json": {Data: []byte(`{}`)}}, "no en.json"},
+		{"key English lacks", fstest.MapFS{
+			"en.json": {Data: []byte(`{"a": "A"}`)},
+			"es.json": {Data: []byte(`{"b": "B"}`)},
+		}, `key "b" is not in en.json`},
+		{"placeholder renamed", fstest.MapFS{
+			"en.json": {Data: []byte(`{"a": "task at {node}"}`)},
+			"ja.json": {Data: []byte(`{"a": "{nodo} のタスク"}`)},
+		}, "uses {nodo}"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(tc.fs)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("want error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestMatch_AcceptLanguageByQuality(t *testing.T) {
+	c := testCatalog(t)
+	cases := []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"es-MX"}, "es"},
+		{[]string{"ja_JP"}, "ja"},
+		{[]string{"fr-FR,fr;q=0.9,ja;q=0.5,es;q=0.8"}, "es"},
+		{[]string{"de", "fr;q=0.9"}, ""},
+		{[]string{"", "ja"}, "ja"},
+		{[]string{"es;q=0"}, ""},
+	}
+	for _, tc := range cases {
+		if got := c.Match(tc.in...); got != tc.want {
+			t.Errorf("Match(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestErrorf_LocalizesThroughWrapping(t *testing.T) {
+	c := testCatalog(t)
+	err := fmt.Errorf("refuse: %w", c.Errorf("blocker.order", "order", 9, "status", "queued"))
+	if got := c.Localize("es", err); got != "pedido 9 en queued" {
+		t.Errorf("Localize(es) = %q", got)
+	}
+	if got := err.Error(); got != "refuse: order 9 in queued" {
+		t.Errorf("Error() = %q, want the English", got)
+	}
+	if got := c.Localize("es", errors.New("plain")); got != "plain" {
+		t.Errorf("plain error = %q", got)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	cases := []struct {
+		lang     string
+		v        float64
+		decimals int
+		want     string
+	}{
+		{"en", 1234567.891, 2, "1,234,567.89"},
+		{"ja", 1234567.891, 0, "1,234,568"},
+		{"es", 1234567.891, 2, "1.234.567,89"},
+		{"es", 1234.5, 1, "1234,5"},
+		{"es", 12345, 0, "12.345"},
+		{"en", -1234.5, 1, "-1,234.5"},
+		{"en", 999, 0, "999"},
+		{"xx", 1234, 0, "1,234"},
+	}
+	for _, tc := range cases {
+		if got := FormatNumber(tc.lang, tc.v, tc.decimals); got != tc.want {
+			t.Errorf("FormatNumber(%s, %v, %d) = %q, want %q", tc.lang, tc.v, tc.decimals, got, tc.want)
+		}
+	}
+}
+
+func TestFormatTime_InThePlantZone(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	at := time.Date(2026, 3, 5, 4, 30, 0, 0, time.UTC) // 22:30 on the 4th in Chicago
+	cases := map[string]string{
+		"en": "Mar 4, 2026 22:30",
+		"es": "4 mar 2026 22:30",
+		"ja": "2026年3月4日 22:30",
+	}
+	for lang, want := range cases {
+		if got := FormatTime(lang, at, chicago); got != want {
+			t.Errorf("FormatTime(%s) = %q, want %q", lang, got, want)
+		}
+	}
+}
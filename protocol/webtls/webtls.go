@@ -0,0 +1,363 @@
+// Package webtls serves the Core and Edge web UIs over HTTPS.
+//
+// A plant either supplies a certificate (CertFile/KeyFile, issued by its own
+// PKI) or lets the process generate one. Generation is two-level on purpose:
+// one plant CA, then a host certificate per machine signed by it. Browsers and
+// the edge's Core client are told to trust the CA once, and every host it
+// signs — Core and each edge — is then trusted without a per-machine prompt.
+// The CA key is what signs, so it is written 0600 and is the one file to
+// protect; an edge given a copy of Core's CA issues its own host certificate
+// from it, and an edge with none generates a CA of its own.
+package webtls
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Lifetimes of generated certificates. The host certificate is reissued at
+// startup once it is inside RenewBefore, so a process restarted at least
+// monthly never serves an expired one; the CA is never reissued automatically,
+// because every browser that trusts it would have to be told again.
+const (
+	CALifetime   = 10 * 365 * 24 * time.Hour
+	HostLifetime = 397 * 24 * time.Hour // the longest browsers accept
+	RenewBefore  = 30 * 24 * time.Hour
+)
+
+// HSTSMaxAge is the Strict-Transport-Security lifetime sent while TLS is on.
+// Browsers ignore the header on a connection with certificate errors, so it
+// cannot lock anyone out of a host whose CA they have not yet trusted.
+const HSTSMaxAge = 180 * 24 * time.Hour
+
+// Options says where the certificate comes from.
+type Options struct {
+	// CertFile and KeyFile are an operator-supplied certificate. When set they
+	// are used as they are and nothing is generated.
+	CertFile string
+	KeyFile  string
+	// Generate creates the plant CA and a host certificate when no
+	// certificate is supplied.
+	Generate bool
+	// Dir holds generated files: ca.pem, ca-key.pem, host.pem, host-key.pem.
+	Dir string
+	// CAFile and CAKeyFile override the CA paths inside Dir, for a CA copied
+	// from another host.
+	CAFile    string
+	CAKeyFile string
+	// Hosts are the names and addresses the host certificate must cover.
+	// Empty means this machine's hostname, localhost and its interface
+	// addresses.
+	Hosts []string
+}
+
+// Certificate is the prepared serving certificate.
+type Certificate struct {
+	TLSConfig *tls.Config
+	NotAfter  time.Time
+	// CAFile is the plant CA that signed a generated certificate; empty for a
+	// supplied one.
+	CAFile string
+	// Generated reports that this call issued a new host certificate.
+	Generated bool
+}
+
+// Prepare loads or generates the serving certificate.
+func Prepare(opts Options) (*Certificate, error) {
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, errors.New("web tls: cert_file and key_file must be set together")
+		}
+		return load(opts.CertFile, opts.KeyFile, "", false)
+	}
+	if !opts.Generate {
+		return nil, errors.New("web tls: set cert_file and key_file, or self_signed")
+	}
+	if opts.Dir == "" {
+		return nil, errors.New("web tls: self_signed needs a dir for the generated files")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("web tls: %w", err)
+	}
+	caFile, caKeyFile := opts.CAFile, opts.CAKeyFile
+	if caFile == "" {
+		caFile = filepath.Join(opts.Dir, "ca.pem")
+	}
+	if caKeyFile == "" {
+		caKeyFile = filepath.Join(opts.Dir, "ca-key.pem")
+	}
+	ca, caKey, err := ensureCA(caFile, caKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	hosts := opts.Hosts
+	if len(hosts) == 0 {
+		hosts = DefaultHosts()
+	}
+	certFile := filepath.Join(opts.Dir, "host.pem")
+	keyFile := filepath.Join(opts.Dir, "host-key.pem")
+	issued := false
+	if reason := needsReissue(certFile, ca, hosts, time.Now()); reason != "" {
+		if err := issueHost(certFile, keyFile, ca, caKey, hosts); err != nil {
+			return nil, err
+		}
+		issued = true
+	}
+	return load(certFile, keyFile, caFile, issued)
+}
+
+func load(certFile, keyFile, caFile string, generated bool) (*Certificate, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("web tls: load certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("web tls: parse certificate: %w", err)
+	}
+	return &Certificate{
+		TLSConfig: &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{pair},
+		},
+		NotAfter:  leaf.NotAfter,
+		CAFile:    caFile,
+		Generated: generated,
+	}, nil
+}
+
+// DefaultHosts is this machine's hostname, localhost, and the addresses of
+// its up interfaces — what a browser on the plant LAN will type.
+func DefaultHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if name, err := os.Hostname(); err == nil && name != "" {
+		hosts = append(hosts, name)
+	}
+	ifaces, _ := net.Interfaces()
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, a := range addrs {
+			if ipn, ok := a.(*net.IPNet); ok && !ipn.IP.IsLinkLocalUnicast() {
+				hosts = append(hosts, ipn.IP.String())
+			}
+		}
+	}
+	slices.Sort(hosts)
+	return slices.Compact(hosts)
+}
+
+func ensureCA(certFile, keyFile string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	if _, err := os.Stat(certFile); err == nil {
+		return readCA(certFile, keyFile)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("web tls: %w", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	host, _ := os.Hostname()
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial(),
+		Subject:               pkix.Name{Organization: []string{"ShinGo"}, CommonName: "ShinGo plant CA (" + host + ")"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(CALifetime),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		MaxPathLenZero:        true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("web tls: create CA: %w", err)
+	}
+	if err := writePEM(keyFile, key, 0o600); err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return nil, nil, fmt.Errorf("web tls: write CA: %w", err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	return ca, key, err
+}
+
+func readCA(certFile, keyFile string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	ca, err := readCert(certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("web tls: CA key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("web tls: no PEM key in %s", keyFile)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("web tls: CA key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("web tls: CA key in %s is not ECDSA", keyFile)
+	}
+	return ca, key, nil
+}
+
+func readCert(file string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("web tls: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("web tls: no certificate in %s", file)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// needsReissue says why the host certificate at certFile cannot be served
+// as it is, or "" when it can.
+func needsReissue(certFile string, ca *x509.Certificate, hosts []string, now time.Time) string {
+	cert, err := readCert(certFile)
+	if err != nil {
+		return "missing"
+	}
+	if now.Add(RenewBefore).After(cert.NotAfter) {
+		return "expiring"
+	}
+	if !bytes.Equal(cert.RawIssuer, ca.RawSubject) || cert.CheckSignatureFrom(ca) != nil {
+		return "signed by another CA"
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return "does not cover " + h
+		}
+	}
+	return ""
+}
+
+func issueHost(certFile, keyFile string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial(),
+		Subject:      pkix.Name{Organization: []string{"ShinGo"}, CommonName: hosts[0]},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(HostLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("web tls: issue host certificate: %w", err)
+	}
+	if err := writePEM(keyFile, key, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return fmt.Errorf("web tls: write host certificate: %w", err)
+	}
+	return nil
+}
+
+func writePEM(file string, key *ecdsa.PrivateKey, mode os.FileMode) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), mode); err != nil {
+		return fmt.Errorf("web tls: write key: %w", err)
+	}
+	return nil
+}
+
+func serial() *big.Int {
+	n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	return n
+}
+
+// CertPool is the system roots plus every PEM bundle in files, for a client
+// that must trust the plant CA. Empty file names are skipped.
+func CertPool(files ...string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("web tls: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("web tls: no certificates in %s", f)
+		}
+	}
+	return pool, nil
+}
+
+// HSTS sets Strict-Transport-Security on every response.
+func HSTS(next http.Handler) http.Handler {
+	value := "max-age=" + strconv.Itoa(int(HSTSMaxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", value)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RedirectHandler sends every plain-HTTP request to the same path over HTTPS
+// on httpsPort. 308 keeps the method, so a bookmarked form POST still lands.
+func RedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		target := "https://" + net.JoinHostPort(host, strconv.Itoa(httpsPort)) + r.URL.RequestURI()
+		if httpsPort == 443 {
+			target = "https://" + bracketIPv6(host) + r.URL.RequestURI()
+		}
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
+
+func bracketIPv6(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "[" + host + "]"
+	}
+	return host
+}
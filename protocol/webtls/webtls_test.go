@@ -0,0 +1,185 @@
+package webtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrepare_GeneratesCAAndHostCert(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	cert, err := Prepare(Options{Generate: true, Dir: dir, Hosts: []string{"core.plant.local", "10.0.0.5"}})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if !cert.Generated {
+		t.Error("first Prepare did not report a generated certificate")
+	}
+	if cert.CAFile != filepath.Join(dir, "ca.pem") {
+		t.Errorf("CAFile = %q", cert.CAFile)
+	}
+	if left := time.Until(cert.NotAfter); left < HostLifetime-2*time.Hour || left > HostLifetime {
+		t.Errorf("NotAfter %v is not one host lifetime away", cert.NotAfter)
+	}
+	info, err := os.Stat(filepath.Join(dir, "ca-key.pem"))
+	if err != nil {
+		t.Fatalf("CA key not written: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("CA key mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The host certificate must chain to the CA a client is told to trust.
+	pool, err := CertPool(cert.CAFile)
+	if err != nil {
+		t.Fatalf("CertPool: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.TLSConfig.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("parse leaf: %v", err)
+	}
+	for _, host := range []string{"core.plant.local", "10.0.0.5"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: pool}); err != nil {
+			t.Errorf("verify for %s: %v", host, err)
+		}
+	}
+}
+
+func TestPrepare_ReusesUntilHostsChange(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	opts := Options{Generate: true, Dir: dir, Hosts: []string{"edge-1"}}
+	if _, err := Prepare(opts); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	caBefore, _ := os.ReadFile(filepath.Join(dir, "ca.pem"))
+
+	again, err := Prepare(opts)
+	if err != nil {
+		t.Fatalf("second Prepare: %v", err)
+	}
+	if again.Generated {
+		t.Error("an unchanged, valid certificate was reissued")
+	}
+
+	opts.Hosts = append(opts.Hosts, "edge-1.plant.local")
+	changed, err := Prepare(opts)
+	if err != nil {
+		t.Fatalf("third Prepare: %v", err)
+	}
+	if !changed.Generated {
+		t.Error("adding a host did not reissue the certificate")
+	}
+	caAfter, _ := os.ReadFile(filepath.Join(dir, "ca.pem"))
+	if string(caBefore) != string(caAfter) {
+		t.Error("the plant CA was replaced; browsers that trust it would break")
+	}
+}
+
+func TestPrepare_SharedCASignsBothHosts(t *testing.T) {
+	t.Parallel()
+	coreDir, edgeDir := t.TempDir(), t.TempDir()
+	core, err := Prepare(Options{Generate: true, Dir: coreDir, Hosts: []string{"core"}})
+	if err != nil {
+		t.Fatalf("core Prepare: %v", err)
+	}
+	edge, err := Prepare(Options{
+		Generate: true, Dir: edgeDir, Hosts: []string{"edge"},
+		CAFile: core.CAFile, CAKeyFile: filepath.Join(coreDir, "ca-key.pem"),
+	})
+	if err != nil {
+		t.Fatalf("edge Prepare: %v", err)
+	}
+	pool, _ := CertPool(core.CAFile)
+	leaf, _ := x509.ParseCertificate(edge.TLSConfig.Certificates[0].Certificate[0])
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "edge", Roots: pool}); err != nil {
+		t.Errorf("edge certificate does not chain to Core's CA: %v", err)
+	}
+}
+
+func TestPrepare_SuppliedCertificate(t *testing.T) {
+	t.Parallel()
+	gen, err := Prepare(Options{Generate: true, Dir: t.TempDir(), Hosts: []string{"h"}})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	dir := filepath.Dir(gen.CAFile)
+	cert, err := Prepare(Options{CertFile: filepath.Join(dir, "host.pem"), KeyFile: filepath.Join(dir, "host-key.pem")})
+	if err != nil {
+		t.Fatalf("Prepare supplied: %v", err)
+	}
+	if cert.Generated || cert.CAFile != "" || !cert.NotAfter.Equal(gen.NotAfter) {
+		t.Errorf("supplied certificate = %+v", cert)
+	}
+}
+
+func TestPrepare_RejectsIncompleteOptions(t *testing.T) {
+	t.Parallel()
+	for name, opts := range map[string]Options{
+		"nothing":         {},
+		"cert only":       {CertFile: "host.pem"},
+		"generate no dir": {Generate: true},
+	} {
+		if _, err := Prepare(opts); err == nil {
+			t.Errorf("%s: Prepare accepted %+v", name, opts)
+		}
+	}
+}
+
+func TestHSTS(t *testing.T) {
+	t.Parallel()
+	h := HSTS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "max-age=15552000" {
+		t.Errorf("Strict-Transport-Security = %q", got)
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		port      int
+		host, url string
+		want      string
+	}{
+		{8083, "core.plant.local:8080", "/orders?id=4", "https://core.plant.local:8083/orders?id=4"},
+		{443, "core.plant.local", "/", "https://core.plant.local/"},
+		{8443, "[::1]:80", "/x", "https://[::1]:8443/x"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, tc.url, nil)
+		req.Host = tc.host
+		rec := httptest.NewRecorder()
+		RedirectHandler(tc.port).ServeHTTP(rec, req)
+		if rec.Code != http.StatusPermanentRedirect || rec.Header().Get("Location") != tc.want {
+			t.Errorf("%s%s → %d %q, want 308 %q", tc.host, tc.url, rec.Code, rec.Header().Get("Location"), tc.want)
+		}
+	}
+}
+
+func TestServeWithGeneratedCertificate(t *testing.T) {
+	t.Parallel()
+	cert, err := Prepare(Options{Generate: true, Dir: t.TempDir(), Hosts: []string{"127.0.0.1"}})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = cert.TLSConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool, _ := CertPool(cert.CAFile)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET over TLS trusting the plant CA: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,177 @@
+package backupstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FilesystemStorage keeps archives under a directory, keyed by path: a
+// local disk, or more usefully an NFS or SMB mount on a plant file server.
+// Object metadata has nowhere to live and is dropped; nothing reads it back.
+type FilesystemStorage struct {
+	root string
+}
+
+func NewFilesystemStorage(cfg FilesystemConfig) (*FilesystemStorage, error) {
+	root := strings.TrimSpace(cfg.Path)
+	if root == "" {
+		return nil, fmt.Errorf("backup directory is required")
+	}
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("backup directory %q must be an absolute path", root)
+	}
+	return &FilesystemStorage{root: filepath.Clean(root)}, nil
+}
+
+// Test checks the directory exists before the round trip: a share that is
+// not mounted shows up as a missing directory, and should fail here rather
+// than quietly fill the disk under the mount point.
+func (s *FilesystemStorage) Test(ctx context.Context, prefix string) error {
+	if err := s.checkRoot(); err != nil {
+		return err
+	}
+	return RoundTrip(ctx, s, prefix)
+}
+
+func (s *FilesystemStorage) checkRoot() error {
+	info, err := os.Stat(s.root)
+	if err != nil {
+		return fmt.Errorf("backup directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("backup directory %s is not a directory", s.root)
+	}
+	return nil
+}
+
+func (s *FilesystemStorage) path(key string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a partial file beside the target and renames it into place,
+// so a crash mid-upload never leaves a truncated archive that List offers
+// for restore.
+func (s *FilesystemStorage) Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error {
+	if err := s.checkRoot(); err != nil {
+		return err
+	}
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	tmp := dst + PartialSuffix
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *FilesystemStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("get object %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// List walks the directory the prefix names and reports every complete file
+// whose key starts with it.
+func (s *FilesystemStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if err := s.checkRoot(); err != nil {
+		return nil, err
+	}
+	start := s.root
+	if dir := path.Dir(prefix); strings.Contains(prefix, "/") && dir != "." {
+		if err := CheckKey(dir); err != nil {
+			return nil, err
+		}
+		start = filepath.Join(s.root, filepath.FromSlash(dir))
+	}
+	var out []ObjectInfo
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == start && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(p, PartialSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		modified := info.ModTime().UTC()
+		out = append(out, ObjectInfo{Key: key, Size: info.Size(), LastModified: &modified})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list objects for %s: %w", prefix, err)
+	}
+	return out, nil
+}
+
+// Delete removes the file, then any directories the removal left empty, up
+// to the root — so pruning a month does not leave a tree of empty folders.
+func (s *FilesystemStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete object %s: %w", key, err)
+	}
+	for dir := filepath.Dir(p); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
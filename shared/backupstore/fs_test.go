@@ -0,0 +1,66 @@
+package backupstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shingo/protocol/testutil"
+)
+
+func TestFilesystemStorageRefusesMissingDirectory(t *testing.T) {
+	t.Parallel()
+	missing := filepath.Join(t.TempDir(), "not-mounted")
+	storage, err := NewFilesystemStorage(FilesystemConfig{Path: missing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Test(context.Background(), "line-1/"); err == nil {
+		t.Fatal("test passed against a missing directory")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Fatalf("missing directory was created: %v", err)
+	}
+	if _, err := NewFilesystemStorage(FilesystemConfig{Path: "relative/dir"}); err == nil {
+		t.Fatal("relative directory accepted")
+	}
+}
+
+// Put lands the file whole and Delete takes the folders it emptied with it;
+// List skips an interrupted upload and a key cannot climb out of the root.
+func TestFilesystemStoragePutListDelete(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	storage, err := New(Config{Kind: KindFilesystem, Filesystem: FilesystemConfig{Path: root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	testutil.MustNoErr(t, storage.Test(ctx, "line-1/"), "test")
+
+	key := "line-1/2026/10/18/a.tar.gz"
+	testutil.MustNoErr(t, storage.Put(ctx, key, strings.NewReader("archive"), 7, nil), "put")
+	testutil.MustNoErr(t, os.WriteFile(filepath.Join(root, "line-1", "b.tar.gz"+PartialSuffix), []byte("half"), 0o644), "partial")
+	items, err := storage.List(ctx, "line-1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Key != key || items[0].Size != 7 || items[0].LastModified == nil {
+		t.Fatalf("list = %+v", items)
+	}
+	for _, bad := range []string{"../escape", "/abs", "a//b", `a\b`} {
+		if _, err := storage.Get(ctx, bad); err == nil {
+			t.Errorf("Get(%q) accepted", bad)
+		}
+	}
+
+	testutil.MustNoErr(t, storage.Delete(ctx, key), "delete")
+	if _, err := os.Stat(filepath.Join(root, "line-1", "2026")); !os.IsNotExist(err) {
+		t.Fatalf("emptied folders left behind: %v", err)
+	}
+	if _, err := New(Config{Kind: "tape"}); err == nil {
+		t.Fatal("unknown kind accepted")
+	}
+}
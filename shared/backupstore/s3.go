@@ -1,7 +1,6 @@
-package backup
+package backupstore
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -11,8 +10,6 @@ import (
 	"strings"
 	"time"
 
-	"shingoedge/config"
-
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
@@ -22,7 +19,7 @@ type S3Storage struct {
 	bucket string
 }
 
-func NewS3Storage(cfg config.BackupS3Config) (*S3Storage, error) {
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
 	if strings.TrimSpace(cfg.Endpoint) == "" {
 		return nil, fmt.Errorf("backup endpoint is required")
 	}
@@ -84,28 +81,8 @@ func NewS3Storage(cfg config.BackupS3Config) (*S3Storage, error) {
 	return &S3Storage{client: client, bucket: strings.TrimSpace(cfg.Bucket)}, nil
 }
 
-func (s *S3Storage) Test(ctx context.Context, stationID string) error {
-	key := objectPrefix(stationID) + ".healthcheck-" + time.Now().UTC().Format("20060102T150405.000000000Z") + ".txt"
-	body := []byte("ok")
-	if err := s.Put(ctx, key, bytes.NewReader(body), int64(len(body)), map[string]string{"station-id": stationID}); err != nil {
-		return err
-	}
-	rc, err := s.Get(ctx, key)
-	if err != nil {
-		_ = s.Delete(ctx, key)
-		return err
-	}
-	defer rc.Close()
-	got, err := io.ReadAll(rc)
-	if err != nil {
-		_ = s.Delete(ctx, key)
-		return fmt.Errorf("read test object: %w", err)
-	}
-	if string(got) != "ok" {
-		_ = s.Delete(ctx, key)
-		return fmt.Errorf("unexpected test object contents")
-	}
-	return s.Delete(ctx, key)
+func (s *S3Storage) Test(ctx context.Context, prefix string) error {
+	return RoundTrip(ctx, s, prefix)
 }
 
 func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error {
@@ -0,0 +1,124 @@
+// Package backupstore is where backups go: a directory (a local disk, or an
+// NFS or SMB mount) or an S3-compatible bucket, behind one Storage interface.
+//
+// It lives in shared/ because Core's and the Edge's backups write to the same
+// kinds of target and used to carry a copy each of both backends. Each side
+// keeps its own config section, with its own defaults and YAML, and adapts it
+// to Config here; the Edge adds its SFTP backend on top. Keys are the
+// caller's: this package only refuses one that would escape a directory.
+package backupstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type Storage interface {
+	// Test round-trips a small object under prefix, the key prefix the
+	// caller's archives live under, so a bucket policy scoped to it is what
+	// gets tested.
+	Test(ctx context.Context, prefix string) error
+	Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type ObjectInfo struct {
+	Key          string     `json:"key"`
+	Size         int64      `json:"size"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+}
+
+// Storage kinds, as both sides' config files spell them.
+const (
+	KindFilesystem = "filesystem"
+	KindS3         = "s3"
+)
+
+// Config names a target. Kind is resolved by the caller — an empty kind
+// means different things to Core and the Edge — and only the matching
+// section is read.
+type Config struct {
+	Kind       string
+	Filesystem FilesystemConfig
+	S3         S3Config
+}
+
+// FilesystemConfig is a directory target. It must already exist; it is never
+// created, so an unmounted share fails the backup instead of filling the root
+// disk.
+type FilesystemConfig struct {
+	Path string
+}
+
+// S3Config is an S3-compatible target.
+type S3Config struct {
+	Endpoint              string
+	Bucket                string
+	Region                string
+	AccessKey             string
+	SecretKey             string
+	UsePathStyle          bool
+	InsecureSkipTLSVerify bool
+}
+
+// New builds the backend cfg.Kind names.
+func New(cfg Config) (Storage, error) {
+	switch strings.TrimSpace(cfg.Kind) {
+	case KindFilesystem:
+		return NewFilesystemStorage(cfg.Filesystem)
+	case KindS3:
+		return NewS3Storage(cfg.S3)
+	}
+	return nil, fmt.Errorf("unknown backup storage %q", cfg.Kind)
+}
+
+// RoundTrip is every backend's Test: write a small object under prefix, read
+// it back, delete it.
+func RoundTrip(ctx context.Context, s Storage, prefix string) error {
+	key := prefix + ".healthcheck-" + time.Now().UTC().Format("20060102T150405.000000000Z") + ".txt"
+	body := []byte("ok")
+	if err := s.Put(ctx, key, bytes.NewReader(body), int64(len(body)), nil); err != nil {
+		return err
+	}
+	rc, err := s.Get(ctx, key)
+	if err != nil {
+		_ = s.Delete(ctx, key)
+		return err
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		_ = s.Delete(ctx, key)
+		return fmt.Errorf("read test object: %w", err)
+	}
+	if string(got) != "ok" {
+		_ = s.Delete(ctx, key)
+		return fmt.Errorf("unexpected test object contents")
+	}
+	return s.Delete(ctx, key)
+}
+
+// CheckKey refuses a key that would escape a directory-backed store's root.
+// Callers build keys from a sanitized station ID, so this only trips on a
+// hand-typed restore key.
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid backup key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid backup key %q", key)
+		}
+	}
+	return nil
+}
+
+// PartialSuffix marks an upload in progress on directory-backed stores. It is
+// renamed away when the upload completes, and List never reports it.
+const PartialSuffix = ".partial"
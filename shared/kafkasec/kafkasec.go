@@ -0,0 +1,141 @@
+// Package kafkasec builds kafka-go's Dialer and Transport with TLS and SASL.
+//
+// kafka-go takes TLS and SASL in places that do not share a type: the Dialer
+// (the probe, topic creation, readers) and the Transport (the writer). Both
+// are built here from one Config so no connection can end up plaintext
+// because its path was missed. It lives in shared/ because Core and the Edge
+// connect to the same broker listener and used to carry a copy each; each
+// side validates its own KafkaConfig and adapts it to Config.
+package kafkasec
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SASL mechanisms accepted in SASLConfig.Mechanism. The names are the
+// broker's own (sasl.enabled.mechanisms), lower-cased.
+const (
+	SASLPlain       = "plain"
+	SASLSCRAMSHA256 = "scram-sha-256"
+	SASLSCRAMSHA512 = "scram-sha-512"
+)
+
+// Config is the security of every broker connection. Both layers off is
+// plaintext.
+type Config struct {
+	TLS  TLSConfig
+	SASL SASLConfig
+}
+
+// TLSConfig encrypts broker connections. CAFile empty means the system
+// roots; CertFile and KeyFile together are a client certificate.
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// SASLConfig authenticates to the broker. Mechanism empty disables SASL.
+type SASLConfig struct {
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// Dialer returns the dialer for the probe, topic creation and readers.
+// timeout bounds one broker dial, TLS and SASL handshakes included.
+func Dialer(c Config, timeout time.Duration) (*kafka.Dialer, error) {
+	tlsCfg, mech, err := security(c)
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		Timeout:       timeout,
+		DualStack:     true,
+		TLS:           tlsCfg,
+		SASLMechanism: mech,
+	}, nil
+}
+
+// Transport returns the writer's transport, secured the same way.
+func Transport(c Config, timeout time.Duration) (*kafka.Transport, error) {
+	tlsCfg, mech, err := security(c)
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Transport{
+		DialTimeout: timeout,
+		TLS:         tlsCfg,
+		SASL:        mech,
+	}, nil
+}
+
+// security returns the TLS config and SASL mechanism for c. Either is nil
+// when that layer is off.
+func security(c Config) (*tls.Config, sasl.Mechanism, error) {
+	tlsCfg, err := tlsConfig(c.TLS)
+	if err != nil {
+		return nil, nil, err
+	}
+	mech, err := mechanism(c.SASL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tlsCfg, mech, nil
+}
+
+func tlsConfig(t TLSConfig) (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka tls: read ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("kafka tls: no certificates in %s", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka tls: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func mechanism(s SASLConfig) (sasl.Mechanism, error) {
+	switch s.Mechanism {
+	case "":
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: s.Username, Password: s.Password}, nil
+	case SASLSCRAMSHA256:
+		return scram.Mechanism(scram.SHA256, s.Username, s.Password)
+	case SASLSCRAMSHA512:
+		return scram.Mechanism(scram.SHA512, s.Username, s.Password)
+	}
+	return nil, fmt.Errorf("kafka sasl: unknown mechanism %q", s.Mechanism)
+}
@@ -0,0 +1,119 @@
+package kafkasec
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+)
+
+// writeTestCert writes a self-signed certificate and its key as PEM files,
+// standing in for both the plant CA bundle and a client certificate.
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.MustNoErr(t, err, "generate key")
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "shingo test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	testutil.MustNoErr(t, err, "create certificate")
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	testutil.MustNoErr(t, err, "marshal key")
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	testutil.MustNoErr(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600), "write cert")
+	testutil.MustNoErr(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600), "write key")
+	return certFile, keyFile
+}
+
+func TestDialer_Plaintext(t *testing.T) {
+	t.Parallel()
+	d, err := Dialer(Config{}, time.Second)
+	testutil.MustNoErr(t, err, "Dialer")
+	if d.TLS != nil || d.SASLMechanism != nil {
+		t.Fatalf("plaintext config produced TLS=%v SASL=%v", d.TLS, d.SASLMechanism)
+	}
+	if d.Timeout != time.Second {
+		t.Errorf("Timeout = %v, want the 1s passed in", d.Timeout)
+	}
+}
+
+func TestDialerAndTransport_TLSAndSASL(t *testing.T) {
+	t.Parallel()
+	certFile, keyFile := writeTestCert(t)
+	c := Config{
+		TLS: TLSConfig{
+			Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile,
+			ServerName: "kafka.plant.local",
+		},
+		SASL: SASLConfig{Mechanism: SASLSCRAMSHA512, Username: "shingocore", Password: "pw"},
+	}
+	d, err := Dialer(c, time.Second)
+	testutil.MustNoErr(t, err, "Dialer")
+	if d.TLS == nil || d.TLS.RootCAs == nil || len(d.TLS.Certificates) != 1 || d.TLS.ServerName != "kafka.plant.local" {
+		t.Fatalf("TLS = %+v", d.TLS)
+	}
+	if d.TLS.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify set without being configured")
+	}
+	if d.SASLMechanism == nil || d.SASLMechanism.Name() != "SCRAM-SHA-512" {
+		t.Fatalf("SASL mechanism = %v", d.SASLMechanism)
+	}
+
+	tr, err := Transport(c, 2*time.Second)
+	testutil.MustNoErr(t, err, "Transport")
+	if tr.TLS == nil || tr.SASL == nil || tr.SASL.Name() != "SCRAM-SHA-512" {
+		t.Fatalf("transport TLS=%v SASL=%v — the writer must be secured like the readers", tr.TLS, tr.SASL)
+	}
+	if tr.DialTimeout != 2*time.Second {
+		t.Errorf("DialTimeout = %v, want the 2s passed in", tr.DialTimeout)
+	}
+}
+
+func TestSASLMechanismNames(t *testing.T) {
+	t.Parallel()
+	for mech, want := range map[string]string{
+		SASLPlain:       "PLAIN",
+		SASLSCRAMSHA256: "SCRAM-SHA-256",
+		SASLSCRAMSHA512: "SCRAM-SHA-512",
+	} {
+		m, err := mechanism(SASLConfig{Mechanism: mech, Username: "u", Password: "p"})
+		testutil.MustNoErr(t, err, mech)
+		if m.Name() != want {
+			t.Errorf("%s: mechanism %q, want %q", mech, m.Name(), want)
+		}
+	}
+}
+
+func TestDialer_RejectsUnusableSettings(t *testing.T) {
+	t.Parallel()
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	testutil.MustNoErr(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600), "write ca")
+	for name, c := range map[string]Config{
+		"missing ca file":   {TLS: TLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "absent.pem")}},
+		"ca without certs":  {TLS: TLSConfig{Enabled: true, CAFile: notPEM}},
+		"cert without key":  {TLS: TLSConfig{Enabled: true, CertFile: notPEM}},
+		"unknown mechanism": {SASL: SASLConfig{Mechanism: "gssapi", Username: "u"}},
+	} {
+		if _, err := Dialer(c, time.Second); err == nil {
+			t.Errorf("%s: Dialer accepted %+v", name, c)
+		}
+	}
+}
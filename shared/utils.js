@@ -154,7 +154,17 @@ export function formatTime(ts, opts) {
     if (opts && opts.precision === 'ms') {
         return d.toTimeString().slice(0, 8) + '.' + String(d.getMilliseconds()).padStart(3, '0');
     }
-    return d.toLocaleString();
+    return d.toLocaleString(...pageLocale());
+}
+
+// pageLocale is the toLocaleString arguments for this page: the language the
+// server rendered it in (<html lang>) and the plant's zone (<html data-tz>).
+// A page that sets neither gets the browser's own, as before.
+function pageLocale() {
+    const root = typeof document !== 'undefined' && document.documentElement;
+    if (!root) return [];
+    const tz = root.dataset && root.dataset.tz;
+    return [root.lang || undefined, tz ? { timeZone: tz } : undefined];
 }
 
 export function formatDuration(ms) {
@@ -272,13 +282,15 @@ export function installHtmxLiveDurations() {
     }
 }
 
-// Rewrite <time data-utc="..."> elements to the browser's local-time string.
-// Idempotent; safe to re-run after htmx swaps insert new <time> nodes.
+// Rewrite <time data-utc="..."> elements to a local-time string in the page's
+// language and plant zone (see pageLocale). Idempotent; safe to re-run after
+// htmx swaps insert new <time> nodes.
 export function convertTimestamps(root) {
     const scope = root || document;
+    const locale = pageLocale();
     scope.querySelectorAll('time[data-utc]').forEach(elem => {
         const d = new Date(elem.getAttribute('data-utc'));
-        if (!isNaN(d.getTime())) elem.textContent = d.toLocaleString();
+        if (!isNaN(d.getTime())) elem.textContent = d.toLocaleString(...locale);
     });
 }
 
@@ -0,0 +1,306 @@
+// Package alerting decides which alerts a set of rules raises against a
+// snapshot of the plant. It is pure — no database, no clock of its own, no
+// delivery — so every rule kind, the hold-for timing and quiet hours are
+// pinned by plain unit tests.
+//
+// The engine owns the rest (engine/engine_alerts.go): it builds the Snapshot,
+// hands it to an Evaluator every alerts.eval_interval, writes what comes back
+// through service.AlertService, and delivers notifications.
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"shingocore/config"
+	"shingocore/mapfix"
+)
+
+// Rule kinds. A config rule's kind must be one of these.
+const (
+	KindOrderStuck         = "order_stuck"
+	KindEdgeStale          = "edge_stale"
+	KindLinesideLow        = "lineside_low"
+	KindDeadLetters        = "dead_letters"
+	KindFleetDisconnected  = "fleet_disconnected"
+	KindRobotLowConfidence = "robot_low_confidence"
+	KindHotspot            = "localization_hotspot"
+	KindEvent              = "event"
+)
+
+// Severities, lowest first. They are the notify package's words too, so a
+// channel's priority follows an alert's severity without a mapping.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// linesideFreshFor is how old a lineside report may be and still count as the
+// level. Edge reports every minute or so; a row older than this is a station
+// that stopped reporting, which edge_stale says, not a level that is low now.
+const linesideFreshFor = 10 * time.Minute
+
+// Rank orders severities: info 1, warning 2, critical 3, anything else 0.
+func Rank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityCritical:
+		return 3
+	}
+	return 0
+}
+
+// Snapshot is everything the periodic rules read, taken once per evaluation
+// so every rule in a pass sees the same plant.
+type Snapshot struct {
+	Now            time.Time
+	Orders         []OrderAge
+	Edges          []EdgeBeat
+	Lineside       []LinesideLevel
+	DeadLetters    int
+	FleetConnected bool
+	Robots         []RobotConfidence
+	// Hotspots are the places the fleet persistently loses its position
+	// (shingocore/mapfix), worst first. The engine refreshes them on a slow
+	// clock of their own; they move by the day, not by the tick.
+	Hotspots []mapfix.Hotspot
+}
+
+// OrderAge is an active order and when it entered its current status.
+type OrderAge struct {
+	ID        int64
+	StationID string
+	Status    string
+	Since     time.Time
+}
+
+// EdgeBeat is a registered edge and its last heartbeat (nil: never beat).
+type EdgeBeat struct {
+	StationID     string
+	DisplayName   string
+	LastHeartbeat *time.Time
+}
+
+// LinesideLevel is one reported lineside level: the units left on the node,
+// counting the bin there and the loose bucket.
+type LinesideLevel struct {
+	Station    string
+	Node       string
+	Payload    string
+	Level      int
+	ReportedAt time.Time
+}
+
+// RobotConfidence is one robot's localization reading from the fleet cache.
+// Relocating is true while the robot is mid-relocalization, when the vendor's
+// number means nothing.
+type RobotConfidence struct {
+	VehicleID  string
+	Connected  bool
+	Relocating bool
+	Confidence float64
+	X, Y       float64
+}
+
+// Finding is one subject a rule's condition holds for. Key is what alerts are
+// de-duplicated on within the rule — the order id, the station, the robot —
+// so the same condition on the same subject stays one alert.
+type Finding struct {
+	Key     string
+	Subject string
+	Detail  string
+}
+
+// Validate reports what is wrong with a rule, or nil. The engine skips a rule
+// that fails and logs why; the other rules still run.
+func Validate(r config.AlertRule) error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("alert rule has no name")
+	}
+	switch r.Kind {
+	case KindOrderStuck, KindEdgeStale, KindFleetDisconnected:
+		if r.After <= 0 {
+			return fmt.Errorf("alert rule %q: %s needs after", r.Name, r.Kind)
+		}
+	case KindLinesideLow, KindRobotLowConfidence, KindHotspot:
+		if r.Threshold <= 0 {
+			return fmt.Errorf("alert rule %q: %s needs a threshold", r.Name, r.Kind)
+		}
+	case KindDeadLetters:
+	case KindEvent:
+		if len(r.Events) == 0 {
+			return fmt.Errorf("alert rule %q: event rules need events", r.Name)
+		}
+	default:
+		return fmt.Errorf("alert rule %q: unknown kind %q", r.Name, r.Kind)
+	}
+	if r.Severity != "" && Rank(r.Severity) == 0 {
+		return fmt.Errorf("alert rule %q: unknown severity %q", r.Name, r.Severity)
+	}
+	if r.EscalateSeverity != "" && Rank(r.EscalateSeverity) == 0 {
+		return fmt.Errorf("alert rule %q: unknown escalate_severity %q", r.Name, r.EscalateSeverity)
+	}
+	return nil
+}
+
+// SeverityOf is the rule's severity, warning when unset.
+func SeverityOf(r config.AlertRule) string {
+	if r.Severity == "" {
+		return SeverityWarning
+	}
+	return r.Severity
+}
+
+// EscalationSeverity is what an unacknowledged alert escalates to: the rule's
+// escalate_severity, critical when unset.
+func EscalationSeverity(r config.AlertRule) string {
+	if r.EscalateSeverity == "" {
+		return SeverityCritical
+	}
+	return r.EscalateSeverity
+}
+
+// DueEscalation reports whether an alert first seen at firstSeen and still
+// unacknowledged at now has waited out the rule's escalate_after.
+func DueEscalation(r config.AlertRule, firstSeen, now time.Time) bool {
+	return r.EscalateAfter > 0 && now.Sub(firstSeen) >= r.EscalateAfter
+}
+
+// Check is the raw condition: every subject it holds for right now, before
+// any hold-for timing. Event rules have no periodic condition and return nil.
+func Check(r config.AlertRule, s Snapshot) []Finding {
+	var out []Finding
+	switch r.Kind {
+	case KindOrderStuck:
+		for _, o := range s.Orders {
+			if len(r.Statuses) > 0 && !slices.Contains(r.Statuses, o.Status) {
+				continue
+			}
+			if age := s.Now.Sub(o.Since); age >= r.After {
+				out = append(out, Finding{
+					Key:     fmt.Sprintf("order:%d", o.ID),
+					Subject: fmt.Sprintf("Order %d stuck in %s", o.ID, o.Status),
+					Detail:  fmt.Sprintf("%s for %s (station %s)", o.Status, age.Round(time.Minute), orDash(o.StationID)),
+				})
+			}
+		}
+	case KindEdgeStale:
+		for _, e := range s.Edges {
+			if e.LastHeartbeat == nil {
+				continue
+			}
+			if age := s.Now.Sub(*e.LastHeartbeat); age >= r.After {
+				out = append(out, Finding{
+					Key:     "edge:" + e.StationID,
+					Subject: fmt.Sprintf("Edge %s not heard from", edgeName(e)),
+					Detail:  fmt.Sprintf("last heartbeat %s ago", age.Round(time.Minute)),
+				})
+			}
+		}
+	case KindLinesideLow:
+		for _, l := range s.Lineside {
+			if s.Now.Sub(l.ReportedAt) > linesideFreshFor {
+				continue
+			}
+			if len(r.Nodes) > 0 && !slices.Contains(r.Nodes, l.Node) {
+				continue
+			}
+			if len(r.Payloads) > 0 && !slices.Contains(r.Payloads, l.Payload) {
+				continue
+			}
+			if float64(l.Level) < r.Threshold {
+				out = append(out, Finding{
+					Key:     "lineside:" + l.Station + "/" + l.Node + "/" + l.Payload,
+					Subject: fmt.Sprintf("%s low at %s", l.Payload, l.Node),
+					Detail:  fmt.Sprintf("%d left at %s (%s), below %g", l.Level, l.Node, l.Station, r.Threshold),
+				})
+			}
+		}
+	case KindDeadLetters:
+		min := int(r.Threshold)
+		if min < 1 {
+			min = 1
+		}
+		if s.DeadLetters >= min {
+			out = append(out, Finding{
+				Key:     "outbox",
+				Subject: "Outbox has dead letters",
+				Detail:  fmt.Sprintf("%d message(s) gave up retrying", s.DeadLetters),
+			})
+		}
+	case KindFleetDisconnected:
+		if !s.FleetConnected {
+			out = append(out, Finding{Key: "fleet", Subject: "Fleet manager unreachable"})
+		}
+	case KindRobotLowConfidence:
+		for _, rb := range s.Robots {
+			// -0.0 is the vendor's "no estimate in this zone", not a low reading.
+			if !rb.Connected || rb.Relocating || (rb.Confidence == 0 && math.Signbit(rb.Confidence)) {
+				continue
+			}
+			if rb.Confidence < r.Threshold {
+				out = append(out, Finding{
+					Key:     "robot:" + rb.VehicleID,
+					Subject: fmt.Sprintf("Robot %s localization low", rb.VehicleID),
+					Detail:  fmt.Sprintf("confidence %.2f, below %.2f", rb.Confidence, r.Threshold),
+				})
+			}
+		}
+	case KindHotspot:
+		// The robot is slipping where the fleet always slips: the live reading
+		// is the trigger, the hotspot is why it is worth a page, and the fix
+		// travels with it so the page says what to do about the place rather
+		// than the robot.
+		for _, rb := range s.Robots {
+			if !rb.Connected || rb.Relocating || rb.Confidence <= 0 || rb.Confidence >= r.Threshold {
+				continue
+			}
+			for _, h := range s.Hotspots {
+				if !h.Covers(rb.X, rb.Y) {
+					continue
+				}
+				detail := fmt.Sprintf("confidence %.2f, below %.2f, in %s — weak on %d of %d days (%.0f%% of readings)",
+					rb.Confidence, r.Threshold, h.Label(), h.BadDays, h.Days, h.WeakRate*100)
+				if h.Fix != "" {
+					detail += "; recommended: " + h.Fix
+				}
+				out = append(out, Finding{
+					Key:     "robot:" + rb.VehicleID,
+					Subject: fmt.Sprintf("Robot %s slipping in localization hotspot %s", rb.VehicleID, h.Label()),
+					Detail:  detail,
+				})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ownsDuration reports whether a kind's condition already carries its own
+// age — an order's time in status, an edge's heartbeat age — so After is part
+// of the condition rather than a hold-for on top of it.
+func ownsDuration(kind string) bool {
+	return kind == KindOrderStuck || kind == KindEdgeStale
+}
+
+func edgeName(e EdgeBeat) string {
+	if e.DisplayName != "" {
+		return e.DisplayName
+	}
+	return e.StationID
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,187 @@
+package alerting
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"shingocore/config"
+	"shingocore/mapfix"
+	"shingocore/scenemap"
+)
+
+var t0 = time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+
+func keys(fs []Finding) []string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, f.Key)
+	}
+	return out
+}
+
+func sameKeys(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCheck_EachKind(t *testing.T) {
+	t.Parallel()
+	beat := func(ago time.Duration) *time.Time { v := t0.Add(-ago); return &v }
+	snap := Snapshot{
+		Now: t0,
+		Orders: []OrderAge{
+			{ID: 1, Status: "in_transit", Since: t0.Add(-45 * time.Minute)},
+			{ID: 2, Status: "in_transit", Since: t0.Add(-5 * time.Minute)},
+			{ID: 3, Status: "staged", Since: t0.Add(-3 * time.Hour)},
+		},
+		Edges: []EdgeBeat{
+			{StationID: "line-1", LastHeartbeat: beat(20 * time.Minute)},
+			{StationID: "line-2", LastHeartbeat: beat(time.Minute)},
+			{StationID: "never"},
+		},
+		Lineside: []LinesideLevel{
+			{Station: "line-1", Node: "PRESS-1", Payload: "GEAR-A", Level: 4, ReportedAt: t0.Add(-time.Minute)},
+			{Station: "line-1", Node: "PRESS-2", Payload: "GEAR-A", Level: 40, ReportedAt: t0.Add(-time.Minute)},
+			{Station: "line-1", Node: "PRESS-3", Payload: "GEAR-A", Level: 0, ReportedAt: t0.Add(-time.Hour)},
+			{Station: "line-1", Node: "PRESS-1", Payload: "BOLT-B", Level: 1, ReportedAt: t0.Add(-time.Minute)},
+		},
+		DeadLetters:    2,
+		FleetConnected: false,
+		Robots: []RobotConfidence{
+			{VehicleID: "AMR-01", Connected: true, Confidence: 0.31},
+			{VehicleID: "AMR-02", Connected: true, Confidence: 0.92},
+			{VehicleID: "AMR-03", Connected: true, Confidence: math.Copysign(0, -1)},
+			{VehicleID: "AMR-04", Connected: true, Relocating: true, Confidence: 0.05},
+			{VehicleID: "AMR-05", Connected: false, Confidence: 0.05},
+			{VehicleID: "AMR-06", Connected: true, Confidence: 0.45, X: 11, Y: 11},
+			{VehicleID: "AMR-07", Connected: true, Confidence: 0.92, X: 11, Y: 11},
+		},
+		Hotspots: []mapfix.Hotspot{{
+			Key: "zone:08", Kind: mapfix.KindZone, Name: "08", BadDays: 5, Days: 7, WeakRate: 0.4,
+			Polygon: []scenemap.Point{{X: 10, Y: 10}, {X: 12, Y: 10}, {X: 12, Y: 12}, {X: 10, Y: 12}},
+		}},
+	}
+	cases := []struct {
+		rule config.AlertRule
+		want []string
+	}{
+		{config.AlertRule{Kind: KindOrderStuck, After: 30 * time.Minute, Statuses: []string{"in_transit"}}, []string{"order:1"}},
+		{config.AlertRule{Kind: KindOrderStuck, After: 30 * time.Minute}, []string{"order:1", "order:3"}},
+		{config.AlertRule{Kind: KindEdgeStale, After: 15 * time.Minute}, []string{"edge:line-1"}},
+		{config.AlertRule{Kind: KindLinesideLow, Threshold: 10, Payloads: []string{"GEAR-A"}}, []string{"lineside:line-1/PRESS-1/GEAR-A"}},
+		{config.AlertRule{Kind: KindLinesideLow, Threshold: 10, Nodes: []string{"PRESS-1"}},
+			[]string{"lineside:line-1/PRESS-1/GEAR-A", "lineside:line-1/PRESS-1/BOLT-B"}},
+		{config.AlertRule{Kind: KindDeadLetters}, []string{"outbox"}},
+		{config.AlertRule{Kind: KindDeadLetters, Threshold: 5}, nil},
+		{config.AlertRule{Kind: KindFleetDisconnected, After: time.Minute}, []string{"fleet"}},
+		{config.AlertRule{Kind: KindRobotLowConfidence, Threshold: 0.5}, []string{"robot:AMR-01", "robot:AMR-06"}},
+		{config.AlertRule{Kind: KindHotspot, Threshold: 0.5}, []string{"robot:AMR-06"}},
+		{config.AlertRule{Kind: KindEvent, Events: []string{"order_failed"}}, nil},
+	}
+	for _, tc := range cases {
+		if got := keys(Check(tc.rule, snap)); !sameKeys(got, tc.want) {
+			t.Errorf("Check(%+v) = %v, want %v", tc.rule, got, tc.want)
+		}
+	}
+}
+
+// TestEvaluator_HoldFor: a condition without an age of its own is due only
+// after it has held for the rule's after; while it is pending its key is
+// still Holding, and once it clears the timer starts over.
+func TestEvaluator_HoldFor(t *testing.T) {
+	t.Parallel()
+	rules := []config.AlertRule{{Name: "fleet", Kind: KindFleetDisconnected, After: 2 * time.Minute}}
+	ev := NewEvaluator()
+	at := func(d time.Duration, connected bool) Result {
+		return ev.Evaluate(rules, Snapshot{Now: t0.Add(d), FleetConnected: connected})[0]
+	}
+
+	if r := at(0, false); len(r.Due) != 0 || !sameKeys(r.Holding, []string{"fleet"}) {
+		t.Fatalf("t=0: due %v holding %v, want none due and fleet holding", keys(r.Due), r.Holding)
+	}
+	if r := at(time.Minute, false); len(r.Due) != 0 {
+		t.Fatalf("t=1m: due %v, want nothing yet", keys(r.Due))
+	}
+	if r := at(2*time.Minute, false); !sameKeys(keys(r.Due), []string{"fleet"}) {
+		t.Fatalf("t=2m: due %v, want fleet", keys(r.Due))
+	}
+	if r := at(3*time.Minute, true); len(r.Due) != 0 || len(r.Holding) != 0 {
+		t.Fatalf("reconnected: due %v holding %v, want nothing", keys(r.Due), r.Holding)
+	}
+	if r := at(4*time.Minute, false); len(r.Due) != 0 {
+		t.Fatalf("dropped again: due %v, want the timer to start over", keys(r.Due))
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	t.Parallel()
+	at := func(h, m int) time.Time { return time.Date(2026, 10, 18, h, m, 0, 0, time.UTC) }
+	overnight := config.AlertQuietHours{Start: "22:00", End: "06:00"}
+	cases := []struct {
+		q    config.AlertQuietHours
+		t    time.Time
+		want bool
+	}{
+		{overnight, at(23, 30), true},
+		{overnight, at(2, 0), true},
+		{overnight, at(6, 0), false},
+		{overnight, at(12, 0), false},
+		{config.AlertQuietHours{Start: "12:00", End: "13:00"}, at(12, 30), true},
+		{config.AlertQuietHours{Start: "12:00", End: "13:00"}, at(13, 0), false},
+		{config.AlertQuietHours{Start: "08:00", End: "08:00"}, at(8, 0), false},
+		{config.AlertQuietHours{}, at(2, 0), false},
+		{config.AlertQuietHours{Start: "25:00", End: "06:00"}, at(2, 0), false},
+	}
+	for _, tc := range cases {
+		if got := QuietNow(tc.q, tc.t); got != tc.want {
+			t.Errorf("QuietNow(%+v, %s) = %v, want %v", tc.q, tc.t.Format("15:04"), got, tc.want)
+		}
+	}
+
+	night := at(2, 0)
+	if !HoldNotification(overnight, SeverityWarning, night) {
+		t.Error("a warning at 02:00 must wait for morning")
+	}
+	if HoldNotification(overnight, SeverityCritical, night) {
+		t.Error("a critical alert must never wait")
+	}
+	overnight.MinSeverity = SeverityWarning
+	if HoldNotification(overnight, SeverityWarning, night) {
+		t.Error("min_severity warning lets a warning through")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	for _, r := range config.DefaultAlertRules() {
+		if err := Validate(r); err != nil {
+			t.Errorf("shipped rule %q: %v", r.Name, err)
+		}
+	}
+	cases := []struct {
+		rule config.AlertRule
+		want string
+	}{
+		{config.AlertRule{Kind: KindDeadLetters}, "no name"},
+		{config.AlertRule{Name: "a", Kind: "disk_full"}, "unknown kind"},
+		{config.AlertRule{Name: "a", Kind: KindOrderStuck}, "needs after"},
+		{config.AlertRule{Name: "a", Kind: KindLinesideLow}, "threshold"},
+		{config.AlertRule{Name: "a", Kind: KindHotspot}, "threshold"},
+		{config.AlertRule{Name: "a", Kind: KindEvent}, "need events"},
+		{config.AlertRule{Name: "a", Kind: KindDeadLetters, Severity: "loud"}, "unknown severity"},
+	}
+	for _, tc := range cases {
+		if err := Validate(tc.rule); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Validate(%+v) = %v, want error containing %q", tc.rule, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package alerting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"shingocore/config"
+)
+
+// Result is one rule's outcome for one evaluation.
+//
+// Due is what should be raised (or kept raised): the condition holds and has
+// held for the rule's after. Holding is every key the condition holds for at
+// all, due or not — an alert is resolved only when its key drops out of
+// Holding, so an open alert does not flap resolved while the hold-for timer
+// re-arms after a restart.
+type Result struct {
+	Rule    config.AlertRule
+	Due     []Finding
+	Holding []string
+}
+
+// Evaluator runs the periodic rules and remembers, per rule and key, when
+// each condition was first seen to hold — the hold-for half of a rule like
+// "fleet disconnected for more than two minutes".
+//
+// Not safe for concurrent use; the engine's alert loop is its only caller.
+// The memory is deliberately not persisted: after a restart a condition has
+// to hold for after again before a NEW alert is raised, and an alert already
+// open stays open meanwhile because its key is still in Holding.
+type Evaluator struct {
+	since map[string]time.Time
+}
+
+func NewEvaluator() *Evaluator {
+	return &Evaluator{since: make(map[string]time.Time)}
+}
+
+// Evaluate checks every periodic rule against s. Event rules are skipped —
+// they raise from the event bus, not from a snapshot.
+func (ev *Evaluator) Evaluate(rules []config.AlertRule, s Snapshot) []Result {
+	seen := make(map[string]bool)
+	var out []Result
+	for _, r := range rules {
+		if r.Kind == KindEvent {
+			continue
+		}
+		res := Result{Rule: r}
+		for _, f := range Check(r, s) {
+			res.Holding = append(res.Holding, f.Key)
+			if ownsDuration(r.Kind) || r.After <= 0 {
+				res.Due = append(res.Due, f)
+				continue
+			}
+			id := r.Name + "\x00" + f.Key
+			seen[id] = true
+			first, ok := ev.since[id]
+			if !ok {
+				ev.since[id] = s.Now
+				first = s.Now
+			}
+			if s.Now.Sub(first) >= r.After {
+				res.Due = append(res.Due, f)
+			}
+		}
+		out = append(out, res)
+	}
+	// A condition that stopped holding starts its hold-for from scratch.
+	for id := range ev.since {
+		if !seen[id] {
+			delete(ev.since, id)
+		}
+	}
+	return out
+}
+
+// QuietNow reports whether t falls inside the daily quiet-hours window, in
+// t's location. A window whose start is after its end wraps midnight.
+// Unparseable, empty or zero-length windows are never quiet.
+func QuietNow(q config.AlertQuietHours, t time.Time) bool {
+	start, ok1 := clockMinutes(q.Start)
+	end, ok2 := clockMinutes(q.End)
+	if !ok1 || !ok2 || start == end {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// HoldNotification reports whether quiet hours hold back the notification
+// for an alert of this severity: it is quiet, and the severity is below the
+// window's min_severity (critical when unset).
+func HoldNotification(q config.AlertQuietHours, severity string, t time.Time) bool {
+	if !QuietNow(q, t) {
+		return false
+	}
+	min := q.MinSeverity
+	if min == "" {
+		min = SeverityCritical
+	}
+	return Rank(severity) < Rank(min)
+}
+
+// ValidQuietHours reports a malformed window, or nil; empty is valid (off).
+func ValidQuietHours(q config.AlertQuietHours) error {
+	if q.Start == "" && q.End == "" {
+		return nil
+	}
+	if _, ok := clockMinutes(q.Start); !ok {
+		return fmt.Errorf("alerts.quiet_hours.start %q is not HH:MM", q.Start)
+	}
+	if _, ok := clockMinutes(q.End); !ok {
+		return fmt.Errorf("alerts.quiet_hours.end %q is not HH:MM", q.End)
+	}
+	if q.MinSeverity != "" && Rank(q.MinSeverity) == 0 {
+		return fmt.Errorf("alerts.quiet_hours.min_severity %q is not a severity", q.MinSeverity)
+	}
+	return nil
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight.
+func clockMinutes(s string) (int, bool) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, false
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || hh > 23 || mm < 0 || mm > 59 {
+		return 0, false
+	}
+	return hh*60 + mm, true
+}
@@ -0,0 +1,131 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+)
+
+// Anonymization rewrites the columns that name people or machines and blanks
+// free-text notes, so a support snapshot can leave the plant. It is by column
+// name, over text columns only, which is what lets it run on COPY output
+// without knowing the schema: actor and *_by hold who did something, *hostname
+// the edge PCs, note/notes and *_note whatever an operator typed.
+//
+// Pseudonyms are an HMAC under a key drawn per export, so one person keeps one
+// pseudonym throughout a snapshot — "the same operator released all four" is
+// still answerable — but nothing links two snapshots or leads back to a name.
+// Detail and JSON columns are left alone: they are written by Core, not typed.
+
+// machineActors are actor values that name Core itself rather than a person.
+var machineActors = map[string]bool{
+	"":              true,
+	"system":        true,
+	"core":          true,
+	"edge-operator": true,
+}
+
+type columnRule int
+
+const (
+	keepColumn columnRule = iota
+	personColumn
+	hostColumn
+	noteColumn
+)
+
+// ruleFor picks the rewrite for a column from its name and formatted type.
+func ruleFor(column, typ string) columnRule {
+	if typ != "text" && !strings.HasPrefix(typ, "character varying") {
+		return keepColumn
+	}
+	switch {
+	case column == "actor" || strings.HasSuffix(column, "_by"):
+		return personColumn
+	case strings.HasSuffix(column, "hostname"):
+		return hostColumn
+	case column == "note" || column == "notes" || strings.HasSuffix(column, "_note"):
+		return noteColumn
+	}
+	return keepColumn
+}
+
+type anonymizer struct {
+	key []byte
+}
+
+// pseudonym maps a raw COPY field to its stand-in. The field is hashed as
+// COPY wrote it; escaping is deterministic, so equal values still map equally.
+func (a *anonymizer) pseudonym(prefix string, field []byte) []byte {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write(field)
+	return []byte(prefix + hex.EncodeToString(mac.Sum(nil))[:8])
+}
+
+// rewrite applies rules to one COPY text-format line (no trailing newline).
+// Fields are tab-separated and \N is NULL; tabs inside values are escaped, so
+// splitting on a raw tab is exact.
+func (a *anonymizer) rewrite(line []byte, rules []columnRule) []byte {
+	fields := bytes.Split(line, []byte{'\t'})
+	if len(fields) != len(rules) {
+		return line
+	}
+	for i, rule := range rules {
+		f := fields[i]
+		if rule == keepColumn || string(f) == `\N` {
+			continue
+		}
+		switch rule {
+		case personColumn:
+			if machineActors[string(f)] || strings.HasPrefix(string(f), "system:") {
+				continue
+			}
+			fields[i] = a.pseudonym("person-", f)
+		case hostColumn:
+			if len(f) > 0 {
+				fields[i] = a.pseudonym("host-", f)
+			}
+		case noteColumn:
+			fields[i] = nil
+		}
+	}
+	return bytes.Join(fields, []byte{'\t'})
+}
+
+// lineRewriter passes COPY output through rewrite a line at a time.
+type lineRewriter struct {
+	w     io.Writer
+	a     *anonymizer
+	rules []columnRule
+	buf   []byte
+}
+
+func (lr *lineRewriter) Write(p []byte) (int, error) {
+	lr.buf = append(lr.buf, p...)
+	for {
+		i := bytes.IndexByte(lr.buf, '\n')
+		if i < 0 {
+			break
+		}
+		out := append(lr.a.rewrite(lr.buf[:i], lr.rules), '\n')
+		if _, err := lr.w.Write(out); err != nil {
+			return 0, err
+		}
+		lr.buf = lr.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Close flushes a final line with no newline, which COPY never produces but
+// costs nothing to handle.
+func (lr *lineRewriter) Close() error {
+	if len(lr.buf) == 0 {
+		return nil
+	}
+	_, err := lr.w.Write(lr.a.rewrite(lr.buf, lr.rules))
+	lr.buf = nil
+	return err
+}
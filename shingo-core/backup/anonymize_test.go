@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRuleForColumns(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		column, typ string
+		want        columnRule
+	}{
+		{"actor", "text", personColumn},
+		{"approved_by", "text", personColumn},
+		{"claimed_by", "bigint", keepColumn}, // an order ID, not a person
+		{"hostname", "text", hostColumn},
+		{"bound_hostname", "character varying(255)", hostColumn},
+		{"note", "text", noteColumn},
+		{"anomaly_note", "text", noteColumn},
+		{"detail", "text", keepColumn},
+		{"name", "text", keepColumn},
+	}
+	for _, c := range cases {
+		if got := ruleFor(c.column, c.typ); got != c.want {
+			t.Errorf("ruleFor(%q, %q) = %d, want %d", c.column, c.typ, got, c.want)
+		}
+	}
+}
+
+func TestRewriteKeepsIdentityWithinSnapshot(t *testing.T) {
+	t.Parallel()
+	a := &anonymizer{key: []byte("k")}
+	rules := []columnRule{keepColumn, personColumn, hostColumn, noteColumn}
+
+	got := string(a.rewrite([]byte("7\tjsmith\tLINE1-PC\tcalled maintenance, ext 4411"), rules))
+	f := strings.Split(got, "\t")
+	if len(f) != 4 || f[0] != "7" || !strings.HasPrefix(f[1], "person-") || !strings.HasPrefix(f[2], "host-") || f[3] != "" {
+		t.Fatalf("rewrite = %q", got)
+	}
+	again := strings.Split(string(a.rewrite([]byte("8\tjsmith\tLINE1-PC\t"), rules)), "\t")
+	if again[1] != f[1] || again[2] != f[2] {
+		t.Fatalf("same person mapped to %q then %q", f[1], again[1])
+	}
+	other := &anonymizer{key: []byte("another export")}
+	if o := strings.Split(string(other.rewrite([]byte("8\tjsmith\tLINE1-PC\t"), rules)), "\t"); o[1] == f[1] {
+		t.Fatal("pseudonym is the same across exports")
+	}
+
+	for _, keep := range []string{`\N`, "system", "system:inferred", ""} {
+		line := "1\t" + keep + "\t\\N\t\\N"
+		if got := string(a.rewrite([]byte(line), rules)); got != line {
+			t.Errorf("rewrite(%q) = %q, want unchanged", line, got)
+		}
+	}
+}
+
+func TestLineRewriterSplitsAcrossWrites(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	lr := &lineRewriter{w: &out, a: &anonymizer{key: []byte("k")}, rules: []columnRule{keepColumn, noteColumn}}
+	for _, chunk := range []string{"1\tfirst no", "te\n2\tsec", "ond\n3\t\\N\n"} {
+		if _, err := lr.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\t\n2\t\n3\t\\N\n"; out.String() != want {
+		t.Fatalf("rewritten = %q, want %q", out.String(), want)
+	}
+}
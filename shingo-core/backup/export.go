@@ -0,0 +1,361 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ExportOptions shapes an archive.
+type ExportOptions struct {
+	Kind       string // KindBackup or KindSupport; empty is KindBackup
+	Anonymize  bool   // rewrite people, hostnames and notes; see anonymize.go
+	StationID  string
+	AppVersion string
+}
+
+// supportExcluded are tables a support snapshot never carries. admin_users
+// holds password hashes; a dev stack that loads the snapshot gets the default
+// admin back on its next start.
+var supportExcluded = map[string]bool{
+	"admin_users": true,
+}
+
+// Export writes a logical export of the Core schema to w as a tar.gz:
+// manifest.json first, then one COPY text file per table. Everything is read
+// in one REPEATABLE READ transaction, so the archive is a single consistent
+// point in time — bins, orders and the ledger agree with each other — while
+// Core keeps running; readers never block writers in Postgres.
+//
+// schema_migrations is not exported. The manifest records its head instead,
+// and restore checks the target is at the same version.
+func Export(ctx context.Context, db *sql.DB, w io.Writer, opts ExportOptions) (*Manifest, error) {
+	if opts.Kind == "" {
+		opts.Kind = KindBackup
+	}
+	if opts.Kind != KindBackup && opts.Kind != KindSupport {
+		return nil, fmt.Errorf("unknown export kind %q", opts.Kind)
+	}
+	tmpDir, err := os.MkdirTemp("", "shingocore-backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		Kind:          opts.Kind,
+		StationID:     opts.StationID,
+		CreatedAt:     time.Now().UTC(),
+		AppVersion:    opts.AppVersion,
+		Anonymized:    opts.Anonymize,
+	}
+	var anon *anonymizer
+	if opts.Anonymize {
+		anon = &anonymizer{key: make([]byte, 32)}
+		if _, err := rand.Read(anon.key); err != nil {
+			return nil, fmt.Errorf("anonymization key: %w", err)
+		}
+	}
+	err = withPgxConn(ctx, db, func(conn *pgx.Conn) error {
+		tx, err := conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+		if err != nil {
+			return fmt.Errorf("begin export: %w", err)
+		}
+		defer tx.Rollback(ctx)
+		// The session default caps statements at 30s; a large table's COPY
+		// is one statement.
+		if _, err := tx.Exec(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+			return err
+		}
+		return exportTables(ctx, tx, tmpDir, manifest, anon)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeArchive(w, tmpDir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func exportTables(ctx context.Context, tx pgx.Tx, tmpDir string, m *Manifest, anon *anonymizer) error {
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&m.SchemaVersion); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	tables, err := listTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		entry := ManifestTable{Name: t.name, Partitions: t.partitions}
+		for _, c := range t.columns {
+			entry.Columns = append(entry.Columns, c.name)
+		}
+		if m.Kind == KindSupport && supportExcluded[t.name] {
+			entry.Excluded = true
+			m.Tables = append(m.Tables, entry)
+			continue
+		}
+		entry.File = tablesDir + t.name + ".copy"
+		if err := exportTable(ctx, tx, filepath.Join(tmpDir, t.name+".copy"), t, &entry, anon); err != nil {
+			return err
+		}
+		m.Tables = append(m.Tables, entry)
+	}
+	m.Sequences, err = readSequences(ctx, tx)
+	return err
+}
+
+func exportTable(ctx context.Context, tx pgx.Tx, path string, t tableInfo, entry *ManifestTable, anon *anonymizer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", t.name, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(f, h)}
+	var dst io.Writer = counter
+	var lr *lineRewriter
+	if anon != nil {
+		rules := make([]columnRule, len(t.columns))
+		rewrites := false
+		for i, c := range t.columns {
+			rules[i] = ruleFor(c.name, c.typ)
+			rewrites = rewrites || rules[i] != keepColumn
+		}
+		if rewrites {
+			lr = &lineRewriter{w: counter, a: anon, rules: rules}
+			dst = lr
+		}
+	}
+	tag, err := tx.Conn().PgConn().CopyTo(ctx, dst, fmt.Sprintf(`COPY (SELECT %s FROM %s) TO STDOUT`, columnList(t.columns), quoteIdent(t.name)))
+	if err != nil {
+		return fmt.Errorf("export %s: %w", t.name, err)
+	}
+	if lr != nil {
+		if err := lr.Close(); err != nil {
+			return fmt.Errorf("export %s: %w", t.name, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("export %s: %w", t.name, err)
+	}
+	entry.Rows = tag.RowsAffected()
+	entry.Size = counter.n
+	entry.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+// writeArchive streams the manifest and the table files into a tar.gz.
+func writeArchive(w io.Writer, tmpDir string, m *Manifest) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifestBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0o644, Size: int64(len(manifestBytes)), ModTime: m.CreatedAt}); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	for _, t := range m.Tables {
+		if t.Excluded {
+			continue
+		}
+		if err := addFile(tw, filepath.Join(tmpDir, strings.TrimPrefix(t.File, tablesDir)), t.File, t.Size, m.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+	return nil
+}
+
+func addFile(tw *tar.Writer, path, name string, size int64, modTime time.Time) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", name, err)
+	}
+	defer f.Close()
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: modTime}); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+type tableInfo struct {
+	name       string
+	columns    []columnInfo
+	partitions []Partition
+}
+
+type columnInfo struct {
+	name string
+	typ  string
+}
+
+// listTables returns every top-level table in the public schema except
+// schema_migrations. Partitions are read through their parent and listed
+// under it, so their rows are exported once.
+func listTables(ctx context.Context, q pgxQuerier) ([]tableInfo, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.oid, c.relname
+		  FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p')
+		   AND NOT c.relispartition AND c.relname <> 'schema_migrations'
+		 ORDER BY c.relname`)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	type rel struct {
+		oid  uint32
+		name string
+	}
+	var rels []rel
+	for rows.Next() {
+		var r rel
+		if err := rows.Scan(&r.oid, &r.name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("list tables: %w", err)
+		}
+		rels = append(rels, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	out := make([]tableInfo, 0, len(rels))
+	for _, r := range rels {
+		t := tableInfo{name: r.name}
+		if t.columns, err = listColumns(ctx, q, r.oid); err != nil {
+			return nil, fmt.Errorf("columns of %s: %w", r.name, err)
+		}
+		if t.partitions, err = listPartitions(ctx, q, r.oid); err != nil {
+			return nil, fmt.Errorf("partitions of %s: %w", r.name, err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// listColumns skips generated columns, which COPY FROM cannot load.
+func listColumns(ctx context.Context, q pgxQuerier, oid uint32) ([]columnInfo, error) {
+	rows, err := q.Query(ctx, `
+		SELECT attname, format_type(atttypid, atttypmod)
+		  FROM pg_attribute
+		 WHERE attrelid = $1 AND attnum > 0 AND NOT attisdropped AND attgenerated = ''
+		 ORDER BY attnum`, oid)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (columnInfo, error) {
+		var c columnInfo
+		err := row.Scan(&c.name, &c.typ)
+		return c, err
+	})
+}
+
+func listPartitions(ctx context.Context, q pgxQuerier, oid uint32) ([]Partition, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+		  FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		 WHERE i.inhparent = $1
+		 ORDER BY c.relname`, oid)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Partition, error) {
+		var p Partition
+		err := row.Scan(&p.Name, &p.Bound)
+		return p, err
+	})
+}
+
+func readSequences(ctx context.Context, q pgxQuerier) ([]SequenceValue, error) {
+	rows, err := q.Query(ctx, `SELECT sequencename FROM pg_sequences WHERE schemaname = 'public' ORDER BY sequencename`)
+	if err != nil {
+		return nil, fmt.Errorf("list sequences: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("list sequences: %w", err)
+	}
+	out := make([]SequenceValue, 0, len(names))
+	for _, name := range names {
+		s := SequenceValue{Name: name}
+		if err := q.QueryRow(ctx, `SELECT last_value, is_called FROM `+quoteIdent(name)).Scan(&s.LastValue, &s.IsCalled); err != nil {
+			return nil, fmt.Errorf("read sequence %s: %w", name, err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+type pgxQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// withPgxConn runs fn on one pool connection as a native pgx connection,
+// which is what COPY needs; database/sql has no COPY.
+func withPgxConn(ctx context.Context, db *sql.DB, fn func(*pgx.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Close()
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("backup needs the pgx driver, got %T", driverConn)
+		}
+		return fn(c.Conn())
+	})
+}
+
+func quoteIdent(name string) string {
+	return pgx.Identifier{"public", name}.Sanitize()
+}
+
+func columnList(cols []columnInfo) string {
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = pgx.Identifier{c.name}.Sanitize()
+	}
+	return strings.Join(parts, ", ")
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,274 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrSchemaMismatch is returned when an archive was taken at a different
+// migrations version than the database it is being restored into.
+var ErrSchemaMismatch = errors.New("backup schema version does not match the database")
+
+// RestoreOptions controls what Restore accepts.
+type RestoreOptions struct {
+	// AllowSupport accepts support snapshots. Only the dev-stack load sets
+	// it: a support snapshot has no admin users and may be anonymized, so it
+	// is never something to put back under a plant.
+	AllowSupport bool
+}
+
+// CheckSchemaVersion refuses an archive whose schema version differs from the
+// database's. The data is a COPY of every column at that version, so it only
+// fits that schema: an older archive is missing columns later migrations
+// added (and their backfills), a newer one has columns this Core does not
+// know. Either way the answer is the Core release that matches the archive.
+func CheckSchemaVersion(archive, database int) error {
+	switch {
+	case archive == database:
+		return nil
+	case archive < database:
+		return fmt.Errorf("%w: archive is at v%d, database at v%d — restore it into an empty database with the Core release whose migrations end at v%d, then upgrade Core as usual",
+			ErrSchemaMismatch, archive, database, archive)
+	default:
+		return fmt.Errorf("%w: archive is at v%d, database at v%d — it was taken by a newer Core; upgrade this Core first",
+			ErrSchemaMismatch, archive, database)
+	}
+}
+
+// ReadManifest reads the manifest from the head of an archive without
+// touching the rest of it.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	_, m, err := openArchive(r)
+	return m, err
+}
+
+func openArchive(r io.Reader) (*tar.Reader, *Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read archive: %w", err)
+	}
+	if hdr.Name != ManifestName {
+		return nil, nil, fmt.Errorf("archive does not start with %s", ManifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return nil, nil, fmt.Errorf("unsupported backup format version %d", m.FormatVersion)
+	}
+	return tr, &m, nil
+}
+
+// Restore replaces every row in the Core schema with the archive's, in one
+// transaction: a failure anywhere — a checksum, a constraint, a lock — leaves
+// the database exactly as it was. Core must be stopped; the TRUNCATE takes
+// ACCESS EXCLUSIVE on every table, and a running Core holding any of them
+// fails the restore at the 3s lock timeout rather than blocking the plant.
+//
+// Foreign keys are dropped for the load and re-added after it, which checks
+// every reference in the restored data at once, so the load order of the
+// tables does not matter and cycles (nodes.claimed_by → orders → bins →
+// nodes) need no special handling.
+func Restore(ctx context.Context, db *sql.DB, r io.Reader, opts RestoreOptions) (*Manifest, error) {
+	tr, m, err := openArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if m.Kind == KindSupport && !opts.AllowSupport {
+		return nil, fmt.Errorf("archive is a support snapshot; load it into a dev stack with `corebackup load`, not over a plant database")
+	}
+	var dbVersion int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&dbVersion); err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+	if err := CheckSchemaVersion(m.SchemaVersion, dbVersion); err != nil {
+		return nil, err
+	}
+	err = withPgxConn(ctx, db, func(conn *pgx.Conn) error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("begin restore: %w", err)
+		}
+		defer tx.Rollback(ctx)
+		if _, err := tx.Exec(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+			return err
+		}
+		if err := restoreTables(ctx, tx, tr, m); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "55P03" {
+			return nil, fmt.Errorf("restore: %w (a table is locked — is Core still running? Stop it before restoring)", err)
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func restoreTables(ctx context.Context, tx pgx.Tx, tr *tar.Reader, m *Manifest) error {
+	current, err := listTables(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := checkTables(m, current); err != nil {
+		return err
+	}
+	fks, err := dropForeignKeys(ctx, tx)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(current))
+	for i, t := range current {
+		names[i] = quoteIdent(t.name)
+	}
+	if len(names) > 0 {
+		if _, err := tx.Exec(ctx, `TRUNCATE `+strings.Join(names, ", ")); err != nil {
+			return fmt.Errorf("truncate: %w", err)
+		}
+	}
+	for _, t := range m.Tables {
+		for _, p := range t.Partitions {
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s %s`, quoteIdent(p.Name), quoteIdent(t.Name), p.Bound)); err != nil {
+				return fmt.Errorf("create partition %s: %w", p.Name, err)
+			}
+		}
+	}
+	if err := loadTables(ctx, tx, tr, m); err != nil {
+		return err
+	}
+	for _, s := range m.Sequences {
+		if _, err := tx.Exec(ctx, `SELECT setval($1::regclass, $2, $3)`, quoteIdent(s.Name), s.LastValue, s.IsCalled); err != nil {
+			return fmt.Errorf("restore sequence %s: %w", s.Name, err)
+		}
+	}
+	for _, fk := range fks {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT %s %s`, fk.table, pgx.Identifier{fk.name}.Sanitize(), fk.def)); err != nil {
+			return fmt.Errorf("restored data breaks %s on %s: %w", fk.name, fk.table, err)
+		}
+	}
+	return nil
+}
+
+// checkTables confirms every archived table exists with the archived
+// columns. Matching schema versions should guarantee it; this is what catches
+// a database someone altered by hand.
+func checkTables(m *Manifest, current []tableInfo) error {
+	byName := make(map[string]tableInfo, len(current))
+	for _, t := range current {
+		byName[t.name] = t
+	}
+	for _, t := range m.Tables {
+		cur, ok := byName[t.Name]
+		if !ok {
+			return fmt.Errorf("archive table %s does not exist in the database", t.Name)
+		}
+		for _, col := range t.Columns {
+			if !slices.ContainsFunc(cur.columns, func(c columnInfo) bool { return c.name == col }) {
+				return fmt.Errorf("archive column %s.%s does not exist in the database", t.Name, col)
+			}
+		}
+	}
+	return nil
+}
+
+// loadTables COPYs each table file in, checking its digest and row count
+// against the manifest before the transaction can commit.
+func loadTables(ctx context.Context, tx pgx.Tx, tr *tar.Reader, m *Manifest) error {
+	byFile := make(map[string]ManifestTable, len(m.Tables))
+	for _, t := range m.Tables {
+		if !t.Excluded {
+			byFile[t.File] = t
+		}
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		t, ok := byFile[hdr.Name]
+		if !ok {
+			return fmt.Errorf("archive entry %s is not in the manifest", hdr.Name)
+		}
+		delete(byFile, hdr.Name)
+		cols := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			cols[i] = pgx.Identifier{c}.Sanitize()
+		}
+		h := sha256.New()
+		tag, err := tx.Conn().PgConn().CopyFrom(ctx, io.TeeReader(tr, h),
+			fmt.Sprintf(`COPY %s (%s) FROM STDIN`, quoteIdent(t.Name), strings.Join(cols, ", ")))
+		if err != nil {
+			return fmt.Errorf("load %s: %w", t.Name, err)
+		}
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != t.SHA256 {
+			return fmt.Errorf("load %s: checksum mismatch, archive is corrupt", t.Name)
+		}
+		if tag.RowsAffected() != t.Rows {
+			return fmt.Errorf("load %s: loaded %d rows, manifest lists %d", t.Name, tag.RowsAffected(), t.Rows)
+		}
+	}
+	if len(byFile) > 0 {
+		missing := slices.Sorted(maps.Keys(byFile))
+		return fmt.Errorf("archive is missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+type foreignKey struct {
+	table string // already quoted, as regclass renders it
+	name  string
+	def   string
+}
+
+// dropForeignKeys drops every foreign key in the schema and returns them for
+// re-adding. Keys inherited by partitions go with their parent's.
+func dropForeignKeys(ctx context.Context, tx pgx.Tx) ([]foreignKey, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.conrelid::regclass::text, c.conname, pg_get_constraintdef(c.oid)
+		  FROM pg_constraint c JOIN pg_namespace n ON n.oid = c.connamespace
+		 WHERE c.contype = 'f' AND n.nspname = 'public' AND c.conparentid = 0
+		 ORDER BY 1, 2`)
+	if err != nil {
+		return nil, fmt.Errorf("list foreign keys: %w", err)
+	}
+	fks, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (foreignKey, error) {
+		var fk foreignKey
+		err := row.Scan(&fk.table, &fk.name, &fk.def)
+		return fk, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list foreign keys: %w", err)
+	}
+	for _, fk := range fks {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT %s`, fk.table, pgx.Identifier{fk.name}.Sanitize())); err != nil {
+			return nil, fmt.Errorf("drop %s: %w", fk.name, err)
+		}
+	}
+	return fks, nil
+}
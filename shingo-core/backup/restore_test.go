@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+)
+
+func TestCheckSchemaVersion(t *testing.T) {
+	t.Parallel()
+	testutil.MustNoErr(t, CheckSchemaVersion(100, 100), "same version")
+
+	err := CheckSchemaVersion(98, 100)
+	if !errors.Is(err, ErrSchemaMismatch) || !strings.Contains(err.Error(), "migrations end at v98") {
+		t.Fatalf("older archive: %v", err)
+	}
+	err = CheckSchemaVersion(101, 100)
+	if !errors.Is(err, ErrSchemaMismatch) || !strings.Contains(err.Error(), "upgrade this Core first") {
+		t.Fatalf("newer archive: %v", err)
+	}
+}
+
+// testArchive builds an archive from in-memory table files, as Export would
+// lay it out.
+func testArchive(t *testing.T, kind string, files map[string]string) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	m := &Manifest{FormatVersion: FormatVersion, Kind: kind, CreatedAt: time.Now().UTC(), SchemaVersion: 100}
+	for name, body := range files {
+		testutil.MustNoErr(t, os.WriteFile(filepath.Join(dir, name+".copy"), []byte(body), 0o644), "write table")
+		m.Tables = append(m.Tables, ManifestTable{Name: name, File: tablesDir + name + ".copy", Size: int64(len(body))})
+	}
+	m.Tables = append(m.Tables, ManifestTable{Name: "admin_users", Excluded: true})
+	var buf bytes.Buffer
+	testutil.MustNoErr(t, writeArchive(&buf, dir, m), "write archive")
+	return buf.Bytes()
+}
+
+func TestReadManifest(t *testing.T) {
+	t.Parallel()
+	data := testArchive(t, KindSupport, map[string]string{"bins": "1\tB-1\n"})
+	m, err := ReadManifest(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Kind != KindSupport || m.SchemaVersion != 100 || len(m.Tables) != 2 {
+		t.Fatalf("manifest = %+v", m)
+	}
+	if _, err := ReadManifest(strings.NewReader("not an archive")); err == nil {
+		t.Fatal("garbage accepted as an archive")
+	}
+}
+
+// TestRestoreRefusesSupportSnapshot checks the refusal comes before any
+// database access: a support snapshot never reaches a plant database.
+func TestRestoreRefusesSupportSnapshot(t *testing.T) {
+	t.Parallel()
+	data := testArchive(t, KindSupport, map[string]string{"bins": ""})
+	_, err := Restore(context.Background(), nil, bytes.NewReader(data), RestoreOptions{})
+	if err == nil || !strings.Contains(err.Error(), "support snapshot") {
+		t.Fatalf("support snapshot restore: %v", err)
+	}
+}
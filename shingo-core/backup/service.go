@@ -0,0 +1,363 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"shingocore/config"
+	"shingocore/store"
+)
+
+// Service runs the scheduled exports. It mirrors the edge's backup service
+// without the change-triggered runs: Core's data changes continuously, so the
+// schedule is the only cadence that means anything.
+type Service struct {
+	db         *store.DB
+	cfg        *config.Config
+	appVersion string
+	logf       func(string, ...any)
+
+	mu             sync.RWMutex
+	status         Status
+	stopCh         chan struct{}
+	wg             sync.WaitGroup
+	storageFactory func(config.BackupConfig) (Storage, error)
+	runFlag        atomic.Bool
+}
+
+func NewService(db *store.DB, cfg *config.Config, appVersion string, logf func(string, ...any)) *Service {
+	if logf == nil {
+		logf = log.Printf
+	}
+	svc := &Service{
+		db:             db,
+		cfg:            cfg,
+		appVersion:     appVersion,
+		logf:           logf,
+		stopCh:         make(chan struct{}),
+		storageFactory: NewStorage,
+	}
+	svc.refreshStaticStatus()
+	return svc
+}
+
+func (s *Service) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+func (s *Service) Stop() {
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+	s.wg.Wait()
+}
+
+func (s *Service) Status() Status {
+	s.refreshStaticStatus()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+func (s *Service) RunNow(ctx context.Context, reason string) error {
+	return s.runBackup(ctx, reason)
+}
+
+func (s *Service) loop() {
+	defer s.wg.Done()
+	s.seedLastSuccess()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			if !s.shouldRunScheduled() {
+				continue
+			}
+			if err := s.runBackup(context.Background(), "scheduled"); err != nil {
+				s.logf("backup: scheduled run failed: %v", err)
+			}
+		}
+	}
+}
+
+// seedLastSuccess takes the newest stored export as the last success, so a
+// Core restart does not mean an immediate full export.
+func (s *Service) seedLastSuccess() {
+	s.cfg.Lock()
+	backupCfg := s.cfg.Backup
+	stationID := s.cfg.Messaging.StationID
+	s.cfg.Unlock()
+	if !backupCfg.Enabled {
+		return
+	}
+	storage, err := s.storageFactory(backupCfg)
+	if err != nil {
+		s.logf("backup: %v", err)
+		return
+	}
+	items, err := listBackupsForStation(context.Background(), storage, stationID)
+	if err != nil {
+		s.logf("backup: list existing exports: %v", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+	ts := snapshotTime(items[0])
+	s.mu.Lock()
+	s.status.LastSuccessAt = &ts
+	s.status.LastSuccessKey = items[0].Key
+	s.mu.Unlock()
+}
+
+func (s *Service) shouldRunScheduled() bool {
+	s.cfg.Lock()
+	enabled := s.cfg.Backup.Enabled
+	interval := s.cfg.Backup.ScheduleInterval
+	s.cfg.Unlock()
+	s.refreshStaticStatus()
+	if !enabled {
+		return false
+	}
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.status.Running {
+		return false
+	}
+	if s.status.LastSuccessAt == nil {
+		return true
+	}
+	return time.Since(*s.status.LastSuccessAt) >= interval
+}
+
+func (s *Service) runBackup(ctx context.Context, reason string) error {
+	if !s.runFlag.CompareAndSwap(false, true) {
+		return fmt.Errorf("backup already running")
+	}
+	defer s.runFlag.Store(false)
+
+	s.cfg.Lock()
+	backupCfg := s.cfg.Backup
+	stationID := s.cfg.Messaging.StationID
+	s.cfg.Unlock()
+	storage, err := s.storageFactory(backupCfg)
+	if err != nil {
+		s.markFailure(err)
+		return err
+	}
+	s.markRunning(true, reason)
+	defer s.markRunning(false, "")
+
+	key, err := exportTo(ctx, s.db, storage, ExportOptions{StationID: stationID, AppVersion: s.appVersion})
+	if err != nil {
+		s.markFailure(err)
+		return err
+	}
+	if err := prune(ctx, storage, backupCfg, stationID, s.logf); err != nil {
+		s.logf("backup: prune failed after upload: %v", err)
+	}
+	s.markSuccess(key)
+	s.logf("backup: uploaded %s", key)
+	return nil
+}
+
+// exportTo exports through a temp file, since storage wants the size up
+// front, and uploads it under the archive's key.
+func exportTo(ctx context.Context, db *store.DB, storage Storage, opts ExportOptions) (string, error) {
+	f, err := os.CreateTemp("", "shingocore-backup-*.tar.gz")
+	if err != nil {
+		return "", fmt.Errorf("create temp archive: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	manifest, err := Export(ctx, db.DB, f, opts)
+	if err != nil {
+		return "", err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", fmt.Errorf("archive size: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("rewind archive: %w", err)
+	}
+	key := archiveKey(opts.StationID, manifest.CreatedAt)
+	if err := storage.Put(ctx, key, f, size, map[string]string{
+		"station-id":     opts.StationID,
+		"format-version": "1",
+		"schema-version": itoa(manifest.SchemaVersion),
+		"created-at":     manifest.CreatedAt.Format(time.RFC3339),
+	}); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func prune(ctx context.Context, storage Storage, backupCfg config.BackupConfig, stationID string, logf func(string, ...any)) error {
+	items, err := listBackupsForStation(ctx, storage, stationID)
+	if err != nil {
+		return err
+	}
+	keep := retainedKeys(items, backupCfg.KeepHourly, backupCfg.KeepDaily, backupCfg.KeepWeekly, backupCfg.KeepMonthly)
+	for _, item := range items {
+		if _, ok := keep[item.Key]; ok {
+			continue
+		}
+		if err := storage.Delete(ctx, item.Key); err != nil {
+			logf("backup: prune delete failed for %s: %v", item.Key, err)
+		}
+	}
+	return nil
+}
+
+// ListBackups lists stored exports, newest first.
+func ListBackups(ctx context.Context, backupCfg config.BackupConfig, stationID string) ([]SnapshotInfo, error) {
+	storage, err := NewStorage(backupCfg)
+	if err != nil {
+		return nil, err
+	}
+	return listBackupsForStation(ctx, storage, stationID)
+}
+
+// Upload exports the database straight to the configured storage and
+// applies retention, as one scheduled run would.
+func Upload(ctx context.Context, db *store.DB, backupCfg config.BackupConfig, opts ExportOptions) (string, error) {
+	storage, err := NewStorage(backupCfg)
+	if err != nil {
+		return "", err
+	}
+	key, err := exportTo(ctx, db, storage, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := prune(ctx, storage, backupCfg, opts.StationID, log.Printf); err != nil {
+		log.Printf("backup: prune failed after upload: %v", err)
+	}
+	return key, nil
+}
+
+// Fetch opens a stored export for reading.
+func Fetch(ctx context.Context, backupCfg config.BackupConfig, key string) (io.ReadCloser, error) {
+	storage, err := NewStorage(backupCfg)
+	if err != nil {
+		return nil, err
+	}
+	return storage.Get(ctx, key)
+}
+
+func (s *Service) refreshStaticStatus() {
+	s.cfg.Lock()
+	enabled := s.cfg.Backup.Enabled
+	interval := s.cfg.Backup.ScheduleInterval
+	s.cfg.Unlock()
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	next := time.Now().UTC().Add(interval)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Enabled = enabled
+	s.status.ScheduleInterval = interval.String()
+	s.status.Stale, s.status.StaleReason = false, ""
+	if s.status.LastSuccessAt != nil {
+		next = s.status.LastSuccessAt.Add(interval)
+		if enabled && time.Since(*s.status.LastSuccessAt) > interval*2 {
+			s.status.Stale = true
+			s.status.StaleReason = "last successful backup is older than twice the configured interval"
+		}
+	} else if enabled {
+		s.status.Stale = true
+		s.status.StaleReason = "no successful backup has been recorded yet"
+	}
+	s.status.NextScheduledAt = &next
+}
+
+func (s *Service) markRunning(running bool, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Running = running
+	if running {
+		s.status.LastRunReason = reason
+		s.status.LastError = ""
+	}
+}
+
+func (s *Service) markSuccess(key string) {
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastSuccessAt = &now
+	s.status.LastSuccessKey = key
+	s.status.LastError = ""
+}
+
+func (s *Service) markFailure(err error) {
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastFailureAt = &now
+	s.status.LastError = err.Error()
+}
+
+func listBackupsForStation(ctx context.Context, storage Storage, stationID string) ([]SnapshotInfo, error) {
+	items, err := storage.List(ctx, objectPrefix(stationID))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SnapshotInfo, 0, len(items))
+	for _, item := range items {
+		if !strings.HasSuffix(item.Key, ".tar.gz") {
+			continue
+		}
+		snap := SnapshotInfo{Key: item.Key, Size: item.Size, LastModified: item.LastModified}
+		if ts, ok := inferSnapshotTime(item.Key); ok {
+			snap.CreatedAt = &ts
+		}
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return snapshotTime(out[i]).After(snapshotTime(out[j]))
+	})
+	return out, nil
+}
+
+func archiveKey(stationID string, ts time.Time) string {
+	ts = ts.UTC()
+	return objectPrefix(stationID) + ts.Format("2006/01/02/") + ts.Format("2006-01-02T15-04-05Z") + ".tar.gz"
+}
+
+func objectPrefix(stationID string) string {
+	v := strings.TrimSpace(stationID)
+	v = strings.ReplaceAll(v, "\\", "_")
+	v = strings.ReplaceAll(v, "/", "_")
+	if v == "" {
+		v = "core"
+	}
+	return v + "/"
+}
+
+func inferSnapshotTime(key string) (time.Time, bool) {
+	base := key[strings.LastIndex(key, "/")+1:]
+	ts, err := time.Parse("2006-01-02T15-04-05Z", strings.TrimSuffix(base, ".tar.gz"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
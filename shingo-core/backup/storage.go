@@ -0,0 +1,27 @@
+package backup
+
+import (
+	"strings"
+
+	"shingo/shared/backupstore"
+	"shingocore/config"
+)
+
+// Storage and its directory and S3 backends are shared with the Edge's
+// backups (shared/backupstore); this package adapts Core's config to them.
+type Storage = backupstore.Storage
+
+type ObjectInfo = backupstore.ObjectInfo
+
+// NewStorage builds the backend cfg.Storage names. Empty is filesystem.
+func NewStorage(cfg config.BackupConfig) (Storage, error) {
+	kind := strings.TrimSpace(cfg.Storage)
+	if kind == "" {
+		kind = config.BackupStorageFilesystem
+	}
+	return backupstore.New(backupstore.Config{
+		Kind:       kind,
+		Filesystem: backupstore.FilesystemConfig(cfg.Filesystem),
+		S3:         backupstore.S3Config(cfg.S3),
+	})
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingo/shared/backupstore"
+	"shingocore/config"
+)
+
+func TestFilesystemStorage(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	storage, err := NewStorage(config.BackupConfig{Storage: config.BackupStorageFilesystem, Filesystem: config.BackupFilesystemConfig{Path: root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	testutil.MustNoErr(t, storage.Test(ctx, objectPrefix("core")), "test")
+
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var keys []string
+	for i := 0; i < 3; i++ {
+		key := archiveKey("core", base.Add(-time.Duration(i)*time.Hour))
+		body := "archive-" + key
+		testutil.MustNoErr(t, storage.Put(ctx, key, strings.NewReader(body), int64(len(body)), nil), "put")
+		keys = append(keys, key)
+	}
+	// An interrupted upload is never offered.
+	testutil.MustNoErr(t, os.WriteFile(filepath.Join(root, filepath.FromSlash(keys[0]))+backupstore.PartialSuffix, []byte("half"), 0o644), "partial")
+
+	snaps, err := listBackupsForStation(ctx, storage, "core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 || snaps[0].Key != keys[0] || snaps[0].CreatedAt == nil || !snaps[0].CreatedAt.Equal(base) {
+		t.Fatalf("list = %+v", snaps)
+	}
+
+	// Retention reads the time in the key, so the newest is kept.
+	testutil.MustNoErr(t, prune(ctx, storage, config.BackupConfig{KeepHourly: 1}, "core", t.Logf), "prune")
+	items, err := storage.List(ctx, objectPrefix("core"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(items))
+	for _, it := range items {
+		got = append(got, it.Key)
+	}
+	sort.Strings(got)
+	if len(got) != 1 || got[0] != keys[0] {
+		t.Fatalf("after prune: %v, want [%s]", got, keys[0])
+	}
+
+	rc, err := storage.Get(ctx, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(body) != "archive-"+keys[0] {
+		t.Fatalf("get = %q, %v", body, err)
+	}
+	if _, err := storage.Get(ctx, "../escape"); err == nil {
+		t.Fatal("key outside the root accepted")
+	}
+}
+
+func TestNewStorageDefaultsToFilesystem(t *testing.T) {
+	t.Parallel()
+	if _, err := NewStorage(config.BackupConfig{}); err == nil || !strings.Contains(err.Error(), "directory") {
+		t.Fatalf("empty storage should build filesystem and ask for a directory, got %v", err)
+	}
+	if _, err := NewStorage(config.BackupConfig{Storage: "tape"}); err == nil {
+		t.Fatal("unknown storage accepted")
+	}
+}
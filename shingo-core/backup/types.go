@@ -0,0 +1,82 @@
+package backup
+
+import "time"
+
+const (
+	FormatVersion = 1
+	ManifestName  = "manifest.json"
+	tablesDir     = "tables/"
+)
+
+// Archive kinds. A backup is the whole schema and restores over a plant's
+// database; a support snapshot leaves out credentials, may be anonymized, and
+// only loads into a dev stack.
+const (
+	KindBackup  = "backup"
+	KindSupport = "support"
+)
+
+// Manifest is the first entry of every archive. SchemaVersion is the
+// schema_migrations head of the database it was taken from: the data only
+// fits a schema at exactly that version.
+type Manifest struct {
+	FormatVersion int             `json:"format_version"`
+	Kind          string          `json:"kind"`
+	StationID     string          `json:"station_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	AppVersion    string          `json:"app_version"`
+	SchemaVersion int             `json:"schema_version"`
+	Anonymized    bool            `json:"anonymized,omitempty"`
+	Tables        []ManifestTable `json:"tables"`
+	Sequences     []SequenceValue `json:"sequences"`
+}
+
+// ManifestTable is one table's data file. Excluded tables are listed with no
+// file so a restore empties them rather than leaving stale rows behind.
+type ManifestTable struct {
+	Name       string      `json:"name"`
+	Columns    []string    `json:"columns"`
+	File       string      `json:"file,omitempty"`
+	Rows       int64       `json:"rows"`
+	Size       int64       `json:"size"`
+	SHA256     string      `json:"sha256,omitempty"`
+	Excluded   bool        `json:"excluded,omitempty"`
+	Partitions []Partition `json:"partitions,omitempty"`
+}
+
+// Partition is one child of a partitioned table. The partition-maintenance
+// loops only create partitions going forward, so restore recreates the ones
+// the archive's rows land in.
+type Partition struct {
+	Name  string `json:"name"`
+	Bound string `json:"bound"` // pg_get_expr(relpartbound), e.g. FOR VALUES FROM (...) TO (...)
+}
+
+// SequenceValue is a sequence's position, restored exactly so IDs handed out
+// after a restore never collide with ones an edge already holds.
+type SequenceValue struct {
+	Name      string `json:"name"`
+	LastValue int64  `json:"last_value"`
+	IsCalled  bool   `json:"is_called"`
+}
+
+type SnapshotInfo struct {
+	Key          string     `json:"key"`
+	Size         int64      `json:"size"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+type Status struct {
+	Enabled          bool       `json:"enabled"`
+	ScheduleInterval string     `json:"schedule_interval,omitempty"`
+	Running          bool       `json:"running"`
+	LastRunReason    string     `json:"last_run_reason,omitempty"`
+	LastSuccessAt    *time.Time `json:"last_success_at,omitempty"`
+	LastSuccessKey   string     `json:"last_success_key,omitempty"`
+	LastFailureAt    *time.Time `json:"last_failure_at,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	NextScheduledAt  *time.Time `json:"next_scheduled_at,omitempty"`
+	Stale            bool       `json:"stale"`
+	StaleReason      string     `json:"stale_reason,omitempty"`
+}
@@ -0,0 +1,217 @@
+// backup_cmd.go — the `shingocore backup` subcommands.
+//
+// They live in the Core binary rather than a tool of their own so a plant has
+// them wherever Core is installed, and so a restore is always checked against
+// the migrations of the binary that will run on the restored database.
+//
+//   export   write an archive to a file, or upload one to backup storage
+//   list     list archives in backup storage
+//   restore  replace the database with an archive (Core stopped)
+//   load     load an archive, support snapshots included, into a dev stack
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"shingocore/backup"
+	"shingocore/config"
+	"shingocore/store"
+)
+
+func printBackupUsage() {
+	fmt.Println("Usage: shingocore backup <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  export   [--out FILE] [--support [--anonymize]]")
+	fmt.Println("           without --out, upload to backup storage and apply retention")
+	fmt.Println("  list     list archives in backup storage")
+	fmt.Println("  restore  --file FILE | --key KEY [--yes]")
+	fmt.Println("           replace every row in the database; stop Core first")
+	fmt.Println("  load     --file FILE [--yes]")
+	fmt.Println("           restore into a dev stack; accepts support snapshots and")
+	fmt.Println("           requires sim.enabled=true and SHINGO_ALLOW_SIM=1")
+	fmt.Println()
+	fmt.Println("Every command takes --config PATH (default: shingocore.yaml).")
+}
+
+// runBackupCommand runs one subcommand and returns the exit status.
+func runBackupCommand(args []string) int {
+	log.SetPrefix("[backup] ")
+	log.SetFlags(0)
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" {
+		printBackupUsage()
+		return 0
+	}
+	fs := flag.NewFlagSet("backup "+args[0], flag.ContinueOnError)
+	configPath := fs.String("config", "shingocore.yaml", "path to config file")
+	out := fs.String("out", "", "export: write the archive to FILE instead of backup storage")
+	support := fs.Bool("support", false, "export: support snapshot (no admin users)")
+	anonymize := fs.Bool("anonymize", false, "export: pseudonymize people and hostnames, blank notes")
+	file := fs.String("file", "", "restore/load: archive file")
+	key := fs.String("key", "", "restore: archive key in backup storage")
+	yes := fs.Bool("yes", false, "restore/load: skip the typed confirmation")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Printf("load config: %v", err)
+		return 1
+	}
+	ctx := context.Background()
+	switch args[0] {
+	case "export":
+		err = backupExport(ctx, cfg, *out, *support, *anonymize)
+	case "list":
+		err = backupList(ctx, cfg)
+	case "restore":
+		err = backupRestore(ctx, cfg, *file, *key, *yes)
+	case "load":
+		err = backupLoad(ctx, cfg, *file, *yes)
+	default:
+		printBackupUsage()
+		return 2
+	}
+	if err != nil {
+		log.Printf("%s: %v", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func backupExport(ctx context.Context, cfg *config.Config, out string, support, anonymize bool) error {
+	opts := backup.ExportOptions{Kind: backup.KindBackup, StationID: cfg.Messaging.StationID, AppVersion: Version}
+	if support {
+		opts.Kind = backup.KindSupport
+		opts.Anonymize = anonymize
+	} else if anonymize {
+		return fmt.Errorf("--anonymize applies to support snapshots; add --support")
+	}
+	if support && out == "" {
+		return fmt.Errorf("a support snapshot is written to a file; pass --out")
+	}
+	db, err := store.Open(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+	if out == "" {
+		key, err := backup.Upload(ctx, db, cfg.Backup, opts)
+		if err != nil {
+			return err
+		}
+		log.Printf("uploaded %s", key)
+		return nil
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	m, err := backup.Export(ctx, db.DB, f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out)
+		return err
+	}
+	log.Printf("wrote %s: %s, schema v%d, %d tables%s", out, m.Kind, m.SchemaVersion, len(m.Tables), anonymizedNote(m))
+	return nil
+}
+
+func anonymizedNote(m *backup.Manifest) string {
+	if m.Anonymized {
+		return ", anonymized"
+	}
+	return ""
+}
+
+func backupList(ctx context.Context, cfg *config.Config) error {
+	items, err := backup.ListBackups(ctx, cfg.Backup, cfg.Messaging.StationID)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("no archives")
+		return nil
+	}
+	for _, item := range items {
+		fmt.Printf("%s  %10d\n", item.Key, item.Size)
+	}
+	return nil
+}
+
+func backupRestore(ctx context.Context, cfg *config.Config, file, key string, yes bool) error {
+	if (file == "") == (key == "") {
+		return fmt.Errorf("pass exactly one of --file or --key")
+	}
+	src, err := openArchiveSource(ctx, cfg, file, key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return restoreInto(ctx, cfg, src, backup.RestoreOptions{}, yes)
+}
+
+// backupLoad is restore for a dev stack, gated the way seeddev --wipe is.
+func backupLoad(ctx context.Context, cfg *config.Config, file string, yes bool) error {
+	if !cfg.Sim.Enabled || os.Getenv("SHINGO_ALLOW_SIM") != "1" {
+		return fmt.Errorf("refused: requires sim.enabled=true in the config AND SHINGO_ALLOW_SIM=1")
+	}
+	if file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := restoreInto(ctx, cfg, src, backup.RestoreOptions{AllowSupport: true}, yes); err != nil {
+		return err
+	}
+	log.Printf("start Core to pick it up; a support snapshot has no admin users, so Core recreates the default admin")
+	return nil
+}
+
+func openArchiveSource(ctx context.Context, cfg *config.Config, file, key string) (io.ReadCloser, error) {
+	if file != "" {
+		return os.Open(file)
+	}
+	return backup.Fetch(ctx, cfg.Backup, key)
+}
+
+// restoreInto opens the database — which migrates it to this binary's head,
+// so an empty database gets the schema the archive is checked against — and
+// replaces its contents after a typed confirmation.
+func restoreInto(ctx context.Context, cfg *config.Config, src io.Reader, opts backup.RestoreOptions, yes bool) error {
+	db, err := store.Open(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+	pg := cfg.Database.Postgres
+	if !yes && !confirmTyped(fmt.Sprintf("This replaces ALL data in %s on %s. Stop Core first. Type 'yes' to confirm: ", pg.Database, pg.Host)) {
+		return fmt.Errorf("aborted")
+	}
+	m, err := backup.Restore(ctx, db.DB, src, opts)
+	if err != nil {
+		return err
+	}
+	log.Printf("restored %s from %s taken %s by %s (schema v%d)",
+		m.Kind, m.StationID, m.CreatedAt.Format("2006-01-02 15:04:05Z07:00"), m.AppVersion, m.SchemaVersion)
+	return nil
+}
+
+func confirmTyped(prompt string) bool {
+	fmt.Fprint(os.Stderr, prompt)
+	var answer string
+	_, _ = fmt.Scanln(&answer)
+	return strings.TrimSpace(answer) == "yes"
+}
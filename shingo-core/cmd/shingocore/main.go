@@ -20,10 +20,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -33,6 +35,8 @@ import (
 
 	"shingo/protocol"
 	"shingo/protocol/debuglog"
+	"shingo/protocol/webtls"
+	"shingocore/backup"
 	"shingocore/config"
 	"shingocore/dispatch"
 	"shingocore/engine"
@@ -64,8 +68,11 @@ type coreFlags struct {
 }
 
 // parseFlags handles the custom --log-debug stripping and standard flag parsing.
-// Exits on --help or --version.
+// Exits on --help or --version, and after running a `backup` subcommand.
 func parseFlags() coreFlags {
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		os.Exit(runBackupCommand(os.Args[2:]))
+	}
 	filteredArgs, fileFilter := debuglog.ParseDebugFlag(os.Args[1:])
 	os.Args = append(os.Args[:1], filteredArgs...)
 
@@ -89,6 +96,7 @@ func parseFlags() coreFlags {
 
 func printUsage() {
 	fmt.Println("Usage: shingocore [options]")
+	fmt.Println("       shingocore backup <export|list|restore|load> [options]")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  --config PATH         config file path (default: shingocore.yaml)")
@@ -214,21 +222,82 @@ func auditLaneGeometry(db *store.DB) {
 	}
 }
 
-func startHTTPServer(addr string, handler http.Handler) *http.Server {
+// prepareWebTLS loads or generates the web certificate. Nil means plain
+// HTTP. A configured but unusable certificate is fatal: falling back to HTTP
+// would silently undo what the plant turned TLS on for.
+func prepareWebTLS(t config.WebTLSConfig) *webtls.Certificate {
+	if !t.Enabled {
+		return nil
+	}
+	cert, err := webtls.Prepare(webtls.Options{
+		CertFile:  t.CertFile,
+		KeyFile:   t.KeyFile,
+		Generate:  t.SelfSigned,
+		Dir:       t.Dir,
+		CAFile:    t.CAFile,
+		CAKeyFile: t.CAKeyFile,
+		Hosts:     t.Hosts,
+	})
+	if err != nil {
+		log.Fatalf("shingocore: %v", err)
+	}
+	if cert.Generated {
+		log.Printf("shingocore: issued web certificate signed by plant CA %s — browsers and edges must trust that file", cert.CAFile)
+	}
+	log.Printf("shingocore: web certificate valid until %s", cert.NotAfter.Format(time.DateOnly))
+	www.SetWebCertificate(cert.NotAfter)
+	return cert
+}
+
+// startHTTPServer serves handler on addr, over TLS when cert is non-nil.
+// A non-zero redirectPort adds a plain-HTTP listener that only redirects to
+// HTTPS; it closes when srv shuts down.
+func startHTTPServer(addr string, handler http.Handler, cert *webtls.Certificate, redirectPort int) *http.Server {
 	srv := &http.Server{
 		Addr:        addr,
 		Handler:     handler,
 		IdleTimeout: 120 * time.Second,
 	}
+	if cert != nil {
+		srv.TLSConfig = cert.TLSConfig
+	}
 	go func() {
-		log.Printf("shingocore: web server listening on %s", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if cert != nil {
+			log.Printf("shingocore: web server listening on %s (https)", addr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("shingocore: web server listening on %s", addr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("web server: %v", err)
 		}
 	}()
+	if cert != nil && redirectPort > 0 {
+		startRedirectServer(srv, redirectPort)
+	}
 	return srv
 }
 
+func startRedirectServer(srv *http.Server, redirectPort int) {
+	_, httpsPort, _ := net.SplitHostPort(srv.Addr)
+	port, _ := strconv.Atoi(httpsPort)
+	host, _, _ := net.SplitHostPort(srv.Addr)
+	redirect := &http.Server{
+		Addr:              net.JoinHostPort(host, strconv.Itoa(redirectPort)),
+		Handler:           webtls.RedirectHandler(port),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv.RegisterOnShutdown(func() { redirect.Close() })
+	go func() {
+		log.Printf("shingocore: redirecting http on %s to https", redirect.Addr)
+		if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("shingocore: http redirect: %v", err)
+		}
+	}()
+}
+
 func awaitShutdown(srv *http.Server, stopWeb func()) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -410,6 +479,12 @@ func main() {
 			Station: station, ProcessID: processID, StyleID: styleID, RecordedAt: recordedAt,
 		}})
 	})
+	// Persisted lineside reports redraw the lineside wall displays.
+	coreDataService.SetLinesideEmitter(func(station string, payloads []string) {
+		eng.Events.Emit(engine.Event{Type: engine.EventLinesideReported, Payload: engine.LinesideReportedEvent{
+			Station: station, Payloads: payloads,
+		}})
+	})
 	// Launch the async cell_part_events projection worker + partition manager
 	// (plan §12). Must follow registration; the handler only enqueues.
 	coreDataService.StartHeartbeatProjection()
@@ -503,6 +578,16 @@ func main() {
 		}
 	}()
 
+	// ── Core backups ───────────────────────────────────────────────────
+	// Scheduled logical exports to backup storage. Not fatal when storage is
+	// unreachable: the run fails, is logged, and retries on the next tick.
+	if cfg.Backup.Enabled {
+		backupSvc := backup.NewService(db, cfg, Version, log.Printf)
+		backupSvc.Start()
+		defer backupSvc.Stop()
+		log.Printf("shingocore: backups every %s to %s storage", cfg.Backup.ScheduleInterval, cfg.Backup.Storage)
+	}
+
 	// ── Robot localization confidence ──────────────────────────────────
 	// Collection itself rides the engine's existing 2-second robot poll (see
 	// engine_robot_confidence.go). What lives here is the housekeeping the
@@ -608,7 +693,7 @@ func main() {
 		log.Fatalf("shingocore: build router: %v", err)
 	}
 	addr := fmt.Sprintf("%s:%d", cfg.Web.Host, cfg.Web.Port)
-	srv := startHTTPServer(addr, handler)
+	srv := startHTTPServer(addr, handler, prepareWebTLS(cfg.Web.TLS), cfg.Web.TLS.RedirectPort)
 
 	// ── Ready — wait for shutdown signal ────────────────────────────────
 	log.Printf("shingocore: ready")
@@ -31,9 +31,17 @@ type Config struct {
 	Logging       LoggingConfig       `yaml:"logging"`
 	Dispatch      DispatchConfig      `yaml:"dispatch"`
 	Demand        DemandConfig        `yaml:"demand"`
+	Quality       QualityConfig       `yaml:"quality"`
+	Alerts        AlertsConfig        `yaml:"alerts"`
+	Reports       ReportsConfig       `yaml:"reports"`
+	Backup        BackupConfig        `yaml:"backup"`
+
+	AnalyticsExport AnalyticsExportConfig `yaml:"analytics_export"`
 
 	RobotConfidence RobotConfidenceConfig `yaml:"robot_confidence"`
 
+	RobotMaintenance RobotMaintenanceConfig `yaml:"robot_maintenance"`
+
 	// Display holds the Phase 6 surfaces' numeric constants. Read it through
 	// DisplayConstants(), not directly — see provenance.go, which also carries
 	// the record of where each of these numbers came from and which of them a
@@ -93,6 +101,13 @@ type DemandConfig struct {
 // DispatchConfig tunes planner-side safety nets.
 type DispatchConfig struct {
 	Futility FutilityConfig `yaml:"futility"`
+	// EmptyAffinityGrace is how long an empty standing under a node with
+	// empty_affinity=own stays hidden from asks delivering elsewhere, counted
+	// from when it became an empty there. Long enough for the owning line's
+	// operator to ask for it; short enough that a line that never does cannot
+	// hoard carriers the plant is short of. 0 keeps the ranking (owners first)
+	// and hides nothing.
+	EmptyAffinityGrace time.Duration `yaml:"empty_affinity_grace"`
 }
 
 // FutilityConfig tunes the rate-per-tuple futility detector — the net for the
@@ -199,6 +214,241 @@ type ReplenishmentConfig struct {
 	LinesideDecisionMode string `yaml:"lineside_decision_mode"`
 }
 
+// QualityConfig governs quality holds — the workflow that replaced flipping a
+// bin to quality_hold by hand.
+//
+// A hold is a standing rule ("every bin of lot L-2207") rather than a status on
+// one bin, so bins that match it AFTER it was placed — a late arrival, a
+// manifest corrected to the held lot — have to be caught too. SweepInterval is
+// how long such a bin can stay sourceable before the sweep holds it. Latency
+// only: dispatch consults active holds directly, so a matching bin is never
+// picked while the sweep has yet to reach it.
+type QualityConfig struct {
+	// ReasonCodes is the closed list a hold's reason must come from. Free
+	// text travels in the hold's note; the code is what a report groups by.
+	ReasonCodes []string `yaml:"reason_codes"`
+
+	// Approvers are the usernames allowed to approve a disposition (release,
+	// rework, scrap). EMPTY MEANS EVERY SIGNED-IN USER, which is what a plant
+	// that has not named a quality team gets. Either way the approver must be
+	// someone other than the person who requested the disposition.
+	Approvers []string `yaml:"approvers"`
+
+	// SweepInterval is the propagation sweep cadence. Default 1m.
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+}
+
+// DefaultQualityReasonCodes is the shipped reason list. "other" is last and
+// always present so an inspector is never stuck choosing a wrong code.
+func DefaultQualityReasonCodes() []string {
+	return []string{"dimensional", "contamination", "supplier", "mislabel", "other"}
+}
+
+// AlertsConfig is the alert rules engine: conditions Core checks on a timer
+// (an order stuck in one status, an edge gone quiet, a lineside level run low,
+// dead letters in the outbox, the fleet or a robot's localization lost) and
+// engine events it raises on, each with a severity.
+//
+// An alert is raised once per subject and stays one row while the condition
+// holds — a stuck order is one alert, not one per evaluation — and resolves on
+// its own when the condition clears. Nobody acknowledging it inside a rule's
+// EscalateAfter raises its severity and notifies again. Delivery rides the
+// notification channels (notifications.channels), routed by the event types
+// alert_raised and alert_escalated.
+type AlertsConfig struct {
+	// Enabled false stops evaluation; alert history stays readable.
+	Enabled bool `yaml:"enabled"`
+	// EvalInterval is the periodic-check cadence. Default 30s.
+	EvalInterval time.Duration `yaml:"eval_interval"`
+	// QuietHours holds back notification of anything below MinSeverity.
+	// Alerts are still raised and shown; only the notification waits, and it
+	// goes out when quiet hours end if the alert is still open.
+	QuietHours AlertQuietHours `yaml:"quiet_hours"`
+	// Rules replaces the shipped list (DefaultAlertRules) when set at all.
+	Rules []AlertRule `yaml:"rules"`
+}
+
+// AlertQuietHours is a daily window in the plant's time (timezone:). Start after
+// End wraps midnight ("22:00"–"06:00"); Start equal to End, or either empty,
+// is no quiet hours.
+type AlertQuietHours struct {
+	Start       string `yaml:"start"` // "HH:MM"
+	End         string `yaml:"end"`   // "HH:MM"
+	MinSeverity string `yaml:"min_severity"`
+}
+
+// AlertRule is one condition. Which fields a rule reads depends on its kind:
+//
+//   - order_stuck — an active order in one of Statuses (any active status when
+//     empty) for longer than After.
+//   - edge_stale — an edge whose last heartbeat is older than After.
+//   - lineside_low — a lineside level reported below Threshold, optionally
+//     only for Nodes and Payloads; After is how long it must stay low.
+//   - dead_letters — at least Threshold outbox messages (default 1) gave up
+//     retrying.
+//   - fleet_disconnected — the fleet manager unreachable for longer than After.
+//   - robot_low_confidence — a connected robot's localization confidence below
+//     Threshold for longer than After.
+//   - localization_hotspot — a connected robot reading below Threshold inside
+//     a place the fleet persistently loses its position (shingocore/mapfix),
+//     for longer than After. Set Threshold above robot_low_confidence's so it
+//     fires while the robot is slipping, before it is lost.
+//   - event — raised by an engine event named in Events (order_faulted,
+//     order_failed, grace_expired). Nothing clears an event alert but an
+//     acknowledgement.
+type AlertRule struct {
+	Name     string        `yaml:"name"`
+	Kind     string        `yaml:"kind"`
+	Severity string        `yaml:"severity"` // info | warning | critical
+	After    time.Duration `yaml:"after"`
+
+	Threshold float64  `yaml:"threshold"`
+	Statuses  []string `yaml:"statuses"`
+	Nodes     []string `yaml:"nodes"`
+	Payloads  []string `yaml:"payloads"`
+	Events    []string `yaml:"events"`
+
+	// EscalateAfter is how long an alert may stay unacknowledged before it is
+	// escalated to EscalateSeverity (default critical). Zero never escalates.
+	EscalateAfter    time.Duration `yaml:"escalate_after"`
+	EscalateSeverity string        `yaml:"escalate_severity"`
+}
+
+// DefaultAlertRules is the shipped rule list: the conditions that used to be
+// found by someone noticing. Lineside levels have no default — a threshold
+// means nothing without knowing the part — and neither do event rules, which
+// would repeat the fault and failure emails a plant already gets.
+func DefaultAlertRules() []AlertRule {
+	return []AlertRule{
+		{Name: "order-stuck", Kind: "order_stuck", Severity: "warning", After: 30 * time.Minute,
+			Statuses: []string{"dispatched", "acknowledged", "in_transit"}, EscalateAfter: 30 * time.Minute},
+		{Name: "edge-stale", Kind: "edge_stale", Severity: "warning", After: 15 * time.Minute,
+			EscalateAfter: time.Hour},
+		{Name: "outbox-dead-letters", Kind: "dead_letters", Severity: "warning", Threshold: 1},
+		{Name: "fleet-disconnected", Kind: "fleet_disconnected", Severity: "critical", After: 2 * time.Minute},
+		{Name: "robot-low-confidence", Kind: "robot_low_confidence", Severity: "warning",
+			Threshold: 0.5, After: 2 * time.Minute, EscalateAfter: 15 * time.Minute},
+		{Name: "localization-hotspot", Kind: "localization_hotspot", Severity: "warning",
+			Threshold: 0.7, After: time.Minute},
+	}
+}
+
+// ReportsConfig is the end-of-shift operations report: when a shift ends, Core
+// totals it (orders by type and outcome, delivery times, downtime per cell,
+// faults per robot, inventory corrections, dead letters), keeps the report
+// for the Reports page, and sends it to the notification channels under the
+// event type shift_report.
+//
+// Shift times are wall-clock in the plant timezone (PLANT_TIMEZONE, the zone
+// the dashboards' date filters already use), not the server's — a server on
+// UTC would otherwise close the night shift at 1 a.m. plant time.
+type ReportsConfig struct {
+	// Enabled false stops generation; stored reports stay readable.
+	Enabled bool `yaml:"enabled"`
+	// Delay is how long after a shift ends its report is built, so the last
+	// confirmations and downtime events off the edges are in before it is
+	// totalled. Default 5m.
+	Delay time.Duration `yaml:"delay"`
+	// Shifts replaces the shipped three-shift day (DefaultReportShifts) when
+	// set at all.
+	Shifts []ReportShift `yaml:"shifts"`
+}
+
+// ReportShift is one named shift. End at or before Start runs past midnight
+// ("22:00"–"06:00"), and the report is filed under the day the shift started.
+type ReportShift struct {
+	Name  string `yaml:"name"`
+	Start string `yaml:"start"` // "HH:MM"
+	End   string `yaml:"end"`   // "HH:MM"
+}
+
+// DefaultReportShifts is the shipped shift pattern: three eights from 06:00.
+func DefaultReportShifts() []ReportShift {
+	return []ReportShift{
+		{Name: "1st", Start: "06:00", End: "14:00"},
+		{Name: "2nd", Start: "14:00", End: "22:00"},
+		{Name: "3rd", Start: "22:00", End: "06:00"},
+	}
+}
+
+// Backup storage backends.
+const (
+	BackupStorageS3         = "s3"
+	BackupStorageFilesystem = "filesystem"
+)
+
+// BackupConfig schedules logical exports of the Core schema — bins, orders,
+// the ledger and everything else Core is the system of record for. They sit
+// beside whatever the DBA does with Postgres, not in place of it: an export is
+// consistent, portable across Postgres versions and restorable by the Core
+// binary that wrote it, but it is not point-in-time recovery between runs.
+type BackupConfig struct {
+	// Enabled false stops the schedule; `corebackup export` still works.
+	Enabled bool `yaml:"enabled"`
+	// ScheduleInterval is the time between exports. Default 6h.
+	ScheduleInterval time.Duration `yaml:"schedule_interval"`
+	// Retention, as on the edge: the newest export is always kept, plus the
+	// newest in each of the last N hours, days, ISO weeks and months.
+	KeepHourly  int `yaml:"keep_hourly"`
+	KeepDaily   int `yaml:"keep_daily"`
+	KeepWeekly  int `yaml:"keep_weekly"`
+	KeepMonthly int `yaml:"keep_monthly"`
+	// Storage picks the backend: filesystem or s3. Default filesystem.
+	Storage    string                 `yaml:"storage"`
+	Filesystem BackupFilesystemConfig `yaml:"filesystem"`
+	S3         BackupS3Config         `yaml:"s3"`
+}
+
+// BackupFilesystemConfig is a directory target: a local disk or an NFS or SMB
+// mount. It must already exist; it is never created, so an unmounted share
+// fails the export instead of filling the root disk.
+type BackupFilesystemConfig struct {
+	Path string `yaml:"path"`
+}
+
+// BackupS3Config is an S3-compatible target.
+type BackupS3Config struct {
+	Endpoint              string `yaml:"endpoint"`
+	Bucket                string `yaml:"bucket"`
+	Region                string `yaml:"region"`
+	AccessKey             string `yaml:"access_key"`
+	SecretKey             string `yaml:"secret_key"`
+	UsePathStyle          bool   `yaml:"use_path_style"`
+	InsecureSkipTLSVerify bool   `yaml:"insecure_skip_tls_verify"`
+}
+
+// AnalyticsExportConfig schedules the analytics export: every closed plant day
+// of the analytics datasets, written as Parquet and/or CSV into a directory or
+// S3 bucket for the industrial engineers' offline analysis
+// (docs/analytics-export.md). The target is the backup section's kind of
+// target, configured separately so the two can point at different places.
+type AnalyticsExportConfig struct {
+	// Enabled false stops the schedule; the download on the Missions page
+	// still works.
+	Enabled bool `yaml:"enabled"`
+	// Formats to write: parquet, csv or both. Default parquet.
+	Formats []string `yaml:"formats"`
+	// Datasets to write. Empty is every dataset.
+	Datasets []string `yaml:"datasets"`
+	// LookbackDays is how many closed days the schedule keeps complete: a
+	// day in the window with no manifest on the target is exported, so a
+	// Core that was down, or a target that was unreachable, catches up.
+	// Default 7.
+	LookbackDays int `yaml:"lookback_days"`
+	// Delay is how long after plant midnight a day is left open for late
+	// Edge data before it is exported. Default 1h.
+	Delay time.Duration `yaml:"delay"`
+	// Storage picks the backend: filesystem or s3. Default filesystem.
+	Storage    string                 `yaml:"storage"`
+	Filesystem BackupFilesystemConfig `yaml:"filesystem"`
+	S3         BackupS3Config         `yaml:"s3"`
+}
+
+// Target is the export's storage as the backup package's backends take it.
+func (c AnalyticsExportConfig) Target() BackupConfig {
+	return BackupConfig{Storage: c.Storage, Filesystem: c.Filesystem, S3: c.S3}
+}
+
 type FireAlarmConfig struct {
 	Enabled           bool `yaml:"enabled"`             // feature gate; false = hidden from UI
 	AutoResumeDefault bool `yaml:"auto_resume_default"` // default checkbox state for auto-resume on clear
@@ -214,6 +464,44 @@ type NotificationsConfig struct {
 	FromAddress     string   `yaml:"from_address"`
 	Recipients      []string `yaml:"recipients"`
 	ThrottleMinutes int      `yaml:"throttle_minutes"`
+
+	// EmailEvents routes event types to the SMTP channel; empty means every
+	// event, which is what email did before channels existed.
+	EmailEvents []string `yaml:"email_events"`
+	// Channels are the non-email destinations — webhook, slack, teams, ntfy.
+	// Enabled above is the master switch for all of them and for email.
+	Channels []NotificationChannel `yaml:"channels"`
+	Retry    NotificationRetry     `yaml:"retry"`
+}
+
+// NotificationChannel is one chat, pager or webhook destination.
+//
+// The URL is a credential for slack and teams — whoever holds an incoming-
+// webhook URL can post to the channel — so it lives in the config file and is
+// never rendered back on the config page.
+type NotificationChannel struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"` // webhook | slack | teams | ntfy
+	URL  string `yaml:"url"`
+	// Events lists the event types this channel receives; empty means all.
+	Events []string `yaml:"events"`
+	// Token is sent as "Authorization: Bearer" (webhook, ntfy).
+	Token   string            `yaml:"token"`
+	Headers map[string]string `yaml:"headers"`
+	// Priority overrides the ntfy priority derived from severity (1–5 or a name).
+	Priority string `yaml:"priority"`
+	// TitleTemplate and BodyTemplate are Go text/templates over notify.Message.
+	// Empty uses the message's own subject and summary.
+	TitleTemplate string        `yaml:"title_template"`
+	BodyTemplate  string        `yaml:"body_template"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// NotificationRetry is the delivery retry policy shared by every channel:
+// Attempts tries in total, waiting Backoff, then twice that, and so on.
+type NotificationRetry struct {
+	Attempts int           `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
 }
 
 // SimConfig configures the local-dev fleet simulator (core side). Sim code is
@@ -390,6 +678,32 @@ type WebConfig struct {
 	Host          string `yaml:"host"`
 	Port          int    `yaml:"port"`
 	SessionSecret string `yaml:"session_secret"`
+	// TLS serves the UI and API over HTTPS on Port. While it is on, session
+	// cookies are marked Secure and every response carries HSTS.
+	TLS WebTLSConfig `yaml:"tls"`
+}
+
+// WebTLSConfig is where the web certificate comes from. Either CertFile and
+// KeyFile name one issued by the plant's own PKI, or SelfSigned generates a
+// plant CA and a host certificate under Dir (see shingo/protocol/webtls).
+type WebTLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// SelfSigned generates the plant CA and host certificate when no
+	// cert_file is given. Browsers and edges must then be told to trust
+	// Dir/ca.pem once.
+	SelfSigned bool   `yaml:"self_signed"`
+	Dir        string `yaml:"dir"`
+	// CAFile and CAKeyFile use an existing plant CA instead of Dir/ca.pem.
+	CAFile    string `yaml:"ca_file"`
+	CAKeyFile string `yaml:"ca_key_file"`
+	// Hosts the generated certificate covers. Empty is this machine's
+	// hostname, localhost and its interface addresses.
+	Hosts []string `yaml:"hosts"`
+	// RedirectPort, when non-zero, listens for plain HTTP there and
+	// redirects every request to HTTPS — old bookmarks keep working.
+	RedirectPort int `yaml:"redirect_port"`
 }
 
 type MessagingConfig struct {
@@ -417,6 +731,65 @@ type KafkaConfig struct {
 	// (or a plant) saving broker names that don't resolve would hold the
 	// handler for 5s × brokers.
 	DialTimeout time.Duration `yaml:"dial_timeout"`
+
+	// TLS and SASL apply to every broker connection: the reachability probe,
+	// topic creation, readers and the writer. Both off is plaintext, which is
+	// only acceptable while the broker sits on an isolated VLAN.
+	TLS  KafkaTLSConfig  `yaml:"tls"`
+	SASL KafkaSASLConfig `yaml:"sasl"`
+}
+
+// SASL mechanisms accepted in KafkaSASLConfig.Mechanism. The names are the
+// broker's own (sasl.enabled.mechanisms), lower-cased.
+const (
+	KafkaSASLPlain       = "plain"
+	KafkaSASLSCRAMSHA256 = "scram-sha-256"
+	KafkaSASLSCRAMSHA512 = "scram-sha-512"
+)
+
+// KafkaTLSConfig encrypts broker connections. CAFile empty means the system
+// roots; CertFile and KeyFile together are a client certificate, for brokers
+// that authenticate clients by mTLS rather than (or as well as) SASL.
+type KafkaTLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CAFile   string `yaml:"ca_file"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ServerName overrides the name checked against the broker certificate,
+	// for brokers addressed by IP or by an alias the certificate omits.
+	ServerName string `yaml:"server_name"`
+	// InsecureSkipVerify accepts any broker certificate. Commissioning only:
+	// it keeps the traffic encrypted but lets anyone on the wire impersonate
+	// the broker.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// KafkaSASLConfig authenticates to the broker. Mechanism empty disables SASL.
+// PLAIN sends the password as-is, so it belongs only on a TLS connection.
+type KafkaSASLConfig struct {
+	Mechanism string `yaml:"mechanism"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+}
+
+// Validate reports a TLS or SASL setting that cannot produce a working
+// connection, so a typo fails the save or the boot rather than every dial.
+func (k KafkaConfig) Validate() error {
+	if k.TLS.Enabled && (k.TLS.CertFile == "") != (k.TLS.KeyFile == "") {
+		return fmt.Errorf("kafka tls: cert_file and key_file must be set together")
+	}
+	switch k.SASL.Mechanism {
+	case "":
+		return nil
+	case KafkaSASLPlain, KafkaSASLSCRAMSHA256, KafkaSASLSCRAMSHA512:
+	default:
+		return fmt.Errorf("kafka sasl: unknown mechanism %q (want %s, %s or %s)",
+			k.SASL.Mechanism, KafkaSASLPlain, KafkaSASLSCRAMSHA256, KafkaSASLSCRAMSHA512)
+	}
+	if k.SASL.Username == "" {
+		return fmt.Errorf("kafka sasl: username is required for %s", k.SASL.Mechanism)
+	}
+	return nil
 }
 
 // DialTimeoutOr returns the effective broker-probe timeout: the configured
@@ -491,6 +864,38 @@ type RobotConfidenceConfig struct {
 	BaselineDays int `yaml:"baseline_days"`
 }
 
+// RobotMaintenanceConfig is predictive robot maintenance: the health record
+// sampled off the robot poll, the indicators assessed from it, and the work
+// items raised when one is due (shingocore/robothealth). A work item is sent
+// to the notification channels as event type robot_maintenance_due, which is
+// how it reaches a CMMS — a webhook channel routed to that event.
+type RobotMaintenanceConfig struct {
+	// Enabled false stops sampling and assessment; items already filed stay
+	// on the robots page.
+	Enabled bool `yaml:"enabled"`
+	// SampleInterval is how often a robot's health is written. Alarm onsets
+	// are recorded on every poll regardless. Default 5m.
+	SampleInterval time.Duration `yaml:"sample_interval"`
+	// LookbackDays is the record the indicators read; capacity fade's
+	// baseline is its first week. Default 60.
+	LookbackDays int `yaml:"lookback_days"`
+	// RetentionDays bounds the samples and cleared faults kept. Default 400,
+	// so a battery can be compared against itself a year ago.
+	RetentionDays int `yaml:"retention_days"`
+	// HoldDueRobots takes a robot out of availability when an item is
+	// filed for it, and gives it back when its last held item is closed.
+	// Off by default: a plant decides whether a due robot may finish its
+	// shift.
+	HoldDueRobots bool `yaml:"hold_due_robots"`
+
+	// The due points; zero disables an indicator.
+	BatteryCycles   int     `yaml:"battery_cycles"`    // rated charge cycles
+	CapacityFadePct float64 `yaml:"capacity_fade_pct"` // % more battery per km than the first week
+	FaultsPerWeek   int     `yaml:"faults_per_week"`   // alarm onsets in 7 days
+	ServiceKm       float64 `yaml:"service_km"`        // km between services
+	ConfidenceDrop  float64 `yaml:"confidence_drop"`   // mean confidence lost week on week
+}
+
 func Defaults() *Config {
 	return &Config{
 		Database: DatabaseConfig{
@@ -514,6 +919,7 @@ func Defaults() *Config {
 			Host:          "0.0.0.0",
 			Port:          8083,
 			SessionSecret: "change-me-in-production",
+			TLS:           WebTLSConfig{Dir: "tls"},
 		},
 		Staging: StagingConfig{
 			TTL:                  0, // 0 = never auto-unstage; override per node group via staging_ttl property
@@ -539,6 +945,7 @@ func Defaults() *Config {
 				Window:        60 * time.Minute,
 				AlertThrottle: 15 * time.Minute,
 			},
+			EmptyAffinityGrace: 10 * time.Minute,
 		},
 		Replenishment: ReplenishmentConfig{
 			// R1 LIVE by default: decide off the Edge lineside reports (ledger +
@@ -555,6 +962,37 @@ func Defaults() *Config {
 			ChildlessGrace:    15 * time.Minute,
 			OrphanGrace:       24 * time.Hour,
 		},
+		Quality: QualityConfig{
+			ReasonCodes:   DefaultQualityReasonCodes(),
+			SweepInterval: time.Minute,
+		},
+		Alerts: AlertsConfig{
+			Enabled:      true,
+			EvalInterval: 30 * time.Second,
+			QuietHours:   AlertQuietHours{MinSeverity: "critical"},
+			Rules:        DefaultAlertRules(),
+		},
+		Reports: ReportsConfig{
+			Enabled: true,
+			Delay:   5 * time.Minute,
+			Shifts:  DefaultReportShifts(),
+		},
+		Backup: BackupConfig{
+			Enabled:          false,
+			ScheduleInterval: 6 * time.Hour,
+			KeepHourly:       0,
+			KeepDaily:        7,
+			KeepWeekly:       4,
+			KeepMonthly:      6,
+			Storage:          BackupStorageFilesystem,
+		},
+		AnalyticsExport: AnalyticsExportConfig{
+			Enabled:      false,
+			Formats:      []string{"parquet"},
+			LookbackDays: 7,
+			Delay:        time.Hour,
+			Storage:      BackupStorageFilesystem,
+		},
 		Messaging: MessagingConfig{
 			Kafka: KafkaConfig{
 				Brokers: []string{"localhost:9092"},
@@ -572,6 +1010,7 @@ func Defaults() *Config {
 			SMTPPort:        587,
 			SMTPTLS:         true,
 			ThrottleMinutes: 15,
+			Retry:           NotificationRetry{Attempts: 3, Backoff: 2 * time.Second},
 		},
 		Sim: SimConfig{
 			// Enabled false by default; Seed 0 = derive+log. Sane sim timings so a
@@ -597,6 +1036,17 @@ func Defaults() *Config {
 			SnapToleranceMetres:        2.0,
 			BaselineDays:               14,
 		},
+		RobotMaintenance: RobotMaintenanceConfig{
+			Enabled:         true,
+			SampleInterval:  5 * time.Minute,
+			LookbackDays:    60,
+			RetentionDays:   400,
+			BatteryCycles:   1500,
+			CapacityFadePct: 20,
+			FaultsPerWeek:   25,
+			ServiceKm:       2000,
+			ConfidenceDrop:  0.10,
+		},
 		// Values and the reasoning behind each of them live in provenance.go,
 		// together, so that neither can be edited without the other in view.
 		Display: DisplayDefaults(),
@@ -141,6 +141,90 @@ func TestLoad_InvalidYAML_Error(t *testing.T) {
 	}
 }
 
+func TestLoad_WebTLS(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "shingocore.yaml")
+	testutil.MustNoErr(t, os.WriteFile(path, []byte(`web:
+  port: 8443
+  tls:
+    enabled: true
+    self_signed: true
+    hosts: [core.plant.local, 10.0.0.5]
+    redirect_port: 8083
+`), 0644), "WriteFile")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got := cfg.Web.TLS
+	if !got.Enabled || !got.SelfSigned || got.RedirectPort != 8083 || len(got.Hosts) != 2 {
+		t.Errorf("TLS = %+v", got)
+	}
+	if got.Dir != "tls" {
+		t.Errorf("Dir = %q, want the default %q kept when the file omits it", got.Dir, "tls")
+	}
+	if Defaults().Web.TLS.Enabled {
+		t.Error("web TLS must default off — an existing plain-HTTP install must not change on upgrade")
+	}
+}
+
+func TestLoad_KafkaSecurity(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "shingocore.yaml")
+	testutil.MustNoErr(t, os.WriteFile(path, []byte(`messaging:
+  kafka:
+    brokers: [kafka.plant.local:9093]
+    tls:
+      enabled: true
+      ca_file: /etc/shingo/kafka-ca.pem
+      cert_file: /etc/shingo/core.pem
+      key_file: /etc/shingo/core.key
+      server_name: kafka.plant.local
+    sasl:
+      mechanism: scram-sha-512
+      username: shingocore
+      password: hunter2
+`), 0644), "WriteFile")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	k := cfg.Messaging.Kafka
+	want := KafkaTLSConfig{Enabled: true, CAFile: "/etc/shingo/kafka-ca.pem", CertFile: "/etc/shingo/core.pem", KeyFile: "/etc/shingo/core.key", ServerName: "kafka.plant.local"}
+	if k.TLS != want {
+		t.Errorf("TLS = %+v, want %+v", k.TLS, want)
+	}
+	if k.SASL != (KafkaSASLConfig{Mechanism: KafkaSASLSCRAMSHA512, Username: "shingocore", Password: "hunter2"}) {
+		t.Errorf("SASL = %+v", k.SASL)
+	}
+	testutil.MustNoErr(t, k.Validate(), "Validate")
+	if d := Defaults().Messaging.Kafka; d.TLS.Enabled || d.SASL.Mechanism != "" {
+		t.Errorf("defaults enable kafka security: %+v", d)
+	}
+}
+
+func TestKafkaConfig_Validate(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		k    KafkaConfig
+		ok   bool
+	}{
+		{"plaintext", KafkaConfig{}, true},
+		{"tls system roots", KafkaConfig{TLS: KafkaTLSConfig{Enabled: true}}, true},
+		{"cert without key", KafkaConfig{TLS: KafkaTLSConfig{Enabled: true, CertFile: "c.pem"}}, false},
+		{"plain", KafkaConfig{SASL: KafkaSASLConfig{Mechanism: KafkaSASLPlain, Username: "u"}}, true},
+		{"scram-sha-256", KafkaConfig{SASL: KafkaSASLConfig{Mechanism: KafkaSASLSCRAMSHA256, Username: "u"}}, true},
+		{"no username", KafkaConfig{SASL: KafkaSASLConfig{Mechanism: KafkaSASLSCRAMSHA512}}, false},
+		{"unknown mechanism", KafkaConfig{SASL: KafkaSASLConfig{Mechanism: "SCRAM-SHA-512", Username: "u"}}, false},
+	}
+	for _, c := range cases {
+		if err := c.k.Validate(); (err == nil) != c.ok {
+			t.Errorf("%s: Validate() = %v, want ok=%v", c.name, err, c.ok)
+		}
+	}
+}
+
 func TestLockUnlock_Reentrancy(t *testing.T) {
 	t.Parallel()
 	c := Defaults()
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// PlantLocation is the plant's IANA timezone, resolved once from the
+// PLANT_TIMEZONE env var (default America/Chicago). Timestamps are stored
+// UTC; what is read as a plant day or a plant shift — a dashboard's "Today",
+// the end of the night shift — is reckoned in this zone (Q-004). An invalid
+// name falls back to UTC and says so once.
+//
+// An env var rather than a config field because the server process, not the
+// config page, owns it: the www date filters resolved it this way first, and
+// moving it here let the shift reports share the answer instead of repeating
+// the lookup.
+var PlantLocation = sync.OnceValue(func() *time.Location {
+	name := os.Getenv("PLANT_TIMEZONE")
+	if name == "" {
+		name = "America/Chicago"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("config: PLANT_TIMEZONE %q invalid (%v); falling back to UTC", name, err)
+		return time.UTC
+	}
+	return loc
+})
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"shingo/protocol"
 	"shingocore/fleet"
@@ -980,6 +981,12 @@ func (d *Dispatcher) failOrder(order *orders.Order, env *protocol.Envelope, erro
 	d.sendError(env, order.EdgeUUID, errorCode, detail)
 }
 
+// SetEmptyAffinityGrace installs dispatch.empty_affinity_grace on the
+// dispatcher's finder. See SourceFinder.SetEmptyAffinityGrace.
+func (d *Dispatcher) SetEmptyAffinityGrace(grace func() time.Duration) {
+	d.finder.SetEmptyAffinityGrace(grace)
+}
+
 // SetPostFindHook installs a test-only hook the fulfillment scanner fires between
 // Find and Claim — the single claim point after the claim-move to the scanner. Used
 // for deterministic concurrency testing (a claim race must re-queue, never drop).
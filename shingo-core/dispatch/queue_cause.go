@@ -470,6 +470,25 @@ const (
 	// declared. It waits rather than taking another: "a declared mix that is
 	// abandoned when inconvenient is not a mix".
 	CauseFinderNoEmptyOfType QueueCause = "finder-no-empty-of-type"
+	// CauseFinderQualityHold — the plant-wide search found nothing, and bins of
+	// the payload exist on quality hold. The plant is not out of material; the
+	// material is quarantined.
+	//
+	// A SEPARATE CAUSE FROM finder-plant-empty because the two are released by
+	// different people. An empty plant is waiting on a loader; this is waiting on
+	// a quality tech to disposition a hold, and a histogram that counted both as
+	// "no material" would send the starvation review to the wrong department.
+	CauseFinderQualityHold QueueCause = "finder-quality-hold"
+	// CauseFinderEmptyAffinity — the plant-wide empty search found nothing it
+	// was allowed to take, and empties exist that are held for another line's
+	// own use (empty_affinity=own, inside its grace). TC-39's cure, made
+	// visible: the plant is not out of empties, another line has first call.
+	//
+	// A SEPARATE CAUSE FROM finder-plant-empty because it releases itself on a
+	// clock. An empty plant waits for a carrier to appear; this waits for the
+	// grace to run out or the owner to take its empty, and a starvation review
+	// that read both as "no empties" would buy carriers the plant already has.
+	CauseFinderEmptyAffinity QueueCause = "finder-empty-affinity"
 
 	// ── Intake ────────────────────────────────────────────────────────────
 
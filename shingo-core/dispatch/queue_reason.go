@@ -127,6 +127,23 @@ type QueueParams struct {
 	// this wait is a carrier leaving OR the level being raised, and neither is
 	// findable from a sentence about slots.
 	AtLevel bool
+	// HeldBins is how many bins of the payload are on quality hold, set when a
+	// material wait came back empty and held bins exist. HoldRef ("QH-12") and
+	// HoldReason ("dimensional") name the hold holding most of them.
+	//
+	// Appended rather than replacing the sentence: the material IS short in the
+	// scope searched, and the hold is why. An operator reading "3 bins on quality
+	// hold (QH-12: dimensional)" knows who to call; one reading "waiting for
+	// material" walks to the warehouse and finds it full.
+	HeldBins   int
+	HoldRef    string
+	HoldReason string
+	// AffinityHeld is how many empties the plant-wide search skipped because
+	// they are held for their own line's use (empty_affinity=own, in grace);
+	// AffinityOwner names the owner holding most of them. Appended, like
+	// HeldBins: the asker IS short of an empty it may take, and the hold is why.
+	AffinityHeld  int
+	AffinityOwner string
 }
 
 // FormatQueueSentence renders the operator-visible sentence for a queue code +
@@ -191,6 +208,23 @@ func materialSentence(p QueueParams) string {
 			s = "That group's empties are kept for other equipment — waiting"
 		}
 	}
+	if p.HeldBins > 0 {
+		s += fmt.Sprintf(" — %s on quality hold", plural(p.HeldBins, "bin", "bins"))
+		switch {
+		case p.HoldRef != "" && p.HoldReason != "":
+			s += fmt.Sprintf(" (%s: %s)", p.HoldRef, p.HoldReason)
+		case p.HoldRef != "":
+			s += fmt.Sprintf(" (%s)", p.HoldRef)
+		}
+	}
+	if p.AffinityHeld > 0 {
+		s += fmt.Sprintf(" — %s held for ", plural(p.AffinityHeld, "empty", "empties"))
+		if p.AffinityOwner != "" {
+			s += p.AffinityOwner
+		} else {
+			s += "another line"
+		}
+	}
 	if p.Partial {
 		s += " — partial set already held"
 	}
@@ -78,6 +78,35 @@ func TestFormatQueueSentence_Snapshot(t *testing.T) {
 			params: QueueParams{Payload: "SNF2-6SA0B.06", Partial: true},
 			want:   "Waiting for material: SNF2-6SA0B.06 — partial set already held",
 		},
+		{
+			name:   "material on quality hold names the hold",
+			code:   protocol.QueueWaitingForMaterial,
+			params: QueueParams{Payload: "SNF2-6SA0B.06", HeldBins: 3, HoldRef: "QH-12", HoldReason: "dimensional"},
+			want:   "Waiting for material: SNF2-6SA0B.06 — 3 bins on quality hold (QH-12: dimensional)",
+		},
+		{
+			// Held bins with no hold row behind them — a bin put on hold
+			// before holds were rows. The count is still true; the name is not
+			// invented.
+			name:   "material on quality hold without a hold row",
+			code:   protocol.QueueWaitingForMaterial,
+			params: QueueParams{Payload: "SNF2-6SA0B.06", Group: "AMR Supermarket", HeldBins: 1},
+			want:   "Waiting for material: SNF2-6SA0B.06 in AMR Supermarket — 1 bin on quality hold",
+		},
+		{
+			// TC-39: the empties exist and belong to the line that made them.
+			// "Waiting for an empty bin" would send someone to look.
+			name:   "empty held by another line's affinity names the owner",
+			code:   protocol.QueueWaitingForMaterial,
+			params: QueueParams{Kind: "empty", AffinityHeld: 2, AffinityOwner: "LINE-A"},
+			want:   "Waiting for an empty bin — 2 empties held for LINE-A",
+		},
+		{
+			name:   "one empty held without an owner name",
+			code:   protocol.QueueWaitingForMaterial,
+			params: QueueParams{Kind: "empty", AffinityHeld: 1},
+			want:   "Waiting for an empty bin — 1 empty held for another line",
+		},
 		{
 			name:   "slot at destination",
 			code:   protocol.QueueWaitingForSlot,
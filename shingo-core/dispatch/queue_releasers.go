@@ -795,6 +795,16 @@ var causeReleasers = []causeReleaser{
 		populations: []WaitPopulation{PopAcquiring},
 		what:        "material of this payload appears anywhere in the plant",
 	},
+	{
+		cause:       CauseFinderQualityHold,
+		populations: []WaitPopulation{PopAcquiring},
+		what:        "a quality hold on the payload is dispositioned (release or rework) or unheld material arrives",
+	},
+	{
+		cause:       CauseFinderEmptyAffinity,
+		populations: []WaitPopulation{PopAcquiring},
+		what:        "the owning line takes its empty, its affinity grace runs out, or an unowned empty appears",
+	},
 	{
 		cause:       CauseFinderNoFullCarrier,
 		populations: []WaitPopulation{PopAcquiring},
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"shingo/protocol"
 	"shingocore/dispatch/binresolver"
@@ -41,6 +42,11 @@ type SourceFinder struct {
 	db       FinderDB
 	resolver NodeResolver // may be nil (tier 1 self-guards)
 	dbg      func(string, ...any)
+
+	// affinityGrace is dispatch.empty_affinity_grace, read per search so a
+	// config save applies to the next one. Nil until the engine installs it,
+	// and nil holds nothing — the ranking still applies.
+	affinityGrace func() time.Duration
 }
 
 // NewSourceFinder constructs a SourceFinder. resolver may be nil — the NGRP tier
@@ -49,6 +55,12 @@ func NewSourceFinder(db FinderDB, resolver NodeResolver, dbg func(string, ...any
 	return &SourceFinder{db: db, resolver: resolver, dbg: dbg}
 }
 
+// SetEmptyAffinityGrace installs the empty-affinity grace policy. A func, not
+// a value, so the finder never holds config.
+func (f *SourceFinder) SetEmptyAffinityGrace(grace func() time.Duration) {
+	f.affinityGrace = grace
+}
+
 // isFullCarrier reports whether a carrier is FULL: at or above its payload's
 // per-bin capacity.
 //
@@ -281,6 +293,12 @@ func nodeLocalKind(intent Intent) string {
 // source needs each get correctly-scoped resolution instead of inheriting the
 // order's shape.
 func (f *SourceFinder) FindSourceForNeed(need SourceNeed) SourceResult {
+	return f.explainQualityHold(f.findSourceForNeed(need))
+}
+
+// findSourceForNeed is the tier cascade. FindSourceForNeed wraps it so every
+// none-found wait, from whichever tier, passes the quality-hold check once.
+func (f *SourceFinder) findSourceForNeed(need SourceNeed) SourceResult {
 	payloadCode := need.PayloadCode
 	intent := need.Intent
 	// moveShaped keeps its historical name inside the cascade; it now means
@@ -573,7 +591,7 @@ func (f *SourceFinder) FindSourceForNeed(need SourceNeed) SourceResult {
 			// and carries no maintain origin, and then these render byte-for-byte
 			// the queries they were before MG3-1: sharing is the plant default and
 			// the only fenced zones are maintained groups.
-			fence := f.emptyFenceFor(need)
+			fence := f.emptyFenceFor(need, excludeID)
 			if wantType != "" {
 				b, err = f.db.FindEmptyBinOfType(wantType, preferZone, excludeID, fence, need.Asker)
 				cause = CauseFinderNoEmptyOfType
@@ -585,12 +603,12 @@ func (f *SourceFinder) FindSourceForNeed(need SourceNeed) SourceResult {
 				return unreadableSource("empty", payloadCode, "")
 			}
 			if b == nil {
-				return SourceResult{
+				return f.explainEmptyAffinity(SourceResult{
 					Outcome:     OutcomeWait,
 					QueueCode:   protocol.QueueWaitingForMaterial,
 					QueueCause:  cause,
 					QueueParams: QueueParams{Kind: "empty", Payload: payloadCode},
-				}
+				}, payloadCode, wantType, excludeID, fence, need.Asker)
 			}
 			bin = b
 		}
@@ -0,0 +1,72 @@
+package dispatch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"shingocore/store"
+	"shingocore/store/bins"
+	"shingocore/store/reservations"
+)
+
+// EmptyAffinityReader is the optional store surface the finder asks, after a
+// plant-wide empty search came back empty, whether empties were there and
+// held for another line's own use (TC-39).
+//
+// OPTIONAL, NOT A FinderDB METHOD, on QualityHoldReader's precedent: the
+// answer changes the words on a wait and never the decision — the hold was
+// applied inside the search. A fake that does not implement it gets the
+// sentences it always did. *store.DB implements it.
+type EmptyAffinityReader interface {
+	EmptiesHeldForOthers(payloadCode, binTypeCode string, excludeNodeID int64,
+		fence bins.EmptyFence, asker reservations.DigAsker) ([]bins.AffinityHold, error)
+}
+
+var _ EmptyAffinityReader = (*store.DB)(nil)
+
+// explainEmptyAffinity rewrites a plant-wide empty wait when the search
+// skipped empties inside another owner's grace, so the floor reads "2 empties
+// held for LINE-A" instead of "waiting for an empty bin" while empties stand
+// at the next line. The debug line says which owners, how many each, and when
+// the first comes free — the part of the answer that goes stale, and so stays
+// out of the persisted sentence.
+//
+// Only called on the plant-wide miss, where the hold is the whole story: the
+// widest search found nothing it was allowed to take. A read error leaves the
+// wait exactly as the search produced it.
+func (f *SourceFinder) explainEmptyAffinity(r SourceResult, payloadCode, binTypeCode string,
+	excludeID int64, fence bins.EmptyFence, asker reservations.DigAsker) SourceResult {
+	if !fence.Holds() {
+		return r
+	}
+	ar, ok := f.db.(EmptyAffinityReader)
+	if !ok {
+		return r
+	}
+	held, err := ar.EmptiesHeldForOthers(payloadCode, binTypeCode, excludeID, fence, asker)
+	if err != nil {
+		f.debug("finder: empty-affinity read failed: %v", err)
+		return r
+	}
+	if len(held) == 0 {
+		return r
+	}
+	var grace time.Duration
+	if f.affinityGrace != nil {
+		grace = f.affinityGrace()
+	}
+	parts := make([]string, 0, len(held))
+	total := 0
+	for _, h := range held {
+		total += h.Bins
+		free := h.Since.Add(grace)
+		parts = append(parts, fmt.Sprintf("%s×%d(first free %s)", h.Owner, h.Bins, free.UTC().Format(time.RFC3339)))
+	}
+	f.debug("finder: empty search for node %d skipped %d empties held by affinity: %s",
+		fence.AskerNode, total, strings.Join(parts, " "))
+	r.QueueCause = CauseFinderEmptyAffinity
+	r.QueueParams.AffinityHeld = total
+	r.QueueParams.AffinityOwner = held[0].Owner
+	return r
+}
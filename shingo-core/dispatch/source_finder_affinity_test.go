@@ -0,0 +1,91 @@
+package dispatch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shingo/protocol"
+	"shingocore/store/bins"
+	"shingocore/store/reservations"
+)
+
+// affinityFinderDB is the plain finder fake plus the optional affinity read.
+type affinityFinderDB struct {
+	*fakeFinderDB
+	held  []bins.AffinityHold
+	err   error
+	calls int
+}
+
+func (a *affinityFinderDB) EmptiesHeldForOthers(string, string, int64, bins.EmptyFence, reservations.DigAsker) ([]bins.AffinityHold, error) {
+	a.calls++
+	return a.held, a.err
+}
+
+func TestFinder_EmptyAffinityExplainsPlantWideMiss(t *testing.T) {
+	t.Parallel()
+	base := newFakeFinderDB()
+	base.addNode(pinNode(71, "AF-LINE-B"))
+	db := &affinityFinderDB{fakeFinderDB: base, held: []bins.AffinityHold{
+		{Owner: "LINE-A", Bins: 2, Since: time.Now().UTC()},
+		{Owner: "LINE-C", Bins: 1, Since: time.Now().UTC()},
+	}}
+	f := NewSourceFinder(db, nil, nil)
+	f.SetEmptyAffinityGrace(func() time.Duration { return 10 * time.Minute })
+
+	got := f.FindSourceForNeed(SourceNeed{DeliveryNode: "AF-LINE-B", PayloadCode: "PANEL-A", Intent: IntentEmpty})
+	if got.Outcome != OutcomeWait || got.QueueCode != protocol.QueueWaitingForMaterial {
+		t.Fatalf("got %v/%q, want a material wait", got.Outcome, got.QueueCode)
+	}
+	if got.QueueCause != CauseFinderEmptyAffinity {
+		t.Errorf("cause = %q, want %q", got.QueueCause, CauseFinderEmptyAffinity)
+	}
+	if p := got.QueueParams; p.AffinityHeld != 3 || p.AffinityOwner != "LINE-A" {
+		t.Errorf("params = %+v, want 3 held naming LINE-A", p)
+	}
+	if fence := db.lastFence; fence.AskerNode != 71 || fence.HeldAfter.IsZero() {
+		t.Errorf("fence = %+v, want the destination and a hold cutoff", fence)
+	}
+}
+
+// TestFinder_EmptyAffinityLeavesOtherWaitsAlone pins the ways the affinity
+// read must NOT change a wait: nothing held, a failed read, and a grace of
+// zero — affinity only ranks then, so nothing was skipped and the read is
+// never asked.
+func TestFinder_EmptyAffinityLeavesOtherWaitsAlone(t *testing.T) {
+	t.Parallel()
+	held := []bins.AffinityHold{{Owner: "LINE-A", Bins: 2}}
+	cases := []struct {
+		name      string
+		held      []bins.AffinityHold
+		err       error
+		grace     time.Duration
+		wantCalls int
+	}{
+		{"nothing held", nil, nil, 10 * time.Minute, 1},
+		{"read failed", held, errors.New("boom"), 10 * time.Minute, 1},
+		{"no grace", held, nil, 0, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			base := newFakeFinderDB()
+			base.addNode(pinNode(72, "AF-LINE-B2"))
+			db := &affinityFinderDB{fakeFinderDB: base, held: tc.held, err: tc.err}
+			f := NewSourceFinder(db, nil, nil)
+			f.SetEmptyAffinityGrace(func() time.Duration { return tc.grace })
+
+			got := f.FindSourceForNeed(SourceNeed{DeliveryNode: "AF-LINE-B2", PayloadCode: "PANEL-A", Intent: IntentEmpty})
+			if got.QueueCause != CauseFinderPlantEmpty {
+				t.Errorf("cause = %q, want %q", got.QueueCause, CauseFinderPlantEmpty)
+			}
+			if got.QueueParams.AffinityHeld != 0 {
+				t.Errorf("AffinityHeld = %d, want 0", got.QueueParams.AffinityHeld)
+			}
+			if db.calls != tc.wantCalls {
+				t.Errorf("affinity reads = %d, want %d", db.calls, tc.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package dispatch
+
+import (
+	"shingo/protocol"
+	"shingocore/store"
+	"shingocore/store/qualityholds"
+)
+
+// QualityHoldReader is the optional store surface the finder asks, after a
+// full-material search came back empty, whether the material exists and is on
+// quality hold.
+//
+// OPTIONAL, NOT A FinderDB METHOD, because the answer only ever changes the
+// words on a wait and never the decision: a held bin was already excluded by
+// status inside every query above. The many FinderDB fakes have nothing to say
+// about holds, and a fake that does not implement this gets exactly the
+// sentences it always did. *store.DB implements it.
+type QualityHoldReader interface {
+	QualityHoldBlocking(payloadCode string) (qualityholds.Blocking, error)
+}
+
+var _ QualityHoldReader = (*store.DB)(nil)
+
+// explainQualityHold rewrites a none-found material wait when the payload's
+// bins are on quality hold, so the floor reads "on quality hold (QH-12:
+// dimensional)" instead of "waiting for material" about material that is
+// standing in the warehouse behind a hold.
+//
+// Only the plant-wide miss changes CAUSE. There, the hold is the whole story:
+// the widest search found nothing, and there are held bins of the payload. A
+// scoped tier (a group, a pool, a node) keeps its own cause — the hold may be
+// on bins somewhere else entirely, and the scope that came up empty is still
+// the fact that releases it — but its sentence carries the hold too, because
+// "waiting for material in PRESS-BUFFER" is the same half-truth one level down.
+//
+// Empty-carrier waits are never touched: an empty carrier has no lot to hold.
+// A read error leaves the wait exactly as the tiers produced it.
+func (f *SourceFinder) explainQualityHold(r SourceResult) SourceResult {
+	if r.Outcome != OutcomeWait || r.QueueCode != protocol.QueueWaitingForMaterial {
+		return r
+	}
+	p := r.QueueParams
+	if p.Kind == "empty" || p.Payload == "" || p.Reserved {
+		return r
+	}
+	promote := false
+	switch r.QueueCause {
+	case CauseFinderPlantEmpty:
+		promote = true
+	case CauseFinderGroupEmpty, CauseFinderPoolEmpty, CauseFinderNodeEmpty:
+	default:
+		return r
+	}
+	qr, ok := f.db.(QualityHoldReader)
+	if !ok {
+		return r
+	}
+	b, err := qr.QualityHoldBlocking(p.Payload)
+	if err != nil {
+		f.debug("finder: quality-hold read for %s failed: %v", p.Payload, err)
+		return r
+	}
+	if b.HeldBins == 0 {
+		return r
+	}
+	r.QueueParams.HeldBins = b.HeldBins
+	if b.HoldID > 0 {
+		r.QueueParams.HoldRef = qualityholds.Ref(b.HoldID)
+		r.QueueParams.HoldReason = b.ReasonCode
+	}
+	if promote {
+		r.QueueCause = CauseFinderQualityHold
+	}
+	return r
+}
@@ -0,0 +1,86 @@
+package dispatch
+
+import (
+	"errors"
+	"testing"
+
+	"shingo/protocol"
+	"shingocore/store/qualityholds"
+)
+
+// holdFinderDB is the plain finder fake plus the optional quality-hold read.
+type holdFinderDB struct {
+	*fakeFinderDB
+	blocking qualityholds.Blocking
+	err      error
+	calls    int
+}
+
+func (h *holdFinderDB) QualityHoldBlocking(string) (qualityholds.Blocking, error) {
+	h.calls++
+	return h.blocking, h.err
+}
+
+func TestFinder_QualityHoldExplainsPlantWideMiss(t *testing.T) {
+	t.Parallel()
+	base := newFakeFinderDB()
+	base.addNode(pinNode(61, "QH-LINE"))
+	db := &holdFinderDB{fakeFinderDB: base,
+		blocking: qualityholds.Blocking{HeldBins: 4, HoldID: 12, ReasonCode: "dimensional"}}
+	f := NewSourceFinder(db, nil, nil)
+
+	got := f.FindSourceForNeed(SourceNeed{DeliveryNode: "QH-LINE", PayloadCode: "PANEL-A", Intent: IntentFull})
+	if got.Outcome != OutcomeWait || got.QueueCode != protocol.QueueWaitingForMaterial {
+		t.Fatalf("got %v/%q, want a material wait", got.Outcome, got.QueueCode)
+	}
+	if got.QueueCause != CauseFinderQualityHold {
+		t.Errorf("cause = %q, want %q", got.QueueCause, CauseFinderQualityHold)
+	}
+	p := got.QueueParams
+	if p.HeldBins != 4 || p.HoldRef != "QH-12" || p.HoldReason != "dimensional" {
+		t.Errorf("params = %+v, want 4 held bins naming QH-12 dimensional", p)
+	}
+}
+
+// TestFinder_QualityHoldLeavesOtherWaitsAlone pins the three ways the hold
+// check must NOT change a wait: no held bins, a failed read, and an empty-carrier
+// need (which the read is never even asked about).
+func TestFinder_QualityHoldLeavesOtherWaitsAlone(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name      string
+		db        func(*fakeFinderDB) *holdFinderDB
+		intent    Intent
+		wantCause QueueCause
+		wantCalls int
+	}{
+		{"nothing held", func(b *fakeFinderDB) *holdFinderDB { return &holdFinderDB{fakeFinderDB: b} },
+			IntentFull, CauseFinderPlantEmpty, 1},
+		{"read failed", func(b *fakeFinderDB) *holdFinderDB {
+			return &holdFinderDB{fakeFinderDB: b, err: errors.New("boom"),
+				blocking: qualityholds.Blocking{HeldBins: 2}}
+		}, IntentFull, CauseFinderPlantEmpty, 1},
+		{"empty carrier", func(b *fakeFinderDB) *holdFinderDB {
+			return &holdFinderDB{fakeFinderDB: b, blocking: qualityholds.Blocking{HeldBins: 2}}
+		}, IntentEmpty, CauseFinderPlantEmpty, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			base := newFakeFinderDB()
+			base.addNode(pinNode(62, "QH-LINE2"))
+			db := tc.db(base)
+			got := NewSourceFinder(db, nil, nil).FindSourceForNeed(SourceNeed{
+				DeliveryNode: "QH-LINE2", PayloadCode: "PANEL-A", Intent: tc.intent})
+			if got.QueueCause != tc.wantCause {
+				t.Errorf("cause = %q, want %q", got.QueueCause, tc.wantCause)
+			}
+			if got.QueueParams.HeldBins != 0 {
+				t.Errorf("HeldBins = %d, want 0", got.QueueParams.HeldBins)
+			}
+			if db.calls != tc.wantCalls {
+				t.Errorf("hold reads = %d, want %d", db.calls, tc.wantCalls)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package dispatch
 
-import "shingocore/store/bins"
+import (
+	"shingo/protocol/clock"
+
+	"shingocore/store/bins"
+)
 
 // source_finder_want.go — which carrier TYPE an empty should be.
 //
@@ -168,8 +172,18 @@ func (f *SourceFinder) wantedBinType(need SourceNeed) string {
 // group, which the next tick corrects, against parking every empty pull in the
 // plant on a database blip. The alternative — treat an unreadable episode as
 // "fence everything" — would turn one bad read into a plant-wide stall.
-func (f *SourceFinder) emptyFenceFor(need SourceNeed) bins.EmptyFence {
-	fence := bins.EmptyFence{ProcessNode: need.ProcessNode}
+//
+// THE DESTINATION RIDES ALONG FOR EMPTY AFFINITY (TC-39): destID is the node
+// the carrier is going to, and the grace cutoff is taken from the injected
+// clock, as empty_since is written. No destination, no affinity — the ask has
+// no line to own anything on behalf of.
+func (f *SourceFinder) emptyFenceFor(need SourceNeed, destID int64) bins.EmptyFence {
+	fence := bins.EmptyFence{ProcessNode: need.ProcessNode, AskerNode: destID}
+	if destID != 0 && f.affinityGrace != nil {
+		if g := f.affinityGrace(); g > 0 {
+			fence.HeldAfter = clock.Now().UTC().Add(-g)
+		}
+	}
 	if need.OriginID == "" {
 		return fence
 	}
@@ -0,0 +1,315 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// forecast.go — a payload's demand per hour, learned from what the lines
+// actually drew, and the UOP threshold that demand calls for.
+//
+// The queries live in store/forecast. Everything here is a PURE FUNCTION of
+// (hourly consumption, hourly production, lead time, service level): no
+// database, no clock.
+//
+// ── WHERE THE DEMAND COMES FROM ──────────────────────────────────────────────
+//
+// Consumption is the truth path: bin_uop_ledger's applied consume_tick rows,
+// the before−after drop on the bin the line was drawing from. The station is
+// the row's actor (store/audit/cycle_time.go says why). cell_part_events
+// cannot supply it — its payload_code is empty on every row — but it is the
+// only record of WHICH STYLE the line was running, so production ticks name
+// the style and the ledger supplies the quantity.
+//
+// The unit is one plant hour of one payload: every station that drew the
+// payload in the window is a consumer, and an hour counts if any consumer
+// made parts or drew the payload in it. An hour a consumer ran and drew
+// nothing is a zero, and it belongs in the sample: leaving it out would rate
+// the payload at the pace of its busiest hours only. An hour no consumer ran
+// at all is not demand and is not counted — a weekend is not a quiet shift.
+//
+// The hour's style is the one the consumers made most parts of in it (the
+// lower id on a tie); an hour with consumption and no production ticks is
+// style 0, unknown. Its shift is the report shift (reports.shifts) the hour's
+// midpoint falls in.
+//
+// ── FROM DEMAND TO A THRESHOLD ───────────────────────────────────────────────
+//
+// Over a replenishment lead time of L hours, with hourly demand of mean μ and
+// standard deviation σ, and hours taken as independent:
+//
+//	lead demand   μL = μ·L        σL = σ·√L
+//	safety stock  SS = z·σL       z = Φ⁻¹(service level)
+//	threshold        = ⌈μL + SS⌉
+//
+// LOW VOLUME IS POISSON. Below lowVolumeLeadDemand UOP of expected lead
+// demand the normal curve is a poor fit — it puts weight on negative demand
+// and rounds a handful of parts into a fraction of one — so the threshold is
+// the smallest k with P(Poisson(μL) ≤ k) ≥ service level, and the safety
+// stock is what that adds over μL. Only when the hours are not overdispersed,
+// though: Poisson's variance is its mean, and a payload drawn in lumps (whole
+// kits at a time) has far more, which Poisson would under-cover. Those stay
+// normal on their observed σ.
+//
+// The confidence interval is on the RATE: μ ± 1.96·σ/√n over the n sample
+// hours, and the threshold is recomputed at each end. It says how much the
+// suggestion would move if the window had been another run of the same
+// plant, not how often the line will run short.
+//
+// Nothing here is applied. A suggestion is a number for an engineer to read
+// beside the current threshold; the inventory page's Apply only fills the
+// field, and Save is still theirs.
+
+// lowVolumeLeadDemand is the expected lead-time demand, in UOP, below which
+// the threshold is a Poisson quantile instead of a normal one.
+const lowVolumeLeadDemand = 20
+
+// poissonDispersionMax is the variance-to-mean ratio of the hourly demand
+// above which a low-volume payload is too lumpy for Poisson.
+const poissonDispersionMax = 1.5
+
+// Forecast methods.
+const (
+	ForecastNormal  = "normal"
+	ForecastPoisson = "poisson"
+)
+
+// DemandConsumption is one station's draw of a payload in one hour.
+type DemandConsumption struct {
+	Station string
+	Hour    time.Time
+	UOP     int64
+}
+
+// StyleHour is the parts one station made of one style in one hour.
+type StyleHour struct {
+	Station string
+	Hour    time.Time
+	StyleID int64
+	Parts   int64
+}
+
+// DemandHour is one plant hour of one payload's demand.
+type DemandHour struct {
+	Hour    time.Time
+	StyleID int64
+	Shift   string
+	UOP     int64
+}
+
+// DemandHours builds a payload's sample hours from its consumption and the
+// plant's production, per the file comment. shiftOf names the shift an
+// instant falls in. Oldest first.
+func DemandHours(consumed []DemandConsumption, styles []StyleHour, shiftOf func(time.Time) string) []DemandHour {
+	consumers := make(map[string]bool)
+	uop := make(map[time.Time]int64)
+	for _, c := range consumed {
+		consumers[c.Station] = true
+		uop[c.Hour] += c.UOP
+	}
+	parts := make(map[time.Time]map[int64]int64)
+	for _, s := range styles {
+		if !consumers[s.Station] || s.Parts <= 0 {
+			continue
+		}
+		if parts[s.Hour] == nil {
+			parts[s.Hour] = make(map[int64]int64)
+		}
+		parts[s.Hour][s.StyleID] += s.Parts
+	}
+
+	hours := make(map[time.Time]bool, len(uop)+len(parts))
+	for h := range uop {
+		hours[h] = true
+	}
+	for h := range parts {
+		hours[h] = true
+	}
+	out := make([]DemandHour, 0, len(hours))
+	for h := range hours {
+		var style, most int64
+		for id, n := range parts[h] {
+			if n > most || (n == most && id < style) {
+				style, most = id, n
+			}
+		}
+		out = append(out, DemandHour{Hour: h, StyleID: style, Shift: shiftOf(h.Add(30 * time.Minute)), UOP: uop[h]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hour.Before(out[j].Hour) })
+	return out
+}
+
+// DemandThreshold is one threshold suggestion and how it was reached.
+type DemandThreshold struct {
+	Method      string  `json:"method"`
+	LeadMean    float64 `json:"lead_mean"`
+	LeadStdDev  float64 `json:"lead_stddev"`
+	SafetyStock int     `json:"safety_stock"`
+	Threshold   int     `json:"threshold"`
+}
+
+// ThresholdForDemand is the threshold for hourly demand of mean and stddev
+// over leadHours at serviceLevel, per the file comment.
+func ThresholdForDemand(mean, stddev, leadHours, serviceLevel float64) DemandThreshold {
+	mean = math.Max(mean, 0)
+	t := DemandThreshold{
+		Method:     ForecastNormal,
+		LeadMean:   mean * leadHours,
+		LeadStdDev: stddev * math.Sqrt(leadHours),
+	}
+	if t.LeadMean <= 0 {
+		return t
+	}
+	if t.LeadMean < lowVolumeLeadDemand && stddev*stddev <= poissonDispersionMax*mean {
+		t.Method = ForecastPoisson
+		t.Threshold = PoissonQuantile(t.LeadMean, serviceLevel)
+	} else {
+		t.Threshold = int(math.Ceil(t.LeadMean + NormalQuantile(serviceLevel)*t.LeadStdDev))
+	}
+	t.SafetyStock = max(t.Threshold-int(math.Ceil(t.LeadMean)), 0)
+	return t
+}
+
+// DemandForecastGroup is the demand of one style in one shift, and the
+// threshold it calls for.
+type DemandForecastGroup struct {
+	StyleID       int64   `json:"style_id"`
+	Shift         string  `json:"shift"`
+	Hours         int     `json:"hours"`
+	MeanPerHour   float64 `json:"mean_per_hour"`
+	StdDevPerHour float64 `json:"stddev_per_hour"`
+	DemandThreshold
+	// Low and High are the threshold at the two ends of the rate's 95%
+	// confidence interval; equal to Threshold with fewer than two hours.
+	Low        int    `json:"low"`
+	High       int    `json:"high"`
+	Confidence string `json:"confidence"` // HIGH | MEDIUM | LOW
+}
+
+// ForecastDemand groups the hours by style and shift and suggests a threshold
+// for each, heaviest threshold first.
+func ForecastDemand(hours []DemandHour, leadHours, serviceLevel float64) []DemandForecastGroup {
+	type key struct {
+		style int64
+		shift string
+	}
+	samples := make(map[key][]float64)
+	for _, h := range hours {
+		k := key{h.StyleID, h.Shift}
+		samples[k] = append(samples[k], float64(h.UOP))
+	}
+	out := make([]DemandForecastGroup, 0, len(samples))
+	for k, xs := range samples {
+		mean, sd := meanStdDev(xs)
+		g := DemandForecastGroup{
+			StyleID:         k.style,
+			Shift:           k.shift,
+			Hours:           len(xs),
+			MeanPerHour:     mean,
+			StdDevPerHour:   sd,
+			DemandThreshold: ThresholdForDemand(mean, sd, leadHours, serviceLevel),
+			Confidence:      forecastConfidence(len(xs)),
+		}
+		g.Low, g.High = g.Threshold, g.Threshold
+		if len(xs) > 1 {
+			half := 1.96 * sd / math.Sqrt(float64(len(xs)))
+			g.Low = ThresholdForDemand(mean-half, sd, leadHours, serviceLevel).Threshold
+			g.High = ThresholdForDemand(mean+half, sd, leadHours, serviceLevel).Threshold
+		}
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Threshold != out[j].Threshold {
+			return out[i].Threshold > out[j].Threshold
+		}
+		if out[i].StyleID != out[j].StyleID {
+			return out[i].StyleID < out[j].StyleID
+		}
+		return out[i].Shift < out[j].Shift
+	})
+	return out
+}
+
+// forecastConfidence grades a group by its sample hours: two weeks of one
+// eight-hour shift is HIGH, three shifts MEDIUM.
+func forecastConfidence(hours int) string {
+	switch {
+	case hours >= 80:
+		return "HIGH"
+	case hours >= 24:
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+// meanStdDev is the mean and sample standard deviation of xs.
+func meanStdDev(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(xs)-1))
+}
+
+// NormalQuantile is Φ⁻¹(p), the standard normal quantile, by Acklam's
+// rational approximation (relative error under 1.2e-9). p outside (0, 1)
+// returns ±Inf.
+func NormalQuantile(p float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	a := [...]float64{-3.969683028665376e+01, 2.209460984245205e+02, -2.759285104469687e+02,
+		1.383577518672690e+02, -3.066479806614716e+01, 2.506628277459239e+00}
+	b := [...]float64{-5.447609879822406e+01, 1.615858368580409e+02, -1.556989798598866e+02,
+		6.680131188771972e+01, -1.328068155288572e+01}
+	c := [...]float64{-7.784894002430293e-03, -3.223964580411365e-01, -2.400758277161838e+00,
+		-2.549732539343734e+00, 4.374664141464968e+00, 2.938163982698783e+00}
+	d := [...]float64{7.784695709041462e-03, 3.224671290700398e-01, 2.445134137142996e+00,
+		3.754408661907416e+00}
+	const low = 0.02425
+	switch {
+	case p < low:
+		q := math.Sqrt(-2 * math.Log(p))
+		return (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	case p > 1-low:
+		q := math.Sqrt(-2 * math.Log(1-p))
+		return -(((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	}
+	q := p - 0.5
+	r := q * q
+	return (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q /
+		(((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+}
+
+// PoissonQuantile is the smallest k with P(Poisson(mean) ≤ k) ≥ p.
+func PoissonQuantile(mean, p float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	term := math.Exp(-mean)
+	cdf := term
+	k := 0
+	for cdf < p && k < 10000 {
+		k++
+		term *= mean / float64(k)
+		cdf += term
+	}
+	return k
+}
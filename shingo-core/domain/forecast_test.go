@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// forecast_test.go — the enforcement half of forecast.go. Same contract as
+// oee_test.go: each test names the mutation it was verified red by.
+
+func fcHour(h int) time.Time {
+	return time.Date(2026, 10, 12, h, 0, 0, 0, time.UTC)
+}
+
+func fcShift(t time.Time) string {
+	if t.Hour() < 14 {
+		return "Day"
+	}
+	return "Swing"
+}
+
+// TestDemandHoursCountsRunningHoursWithNoDraw: an hour a consumer made parts
+// and drew nothing is a zero in the sample; a station that never drew the
+// payload adds no hours, and neither does an hour nobody ran.
+//
+// VERIFIED RED BY: building the hours from consumption only — hour 9 went
+// missing and the payload read as drawn every hour it ran.
+func TestDemandHoursCountsRunningHoursWithNoDraw(t *testing.T) {
+	got := DemandHours(
+		[]DemandConsumption{{Station: "ALN-1", Hour: fcHour(8), UOP: 12}},
+		[]StyleHour{
+			{Station: "ALN-1", Hour: fcHour(8), StyleID: 4, Parts: 12},
+			{Station: "ALN-1", Hour: fcHour(9), StyleID: 4, Parts: 10},
+			{Station: "WLD-2", Hour: fcHour(10), StyleID: 7, Parts: 30},
+		}, fcShift)
+	want := []DemandHour{
+		{Hour: fcHour(8), StyleID: 4, Shift: "Day", UOP: 12},
+		{Hour: fcHour(9), StyleID: 4, Shift: "Day", UOP: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("hours = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("hour %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestDemandHoursStyleIsTheMostMade: the hour's style is the one the
+// consumers made most of, summed across them, the lower id on a tie; an hour
+// drawn with no ticks is style 0.
+//
+// VERIFIED RED BY: overwriting a style's parts per station instead of
+// summing them across consumers — hour 8 came back as style 2.
+func TestDemandHoursStyleIsTheMostMade(t *testing.T) {
+	got := DemandHours(
+		[]DemandConsumption{
+			{Station: "ALN-1", Hour: fcHour(8), UOP: 5},
+			{Station: "ALN-2", Hour: fcHour(8), UOP: 5},
+			{Station: "ALN-1", Hour: fcHour(15), UOP: 3},
+		},
+		[]StyleHour{
+			{Station: "ALN-1", Hour: fcHour(8), StyleID: 2, Parts: 6},
+			{Station: "ALN-1", Hour: fcHour(8), StyleID: 3, Parts: 4},
+			{Station: "ALN-2", Hour: fcHour(8), StyleID: 3, Parts: 4},
+			{Station: "ALN-1", Hour: fcHour(9), StyleID: 6, Parts: 5},
+			{Station: "ALN-2", Hour: fcHour(9), StyleID: 5, Parts: 5},
+		}, fcShift)
+	if len(got) != 3 {
+		t.Fatalf("hours = %+v, want 8, 9 and 15", got)
+	}
+	if got[0].StyleID != 3 || got[0].UOP != 10 {
+		t.Errorf("hour 8 = %+v, want style 3 (8 parts over 6) drawing 10", got[0])
+	}
+	if got[1].StyleID != 5 {
+		t.Errorf("hour 9 = %+v, want style 5 on the tie", got[1])
+	}
+	if got[2].StyleID != 0 || got[2].Shift != "Swing" {
+		t.Errorf("hour 15 = %+v, want unknown style on Swing", got[2])
+	}
+}
+
+// TestThresholdForDemandNormal: 30 UOP/h, σ 6, a two-hour lead at 95% is
+// 60 + 1.645·6·√2 = 73.96 → 74, 14 of it safety stock.
+//
+// VERIFIED RED BY: scaling σ by L instead of √L — the threshold read 80.
+func TestThresholdForDemandNormal(t *testing.T) {
+	got := ThresholdForDemand(30, 6, 2, 0.95)
+	if got.Method != ForecastNormal || got.Threshold != 74 || got.SafetyStock != 14 {
+		t.Fatalf("threshold = %+v, want normal 74 with 14 safety", got)
+	}
+}
+
+// TestThresholdForDemandPoissonAtLowVolume: 2 UOP/h over two hours is a lead
+// demand of 4, and P(Poisson(4) ≤ 8) is the first past 95%.
+//
+// VERIFIED RED BY: dropping the low-volume branch — the normal curve gave 7,
+// which covers 94.9%.
+func TestThresholdForDemandPoissonAtLowVolume(t *testing.T) {
+	got := ThresholdForDemand(2, 1, 2, 0.95)
+	if got.Method != ForecastPoisson || got.Threshold != 8 || got.SafetyStock != 4 {
+		t.Fatalf("threshold = %+v, want poisson 8 with 4 safety", got)
+	}
+}
+
+// TestThresholdForDemandLumpyStaysNormal: the same volume drawn in lumps (σ 4
+// against a mean of 2) is too dispersed for Poisson and keeps its σ.
+//
+// VERIFIED RED BY: removing the dispersion guard — Poisson's 8 came back,
+// well short of the 14 the lumps call for.
+func TestThresholdForDemandLumpyStaysNormal(t *testing.T) {
+	got := ThresholdForDemand(2, 4, 2, 0.95)
+	if got.Method != ForecastNormal || got.Threshold != 14 {
+		t.Fatalf("threshold = %+v, want normal 14", got)
+	}
+}
+
+// TestThresholdForDemandNoDemand: nothing drawn is a threshold of zero, not
+// a safety stock on nothing.
+func TestThresholdForDemandNoDemand(t *testing.T) {
+	if got := ThresholdForDemand(0, 0, 2, 0.95); got.Threshold != 0 || got.SafetyStock != 0 {
+		t.Fatalf("threshold = %+v, want zero", got)
+	}
+}
+
+// TestForecastDemandGroupsAndInterval: hours group by style and shift, the
+// heaviest threshold leads, and the interval brackets the suggestion — a
+// single hour has none.
+//
+// VERIFIED RED BY: computing Low and High from σ instead of σ/√n — the
+// interval on the 40-hour group widened to 54–86.
+func TestForecastDemandGroupsAndInterval(t *testing.T) {
+	var hours []DemandHour
+	for i := 0; i < 40; i++ {
+		hours = append(hours, DemandHour{Hour: fcHour(0).Add(time.Duration(i) * time.Hour), StyleID: 4, Shift: "Day", UOP: int64(26 + 8*(i%2))})
+	}
+	hours = append(hours, DemandHour{Hour: fcHour(0), StyleID: 9, Shift: "Swing", UOP: 5})
+	got := ForecastDemand(hours, 2, 0.95)
+	if len(got) != 2 {
+		t.Fatalf("groups = %+v, want style 4 Day and style 9 Swing", got)
+	}
+	g := got[0]
+	if g.StyleID != 4 || g.Shift != "Day" || g.Hours != 40 || g.MeanPerHour != 30 || g.Confidence != "MEDIUM" {
+		t.Fatalf("first group = %+v, want style 4 Day, 40 hours at 30/h, MEDIUM", g)
+	}
+	if !(g.Low < g.Threshold && g.Threshold < g.High) || g.High-g.Low > 6 {
+		t.Errorf("interval = %d–%d around %d, want a tight bracket", g.Low, g.High, g.Threshold)
+	}
+	one := got[1]
+	if one.Hours != 1 || one.Low != one.Threshold || one.High != one.Threshold || one.Confidence != "LOW" {
+		t.Errorf("single-hour group = %+v, want no interval and LOW", one)
+	}
+}
+
+// TestNormalQuantile pins Φ⁻¹ at the service levels the page offers.
+//
+// VERIFIED RED BY: swapping the tail branches' sign — 0.99 read −2.326.
+func TestNormalQuantile(t *testing.T) {
+	for _, c := range []struct{ p, want float64 }{
+		{0.5, 0}, {0.9, 1.281552}, {0.95, 1.644854}, {0.975, 1.959964}, {0.99, 2.326348}, {0.01, -2.326348},
+	} {
+		if got := NormalQuantile(c.p); math.Abs(got-c.want) > 1e-5 {
+			t.Errorf("NormalQuantile(%v) = %v, want %v", c.p, got, c.want)
+		}
+	}
+}
+
+// TestPoissonQuantile pins the low-volume quantile.
+//
+// VERIFIED RED BY: leaving P(0) out of the sum — a mean of 0.05 at 95%
+// never got there and ran to the loop's cap.
+func TestPoissonQuantile(t *testing.T) {
+	for _, c := range []struct {
+		mean, p float64
+		want    int
+	}{
+		{4, 0.95, 8}, {4, 0.5, 4}, {0.05, 0.95, 0}, {0, 0.95, 0},
+	} {
+		if got := PoissonQuantile(c.mean, c.p); got != c.want {
+			t.Errorf("PoissonQuantile(%v, %v) = %d, want %d", c.mean, c.p, got, c.want)
+		}
+	}
+}
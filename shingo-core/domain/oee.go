@@ -0,0 +1,302 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// oee.go — overall equipment effectiveness per cell, per shift and per day.
+//
+// The queries live in store/oee. Everything here is a PURE FUNCTION of
+// (window, downtime, material waits, production, cycle times, scrap): no
+// database, no clock. The rules that are easy to get wrong are the interval
+// arithmetic and the absences, and both have to be testable without Postgres.
+//
+// ── THE THREE FACTORS ────────────────────────────────────────────────────────
+//
+//	Availability = run time ÷ planned time
+//	Performance  = Σ(engineered cycle × parts) ÷ run time, per style
+//	Quality      = (parts − scrap) ÷ parts
+//
+// Planned time is the shift window (up to now, for the shift still running).
+// Run time is planned time less downtime_events outages clipped to it.
+//
+// ── ABSENCE IS NOT 100% ──────────────────────────────────────────────────────
+//
+// Performance needs an engineered cycle for EVERY style that made parts in the
+// window. A style with parts and no cycle is not skipped: skipping it would
+// rate the cell on the styles somebody happened to key in, and a cell running
+// mostly unkeyed styles would look as fast as its best-documented one. The
+// factor is reported as not computable and the reason names how many parts
+// had no cycle.
+//
+// Quality needs scrap to have been REPORTED. No scrap rows is "not reported",
+// never "no scrap", and OEE is only the product of three real factors —
+// availability × performance is carried beside it for the cells that do not
+// report scrap, under its own name.
+//
+// ── STARVATION IS ATTRIBUTED, NOT SUBTRACTED ─────────────────────────────────
+//
+// Downtime that overlaps a demand episode waiting on material at the same cell
+// is starvation: the cell was down while ShinGo owed it a delivery. It still
+// counts against availability — the line did not run — but it is reported as
+// its own minutes, with the availability the cell would have had without it,
+// so a starved cell and a broken one do not read the same.
+
+// OEEInterval is a half-open [Start, End) span. A zero End is still open and
+// runs to the end of whatever window it is clipped to.
+type OEEInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// OEEInput is everything one cell's figure for one window is computed from.
+type OEEInput struct {
+	Window OEEInterval
+
+	// Downtime is the cell's outages; MaterialWaits its demand episodes waiting
+	// on material. Either may overlap the window only partly, or each other.
+	Downtime      []OEEInterval
+	MaterialWaits []OEEInterval
+
+	// Parts is good-count ticks per style in the window.
+	Parts map[int64]int64
+
+	// IdealCycle is the engineered cycle per style. Missing or non-positive is
+	// "not set".
+	IdealCycle map[int64]time.Duration
+
+	// Scrap is reported scrap per style. nil means none was reported in the
+	// window — not zero scrap.
+	Scrap map[int64]int64
+}
+
+// OEEFigures is one cell's result for one window.
+//
+// The minute and part fields are the raw terms, kept so figures for several
+// windows can be summed (CombineOEE) and the ratios recomputed, rather than
+// averaging ratios over windows of different lengths. Every Have flag is
+// load-bearing in the same sense as CycleStats'.
+type OEEFigures struct {
+	PlannedMinutes  float64 `json:"planned_minutes"`
+	DowntimeMinutes float64 `json:"downtime_minutes"`
+	// StarvedMinutes is the part of DowntimeMinutes that overlapped a material
+	// wait. Never more than DowntimeMinutes.
+	StarvedMinutes float64 `json:"starved_minutes"`
+	RunMinutes     float64 `json:"run_minutes"`
+
+	Parts int64 `json:"parts"`
+	// IdealMinutes is Σ(engineered cycle × parts) over the styles that have a
+	// cycle; UncycledParts counts the parts of styles that do not.
+	IdealMinutes  float64 `json:"ideal_minutes"`
+	UncycledParts int64   `json:"uncycled_parts"`
+
+	Scrap         int64 `json:"scrap"`
+	ScrapReported bool  `json:"scrap_reported"`
+
+	HaveAvailability bool    `json:"have_availability"`
+	Availability     float64 `json:"availability"`
+	// AvailabilityExStarvation is availability with the starved minutes given
+	// back — what the cell would have had if material had been there.
+	AvailabilityExStarvation float64 `json:"availability_ex_starvation"`
+
+	HavePerformance   bool    `json:"have_performance"`
+	Performance       float64 `json:"performance"`
+	PerformanceReason string  `json:"performance_reason,omitempty"`
+
+	HaveQuality   bool    `json:"have_quality"`
+	Quality       float64 `json:"quality"`
+	QualityReason string  `json:"quality_reason,omitempty"`
+
+	// AvailabilityPerformance is A × P, reported whenever both are, for the
+	// cells that report no scrap.
+	HaveAvailabilityPerformance bool    `json:"have_availability_performance"`
+	AvailabilityPerformance     float64 `json:"availability_performance"`
+
+	HaveOEE bool    `json:"have_oee"`
+	OEE     float64 `json:"oee"`
+}
+
+// ComputeOEE computes one cell's figures for one window.
+func ComputeOEE(in OEEInput) OEEFigures {
+	var f OEEFigures
+	w := in.Window
+	if !w.End.After(w.Start) {
+		return f.finish()
+	}
+	f.PlannedMinutes = w.End.Sub(w.Start).Minutes()
+
+	down := clipIntervals(in.Downtime, w)
+	f.DowntimeMinutes = totalMinutes(down)
+	f.StarvedMinutes = totalMinutes(intersectIntervals(down, clipIntervals(in.MaterialWaits, w)))
+
+	for style, n := range in.Parts {
+		if n <= 0 {
+			continue
+		}
+		f.Parts += n
+		if c := in.IdealCycle[style]; c > 0 {
+			f.IdealMinutes += c.Minutes() * float64(n)
+		} else {
+			f.UncycledParts += n
+		}
+	}
+	if in.Scrap != nil {
+		f.ScrapReported = true
+		for _, n := range in.Scrap {
+			f.Scrap += n
+		}
+	}
+	return f.finish()
+}
+
+// CombineOEE sums several windows' figures — a day's shifts — and recomputes
+// the ratios from the summed terms.
+//
+// Scrap counts as reported for the sum only if every window that made parts
+// reported it. One shift keying scrap does not vouch for another that did
+// not: its parts would enter the denominator as zero scrap, which is the
+// absent-as-perfect reading the section above rules out.
+func CombineOEE(parts ...OEEFigures) OEEFigures {
+	var f OEEFigures
+	var anyReported bool
+	var unreported int64
+	for _, p := range parts {
+		f.PlannedMinutes += p.PlannedMinutes
+		f.DowntimeMinutes += p.DowntimeMinutes
+		f.StarvedMinutes += p.StarvedMinutes
+		f.Parts += p.Parts
+		f.IdealMinutes += p.IdealMinutes
+		f.UncycledParts += p.UncycledParts
+		f.Scrap += p.Scrap
+		if p.ScrapReported {
+			anyReported = true
+		} else {
+			unreported += p.Parts
+		}
+	}
+	f.ScrapReported = anyReported && unreported == 0
+	f = f.finish()
+	if anyReported && unreported > 0 {
+		f.QualityReason = fmt.Sprintf("%d part(s) made in windows with no scrap reported", unreported)
+	}
+	return f
+}
+
+// finish derives run time and the ratios from the raw terms.
+func (f OEEFigures) finish() OEEFigures {
+	f.RunMinutes = f.PlannedMinutes - f.DowntimeMinutes
+	if f.RunMinutes < 0 {
+		f.RunMinutes = 0
+	}
+
+	if f.PlannedMinutes > 0 {
+		f.HaveAvailability = true
+		f.Availability = f.RunMinutes / f.PlannedMinutes
+		f.AvailabilityExStarvation = (f.RunMinutes + f.StarvedMinutes) / f.PlannedMinutes
+	}
+
+	switch {
+	case f.PlannedMinutes <= 0:
+		f.PerformanceReason = "no planned time in this window"
+	case f.UncycledParts > 0:
+		f.PerformanceReason = fmt.Sprintf("%d part(s) made on styles with no engineered cycle time", f.UncycledParts)
+	case f.Parts == 0:
+		f.PerformanceReason = "no parts counted in this window"
+	case f.RunMinutes <= 0:
+		f.PerformanceReason = "the cell was down for the whole window"
+	default:
+		f.HavePerformance = true
+		f.Performance = f.IdealMinutes / f.RunMinutes
+	}
+
+	switch {
+	case !f.ScrapReported:
+		f.QualityReason = "no scrap reported for this cell in this window"
+	case f.Parts == 0:
+		f.QualityReason = "scrap reported but no parts counted"
+	default:
+		f.HaveQuality = true
+		good := f.Parts - f.Scrap
+		if good < 0 {
+			good = 0
+		}
+		f.Quality = float64(good) / float64(f.Parts)
+	}
+
+	if f.HaveAvailability && f.HavePerformance {
+		f.HaveAvailabilityPerformance = true
+		f.AvailabilityPerformance = f.Availability * f.Performance
+		if f.HaveQuality {
+			f.HaveOEE = true
+			f.OEE = f.AvailabilityPerformance * f.Quality
+		}
+	}
+	return f
+}
+
+// clipIntervals clips each interval to w, closing open ones at w.End and
+// dropping what falls outside, then merges overlaps. Downtime rows can
+// overlap — two PLCs at one station reporting the same stop — and an unmerged
+// pair would count the same minutes twice.
+func clipIntervals(in []OEEInterval, w OEEInterval) []OEEInterval {
+	out := make([]OEEInterval, 0, len(in))
+	for _, iv := range in {
+		end := iv.End
+		if end.IsZero() || end.After(w.End) {
+			end = w.End
+		}
+		start := iv.Start
+		if start.Before(w.Start) {
+			start = w.Start
+		}
+		if end.After(start) {
+			out = append(out, OEEInterval{Start: start, End: end})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	merged := out[:0]
+	for _, iv := range out {
+		if n := len(merged); n > 0 && !iv.Start.After(merged[n-1].End) {
+			if iv.End.After(merged[n-1].End) {
+				merged[n-1].End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// intersectIntervals is the overlap of two sorted, merged interval lists.
+func intersectIntervals(a, b []OEEInterval) []OEEInterval {
+	var out []OEEInterval
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if end.After(start) {
+			out = append(out, OEEInterval{Start: start, End: end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return out
+}
+
+func totalMinutes(in []OEEInterval) float64 {
+	var d time.Duration
+	for _, iv := range in {
+		d += iv.End.Sub(iv.Start)
+	}
+	return d.Minutes()
+}
@@ -0,0 +1,243 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// oee_test.go — the enforcement half of oee.go. Same contract as
+// cycle_time_test.go: each test names the mutation it was verified red by.
+
+func oeeAt(min int) time.Time { return t0.Add(time.Duration(min) * time.Minute) }
+
+func span(from, to int) OEEInterval { return OEEInterval{Start: oeeAt(from), End: oeeAt(to)} }
+
+// oeeShift is an 8-hour window from t0.
+var oeeShift = span(0, 480)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+// TestOEEHappyPath pins the three factors and their product on numbers small
+// enough to check by hand: 480 planned, 48 down, 432 run; 400 parts at 60 s
+// ideal is 400 ideal minutes; 20 scrap.
+//
+// VERIFIED RED BY: dividing performance by planned instead of run minutes —
+// 0.833 instead of 0.926.
+func TestOEEHappyPath(t *testing.T) {
+	f := ComputeOEE(OEEInput{
+		Window:     oeeShift,
+		Downtime:   []OEEInterval{span(60, 108)},
+		Parts:      map[int64]int64{1: 400},
+		IdealCycle: map[int64]time.Duration{1: time.Minute},
+		Scrap:      map[int64]int64{1: 20},
+	})
+	if !f.HaveAvailability || !near(f.Availability, 432.0/480) {
+		t.Errorf("availability = %v (have %v), want 0.9", f.Availability, f.HaveAvailability)
+	}
+	if !f.HavePerformance || !near(f.Performance, 400.0/432) {
+		t.Errorf("performance = %v (have %v), want 400/432 — ideal time over RUN time", f.Performance, f.HavePerformance)
+	}
+	if !f.HaveQuality || !near(f.Quality, 380.0/400) {
+		t.Errorf("quality = %v (have %v), want 0.95", f.Quality, f.HaveQuality)
+	}
+	if !f.HaveOEE || !near(f.OEE, f.Availability*f.Performance*f.Quality) {
+		t.Errorf("oee = %v (have %v), want A×P×Q", f.OEE, f.HaveOEE)
+	}
+}
+
+// TestOEEPerformanceIsPerStyle. A fast style and a slow style at one cell
+// must each be rated against their own engineered cycle.
+//
+// VERIFIED RED BY: using the first style's cycle for every part — ideal 300
+// minutes instead of 200.
+func TestOEEPerformanceIsPerStyle(t *testing.T) {
+	f := ComputeOEE(OEEInput{
+		Window:     oeeShift,
+		Parts:      map[int64]int64{1: 100, 2: 200},
+		IdealCycle: map[int64]time.Duration{1: 30 * time.Second, 2: 45 * time.Second},
+	})
+	if !near(f.IdealMinutes, 50+150) {
+		t.Errorf("ideal minutes = %v, want 200 (100×0.5 + 200×0.75)", f.IdealMinutes)
+	}
+	if !near(f.Performance, 200.0/480) {
+		t.Errorf("performance = %v, want 200/480", f.Performance)
+	}
+}
+
+// TestOEEStyleWithoutCycleIsNotSkipped. Skipping an unkeyed style would rate
+// the cell on the styles somebody keyed in, and the figure would look good for
+// exactly the cells nobody documented.
+//
+// VERIFIED RED BY: `continue` on a missing cycle instead of counting the parts
+// as uncycled — performance came back 0.104 with HavePerformance true.
+func TestOEEStyleWithoutCycleIsNotSkipped(t *testing.T) {
+	f := ComputeOEE(OEEInput{
+		Window:     oeeShift,
+		Parts:      map[int64]int64{1: 50, 2: 900},
+		IdealCycle: map[int64]time.Duration{1: time.Minute},
+		Scrap:      map[int64]int64{},
+	})
+	if f.HavePerformance {
+		t.Fatalf("performance = %v — 900 parts had no engineered cycle, the factor is not computable", f.Performance)
+	}
+	if f.UncycledParts != 900 || !strings.Contains(f.PerformanceReason, "900") {
+		t.Errorf("uncycled = %d, reason %q — want 900 named in the reason", f.UncycledParts, f.PerformanceReason)
+	}
+	if f.HaveOEE || f.HaveAvailabilityPerformance {
+		t.Error("no OEE and no A×P without performance")
+	}
+}
+
+// TestOEEUnreportedScrapIsNotPerfectQuality. nil scrap is "not reported"; an
+// empty map is "reported, none".
+//
+// VERIFIED RED BY: treating nil as an empty map — quality 1.0 and an OEE for a
+// cell that has never reported scrap.
+func TestOEEUnreportedScrapIsNotPerfectQuality(t *testing.T) {
+	in := OEEInput{
+		Window:     oeeShift,
+		Parts:      map[int64]int64{1: 480},
+		IdealCycle: map[int64]time.Duration{1: time.Minute},
+	}
+	f := ComputeOEE(in)
+	if f.HaveQuality || f.HaveOEE {
+		t.Fatalf("quality %v / oee %v reported with no scrap reported", f.Quality, f.OEE)
+	}
+	if !f.HaveAvailabilityPerformance || !near(f.AvailabilityPerformance, 1) {
+		t.Errorf("A×P = %v (have %v), want 1 — it is still reported without scrap",
+			f.AvailabilityPerformance, f.HaveAvailabilityPerformance)
+	}
+
+	in.Scrap = map[int64]int64{}
+	f = ComputeOEE(in)
+	if !f.HaveQuality || !near(f.Quality, 1) || !f.HaveOEE {
+		t.Errorf("reported-zero scrap: quality %v (have %v), oee have %v — want 1, true, true",
+			f.Quality, f.HaveQuality, f.HaveOEE)
+	}
+}
+
+// TestOEEDowntimeIsClippedAndMerged. An outage that started before the shift,
+// one still open, and two PLCs reporting the same stop.
+//
+// VERIFIED RED BY: summing raw durations without the merge — 90 down minutes
+// instead of 60 (the duplicate 30 counted twice).
+func TestOEEDowntimeIsClippedAndMerged(t *testing.T) {
+	f := ComputeOEE(OEEInput{
+		Window: oeeShift,
+		Downtime: []OEEInterval{
+			span(-30, 10),       // 10 inside the window
+			span(100, 130),      // overlaps the next:
+			span(110, 140),      // 40 between them, not 60
+			{Start: oeeAt(470)}, // open: runs to 480, 10
+			span(500, 520),      // outside
+		},
+	})
+	if !near(f.DowntimeMinutes, 60) {
+		t.Errorf("downtime = %v, want 60 (10 + 40 + 10)", f.DowntimeMinutes)
+	}
+	if !near(f.RunMinutes, 420) {
+		t.Errorf("run = %v, want 420", f.RunMinutes)
+	}
+}
+
+// TestOEEStarvationIsTheOverlapOnly. Downtime while material was owed is
+// starvation; downtime outside any material wait is not, and a material wait
+// while the cell ran is not downtime at all.
+//
+// VERIFIED RED BY: counting the whole downtime interval as starved when it
+// touched a wait — 60 starved instead of 20.
+func TestOEEStarvationIsTheOverlapOnly(t *testing.T) {
+	f := ComputeOEE(OEEInput{
+		Window:        oeeShift,
+		Downtime:      []OEEInterval{span(100, 160)},
+		MaterialWaits: []OEEInterval{span(140, 200), span(300, 340)},
+	})
+	if !near(f.StarvedMinutes, 20) {
+		t.Errorf("starved = %v, want 20 (the 140–160 overlap)", f.StarvedMinutes)
+	}
+	if !near(f.Availability, 420.0/480) {
+		t.Errorf("availability = %v — starvation still counts against it", f.Availability)
+	}
+	if !near(f.AvailabilityExStarvation, 440.0/480) {
+		t.Errorf("availability ex starvation = %v, want 440/480", f.AvailabilityExStarvation)
+	}
+}
+
+// TestOEEOverlappingWaitsDoNotDoubleCountStarvation. Two consume episodes open
+// at once (two parts short) over one outage is one starvation, not two.
+//
+// VERIFIED RED BY: intersecting downtime with each wait separately and summing
+// — 60 starved minutes from a 30-minute outage.
+func TestOEEOverlappingWaitsDoNotDoubleCountStarvation(t *testing.T) {
+	f := ComputeOEE(OEEInput{
+		Window:        oeeShift,
+		Downtime:      []OEEInterval{span(100, 130)},
+		MaterialWaits: []OEEInterval{span(90, 140), {Start: oeeAt(95)}},
+	})
+	if !near(f.StarvedMinutes, 30) {
+		t.Errorf("starved = %v, want 30 — never more than the downtime", f.StarvedMinutes)
+	}
+}
+
+// TestCombineOEERecomputesFromTerms. A day is its shifts' summed terms, not
+// the mean of their ratios — a 2-hour overtime shift at 50% must not weigh the
+// same as an 8-hour shift at 100%.
+//
+// VERIFIED RED BY: averaging the shifts' availabilities — 0.75 instead of 0.9.
+func TestCombineOEERecomputesFromTerms(t *testing.T) {
+	a := ComputeOEE(OEEInput{Window: oeeShift})
+	b := ComputeOEE(OEEInput{Window: span(480, 600), Downtime: []OEEInterval{span(480, 540)}})
+	day := CombineOEE(a, b)
+	if !near(day.PlannedMinutes, 600) || !near(day.DowntimeMinutes, 60) {
+		t.Fatalf("day terms = %v planned / %v down, want 600 / 60", day.PlannedMinutes, day.DowntimeMinutes)
+	}
+	if !near(day.Availability, 0.9) {
+		t.Errorf("day availability = %v, want 0.9", day.Availability)
+	}
+}
+
+// TestCombineOEEMixedScrapReportingHasNoQuality. Days shift reported its
+// scrap, nights did not. Nights' parts are not zero scrap, so the day has no
+// quality — and no OEE — while a shift that made nothing does not spoil it.
+//
+// VERIFIED RED BY: OR-ing ScrapReported across shifts — the day read quality
+// 0.99 on 960 parts of which only 480 had scrap keyed.
+func TestCombineOEEMixedScrapReportingHasNoQuality(t *testing.T) {
+	cycle := map[int64]time.Duration{1: time.Minute}
+	days := ComputeOEE(OEEInput{Window: oeeShift, Parts: map[int64]int64{1: 480}, IdealCycle: cycle,
+		Scrap: map[int64]int64{1: 10}})
+	nights := ComputeOEE(OEEInput{Window: span(480, 960), Parts: map[int64]int64{1: 480}, IdealCycle: cycle})
+	idle := ComputeOEE(OEEInput{Window: span(960, 1440)})
+
+	day := CombineOEE(days, nights, idle)
+	if day.HaveQuality || day.HaveOEE {
+		t.Fatalf("day quality %v (have %v), oee have %v — want none with one shift unreported",
+			day.Quality, day.HaveQuality, day.HaveOEE)
+	}
+	if !strings.Contains(day.QualityReason, "480 part(s)") {
+		t.Errorf("quality reason = %q, want it to count nights' 480 parts", day.QualityReason)
+	}
+	if !day.HaveAvailabilityPerformance {
+		t.Error("A×P dropped with quality; it does not depend on scrap")
+	}
+
+	day = CombineOEE(days, idle)
+	if !day.HaveQuality || !near(day.Quality, 470.0/480) {
+		t.Errorf("reported shift plus an idle one: quality %v (have %v), want %v",
+			day.Quality, day.HaveQuality, 470.0/480)
+	}
+}
+
+// TestOEEEmptyWindowReportsNothing. A shift that has not started yet has no
+// planned time; every factor is absent, none is zero-and-present.
+//
+// VERIFIED RED BY: removing the PlannedMinutes guard on availability — NaN
+// reported as a present figure.
+func TestOEEEmptyWindowReportsNothing(t *testing.T) {
+	f := ComputeOEE(OEEInput{Window: span(60, 60)})
+	if f.HaveAvailability || f.HavePerformance || f.HaveQuality || f.HaveOEE {
+		t.Errorf("empty window reported a factor: %+v", f)
+	}
+}
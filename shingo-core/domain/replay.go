@@ -0,0 +1,366 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"shingo/protocol"
+)
+
+// replay.go — the plant as it was: where each robot stood, what state each
+// order was in, where each bin sat and which slots were held, across a window
+// in the past. Pure functions of what store/replay reads; the page scrubs the
+// result without asking the server again.
+//
+// ── WHERE EACH TRACK COMES FROM ──────────────────────────────────────────────
+//
+// Robots: robot_confidence_samples. The write rule stores a row when a robot
+// moves past the dead band or its localization changes, not every poll, so a
+// robot holds its last pose until the next row — that is what a parked robot
+// looks like, not a gap. Raw samples are kept for robot_confidence's
+// raw_retention_days; a window older than that replays orders and bins with no
+// robots on the floor.
+//
+// Orders: order_history, a row per transition. Before an order's first row it
+// is pending from created_at, which is what every order is born as.
+//
+// Bins: the "payload=… node=…" rows the BinUpdated subscriber appends to
+// audit_log — every bin event, so each one says where the bin was and what it
+// held at that moment. A bin with no row before the window and none since is
+// where the bins table says it is now; a bin with rows only after the window
+// is left off rather than guessed at.
+//
+// Slot holds: DERIVED, not read. The reservations table keeps only live rows
+// and a released hold is deleted, so nothing records when a slot was held. An
+// order holds its delivery node from when the destination was chosen —
+// destination_resolved_at when intake stamped one, created_at otherwise — to
+// its terminal transition. That is the claim the lane map shows as reserved
+// (nodes.claimed_by), without the moments between a terminal and its release.
+
+// MaxReplayWindow is the longest window one replay reads. Past it the robot
+// tracks are thinned too far to follow a robot between two nodes.
+const MaxReplayWindow = 8 * time.Hour
+
+// replayFrames is how many poses a robot's track is thinned to across a window,
+// at most.
+const replayFrames = 1800
+
+// ReplayStep is the spacing a window's robot tracks are thinned to: the
+// window over replayFrames, to the second, and never finer than the 2 s the
+// fleet is polled at — below that every bucket holds one sample anyway.
+func ReplayStep(window time.Duration) time.Duration {
+	step := (window / replayFrames).Truncate(time.Second)
+	if step < 2*time.Second {
+		step = 2 * time.Second
+	}
+	return step
+}
+
+// ReplayPose is one stored robot sample.
+type ReplayPose struct {
+	At         time.Time `json:"at"`
+	X          float64   `json:"x"`
+	Y          float64   `json:"y"`
+	Angle      float64   `json:"angle"`
+	Confidence float64   `json:"confidence"`
+	Station    string    `json:"station,omitempty"`
+	OrderID    int64     `json:"order_id,omitempty"`
+	Blocked    bool      `json:"blocked,omitempty"`
+}
+
+// ReplayTrack is one robot's poses, oldest first. The first may be from
+// before the window: where the robot stood when it opened.
+type ReplayTrack struct {
+	VehicleID string       `json:"vehicle_id"`
+	Poses     []ReplayPose `json:"poses"`
+}
+
+// ReplayTransition is one order_history row.
+type ReplayTransition struct {
+	At     time.Time
+	Status string
+	Code   string
+	Detail string
+}
+
+// ReplayOrderRow is one order open at some point in the window, with its
+// history up to the window's end, oldest first.
+type ReplayOrderRow struct {
+	ID                    int64
+	StationID             string
+	OrderType             string
+	SourceNode            string
+	DeliveryNode          string
+	RobotID               string
+	PayloadCode           string
+	BinID                 *int64
+	CreatedAt             time.Time
+	DestinationResolvedAt *time.Time
+	History               []ReplayTransition
+}
+
+// ReplaySpan is a stretch of time something spent in one state. A nil End is
+// still in it when the window closes.
+type ReplaySpan struct {
+	Status string     `json:"status"`
+	Code   string     `json:"code,omitempty"`
+	Detail string     `json:"detail,omitempty"`
+	Start  time.Time  `json:"start"`
+	End    *time.Time `json:"end,omitempty"`
+	// Terminal is a status the order never leaves: from Start on it is off
+	// the board.
+	Terminal bool `json:"terminal,omitempty"`
+}
+
+// ReplayOrder is one order's states across the window.
+type ReplayOrder struct {
+	ID           int64        `json:"order_id"`
+	StationID    string       `json:"station_id"`
+	OrderType    string       `json:"order_type"`
+	SourceNode   string       `json:"source_node"`
+	DeliveryNode string       `json:"delivery_node"`
+	RobotID      string       `json:"robot_id"`
+	PayloadCode  string       `json:"payload_code"`
+	BinID        *int64       `json:"bin_id,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Spans        []ReplaySpan `json:"spans"`
+}
+
+// ReplayBinObservation is one bin audit row: where the bin was and what it
+// held as of At. Action is the event that wrote it ("moved", "loaded", ...).
+// An empty Node is a bin on no node.
+type ReplayBinObservation struct {
+	At      time.Time
+	Action  string
+	Node    string
+	Payload string
+}
+
+// ReplayBinRow is one bin: where it is now, and its audit rows — the last one
+// before the window, if any, then every one inside it, oldest first.
+type ReplayBinRow struct {
+	BinID          int64
+	Label          string
+	CurrentNode    string
+	CurrentPayload string
+	CreatedAt      time.Time
+	Observed       []ReplayBinObservation
+	// ObservedAfter is a row at or after the window's end. Without rows
+	// before or inside the window, it is what says the bin's present place
+	// is not where it was.
+	ObservedAfter bool
+}
+
+// ReplayBinSpan is a stretch a bin sat at one node holding one payload.
+type ReplayBinSpan struct {
+	Node    string     `json:"node"`
+	Payload string     `json:"payload,omitempty"`
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"`
+}
+
+// ReplayBin is one bin's places across the window.
+type ReplayBin struct {
+	BinID int64           `json:"bin_id"`
+	Label string          `json:"label"`
+	Spans []ReplayBinSpan `json:"spans"`
+}
+
+// ReplayHold is a slot held as an order's destination.
+type ReplayHold struct {
+	Node    string     `json:"node"`
+	OrderID int64      `json:"order_id"`
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"`
+}
+
+// Replay event kinds.
+const (
+	ReplayEventOrder = "order"
+	ReplayEventBin   = "bin"
+)
+
+// ReplayEvent is one thing that happened inside the window, to jump to.
+type ReplayEvent struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"`
+	OrderID int64     `json:"order_id,omitempty"`
+	BinID   int64     `json:"bin_id,omitempty"`
+	// Label is the order's robot or the bin's label.
+	Label  string `json:"label,omitempty"`
+	Status string `json:"status,omitempty"`
+	Node   string `json:"node,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	// Notable is an order that faulted, failed or was cancelled — what a
+	// post-incident review opens the list looking for.
+	Notable bool `json:"notable,omitempty"`
+}
+
+// ReplayTimeline is everything one replay draws.
+type ReplayTimeline struct {
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	StepMS int64         `json:"step_ms"`
+	Robots []ReplayTrack `json:"robots"`
+	Orders []ReplayOrder `json:"orders"`
+	Bins   []ReplayBin   `json:"bins"`
+	Holds  []ReplayHold  `json:"holds"`
+	Events []ReplayEvent `json:"events"`
+}
+
+// BuildReplay assembles a window's timeline. poses is each robot's track as
+// store/replay thinned it; orders and bins are as ReplayOrderRow and
+// ReplayBinRow describe.
+func BuildReplay(from, to time.Time, step time.Duration, poses map[string][]ReplayPose, orders []ReplayOrderRow, bins []ReplayBinRow) ReplayTimeline {
+	tl := ReplayTimeline{
+		From: from, To: to, StepMS: step.Milliseconds(),
+		Robots: []ReplayTrack{}, Orders: []ReplayOrder{}, Bins: []ReplayBin{},
+		Holds: []ReplayHold{}, Events: []ReplayEvent{},
+	}
+	for id, p := range poses {
+		if len(p) > 0 {
+			tl.Robots = append(tl.Robots, ReplayTrack{VehicleID: id, Poses: p})
+		}
+	}
+	sort.Slice(tl.Robots, func(i, j int) bool { return tl.Robots[i].VehicleID < tl.Robots[j].VehicleID })
+
+	for _, o := range orders {
+		spans := ReplayOrderSpans(o, from)
+		if len(spans) == 0 {
+			continue
+		}
+		tl.Orders = append(tl.Orders, ReplayOrder{
+			ID: o.ID, StationID: o.StationID, OrderType: o.OrderType,
+			SourceNode: o.SourceNode, DeliveryNode: o.DeliveryNode, RobotID: o.RobotID,
+			PayloadCode: o.PayloadCode, BinID: o.BinID, CreatedAt: o.CreatedAt, Spans: spans,
+		})
+		if h, ok := ReplaySlotHold(o, from); ok {
+			tl.Holds = append(tl.Holds, h)
+		}
+		for _, r := range o.History {
+			if r.At.Before(from) || !r.At.Before(to) {
+				continue
+			}
+			tl.Events = append(tl.Events, ReplayEvent{
+				At: r.At, Kind: ReplayEventOrder, OrderID: o.ID, Label: o.RobotID,
+				Status: r.Status, Node: o.DeliveryNode, Detail: r.Detail,
+				Notable: replayNotable(r.Status),
+			})
+		}
+	}
+	sort.Slice(tl.Orders, func(i, j int) bool { return tl.Orders[i].ID < tl.Orders[j].ID })
+
+	for _, b := range bins {
+		spans := ReplayBinSpans(b, from, to)
+		if len(spans) > 0 {
+			tl.Bins = append(tl.Bins, ReplayBin{BinID: b.BinID, Label: b.Label, Spans: spans})
+		}
+		for _, ob := range b.Observed {
+			if ob.Action != "moved" || ob.At.Before(from) || !ob.At.Before(to) {
+				continue
+			}
+			tl.Events = append(tl.Events, ReplayEvent{
+				At: ob.At, Kind: ReplayEventBin, BinID: b.BinID, Label: b.Label, Node: ob.Node,
+			})
+		}
+	}
+	sort.Slice(tl.Bins, func(i, j int) bool { return tl.Bins[i].BinID < tl.Bins[j].BinID })
+	sort.SliceStable(tl.Events, func(i, j int) bool { return tl.Events[i].At.Before(tl.Events[j].At) })
+	return tl
+}
+
+// ReplayOrderSpans turns an order's history into the states it was in. A row's
+// state lasts until the next row; before the first row the order is pending
+// from created_at. Spans over before from are dropped, except that an order
+// already terminal when the window opens has none at all — it was never on the
+// board inside it.
+func ReplayOrderSpans(o ReplayOrderRow, from time.Time) []ReplaySpan {
+	var all []ReplaySpan
+	if len(o.History) == 0 || o.CreatedAt.Before(o.History[0].At) {
+		all = append(all, ReplaySpan{Status: string(protocol.StatusPending), Start: o.CreatedAt})
+	}
+	for _, r := range o.History {
+		all = append(all, ReplaySpan{
+			Status: r.Status, Code: r.Code, Detail: r.Detail, Start: r.At,
+			Terminal: protocol.IsTerminal(protocol.Status(r.Status)),
+		})
+	}
+	for i := 0; i+1 < len(all); i++ {
+		end := all[i+1].Start
+		all[i].End = &end
+	}
+	out := all[:0]
+	for _, s := range all {
+		if s.End != nil && !s.End.After(from) {
+			continue
+		}
+		if s.Terminal && !s.Start.After(from) {
+			return nil
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// ReplaySlotHold is the hold an order put on its delivery node, and false for
+// an order with no delivery node or one whose hold ended before from.
+func ReplaySlotHold(o ReplayOrderRow, from time.Time) (ReplayHold, bool) {
+	if o.DeliveryNode == "" {
+		return ReplayHold{}, false
+	}
+	h := ReplayHold{Node: o.DeliveryNode, OrderID: o.ID, Start: o.CreatedAt}
+	if o.DestinationResolvedAt != nil && o.DestinationResolvedAt.After(h.Start) {
+		h.Start = *o.DestinationResolvedAt
+	}
+	for _, r := range o.History {
+		if protocol.IsTerminal(protocol.Status(r.Status)) {
+			end := r.At
+			h.End = &end
+			break
+		}
+	}
+	if h.End != nil && !h.End.After(from) {
+		return ReplayHold{}, false
+	}
+	return h, true
+}
+
+// ReplayBinSpans turns a bin's audit rows into the places it sat. Rows that
+// leave node and payload unchanged — a count, a lock — extend the span they
+// fall in. A span never starts before from.
+func ReplayBinSpans(b ReplayBinRow, from, to time.Time) []ReplayBinSpan {
+	if len(b.Observed) == 0 {
+		if b.ObservedAfter || !b.CreatedAt.Before(to) {
+			return nil
+		}
+		start := from
+		if b.CreatedAt.After(from) {
+			start = b.CreatedAt
+		}
+		return []ReplayBinSpan{{Node: b.CurrentNode, Payload: b.CurrentPayload, Start: start}}
+	}
+	var out []ReplayBinSpan
+	for _, ob := range b.Observed {
+		if n := len(out); n > 0 && out[n-1].Node == ob.Node && out[n-1].Payload == ob.Payload {
+			continue
+		}
+		start := ob.At
+		if start.Before(from) {
+			start = from
+		}
+		if n := len(out); n > 0 {
+			out[n-1].End = &start
+		}
+		out = append(out, ReplayBinSpan{Node: ob.Node, Payload: ob.Payload, Start: start})
+	}
+	return out
+}
+
+// replayNotable is the statuses the event list flags.
+func replayNotable(status string) bool {
+	switch protocol.Status(status) {
+	case protocol.StatusFaulted, protocol.StatusFailed, protocol.StatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// replay_test.go — the enforcement half of replay.go. Same contract as
+// oee_test.go: each test names the mutation it was verified red by. oeeAt is
+// oee_test.go's.
+
+func replayRow(min int, status string) ReplayTransition {
+	return ReplayTransition{At: oeeAt(min), Status: status}
+}
+
+// TestReplayOrderSpansStartPendingAndEndTerminal: an order is pending from
+// created_at until its first history row, each row's state lasts until the
+// next, and the terminal row is flagged so the board drops the order there.
+//
+// VERIFIED RED BY: dropping the pending span — the order appeared at minute 5
+// instead of minute 0.
+func TestReplayOrderSpansStartPendingAndEndTerminal(t *testing.T) {
+	spans := ReplayOrderSpans(ReplayOrderRow{
+		ID: 1, CreatedAt: oeeAt(0),
+		History: []ReplayTransition{replayRow(5, "dispatched"), replayRow(20, "faulted"), replayRow(30, "failed")},
+	}, oeeAt(0))
+	if len(spans) != 4 {
+		t.Fatalf("spans = %+v, want pending, dispatched, faulted, failed", spans)
+	}
+	if spans[0].Status != "pending" || !spans[0].Start.Equal(oeeAt(0)) || !spans[0].End.Equal(oeeAt(5)) {
+		t.Errorf("first span = %+v, want pending 0–5", spans[0])
+	}
+	if spans[2].Status != "faulted" || !spans[2].End.Equal(oeeAt(30)) || spans[2].Terminal {
+		t.Errorf("faulted span = %+v, want 20–30, not terminal", spans[2])
+	}
+	if last := spans[3]; !last.Terminal || last.End != nil {
+		t.Errorf("failed span = %+v, want terminal and open", last)
+	}
+}
+
+// TestReplayOrderSpansDropsWhatEndedBeforeTheWindow: states over before the
+// window opened are not sent, the one the order was in when it opened is, and
+// an order already terminal then has nothing to draw.
+//
+// VERIFIED RED BY: testing Start instead of End against from — the
+// dispatched span the window opens inside was dropped.
+func TestReplayOrderSpansDropsWhatEndedBeforeTheWindow(t *testing.T) {
+	o := ReplayOrderRow{ID: 2, CreatedAt: oeeAt(0), History: []ReplayTransition{
+		replayRow(0, "pending"), replayRow(10, "dispatched"), replayRow(60, "confirmed"),
+	}}
+	spans := ReplayOrderSpans(o, oeeAt(30))
+	if len(spans) != 2 || spans[0].Status != "dispatched" || spans[1].Status != "confirmed" {
+		t.Fatalf("spans = %+v, want dispatched then confirmed", spans)
+	}
+	if got := ReplayOrderSpans(o, oeeAt(90)); got != nil {
+		t.Errorf("order confirmed before the window = %+v, want nothing", got)
+	}
+}
+
+// TestReplaySlotHoldRunsFromResolveToTerminal: the delivery node is held from
+// when intake chose it to the order's terminal row; an order whose hold ended
+// before the window holds nothing in it.
+//
+// VERIFIED RED BY: ignoring DestinationResolvedAt — the hold started at
+// created_at, minute 0.
+func TestReplaySlotHoldRunsFromResolveToTerminal(t *testing.T) {
+	resolved := oeeAt(3)
+	o := ReplayOrderRow{ID: 3, DeliveryNode: "LANE-A-1", CreatedAt: oeeAt(0), DestinationResolvedAt: &resolved,
+		History: []ReplayTransition{replayRow(0, "pending"), replayRow(15, "delivered"), replayRow(18, "confirmed")}}
+	h, ok := ReplaySlotHold(o, oeeAt(0))
+	if !ok || h.Node != "LANE-A-1" || !h.Start.Equal(oeeAt(3)) || h.End == nil || !h.End.Equal(oeeAt(18)) {
+		t.Fatalf("hold = %+v, %v; want LANE-A-1 3–18", h, ok)
+	}
+	if _, ok := ReplaySlotHold(o, oeeAt(20)); ok {
+		t.Error("hold released at 18 returned for a window opening at 20")
+	}
+	if _, ok := ReplaySlotHold(ReplayOrderRow{ID: 4, CreatedAt: oeeAt(0)}, oeeAt(0)); ok {
+		t.Error("order with no delivery node returned a hold")
+	}
+}
+
+// TestReplayBinSpansFollowTheAuditRows: the row before the window places the
+// bin when it opens; a count at the same node and payload does not split the
+// span; a move does.
+//
+// VERIFIED RED BY: not merging unchanged rows — the count split STOR-1 in two.
+func TestReplayBinSpansFollowTheAuditRows(t *testing.T) {
+	spans := ReplayBinSpans(ReplayBinRow{BinID: 7, CreatedAt: oeeAt(-600), Observed: []ReplayBinObservation{
+		{At: oeeAt(-30), Action: "loaded", Node: "STOR-1", Payload: "P1"},
+		{At: oeeAt(10), Action: "counted", Node: "STOR-1", Payload: "P1"},
+		{At: oeeAt(40), Action: "moved", Node: "LINE-2", Payload: "P1"},
+	}}, oeeAt(0), oeeAt(120))
+	if len(spans) != 2 {
+		t.Fatalf("spans = %+v, want STOR-1 then LINE-2", spans)
+	}
+	if spans[0].Node != "STOR-1" || !spans[0].Start.Equal(oeeAt(0)) || !spans[0].End.Equal(oeeAt(40)) {
+		t.Errorf("first span = %+v, want STOR-1 from the window's start to 40", spans[0])
+	}
+	if spans[1].Node != "LINE-2" || spans[1].End != nil {
+		t.Errorf("second span = %+v, want LINE-2, open", spans[1])
+	}
+}
+
+// TestReplayBinSpansWithoutRows: a bin with no audit rows is where it is now
+// for the whole window — unless rows after the window say it has moved since,
+// in which case where it was is not known and it is left off.
+//
+// VERIFIED RED BY: ignoring ObservedAfter — the moved-since bin was drawn at
+// its present node.
+func TestReplayBinSpansWithoutRows(t *testing.T) {
+	still := ReplayBinRow{BinID: 8, CurrentNode: "STOR-2", CreatedAt: oeeAt(-600)}
+	if spans := ReplayBinSpans(still, oeeAt(0), oeeAt(60)); len(spans) != 1 || spans[0].Node != "STOR-2" {
+		t.Errorf("unmoved bin = %+v, want STOR-2 across the window", spans)
+	}
+	moved := still
+	moved.ObservedAfter = true
+	if spans := ReplayBinSpans(moved, oeeAt(0), oeeAt(60)); spans != nil {
+		t.Errorf("bin moved since = %+v, want nothing", spans)
+	}
+}
+
+// TestBuildReplayEventsInsideTheWindow: the event list is the window's
+// transitions and bin moves, oldest first, with the fault flagged; history
+// from before the window is not an event.
+//
+// VERIFIED RED BY: dropping the from check — the pending row at -5 was listed.
+func TestBuildReplayEventsInsideTheWindow(t *testing.T) {
+	tl := BuildReplay(oeeAt(0), oeeAt(60), 2*time.Second, nil,
+		[]ReplayOrderRow{{ID: 9, RobotID: "AMR-1", CreatedAt: oeeAt(-5), History: []ReplayTransition{
+			replayRow(-5, "pending"), replayRow(10, "in_transit"), replayRow(25, "faulted"),
+		}}},
+		[]ReplayBinRow{{BinID: 5, Label: "B5", CreatedAt: oeeAt(-600), Observed: []ReplayBinObservation{
+			{At: oeeAt(15), Action: "moved", Node: "LINE-1"},
+		}}})
+	if len(tl.Events) != 3 {
+		t.Fatalf("events = %+v, want in_transit, bin move, faulted", tl.Events)
+	}
+	if tl.Events[0].Status != "in_transit" || tl.Events[1].Kind != ReplayEventBin || tl.Events[2].Status != "faulted" {
+		t.Errorf("events = %+v, want in_transit, bin move, faulted in that order", tl.Events)
+	}
+	if !tl.Events[2].Notable || tl.Events[0].Notable {
+		t.Errorf("notable flags = %v, %v; want only the fault", tl.Events[0].Notable, tl.Events[2].Notable)
+	}
+}
+
+// TestReplayStep: a half hour and an hour keep the 2 s floor; eight hours
+// thins to 16 s.
+//
+// VERIFIED RED BY: removing the floor — the half hour came back as 1 s.
+func TestReplayStep(t *testing.T) {
+	for _, c := range []struct {
+		window, want time.Duration
+	}{
+		{30 * time.Minute, 2 * time.Second},
+		{time.Hour, 2 * time.Second},
+		{8 * time.Hour, 16 * time.Second},
+	} {
+		if got := ReplayStep(c.window); got != c.want {
+			t.Errorf("ReplayStep(%v) = %v, want %v", c.window, got, c.want)
+		}
+	}
+}
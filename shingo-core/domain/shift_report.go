@@ -0,0 +1,142 @@
+package domain
+
+import "time"
+
+// ShiftReport is one shift's operations summary: what moved, how long it took,
+// where the line stood, what went wrong. Built by store/shiftreports when the
+// shift ends and kept whole — the sections below are stored as written, so a
+// report read a month later says what it said the morning after, even once
+// telemetry retention has pruned the rows it was built from.
+type ShiftReport struct {
+	ID int64 `json:"id"`
+	// Shift is the shift's name from reports.shifts; Day is the plant-local
+	// date (2006-01-02) it started on, so a night shift is filed under the
+	// evening it began.
+	Shift    string `json:"shift"`
+	Day      string `json:"day"`
+	Timezone string `json:"timezone"`
+	// Start and End bound the window, [Start, End).
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"`
+
+	ShiftReportBody
+}
+
+// ShiftReportBody is the computed part of a report — the part stored as one
+// JSON document.
+type ShiftReportBody struct {
+	// Orders is one row per order type that was created or finished in the
+	// shift.
+	Orders []ShiftOrders `json:"orders"`
+	// Delivery is delivery (lead) time per order type, with the all-types row
+	// first under Type "".
+	Delivery []ShiftDelivery `json:"delivery"`
+	// Downtime is minutes down per cell and reason, longest first. Starvation
+	// is here when the cell's PLC reports it as its downtime reason; Core
+	// records no separate starved state.
+	Downtime []ShiftDowntime `json:"downtime"`
+	// Faults is fault count per robot, most first.
+	Faults []ShiftFaults `json:"faults"`
+	// Corrections is inventory corrections per correction type.
+	Corrections []ShiftCorrections `json:"corrections"`
+	// DeadLetters is outbox messages queued in the shift that gave up
+	// retrying; DeadLettersOpen is every dead letter outstanding when the
+	// report was built, whenever it was queued.
+	DeadLetters     int `json:"dead_letters"`
+	DeadLettersOpen int `json:"dead_letters_open"`
+}
+
+// ShiftOrders counts one order type: created in the shift, and each terminal
+// outcome reached in it (an order created last shift and confirmed in this one
+// counts here as confirmed).
+type ShiftOrders struct {
+	Type      string `json:"type"`
+	Created   int64  `json:"created"`
+	Confirmed int64  `json:"confirmed"`
+	Failed    int64  `json:"failed"`
+	Cancelled int64  `json:"cancelled"`
+	Skipped   int64  `json:"skipped"`
+}
+
+// ShiftDelivery is the delivery-time spread of the missions one order type
+// completed in the shift.
+type ShiftDelivery struct {
+	Type       string  `json:"type"`
+	Count      int64   `json:"count"`
+	AvgSeconds float64 `json:"avg_seconds"`
+	P90Seconds float64 `json:"p90_seconds"`
+}
+
+// ShiftDowntime is one cell's downtime for one reason, clipped to the shift.
+type ShiftDowntime struct {
+	Station string  `json:"station"`
+	Reason  string  `json:"reason"`
+	Events  int64   `json:"events"`
+	Minutes float64 `json:"minutes"`
+}
+
+// ShiftFaults is one robot's faults in the shift.
+type ShiftFaults struct {
+	RobotID string `json:"robot_id"`
+	Faults  int64  `json:"faults"`
+	Orders  int64  `json:"orders"`
+}
+
+// ShiftCorrections is one correction type's count and net quantity.
+type ShiftCorrections struct {
+	Type     string `json:"type"`
+	Count    int64  `json:"count"`
+	Quantity int64  `json:"quantity"`
+}
+
+// TotalOrders sums Orders into one row with an empty Type.
+func (b ShiftReportBody) TotalOrders() ShiftOrders {
+	var t ShiftOrders
+	for _, o := range b.Orders {
+		t.Created += o.Created
+		t.Confirmed += o.Confirmed
+		t.Failed += o.Failed
+		t.Cancelled += o.Cancelled
+		t.Skipped += o.Skipped
+	}
+	return t
+}
+
+// DowntimeMinutes sums Downtime.
+func (b ShiftReportBody) DowntimeMinutes() float64 {
+	var m float64
+	for _, d := range b.Downtime {
+		m += d.Minutes
+	}
+	return m
+}
+
+// FaultCount sums Faults.
+func (b ShiftReportBody) FaultCount() int64 {
+	var n int64
+	for _, f := range b.Faults {
+		n += f.Faults
+	}
+	return n
+}
+
+// CorrectionCount sums Corrections.
+func (b ShiftReportBody) CorrectionCount() int64 {
+	var n int64
+	for _, c := range b.Corrections {
+		n += c.Count
+	}
+	return n
+}
+
+// OverallDelivery is the all-types delivery row, zero when nothing delivered.
+func (b ShiftReportBody) OverallDelivery() ShiftDelivery {
+	for _, d := range b.Delivery {
+		if d.Type == "" {
+			return d
+		}
+	}
+	return ShiftDelivery{}
+}
@@ -0,0 +1,317 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"shingo/protocol"
+)
+
+// starvation.go — WHY a cell was starved: for every minute a cell was down
+// while a demand episode at it was waiting on material, which part of the
+// material path it was waiting on.
+//
+// oee.go already finds the starved minutes (downtime ∩ material waits). This
+// file splits them by cause. Pure functions of (window, intervals, per-order
+// status timelines); the queries are store/starvation's and store/oee's.
+//
+// ── WHERE EACH CAUSE COMES FROM ──────────────────────────────────────────────
+//
+// Every cause is read off the episode's CHILD ORDERS' order_history, except
+// edge-offline, which is an edge_stale alert open for the cell's edge:
+//
+//	queued, code waiting_for_material / waiting_for_partner → no inventory
+//	queued, code storage_rearranging / waiting_for_slot     → lane blocked / dig
+//	reshuffling                                             → lane blocked / dig
+//	queued, code fleet_unavailable                          → fleet busy
+//	submitted, dispatched, acknowledged, in_transit         → fleet busy
+//	faulted                                                 → robot fault
+//	staged                                                  → awaiting release
+//	pending, sourcing, queued with no code                  → unclassified
+//
+// queue_code is readable historically ONLY because SetQueueDetail stamps it
+// onto the queued history row; orders.queue_code itself is overwritten in
+// place. A queued row from before that stamp existed has no code and lands in
+// unclassified rather than being guessed at.
+//
+// "Fleet busy" is the whole span from hand-off to arrival — waiting for a robot
+// and the robot driving. The history does not separate the two, and calling
+// the drive "busy" is the conservative reading: it is time the fleet owned.
+//
+// "Awaiting release" is a robot dwelling at a wait. Most of those are station
+// waits (somebody has to press Release); a lane-gate dwell is also staged and
+// is Core's to advance. The history row does not say which, so the label is
+// the common case and the caveat is here.
+//
+// ── ONE CAUSE PER MINUTE ─────────────────────────────────────────────────────
+//
+// Two child orders can be in flight at once, in different states. Every
+// starved minute is given to exactly ONE cause, by precedence, so the Pareto
+// sums to the starved minutes and no minute is counted twice. The order is
+// most-specific-blocker first: an offline edge explains everything under it;
+// a faulted robot or an unreleased one is a named obstacle; a lane or empty
+// inventory is a plant condition; a robot on its way is the fleet doing its
+// job slowly. A minute with material owed and no child order in flight is
+// "no order in flight" — the episode was open and nothing was moving.
+
+// StarvationCause is one bucket a starved minute is attributed to.
+type StarvationCause string
+
+const (
+	StarvationEdgeOffline     StarvationCause = "edge_offline"
+	StarvationRobotFault      StarvationCause = "robot_fault"
+	StarvationAwaitingRelease StarvationCause = "awaiting_release"
+	StarvationLaneBlocked     StarvationCause = "lane_blocked"
+	StarvationNoInventory     StarvationCause = "no_inventory"
+	StarvationFleetBusy       StarvationCause = "fleet_busy"
+	StarvationUnclassified    StarvationCause = "unclassified"
+	StarvationNoOrder         StarvationCause = "no_order"
+)
+
+// starvationPrecedence is the attribution order, first wins.
+var starvationPrecedence = []StarvationCause{
+	StarvationEdgeOffline,
+	StarvationRobotFault,
+	StarvationAwaitingRelease,
+	StarvationLaneBlocked,
+	StarvationNoInventory,
+	StarvationFleetBusy,
+	StarvationUnclassified,
+	StarvationNoOrder,
+}
+
+// StarvationCauses lists every cause in precedence order.
+func StarvationCauses() []StarvationCause {
+	return append([]StarvationCause(nil), starvationPrecedence...)
+}
+
+// Label is the cause's printed name.
+func (c StarvationCause) Label() string {
+	switch c {
+	case StarvationEdgeOffline:
+		return "Edge offline"
+	case StarvationRobotFault:
+		return "Robot fault"
+	case StarvationAwaitingRelease:
+		return "Awaiting release"
+	case StarvationLaneBlocked:
+		return "Lane blocked / dig"
+	case StarvationNoInventory:
+		return "No inventory"
+	case StarvationFleetBusy:
+		return "Fleet busy"
+	case StarvationUnclassified:
+		return "Unclassified"
+	case StarvationNoOrder:
+		return "No order in flight"
+	}
+	return string(c)
+}
+
+func (c StarvationCause) rank() int {
+	for i, p := range starvationPrecedence {
+		if p == c {
+			return i
+		}
+	}
+	return len(starvationPrecedence)
+}
+
+// OrderStatusRow is one order_history row: the status the order entered at
+// At, and the queue code stamped on it (queued rows only).
+type OrderStatusRow struct {
+	At     time.Time
+	Status string
+	Code   string
+}
+
+// CauseSpan is an interval one order spent in a state that maps to a cause.
+type CauseSpan struct {
+	OEEInterval
+	Cause StarvationCause
+}
+
+// CauseOfStatus maps one history row to the cause it stands for, and false
+// for a state that is not a wait on the material path (delivered and every
+// terminal).
+func CauseOfStatus(status, code string) (StarvationCause, bool) {
+	switch protocol.Status(status) {
+	case protocol.StatusQueued:
+		switch protocol.QueueCode(code) {
+		case protocol.QueueWaitingForMaterial, protocol.QueueWaitingForPartner:
+			return StarvationNoInventory, true
+		case protocol.QueueStorageRearranging, protocol.QueueWaitingForSlot:
+			return StarvationLaneBlocked, true
+		case protocol.QueueFleetUnavailable:
+			return StarvationFleetBusy, true
+		}
+		return StarvationUnclassified, true
+	case protocol.StatusPending, protocol.StatusSourcing:
+		return StarvationUnclassified, true
+	case protocol.StatusReshuffling:
+		return StarvationLaneBlocked, true
+	case protocol.StatusSubmitted, protocol.StatusDispatched, protocol.StatusAcknowledged, protocol.StatusInTransit:
+		return StarvationFleetBusy, true
+	case protocol.StatusFaulted:
+		return StarvationRobotFault, true
+	case protocol.StatusStaged:
+		return StarvationAwaitingRelease, true
+	}
+	return "", false
+}
+
+// OrderCauseSpans turns one order's history, oldest first, into the spans it
+// spent in each cause. A row's state lasts until the next row; the last row's
+// lasts until until, unless it is not a wait (delivered, or a terminal).
+func OrderCauseSpans(rows []OrderStatusRow, until time.Time) []CauseSpan {
+	var out []CauseSpan
+	for i, r := range rows {
+		cause, ok := CauseOfStatus(r.Status, r.Code)
+		if !ok {
+			continue
+		}
+		end := until
+		if i+1 < len(rows) {
+			end = rows[i+1].At
+		}
+		if end.After(r.At) {
+			out = append(out, CauseSpan{OEEInterval: OEEInterval{Start: r.At, End: end}, Cause: cause})
+		}
+	}
+	return out
+}
+
+// StarvationInput is one cell's material for one window.
+type StarvationInput struct {
+	Window        OEEInterval
+	Downtime      []OEEInterval
+	MaterialWaits []OEEInterval
+	// EdgeOffline is when the cell's edge was known to be silent.
+	EdgeOffline []OEEInterval
+	// Orders is every child order's cause spans, across the cell's episodes.
+	Orders []CauseSpan
+}
+
+// StarvationAttribution is one cell's downtime for one window, split.
+//
+// DowntimeMinutes = NotMaterialMinutes + Σ Minutes. NotMaterialMinutes is the
+// downtime with no material owed — the cell's own stops, which ShinGo did not
+// cause and this surface does not explain.
+type StarvationAttribution struct {
+	DowntimeMinutes    float64                     `json:"downtime_minutes"`
+	NotMaterialMinutes float64                     `json:"not_material_minutes"`
+	StarvedMinutes     float64                     `json:"starved_minutes"`
+	Minutes            map[StarvationCause]float64 `json:"minutes"`
+}
+
+// AttributeStarvation splits one cell's downtime in one window by cause.
+func AttributeStarvation(in StarvationInput) StarvationAttribution {
+	a := StarvationAttribution{Minutes: make(map[StarvationCause]float64)}
+	w := in.Window
+	if !w.End.After(w.Start) {
+		return a
+	}
+	down := clipIntervals(in.Downtime, w)
+	waits := clipIntervals(in.MaterialWaits, w)
+	offline := clipIntervals(in.EdgeOffline, w)
+	spans := make([]CauseSpan, 0, len(in.Orders))
+	for _, s := range in.Orders {
+		for _, iv := range clipIntervals([]OEEInterval{s.OEEInterval}, w) {
+			spans = append(spans, CauseSpan{OEEInterval: iv, Cause: s.Cause})
+		}
+	}
+
+	// Every boundary inside the downtime cuts it into pieces over which the
+	// set of open waits, offline spans and order states is constant.
+	var cuts []time.Time
+	for _, set := range [][]OEEInterval{down, waits, offline} {
+		for _, iv := range set {
+			cuts = append(cuts, iv.Start, iv.End)
+		}
+	}
+	for _, s := range spans {
+		cuts = append(cuts, s.Start, s.End)
+	}
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+
+	for i := 0; i+1 < len(cuts); i++ {
+		from, to := cuts[i], cuts[i+1]
+		if !to.After(from) || !covers(down, from) {
+			continue
+		}
+		m := to.Sub(from).Minutes()
+		a.DowntimeMinutes += m
+		if !covers(waits, from) {
+			a.NotMaterialMinutes += m
+			continue
+		}
+		a.StarvedMinutes += m
+		a.Minutes[causeAt(from, offline, spans)] += m
+	}
+	return a
+}
+
+// causeAt is the highest-precedence cause holding at t.
+func causeAt(t time.Time, offline []OEEInterval, spans []CauseSpan) StarvationCause {
+	if covers(offline, t) {
+		return StarvationEdgeOffline
+	}
+	best := StarvationNoOrder
+	for _, s := range spans {
+		if !t.Before(s.Start) && t.Before(s.End) && s.Cause.rank() < best.rank() {
+			best = s.Cause
+		}
+	}
+	return best
+}
+
+// covers reports whether t falls in any of the [Start, End) intervals.
+func covers(ivs []OEEInterval, t time.Time) bool {
+	for _, iv := range ivs {
+		if !t.Before(iv.Start) && t.Before(iv.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add folds another window's attribution into a.
+func (a *StarvationAttribution) Add(b StarvationAttribution) {
+	if a.Minutes == nil {
+		a.Minutes = make(map[StarvationCause]float64)
+	}
+	a.DowntimeMinutes += b.DowntimeMinutes
+	a.NotMaterialMinutes += b.NotMaterialMinutes
+	a.StarvedMinutes += b.StarvedMinutes
+	for c, m := range b.Minutes {
+		a.Minutes[c] += m
+	}
+}
+
+// ParetoBar is one cause's bar: its minutes and the cumulative share of the
+// starved minutes up to and including it.
+type ParetoBar struct {
+	Cause      StarvationCause `json:"cause"`
+	Label      string          `json:"label"`
+	Minutes    float64         `json:"minutes"`
+	Cumulative float64         `json:"cumulative"`
+}
+
+// Pareto orders the causes with minutes, most first; ties go by precedence.
+func (a StarvationAttribution) Pareto() []ParetoBar {
+	var out []ParetoBar
+	for _, c := range starvationPrecedence {
+		if m := a.Minutes[c]; m > 0 {
+			out = append(out, ParetoBar{Cause: c, Label: c.Label(), Minutes: m})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Minutes > out[j].Minutes })
+	var cum float64
+	for i := range out {
+		cum += out[i].Minutes
+		if a.StarvedMinutes > 0 {
+			out[i].Cumulative = cum / a.StarvedMinutes
+		}
+	}
+	return out
+}
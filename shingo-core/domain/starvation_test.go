@@ -0,0 +1,201 @@
+package domain
+
+import "testing"
+
+// starvation_test.go — the enforcement half of starvation.go. Same contract
+// as oee_test.go: each test names the mutation it was verified red by. The
+// helpers (oeeAt, span, oeeShift, near) are oee_test.go's.
+
+func statusAt(min int, status, code string) OrderStatusRow {
+	return OrderStatusRow{At: oeeAt(min), Status: status, Code: code}
+}
+
+// TestOrderCauseSpansFollowTheHistory: each row's state lasts until the next
+// row; a delivered order contributes nothing after delivery, and the queue
+// code decides what a queued row was waiting for.
+//
+// VERIFIED RED BY: ending every span at until instead of the next row —
+// the queued span ran to minute 480 and swallowed the fleet span.
+func TestOrderCauseSpansFollowTheHistory(t *testing.T) {
+	spans := OrderCauseSpans([]OrderStatusRow{
+		statusAt(0, "pending", ""),
+		statusAt(2, "queued", "waiting_for_material"),
+		statusAt(30, "dispatched", ""),
+		statusAt(45, "delivered", ""),
+		statusAt(50, "confirmed", ""),
+	}, oeeAt(480))
+	want := []CauseSpan{
+		{OEEInterval: span(0, 2), Cause: StarvationUnclassified},
+		{OEEInterval: span(2, 30), Cause: StarvationNoInventory},
+		{OEEInterval: span(30, 45), Cause: StarvationFleetBusy},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("spans = %+v, want %+v", spans, want)
+	}
+	for i := range want {
+		if spans[i] != want[i] {
+			t.Errorf("span %d = %+v, want %+v", i, spans[i], want[i])
+		}
+	}
+}
+
+// TestOrderCauseSpansLastStateRunsToUntil: an order still staged at the end
+// of the read is awaiting release up to until.
+//
+// VERIFIED RED BY: skipping the last row — the staged span vanished.
+func TestOrderCauseSpansLastStateRunsToUntil(t *testing.T) {
+	spans := OrderCauseSpans([]OrderStatusRow{statusAt(10, "staged", "")}, oeeAt(40))
+	if len(spans) != 1 || spans[0].Cause != StarvationAwaitingRelease || spans[0].OEEInterval != span(10, 40) {
+		t.Fatalf("spans = %+v, want awaiting release 10–40", spans)
+	}
+}
+
+// TestCauseOfStatusQueueCodes pins the queue-code half of the table in the
+// file comment.
+//
+// VERIFIED RED BY: mapping storage_rearranging to no inventory.
+func TestCauseOfStatusQueueCodes(t *testing.T) {
+	for _, c := range []struct {
+		status, code string
+		want         StarvationCause
+	}{
+		{"queued", "waiting_for_material", StarvationNoInventory},
+		{"queued", "waiting_for_partner", StarvationNoInventory},
+		{"queued", "storage_rearranging", StarvationLaneBlocked},
+		{"queued", "waiting_for_slot", StarvationLaneBlocked},
+		{"queued", "fleet_unavailable", StarvationFleetBusy},
+		{"queued", "", StarvationUnclassified},
+		{"reshuffling", "", StarvationLaneBlocked},
+		{"in_transit", "", StarvationFleetBusy},
+		{"faulted", "", StarvationRobotFault},
+		{"staged", "", StarvationAwaitingRelease},
+	} {
+		got, ok := CauseOfStatus(c.status, c.code)
+		if !ok || got != c.want {
+			t.Errorf("CauseOfStatus(%q, %q) = %q, %v; want %q", c.status, c.code, got, ok, c.want)
+		}
+	}
+	for _, s := range []string{"delivered", "confirmed", "failed", "cancelled", "skipped"} {
+		if got, ok := CauseOfStatus(s, ""); ok {
+			t.Errorf("CauseOfStatus(%q) = %q, want not a wait", s, got)
+		}
+	}
+}
+
+// TestStarvationSplitsOnlyStarvedDowntime: downtime outside any material wait
+// is not-material and never reaches the Pareto; inside, each minute goes to
+// the order state holding at that minute.
+//
+// VERIFIED RED BY: attributing the whole downtime, not just its overlap with
+// the wait — no inventory read 30 instead of 20.
+func TestStarvationSplitsOnlyStarvedDowntime(t *testing.T) {
+	a := AttributeStarvation(StarvationInput{
+		Window:        oeeShift,
+		Downtime:      []OEEInterval{span(100, 160)},
+		MaterialWaits: []OEEInterval{span(120, 200)},
+		Orders: []CauseSpan{
+			{OEEInterval: span(110, 140), Cause: StarvationNoInventory},
+			{OEEInterval: span(140, 200), Cause: StarvationFleetBusy},
+		},
+	})
+	if !near(a.DowntimeMinutes, 60) || !near(a.NotMaterialMinutes, 20) || !near(a.StarvedMinutes, 40) {
+		t.Fatalf("down/not-material/starved = %v/%v/%v, want 60/20/40", a.DowntimeMinutes, a.NotMaterialMinutes, a.StarvedMinutes)
+	}
+	if !near(a.Minutes[StarvationNoInventory], 20) || !near(a.Minutes[StarvationFleetBusy], 20) {
+		t.Errorf("minutes = %v, want no inventory 20, fleet busy 20", a.Minutes)
+	}
+}
+
+// TestStarvationCountsEachMinuteOnce: two child orders in flight at once give
+// the minute to the higher-precedence cause, and the causes sum to the
+// starved minutes.
+//
+// VERIFIED RED BY: crediting every cause holding at a minute — the sum read
+// 50 against 30 starved.
+func TestStarvationCountsEachMinuteOnce(t *testing.T) {
+	a := AttributeStarvation(StarvationInput{
+		Window:        oeeShift,
+		Downtime:      []OEEInterval{span(0, 30)},
+		MaterialWaits: []OEEInterval{span(0, 30)},
+		Orders: []CauseSpan{
+			{OEEInterval: span(0, 30), Cause: StarvationFleetBusy},
+			{OEEInterval: span(10, 30), Cause: StarvationRobotFault},
+		},
+	})
+	var sum float64
+	for _, m := range a.Minutes {
+		sum += m
+	}
+	if !near(sum, a.StarvedMinutes) || !near(a.StarvedMinutes, 30) {
+		t.Fatalf("Σ causes = %v, starved = %v, want both 30", sum, a.StarvedMinutes)
+	}
+	if !near(a.Minutes[StarvationRobotFault], 20) || !near(a.Minutes[StarvationFleetBusy], 10) {
+		t.Errorf("minutes = %v, want robot fault 20, fleet busy 10", a.Minutes)
+	}
+}
+
+// TestStarvationEdgeOfflineAndNoOrder: an offline edge outranks whatever the
+// orders say, and a wait with nothing in flight is "no order in flight", not
+// dropped.
+//
+// VERIFIED RED BY: leaving a minute with no order span unattributed — no
+// order read 0.
+func TestStarvationEdgeOfflineAndNoOrder(t *testing.T) {
+	a := AttributeStarvation(StarvationInput{
+		Window:        oeeShift,
+		Downtime:      []OEEInterval{span(0, 60)},
+		MaterialWaits: []OEEInterval{{Start: oeeAt(0)}}, // still open
+		EdgeOffline:   []OEEInterval{span(0, 15)},
+		Orders:        []CauseSpan{{OEEInterval: span(0, 30), Cause: StarvationAwaitingRelease}},
+	})
+	if !near(a.Minutes[StarvationEdgeOffline], 15) {
+		t.Errorf("edge offline = %v, want 15", a.Minutes[StarvationEdgeOffline])
+	}
+	if !near(a.Minutes[StarvationAwaitingRelease], 15) {
+		t.Errorf("awaiting release = %v, want 15", a.Minutes[StarvationAwaitingRelease])
+	}
+	if !near(a.Minutes[StarvationNoOrder], 30) {
+		t.Errorf("no order = %v, want 30", a.Minutes[StarvationNoOrder])
+	}
+}
+
+// TestStarvationParetoOrdersAndAccumulates: bars most-first, the cumulative
+// share ending at 1, and combining windows before ranking.
+//
+// VERIFIED RED BY: accumulating over downtime instead of starved minutes —
+// the last bar read 0.8.
+func TestStarvationParetoOrdersAndAccumulates(t *testing.T) {
+	var week StarvationAttribution
+	week.Add(StarvationAttribution{DowntimeMinutes: 40, NotMaterialMinutes: 10, StarvedMinutes: 30,
+		Minutes: map[StarvationCause]float64{StarvationFleetBusy: 10, StarvationLaneBlocked: 20}})
+	week.Add(StarvationAttribution{DowntimeMinutes: 10, StarvedMinutes: 10,
+		Minutes: map[StarvationCause]float64{StarvationFleetBusy: 10}})
+	bars := week.Pareto()
+	if len(bars) != 2 {
+		t.Fatalf("bars = %+v, want 2", bars)
+	}
+	// 20 / 20 tie: precedence puts lane blocked first.
+	if bars[0].Cause != StarvationLaneBlocked || bars[1].Cause != StarvationFleetBusy {
+		t.Errorf("order = %s, %s; want lane blocked, fleet busy", bars[0].Cause, bars[1].Cause)
+	}
+	if !near(bars[0].Cumulative, 0.5) || !near(bars[1].Cumulative, 1) {
+		t.Errorf("cumulative = %v, %v; want 0.5, 1", bars[0].Cumulative, bars[1].Cumulative)
+	}
+}
+
+// TestStarvationIsClippedToTheWindow: downtime and a wait that began before
+// the window only count from the window's start, so a week's first shift
+// does not inherit the previous week's minutes.
+//
+// VERIFIED RED BY: cutting on the unclipped downtime — downtime read 60.
+func TestStarvationIsClippedToTheWindow(t *testing.T) {
+	a := AttributeStarvation(StarvationInput{
+		Window:        oeeShift,
+		Downtime:      []OEEInterval{span(-30, 30)},
+		MaterialWaits: []OEEInterval{span(-30, 30)},
+		Orders:        []CauseSpan{{OEEInterval: span(-30, 30), Cause: StarvationLaneBlocked}},
+	})
+	if !near(a.DowntimeMinutes, 30) || !near(a.StarvedMinutes, 30) || !near(a.Minutes[StarvationLaneBlocked], 30) {
+		t.Errorf("down = %v, starved = %v, lane blocked = %v; want 30 each", a.DowntimeMinutes, a.StarvedMinutes, a.Minutes[StarvationLaneBlocked])
+	}
+}
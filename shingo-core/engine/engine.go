@@ -86,11 +86,21 @@ type Engine struct {
 	footprintService      *service.FootprintService
 	partsService          *service.PartsService
 	heartbeatService      *service.HeartbeatService
+	qualityHoldService    *service.QualityHoldService
+	alertService          *service.AlertService
+	shiftReportService    *service.ShiftReportService
+	oeeService            *service.OEEService
+	starvationService     *service.StarvationService
+	replayService         *service.ReplayService
+	forecastService       *service.DemandForecastService
+	analyticsExport       *service.AnalyticsExportService
+	robotMaintenance      *service.RobotMaintenanceService
 	thresholdMonitor      *ThresholdMonitor
 	sourceabilityMonitor  *SourceabilityMonitor
 	maintainer            *Maintainer
 	etaCache              *eta.Cache
 	notifier              *notify.Notifier
+	notifying             sync.Map // deliverThenMark keys with a send in progress
 	stopChan              chan struct{}
 	stopOnce              sync.Once
 	sceneSyncing          atomic.Bool
@@ -120,6 +130,11 @@ type Engine struct {
 	// one extra row per robot to re-establish.
 	confidence *robotConfidenceSampler
 
+	// health is the maintenance sampler's memory: when each robot's health
+	// was last written and which alarm codes it had open. Single-writer, as
+	// confidence: only sampleRobotHealth, from robotRefreshLoop, touches it.
+	health *robotHealthSampler
+
 	// lastSceneSync is when the previous scene observation landed, so a diff
 	// row can record the WINDOW an edit happened in rather than only the
 	// moment it was noticed. A diff row is one observed edit, and two edits
@@ -241,6 +256,15 @@ func New(c Config) *Engine {
 	e.footprintService = service.NewFootprintService(e.db)
 	e.partsService = service.NewPartsService(e.db)
 	e.heartbeatService = service.NewHeartbeatService(e.db)
+	e.qualityHoldService = service.NewQualityHoldService(e.db, e.binService, e.qualityPolicy)
+	e.alertService = service.NewAlertService(e.db)
+	e.shiftReportService = service.NewShiftReportService(e.db)
+	e.oeeService = service.NewOEEService(e.db)
+	e.starvationService = service.NewStarvationService(e.db)
+	e.replayService = service.NewReplayService(e.db)
+	e.forecastService = service.NewDemandForecastService(e.db)
+	e.analyticsExport = service.NewAnalyticsExportService(e.db)
+	e.robotMaintenance = service.NewRobotMaintenanceService(e.db, e.fleet)
 	e.thresholdMonitor = NewThresholdMonitor(e)
 	e.sourceabilityMonitor = NewSourceabilityMonitor(e)
 	e.maintainer = NewMaintainer(e, nil)
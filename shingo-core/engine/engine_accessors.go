@@ -61,6 +61,41 @@ func (e *Engine) HeartbeatService() *service.HeartbeatService { return e.heartbe
 func (e *Engine) EventBus() *EventBus                         { return e.Events }
 func (e *Engine) EtaCache() *eta.Cache                        { return e.etaCache }
 func (e *Engine) Notifier() *notify.Notifier                  { return e.notifier }
+func (e *Engine) QualityHoldService() *service.QualityHoldService {
+	return e.qualityHoldService
+}
+
+func (e *Engine) AlertService() *service.AlertService {
+	return e.alertService
+}
+
+func (e *Engine) ShiftReportService() *service.ShiftReportService {
+	return e.shiftReportService
+}
+
+func (e *Engine) OEEService() *service.OEEService {
+	return e.oeeService
+}
+
+func (e *Engine) StarvationService() *service.StarvationService {
+	return e.starvationService
+}
+
+func (e *Engine) ReplayService() *service.ReplayService {
+	return e.replayService
+}
+
+func (e *Engine) DemandForecastService() *service.DemandForecastService {
+	return e.forecastService
+}
+
+func (e *Engine) AnalyticsExportService() *service.AnalyticsExportService {
+	return e.analyticsExport
+}
+
+func (e *Engine) RobotMaintenanceService() *service.RobotMaintenanceService {
+	return e.robotMaintenance
+}
 
 // Maintainer returns the maintained-group level keeper, for the health page.
 func (e *Engine) Maintainer() *Maintainer { return e.maintainer }
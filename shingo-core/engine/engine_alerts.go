@@ -0,0 +1,336 @@
+// engine_alerts.go — the alert rules engine's loop.
+//
+// Every alerts.eval_interval the loop takes one snapshot of the plant (active
+// orders and their time in status, edge heartbeats, lineside levels, outbox
+// dead letters, fleet and robot state), runs the periodic rules over it
+// (shingocore/alerting), and writes the outcome through AlertService: new
+// findings raise, continuing ones refresh the same row, cleared ones resolve.
+// Then two passes over the table: escalate what nobody acknowledged in time,
+// and notify whatever has not been notified — which is also how an alert held
+// back by quiet hours goes out once they end.
+//
+// Event rules raise from the bus (wiring.go) and ride the same notify pass,
+// so an event alert reaches the channels within one interval.
+//
+// A pass that changes what is unresolved emits EventAlertsChanged, which the
+// alert wall display redraws on.
+//
+// localization_hotspot rules read the map-fix hotspots (shingocore/mapfix).
+// Those move by the day, so the loop keeps them between passes and re-reads
+// them every hotspotRefresh, and only while such a rule is configured.
+
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"shingo/protocol/clock"
+	"shingocore/alerting"
+	"shingocore/config"
+	"shingocore/mapfix"
+	"shingocore/notify"
+	"shingocore/service"
+)
+
+// alertLoopState is the loop's memory between passes. Single-writer: only
+// alertLoop's goroutine touches it.
+type alertLoopState struct {
+	eval *alerting.Evaluator
+	// reported is the last set of config problems logged, so a bad rule is
+	// logged when it appears rather than every thirty seconds.
+	reported string
+	// board is the unresolved alerts' signature after the last pass; a pass
+	// that moves it emits EventAlertsChanged.
+	board string
+	// hotspots are the last read, at hotspotsAt.
+	hotspots   []mapfix.Hotspot
+	hotspotsAt time.Time
+}
+
+const (
+	// hotspotRefresh is how often the hotspots are re-read. The roll-up they
+	// come from is daily; this is for a plant whose day just rolled over.
+	hotspotRefresh = 15 * time.Minute
+	// hotspotDays is the window they are judged over — the board's default.
+	hotspotDays = 7
+)
+
+// alertLoop runs the periodic rules. The interval is read once at start; the
+// rules themselves are re-read every pass, so a config edit applies on the
+// next one.
+func (e *Engine) alertLoop() {
+	interval := e.alertPolicy().EvalInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	st := &alertLoopState{eval: alerting.NewEvaluator()}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			e.evaluateAlerts(st, clock.Now().UTC())
+		}
+	}
+}
+
+// alertPolicy copies the alerts section of the live config under the config
+// lock, so a save from the config page is seen whole.
+func (e *Engine) alertPolicy() config.AlertsConfig {
+	e.cfg.Lock()
+	defer e.cfg.Unlock()
+	p := e.cfg.Alerts
+	p.Rules = slices.Clone(p.Rules)
+	return p
+}
+
+// validAlertRules drops the rules that do not validate, and duplicate names
+// (the name is half the de-dup key, so two rules sharing one would resolve
+// each other's alerts). The problems come back joined for the caller to log.
+func validAlertRules(p config.AlertsConfig) ([]config.AlertRule, string) {
+	var (
+		out      []config.AlertRule
+		problems string
+	)
+	seen := make(map[string]bool)
+	for _, r := range p.Rules {
+		err := alerting.Validate(r)
+		if err == nil && seen[r.Name] {
+			err = fmt.Errorf("alert rule %q: duplicate name", r.Name)
+		}
+		if err != nil {
+			problems += err.Error() + "; "
+			continue
+		}
+		seen[r.Name] = true
+		out = append(out, r)
+	}
+	if err := alerting.ValidQuietHours(p.QuietHours); err != nil {
+		problems += err.Error() + "; "
+	}
+	return out, problems
+}
+
+// evaluateAlerts is one pass of the loop.
+func (e *Engine) evaluateAlerts(st *alertLoopState, now time.Time) {
+	p := e.alertPolicy()
+	if !p.Enabled {
+		return
+	}
+	rules, problems := validAlertRules(p)
+	if problems != st.reported {
+		if problems != "" {
+			e.logFn("alerts: skipping invalid config: %s", problems)
+		}
+		st.reported = problems
+	}
+
+	snap, err := e.alertSnapshot(now)
+	if err != nil {
+		e.logFn("alerts: snapshot: %v", err)
+		return
+	}
+	snap.Hotspots = e.alertHotspots(st, rules, now)
+	names := make([]string, 0, len(rules))
+	for _, res := range st.eval.Evaluate(rules, snap) {
+		names = append(names, res.Rule.Name)
+		due := make([]service.AlertRaise, 0, len(res.Due))
+		for _, f := range res.Due {
+			due = append(due, service.AlertRaise{
+				Rule: res.Rule.Name, Kind: res.Rule.Kind, DedupKey: f.Key,
+				Severity: alerting.SeverityOf(res.Rule), Subject: f.Subject, Detail: f.Detail,
+			})
+		}
+		created, err := e.alertService.Sync(res.Rule.Name, due, res.Holding, now)
+		if err != nil {
+			e.logFn("alerts: rule %s: %v", res.Rule.Name, err)
+			continue
+		}
+		for _, a := range created {
+			e.logFn("alerts: A-%d raised (%s, %s): %s", a.ID, a.Rule, a.Severity, a.Subject)
+		}
+	}
+	if _, err := e.alertService.ResolveRulesExcept(names, now); err != nil {
+		e.logFn("alerts: %v", err)
+	}
+	e.escalateAlerts(rules, now)
+	e.notifyAlerts(p.QuietHours, now)
+
+	// Event alerts raised and acknowledgements made since the last pass show
+	// up here too, at most one interval late; raiseEventAlert emits at once.
+	if sig, err := e.alertService.BoardSignature(); err != nil {
+		e.logFn("alerts: %v", err)
+	} else if sig != st.board {
+		st.board = sig
+		e.Events.Emit(Event{Type: EventAlertsChanged, Payload: AlertsChangedEvent{}})
+	}
+}
+
+// alertSnapshot gathers what the periodic rules read. The database reads are
+// one query each; fleet and robot state come from the engine's own caches.
+func (e *Engine) alertSnapshot(now time.Time) (alerting.Snapshot, error) {
+	snap := alerting.Snapshot{Now: now, FleetConnected: e.fleetConnected.Load()}
+
+	orders, err := e.alertService.ActiveOrderAges()
+	if err != nil {
+		return snap, err
+	}
+	for _, o := range orders {
+		snap.Orders = append(snap.Orders, alerting.OrderAge(o))
+	}
+	edges, err := e.db.ListEdges()
+	if err != nil {
+		return snap, err
+	}
+	for _, ed := range edges {
+		snap.Edges = append(snap.Edges, alerting.EdgeBeat{
+			StationID: ed.StationID, DisplayName: ed.DisplayName, LastHeartbeat: ed.LastHeartbeat,
+		})
+	}
+	levels, err := e.alertService.LinesideLevels()
+	if err != nil {
+		return snap, err
+	}
+	for _, l := range levels {
+		snap.Lineside = append(snap.Lineside, alerting.LinesideLevel(l))
+	}
+	if snap.DeadLetters, err = e.alertService.DeadLetterCount(); err != nil {
+		return snap, err
+	}
+	// A disconnected fleet leaves the cache holding its last readings; judging
+	// robots on those would raise on numbers nobody is reporting any more.
+	if snap.FleetConnected {
+		for _, r := range e.GetAllCachedRobots() {
+			snap.Robots = append(snap.Robots, alerting.RobotConfidence{
+				VehicleID: r.VehicleID, Connected: r.Connected,
+				Relocating: r.RelocStatus == 2, Confidence: r.Confidence,
+				X: r.X, Y: r.Y,
+			})
+		}
+	}
+	return snap, nil
+}
+
+// alertHotspots returns the localization hotspots for this pass, re-read when
+// the cached ones are older than hotspotRefresh. A failed read keeps the last
+// good set: a hotspot does not stop being one because a query timed out.
+func (e *Engine) alertHotspots(st *alertLoopState, rules []config.AlertRule, now time.Time) []mapfix.Hotspot {
+	if !slices.ContainsFunc(rules, func(r config.AlertRule) bool { return r.Kind == alerting.KindHotspot }) {
+		return nil
+	}
+	if !st.hotspotsAt.IsZero() && now.Sub(st.hotspotsAt) < hotspotRefresh {
+		return st.hotspots
+	}
+	fixes, err := e.nodeService.MapFixesAt(hotspotDays, now)
+	if err != nil {
+		e.logFn("alerts: localization hotspots: %v", err)
+		return st.hotspots
+	}
+	st.hotspots, st.hotspotsAt = fixes.Hotspots, now
+	return st.hotspots
+}
+
+// escalateAlerts raises the severity of every open alert that has waited out
+// its rule's escalate_after unacknowledged. An alert whose rule has gone from
+// the config does not escalate.
+func (e *Engine) escalateAlerts(rules []config.AlertRule, now time.Time) {
+	open, err := e.alertService.Unacknowledged()
+	if err != nil {
+		e.logFn("alerts: escalation: %v", err)
+		return
+	}
+	for _, a := range open {
+		i := slices.IndexFunc(rules, func(r config.AlertRule) bool { return r.Name == a.Rule })
+		if i < 0 || !alerting.DueEscalation(rules[i], a.FirstSeen, now) {
+			continue
+		}
+		sev := alerting.EscalationSeverity(rules[i])
+		if alerting.Rank(sev) < alerting.Rank(a.Severity) {
+			sev = a.Severity
+		}
+		if ok, err := e.alertService.Escalate(a.ID, sev, now); err != nil {
+			e.logFn("alerts: escalate A-%d: %v", a.ID, err)
+		} else if ok {
+			e.logFn("alerts: A-%d escalated to %s — unacknowledged since %s", a.ID, sev, a.FirstSeen.Format(time.RFC3339))
+		}
+	}
+}
+
+// notifyAlerts sends every unresolved alert whose notification has not gone
+// out, except what quiet hours hold back. Quiet hours are the plant's clock,
+// like every other time of day Core reads. notified_at is stamped once every
+// channel has delivered (deliverThenMark); until then the alert is sent again
+// on the next pass.
+func (e *Engine) notifyAlerts(q config.AlertQuietHours, now time.Time) {
+	if !e.notifier.Enabled() {
+		return
+	}
+	pending, err := e.alertService.Unnotified()
+	if err != nil {
+		e.logFn("alerts: notify: %v", err)
+		return
+	}
+	plant := now.In(config.PlantLocation())
+	for _, a := range pending {
+		if alerting.HoldNotification(q, a.Severity, plant) {
+			continue
+		}
+		id := a.ID
+		e.deliverThenMark(fmt.Sprintf("alert A-%d", id), alertMessage(a), func() error {
+			return e.alertService.MarkNotified(id, now)
+		})
+	}
+}
+
+func alertMessage(a *service.Alert) notify.Message {
+	escalated := a.EscalatedAt != nil
+	event := notify.EventAlertRaised
+	if escalated {
+		event = notify.EventAlertEscalated
+	}
+	return notify.Message{
+		Event:    event,
+		Severity: a.Severity,
+		Subject:  notify.AlertSubject(a.Severity, a.Subject, escalated),
+		Summary:  notify.AlertSummary(a.ID, a.Subject, a.Detail, escalated),
+		Body:     notify.AlertAlert(a.ID, a.Rule, a.Severity, a.Subject, a.Detail, a.FirstSeen, escalated),
+		Fields: map[string]string{
+			"alert_id": fmt.Sprintf("%d", a.ID),
+			"rule":     a.Rule,
+			"kind":     a.Kind,
+			"key":      a.DedupKey,
+		},
+	}
+}
+
+// raiseEventAlert raises an alert for every event rule routed for event. key
+// is the de-dup key — a repeat of the same event on the same subject while
+// its alert is unresolved counts as another occurrence on that alert.
+func (e *Engine) raiseEventAlert(event, key, subject, detail string) {
+	p := e.alertPolicy()
+	if !p.Enabled {
+		return
+	}
+	rules, _ := validAlertRules(p)
+	for _, r := range rules {
+		if r.Kind != alerting.KindEvent || !slices.Contains(r.Events, event) {
+			continue
+		}
+		a, created, err := e.alertService.RaiseEvent(service.AlertRaise{
+			Rule: r.Name, Kind: r.Kind, DedupKey: key,
+			Severity: alerting.SeverityOf(r), Subject: subject, Detail: detail,
+		})
+		if err != nil {
+			e.logFn("alerts: rule %s on %s: %v", r.Name, event, err)
+			continue
+		}
+		if created {
+			e.logFn("alerts: A-%d raised (%s, %s): %s", a.ID, a.Rule, a.Severity, a.Subject)
+			e.Events.Emit(Event{Type: EventAlertsChanged, Payload: AlertsChangedEvent{}})
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// engine_analytics_export.go — the scheduled analytics export.
+//
+// Every ten minutes the loop asks which closed plant days in the last
+// analytics_export.lookback_days have no manifest on the target, and exports
+// each of them (service/analytics_export_service.go). What has been exported
+// is read off the target, not remembered: a Core restarted mid-export, or a
+// share that was unmounted for a night, catches up on the next pass, and a
+// day deleted from the target by hand is written again.
+
+package engine
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"shingo/protocol/clock"
+	"shingocore/backup"
+	"shingocore/config"
+)
+
+// analyticsExportInterval is the loop's cadence. A day is exported once; the
+// interval only decides how soon after analytics_export.delay that happens.
+const analyticsExportInterval = 10 * time.Minute
+
+func (e *Engine) analyticsExportLoop() {
+	// Ctx tied to stopChan so a day's export aborts on shutdown; the next
+	// start exports the day again from the top.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-e.stopChan
+		cancel()
+	}()
+	ticker := time.NewTicker(analyticsExportInterval)
+	defer ticker.Stop()
+	var done time.Time
+	var reported string
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			done, reported = e.runAnalyticsExport(ctx, done, reported, clock.Now().UTC())
+		}
+	}
+}
+
+// analyticsExportPolicy copies the analytics_export section of the live
+// config under the config lock.
+func (e *Engine) analyticsExportPolicy() config.AnalyticsExportConfig {
+	e.cfg.Lock()
+	defer e.cfg.Unlock()
+	p := e.cfg.AnalyticsExport
+	p.Formats = slices.Clone(p.Formats)
+	p.Datasets = slices.Clone(p.Datasets)
+	return p
+}
+
+// runAnalyticsExport is one pass. done is the newest day the last pass left
+// complete, so a pass with nothing new to export does not list the target;
+// reported is the failure last logged, so a target that stays down is logged
+// once rather than every pass. Both are returned updated.
+func (e *Engine) runAnalyticsExport(ctx context.Context, done time.Time, reported string, now time.Time) (time.Time, string) {
+	p := e.analyticsExportPolicy()
+	if !p.Enabled || p.LookbackDays <= 0 {
+		return done, reported
+	}
+	// Days are plant days (config.PlantLocation), the day an engineer reads
+	// off the floor's clocks, not UTC's, which splits a US plant's evening.
+	loc := config.PlantLocation()
+	y, m, d := now.Add(-p.Delay).In(loc).Date()
+	last := time.Date(y, m, d, 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+	if !last.After(done) {
+		return done, reported
+	}
+	fail := func(err error) (time.Time, string) {
+		if msg := err.Error(); msg != reported {
+			e.logFn("analytics export: %s", msg)
+			return done, msg
+		}
+		return done, reported
+	}
+
+	st, err := backup.NewStorage(p.Target())
+	if err != nil {
+		return fail(err)
+	}
+	svc := e.analyticsExport
+	have, err := svc.ExportedDays(ctx, st, loc)
+	if err != nil {
+		return fail(err)
+	}
+	for day := last.AddDate(0, 0, 1-p.LookbackDays); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if have[day] {
+			continue
+		}
+		m, err := svc.ExportDayTo(ctx, st, day, p.Datasets, p.Formats)
+		if err != nil {
+			return fail(err)
+		}
+		var rows int64
+		for _, f := range m.Files {
+			rows += f.Rows
+		}
+		e.logFn("analytics export: %s written, %d files, %d rows", m.From, len(m.Files), rows)
+	}
+	return last, ""
+}
@@ -3,9 +3,11 @@ package engine
 import (
 	"crypto/sha256"
 	"encoding/json"
+	"slices"
 	"time"
 
 	"shingocore/fleet"
+	"shingocore/service"
 )
 
 // ── Background loops ────────────────────────────────────────────────
@@ -15,7 +17,8 @@ import (
 // changes (SHA-256 compare), so UI subscribers don't re-render on
 // every poll. stagedBinSweepLoop runs the two bin-hygiene passes —
 // expired staged bins and orphaned claims — on the configured staging
-// sweep interval.
+// sweep interval. qualityHoldSweepLoop extends open quality holds to bins
+// that started matching them after they were placed.
 
 // robotRefreshLoop polls robot status every 2 seconds and emits EventRobotsUpdated
 // only when the robot state has actually changed.
@@ -60,6 +63,10 @@ func (e *Engine) robotRefreshLoop() {
 			// are exactly the ones that leave the fleet hash untouched.
 			e.sampleRobotConfidence(robots, time.Now())
 
+			// The maintenance health record, for the same reason: a fault
+			// that comes and goes while a robot stands still is a fault.
+			e.sampleRobotHealth(robots, time.Now())
+
 			// A bin riding a robot's deck is placed the moment that deck
 			// reports empty. Before the hash short-circuit for the same reason
 			// as the sampler: a robot that has parked and set a bin down has
@@ -188,3 +195,43 @@ func (e *Engine) stagedBinSweepLoop() {
 		}
 	}
 }
+
+// qualityHoldSweepLoop periodically propagates every open quality hold.
+//
+// Latency only, never correctness of dispatch: a bin that matches a hold but
+// has not been swept yet is still sourceable for up to one interval, which is
+// why the interval is short. Placing a hold propagates immediately; this is
+// for the bin loaded into a held lot an hour later.
+func (e *Engine) qualityHoldSweepLoop() {
+	interval := e.cfg.Quality.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			n, err := e.qualityHoldService.PropagateOpen()
+			if err != nil {
+				e.logFn("engine: quality hold sweep error: %v", err)
+			}
+			if n > 0 {
+				e.logFn("engine: quality hold sweep held %d more bin(s)", n)
+			}
+		}
+	}
+}
+
+// qualityPolicy reads the quality section of the live config for the hold
+// service, under the config lock so a save from the config page is seen whole.
+func (e *Engine) qualityPolicy() service.QualityPolicy {
+	e.cfg.Lock()
+	defer e.cfg.Unlock()
+	return service.QualityPolicy{
+		ReasonCodes: slices.Clone(e.cfg.Quality.ReasonCodes),
+		Approvers:   slices.Clone(e.cfg.Quality.Approvers),
+	}
+}
@@ -46,6 +46,9 @@ func (e *Engine) Start() {
 	// SourceFinder seam with intake planning (same resolver + DB) so replay can't
 	// drift its source scoping from intake.
 	sourceFinder := dispatch.NewSourceFinder(e.db, resolver, e.debugLog)
+	// Both finders, because there are two instances; the seam is one policy.
+	e.dispatcher.SetEmptyAffinityGrace(e.emptyAffinityGrace)
+	sourceFinder.SetEmptyAffinityGrace(e.emptyAffinityGrace)
 	e.fulfillment = fulfillment.NewScanner(e.db, e.dispatcher, e.dispatcher.Lifecycle(), sourceFinder, e.binManifest, e.sendToEdge, e.failOrderAndEmit, e.logFn, e.debugLog)
 
 	// Wire event handlers
@@ -133,6 +136,23 @@ func (e *Engine) Start() {
 	// Start staged bin expiry sweep
 	go e.stagedBinSweepLoop()
 
+	// Quality-hold propagation: holds bins that started matching an open hold
+	// after it was placed.
+	go e.qualityHoldSweepLoop()
+
+	// Alert rules: periodic checks, escalation, and notification of alerts.
+	// Event rules raise from the bus (wiring.go); this loop delivers them.
+	go e.alertLoop()
+
+	// End-of-shift reports: built and sent once each shift has ended.
+	go e.shiftReportLoop()
+
+	// Analytics export: each closed day written to the configured target.
+	go e.analyticsExportLoop()
+
+	// Robot maintenance: indicators assessed, work items filed and sent.
+	go e.robotMaintenanceLoop()
+
 	// Map + scene sync gates. Deliberately NO boot pass, unlike the confidence
 	// roll-up: both gates read the robot cache, which robotRefreshLoop above
 	// fills on its 2-second tick, so a pass at boot would run against an empty
@@ -329,3 +349,12 @@ func (e *Engine) loadActiveOrders() {
 	}
 	e.logFn("engine: loaded %d active vendor orders into tracker", len(ids))
 }
+
+// emptyAffinityGrace is dispatch.empty_affinity_grace under the config lock,
+// read by the finders on every plant-wide empty search so a saved change
+// applies to the next one.
+func (e *Engine) emptyAffinityGrace() time.Duration {
+	e.cfg.Lock()
+	defer e.cfg.Unlock()
+	return e.cfg.Dispatch.EmptyAffinityGrace
+}
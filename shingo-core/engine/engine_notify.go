@@ -0,0 +1,28 @@
+package engine
+
+import "shingocore/notify"
+
+// deliverThenMark sends m in the background and runs mark once every channel
+// has delivered it, so a notified_at stamp means the message went out: a
+// webhook that stays down through the retries, or a restart mid-send, leaves
+// the row unstamped and the next pass sends it again. A channel that did
+// deliver may then see the message twice, which a receiver reconciles on the
+// ids the message carries; a work order nobody ever received cannot be.
+//
+// key names the record being sent. While its send is in progress the next
+// pass skips it rather than starting a second one.
+func (e *Engine) deliverThenMark(key string, m notify.Message, mark func() error) {
+	if _, busy := e.notifying.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+	go func() {
+		defer e.notifying.Delete(key)
+		if err := e.notifier.Deliver(m); err != nil {
+			e.logFn("notify: %s not delivered, will retry: %v", key, err)
+			return
+		}
+		if err := mark(); err != nil {
+			e.logFn("notify: %s delivered but not recorded: %v", key, err)
+		}
+	}()
+}
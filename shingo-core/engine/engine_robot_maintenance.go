@@ -0,0 +1,276 @@
+// engine_robot_maintenance.go — predictive robot maintenance.
+//
+// Two halves. sampleRobotHealth rides the existing robot poll, as the
+// confidence sampler does: it records each alarm onset and clear as it
+// happens, and writes a robot's battery, odometer and wear counters once per
+// robot_maintenance.sample_interval. robotMaintenanceLoop assesses the record
+// hourly (shingocore/robothealth), files a work item for every indicator that
+// is due, optionally takes the robot out of availability, and sends the new
+// items to the notification channels — which is how they reach a CMMS.
+
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"shingo/protocol/clock"
+	"shingocore/config"
+	"shingocore/fleet"
+	"shingocore/notify"
+	"shingocore/service"
+)
+
+// robotMaintenanceInterval is the assessment cadence. Every indicator is a
+// daily or weekly rate; an hour only decides how soon after a threshold is
+// crossed the item is filed.
+const robotMaintenanceInterval = time.Hour
+
+// robotHealthSampler is the health sampler's memory between polls.
+//
+// Single-writer by construction, as robotConfidenceSampler: every field is
+// touched only from robotRefreshLoop's goroutine.
+type robotHealthSampler struct {
+	written map[string]time.Time
+	// faults is the codes open per robot, loaded from the table on the first
+	// pass so a restart neither re-raises a standing alarm nor misses the
+	// clear of one that went while Core was down.
+	faults map[string]map[int]bool
+}
+
+// robotMaintenancePolicy copies the robot_maintenance section of the live
+// config under the config lock.
+func (e *Engine) robotMaintenancePolicy() config.RobotMaintenanceConfig {
+	e.cfg.Lock()
+	defer e.cfg.Unlock()
+	return e.cfg.RobotMaintenance
+}
+
+// sampleRobotHealth records one poll's alarm changes and the health samples
+// that are due.
+func (e *Engine) sampleRobotHealth(robots []fleet.RobotStatus, now time.Time) {
+	if e.cfg == nil || e.db == nil {
+		return
+	}
+	p := e.robotMaintenancePolicy()
+	if !p.Enabled {
+		return
+	}
+	svc := e.robotMaintenance
+	if e.health == nil {
+		open, err := svc.OpenFaults()
+		if err != nil {
+			e.dbg("engine: robot health: open faults: %v", err)
+			return
+		}
+		e.health = &robotHealthSampler{written: map[string]time.Time{}, faults: open}
+	}
+	s := e.health
+
+	var batch []service.RobotHealthSample
+	for _, r := range robots {
+		// A disconnected robot's alarm list and counters are stale, not
+		// cleared: nothing is recorded for it until it is back.
+		if r.VehicleID == "" || !r.Connected {
+			continue
+		}
+		e.syncRobotFaults(s, r, now)
+		if now.Sub(s.written[r.VehicleID]) < p.SampleInterval {
+			continue
+		}
+		batch = append(batch, service.RobotHealthSample{
+			VehicleID:    r.VehicleID,
+			SampledAt:    now,
+			BatteryLevel: r.BatteryLevel,
+			BatteryCycle: r.BatteryCycle,
+			BatteryTemp:  r.BatteryTemp,
+			BatteryV:     r.BatteryV,
+			Charging:     r.Charging,
+			OdoTotalM:    r.OdoTotal,
+			RuntimeMs:    r.TotalMs,
+			LiftCount:    r.LiftCount,
+			CtrlTemp:     r.CtrlTemp,
+		})
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := svc.RecordSamples(batch); err != nil {
+		e.dbg("engine: robot health: insert: %v", err)
+		return
+	}
+	for _, b := range batch {
+		s.written[b.VehicleID] = now
+	}
+}
+
+// syncRobotFaults records the alarms that appeared on r since the last poll
+// and clears the ones that went. The memory moves only when the write lands,
+// so a failed write is retried on the next poll.
+func (e *Engine) syncRobotFaults(s *robotHealthSampler, r fleet.RobotStatus, now time.Time) {
+	svc := e.robotMaintenance
+	open := s.faults[r.VehicleID]
+	if open == nil {
+		open = map[int]bool{}
+		s.faults[r.VehicleID] = open
+	}
+	seen := make(map[int]bool, len(r.Alarms))
+	for _, a := range r.Alarms {
+		seen[a.Code] = true
+		if open[a.Code] {
+			continue
+		}
+		err := svc.RaiseFault(service.RobotFault{
+			VehicleID: r.VehicleID, Code: a.Code, Severity: a.Severity,
+			Description: a.Desc, RaisedAt: now,
+		})
+		if err != nil {
+			e.dbg("engine: robot health: %v", err)
+			continue
+		}
+		open[a.Code] = true
+	}
+	for code := range open {
+		if seen[code] {
+			continue
+		}
+		if err := svc.ClearFault(r.VehicleID, code, now); err != nil {
+			e.dbg("engine: robot health: %v", err)
+			continue
+		}
+		delete(open, code)
+	}
+}
+
+func (e *Engine) robotMaintenanceLoop() {
+	ticker := time.NewTicker(robotMaintenanceInterval)
+	defer ticker.Stop()
+	var pruned time.Time
+	var reported string
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			pruned, reported = e.runRobotMaintenance(pruned, reported, clock.Now().UTC())
+		}
+	}
+}
+
+// runRobotMaintenance is one pass. pruned is when the record was last pruned,
+// so that happens daily; reported is the failure last logged, so a database
+// that stays down is logged once. Both are returned updated.
+func (e *Engine) runRobotMaintenance(pruned time.Time, reported string, now time.Time) (time.Time, string) {
+	p := e.robotMaintenancePolicy()
+	if !p.Enabled {
+		return pruned, reported
+	}
+	svc := e.robotMaintenance
+	var vehicles []string
+	for _, r := range e.GetAllCachedRobots() {
+		vehicles = append(vehicles, r.VehicleID)
+	}
+	health, err := svc.Assess(p, vehicles, now)
+	if err != nil {
+		if msg := err.Error(); msg != reported {
+			e.logFn("robot maintenance: %s", msg)
+			return pruned, msg
+		}
+		return pruned, reported
+	}
+	for _, h := range health {
+		for _, in := range h.Due() {
+			it, created, err := svc.OpenItem(h.VehicleID, in, now)
+			if err != nil {
+				e.logFn("robot maintenance: %v", err)
+				continue
+			}
+			if created {
+				e.logFn("robot maintenance: %s due for %s (%s)", h.VehicleID, in.Key, in.Detail)
+			}
+			if p.HoldDueRobots && !it.Held {
+				e.holdRobotForMaintenance(it)
+			}
+		}
+	}
+	e.notifyRobotMaintenance(now)
+
+	if p.RetentionDays > 0 && now.Sub(pruned) >= 24*time.Hour {
+		if n, err := svc.Prune(now.AddDate(0, 0, -p.RetentionDays)); err != nil {
+			e.logFn("robot maintenance: %v", err)
+		} else {
+			pruned = now
+			if n > 0 {
+				e.dbg("robot maintenance: pruned %d rows", n)
+			}
+		}
+	}
+	return pruned, ""
+}
+
+// holdRobotForMaintenance takes the item's robot out of availability and
+// marks the item as holding it; closing the robot's last held item gives it
+// back (RobotMaintenanceService.Close). A robot on a mission finishes it — availability only stops new
+// work being dispatched.
+func (e *Engine) holdRobotForMaintenance(it *service.RobotMaintenanceItem) {
+	rl, ok := e.fleet.(fleet.RobotLister)
+	if !ok {
+		return
+	}
+	if err := rl.SetAvailability(it.VehicleID, false); err != nil {
+		e.logFn("robot maintenance: hold %s: %v", it.VehicleID, err)
+		return
+	}
+	if err := e.robotMaintenance.SetHeld(it.ID, true); err != nil {
+		e.logFn("robot maintenance: %v", err)
+		return
+	}
+	it.Held = true
+	e.logFn("robot maintenance: %s taken out of availability for %s", it.VehicleID, it.Indicator)
+}
+
+// notifyRobotMaintenance sends the open items not yet sent. notified_at is
+// stamped only once every channel has delivered the item, so a CMMS webhook
+// that is down, or a restart mid-send, costs a later pass and not the work
+// order.
+func (e *Engine) notifyRobotMaintenance(now time.Time) {
+	if !e.notifier.Enabled() {
+		return
+	}
+	pending, err := e.robotMaintenance.Unnotified()
+	if err != nil {
+		e.logFn("robot maintenance: notify: %v", err)
+		return
+	}
+	for _, it := range pending {
+		id := it.ID
+		e.deliverThenMark(fmt.Sprintf("robot maintenance item %d", id), robotMaintenanceMessage(it), func() error {
+			return e.robotMaintenance.MarkNotified(id, now)
+		})
+	}
+}
+
+// robotMaintenanceMessage is the work item as the channels send it. Fields
+// are what a CMMS webhook maps to a work order; item_id is the key to
+// reconcile it against when the work is closed on either side.
+func robotMaintenanceMessage(it *service.RobotMaintenanceItem) notify.Message {
+	subject := fmt.Sprintf("Robot %s due for maintenance: %s", it.VehicleID, it.Indicator)
+	return notify.Message{
+		Event:    notify.EventRobotMaintenance,
+		Severity: notify.SeverityWarning,
+		Subject:  subject,
+		Summary:  subject,
+		Body:     fmt.Sprintf("%s\n\nValue %.2f, threshold %.2f.\n%s", subject, it.Value, it.Threshold, it.Detail),
+		Fields: map[string]string{
+			"item_id":    fmt.Sprintf("%d", it.ID),
+			"vehicle_id": it.VehicleID,
+			"indicator":  it.Indicator,
+			"value":      fmt.Sprintf("%g", it.Value),
+			"threshold":  fmt.Sprintf("%g", it.Threshold),
+			"detail":     it.Detail,
+			"held":       fmt.Sprintf("%t", it.Held),
+			"opened_at":  it.OpenedAt.UTC().Format(time.RFC3339),
+		},
+		Time: it.OpenedAt,
+	}
+}
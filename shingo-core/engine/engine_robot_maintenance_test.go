@@ -0,0 +1,33 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/notify"
+	"shingocore/service"
+)
+
+// TestRobotMaintenanceMessage_CarriesTheWorkOrderFields: the webhook payload
+// is the only thing a CMMS sees, so the item id, robot and indicator must be
+// fields and not only prose in the body.
+func TestRobotMaintenanceMessage_CarriesTheWorkOrderFields(t *testing.T) {
+	it := &service.RobotMaintenanceItem{
+		ID: 42, VehicleID: "AMR-03", Indicator: "capacity_fade",
+		Value: 23.5, Threshold: 20, Detail: "0.52%/km against 0.42%/km", Held: true,
+		OpenedAt: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+	}
+	m := robotMaintenanceMessage(it)
+	if m.Event != notify.EventRobotMaintenance {
+		t.Fatalf("event %q", m.Event)
+	}
+	want := map[string]string{
+		"item_id": "42", "vehicle_id": "AMR-03", "indicator": "capacity_fade",
+		"value": "23.5", "threshold": "20", "held": "true", "opened_at": "2026-10-18T09:00:00Z",
+	}
+	for k, v := range want {
+		if m.Fields[k] != v {
+			t.Errorf("field %s = %q, want %q", k, m.Fields[k], v)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// engine_shift_reports.go — the end-of-shift report loop.
+//
+// Once a minute the loop asks which shifts (reports.shifts, wall-clock in the
+// plant timezone) ended more than reports.delay ago, builds the report for any
+// that has none on file, and sends every report whose notification has not
+// gone out. Both passes look back one day: a Core that was down over a shift
+// change files the missed report when it comes back, and one down longer than
+// a day does not bury the channels in a backlog nobody asked for.
+//
+// The report is filed before it is sent, so a notifier that is off, or a
+// channel that is down, loses nothing — the Reports page has it either way.
+
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"shingo/protocol/clock"
+	"shingocore/config"
+	"shingocore/notify"
+	"shingocore/service"
+	"shingocore/shiftreport"
+)
+
+// shiftReportLookback bounds how far back the loop files and sends reports.
+const shiftReportLookback = 24 * time.Hour
+
+// shiftReportLoop runs the report pass every minute. The cadence is the
+// resolution of "at shift end", not a knob: a report a minute late is on time.
+func (e *Engine) shiftReportLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	var reported string
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			reported = e.runShiftReports(reported, clock.Now().UTC())
+		}
+	}
+}
+
+// reportPolicy copies the reports section of the live config under the
+// config lock.
+func (e *Engine) reportPolicy() config.ReportsConfig {
+	e.cfg.Lock()
+	defer e.cfg.Unlock()
+	p := e.cfg.Reports
+	p.Shifts = slices.Clone(p.Shifts)
+	return p
+}
+
+// runShiftReports is one pass. reported is the config problem last logged,
+// returned updated so a bad shift is logged when it appears rather than every
+// minute.
+func (e *Engine) runShiftReports(reported string, now time.Time) string {
+	p := e.reportPolicy()
+	if !p.Enabled {
+		return reported
+	}
+	problem := ""
+	if err := shiftreport.Validate(p.Shifts); err != nil {
+		problem = err.Error()
+	}
+	if problem != reported && problem != "" {
+		e.logFn("shift reports: %s — skipping the shifts that do not validate", problem)
+	}
+
+	loc := config.PlantLocation()
+	cutoff := now.Add(-p.Delay)
+	for _, w := range shiftreport.Ended(p.Shifts, loc, cutoff.Add(-shiftReportLookback), cutoff) {
+		filed, err := e.shiftReportService.Exists(w.Shift, w.Start)
+		if err != nil {
+			e.logFn("shift reports: %v", err)
+			return problem
+		}
+		if filed {
+			continue
+		}
+		r, created, err := e.shiftReportService.Generate(w.Shift, w.Day, loc.String(), w.Start, w.End)
+		if err != nil {
+			e.logFn("shift reports: %s %s: %v", w.Day, w.Shift, err)
+			continue
+		}
+		if created {
+			e.logFn("shift reports: filed %s shift %s as report %d", r.Shift, r.Day, r.ID)
+		}
+	}
+	e.notifyShiftReports(now)
+	return problem
+}
+
+// notifyShiftReports sends the filed reports not yet sent. notified_at is
+// stamped only once every channel has delivered the report, as for alerts.
+func (e *Engine) notifyShiftReports(now time.Time) {
+	if !e.notifier.Enabled() {
+		return
+	}
+	pending, err := e.shiftReportService.Unnotified(now.Add(-shiftReportLookback))
+	if err != nil {
+		e.logFn("shift reports: notify: %v", err)
+		return
+	}
+	for _, r := range pending {
+		id := r.ID
+		e.deliverThenMark(fmt.Sprintf("shift report %d", id), shiftReportMessage(r), func() error {
+			return e.shiftReportService.MarkNotified(id, now)
+		})
+	}
+}
+
+func shiftReportMessage(r *service.ShiftReport) notify.Message {
+	return notify.Message{
+		Event:    notify.EventShiftReport,
+		Severity: notify.SeverityInfo,
+		Subject:  notify.ShiftReportSubject(r.Shift, r.Day),
+		Summary:  notify.ShiftReportSummary(r),
+		Body:     notify.ShiftReportText(r),
+		Fields: map[string]string{
+			"report_id": fmt.Sprintf("%d", r.ID),
+			"shift":     r.Shift,
+			"day":       r.Day,
+		},
+	}
+}
@@ -62,6 +62,15 @@ const (
 	// later push was an illegal jump it rejected — three robots per run, held
 	// from the first minute of the soak to the end. See §12.49.
 	EventOrderResumed
+	// EventLinesideReported — an edge's lineside level report was persisted.
+	// SetupEngineListeners rebroadcasts it as the SSE `lineside-update` so a
+	// lineside wall display redraws on the report rather than on a timer.
+	EventLinesideReported
+	// EventAlertsChanged — the set of unresolved alerts, or the state of one
+	// of them, changed: raised, resolved, escalated or acknowledged. Emitted
+	// once per alert-loop pass that changed something, never on an idle pass;
+	// rebroadcast as the SSE `alert-update` for the alert wall display.
+	EventAlertsChanged
 )
 
 // --- Event payloads ---
@@ -189,6 +198,19 @@ type SourcingUpdatedEvent struct {
 	Changed int
 }
 
+// LinesideReportedEvent names the station whose lineside levels were
+// persisted, and the payloads its report carried.
+type LinesideReportedEvent struct {
+	eventbus.PayloadBase
+	Station  string
+	Payloads []string
+}
+
+// AlertsChangedEvent carries nothing: the alert board re-reads the table.
+type AlertsChangedEvent struct {
+	eventbus.PayloadBase
+}
+
 type NodeUpdatedEvent struct {
 	eventbus.PayloadBase
 	NodeID   int64
@@ -30,6 +30,8 @@ package engine
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -50,6 +52,19 @@ func lookupRobotID(e *Engine, orderID int64) string {
 	return order.RobotID
 }
 
+// orderFields are the structured fields an order alert carries to webhook
+// channels and channel templates ({{.Fields.order_id}}).
+func orderFields(orderID int64, stationID, robotID string) map[string]string {
+	f := map[string]string{"order_id": strconv.FormatInt(orderID, 10)}
+	if stationID != "" {
+		f["station"] = stationID
+	}
+	if robotID != "" {
+		f["robot_id"] = robotID
+	}
+	return f
+}
+
 // ── Outbound messaging ──────────────────────────────────────────────
 
 // sendToEdge builds a protocol envelope and enqueues it for dispatch to an edge station.
@@ -634,11 +649,15 @@ func (e *Engine) wireEventHandlers() {
 			faultTimersMu.Unlock()
 
 			msgID := notify.GenerateMessageID(fmt.Sprintf("fault-%d", ev.OrderID))
-			_ = e.notifier.SendWithHeaders(
-				notify.FaultSubject(robotID),
-				notify.FaultAlert(ev.OrderID, ev.EdgeUUID, ev.StationID, ev.Reason, robotID),
-				notify.WithMessageID(msgID),
-			)
+			e.notifier.Notify(notify.Message{
+				Event:     notify.EventOrderFaulted,
+				Severity:  notify.SeverityWarning,
+				Subject:   notify.FaultSubject(robotID),
+				Summary:   notify.FaultSummary(ev.OrderID, ev.StationID, ev.Reason, robotID),
+				Body:      notify.FaultAlert(ev.OrderID, ev.EdgeUUID, ev.StationID, ev.Reason, robotID),
+				Fields:    orderFields(ev.OrderID, ev.StationID, robotID),
+				MessageID: msgID,
+			})
 
 			faultSentMu.Lock()
 			faultSent[ev.OrderID] = faultSentInfo{
@@ -670,18 +689,15 @@ func (e *Engine) wireEventHandlers() {
 		}
 		faultTimersMu.Unlock()
 
-		var opts []notify.SendOption
+		var inReplyTo, msgID string
 		var timeFaulted string
 
 		faultSentMu.Lock()
 		if info, ok := faultSent[ev.OrderID]; ok {
 			d := time.Since(info.sentAt).Round(time.Second)
 			timeFaulted = fmt.Sprintf("%d m %d s", int(d.Minutes()), int(d.Seconds())%60)
-			opts = []notify.SendOption{
-				notify.WithInReplyTo(info.messageID),
-				notify.WithReferences(info.messageID),
-				notify.WithMessageID(notify.GenerateMessageID(fmt.Sprintf("cleared-%d", ev.OrderID))),
-			}
+			inReplyTo = info.messageID
+			msgID = notify.GenerateMessageID(fmt.Sprintf("cleared-%d", ev.OrderID))
 			delete(faultSent, ev.OrderID)
 			if ev.EdgeUUID == "" {
 				ev.EdgeUUID = info.edgeUUID
@@ -692,11 +708,16 @@ func (e *Engine) wireEventHandlers() {
 		}
 		faultSentMu.Unlock()
 
-		_ = e.notifier.SendWithHeaders(
-			notify.FaultClearedSubject(robotID),
-			notify.FaultClearedAlert(ev.OrderID, ev.EdgeUUID, ev.StationID, robotID, timeFaulted),
-			opts...,
-		)
+		e.notifier.Notify(notify.Message{
+			Event:     notify.EventFaultCleared,
+			Severity:  notify.SeverityInfo,
+			Subject:   notify.FaultClearedSubject(robotID),
+			Summary:   notify.FaultClearedSummary(ev.OrderID, ev.StationID, robotID, timeFaulted),
+			Body:      notify.FaultClearedAlert(ev.OrderID, ev.EdgeUUID, ev.StationID, robotID, timeFaulted),
+			Fields:    orderFields(ev.OrderID, ev.StationID, robotID),
+			MessageID: msgID,
+			InReplyTo: inReplyTo,
+		})
 	}, EventOrderFaultedRecovered)
 
 	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, OrderFailedEvent]) {
@@ -705,10 +726,14 @@ func (e *Engine) wireEventHandlers() {
 		}
 		ev := evt.Payload
 		robotID := lookupRobotID(e, ev.OrderID)
-		_ = e.notifier.Send(
-			notify.FailSubject(robotID),
-			notify.FailAlert(ev.OrderID, ev.EdgeUUID, ev.StationID, ev.ErrorCode, ev.Detail, robotID),
-		)
+		e.notifier.Notify(notify.Message{
+			Event:    notify.EventOrderFailed,
+			Severity: notify.SeverityCritical,
+			Subject:  notify.FailSubject(robotID),
+			Summary:  notify.FailSummary(ev.OrderID, ev.StationID, ev.ErrorCode, ev.Detail, robotID),
+			Body:     notify.FailAlert(ev.OrderID, ev.EdgeUUID, ev.StationID, ev.ErrorCode, ev.Detail, robotID),
+			Fields:   orderFields(ev.OrderID, ev.StationID, robotID),
+		})
 	}, EventOrderFailed)
 
 	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, GraceExpiredEvent]) {
@@ -717,10 +742,35 @@ func (e *Engine) wireEventHandlers() {
 		}
 		ev := evt.Payload
 		robotID := lookupRobotID(e, ev.OrderID)
-		_ = e.notifier.Send(
-			notify.GraceExpiredSubject(),
-			notify.GraceExpiredAlert(ev.OrderID, ev.VendorOrderID, robotID),
-		)
+		e.notifier.Notify(notify.Message{
+			Event:    notify.EventGraceExpired,
+			Severity: notify.SeverityCritical,
+			Subject:  notify.GraceExpiredSubject(),
+			Summary:  notify.GraceExpiredSummary(ev.OrderID, robotID),
+			Body:     notify.GraceExpiredAlert(ev.OrderID, ev.VendorOrderID, robotID),
+			Fields:   orderFields(ev.OrderID, "", robotID),
+		})
+	}, EventGraceExpired)
+
+	// ── Alert rules (event kind) ───────────────────────────────────
+	// Independent of the notifications above: those are the fixed fault and
+	// failure messages, these are rows in the alert history that someone
+	// acknowledges. Which events raise alerts is the config's call
+	// (alerts.rules with kind: event); with none configured these are no-ops.
+	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, OrderFaultedEvent]) {
+		ev := evt.Payload
+		e.raiseEventAlert(notify.EventOrderFaulted, fmt.Sprintf("order:%d", ev.OrderID),
+			fmt.Sprintf("Order %d faulted", ev.OrderID), fmt.Sprintf("%s (station %s)", ev.Reason, ev.StationID))
+	}, EventOrderFaulted)
+	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, OrderFailedEvent]) {
+		ev := evt.Payload
+		e.raiseEventAlert(notify.EventOrderFailed, fmt.Sprintf("order:%d", ev.OrderID),
+			fmt.Sprintf("Order %d failed", ev.OrderID), strings.TrimSpace(ev.ErrorCode+" "+ev.Detail))
+	}, EventOrderFailed)
+	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, GraceExpiredEvent]) {
+		ev := evt.Payload
+		e.raiseEventAlert(notify.EventGraceExpired, fmt.Sprintf("order:%d", ev.OrderID),
+			fmt.Sprintf("Order %d grace period expired", ev.OrderID), "failed and cancelled at the vendor")
 	}, EventGraceExpired)
 
 	// ── Lane-gate release evaluator ─────────────────────────────────────
@@ -0,0 +1,20 @@
+// Package locales is Core's message catalog: the text of the floor-facing
+// kiosk pages — the wall dashboards and the production heartbeat — in every
+// language the plants run. The admin pages are English and stay out of it.
+//
+// en.json is the reference and every key starts there; TestCatalogComplete
+// fails until es.json and ja.json carry a new key too.
+package locales
+
+import (
+	"embed"
+
+	"shingo/protocol/i18n"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// Catalog is loaded once at init; a malformed or inconsistent file is a build
+// defect and panics rather than shipping a screen of raw keys.
+var Catalog = i18n.MustLoad(files)
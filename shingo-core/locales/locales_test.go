@@ -0,0 +1,17 @@
+package locales
+
+import "testing"
+
+// Every shipped language carries every key. The runtime fallback to English
+// keeps a screen readable when this slips; this keeps it from slipping.
+func TestCatalogComplete(t *testing.T) {
+	langs := Catalog.Languages()
+	if len(langs) < 3 {
+		t.Fatalf("languages = %v, want en, es and ja at least", langs)
+	}
+	for _, lang := range langs {
+		if missing := Catalog.Missing(lang); len(missing) > 0 {
+			t.Errorf("%s.json lacks %d key(s): %v", lang, len(missing), missing)
+		}
+	}
+}
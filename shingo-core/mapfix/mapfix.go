@@ -0,0 +1,652 @@
+// Package mapfix turns the localization record into a ranked list of map
+// fixes: where the fleet persistently loses its position, what the map says
+// about those places, and the edit or installation most likely to stop it.
+//
+// The localization board answers "how is this lane". An engineer standing in
+// front of it still has to do the reading — find the red, open the zone,
+// notice the reflector count, remember the edit last Tuesday. This package is
+// that reading, written down once, with the evidence for every step carried
+// on the result so a recommendation can be checked rather than trusted.
+//
+// PURE. No database and no clock: service/localization_fixes.go reads the
+// days, the zones, the reflectors and the edits, and this decides. Same split
+// as shingocore/alerting, which also reads what this finds.
+//
+// ── WHAT A HOTSPOT IS ─────────────────────────────────────────────────────────
+//
+// A day is WEAK on a lane or zone when at least weakShareMin of its readings
+// were no-estimates or under 0.30 — the vendor's red. Weak, not blind: a lane
+// that reads 0.2 all day has not lost anything yet and is where a robot will.
+//
+// A hotspot is PERSISTENT: weak on at least badDaysMin days and on at least
+// half the days it carried enough traffic to judge. One bad afternoon is an
+// incident, and the map is not what to change for it.
+//
+// And it is SHARED: at least robotsMin robots on its weak days. One robot weak
+// everywhere it goes is a robot problem, and the confidence residual
+// (store/robotconfidence) is the tool for it. Recommending a reflector for a
+// dirty scanner is the one mistake this package must not make.
+//
+// ── WHAT IT RECOMMENDS ───────────────────────────────────────────────────────
+//
+// Three actions, and the evidence they rest on is the project's own finding
+// (scenemap): the CLASS predicts a miss — every ReflectorArea carrying traffic
+// loses 23-71% of its readings and neither LocConfigArea loses any — and nine
+// ReflectorAreas at Springfield hold zero reflectors between them.
+//
+//   - review_edit: the hotspot began after a lane or zone was edited, with
+//     good days before it. The edit is the likeliest cause and the cheapest
+//     thing to undo, so it leads.
+//   - switch_class: a hotspot in a ReflectorArea. Declaring reflector
+//     localization where the laser cannot see reflectors is what the class
+//     finding measures; re-declaring the zone is a map edit.
+//   - add_reflectors: a hotspot the map cannot fix — reflectors inside a
+//     ReflectorArea that has none (the alternative to switching it), or along
+//     a lane no reflector zone covers.
+//
+// The reflector count is EVIDENCE, never a score. Measured, it does not
+// predict the miss rate (scenemap.ReflectorsInside says why), so it decides
+// which sentence a fix carries, not where the fix ranks. Fixes rank by the
+// weak readings they address, a map edit before an installation on a tie.
+package mapfix
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"shingocore/scenemap"
+)
+
+const (
+	// daySamplesMin is the traffic a day needs before it is judged at all —
+	// the board's own minimum (service.BoardMinSamples), so a day the board
+	// greys is a day this does not count.
+	daySamplesMin = 20
+	// weakShareMin is the share of weak readings that makes a day weak.
+	weakShareMin = 0.20
+	// badDaysMin is how many weak days make a hotspot.
+	badDaysMin = 3
+	// robotsMin is how many robots a hotspot's weak days must have seen.
+	robotsMin = 2
+	// laneCorridor is how far from a lane a robot is still on it, in metres —
+	// the roll-up's snap tolerance.
+	laneCorridor = 1.0
+)
+
+// Actions, in the order a tie is broken.
+const (
+	ActionReviewEdit    = "review_edit"
+	ActionSwitchClass   = "switch_class"
+	ActionAddReflectors = "add_reflectors"
+)
+
+// Efforts. A map edit is an afternoon in the vendor's editor and undoes; an
+// install is a work order.
+const (
+	EffortMapEdit = "map_edit"
+	EffortInstall = "install"
+)
+
+// Hotspot kinds.
+const (
+	KindZone = "zone"
+	KindLane = "lane"
+)
+
+// Day is one lane's or zone's rolled-up day. Weak counts the no-estimates
+// plus the readings under 0.30.
+type Day struct {
+	Day      time.Time
+	Samples  int
+	Weak     int
+	Sentinel int
+	Robots   int
+}
+
+// Edit is one change to a lane or zone inside the window.
+type Edit struct {
+	At     time.Time
+	DiffID int64
+	MovedM *float64
+}
+
+// Zone is one declared zone: its class and outline as the map stands at the
+// end of the window, and its days. Polygon is nil when the .smap has not been
+// fetched, which leaves the class (from the roll-up) and no way to count
+// reflectors.
+type Zone struct {
+	Name    string
+	Class   string
+	Polygon []scenemap.Point
+	Edits   []Edit
+	Days    []Day
+}
+
+// Lane is one physical lane: its endpoints and midpoint on the curve, and its
+// days. HasGeometry is false for a lane the scene no longer carries.
+type Lane struct {
+	Area        string
+	Lane        string
+	From, Mid   scenemap.Point
+	To          scenemap.Point
+	HasGeometry bool
+	Edits       []Edit
+	Days        []Day
+}
+
+// Input is everything a recommendation reads. Reflectors are the map's
+// reflector positions at the start and the end of the window.
+type Input struct {
+	Zones           []Zone
+	Lanes           []Lane
+	ReflectorsStart []scenemap.Point
+	ReflectorsEnd   []scenemap.Point
+}
+
+// Hotspot is one lane or zone the fleet persistently loses its position in.
+type Hotspot struct {
+	Key   string `json:"key"`
+	Kind  string `json:"kind"`
+	Area  string `json:"area,omitempty"`
+	Name  string `json:"name"`
+	Class string `json:"class,omitempty"`
+	// Where is the zone's centre or the lane's midpoint; nil with no geometry.
+	Where   *scenemap.Point  `json:"where,omitempty"`
+	Polygon []scenemap.Point `json:"polygon,omitempty"`
+	// From and To are the lane's ends, for Covers.
+	From *scenemap.Point `json:"from,omitempty"`
+	To   *scenemap.Point `json:"to,omitempty"`
+	// Zones are the declared zones a lane's midpoint falls in.
+	Zones    []string  `json:"zones,omitempty"`
+	Days     int       `json:"days"`
+	BadDays  int       `json:"bad_days"`
+	Samples  int       `json:"samples"`
+	Weak     int       `json:"weak"`
+	Sentinel int       `json:"sentinel"`
+	WeakRate float64   `json:"weak_rate"`
+	Robots   int       `json:"robots"`
+	FirstBad time.Time `json:"first_bad"`
+	LastBad  time.Time `json:"last_bad"`
+	// Fix is the title of the highest-ranked fix that cites this hotspot, so
+	// an alert can say what to do without the whole report.
+	Fix string `json:"fix,omitempty"`
+}
+
+// Label is how a hotspot reads in a sentence: "zone 08", "lane LM13-LM14".
+func (h Hotspot) Label() string { return h.Kind + " " + h.Name }
+
+// Covers reports whether a robot at (x, y) is in the hotspot: inside a
+// zone's outline, or within the snap tolerance of a lane's path through its
+// midpoint. Always false with no geometry.
+func (h Hotspot) Covers(x, y float64) bool {
+	p := scenemap.Point{X: x, Y: y}
+	switch {
+	case h.Polygon != nil:
+		return scenemap.PointInPolygon(p, h.Polygon)
+	case h.From != nil && h.To != nil && h.Where != nil:
+		return toSegment(p, *h.From, *h.Where) <= laneCorridor ||
+			toSegment(p, *h.Where, *h.To) <= laneCorridor
+	}
+	return false
+}
+
+// Fix is one recommended change, with what it rests on.
+type Fix struct {
+	Rank   int    `json:"rank"`
+	Action string `json:"action"`
+	Effort string `json:"effort"`
+	Kind   string `json:"kind"`
+	Area   string `json:"area,omitempty"`
+	Name   string `json:"name"`
+	Title  string `json:"title"`
+	// Where is where to stand; nil when the subject has no geometry.
+	Where *scenemap.Point `json:"where,omitempty"`
+	// Impact is the weak readings over the window the fix addresses — the
+	// subject's own when it is a hotspot, else the hotspot lanes inside it.
+	Impact   int      `json:"impact"`
+	Evidence []string `json:"evidence"`
+	Hotspots []string `json:"hotspots"`
+	// DiffID is the edit a review_edit points at.
+	DiffID int64 `json:"diff_id,omitempty"`
+}
+
+// Report is the hotspots, worst first, and the fixes, ranked.
+type Report struct {
+	Hotspots []Hotspot `json:"hotspots"`
+	Fixes    []Fix     `json:"fixes"`
+}
+
+// Recommend finds the hotspots in, and the fixes for them.
+func Recommend(in Input) Report {
+	r := recommender{in: in, fixes: map[string]*Fix{}, zones: map[string]*Zone{}, zoneHot: map[string]*Hotspot{}}
+	for i := range in.Zones {
+		r.zones[in.Zones[i].Name] = &in.Zones[i]
+	}
+	r.baseline = classBaseline(in.Zones, scenemap.ClassLocConfigArea)
+
+	hot := []Hotspot{}
+	for _, z := range in.Zones {
+		if h, ok := zoneHotspot(z); ok {
+			hot = append(hot, h)
+		}
+	}
+	for _, l := range in.Lanes {
+		if h, ok := r.laneHotspot(l); ok {
+			hot = append(hot, h)
+		}
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		if hot[i].Weak != hot[j].Weak {
+			return hot[i].Weak > hot[j].Weak
+		}
+		return hot[i].Key < hot[j].Key
+	})
+	// Zones first, so a lane inside a hotspot zone joins the zone's fixes
+	// rather than adding its readings to them twice.
+	for i := range hot {
+		if hot[i].Kind == KindZone {
+			r.zoneHot[hot[i].Name] = &hot[i]
+			r.zoneFixes(hot[i])
+		}
+	}
+	for i := range hot {
+		if hot[i].Kind == KindLane {
+			r.laneFixes(hot[i])
+		}
+	}
+
+	out := Report{Hotspots: hot, Fixes: r.ranked()}
+	for i := range out.Hotspots {
+		for _, f := range out.Fixes {
+			if contains(f.Hotspots, out.Hotspots[i].Key) {
+				out.Hotspots[i].Fix = f.Title
+				break
+			}
+		}
+	}
+	return out
+}
+
+type recommender struct {
+	in       Input
+	fixes    map[string]*Fix
+	zones    map[string]*Zone
+	zoneHot  map[string]*Hotspot
+	baseline *float64
+	lanes    map[string]Lane
+}
+
+// judge reads a subject's days against the hotspot rules.
+func judge(days []Day) (h Hotspot, ok bool) {
+	robots := 0
+	for _, d := range days {
+		h.Samples += d.Samples
+		h.Weak += d.Weak
+		h.Sentinel += d.Sentinel
+		if d.Samples < daySamplesMin {
+			continue
+		}
+		h.Days++
+		if float64(d.Weak) < weakShareMin*float64(d.Samples) {
+			continue
+		}
+		h.BadDays++
+		robots = max(robots, d.Robots)
+		if h.FirstBad.IsZero() {
+			h.FirstBad = d.Day
+		}
+		h.LastBad = d.Day
+	}
+	h.Robots = robots
+	if h.Samples > 0 {
+		h.WeakRate = float64(h.Weak) / float64(h.Samples)
+	}
+	return h, h.BadDays >= badDaysMin && 2*h.BadDays >= h.Days && robots >= robotsMin
+}
+
+func zoneHotspot(z Zone) (Hotspot, bool) {
+	h, ok := judge(z.Days)
+	if !ok {
+		return h, false
+	}
+	h.Key, h.Kind, h.Name, h.Class = "zone:"+z.Name, KindZone, z.Name, z.Class
+	if len(z.Polygon) >= 3 {
+		h.Polygon = z.Polygon
+		minX, minY, maxX, maxY := scenemap.Bounds(z.Polygon)
+		h.Where = &scenemap.Point{X: (minX + maxX) / 2, Y: (minY + maxY) / 2}
+	}
+	return h, true
+}
+
+func (r *recommender) laneHotspot(l Lane) (Hotspot, bool) {
+	h, ok := judge(l.Days)
+	if !ok {
+		return h, false
+	}
+	if r.lanes == nil {
+		r.lanes = map[string]Lane{}
+	}
+	h.Key, h.Kind, h.Area, h.Name = "lane:"+l.Area+"/"+l.Lane, KindLane, l.Area, l.Lane
+	r.lanes[h.Key] = l
+	if l.HasGeometry {
+		from, mid, to := l.From, l.Mid, l.To
+		h.From, h.Where, h.To = &from, &mid, &to
+		for _, z := range r.in.Zones {
+			if len(z.Polygon) >= 3 && scenemap.PointInPolygon(mid, z.Polygon) {
+				h.Zones = append(h.Zones, z.Name)
+			}
+		}
+		sort.Strings(h.Zones)
+	}
+	return h, true
+}
+
+// zoneFixes recommends for a hotspot zone.
+func (r *recommender) zoneFixes(h Hotspot) {
+	z := r.zones[h.Name]
+	lost := lostSentence(h)
+	if e, ok := editCause(z.Edits, z.Days, h.FirstBad); ok {
+		r.add(Fix{
+			Action: ActionReviewEdit, Effort: EffortMapEdit, Kind: KindZone, Name: z.Name,
+			Title: fmt.Sprintf("Review the %s edit to zone %s", e.At.UTC().Format("2006-01-02"), z.Name),
+			Where: h.Where, DiffID: e.DiffID,
+			Evidence: []string{lost, editSentence("zone "+z.Name, e, z.Days)},
+		}, h.Key, h.Weak)
+	}
+	if z.Class == scenemap.ClassReflectorArea {
+		r.reflectorZoneFixes(z, h.Where, h.Key, h.Weak, lost)
+		return
+	}
+	ev := []string{lost, fmt.Sprintf("it is declared %s, not a reflector zone", orUnknown(z.Class))}
+	if z.Polygon != nil {
+		ev = append(ev, reflectorSentence(r.in, z))
+	}
+	r.add(Fix{
+		Action: ActionAddReflectors, Effort: EffortInstall, Kind: KindZone, Name: z.Name,
+		Title: fmt.Sprintf("Install reflectors in zone %s and declare it a %s", z.Name, scenemap.ClassReflectorArea),
+		Where: h.Where, Evidence: ev,
+	}, h.Key, h.Weak)
+}
+
+// reflectorZoneFixes is the pair every hotspot in a ReflectorArea gets: stop
+// declaring reflectors, or install them. lost is the sentence on the readings
+// that put the zone here — its own, or a lane's inside it.
+func (r *recommender) reflectorZoneFixes(z *Zone, where *scenemap.Point, key string, impact int, lost string) {
+	switchEv := []string{lost, fmt.Sprintf("zone %s declares reflector localization (%s)", z.Name, scenemap.ClassReflectorArea)}
+	if r.baseline != nil {
+		switchEv = append(switchEv, fmt.Sprintf("%s zones were weak on %s of their readings over the same days",
+			scenemap.ClassLocConfigArea, pct(*r.baseline)))
+	}
+	if z.Polygon == nil {
+		switchEv = append(switchEv, "the zone's outline has not been fetched from the robot, so its reflectors cannot be counted")
+	} else {
+		switchEv = append(switchEv, reflectorSentence(r.in, z))
+	}
+	r.add(Fix{
+		Action: ActionSwitchClass, Effort: EffortMapEdit, Kind: KindZone, Name: z.Name,
+		Title: fmt.Sprintf("Switch zone %s from %s to %s", z.Name, scenemap.ClassReflectorArea, scenemap.ClassLocConfigArea),
+		Where: where, Evidence: switchEv,
+	}, key, impact)
+	if z.Polygon == nil {
+		return
+	}
+	addEv := []string{lost, reflectorSentence(r.in, z)}
+	if removed := countInside(r.in.ReflectorsStart, z.Polygon) - countInside(r.in.ReflectorsEnd, z.Polygon); removed > 0 {
+		addEv = append(addEv, fmt.Sprintf("%d reflector(s) inside it left the map during the window", removed))
+	}
+	title := fmt.Sprintf("Install reflectors in zone %s", z.Name)
+	if countInside(r.in.ReflectorsEnd, z.Polygon) > 0 {
+		title = fmt.Sprintf("Add reflectors in zone %s", z.Name)
+	}
+	r.add(Fix{
+		Action: ActionAddReflectors, Effort: EffortInstall, Kind: KindZone, Name: z.Name,
+		Title: title, Where: where, Evidence: addEv,
+	}, key, impact)
+}
+
+// laneFixes recommends for a hotspot lane.
+func (r *recommender) laneFixes(h Hotspot) {
+	l := r.lanes[h.Key]
+	lost := lostSentence(h)
+	if e, ok := editCause(l.Edits, l.Days, h.FirstBad); ok {
+		r.add(Fix{
+			Action: ActionReviewEdit, Effort: EffortMapEdit, Kind: KindLane, Area: l.Area, Name: l.Lane,
+			Title: fmt.Sprintf("Review the %s edit to lane %s", e.At.UTC().Format("2006-01-02"), l.Lane),
+			Where: h.Where, DiffID: e.DiffID,
+			Evidence: []string{lost, editSentence("lane "+l.Lane, e, l.Days)},
+		}, h.Key, h.Weak)
+	}
+	for _, name := range h.Zones {
+		z := r.zones[name]
+		if z.Class != scenemap.ClassReflectorArea {
+			continue
+		}
+		if zh := r.zoneHot[name]; zh != nil {
+			// The zone's own readings already include the lane's; the lane
+			// joins as evidence and its readings are not counted twice.
+			r.cite(ActionSwitchClass, KindZone, name, h.Key, "lane "+h.Name+" inside it: "+lost)
+			r.cite(ActionAddReflectors, KindZone, name, h.Key, "lane "+h.Name+" inside it: "+lost)
+			return
+		}
+		var where *scenemap.Point
+		if minX, minY, maxX, maxY := scenemap.Bounds(z.Polygon); z.Polygon != nil {
+			where = &scenemap.Point{X: (minX + maxX) / 2, Y: (minY + maxY) / 2}
+		}
+		r.reflectorZoneFixes(z, where, h.Key, h.Weak, "lane "+h.Name+" inside it: "+lost)
+		return
+	}
+	ev := []string{lost}
+	switch {
+	case !l.HasGeometry:
+		ev = append(ev, "the lane is no longer in the synced scene")
+	case len(h.Zones) == 0:
+		ev = append(ev, "no declared zone covers it, so the robot has only the laser's view of the walls here")
+	default:
+		ev = append(ev, "it runs through zone(s) "+join(h.Zones)+", none of them a reflector zone")
+	}
+	if l.HasGeometry {
+		ev = append(ev, fmt.Sprintf("%d reflector(s) on the map within %g m of its midpoint",
+			countNear(r.in.ReflectorsEnd, l.Mid, nearReflector), nearReflector))
+	}
+	r.add(Fix{
+		Action: ActionAddReflectors, Effort: EffortInstall, Kind: KindLane, Area: l.Area, Name: l.Lane,
+		Title: fmt.Sprintf("Install reflectors along lane %s", l.Lane),
+		Where: h.Where, Evidence: ev,
+	}, h.Key, h.Weak)
+}
+
+// nearReflector is the radius a lane's reflectors are counted within, in
+// metres — about what the vendor's scanner resolves a reflector at.
+const nearReflector = 15.0
+
+// add merges a fix into the report: the same action on the same subject is
+// one fix, carrying every hotspot it addresses.
+func (r *recommender) add(f Fix, hotspot string, impact int) {
+	id := f.Action + "\x00" + f.Kind + "\x00" + f.Area + "\x00" + f.Name
+	if have := r.fixes[id]; have != nil {
+		if !contains(have.Hotspots, hotspot) {
+			have.Hotspots = append(have.Hotspots, hotspot)
+			have.Impact += impact
+			have.Evidence = append(have.Evidence, f.Evidence[0])
+		}
+		return
+	}
+	f.Hotspots = []string{hotspot}
+	f.Impact = impact
+	r.fixes[id] = &f
+}
+
+// cite attaches a hotspot to an existing fix as evidence only.
+func (r *recommender) cite(action, kind, name, hotspot, sentence string) {
+	if f := r.fixes[action+"\x00"+kind+"\x00\x00"+name]; f != nil && !contains(f.Hotspots, hotspot) {
+		f.Hotspots = append(f.Hotspots, hotspot)
+		f.Evidence = append(f.Evidence, sentence)
+	}
+}
+
+func (r *recommender) ranked() []Fix {
+	out := make([]Fix, 0, len(r.fixes))
+	for _, f := range r.fixes {
+		out = append(out, *f)
+	}
+	order := map[string]int{ActionReviewEdit: 0, ActionSwitchClass: 1, ActionAddReflectors: 2}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Impact != b.Impact {
+			return a.Impact > b.Impact
+		}
+		if a.Effort != b.Effort {
+			return a.Effort == EffortMapEdit
+		}
+		if order[a.Action] != order[b.Action] {
+			return order[a.Action] < order[b.Action]
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Area+a.Name < b.Area+b.Name
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+	return out
+}
+
+// editCause returns the edit a hotspot began after: the latest edit on or
+// before its first weak day, with at least one judged day before the edit and
+// none of those weak. A hotspot that was already weak before the edit was not
+// caused by it, and one with no day before it cannot say.
+func editCause(edits []Edit, days []Day, firstBad time.Time) (Edit, bool) {
+	var cause Edit
+	found := false
+	for _, e := range edits {
+		if day := e.At.UTC().Truncate(24 * time.Hour); !day.After(firstBad) {
+			cause, found = e, true
+		}
+	}
+	if !found {
+		return cause, false
+	}
+	editDay := cause.At.UTC().Truncate(24 * time.Hour)
+	before := 0
+	for _, d := range days {
+		if !d.Day.Before(editDay) || d.Samples < daySamplesMin {
+			continue
+		}
+		if float64(d.Weak) >= weakShareMin*float64(d.Samples) {
+			return cause, false
+		}
+		before++
+	}
+	return cause, before > 0
+}
+
+// classBaseline is the weak share over every zone of a class, or nil when no
+// zone of it carried traffic.
+func classBaseline(zones []Zone, class string) *float64 {
+	var weak, samples int
+	for _, z := range zones {
+		if z.Class != class {
+			continue
+		}
+		for _, d := range z.Days {
+			weak += d.Weak
+			samples += d.Samples
+		}
+	}
+	if samples < daySamplesMin {
+		return nil
+	}
+	v := float64(weak) / float64(samples)
+	return &v
+}
+
+func lostSentence(h Hotspot) string {
+	return fmt.Sprintf("%d of %d readings weak or without an estimate (%s), on %d of %d days, across %d robots",
+		h.Weak, h.Samples, pct(h.WeakRate), h.BadDays, h.Days, h.Robots)
+}
+
+func editSentence(subject string, e Edit, days []Day) string {
+	editDay := e.At.UTC().Truncate(24 * time.Hour)
+	before := 0
+	for _, d := range days {
+		if d.Day.Before(editDay) && d.Samples >= daySamplesMin {
+			before++
+		}
+	}
+	s := fmt.Sprintf("%s was edited %s (diff #%d)", subject, e.At.UTC().Format("2006-01-02 15:04"), e.DiffID)
+	if e.MovedM != nil {
+		s += fmt.Sprintf(", moving up to %.2f m", *e.MovedM)
+	}
+	return s + fmt.Sprintf("; it was not weak on any of the %d days before", before)
+}
+
+func reflectorSentence(in Input, z *Zone) string {
+	n := countInside(in.ReflectorsEnd, z.Polygon)
+	if n == 0 {
+		return fmt.Sprintf("zone %s holds no reflectors on the map", z.Name)
+	}
+	return fmt.Sprintf("zone %s holds %d reflector(s) on the map", z.Name, n)
+}
+
+func countInside(pts []scenemap.Point, poly []scenemap.Point) int {
+	n := 0
+	for _, p := range pts {
+		if scenemap.PointInPolygon(p, poly) {
+			n++
+		}
+	}
+	return n
+}
+
+func countNear(pts []scenemap.Point, at scenemap.Point, radius float64) int {
+	n := 0
+	for _, p := range pts {
+		if math.Hypot(p.X-at.X, p.Y-at.Y) <= radius {
+			n++
+		}
+	}
+	return n
+}
+
+// toSegment is the distance from p to the segment a-b.
+func toSegment(p, a, b scenemap.Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lenSq := dx*dx + dy*dy
+	if lenSq == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lenSq))
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
+
+func pct(v float64) string { return fmt.Sprintf("%.0f%%", v*100) }
+
+func orUnknown(class string) string {
+	if class == "" {
+		return "with no known class"
+	}
+	return class
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func join(list []string) string {
+	out := ""
+	for i, s := range list {
+		if i > 0 {
+			out += ", "
+		}
+		out += s
+	}
+	return out
+}
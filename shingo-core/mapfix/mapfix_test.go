@@ -0,0 +1,198 @@
+package mapfix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shingocore/scenemap"
+)
+
+var day0 = time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+
+// week is seven days of the same traffic; bad lists the days (0-6) that lose
+// weak of their hundred readings.
+func week(robots, weak int, bad ...int) []Day {
+	out := make([]Day, 7)
+	for i := range out {
+		out[i] = Day{Day: day0.AddDate(0, 0, i), Samples: 100, Robots: robots}
+		for _, b := range bad {
+			if b == i {
+				out[i].Weak, out[i].Sentinel = weak, weak/2
+			}
+		}
+	}
+	return out
+}
+
+func allDays() []int { return []int{0, 1, 2, 3, 4, 5, 6} }
+
+// springfield is the real map's zones and reflectors, from scenemap's own
+// fixture, with the days each test supplies.
+func springfield(t *testing.T, days map[string][]Day) Input {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.FromSlash("../scenemap/testdata/spramrmap-trimmed.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	m, err := scenemap.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var in Input
+	for _, a := range m.Areas {
+		in.Zones = append(in.Zones, Zone{Name: a.Name, Class: a.Class, Polygon: a.Polygon, Days: days[a.Name]})
+	}
+	for _, r := range m.Reflectors {
+		in.ReflectorsStart = append(in.ReflectorsStart, scenemap.Point{X: r.X, Y: r.Y})
+	}
+	in.ReflectorsEnd = in.ReflectorsStart
+	return in
+}
+
+func lane(area, name string, from, to scenemap.Point, days []Day, edits ...Edit) Lane {
+	mid := scenemap.Point{X: (from.X + to.X) / 2, Y: (from.Y + to.Y) / 2}
+	return Lane{Area: area, Lane: name, From: from, Mid: mid, To: to, HasGeometry: true, Days: days, Edits: edits}
+}
+
+// THE REGRESSION, against Springfield's real map. Zone 08 is a ReflectorArea
+// holding no reflectors and losing 40% every day: both fixes, the map edit
+// first. Zone 05 is the LocConfigArea that loses nothing: no fix, and it is
+// the baseline the switch quotes. Zone 09 loses more than 08 but only one
+// robot ever drives it: a robot problem, no fix. Zone 10 had one bad day: an
+// incident, no fix. A lane that went weak the day after it was edited gets the
+// edit reviewed ahead of the install, and a lane inside 08 joins 08's fixes
+// without counting its readings twice.
+func TestRecommend_SpringfieldRanksTheFixes(t *testing.T) {
+	t.Parallel()
+	in := springfield(t, map[string][]Day{
+		"08": week(3, 40, allDays()...),
+		"05": week(3, 0),
+		"09": week(1, 60, allDays()...),
+		"10": week(3, 90, 4),
+	})
+	edited := Edit{At: day0.AddDate(0, 0, 3).Add(14 * time.Hour), DiffID: 42}
+	in.Lanes = []Lane{
+		lane("area-a", "LM20-LM21", scenemap.Point{X: 20, Y: 10}, scenemap.Point{X: 30, Y: 10},
+			week(2, 30, 3, 4, 5, 6), edited),
+		lane("area-a", "LM8-LM9", scenemap.Point{X: -0.8, Y: 0.6}, scenemap.Point{X: -0.8, Y: 2.4},
+			week(2, 50, allDays()...)),
+	}
+
+	rep := Recommend(in)
+
+	type want struct {
+		action, kind, name string
+		impact             int
+	}
+	wants := []want{
+		{ActionSwitchClass, KindZone, "08", 280},
+		{ActionAddReflectors, KindZone, "08", 280},
+		{ActionReviewEdit, KindLane, "LM20-LM21", 120},
+		{ActionAddReflectors, KindLane, "LM20-LM21", 120},
+	}
+	if len(rep.Fixes) != len(wants) {
+		for _, f := range rep.Fixes {
+			t.Logf("%d %s %s %s %d", f.Rank, f.Action, f.Kind, f.Name, f.Impact)
+		}
+		t.Fatalf("fixes = %d, want %d", len(rep.Fixes), len(wants))
+	}
+	for i, w := range wants {
+		f := rep.Fixes[i]
+		if f.Rank != i+1 || f.Action != w.action || f.Kind != w.kind || f.Name != w.name || f.Impact != w.impact {
+			t.Errorf("fix %d = #%d %s %s %s impact %d, want %+v", i, f.Rank, f.Action, f.Kind, f.Name, f.Impact, w)
+		}
+	}
+
+	sw := rep.Fixes[0]
+	if !hasEvidence(sw, "holds no reflectors") || !hasEvidence(sw, "LocConfigArea zones were weak on 0%") {
+		t.Errorf("switch evidence = %q, want the empty zone and the class baseline", sw.Evidence)
+	}
+	if len(sw.Hotspots) != 2 || !hasEvidence(sw, "lane LM8-LM9 inside it") {
+		t.Errorf("switch cites %v, want zone 08 and the lane inside it", sw.Hotspots)
+	}
+	if rev := rep.Fixes[2]; rev.DiffID != 42 || !hasEvidence(rev, "not weak on any of the 3 days before") {
+		t.Errorf("review = %+v, want diff 42 with three good days before", rev)
+	}
+	if add := rep.Fixes[3]; !hasEvidence(add, "no declared zone covers it") {
+		t.Errorf("lane install evidence = %q", add.Evidence)
+	}
+
+	var keys []string
+	for _, h := range rep.Hotspots {
+		keys = append(keys, h.Key)
+		if h.Fix == "" {
+			t.Errorf("hotspot %s carries no fix", h.Key)
+		}
+	}
+	if strings.Join(keys, ",") != "lane:area-a/LM8-LM9,zone:08,lane:area-a/LM20-LM21" {
+		t.Errorf("hotspots = %v, want them worst first", keys)
+	}
+}
+
+// An edit AFTER the trouble started did not cause it, and neither did one
+// with no good day before it to compare against.
+func TestRecommend_EditOnlyLeadsWhenTheLaneWasFineBefore(t *testing.T) {
+	t.Parallel()
+	from, to := scenemap.Point{X: 20, Y: 10}, scenemap.Point{X: 30, Y: 10}
+	cases := map[string]Lane{
+		"edited after": lane("a", "L1", from, to, week(2, 30, allDays()...),
+			Edit{At: day0.AddDate(0, 0, 2), DiffID: 7}),
+		"edited before the window": lane("a", "L2", from, to, week(2, 30, allDays()...),
+			Edit{At: day0, DiffID: 8}),
+	}
+	for name, l := range cases {
+		rep := Recommend(Input{Lanes: []Lane{l}})
+		for _, f := range rep.Fixes {
+			if f.Action == ActionReviewEdit {
+				t.Errorf("%s: got a review_edit (%s)", name, f.Title)
+			}
+		}
+		if len(rep.Fixes) != 1 || rep.Fixes[0].Action != ActionAddReflectors {
+			t.Errorf("%s: fixes = %+v, want the install alone", name, rep.Fixes)
+		}
+	}
+}
+
+// A hotspot answers for where a robot is: inside a zone's outline, or along a
+// lane within the snap tolerance.
+func TestHotspot_Covers(t *testing.T) {
+	t.Parallel()
+	rep := Recommend(Input{
+		Zones: []Zone{{Name: "08", Class: scenemap.ClassReflectorArea,
+			Polygon: []scenemap.Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}},
+			Days:    week(2, 40, allDays()...)}},
+		Lanes: []Lane{lane("a", "L1", scenemap.Point{X: 10, Y: 0}, scenemap.Point{X: 20, Y: 0},
+			week(2, 40, allDays()...))},
+	})
+	if len(rep.Hotspots) != 2 {
+		t.Fatalf("hotspots = %+v, want the zone and the lane", rep.Hotspots)
+	}
+	for _, c := range []struct {
+		x, y float64
+		want string
+	}{
+		{1, 1, "zone:08"}, {15, 0.8, "lane:a/L1"}, {15, 1.5, ""}, {5, 0, ""},
+	} {
+		got := ""
+		for _, h := range rep.Hotspots {
+			if h.Covers(c.x, c.y) {
+				got = h.Key
+			}
+		}
+		if got != c.want {
+			t.Errorf("(%g, %g) covered by %q, want %q", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func hasEvidence(f Fix, sub string) bool {
+	for _, e := range f.Evidence {
+		if strings.Contains(e, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -84,6 +84,9 @@ type Client struct {
 type kafkaState struct {
 	readers map[string]*kafka.Reader
 	writer  *kafka.Writer
+	// dialer carries the TLS and SASL settings every reader is created with,
+	// including the ones readLoop recreates after an error.
+	dialer *kafka.Dialer
 }
 
 func NewClient(cfg *config.MessagingConfig) *Client {
@@ -107,6 +110,14 @@ func (c *Client) Connect() error {
 	if len(c.cfg.Kafka.Brokers) == 0 {
 		return fmt.Errorf("no kafka brokers configured")
 	}
+	dialer, err := newDialer(c.cfg.Kafka)
+	if err != nil {
+		return err
+	}
+	transport, err := newTransport(c.cfg.Kafka)
+	if err != nil {
+		return err
+	}
 
 	// Verify at least one broker is reachable
 	var conn *kafka.Conn
@@ -114,7 +125,7 @@ func (c *Client) Connect() error {
 	for _, broker := range c.cfg.Kafka.Brokers {
 		c.dbg("connect: probing broker %s", broker)
 		ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Kafka.DialTimeoutOr())
-		conn, connErr = kafka.DialContext(ctx, "tcp", broker)
+		conn, connErr = dialer.DialContext(ctx, "tcp", broker)
 		cancel()
 		if connErr == nil {
 			log.Printf("messaging: kafka connected to %s", broker)
@@ -128,13 +139,15 @@ func (c *Client) Connect() error {
 	}
 
 	// Ensure configured topics exist before setting up readers/writer
-	c.ensureTopics(conn, c.cfg.OrdersTopic, c.cfg.DispatchTopic)
+	c.ensureTopics(dialer, conn, c.cfg.OrdersTopic, c.cfg.DispatchTopic)
 	conn.Close()
 
 	c.kafka = &kafkaState{
 		readers: make(map[string]*kafka.Reader),
+		dialer:  dialer,
 		writer: &kafka.Writer{
 			Addr:         kafka.TCP(c.cfg.Kafka.Brokers...),
+			Transport:    transport,
 			Balancer:     &kafka.Hash{},
 			RequiredAcks: kafka.RequireOne,
 			BatchTimeout: writerBatchTimeout,
@@ -174,7 +187,7 @@ func (c *Client) Publish(topic string, payload []byte) error {
 // Requires a live connection to any broker; uses it to discover the
 // controller and issue CreateTopics. Errors are logged but not fatal
 // since the broker may have auto.create.topics.enable=true anyway.
-func (c *Client) ensureTopics(conn *kafka.Conn, topics ...string) {
+func (c *Client) ensureTopics(dialer *kafka.Dialer, conn *kafka.Conn, topics ...string) {
 	if len(topics) == 0 {
 		return
 	}
@@ -186,7 +199,7 @@ func (c *Client) ensureTopics(conn *kafka.Conn, topics ...string) {
 	}
 
 	controllerAddr := net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port))
-	controllerConn, err := kafka.Dial("tcp", controllerAddr)
+	controllerConn, err := dialer.Dial("tcp", controllerAddr)
 	if err != nil {
 		log.Printf("messaging: cannot connect to controller: %v", err)
 		return
@@ -222,6 +235,7 @@ func (c *Client) Subscribe(topic string, handler MessageHandler) error {
 		Brokers: c.cfg.Kafka.Brokers,
 		Topic:   topic,
 		GroupID: c.cfg.Kafka.GroupID,
+		Dialer:  c.kafka.dialer,
 	})
 	c.kafka.readers[topic] = reader
 	c.dbg("subscribe: topic=%s group=%s", topic, c.cfg.Kafka.GroupID)
@@ -267,10 +281,15 @@ func (c *Client) readLoop(topic string, reader *kafka.Reader, handler MessageHan
 			// Recreate the reader
 			c.mu.Lock()
 			reader.Close()
+			var dialer *kafka.Dialer
+			if c.kafka != nil {
+				dialer = c.kafka.dialer
+			}
 			reader = kafka.NewReader(kafka.ReaderConfig{
 				Brokers: c.cfg.Kafka.Brokers,
 				Topic:   topic,
 				GroupID: c.cfg.Kafka.GroupID,
+				Dialer:  dialer,
 			})
 			if c.kafka != nil {
 				c.kafka.readers[topic] = reader
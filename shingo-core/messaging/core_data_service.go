@@ -74,6 +74,9 @@ type CoreDataService struct {
 	// (Phase E). Optional; nil in tests and headless runs. Set once before
 	// StartHeartbeatProjection, so the worker reads it race-free.
 	cellTickEmitter func(station string, processID, styleID int64, recordedAt time.Time)
+	// linesideEmitter, if set, fires after a lineside level report is
+	// persisted — the SSE lineside-update. Optional, like cellTickEmitter.
+	linesideEmitter func(station string, payloads []string)
 	// faultGrace / faultNoticeAfter are config durations echoed onto faulted
 	// order snapshots so a reconciling Edge can render the fault line and its
 	// clock. Optional (see SetFaultWindow); zero means the snapshot carries the
@@ -110,6 +113,13 @@ func (s *CoreDataService) SetCellTickEmitter(fn func(station string, processID,
 	s.cellTickEmitter = fn
 }
 
+// SetLinesideEmitter wires a callback invoked after each lineside level report
+// is persisted. The composition root points it at the engine event bus, which
+// SetupEngineListeners rebroadcasts as the SSE lineside-update. Optional.
+func (s *CoreDataService) SetLinesideEmitter(fn func(station string, payloads []string)) {
+	s.linesideEmitter = fn
+}
+
 // NewCoreDataService constructs a CoreDataService. The TagVerifyService is
 // built internally from the same *store.DB so the constructor signature
 // stays minimal. Subject-router registration is the composition root's
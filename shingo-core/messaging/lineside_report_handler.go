@@ -55,4 +55,7 @@ func (s *CoreDataService) HandleLinesideLevelReport(env *protocol.Envelope, r *p
 	if s.thresholdMonitor != nil && len(payloads) > 0 {
 		s.thresholdMonitor.OnLinesideReports(payloads)
 	}
+	if s.linesideEmitter != nil && len(payloads) > 0 {
+		s.linesideEmitter(station, payloads)
+	}
 }
@@ -0,0 +1,38 @@
+package messaging
+
+import (
+	"github.com/segmentio/kafka-go"
+
+	"shingo/shared/kafkasec"
+	"shingocore/config"
+)
+
+// Every broker connection — the probe, topic creation, readers and the
+// writer — gets its TLS and SASL from shared/kafkasec, which the Edge uses
+// too. This adapts Core's KafkaConfig to it.
+
+// kafkaSecurity validates k and returns its security settings.
+func kafkaSecurity(k config.KafkaConfig) (kafkasec.Config, error) {
+	if err := k.Validate(); err != nil {
+		return kafkasec.Config{}, err
+	}
+	return kafkasec.Config{TLS: kafkasec.TLSConfig(k.TLS), SASL: kafkasec.SASLConfig(k.SASL)}, nil
+}
+
+// newDialer returns the dialer for the probe, topic creation and readers.
+func newDialer(k config.KafkaConfig) (*kafka.Dialer, error) {
+	c, err := kafkaSecurity(k)
+	if err != nil {
+		return nil, err
+	}
+	return kafkasec.Dialer(c, k.DialTimeoutOr())
+}
+
+// newTransport returns the writer's transport, secured the same way.
+func newTransport(k config.KafkaConfig) (*kafka.Transport, error) {
+	c, err := kafkaSecurity(k)
+	if err != nil {
+		return nil, err
+	}
+	return kafkasec.Transport(c, k.DialTimeoutOr())
+}
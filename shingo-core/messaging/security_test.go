@@ -0,0 +1,58 @@
+package messaging
+
+import (
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingocore/config"
+)
+
+// The TLS and SASL construction is tested in shared/kafkasec; these check
+// Core's config reaches it: the dial timeout, the settings, and validation.
+
+func TestNewDialer_Plaintext(t *testing.T) {
+	t.Parallel()
+	d, err := newDialer(config.KafkaConfig{DialTimeout: time.Second})
+	testutil.MustNoErr(t, err, "newDialer")
+	if d.TLS != nil || d.SASLMechanism != nil {
+		t.Fatalf("plaintext config produced TLS=%v SASL=%v", d.TLS, d.SASLMechanism)
+	}
+	if d.Timeout != time.Second {
+		t.Errorf("Timeout = %v, want the configured 1s", d.Timeout)
+	}
+}
+
+func TestNewDialerAndTransport_CarryTLSAndSASL(t *testing.T) {
+	t.Parallel()
+	k := config.KafkaConfig{
+		TLS:  config.KafkaTLSConfig{Enabled: true, ServerName: "kafka.plant.local"},
+		SASL: config.KafkaSASLConfig{Mechanism: config.KafkaSASLSCRAMSHA512, Username: "shingocore", Password: "pw"},
+	}
+	d, err := newDialer(k)
+	testutil.MustNoErr(t, err, "newDialer")
+	if d.TLS == nil || d.TLS.ServerName != "kafka.plant.local" || d.SASLMechanism == nil || d.SASLMechanism.Name() != "SCRAM-SHA-512" {
+		t.Fatalf("dialer TLS=%+v SASL=%v", d.TLS, d.SASLMechanism)
+	}
+	if d.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want the 5s default", d.Timeout)
+	}
+	tr, err := newTransport(k)
+	testutil.MustNoErr(t, err, "newTransport")
+	if tr.TLS == nil || tr.SASL == nil || tr.SASL.Name() != "SCRAM-SHA-512" {
+		t.Fatalf("transport TLS=%v SASL=%v — the writer must be secured like the readers", tr.TLS, tr.SASL)
+	}
+}
+
+func TestNewDialer_RejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+	for name, k := range map[string]config.KafkaConfig{
+		"cert without key":  {TLS: config.KafkaTLSConfig{Enabled: true, CertFile: "/etc/shingo/client.pem"}},
+		"unknown mechanism": {SASL: config.KafkaSASLConfig{Mechanism: "gssapi", Username: "u"}},
+		"no username":       {SASL: config.KafkaSASLConfig{Mechanism: config.KafkaSASLPlain}},
+	} {
+		if _, err := newDialer(k); err == nil {
+			t.Errorf("%s: newDialer accepted %+v", name, k)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"shingocore/config"
+)
+
+// Event types a message can carry. A channel's events list and
+// notifications.email_events are written in these words.
+const (
+	EventOrderFaulted = "order_faulted"
+	EventFaultCleared = "order_fault_cleared"
+	EventOrderFailed  = "order_failed"
+	EventGraceExpired = "grace_expired"
+	EventTest         = "test"
+
+	// Raised by the alert rules engine: a new alert, and one nobody
+	// acknowledged inside its rule's escalate_after.
+	EventAlertRaised    = "alert_raised"
+	EventAlertEscalated = "alert_escalated"
+
+	// The end-of-shift operations report, sent when a shift's report is built.
+	EventShiftReport = "shift_report"
+
+	// A robot due for maintenance: one work item, with the fields a CMMS
+	// webhook maps to a work order.
+	EventRobotMaintenance = "robot_maintenance_due"
+)
+
+// Severity levels. Chat channels ignore them; ntfy maps them to priority.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Message is one notification, rendered once and handed to every channel
+// routed for its event.
+//
+// Body is the long plain-text form the email templates produce; Summary is
+// the one line a chat message or a phone notification has room for.
+type Message struct {
+	Event    string
+	Severity string
+	Subject  string
+	Summary  string
+	Body     string
+	Fields   map[string]string
+	Time     time.Time
+
+	// MessageID and InReplyTo thread email (a fault and its clear read as one
+	// conversation). Every other channel ignores them.
+	MessageID string
+	InReplyTo string
+}
+
+// Channel is one notification destination.
+type Channel interface {
+	Name() string
+	Kind() string
+	// Wants reports whether the channel is routed for an event type.
+	Wants(event string) bool
+	Send(ctx context.Context, m Message) error
+}
+
+// permanentError marks a failure retrying cannot fix — a 4xx from the
+// receiver, a template that does not render.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so SendWithRetry gives up on it immediately.
+func Permanent(err error) error { return permanentError{err} }
+
+// IsPermanent reports whether err was marked permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// NewChannel builds a channel from its config. Errors name the channel, so a
+// bad entry in a list of five is findable.
+func NewChannel(c config.NotificationChannel) (Channel, error) {
+	if strings.TrimSpace(c.Name) == "" {
+		return nil, errors.New("notification channel has no name")
+	}
+	if strings.TrimSpace(c.URL) == "" {
+		return nil, fmt.Errorf("channel %q: url is required", c.Name)
+	}
+	tpl, err := parseTemplates(c)
+	if err != nil {
+		return nil, fmt.Errorf("channel %q: %w", c.Name, err)
+	}
+	base := httpChannel{cfg: c, tpl: tpl}
+	switch c.Kind {
+	case "webhook":
+		return &webhookChannel{base}, nil
+	case "slack", "teams":
+		return &chatChannel{base}, nil
+	case "ntfy":
+		return &ntfyChannel{base}, nil
+	default:
+		return nil, fmt.Errorf("channel %q: unknown kind %q (webhook, slack, teams, ntfy)", c.Name, c.Kind)
+	}
+}
+
+// routes reports whether an events list admits an event. Empty admits every
+// event; the test event is always admitted, so a test-send works on a channel
+// routed for one narrow event.
+func routes(events []string, event string) bool {
+	return len(events) == 0 || event == EventTest || slices.Contains(events, event)
+}
+
+// templates is a channel's rendered title and body.
+type templates struct {
+	title *template.Template
+	body  *template.Template
+}
+
+func parseTemplates(c config.NotificationChannel) (templates, error) {
+	var t templates
+	var err error
+	if c.TitleTemplate != "" {
+		if t.title, err = template.New("title").Option("missingkey=zero").Parse(c.TitleTemplate); err != nil {
+			return t, fmt.Errorf("title_template: %w", err)
+		}
+	}
+	if c.BodyTemplate != "" {
+		if t.body, err = template.New("body").Option("missingkey=zero").Parse(c.BodyTemplate); err != nil {
+			return t, fmt.Errorf("body_template: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// render returns the title and body a channel sends for m. Without templates
+// that is the subject and the summary (or the full body when a message has no
+// summary).
+func (t templates) render(m Message) (title, body string, err error) {
+	title = m.Subject
+	body = m.Summary
+	if body == "" {
+		body = m.Body
+	}
+	if t.title != nil {
+		var b bytes.Buffer
+		if err := t.title.Execute(&b, m); err != nil {
+			return "", "", Permanent(fmt.Errorf("title_template: %w", err))
+		}
+		title = b.String()
+	}
+	if t.body != nil {
+		var b bytes.Buffer
+		if err := t.body.Execute(&b, m); err != nil {
+			return "", "", Permanent(fmt.Errorf("body_template: %w", err))
+		}
+		body = b.String()
+	}
+	return title, body, nil
+}
+
+// SendWithRetry delivers m on ch, retrying a failed attempt after backoff,
+// then twice that, and so on, up to attempts tries in total. A permanent
+// error stops it at once. sleep is time.Sleep outside tests.
+func SendWithRetry(ctx context.Context, ch Channel, m Message, attempts int, backoff time.Duration, sleep func(time.Duration)) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	wait := backoff
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			sleep(wait)
+			wait *= 2
+		}
+		if err = ch.Send(ctx, m); err == nil || IsPermanent(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("%d attempts: %w", attempts, err)
+}
+
+// TestMessage is what a test-send delivers.
+func TestMessage(channel string) Message {
+	return Message{
+		Event:    EventTest,
+		Severity: SeverityInfo,
+		Subject:  "ShinGo test notification",
+		Summary:  fmt.Sprintf("Test from ShinGo Core to channel %q. If you can read this, the channel works.", channel),
+		Body:     fmt.Sprintf("This is a test notification from ShinGo Core to channel %q.\n", channel),
+		Fields:   map[string]string{"channel": channel},
+		Time:     time.Now(),
+	}
+}
+
+// TestChannel sends one test message on the configured channel, without
+// retrying, so the config page reports the first failure as it happened.
+func TestChannel(c config.NotificationChannel) error {
+	ch, err := NewChannel(c)
+	if err != nil {
+		return err
+	}
+	return ch.Send(context.Background(), TestMessage(c.Name))
+}
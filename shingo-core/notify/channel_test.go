@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"shingocore/config"
+)
+
+// standIn is a local HTTP receiver that records what each channel posted.
+type standIn struct {
+	mu   sync.Mutex
+	reqs []recorded
+	srv  *httptest.Server
+}
+
+type recorded struct {
+	header http.Header
+	body   string
+}
+
+func newStandIn(t *testing.T, status func(n int) int) *standIn {
+	t.Helper()
+	s := &standIn{}
+	var n atomic.Int32
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.reqs = append(s.reqs, recorded{header: r.Header.Clone(), body: string(b)})
+		s.mu.Unlock()
+		code := http.StatusOK
+		if status != nil {
+			code = status(int(n.Add(1)))
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *standIn) requests() []recorded {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recorded(nil), s.reqs...)
+}
+
+func faultMessage() Message {
+	return Message{
+		Event:    EventOrderFaulted,
+		Severity: SeverityWarning,
+		Subject:  FaultSubject("R7"),
+		Summary:  FaultSummary(41, "PRESS-2", "blocked", "R7"),
+		Body:     FaultAlert(41, "", "PRESS-2", "blocked", "R7"),
+		Fields:   map[string]string{"order_id": "41", "station": "PRESS-2"},
+		Time:     time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestChannelPayloads(t *testing.T) {
+	t.Parallel()
+	s := newStandIn(t, nil)
+	cases := []struct {
+		cfg   config.NotificationChannel
+		check func(t *testing.T, r recorded)
+	}{
+		{config.NotificationChannel{Name: "hook", Kind: "webhook", URL: s.srv.URL, Token: "tk"}, func(t *testing.T, r recorded) {
+			var p WebhookPayload
+			if err := json.Unmarshal([]byte(r.body), &p); err != nil {
+				t.Fatalf("webhook body: %v", err)
+			}
+			if p.Event != EventOrderFaulted || p.Fields["order_id"] != "41" || !strings.Contains(p.Text, "Order 41 faulted at PRESS-2") {
+				t.Errorf("webhook payload = %+v", p)
+			}
+			if got := r.header.Get("Authorization"); got != "Bearer tk" {
+				t.Errorf("Authorization = %q", got)
+			}
+		}},
+		{config.NotificationChannel{Name: "slack", Kind: "slack", URL: s.srv.URL}, func(t *testing.T, r recorded) {
+			var p map[string]string
+			json.Unmarshal([]byte(r.body), &p)
+			if !strings.HasPrefix(p["text"], "*Shingo Fault Alert - Robot R7*\n") {
+				t.Errorf("slack text = %q", p["text"])
+			}
+		}},
+		{config.NotificationChannel{Name: "teams", Kind: "teams", URL: s.srv.URL}, func(t *testing.T, r recorded) {
+			var p map[string]string
+			json.Unmarshal([]byte(r.body), &p)
+			if !strings.HasPrefix(p["text"], "**Shingo Fault Alert - Robot R7**\n\n") {
+				t.Errorf("teams text = %q", p["text"])
+			}
+		}},
+		{config.NotificationChannel{Name: "pager", Kind: "ntfy", URL: s.srv.URL,
+			TitleTemplate: "{{.Fields.station}} fault", BodyTemplate: "order {{.Fields.order_id}}: {{.Summary}}"}, func(t *testing.T, r recorded) {
+			if got := r.header.Get("Title"); got != "PRESS-2 fault" {
+				t.Errorf("Title = %q", got)
+			}
+			if got := r.header.Get("Priority"); got != "4" {
+				t.Errorf("Priority = %q, want 4 for a warning", got)
+			}
+			if !strings.HasPrefix(r.body, "order 41: Order 41 faulted") {
+				t.Errorf("ntfy body = %q", r.body)
+			}
+		}},
+	}
+	for _, tc := range cases {
+		ch, err := NewChannel(tc.cfg)
+		if err != nil {
+			t.Fatalf("NewChannel(%s): %v", tc.cfg.Name, err)
+		}
+		before := len(s.requests())
+		if err := ch.Send(t.Context(), faultMessage()); err != nil {
+			t.Fatalf("%s send: %v", tc.cfg.Name, err)
+		}
+		reqs := s.requests()
+		if len(reqs) != before+1 {
+			t.Fatalf("%s: %d requests, want 1", tc.cfg.Name, len(reqs)-before)
+		}
+		tc.check(t, reqs[len(reqs)-1])
+	}
+}
+
+func TestNewChannel_Rejects(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		cfg  config.NotificationChannel
+		want string
+	}{
+		{config.NotificationChannel{Kind: "slack", URL: "http://x"}, "no name"},
+		{config.NotificationChannel{Name: "a", Kind: "slack"}, "url is required"},
+		{config.NotificationChannel{Name: "a", Kind: "pagerduty", URL: "http://x"}, "unknown kind"},
+		{config.NotificationChannel{Name: "a", Kind: "ntfy", URL: "http://x", TitleTemplate: "{{.Nope"}, "title_template"},
+	}
+	for _, tc := range cases {
+		if _, err := NewChannel(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("NewChannel(%+v) = %v, want error containing %q", tc.cfg, err, tc.want)
+		}
+	}
+}
+
+// TestSendWithRetry: a 5xx is retried with doubling backoff until it
+// succeeds; a 4xx is the receiver refusing and is not retried at all.
+func TestSendWithRetry(t *testing.T) {
+	t.Parallel()
+	flaky := newStandIn(t, func(n int) int {
+		if n < 3 {
+			return http.StatusBadGateway
+		}
+		return http.StatusOK
+	})
+	ch, _ := NewChannel(config.NotificationChannel{Name: "hook", Kind: "webhook", URL: flaky.srv.URL})
+	var waits []time.Duration
+	sleep := func(d time.Duration) { waits = append(waits, d) }
+	if err := SendWithRetry(t.Context(), ch, faultMessage(), 4, time.Second, sleep); err != nil {
+		t.Fatalf("flaky: %v", err)
+	}
+	if len(flaky.requests()) != 3 {
+		t.Errorf("flaky: %d attempts, want 3", len(flaky.requests()))
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 2*time.Second {
+		t.Errorf("backoff waits = %v, want [1s 2s]", waits)
+	}
+
+	refused := newStandIn(t, func(int) int { return http.StatusNotFound })
+	ch, _ = NewChannel(config.NotificationChannel{Name: "gone", Kind: "slack", URL: refused.srv.URL})
+	err := SendWithRetry(t.Context(), ch, faultMessage(), 4, time.Second, func(time.Duration) {})
+	if err == nil || !IsPermanent(err) {
+		t.Fatalf("404: err = %v, want a permanent error", err)
+	}
+	if len(refused.requests()) != 1 {
+		t.Errorf("404: %d attempts, want 1", len(refused.requests()))
+	}
+}
+
+// TestDispatch_RoutesByEvent: a channel receives only the events in its list,
+// an empty list receives everything, and the master switch stops all of it.
+func TestDispatch_RoutesByEvent(t *testing.T) {
+	t.Parallel()
+	failsOnly := newStandIn(t, nil)
+	everything := newStandIn(t, nil)
+	cfg := &config.NotificationsConfig{
+		Enabled: true,
+		Channels: []config.NotificationChannel{
+			{Name: "fails", Kind: "webhook", URL: failsOnly.srv.URL, Events: []string{EventOrderFailed}},
+			{Name: "all", Kind: "ntfy", URL: everything.srv.URL},
+		},
+		Retry: config.NotificationRetry{Attempts: 1},
+	}
+	n := New(cfg)
+	if !n.Enabled() {
+		t.Fatal("Enabled() = false with two channels configured")
+	}
+
+	if err := n.dispatch(faultMessage()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(failsOnly.requests()) != 0 || len(everything.requests()) != 1 {
+		t.Errorf("fault: fails-only got %d, all got %d; want 0 and 1",
+			len(failsOnly.requests()), len(everything.requests()))
+	}
+
+	failed := faultMessage()
+	failed.Event = EventOrderFailed
+	if err := n.dispatch(failed); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(failsOnly.requests()) != 1 || len(everything.requests()) != 2 {
+		t.Errorf("fail: fails-only got %d, all got %d; want 1 and 2",
+			len(failsOnly.requests()), len(everything.requests()))
+	}
+
+	off := *cfg
+	off.Enabled = false
+	n.Reconfigure(&off)
+	if err := n.dispatch(failed); err == nil {
+		t.Error("dispatch with notifications disabled returned nil")
+	}
+	if len(everything.requests()) != 2 {
+		t.Errorf("disabled: all got %d, want still 2", len(everything.requests()))
+	}
+}
+
+// TestDeliver_FailsWhileAnyChannelFails: Deliver is the call whose result a
+// caller records, so one channel still down after its retries fails it even
+// when the others delivered.
+func TestDeliver_FailsWhileAnyChannelFails(t *testing.T) {
+	t.Parallel()
+	down := newStandIn(t, func(int) int { return http.StatusServiceUnavailable })
+	up := newStandIn(t, nil)
+	n := New(&config.NotificationsConfig{
+		Enabled: true,
+		Channels: []config.NotificationChannel{
+			{Name: "cmms", Kind: "webhook", URL: down.srv.URL},
+			{Name: "pager", Kind: "ntfy", URL: up.srv.URL},
+		},
+		Retry: config.NotificationRetry{Attempts: 2},
+	})
+	n.sleep = func(time.Duration) {}
+
+	if err := n.Deliver(faultMessage()); err == nil {
+		t.Fatal("Deliver = nil with the webhook down")
+	}
+	if len(down.requests()) != 2 || len(up.requests()) != 1 {
+		t.Errorf("down got %d tries, up got %d; want 2 and 1", len(down.requests()), len(up.requests()))
+	}
+}
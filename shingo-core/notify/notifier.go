@@ -1,34 +1,62 @@
 package notify
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"shingocore/config"
 )
 
+// Notifier routes each message to every channel configured for its event —
+// email through SMTP, plus any webhook, slack, teams or ntfy channels — and
+// retries each delivery independently, so one dead pager does not hold up
+// the email.
 type Notifier struct {
-	mu  sync.RWMutex
-	cfg *config.NotificationsConfig
+	mu       sync.RWMutex
+	cfg      *config.NotificationsConfig
+	channels []Channel
+
+	// sleep is time.Sleep; tests replace it so a retry test does not wait.
+	sleep func(time.Duration)
 }
 
 func New(cfg *config.NotificationsConfig) *Notifier {
-	return &Notifier{
-		cfg: cfg,
-	}
+	n := &Notifier{sleep: time.Sleep}
+	n.Reconfigure(cfg)
+	return n
 }
 
+// Enabled reports whether anything would be delivered: the master switch is
+// on and email or at least one channel is usable.
 func (n *Notifier) Enabled() bool {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
-	return n.cfg.Enabled && n.cfg.SMTPHost != "" && n.cfg.FromAddress != "" && len(n.cfg.Recipients) > 0
+	return n.cfg.Enabled && len(n.channels) > 0
 }
 
+// Reconfigure rebuilds the channel list. A channel whose config does not
+// build is logged and left out; the rest still deliver.
 func (n *Notifier) Reconfigure(cfg *config.NotificationsConfig) {
+	var channels []Channel
+	if smtpReady(cfg) {
+		channels = append(channels, newEmailChannel(cfg))
+	}
+	for _, c := range cfg.Channels {
+		ch, err := NewChannel(c)
+		if err != nil {
+			log.Printf("notify: %v — channel skipped", err)
+			continue
+		}
+		channels = append(channels, ch)
+	}
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.cfg = cfg
+	n.channels = channels
 }
 
 func (n *Notifier) Config() *config.NotificationsConfig {
@@ -37,52 +65,69 @@ func (n *Notifier) Config() *config.NotificationsConfig {
 	return n.cfg
 }
 
-func (n *Notifier) Send(subject, body string) error {
-	n.mu.RLock()
-	cfg := n.cfg
-	n.mu.RUnlock()
-
-	if !cfg.Enabled || cfg.SMTPHost == "" || cfg.FromAddress == "" || len(cfg.Recipients) == 0 {
-		return fmt.Errorf("notifications not configured")
-	}
-
-	addr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
-	sendMail := PlainSend
-	if cfg.SMTPTLS {
-		sendMail = TLSSend
-	}
-
-	err := sendMail(addr, cfg.SMTPUser, cfg.SMTPPassword, cfg.FromAddress, cfg.Recipients, subject, body)
-	if err != nil {
-		log.Printf("notify: send error: %v", err)
-		return err
-	}
+// Notify delivers m in the background. Callers are event-bus handlers, which
+// run synchronously on the bus, and a retry's backoff must not stall it.
+func (n *Notifier) Notify(m Message) {
+	go func() { _ = n.dispatch(m) }()
+}
 
-	log.Printf("notify: alert sent to %d recipient(s)", len(cfg.Recipients))
-	return nil
+// Deliver sends m on every routed channel, retries included, and returns
+// once all are done: nil only when every channel delivered. It is for a
+// caller that records the delivery — a message whose record says sent must
+// have been sent.
+func (n *Notifier) Deliver(m Message) error {
+	return n.dispatch(m)
 }
 
-func (n *Notifier) SendWithHeaders(subject, body string, opts ...SendOption) error {
+// dispatch delivers m on every routed channel in parallel and returns once
+// all are done, joining their failures.
+func (n *Notifier) dispatch(m Message) error {
 	n.mu.RLock()
 	cfg := n.cfg
+	channels := n.channels
 	n.mu.RUnlock()
 
-	if !cfg.Enabled || cfg.SMTPHost == "" || cfg.FromAddress == "" || len(cfg.Recipients) == 0 {
-		return fmt.Errorf("notifications not configured")
+	if !cfg.Enabled {
+		return fmt.Errorf("notifications not enabled")
 	}
-
-	addr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
-	sendMail := PlainSend
-	if cfg.SMTPTLS {
-		sendMail = TLSSend
+	if m.Time.IsZero() {
+		m.Time = time.Now()
 	}
 
-	err := sendMail(addr, cfg.SMTPUser, cfg.SMTPPassword, cfg.FromAddress, cfg.Recipients, subject, body, opts...)
-	if err != nil {
-		log.Printf("notify: send error: %v", err)
-		return err
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, ch := range channels {
+		if !ch.Wants(m.Event) {
+			continue
+		}
+		wg.Add(1)
+		go func(ch Channel) {
+			defer wg.Done()
+			err := SendWithRetry(context.Background(), ch, m, cfg.Retry.Attempts, cfg.Retry.Backoff, n.sleep)
+			if err != nil {
+				log.Printf("notify: %s %s (%s): %v", ch.Kind(), ch.Name(), m.Event, err)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			log.Printf("notify: %s sent via %s %s", m.Event, ch.Kind(), ch.Name())
+		}(ch)
 	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-	log.Printf("notify: alert sent to %d recipient(s)", len(cfg.Recipients))
-	return nil
+// ChannelNames lists the channels that built, email first when configured.
+func (n *Notifier) ChannelNames() []string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	names := make([]string, 0, len(n.channels))
+	for _, ch := range n.channels {
+		names = append(names, ch.Name())
+	}
+	return names
 }
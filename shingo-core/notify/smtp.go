@@ -1,6 +1,7 @@
 package notify
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/hex"
@@ -9,6 +10,8 @@ import (
 	"net/mail"
 	"net/smtp"
 	"time"
+
+	"shingocore/config"
 )
 
 func PlainSend(addr, user, password, from string, to []string, subject, body string, opts ...SendOption) error {
@@ -169,3 +172,45 @@ func hostOnly(addr string) string {
 	}
 	return h
 }
+
+// emailChannelName is the SMTP channel's name in logs and routing.
+const emailChannelName = "email"
+
+func smtpReady(cfg *config.NotificationsConfig) bool {
+	return cfg.SMTPHost != "" && cfg.FromAddress != "" && len(cfg.Recipients) > 0
+}
+
+// emailChannel is SMTP as one channel among the others. It sends the long
+// Body, not the summary — an email has room for it, and the fault/fail
+// templates were written for it.
+type emailChannel struct {
+	cfg config.NotificationsConfig
+}
+
+func newEmailChannel(cfg *config.NotificationsConfig) *emailChannel {
+	return &emailChannel{cfg: *cfg}
+}
+
+func (c *emailChannel) Name() string            { return emailChannelName }
+func (c *emailChannel) Kind() string            { return "smtp" }
+func (c *emailChannel) Wants(event string) bool { return routes(c.cfg.EmailEvents, event) }
+
+func (c *emailChannel) Send(_ context.Context, m Message) error {
+	addr := fmt.Sprintf("%s:%d", c.cfg.SMTPHost, c.cfg.SMTPPort)
+	sendMail := PlainSend
+	if c.cfg.SMTPTLS {
+		sendMail = TLSSend
+	}
+	var opts []SendOption
+	if m.MessageID != "" {
+		opts = append(opts, WithMessageID(m.MessageID))
+	}
+	if m.InReplyTo != "" {
+		opts = append(opts, WithInReplyTo(m.InReplyTo), WithReferences(m.InReplyTo))
+	}
+	body := m.Body
+	if body == "" {
+		body = m.Summary
+	}
+	return sendMail(addr, c.cfg.SMTPUser, c.cfg.SMTPPassword, c.cfg.FromAddress, c.cfg.Recipients, m.Subject, body, opts...)
+}
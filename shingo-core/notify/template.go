@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"shingocore/domain"
 )
 
 func FaultAlert(orderID int64, edgeUUID, stationID, reason, robotID string) string {
@@ -122,3 +124,188 @@ func FaultClearedAlert(orderID int64, edgeUUID, stationID, robotID, timeFaulted
 	b.WriteString("\n\n\n")
 	return b.String()
 }
+
+// AlertAlert is the email body for an alert the rules engine raised or
+// escalated.
+func AlertAlert(id int64, rule, severity, subject, detail string, firstSeen time.Time, escalated bool) string {
+	var b strings.Builder
+	if escalated {
+		b.WriteString("SHINGO ALERT — ESCALATED\n")
+		b.WriteString("========================\n\n")
+	} else {
+		b.WriteString("SHINGO ALERT\n")
+		b.WriteString("============\n\n")
+	}
+	b.WriteString(fmt.Sprintf("Alert:        A-%d\n", id))
+	b.WriteString(fmt.Sprintf("Rule:         %s\n", rule))
+	b.WriteString(fmt.Sprintf("Severity:     %s\n", severity))
+	b.WriteString(fmt.Sprintf("What:         %s\n", subject))
+	if detail != "" {
+		b.WriteString(fmt.Sprintf("Detail:       %s\n", detail))
+	}
+	b.WriteString(fmt.Sprintf("First seen:   %s\n", firstSeen.Local().Format(time.RFC1123)))
+	b.WriteString("\n")
+	if escalated {
+		b.WriteString("Nobody has acknowledged this alert. Acknowledge it on the Alerts page to stop further escalation.\n")
+	} else {
+		b.WriteString("Acknowledge it on the Alerts page. It resolves on its own when the condition clears.\n")
+	}
+	b.WriteString("\n\n\n")
+	return b.String()
+}
+
+func AlertSubject(severity, subject string, escalated bool) string {
+	if escalated {
+		return fmt.Sprintf("Shingo Alert [%s, escalated] - %s", strings.ToUpper(severity), subject)
+	}
+	return fmt.Sprintf("Shingo Alert [%s] - %s", strings.ToUpper(severity), subject)
+}
+
+// ShiftReportText is the email body for an end-of-shift report: the same
+// sections as the Reports page, as fixed-width tables. Times are in the
+// plant timezone the shift was defined in.
+func ShiftReportText(r *domain.ShiftReport) string {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	var b strings.Builder
+	b.WriteString("SHINGO SHIFT REPORT\n")
+	b.WriteString("===================\n\n")
+	b.WriteString(fmt.Sprintf("Shift:        %s, %s\n", r.Shift, r.Day))
+	b.WriteString(fmt.Sprintf("Window:       %s – %s (%s)\n",
+		r.Start.In(loc).Format("Mon Jan 2 15:04"), r.End.In(loc).Format("Mon Jan 2 15:04"), loc))
+
+	t := r.TotalOrders()
+	b.WriteString("\nORDERS\n")
+	b.WriteString(fmt.Sprintf("  %-14s %8s %9s %7s %9s %7s\n", "type", "created", "confirmed", "failed", "cancelled", "skipped"))
+	for _, o := range r.Orders {
+		b.WriteString(fmt.Sprintf("  %-14s %8d %9d %7d %9d %7d\n", o.Type, o.Created, o.Confirmed, o.Failed, o.Cancelled, o.Skipped))
+	}
+	b.WriteString(fmt.Sprintf("  %-14s %8d %9d %7d %9d %7d\n", "total", t.Created, t.Confirmed, t.Failed, t.Cancelled, t.Skipped))
+
+	b.WriteString("\nDELIVERY TIME\n")
+	if len(r.Delivery) == 0 {
+		b.WriteString("  No deliveries completed.\n")
+	}
+	for _, d := range r.Delivery {
+		name := d.Type
+		if name == "" {
+			name = "all"
+		}
+		b.WriteString(fmt.Sprintf("  %-14s %5d delivered   avg %s   p90 %s\n", name, d.Count,
+			formatDuration(time.Duration(d.AvgSeconds*float64(time.Second))),
+			formatDuration(time.Duration(d.P90Seconds*float64(time.Second)))))
+	}
+
+	b.WriteString("\nDOWNTIME\n")
+	if len(r.Downtime) == 0 {
+		b.WriteString("  None reported.\n")
+	}
+	for _, d := range r.Downtime {
+		b.WriteString(fmt.Sprintf("  %-24s %-14s %4d× %7.1f min\n", d.Station, d.Reason, d.Events, d.Minutes))
+	}
+
+	b.WriteString("\nFAULTS BY ROBOT\n")
+	if len(r.Faults) == 0 {
+		b.WriteString("  None.\n")
+	}
+	for _, f := range r.Faults {
+		robot := f.RobotID
+		if robot == "" {
+			robot = "(unassigned)"
+		}
+		b.WriteString(fmt.Sprintf("  %-14s %4d faults on %d orders\n", robot, f.Faults, f.Orders))
+	}
+
+	b.WriteString("\nINVENTORY CORRECTIONS\n")
+	if len(r.Corrections) == 0 {
+		b.WriteString("  None.\n")
+	}
+	for _, c := range r.Corrections {
+		b.WriteString(fmt.Sprintf("  %-14s %4d   net qty %+d\n", c.Type, c.Count, c.Quantity))
+	}
+
+	b.WriteString(fmt.Sprintf("\nDEAD LETTERS: %d this shift, %d outstanding\n", r.DeadLetters, r.DeadLettersOpen))
+	b.WriteString("\nThis report and earlier ones are on the Reports page.\n")
+	b.WriteString("\n\n\n")
+	return b.String()
+}
+
+func ShiftReportSubject(shift, day string) string {
+	return fmt.Sprintf("Shingo Shift Report - %s shift, %s", shift, day)
+}
+
+// The summaries are the one-line forms of the alerts above, for channels with
+// room for a sentence rather than a page. They name the same facts in the same
+// order so the email and the chat line can be read against each other.
+
+func FaultSummary(orderID int64, stationID, reason, robotID string) string {
+	return fmt.Sprintf("Order %d faulted%s%s: %s — it fails automatically if the fleet does not recover within the grace window.",
+		orderID, atStation(stationID), onRobot(robotID), reason)
+}
+
+func FailSummary(orderID int64, stationID, errorCode, detail, robotID string) string {
+	why := detail
+	if errorCode != "" {
+		why = strings.TrimSpace(errorCode + " " + detail)
+	}
+	return fmt.Sprintf("Order %d failed%s%s: %s", orderID, atStation(stationID), onRobot(robotID), why)
+}
+
+func GraceExpiredSummary(orderID int64, robotID string) string {
+	return fmt.Sprintf("Order %d%s: grace period expired without fleet recovery — failed and cancelled at the vendor.", orderID, onRobot(robotID))
+}
+
+func FaultClearedSummary(orderID int64, stationID, robotID, timeFaulted string) string {
+	s := fmt.Sprintf("Order %d recovered%s%s", orderID, atStation(stationID), onRobot(robotID))
+	if timeFaulted != "" {
+		s += " after " + timeFaulted
+	}
+	return s + "."
+}
+
+func AlertSummary(id int64, subject, detail string, escalated bool) string {
+	s := fmt.Sprintf("A-%d: %s", id, subject)
+	if detail != "" {
+		s += " — " + detail
+	}
+	if escalated {
+		s += " (unacknowledged, escalated)"
+	}
+	return s + "."
+}
+
+func ShiftReportSummary(r *domain.ShiftReport) string {
+	t := r.TotalOrders()
+	return fmt.Sprintf("%s shift %s: %d orders confirmed, %d failed; p90 delivery %s; %.0f min downtime; %d faults; %d corrections; %d dead letters.",
+		r.Shift, r.Day, t.Confirmed, t.Failed,
+		formatDuration(time.Duration(r.OverallDelivery().P90Seconds*float64(time.Second))),
+		r.DowntimeMinutes(), r.FaultCount(), r.CorrectionCount(), r.DeadLetters)
+}
+
+func atStation(stationID string) string {
+	if stationID == "" {
+		return ""
+	}
+	return " at " + stationID
+}
+
+func onRobot(robotID string) string {
+	if robotID == "" {
+		return ""
+	}
+	return " (robot " + robotID + ")"
+}
+
+// formatDuration renders a delivery time to the second: "4m07s", "52s".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"shingocore/config"
+)
+
+const defaultChannelTimeout = 10 * time.Second
+
+// httpChannel is what the three HTTP kinds share: config, templates, and the
+// POST with status classification.
+type httpChannel struct {
+	cfg config.NotificationChannel
+	tpl templates
+}
+
+func (c httpChannel) Name() string            { return c.cfg.Name }
+func (c httpChannel) Kind() string            { return c.cfg.Kind }
+func (c httpChannel) Wants(event string) bool { return routes(c.cfg.Events, event) }
+
+// post sends body and classifies the answer. A 4xx other than 408 and 429 is
+// permanent: the receiver understood the request and refused it, and sending
+// it again three times only triples the refusal.
+func (c httpChannel) post(ctx context.Context, contentType string, body []byte, headers map[string]string) error {
+	timeout := c.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultChannelTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("channel %q: %w", c.cfg.Name, err))
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "shingo-core")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("channel %q: %w", c.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	err = fmt.Errorf("channel %q: %s: %s", c.cfg.Name, resp.Status, strings.TrimSpace(string(snippet)))
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
+
+// webhookChannel posts the whole message as JSON, for receivers that want the
+// fields rather than a sentence.
+type webhookChannel struct{ httpChannel }
+
+// WebhookPayload is the generic webhook body.
+type WebhookPayload struct {
+	Event    string            `json:"event"`
+	Severity string            `json:"severity,omitempty"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text"`
+	Body     string            `json:"body,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+func (c *webhookChannel) Send(ctx context.Context, m Message) error {
+	title, text, err := c.tpl.render(m)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(WebhookPayload{
+		Event: m.Event, Severity: m.Severity, Title: title, Text: text,
+		Body: m.Body, Fields: m.Fields, Time: m.Time.UTC(),
+	})
+	if err != nil {
+		return Permanent(err)
+	}
+	return c.post(ctx, "application/json", b, nil)
+}
+
+// chatChannel posts to a Slack or Teams incoming webhook. Both accept
+// {"text": ...}; they differ only in how bold is written.
+type chatChannel struct{ httpChannel }
+
+func (c *chatChannel) Send(ctx context.Context, m Message) error {
+	title, text, err := c.tpl.render(m)
+	if err != nil {
+		return err
+	}
+	var msg string
+	switch {
+	case title == "":
+		msg = text
+	case c.cfg.Kind == "teams":
+		msg = "**" + title + "**\n\n" + text
+	default:
+		msg = "*" + title + "*\n" + text
+	}
+	b, err := json.Marshal(map[string]string{"text": msg})
+	if err != nil {
+		return Permanent(err)
+	}
+	return c.post(ctx, "application/json", b, nil)
+}
+
+// ntfyChannel publishes to an ntfy topic: the body is the text, the title and
+// priority ride in headers. The URL is the topic URL.
+type ntfyChannel struct{ httpChannel }
+
+func (c *ntfyChannel) Send(ctx context.Context, m Message) error {
+	title, text, err := c.tpl.render(m)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"Priority": ntfyPriority(c.cfg.Priority, m.Severity)}
+	if title != "" {
+		headers["Title"] = title
+	}
+	if m.Event != "" {
+		headers["Tags"] = m.Event
+	}
+	return c.post(ctx, "text/plain; charset=utf-8", []byte(text), headers)
+}
+
+// ntfyPriority is the configured override, else the severity's: critical
+// pages (5), warning buzzes (4), everything else is ntfy's default (3).
+func ntfyPriority(override, severity string) string {
+	if override != "" {
+		return override
+	}
+	switch severity {
+	case SeverityCritical:
+		return "5"
+	case SeverityWarning:
+		return "4"
+	}
+	return "3"
+}
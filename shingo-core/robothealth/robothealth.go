@@ -0,0 +1,336 @@
+// Package robothealth turns a robot's health record into maintenance
+// indicators: how worn the battery is, whether it holds less than it did,
+// how often the robot faults, how far it has driven since it was last
+// serviced, and whether it localizes worse than it did.
+//
+// The e-maint report (/api/telemetry/e-maint) is a snapshot — it can say a
+// battery is on its 1,400th cycle, not that it now empties a fifth faster
+// than in spring. The engine keeps the record (store/robotmaintenance) and this
+// package reads it, so an indicator can be checked against the days it was
+// computed from rather than trusted.
+//
+// PURE. No database and no clock: service/robot_maintenance_service.go reads
+// the days and the service history, and this decides. Same split as
+// shingocore/mapfix.
+//
+// ── THE INDICATORS ──────────────────────────────────────────────────────────
+//
+//   - battery_cycles: the pack's charge cycle count against the rated life.
+//   - capacity_fade: battery percent drawn per kilometre driven, this week
+//     against the first week on record. A pack that has lost capacity spends
+//     more of its percentage on the same distance; the ratio cancels out how
+//     busy the robot was. Only pairs of samples with the robot off the
+//     charger count (store/robotmaintenance.Days).
+//   - fault_rate: alarm onsets in the last week. A code held for an hour is
+//     one fault, not 1,800 polls of one.
+//   - service_interval: kilometres since the last service item was closed
+//     done — or since the record began, for a robot never serviced here.
+//   - localization: mean confidence this week against the week before. The
+//     fleet-wide picture is mapfix's; a drop on ONE robot is a dirty or
+//     knocked scanner, which is maintenance.
+//
+// An indicator is DUE at its threshold and WATCH from watchShare of it. One
+// with too little record to judge is NO_DATA, never ok: a robot that has not
+// driven enough this week to measure its battery has not passed the check.
+package robothealth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Indicator keys. They are the maintenance item's indicator column and the
+// notification's field, so a CMMS mapping keys on them; do not rename.
+const (
+	BatteryCycles   = "battery_cycles"
+	CapacityFade    = "capacity_fade"
+	FaultRate       = "fault_rate"
+	ServiceInterval = "service_interval"
+	Localization    = "localization"
+)
+
+// Indicators is every key, in the order an assessment lists them.
+var Indicators = []string{BatteryCycles, CapacityFade, FaultRate, ServiceInterval, Localization}
+
+// Statuses, best to worst.
+const (
+	StatusNoData = "no_data"
+	StatusOK     = "ok"
+	StatusWatch  = "watch"
+	StatusDue    = "due"
+)
+
+var statusRank = map[string]int{StatusNoData: 0, StatusOK: 1, StatusWatch: 2, StatusDue: 3}
+
+// watchShare is how close to its threshold an indicator is WATCH. A fifth
+// short leaves a week or two to plan the work at the wear rates these robots
+// see, which is the point of predicting it.
+const watchShare = 0.8
+
+// window is the "recent" span every rate is measured over.
+const window = 7
+
+// Minimum evidence per window. Below these an indicator is NO_DATA.
+const (
+	// minFadeKm: at 1% battery resolution, a kilometre moves the figure by
+	// a percent or two; five keeps the rounding under the threshold's noise.
+	minFadeKm = 5.0
+	// minConfidenceSamples is about an hour of driving at the confidence
+	// sampler's write rate.
+	minConfidenceSamples = 100
+)
+
+// Thresholds are the DUE points. Zero disables an indicator.
+type Thresholds struct {
+	BatteryCycles   int     // charge cycles
+	CapacityFadePct float64 // percent more battery per km than the baseline week
+	FaultsPerWeek   int     // alarm onsets in the last 7 days
+	ServiceKm       float64 // km since the last service
+	ConfidenceDrop  float64 // mean confidence lost, week on week (0-1)
+}
+
+// Day is one UTC day of a robot's record.
+type Day struct {
+	Day          time.Time `json:"day"`
+	Samples      int       `json:"samples"`
+	BatteryCycle int       `json:"battery_cycle"` // highest seen that day
+	// DischargePct and DischargeKm are summed over consecutive sample pairs
+	// with the robot off the charger: battery percent drawn and km driven.
+	DischargePct float64 `json:"discharge_pct"`
+	DischargeKm  float64 `json:"discharge_km"`
+	OdoM         float64 `json:"odo_m"` // odometer at the day's last sample
+	Faults       int     `json:"faults"`
+	Missions     int     `json:"missions"`
+	// ConfidenceMean is the day's mean localization confidence from the
+	// nightly roll-up (robot_confidence_daily); ConfidenceSamples == 0 when
+	// the day has not been rolled up.
+	ConfidenceMean    float64 `json:"confidence_mean"`
+	ConfidenceSamples int     `json:"confidence_samples"`
+}
+
+// Robot is everything Assess reads for one robot.
+type Robot struct {
+	VehicleID string
+	// Days is the lookback, oldest first. Missing days are days with no
+	// record, not days of zero.
+	Days []Day
+	// Now bounds "the last week": the days in (Now-7d, Now].
+	Now time.Time
+	// FaultsByCode counts the last week's onsets per alarm code.
+	FaultsByCode map[int]int
+	BatteryCycle int     // latest
+	OdoM         float64 // latest odometer
+	// ServiceOdoM is the odometer at the last service item closed done, or
+	// the earliest on record when there is none; ServiceSince is when.
+	ServiceOdoM  float64
+	ServiceSince time.Time
+}
+
+// Indicator is one assessed measure.
+type Indicator struct {
+	Key       string  `json:"key"`
+	Label     string  `json:"label"`
+	Status    string  `json:"status"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Unit      string  `json:"unit"`
+	Detail    string  `json:"detail"`
+}
+
+// Assessment is a robot's indicators and the worst of them.
+type Assessment struct {
+	VehicleID  string      `json:"vehicle_id"`
+	Status     string      `json:"status"`
+	Indicators []Indicator `json:"indicators"`
+}
+
+// Due returns the indicators at or over their threshold.
+func (a Assessment) Due() []Indicator {
+	var out []Indicator
+	for _, in := range a.Indicators {
+		if in.Status == StatusDue {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// Assess scores one robot. Disabled indicators (zero threshold) are left out.
+func Assess(r Robot, t Thresholds) Assessment {
+	a := Assessment{VehicleID: r.VehicleID, Status: StatusNoData}
+	recent, prior, first := split(r)
+	add := func(in Indicator) {
+		a.Indicators = append(a.Indicators, in)
+		if statusRank[in.Status] > statusRank[a.Status] {
+			a.Status = in.Status
+		}
+	}
+	if t.BatteryCycles > 0 {
+		in := Indicator{Key: BatteryCycles, Label: "Battery cycles", Unit: "cycles",
+			Value: float64(r.BatteryCycle), Threshold: float64(t.BatteryCycles), Status: StatusNoData}
+		if r.BatteryCycle > 0 {
+			in.Status = rising(in.Value, in.Threshold)
+			in.Detail = fmt.Sprintf("%d of %d rated cycles", r.BatteryCycle, t.BatteryCycles)
+		}
+		add(in)
+	}
+	if t.CapacityFadePct > 0 {
+		add(capacityFade(recent, first, t.CapacityFadePct))
+	}
+	if t.FaultsPerWeek > 0 {
+		n := 0
+		for _, d := range recent {
+			n += d.Faults
+		}
+		in := Indicator{Key: FaultRate, Label: "Faults per week", Unit: "faults",
+			Value: float64(n), Threshold: float64(t.FaultsPerWeek), Status: StatusNoData}
+		if len(recent) > 0 {
+			in.Status = rising(in.Value, in.Threshold)
+			in.Detail = topCodes(r.FaultsByCode)
+		}
+		add(in)
+	}
+	if t.ServiceKm > 0 {
+		in := Indicator{Key: ServiceInterval, Label: "Distance since service", Unit: "km",
+			Threshold: t.ServiceKm, Status: StatusNoData}
+		if r.OdoM > 0 && !r.ServiceSince.IsZero() {
+			in.Value = max(0, (r.OdoM-r.ServiceOdoM)/1000)
+			in.Status = rising(in.Value, in.Threshold)
+			missions := 0
+			for _, d := range r.Days {
+				if d.Day.After(r.ServiceSince.AddDate(0, 0, -1)) {
+					missions += d.Missions
+				}
+			}
+			in.Detail = fmt.Sprintf("%.0f km and %d missions since %s", in.Value, missions, r.ServiceSince.UTC().Format(time.DateOnly))
+		}
+		add(in)
+	}
+	if t.ConfidenceDrop > 0 {
+		add(localization(recent, prior, t.ConfidenceDrop))
+	}
+	return a
+}
+
+// split returns the last week's days, the week before it, and the first
+// week on record — which is only a baseline when it ends before the last
+// week begins.
+func split(r Robot) (recent, prior, first []Day) {
+	now := r.Now.UTC()
+	cut := now.AddDate(0, 0, -window)
+	for _, d := range r.Days {
+		switch {
+		case d.Day.After(cut):
+			recent = append(recent, d)
+		case d.Day.After(cut.AddDate(0, 0, -window)):
+			prior = append(prior, d)
+		}
+	}
+	if len(r.Days) > 0 {
+		end := r.Days[0].Day.AddDate(0, 0, window)
+		for _, d := range r.Days {
+			if d.Day.Before(end) && !d.Day.After(cut) {
+				first = append(first, d)
+			}
+		}
+	}
+	return recent, prior, first
+}
+
+func capacityFade(recent, first []Day, threshold float64) Indicator {
+	in := Indicator{Key: CapacityFade, Label: "Battery capacity fade", Unit: "%",
+		Threshold: threshold, Status: StatusNoData}
+	now, nowKm := perKm(recent)
+	base, baseKm := perKm(first)
+	switch {
+	case len(first) == 0:
+		in.Detail = "no baseline week on record yet"
+	case baseKm < minFadeKm || base <= 0:
+		in.Detail = fmt.Sprintf("baseline week drove %.1f km off the charger, %.0f needed", baseKm, minFadeKm)
+	case nowKm < minFadeKm:
+		in.Detail = fmt.Sprintf("last week drove %.1f km off the charger, %.0f needed", nowKm, minFadeKm)
+	default:
+		in.Value = (now - base) / base * 100
+		in.Status = rising(in.Value, threshold)
+		in.Detail = fmt.Sprintf("%.2f%%/km against %.2f%%/km in the week of %s",
+			now, base, first[0].Day.Format(time.DateOnly))
+	}
+	return in
+}
+
+func perKm(days []Day) (rate, km float64) {
+	var pct float64
+	for _, d := range days {
+		pct += d.DischargePct
+		km += d.DischargeKm
+	}
+	if km == 0 {
+		return 0, 0
+	}
+	return pct / km, km
+}
+
+func localization(recent, prior []Day, threshold float64) Indicator {
+	in := Indicator{Key: Localization, Label: "Localization confidence drop",
+		Threshold: threshold, Status: StatusNoData}
+	now, nn := meanConfidence(recent)
+	was, pn := meanConfidence(prior)
+	if nn < minConfidenceSamples || pn < minConfidenceSamples {
+		in.Detail = fmt.Sprintf("%d and %d rolled-up readings, %d needed in each week", pn, nn, minConfidenceSamples)
+		return in
+	}
+	in.Value = was - now
+	in.Status = rising(in.Value, threshold)
+	in.Detail = fmt.Sprintf("mean %.2f this week, %.2f the week before", now, was)
+	return in
+}
+
+func meanConfidence(days []Day) (mean float64, n int) {
+	var sum float64
+	for _, d := range days {
+		sum += d.ConfidenceMean * float64(d.ConfidenceSamples)
+		n += d.ConfidenceSamples
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return sum / float64(n), n
+}
+
+// rising grades a measure that gets worse as it grows.
+func rising(v, threshold float64) string {
+	switch {
+	case v >= threshold:
+		return StatusDue
+	case v >= threshold*watchShare:
+		return StatusWatch
+	}
+	return StatusOK
+}
+
+// topCodes names the three most frequent alarm codes, most first.
+func topCodes(byCode map[int]int) string {
+	if len(byCode) == 0 {
+		return "no faults"
+	}
+	codes := make([]int, 0, len(byCode))
+	for c := range byCode {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if byCode[codes[i]] != byCode[codes[j]] {
+			return byCode[codes[i]] > byCode[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	if len(codes) > 3 {
+		codes = codes[:3]
+	}
+	parts := make([]string, len(codes))
+	for i, c := range codes {
+		parts[i] = fmt.Sprintf("code %d x%d", c, byCode[c])
+	}
+	return strings.Join(parts, ", ")
+}
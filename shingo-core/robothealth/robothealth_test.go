@@ -0,0 +1,141 @@
+package robothealth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+var limits = Thresholds{
+	BatteryCycles:   1500,
+	CapacityFadePct: 20,
+	FaultsPerWeek:   10,
+	ServiceKm:       2000,
+	ConfidenceDrop:  0.10,
+}
+
+// record is n days ending today, each driving 2 km off the charger on pct
+// battery percent per km, with conf mean confidence over 50 readings.
+func record(n int, pct, conf float64) []Day {
+	out := make([]Day, n)
+	for i := range out {
+		out[i] = Day{
+			Day:               now.Truncate(24*time.Hour).AddDate(0, 0, i-n+1),
+			Samples:           288,
+			DischargeKm:       2,
+			DischargePct:      2 * pct,
+			ConfidenceMean:    conf,
+			ConfidenceSamples: 50,
+			Missions:          10,
+		}
+	}
+	return out
+}
+
+func indicator(t *testing.T, a Assessment, key string) Indicator {
+	t.Helper()
+	for _, in := range a.Indicators {
+		if in.Key == key {
+			return in
+		}
+	}
+	t.Fatalf("%s not assessed", key)
+	return Indicator{}
+}
+
+func TestBatteryCycles(t *testing.T) {
+	for cycles, want := range map[int]string{0: StatusNoData, 900: StatusOK, 1200: StatusWatch, 1500: StatusDue} {
+		a := Assess(Robot{Now: now, BatteryCycle: cycles}, limits)
+		if got := indicator(t, a, BatteryCycles).Status; got != want {
+			t.Errorf("%d cycles: %s, want %s", cycles, got, want)
+		}
+	}
+}
+
+// The same distance costing a quarter more battery is fade, whatever the
+// robot's workload — the first week is the baseline, the last is compared.
+func TestCapacityFade(t *testing.T) {
+	days := record(28, 4, 0.9)
+	for i := 21; i < 28; i++ {
+		days[i].DischargePct *= 1.25
+	}
+	in := indicator(t, Assess(Robot{Now: now, Days: days}, limits), CapacityFade)
+	if in.Status != StatusDue || in.Value < 24.9 || in.Value > 25.1 {
+		t.Fatalf("fade = %.2f%% %s, want 25%% due", in.Value, in.Status)
+	}
+
+	// A week of record has no separate baseline.
+	in = indicator(t, Assess(Robot{Now: now, Days: record(7, 4, 0.9)}, limits), CapacityFade)
+	if in.Status != StatusNoData {
+		t.Fatalf("one week: %s, want no_data", in.Status)
+	}
+
+	// Too little driving this week to measure is not a pass.
+	days = record(28, 4, 0.9)
+	for i := 21; i < 28; i++ {
+		days[i].DischargeKm, days[i].DischargePct = 0.5, 2
+	}
+	in = indicator(t, Assess(Robot{Now: now, Days: days}, limits), CapacityFade)
+	if in.Status != StatusNoData || !strings.Contains(in.Detail, "last week") {
+		t.Fatalf("idle week: %s %q", in.Status, in.Detail)
+	}
+}
+
+func TestFaultRateNamesTheCodes(t *testing.T) {
+	days := record(14, 4, 0.9)
+	days[13].Faults, days[12].Faults, days[2].Faults = 6, 3, 40
+	a := Assess(Robot{Now: now, Days: days, FaultsByCode: map[int]int{54013: 5, 52200: 3, 50100: 1}}, limits)
+	in := indicator(t, a, FaultRate)
+	if in.Value != 9 || in.Status != StatusWatch {
+		t.Fatalf("faults = %v %s, want 9 watch (day 2 is outside the week)", in.Value, in.Status)
+	}
+	if in.Detail != "code 54013 x5, code 52200 x3, code 50100 x1" {
+		t.Fatalf("detail %q", in.Detail)
+	}
+}
+
+func TestServiceInterval(t *testing.T) {
+	r := Robot{Now: now, Days: record(14, 4, 0.9), OdoM: 5_100_000,
+		ServiceOdoM: 3_000_000, ServiceSince: now.AddDate(0, 0, -3)}
+	in := indicator(t, Assess(r, limits), ServiceInterval)
+	if in.Value != 2100 || in.Status != StatusDue {
+		t.Fatalf("service = %v km %s", in.Value, in.Status)
+	}
+	if !strings.Contains(in.Detail, "40 missions") {
+		t.Fatalf("detail %q counts the wrong days", in.Detail)
+	}
+	r.ServiceSince = time.Time{}
+	if in := indicator(t, Assess(r, limits), ServiceInterval); in.Status != StatusNoData {
+		t.Fatalf("no service record: %s", in.Status)
+	}
+}
+
+func TestLocalizationDrop(t *testing.T) {
+	days := record(14, 4, 0.90)
+	for i := 7; i < 14; i++ {
+		days[i].ConfidenceMean = 0.78
+	}
+	in := indicator(t, Assess(Robot{Now: now, Days: days}, limits), Localization)
+	if in.Status != StatusDue || in.Value < 0.119 || in.Value > 0.121 {
+		t.Fatalf("drop = %.3f %s", in.Value, in.Status)
+	}
+	days[13].ConfidenceSamples = 0
+	for i := 7; i < 13; i++ {
+		days[i].ConfidenceSamples = 10
+	}
+	if in := indicator(t, Assess(Robot{Now: now, Days: days}, limits), Localization); in.Status != StatusNoData {
+		t.Fatalf("thin week: %s", in.Status)
+	}
+}
+
+func TestAssessmentIsTheWorstIndicator(t *testing.T) {
+	a := Assess(Robot{Now: now, Days: record(14, 4, 0.9), BatteryCycle: 1600}, limits)
+	if a.Status != StatusDue || len(a.Due()) != 1 || a.Due()[0].Key != BatteryCycles {
+		t.Fatalf("status %s, due %+v", a.Status, a.Due())
+	}
+	if got := Assess(Robot{Now: now}, Thresholds{}); len(got.Indicators) != 0 || got.Status != StatusNoData {
+		t.Fatalf("all disabled: %+v", got)
+	}
+}
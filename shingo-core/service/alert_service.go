@@ -0,0 +1,120 @@
+package service
+
+import (
+	"time"
+
+	"shingo/protocol/clock"
+	"shingocore/store"
+	"shingocore/store/alerts"
+	"shingocore/store/messaging"
+)
+
+// AlertService is the alert history: what the rules engine raised, who
+// acknowledged it, when it escalated and resolved.
+//
+// Persistence is store/alerts; what to raise is shingocore/alerting's call
+// and the engine's alert loop drives both. This layer is the seam the www
+// handlers read and acknowledge through, plus the snapshot reads the loop
+// needs that are SQL rather than in-memory engine state.
+type AlertService struct {
+	db *store.DB
+}
+
+func NewAlertService(db *store.DB) *AlertService {
+	return &AlertService{db: db}
+}
+
+// Re-exported for www, which must not import store packages (depguard).
+type (
+	Alert       = alerts.Alert
+	AlertRaise  = alerts.Raise
+	AlertFilter = alerts.Filter
+	AlertStatus = alerts.Status
+	// LinesideLevel is one reported lineside level; the lineside wall
+	// display tiles them.
+	LinesideLevel = alerts.LinesideLevel
+)
+
+const (
+	AlertStatusOpen         = alerts.StatusOpen
+	AlertStatusAcknowledged = alerts.StatusAcknowledged
+	AlertStatusResolved     = alerts.StatusResolved
+)
+
+var (
+	ErrAlertNotFound = alerts.ErrNotFound
+	ErrAlertNotOpen  = alerts.ErrNotOpen
+)
+
+// List returns alert history, newest first.
+func (s *AlertService) List(f AlertFilter) ([]*Alert, error) {
+	return alerts.List(s.db.DB, f)
+}
+
+// Get returns one alert.
+func (s *AlertService) Get(id int64) (*Alert, error) {
+	return alerts.Get(s.db.DB, id)
+}
+
+// Acknowledge records that actor has seen the alert, which stops it
+// escalating. It does not resolve a periodic alert — only the condition
+// clearing does that.
+func (s *AlertService) Acknowledge(id int64, actor string) (*Alert, error) {
+	return alerts.Acknowledge(s.db.DB, id, actor, clock.Now().UTC())
+}
+
+// RaiseEvent raises (or counts another occurrence of) an event alert.
+func (s *AlertService) RaiseEvent(r AlertRaise) (*Alert, bool, error) {
+	return alerts.RaiseEvent(s.db.DB, r, clock.Now().UTC())
+}
+
+// Sync writes one periodic rule's evaluation and returns the new alerts.
+func (s *AlertService) Sync(rule string, due []AlertRaise, holding []string, now time.Time) ([]*Alert, error) {
+	return alerts.Sync(s.db.DB, rule, due, holding, now)
+}
+
+// ResolveRulesExcept resolves what rules no longer in the config left open.
+func (s *AlertService) ResolveRulesExcept(rules []string, now time.Time) (int64, error) {
+	return alerts.ResolveRulesExcept(s.db.DB, rules, now)
+}
+
+// Unnotified lists unresolved alerts whose notification has not gone out.
+func (s *AlertService) Unnotified() ([]*Alert, error) {
+	return alerts.Unnotified(s.db.DB)
+}
+
+// MarkNotified records a delivered notification.
+func (s *AlertService) MarkNotified(id int64, now time.Time) error {
+	return alerts.MarkNotified(s.db.DB, id, now)
+}
+
+// Unacknowledged lists the escalation pass's candidates.
+func (s *AlertService) Unacknowledged() ([]*Alert, error) {
+	return alerts.Unacknowledged(s.db.DB)
+}
+
+// Escalate raises an open alert's severity and re-arms its notification.
+func (s *AlertService) Escalate(id int64, severity string, now time.Time) (bool, error) {
+	return alerts.Escalate(s.db.DB, id, severity, now)
+}
+
+// ActiveOrderAges returns every active order with its time in status.
+func (s *AlertService) ActiveOrderAges() ([]alerts.OrderAge, error) {
+	return alerts.ActiveOrderAges(s.db.DB)
+}
+
+// LinesideLevels returns every reported lineside level.
+func (s *AlertService) LinesideLevels() ([]LinesideLevel, error) {
+	return alerts.LinesideLevels(s.db.DB)
+}
+
+// BoardSignature summarizes the unresolved alerts; it changes when any of
+// them is raised, resolved, escalated or acknowledged.
+func (s *AlertService) BoardSignature() (string, error) {
+	return alerts.BoardSignature(s.db.DB)
+}
+
+// DeadLetterCount counts outbox messages that gave up retrying.
+func (s *AlertService) DeadLetterCount() (int, error) {
+	return messaging.CountDeadLetterOutbox(s.db.DB)
+}
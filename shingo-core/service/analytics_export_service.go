@@ -0,0 +1,331 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"shingocore/backup"
+	"shingocore/store"
+	"shingocore/store/analyticsexport"
+	"shingocore/tabular"
+)
+
+// AnalyticsExportService writes the analytics datasets (store/analyticsexport)
+// out as Parquet or CSV, one file per dataset per plant day, for the industrial
+// engineers who would otherwise page through /missions/stats by hand.
+//
+// Two ways out, one layout. A download is a zip streamed straight to the
+// response; the schedule puts the same files into a storage target — a
+// directory or an S3 bucket, the backup package's backends — one closed day
+// at a time. Either way a file is written from the database cursor as it is
+// read, so an export's size is bounded by the disk, not by Core's memory.
+//
+//	<dataset>/date=YYYY-MM-DD/part-00000.parquet   (or .csv)
+//	manifest.json                                  (download)
+//	_manifests/date=YYYY-MM-DD.json                (storage; written last)
+//
+// The date=... directories are Hive-style partitions, so DuckDB, Spark and
+// pandas.read_parquet read a whole dataset directory as one table.
+type AnalyticsExportService struct {
+	db *store.DB
+}
+
+func NewAnalyticsExportService(db *store.DB) *AnalyticsExportService {
+	return &AnalyticsExportService{db: db}
+}
+
+// AnalyticsDataset is a dataset's column contract, re-exported so www can
+// serve the schema without importing the store package (the
+// www-no-direct-store depguard guardrail).
+type AnalyticsDataset = analyticsexport.Dataset
+
+// AnalyticsSchemaVersion is the version of the dataset contract, re-exported
+// for the same reason.
+const AnalyticsSchemaVersion = analyticsexport.SchemaVersion
+
+// MaxAnalyticsExportDays bounds one request. The export streams, so this is
+// not about memory: it is a year, and a request for more is a typo.
+const MaxAnalyticsExportDays = 366
+
+// AnalyticsExportRequest names what to export: the plant days in [From, To),
+// and which datasets and formats. From and To are read as calendar dates —
+// their own year, month and day — and each day runs from midnight to midnight
+// in Location, so a DST day is 23 or 25 hours. Nil Location is UTC. Empty
+// Datasets is every dataset; empty Formats is Parquet.
+type AnalyticsExportRequest struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+	Datasets []string
+	Formats  []string
+}
+
+// AnalyticsExportManifest describes one export: the schema it was written
+// against and every file in it, with the row count and checksum a reader can
+// verify a copy by.
+type AnalyticsExportManifest struct {
+	SchemaVersion int                   `json:"schema_version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	From          string                `json:"from"`     // first day, YYYY-MM-DD
+	To            string                `json:"to"`       // last day, inclusive
+	Timezone      string                `json:"timezone"` // IANA zone the days are midnight to midnight in
+	Formats       []string              `json:"formats"`
+	Datasets      []AnalyticsDataset    `json:"datasets"`
+	Files         []AnalyticsExportFile `json:"files"`
+}
+
+// AnalyticsExportFile is one written file.
+type AnalyticsExportFile struct {
+	Path    string `json:"path"`
+	Dataset string `json:"dataset"`
+	Date    string `json:"date"`
+	Format  string `json:"format"`
+	Rows    int64  `json:"rows"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256"`
+}
+
+const analyticsManifestPrefix = "_manifests/"
+
+// ErrAnalyticsExport is a request the export will not run: an empty or
+// over-long range, or a dataset or format it does not know.
+var ErrAnalyticsExport = errors.New("invalid analytics export request")
+
+// Datasets returns the dataset contract, in export order.
+func (s *AnalyticsExportService) Datasets() []AnalyticsDataset {
+	return analyticsexport.Datasets()
+}
+
+// plan validates a request and resolves its defaults.
+func (s *AnalyticsExportService) plan(req AnalyticsExportRequest) (days []time.Time, sets []AnalyticsDataset, formats []string, err error) {
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	from, to := plantDay(req.From, loc), plantDay(req.To, loc)
+	if !to.After(from) {
+		return nil, nil, nil, fmt.Errorf("%w: empty date range", ErrAnalyticsExport)
+	}
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	if len(days) > MaxAnalyticsExportDays {
+		return nil, nil, nil, fmt.Errorf("%w: %d days requested, at most %d", ErrAnalyticsExport, len(days), MaxAnalyticsExportDays)
+	}
+	if len(req.Datasets) == 0 {
+		sets = analyticsexport.Datasets()
+	}
+	for _, name := range req.Datasets {
+		d, ok := analyticsexport.Lookup(strings.TrimSpace(name))
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: unknown dataset %q", ErrAnalyticsExport, name)
+		}
+		sets = append(sets, d)
+	}
+	formats = req.Formats
+	if len(formats) == 0 {
+		formats = []string{tabular.FormatParquet}
+	}
+	for _, f := range formats {
+		if !slices.Contains(tabular.Formats, f) {
+			return nil, nil, nil, fmt.Errorf("%w: unknown format %q", ErrAnalyticsExport, f)
+		}
+	}
+	return days, sets, formats, nil
+}
+
+// Validate reports whether req would be accepted, so a handler can answer
+// 400 before it commits to a streamed 200.
+func (s *AnalyticsExportService) Validate(req AnalyticsExportRequest) error {
+	_, _, _, err := s.plan(req)
+	return err
+}
+
+// WriteZip streams the export to w as a zip, manifest last. An error after
+// the first byte leaves a zip with no central directory — every unzip tool
+// rejects it, which is the point: a truncated export must not pass for a
+// short one.
+func (s *AnalyticsExportService) WriteZip(ctx context.Context, w io.Writer, req AnalyticsExportRequest) error {
+	days, sets, formats, err := s.plan(req)
+	if err != nil {
+		return err
+	}
+	m := newAnalyticsManifest(days, sets, formats)
+	zw := zip.NewWriter(w)
+	for _, day := range days {
+		for _, d := range sets {
+			for _, format := range formats {
+				path := analyticsFilePath(d.Name, day, format)
+				// Parquet pages are already gzip'd; deflating them again
+				// costs CPU and saves nothing.
+				method := zip.Deflate
+				if format == tabular.FormatParquet {
+					method = zip.Store
+				}
+				fw, err := zw.CreateHeader(&zip.FileHeader{Name: path, Method: method, Modified: m.CreatedAt})
+				if err != nil {
+					return err
+				}
+				f, err := s.writeFile(ctx, fw, d, day, format)
+				if err != nil {
+					return err
+				}
+				f.Path = path
+				m.Files = append(m.Files, f)
+			}
+		}
+	}
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	if err := writeManifest(mw, m); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ExportDayTo writes one plant day of every named dataset into storage, then
+// the day's manifest. day's date is the day and its location the zone it is
+// reckoned in. The manifest goes last and is what ExportedDays looks for, so
+// a day interrupted part way is simply exported again.
+func (s *AnalyticsExportService) ExportDayTo(ctx context.Context, st backup.Storage, day time.Time, datasets, formats []string) (AnalyticsExportManifest, error) {
+	days, sets, formats, err := s.plan(AnalyticsExportRequest{From: day, To: day.AddDate(0, 0, 1), Location: day.Location(), Datasets: datasets, Formats: formats})
+	if err != nil {
+		return AnalyticsExportManifest{}, err
+	}
+	day = days[0]
+	m := newAnalyticsManifest(days, sets, formats)
+
+	tmp, err := os.CreateTemp("", "shingo-analytics-*")
+	if err != nil {
+		return m, fmt.Errorf("analytics export: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	for _, d := range sets {
+		for _, format := range formats {
+			// Storage wants the size up front (backup.Storage.Put), so each
+			// file goes through a temp file rather than straight to the target.
+			if err := tmp.Truncate(0); err != nil {
+				return m, err
+			}
+			if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+				return m, err
+			}
+			f, err := s.writeFile(ctx, tmp, d, day, format)
+			if err != nil {
+				return m, err
+			}
+			f.Path = analyticsFilePath(d.Name, day, format)
+			if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+				return m, err
+			}
+			if err := st.Put(ctx, f.Path, tmp, f.Bytes, map[string]string{"sha256": f.SHA256}); err != nil {
+				return m, fmt.Errorf("analytics export: %w", err)
+			}
+			m.Files = append(m.Files, f)
+		}
+	}
+
+	var buf strings.Builder
+	if err := writeManifest(&buf, m); err != nil {
+		return m, err
+	}
+	key := analyticsManifestPrefix + "date=" + day.Format(time.DateOnly) + ".json"
+	if err := st.Put(ctx, key, strings.NewReader(buf.String()), int64(buf.Len()), nil); err != nil {
+		return m, fmt.Errorf("analytics export: %w", err)
+	}
+	return m, nil
+}
+
+// ExportedDays returns the days storage holds a manifest for, as midnights in
+// loc — the keys ExportDayTo's days compare equal to.
+func (s *AnalyticsExportService) ExportedDays(ctx context.Context, st backup.Storage, loc *time.Location) (map[time.Time]bool, error) {
+	items, err := st.List(ctx, analyticsManifestPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("analytics export: %w", err)
+	}
+	out := map[time.Time]bool{}
+	for _, it := range items {
+		name := strings.TrimPrefix(it.Key, analyticsManifestPrefix)
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "date="), ".json")
+		if d, err := time.Parse(time.DateOnly, name); err == nil {
+			out[plantDay(d, loc)] = true
+		}
+	}
+	return out, nil
+}
+
+// writeFile streams one dataset-day into w and describes what it wrote.
+func (s *AnalyticsExportService) writeFile(ctx context.Context, w io.Writer, d AnalyticsDataset, day time.Time, format string) (AnalyticsExportFile, error) {
+	f := AnalyticsExportFile{Dataset: d.Name, Date: day.Format(time.DateOnly), Format: format}
+	hw := &hashingWriter{w: w, h: sha256.New()}
+	tw, err := tabular.NewWriter(format, hw, d.Columns)
+	if err != nil {
+		return f, err
+	}
+	if err := analyticsexport.Stream(ctx, s.db.DB, d, day, day.AddDate(0, 0, 1), tw.Write); err != nil {
+		return f, err
+	}
+	if err := tw.Close(); err != nil {
+		return f, fmt.Errorf("analytics export %s: %w", d.Name, err)
+	}
+	f.Rows, f.Bytes, f.SHA256 = tw.Rows(), hw.n, hex.EncodeToString(hw.h.Sum(nil))
+	return f, nil
+}
+
+func newAnalyticsManifest(days []time.Time, sets []AnalyticsDataset, formats []string) AnalyticsExportManifest {
+	return AnalyticsExportManifest{
+		SchemaVersion: analyticsexport.SchemaVersion,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+		From:          days[0].Format(time.DateOnly),
+		To:            days[len(days)-1].Format(time.DateOnly),
+		Timezone:      days[0].Location().String(),
+		Formats:       formats,
+		Datasets:      sets,
+	}
+}
+
+func writeManifest(w io.Writer, m AnalyticsExportManifest) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// plantDay is midnight in loc of t's calendar date, read in t's own location:
+// a date parsed as UTC midnight names the same day in the plant's zone.
+func plantDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func analyticsFilePath(dataset string, day time.Time, format string) string {
+	return dataset + "/date=" + day.Format(time.DateOnly) + "/part-00000." + format
+}
+
+// hashingWriter counts and checksums what passes through it.
+type hashingWriter struct {
+	w io.Writer
+	h hash.Hash
+	n int64
+}
+
+func (hw *hashingWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	hw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// A request's dates are plant days: midnight to midnight in the plant's zone,
+// 25 hours on the night the clocks go back, and the manifest names the zone.
+func TestAnalyticsExportPlanUsesPlantDays(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	s := &AnalyticsExportService{}
+	days, sets, formats, err := s.plan(AnalyticsExportRequest{
+		From:     time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+		Location: chicago,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("days = %v, want 2", days)
+	}
+	if want := time.Date(2026, 10, 31, 5, 0, 0, 0, time.UTC); !days[0].Equal(want) {
+		t.Errorf("first day starts %v, want %v (Chicago midnight, CDT)", days[0].UTC(), want)
+	}
+	if got := days[1].AddDate(0, 0, 1).Sub(days[1]); got != 25*time.Hour {
+		t.Errorf("2026-11-01 is %v long, want 25h (DST ends)", got)
+	}
+
+	m := newAnalyticsManifest(days, sets, formats)
+	if m.From != "2026-10-31" || m.To != "2026-11-01" || m.Timezone != "America/Chicago" {
+		t.Errorf("manifest from %s to %s in %q", m.From, m.To, m.Timezone)
+	}
+	if p := analyticsFilePath("orders", days[1], formats[0]); p != "orders/date=2026-11-01/part-00000.parquet" {
+		t.Errorf("path = %s", p)
+	}
+}
+
+// A manifest's date read back by ExportedDays is the same map key the
+// scheduler builds for that plant day.
+func TestPlantDayKeysMatch(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	parsed, _ := time.Parse(time.DateOnly, "2026-11-01")
+	sched := time.Date(2026, 10, 31, 0, 0, 0, 0, chicago).AddDate(0, 0, 1)
+	if have := map[time.Time]bool{plantDay(parsed, chicago): true}; !have[sched] {
+		t.Errorf("plantDay(%v) = %v, scheduler day %v", parsed, plantDay(parsed, chicago), sched)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"shingo/protocol"
+	"shingo/protocol/clock"
 
 	"shingocore/domain"
 	"shingocore/store/audit"
@@ -252,9 +253,9 @@ func (s *BinManifestService) ClearForReuseTx(tx *sql.Tx, binID int64, binTypeID
 	// left unchanged; when non-nil the dunnage type is re-stamped
 	// atomically with the manifest clear + epoch bump.
 	if _, err := tx.Exec(`UPDATE bins SET payload_code='', manifest=NULL, uop_remaining=0,
-		manifest_confirmed=false, loaded_at=NULL,
+		manifest_confirmed=false, loaded_at=NULL, empty_since=$3,
 		bin_type_id=COALESCE($2, bin_type_id), updated_at=NOW()
-		WHERE id=$1`, binID, binTypeID); err != nil {
+		WHERE id=$1`, binID, binTypeID, clock.Now().UTC()); err != nil {
 		return 0, fmt.Errorf("clear manifest bin %d: %w", binID, err)
 	}
 	newEpoch, err := s.bumpEpoch(tx, binID)
@@ -523,11 +524,11 @@ func (s *BinManifestService) clearAndClaimTx(tx *sql.Tx, binID, orderID int64) e
 	res, err := tx.Exec(`
 		UPDATE bins SET
 			payload_code='', manifest=NULL, uop_remaining=0,
-			manifest_confirmed=false, loaded_at=NULL,
+			manifest_confirmed=false, loaded_at=NULL, empty_since=$3,
 			claimed_by=$1, updated_at=NOW()
 		WHERE id=$2 AND locked=false AND (claimed_by IS NULL OR claimed_by=$1)
 		  AND EXISTS (SELECT 1 FROM reservations WHERE order_id=$1 AND bin_id=$2 AND state='pending')`,
-		orderID, binID)
+		orderID, binID, clock.Now().UTC())
 	if err != nil {
 		return fmt.Errorf("clear+claim bin %d: %w", binID, err)
 	}
@@ -833,14 +834,14 @@ func (s *BinManifestService) syncOrClearForReleased(binID, orderID int64, remain
 		clearSQL := `
 			UPDATE bins SET
 				payload_code='', manifest=NULL, uop_remaining=0,
-				manifest_confirmed=false, loaded_at=NULL,
+				manifest_confirmed=false, loaded_at=NULL, empty_since=$2,
 				updated_at=NOW()
 			WHERE id=$1 AND locked=false`
 		var res sql.Result
 		if sourceNodeFallback {
-			res, err = tx.Exec(clearSQL, binID)
+			res, err = tx.Exec(clearSQL, binID, clock.Now().UTC())
 		} else {
-			res, err = tx.Exec(clearSQL+` AND claimed_by=$2`, binID, orderID)
+			res, err = tx.Exec(clearSQL+` AND claimed_by=$3`, binID, clock.Now().UTC(), orderID)
 		}
 		if err != nil {
 			return fmt.Errorf("clear manifest for released bin %d%s: %w", binID, errSuffix, err)
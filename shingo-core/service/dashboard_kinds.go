@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"shingocore/alerting"
+)
+
+// dashboard_kinds.go — the config schemas of the wall-display kinds that have
+// one. A dashboard's config_json is opaque to the platform (store/dashboards);
+// a kind that reads options out of it registers a schema here, and Create and
+// Update run it, so a typo in an option fails at save rather than as a blank
+// screen on the floor.
+//
+// The four original kinds (task-board, robot-map, heartbeat, node-report)
+// predate this and keep their free-form configs; they have no entry, and an
+// unregistered kind's config is stored as given.
+
+// Kinds with a config schema.
+const (
+	LinesideBoardKind   = "lineside"
+	LaneMapKind         = "lane-map"
+	ProductionBoardKind = "production"
+	AlertBoardKind      = "alert-board"
+	FleetBoardKind      = "fleet-status"
+)
+
+// LinesideBoardConfig is a lineside board: every reported lineside level at
+// the board's stations, one tile per node and payload.
+type LinesideBoardConfig struct {
+	// LowAt is the level at or below which a tile is shown low.
+	LowAt int `json:"low_at"`
+	// StaleAfterMin is how old a report can be before its tile says so.
+	StaleAfterMin int `json:"stale_after_min"`
+}
+
+// LaneMapConfig is a supermarket/lane occupancy map.
+type LaneMapConfig struct {
+	// Groups are the node groups (NGRP) to draw; empty is every group.
+	Groups []string `json:"groups"`
+}
+
+// ProductionBoardConfig is a production-vs-plan board with hourly counts.
+type ProductionBoardConfig struct {
+	// Hours is how many plant-local hours are shown, the current one last.
+	Hours int `json:"hours"`
+	// Targets is the planned parts per hour, by cell. A cell with no target
+	// shows its counts against no plan rather than against zero.
+	Targets map[string]int `json:"targets"`
+}
+
+// AlertBoardConfig is an alert board: the unresolved alerts.
+type AlertBoardConfig struct {
+	// MinSeverity hides alerts below it.
+	MinSeverity string `json:"min_severity"`
+	// ShowAcknowledged keeps acknowledged alerts on the board.
+	ShowAcknowledged bool `json:"show_acknowledged"`
+}
+
+// FleetBoardConfig is a robot fleet status board.
+type FleetBoardConfig struct {
+	// LowBatteryPct marks a robot's battery low at or below it.
+	LowBatteryPct int `json:"low_battery_pct"`
+	// ShowDisconnected keeps robots the fleet reports disconnected.
+	ShowDisconnected bool `json:"show_disconnected"`
+}
+
+// DashboardKindInfo describes one kind for the hub's create form.
+type DashboardKindInfo struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	// Scoped says whether the board honours the station scope.
+	Scoped bool `json:"scoped"`
+	// Defaults is the kind's config with every option at its default; nil for
+	// a kind with no schema.
+	Defaults any `json:"defaults,omitempty"`
+}
+
+// DashboardKinds lists every kind, the original four first.
+func DashboardKinds() []DashboardKindInfo {
+	lineside, _ := ParseLinesideBoardConfig(nil)
+	lanes, _ := ParseLaneMapConfig(nil)
+	production, _ := ParseProductionBoardConfig(nil)
+	alertBoard, _ := ParseAlertBoardConfig(nil)
+	fleet, _ := ParseFleetBoardConfig(nil)
+	return []DashboardKindInfo{
+		{Kind: HeartbeatKind, Label: "Heartbeat", Scoped: true},
+		{Kind: "task-board", Label: "Flight Board", Scoped: true},
+		{Kind: "robot-map", Label: "Robot Map", Scoped: true},
+		{Kind: "node-report", Label: "Node Report"},
+		{Kind: LinesideBoardKind, Label: "Lineside", Scoped: true, Defaults: lineside},
+		{Kind: LaneMapKind, Label: "Lane Map", Defaults: lanes},
+		{Kind: ProductionBoardKind, Label: "Production vs Plan", Scoped: true, Defaults: production},
+		{Kind: AlertBoardKind, Label: "Alerts", Defaults: alertBoard},
+		{Kind: FleetBoardKind, Label: "Fleet Status", Defaults: fleet},
+	}
+}
+
+// dashboardConfigSchemas validates and normalizes a kind's config, returning
+// it re-encoded with defaults filled in.
+var dashboardConfigSchemas = map[string]func(json.RawMessage) (any, error){
+	LinesideBoardKind:   func(raw json.RawMessage) (any, error) { return ParseLinesideBoardConfig(raw) },
+	LaneMapKind:         func(raw json.RawMessage) (any, error) { return ParseLaneMapConfig(raw) },
+	ProductionBoardKind: func(raw json.RawMessage) (any, error) { return ParseProductionBoardConfig(raw) },
+	AlertBoardKind:      func(raw json.RawMessage) (any, error) { return ParseAlertBoardConfig(raw) },
+	FleetBoardKind:      func(raw json.RawMessage) (any, error) { return ParseFleetBoardConfig(raw) },
+}
+
+// normalizeDashboardConfig runs the kind's schema, if it has one.
+func normalizeDashboardConfig(kind string, raw json.RawMessage) (json.RawMessage, error) {
+	parse, ok := dashboardConfigSchemas[kind]
+	if !ok {
+		return raw, nil
+	}
+	cfg, err := parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s config: %w", kind, err)
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// decodeDashboardConfig decodes raw strictly — an unknown option is an error,
+// not silently ignored — leaving dst's defaults for anything absent.
+func decodeDashboardConfig(raw json.RawMessage, dst any) error {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(dst)
+}
+
+// ParseLinesideBoardConfig decodes a lineside board's config. Defaults: low
+// at 0 (empty), stale after 15 minutes.
+func ParseLinesideBoardConfig(raw json.RawMessage) (LinesideBoardConfig, error) {
+	c := LinesideBoardConfig{StaleAfterMin: 15}
+	if err := decodeDashboardConfig(raw, &c); err != nil {
+		return c, err
+	}
+	if c.LowAt < 0 {
+		return c, fmt.Errorf("low_at must not be negative")
+	}
+	if c.StaleAfterMin <= 0 {
+		return c, fmt.Errorf("stale_after_min must be positive")
+	}
+	return c, nil
+}
+
+// ParseLaneMapConfig decodes a lane map's config. Default: every group.
+func ParseLaneMapConfig(raw json.RawMessage) (LaneMapConfig, error) {
+	c := LaneMapConfig{Groups: []string{}}
+	if err := decodeDashboardConfig(raw, &c); err != nil {
+		return c, err
+	}
+	clean := make([]string, 0, len(c.Groups))
+	for _, g := range c.Groups {
+		if g = strings.TrimSpace(g); g != "" {
+			clean = append(clean, g)
+		}
+	}
+	c.Groups = clean
+	return c, nil
+}
+
+// MaxProductionBoardHours bounds a production board's window.
+const MaxProductionBoardHours = 24
+
+// ParseProductionBoardConfig decodes a production board's config. Defaults:
+// 8 hours, no targets.
+func ParseProductionBoardConfig(raw json.RawMessage) (ProductionBoardConfig, error) {
+	c := ProductionBoardConfig{Hours: 8, Targets: map[string]int{}}
+	if err := decodeDashboardConfig(raw, &c); err != nil {
+		return c, err
+	}
+	if c.Hours < 1 || c.Hours > MaxProductionBoardHours {
+		return c, fmt.Errorf("hours must be between 1 and %d", MaxProductionBoardHours)
+	}
+	if c.Targets == nil {
+		c.Targets = map[string]int{}
+	}
+	for cell, n := range c.Targets {
+		if n <= 0 {
+			return c, fmt.Errorf("target for %s must be positive", cell)
+		}
+	}
+	return c, nil
+}
+
+// ParseAlertBoardConfig decodes an alert board's config. Defaults: warning
+// and up, acknowledged alerts hidden.
+func ParseAlertBoardConfig(raw json.RawMessage) (AlertBoardConfig, error) {
+	c := AlertBoardConfig{MinSeverity: alerting.SeverityWarning}
+	if err := decodeDashboardConfig(raw, &c); err != nil {
+		return c, err
+	}
+	switch c.MinSeverity {
+	case alerting.SeverityInfo, alerting.SeverityWarning, alerting.SeverityCritical:
+	default:
+		return c, fmt.Errorf("min_severity must be info, warning or critical")
+	}
+	return c, nil
+}
+
+// ParseFleetBoardConfig decodes a fleet board's config. Defaults: battery low
+// at 20%, disconnected robots shown.
+func ParseFleetBoardConfig(raw json.RawMessage) (FleetBoardConfig, error) {
+	c := FleetBoardConfig{LowBatteryPct: 20, ShowDisconnected: true}
+	if err := decodeDashboardConfig(raw, &c); err != nil {
+		return c, err
+	}
+	if c.LowBatteryPct < 0 || c.LowBatteryPct > 100 {
+		return c, fmt.Errorf("low_battery_pct must be between 0 and 100")
+	}
+	return c, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestDashboardConfigDefaultsAreFilledOnSave: an empty config for a schema
+// kind is stored with every option at its default, so what the hub shows on
+// edit is what the board is running with.
+func TestDashboardConfigDefaultsAreFilledOnSave(t *testing.T) {
+	raw, err := normalizeDashboardConfig(ProductionBoardKind, nil)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	var cfg ProductionBoardConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		t.Fatalf("stored config %s: %v", raw, err)
+	}
+	if cfg.Hours != 8 || cfg.Targets == nil {
+		t.Errorf("stored %s, want 8 hours and an empty target map", raw)
+	}
+}
+
+// TestDashboardConfigRejectsUnknownAndOutOfRange: a misspelt option fails
+// with its name rather than being dropped, and a value out of range fails
+// with the bound. VERIFIED RED BY: deleting DisallowUnknownFields — the
+// "low_level" typo saved silently as the default.
+func TestDashboardConfigRejectsUnknownAndOutOfRange(t *testing.T) {
+	for _, tc := range []struct {
+		kind, raw, want string
+	}{
+		{LinesideBoardKind, `{"low_level": 5}`, "low_level"},
+		{ProductionBoardKind, `{"hours": 25}`, "hours must be between 1 and 24"},
+		{ProductionBoardKind, `{"targets": {"SPR-1": 0}}`, "target for SPR-1"},
+		{AlertBoardKind, `{"min_severity": "urgent"}`, "min_severity"},
+		{FleetBoardKind, `{"low_battery_pct": 120}`, "low_battery_pct"},
+	} {
+		_, err := normalizeDashboardConfig(tc.kind, json.RawMessage(tc.raw))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s %s: err = %v, want it to name %q", tc.kind, tc.raw, err, tc.want)
+		}
+	}
+}
+
+// TestDashboardConfigOfUnregisteredKindIsStoredAsGiven: the four original
+// kinds keep their free-form configs — a node-report's loader_id is not an
+// unknown field to anyone.
+func TestDashboardConfigOfUnregisteredKindIsStoredAsGiven(t *testing.T) {
+	in := json.RawMessage(`{"loader_id": 3}`)
+	out, err := normalizeDashboardConfig("node-report", in)
+	if err != nil || string(out) != string(in) {
+		t.Errorf("node-report config = %s, %v; want it unchanged", out, err)
+	}
+}
+
+// TestDashboardKindsListsEverySchemaKindWithDefaults: the hub's form reads
+// the defaults off this list; a schema kind missing from it has no options
+// field.
+func TestDashboardKindsListsEverySchemaKindWithDefaults(t *testing.T) {
+	seen := map[string]bool{}
+	for _, k := range DashboardKinds() {
+		seen[k.Kind] = true
+		if _, schema := dashboardConfigSchemas[k.Kind]; schema != (k.Defaults != nil) {
+			t.Errorf("%s: has schema %v but defaults %v", k.Kind, schema, k.Defaults)
+		}
+	}
+	for kind := range dashboardConfigSchemas {
+		if !seen[kind] {
+			t.Errorf("%s has a schema but is not in DashboardKinds", kind)
+		}
+	}
+}
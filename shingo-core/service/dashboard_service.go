@@ -9,10 +9,10 @@ import (
 )
 
 // DashboardService is the floor-display platform's CRUD surface. A dashboard
-// is a saved, station-scoped view of Core's live data (the AMR task board
-// today; other kinds later). This service is plain CRUD over store/dashboards
-// with light input normalization — dashboards own no operational state, so
-// there is no cross-aggregate orchestration here.
+// is a saved, station-scoped view of Core's live data (the AMR task board, the
+// heartbeat, and the kinds in dashboard_kinds.go). This service is plain CRUD
+// over store/dashboards with light input normalization — dashboards own no
+// operational state, so there is no cross-aggregate orchestration here.
 type DashboardService struct {
 	db *store.DB
 }
@@ -30,6 +30,9 @@ const DefaultKind = "task-board"
 // is a type alias — identical to store/dashboards.Input.
 type DashboardInput = dashboards.Input
 
+// Dashboard is a stored wall display, re-exported for the same reason.
+type Dashboard = dashboards.Dashboard
+
 // List returns all dashboards, ordered for display.
 func (s *DashboardService) List() ([]dashboards.Dashboard, error) {
 	return dashboards.List(s.db.DB)
@@ -110,8 +113,9 @@ func (s *DashboardService) SeedDefaultDashboards() (int, error) {
 }
 
 // normalizeDashboard trims and defaults the input. Name is required; kind
-// defaults to DefaultKind. Station entries are trimmed, de-duplicated, and
-// empties dropped so the stored area filter is clean.
+// defaults to DefaultKind, and a kind with a config schema has its config
+// validated and filled in (dashboard_kinds.go). Station entries are trimmed,
+// de-duplicated, and empties dropped so the stored area filter is clean.
 func normalizeDashboard(in dashboards.Input) (dashboards.Input, error) {
 	in.Name = strings.TrimSpace(in.Name)
 	if in.Name == "" {
@@ -121,6 +125,11 @@ func normalizeDashboard(in dashboards.Input) (dashboards.Input, error) {
 	if in.Kind == "" {
 		in.Kind = DefaultKind
 	}
+	cfg, err := normalizeDashboardConfig(in.Kind, in.Config)
+	if err != nil {
+		return in, err
+	}
+	in.Config = cfg
 	clean := make([]string, 0, len(in.Stations))
 	seen := map[string]bool{}
 	for _, st := range in.Stations {
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"shingocore/config"
+	"shingocore/domain"
+	"shingocore/shiftreport"
+	"shingocore/store"
+	"shingocore/store/audit"
+	"shingocore/store/forecast"
+	"shingocore/store/orders"
+)
+
+// DemandForecastService learns a payload's consumption per style and shift
+// from what the lines drew of it, and suggests the UOP threshold that demand
+// calls for — the statistical counterpart of ThresholdCalculatorService, which
+// takes the rate as an engineer-entered cycle time.
+//
+// Suggestions only. Nothing here writes a threshold; the inventory page shows
+// them beside the loader's current value and the engineer applies one, or
+// not. The sampling and the arithmetic are domain/forecast.go's.
+type DemandForecastService struct {
+	db *store.DB
+}
+
+func NewDemandForecastService(db *store.DB) *DemandForecastService {
+	return &DemandForecastService{db: db}
+}
+
+const (
+	// DefaultForecastDays is the lookback when the request names none: four
+	// weeks, so every weekday and shift is seen more than once.
+	DefaultForecastDays = 28
+	// MaxForecastDays is the raw bin_uop_ledger retention (store/audit); the
+	// consume ticks a forecast samples are gone past it.
+	MaxForecastDays = 90
+	// DefaultServiceLevel is the share of lead times the suggestion covers.
+	DefaultServiceLevel = 0.95
+)
+
+// ForecastOffShift names hours outside every configured report shift.
+const ForecastOffShift = "off shift"
+
+// ErrDemandForecast is a request Forecast will not run: no payload, a
+// lookback past MaxForecastDays, or a service level outside [0.5, 0.999].
+var ErrDemandForecast = errors.New("invalid demand forecast request")
+
+// DemandForecastRequest is what the page asks for. A zero Days or
+// ServiceLevel takes the default; a zero LeadSeconds uses the observed L1
+// lead time over the same window.
+type DemandForecastRequest struct {
+	PayloadCode  string
+	Days         int
+	ServiceLevel float64
+	LeadSeconds  float64
+}
+
+// DemandForecast is one payload's forecast: a group per style and shift,
+// heaviest threshold first, and the one suggestion the page leads with.
+type DemandForecast struct {
+	PayloadCode  string    `json:"payload_code"`
+	Since        time.Time `json:"since"`
+	Until        time.Time `json:"until"`
+	ServiceLevel float64   `json:"service_level"`
+	Z            float64   `json:"z"`
+	LeadSeconds  float64   `json:"lead_seconds"`
+	// LeadObserved is false when the caller supplied the lead time.
+	LeadObserved bool                         `json:"lead_observed"`
+	Stations     []string                     `json:"stations"`
+	SampleHours  int                          `json:"sample_hours"`
+	Groups       []domain.DemandForecastGroup `json:"groups"`
+	// Suggested is the heaviest threshold among the groups with at least
+	// MEDIUM confidence — a threshold has to cover the heaviest style and
+	// shift the loader feeds — or the heaviest overall when none has.
+	Suggested int `json:"suggested"`
+}
+
+// Forecast runs req over the req.Days before now. shifts and loc are the
+// report pattern (reports.shifts) the hours are labelled by; with no pattern
+// every hour is in one unnamed group per style.
+func (s *DemandForecastService) Forecast(req DemandForecastRequest, shifts []config.ReportShift, loc *time.Location, now time.Time) (*DemandForecast, error) {
+	if req.PayloadCode == "" {
+		return nil, fmt.Errorf("%w: payload_code is required", ErrDemandForecast)
+	}
+	if req.Days == 0 {
+		req.Days = DefaultForecastDays
+	}
+	if req.Days < 1 || req.Days > MaxForecastDays {
+		return nil, fmt.Errorf("%w: days must be 1 to %d", ErrDemandForecast, MaxForecastDays)
+	}
+	if req.ServiceLevel == 0 {
+		req.ServiceLevel = DefaultServiceLevel
+	}
+	if req.ServiceLevel < 0.5 || req.ServiceLevel > 0.999 {
+		return nil, fmt.Errorf("%w: service_level must be 0.5 to 0.999", ErrDemandForecast)
+	}
+	if req.LeadSeconds < 0 {
+		return nil, fmt.Errorf("%w: lead_seconds cannot be negative", ErrDemandForecast)
+	}
+
+	until := now.UTC().Truncate(time.Hour)
+	since := until.AddDate(0, 0, -req.Days)
+	out := &DemandForecast{
+		PayloadCode:  req.PayloadCode,
+		Since:        since,
+		Until:        until,
+		ServiceLevel: req.ServiceLevel,
+		Z:            domain.NormalQuantile(req.ServiceLevel),
+		LeadSeconds:  req.LeadSeconds,
+	}
+	if out.LeadSeconds == 0 {
+		in := observedLeadTimes(s.db.DB, req.PayloadCode, orders.LeadTimeRange{Start: since, End: until})
+		out.LeadSeconds = in.L1QueueSeconds + in.L1TransitSeconds + in.L2LoadSeconds + in.L2TransitSeconds
+		out.LeadObserved = true
+	}
+
+	consumed, err := forecast.Consumption(s.db.DB, audit.OpBinUOPDelta, req.PayloadCode, since, until)
+	if err != nil {
+		return nil, err
+	}
+	styles, err := forecast.StyleHours(s.db.DB, since, until)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, c := range consumed {
+		if !seen[c.Station] {
+			seen[c.Station] = true
+			out.Stations = append(out.Stations, c.Station)
+		}
+	}
+	sort.Strings(out.Stations)
+
+	hours := domain.DemandHours(consumed, styles, shiftNamer(shifts, loc, since, until))
+	out.SampleHours = len(hours)
+	out.Groups = domain.ForecastDemand(hours, out.LeadSeconds/3600, req.ServiceLevel)
+	if len(out.Groups) > 0 {
+		out.Suggested = out.Groups[0].Threshold
+	}
+	for _, g := range out.Groups {
+		if g.Confidence != "LOW" {
+			out.Suggested = g.Threshold
+			break
+		}
+	}
+	return out, nil
+}
+
+// shiftNamer returns the report shift an instant in [since, until) falls in.
+// With no pattern every instant is in the unnamed shift.
+func shiftNamer(shifts []config.ReportShift, loc *time.Location, since, until time.Time) func(time.Time) string {
+	if len(shifts) == 0 {
+		return func(time.Time) string { return "" }
+	}
+	// Every window that ends after since, through the last one starting
+	// before until — a shift is at most a day long.
+	windows := shiftreport.Ended(shifts, loc, since, until.Add(48*time.Hour))
+	return func(t time.Time) string {
+		for _, w := range windows {
+			if !t.Before(w.Start) && t.Before(w.End) {
+				return w.Shift
+			}
+		}
+		return ForecastOffShift
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shingocore/config"
+)
+
+// TestShiftNamerNightShiftAndGaps: an hour after midnight belongs to the night
+// shift that started the evening before, and an hour between shifts is off
+// shift.
+func TestShiftNamerNightShiftAndGaps(t *testing.T) {
+	loc := time.UTC
+	shifts := []config.ReportShift{
+		{Name: "Day", Start: "06:00", End: "14:00"},
+		{Name: "Night", Start: "22:00", End: "06:00"},
+	}
+	since := time.Date(2026, 10, 12, 0, 0, 0, 0, loc)
+	name := shiftNamer(shifts, loc, since, since.AddDate(0, 0, 2))
+	for _, c := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 10, 12, 2, 30, 0, 0, loc), "Night"},
+		{time.Date(2026, 10, 12, 9, 30, 0, 0, loc), "Day"},
+		{time.Date(2026, 10, 12, 16, 30, 0, 0, loc), ForecastOffShift},
+		{time.Date(2026, 10, 13, 23, 30, 0, 0, loc), "Night"},
+	} {
+		if got := name(c.at); got != c.want {
+			t.Errorf("shift at %s = %q, want %q", c.at.Format("Jan 2 15:04"), got, c.want)
+		}
+	}
+	if got := shiftNamer(nil, loc, since, since.AddDate(0, 0, 1))(since); got != "" {
+		t.Errorf("no pattern = %q, want the unnamed shift", got)
+	}
+}
+
+// TestForecastRejectsBadRequests: the checks run before any read, so a nil
+// store is never reached.
+func TestForecastRejectsBadRequests(t *testing.T) {
+	s := NewDemandForecastService(nil)
+	for _, req := range []DemandForecastRequest{
+		{},
+		{PayloadCode: "P1", Days: MaxForecastDays + 1},
+		{PayloadCode: "P1", ServiceLevel: 1},
+		{PayloadCode: "P1", LeadSeconds: -1},
+	} {
+		if _, err := s.Forecast(req, nil, time.UTC, time.Now()); !errors.Is(err, ErrDemandForecast) {
+			t.Errorf("Forecast(%+v) err = %v, want ErrDemandForecast", req, err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"shingocore/mapfix"
+	"shingocore/scenemap"
+	"shingocore/store/robotconfidence"
+	"shingocore/store/sceneversion"
+)
+
+// The map-fix recommendations — the board's reading, done for the engineer.
+//
+// Same depguard shape as the board: everything is read here and handed to
+// shingocore/mapfix as plain values, so the handler and the alert loop see a
+// report and never a store type. The judgement itself lives in mapfix, where
+// it is tested against Springfield's real map without a database.
+
+// MapFixes is the recommendations over a window.
+type MapFixes struct {
+	Window   BoardWindow      `json:"window"`
+	Hotspots []mapfix.Hotspot `json:"hotspots"`
+	Fixes    []mapfix.Fix     `json:"fixes"`
+}
+
+// MapFixesAt finds the persistent localization hotspots over the `days` days
+// ending at `to`, and the ranked map fixes for them. The window is the board's:
+// day-grained, `to` exclusive after rounding up to the day.
+//
+// Geometry is read at the END of the window, as the board reads it — the
+// fixes are for the map as it stands, and a zone re-classed on day five is
+// judged as what it became. The reflector positions are read at both ends, so
+// a reflector removed mid-window is evidence rather than silently gone.
+func (s *NodeService) MapFixesAt(days int, to time.Time) (MapFixes, error) {
+	var out MapFixes
+	if days <= 0 {
+		return out, fmt.Errorf("map fixes: window must be at least one day, got %d", days)
+	}
+	toDay := to.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	fromDay := toDay.AddDate(0, 0, -days)
+	out.Window = BoardWindow{Label: fmt.Sprintf("%dd", days), From: fromDay, To: toDay, RequestedDays: days}
+
+	laneDays, err := s.db.LaneConfidenceDays(fromDay, toDay)
+	if err != nil {
+		return out, err
+	}
+	areaDays, err := s.db.AreaConfidenceDays(fromDay, toDay)
+	if err != nil {
+		return out, err
+	}
+	// The roll-up labels each zone row with its class; that is the fallback
+	// for a zone the map sync has not drawn yet.
+	areaWindows, err := s.db.AreaWindows(fromDay, toDay)
+	if err != nil {
+		return out, err
+	}
+	areas, err := s.db.SceneAreasAt(toDay)
+	if err != nil {
+		return out, err
+	}
+	reflStart, err := s.db.SceneReflectorsAt(fromDay)
+	if err != nil {
+		return out, err
+	}
+	reflEnd, err := s.db.SceneReflectorsAt(toDay)
+	if err != nil {
+		return out, err
+	}
+	segments, err := s.db.SceneSegments()
+	if err != nil {
+		return out, err
+	}
+	laneEdits, err := s.db.LaneEditsIn(fromDay, toDay)
+	if err != nil {
+		return out, err
+	}
+	areaEdits, err := s.db.AreaEditsIn(fromDay, toDay)
+	if err != nil {
+		return out, err
+	}
+
+	in := mapfix.Input{
+		ReflectorsStart: reflectorPoints(reflStart),
+		ReflectorsEnd:   reflectorPoints(reflEnd),
+	}
+
+	// Zones: the union of what has a shape and what has days, as on the board.
+	seen := map[string]bool{}
+	for _, a := range areas {
+		n := scenemap.NormalizeAreaID(a.Name)
+		seen[n] = true
+		in.Zones = append(in.Zones, mapfix.Zone{
+			Name: n, Class: a.Class, Polygon: a.Polygon,
+			Days:  mapfixDays(areaDays[n]),
+			Edits: mapfixEdits(areaEdits[a.Name]),
+		})
+	}
+	for n, d := range areaDays {
+		if seen[n] {
+			continue
+		}
+		z := mapfix.Zone{Name: n, Days: mapfixDays(d)}
+		if w := areaWindows[n]; w != nil {
+			z.Class = w.Class
+		}
+		in.Zones = append(in.Zones, z)
+	}
+	sort.Slice(in.Zones, func(i, j int) bool { return in.Zones[i].Name < in.Zones[j].Name })
+
+	// Lanes: one per physical lane with days. scene_edges holds each lane
+	// twice (see Segment.Lane); the first row placed wins, and the two carry
+	// the same geometry.
+	placed := map[string]bool{}
+	for _, seg := range segments {
+		lane := seg.Lane()
+		key := seg.Area + "\x00" + lane
+		if lane == "" || placed[key] || laneDays[key] == nil {
+			continue
+		}
+		placed[key] = true
+		in.Lanes = append(in.Lanes, laneInput(seg, lane, laneDays[key], laneEdits[key]))
+	}
+	for key, d := range laneDays {
+		if placed[key] {
+			continue
+		}
+		// Days for a lane the scene no longer carries: judged, not drawn.
+		area, lane, _ := strings.Cut(key, "\x00")
+		in.Lanes = append(in.Lanes, mapfix.Lane{Area: area, Lane: lane, Days: mapfixDays(d), Edits: mapfixEdits(laneEdits[key])})
+	}
+	sort.Slice(in.Lanes, func(i, j int) bool {
+		if in.Lanes[i].Area != in.Lanes[j].Area {
+			return in.Lanes[i].Area < in.Lanes[j].Area
+		}
+		return in.Lanes[i].Lane < in.Lanes[j].Lane
+	})
+
+	for _, d := range areaDays {
+		out.Window.DataDays = max(out.Window.DataDays, len(d))
+	}
+	rep := mapfix.Recommend(in)
+	out.Hotspots, out.Fixes = rep.Hotspots, rep.Fixes
+	return out, nil
+}
+
+func laneInput(seg robotconfidence.Segment, lane string, days []robotconfidence.DayStat, edits []sceneversion.Edit) mapfix.Lane {
+	mx, my := seg.Midpoint()
+	return mapfix.Lane{
+		Area: seg.Area, Lane: lane,
+		From:        scenemap.Point{X: seg.FromX, Y: seg.FromY},
+		Mid:         scenemap.Point{X: mx, Y: my},
+		To:          scenemap.Point{X: seg.ToX, Y: seg.ToY},
+		HasGeometry: true,
+		Days:        mapfixDays(days),
+		Edits:       mapfixEdits(edits),
+	}
+}
+
+func mapfixDays(days []robotconfidence.DayStat) []mapfix.Day {
+	out := make([]mapfix.Day, 0, len(days))
+	for _, d := range days {
+		out = append(out, mapfix.Day{Day: d.Day, Samples: d.Samples, Weak: d.Weak, Sentinel: d.Sentinel, Robots: d.Robots})
+	}
+	return out
+}
+
+func mapfixEdits(edits []sceneversion.Edit) []mapfix.Edit {
+	out := make([]mapfix.Edit, 0, len(edits))
+	for _, e := range edits {
+		out = append(out, mapfix.Edit{At: e.At, DiffID: e.DiffID, MovedM: e.MovedM})
+	}
+	return out
+}
+
+func reflectorPoints(refl []sceneversion.ReflectorView) []scenemap.Point {
+	out := make([]scenemap.Point, 0, len(refl))
+	for _, r := range refl {
+		out = append(out, scenemap.Point{X: r.X, Y: r.Y})
+	}
+	return out
+}
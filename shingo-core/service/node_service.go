@@ -341,8 +341,14 @@ func (s *NodeService) GetNodeProperty(nodeID int64, key string) string {
 
 // SetNodeProperty upserts a key/value property on a node. Absorbed
 // from engine_db_methods.go as part of the www-handler service
-// migration (PR 3a.1b).
+// migration (PR 3a.1b). An empty_affinity value the finders would not
+// recognise is refused rather than stored as a silent off.
 func (s *NodeService) SetNodeProperty(nodeID int64, key, value string) error {
+	if key == nodes.PropEmptyAffinity {
+		if err := nodes.ValidateEmptyAffinity(value); err != nil {
+			return err
+		}
+	}
 	return s.db.SetNodeProperty(nodeID, key, value)
 }
 
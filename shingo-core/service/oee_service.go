@@ -0,0 +1,207 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"shingocore/config"
+	"shingocore/domain"
+	"shingocore/shiftreport"
+	"shingocore/store"
+	"shingocore/store/oee"
+)
+
+// OEEService computes overall equipment effectiveness per cell, shift and
+// plant-local day, and keeps the two inputs OEE needs that nothing else
+// records: engineered cycle times and scrap reports.
+//
+// The figures are computed on read from downtime_events, cell_part_events
+// and demand_origins; none is stored. The arithmetic is domain.ComputeOEE's.
+type OEEService struct {
+	db *store.DB
+}
+
+func NewOEEService(db *store.DB) *OEEService {
+	return &OEEService{db: db}
+}
+
+// Re-exported for www, which must not import store packages (depguard).
+type (
+	OEECycleTime   = oee.CycleTime
+	OEEScrapReport = oee.ScrapReport
+)
+
+var ErrOEECycleTimeNotFound = oee.ErrNotFound
+
+// OEEWindow is one shift of the day the figures are computed over. End is
+// clipped to now for the shift still running; Future is a shift that has not
+// started.
+type OEEWindow struct {
+	Shift   string    `json:"shift"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Running bool      `json:"running"`
+	Future  bool      `json:"future"`
+}
+
+// OEEShiftFigures is one cell's figures for one shift.
+type OEEShiftFigures struct {
+	Shift string `json:"shift"`
+	domain.OEEFigures
+}
+
+// OEECell is one cell's row: a figure per shift, and the day's.
+type OEECell struct {
+	CellID string            `json:"cell_id"`
+	Shifts []OEEShiftFigures `json:"shifts"`
+	Day    domain.OEEFigures `json:"day"`
+}
+
+// OEEDay is the OEE page for one plant-local day.
+type OEEDay struct {
+	Date     string      `json:"date"`
+	Timezone string      `json:"timezone"`
+	Windows  []OEEWindow `json:"windows"`
+	Cells    []OEECell   `json:"cells"`
+}
+
+// Day computes every cell's OEE for the plant-local date day, per shift of
+// the configured pattern. With no shifts configured the day is one window,
+// midnight to midnight. Shifts are labelled by the day they start on, as the
+// shift reports label them, so the night shift of the 18th ends on the 19th.
+func (s *OEEService) Day(day string, shifts []config.ReportShift, loc *time.Location, now time.Time) (*OEEDay, error) {
+	if len(shifts) == 0 {
+		shifts = []config.ReportShift{{Name: "Day", Start: "00:00", End: "00:00"}}
+	}
+	windows, err := shiftreport.ForDay(shifts, loc, day)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		// The whole-day fallback has start == end, which shiftreport rejects
+		// as a shift; build it directly.
+		d, _ := time.ParseInLocation("2006-01-02", day, loc)
+		windows = []shiftreport.Window{{Shift: "Day", Day: day, Start: d, End: d.AddDate(0, 0, 1)}}
+	}
+	cycles, err := oee.CycleTimeMap(s.db.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OEEDay{Date: day, Timezone: loc.String()}
+	perCell := make(map[string][]OEEShiftFigures)
+	for _, w := range windows {
+		win := OEEWindow{Shift: w.Shift, Start: w.Start, End: w.End}
+		switch {
+		case !now.After(w.Start):
+			win.Future = true
+			win.End = w.Start
+		case now.Before(w.End):
+			win.Running = true
+			win.End = now
+		}
+		out.Windows = append(out.Windows, win)
+		if win.Future {
+			continue
+		}
+		figs, err := s.window(win.Start, win.End, cycles)
+		if err != nil {
+			return nil, err
+		}
+		for cell, f := range figs {
+			perCell[cell] = append(perCell[cell], OEEShiftFigures{Shift: w.Shift, OEEFigures: f})
+		}
+	}
+
+	for cell, fs := range perCell {
+		c := OEECell{CellID: cell, Shifts: fs}
+		parts := make([]domain.OEEFigures, len(fs))
+		for i, f := range fs {
+			parts[i] = f.OEEFigures
+		}
+		c.Day = domain.CombineOEE(parts...)
+		out.Cells = append(out.Cells, c)
+	}
+	sort.Slice(out.Cells, func(i, j int) bool { return out.Cells[i].CellID < out.Cells[j].CellID })
+	return out, nil
+}
+
+// window computes every cell's figures for [start, end). A cell is in the
+// result if anything was recorded for it in the window or it has engineered
+// cycles set — a cell with cycles and no parts is a cell that did not run,
+// which is worth seeing.
+func (s *OEEService) window(start, end time.Time, cycles map[string]map[int64]time.Duration) (map[string]domain.OEEFigures, error) {
+	down, err := oee.Downtime(s.db.DB, start, end)
+	if err != nil {
+		return nil, err
+	}
+	waits, err := oee.MaterialWaits(s.db.DB, start, end)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := oee.Production(s.db.DB, start, end)
+	if err != nil {
+		return nil, err
+	}
+	scrap, err := oee.Scrap(s.db.DB, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make(map[string]bool)
+	for c := range down {
+		cells[c] = true
+	}
+	for c := range parts {
+		cells[c] = true
+	}
+	for c := range scrap {
+		cells[c] = true
+	}
+	for c := range cycles {
+		cells[c] = true
+	}
+	out := make(map[string]domain.OEEFigures, len(cells))
+	for c := range cells {
+		out[c] = domain.ComputeOEE(domain.OEEInput{
+			Window:        domain.OEEInterval{Start: start, End: end},
+			Downtime:      down[c],
+			MaterialWaits: waits[c],
+			Parts:         parts[c],
+			IdealCycle:    cycles[c],
+			Scrap:         scrap[c],
+		})
+	}
+	return out, nil
+}
+
+// HourlyParts returns parts counted per cell per clock hour in [start, end),
+// keyed by the hour's start in UTC. The production wall display reads it.
+func (s *OEEService) HourlyParts(start, end time.Time) (map[string]map[time.Time]int64, error) {
+	return oee.HourlyProduction(s.db.DB, start, end)
+}
+
+// ListCycleTimes returns every engineered cycle time.
+func (s *OEEService) ListCycleTimes() ([]OEECycleTime, error) {
+	return oee.ListCycleTimes(s.db.DB)
+}
+
+// SetCycleTime creates or replaces a cell and style's engineered cycle.
+func (s *OEEService) SetCycleTime(c OEECycleTime) error {
+	return oee.SetCycleTime(s.db.DB, c)
+}
+
+// DeleteCycleTime removes a cell and style's engineered cycle.
+func (s *OEEService) DeleteCycleTime(cellID string, styleID int64) error {
+	return oee.DeleteCycleTime(s.db.DB, cellID, styleID)
+}
+
+// RecordScrap files one scrap report and returns its ID.
+func (s *OEEService) RecordScrap(r OEEScrapReport) (int64, error) {
+	return oee.InsertScrap(s.db.DB, r)
+}
+
+// ListScrap returns the scrap reports in [start, end), newest first.
+func (s *OEEService) ListScrap(start, end time.Time) ([]OEEScrapReport, error) {
+	return oee.ListScrap(s.db.DB, start, end)
+}
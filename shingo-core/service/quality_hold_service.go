@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"shingocore/domain"
+	"shingocore/store"
+	"shingocore/store/qualityholds"
+)
+
+// QualityHoldService is the quality-hold workflow: place a hold, hold every bin
+// it matches (now and as bins start matching later), and close it through a
+// requested-then-approved disposition.
+//
+// Persistence is store/qualityholds. This layer owns the three things that are
+// policy rather than SQL: which reason codes exist, who may approve, and what a
+// scrap does to a bin — which is a manifest clear, and that is BinService's
+// job, not the hold tables'.
+type QualityHoldService struct {
+	db     *store.DB
+	bins   *BinService
+	policy func() QualityPolicy
+}
+
+// QualityPolicy is the configured half of the workflow (config.QualityConfig),
+// read on every call so an edit on the config page applies without a restart.
+type QualityPolicy struct {
+	ReasonCodes []string
+	// Approvers empty means every signed-in user may approve.
+	Approvers []string
+}
+
+func NewQualityHoldService(db *store.DB, bins *BinService, policy func() QualityPolicy) *QualityHoldService {
+	return &QualityHoldService{db: db, bins: bins, policy: policy}
+}
+
+// Re-exported for www, which must not import store packages (depguard).
+type (
+	QualityHold        = qualityholds.Hold
+	QualityHoldInput   = qualityholds.Input
+	QualityHeldBin     = qualityholds.HeldBin
+	QualityHoldEvent   = qualityholds.Event
+	QualityDisposition = qualityholds.Disposition
+	QualityHoldScope   = qualityholds.Scope
+)
+
+const (
+	QualityScopeBin     = qualityholds.ScopeBin
+	QualityDispRelease  = qualityholds.DispositionRelease
+	QualityDispRework   = qualityholds.DispositionRework
+	QualityDispScrap    = qualityholds.DispositionScrap
+	qualitySweepActor   = "system"
+	qualityHoldNoteType = "hold"
+)
+
+// ErrNotApprover is returned when the approver does not hold the role.
+var ErrNotApprover = errors.New("not a quality approver")
+
+// CanApprove is the approval rule, pure so it can be pinned without a
+// database: the approver must hold the role, and must not be the person who
+// requested the disposition. Two people, always — a hold exists because
+// somebody doubted the material, and one person both asking and agreeing is
+// not a second look.
+func CanApprove(p QualityPolicy, approver, requester string) error {
+	if approver == "" {
+		return fmt.Errorf("%w: sign in to approve", ErrNotApprover)
+	}
+	if len(p.Approvers) > 0 && !slices.Contains(p.Approvers, approver) {
+		return fmt.Errorf("%w: %s is not in quality.approvers", ErrNotApprover, approver)
+	}
+	if strings.EqualFold(approver, requester) {
+		return fmt.Errorf("%w: the disposition was requested by %s — a different person must approve it",
+			ErrNotApprover, requester)
+	}
+	return nil
+}
+
+// ReasonCodes returns the configured reason codes, for the placement form.
+func (s *QualityHoldService) ReasonCodes() []string {
+	return s.policy().ReasonCodes
+}
+
+// Place creates a hold and immediately holds every bin it matches, in one
+// transaction: a hold that could not reach its bins is not placed at all.
+// Returns the hold id and the bins it held.
+func (s *QualityHoldService) Place(in QualityHoldInput, actor string) (int64, []QualityHeldBin, error) {
+	if !slices.Contains(s.policy().ReasonCodes, in.ReasonCode) {
+		return 0, nil, fmt.Errorf("unknown reason code %q (configured: %s)",
+			in.ReasonCode, strings.Join(s.policy().ReasonCodes, ", "))
+	}
+	in.CreatedBy = actor
+	id, held, err := qualityholds.Place(s.db.DB, in)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, held, s.recordHeld(id, held, in.Note, actor)
+}
+
+// propagate holds newly matching bins and records them.
+func (s *QualityHoldService) propagate(holdID int64, note, actor string) ([]QualityHeldBin, error) {
+	held, err := qualityholds.Propagate(s.db.DB, holdID)
+	if err != nil {
+		return nil, err
+	}
+	return held, s.recordHeld(holdID, held, note, actor)
+}
+
+// recordHeld writes each newly held bin's bin-level audit, so a bin's own
+// history says it went on hold and under which hold, and the hold's
+// propagated event.
+func (s *QualityHoldService) recordHeld(holdID int64, held []QualityHeldBin, note, actor string) error {
+	if len(held) == 0 {
+		return nil
+	}
+	for _, b := range held {
+		if b.PriorStatus != string(domain.BinStatusQualityHold) {
+			s.db.AppendAudit("bin", b.BinID, "status", b.PriorStatus, string(domain.BinStatusQualityHold), actor)
+		}
+		if note != "" {
+			s.bins.AddNote(b.BinID, qualityHoldNoteType, note, actor)
+		}
+	}
+	return qualityholds.AppendEvent(s.db.DB, holdID, qualityholds.ActionPropagated, actor,
+		fmt.Sprintf("%d bin(s)", len(held)))
+}
+
+// PropagateOpen runs propagation for every open hold — the sweep. Returns the
+// number of bins newly held. A bin loaded into a held lot after the hold was
+// placed is the case this exists for.
+func (s *QualityHoldService) PropagateOpen() (int, error) {
+	open, err := qualityholds.List(s.db.DB, true, 0)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	var errs []error
+	for _, h := range open {
+		held, err := s.propagate(h.ID, "", qualitySweepActor)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Ref(), err))
+			continue
+		}
+		total += len(held)
+	}
+	return total, errors.Join(errs...)
+}
+
+// RequestDisposition asks for a hold to be closed with d. It does not move a
+// bin: the hold keeps holding until someone else approves.
+func (s *QualityHoldService) RequestDisposition(holdID int64, d QualityDisposition, actor string) error {
+	ok, err := qualityholds.RequestDisposition(s.db.DB, holdID, d, actor)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s is not active — a disposition is already pending or it is closed", qualityholds.Ref(holdID))
+	}
+	return nil
+}
+
+// Reject sends a pending disposition back; the hold returns to active.
+func (s *QualityHoldService) Reject(holdID int64, actor, reason string) error {
+	h, err := qualityholds.Get(s.db.DB, holdID)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return fmt.Errorf("%s not found", qualityholds.Ref(holdID))
+	}
+	if err := CanApprove(s.policy(), actor, h.RequestedBy); err != nil {
+		return err
+	}
+	ok, err := qualityholds.RejectDisposition(s.db.DB, holdID, actor, reason)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s has no pending disposition", h.Ref())
+	}
+	return nil
+}
+
+// ApproveResult is what an approval did, for the handler to broadcast.
+type ApproveResult struct {
+	Disposition QualityDisposition
+	// Released are the bins whose status moved (release or rework).
+	Released []int64
+	// Scrapped are the bins emptied for reuse, with the epoch each clear
+	// started — the handler owes Edge a zero count for each one on a node.
+	Scrapped []ScrappedBin
+	// StillHeld are bins another open hold still holds.
+	StillHeld []int64
+}
+
+// ScrappedBin is one bin a scrap disposition emptied.
+type ScrappedBin struct {
+	BinID int64
+	Epoch int64
+}
+
+// Approve closes a pending hold with its requested disposition.
+//
+// Scrap runs in two steps on purpose — close the hold leaving bins held, then
+// empty and free each bin. See qualityholds.Close for why that order is the
+// safe one. A bin whose clear fails stays on hold and is reported; the hold is
+// still closed, and the bin can be cleared from the bin page.
+func (s *QualityHoldService) Approve(holdID int64, approver string) (ApproveResult, error) {
+	var res ApproveResult
+	h, err := qualityholds.Get(s.db.DB, holdID)
+	if err != nil {
+		return res, err
+	}
+	if h == nil {
+		return res, fmt.Errorf("%s not found", qualityholds.Ref(holdID))
+	}
+	if h.Status != qualityholds.StatusPendingDisposition {
+		return res, fmt.Errorf("%s has no pending disposition", h.Ref())
+	}
+	if err := CanApprove(s.policy(), approver, h.RequestedBy); err != nil {
+		return res, err
+	}
+	d, freed, err := qualityholds.Close(s.db.DB, holdID, approver)
+	if err != nil {
+		return res, err
+	}
+	if d == "" {
+		return res, fmt.Errorf("%s was resolved by someone else first", h.Ref())
+	}
+	res.Disposition = d
+
+	var errs []error
+	for _, f := range freed {
+		if f.StillHeld {
+			res.StillHeld = append(res.StillHeld, f.BinID)
+			continue
+		}
+		switch d {
+		case qualityholds.DispositionScrap:
+			epoch, err := s.bins.Manifest().ClearForReuse(f.BinID, nil)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("bin %d: %w", f.BinID, err))
+				continue
+			}
+			if err := s.bins.ChangeStatus(f.BinID, domain.BinStatusAvailable); err != nil {
+				errs = append(errs, fmt.Errorf("bin %d: %w", f.BinID, err))
+				continue
+			}
+			s.db.AppendAudit("bin", f.BinID, "status", string(domain.BinStatusQualityHold), string(domain.BinStatusAvailable), approver)
+			res.Scrapped = append(res.Scrapped, ScrappedBin{BinID: f.BinID, Epoch: epoch})
+		case qualityholds.DispositionRework:
+			s.db.AppendAudit("bin", f.BinID, "status", string(domain.BinStatusQualityHold), string(domain.BinStatusFlagged), approver)
+			s.bins.AddNote(f.BinID, qualityHoldNoteType, fmt.Sprintf("%s dispositioned to rework", h.Ref()), approver)
+			res.Released = append(res.Released, f.BinID)
+		default:
+			s.db.AppendAudit("bin", f.BinID, "status", string(domain.BinStatusQualityHold), f.PriorStatus, approver)
+			res.Released = append(res.Released, f.BinID)
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+// Get returns one hold, or (nil, nil).
+func (s *QualityHoldService) Get(id int64) (*QualityHold, error) {
+	return qualityholds.Get(s.db.DB, id)
+}
+
+// List returns holds newest first; openOnly drops closed ones.
+func (s *QualityHoldService) List(openOnly bool) ([]QualityHold, error) {
+	return qualityholds.List(s.db.DB, openOnly, 0)
+}
+
+// Bins returns every bin a hold has held.
+func (s *QualityHoldService) Bins(id int64) ([]QualityHeldBin, error) {
+	return qualityholds.Bins(s.db.DB, id)
+}
+
+// Events returns a hold's audit trail.
+func (s *QualityHoldService) Events(id int64) ([]QualityHoldEvent, error) {
+	return qualityholds.Events(s.db.DB, id)
+}
+
+// OpenHoldForBin returns the open hold holding a bin, or (nil, nil).
+func (s *QualityHoldService) OpenHoldForBin(binID int64) (*QualityHold, error) {
+	return qualityholds.OpenHoldForBin(s.db.DB, binID)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanApprove(t *testing.T) {
+	t.Parallel()
+	team := QualityPolicy{Approvers: []string{"qa-lead", "qa-tech"}}
+	open := QualityPolicy{}
+	cases := []struct {
+		name      string
+		policy    QualityPolicy
+		approver  string
+		requester string
+		ok        bool
+	}{
+		{"named approver", team, "qa-lead", "operator-1", true},
+		{"not on the list", team, "operator-2", "operator-1", false},
+		{"own request", team, "qa-lead", "qa-lead", false},
+		{"own request, case differs", team, "qa-lead", "QA-Lead", false},
+		{"empty list grants every user", open, "operator-2", "operator-1", true},
+		{"empty list still needs two people", open, "operator-1", "operator-1", false},
+		{"anonymous", open, "", "operator-1", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CanApprove(tc.policy, tc.approver, tc.requester)
+			if tc.ok && err != nil {
+				t.Fatalf("CanApprove = %v, want nil", err)
+			}
+			if !tc.ok && !errors.Is(err, ErrNotApprover) {
+				t.Fatalf("CanApprove = %v, want ErrNotApprover", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"shingocore/domain"
+	"shingocore/store"
+	"shingocore/store/replay"
+)
+
+// ReplayService puts the plant back the way it was across a past window —
+// robot poses, order states, bin places and slot holds — for the map and task
+// board's replay mode.
+//
+// Read from robot_confidence_samples, order_history and the bin rows of
+// audit_log (store/replay); nothing is recorded for replay's sake. How the rows
+// become a timeline, and what is derived rather than read, is domain/replay.go.
+type ReplayService struct {
+	db *store.DB
+}
+
+func NewReplayService(db *store.DB) *ReplayService {
+	return &ReplayService{db: db}
+}
+
+// ErrReplayWindow is a window Timeline will not read: empty, backwards, or
+// longer than domain.MaxReplayWindow.
+var ErrReplayWindow = errors.New("invalid replay window")
+
+// Timeline reads [from, to). stations scopes the orders and their slot holds
+// the way the task board's ?dashboard= does; empty is plant-wide. Robots and
+// bins are never scoped: a robot crossing the area or a bin moved into it is
+// part of what happened there.
+func (s *ReplayService) Timeline(from, to time.Time, stations []string) (*domain.ReplayTimeline, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: it must end after it starts", ErrReplayWindow)
+	}
+	if to.Sub(from) > domain.MaxReplayWindow {
+		return nil, fmt.Errorf("%w: it is at most %s", ErrReplayWindow, domain.MaxReplayWindow)
+	}
+	step := domain.ReplayStep(to.Sub(from))
+	poses, err := replay.RobotPoses(s.db.DB, from, to, step)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := replay.Orders(s.db.DB, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(stations) > 0 {
+		want := make(map[string]bool, len(stations))
+		for _, st := range stations {
+			want[st] = true
+		}
+		kept := orders[:0]
+		for _, o := range orders {
+			if want[o.StationID] {
+				kept = append(kept, o)
+			}
+		}
+		orders = kept
+	}
+	bins, err := replay.Bins(s.db.DB, from, to)
+	if err != nil {
+		return nil, err
+	}
+	tl := domain.BuildReplay(from, to, step, poses, orders, bins)
+	return &tl, nil
+}
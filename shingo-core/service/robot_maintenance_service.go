@@ -0,0 +1,237 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"shingocore/config"
+	"shingocore/fleet"
+	"shingocore/robothealth"
+	"shingocore/store"
+	"shingocore/store/robotmaintenance"
+)
+
+// RobotMaintenanceService is predictive robot maintenance: the health record
+// the engine samples off the robot poll, the indicators assessed from it, and
+// the work items filed when one is due.
+//
+// The engine writes the record, runs the hourly assessment and holds due
+// robots; the www handlers read the assessment and close items through here,
+// and Close gives a robot back when its last hold goes. What counts as due is
+// shingocore/robothealth's call.
+type RobotMaintenanceService struct {
+	db    *store.DB
+	fleet fleet.Backend
+}
+
+func NewRobotMaintenanceService(db *store.DB, f fleet.Backend) *RobotMaintenanceService {
+	return &RobotMaintenanceService{db: db, fleet: f}
+}
+
+// Re-exported for www and the engine, which must not import store packages
+// (depguard).
+type (
+	RobotHealthSample      = robotmaintenance.Sample
+	RobotFault             = robotmaintenance.Fault
+	RobotMaintenanceItem   = robotmaintenance.Item
+	RobotMaintenanceFilter = robotmaintenance.Filter
+)
+
+const (
+	MaintenanceItemOpen      = robotmaintenance.ItemOpen
+	MaintenanceItemDone      = robotmaintenance.ItemDone
+	MaintenanceItemDismissed = robotmaintenance.ItemDismissed
+)
+
+var (
+	ErrMaintenanceItemNotFound = robotmaintenance.ErrNotFound
+	ErrMaintenanceItemNotOpen  = robotmaintenance.ErrNotOpen
+)
+
+// RobotHealth is one robot's assessment with the days it was computed from.
+type RobotHealth struct {
+	robothealth.Assessment
+	Days      []robothealth.Day `json:"days"`
+	OdoM      float64           `json:"odo_m"`
+	SampledAt *time.Time        `json:"sampled_at,omitempty"`
+}
+
+// robotThresholds returns the section's due points as robothealth takes them.
+func robotThresholds(p config.RobotMaintenanceConfig) robothealth.Thresholds {
+	return robothealth.Thresholds{
+		BatteryCycles:   p.BatteryCycles,
+		CapacityFadePct: p.CapacityFadePct,
+		FaultsPerWeek:   p.FaultsPerWeek,
+		ServiceKm:       p.ServiceKm,
+		ConfidenceDrop:  p.ConfidenceDrop,
+	}
+}
+
+// Assess scores every robot in vehicles and every robot with a health record,
+// by vehicle id. A robot the fleet lists but the record has never seen is
+// assessed too, as no data, so the robots page can say so.
+func (s *RobotMaintenanceService) Assess(p config.RobotMaintenanceConfig, vehicles []string, now time.Time) ([]RobotHealth, error) {
+	now = now.UTC()
+	lookback := max(p.LookbackDays, 14)
+	since := now.Truncate(24*time.Hour).AddDate(0, 0, 1-lookback)
+	// A pair of samples further apart than three intervals was not one
+	// stretch of driving: the robot was off, or Core was.
+	days, err := robotmaintenance.Days(s.db.DB, since, now.Add(time.Second), 3*p.SampleInterval)
+	if err != nil {
+		return nil, err
+	}
+	faults, err := robotmaintenance.FaultsByCode(s.db.DB, now.AddDate(0, 0, -7), now.Add(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	latest, err := robotmaintenance.Latest(s.db.DB)
+	if err != nil {
+		return nil, err
+	}
+	earliest, err := robotmaintenance.Earliest(s.db.DB)
+	if err != nil {
+		return nil, err
+	}
+	serviced, err := robotmaintenance.LastService(s.db.DB, robothealth.ServiceInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]bool{}
+	for _, v := range vehicles {
+		ids[v] = true
+	}
+	for v := range latest {
+		ids[v] = true
+	}
+	t := robotThresholds(p)
+	out := make([]RobotHealth, 0, len(ids))
+	for v := range ids {
+		r := robothealth.Robot{VehicleID: v, Days: days[v], Now: now, FaultsByCode: faults[v]}
+		h := RobotHealth{Days: days[v]}
+		if l, ok := latest[v]; ok {
+			r.BatteryCycle, r.OdoM = l.Cycle, l.OdoM
+			h.OdoM = l.OdoM
+			at := l.At
+			h.SampledAt = &at
+		}
+		if sv, ok := serviced[v]; ok {
+			r.ServiceOdoM, r.ServiceSince = sv.OdoM, sv.At
+		} else if e, ok := earliest[v]; ok {
+			r.ServiceOdoM, r.ServiceSince = e.OdoM, e.At
+		}
+		h.Assessment = robothealth.Assess(r, t)
+		if h.Days == nil {
+			h.Days = []robothealth.Day{}
+		}
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].VehicleID < out[j].VehicleID })
+	return out, nil
+}
+
+// RecordSamples writes one poll's health samples.
+func (s *RobotMaintenanceService) RecordSamples(batch []RobotHealthSample) error {
+	return robotmaintenance.InsertSamples(s.db.DB, batch)
+}
+
+// RaiseFault records an alarm onset.
+func (s *RobotMaintenanceService) RaiseFault(f RobotFault) error {
+	return robotmaintenance.RaiseFault(s.db.DB, f)
+}
+
+// ClearFault records an alarm leaving the robot.
+func (s *RobotMaintenanceService) ClearFault(vehicleID string, code int, at time.Time) error {
+	return robotmaintenance.ClearFault(s.db.DB, vehicleID, code, at)
+}
+
+// OpenFaults returns the alarm codes open per robot.
+func (s *RobotMaintenanceService) OpenFaults() (map[string]map[int]bool, error) {
+	return robotmaintenance.OpenFaults(s.db.DB)
+}
+
+// Prune deletes samples and cleared faults older than before.
+func (s *RobotMaintenanceService) Prune(before time.Time) (int64, error) {
+	return robotmaintenance.Prune(s.db.DB, before)
+}
+
+// OpenItem files a work item for a due indicator, or refreshes the one
+// already open; created reports which.
+func (s *RobotMaintenanceService) OpenItem(vehicleID string, in robothealth.Indicator, now time.Time) (*RobotMaintenanceItem, bool, error) {
+	it := &RobotMaintenanceItem{
+		VehicleID: vehicleID, Indicator: in.Key, Value: in.Value,
+		Threshold: in.Threshold, Detail: in.Detail, OpenedAt: now,
+	}
+	created, err := robotmaintenance.OpenItem(s.db.DB, it)
+	if err != nil {
+		return nil, false, err
+	}
+	return it, created, nil
+}
+
+// SetHeld records whether an item holds its robot out of availability.
+func (s *RobotMaintenanceService) SetHeld(id int64, held bool) error {
+	return robotmaintenance.SetHeld(s.db.DB, id, held)
+}
+
+// List returns work items, newest first.
+func (s *RobotMaintenanceService) List(f RobotMaintenanceFilter) ([]*RobotMaintenanceItem, error) {
+	return robotmaintenance.List(s.db.DB, f)
+}
+
+// Close closes an open item as done or dismissed, at the robot's latest
+// odometer reading. When the item held its robot and no other open item does,
+// the robot is made available again and released is true. The release is here
+// and not with the caller so that every path that closes an item gives the
+// robot back.
+func (s *RobotMaintenanceService) Close(id int64, status, by, note string, now time.Time) (it *RobotMaintenanceItem, released bool, err error) {
+	cur, err := robotmaintenance.Get(s.db.DB, id)
+	if err != nil {
+		return nil, false, err
+	}
+	latest, err := robotmaintenance.Latest(s.db.DB)
+	if err != nil {
+		return nil, false, err
+	}
+	var odo *float64
+	if l, ok := latest[cur.VehicleID]; ok {
+		odo = &l.OdoM
+	}
+	if it, err = robotmaintenance.Close(s.db.DB, id, status, by, note, odo, now); err != nil {
+		return nil, false, err
+	}
+	if !it.Held {
+		return it, false, nil
+	}
+	still, err := robotmaintenance.StillHeld(s.db.DB, it.VehicleID, it.ID)
+	if err != nil || still {
+		return it, false, err
+	}
+	return it, s.release(it.VehicleID), nil
+}
+
+// release makes a robot available again. The item is closed either way; a
+// fleet that refuses leaves the robot for an operator to make available by
+// hand, and release reports false.
+func (s *RobotMaintenanceService) release(vehicleID string) bool {
+	rl, ok := s.fleet.(fleet.RobotLister)
+	if !ok {
+		return false
+	}
+	if err := rl.SetAvailability(vehicleID, true); err != nil {
+		log.Printf("robot maintenance: release %s: %v", vehicleID, err)
+		return false
+	}
+	return true
+}
+
+// Unnotified lists open items whose notification has not gone out.
+func (s *RobotMaintenanceService) Unnotified() ([]*RobotMaintenanceItem, error) {
+	return robotmaintenance.Unnotified(s.db.DB)
+}
+
+// MarkNotified records an item every channel has delivered.
+func (s *RobotMaintenanceService) MarkNotified(id int64, now time.Time) error {
+	return robotmaintenance.MarkNotified(s.db.DB, id, now)
+}
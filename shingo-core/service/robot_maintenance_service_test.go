@@ -0,0 +1,62 @@
+//go:build docker
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/fleet"
+	"shingocore/internal/testdb"
+	"shingocore/robothealth"
+)
+
+// availabilityFleet records the availability calls a RobotLister receives.
+type availabilityFleet struct {
+	*testdb.MockBackend
+	set map[string]bool
+}
+
+func (f *availabilityFleet) GetRobotsStatus() ([]fleet.RobotStatus, error) { return nil, nil }
+func (f *availabilityFleet) RetryFailed(string) error                      { return nil }
+func (f *availabilityFleet) ForceComplete(string) error                    { return nil }
+func (f *availabilityFleet) SetAvailability(vehicleID string, available bool) error {
+	f.set[vehicleID] = available
+	return nil
+}
+
+// TestRobotMaintenanceClose_ReleasesOnTheLastHold: Close itself gives the
+// robot back, and only when the item closed was the last one holding it, so
+// no caller has to remember to.
+func TestRobotMaintenanceClose_ReleasesOnTheLastHold(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	f := &availabilityFleet{MockBackend: testdb.NewSuccessBackend(), set: map[string]bool{}}
+	svc := NewRobotMaintenanceService(db, f)
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	var held []*RobotMaintenanceItem
+	for _, key := range []string{"service_interval", "battery_cycles"} {
+		it, _, err := svc.OpenItem("AMR-07", robothealth.Indicator{Key: key, Value: 2, Threshold: 1}, now)
+		if err != nil {
+			t.Fatalf("open %s: %v", key, err)
+		}
+		if err := svc.SetHeld(it.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		held = append(held, it)
+	}
+
+	if _, released, err := svc.Close(held[0].ID, MaintenanceItemDone, "tech", "", now); err != nil || released {
+		t.Fatalf("first close: released %v, %v; want held by the other item", released, err)
+	}
+	if _, ok := f.set["AMR-07"]; ok {
+		t.Fatal("robot made available while another item still holds it")
+	}
+	if _, released, err := svc.Close(held[1].ID, MaintenanceItemDismissed, "tech", "", now); err != nil || !released {
+		t.Fatalf("last close: released %v, %v; want released", released, err)
+	}
+	if !f.set["AMR-07"] {
+		t.Fatal("closing the last hold did not make the robot available")
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"time"
+
+	"shingo/protocol/clock"
+	"shingocore/domain"
+	"shingocore/store"
+	"shingocore/store/shiftreports"
+)
+
+// ShiftReportService is the end-of-shift report archive: building a shift's
+// report from the operational tables, filing it, and reading it back.
+//
+// Which shifts have ended is shingocore/shiftreport's call and the engine's
+// report loop drives both; this layer is the seam the www handlers read
+// through.
+type ShiftReportService struct {
+	db *store.DB
+}
+
+func NewShiftReportService(db *store.DB) *ShiftReportService {
+	return &ShiftReportService{db: db}
+}
+
+// Re-exported for www, which must not import store packages (depguard).
+type (
+	ShiftReport       = domain.ShiftReport
+	ShiftReportFilter = shiftreports.Filter
+)
+
+var ErrShiftReportNotFound = shiftreports.ErrNotFound
+
+// Generate builds the report for one shift window and files it. When that
+// window's report is already on file it is left alone — a report says what
+// was known when it was written — and Generate returns nil, false.
+func (s *ShiftReportService) Generate(shift, day, timezone string, start, end time.Time) (r *ShiftReport, created bool, err error) {
+	body, err := shiftreports.Build(s.db.DB, start, end)
+	if err != nil {
+		return nil, false, err
+	}
+	r = &ShiftReport{
+		Shift: shift, Day: day, Timezone: timezone,
+		Start: start.UTC(), End: end.UTC(), GeneratedAt: clock.Now().UTC(),
+		ShiftReportBody: body,
+	}
+	if created, err = shiftreports.Save(s.db.DB, r); err != nil || !created {
+		return nil, false, err
+	}
+	return r, true, nil
+}
+
+// Exists reports whether a shift window's report is already filed.
+func (s *ShiftReportService) Exists(shift string, start time.Time) (bool, error) {
+	return shiftreports.Exists(s.db.DB, shift, start)
+}
+
+// List returns filed reports, latest shift first.
+func (s *ShiftReportService) List(f ShiftReportFilter) ([]*ShiftReport, error) {
+	return shiftreports.List(s.db.DB, f)
+}
+
+// Get returns one report.
+func (s *ShiftReportService) Get(id int64) (*ShiftReport, error) {
+	return shiftreports.Get(s.db.DB, id)
+}
+
+// Unnotified lists reports for windows that ended after since whose
+// notification has not gone out.
+func (s *ShiftReportService) Unnotified(since time.Time) ([]*ShiftReport, error) {
+	return shiftreports.Unnotified(s.db.DB, since)
+}
+
+// MarkNotified records a report every channel has delivered.
+func (s *ShiftReportService) MarkNotified(id int64, now time.Time) error {
+	return shiftreports.MarkNotified(s.db.DB, id, now)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"shingocore/domain"
+	"shingocore/store"
+	"shingocore/store/oee"
+	"shingocore/store/starvation"
+)
+
+// StarvationService attributes each cell's downtime to the part of the
+// material path it was waiting on, per plant-local ISO week.
+//
+// Computed on read from the same downtime and material-wait queries OEE uses
+// (store/oee), so a week's starved minutes here are the starved minutes OEE's
+// availability split reports; store/starvation adds the child-order history
+// and edge-offline spans. The attribution is domain.AttributeStarvation's.
+type StarvationService struct {
+	db *store.DB
+}
+
+func NewStarvationService(db *store.DB) *StarvationService {
+	return &StarvationService{db: db}
+}
+
+// StarvationCell is one cell's week: the split and its Pareto bars.
+type StarvationCell struct {
+	CellID string `json:"cell_id"`
+	domain.StarvationAttribution
+	Pareto []domain.ParetoBar `json:"pareto"`
+}
+
+// StarvationWeek is one plant-local ISO week, Monday to Monday. End is
+// clipped to now for the week still running.
+type StarvationWeek struct {
+	Week    string           `json:"week"`
+	Start   time.Time        `json:"start"`
+	End     time.Time        `json:"end"`
+	Running bool             `json:"running"`
+	Cells   []StarvationCell `json:"cells"`
+	Plant   StarvationCell   `json:"plant"`
+}
+
+// MaxStarvationWeeks bounds how far back Weeks reads; each week is four
+// window queries.
+const MaxStarvationWeeks = 26
+
+// Weeks computes the last n plant-local ISO weeks, newest first, the first
+// being the week containing now. Only cells with downtime in a week appear in
+// it; Plant is every cell's minutes summed.
+func (s *StarvationService) Weeks(n int, loc *time.Location, now time.Time) ([]StarvationWeek, error) {
+	if n < 1 || n > MaxStarvationWeeks {
+		return nil, fmt.Errorf("weeks must be between 1 and %d", MaxStarvationWeeks)
+	}
+	monday := WeekStart(now, loc)
+	out := make([]StarvationWeek, 0, n)
+	for i := 0; i < n; i++ {
+		start := monday.AddDate(0, 0, -7*i)
+		end := start.AddDate(0, 0, 7)
+		wk := StarvationWeek{Week: ISOWeekLabel(start), Start: start, End: end}
+		if now.Before(end) {
+			wk.Running = true
+			wk.End = now
+		}
+		if err := s.week(&wk); err != nil {
+			return nil, err
+		}
+		out = append(out, wk)
+	}
+	return out, nil
+}
+
+// week fills one week's cells.
+func (s *StarvationService) week(wk *StarvationWeek) error {
+	down, err := oee.Downtime(s.db.DB, wk.Start, wk.End)
+	if err != nil {
+		return err
+	}
+	waits, err := oee.MaterialWaits(s.db.DB, wk.Start, wk.End)
+	if err != nil {
+		return err
+	}
+	history, err := starvation.OrderHistory(s.db.DB, wk.Start, wk.End)
+	if err != nil {
+		return err
+	}
+	offline, err := starvation.EdgeOffline(s.db.DB, wk.Start, wk.End)
+	if err != nil {
+		return err
+	}
+
+	wk.Plant = StarvationCell{CellID: "Plant"}
+	for cell, ivs := range down {
+		var spans []domain.CauseSpan
+		for _, rows := range history[cell] {
+			spans = append(spans, domain.OrderCauseSpans(rows, wk.End)...)
+		}
+		a := domain.AttributeStarvation(domain.StarvationInput{
+			Window:        domain.OEEInterval{Start: wk.Start, End: wk.End},
+			Downtime:      ivs,
+			MaterialWaits: waits[cell],
+			EdgeOffline:   offline[cell],
+			Orders:        spans,
+		})
+		if a.DowntimeMinutes == 0 {
+			continue
+		}
+		wk.Cells = append(wk.Cells, StarvationCell{CellID: cell, StarvationAttribution: a, Pareto: a.Pareto()})
+		wk.Plant.Add(a)
+	}
+	wk.Plant.Pareto = wk.Plant.StarvationAttribution.Pareto()
+	sort.Slice(wk.Cells, func(i, j int) bool {
+		if wk.Cells[i].StarvedMinutes != wk.Cells[j].StarvedMinutes {
+			return wk.Cells[i].StarvedMinutes > wk.Cells[j].StarvedMinutes
+		}
+		return wk.Cells[i].CellID < wk.Cells[j].CellID
+	})
+	return nil
+}
+
+// WeekStart is plant-local midnight of the Monday on or before t.
+func WeekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	back := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-back, 0, 0, 0, 0, loc)
+}
+
+// ISOWeekLabel is t's ISO week as 2026-W42.
+func ISOWeekLabel(t time.Time) string {
+	y, w := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", y, w)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWeekStartIsPlantLocalMonday: a Sunday evening is still the week that
+// began the previous Monday, in the plant's zone — not UTC's, where the same
+// instant is already Monday.
+func TestWeekStartIsPlantLocalMonday(t *testing.T) {
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("tzdata: %v", err)
+	}
+	sunday := time.Date(2026, 10, 18, 21, 0, 0, 0, loc) // 02:00 Monday UTC
+	got := WeekStart(sunday, loc)
+	if want := time.Date(2026, 10, 12, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("WeekStart = %v, want %v", got, want)
+	}
+	if l := ISOWeekLabel(got); l != "2026-W42" {
+		t.Errorf("label = %q, want 2026-W42", l)
+	}
+	// ISO week-years: 2027-01-01 is a Friday and belongs to 2026-W53.
+	if l := ISOWeekLabel(WeekStart(time.Date(2027, 1, 1, 12, 0, 0, 0, loc), loc)); l != "2026-W53" {
+		t.Errorf("new-year label = %q, want 2026-W53", l)
+	}
+}
@@ -22,6 +22,7 @@
 package service
 
 import (
+	"database/sql"
 	"math"
 	"time"
 
@@ -109,26 +110,10 @@ type CalculateResult struct {
 // window, runs the formula, and scores confidence from data coverage.
 func (s *ThresholdCalculatorService) Calculate(req CalculateRequest) (CalculateResult, error) {
 	db := s.db.DB
-	in := ThresholdCalculatorInputs{
-		SafetyFactor:   req.SafetyFactor,
-		BinCapacityUOP: req.BinCapacityUOP,
-		CycleSeconds:   req.CycleSeconds,
-	}
-	if v, err := orders.AvgL1QueueSeconds(db, req.PayloadCode, req.DateRange); err == nil {
-		in.L1QueueSeconds = v
-	}
-	if v, err := orders.AvgL1TransitSeconds(db, req.PayloadCode, req.DateRange); err == nil {
-		in.L1TransitSeconds = v
-	}
-	if v, err := orders.MedianL2LoadSeconds(db, req.PayloadCode, req.DateRange); err == nil {
-		in.L2LoadSeconds = v
-	}
-	// L2 (store) transit was keyed on the plain-store family, removed here — no
-	// store orders exist, so this auto-fetch always returned 0. L2TransitSeconds
-	// stays an operator-editable modal input, now defaulting to 0.
-	if v, err := orders.P95MarketToCellSeconds(db, req.PayloadCode, req.DateRange); err == nil {
-		in.MarketToCellSeconds = v
-	}
+	in := observedLeadTimes(db, req.PayloadCode, req.DateRange)
+	in.SafetyFactor = req.SafetyFactor
+	in.BinCapacityUOP = req.BinCapacityUOP
+	in.CycleSeconds = req.CycleSeconds
 
 	out := CalculateThresholds(in)
 
@@ -155,6 +140,29 @@ func (s *ThresholdCalculatorService) Calculate(req CalculateRequest) (CalculateR
 	}, nil
 }
 
+// observedLeadTimes fetches the lead-time inputs from Core's order_history
+// over the window. A helper with no samples leaves its input at 0. The demand
+// forecast (demand_forecast_service.go) reads the same L1 lead.
+func observedLeadTimes(db *sql.DB, payloadCode string, rng orders.LeadTimeRange) ThresholdCalculatorInputs {
+	var in ThresholdCalculatorInputs
+	if v, err := orders.AvgL1QueueSeconds(db, payloadCode, rng); err == nil {
+		in.L1QueueSeconds = v
+	}
+	if v, err := orders.AvgL1TransitSeconds(db, payloadCode, rng); err == nil {
+		in.L1TransitSeconds = v
+	}
+	if v, err := orders.MedianL2LoadSeconds(db, payloadCode, rng); err == nil {
+		in.L2LoadSeconds = v
+	}
+	// L2 (store) transit was keyed on the plain-store family, removed here — no
+	// store orders exist, so this auto-fetch always returned 0. L2TransitSeconds
+	// stays an operator-editable modal input, now defaulting to 0.
+	if v, err := orders.P95MarketToCellSeconds(db, payloadCode, rng); err == nil {
+		in.MarketToCellSeconds = v
+	}
+	return in
+}
+
 // CalculateDays is the handler-facing entrypoint: builds a days-lookback window
 // ending now and runs Calculate, so www handlers can call the calculator with
 // primitives instead of importing the store's range type.
@@ -0,0 +1,143 @@
+// Package shiftreport turns the configured shift pattern into the concrete
+// windows end-of-shift reports are built over. It is pure — the plant
+// timezone and the clock are arguments — so midnight-spanning shifts and
+// daylight-saving days are pinned by plain unit tests.
+//
+// The engine owns the rest (engine/engine_shift_reports.go): it asks which
+// windows have ended, builds and stores a report for each through
+// service.ShiftReportService, and delivers it.
+package shiftreport
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"shingocore/config"
+)
+
+// Window is one occurrence of a shift: its name, the plant-local date it
+// started on, and the instants it ran between, [Start, End).
+type Window struct {
+	Shift string
+	Day   string
+	Start time.Time
+	End   time.Time
+}
+
+// Validate checks a shift pattern: every shift named, once, with two
+// wall-clock times that differ.
+func Validate(shifts []config.ReportShift) error {
+	seen := make(map[string]bool)
+	for _, s := range shifts {
+		if strings.TrimSpace(s.Name) == "" {
+			return fmt.Errorf("report shift %s–%s: name is required", s.Start, s.End)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("report shift %q: duplicate name", s.Name)
+		}
+		seen[s.Name] = true
+		start, ok1 := clockMinutes(s.Start)
+		end, ok2 := clockMinutes(s.End)
+		if !ok1 || !ok2 {
+			return fmt.Errorf("report shift %q: start and end must be HH:MM", s.Name)
+		}
+		if start == end {
+			return fmt.Errorf("report shift %q: start and end are the same time", s.Name)
+		}
+	}
+	return nil
+}
+
+// Ended returns every window of shifts that ended in (after, until], oldest
+// end first. Times are wall-clock in loc; a shift whose end is at or before
+// its start ends the next day. Shifts that do not validate are skipped — the
+// caller reports Validate's error.
+//
+// A wall-clock time that falls in a daylight-saving gap resolves the way
+// time.Date does (02:30 on spring-forward day is 03:30), so the shift
+// spanning the change is an hour shorter or longer, as it was on the floor.
+func Ended(shifts []config.ReportShift, loc *time.Location, after, until time.Time) []Window {
+	var out []Window
+	first := after.In(loc).AddDate(0, 0, -1)
+	last := until.In(loc)
+	for _, s := range shifts {
+		start, ok1 := clockMinutes(s.Start)
+		end, ok2 := clockMinutes(s.End)
+		if !ok1 || !ok2 || start == end || strings.TrimSpace(s.Name) == "" {
+			continue
+		}
+		for d := dateOf(first, loc); !d.After(dateOf(last, loc)); d = d.AddDate(0, 0, 1) {
+			w := window(s.Name, d, start, end, loc)
+			if w.End.After(after) && !w.End.After(until) {
+				out = append(out, w)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].End.Equal(out[j].End) {
+			return out[i].End.Before(out[j].End)
+		}
+		return out[i].Shift < out[j].Shift
+	})
+	return out
+}
+
+// ForDay returns the windows of shifts that start on the plant-local date
+// day ("YYYY-MM-DD"), in start order. A night shift belongs to the day it
+// started on, the same way Ended labels it. Shifts that do not validate are
+// skipped.
+func ForDay(shifts []config.ReportShift, loc *time.Location, day string) ([]Window, error) {
+	d, err := time.ParseInLocation("2006-01-02", day, loc)
+	if err != nil {
+		return nil, fmt.Errorf("day %q: want YYYY-MM-DD", day)
+	}
+	var out []Window
+	for _, s := range shifts {
+		start, ok1 := clockMinutes(s.Start)
+		end, ok2 := clockMinutes(s.End)
+		if !ok1 || !ok2 || start == end || strings.TrimSpace(s.Name) == "" {
+			continue
+		}
+		out = append(out, window(s.Name, d, start, end, loc))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+// window builds the occurrence of a shift that starts on day.
+func window(name string, day time.Time, start, end int, loc *time.Location) Window {
+	y, m, d := day.Date()
+	w := Window{
+		Shift: name,
+		Day:   day.Format("2006-01-02"),
+		Start: time.Date(y, m, d, start/60, start%60, 0, 0, loc),
+	}
+	if end <= start {
+		d++
+	}
+	w.End = time.Date(y, m, d, end/60, end%60, 0, 0, loc)
+	return w
+}
+
+// dateOf is midnight of t's calendar day in loc.
+func dateOf(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// clockMinutes parses "HH:MM" into minutes past midnight.
+func clockMinutes(s string) (int, bool) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, false
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || hh > 23 || mm < 0 || mm > 59 {
+		return 0, false
+	}
+	return hh*60 + mm, true
+}
@@ -0,0 +1,130 @@
+package shiftreport
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/config"
+)
+
+func chicago(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("tz database unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(config.DefaultReportShifts()); err != nil {
+		t.Fatalf("shipped shifts: %v", err)
+	}
+	bad := map[string][]config.ReportShift{
+		"no name":     {{Start: "06:00", End: "14:00"}},
+		"duplicate":   {{Name: "a", Start: "06:00", End: "14:00"}, {Name: "a", Start: "14:00", End: "22:00"}},
+		"bad clock":   {{Name: "a", Start: "6am", End: "14:00"}},
+		"out of day":  {{Name: "a", Start: "06:00", End: "24:00"}},
+		"zero length": {{Name: "a", Start: "06:00", End: "06:00"}},
+	}
+	for name, shifts := range bad {
+		if err := Validate(shifts); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestEndedPlantLocalAndOvernight(t *testing.T) {
+	loc := chicago(t)
+	// 06:10 plant time on Oct 18: the night shift that began at 22:00 on the
+	// 17th has just ended, and nothing else has since 23:00 on the 17th.
+	until := time.Date(2026, 10, 18, 6, 10, 0, 0, loc)
+	after := time.Date(2026, 10, 17, 23, 0, 0, 0, loc)
+	got := Ended(config.DefaultReportShifts(), loc, after, until)
+	if len(got) != 1 {
+		t.Fatalf("got %d windows, want 1: %+v", len(got), got)
+	}
+	w := got[0]
+	if w.Shift != "3rd" || w.Day != "2026-10-17" {
+		t.Errorf("got %s on %s, want 3rd on 2026-10-17", w.Shift, w.Day)
+	}
+	if want := time.Date(2026, 10, 17, 22, 0, 0, 0, loc); !w.Start.Equal(want) {
+		t.Errorf("start %v, want %v", w.Start, want)
+	}
+	if want := time.Date(2026, 10, 18, 6, 0, 0, 0, loc); !w.End.Equal(want) {
+		t.Errorf("end %v, want %v", w.End, want)
+	}
+	// In UTC the night shift ends at 11:00, not at the server's 06:00.
+	if h := w.End.UTC().Hour(); h != 11 {
+		t.Errorf("end is %02d:00 UTC, want 11:00", h)
+	}
+}
+
+func TestEndedOrderAndBounds(t *testing.T) {
+	loc := chicago(t)
+	until := time.Date(2026, 10, 18, 22, 0, 0, 0, loc)
+	after := until.Add(-24 * time.Hour)
+	got := Ended(config.DefaultReportShifts(), loc, after, until)
+	var names []string
+	for _, w := range got {
+		names = append(names, w.Day+" "+w.Shift)
+	}
+	// The 2nd shift of the 17th ended exactly at after, so it is excluded; the
+	// one ending exactly at until is included.
+	want := []string{"2026-10-17 3rd", "2026-10-18 1st", "2026-10-18 2nd"}
+	if len(names) != len(want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got %v, want %v", names, want)
+		}
+	}
+}
+
+func TestEndedAcrossDaylightSavingEnd(t *testing.T) {
+	loc := chicago(t)
+	// Clocks go back at 02:00 on 2026-11-01: the night shift of Oct 31 runs
+	// nine real hours.
+	until := time.Date(2026, 11, 1, 7, 0, 0, 0, loc)
+	got := Ended([]config.ReportShift{{Name: "night", Start: "22:00", End: "06:00"}}, loc, until.Add(-2*time.Hour), until)
+	if len(got) != 1 {
+		t.Fatalf("got %d windows, want 1", len(got))
+	}
+	if d := got[0].End.Sub(got[0].Start); d != 9*time.Hour {
+		t.Errorf("night shift ran %v, want 9h", d)
+	}
+}
+
+func TestEndedSkipsInvalidShifts(t *testing.T) {
+	until := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	shifts := []config.ReportShift{
+		{Name: "bad", Start: "nope", End: "10:00"},
+		{Name: "day", Start: "02:00", End: "10:00"},
+	}
+	got := Ended(shifts, time.UTC, until.Add(-12*time.Hour), until)
+	if len(got) != 1 || got[0].Shift != "day" {
+		t.Fatalf("got %+v, want only the valid shift", got)
+	}
+}
+
+func TestForDayLabelsNightShiftByItsStart(t *testing.T) {
+	loc := chicago(t)
+	got, err := ForDay(config.DefaultReportShifts(), loc, "2026-10-18")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d windows, want 3: %+v", len(got), got)
+	}
+	last := got[len(got)-1]
+	if last.Shift != "3rd" || last.Day != "2026-10-18" {
+		t.Errorf("last window %s on %s, want 3rd on 2026-10-18", last.Shift, last.Day)
+	}
+	if want := time.Date(2026, 10, 19, 6, 0, 0, 0, loc); !last.End.Equal(want) {
+		t.Errorf("night shift ends %v, want %v — the next morning", last.End, want)
+	}
+	if _, err := ForDay(config.DefaultReportShifts(), loc, "18/10/2026"); err == nil {
+		t.Error("malformed day: want an error")
+	}
+}
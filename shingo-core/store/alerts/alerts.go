@@ -0,0 +1,419 @@
+// Package alerts is the persistence layer for the alert rules engine (v98).
+//
+// One row per alert: raising the same rule on the same subject while an
+// earlier alert for it is unresolved updates that row instead of adding one,
+// which is the de-duplication. Resolved rows stay — the table is the history
+// a post-incident review reads.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; shingocore/alerting decides what to raise, and
+// service/alert_service.go wraps these for the engine and the www handlers.
+package alerts
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shingo/protocol"
+)
+
+// Status is where an alert is in its life.
+type Status string
+
+const (
+	StatusOpen         Status = "open"
+	StatusAcknowledged Status = "acknowledged"
+	StatusResolved     Status = "resolved"
+)
+
+// ErrNotFound is returned when no alert has the id.
+var ErrNotFound = errors.New("alert not found")
+
+// ErrNotOpen is returned when acknowledging an alert that is not open.
+var ErrNotOpen = errors.New("alert is not open")
+
+// Alert is one row of alerts.
+type Alert struct {
+	ID             int64      `json:"id"`
+	Rule           string     `json:"rule"`
+	Kind           string     `json:"kind"`
+	DedupKey       string     `json:"dedup_key"`
+	Severity       string     `json:"severity"`
+	Subject        string     `json:"subject"`
+	Detail         string     `json:"detail"`
+	Status         Status     `json:"status"`
+	Occurrences    int        `json:"occurrences"`
+	FirstSeen      time.Time  `json:"first_seen"`
+	LastSeen       time.Time  `json:"last_seen"`
+	NotifiedAt     *time.Time `json:"notified_at,omitempty"`
+	EscalatedAt    *time.Time `json:"escalated_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Raise is what a rule found: one subject, under the rule's de-dup key.
+type Raise struct {
+	Rule     string
+	Kind     string
+	DedupKey string
+	Severity string
+	Subject  string
+	Detail   string
+}
+
+// Filter narrows List. Zero values match everything; Limit defaults to 200.
+type Filter struct {
+	Status Status
+	Rule   string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+const selectCols = `id, rule, kind, dedup_key, severity, subject, detail, status, occurrences,
+	first_seen, last_seen, notified_at, escalated_at, acknowledged_by, acknowledged_at, resolved_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface{ Scan(...any) error }
+
+func scanAlert(s rowScanner) (*Alert, error) {
+	var (
+		a                            Alert
+		notified, escalated, ack, rs sql.NullTime
+	)
+	if err := s.Scan(&a.ID, &a.Rule, &a.Kind, &a.DedupKey, &a.Severity, &a.Subject, &a.Detail,
+		&a.Status, &a.Occurrences, &a.FirstSeen, &a.LastSeen, &notified, &escalated,
+		&a.AcknowledgedBy, &ack, &rs); err != nil {
+		return nil, err
+	}
+	a.NotifiedAt = nullTime(notified)
+	a.EscalatedAt = nullTime(escalated)
+	a.AcknowledgedAt = nullTime(ack)
+	a.ResolvedAt = nullTime(rs)
+	return &a, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
+
+func scanAlerts(rows *sql.Rows) ([]*Alert, error) {
+	defer rows.Close()
+	var out []*Alert
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// upsert raises r, or refreshes the unresolved alert it duplicates. bump adds
+// to occurrences: an event raising again is another occurrence, a periodic
+// condition still holding on the next pass is not. Severity is never lowered
+// by the refresh — an escalated alert stays escalated.
+func upsert(q interface {
+	QueryRow(string, ...any) *sql.Row
+}, r Raise, bump int, now time.Time) (*Alert, bool, error) {
+	var created bool
+	row := q.QueryRow(`INSERT INTO alerts (rule, kind, dedup_key, severity, subject, detail, first_seen, last_seen)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$7)
+		ON CONFLICT (rule, dedup_key) WHERE resolved_at IS NULL DO UPDATE SET
+			occurrences = alerts.occurrences + $8,
+			subject     = EXCLUDED.subject,
+			detail      = EXCLUDED.detail,
+			last_seen   = EXCLUDED.last_seen
+		RETURNING `+selectCols+`, (xmax = 0)`,
+		r.Rule, r.Kind, r.DedupKey, r.Severity, r.Subject, r.Detail, now, bump)
+	a, err := scanAlert(scanTail{row, &created})
+	if err != nil {
+		return nil, false, fmt.Errorf("raise alert %s/%s: %w", r.Rule, r.DedupKey, err)
+	}
+	return a, created, nil
+}
+
+// scanTail appends one trailing destination to a row scan, so upsert can read
+// the inserted flag after the alert columns without a second scanner.
+type scanTail struct {
+	row  rowScanner
+	tail any
+}
+
+func (s scanTail) Scan(dest ...any) error { return s.row.Scan(append(dest, s.tail)...) }
+
+// RaiseEvent raises an alert from an engine event. A repeat while it is still
+// unresolved counts another occurrence on the same row. created reports
+// whether this was a new alert.
+func RaiseEvent(db *sql.DB, r Raise, now time.Time) (a *Alert, created bool, err error) {
+	return upsert(db, r, 1, now)
+}
+
+// Sync writes one periodic rule's evaluation in one transaction: every due
+// finding is raised or refreshed, and every unresolved alert of the rule
+// whose key is no longer in holding is resolved. It returns the alerts this
+// pass created.
+func Sync(db *sql.DB, rule string, due []Raise, holding []string, now time.Time) ([]*Alert, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var created []*Alert
+	for _, r := range due {
+		a, isNew, err := upsert(tx, r, 0, now)
+		if err != nil {
+			return nil, err
+		}
+		if isNew {
+			created = append(created, a)
+		}
+	}
+	keep, args := notIn("dedup_key", holding, 3)
+	if _, err := tx.Exec(`UPDATE alerts SET status = 'resolved', resolved_at = $1
+		WHERE rule = $2 AND resolved_at IS NULL AND kind <> 'event'`+keep,
+		append([]any{now, rule}, args...)...); err != nil {
+		return nil, fmt.Errorf("resolve alerts for %s: %w", rule, err)
+	}
+	return created, tx.Commit()
+}
+
+// ResolveRulesExcept resolves every unresolved periodic alert whose rule is
+// not in rules — a rule removed from the config leaves nothing open behind it.
+func ResolveRulesExcept(db *sql.DB, rules []string, now time.Time) (int64, error) {
+	keep, args := notIn("rule", rules, 2)
+	res, err := db.Exec(`UPDATE alerts SET status = 'resolved', resolved_at = $1
+		WHERE resolved_at IS NULL AND kind <> 'event'`+keep,
+		append([]any{now}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("resolve alerts of removed rules: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// notIn renders " AND col NOT IN ($n, …)" numbered from first, or nothing
+// for an empty list (which excludes nothing). The package takes a bare *sql.DB
+// with the driver wired above it, so lists go positional rather than as arrays.
+func notIn(col string, vals []string, first int) (string, []any) {
+	if len(vals) == 0 {
+		return "", nil
+	}
+	ph := make([]string, len(vals))
+	args := make([]any, len(vals))
+	for i, v := range vals {
+		ph[i] = fmt.Sprintf("$%d", first+i)
+		args[i] = v
+	}
+	return " AND " + col + " NOT IN (" + strings.Join(ph, ",") + ")", args
+}
+
+// Acknowledge records who saw an open alert. An event alert has no condition
+// that could clear it, so acknowledging one resolves it too.
+func Acknowledge(db *sql.DB, id int64, actor string, now time.Time) (*Alert, error) {
+	row := db.QueryRow(`UPDATE alerts SET
+			status          = CASE WHEN kind = 'event' THEN 'resolved' ELSE 'acknowledged' END,
+			resolved_at     = CASE WHEN kind = 'event' THEN $3 ELSE resolved_at END,
+			acknowledged_by = $2,
+			acknowledged_at = $3
+		WHERE id = $1 AND status = 'open'
+		RETURNING `+selectCols, id, actor, now)
+	a, err := scanAlert(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, gerr := Get(db, id); gerr != nil {
+			return nil, gerr
+		}
+		return nil, ErrNotOpen
+	}
+	if err != nil {
+		return nil, fmt.Errorf("acknowledge alert %d: %w", id, err)
+	}
+	return a, nil
+}
+
+// Unnotified lists unresolved alerts whose notification has not gone out —
+// new ones, escalated ones, and any held back by quiet hours.
+func Unnotified(db *sql.DB) ([]*Alert, error) {
+	rows, err := db.Query(`SELECT ` + selectCols + ` FROM alerts
+		WHERE resolved_at IS NULL AND notified_at IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list unnotified alerts: %w", err)
+	}
+	return scanAlerts(rows)
+}
+
+// MarkNotified records that an alert's notification was delivered.
+func MarkNotified(db *sql.DB, id int64, now time.Time) error {
+	if _, err := db.Exec(`UPDATE alerts SET notified_at = $2 WHERE id = $1`, id, now); err != nil {
+		return fmt.Errorf("mark alert %d notified: %w", id, err)
+	}
+	return nil
+}
+
+// Unacknowledged lists open alerts not yet escalated — the escalation pass's
+// candidates.
+func Unacknowledged(db *sql.DB) ([]*Alert, error) {
+	rows, err := db.Query(`SELECT ` + selectCols + ` FROM alerts
+		WHERE status = 'open' AND escalated_at IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list unacknowledged alerts: %w", err)
+	}
+	return scanAlerts(rows)
+}
+
+// Escalate raises an open alert's severity and clears notified_at, so the
+// delivery pass sends it again as an escalation (quiet hours permitting).
+// Guarded on status, so an acknowledgement that landed first wins.
+func Escalate(db *sql.DB, id int64, severity string, now time.Time) (bool, error) {
+	res, err := db.Exec(`UPDATE alerts SET severity = $2, escalated_at = $3, notified_at = NULL
+		WHERE id = $1 AND status = 'open' AND escalated_at IS NULL`, id, severity, now)
+	if err != nil {
+		return false, fmt.Errorf("escalate alert %d: %w", id, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Get returns one alert.
+func Get(db *sql.DB, id int64) (*Alert, error) {
+	a, err := scanAlert(db.QueryRow(`SELECT `+selectCols+` FROM alerts WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get alert %d: %w", id, err)
+	}
+	return a, nil
+}
+
+// List returns alerts newest first. Status "unresolved" matches open and
+// acknowledged together, which is what the alerts page shows by default.
+func List(db *sql.DB, f Filter) ([]*Alert, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(clause string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	switch f.Status {
+	case "":
+	case "unresolved":
+		where = append(where, "resolved_at IS NULL")
+	default:
+		add("status = $%d", string(f.Status))
+	}
+	if f.Rule != "" {
+		add("rule = $%d", f.Rule)
+	}
+	if !f.Since.IsZero() {
+		add("first_seen >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("first_seen < $%d", f.Until)
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	q := `SELECT ` + selectCols + ` FROM alerts`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	args = append(args, limit)
+	q += fmt.Sprintf(` ORDER BY first_seen DESC, id DESC LIMIT $%d`, len(args))
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list alerts: %w", err)
+	}
+	return scanAlerts(rows)
+}
+
+// OrderAge is an active order and when it entered its current status.
+type OrderAge struct {
+	ID        int64
+	StationID string
+	Status    string
+	Since     time.Time
+}
+
+// ActiveOrderAges returns every non-terminal order with the time it entered
+// its current status — the latest order_history row for that status, falling
+// back to the order's updated_at for an order with no history row.
+func ActiveOrderAges(db *sql.DB) ([]OrderAge, error) {
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT o.id, o.station_id, o.status, COALESCE(h.at, o.updated_at)
+		FROM orders o
+		LEFT JOIN LATERAL (
+			SELECT MAX(created_at) AS at FROM order_history
+			WHERE order_id = o.id AND status = o.status
+		) h ON TRUE
+		WHERE o.status NOT IN (%s)`, protocol.TerminalStatusSQLList()))
+	if err != nil {
+		return nil, fmt.Errorf("active order ages: %w", err)
+	}
+	defer rows.Close()
+	var out []OrderAge
+	for rows.Next() {
+		var o OrderAge
+		if err := rows.Scan(&o.ID, &o.StationID, &o.Status, &o.Since); err != nil {
+			return nil, fmt.Errorf("scan order age: %w", err)
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// LinesideLevel is one edge_lineside_reports row reduced to a level.
+type LinesideLevel struct {
+	Station    string
+	Node       string
+	Payload    string
+	Level      int
+	ReportedAt time.Time
+}
+
+// LinesideLevels returns every reported lineside level: the units in the bin
+// at the node plus the loose bucket, the same sum the threshold monitor reads.
+func LinesideLevels(db *sql.DB) ([]LinesideLevel, error) {
+	rows, err := db.Query(`SELECT station, core_node_name, payload_code, bin_uop + bucket_qty, reported_at
+		FROM edge_lineside_reports`)
+	if err != nil {
+		return nil, fmt.Errorf("lineside levels: %w", err)
+	}
+	defer rows.Close()
+	var out []LinesideLevel
+	for rows.Next() {
+		var l LinesideLevel
+		if err := rows.Scan(&l.Station, &l.Node, &l.Payload, &l.Level, &l.ReportedAt); err != nil {
+			return nil, fmt.Errorf("scan lineside level: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// BoardSignature summarizes the unresolved alerts so a change to them can be
+// noticed without diffing rows: how many there are, the newest id, and how
+// many have escalated or been acknowledged. A raise moves the id or the
+// count, a resolve the count, escalation and acknowledgement their own. A
+// refresh of a still-holding alert moves none of them, which is the point —
+// it is not a change anyone watching a board would see.
+func BoardSignature(db *sql.DB) (string, error) {
+	var total, maxID, escalated, acked int64
+	err := db.QueryRow(`SELECT COUNT(*), COALESCE(MAX(id), 0), COUNT(escalated_at), COUNT(acknowledged_at)
+		FROM alerts WHERE resolved_at IS NULL`).Scan(&total, &maxID, &escalated, &acked)
+	if err != nil {
+		return "", fmt.Errorf("alert board signature: %w", err)
+	}
+	return fmt.Sprintf("%d/%d/%d/%d", total, maxID, escalated, acked), nil
+}
@@ -0,0 +1,111 @@
+//go:build docker
+
+package alerts_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/alerts"
+)
+
+// TestSync_DeduplicatesAndResolves walks one periodic rule through three
+// passes: a finding raises one alert, the same finding on the next pass
+// refreshes that row rather than adding one, and a finding that drops out of
+// holding resolves. Raised again afterwards, it is a new alert — the history
+// keeps the two incidents apart.
+func TestSync_DeduplicatesAndResolves(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	r := alerts.Raise{Rule: "robot-low", Kind: "robot_low_confidence", DedupKey: "robot:AMR-01",
+		Severity: "warning", Subject: "Robot AMR-01 localization low", Detail: "confidence 0.31"}
+
+	created, err := alerts.Sync(db.DB, r.Rule, []alerts.Raise{r}, []string{r.DedupKey}, now)
+	if err != nil || len(created) != 1 {
+		t.Fatalf("first pass: created %d, err %v; want 1", len(created), err)
+	}
+	first := created[0]
+
+	r.Detail = "confidence 0.28"
+	created, err = alerts.Sync(db.DB, r.Rule, []alerts.Raise{r}, []string{r.DedupKey}, now.Add(30*time.Second))
+	if err != nil || len(created) != 0 {
+		t.Fatalf("second pass: created %d, err %v; want 0 (same alert)", len(created), err)
+	}
+	a, err := alerts.Get(db.DB, first.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if a.Detail != "confidence 0.28" || a.Occurrences != 1 || !a.LastSeen.After(a.FirstSeen) {
+		t.Errorf("refreshed alert = %+v; want new detail, one occurrence, last_seen moved", a)
+	}
+
+	if _, err := alerts.Sync(db.DB, r.Rule, nil, nil, now.Add(time.Minute)); err != nil {
+		t.Fatalf("clear pass: %v", err)
+	}
+	if a, _ := alerts.Get(db.DB, first.ID); a.Status != alerts.StatusResolved || a.ResolvedAt == nil {
+		t.Errorf("after clearing: status %q resolved_at %v, want resolved", a.Status, a.ResolvedAt)
+	}
+
+	created, _ = alerts.Sync(db.DB, r.Rule, []alerts.Raise{r}, []string{r.DedupKey}, now.Add(2*time.Minute))
+	if len(created) != 1 || created[0].ID == first.ID {
+		t.Errorf("raised again: %+v, want a new alert", created)
+	}
+}
+
+// TestAcknowledgeAndEscalate: an unacknowledged alert escalates once, which
+// re-arms its notification; an acknowledged one cannot escalate; an event
+// alert resolves on acknowledgement because nothing else would clear it.
+func TestAcknowledgeAndEscalate(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	now := time.Now().UTC()
+
+	ev := alerts.Raise{Rule: "failures", Kind: "event", DedupKey: "order:41", Severity: "warning", Subject: "Order 41 failed"}
+	a, created, err := alerts.RaiseEvent(db.DB, ev, now)
+	if err != nil || !created {
+		t.Fatalf("raise event: created %v err %v", created, err)
+	}
+	if again, created, _ := alerts.RaiseEvent(db.DB, ev, now.Add(time.Second)); created || again.Occurrences != 2 {
+		t.Errorf("repeat event: created %v occurrences %d, want same alert with 2", created, again.Occurrences)
+	}
+	if err := alerts.MarkNotified(db.DB, a.ID, now); err != nil {
+		t.Fatalf("mark notified: %v", err)
+	}
+
+	ok, err := alerts.Escalate(db.DB, a.ID, "critical", now.Add(time.Hour))
+	if err != nil || !ok {
+		t.Fatalf("escalate: %v %v", ok, err)
+	}
+	if ok, _ := alerts.Escalate(db.DB, a.ID, "critical", now.Add(2*time.Hour)); ok {
+		t.Error("escalated twice")
+	}
+	pending, _ := alerts.Unnotified(db.DB)
+	if len(pending) != 1 || pending[0].Severity != "critical" || pending[0].EscalatedAt == nil {
+		t.Fatalf("after escalation unnotified = %+v, want the escalated alert", pending)
+	}
+
+	acked, err := alerts.Acknowledge(db.DB, a.ID, "shift-lead", now.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if acked.Status != alerts.StatusResolved || acked.AcknowledgedBy != "shift-lead" {
+		t.Errorf("acknowledged event alert = %+v, want resolved by shift-lead", acked)
+	}
+	if _, err := alerts.Acknowledge(db.DB, a.ID, "someone", now); !errors.Is(err, alerts.ErrNotOpen) {
+		t.Errorf("second acknowledge: %v, want ErrNotOpen", err)
+	}
+	if _, err := alerts.Acknowledge(db.DB, 999999, "someone", now); !errors.Is(err, alerts.ErrNotFound) {
+		t.Errorf("unknown id: %v, want ErrNotFound", err)
+	}
+
+	hist, err := alerts.List(db.DB, alerts.Filter{Rule: "failures"})
+	if err != nil || len(hist) != 1 {
+		t.Fatalf("history: %d rows, err %v; want 1", len(hist), err)
+	}
+	if open, _ := alerts.List(db.DB, alerts.Filter{Status: "unresolved"}); len(open) != 0 {
+		t.Errorf("unresolved after ack = %d, want 0", len(open))
+	}
+}
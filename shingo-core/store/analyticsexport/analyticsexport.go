@@ -0,0 +1,277 @@
+// Package analyticsexport is the persistence layer for the bulk analytics
+// export: the stable, documented datasets an industrial engineer pulls into
+// pandas or Power BI, and the day-at-a-time cursor each one is read through.
+//
+// The datasets are a CONTRACT, not a view of the tables. A column here is
+// named and typed for the reader, and once shipped it is not renamed, retyped
+// or dropped — a change of that kind is a new SchemaVersion, and new columns
+// go on the end. docs/analytics-export.md is the published form of this file;
+// TestDatasetsMatchDocs keeps the two from drifting.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/analytics_export_service.go wraps these for the www handlers
+// and the scheduled export.
+package analyticsexport
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shingocore/tabular"
+)
+
+// SchemaVersion is the version of the dataset contract below. It is written
+// into every manifest.
+const SchemaVersion = 1
+
+// Dataset is one exportable dataset: its column contract and the table and
+// timestamp its rows are read from and partitioned by.
+type Dataset struct {
+	Name        string           `json:"name"`
+	Doc         string           `json:"doc"`
+	PartitionBy string           `json:"partition_by"` // the column whose plant day a row is filed under
+	Columns     []tabular.Column `json:"columns"`
+
+	table string
+	exprs []string // one SELECT expression per column, in order
+}
+
+type col struct {
+	name     string
+	typ      tabular.Type
+	nullable bool
+	expr     string
+	doc      string
+}
+
+func dataset(name, doc, table, at string, cols ...col) Dataset {
+	d := Dataset{Name: name, Doc: doc, PartitionBy: at, table: table}
+	for _, c := range cols {
+		expr := c.expr
+		if expr == "" {
+			expr = c.name
+		}
+		d.Columns = append(d.Columns, tabular.Column{Name: c.name, Type: c.typ, Nullable: c.nullable, Doc: c.doc})
+		d.exprs = append(d.exprs, expr)
+	}
+	return d
+}
+
+const (
+	str = tabular.String
+	i64 = tabular.Int64
+	f64 = tabular.Float64
+	bln = tabular.Bool
+	ts  = tabular.Timestamp
+)
+
+var datasets = []Dataset{
+	dataset("orders", "One row per order, filed under the day it was created.", "orders", "created_at",
+		col{"order_id", i64, false, "id", "Core order id."},
+		col{"edge_uuid", str, false, "", "The order's id on the Edge that raised it."},
+		col{"station_id", str, false, "", "Edge station that raised the order."},
+		col{"order_type", str, false, "", "retrieve, move, store, complex, ..."},
+		col{"status", str, false, "", "Status at export time."},
+		col{"quantity", i64, false, "", "Requested quantity."},
+		col{"source_node", str, false, "", "Pickup node name; empty until resolved."},
+		col{"delivery_node", str, false, "", "Drop node name."},
+		col{"process_node", str, false, "", "Process node the order serves."},
+		col{"payload_code", str, false, "", "Payload code carried."},
+		col{"bin_id", i64, true, "", "Bin claimed, if any."},
+		col{"parent_order_id", i64, true, "", "Compound parent, for a child order."},
+		col{"vendor_order_id", str, false, "", "Fleet manager order id."},
+		col{"robot_id", str, false, "", "Robot that ran it; empty if never dispatched."},
+		col{"priority", i64, false, "priority::bigint", "Dispatch priority."},
+		col{"queue_reason", str, false, "", "Why it last waited in the queue."},
+		col{"error_detail", str, false, "", "Failure detail for a failed order."},
+		col{"created_at", ts, false, "", "When Core accepted the order."},
+		col{"updated_at", ts, false, "", "Last status change."},
+		col{"completed_at", ts, true, "", "When it reached a terminal status."},
+	),
+	dataset("order_history", "Every status transition of every order, filed under the transition's day.", "order_history", "created_at",
+		col{"history_id", i64, false, "id", "Row id; orders transitions within an order."},
+		col{"order_id", i64, false, "", "Core order id (joins orders.order_id)."},
+		col{"status", str, false, "", "Status entered."},
+		col{"detail", str, false, "", "Free-text detail."},
+		col{"code", str, true, "", "Machine-readable reason code, where one was recorded."},
+		col{"actor", str, true, "", "Who or what made the transition."},
+		col{"created_at", ts, false, "", "When the transition happened."},
+	),
+	dataset("bin_ledger", "Every change to a bin's unit-of-production count.", "bin_uop_ledger", "applied_at",
+		col{"ledger_id", i64, false, "id", "Row id."},
+		col{"bin_id", i64, false, "", "Bin."},
+		col{"before_uop", i64, true, "before_uop::bigint", "Count before; null for a bin's first row."},
+		col{"after_uop", i64, false, "after_uop::bigint", "Count after."},
+		col{"op", str, false, "", "Ledger operation."},
+		col{"source", str, false, "", "Subsystem that wrote the row."},
+		col{"reason", str, false, "COALESCE(metadata->>'reason', '')", "consume_tick, produce_tick, ... where the op records one."},
+		col{"order_id", i64, true, "", "Order the change belongs to, if any."},
+		col{"payload_code", str, false, "", "Payload code in the bin."},
+		col{"actor", str, false, "", "Station or user that made the change."},
+		col{"station", str, false, "", "Station column, where the writer set it."},
+		col{"node_id", i64, true, "", "Node the bin was at, where recorded."},
+		col{"loader_id", i64, true, "", "Loader, for a loader fill."},
+		col{"applied_at", ts, false, "", "When the change was applied."},
+	),
+	dataset("downtime", "PLC downtime events, filed under the day they started.", "downtime_events", "started_at",
+		col{"downtime_id", i64, false, "id", "Row id."},
+		col{"station", str, false, "", "Edge station."},
+		col{"plc_name", str, false, "", "PLC that reported it."},
+		col{"reason", str, false, "", "Reason text from the PLC or operator."},
+		col{"started_at", ts, false, "", "Start."},
+		col{"ended_at", ts, true, "", "End; null while still down at export time."},
+		col{"duration_ms", i64, false, "", "Duration; 0 while open."},
+	),
+	dataset("production", "Production counter ticks per cell.", "cell_part_events", "recorded_at",
+		col{"tick_id", i64, false, "id", "Row id."},
+		col{"cell_id", str, false, "", "Cell (station)."},
+		col{"payload_code", str, false, "", "Payload the cell was making."},
+		col{"process_id", i64, false, "", "Process id at the Edge."},
+		col{"style_id", i64, false, "", "Style id at the Edge."},
+		col{"count_value", i64, false, "", "Raw counter reading."},
+		col{"delta", i64, false, "", "Parts since the previous reading."},
+		col{"anomaly", str, false, "", "Empty, or the anomaly (e.g. jump, reset) that makes delta unreliable."},
+		col{"recorded_at", ts, false, "", "When the Edge read the counter."},
+	),
+	dataset("robot_telemetry", "Robot localization and state samples.", "robot_confidence_samples", "sampled_at",
+		col{"sample_id", i64, false, "id", "Row id."},
+		col{"vehicle_id", str, false, "", "Robot."},
+		col{"sampled_at", ts, false, "", "Sample time."},
+		col{"confidence", f64, false, "", "Localization confidence, 0-1."},
+		col{"x", f64, false, "", "Map x, metres."},
+		col{"y", f64, false, "", "Map y, metres."},
+		col{"angle", f64, false, "", "Heading, radians."},
+		col{"station", str, false, "", "Map station the robot was at."},
+		col{"last_station", str, false, "", "Last map station passed."},
+		col{"order_id", i64, false, "", "Core order being run; 0 when idle."},
+		col{"on_task", bln, false, "", "Running an order."},
+		col{"blocked", bln, false, "", "Blocked by an obstacle."},
+		col{"reloc_status", i64, false, "reloc_status::bigint", "Fleet relocalization status code."},
+		col{"area_ids", str, false, "array_to_string(COALESCE(area_ids, '{}'), ';')", "Map zones the robot was in, ';'-separated."},
+		col{"alarm_codes", str, false, "array_to_string(COALESCE(alarm_codes, '{}'), ';')", "Active alarm codes, ';'-separated."},
+		col{"map_md5", str, true, "", "Map the robot was localized on."},
+	),
+	dataset("missions", "Per-mission fleet telemetry, filed under the day it was recorded.", "mission_telemetry", "created_at",
+		col{"mission_id", i64, false, "id", "Row id."},
+		col{"order_id", i64, false, "", "Core order id (joins orders.order_id)."},
+		col{"vendor_order_id", str, false, "", "Fleet manager order id."},
+		col{"robot_id", str, false, "", "Robot."},
+		col{"station_id", str, false, "", "Edge station."},
+		col{"order_type", str, false, "", "Order type."},
+		col{"source_node", str, false, "", "Pickup node."},
+		col{"delivery_node", str, false, "", "Drop node."},
+		col{"terminal_state", str, false, "", "Fleet terminal state."},
+		col{"vendor_created", ts, true, "", "Fleet's create time."},
+		col{"vendor_completed", ts, true, "", "Fleet's completion time."},
+		col{"core_created", ts, true, "", "Core's create time."},
+		col{"core_completed", ts, true, "", "Core's completion time."},
+		col{"duration_ms", i64, false, "", "Core-measured duration."},
+		col{"vendor_duration_ms", i64, false, "", "Fleet-measured duration."},
+		col{"created_at", ts, false, "", "When the row was recorded."},
+	),
+}
+
+// Datasets returns every dataset, in export order.
+func Datasets() []Dataset {
+	return append([]Dataset(nil), datasets...)
+}
+
+// Lookup returns the named dataset.
+func Lookup(name string) (Dataset, bool) {
+	for _, d := range datasets {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Dataset{}, false
+}
+
+// Stream reads the dataset's rows with PartitionBy in [from, to), oldest
+// first, and hands each to fn as values tabular.Writer takes. Rows come off
+// the cursor one at a time: nothing here holds more than the row in hand, and
+// fn must not hold it either — the slice is reused for the next row.
+//
+// The read is one REPEATABLE READ, read-only transaction with the statement
+// timeout lifted — the session default caps statements at 30s, and a busy
+// day of robot telemetry is one statement (backup/export.go reads the same
+// way for the same reason).
+func Stream(ctx context.Context, db *sql.DB, d Dataset, from, to time.Time, fn func(row []any) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("export %s: %w", d.Name, err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+		return fmt.Errorf("export %s: %w", d.Name, err)
+	}
+
+	q := "SELECT "
+	for i, e := range d.exprs {
+		if i > 0 {
+			q += ", "
+		}
+		q += e
+	}
+	q += fmt.Sprintf(" FROM %s WHERE %s >= $1 AND %s < $2 ORDER BY %s, id", d.table, d.PartitionBy, d.PartitionBy, d.PartitionBy)
+	rows, err := tx.QueryContext(ctx, q, from.UTC(), to.UTC())
+	if err != nil {
+		return fmt.Errorf("export %s: %w", d.Name, err)
+	}
+	defer rows.Close()
+
+	dest := make([]any, len(d.Columns))
+	for i, c := range d.Columns {
+		switch c.Type {
+		case tabular.Int64:
+			dest[i] = new(sql.NullInt64)
+		case tabular.Float64:
+			dest[i] = new(sql.NullFloat64)
+		case tabular.Bool:
+			dest[i] = new(sql.NullBool)
+		case tabular.Timestamp:
+			dest[i] = new(sql.NullTime)
+		default:
+			dest[i] = new(sql.NullString)
+		}
+	}
+	row := make([]any, len(d.Columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("export %s: %w", d.Name, err)
+		}
+		for i, v := range dest {
+			row[i] = nil
+			switch x := v.(type) {
+			case *sql.NullInt64:
+				if x.Valid {
+					row[i] = x.Int64
+				}
+			case *sql.NullFloat64:
+				if x.Valid {
+					row[i] = x.Float64
+				}
+			case *sql.NullBool:
+				if x.Valid {
+					row[i] = x.Bool
+				}
+			case *sql.NullTime:
+				if x.Valid {
+					row[i] = x.Time.UTC()
+				}
+			case *sql.NullString:
+				if x.Valid {
+					row[i] = x.String
+				}
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("export %s: %w", d.Name, err)
+	}
+	return tx.Commit()
+}
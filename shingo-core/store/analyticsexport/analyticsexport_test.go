@@ -0,0 +1,59 @@
+package analyticsexport
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestDatasetsMatchDocs: docs/analytics-export.md is what engineers build
+// their notebooks against, so every dataset and column here is in its table
+// there — same order, same type, same nullability — and nothing is there that
+// is not here.
+func TestDatasetsMatchDocs(t *testing.T) {
+	raw, err := os.ReadFile("../../docs/analytics-export.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	heading := regexp.MustCompile("^### `([a-z_]+)`$")
+	row := regexp.MustCompile("^\\| `([a-z_0-9]+)` \\| ([a-z0-9]+) \\| (yes)? \\|")
+	docs := map[string][]string{}
+	var order []string
+	current := ""
+	for _, line := range strings.Split(string(raw), "\n") {
+		if m := heading.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			order = append(order, current)
+			continue
+		}
+		if m := row.FindStringSubmatch(line); m != nil && current != "" {
+			docs[current] = append(docs[current], m[1]+" "+m[2]+" "+m[3])
+		}
+	}
+
+	var names []string
+	for _, d := range Datasets() {
+		names = append(names, d.Name)
+		var want []string
+		for _, c := range d.Columns {
+			if c.Doc == "" {
+				t.Errorf("%s.%s has no doc", d.Name, c.Name)
+			}
+			null := ""
+			if c.Nullable {
+				null = "yes"
+			}
+			want = append(want, c.Name+" "+string(c.Type)+" "+null)
+		}
+		if got := strings.Join(docs[d.Name], "\n"); got != strings.Join(want, "\n") {
+			t.Errorf("%s: docs table\n%s\nwant\n%s", d.Name, got, strings.Join(want, "\n"))
+		}
+		if len(d.exprs) != len(d.Columns) {
+			t.Errorf("%s: %d expressions for %d columns", d.Name, len(d.exprs), len(d.Columns))
+		}
+	}
+	if strings.Join(order, ",") != strings.Join(names, ",") {
+		t.Errorf("docs datasets %v, want %v", order, names)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build docker
+
+package analyticsexport_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/analyticsexport"
+)
+
+// TestStream_OrdersDay: Stream returns the day's orders oldest first, each
+// value typed as its column says, NULL as nil, and nothing from the days
+// either side.
+func TestStream_OrdersDay(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`
+		INSERT INTO orders (edge_uuid, station_id, payload_code, created_at, updated_at, completed_at)
+		VALUES ('ax-2', 'line-1', 'P-1', $1::timestamptz + INTERVAL '9 hours', $1::timestamptz + INTERVAL '9 hours', $1::timestamptz + INTERVAL '10 hours'),
+		       ('ax-1', 'line-1', 'P-1', $1::timestamptz + INTERVAL '1 hour', $1::timestamptz + INTERVAL '1 hour', NULL),
+		       ('ax-0', 'line-1', 'P-1', $1::timestamptz - INTERVAL '1 minute', $1::timestamptz, NULL),
+		       ('ax-3', 'line-1', 'P-1', $1::timestamptz + INTERVAL '24 hours', $1::timestamptz, NULL)`,
+		day); err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	d, ok := analyticsexport.Lookup("orders")
+	if !ok {
+		t.Fatal("no orders dataset")
+	}
+	idx := map[string]int{}
+	for i, c := range d.Columns {
+		idx[c.Name] = i
+	}
+	var got [][]any
+	err := analyticsexport.Stream(context.Background(), db.DB, d, day, day.AddDate(0, 0, 1), func(row []any) error {
+		got = append(got, append([]any(nil), row...))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("%d rows, want 2", len(got))
+	}
+	if got[0][idx["edge_uuid"]] != "ax-1" || got[1][idx["edge_uuid"]] != "ax-2" {
+		t.Fatalf("order = %v, %v", got[0][idx["edge_uuid"]], got[1][idx["edge_uuid"]])
+	}
+	if got[0][idx["completed_at"]] != nil || got[0][idx["bin_id"]] != nil {
+		t.Fatalf("nulls = %v, %v", got[0][idx["completed_at"]], got[0][idx["bin_id"]])
+	}
+	if at, ok := got[1][idx["completed_at"]].(time.Time); !ok || !at.Equal(day.Add(10*time.Hour)) {
+		t.Fatalf("completed_at = %v", got[1][idx["completed_at"]])
+	}
+	if _, ok := got[0][idx["priority"]].(int64); !ok {
+		t.Fatalf("priority is %T", got[0][idx["priority"]])
+	}
+}
+
+// TestStream_EveryDatasetReads: every dataset's SELECT runs against the live
+// schema — a column renamed under the contract fails here, not at 01:00 on
+// the first night after the upgrade.
+func TestStream_EveryDatasetReads(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	for _, d := range analyticsexport.Datasets() {
+		if err := analyticsexport.Stream(context.Background(), db.DB, d, day, day.AddDate(0, 0, 1), func([]any) error { return nil }); err != nil {
+			t.Errorf("%s: %v", d.Name, err)
+		}
+	}
+}
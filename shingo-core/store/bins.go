@@ -71,6 +71,15 @@ func (db *DB) FindEmptyCompatibleBin(payloadCode, preferZone string, excludeNode
 	return bins.FindEmptyCompatible(db.DB, payloadCode, preferZone, excludeNodeID, fence, asker)
 }
 
+// EmptiesHeldForOthers lists, per owner, the empties a plant-wide empty search
+// skipped because they are inside another owner's affinity grace. binTypeCode
+// set asks about FindEmptyBinOfType's search, blank about
+// FindEmptyCompatibleBin's. See bins.EmptiesHeldForOthers.
+func (db *DB) EmptiesHeldForOthers(payloadCode, binTypeCode string, excludeNodeID int64,
+	fence bins.EmptyFence, asker reservations.DigAsker) ([]bins.AffinityHold, error) {
+	return bins.EmptiesHeldForOthers(db.DB, payloadCode, binTypeCode, excludeNodeID, fence, asker)
+}
+
 // FindEmptyCompatibleBinInGroup is FindEmptyCompatibleBin scoped to descendants
 // of a synthetic group node. See bins.FindEmptyCompatibleInGroup for the full
 // rationale. Used by planRetrieveEmpty's source-group branch.
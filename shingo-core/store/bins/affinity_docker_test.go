@@ -0,0 +1,152 @@
+//go:build docker
+
+package bins_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingocore/internal/testdb"
+	"shingocore/store"
+	"shingocore/store/bins"
+	"shingocore/store/nodes"
+	"shingocore/store/reservations"
+)
+
+// affinity_docker_test.go — TC-39. A line that owns its empties ranks them
+// first, and another line's plant-wide search does not see them until the
+// owner's grace has run out.
+
+type affinityFixture struct {
+	db                     *store.DB
+	askA, askB             int64 // delivery positions under LINE-A and LINE-B
+	neutralBin, ownedBin   int64
+	neutralNode, ownedNode int64
+}
+
+// newAffinityFixture builds LINE-A (empty_affinity=own) with an empty at A-1,
+// a position A-2 to ask from, LINE-B's B-1, and an unowned empty elsewhere.
+// The unowned empty has the LOWER id, so a search that ignored ownership
+// would pick it for LINE-A and every assertion below discriminates.
+func newAffinityFixture(t *testing.T, prefix, code string) affinityFixture {
+	t.Helper()
+	db := testdb.Open(t)
+	sdb := db.DB
+	var btID int64
+	testutil.MustNoErr(t, sdb.QueryRow(`INSERT INTO bin_types (code) VALUES ($1) RETURNING id`, code).Scan(&btID), "bin type")
+
+	node := func(name string, parent *int64) int64 {
+		n := &nodes.Node{Name: prefix + name, Enabled: true, ParentID: parent}
+		testutil.MustNoErr(t, nodes.Create(sdb, n), "create "+name)
+		return n.ID
+	}
+	bin := func(label string, at int64) int64 {
+		var id int64
+		testutil.MustNoErr(t, sdb.QueryRow(
+			`INSERT INTO bins (bin_type_id, label, node_id, status) VALUES ($1,$2,$3,'available') RETURNING id`,
+			btID, prefix+label, at).Scan(&id), "bin "+label)
+		return id
+	}
+	f := affinityFixture{db: db}
+	f.neutralNode = node("-MARKET", nil)
+	f.neutralBin = bin("-NEUTRAL", f.neutralNode)
+
+	lineA, err := nodes.CreateGroup(sdb, prefix+"-LINE-A")
+	testutil.MustNoErr(t, err, "LINE-A")
+	testutil.MustNoErr(t, db.SetNodeProperty(lineA, nodes.PropEmptyAffinity, nodes.EmptyAffinityOwn), "own")
+	f.ownedNode = node("-A-1", &lineA)
+	f.askA = node("-A-2", &lineA)
+	f.ownedBin = bin("-OWNED", f.ownedNode)
+	f.askB = node("-B-1", nil)
+	return f
+}
+
+// emptiedAt stamps when the owned carrier became an empty.
+func (f affinityFixture) emptiedAt(t *testing.T, at *time.Time) {
+	t.Helper()
+	_, err := f.db.Exec(`UPDATE bins SET empty_since=$2 WHERE id=$1`, f.ownedBin, at)
+	testutil.MustNoErr(t, err, "empty_since")
+}
+
+func (f affinityFixture) find(t *testing.T, code string, fence bins.EmptyFence) int64 {
+	t.Helper()
+	got, err := bins.FindEmptyOfType(f.db.DB, code, "", 0, fence, reservations.Anyone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0
+	}
+	testutil.MustNoErr(t, err, "find")
+	return got.ID
+}
+
+func TestAffinity_OwnerRanksItsOwnEmptyFirst(t *testing.T) {
+	t.Parallel()
+	f := newAffinityFixture(t, "AFO", "AFO-45x58")
+	now := time.Now().UTC()
+	f.emptiedAt(t, &now)
+
+	if got := f.find(t, "AFO-45x58", bins.EmptyFence{AskerNode: f.askA, HeldAfter: now.Add(-10 * time.Minute)}); got != f.ownedBin {
+		t.Errorf("LINE-A got %d, want its own empty %d ahead of the market's %d", got, f.ownedBin, f.neutralBin)
+	}
+	if got := f.find(t, "AFO-45x58", bins.EmptyFence{}); got != f.neutralBin {
+		t.Errorf("an ask with no destination got %d, want the least-work pick %d — affinity needs an asker", got, f.neutralBin)
+	}
+}
+
+func TestAffinity_GraceHidesFromOtherLinesThenReleases(t *testing.T) {
+	t.Parallel()
+	f := newAffinityFixture(t, "AFG", "AFG-45x58")
+	now := time.Now().UTC()
+	fence := bins.EmptyFence{AskerNode: f.askB, HeldAfter: now.Add(-10 * time.Minute)}
+
+	// With the market empty gone, the owned carrier is all there is.
+	_, err := f.db.Exec(`DELETE FROM bins WHERE id=$1`, f.neutralBin)
+	testutil.MustNoErr(t, err, "remove market empty")
+
+	fresh := now.Add(-time.Minute)
+	f.emptiedAt(t, &fresh)
+	if got := f.find(t, "AFG-45x58", fence); got != 0 {
+		t.Errorf("LINE-B got %d inside LINE-A's grace, want nothing", got)
+	}
+	held, err := f.db.EmptiesHeldForOthers("", "AFG-45x58", 0, fence, reservations.Anyone)
+	testutil.MustNoErr(t, err, "held for others")
+	if len(held) != 1 || held[0].Owner != "AFG-LINE-A" || held[0].Bins != 1 {
+		t.Errorf("held = %+v, want one empty held for AFG-LINE-A", held)
+	}
+
+	stale := now.Add(-time.Hour)
+	f.emptiedAt(t, &stale)
+	if got := f.find(t, "AFG-45x58", fence); got != f.ownedBin {
+		t.Errorf("LINE-B got %d after the grace, want LINE-A's empty %d", got, f.ownedBin)
+	}
+
+	// Not stamped (emptied before the column existed): outside the grace.
+	f.emptiedAt(t, nil)
+	if got := f.find(t, "AFG-45x58", fence); got != f.ownedBin {
+		t.Errorf("LINE-B got %d with no empty_since, want %d — an unknown age holds nothing", got, f.ownedBin)
+	}
+	if got := f.find(t, "AFG-45x58", bins.EmptyFence{AskerNode: f.askB}); got != f.ownedBin {
+		t.Errorf("LINE-B got %d with no grace, want %d — a zero grace only ranks", got, f.ownedBin)
+	}
+}
+
+func TestAffinity_ForeignEmptiesRankBehindUnowned(t *testing.T) {
+	t.Parallel()
+	f := newAffinityFixture(t, "AFR", "AFR-45x58")
+	// Re-home the market empty under a fresh, higher id, so least work alone
+	// would hand LINE-B the owned carrier.
+	var later int64
+	testutil.MustNoErr(t, f.db.QueryRow(`INSERT INTO bins (bin_type_id, label, node_id, status)
+		SELECT bin_type_id, 'AFR-NEUTRAL-2', node_id, status FROM bins WHERE id=$1 RETURNING id`,
+		f.neutralBin).Scan(&later), "second market empty")
+	_, err := f.db.Exec(`DELETE FROM bins WHERE id=$1`, f.neutralBin)
+	testutil.MustNoErr(t, err, "remove first market empty")
+
+	stale := time.Now().UTC().Add(-time.Hour)
+	f.emptiedAt(t, &stale)
+	if got := f.find(t, "AFR-45x58", bins.EmptyFence{AskerNode: f.askB}); got != later {
+		t.Errorf("LINE-B got %d, want the unowned empty %d before LINE-A's %d", got, later, f.ownedBin)
+	}
+}
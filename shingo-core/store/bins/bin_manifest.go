@@ -98,9 +98,10 @@ func ConfirmManifestTx(tx *sql.Tx, binID int64, producedAt string) (loadedAt tim
 	return loadedAt, uop, payloadCode, err
 }
 
-// ClearManifest empties a bin's manifest (bin is now empty).
+// ClearManifest empties a bin's manifest (bin is now empty). It starts the
+// carrier's empty-affinity clock (empty_since) where it stands.
 func ClearManifest(db *sql.DB, binID int64) error {
-	_, err := db.Exec(`UPDATE bins SET payload_code='', manifest=NULL, uop_remaining=0, manifest_confirmed=false, loaded_at=NULL, updated_at=$2 WHERE id=$1`,
+	_, err := db.Exec(`UPDATE bins SET payload_code='', manifest=NULL, uop_remaining=0, manifest_confirmed=false, loaded_at=NULL, empty_since=$2, updated_at=$2 WHERE id=$1`,
 		binID, clock.Now().UTC())
 	return err
 }
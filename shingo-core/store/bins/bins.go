@@ -163,12 +163,33 @@ type EmptyFence struct {
 	// OriginGroup is the maintained group this ask exists to FILL, by name.
 	// Blank for everything that is not a level keeper's top-off.
 	OriginGroup string
+
+	// AskerNode is the node the empty is being fetched TO, by id — the
+	// identity empty affinity is keyed on. An empty whose owner (the nearest
+	// node above it carrying empty_affinity) sits on this node's parent chain
+	// is the asker's own. Zero means the ask has no destination, and affinity
+	// neither ranks nor hides anything for it.
+	AskerNode int64
+	// HeldAfter is the affinity grace's cutoff: an empty that became an empty
+	// after it, under an owner with empty_affinity=own that the asker is not
+	// part of, is held for that owner and hidden. Zero holds nothing — the
+	// ranking still applies, which is what a grace of 0 configures.
+	HeldAfter time.Time
 }
 
-// Empty reports whether this fence excludes nothing, so a caller can skip
-// rendering the CTE entirely rather than run a walk over an empty root set.
+// Empty reports whether the maintained-group half of this fence excludes
+// nothing, so a caller can skip rendering its CTE entirely rather than run a
+// walk over an empty root set. Affinity is asked separately (Affine, Holds):
+// it is keyed on the destination, not on supports and origin.
 func (f EmptyFence) Empty() bool { return f.ProcessNode == "" && f.OriginGroup == "" }
 
+// Affine reports whether empty affinity applies to this ask at all.
+func (f EmptyFence) Affine() bool { return f.AskerNode != 0 }
+
+// Holds reports whether affinity may HIDE a carrier from this ask, as well as
+// rank it.
+func (f EmptyFence) Holds() bool { return f.Affine() && !f.HeldAfter.IsZero() }
+
 // Args returns the two bind values FencedNodesCTE's placeholders take, in the
 // order the placeholders were named. Beside the renderer, on DigAsker.Args's
 // precedent, so a caller cannot pass them in the wrong order or forget one.
@@ -230,7 +251,13 @@ func (f EmptyFence) Args() []any { return []any{f.ProcessNode, f.OriginGroup} }
 // processParam and originParam are the 1-based positional parameters that will
 // carry EmptyFence.Args().
 func FencedNodesCTE(processParam, originParam int) string {
-	return fmt.Sprintf(`WITH RECURSIVE fenced_roots(id) AS (
+	return "WITH RECURSIVE " + fencedNodesDefs(processParam, originParam) + " "
+}
+
+// fencedNodesDefs is FencedNodesCTE's two CTEs without the WITH RECURSIVE, so
+// the plant-wide finders can list them beside the affinity CTEs.
+func fencedNodesDefs(processParam, originParam int) string {
+	return fmt.Sprintf(`fenced_roots(id) AS (
 		SELECT np.node_id FROM node_properties np
 		 WHERE np.key = 'strict_sourcing' AND np.value = 'on'
 		   AND NOT EXISTS (
@@ -245,7 +272,7 @@ func FencedNodesCTE(processParam, originParam int) string {
 		SELECT id FROM fenced_roots
 		UNION ALL
 		SELECT n2.id FROM nodes n2 JOIN fenced f ON n2.parent_id = f.id
-	) `, processParam, originParam, originParam)
+	)`, processParam, originParam, originParam)
 }
 
 // NotFencedArm keeps a candidate out of the fenced set. Assumes a `fenced(id)`
@@ -259,6 +286,101 @@ func NotFencedArm() string {
 	  AND b.node_id NOT IN (SELECT id FROM fenced)`
 }
 
+// ── EMPTY AFFINITY ──────────────────────────────────────────────────────────
+//
+// TC-39, cross-line poaching. Producer line A clears a carrier at its own
+// lineside; before A asks for its next empty, line B's reorder fires, the
+// plant-wide scan finds A's carrier, and a robot carries it to B. A starves
+// for an empty it was standing next to. The zone preference softens this and
+// its any-zone fallback undoes it.
+//
+// A node — a cell, a lineside position, a maintained group — that carries
+// empty_affinity OWNS the empties standing in its subtree (nodes.PropEmptyAffinity):
+//
+//	prefer  the owner's asks rank its own empties first; everyone else ranks
+//	        them after every unowned empty, and may still take them.
+//	own     all of prefer, and for dispatch.empty_affinity_grace after a
+//	        carrier became an empty there (bins.empty_since) it is HIDDEN from
+//	        everyone else. After the grace it is an ordinary foreign empty.
+//
+// "The owner's asks" are asks whose DESTINATION is inside the owner's subtree
+// (EmptyFence.AskerNode). Keyed on the destination and not on ProcessNode
+// because that is what poaching is about: where the carrier ends up.
+//
+// NESTING RESOLVES TO THE NEAREST OWNER without being asked to. An empty is
+// FOREIGN to an asker when any owner above it is off the asker's parent chain,
+// and OWNED when it has owners and none of them is. If the nearest owner is on
+// the chain, every owner above it is too, so "all owners cover me" is exactly
+// "the nearest owner covers me".
+//
+// FIND-SIDE ONLY, like the fence and the dig arm, and for their reason: a hold
+// keyed on who is asking must never enter a count (EmptyOfTypeInGroupWhere).
+//
+// The key and its values are spelled here literally, as strict_sourcing is in
+// FencedNodesCTE; store/nodes owns the names.
+
+// affinityDefs renders the three CTEs the affinity arms read:
+//
+//	ancestors         the asker's node and its parent chain (nodetree);
+//	affinity          every node under an owner, once per owner above it, with
+//	                  the owner's mode and how many hops down it sits;
+//	foreign_affinity  the rows of affinity whose owner is not on the chain.
+//
+// askerParam is the 1-based parameter carrying EmptyFence.AskerNode.
+func affinityDefs(askerParam int) string {
+	return nodetree.AncestorsCTE(askerParam) + `,
+	affinity(id, owner, mode, hops) AS (
+		SELECT np.node_id, np.node_id, np.value, 0 FROM node_properties np
+		 WHERE np.key = 'empty_affinity' AND np.value IN ('own', 'prefer')
+		UNION ALL
+		SELECT n2.id, af.owner, af.mode, af.hops + 1
+		  FROM nodes n2 JOIN affinity af ON n2.parent_id = af.id
+	),
+	foreign_affinity(id, owner, mode, hops) AS (
+		SELECT id, owner, mode, hops FROM affinity
+		 WHERE owner NOT IN (SELECT id FROM ancestors)
+	)`
+}
+
+// withRecursive joins CTE definitions into one WITH RECURSIVE clause, or
+// nothing when there are none.
+func withRecursive(defs []string) string {
+	if len(defs) == 0 {
+		return ""
+	}
+	return "WITH RECURSIVE " + strings.Join(defs, ",\n\t") + " "
+}
+
+// heldForAnotherSQL is the one spelling of "this empty is inside another
+// owner's grace": it became an empty after the cutoff, under an own-mode owner
+// the asker is not part of. A NULL empty_since is outside every grace — that
+// is every empty that predates v100, and the reading that leaves them exactly
+// as sourceable as they were.
+func heldForAnotherSQL(heldAfterParam int) string {
+	return fmt.Sprintf(`(COALESCE(b.empty_since > $%d, false)
+	       AND b.node_id IN (SELECT id FROM foreign_affinity WHERE mode = 'own'))`, heldAfterParam)
+}
+
+// NotHeldForAnotherArm hides empties inside another owner's grace. Assumes the
+// affinity CTEs are in scope.
+func NotHeldForAnotherArm(heldAfterParam int) string {
+	return `
+	  AND NOT ` + heldForAnotherSQL(heldAfterParam)
+}
+
+// HeldForAnotherArm is its inverse — only the held empties — which is what
+// EmptiesHeldForOthers counts to explain a wait.
+func HeldForAnotherArm(heldAfterParam int) string {
+	return `
+	  AND ` + heldForAnotherSQL(heldAfterParam)
+}
+
+// AffinityRankSQL ranks an empty for the asker: its own first (0), then
+// unowned ones (1), then other owners' (2). Assumes the affinity CTEs.
+const AffinityRankSQL = `CASE WHEN b.node_id IN (SELECT id FROM foreign_affinity) THEN 2
+	              WHEN b.node_id IN (SELECT id FROM affinity) THEN 0
+	              ELSE 1 END`
+
 // ── THE EMPTY-CARRIER FRAGMENT FAMILY ───────────────────────────────────────
 //
 // Four empty finders carried four hand-written copies of the same predicate,
@@ -495,9 +617,25 @@ const PayloadBinTypeAdvisoryClause = `
 // A var rather than a const now, since it is composed at init. Every caller
 // interpolates it with fmt.Sprintf, so nothing needed a constant.
 var AccessibleEmptyOrder = `
-	ORDER BY (n.parent_id IS NULL OR n.depth IS NULL OR ` + helpers.ReachableSQL("n") + `) DESC,
+	ORDER BY ` + accessibleEmptyKeys + `
+	LIMIT 1`
+
+// accessibleEmptyKeys is AccessibleEmptyOrder's keys, shared with
+// AffinityEmptyOrder so the least-work ranking is one spelling under both.
+var accessibleEmptyKeys = `(n.parent_id IS NULL OR n.depth IS NULL OR ` + helpers.ReachableSQL("n") + `) DESC,
 	         COALESCE(n.depth, 0) ASC,
-	         b.id ASC
+	         b.id ASC`
+
+// AffinityEmptyOrder is AccessibleEmptyOrder with ownership ranked FIRST, for a
+// plant-wide ask that has a destination. Assumes the affinity CTEs.
+//
+// Ownership outranks accessibility on purpose. The owner's own empty one row
+// deep costs a short reshuffle; taking a neighbour's accessible one costs the
+// neighbour its next empty, which is the whole of TC-39. Among equals the
+// least-work ladder decides exactly as before.
+var AffinityEmptyOrder = `
+	ORDER BY ` + AffinityRankSQL + ` ASC,
+	         ` + accessibleEmptyKeys + `
 	LIMIT 1`
 
 // ScanBin reads a single bin row (including joined bin_type code + node name).
@@ -788,7 +926,7 @@ func MoveAndClearStaging(db *sql.DB, binID, toNodeID int64, clearStaging bool) e
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`UPDATE bins SET node_id=$1, updated_at=$3 WHERE id=$2 AND (node_id IS NULL OR node_id != $1)`, toNodeID, binID, clock.Now().UTC())
+	res, err := tx.Exec(`UPDATE bins SET node_id=$1, `+helpers.EmptyArrivalSQL(3)+`, updated_at=$3 WHERE id=$2 AND (node_id IS NULL OR node_id != $1)`, toNodeID, binID, clock.Now().UTC())
 	if err != nil {
 		return err
 	}
@@ -1054,19 +1192,15 @@ func FindEmptyOfType(db *sql.DB, binTypeCode, preferZone string, excludeNodeID i
 	// type name.
 	build := func(withZone bool) (string, []any) {
 		a := &emptyQueryArgs{}
-		where := EmptyCarrierWhere + OfTypeArm(a.add(binTypeCode))
+		zone := ""
 		if withZone {
-			where += InZoneArm(a.add(preferZone))
+			zone = preferZone
 		}
-		where += ExcludeNodeArm(a.add(excludeNodeID))
-		cte := ""
-		if !fence.Empty() {
-			cte = FencedNodesCTE(a.add(fence.ProcessNode), a.add(fence.OriginGroup))
-			where += NotFencedArm()
+		cte, where := plantWideEmptyWhere(a, binTypeCode, zone, excludeNodeID, fence, asker)
+		if fence.Holds() {
+			where += NotHeldForAnotherArm(a.add(fence.HeldAfter))
 		}
-		where += NotForeignDugArm(a.add(string(reservations.ModeDig)),
-			a.add(asker.OrderID), a.add(asker.LaneOwner))
-		return cte + BinJoinQuery + where + AccessibleEmptyOrder, a.vals
+		return cte + BinJoinQuery + where + plantWideEmptyOrder(fence), a.vals
 	}
 
 	if preferZone != "" {
@@ -1109,21 +1243,16 @@ func FindEmptyCompatible(db *sql.DB, payloadCode, preferZone string, excludeNode
 		a := &emptyQueryArgs{}
 		// $1 is the payload for PayloadBinTypeAdvisoryClause, which names it
 		// explicitly — so it is added first whether or not the zone arm follows.
-		payloadP := a.add(payloadCode)
-		where := EmptyCarrierWhere
+		a.add(payloadCode)
+		zone := ""
 		if withZone {
-			where += InZoneArm(a.add(preferZone))
+			zone = preferZone
 		}
-		where += ExcludeNodeArm(a.add(excludeNodeID))
-		cte := ""
-		if !fence.Empty() {
-			cte = FencedNodesCTE(a.add(fence.ProcessNode), a.add(fence.OriginGroup))
-			where += NotFencedArm()
+		cte, where := plantWideEmptyWhere(a, "", zone, excludeNodeID, fence, asker)
+		if fence.Holds() {
+			where += NotHeldForAnotherArm(a.add(fence.HeldAfter))
 		}
-		where += NotForeignDugArm(a.add(string(reservations.ModeDig)),
-			a.add(asker.OrderID), a.add(asker.LaneOwner))
-		_ = payloadP
-		return cte + BinJoinQuery + where + PayloadBinTypeAdvisoryClause + AccessibleEmptyOrder, a.vals
+		return cte + BinJoinQuery + where + PayloadBinTypeAdvisoryClause + plantWideEmptyOrder(fence), a.vals
 	}
 
 	if preferZone != "" {
@@ -1140,6 +1269,102 @@ func FindEmptyCompatible(db *sql.DB, payloadCode, preferZone string, excludeNode
 	return ScanBin(db.QueryRow(q, args...))
 }
 
+// plantWideEmptyWhere assembles what the two plant-wide finders share: the
+// CTEs their arms need and the WHERE up to the dig arm. binTypeCode narrows to
+// a type when set; zone narrows to a zone when set. The caller adds what is
+// its own — the payload rules, the affinity hold — and the SELECT and ORDER BY.
+//
+// One assembly for both finders and for EmptiesHeldForOthers, so the count
+// that explains a wait cannot see a different population from the search it
+// is explaining.
+func plantWideEmptyWhere(a *emptyQueryArgs, binTypeCode, zone string, excludeNodeID int64,
+	fence EmptyFence, asker reservations.DigAsker) (cte, where string) {
+
+	where = EmptyCarrierWhere
+	if binTypeCode != "" {
+		where += OfTypeArm(a.add(binTypeCode))
+	}
+	if zone != "" {
+		where += InZoneArm(a.add(zone))
+	}
+	where += ExcludeNodeArm(a.add(excludeNodeID))
+	var defs []string
+	if !fence.Empty() {
+		defs = append(defs, fencedNodesDefs(a.add(fence.ProcessNode), a.add(fence.OriginGroup)))
+		where += NotFencedArm()
+	}
+	if fence.Affine() {
+		defs = append(defs, affinityDefs(a.add(fence.AskerNode)))
+	}
+	where += NotForeignDugArm(a.add(string(reservations.ModeDig)),
+		a.add(asker.OrderID), a.add(asker.LaneOwner))
+	return withRecursive(defs), where
+}
+
+// plantWideEmptyOrder picks the ranking: ownership first when the ask has a
+// destination, the least-work ladder alone when it does not.
+func plantWideEmptyOrder(fence EmptyFence) string {
+	if fence.Affine() {
+		return AffinityEmptyOrder
+	}
+	return AccessibleEmptyOrder
+}
+
+// AffinityHold is one owner's share of the empties a plant-wide search was not
+// allowed to see: how many, and when the earliest of them became an empty (its
+// grace runs from there).
+type AffinityHold struct {
+	Owner string
+	Bins  int
+	Since time.Time
+}
+
+// EmptiesHeldForOthers lists, per owner, the empties the same plant-wide
+// search would have found but for another owner's grace — the any-zone pass
+// of FindEmptyOfType (binTypeCode set) or FindEmptyCompatible (blank). Most
+// held first. Nil when the fence holds nothing.
+//
+// It answers "why was nothing found", for the wait's sentence; it decides
+// nothing. A carrier under two foreign owners counts once, for the nearer.
+func EmptiesHeldForOthers(db *sql.DB, payloadCode, binTypeCode string, excludeNodeID int64,
+	fence EmptyFence, asker reservations.DigAsker) ([]AffinityHold, error) {
+
+	if !fence.Holds() {
+		return nil, nil
+	}
+	a := &emptyQueryArgs{}
+	if binTypeCode == "" {
+		a.add(payloadCode) // $1, for PayloadBinTypeAdvisoryClause
+	}
+	cte, where := plantWideEmptyWhere(a, binTypeCode, "", excludeNodeID, fence, asker)
+	if binTypeCode == "" {
+		where += PayloadBinTypeAdvisoryClause
+	}
+	where += HeldForAnotherArm(a.add(fence.HeldAfter))
+	rows, err := db.Query(cte+`SELECT owner, COUNT(*), MIN(since) FROM (
+		SELECT DISTINCT ON (b.id) o.name AS owner, b.empty_since AS since
+		`+BinFromClause+`
+		JOIN foreign_affinity fa ON fa.id = b.node_id AND fa.mode = 'own'
+		JOIN nodes o ON o.id = fa.owner`+where+`
+		ORDER BY b.id, fa.hops
+	) held
+	GROUP BY owner
+	ORDER BY COUNT(*) DESC, owner`, a.vals...)
+	if err != nil {
+		return nil, fmt.Errorf("empties held for others: %w", err)
+	}
+	defer rows.Close()
+	var out []AffinityHold
+	for rows.Next() {
+		var h AffinityHold
+		if err := rows.Scan(&h.Owner, &h.Bins, &h.Since); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
 // UpdateStatus sets the status on a bin.
 func UpdateStatus(db *sql.DB, binID int64, status domain.BinStatus) error {
 	_, err := db.Exec(`UPDATE bins SET status=$1, updated_at=$3 WHERE id=$2`, status, binID, clock.Now().UTC())
@@ -1253,7 +1478,7 @@ func ClearAnomaly(db *sql.DB, binID int64) error {
 // empty.
 func RecoverToNode(db *sql.DB, binID, toNodeID int64) error {
 	_, err := db.Exec(
-		`UPDATE bins SET node_id=$1, anomaly_at=NULL, updated_at=$3 WHERE id=$2`,
+		`UPDATE bins SET node_id=$1, anomaly_at=NULL, `+helpers.EmptyArrivalSQL(3)+`, updated_at=$3 WHERE id=$2`,
 		toNodeID, binID, clock.Now().UTC())
 	return err
 }
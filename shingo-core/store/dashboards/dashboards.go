@@ -1,6 +1,7 @@
 // Package dashboards is the persistence layer for the floor display
 // platform. A dashboard row is a saved, named, station-scoped view of
-// Core's live data (the AMR task board today; other kinds later). It is
+// Core's live data (the AMR task board, the heartbeat, lineside, lane,
+// production, alert and fleet boards — service/dashboard_kinds.go). It is
 // pure presentation config — it owns no operational state, so this package
 // is plain CRUD with no cross-aggregate orchestration.
 //
@@ -0,0 +1,76 @@
+// Package forecast is the persistence layer for demand forecasting: the two
+// hourly reads a payload's demand is learned from — what the lines drew of it
+// (bin_uop_ledger) and what they were making while they did (cell_part_events).
+//
+// Nothing here decides what an hour means. Which hours count, which style an
+// hour belongs to and what threshold the demand calls for are
+// domain/forecast.go's, tested without Postgres.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/demand_forecast_service.go wraps these for the www handlers.
+package forecast
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shingocore/domain"
+)
+
+// Consumption returns payloadCode's consume-tick draw per station per clock
+// hour in [since, until). op is the applied-delta op tag, supplied by the
+// caller from store/audit so this package does not import another store
+// aggregate. The station is the row's actor — the applied-delta INSERT
+// writes neither the station column nor node_id (store/audit/cycle_time.go).
+// A row that raised the bin is not a draw and is not summed.
+func Consumption(db *sql.DB, op, payloadCode string, since, until time.Time) ([]domain.DemandConsumption, error) {
+	rows, err := db.Query(`
+		SELECT actor, date_trunc('hour', applied_at), SUM(COALESCE(before_uop, 0) - after_uop)::bigint
+		  FROM bin_uop_ledger
+		 WHERE op = $1 AND metadata->>'reason' = $2 AND payload_code = $3
+		   AND applied_at >= $4 AND applied_at < $5
+		   AND actor <> '' AND COALESCE(before_uop, 0) > after_uop
+		 GROUP BY 1, 2`,
+		op, domain.CycleDirectionConsume, payloadCode, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("forecast consumption: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.DemandConsumption
+	for rows.Next() {
+		var c domain.DemandConsumption
+		if err := rows.Scan(&c.Station, &c.Hour, &c.UOP); err != nil {
+			return nil, fmt.Errorf("forecast consumption: %w", err)
+		}
+		c.Hour = c.Hour.UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// StyleHours returns the parts made per cell, style and clock hour in
+// [since, until), with the filter OEE counts production by (store/oee): a
+// positive delta that is not a counter jump. The cell is the station.
+func StyleHours(db *sql.DB, since, until time.Time) ([]domain.StyleHour, error) {
+	rows, err := db.Query(`
+		SELECT cell_id, date_trunc('hour', recorded_at), style_id, SUM(delta)::bigint
+		  FROM cell_part_events
+		 WHERE recorded_at >= $1 AND recorded_at < $2
+		   AND delta > 0 AND anomaly <> 'jump'
+		 GROUP BY 1, 2, 3`, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("forecast style hours: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.StyleHour
+	for rows.Next() {
+		var s domain.StyleHour
+		if err := rows.Scan(&s.Station, &s.Hour, &s.StyleID, &s.Parts); err != nil {
+			return nil, fmt.Errorf("forecast style hours: %w", err)
+		}
+		s.Hour = s.Hour.UTC()
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,78 @@
+//go:build docker
+
+package forecast_test
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/audit"
+	"shingocore/store/forecast"
+	"shingocore/store/heartbeat"
+)
+
+// TestConsumption_DrawsPerStationHour: consume ticks on the payload sum per
+// actor and clock hour; a produce tick, another payload, a row that raised
+// the bin and a row outside the window do not.
+func TestConsumption_DrawsPerStationHour(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`
+		INSERT INTO bin_uop_ledger (bin_id, before_uop, after_uop, op, source, payload_code, actor, metadata, applied_at)
+		VALUES (4501, 40, 36, $1, 'test', 'FC-1', 'ALN-1', '{"reason":"consume_tick"}', $2::timestamptz + INTERVAL '5 minutes'),
+		       (4501, 36, 30, $1, 'test', 'FC-1', 'ALN-1', '{"reason":"consume_tick"}', $2::timestamptz + INTERVAL '50 minutes'),
+		       (4502, 20, 18, $1, 'test', 'FC-1', 'ALN-2', '{"reason":"consume_tick"}', $2::timestamptz + INTERVAL '70 minutes'),
+		       (4501, 30, 31, $1, 'test', 'FC-1', 'ALN-1', '{"reason":"consume_tick"}', $2::timestamptz + INTERVAL '10 minutes'),
+		       (4503, 10, 11, $1, 'test', 'FC-1', 'PRS-1', '{"reason":"produce_tick"}', $2::timestamptz + INTERVAL '10 minutes'),
+		       (4504, 10, 5, $1, 'test', 'FC-2', 'ALN-1', '{"reason":"consume_tick"}', $2::timestamptz + INTERVAL '10 minutes'),
+		       (4501, 50, 40, $1, 'test', 'FC-1', 'ALN-1', '{"reason":"consume_tick"}', $2::timestamptz - INTERVAL '1 minute')`,
+		audit.OpBinUOPDelta, at); err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	got, err := forecast.Consumption(db.DB, audit.OpBinUOPDelta, "FC-1", at, at.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("consumption: %v", err)
+	}
+	want := map[string]int64{"ALN-1@09": 10, "ALN-2@10": 2}
+	if len(got) != len(want) {
+		t.Fatalf("consumption = %+v, want %v", got, want)
+	}
+	for _, c := range got {
+		if k := c.Station + "@" + c.Hour.Format("15"); want[k] != c.UOP {
+			t.Errorf("%s = %d, want %d", k, c.UOP, want[k])
+		}
+	}
+}
+
+// TestStyleHours_CountsLikeOEE: parts sum per cell, style and hour, and a
+// counter jump is not production.
+func TestStyleHours_CountsLikeOEE(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	if err := heartbeat.EnsurePartitions(db.DB, at); err != nil {
+		t.Fatalf("partitions: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO cell_part_events (cell_id, recorded_at, edge_snapshot_id, count_value, delta, anomaly, style_id)
+		VALUES ('FC-ALN', $1::timestamptz + INTERVAL '1 minute', 1, 3, 3, '', 4),
+		       ('FC-ALN', $1::timestamptz + INTERVAL '9 minutes', 2, 5, 2, '', 4),
+		       ('FC-ALN', $1::timestamptz + INTERVAL '20 minutes', 3, 900, 895, 'jump', 4)`, at); err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	got, err := forecast.StyleHours(db.DB, at, at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("style hours: %v", err)
+	}
+	var parts int64
+	for _, s := range got {
+		if s.Station == "FC-ALN" && s.StyleID == 4 && s.Hour.Equal(at) {
+			parts += s.Parts
+		}
+	}
+	if parts != 5 {
+		t.Errorf("FC-ALN style 4 at 09:00 = %d parts, want 5 (the jump left out): %+v", parts, got)
+	}
+}
@@ -0,0 +1,21 @@
+package helpers
+
+import "fmt"
+
+// EmptyArrivalSQL is the SET fragment a bin move writes so the carrier's
+// empty-affinity clock (bins.empty_since, v100) restarts where it lands: now
+// if it arrives empty, NULL if it arrives carrying something.
+//
+// HERE BECAUSE TWO AGGREGATES MOVE BINS — store/bins (the manual move, the
+// transit recovery) and PlaceBinTx below — and an empty set down by one and
+// not the other would be held for its new owner or not depending on which
+// path carried it.
+//
+// It reads payload_code as the row stands BEFORE the update, which is the
+// carrier that arrived. atParam is the positional parameter carrying the
+// injected clock's now: the grace this clock is compared against is computed
+// on that clock, and a NOW() here would put the stamp and its cutoff in two
+// time domains (see PlaceBinTx's staging note).
+func EmptyArrivalSQL(atParam int) string {
+	return fmt.Sprintf(`empty_since = CASE WHEN COALESCE(payload_code, '') = '' THEN $%d::timestamptz END`, atParam)
+}
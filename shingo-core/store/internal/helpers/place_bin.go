@@ -123,7 +123,8 @@ func PlaceBinTx(tx *sql.Tx, p BinPlacement) ([]int64, error) {
 
 	// 2. The fact.
 	if _, err := tx.Exec(
-		`UPDATE bins SET node_id=$1, updated_at=NOW() WHERE id=$2`, p.ToNodeID, p.BinID); err != nil {
+		`UPDATE bins SET node_id=$1, `+EmptyArrivalSQL(3)+`, updated_at=NOW() WHERE id=$2`,
+		p.ToNodeID, p.BinID, clock.Now().UTC()); err != nil {
 		return nil, fmt.Errorf("move bin %d: %w", p.BinID, err)
 	}
 
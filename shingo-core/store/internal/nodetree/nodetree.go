@@ -87,7 +87,15 @@ func SubtreeOf(nodeParam int) string {
 // parent_id). An unused derived column changes no row and no result; a second
 // spelling of the walk to avoid it would.
 func AncestorsOf(nodeParam int) string {
-	return fmt.Sprintf(`WITH RECURSIVE ancestors AS (
+	return "WITH RECURSIVE " + AncestorsCTE(nodeParam)
+}
+
+// AncestorsCTE is AncestorsOf's body without the WITH RECURSIVE — the one CTE,
+// `ancestors AS (...)`, for a query that composes it beside CTEs of its own.
+// The header note anticipated this caller: the body is extracted here rather
+// than string-edited out of AncestorsOf's result, so the two stay one walk.
+func AncestorsCTE(nodeParam int) string {
+	return fmt.Sprintf(`ancestors AS (
 			SELECT id, parent_id, 0 AS depth FROM nodes WHERE id = $%d
 			UNION ALL
 			SELECT n.id, n.parent_id, a.depth + 1 FROM nodes n
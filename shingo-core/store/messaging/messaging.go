@@ -92,6 +92,14 @@ func ListDeadLetterOutbox(db *sql.DB, limit int) ([]*OutboxMessage, error) {
 	return scanOutbox(rows)
 }
 
+// CountDeadLetterOutbox counts unsent rows that exhausted retries — the same
+// predicate as ListDeadLetterOutbox, without reading the payloads.
+func CountDeadLetterOutbox(db *sql.DB) (int, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM outbox WHERE sent_at IS NULL AND retries >= $1`, MaxOutboxRetries).Scan(&n)
+	return n, err
+}
+
 func scanOutbox(rows *sql.Rows) ([]*OutboxMessage, error) {
 	var msgs []*OutboxMessage
 	for rows.Next() {
@@ -4074,6 +4074,46 @@ func migrationList() []migration {
 			func(q schema.Querier) bool {
 				return schema.ColumnExists(q, "bins", "anomaly_note")
 			}},
+		{97, "quality_holds — holds, the bins they hold, and their audit trail",
+			v97QualityHolds,
+			func(q schema.Querier) bool {
+				return schema.TableExists(q, "quality_holds") &&
+					schema.TableExists(q, "quality_hold_bins") &&
+					schema.TableExists(q, "quality_hold_events")
+			}},
+		{98, "alerts — raised alerts, their acknowledgement and escalation",
+			v98Alerts,
+			func(q schema.Querier) bool {
+				return schema.TableExists(q, "alerts")
+			}},
+		{99, "shift_reports — end-of-shift operations reports",
+			v99ShiftReports,
+			func(q schema.Querier) bool {
+				return schema.TableExists(q, "shift_reports")
+			}},
+		{100, "bins.empty_since — when a carrier became an empty where it stands",
+			v100BinEmptySince,
+			func(q schema.Querier) bool {
+				return schema.ColumnExists(q, "bins", "empty_since")
+			}},
+		{101, "style_cycle_times, cell_scrap_reports — OEE performance and quality inputs",
+			v101OEEInputs,
+			func(q schema.Querier) bool {
+				return schema.TableExists(q, "style_cycle_times") &&
+					schema.TableExists(q, "cell_scrap_reports")
+			}},
+		{102, "idx_audit_bin_time — bin audit rows by time, for replay",
+			v102AuditBinTimeIndex,
+			func(q schema.Querier) bool {
+				return schema.IndexExists(q, "idx_audit_bin_time")
+			}},
+		{103, "robot_health_samples, robot_fault_events, robot_maintenance_items — predictive robot maintenance",
+			v103RobotMaintenance,
+			func(q schema.Querier) bool {
+				return schema.TableExists(q, "robot_health_samples") &&
+					schema.TableExists(q, "robot_fault_events") &&
+					schema.TableExists(q, "robot_maintenance_items")
+			}},
 	}
 }
 
@@ -4097,6 +4137,331 @@ func v96BinAnomalyNote(tx *sql.Tx) error {
 	return nil
 }
 
+// v97QualityHolds installs the quality-hold workflow's three tables.
+//
+// Before this a hold was bins.status = 'quality_hold' and a free-text note, set
+// and cleared one bin at a time through the bin actions. That answered "is this
+// bin held?" and nothing else: not why, not which other bins were held for the
+// same reason, not who let it go. A quality problem is almost never one bin —
+// it is a lot, a supplier's payload, or a shift's worth of loading — so the
+// hold is now a row of its own and bins are held BY it.
+//
+//   - quality_holds — the rule. scope_kind says which of bin_id / lot_code /
+//     payload_code / loaded_from..loaded_to is the match; the others are empty.
+//     status walks active → pending_disposition → closed, and the disposition
+//     is recorded on the row that closed it.
+//   - quality_hold_bins — every bin a hold has held, with the status it had
+//     before (prior_status), so a release puts back what was there rather than
+//     guessing 'available'. A bin held by two holds has two rows; it is only
+//     let go when the last of them resolves.
+//   - quality_hold_events — the audit trail, one row per action, append-only.
+//     Separate from audit_log because audit_log is keyed by entity id and a
+//     hold's story (placed, propagated to 14 bins, disposition requested,
+//     rejected, requested again, approved) is read as one sequence.
+//
+// bins.status stays the thing dispatch filters on. The hold tables explain a
+// status; they do not replace it.
+//
+// ROLLBACK: a pre-v97 binary never reads or writes these tables. Bins it finds
+// in quality_hold are released the old way, by hand, and the hold rows go stale
+// but harmless.
+func v97QualityHolds(tx *sql.Tx) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS quality_holds (
+			id            BIGSERIAL PRIMARY KEY,
+			reason_code   TEXT NOT NULL,
+			note          TEXT NOT NULL DEFAULT '',
+			scope_kind    TEXT NOT NULL,          -- bin | lot | payload | loaded_window
+			bin_id        BIGINT,
+			lot_code      TEXT NOT NULL DEFAULT '',
+			payload_code  TEXT NOT NULL DEFAULT '',
+			loaded_from   TIMESTAMPTZ,
+			loaded_to     TIMESTAMPTZ,
+			status        TEXT NOT NULL DEFAULT 'active', -- active | pending_disposition | closed
+			disposition   TEXT NOT NULL DEFAULT '',       -- release | rework | scrap, once requested
+			requested_by  TEXT NOT NULL DEFAULT '',
+			requested_at  TIMESTAMPTZ,
+			approved_by   TEXT NOT NULL DEFAULT '',
+			created_by    TEXT NOT NULL DEFAULT '',
+			created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			closed_at     TIMESTAMPTZ
+		)`,
+		// The sweep and the source finder only ever ask about holds that are
+		// still holding, which on a healthy plant is a handful of rows.
+		`CREATE INDEX IF NOT EXISTS idx_quality_holds_open ON quality_holds (status) WHERE status <> 'closed'`,
+		`CREATE TABLE IF NOT EXISTS quality_hold_bins (
+			hold_id      BIGINT NOT NULL REFERENCES quality_holds(id) ON DELETE CASCADE,
+			bin_id       BIGINT NOT NULL,
+			prior_status TEXT NOT NULL,
+			held_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			resolved_at  TIMESTAMPTZ,
+			outcome      TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (hold_id, bin_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_quality_hold_bins_open ON quality_hold_bins (bin_id) WHERE resolved_at IS NULL`,
+		`CREATE TABLE IF NOT EXISTS quality_hold_events (
+			id      BIGSERIAL PRIMARY KEY,
+			hold_id BIGINT NOT NULL REFERENCES quality_holds(id) ON DELETE CASCADE,
+			action  TEXT NOT NULL,
+			actor   TEXT NOT NULL DEFAULT '',
+			detail  TEXT NOT NULL DEFAULT '',
+			at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_quality_hold_events_hold ON quality_hold_events (hold_id, at)`,
+	}
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("v97 quality_holds: %w", err)
+		}
+	}
+	return nil
+}
+
+// v98Alerts installs the alert history.
+//
+// One row per alert, not per evaluation: a rule raising on the same subject
+// (order 4411, edge plant-a.line-1, robot AMR-03) while an earlier alert for it
+// is still unresolved bumps occurrences and last_seen on that row instead. The
+// partial unique index is what makes that an upsert — and what lets the same
+// subject raise a fresh alert once the old one has resolved, so a review of
+// last Tuesday reads as the three separate incidents it was.
+//
+// status walks open → acknowledged → resolved; a condition clearing resolves an
+// alert whether or not anyone acknowledged it, and acknowledged_by stays empty
+// on those, which is itself the finding ("nobody saw it"). notified_at is set
+// once the notification is handed to the channels, so one held back by quiet
+// hours is found and sent when they end; escalation clears it to send again.
+//
+// ROLLBACK: a pre-v98 binary never reads or writes the table.
+func v98Alerts(tx *sql.Tx) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS alerts (
+			id              BIGSERIAL PRIMARY KEY,
+			rule            TEXT NOT NULL,
+			kind            TEXT NOT NULL,
+			dedup_key       TEXT NOT NULL,
+			severity        TEXT NOT NULL,        -- info | warning | critical
+			subject         TEXT NOT NULL DEFAULT '',
+			detail          TEXT NOT NULL DEFAULT '',
+			status          TEXT NOT NULL DEFAULT 'open', -- open | acknowledged | resolved
+			occurrences     INTEGER NOT NULL DEFAULT 1,
+			first_seen      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			last_seen       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			notified_at     TIMESTAMPTZ,
+			escalated_at    TIMESTAMPTZ,
+			acknowledged_by TEXT NOT NULL DEFAULT '',
+			acknowledged_at TIMESTAMPTZ,
+			resolved_at     TIMESTAMPTZ
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_unresolved ON alerts (rule, dedup_key) WHERE resolved_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_alerts_first_seen ON alerts (first_seen DESC)`,
+	}
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("v98 alerts: %w", err)
+		}
+	}
+	return nil
+}
+
+// v99ShiftReports installs the end-of-shift report archive.
+//
+// One row per shift occurrence — (shift, window_start) is unique, which is
+// what keeps a restart inside the report delay, or two passes racing, from
+// filing the same night twice. The computed sections are one JSONB document
+// (domain.ShiftReportBody) rather than tables of their own: a report is read
+// whole and never queried into, and storing what was written is the point —
+// mission_telemetry and downtime_events are pruned on their own schedules,
+// and last month's report has to keep saying what it said that morning.
+//
+// shift_day is the plant-local date the shift started on, kept beside the
+// UTC window because it is what a supervisor browses by. notified_at is set
+// once the report is handed to the channels.
+//
+// ROLLBACK: a pre-v99 binary never reads or writes the table.
+func v99ShiftReports(tx *sql.Tx) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS shift_reports (
+			id           BIGSERIAL PRIMARY KEY,
+			shift        TEXT NOT NULL,
+			shift_day    DATE NOT NULL,
+			timezone     TEXT NOT NULL DEFAULT '',
+			window_start TIMESTAMPTZ NOT NULL,
+			window_end   TIMESTAMPTZ NOT NULL,
+			body         JSONB NOT NULL DEFAULT '{}'::jsonb,
+			generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			notified_at  TIMESTAMPTZ,
+			UNIQUE (shift, window_start)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_shift_reports_window_end ON shift_reports (window_end DESC)`,
+	}
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("v99 shift_reports: %w", err)
+		}
+	}
+	return nil
+}
+
+// v100BinEmptySince adds the clock empty-carrier affinity runs on.
+//
+// An empty standing at a node whose subtree carries empty_affinity=own is held
+// for that owner for dispatch.empty_affinity_grace after it BECAME an empty
+// there — cleared in place, or set down already empty. updated_at cannot answer
+// that: a claim that is cancelled, a count, a lock all move it, and a clock
+// that restarts on every touch would hold an empty forever.
+//
+// NOT BACKFILLED. NULL reads as "no longer in its grace", so every empty on the
+// floor at upgrade stays exactly as sourceable as it was; affinity starts with
+// the next clear.
+//
+// ROLLBACK: a pre-v100 binary never reads or writes it; the column sits unused.
+func v100BinEmptySince(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE bins ADD COLUMN IF NOT EXISTS empty_since TIMESTAMPTZ`); err != nil {
+		return fmt.Errorf("v100 bins.empty_since: %w", err)
+	}
+	return nil
+}
+
+// v101OEEInputs installs the two inputs OEE needs that nothing recorded yet.
+//
+// style_cycle_times is the engineered cycle per (cell, style) — what
+// performance is rated against. It is not cell_targets: that is the heartbeat
+// target per payload, set by whoever owns the alarm, and a style running two
+// payloads at different rates has one engineered cycle, not two. style_id is
+// the edge's style ID, the same one cell_part_events carries; it is only
+// unique per edge, which is why the cell is part of the key.
+//
+// cell_scrap_reports is scrap as reported — append-only, one row per report,
+// so a correction is a negative row and the history says who changed what.
+// A cell with no rows in a window has NOT reported scrap; quality is not
+// computed for it rather than read as perfect.
+//
+// ROLLBACK: a pre-v101 binary never reads or writes either table.
+func v101OEEInputs(tx *sql.Tx) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS style_cycle_times (
+			cell_id    TEXT NOT NULL,
+			style_id   BIGINT NOT NULL,
+			cycle_ms   BIGINT NOT NULL CHECK (cycle_ms > 0),
+			updated_by TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (cell_id, style_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS cell_scrap_reports (
+			id          BIGSERIAL PRIMARY KEY,
+			cell_id     TEXT NOT NULL,
+			style_id    BIGINT NOT NULL DEFAULT 0,
+			qty         BIGINT NOT NULL,
+			reported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			reported_by TEXT NOT NULL DEFAULT '',
+			note        TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_cell_scrap_reports_cell_time ON cell_scrap_reports (cell_id, reported_at)`,
+	}
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("v101 oee inputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// v102AuditBinTimeIndex indexes the bin rows of audit_log by time.
+//
+// Replay (store/replay) reads where every bin was across a window from the
+// "payload=… node=…" rows the BinUpdated subscriber appends. audit_log's only
+// index is (entity_type, entity_id), which answers "this bin's history" but
+// not "every bin row in these two hours": without this the window read is a
+// scan of the whole log, the largest table in the database after a year.
+// Partial, because bins are the only entity replay reads by time.
+//
+// A plain CREATE INDEX, not CONCURRENTLY: migrations run in a transaction,
+// and at plant volumes the build is seconds of blocked audit writes at boot.
+//
+// ROLLBACK: a pre-v102 binary never names the index; the planner keeps using it.
+func v102AuditBinTimeIndex(tx *sql.Tx) error {
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_bin_time
+		ON audit_log (created_at) WHERE entity_type = 'bin'`); err != nil {
+		return fmt.Errorf("v102 idx_audit_bin_time: %w", err)
+	}
+	return nil
+}
+
+// v103RobotMaintenance installs the robot health record and the maintenance
+// work items raised from it.
+//
+// robot_health_samples is the e-maint report kept over time: battery level,
+// cycle count, temperatures, odometer, runtime and lift count per robot, one
+// row per maintenance.sample_interval rather than per poll. Capacity fade is
+// read from consecutive rows — battery drawn against metres driven — so the
+// charging flag is on the row and not inferred.
+//
+// robot_fault_events is one row per alarm ONSET, cleared_at set when the code
+// leaves the robot's alarm list. The partial unique index is what makes a
+// second Core, or a restart mid-fault, record the onset once.
+//
+// robot_maintenance_items are the work items. At most one open per robot and
+// indicator, so an hourly assessment that still finds a robot due does not
+// file it again; odometer_m is where the robot stood when the item was
+// CLOSED, and the service interval is measured from the last one done.
+//
+// ROLLBACK: a pre-v103 binary never reads or writes the tables.
+func v103RobotMaintenance(tx *sql.Tx) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS robot_health_samples (
+			id            BIGSERIAL PRIMARY KEY,
+			vehicle_id    TEXT NOT NULL,
+			sampled_at    TIMESTAMPTZ NOT NULL,
+			battery_level DOUBLE PRECISION NOT NULL,
+			battery_cycle INTEGER NOT NULL DEFAULT 0,
+			battery_temp  DOUBLE PRECISION NOT NULL DEFAULT 0,
+			battery_v     DOUBLE PRECISION NOT NULL DEFAULT 0,
+			charging      BOOLEAN NOT NULL,
+			odo_total_m   DOUBLE PRECISION NOT NULL,
+			runtime_ms    BIGINT NOT NULL DEFAULT 0,
+			lift_count    INTEGER NOT NULL DEFAULT 0,
+			ctrl_temp     DOUBLE PRECISION NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_robot_health_samples_vehicle_time ON robot_health_samples (vehicle_id, sampled_at)`,
+		`CREATE TABLE IF NOT EXISTS robot_fault_events (
+			id          BIGSERIAL PRIMARY KEY,
+			vehicle_id  TEXT NOT NULL,
+			code        INTEGER NOT NULL,
+			severity    TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			raised_at   TIMESTAMPTZ NOT NULL,
+			cleared_at  TIMESTAMPTZ
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_robot_fault_events_vehicle_time ON robot_fault_events (vehicle_id, raised_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_robot_fault_events_open ON robot_fault_events (vehicle_id, code) WHERE cleared_at IS NULL`,
+		`CREATE TABLE IF NOT EXISTS robot_maintenance_items (
+			id          BIGSERIAL PRIMARY KEY,
+			vehicle_id  TEXT NOT NULL,
+			indicator   TEXT NOT NULL,
+			status      TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'done', 'dismissed')),
+			value       DOUBLE PRECISION NOT NULL,
+			threshold   DOUBLE PRECISION NOT NULL,
+			detail      TEXT NOT NULL DEFAULT '',
+			held        BOOLEAN NOT NULL DEFAULT FALSE,
+			opened_at   TIMESTAMPTZ NOT NULL,
+			notified_at TIMESTAMPTZ,
+			closed_at   TIMESTAMPTZ,
+			closed_by   TEXT NOT NULL DEFAULT '',
+			note        TEXT NOT NULL DEFAULT '',
+			odometer_m  DOUBLE PRECISION
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_robot_maintenance_items_vehicle ON robot_maintenance_items (vehicle_id, opened_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_robot_maintenance_items_open ON robot_maintenance_items (vehicle_id, indicator) WHERE status = 'open'`,
+	}
+	for _, s := range stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("v103 robot maintenance: %w", err)
+		}
+	}
+	return nil
+}
+
 // MigrationsFailingTheirPostCondition returns every RECORDED-APPLIED migration
 // whose verify is false right now — the set the self-heal would re-run on the
 // next boot.
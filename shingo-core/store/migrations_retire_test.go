@@ -94,8 +94,8 @@ func TestMigrate_PendingRestocksRetired(t *testing.T) {
 	if schema.TableExists(db.DB, "pending_restocks") {
 		t.Error("pending_restocks must be dropped by v70")
 	}
-	if got := store.LatestMigrationVersion(); got != 96 {
-		t.Errorf("head migration = %d, want 96", got)
+	if got := store.LatestMigrationVersion(); got != 103 {
+		t.Errorf("head migration = %d, want 103", got)
 	}
 }
 
@@ -0,0 +1,35 @@
+package nodes
+
+import "fmt"
+
+// Empty affinity — which node's subtree owns the empty carriers standing in
+// it (TC-39, cross-line poaching). The plant-wide empty finders read it;
+// bins.affinityDefs spells the key and values in SQL, so a change here is a
+// change there.
+const (
+	// PropEmptyAffinity is set on a cell, a lineside position or a group.
+	// Absent or blank is off: the node's empties are the plant's, as every
+	// empty was before affinity existed.
+	PropEmptyAffinity = "empty_affinity"
+
+	// EmptyAffinityPrefer ranks the subtree's empties first for asks
+	// delivering into it, and last for everyone else. Nothing is hidden.
+	EmptyAffinityPrefer = "prefer"
+
+	// EmptyAffinityOwn is prefer plus a hold: for the configured grace after a
+	// carrier becomes an empty in the subtree, asks delivering elsewhere cannot
+	// see it at all.
+	EmptyAffinityOwn = "own"
+)
+
+// ValidateEmptyAffinity rejects a value the finders would silently read as
+// off. A typo here ("owned") is a line that believes it is protected and is
+// not, which is worse than being told.
+func ValidateEmptyAffinity(v string) error {
+	switch v {
+	case "", EmptyAffinityPrefer, EmptyAffinityOwn:
+		return nil
+	}
+	return fmt.Errorf("%s must be %q, %q or blank, not %q",
+		PropEmptyAffinity, EmptyAffinityOwn, EmptyAffinityPrefer, v)
+}
@@ -0,0 +1,297 @@
+// Package oee is the persistence layer for OEE (v101): the window queries the
+// figures are computed from, the engineered cycle times performance is rated
+// against, and the scrap reports quality is.
+//
+// Every window query returns raw material grouped by cell — intervals, part
+// counts, scrap — and nothing here divides anything. The arithmetic, and the
+// rules for what is missing, are domain.ComputeOEE's.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/oee_service.go wraps these for the www handlers.
+package oee
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shingo/protocol"
+	"shingocore/domain"
+)
+
+// CycleTime is one engineered cycle: a style's ideal time per part at a cell.
+type CycleTime struct {
+	CellID    string    `json:"cell_id"`
+	StyleID   int64     `json:"style_id"`
+	CycleMS   int64     `json:"cycle_ms"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Seconds is the cycle in seconds, for display.
+func (c CycleTime) Seconds() float64 { return float64(c.CycleMS) / 1000 }
+
+// ScrapReport is one reported scrap quantity. A correction is a negative Qty.
+type ScrapReport struct {
+	ID         int64     `json:"id"`
+	CellID     string    `json:"cell_id"`
+	StyleID    int64     `json:"style_id"`
+	Qty        int64     `json:"qty"`
+	ReportedAt time.Time `json:"reported_at"`
+	ReportedBy string    `json:"reported_by"`
+	Note       string    `json:"note"`
+}
+
+// ErrNotFound is returned when no cycle time is set for the cell and style.
+var ErrNotFound = errors.New("cycle time not found")
+
+// Downtime returns, per cell, the outages that overlap [start, end). An outage
+// is a down/up event pair; the pair is found the way the shift report finds it
+// (store/shiftreports), with the same 7-day floor on how long an outage that
+// ran into the window can have started before it. An outage with no up event
+// yet has a zero End.
+func Downtime(db *sql.DB, start, end time.Time) (map[string][]domain.OEEInterval, error) {
+	rows, err := db.Query(`
+		WITH outages AS (
+			SELECT station, started_at,
+			       MAX(ended_at) FILTER (WHERE duration_ms > 0) AS ended_at
+			  FROM downtime_events
+			 WHERE started_at < $2 AND started_at >= $1::timestamptz - INTERVAL '7 days'
+			 GROUP BY station, plc_name, reason, started_at
+		)
+		SELECT station, started_at, ended_at
+		  FROM outages
+		 WHERE COALESCE(ended_at, $2) > $1
+		 ORDER BY station, started_at`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("oee downtime: %w", err)
+	}
+	return scanIntervals(rows, "oee downtime")
+}
+
+// MaterialWaits returns, per cell, the consume-side demand episodes open at
+// any point in [start, end) — the spans the cell was owed material. A still
+// open episode has a zero End.
+func MaterialWaits(db *sql.DB, start, end time.Time) (map[string][]domain.OEEInterval, error) {
+	rows, err := db.Query(`
+		SELECT station_id, opened_at, closed_at
+		  FROM demand_origins
+		 WHERE direction = $3 AND station_id <> ''
+		   AND opened_at < $2 AND COALESCE(closed_at, $2) > $1
+		 ORDER BY station_id, opened_at`, start.UTC(), end.UTC(), string(protocol.ClaimRoleConsume))
+	if err != nil {
+		return nil, fmt.Errorf("oee material waits: %w", err)
+	}
+	return scanIntervals(rows, "oee material waits")
+}
+
+func scanIntervals(rows *sql.Rows, what string) (map[string][]domain.OEEInterval, error) {
+	defer rows.Close()
+	out := make(map[string][]domain.OEEInterval)
+	for rows.Next() {
+		var (
+			cell  string
+			iv    domain.OEEInterval
+			ended sql.NullTime
+		)
+		if err := rows.Scan(&cell, &iv.Start, &ended); err != nil {
+			return nil, fmt.Errorf("%s: %w", what, err)
+		}
+		if ended.Valid {
+			iv.End = ended.Time
+		}
+		out[cell] = append(out[cell], iv)
+	}
+	return out, rows.Err()
+}
+
+// Production returns parts counted per cell and style in [start, end): the
+// positive deltas of cell_part_events, less unconfirmed counter jumps — the
+// filter messaging's isProductionTick applies. Ticks with no style are kept
+// under style 0, so they count as parts without an engineered cycle rather
+// than vanishing.
+func Production(db *sql.DB, start, end time.Time) (map[string]map[int64]int64, error) {
+	rows, err := db.Query(`
+		SELECT cell_id, style_id, SUM(delta)::bigint
+		  FROM cell_part_events
+		 WHERE recorded_at >= $1 AND recorded_at < $2
+		   AND delta > 0 AND anomaly <> 'jump'
+		 GROUP BY cell_id, style_id`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("oee production: %w", err)
+	}
+	return scanCounts(rows, "oee production")
+}
+
+// HourlyProduction returns parts counted per cell per clock hour in
+// [start, end), keyed by the hour's start. The same filter as Production; the
+// hour is UTC-aligned, which is the plant-local hour everywhere the offset is
+// whole hours.
+func HourlyProduction(db *sql.DB, start, end time.Time) (map[string]map[time.Time]int64, error) {
+	rows, err := db.Query(`
+		SELECT cell_id, date_trunc('hour', recorded_at), SUM(delta)::bigint
+		  FROM cell_part_events
+		 WHERE recorded_at >= $1 AND recorded_at < $2
+		   AND delta > 0 AND anomaly <> 'jump'
+		 GROUP BY 1, 2`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("oee hourly production: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]map[time.Time]int64)
+	for rows.Next() {
+		var (
+			cell string
+			hour time.Time
+			n    int64
+		)
+		if err := rows.Scan(&cell, &hour, &n); err != nil {
+			return nil, fmt.Errorf("oee hourly production: %w", err)
+		}
+		if out[cell] == nil {
+			out[cell] = make(map[time.Time]int64)
+		}
+		out[cell][hour.UTC()] += n
+	}
+	return out, rows.Err()
+}
+
+// Scrap returns reported scrap per cell and style in [start, end). A cell
+// appears only if it reported — even a report netting to zero — which is what
+// lets the caller tell "no scrap" from "not reported".
+func Scrap(db *sql.DB, start, end time.Time) (map[string]map[int64]int64, error) {
+	rows, err := db.Query(`
+		SELECT cell_id, style_id, SUM(qty)::bigint
+		  FROM cell_scrap_reports
+		 WHERE reported_at >= $1 AND reported_at < $2
+		 GROUP BY cell_id, style_id`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("oee scrap: %w", err)
+	}
+	return scanCounts(rows, "oee scrap")
+}
+
+func scanCounts(rows *sql.Rows, what string) (map[string]map[int64]int64, error) {
+	defer rows.Close()
+	out := make(map[string]map[int64]int64)
+	for rows.Next() {
+		var (
+			cell  string
+			style int64
+			n     int64
+		)
+		if err := rows.Scan(&cell, &style, &n); err != nil {
+			return nil, fmt.Errorf("%s: %w", what, err)
+		}
+		if out[cell] == nil {
+			out[cell] = make(map[int64]int64)
+		}
+		out[cell][style] += n
+	}
+	return out, rows.Err()
+}
+
+// ListCycleTimes returns every engineered cycle, by cell and style.
+func ListCycleTimes(db *sql.DB) ([]CycleTime, error) {
+	rows, err := db.Query(`
+		SELECT cell_id, style_id, cycle_ms, updated_by, updated_at
+		  FROM style_cycle_times
+		 ORDER BY cell_id, style_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list cycle times: %w", err)
+	}
+	defer rows.Close()
+	var out []CycleTime
+	for rows.Next() {
+		var c CycleTime
+		if err := rows.Scan(&c.CellID, &c.StyleID, &c.CycleMS, &c.UpdatedBy, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("list cycle times: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// CycleTimeMap returns the engineered cycles keyed by cell, then style.
+func CycleTimeMap(db *sql.DB) (map[string]map[int64]time.Duration, error) {
+	list, err := ListCycleTimes(db)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]map[int64]time.Duration)
+	for _, c := range list {
+		if out[c.CellID] == nil {
+			out[c.CellID] = make(map[int64]time.Duration)
+		}
+		out[c.CellID][c.StyleID] = time.Duration(c.CycleMS) * time.Millisecond
+	}
+	return out, nil
+}
+
+// SetCycleTime creates or replaces a cell and style's engineered cycle.
+func SetCycleTime(db *sql.DB, c CycleTime) error {
+	_, err := db.Exec(`
+		INSERT INTO style_cycle_times (cell_id, style_id, cycle_ms, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (cell_id, style_id) DO UPDATE
+		   SET cycle_ms = EXCLUDED.cycle_ms, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		c.CellID, c.StyleID, c.CycleMS, c.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("set cycle time %s/%d: %w", c.CellID, c.StyleID, err)
+	}
+	return nil
+}
+
+// DeleteCycleTime removes a cell and style's engineered cycle.
+func DeleteCycleTime(db *sql.DB, cellID string, styleID int64) error {
+	res, err := db.Exec(`DELETE FROM style_cycle_times WHERE cell_id = $1 AND style_id = $2`, cellID, styleID)
+	if err != nil {
+		return fmt.Errorf("delete cycle time %s/%d: %w", cellID, styleID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// InsertScrap records one scrap report and returns its ID. A zero ReportedAt
+// is now.
+func InsertScrap(db *sql.DB, r ScrapReport) (int64, error) {
+	var at any
+	if !r.ReportedAt.IsZero() {
+		at = r.ReportedAt.UTC()
+	}
+	var id int64
+	err := db.QueryRow(`
+		INSERT INTO cell_scrap_reports (cell_id, style_id, qty, reported_at, reported_by, note)
+		VALUES ($1, $2, $3, COALESCE($4::timestamptz, NOW()), $5, $6)
+		RETURNING id`,
+		r.CellID, r.StyleID, r.Qty, at, r.ReportedBy, r.Note).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert scrap report %s: %w", r.CellID, err)
+	}
+	return id, nil
+}
+
+// ListScrap returns the scrap reports in [start, end), newest first.
+func ListScrap(db *sql.DB, start, end time.Time) ([]ScrapReport, error) {
+	rows, err := db.Query(`
+		SELECT id, cell_id, style_id, qty, reported_at, reported_by, note
+		  FROM cell_scrap_reports
+		 WHERE reported_at >= $1 AND reported_at < $2
+		 ORDER BY reported_at DESC, id DESC`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list scrap reports: %w", err)
+	}
+	defer rows.Close()
+	var out []ScrapReport
+	for rows.Next() {
+		var r ScrapReport
+		if err := rows.Scan(&r.ID, &r.CellID, &r.StyleID, &r.Qty, &r.ReportedAt, &r.ReportedBy, &r.Note); err != nil {
+			return nil, fmt.Errorf("list scrap reports: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,69 @@
+//go:build docker
+
+package oee_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/oee"
+)
+
+// TestCycleTimes_UpsertAndDelete: setting a cycle twice replaces it, the map
+// is keyed by cell then style, and deleting one that is not set says so.
+func TestCycleTimes_UpsertAndDelete(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	for _, c := range []oee.CycleTime{
+		{CellID: "SPR-1", StyleID: 7, CycleMS: 30000, UpdatedBy: "ie"},
+		{CellID: "SPR-1", StyleID: 7, CycleMS: 42000, UpdatedBy: "ie2"},
+		{CellID: "SPR-2", StyleID: 7, CycleMS: 15000},
+	} {
+		if err := oee.SetCycleTime(db.DB, c); err != nil {
+			t.Fatalf("set %+v: %v", c, err)
+		}
+	}
+	m, err := oee.CycleTimeMap(db.DB)
+	if err != nil {
+		t.Fatalf("map: %v", err)
+	}
+	if m["SPR-1"][7] != 42*time.Second || m["SPR-2"][7] != 15*time.Second {
+		t.Errorf("cycle map = %v, want SPR-1/7 42s (replaced) and SPR-2/7 15s", m)
+	}
+	if err := oee.DeleteCycleTime(db.DB, "SPR-1", 7); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := oee.DeleteCycleTime(db.DB, "SPR-1", 7); !errors.Is(err, oee.ErrNotFound) {
+		t.Errorf("second delete = %v, want ErrNotFound", err)
+	}
+}
+
+// TestScrap_ReportedIsDistinctFromZero: a cell that reported and then
+// corrected back to zero is present in the window with zero scrap; a cell
+// that never reported is absent. Quality depends on telling them apart.
+func TestScrap_ReportedIsDistinctFromZero(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	for _, r := range []oee.ScrapReport{
+		{CellID: "SPR-1", StyleID: 7, Qty: 4, ReportedAt: at},
+		{CellID: "SPR-1", StyleID: 7, Qty: -4, ReportedAt: at.Add(time.Minute), Note: "miscount"},
+		{CellID: "SPR-2", StyleID: 7, Qty: 3, ReportedAt: at.Add(-24 * time.Hour)},
+	} {
+		if _, err := oee.InsertScrap(db.DB, r); err != nil {
+			t.Fatalf("insert %+v: %v", r, err)
+		}
+	}
+	got, err := oee.Scrap(db.DB, at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("scrap: %v", err)
+	}
+	if n, ok := got["SPR-1"][7]; !ok || n != 0 {
+		t.Errorf("SPR-1 = %v (present %v), want 0 and present", n, ok)
+	}
+	if _, ok := got["SPR-2"]; ok {
+		t.Errorf("SPR-2 reported only yesterday, want absent: %v", got)
+	}
+}
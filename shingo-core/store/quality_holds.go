@@ -0,0 +1,13 @@
+package store
+
+// Delegate file: quality-hold persistence lives in store/qualityholds/.
+// Preserves the *store.DB method surface for the source finder, which asks
+// through an optional interface (dispatch.QualityHoldReader).
+
+import "shingocore/store/qualityholds"
+
+// QualityHoldBlocking reports how many bins of a payload are on quality hold
+// and which open hold accounts for most of them.
+func (db *DB) QualityHoldBlocking(payloadCode string) (qualityholds.Blocking, error) {
+	return qualityholds.BlockingFor(db.DB, payloadCode)
+}
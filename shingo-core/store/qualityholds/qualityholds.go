@@ -0,0 +1,214 @@
+// Package qualityholds is the persistence layer for quality holds (v97).
+//
+// A hold is a standing rule over bins — one bin, every bin of a lot, every bin
+// of a payload, or every bin loaded inside a time window — and bins are held BY
+// it. bins.status = 'quality_hold' stays the thing dispatch filters on; the
+// rows here say why, since when, on whose word, and what the bin was before.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/quality_hold_service.go owns the workflow (who may approve,
+// what a disposition does to a bin) and wraps these for the www handlers.
+package qualityholds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope is which bins a hold matches.
+type Scope string
+
+const (
+	ScopeBin          Scope = "bin"
+	ScopeLot          Scope = "lot"
+	ScopePayload      Scope = "payload"
+	ScopeLoadedWindow Scope = "loaded_window"
+)
+
+// Status is where a hold is in its life. active holds bins; a
+// pending_disposition hold still holds them while its disposition waits for an
+// approver; closed holds nothing.
+type Status string
+
+const (
+	StatusActive             Status = "active"
+	StatusPendingDisposition Status = "pending_disposition"
+	StatusClosed             Status = "closed"
+)
+
+// Disposition is what happens to the held bins when a hold closes.
+//
+//   - release — the material is good; each bin goes back to the status it had.
+//   - rework  — the material goes to rework; bins are flagged, which keeps them
+//     out of dispatch until someone clears the flag on the reworked bin.
+//   - scrap   — the material is gone; bins are emptied for reuse.
+type Disposition string
+
+const (
+	DispositionRelease Disposition = "release"
+	DispositionRework  Disposition = "rework"
+	DispositionScrap   Disposition = "scrap"
+)
+
+// ValidDisposition reports whether d is one of the three dispositions.
+func ValidDisposition(d Disposition) bool {
+	switch d {
+	case DispositionRelease, DispositionRework, DispositionScrap:
+		return true
+	}
+	return false
+}
+
+// Hold is one quality hold.
+type Hold struct {
+	ID          int64       `json:"id"`
+	ReasonCode  string      `json:"reason_code"`
+	Note        string      `json:"note"`
+	Scope       Scope       `json:"scope"`
+	BinID       *int64      `json:"bin_id,omitempty"`
+	LotCode     string      `json:"lot_code,omitempty"`
+	PayloadCode string      `json:"payload_code,omitempty"`
+	LoadedFrom  *time.Time  `json:"loaded_from,omitempty"`
+	LoadedTo    *time.Time  `json:"loaded_to,omitempty"`
+	Status      Status      `json:"status"`
+	Disposition Disposition `json:"disposition,omitempty"`
+	RequestedBy string      `json:"requested_by,omitempty"`
+	RequestedAt *time.Time  `json:"requested_at,omitempty"`
+	ApprovedBy  string      `json:"approved_by,omitempty"`
+	CreatedBy   string      `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ClosedAt    *time.Time  `json:"closed_at,omitempty"`
+
+	// HeldBins is the number of bins the hold currently holds. Filled by the
+	// readers from quality_hold_bins, not stored on the row.
+	HeldBins int `json:"held_bins"`
+}
+
+// Ref is the hold's short operator-facing name, "QH-12". It is what the queue
+// sentence and the edge show, so an operator can read it to a quality tech.
+func (h *Hold) Ref() string { return Ref(h.ID) }
+
+// Ref formats a hold id the way every screen names it.
+func Ref(id int64) string { return fmt.Sprintf("QH-%d", id) }
+
+// ScopeLabel renders the match in plain words: "lot L-2207", "payload GEAR-A",
+// "bin 41", "loaded 06:00–14:00 on 2026-10-17".
+func (h *Hold) ScopeLabel() string {
+	switch h.Scope {
+	case ScopeBin:
+		if h.BinID != nil {
+			return fmt.Sprintf("bin %d", *h.BinID)
+		}
+	case ScopeLot:
+		if h.PayloadCode != "" {
+			return fmt.Sprintf("lot %s of %s", h.LotCode, h.PayloadCode)
+		}
+		return "lot " + h.LotCode
+	case ScopePayload:
+		return "payload " + h.PayloadCode
+	case ScopeLoadedWindow:
+		if h.LoadedFrom != nil && h.LoadedTo != nil {
+			label := "loaded " + h.LoadedFrom.Format("2006-01-02 15:04") + " – " + h.LoadedTo.Format("2006-01-02 15:04")
+			if h.PayloadCode != "" {
+				label += " (" + h.PayloadCode + ")"
+			}
+			return label
+		}
+	}
+	return string(h.Scope)
+}
+
+// Input is the create request — the hold's rule and who placed it.
+type Input struct {
+	ReasonCode  string     `json:"reason_code"`
+	Note        string     `json:"note"`
+	Scope       Scope      `json:"scope"`
+	BinID       *int64     `json:"bin_id,omitempty"`
+	LotCode     string     `json:"lot_code,omitempty"`
+	PayloadCode string     `json:"payload_code,omitempty"`
+	LoadedFrom  *time.Time `json:"loaded_from,omitempty"`
+	LoadedTo    *time.Time `json:"loaded_to,omitempty"`
+	CreatedBy   string     `json:"-"`
+}
+
+// Validate checks the scope carries the one field it matches on.
+//
+// A lot and a loading window may ALSO carry a payload code, which narrows them:
+// lot codes are a supplier's numbering and two suppliers can reuse one, and a
+// bad shift is usually bad on one line, not every payload loaded in it. A
+// payload scope is the payload and nothing else.
+func (in Input) Validate() error {
+	if strings.TrimSpace(in.ReasonCode) == "" {
+		return errors.New("reason code is required")
+	}
+	switch in.Scope {
+	case ScopeBin:
+		if in.BinID == nil || *in.BinID <= 0 {
+			return errors.New("a bin hold needs a bin")
+		}
+	case ScopeLot:
+		if strings.TrimSpace(in.LotCode) == "" {
+			return errors.New("a lot hold needs a lot code")
+		}
+	case ScopePayload:
+		if strings.TrimSpace(in.PayloadCode) == "" {
+			return errors.New("a payload hold needs a payload code")
+		}
+	case ScopeLoadedWindow:
+		if in.LoadedFrom == nil || in.LoadedTo == nil {
+			return errors.New("a loading-window hold needs both ends of the window")
+		}
+		if !in.LoadedTo.After(*in.LoadedFrom) {
+			return errors.New("the loading window ends before it starts")
+		}
+	default:
+		return fmt.Errorf("unknown hold scope %q", in.Scope)
+	}
+	return nil
+}
+
+// HeldBin is one bin's membership in a hold.
+type HeldBin struct {
+	HoldID      int64      `json:"hold_id"`
+	BinID       int64      `json:"bin_id"`
+	Label       string     `json:"label"`
+	PayloadCode string     `json:"payload_code"`
+	NodeName    string     `json:"node_name"`
+	BinStatus   string     `json:"bin_status"`
+	PriorStatus string     `json:"prior_status"`
+	HeldAt      time.Time  `json:"held_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	Outcome     string     `json:"outcome,omitempty"`
+}
+
+// Event is one row of a hold's audit trail.
+type Event struct {
+	ID     int64     `json:"id"`
+	HoldID int64     `json:"hold_id"`
+	Action string    `json:"action"`
+	Actor  string    `json:"actor"`
+	Detail string    `json:"detail"`
+	At     time.Time `json:"at"`
+}
+
+// Audit-trail actions. Named so a reader grepping for who released a lot
+// finds the one spelling.
+const (
+	ActionPlaced               = "placed"
+	ActionPropagated           = "propagated"
+	ActionDispositionRequested = "disposition_requested"
+	ActionDispositionRejected  = "disposition_rejected"
+	ActionDispositionApproved  = "disposition_approved"
+	ActionClosed               = "closed"
+)
+
+// Blocking summarizes the open holds standing between a payload and dispatch:
+// how many of its bins are held, and the hold to name when there is one worth
+// naming. Zero HeldBins means no hold is in the way.
+type Blocking struct {
+	HeldBins   int
+	HoldID     int64
+	ReasonCode string
+}
@@ -0,0 +1,96 @@
+package qualityholds
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInputValidate(t *testing.T) {
+	t.Parallel()
+	bin := int64(7)
+	from := time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC)
+	to := from.Add(8 * time.Hour)
+	cases := []struct {
+		name    string
+		in      Input
+		wantErr string
+	}{
+		{"bin", Input{ReasonCode: "other", Scope: ScopeBin, BinID: &bin}, ""},
+		{"bin without id", Input{ReasonCode: "other", Scope: ScopeBin}, "needs a bin"},
+		{"lot", Input{ReasonCode: "supplier", Scope: ScopeLot, LotCode: "L-2207"}, ""},
+		{"lot blank", Input{ReasonCode: "supplier", Scope: ScopeLot, LotCode: "  "}, "lot code"},
+		{"payload", Input{ReasonCode: "dimensional", Scope: ScopePayload, PayloadCode: "GEAR-A"}, ""},
+		{"window", Input{ReasonCode: "contamination", Scope: ScopeLoadedWindow, LoadedFrom: &from, LoadedTo: &to}, ""},
+		{"window reversed", Input{ReasonCode: "contamination", Scope: ScopeLoadedWindow, LoadedFrom: &to, LoadedTo: &from}, "ends before"},
+		{"window open-ended", Input{ReasonCode: "contamination", Scope: ScopeLoadedWindow, LoadedFrom: &from}, "both ends"},
+		{"no reason", Input{Scope: ScopePayload, PayloadCode: "GEAR-A"}, "reason code"},
+		{"unknown scope", Input{ReasonCode: "other", Scope: "station"}, "unknown hold scope"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.in.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestMatchPredicate_NumbersFromOffset pins the placeholder numbering, since
+// Propagate splices the fragment in after its own two arguments and an
+// off-by-one here binds a timestamp to a lot code.
+func TestMatchPredicate_NumbersFromOffset(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC)
+	to := from.Add(8 * time.Hour)
+	h := &Hold{Scope: ScopeLoadedWindow, LoadedFrom: &from, LoadedTo: &to, PayloadCode: "GEAR-A"}
+	where, args := matchPredicate(h, 3)
+	want := "b.loaded_at >= $3 AND b.loaded_at < $4 AND b.payload_code = $5"
+	if where != want {
+		t.Errorf("where = %q, want %q", where, want)
+	}
+	if len(args) != 3 || args[2] != "GEAR-A" {
+		t.Errorf("args = %v, want [from to GEAR-A]", args)
+	}
+
+	lot := &Hold{Scope: ScopeLot, LotCode: "L-2207"}
+	where, args = matchPredicate(lot, 3)
+	if !strings.Contains(where, "'lot_code', $3::text") || len(args) != 1 {
+		t.Errorf("lot predicate = %q %v", where, args)
+	}
+
+	// A payload scope IS the payload; it must not add a second payload clause.
+	pay := &Hold{Scope: ScopePayload, PayloadCode: "GEAR-A"}
+	if where, args = matchPredicate(pay, 3); where != "b.payload_code = $3" || len(args) != 1 {
+		t.Errorf("payload predicate = %q %v", where, args)
+	}
+}
+
+func TestScopeLabel(t *testing.T) {
+	t.Parallel()
+	bin := int64(41)
+	cases := []struct {
+		h    Hold
+		want string
+	}{
+		{Hold{Scope: ScopeBin, BinID: &bin}, "bin 41"},
+		{Hold{Scope: ScopeLot, LotCode: "L-2207"}, "lot L-2207"},
+		{Hold{Scope: ScopeLot, LotCode: "L-2207", PayloadCode: "GEAR-A"}, "lot L-2207 of GEAR-A"},
+		{Hold{Scope: ScopePayload, PayloadCode: "GEAR-A"}, "payload GEAR-A"},
+	}
+	for _, tc := range cases {
+		if got := tc.h.ScopeLabel(); got != tc.want {
+			t.Errorf("ScopeLabel(%+v) = %q, want %q", tc.h, got, tc.want)
+		}
+	}
+	if got := Ref(12); got != "QH-12" {
+		t.Errorf("Ref(12) = %q", got)
+	}
+}
@@ -0,0 +1,539 @@
+package qualityholds
+
+// SQL shell for quality holds. Three tables (v97): quality_holds is the rule,
+// quality_hold_bins is every bin a rule has held, quality_hold_events is the
+// trail. Propagation and resolution are single statements or single
+// transactions so a hold can never be half-applied to its bins.
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shingo/protocol/clock"
+)
+
+// execer is satisfied by *sql.DB and *sql.Tx, so an event can be appended
+// inside the transaction that made it true.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// queryer is the rest of what *sql.DB and *sql.Tx share.
+type queryer interface {
+	execer
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+const selectCols = `h.id, h.reason_code, h.note, h.scope_kind, h.bin_id, h.lot_code, h.payload_code,
+	h.loaded_from, h.loaded_to, h.status, h.disposition, h.requested_by, h.requested_at,
+	h.approved_by, h.created_by, h.created_at, h.closed_at,
+	(SELECT COUNT(*) FROM quality_hold_bins qb WHERE qb.hold_id = h.id AND qb.resolved_at IS NULL)`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface{ Scan(...any) error }
+
+func scanHold(s rowScanner) (*Hold, error) {
+	var (
+		h           Hold
+		binID       sql.NullInt64
+		from, to    sql.NullTime
+		requestedAt sql.NullTime
+		closedAt    sql.NullTime
+	)
+	if err := s.Scan(&h.ID, &h.ReasonCode, &h.Note, &h.Scope, &binID, &h.LotCode, &h.PayloadCode,
+		&from, &to, &h.Status, &h.Disposition, &h.RequestedBy, &requestedAt,
+		&h.ApprovedBy, &h.CreatedBy, &h.CreatedAt, &closedAt, &h.HeldBins); err != nil {
+		return nil, err
+	}
+	if binID.Valid {
+		h.BinID = &binID.Int64
+	}
+	h.LoadedFrom = nullTime(from)
+	h.LoadedTo = nullTime(to)
+	h.RequestedAt = nullTime(requestedAt)
+	h.ClosedAt = nullTime(closedAt)
+	return &h, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
+
+// Create inserts a hold in the active state and records who placed it. It
+// holds no bins yet — Propagate does that, separately, because the sweep runs
+// the same statement for every bin that starts matching later.
+func Create(db *sql.DB, in Input) (int64, error) {
+	if err := in.Validate(); err != nil {
+		return 0, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	id, err := create(tx, in)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// Place is Create and the first Propagate in one transaction: a hold that
+// fails to reach its bins is not left behind open and holding none of them.
+func Place(db *sql.DB, in Input) (int64, []HeldBin, error) {
+	if err := in.Validate(); err != nil {
+		return 0, nil, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+	id, err := create(tx, in)
+	if err != nil {
+		return 0, nil, err
+	}
+	held, err := propagate(tx, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, held, tx.Commit()
+}
+
+func create(tx *sql.Tx, in Input) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`INSERT INTO quality_holds
+		(reason_code, note, scope_kind, bin_id, lot_code, payload_code, loaded_from, loaded_to, created_by, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING id`,
+		in.ReasonCode, in.Note, in.Scope, in.BinID, in.LotCode, in.PayloadCode,
+		in.LoadedFrom, in.LoadedTo, in.CreatedBy, clock.Now().UTC()).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert quality_hold: %w", err)
+	}
+	if err := AppendEvent(tx, id, ActionPlaced, in.CreatedBy, in.ReasonCode); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Get returns one hold, or (nil, nil) if it does not exist.
+func Get(db *sql.DB, id int64) (*Hold, error) {
+	return get(db, id)
+}
+
+func get(db queryer, id int64) (*Hold, error) {
+	h, err := scanHold(db.QueryRow(`SELECT `+selectCols+` FROM quality_holds h WHERE h.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return h, err
+}
+
+// List returns holds newest first. openOnly drops closed holds, which is what
+// the sweep and the quality page's default view want.
+func List(db *sql.DB, openOnly bool, limit int) ([]Hold, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	q := `SELECT ` + selectCols + ` FROM quality_holds h`
+	if openOnly {
+		q += ` WHERE h.status <> 'closed'`
+	}
+	q += ` ORDER BY h.id DESC LIMIT $1`
+	rows, err := db.Query(q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Hold
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *h)
+	}
+	return out, rows.Err()
+}
+
+// Bins returns every bin the hold has held, open rows first.
+func Bins(db *sql.DB, holdID int64) ([]HeldBin, error) {
+	rows, err := db.Query(`SELECT qb.hold_id, qb.bin_id, COALESCE(b.label, ''), COALESCE(b.payload_code, ''),
+			COALESCE(n.name, ''), COALESCE(b.status, ''), qb.prior_status, qb.held_at, qb.resolved_at, qb.outcome
+		FROM quality_hold_bins qb
+		LEFT JOIN bins b ON b.id = qb.bin_id
+		LEFT JOIN nodes n ON n.id = b.node_id
+		WHERE qb.hold_id = $1
+		ORDER BY qb.resolved_at IS NOT NULL, qb.held_at, qb.bin_id`, holdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []HeldBin
+	for rows.Next() {
+		var (
+			hb       HeldBin
+			resolved sql.NullTime
+		)
+		if err := rows.Scan(&hb.HoldID, &hb.BinID, &hb.Label, &hb.PayloadCode, &hb.NodeName,
+			&hb.BinStatus, &hb.PriorStatus, &hb.HeldAt, &resolved, &hb.Outcome); err != nil {
+			return nil, err
+		}
+		hb.ResolvedAt = nullTime(resolved)
+		out = append(out, hb)
+	}
+	return out, rows.Err()
+}
+
+// Events returns the hold's audit trail, oldest first.
+func Events(db *sql.DB, holdID int64) ([]Event, error) {
+	rows, err := db.Query(`SELECT id, hold_id, action, actor, detail, at
+		FROM quality_hold_events WHERE hold_id = $1 ORDER BY at, id`, holdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.HoldID, &e.Action, &e.Actor, &e.Detail, &e.At); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// AppendEvent adds one row to a hold's audit trail.
+func AppendEvent(db execer, holdID int64, action, actor, detail string) error {
+	if _, err := db.Exec(`INSERT INTO quality_hold_events (hold_id, action, actor, detail, at)
+		VALUES ($1,$2,$3,$4,$5)`, holdID, action, actor, detail, clock.Now().UTC()); err != nil {
+		return fmt.Errorf("append quality_hold_event: %w", err)
+	}
+	return nil
+}
+
+// matchPredicate renders the hold's scope as a WHERE fragment over bins b,
+// with its arguments numbered from next.
+func matchPredicate(h *Hold, next int) (string, []any) {
+	p := func(i int) string { return fmt.Sprintf("$%d", next+i) }
+	var (
+		where string
+		args  []any
+	)
+	switch h.Scope {
+	case ScopeBin:
+		var id int64
+		if h.BinID != nil {
+			id = *h.BinID
+		}
+		return "b.id = " + p(0), []any{id}
+	case ScopePayload:
+		return "b.payload_code = " + p(0), []any{h.PayloadCode}
+	case ScopeLot:
+		// Lot lives in the manifest, one per line item; a bin of mixed lots
+		// is held if ANY of its items is from the held lot.
+		where = "b.manifest->'items' @> jsonb_build_array(jsonb_build_object('lot_code', " + p(0) + "::text))"
+		args = []any{h.LotCode}
+	case ScopeLoadedWindow:
+		var from, to time.Time
+		if h.LoadedFrom != nil {
+			from = *h.LoadedFrom
+		}
+		if h.LoadedTo != nil {
+			to = *h.LoadedTo
+		}
+		where = "b.loaded_at >= " + p(0) + " AND b.loaded_at < " + p(1)
+		args = []any{from, to}
+	default:
+		return "FALSE", nil
+	}
+	if h.PayloadCode != "" {
+		where += " AND b.payload_code = " + p(len(args))
+		args = append(args, h.PayloadCode)
+	}
+	return where, args
+}
+
+// Propagate holds every non-retired bin that matches the hold and is not held
+// by it yet, and returns the bins it newly held with the status each had.
+//
+// ONE STATEMENT: the membership rows and the status flip commit together, so a
+// bin is never quality_hold without saying which hold put it there. A bin
+// already held by another hold inherits that hold's prior_status rather than
+// recording 'quality_hold' as its past — otherwise releasing the second hold
+// after the first would "restore" the bin to held.
+//
+// A closed hold propagates nothing, and the hold row is locked while the bins
+// go in: a sweep racing Close either sees the hold closed or finishes first,
+// so Close resolves every membership it wrote. Without the lock the sweep
+// could hold bins under a hold that had just closed, and nothing would ever
+// release them. A claimed bin is held like any other: the status does not
+// recall a robot already carrying it, but nothing new will pick it.
+func Propagate(db *sql.DB, holdID int64) ([]HeldBin, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	held, err := propagate(tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	return held, tx.Commit()
+}
+
+func propagate(tx *sql.Tx, holdID int64) ([]HeldBin, error) {
+	var status Status
+	err := tx.QueryRow(`SELECT status FROM quality_holds WHERE id = $1 FOR UPDATE`, holdID).Scan(&status)
+	if err == sql.ErrNoRows || status == StatusClosed {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	h, err := get(tx, holdID)
+	if err != nil || h == nil {
+		return nil, err
+	}
+	pred, args := matchPredicate(h, 3)
+	q := `WITH held AS (
+			INSERT INTO quality_hold_bins (hold_id, bin_id, prior_status, held_at)
+			SELECT $1, b.id,
+				COALESCE((SELECT o.prior_status FROM quality_hold_bins o
+					WHERE o.bin_id = b.id AND o.resolved_at IS NULL
+					ORDER BY o.held_at LIMIT 1), b.status),
+				$2
+			FROM bins b
+			WHERE b.status <> 'retired' AND (` + pred + `)
+			  AND NOT EXISTS (SELECT 1 FROM quality_hold_bins x WHERE x.hold_id = $1 AND x.bin_id = b.id)
+			RETURNING bin_id, prior_status
+		), flipped AS (
+			UPDATE bins SET status = 'quality_hold', updated_at = $2
+			FROM held WHERE bins.id = held.bin_id AND bins.status <> 'quality_hold'
+		)
+		SELECT bin_id, prior_status FROM held ORDER BY bin_id`
+	rows, err := tx.Query(q, append([]any{holdID, clock.Now().UTC()}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("propagate quality_hold %d: %w", holdID, err)
+	}
+	defer rows.Close()
+	var out []HeldBin
+	for rows.Next() {
+		hb := HeldBin{HoldID: holdID}
+		if err := rows.Scan(&hb.BinID, &hb.PriorStatus); err != nil {
+			return nil, err
+		}
+		out = append(out, hb)
+	}
+	return out, rows.Err()
+}
+
+// RequestDisposition moves an active hold to pending_disposition. Reports
+// false when the hold was not active — already pending, or closed.
+func RequestDisposition(db *sql.DB, holdID int64, d Disposition, requester string) (bool, error) {
+	if !ValidDisposition(d) {
+		return false, fmt.Errorf("unknown disposition %q", d)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`UPDATE quality_holds
+		SET status = 'pending_disposition', disposition = $2, requested_by = $3, requested_at = $4
+		WHERE id = $1 AND status = 'active'`, holdID, d, requester, clock.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := AppendEvent(tx, holdID, ActionDispositionRequested, requester, string(d)); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RejectDisposition returns a pending hold to active, clearing the request.
+// The bins never moved, so there is nothing else to undo.
+func RejectDisposition(db *sql.DB, holdID int64, actor, reason string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	var d string
+	err = tx.QueryRow(`SELECT disposition FROM quality_holds
+		WHERE id = $1 AND status = 'pending_disposition' FOR UPDATE`, holdID).Scan(&d)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`UPDATE quality_holds
+		SET status = 'active', disposition = '', requested_by = '', requested_at = NULL
+		WHERE id = $1`, holdID); err != nil {
+		return false, err
+	}
+	detail := d
+	if reason != "" {
+		detail += ": " + reason
+	}
+	if err := AppendEvent(tx, holdID, ActionDispositionRejected, actor, detail); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Freed is one bin a closing hold let go of.
+//
+// StillHeld is true when another open hold also holds the bin: its membership
+// in THIS hold is resolved, but its status must not move — the other hold is
+// still in force.
+type Freed struct {
+	BinID       int64
+	PriorStatus string
+	StillHeld   bool
+}
+
+// Close approves a pending disposition: the hold closes, every open membership
+// resolves with the disposition as its outcome, and bin statuses move for the
+// two dispositions that are status-only.
+//
+//   - release — each freed bin goes back to its prior status.
+//   - rework  — each freed bin goes to flagged.
+//   - scrap   — bins are LEFT in quality_hold. Scrap empties the manifest, which
+//     is the bin service's job, and the caller sets the status once the bin is
+//     empty. Doing it in that order means a failure between the two leaves a
+//     bin held, never a scrapped bin available with its parts still listed.
+//
+// Returns nil, nil when the hold was not pending (someone else approved or
+// rejected it first).
+func Close(db *sql.DB, holdID int64, approver string) (Disposition, []Freed, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+	now := clock.Now().UTC()
+
+	var d Disposition
+	err = tx.QueryRow(`UPDATE quality_holds
+		SET status = 'closed', approved_by = $2, closed_at = $3
+		WHERE id = $1 AND status = 'pending_disposition'
+		RETURNING disposition`, holdID, approver, now).Scan(&d)
+	if err == sql.ErrNoRows {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	rows, err := tx.Query(`UPDATE quality_hold_bins qb
+		SET resolved_at = $2, outcome = $3
+		WHERE qb.hold_id = $1 AND qb.resolved_at IS NULL
+		RETURNING qb.bin_id, qb.prior_status,
+			EXISTS (SELECT 1 FROM quality_hold_bins o
+				WHERE o.bin_id = qb.bin_id AND o.hold_id <> qb.hold_id AND o.resolved_at IS NULL)`,
+		holdID, now, string(d))
+	if err != nil {
+		return "", nil, err
+	}
+	var freed []Freed
+	for rows.Next() {
+		var f Freed
+		if err := rows.Scan(&f.BinID, &f.PriorStatus, &f.StillHeld); err != nil {
+			rows.Close()
+			return "", nil, err
+		}
+		freed = append(freed, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", nil, err
+	}
+
+	for _, f := range freed {
+		if f.StillHeld {
+			continue
+		}
+		var to string
+		switch d {
+		case DispositionRelease:
+			to = f.PriorStatus
+		case DispositionRework:
+			to = "flagged"
+		default:
+			continue
+		}
+		// Guarded on quality_hold: a bin someone moved off hold by hand while
+		// the hold was open keeps the status they gave it.
+		if _, err := tx.Exec(`UPDATE bins SET status = $2, updated_at = $3
+			WHERE id = $1 AND status = 'quality_hold'`, f.BinID, to, now); err != nil {
+			return "", nil, fmt.Errorf("close quality_hold %d bin %d: %w", holdID, f.BinID, err)
+		}
+	}
+
+	if err := AppendEvent(tx, holdID, ActionDispositionApproved, approver,
+		fmt.Sprintf("%s — %d bin(s)", d, len(freed))); err != nil {
+		return "", nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", nil, err
+	}
+	return d, freed, nil
+}
+
+// OpenHoldForBin returns the open hold that holds a bin, oldest first, or
+// (nil, nil) when nothing does.
+func OpenHoldForBin(db *sql.DB, binID int64) (*Hold, error) {
+	h, err := scanHold(db.QueryRow(`SELECT `+selectCols+`
+		FROM quality_holds h
+		JOIN quality_hold_bins qb ON qb.hold_id = h.id
+		WHERE qb.bin_id = $1 AND qb.resolved_at IS NULL AND h.status <> 'closed'
+		ORDER BY h.id LIMIT 1`, binID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return h, err
+}
+
+// BlockingFor reports how many bins of a payload are on quality hold and, if
+// any open hold accounts for some of them, the one that holds the most.
+//
+// Counts bins.status, not hold membership, so a bin put on hold before holds
+// were rows still counts — it is just as unavailable.
+func BlockingFor(db *sql.DB, payloadCode string) (Blocking, error) {
+	var b Blocking
+	if payloadCode == "" {
+		return b, nil
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM bins
+		WHERE payload_code = $1 AND status = 'quality_hold'`, payloadCode).Scan(&b.HeldBins); err != nil {
+		return b, err
+	}
+	if b.HeldBins == 0 {
+		return b, nil
+	}
+	err := db.QueryRow(`SELECT h.id, h.reason_code
+		FROM quality_hold_bins qb
+		JOIN quality_holds h ON h.id = qb.hold_id
+		JOIN bins b ON b.id = qb.bin_id
+		WHERE qb.resolved_at IS NULL AND h.status <> 'closed'
+		  AND b.payload_code = $1 AND b.status = 'quality_hold'
+		GROUP BY h.id, h.reason_code
+		ORDER BY COUNT(*) DESC, h.id
+		LIMIT 1`, payloadCode).Scan(&b.HoldID, &b.ReasonCode)
+	if err != nil && err != sql.ErrNoRows {
+		return b, err
+	}
+	return b, nil
+}
@@ -0,0 +1,196 @@
+//go:build docker
+
+package qualityholds_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"shingocore/domain"
+	"shingocore/internal/testdb"
+	"shingocore/store/qualityholds"
+)
+
+// TestPropagateAndClose_RestoresPriorStatus walks one payload hold end to end:
+// it holds every matching bin (and only those), a bin that starts matching
+// later is picked up by a second pass, and a release puts each bin back to the
+// status it had — a staged bin comes back staged, not available.
+func TestPropagateAndClose_RestoresPriorStatus(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	sd := testdb.SetupStandardData(t, db)
+
+	avail := testdb.CreateBinAtNode(t, db, sd.Payload.Code, sd.StorageNode.ID, "QH-A")
+	staged := testdb.CreateBinAtNode(t, db, sd.Payload.Code, sd.StorageNode.ID, "QH-B")
+	if err := db.UpdateBinStatus(staged.ID, domain.BinStatusStaged); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	retired := testdb.CreateBinAtNode(t, db, sd.Payload.Code, sd.StorageNode.ID, "QH-C")
+	if err := db.UpdateBinStatus(retired.ID, domain.BinStatusRetired); err != nil {
+		t.Fatalf("retire: %v", err)
+	}
+
+	id, err := qualityholds.Create(db.DB, qualityholds.Input{
+		ReasonCode: "dimensional", Scope: qualityholds.ScopePayload,
+		PayloadCode: sd.Payload.Code, CreatedBy: "inspector",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	held, err := qualityholds.Propagate(db.DB, id)
+	if err != nil {
+		t.Fatalf("propagate: %v", err)
+	}
+	if len(held) != 2 {
+		t.Fatalf("held %d bins, want 2 (retired bins are never held)", len(held))
+	}
+	for _, b := range []int64{avail.ID, staged.ID} {
+		if got := testdb.RequireBin(t, db, b).Status; got != domain.BinStatusQualityHold {
+			t.Errorf("bin %d status = %q, want quality_hold", b, got)
+		}
+	}
+
+	// A second pass is a no-op until something new matches.
+	if again, _ := qualityholds.Propagate(db.DB, id); len(again) != 0 {
+		t.Errorf("re-propagate held %d, want 0", len(again))
+	}
+	late := testdb.CreateBinAtNode(t, db, sd.Payload.Code, sd.StorageNode.ID, "QH-D")
+	if again, _ := qualityholds.Propagate(db.DB, id); len(again) != 1 || again[0].BinID != late.ID {
+		t.Errorf("re-propagate after a late bin = %+v, want just bin %d", again, late.ID)
+	}
+
+	b, err := qualityholds.BlockingFor(db.DB, sd.Payload.Code)
+	if err != nil {
+		t.Fatalf("blocking: %v", err)
+	}
+	if b.HeldBins != 3 || b.HoldID != id || b.ReasonCode != "dimensional" {
+		t.Errorf("BlockingFor = %+v, want 3 held bins naming hold %d", b, id)
+	}
+
+	if ok, err := qualityholds.RequestDisposition(db.DB, id, qualityholds.DispositionRelease, "inspector"); err != nil || !ok {
+		t.Fatalf("request disposition: ok=%v err=%v", ok, err)
+	}
+	d, freed, err := qualityholds.Close(db.DB, id, "supervisor")
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if d != qualityholds.DispositionRelease || len(freed) != 3 {
+		t.Fatalf("close = %q with %d freed, want release with 3", d, len(freed))
+	}
+	if got := testdb.RequireBin(t, db, avail.ID).Status; got != domain.BinStatusAvailable {
+		t.Errorf("available bin restored to %q", got)
+	}
+	if got := testdb.RequireBin(t, db, staged.ID).Status; got != domain.BinStatusStaged {
+		t.Errorf("staged bin restored to %q, want staged", got)
+	}
+
+	events, err := qualityholds.Events(db.DB, id)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	want := []string{qualityholds.ActionPlaced, qualityholds.ActionDispositionRequested, qualityholds.ActionDispositionApproved}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %v", events, want)
+	}
+	for i, e := range events {
+		if e.Action != want[i] {
+			t.Errorf("event %d = %q, want %q", i, e.Action, want[i])
+		}
+	}
+}
+
+// TestClose_OverlappingHoldKeepsBinHeld: a bin held by two holds stays on hold
+// when the first closes, and comes back to its ORIGINAL status when the second
+// does.
+func TestClose_OverlappingHoldKeepsBinHeld(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	sd := testdb.SetupStandardData(t, db)
+	bin := testdb.CreateBinAtNode(t, db, sd.Payload.Code, sd.StorageNode.ID, "QH-X")
+
+	place := func(in qualityholds.Input) int64 {
+		t.Helper()
+		id, err := qualityholds.Create(db.DB, in)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if _, err := qualityholds.Propagate(db.DB, id); err != nil {
+			t.Fatalf("propagate: %v", err)
+		}
+		return id
+	}
+	first := place(qualityholds.Input{ReasonCode: "other", Scope: qualityholds.ScopeBin, BinID: &bin.ID})
+	second := place(qualityholds.Input{ReasonCode: "supplier", Scope: qualityholds.ScopePayload, PayloadCode: sd.Payload.Code})
+
+	for _, id := range []int64{first, second} {
+		if _, err := qualityholds.RequestDisposition(db.DB, id, qualityholds.DispositionRelease, "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, freed, err := qualityholds.Close(db.DB, first, "b"); err != nil || len(freed) != 1 || !freed[0].StillHeld {
+		t.Fatalf("close first: freed=%+v err=%v, want one still-held bin", freed, err)
+	}
+	if got := testdb.RequireBin(t, db, bin.ID).Status; got != domain.BinStatusQualityHold {
+		t.Fatalf("bin status after first release = %q, want still quality_hold", got)
+	}
+	if _, _, err := qualityholds.Close(db.DB, second, "b"); err != nil {
+		t.Fatalf("close second: %v", err)
+	}
+	if got := testdb.RequireBin(t, db, bin.ID).Status; got != domain.BinStatusAvailable {
+		t.Errorf("bin status after both released = %q, want available", got)
+	}
+}
+
+// TestPropagate_RacingCloseOrphansNoBin runs the sweep against an approval
+// many times over: whichever wins, no bin is left held under a closed hold,
+// where nothing would ever release it.
+func TestPropagate_RacingCloseOrphansNoBin(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	sd := testdb.SetupStandardData(t, db)
+
+	for i := 0; i < 20; i++ {
+		id, held, err := qualityholds.Place(db.DB, qualityholds.Input{
+			ReasonCode: "dimensional", Scope: qualityholds.ScopePayload, PayloadCode: sd.Payload.Code,
+		})
+		if err != nil {
+			t.Fatalf("place: %v", err)
+		}
+		if i == 0 && len(held) != 0 {
+			t.Fatalf("place held %d bins before any existed", len(held))
+		}
+		if _, err := qualityholds.RequestDisposition(db.DB, id, qualityholds.DispositionRelease, "a"); err != nil {
+			t.Fatal(err)
+		}
+		late := testdb.CreateBinAtNode(t, db, sd.Payload.Code, sd.StorageNode.ID, fmt.Sprintf("QH-R%d", i))
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := qualityholds.Propagate(db.DB, id); err != nil {
+				t.Errorf("propagate: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, _, err := qualityholds.Close(db.DB, id, "b"); err != nil {
+				t.Errorf("close: %v", err)
+			}
+		}()
+		wg.Wait()
+
+		var open int
+		if err := db.DB.QueryRow(`SELECT COUNT(*) FROM quality_hold_bins
+			WHERE hold_id = $1 AND resolved_at IS NULL`, id).Scan(&open); err != nil {
+			t.Fatal(err)
+		}
+		if open != 0 {
+			t.Fatalf("round %d: %d bin(s) left held under closed hold %d", i, open, id)
+		}
+		if got := testdb.RequireBin(t, db, late.ID).Status; got != domain.BinStatusAvailable {
+			t.Fatalf("round %d: late bin status = %q, want available", i, got)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package replay is the persistence layer for historical replay: the reads
+// that put the plant back the way it was across a past window — robot poses,
+// order histories, and bin audit rows.
+//
+// Nothing here decides what a row means. How a history becomes states, an
+// audit row a place, and an order a slot hold is domain/replay.go's, tested
+// without Postgres.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/replay_service.go wraps these for the www handlers.
+package replay
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shingocore/domain"
+)
+
+// seedLookback is how far before the window RobotPoses looks for where each
+// robot stood when it opened. A robot parked longer than this appears at its
+// first sample inside the window.
+const seedLookback = 24 * time.Hour
+
+// RobotPoses returns each robot's poses in [from, to), thinned to the last
+// sample in every step-long bucket, oldest first — led by its last sample in
+// the day before from, so a robot that did not move at the start of the
+// window is on the floor from the first frame.
+func RobotPoses(db *sql.DB, from, to time.Time, step time.Duration) (map[string][]domain.ReplayPose, error) {
+	rows, err := db.Query(`
+		(SELECT DISTINCT ON (vehicle_id)
+		        vehicle_id, sampled_at, x, y, angle, confidence, station, order_id, blocked
+		   FROM robot_confidence_samples
+		  WHERE sampled_at < $1 AND sampled_at >= $1::timestamptz - $4::float8 * INTERVAL '1 second'
+		  ORDER BY vehicle_id, sampled_at DESC)
+		UNION ALL
+		(SELECT DISTINCT ON (vehicle_id, floor(extract(epoch FROM sampled_at) / $3::float8))
+		        vehicle_id, sampled_at, x, y, angle, confidence, station, order_id, blocked
+		   FROM robot_confidence_samples
+		  WHERE sampled_at >= $1 AND sampled_at < $2
+		  ORDER BY vehicle_id, floor(extract(epoch FROM sampled_at) / $3::float8), sampled_at DESC)
+		ORDER BY 1, 2`,
+		from.UTC(), to.UTC(), step.Seconds(), seedLookback.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("replay robot poses: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string][]domain.ReplayPose)
+	for rows.Next() {
+		var (
+			vehicle string
+			p       domain.ReplayPose
+		)
+		if err := rows.Scan(&vehicle, &p.At, &p.X, &p.Y, &p.Angle, &p.Confidence, &p.Station, &p.OrderID, &p.Blocked); err != nil {
+			return nil, fmt.Errorf("replay robot poses: %w", err)
+		}
+		out[vehicle] = append(out[vehicle], p)
+	}
+	return out, rows.Err()
+}
+
+// Orders returns every order open at some point in [from, to) — created
+// before to and not completed before from — with its history up to to,
+// oldest first.
+func Orders(db *sql.DB, from, to time.Time) ([]domain.ReplayOrderRow, error) {
+	rows, err := db.Query(`
+		SELECT o.id, o.station_id, o.order_type, o.source_node, o.delivery_node, o.robot_id,
+		       o.payload_code, o.bin_id, o.created_at, o.destination_resolved_at,
+		       h.status, COALESCE(h.code, ''), COALESCE(h.detail, ''), h.created_at
+		  FROM orders o
+		  LEFT JOIN order_history h ON h.order_id = o.id AND h.created_at < $2
+		 WHERE o.created_at < $2 AND (o.completed_at IS NULL OR o.completed_at >= $1)
+		 ORDER BY o.id, h.created_at, h.id`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("replay orders: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ReplayOrderRow
+	for rows.Next() {
+		var (
+			o        domain.ReplayOrderRow
+			binID    sql.NullInt64
+			resolved sql.NullTime
+			status   sql.NullString
+			code     string
+			detail   string
+			at       sql.NullTime
+		)
+		if err := rows.Scan(&o.ID, &o.StationID, &o.OrderType, &o.SourceNode, &o.DeliveryNode, &o.RobotID,
+			&o.PayloadCode, &binID, &o.CreatedAt, &resolved, &status, &code, &detail, &at); err != nil {
+			return nil, fmt.Errorf("replay orders: %w", err)
+		}
+		if n := len(out); n == 0 || out[n-1].ID != o.ID {
+			if binID.Valid {
+				o.BinID = &binID.Int64
+			}
+			if resolved.Valid {
+				o.DestinationResolvedAt = &resolved.Time
+			}
+			out = append(out, o)
+		}
+		if status.Valid {
+			last := &out[len(out)-1]
+			last.History = append(last.History, domain.ReplayTransition{
+				At: at.Time, Status: status.String, Code: code, Detail: detail,
+			})
+		}
+	}
+	return out, rows.Err()
+}
+
+// binAuditRow matches the rows the BinUpdated subscriber writes
+// (engine/wiring.go): "payload=<code> node=<id>", every bin event carrying
+// where the bin was and what it held. The UI's own "moved" row, old and new
+// node NAMES, does not match and is not read — the event it emits alongside
+// writes one that does.
+const binAuditRow = `a.entity_type = 'bin' AND a.new_value LIKE 'payload=% node=%'`
+
+// Bins returns every bin created before to, with its audit rows: the last one
+// before from, then every one in [from, to), oldest first.
+func Bins(db *sql.DB, from, to time.Time) ([]domain.ReplayBinRow, error) {
+	out, err := binsBefore(db, to)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int64]int, len(out))
+	for i, b := range out {
+		index[b.BinID] = i
+	}
+
+	obs, err := db.Query(`
+		WITH seen AS (
+			SELECT b.id AS entity_id, l.action, l.new_value, l.created_at, l.id
+			  FROM bins b
+			 CROSS JOIN LATERAL (
+				SELECT a.action, a.new_value, a.created_at, a.id
+				  FROM audit_log a
+				 WHERE a.entity_id = b.id AND `+binAuditRow+` AND a.created_at < $1
+				 ORDER BY a.created_at DESC, a.id DESC
+				 LIMIT 1) l
+			 WHERE b.created_at < $2
+			UNION ALL
+			SELECT a.entity_id, a.action, a.new_value, a.created_at, a.id
+			  FROM audit_log a
+			 WHERE `+binAuditRow+` AND a.created_at >= $1 AND a.created_at < $2
+		)
+		SELECT s.entity_id, s.action, s.created_at,
+		       COALESCE(substring(s.new_value FROM '^payload=(.*) node=[0-9]+$'), ''),
+		       COALESCE(n.name, '')
+		  FROM seen s
+		  LEFT JOIN nodes n ON n.id = substring(s.new_value FROM ' node=([0-9]+)$')::bigint
+		 ORDER BY s.entity_id, s.created_at, s.id`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("replay bin audit: %w", err)
+	}
+	defer obs.Close()
+	for obs.Next() {
+		var (
+			binID int64
+			o     domain.ReplayBinObservation
+		)
+		if err := obs.Scan(&binID, &o.Action, &o.At, &o.Payload, &o.Node); err != nil {
+			return nil, fmt.Errorf("replay bin audit: %w", err)
+		}
+		if i, ok := index[binID]; ok {
+			out[i].Observed = append(out[i].Observed, o)
+		}
+	}
+	return out, obs.Err()
+}
+
+// binsBefore returns every bin created before to, as it is now, flagging the
+// ones with an audit row at or after to.
+func binsBefore(db *sql.DB, to time.Time) ([]domain.ReplayBinRow, error) {
+	rows, err := db.Query(`
+		SELECT b.id, b.label, COALESCE(n.name, ''), b.payload_code, b.created_at,
+		       EXISTS (SELECT 1 FROM audit_log a
+		                WHERE a.entity_id = b.id AND `+binAuditRow+` AND a.created_at >= $1)
+		  FROM bins b
+		  LEFT JOIN nodes n ON n.id = b.node_id
+		 WHERE b.created_at < $1
+		 ORDER BY b.id`, to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("replay bins: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ReplayBinRow
+	for rows.Next() {
+		var b domain.ReplayBinRow
+		if err := rows.Scan(&b.BinID, &b.Label, &b.CurrentNode, &b.CurrentPayload, &b.CreatedAt, &b.ObservedAfter); err != nil {
+			return nil, fmt.Errorf("replay bins: %w", err)
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
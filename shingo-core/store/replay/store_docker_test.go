@@ -0,0 +1,126 @@
+//go:build docker
+
+package replay_test
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/replay"
+	"shingocore/store/robotconfidence"
+)
+
+// TestOrders_OpenInTheWindow: an order completed inside the window comes back
+// with its history up to the window's end; one completed before it opened,
+// and one created after it closed, do not.
+func TestOrders_OpenInTheWindow(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	for _, q := range []struct {
+		sql  string
+		args []any
+	}{
+		{`INSERT INTO orders (id, edge_uuid, station_id, status, delivery_node, created_at, completed_at)
+		  VALUES (4401, 'replay-1', 'SPR-1', 'confirmed', 'LINE-1', $1::timestamptz - INTERVAL '10 minutes', $1::timestamptz + INTERVAL '2 hours'),
+		         (4402, 'replay-2', 'SPR-1', 'confirmed', 'LINE-1', $1::timestamptz - INTERVAL '3 hours', $1::timestamptz - INTERVAL '2 hours'),
+		         (4403, 'replay-3', 'SPR-1', 'pending', 'LINE-1', $1::timestamptz + INTERVAL '3 hours', NULL)`,
+			[]any{at}},
+		{`INSERT INTO order_history (order_id, status, created_at)
+		  VALUES (4401, 'pending', $1::timestamptz - INTERVAL '10 minutes'),
+		         (4401, 'faulted', $1::timestamptz + INTERVAL '20 minutes'),
+		         (4401, 'confirmed', $1::timestamptz + INTERVAL '2 hours')`, []any{at}},
+	} {
+		if _, err := db.Exec(q.sql, q.args...); err != nil {
+			t.Fatalf("fixture: %v", err)
+		}
+	}
+
+	got, err := replay.Orders(db.DB, at, at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("orders: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 4401 {
+		t.Fatalf("orders = %+v, want only 4401", got)
+	}
+	if h := got[0].History; len(h) != 2 || h[0].Status != "pending" || h[1].Status != "faulted" {
+		t.Errorf("history = %+v, want pending then faulted, confirmation cut off", h)
+	}
+}
+
+// TestBins_SeedAndWindowRows: a bin's last audit row before the window and its
+// rows inside it come back with the node id resolved to a name; the UI's
+// name-to-name row is not read.
+func TestBins_SeedAndWindowRows(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	var btID, storID, lineID, binID int64
+	if err := db.QueryRow(`INSERT INTO bin_types (code) VALUES ('RPL') RETURNING id`).Scan(&btID); err != nil {
+		t.Fatalf("bin type: %v", err)
+	}
+	if err := db.QueryRow(`INSERT INTO nodes (name) VALUES ('RPL-STOR') RETURNING id`).Scan(&storID); err != nil {
+		t.Fatalf("node: %v", err)
+	}
+	if err := db.QueryRow(`INSERT INTO nodes (name) VALUES ('RPL-LINE') RETURNING id`).Scan(&lineID); err != nil {
+		t.Fatalf("node: %v", err)
+	}
+	if err := db.QueryRow(`INSERT INTO bins (bin_type_id, label, node_id, created_at) VALUES ($1, 'RPL-1', $2, $3) RETURNING id`,
+		btID, lineID, at.Add(-24*time.Hour)).Scan(&binID); err != nil {
+		t.Fatalf("bin: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO audit_log (entity_type, entity_id, action, old_value, new_value, created_at)
+		VALUES ('bin', $1, 'loaded', '', 'payload=P1 node=' || $2::bigint, $4::timestamptz - INTERVAL '5 hours'),
+		       ('bin', $1, 'counted', '', 'payload=P1 node=' || $2::bigint, $4::timestamptz - INTERVAL '1 hour'),
+		       ('bin', $1, 'moved', 'RPL-STOR', 'RPL-LINE', $4::timestamptz + INTERVAL '30 minutes'),
+		       ('bin', $1, 'moved', '', 'payload=P1 node=' || $3::bigint, $4::timestamptz + INTERVAL '30 minutes')`,
+		binID, storID, lineID, at); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+
+	got, err := replay.Bins(db.DB, at, at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("bins: %v", err)
+	}
+	if len(got) != 1 || got[0].CurrentNode != "RPL-LINE" || got[0].ObservedAfter {
+		t.Fatalf("bins = %+v, want RPL-1 at RPL-LINE, nothing after the window", got)
+	}
+	obs := got[0].Observed
+	if len(obs) != 2 {
+		t.Fatalf("observed = %+v, want the counted seed and the move", obs)
+	}
+	if obs[0].Action != "counted" || obs[0].Node != "RPL-STOR" || obs[0].Payload != "P1" {
+		t.Errorf("seed = %+v, want counted at RPL-STOR holding P1", obs[0])
+	}
+	if obs[1].Action != "moved" || obs[1].Node != "RPL-LINE" {
+		t.Errorf("move = %+v, want moved to RPL-LINE", obs[1])
+	}
+}
+
+// TestRobotPoses_SeedThenThinned: a robot's last sample before the window
+// leads its track, and two samples in one step bucket come back as the later.
+func TestRobotPoses_SeedThenThinned(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	if err := robotconfidence.EnsurePartitionsRange(db.DB, at.Add(-24*time.Hour), at); err != nil {
+		t.Fatalf("partitions: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO robot_confidence_samples (vehicle_id, sampled_at, confidence, x, y, angle, reloc_status)
+		VALUES ('AMR-01', $1::timestamptz - INTERVAL '2 hours', 0.9, 1, 1, 0, 1),
+		       ('AMR-01', $1::timestamptz + INTERVAL '10 seconds', 0.9, 2, 1, 0, 1),
+		       ('AMR-01', $1::timestamptz + INTERVAL '20 seconds', 0.9, 3, 1, 0, 1)`, at); err != nil {
+		t.Fatalf("samples: %v", err)
+	}
+	got, err := replay.RobotPoses(db.DB, at, at.Add(time.Hour), time.Minute)
+	if err != nil {
+		t.Fatalf("poses: %v", err)
+	}
+	p := got["AMR-01"]
+	if len(p) != 2 || p[0].X != 1 || p[1].X != 3 {
+		t.Errorf("poses = %+v, want the seed at x=1 then the bucket's last at x=3", p)
+	}
+}
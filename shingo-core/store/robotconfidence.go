@@ -95,6 +95,24 @@ func (db *DB) PlantWindowBetween(from, to time.Time) (*robotconfidence.LaneWindo
 	return robotconfidence.PlantWindowBetween(db.DB, from, to)
 }
 
+// LaneConfidenceDays and AreaConfidenceDays are the same record with the days
+// kept apart: a window sum cannot tell a lane weak every day from one that was
+// blind for an afternoon, and the map-fix recommendations need to. See
+// robotconfidence/days.go.
+func (db *DB) LaneConfidenceDays(from, to time.Time) (map[string][]robotconfidence.DayStat, error) {
+	return robotconfidence.LaneDays(db.DB, from, to)
+}
+
+func (db *DB) AreaConfidenceDays(from, to time.Time) (map[string][]robotconfidence.DayStat, error) {
+	return robotconfidence.AreaDays(db.DB, from, to)
+}
+
+// SceneSegments reads the synced scene's path segments — the geometry the
+// roll-up snapped to, so a lane placed by it is the lane its numbers are for.
+func (db *DB) SceneSegments() ([]robotconfidence.Segment, error) {
+	return robotconfidence.LoadSegments(db.DB)
+}
+
 // AreaClassLookup adapts store/sceneversion to robotconfidence's
 // AreaClassResolver, so the zone roll-up can label each row with the class of
 // zone it describes without the two packages importing each other.
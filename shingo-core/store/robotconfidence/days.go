@@ -0,0 +1,108 @@
+package robotconfidence
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The day-by-day read — what the map-fix recommendations ask for.
+//
+// A WINDOW SUM CANNOT SAY "PERSISTENT". LaneWindows answers how a lane did over
+// seven days; it cannot tell a lane that was weak every day from one that was
+// blind for one afternoon and fine since, and only the first is a map problem.
+// So this keeps the days apart and leaves the judgement to shingocore/mapfix.
+
+// WeakBelow is the reading under which a tick counts as weak: the vendor's own
+// red edge, and a histogram bin edge, so Hist.Below is exact at it.
+const WeakBelow = 0.30
+
+// DayStat is one lane's or zone's rolled-up day.
+type DayStat struct {
+	Day      time.Time
+	Samples  int
+	Sentinel int
+	// Weak is the no-estimates plus the genuine readings under WeakBelow.
+	// On a row with no histogram it is the sentinel count alone — an
+	// undercount rather than a guess — and HistIncomplete says so.
+	Weak           int
+	Robots         int
+	HistIncomplete bool
+}
+
+// LaneDays returns every lane's days in [from, to), oldest first, keyed like
+// LaneWindows (area \x00 lane).
+//
+// A lane edited mid-day has a row per version on that day; they are one piece
+// of floor on one day and are merged, with the robot count the wider of the
+// two rather than the sum.
+func LaneDays(db *sql.DB, from, to time.Time) (map[string][]DayStat, error) {
+	rows, err := db.Query(
+		`SELECT day, area_name || chr(0) || lane, samples, sentinel_samples, robots,
+		        coalesce(conf_hist, '{}')
+		   FROM lane_confidence_daily
+		  WHERE day >= $1 AND day < $2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("lane days: %w", err)
+	}
+	return scanDays(rows)
+}
+
+// AreaDays returns every declared zone's days in [from, to), oldest first,
+// keyed by the zone id as the roll-up stored it.
+func AreaDays(db *sql.DB, from, to time.Time) (map[string][]DayStat, error) {
+	rows, err := db.Query(
+		`SELECT day, area_name, samples, sentinel_samples, robots,
+		        coalesce(conf_hist, '{}')
+		   FROM area_confidence_daily
+		  WHERE day >= $1 AND day < $2`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("area days: %w", err)
+	}
+	return scanDays(rows)
+}
+
+func scanDays(rows *sql.Rows) (map[string][]DayStat, error) {
+	defer rows.Close()
+	byDay := map[string]map[time.Time]*DayStat{}
+	for rows.Next() {
+		var day time.Time
+		var key, hist string
+		var samples, sentinel, robots int
+		if err := rows.Scan(&day, &key, &samples, &sentinel, &robots, &hist); err != nil {
+			return nil, err
+		}
+		day = day.UTC()
+		if byDay[key] == nil {
+			byDay[key] = map[time.Time]*DayStat{}
+		}
+		d := byDay[key][day]
+		if d == nil {
+			d = &DayStat{Day: day}
+			byDay[key][day] = d
+		}
+		d.Samples += samples
+		d.Sentinel += sentinel
+		d.Robots = max(d.Robots, robots)
+		if h, ok := HistFromSlice(parsePGInt32Array(hist)); ok {
+			d.Weak += h.Below(WeakBelow)
+		} else {
+			d.Weak += sentinel
+			d.HistIncomplete = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make(map[string][]DayStat, len(byDay))
+	for key, days := range byDay {
+		list := make([]DayStat, 0, len(days))
+		for _, d := range days {
+			list = append(list, *d)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Day.Before(list[j].Day) })
+		out[key] = list
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+//go:build docker
+
+package robotconfidence_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"shingocore/store/robotconfidence"
+)
+
+// The day-by-day read keeps the days apart, and counts a weak tick the way
+// the histogram files it — the sentinel plus every genuine reading under 0.30.
+func TestLaneAndAreaDays_KeepDaysApartAndCountWeakTicks(t *testing.T) {
+	t.Parallel()
+	db := openWithWindow(t)
+	addSegment(t, db, "area-a", "LM1-LM2", 0, 0, 10, 0)
+
+	noEst := math.Copysign(0, -1)
+	perDay := [][]float64{
+		{0.95, 0.91, 0.30, 0.88},  // 0.30 is fair, not weak
+		{noEst, 0.12, 0.29, 0.90}, // three weak
+	}
+	for d, vals := range perDay {
+		day := testDay.AddDate(0, 0, -d)
+		var batch []robotconfidence.Sample
+		for i, v := range vals {
+			batch = append(batch, withAreaIDs(sample("AMR-01",
+				day.Add(time.Duration(9+i)*time.Hour), v, float64(i), 0, 1), "8"))
+		}
+		insert(t, db, batch...)
+		if _, err := db.RollUpRobotConfidence(day, rollUpCfg()); err != nil {
+			t.Fatalf("roll-up day -%d: %v", d, err)
+		}
+	}
+
+	from, to := testDay.AddDate(0, 0, -1), testDay.AddDate(0, 0, 1)
+	lanes, err := robotconfidence.LaneDays(db.DB, from, to)
+	if err != nil {
+		t.Fatalf("lane days: %v", err)
+	}
+	days := lanes["area-a\x00"+laneOf("LM1-LM2")]
+	if len(days) != 2 {
+		t.Fatalf("lane days = %+v, want two", lanes)
+	}
+	if !days[0].Day.Equal(from) || days[0].Weak != 3 || days[0].Sentinel != 1 || days[0].Samples != 4 {
+		t.Errorf("older day = %+v, want 4 samples, 1 sentinel, 3 weak", days[0])
+	}
+	if days[1].Weak != 0 || days[1].HistIncomplete {
+		t.Errorf("newer day = %+v, want no weak ticks from a full histogram", days[1])
+	}
+
+	zones, err := robotconfidence.AreaDays(db.DB, from, to)
+	if err != nil {
+		t.Fatalf("area days: %v", err)
+	}
+	if z := zones["08"]; len(z) != 2 || z[0].Weak != 3 {
+		t.Errorf("zone 08 days = %+v, want two with 3 weak on the older", z)
+	}
+}
@@ -5,6 +5,8 @@ package robotconfidence_test
 import (
 	"testing"
 	"time"
+
+	"shingocore/store/sceneversion"
 )
 
 // RecentSceneDiffs lists EDITS, not archives.
@@ -55,3 +57,50 @@ func TestRecentSceneDiffs_ListsEditsNotArchives(t *testing.T) {
 			diffs[0].ID, diffs[1].ID, second, first)
 	}
 }
+
+// LaneEditsIn lists CHANGES, not versions. Every lane has a first version
+// opening at the beginning of time; reporting it would mark the whole plant
+// as edited and hand the map-fix recommendations an edit to blame for every
+// hotspot.
+func TestLaneEditsIn_ListsChangesNotFirstVersions(t *testing.T) {
+	t.Parallel()
+	db := openWithWindow(t)
+	addSegment(t, db, "area-a", "LM1-LM2", 0, 0, 10, 0)
+	lane := laneOf("LM1-LM2")
+
+	var firstID, diffID int64
+	if err := db.QueryRow(`SELECT id FROM scene_lane_versions WHERE area_name='area-a' AND lane=$1`,
+		lane).Scan(&firstID); err != nil {
+		t.Fatalf("first version: %v", err)
+	}
+	if err := db.QueryRow(
+		`INSERT INTO scene_diffs (source, gate_hash, observed_at)
+		 VALUES ('rds_scene','edit',$1) RETURNING id`, testDay).Scan(&diffID); err != nil {
+		t.Fatalf("insert diff: %v", err)
+	}
+	edited := testDay.Add(14 * time.Hour)
+	if _, err := db.Exec(`UPDATE scene_lane_versions SET valid_to=$2 WHERE id=$1`,
+		firstID, edited); err != nil {
+		t.Fatalf("close first version: %v", err)
+	}
+	if _, err := db.Exec(
+		`INSERT INTO scene_lane_versions
+		   (area_name, lane, shape_hash, def_hash, shape, directed_rows, diff_id,
+		    valid_from, supersedes_id, max_vertex_delta_m)
+		 VALUES ('area-a',$1,'fy','fy','[]',2,$2,$3,$4,0.8)`,
+		lane, diffID, edited, firstID); err != nil {
+		t.Fatalf("insert edit: %v", err)
+	}
+
+	edits, err := sceneversion.LaneEditsIn(db.DB, testDay.AddDate(0, 0, -7), testDay.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("lane edits: %v", err)
+	}
+	got := edits["area-a\x00"+lane]
+	if len(edits) != 1 || len(got) != 1 {
+		t.Fatalf("edits = %+v, want the one change and not the first version", edits)
+	}
+	if !got[0].At.Equal(edited) || got[0].DiffID != diffID || got[0].MovedM == nil || *got[0].MovedM != 0.8 {
+		t.Errorf("edit = %+v, want diff %d at %s moving 0.8 m", got[0], diffID, edited)
+	}
+}
@@ -104,6 +104,22 @@ func (h Hist) Total() int {
 // SentinelCount is the readings that produced no estimate at all.
 func (h Hist) SentinelCount() int { return int(h[0]) }
 
+// Below counts the readings under conf, the sentinel included — how much of
+// the distribution fell short of a band edge.
+//
+// conf is rounded to the nearest bin edge, so it is exact only ON one. The
+// two that matter, 0.30 and 0.80, are edges by construction; anything else is
+// answered to within a bin rather than refused.
+func (h Hist) Below(conf float64) int {
+	edge := int(math.Round(conf / HistBinWidth))
+	edge = max(0, min(edge, HistBins))
+	n := int(h[0])
+	for j := 0; j < edge; j++ {
+		n += int(h[1+j])
+	}
+	return n
+}
+
 // PercentileEstimate returns the p-th percentile over EVERY reading, counting a
 // no-estimate as the zero it is, and reports whether there was anything to
 // compute it from.
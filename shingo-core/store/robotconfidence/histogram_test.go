@@ -178,3 +178,22 @@ func TestHist_UpperBoundLandsInTheTopBin(t *testing.T) {
 		t.Errorf("p50 of a single 1.0 reading = %v, want within the top bin", got)
 	}
 }
+
+// Below at a band edge agrees with counting the raw readings under it, the
+// sentinel included. 0.30 is what the map-fix hotspots call weak, and a
+// reading of exactly 0.30 is fair, not weak.
+func TestHist_BelowCountsTheSentinelAndStopsAtTheEdge(t *testing.T) {
+	t.Parallel()
+	var h Hist
+	for _, v := range []float64{math.Copysign(0, -1), 0, 0.01, 0.29, 0.2999, 0.30, 0.31, 0.79, 0.80, 1.0} {
+		h.Add(v)
+	}
+	for _, c := range []struct {
+		conf float64
+		want int
+	}{{0.30, 5}, {0.80, 8}, {0, 2}, {1, 10}, {1.5, 10}} {
+		if got := h.Below(c.conf); got != c.want {
+			t.Errorf("Below(%v) = %d, want %d", c.conf, got, c.want)
+		}
+	}
+}
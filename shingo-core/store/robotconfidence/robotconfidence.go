@@ -241,6 +241,15 @@ func (s Segment) Curved() bool {
 	return s.Ctrl1X != nil && s.Ctrl1Y != nil && s.Ctrl2X != nil && s.Ctrl2Y != nil
 }
 
+// Midpoint is the point halfway along the curve the robot drives — on the
+// painted lane, not on the chord, which at Springfield can be 1.3 m off it.
+func (s Segment) Midpoint() (float64, float64) {
+	if !s.Curved() {
+		return (s.FromX + s.ToX) / 2, (s.FromY + s.ToY) / 2
+	}
+	return s.cubicPoint(0.5)
+}
+
 // Lane is the segment's UNDIRECTED identity: the endpoint pair, sorted.
 //
 // THIS IS A CORRECTNESS FIX, NOT A TIDY-UP. scene_edges stores every drivable
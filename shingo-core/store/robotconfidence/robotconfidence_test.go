@@ -304,8 +304,10 @@ func TestSegmentIndex_CurvedLaneIsMeasuredAlongItsCurve(t *testing.T) {
 		t.Errorf("MaxDeviation = %.4f m, want ~1.302 m for LM10-LM113", dev)
 	}
 
-	// A point sitting ON the curve at its midpoint is far from the chord.
-	mx, my := curved.cubicPoint(0.5)
+	// A point sitting ON the curve at its midpoint is far from the chord —
+	// and Midpoint, which the map-fix recommendations place a lane by, is
+	// that point.
+	mx, my := curved.Midpoint()
 	if d := ix.distanceTo(0, mx, my); d > 0.02 {
 		t.Errorf("a point on the curve should snap to it: got %.4f m", d)
 	}
@@ -0,0 +1,555 @@
+// Package robotmaintenance is the persistence layer for predictive robot
+// maintenance (v103): the robot health record the engine samples off the
+// fleet poll, the alarm onsets, and the maintenance work items raised from
+// them.
+//
+// Nothing here decides whether a robot is due. The indicators and their
+// thresholds are shingocore/robothealth's, tested without Postgres; this
+// package reads the record into the days those indicators are computed from.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/robot_maintenance_service.go wraps these for the engine and
+// the www handlers.
+package robotmaintenance
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"shingocore/robothealth"
+)
+
+// Sample is one row of the health record — the e-maint report's battery,
+// odometer and wear counters for one robot at one time.
+type Sample struct {
+	VehicleID    string
+	SampledAt    time.Time
+	BatteryLevel float64 // percent
+	BatteryCycle int
+	BatteryTemp  float64
+	BatteryV     float64
+	Charging     bool
+	OdoTotalM    float64
+	RuntimeMs    int64
+	LiftCount    int
+	CtrlTemp     float64
+}
+
+// InsertSamples writes one poll's samples in a single statement.
+func InsertSamples(db *sql.DB, batch []Sample) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	q := `INSERT INTO robot_health_samples (vehicle_id, sampled_at, battery_level, battery_cycle,
+		battery_temp, battery_v, charging, odo_total_m, runtime_ms, lift_count, ctrl_temp) VALUES `
+	args := make([]any, 0, len(batch)*11)
+	for i, s := range batch {
+		if i > 0 {
+			q += ", "
+		}
+		n := len(args)
+		q += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11)
+		args = append(args, s.VehicleID, s.SampledAt.UTC(), s.BatteryLevel, s.BatteryCycle,
+			s.BatteryTemp, s.BatteryV, s.Charging, s.OdoTotalM, s.RuntimeMs, s.LiftCount, s.CtrlTemp)
+	}
+	if _, err := db.Exec(q, args...); err != nil {
+		return fmt.Errorf("insert robot health samples: %w", err)
+	}
+	return nil
+}
+
+// Fault is an alarm onset.
+type Fault struct {
+	VehicleID   string
+	Code        int
+	Severity    string
+	Description string
+	RaisedAt    time.Time
+}
+
+// RaiseFault records an onset. A code already open on the robot is left as
+// it is, so a restart that re-sees a standing alarm does not count it twice.
+func RaiseFault(db *sql.DB, f Fault) error {
+	_, err := db.Exec(`INSERT INTO robot_fault_events (vehicle_id, code, severity, description, raised_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vehicle_id, code) WHERE cleared_at IS NULL DO NOTHING`,
+		f.VehicleID, f.Code, f.Severity, f.Description, f.RaisedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("raise robot fault %s/%d: %w", f.VehicleID, f.Code, err)
+	}
+	return nil
+}
+
+// ClearFault closes the robot's open onset of code.
+func ClearFault(db *sql.DB, vehicleID string, code int, at time.Time) error {
+	_, err := db.Exec(`UPDATE robot_fault_events SET cleared_at = $3
+		WHERE vehicle_id = $1 AND code = $2 AND cleared_at IS NULL`, vehicleID, code, at.UTC())
+	if err != nil {
+		return fmt.Errorf("clear robot fault %s/%d: %w", vehicleID, code, err)
+	}
+	return nil
+}
+
+// OpenFaults returns the codes open per robot — the sampler's starting state.
+func OpenFaults(db *sql.DB) (map[string]map[int]bool, error) {
+	rows, err := db.Query(`SELECT vehicle_id, code FROM robot_fault_events WHERE cleared_at IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("open robot faults: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]map[int]bool{}
+	for rows.Next() {
+		var v string
+		var code int
+		if err := rows.Scan(&v, &code); err != nil {
+			return nil, fmt.Errorf("open robot faults: %w", err)
+		}
+		if out[v] == nil {
+			out[v] = map[int]bool{}
+		}
+		out[v][code] = true
+	}
+	return out, rows.Err()
+}
+
+// FaultsByCode counts onsets per robot and code raised in [since, until).
+func FaultsByCode(db *sql.DB, since, until time.Time) (map[string]map[int]int, error) {
+	rows, err := db.Query(`SELECT vehicle_id, code, COUNT(*) FROM robot_fault_events
+		WHERE raised_at >= $1 AND raised_at < $2 GROUP BY 1, 2`, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("robot faults by code: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]map[int]int{}
+	for rows.Next() {
+		var v string
+		var code, n int
+		if err := rows.Scan(&v, &code, &n); err != nil {
+			return nil, fmt.Errorf("robot faults by code: %w", err)
+		}
+		if out[v] == nil {
+			out[v] = map[int]int{}
+		}
+		out[v][code] = n
+	}
+	return out, rows.Err()
+}
+
+// Days reads the record for [since, until) into UTC days per robot, oldest
+// first.
+//
+// Discharge is measured between CONSECUTIVE samples, and a pair counts only
+// when the robot was off the charger at both ends, the battery did not rise
+// and the two are no more than maxGap apart — a robot switched off overnight
+// and plugged in by hand must not read as a night of driving on no battery.
+//
+// Faults, missions (mission_telemetry by robot) and localization (the nightly
+// robot_confidence_daily roll-up) are joined by day.
+func Days(db *sql.DB, since, until time.Time, maxGap time.Duration) (map[string][]robothealth.Day, error) {
+	s, u := since.UTC(), until.UTC()
+	days := map[string]map[time.Time]*robothealth.Day{}
+	day := func(v string, d time.Time) *robothealth.Day {
+		if days[v] == nil {
+			days[v] = map[time.Time]*robothealth.Day{}
+		}
+		d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+		if days[v][d] == nil {
+			days[v][d] = &robothealth.Day{Day: d}
+		}
+		return days[v][d]
+	}
+
+	rows, err := db.Query(`
+		WITH s AS (
+			SELECT vehicle_id, sampled_at, battery_level, battery_cycle, charging, odo_total_m,
+			       LAG(sampled_at)    OVER w AS prev_at,
+			       LAG(battery_level) OVER w AS prev_level,
+			       LAG(charging)      OVER w AS prev_charging,
+			       LAG(odo_total_m)   OVER w AS prev_odo
+			  FROM robot_health_samples
+			 WHERE sampled_at >= $1 AND sampled_at < $2
+			WINDOW w AS (PARTITION BY vehicle_id ORDER BY sampled_at)
+		), p AS (
+			SELECT *, (NOT charging AND NOT prev_charging AND battery_level <= prev_level
+			           AND odo_total_m >= prev_odo
+			           AND sampled_at - prev_at <= make_interval(secs => $3)) AS draw
+			  FROM s
+		)
+		SELECT vehicle_id, (sampled_at AT TIME ZONE 'UTC')::date, COUNT(*), MAX(battery_cycle),
+		       COALESCE(SUM(prev_level - battery_level) FILTER (WHERE draw), 0),
+		       COALESCE(SUM(odo_total_m - prev_odo) FILTER (WHERE draw), 0) / 1000,
+		       (ARRAY_AGG(odo_total_m ORDER BY sampled_at DESC))[1]
+		  FROM p
+		 GROUP BY 1, 2`, s, u, maxGap.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("robot health days: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v string
+		var d time.Time
+		var r robothealth.Day
+		if err := rows.Scan(&v, &d, &r.Samples, &r.BatteryCycle, &r.DischargePct, &r.DischargeKm, &r.OdoM); err != nil {
+			return nil, fmt.Errorf("robot health days: %w", err)
+		}
+		p := day(v, d)
+		p.Samples, p.BatteryCycle, p.DischargePct, p.DischargeKm, p.OdoM = r.Samples, r.BatteryCycle, r.DischargePct, r.DischargeKm, r.OdoM
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("robot health days: %w", err)
+	}
+
+	counts := []struct {
+		name, q string
+		set     func(*robothealth.Day, int)
+	}{
+		{"faults", `SELECT vehicle_id, (raised_at AT TIME ZONE 'UTC')::date, COUNT(*) FROM robot_fault_events
+			WHERE raised_at >= $1 AND raised_at < $2 GROUP BY 1, 2`,
+			func(d *robothealth.Day, n int) { d.Faults = n }},
+		{"missions", `SELECT robot_id, (created_at AT TIME ZONE 'UTC')::date, COUNT(*) FROM mission_telemetry
+			WHERE created_at >= $1 AND created_at < $2 AND robot_id <> '' GROUP BY 1, 2`,
+			func(d *robothealth.Day, n int) { d.Missions = n }},
+	}
+	for _, c := range counts {
+		if err := scanDayCounts(db, c.q, s, u, func(v string, d time.Time, n int) { c.set(day(v, d), n) }); err != nil {
+			return nil, fmt.Errorf("robot health %s: %w", c.name, err)
+		}
+	}
+
+	rows, err = db.Query(`SELECT vehicle_id, day, mean, samples FROM robot_confidence_daily
+		WHERE day >= ($1 AT TIME ZONE 'UTC')::date AND day < ($2 AT TIME ZONE 'UTC')::date AND mean IS NOT NULL`, s, u)
+	if err != nil {
+		return nil, fmt.Errorf("robot health confidence: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v string
+		var d time.Time
+		var mean float64
+		var n int
+		if err := rows.Scan(&v, &d, &mean, &n); err != nil {
+			return nil, fmt.Errorf("robot health confidence: %w", err)
+		}
+		p := day(v, d)
+		p.ConfidenceMean, p.ConfidenceSamples = mean, n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("robot health confidence: %w", err)
+	}
+
+	out := make(map[string][]robothealth.Day, len(days))
+	for v, m := range days {
+		list := make([]robothealth.Day, 0, len(m))
+		for _, d := range m {
+			list = append(list, *d)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Day.Before(list[j].Day) })
+		out[v] = list
+	}
+	return out, nil
+}
+
+func scanDayCounts(db *sql.DB, q string, since, until time.Time, fn func(string, time.Time, int)) error {
+	rows, err := db.Query(q, since, until)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v string
+		var d time.Time
+		var n int
+		if err := rows.Scan(&v, &d, &n); err != nil {
+			return err
+		}
+		fn(v, d, n)
+	}
+	return rows.Err()
+}
+
+// Reading is an odometer at a time.
+type Reading struct {
+	At    time.Time
+	OdoM  float64
+	Cycle int
+}
+
+// Latest returns each robot's newest sample.
+func Latest(db *sql.DB) (map[string]Reading, error) {
+	return readings(db, `SELECT DISTINCT ON (vehicle_id) vehicle_id, sampled_at, odo_total_m, battery_cycle
+		FROM robot_health_samples ORDER BY vehicle_id, sampled_at DESC`)
+}
+
+// Earliest returns each robot's oldest sample still on record — where the
+// service interval runs from for a robot with no service item closed done.
+func Earliest(db *sql.DB) (map[string]Reading, error) {
+	return readings(db, `SELECT DISTINCT ON (vehicle_id) vehicle_id, sampled_at, odo_total_m, battery_cycle
+		FROM robot_health_samples ORDER BY vehicle_id, sampled_at`)
+}
+
+func readings(db *sql.DB, q string) (map[string]Reading, error) {
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, fmt.Errorf("robot health readings: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]Reading{}
+	for rows.Next() {
+		var v string
+		var r Reading
+		if err := rows.Scan(&v, &r.At, &r.OdoM, &r.Cycle); err != nil {
+			return nil, fmt.Errorf("robot health readings: %w", err)
+		}
+		out[v] = r
+	}
+	return out, rows.Err()
+}
+
+// Prune deletes samples, and faults cleared, before before. Open faults and
+// work items are kept whatever their age.
+func Prune(db *sql.DB, before time.Time) (int64, error) {
+	var total int64
+	for _, q := range []string{
+		`DELETE FROM robot_health_samples WHERE sampled_at < $1`,
+		`DELETE FROM robot_fault_events WHERE cleared_at < $1`,
+	} {
+		res, err := db.Exec(q, before.UTC())
+		if err != nil {
+			return total, fmt.Errorf("prune robot health: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, nil
+}
+
+// ── Work items ──────────────────────────────────────────────────────────────
+
+// Item statuses.
+const (
+	ItemOpen      = "open"
+	ItemDone      = "done"
+	ItemDismissed = "dismissed"
+)
+
+// Item is one maintenance work item.
+type Item struct {
+	ID         int64      `json:"id"`
+	VehicleID  string     `json:"vehicle_id"`
+	Indicator  string     `json:"indicator"`
+	Status     string     `json:"status"`
+	Value      float64    `json:"value"`
+	Threshold  float64    `json:"threshold"`
+	Detail     string     `json:"detail"`
+	Held       bool       `json:"held"`
+	OpenedAt   time.Time  `json:"opened_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	ClosedBy   string     `json:"closed_by,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	OdometerM  *float64   `json:"odometer_m,omitempty"`
+}
+
+var (
+	// ErrNotFound is returned when no item has the id.
+	ErrNotFound = errors.New("maintenance item not found")
+	// ErrNotOpen is returned when closing an item already closed.
+	ErrNotOpen = errors.New("maintenance item is not open")
+)
+
+const itemCols = `id, vehicle_id, indicator, status, value, threshold, detail, held,
+	opened_at, notified_at, closed_at, closed_by, note, odometer_m`
+
+type rowScanner interface{ Scan(...any) error }
+
+func scanItem(s rowScanner) (*Item, error) {
+	var (
+		it               Item
+		notified, closed sql.NullTime
+		odometer         sql.NullFloat64
+	)
+	if err := s.Scan(&it.ID, &it.VehicleID, &it.Indicator, &it.Status, &it.Value, &it.Threshold,
+		&it.Detail, &it.Held, &it.OpenedAt, &notified, &closed, &it.ClosedBy, &it.Note, &odometer); err != nil {
+		return nil, err
+	}
+	if notified.Valid {
+		t := notified.Time
+		it.NotifiedAt = &t
+	}
+	if closed.Valid {
+		t := closed.Time
+		it.ClosedAt = &t
+	}
+	if odometer.Valid {
+		v := odometer.Float64
+		it.OdometerM = &v
+	}
+	return &it, nil
+}
+
+func scanItems(rows *sql.Rows) ([]*Item, error) {
+	defer rows.Close()
+	var out []*Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan maintenance item: %w", err)
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// OpenItem files a work item for the robot and indicator. While one is
+// already open the open item is kept and its value and detail refreshed;
+// created reports which happened, and it comes back filled in either way.
+func OpenItem(db *sql.DB, it *Item) (created bool, err error) {
+	err = db.QueryRow(`INSERT INTO robot_maintenance_items
+			(vehicle_id, indicator, value, threshold, detail, opened_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (vehicle_id, indicator) WHERE status = 'open'
+		DO UPDATE SET value = EXCLUDED.value, threshold = EXCLUDED.threshold, detail = EXCLUDED.detail
+		RETURNING id, opened_at, held, status, (xmax = 0)`,
+		it.VehicleID, it.Indicator, it.Value, it.Threshold, it.Detail, it.OpenedAt.UTC()).
+		Scan(&it.ID, &it.OpenedAt, &it.Held, &it.Status, &created)
+	if err != nil {
+		return false, fmt.Errorf("open maintenance item %s/%s: %w", it.VehicleID, it.Indicator, err)
+	}
+	return created, nil
+}
+
+// Get returns one item.
+func Get(db *sql.DB, id int64) (*Item, error) {
+	it, err := scanItem(db.QueryRow(`SELECT `+itemCols+` FROM robot_maintenance_items WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return it, err
+}
+
+// Filter narrows List. Zero fields do not filter.
+type Filter struct {
+	VehicleID string
+	Status    string
+	// Limit defaults to 100 and is capped at 1000.
+	Limit int
+}
+
+// List returns items, newest first.
+func List(db *sql.DB, f Filter) ([]*Item, error) {
+	q := `SELECT ` + itemCols + ` FROM robot_maintenance_items WHERE TRUE`
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		q += fmt.Sprintf(cond, len(args))
+	}
+	if f.VehicleID != "" {
+		add(" AND vehicle_id = $%d", f.VehicleID)
+	}
+	if f.Status != "" {
+		add(" AND status = $%d", f.Status)
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	add(" ORDER BY opened_at DESC, id DESC LIMIT $%d", limit)
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance items: %w", err)
+	}
+	return scanItems(rows)
+}
+
+// SetHeld records that the item took its robot out of availability, or gave
+// it back.
+func SetHeld(db *sql.DB, id int64, held bool) error {
+	if _, err := db.Exec(`UPDATE robot_maintenance_items SET held = $2 WHERE id = $1`, id, held); err != nil {
+		return fmt.Errorf("hold maintenance item %d: %w", id, err)
+	}
+	return nil
+}
+
+// Close closes an open item as done or dismissed. odometerM is where the
+// robot stood, nil when it is not known; it is what the next service
+// interval is measured from.
+func Close(db *sql.DB, id int64, status, by, note string, odometerM *float64, now time.Time) (*Item, error) {
+	if status != ItemDone && status != ItemDismissed {
+		return nil, fmt.Errorf("close maintenance item %d: status %q is not done or dismissed", id, status)
+	}
+	var odo sql.NullFloat64
+	if odometerM != nil {
+		odo = sql.NullFloat64{Float64: *odometerM, Valid: true}
+	}
+	it, err := scanItem(db.QueryRow(`UPDATE robot_maintenance_items
+		SET status = $2, closed_by = $3, note = $4, odometer_m = $5, closed_at = $6
+		WHERE id = $1 AND status = 'open'
+		RETURNING `+itemCols, id, status, by, note, odo, now.UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, gerr := Get(db, id); gerr != nil {
+			return nil, gerr
+		}
+		return nil, ErrNotOpen
+	}
+	if err != nil {
+		return nil, fmt.Errorf("close maintenance item %d: %w", id, err)
+	}
+	return it, nil
+}
+
+// LastService returns, per robot, the newest item closed done on indicator
+// with an odometer recorded.
+func LastService(db *sql.DB, indicator string) (map[string]Reading, error) {
+	rows, err := db.Query(`SELECT DISTINCT ON (vehicle_id) vehicle_id, closed_at, odometer_m
+		FROM robot_maintenance_items
+		WHERE indicator = $1 AND status = 'done' AND odometer_m IS NOT NULL
+		ORDER BY vehicle_id, closed_at DESC`, indicator)
+	if err != nil {
+		return nil, fmt.Errorf("last robot service: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]Reading{}
+	for rows.Next() {
+		var v string
+		var r Reading
+		if err := rows.Scan(&v, &r.At, &r.OdoM); err != nil {
+			return nil, fmt.Errorf("last robot service: %w", err)
+		}
+		out[v] = r
+	}
+	return out, rows.Err()
+}
+
+// StillHeld reports whether any other open item holds the robot.
+func StillHeld(db *sql.DB, vehicleID string, exceptID int64) (bool, error) {
+	var ok bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM robot_maintenance_items
+		WHERE vehicle_id = $1 AND status = 'open' AND held AND id <> $2)`, vehicleID, exceptID).Scan(&ok)
+	return ok, err
+}
+
+// Unnotified lists open items whose notification has not gone out, oldest
+// first.
+func Unnotified(db *sql.DB) ([]*Item, error) {
+	rows, err := db.Query(`SELECT ` + itemCols + ` FROM robot_maintenance_items
+		WHERE status = 'open' AND notified_at IS NULL ORDER BY opened_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("unnotified maintenance items: %w", err)
+	}
+	return scanItems(rows)
+}
+
+// MarkNotified records that the item was handed to the channels.
+func MarkNotified(db *sql.DB, id int64, now time.Time) error {
+	if _, err := db.Exec(`UPDATE robot_maintenance_items SET notified_at = $2 WHERE id = $1`, id, now.UTC()); err != nil {
+		return fmt.Errorf("mark maintenance item %d notified: %w", id, err)
+	}
+	return nil
+}
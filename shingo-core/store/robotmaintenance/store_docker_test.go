@@ -0,0 +1,120 @@
+//go:build docker
+
+package robotmaintenance_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/robotmaintenance"
+)
+
+var t0 = time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC)
+
+// TestFaults_OneRowPerOnset: a code seen again while open — a restart that
+// re-reads a standing alarm — is the same fault; once cleared, the next
+// sighting is a new one.
+func TestFaults_OneRowPerOnset(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	f := robotmaintenance.Fault{VehicleID: "AMR-01", Code: 54013, Severity: "error", Description: "laser", RaisedAt: t0}
+	for range 2 {
+		if err := robotmaintenance.RaiseFault(db.DB, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	open, err := robotmaintenance.OpenFaults(db.DB)
+	if err != nil || !open["AMR-01"][54013] {
+		t.Fatalf("open = %v, %v", open, err)
+	}
+	if err := robotmaintenance.ClearFault(db.DB, "AMR-01", 54013, t0.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	f.RaisedAt = t0.Add(time.Hour)
+	if err := robotmaintenance.RaiseFault(db.DB, f); err != nil {
+		t.Fatal(err)
+	}
+	by, err := robotmaintenance.FaultsByCode(db.DB, t0, t0.Add(24*time.Hour))
+	if err != nil || by["AMR-01"][54013] != 2 {
+		t.Fatalf("by code = %v, %v; want 2 onsets", by, err)
+	}
+}
+
+// TestDays_DischargeCountsOnlyDrivingPairs: battery drawn and distance are
+// summed over consecutive samples off the charger. The charging pair, and a
+// pair across a gap longer than maxGap, count toward neither.
+func TestDays_DischargeCountsOnlyDrivingPairs(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := func(m int) time.Time { return t0.Add(time.Duration(m) * time.Minute) }
+	batch := []robotmaintenance.Sample{
+		{VehicleID: "AMR-01", SampledAt: at(0), BatteryLevel: 90, OdoTotalM: 10_000, BatteryCycle: 400},
+		{VehicleID: "AMR-01", SampledAt: at(5), BatteryLevel: 88, OdoTotalM: 10_600, BatteryCycle: 400},
+		{VehicleID: "AMR-01", SampledAt: at(10), BatteryLevel: 86, OdoTotalM: 11_000, BatteryCycle: 400},
+		// On the charger: not a draw.
+		{VehicleID: "AMR-01", SampledAt: at(15), BatteryLevel: 95, OdoTotalM: 11_000, BatteryCycle: 401, Charging: true},
+		{VehicleID: "AMR-01", SampledAt: at(20), BatteryLevel: 96, OdoTotalM: 11_000, BatteryCycle: 401},
+		// Still off, but two hours on: too long a gap to trust.
+		{VehicleID: "AMR-01", SampledAt: at(140), BatteryLevel: 70, OdoTotalM: 12_000, BatteryCycle: 401},
+	}
+	if err := robotmaintenance.InsertSamples(db.DB, batch); err != nil {
+		t.Fatal(err)
+	}
+	days, err := robotmaintenance.Days(db.DB, t0, t0.Add(24*time.Hour), 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := days["AMR-01"]
+	if len(d) != 1 {
+		t.Fatalf("days = %+v", d)
+	}
+	if d[0].Samples != 6 || d[0].BatteryCycle != 401 || d[0].OdoM != 12_000 {
+		t.Fatalf("day = %+v", d[0])
+	}
+	if d[0].DischargePct != 4 || math.Abs(d[0].DischargeKm-1.0) > 1e-9 {
+		t.Fatalf("discharge = %.2f%% over %.3f km, want 4%% over 1 km", d[0].DischargePct, d[0].DischargeKm)
+	}
+}
+
+// TestItems_OneOpenPerIndicatorAndServiceHistory: the hourly pass re-filing
+// a due robot refreshes the open item instead of filing another; closed
+// done with an odometer, it is where the next interval runs from.
+func TestItems_OneOpenPerIndicatorAndServiceHistory(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	it := &robotmaintenance.Item{VehicleID: "AMR-01", Indicator: "service_interval", Value: 2010, Threshold: 2000, OpenedAt: t0}
+	created, err := robotmaintenance.OpenItem(db.DB, it)
+	if err != nil || !created || it.Status != robotmaintenance.ItemOpen {
+		t.Fatalf("first: created %v %+v %v", created, it, err)
+	}
+	again := &robotmaintenance.Item{VehicleID: "AMR-01", Indicator: "service_interval", Value: 2050, Threshold: 2000, OpenedAt: t0.Add(time.Hour)}
+	if created, err := robotmaintenance.OpenItem(db.DB, again); err != nil || created || again.ID != it.ID {
+		t.Fatalf("second: created %v id %d (want %d) %v", created, again.ID, it.ID, err)
+	}
+	if err := robotmaintenance.SetHeld(db.DB, it.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	un, err := robotmaintenance.Unnotified(db.DB)
+	if err != nil || len(un) != 1 || un[0].Value != 2050 || !un[0].Held {
+		t.Fatalf("unnotified = %+v, %v", un, err)
+	}
+
+	odo := 5_100_000.0
+	closed, err := robotmaintenance.Close(db.DB, it.ID, robotmaintenance.ItemDone, "tech", "wheels, scanner", &odo, t0.Add(2*time.Hour))
+	if err != nil || closed.Status != robotmaintenance.ItemDone || closed.ClosedAt == nil {
+		t.Fatalf("close = %+v, %v", closed, err)
+	}
+	if _, err := robotmaintenance.Close(db.DB, it.ID, robotmaintenance.ItemDismissed, "tech", "", nil, t0); !errors.Is(err, robotmaintenance.ErrNotOpen) {
+		t.Fatalf("second close: %v, want ErrNotOpen", err)
+	}
+	if _, err := robotmaintenance.Close(db.DB, 999999, robotmaintenance.ItemDone, "tech", "", nil, t0); !errors.Is(err, robotmaintenance.ErrNotFound) {
+		t.Fatalf("missing: %v, want ErrNotFound", err)
+	}
+	last, err := robotmaintenance.LastService(db.DB, "service_interval")
+	if err != nil || last["AMR-01"].OdoM != odo {
+		t.Fatalf("last service = %+v, %v", last, err)
+	}
+}
@@ -108,3 +108,11 @@ func (db *DB) RecentSceneDiffs(limit int) ([]sceneversion.DiffView, error) {
 func (db *DB) LanesChangedByDiff(diffID int64) ([]string, error) {
 	return sceneversion.LanesChangedByDiff(db.DB, diffID)
 }
+
+func (db *DB) LaneEditsIn(from, to time.Time) (map[string][]sceneversion.Edit, error) {
+	return sceneversion.LaneEditsIn(db.DB, from, to)
+}
+
+func (db *DB) AreaEditsIn(from, to time.Time) (map[string][]sceneversion.Edit, error) {
+	return sceneversion.AreaEditsIn(db.DB, from, to)
+}
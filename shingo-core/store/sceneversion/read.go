@@ -266,3 +266,63 @@ func LastChange(db *sql.DB, area, lane string) (at time.Time, deltaM *float64, o
 	}
 	return at, deltaM, true, nil
 }
+
+// Edit is one change to a lane or zone: when it took effect, the diff that
+// recorded it, and how far the geometry moved (nil for a redraw).
+type Edit struct {
+	At     time.Time `json:"at"`
+	DiffID int64     `json:"diff_id"`
+	MovedM *float64  `json:"moved_m"`
+}
+
+// LaneEditsIn returns every lane change inside [from, to), oldest first per
+// lane, keyed like LanesChangedIn (area \x00 lane). A first version is not a
+// change, for the reason LastChange gives.
+func LaneEditsIn(db *sql.DB, from, to time.Time) (map[string][]Edit, error) {
+	rows, err := db.Query(
+		`SELECT area_name, lane, valid_from, diff_id, max_vertex_delta_m
+		   FROM scene_lane_versions
+		  WHERE valid_from >= $1 AND valid_from < $2
+		    AND supersedes_id IS NOT NULL
+		  ORDER BY valid_from`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("sceneversion: lane edits in window: %w", err)
+	}
+	defer rows.Close()
+	out := map[string][]Edit{}
+	for rows.Next() {
+		var area, lane string
+		var e Edit
+		if err := rows.Scan(&area, &lane, &e.At, &e.DiffID, &e.MovedM); err != nil {
+			return nil, err
+		}
+		out[area+"\x00"+lane] = append(out[area+"\x00"+lane], e)
+	}
+	return out, rows.Err()
+}
+
+// AreaEditsIn is LaneEditsIn for declared zones, keyed by the zone name as
+// the map stores it. A zone re-declared to another class is an edit with no
+// movement at all, and is as likely a cause of a new hotspot as a moved wall.
+func AreaEditsIn(db *sql.DB, from, to time.Time) (map[string][]Edit, error) {
+	rows, err := db.Query(
+		`SELECT area_name, valid_from, diff_id, max_vertex_delta_m
+		   FROM scene_areas
+		  WHERE valid_from >= $1 AND valid_from < $2
+		    AND supersedes_id IS NOT NULL
+		  ORDER BY valid_from`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("sceneversion: area edits in window: %w", err)
+	}
+	defer rows.Close()
+	out := map[string][]Edit{}
+	for rows.Next() {
+		var name string
+		var e Edit
+		if err := rows.Scan(&name, &e.At, &e.DiffID, &e.MovedM); err != nil {
+			return nil, err
+		}
+		out[name] = append(out[name], e)
+	}
+	return out, rows.Err()
+}
@@ -60,6 +60,16 @@ var migrationOnlyTables = map[string]string{
 	"style_claims":                "added by a numbered migration after the baseline was frozen",
 	"supply_refusals":             "added by a numbered migration after the baseline was frozen",
 	"bin_uop_exception":           "added by v93 — the permanent exceptions ledger (owner decision D2: no retention, ever). Migration-created rather than baseline because it carries a one-shot backfill from bin_uop_ledger that must run while the raw rows still exist",
+	"quality_holds":               "added by v97 — a quality hold is a rule over bins (one bin, a lot, a payload, a loading window), not a status on one",
+	"quality_hold_bins":           "added by v97 — every bin a hold has held, with the status it had before, so a release restores rather than guesses",
+	"quality_hold_events":         "added by v97 — the hold's audit trail, read as one sequence per hold",
+	"alerts":                      "added by v98 — one row per raised alert, deduplicated while unresolved, kept for post-incident review",
+	"shift_reports":               "added by v99 — one end-of-shift report per shift occurrence, its sections stored as written",
+	"style_cycle_times":           "added by v101 — the engineered cycle per (cell, style) OEE performance is rated against",
+	"cell_scrap_reports":          "added by v101 — scrap as reported per cell, append-only; no rows is not reported, not zero",
+	"robot_health_samples":        "added by v103 — the e-maint report kept over time, one row per robot per sample interval",
+	"robot_fault_events":          "added by v103 — one row per alarm onset, cleared_at set when the code leaves the robot",
+	"robot_maintenance_items":     "added by v103 — maintenance work items, at most one open per robot and indicator",
 	"bin_uop_delta_daily":         "added by v94 — the permanent daily roll-up of the raw delta stream (owner decision D3: growth accepted). Migration-created for the same reason as v93: the backfill must run while the raw rows still exist",
 }
 
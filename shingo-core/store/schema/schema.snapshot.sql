@@ -14,6 +14,34 @@ CREATE SEQUENCE public.admin_users_id_seq
 
 ALTER SEQUENCE public.admin_users_id_seq OWNED BY public.admin_users.id;
 
+CREATE TABLE public.alerts (
+    id bigint NOT NULL,
+    rule text NOT NULL,
+    kind text NOT NULL,
+    dedup_key text NOT NULL,
+    severity text NOT NULL,
+    subject text DEFAULT ''::text NOT NULL,
+    detail text DEFAULT ''::text NOT NULL,
+    status text DEFAULT 'open'::text NOT NULL,
+    occurrences integer DEFAULT 1 NOT NULL,
+    first_seen timestamp with time zone DEFAULT now() NOT NULL,
+    last_seen timestamp with time zone DEFAULT now() NOT NULL,
+    notified_at timestamp with time zone,
+    escalated_at timestamp with time zone,
+    acknowledged_by text DEFAULT ''::text NOT NULL,
+    acknowledged_at timestamp with time zone,
+    resolved_at timestamp with time zone
+);
+
+CREATE SEQUENCE public.alerts_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.alerts_id_seq OWNED BY public.alerts.id;
+
 CREATE TABLE public.area_confidence_daily (
     day date NOT NULL,
     area_name text NOT NULL,
@@ -223,7 +251,8 @@ CREATE TABLE public.bins (
     anomaly_at timestamp with time zone,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    anomaly_note text DEFAULT ''::text NOT NULL
+    anomaly_note text DEFAULT ''::text NOT NULL,
+    empty_since timestamp with time zone
 );
 
 CREATE SEQUENCE public.bins_id_seq
@@ -267,6 +296,25 @@ CREATE SEQUENCE public.cell_part_events_id_seq
 
 ALTER SEQUENCE public.cell_part_events_id_seq OWNED BY public.cell_part_events.id;
 
+CREATE TABLE public.cell_scrap_reports (
+    id bigint NOT NULL,
+    cell_id text NOT NULL,
+    style_id bigint DEFAULT 0 NOT NULL,
+    qty bigint NOT NULL,
+    reported_at timestamp with time zone DEFAULT now() NOT NULL,
+    reported_by text DEFAULT ''::text NOT NULL,
+    note text DEFAULT ''::text NOT NULL
+);
+
+CREATE SEQUENCE public.cell_scrap_reports_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.cell_scrap_reports_id_seq OWNED BY public.cell_scrap_reports.id;
+
 CREATE TABLE public.cell_targets (
     cell_id text NOT NULL,
     payload_code text DEFAULT ''::text NOT NULL,
@@ -913,6 +961,62 @@ CREATE TABLE public.production_tick_dedup (
     applied_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
+CREATE TABLE public.quality_hold_bins (
+    hold_id bigint NOT NULL,
+    bin_id bigint NOT NULL,
+    prior_status text NOT NULL,
+    held_at timestamp with time zone DEFAULT now() NOT NULL,
+    resolved_at timestamp with time zone,
+    outcome text DEFAULT ''::text NOT NULL
+);
+
+CREATE TABLE public.quality_hold_events (
+    id bigint NOT NULL,
+    hold_id bigint NOT NULL,
+    action text NOT NULL,
+    actor text DEFAULT ''::text NOT NULL,
+    detail text DEFAULT ''::text NOT NULL,
+    at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE SEQUENCE public.quality_hold_events_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.quality_hold_events_id_seq OWNED BY public.quality_hold_events.id;
+
+CREATE TABLE public.quality_holds (
+    id bigint NOT NULL,
+    reason_code text NOT NULL,
+    note text DEFAULT ''::text NOT NULL,
+    scope_kind text NOT NULL,
+    bin_id bigint,
+    lot_code text DEFAULT ''::text NOT NULL,
+    payload_code text DEFAULT ''::text NOT NULL,
+    loaded_from timestamp with time zone,
+    loaded_to timestamp with time zone,
+    status text DEFAULT 'active'::text NOT NULL,
+    disposition text DEFAULT ''::text NOT NULL,
+    requested_by text DEFAULT ''::text NOT NULL,
+    requested_at timestamp with time zone,
+    approved_by text DEFAULT ''::text NOT NULL,
+    created_by text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    closed_at timestamp with time zone
+);
+
+CREATE SEQUENCE public.quality_holds_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.quality_holds_id_seq OWNED BY public.quality_holds.id;
+
 CREATE TABLE public.recovery_actions (
     id bigint NOT NULL,
     action text NOT NULL,
@@ -1027,6 +1131,76 @@ CREATE SEQUENCE public.robot_confidence_samples_id_seq
 
 ALTER SEQUENCE public.robot_confidence_samples_id_seq OWNED BY public.robot_confidence_samples.id;
 
+CREATE TABLE public.robot_fault_events (
+    id bigint NOT NULL,
+    vehicle_id text NOT NULL,
+    code integer NOT NULL,
+    severity text DEFAULT ''::text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    raised_at timestamp with time zone NOT NULL,
+    cleared_at timestamp with time zone
+);
+
+CREATE SEQUENCE public.robot_fault_events_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.robot_fault_events_id_seq OWNED BY public.robot_fault_events.id;
+
+CREATE TABLE public.robot_health_samples (
+    id bigint NOT NULL,
+    vehicle_id text NOT NULL,
+    sampled_at timestamp with time zone NOT NULL,
+    battery_level double precision NOT NULL,
+    battery_cycle integer DEFAULT 0 NOT NULL,
+    battery_temp double precision DEFAULT 0 NOT NULL,
+    battery_v double precision DEFAULT 0 NOT NULL,
+    charging boolean NOT NULL,
+    odo_total_m double precision NOT NULL,
+    runtime_ms bigint DEFAULT 0 NOT NULL,
+    lift_count integer DEFAULT 0 NOT NULL,
+    ctrl_temp double precision DEFAULT 0 NOT NULL
+);
+
+CREATE SEQUENCE public.robot_health_samples_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.robot_health_samples_id_seq OWNED BY public.robot_health_samples.id;
+
+CREATE TABLE public.robot_maintenance_items (
+    id bigint NOT NULL,
+    vehicle_id text NOT NULL,
+    indicator text NOT NULL,
+    status text DEFAULT 'open'::text NOT NULL,
+    value double precision NOT NULL,
+    threshold double precision NOT NULL,
+    detail text DEFAULT ''::text NOT NULL,
+    held boolean DEFAULT false NOT NULL,
+    opened_at timestamp with time zone NOT NULL,
+    notified_at timestamp with time zone,
+    closed_at timestamp with time zone,
+    closed_by text DEFAULT ''::text NOT NULL,
+    note text DEFAULT ''::text NOT NULL,
+    odometer_m double precision,
+    CONSTRAINT robot_maintenance_items_status_check CHECK ((status = ANY (ARRAY['open'::text, 'done'::text, 'dismissed'::text])))
+);
+
+CREATE SEQUENCE public.robot_maintenance_items_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.robot_maintenance_items_id_seq OWNED BY public.robot_maintenance_items.id;
+
 CREATE TABLE public.scene_areas (
     id bigint NOT NULL,
     area_name text NOT NULL,
@@ -1207,6 +1381,27 @@ CREATE TABLE public.schema_migrations (
     applied_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
+CREATE TABLE public.shift_reports (
+    id bigint NOT NULL,
+    shift text NOT NULL,
+    shift_day date NOT NULL,
+    timezone text DEFAULT ''::text NOT NULL,
+    window_start timestamp with time zone NOT NULL,
+    window_end timestamp with time zone NOT NULL,
+    body jsonb DEFAULT '{}'::jsonb NOT NULL,
+    generated_at timestamp with time zone DEFAULT now() NOT NULL,
+    notified_at timestamp with time zone
+);
+
+CREATE SEQUENCE public.shift_reports_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE public.shift_reports_id_seq OWNED BY public.shift_reports.id;
+
 CREATE TABLE public.sourceability_events (
     id bigint NOT NULL,
     process_key text NOT NULL,
@@ -1245,6 +1440,15 @@ CREATE TABLE public.style_claims (
     seq integer DEFAULT 0 NOT NULL
 );
 
+CREATE TABLE public.style_cycle_times (
+    cell_id text NOT NULL,
+    style_id bigint NOT NULL,
+    cycle_ms bigint NOT NULL,
+    updated_by text DEFAULT ''::text NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT style_cycle_times_cycle_ms_check CHECK ((cycle_ms > 0))
+);
+
 CREATE TABLE public.supply_refusals (
     id bigint NOT NULL,
     loader_node text NOT NULL,
@@ -1293,6 +1497,8 @@ ALTER SEQUENCE public.test_commands_id_seq OWNED BY public.test_commands.id;
 
 ALTER TABLE ONLY public.admin_users ALTER COLUMN id SET DEFAULT nextval('public.admin_users_id_seq'::regclass);
 
+ALTER TABLE ONLY public.alerts ALTER COLUMN id SET DEFAULT nextval('public.alerts_id_seq'::regclass);
+
 ALTER TABLE ONLY public.audit_log ALTER COLUMN id SET DEFAULT nextval('public.audit_log_id_seq'::regclass);
 
 ALTER TABLE ONLY public.bin_loaders ALTER COLUMN id SET DEFAULT nextval('public.bin_loaders_id_seq'::regclass);
@@ -1307,6 +1513,8 @@ ALTER TABLE ONLY public.bins ALTER COLUMN id SET DEFAULT nextval('public.bins_id
 
 ALTER TABLE ONLY public.cell_part_events ALTER COLUMN id SET DEFAULT nextval('public.cell_part_events_id_seq'::regclass);
 
+ALTER TABLE ONLY public.cell_scrap_reports ALTER COLUMN id SET DEFAULT nextval('public.cell_scrap_reports_id_seq'::regclass);
+
 ALTER TABLE ONLY public.cms_transactions ALTER COLUMN id SET DEFAULT nextval('public.cms_transactions_id_seq'::regclass);
 
 ALTER TABLE ONLY public.corrections ALTER COLUMN id SET DEFAULT nextval('public.corrections_id_seq'::regclass);
@@ -1345,6 +1553,10 @@ ALTER TABLE ONLY public.payload_manifest ALTER COLUMN id SET DEFAULT nextval('pu
 
 ALTER TABLE ONLY public.payloads ALTER COLUMN id SET DEFAULT nextval('public.payloads_id_seq'::regclass);
 
+ALTER TABLE ONLY public.quality_hold_events ALTER COLUMN id SET DEFAULT nextval('public.quality_hold_events_id_seq'::regclass);
+
+ALTER TABLE ONLY public.quality_holds ALTER COLUMN id SET DEFAULT nextval('public.quality_holds_id_seq'::regclass);
+
 ALTER TABLE ONLY public.recovery_actions ALTER COLUMN id SET DEFAULT nextval('public.recovery_actions_id_seq'::regclass);
 
 ALTER TABLE ONLY public.reservations ALTER COLUMN id SET DEFAULT nextval('public.reservations_id_seq'::regclass);
@@ -1353,6 +1565,12 @@ ALTER TABLE ONLY public.robot_confidence_low ALTER COLUMN id SET DEFAULT nextval
 
 ALTER TABLE ONLY public.robot_confidence_samples ALTER COLUMN id SET DEFAULT nextval('public.robot_confidence_samples_id_seq'::regclass);
 
+ALTER TABLE ONLY public.robot_fault_events ALTER COLUMN id SET DEFAULT nextval('public.robot_fault_events_id_seq'::regclass);
+
+ALTER TABLE ONLY public.robot_health_samples ALTER COLUMN id SET DEFAULT nextval('public.robot_health_samples_id_seq'::regclass);
+
+ALTER TABLE ONLY public.robot_maintenance_items ALTER COLUMN id SET DEFAULT nextval('public.robot_maintenance_items_id_seq'::regclass);
+
 ALTER TABLE ONLY public.scene_areas ALTER COLUMN id SET DEFAULT nextval('public.scene_areas_id_seq'::regclass);
 
 ALTER TABLE ONLY public.scene_diffs ALTER COLUMN id SET DEFAULT nextval('public.scene_diffs_id_seq'::regclass);
@@ -1367,6 +1585,8 @@ ALTER TABLE ONLY public.scene_points ALTER COLUMN id SET DEFAULT nextval('public
 
 ALTER TABLE ONLY public.scene_reflectors ALTER COLUMN id SET DEFAULT nextval('public.scene_reflectors_id_seq'::regclass);
 
+ALTER TABLE ONLY public.shift_reports ALTER COLUMN id SET DEFAULT nextval('public.shift_reports_id_seq'::regclass);
+
 ALTER TABLE ONLY public.sourceability_events ALTER COLUMN id SET DEFAULT nextval('public.sourceability_events_id_seq'::regclass);
 
 ALTER TABLE ONLY public.supply_refusals ALTER COLUMN id SET DEFAULT nextval('public.supply_refusals_id_seq'::regclass);
@@ -1379,6 +1599,9 @@ ALTER TABLE ONLY public.admin_users
 ALTER TABLE ONLY public.admin_users
     ADD CONSTRAINT admin_users_username_key UNIQUE (username);
 
+ALTER TABLE ONLY public.alerts
+    ADD CONSTRAINT alerts_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY public.area_confidence_daily
     ADD CONSTRAINT area_confidence_daily_pkey PRIMARY KEY (day, area_name);
 
@@ -1421,6 +1644,9 @@ ALTER TABLE ONLY public.bins
 ALTER TABLE ONLY public.cell_config
     ADD CONSTRAINT cell_config_pkey PRIMARY KEY (cell_id);
 
+ALTER TABLE ONLY public.cell_scrap_reports
+    ADD CONSTRAINT cell_scrap_reports_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY public.cell_targets
     ADD CONSTRAINT cell_targets_pkey PRIMARY KEY (cell_id, payload_code);
 
@@ -1559,6 +1785,15 @@ ALTER TABLE ONLY public.process_styles
 ALTER TABLE ONLY public.production_tick_dedup
     ADD CONSTRAINT production_tick_dedup_pkey PRIMARY KEY (station, edge_snapshot_id);
 
+ALTER TABLE ONLY public.quality_hold_bins
+    ADD CONSTRAINT quality_hold_bins_pkey PRIMARY KEY (hold_id, bin_id);
+
+ALTER TABLE ONLY public.quality_hold_events
+    ADD CONSTRAINT quality_hold_events_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.quality_holds
+    ADD CONSTRAINT quality_holds_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY public.recovery_actions
     ADD CONSTRAINT recovery_actions_pkey PRIMARY KEY (id);
 
@@ -1568,6 +1803,15 @@ ALTER TABLE ONLY public.reservations
 ALTER TABLE ONLY public.robot_confidence_daily
     ADD CONSTRAINT robot_confidence_daily_pkey PRIMARY KEY (day, vehicle_id);
 
+ALTER TABLE ONLY public.robot_fault_events
+    ADD CONSTRAINT robot_fault_events_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.robot_health_samples
+    ADD CONSTRAINT robot_health_samples_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.robot_maintenance_items
+    ADD CONSTRAINT robot_maintenance_items_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY public.scene_areas
     ADD CONSTRAINT scene_areas_pkey PRIMARY KEY (id);
 
@@ -1601,9 +1845,18 @@ ALTER TABLE ONLY public.scene_reflectors
 ALTER TABLE ONLY public.schema_migrations
     ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
 
+ALTER TABLE ONLY public.shift_reports
+    ADD CONSTRAINT shift_reports_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.shift_reports
+    ADD CONSTRAINT shift_reports_shift_window_start_key UNIQUE (shift, window_start);
+
 ALTER TABLE ONLY public.sourceability_events
     ADD CONSTRAINT sourceability_events_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY public.style_cycle_times
+    ADD CONSTRAINT style_cycle_times_pkey PRIMARY KEY (cell_id, style_id);
+
 ALTER TABLE ONLY public.supply_refusals
     ADD CONSTRAINT supply_refusals_pkey PRIMARY KEY (id);
 
@@ -1614,8 +1867,14 @@ CREATE INDEX cell_config_station_idx ON public.cell_config USING btree (station)
 
 CREATE UNIQUE INDEX edge_registry_station_uid_key ON public.edge_registry USING btree (station_uid) WHERE (station_uid <> ''::text);
 
+CREATE INDEX idx_alerts_first_seen ON public.alerts USING btree (first_seen DESC);
+
+CREATE UNIQUE INDEX idx_alerts_unresolved ON public.alerts USING btree (rule, dedup_key) WHERE (resolved_at IS NULL);
+
 CREATE INDEX idx_area_confidence_daily_area ON public.area_confidence_daily USING btree (area_name, day DESC);
 
+CREATE INDEX idx_audit_bin_time ON public.audit_log USING btree (created_at) WHERE (entity_type = 'bin'::text);
+
 CREATE INDEX idx_audit_entity ON public.audit_log USING btree (entity_type, entity_id);
 
 CREATE INDEX idx_bin_loader_homes_loader ON public.bin_loader_homes USING btree (loader_id);
@@ -1648,6 +1907,8 @@ CREATE INDEX idx_bins_type ON public.bins USING btree (bin_type_id);
 
 CREATE INDEX idx_cell_part_events_cell_time ON ONLY public.cell_part_events USING btree (cell_id, recorded_at);
 
+CREATE INDEX idx_cell_scrap_reports_cell_time ON public.cell_scrap_reports USING btree (cell_id, reported_at);
+
 CREATE INDEX idx_cms_txn_created ON public.cms_transactions USING btree (created_at);
 
 CREATE INDEX idx_cms_txn_node ON public.cms_transactions USING btree (node_id);
@@ -1704,6 +1965,12 @@ CREATE INDEX idx_outbox_pending ON public.outbox USING btree (sent_at) WHERE (se
 
 CREATE INDEX idx_payload_manifest_payload ON public.payload_manifest USING btree (payload_id);
 
+CREATE INDEX idx_quality_hold_bins_open ON public.quality_hold_bins USING btree (bin_id) WHERE (resolved_at IS NULL);
+
+CREATE INDEX idx_quality_hold_events_hold ON public.quality_hold_events USING btree (hold_id, at);
+
+CREATE INDEX idx_quality_holds_open ON public.quality_holds USING btree (status) WHERE (status <> 'closed'::text);
+
 CREATE INDEX idx_recovery_actions_created ON public.recovery_actions USING btree (created_at);
 
 CREATE INDEX idx_reservations_bin ON public.reservations USING btree (bin_id);
@@ -1720,6 +1987,16 @@ CREATE INDEX idx_robot_confidence_samples_time ON ONLY public.robot_confidence_s
 
 CREATE INDEX idx_robot_confidence_samples_vehicle_time ON ONLY public.robot_confidence_samples USING btree (vehicle_id, sampled_at);
 
+CREATE UNIQUE INDEX idx_robot_fault_events_open ON public.robot_fault_events USING btree (vehicle_id, code) WHERE (cleared_at IS NULL);
+
+CREATE INDEX idx_robot_fault_events_vehicle_time ON public.robot_fault_events USING btree (vehicle_id, raised_at);
+
+CREATE INDEX idx_robot_health_samples_vehicle_time ON public.robot_health_samples USING btree (vehicle_id, sampled_at);
+
+CREATE UNIQUE INDEX idx_robot_maintenance_items_open ON public.robot_maintenance_items USING btree (vehicle_id, indicator) WHERE (status = 'open'::text);
+
+CREATE INDEX idx_robot_maintenance_items_vehicle ON public.robot_maintenance_items USING btree (vehicle_id, opened_at);
+
 CREATE INDEX idx_scene_areas_current ON public.scene_areas USING btree (area_name, valid_from DESC);
 
 CREATE UNIQUE INDEX idx_scene_areas_one_open ON public.scene_areas USING btree (area_name) WHERE (valid_to IS NULL);
@@ -1738,6 +2015,8 @@ CREATE INDEX idx_scene_points_class ON public.scene_points USING btree (class_na
 
 CREATE INDEX idx_scene_reflectors_current ON public.scene_reflectors USING btree (shape_hash, valid_from DESC);
 
+CREATE INDEX idx_shift_reports_window_end ON public.shift_reports USING btree (window_end DESC);
+
 CREATE INDEX idx_sourceability_events_key_time ON public.sourceability_events USING btree (process_key, style_id, observed_at DESC);
 
 CREATE INDEX idx_sourceability_events_payload_time ON public.sourceability_events USING btree (missing_payload, observed_at DESC) WHERE (missing_payload <> ''::text);
@@ -1866,6 +2145,12 @@ ALTER TABLE ONLY public.payload_bin_types
 ALTER TABLE ONLY public.payload_manifest
     ADD CONSTRAINT payload_manifest_payload_id_fkey FOREIGN KEY (payload_id) REFERENCES public.payloads(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY public.quality_hold_bins
+    ADD CONSTRAINT quality_hold_bins_hold_id_fkey FOREIGN KEY (hold_id) REFERENCES public.quality_holds(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.quality_hold_events
+    ADD CONSTRAINT quality_hold_events_hold_id_fkey FOREIGN KEY (hold_id) REFERENCES public.quality_holds(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY public.reservations
     ADD CONSTRAINT reservations_bin_id_fkey FOREIGN KEY (bin_id) REFERENCES public.bins(id);
 
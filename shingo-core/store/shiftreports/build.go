@@ -0,0 +1,207 @@
+package shiftreports
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shingocore/domain"
+	"shingocore/store/messaging"
+)
+
+// Build totals the shift [start, end) into a report body. Each section is one
+// query; the window bounds every one of them the same way, so the sections
+// add up against each other.
+//
+// The sections read the tables their dashboards read — orders for outcomes
+// (terminal on COALESCE(completed_at, updated_at), as /missions/stats v2),
+// mission_telemetry for delivery times, downtime_events, order_history's
+// faulted rows, corrections and the outbox — so a number on the report can be
+// checked against the page it would otherwise have been copied from.
+func Build(db *sql.DB, start, end time.Time) (domain.ShiftReportBody, error) {
+	var (
+		b   domain.ShiftReportBody
+		err error
+	)
+	s, e := start.UTC(), end.UTC()
+	if b.Orders, err = orderCounts(db, s, e); err != nil {
+		return b, err
+	}
+	if b.Delivery, err = deliveryTimes(db, s, e); err != nil {
+		return b, err
+	}
+	if b.Downtime, err = downtimeMinutes(db, s, e); err != nil {
+		return b, err
+	}
+	if b.Faults, err = faultsByRobot(db, s, e); err != nil {
+		return b, err
+	}
+	if b.Corrections, err = correctionCounts(db, s, e); err != nil {
+		return b, err
+	}
+	err = db.QueryRow(`SELECT
+			COUNT(*) FILTER (WHERE created_at >= $2 AND created_at < $3),
+			COUNT(*)
+		FROM outbox WHERE sent_at IS NULL AND retries >= $1`,
+		messaging.MaxOutboxRetries, s, e).Scan(&b.DeadLetters, &b.DeadLettersOpen)
+	if err != nil {
+		return b, fmt.Errorf("shift report dead letters: %w", err)
+	}
+	return b, nil
+}
+
+// orderCounts is created and terminal counts per order type. The two halves
+// window on different instants — an order is counted where it was created and
+// again where it finished — so they are computed apart and joined on type.
+func orderCounts(db *sql.DB, start, end time.Time) ([]domain.ShiftOrders, error) {
+	rows, err := db.Query(`
+		WITH created AS (
+			SELECT order_type, COUNT(*) AS n
+			  FROM orders WHERE created_at >= $1 AND created_at < $2
+			 GROUP BY order_type
+		), finished AS (
+			SELECT order_type,
+			       COUNT(*) FILTER (WHERE status = 'confirmed')                AS confirmed,
+			       COUNT(*) FILTER (WHERE status = 'failed')                   AS failed,
+			       COUNT(*) FILTER (WHERE status IN ('cancelled','canceled'))  AS cancelled,
+			       COUNT(*) FILTER (WHERE status = 'skipped')                  AS skipped
+			  FROM orders
+			 WHERE status IN ('confirmed','failed','cancelled','canceled','skipped')
+			   AND COALESCE(completed_at, updated_at) >= $1
+			   AND COALESCE(completed_at, updated_at) <  $2
+			 GROUP BY order_type
+		)
+		SELECT COALESCE(c.order_type, f.order_type), COALESCE(c.n, 0),
+		       COALESCE(f.confirmed, 0), COALESCE(f.failed, 0),
+		       COALESCE(f.cancelled, 0), COALESCE(f.skipped, 0)
+		  FROM created c FULL JOIN finished f ON f.order_type = c.order_type
+		 ORDER BY 1`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("shift report orders: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ShiftOrders
+	for rows.Next() {
+		var o domain.ShiftOrders
+		if err := rows.Scan(&o.Type, &o.Created, &o.Confirmed, &o.Failed, &o.Cancelled, &o.Skipped); err != nil {
+			return nil, fmt.Errorf("shift report orders: %w", err)
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+// deliveryTimes is lead time (created → terminal) over the missions that
+// completed in the shift, per order type plus the all-types row (ROLLUP's
+// NULL type, returned as "" and first). Nonpositive durations are the sim's
+// clock drift, excluded as the dashboard excludes them.
+func deliveryTimes(db *sql.DB, start, end time.Time) ([]domain.ShiftDelivery, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(order_type, ''), COUNT(*),
+		       (COALESCE(AVG(duration_ms), 0) / 1000.0)::float8,
+		       (COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY duration_ms), 0) / 1000.0)::float8
+		  FROM mission_telemetry
+		 WHERE core_completed >= $1 AND core_completed < $2
+		   AND terminal_state IN ('FINISHED', 'delivered', 'confirmed')
+		   AND duration_ms > 0
+		 GROUP BY ROLLUP (order_type)
+		 ORDER BY order_type NULLS FIRST`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("shift report delivery times: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ShiftDelivery
+	for rows.Next() {
+		var d domain.ShiftDelivery
+		if err := rows.Scan(&d.Type, &d.Count, &d.AvgSeconds, &d.P90Seconds); err != nil {
+			return nil, fmt.Errorf("shift report delivery times: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// downtimeMinutes is minutes down per cell and reason, clipped to the shift.
+//
+// An outage is two rows — the "down" row (duration 0, no real end) and the
+// "up" row carrying ended_at — sharing station, plc and started_at, so they
+// are paired first. An outage with no up row yet is still down and counts to
+// the end of the shift. The started_at floor keeps the scan on the recent
+// partitions; an outage older than a week is not a shift's downtime.
+func downtimeMinutes(db *sql.DB, start, end time.Time) ([]domain.ShiftDowntime, error) {
+	rows, err := db.Query(`
+		WITH outages AS (
+			SELECT station, reason, started_at,
+			       MAX(ended_at) FILTER (WHERE duration_ms > 0) AS ended_at
+			  FROM downtime_events
+			 WHERE started_at < $2 AND started_at >= $1::timestamptz - INTERVAL '7 days'
+			 GROUP BY station, plc_name, reason, started_at
+		)
+		SELECT station, reason, COUNT(*),
+		       (SUM(EXTRACT(EPOCH FROM (LEAST(COALESCE(ended_at, $2), $2) - GREATEST(started_at, $1)))) / 60.0)::float8
+		  FROM outages
+		 WHERE COALESCE(ended_at, $2) > $1
+		 GROUP BY station, reason
+		 ORDER BY 4 DESC, station, reason`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("shift report downtime: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ShiftDowntime
+	for rows.Next() {
+		var d domain.ShiftDowntime
+		if err := rows.Scan(&d.Station, &d.Reason, &d.Events, &d.Minutes); err != nil {
+			return nil, fmt.Errorf("shift report downtime: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// faultsByRobot counts faulted transitions in the shift per robot, and the
+// orders they were on. An order faulting three times is three faults and one
+// order — both numbers matter, and neither reads as the other.
+func faultsByRobot(db *sql.DB, start, end time.Time) ([]domain.ShiftFaults, error) {
+	rows, err := db.Query(`
+		SELECT o.robot_id, COUNT(*), COUNT(DISTINCT h.order_id)
+		  FROM order_history h JOIN orders o ON o.id = h.order_id
+		 WHERE h.status = 'faulted' AND h.created_at >= $1 AND h.created_at < $2
+		 GROUP BY o.robot_id
+		 ORDER BY 2 DESC, 1`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("shift report faults: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ShiftFaults
+	for rows.Next() {
+		var f domain.ShiftFaults
+		if err := rows.Scan(&f.RobotID, &f.Faults, &f.Orders); err != nil {
+			return nil, fmt.Errorf("shift report faults: %w", err)
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// correctionCounts is inventory corrections per type, with net quantity.
+func correctionCounts(db *sql.DB, start, end time.Time) ([]domain.ShiftCorrections, error) {
+	rows, err := db.Query(`
+		SELECT correction_type, COUNT(*), COALESCE(SUM(quantity), 0)
+		  FROM corrections
+		 WHERE created_at >= $1 AND created_at < $2
+		 GROUP BY correction_type
+		 ORDER BY 2 DESC, 1`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("shift report corrections: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.ShiftCorrections
+	for rows.Next() {
+		var c domain.ShiftCorrections
+		if err := rows.Scan(&c.Type, &c.Count, &c.Quantity); err != nil {
+			return nil, fmt.Errorf("shift report corrections: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,172 @@
+// Package shiftreports is the persistence layer for end-of-shift operations
+// reports (v99): the queries that total a shift (build.go), and the archive
+// the finished reports are kept in.
+//
+// A report is built once, when its shift ends, and stored as written. The
+// (shift, window_start) key makes Save idempotent, so a pass that races
+// another, or a restart inside the report delay, files nothing twice.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; shingocore/shiftreport decides which windows have ended, and
+// service/shift_report_service.go wraps these for the engine and the www
+// handlers.
+package shiftreports
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"shingocore/domain"
+)
+
+// Report is one stored shift report.
+type Report = domain.ShiftReport
+
+// ErrNotFound is returned when no report has the id.
+var ErrNotFound = errors.New("shift report not found")
+
+const selectCols = `id, shift, shift_day, timezone, window_start, window_end, body, generated_at, notified_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface{ Scan(...any) error }
+
+func scanReport(s rowScanner) (*Report, error) {
+	var (
+		r        Report
+		day      time.Time
+		body     []byte
+		notified sql.NullTime
+	)
+	if err := s.Scan(&r.ID, &r.Shift, &day, &r.Timezone, &r.Start, &r.End, &body,
+		&r.GeneratedAt, &notified); err != nil {
+		return nil, err
+	}
+	r.Day = day.Format("2006-01-02")
+	if notified.Valid {
+		t := notified.Time
+		r.NotifiedAt = &t
+	}
+	if err := json.Unmarshal(body, &r.ShiftReportBody); err != nil {
+		return nil, fmt.Errorf("shift report %d body: %w", r.ID, err)
+	}
+	return &r, nil
+}
+
+func scanReports(rows *sql.Rows) ([]*Report, error) {
+	defer rows.Close()
+	var out []*Report
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan shift report: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// Exists reports whether the shift's report for the window starting at start
+// is already filed.
+func Exists(db *sql.DB, shift string, start time.Time) (bool, error) {
+	var ok bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM shift_reports WHERE shift = $1 AND window_start = $2)`,
+		shift, start.UTC()).Scan(&ok)
+	return ok, err
+}
+
+// Save files r and fills in its id. A report for the same shift and window
+// already on file wins: created is false and r is left as it was.
+func Save(db *sql.DB, r *Report) (created bool, err error) {
+	body, err := json.Marshal(r.ShiftReportBody)
+	if err != nil {
+		return false, fmt.Errorf("encode shift report: %w", err)
+	}
+	err = db.QueryRow(`INSERT INTO shift_reports
+			(shift, shift_day, timezone, window_start, window_end, body, generated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (shift, window_start) DO NOTHING
+		RETURNING id`,
+		r.Shift, r.Day, r.Timezone, r.Start.UTC(), r.End.UTC(), body, r.GeneratedAt.UTC()).Scan(&r.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("save shift report %s %s: %w", r.Day, r.Shift, err)
+	}
+	return true, nil
+}
+
+// Get returns one report.
+func Get(db *sql.DB, id int64) (*Report, error) {
+	r, err := scanReport(db.QueryRow(`SELECT `+selectCols+` FROM shift_reports WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return r, err
+}
+
+// Filter narrows List. Zero fields do not filter.
+type Filter struct {
+	Shift string
+	// Since and Until bound window_start, [Since, Until).
+	Since time.Time
+	Until time.Time
+	// Limit defaults to 100 and is capped at 1000.
+	Limit int
+}
+
+// List returns reports, latest shift first.
+func List(db *sql.DB, f Filter) ([]*Report, error) {
+	q := `SELECT ` + selectCols + ` FROM shift_reports WHERE TRUE`
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		q += fmt.Sprintf(cond, len(args))
+	}
+	if f.Shift != "" {
+		add(" AND shift = $%d", f.Shift)
+	}
+	if !f.Since.IsZero() {
+		add(" AND window_start >= $%d", f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		add(" AND window_start < $%d", f.Until.UTC())
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	add(" ORDER BY window_end DESC, shift LIMIT $%d", limit)
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list shift reports: %w", err)
+	}
+	return scanReports(rows)
+}
+
+// Unnotified lists reports whose window ended after since and whose
+// notification has not gone out, oldest first. The bound keeps a plant that
+// turns notifications on from receiving its whole archive.
+func Unnotified(db *sql.DB, since time.Time) ([]*Report, error) {
+	rows, err := db.Query(`SELECT `+selectCols+` FROM shift_reports
+		WHERE notified_at IS NULL AND window_end > $1
+		ORDER BY window_end, shift`, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("unnotified shift reports: %w", err)
+	}
+	return scanReports(rows)
+}
+
+// MarkNotified records that the report was handed to the channels.
+func MarkNotified(db *sql.DB, id int64, now time.Time) error {
+	if _, err := db.Exec(`UPDATE shift_reports SET notified_at = $2 WHERE id = $1`, id, now.UTC()); err != nil {
+		return fmt.Errorf("mark shift report %d notified: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build docker
+
+package shiftreports_test
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/domain"
+	"shingocore/internal/testdb"
+	"shingocore/store/shiftreports"
+)
+
+// TestSave_IsOncePerWindowAndRoundTrips: the first save of a window files it
+// with its body intact; a second save of the same shift and start files
+// nothing, so a racing pass cannot file the night twice. Notification is
+// tracked per report.
+func TestSave_IsOncePerWindowAndRoundTrips(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	start := time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)
+	r := &shiftreports.Report{
+		Shift: "3rd", Day: "2026-10-17", Timezone: "America/Chicago",
+		Start: start, End: start.Add(8 * time.Hour), GeneratedAt: start.Add(8*time.Hour + 5*time.Minute),
+		ShiftReportBody: domain.ShiftReportBody{
+			Orders:      []domain.ShiftOrders{{Type: "retrieve", Created: 40, Confirmed: 37, Failed: 2}},
+			Delivery:    []domain.ShiftDelivery{{Count: 37, AvgSeconds: 301.5, P90Seconds: 522}},
+			DeadLetters: 1,
+		},
+	}
+	created, err := shiftreports.Save(db.DB, r)
+	if err != nil || !created || r.ID == 0 {
+		t.Fatalf("first save: created %v id %d err %v", created, r.ID, err)
+	}
+	dup := *r
+	dup.ID = 0
+	if created, err := shiftreports.Save(db.DB, &dup); err != nil || created {
+		t.Fatalf("second save: created %v err %v; want nothing filed", created, err)
+	}
+
+	got, err := shiftreports.Get(db.DB, r.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Day != "2026-10-17" || !got.Start.Equal(start) || got.TotalOrders().Confirmed != 37 ||
+		got.OverallDelivery().P90Seconds != 522 || got.DeadLetters != 1 {
+		t.Errorf("round trip = %+v", got)
+	}
+
+	pending, err := shiftreports.Unnotified(db.DB, start)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("unnotified: %d, err %v; want 1", len(pending), err)
+	}
+	if err := shiftreports.MarkNotified(db.DB, r.ID, start.Add(9*time.Hour)); err != nil {
+		t.Fatalf("mark notified: %v", err)
+	}
+	if pending, _ := shiftreports.Unnotified(db.DB, start); len(pending) != 0 {
+		t.Errorf("after marking: %d unnotified, want 0", len(pending))
+	}
+	if list, err := shiftreports.List(db.DB, shiftreports.Filter{Shift: "3rd"}); err != nil || len(list) != 1 {
+		t.Errorf("list: %d, err %v; want 1", len(list), err)
+	}
+}
+
+// TestBuild_ClipsDowntimeToTheShift: an outage that began before the shift
+// counts only its minutes inside it, and one still down at the end counts to
+// the end — the report is a shift's downtime, not the outages that touched it.
+func TestBuild_ClipsDowntimeToTheShift(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	start := time.Now().UTC().Truncate(time.Hour).Add(-10 * time.Hour)
+	end := start.Add(8 * time.Hour)
+	if err := db.EnsureDowntimePartitionsRange(start.Add(-24*time.Hour), end); err != nil {
+		t.Fatalf("partitions: %v", err)
+	}
+	insert := func(plc string, started, ended time.Time, id int64) {
+		t.Helper()
+		var durMS int64
+		if !ended.IsZero() {
+			durMS = ended.Sub(started).Milliseconds()
+		}
+		if _, err := db.Exec(`INSERT INTO downtime_events (station, plc_name, reason, started_at, ended_at, duration_ms, edge_event_id)
+			VALUES ('line-1', $1, 'breakdown', $2, $3, $4, $5)`, plc, started, ended, durMS, id); err != nil {
+			t.Fatalf("insert downtime: %v", err)
+		}
+	}
+	// 30 minutes before the shift to 20 minutes into it: 20 minutes count.
+	early := start.Add(-30 * time.Minute)
+	insert("press", early, time.Time{}, 1)
+	insert("press", early, start.Add(20*time.Minute), 2)
+	// Down 10 minutes before the end and never back up: 10 minutes count.
+	insert("weld", end.Add(-10*time.Minute), time.Time{}, 3)
+
+	b, err := shiftreports.Build(db.DB, start, end)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(b.Downtime) != 1 || b.Downtime[0].Events != 2 {
+		t.Fatalf("downtime = %+v; want one line-1/breakdown row over two outages", b.Downtime)
+	}
+	if m := b.Downtime[0].Minutes; m < 29.9 || m > 30.1 {
+		t.Errorf("downtime minutes = %.2f, want 30", m)
+	}
+}
@@ -0,0 +1,94 @@
+// Package starvation is the persistence layer for starvation root-cause
+// attribution: the two reads domain.AttributeStarvation needs beyond the OEE
+// window queries (store/oee) — every consume episode's child-order history,
+// and the spans each edge was reported offline.
+//
+// Like store/oee, nothing here classifies anything. The status-to-cause table
+// is domain.CauseOfStatus's, tested without Postgres.
+//
+// Convention (see store/store.go): persistence logic lives here as functions on
+// *sql.DB; service/starvation_service.go wraps these for the www handlers.
+package starvation
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shingo/protocol"
+	"shingocore/domain"
+)
+
+// OrderHistory returns, per cell and then per child order, the status history
+// of every order raised by a consume episode open at any point in [start,
+// end). Rows are oldest first and stop at end; rows from before start are
+// kept, because the state an order was already in when the window opened is
+// what the window's first minutes are attributed to.
+func OrderHistory(db *sql.DB, start, end time.Time) (map[string]map[int64][]domain.OrderStatusRow, error) {
+	rows, err := db.Query(`
+		SELECT d.station_id, h.order_id, h.status, COALESCE(h.code, ''), h.created_at
+		  FROM demand_origins d
+		  JOIN orders o ON o.origin_id = d.origin_id
+		  JOIN order_history h ON h.order_id = o.id
+		 WHERE d.direction = $3 AND d.station_id <> ''
+		   AND d.opened_at < $2 AND COALESCE(d.closed_at, $2) > $1
+		   AND h.created_at < $2
+		 ORDER BY d.station_id, h.order_id, h.created_at, h.id`,
+		start.UTC(), end.UTC(), string(protocol.ClaimRoleConsume))
+	if err != nil {
+		return nil, fmt.Errorf("starvation order history: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]map[int64][]domain.OrderStatusRow)
+	for rows.Next() {
+		var (
+			cell  string
+			order int64
+			r     domain.OrderStatusRow
+		)
+		if err := rows.Scan(&cell, &order, &r.Status, &r.Code, &r.At); err != nil {
+			return nil, fmt.Errorf("starvation order history: %w", err)
+		}
+		if out[cell] == nil {
+			out[cell] = make(map[int64][]domain.OrderStatusRow)
+		}
+		out[cell][order] = append(out[cell][order], r)
+	}
+	return out, rows.Err()
+}
+
+// EdgeOffline returns, per station, the edge_stale alerts overlapping [start,
+// end) as intervals from first_seen to resolved_at (zero End while open).
+//
+// first_seen is when the rule fired, which is the rule's After threshold
+// AFTER the last heartbeat; the minutes before it are left to whatever the
+// orders say rather than backdated. With no edge_stale rule configured there
+// are no rows and no minute is ever attributed to an offline edge.
+func EdgeOffline(db *sql.DB, start, end time.Time) (map[string][]domain.OEEInterval, error) {
+	rows, err := db.Query(`
+		SELECT substr(dedup_key, 6), first_seen, resolved_at
+		  FROM alerts
+		 WHERE kind = 'edge_stale' AND dedup_key LIKE 'edge:%'
+		   AND first_seen < $2 AND COALESCE(resolved_at, $2) > $1
+		 ORDER BY dedup_key, first_seen`, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("starvation edge offline: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string][]domain.OEEInterval)
+	for rows.Next() {
+		var (
+			station string
+			iv      domain.OEEInterval
+			ended   sql.NullTime
+		)
+		if err := rows.Scan(&station, &iv.Start, &ended); err != nil {
+			return nil, fmt.Errorf("starvation edge offline: %w", err)
+		}
+		if ended.Valid {
+			iv.End = ended.Time
+		}
+		out[station] = append(out[station], iv)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,82 @@
+//go:build docker
+
+package starvation_test
+
+import (
+	"testing"
+	"time"
+
+	"shingocore/internal/testdb"
+	"shingocore/store/starvation"
+)
+
+// TestOrderHistory_FollowsTheEpisodeToItsOrders: a consume episode's child
+// order's history comes back under the episode's cell, oldest first, with the
+// queue code; a supply-side episode's order does not, and neither does a row
+// after the window.
+func TestOrderHistory_FollowsTheEpisodeToItsOrders(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	for _, q := range []struct {
+		sql  string
+		args []any
+	}{
+		{`INSERT INTO demand_origins (origin_id, episode_key, kind, direction, station_id, opened_at)
+		  VALUES ('42000000-0000-0000-0000-000000000001', 'cell|SPR-1|7|consume', 'cell', 'consume', 'SPR-1', $1),
+		         ('42000000-0000-0000-0000-000000000002', 'cell|SPR-1|7|produce', 'cell', 'produce', 'SPR-1', $1)`,
+			[]any{at}},
+		{`INSERT INTO orders (id, edge_uuid, station_id, status, origin_id)
+		  VALUES (4201, 'starve-1', 'SPR-1', 'in_transit', '42000000-0000-0000-0000-000000000001'),
+		         (4202, 'starve-2', 'SPR-1', 'queued', '42000000-0000-0000-0000-000000000002')`, nil},
+		{`INSERT INTO order_history (order_id, status, code, created_at)
+		  VALUES (4201, 'dispatched', NULL, $1::timestamptz + INTERVAL '20 minutes'),
+		         (4201, 'queued', 'waiting_for_material', $1),
+		         (4201, 'delivered', NULL, $1::timestamptz + INTERVAL '5 hours'),
+		         (4202, 'queued', 'waiting_for_slot', $1)`, []any{at}},
+	} {
+		if _, err := db.Exec(q.sql, q.args...); err != nil {
+			t.Fatalf("fixture: %v", err)
+		}
+	}
+
+	got, err := starvation.OrderHistory(db.DB, at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("order history: %v", err)
+	}
+	rows := got["SPR-1"][4201]
+	if len(rows) != 2 || rows[0].Status != "queued" || rows[0].Code != "waiting_for_material" || rows[1].Status != "dispatched" {
+		t.Errorf("order 4201 = %+v, want queued(waiting_for_material) then dispatched, delivery cut off", rows)
+	}
+	if _, ok := got["SPR-1"][4202]; ok {
+		t.Errorf("produce-side order 4202 returned: %+v", got["SPR-1"][4202])
+	}
+}
+
+// TestEdgeOffline_ReadsEdgeStaleAlerts: an edge_stale alert is the station's
+// offline span, open while unresolved; other kinds are not.
+func TestEdgeOffline_ReadsEdgeStaleAlerts(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	at := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`
+		INSERT INTO alerts (rule, kind, dedup_key, severity, first_seen, last_seen, resolved_at, status)
+		VALUES ('edge-stale', 'edge_stale', 'edge:SPR-1', 'warning', $1, $1, $1::timestamptz + INTERVAL '10 minutes', 'resolved'),
+		       ('edge-stale', 'edge_stale', 'edge:SPR-2', 'warning', $1, $1, NULL, 'open'),
+		       ('fleet', 'fleet_disconnected', 'edge:SPR-3', 'warning', $1, $1, NULL, 'open')`, at); err != nil {
+		t.Fatalf("fixture: %v", err)
+	}
+	got, err := starvation.EdgeOffline(db.DB, at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("edge offline: %v", err)
+	}
+	if iv := got["SPR-1"]; len(iv) != 1 || !iv[0].End.Equal(at.Add(10*time.Minute)) {
+		t.Errorf("SPR-1 = %+v, want one 10-minute span", iv)
+	}
+	if iv := got["SPR-2"]; len(iv) != 1 || !iv[0].End.IsZero() {
+		t.Errorf("SPR-2 = %+v, want one open span", iv)
+	}
+	if _, ok := got["SPR-3"]; ok {
+		t.Errorf("non-edge_stale alert returned: %+v", got["SPR-3"])
+	}
+}
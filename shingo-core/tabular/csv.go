@@ -0,0 +1,60 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvWriter writes RFC 4180 CSV with a header row. A null is an empty field,
+// a timestamp is RFC 3339 in UTC and a bool is true/false — what every
+// spreadsheet and dataframe reader guesses right without being told.
+type csvWriter struct {
+	w    *csv.Writer
+	cols []Column
+	rec  []string
+	rows int64
+}
+
+func newCSVWriter(w io.Writer, cols []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), cols: cols, rec: make([]string, len(cols))}
+	for i, c := range cols {
+		cw.rec[i] = c.Name
+	}
+	if err := cw.w.Write(cw.rec); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row []any) error {
+	if err := check(cw.cols, row); err != nil {
+		return err
+	}
+	for i, v := range row {
+		switch x := v.(type) {
+		case nil:
+			cw.rec[i] = ""
+		case string:
+			cw.rec[i] = x
+		case int64:
+			cw.rec[i] = strconv.FormatInt(x, 10)
+		case float64:
+			cw.rec[i] = strconv.FormatFloat(x, 'g', -1, 64)
+		case bool:
+			cw.rec[i] = strconv.FormatBool(x)
+		case time.Time:
+			cw.rec[i] = x.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	cw.rows++
+	return cw.w.Write(cw.rec)
+}
+
+func (cw *csvWriter) Rows() int64 { return cw.rows }
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,126 @@
+package tabular
+
+import (
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// RowGroupRows is how many rows the Parquet writer holds before writing them
+// out as a row group. It is the writer's whole memory: at the widest dataset
+// this is a few tens of megabytes, whatever the export's length.
+const RowGroupRows = 50_000
+
+// parquetWriter streams rows through parquet-go's writer, which flushes a row
+// group every RowGroupRows rows.
+type parquetWriter struct {
+	w    *parquet.Writer
+	cols []Column
+	row  parquet.Row // reused; WriteRows copies the values out
+	rows int64
+}
+
+func newParquetWriter(w io.Writer, cols []Column) *parquetWriter {
+	schema := parquet.NewSchema("export", newColumnGroup(cols))
+	return &parquetWriter{
+		w: parquet.NewWriter(w, schema,
+			parquet.MaxRowsPerRowGroup(RowGroupRows),
+			parquet.Compression(&parquet.Gzip)),
+		cols: cols,
+		row:  make(parquet.Row, len(cols)),
+	}
+}
+
+func (pw *parquetWriter) Write(row []any) error {
+	if err := check(pw.cols, row); err != nil {
+		return err
+	}
+	for i, v := range row {
+		pw.row[i] = parquetValue(pw.cols[i], i, v)
+	}
+	if _, err := pw.w.WriteRows([]parquet.Row{pw.row}); err != nil {
+		return err
+	}
+	pw.rows++
+	return nil
+}
+
+func (pw *parquetWriter) Rows() int64 { return pw.rows }
+
+func (pw *parquetWriter) Close() error { return pw.w.Close() }
+
+// parquetValue is one checked value at column i. A nullable column's present
+// values sit at definition level 1 and its nulls at 0; a required column has
+// only level 0.
+func parquetValue(c Column, i int, v any) parquet.Value {
+	var pv parquet.Value
+	switch x := v.(type) {
+	case nil:
+		return parquet.NullValue().Level(0, 0, i)
+	case string:
+		pv = parquet.ByteArrayValue([]byte(x))
+	case int64:
+		pv = parquet.Int64Value(x)
+	case float64:
+		pv = parquet.DoubleValue(x)
+	case bool:
+		pv = parquet.BooleanValue(x)
+	case time.Time:
+		pv = parquet.Int64Value(x.UnixMicro())
+	}
+	def := 0
+	if c.Nullable {
+		def = 1
+	}
+	return pv.Level(0, def, i)
+}
+
+// columnGroup is the file's root: parquet.Group with its fields in schema
+// order. Group alone lists them by name, and a column's position is part of
+// what an export promises.
+type columnGroup struct {
+	parquet.Group
+	fields []parquet.Field
+}
+
+func newColumnGroup(cols []Column) columnGroup {
+	g := columnGroup{Group: parquet.Group{}}
+	for _, c := range cols {
+		var n parquet.Node
+		switch c.Type {
+		case String:
+			n = parquet.String()
+		case Int64:
+			n = parquet.Leaf(parquet.Int64Type)
+		case Float64:
+			n = parquet.Leaf(parquet.DoubleType)
+		case Bool:
+			n = parquet.Leaf(parquet.BooleanType)
+		case Timestamp:
+			n = parquet.Timestamp(parquet.Microsecond)
+		}
+		if c.Nullable {
+			n = parquet.Optional(n)
+		}
+		g.Group[c.Name] = n
+		g.fields = append(g.fields, columnField{Node: n, name: c.Name})
+	}
+	return g
+}
+
+func (g columnGroup) Fields() []parquet.Field { return g.fields }
+
+type columnField struct {
+	parquet.Node
+	name string
+}
+
+func (f columnField) Name() string { return f.name }
+
+// Value is the reflection path parquet-go takes for Go values; this package
+// writes parquet.Rows, so it only has to match Group's map lookup.
+func (f columnField) Value(base reflect.Value) reflect.Value {
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
@@ -0,0 +1,104 @@
+// Package tabular writes rows of a fixed column schema as CSV or Parquet, one
+// row at a time, so an export of any size streams from the database cursor to
+// the file without being held in memory.
+//
+// PURE. It knows columns and values, not tables: store/analyticsexport says
+// what the columns are and where the rows come from, and this says how they
+// are laid out on disk.
+//
+// Parquet is written by github.com/parquet-go/parquet-go's streaming writer —
+// flat schema in column order, gzip pages — so the files pandas, DuckDB, Spark
+// and Power BI read are laid out by a maintained encoder, not one kept here.
+// Memory is bounded by RowGroupRows, not by the export.
+package tabular
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Type is a column's value type.
+type Type string
+
+const (
+	String    Type = "string"
+	Int64     Type = "int64"
+	Float64   Type = "float64"
+	Bool      Type = "bool"
+	Timestamp Type = "timestamp" // UTC, microsecond precision
+)
+
+// Column is one column of a schema. Nullable columns take nil; a nil in a
+// column that is not nullable is an error, so a schema cannot promise
+// NOT NULL and quietly break it.
+type Column struct {
+	Name     string `json:"name"`
+	Type     Type   `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Doc      string `json:"doc"`
+}
+
+// Formats.
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Formats lists the formats NewWriter takes, in the order a UI offers them.
+var Formats = []string{FormatParquet, FormatCSV}
+
+// Writer takes rows in schema order. Close flushes and finishes the file; it
+// does not close the underlying writer.
+type Writer interface {
+	Write(row []any) error
+	Rows() int64
+	Close() error
+}
+
+// NewWriter starts a file of the given format on w.
+func NewWriter(format string, w io.Writer, cols []Column) (Writer, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("tabular: no columns")
+	}
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, cols)
+	case FormatParquet:
+		return newParquetWriter(w, cols), nil
+	}
+	return nil, fmt.Errorf("tabular: unknown format %q", format)
+}
+
+// check validates one row against the schema before either writer encodes it.
+func check(cols []Column, row []any) error {
+	if len(row) != len(cols) {
+		return fmt.Errorf("tabular: row has %d values, schema has %d columns", len(row), len(cols))
+	}
+	for i, c := range cols {
+		v := row[i]
+		if v == nil {
+			if !c.Nullable {
+				return fmt.Errorf("tabular: %s is not nullable", c.Name)
+			}
+			continue
+		}
+		ok := false
+		switch c.Type {
+		case String:
+			_, ok = v.(string)
+		case Int64:
+			_, ok = v.(int64)
+		case Float64:
+			_, ok = v.(float64)
+		case Bool:
+			_, ok = v.(bool)
+		case Timestamp:
+			_, ok = v.(time.Time)
+		}
+		if !ok {
+			return fmt.Errorf("tabular: %s is %s, got %T", c.Name, c.Type, v)
+		}
+	}
+	return nil
+}
@@ -15,13 +15,17 @@ import (
 
 const sessionName = "shingocore-session"
 
-func newSessionStore(secret string) *sessions.CookieStore {
+// newSessionStore builds the cookie store. secure follows web.tls.enabled:
+// a Secure cookie is never sent over plain HTTP, so setting it without TLS
+// would make login impossible, and leaving it off under TLS would let the
+// redirect listener's first request carry the session in clear.
+func newSessionStore(secret string, secure bool) *sessions.CookieStore {
 	if secret == "" {
 		secret = "shingocore-default-secret-change-me"
 	}
 	s := sessions.NewCookieStore([]byte(secret))
 	s.Options.HttpOnly = true
-	s.Options.Secure = false // ShinGo runs on plain HTTP (factory LAN)
+	s.Options.Secure = secure
 	s.Options.SameSite = http.SameSiteLaxMode
 	return s
 }
@@ -333,3 +333,21 @@ func TestHandleLogout_ClearsAuthenticatedFlag(t *testing.T) {
 		t.Error("session should NOT be authenticated after logout")
 	}
 }
+
+// The session cookie is Secure exactly when web.tls is on: without TLS a
+// Secure cookie is never sent back and login could not stick.
+func TestNewSessionStore_SecureFollowsTLS(t *testing.T) {
+	t.Parallel()
+	for _, secure := range []bool{false, true} {
+		store := newSessionStore("test-secret", secure)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		session, err := store.New(req, sessionName)
+		testutil.MustNoErr(t, err, "new session")
+		testutil.MustNoErr(t, session.Save(req, rec), "save session")
+		cookie := rec.Header().Get("Set-Cookie")
+		if got := strings.Contains(cookie, "Secure"); got != secure {
+			t.Errorf("secure=%v: Set-Cookie %q", secure, cookie)
+		}
+	}
+}
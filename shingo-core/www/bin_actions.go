@@ -10,6 +10,7 @@ import (
 	"shingo/protocol"
 	"shingocore/domain"
 	"shingocore/engine"
+	"shingocore/service"
 )
 
 // binActionFunc is the handler signature for individual bin actions.
@@ -45,6 +46,14 @@ func (h *Handlers) executeBinAction(b *domain.Bin, action string, params json.Ra
 // --- Bin action handlers (bound method values used by executeBinAction) ---
 
 func (h *Handlers) binActivate(b *domain.Bin, _ json.RawMessage) error {
+	// A bin an open quality hold holds is released by dispositioning the hold,
+	// not by flipping its status — that would put it back into dispatch with the
+	// hold still open and nothing on it saying who decided the material was good.
+	if hold, err := h.engine.QualityHoldService().OpenHoldForBin(b.ID); err != nil {
+		return err
+	} else if hold != nil {
+		return fmt.Errorf("bin is held by %s (%s) — disposition the hold on the Quality holds page", hold.Ref(), hold.ReasonCode)
+	}
 	if err := h.engine.BinService().ChangeStatus(b.ID, domain.BinStatusAvailable); err != nil {
 		return err
 	}
@@ -62,27 +71,41 @@ func (h *Handlers) binFlag(b *domain.Bin, _ json.RawMessage) error {
 	return nil
 }
 
+// binQualityHold places a single-bin quality hold. It goes through
+// QualityHoldService rather than flipping the status, so the bin's hold has a
+// reason code, an audit trail and a disposition like any other — the bin page
+// is just the quickest way to place the narrowest one. The free-text reason
+// becomes the hold's note and, as before, the bin's "hold" note.
 func (h *Handlers) binQualityHold(b *domain.Bin, params json.RawMessage) error {
 	var p struct {
-		Reason string `json:"reason"`
-		Actor  string `json:"actor"`
+		Reason     string `json:"reason"`
+		ReasonCode string `json:"reason_code"`
+		Actor      string `json:"actor"`
 	}
 	if err := json.Unmarshal(params, &p); err != nil && len(params) > 0 {
 		return fmt.Errorf("invalid params: %w", err)
 	}
-	svc := h.engine.BinService()
-	if err := svc.ChangeStatus(b.ID, domain.BinStatusQualityHold); err != nil {
-		return err
+	if p.ReasonCode == "" {
+		p.ReasonCode = quickHoldReasonCode
 	}
-	actor := h.resolveActor(p.Actor)
-	h.engine.AuditService().Append("bin", b.ID, "status", b.Status.String(), string(domain.BinStatusQualityHold), actor)
-	if p.Reason != "" {
-		svc.AddNote(b.ID, "hold", p.Reason, actor)
+	binID := b.ID
+	_, _, err := h.engine.QualityHoldService().Place(service.QualityHoldInput{
+		ReasonCode: p.ReasonCode,
+		Note:       p.Reason,
+		Scope:      service.QualityScopeBin,
+		BinID:      &binID,
+	}, h.resolveActor(p.Actor))
+	if err != nil {
+		return err
 	}
 	h.emitBinUpdate(b, "status_changed", "")
 	return nil
 }
 
+// quickHoldReasonCode is the reason code a hold placed from the bin page gets
+// when the caller names none. It is in config.DefaultQualityReasonCodes.
+const quickHoldReasonCode = "other"
+
 func (h *Handlers) binMaintenance(b *domain.Bin, _ json.RawMessage) error {
 	if err := h.engine.BinService().ChangeStatus(b.ID, domain.BinStatusMaintenance); err != nil {
 		return err
@@ -203,21 +226,30 @@ func (h *Handlers) binClear(b *domain.Bin, _ json.RawMessage) error {
 	// its binding and zeroes the tile, and an unbound node BINDS the staged
 	// carrier through the count-correction repair path. Released stays in use by
 	// binMove, where the bin genuinely did leave.
-	if b.NodeName != "" {
-		if err := h.orchestration.SendDataToEdge(protocol.SubjectUOPAdjustment, protocol.StationBroadcast, &protocol.UOPAdjustment{
-			BinID:        b.ID,
-			CoreNodeName: b.NodeName,
-			NewRemaining: 0,
-			Epoch:        epoch,
-			Actor:        protocol.AuditActorUI,
-			AdjustedAt:   time.Now().UTC(),
-		}); err != nil {
-			log.Printf("bin_clear: zero-count broadcast bin %d (node %s): %v", b.ID, b.NodeName, err)
-		}
-	}
+	h.broadcastZeroCount(b.ID, b.NodeName, epoch, protocol.AuditActorUI, "bin_clear")
 	return nil
 }
 
+// broadcastZeroCount tells Edge the bin at nodeName is now empty — a count
+// correction to zero, for the reasons binClear gives. A bin that is not on a
+// node has nothing at Edge to correct. Also used by a quality-hold scrap,
+// which empties bins exactly the way Clear does.
+func (h *Handlers) broadcastZeroCount(binID int64, nodeName string, epoch int64, actor, logPrefix string) {
+	if nodeName == "" {
+		return
+	}
+	if err := h.orchestration.SendDataToEdge(protocol.SubjectUOPAdjustment, protocol.StationBroadcast, &protocol.UOPAdjustment{
+		BinID:        binID,
+		CoreNodeName: nodeName,
+		NewRemaining: 0,
+		Epoch:        epoch,
+		Actor:        actor,
+		AdjustedAt:   time.Now().UTC(),
+	}); err != nil {
+		log.Printf("%s: zero-count broadcast bin %d (node %s): %v", logPrefix, binID, nodeName, err)
+	}
+}
+
 func (h *Handlers) binConfirmManifest(b *domain.Bin, _ json.RawMessage) error {
 	if b.Manifest == nil {
 		return fmt.Errorf("bin has no manifest to confirm")
@@ -18,6 +18,7 @@ package www
 import (
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"runtime"
@@ -48,6 +49,27 @@ func SetBuildInfo(version, commit string, bootAt time.Time) {
 	buildInfo.version, buildInfo.commit, buildInfo.bootAt = version, commit, bootAt
 }
 
+// webCert is the web server's certificate expiry, stamped at boot when
+// web.tls is on. Zero means plain HTTP and the strip says nothing about it.
+var webCert = struct {
+	mu       sync.RWMutex
+	notAfter time.Time
+}{}
+
+// certWarnDays is when an expiring web certificate turns the strip amber. It
+// matches webtls.RenewBefore: a generated certificate is reissued at the
+// next restart from then on, so amber means "restart Core this month" for a
+// generated one and "get the plant PKI to issue a new one" for a supplied one.
+const certWarnDays = 30
+
+// SetWebCertificate records the serving certificate's expiry. Called once
+// from main when web.tls is enabled.
+func SetWebCertificate(notAfter time.Time) {
+	webCert.mu.Lock()
+	defer webCert.mu.Unlock()
+	webCert.notAfter = notAfter
+}
+
 // goroutineRing is the 12-slot in-memory history behind the sparkline.
 //
 // Twelve points, no schema, resets on restart — and that is the whole design.
@@ -182,6 +204,12 @@ type CoreHealth struct {
 	// "it broke right after a restart" with no correlation work. This is where
 	// the build stamp becomes visible rather than merely logged.
 	Uptime string `json:"uptime"`
+	// CertExpiresAt and CertDaysLeft describe the web certificate; both are
+	// absent on plain HTTP. Days left is a pointer because zero is a real
+	// answer (it lapses today) and can go negative — an expired certificate
+	// is still worth a number.
+	CertExpiresAt *time.Time `json:"cert_expires_at,omitempty"`
+	CertDaysLeft  *int       `json:"cert_days_left,omitempty"`
 
 	// DB pool. A ratio against a limit — a meter, not a number.
 	DBInUse   int `json:"db_in_use"`
@@ -279,6 +307,15 @@ func (h *Handlers) coreHealth(depsOK bool, depReasons []string) CoreHealth {
 		SSEClients:       h.eventHub.ClientCount(),
 	}
 
+	webCert.mu.RLock()
+	if certEnd := webCert.notAfter; !certEnd.IsZero() {
+		// WALL clock: certificate validity is checked by browsers against
+		// real time, whatever the sim clock says.
+		days := int(math.Floor(time.Until(certEnd).Hours() / 24))
+		c.CertExpiresAt, c.CertDaysLeft = &certEnd, &days
+	}
+	webCert.mu.RUnlock()
+
 	if st, ok := h.engine.HealthService().PoolStats(); ok {
 		c.DBInUse, c.DBIdle = st.InUse, st.Idle
 		c.DBMaxOpen = st.MaxOpenConnections
@@ -314,7 +351,7 @@ func (h *Handlers) coreHealth(depsOK bool, depReasons []string) CoreHealth {
 }
 
 // deriveReasons is the verdict rule: worst-of across dependencies, DB pool
-// waits, load, dead letters and completion anomalies.
+// waits, load, dead letters, completion anomalies and web certificate expiry.
 //
 // Every condition produces a SENTENCE, not a flag. A red dot with no
 // explanation is a puzzle rather than a signal, and the strip shows the first
@@ -361,6 +398,16 @@ func deriveReasons(c CoreHealth, depsDown []string) []string {
 			plural(c.CompletionAnomalies, "anomaly", "anomalies"),
 			formatWindow(c.CompletionAnomalyWindowHours)))
 	}
+	// Checked against the boot-time certificate; a browser refuses the whole
+	// UI the day it lapses, so the month before is when to say so.
+	if days := c.CertDaysLeft; days != nil && *days < certWarnDays {
+		if *days < 0 {
+			reasons = append(reasons, "web certificate expired")
+		} else {
+			reasons = append(reasons, fmt.Sprintf("web certificate expires in %d %s",
+				*days, plural(*days, "day", "days")))
+		}
+	}
 	// NOTICE faults only. A 20-second replan is the overwhelming majority of
 	// faults and is not a degraded core; colouring the verdict for it would
 	// leave the strip amber most of the day.
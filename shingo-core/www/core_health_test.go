@@ -1,6 +1,7 @@
 package www
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -186,6 +187,38 @@ func TestCoreHealthVerdict_UnreadableLoadIsNotAFault(t *testing.T) {
 	}
 }
 
+// A web certificate is silent until its last month, then says how long is
+// left; plain HTTP (no expiry) never mentions one.
+func TestCoreHealthVerdict_WebCertificateExpiry(t *testing.T) {
+	end := time.Now().Add(90 * 24 * time.Hour)
+	days := func(n int) *int { return &n }
+	if got := deriveReasons(CoreHealth{Cores: 8, CertExpiresAt: &end, CertDaysLeft: days(90)}, nil); len(got) != 0 {
+		t.Fatalf("a certificate with 90 days left must not degrade the verdict, got %v", got)
+	}
+	got := deriveReasons(CoreHealth{Cores: 8, CertExpiresAt: &end, CertDaysLeft: days(12)}, nil)
+	if len(got) != 1 || got[0] != "web certificate expires in 12 days" {
+		t.Fatalf("reasons = %v", got)
+	}
+	got = deriveReasons(CoreHealth{Cores: 8, CertExpiresAt: &end, CertDaysLeft: days(-1)}, nil)
+	if len(got) != 1 || got[0] != "web certificate expired" {
+		t.Fatalf("reasons = %v", got)
+	}
+	if got := deriveReasons(CoreHealth{Cores: 8}, nil); len(got) != 0 {
+		t.Fatalf("plain HTTP must not mention a certificate, got %v", got)
+	}
+	// Nor does its JSON: no cert_days_left of 0 for a certificate that isn't there.
+	b, err := json.Marshal(CoreHealth{Cores: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "cert_") {
+		t.Fatalf("plain HTTP health carries a certificate field: %s", b)
+	}
+	if b, _ := json.Marshal(CoreHealth{CertExpiresAt: &end, CertDaysLeft: days(0)}); !strings.Contains(string(b), `"cert_days_left":0`) {
+		t.Fatalf("a certificate lapsing today lost its day count: %s", b)
+	}
+}
+
 func resetExpiredDropGauge() {
 	expiredDropGauge.mu.Lock()
 	defer expiredDropGauge.mu.Unlock()
@@ -0,0 +1,339 @@
+package www
+
+import (
+	"sort"
+	"time"
+
+	"shingo/protocol"
+	"shingocore/alerting"
+	"shingocore/domain"
+	"shingocore/fleet"
+	"shingocore/service"
+)
+
+// dashboard_kinds_view.go — what the five config-schema wall displays show
+// (service/dashboard_kinds.go). Pure functions of what the handlers read, so
+// each board's rules are testable without a database: which tiles are low or
+// stale, what state a lane slot is in, how far an hour is from plan, which
+// alerts an alert board hides.
+
+// LinesideTile is one node and payload's reported lineside level.
+type LinesideTile struct {
+	Station    string    `json:"station"`
+	Node       string    `json:"node"`
+	Payload    string    `json:"payload"`
+	Level      int       `json:"level"`
+	ReportedAt time.Time `json:"reported_at"`
+	// Low is the level at or below the board's low_at.
+	Low bool `json:"low"`
+	// Stale is a report older than the board's stale_after_min: the level
+	// shown is the last one the edge sent, and the edge has gone quiet.
+	Stale bool `json:"stale"`
+}
+
+// BuildLinesideTiles tiles the levels reported at the board's stations (every
+// station when stations is empty), ordered by station, node and payload so a
+// tile does not move between redraws.
+func BuildLinesideTiles(levels []service.LinesideLevel, stations []string, cfg service.LinesideBoardConfig, now time.Time) []LinesideTile {
+	want := scopeSet(stations)
+	staleAfter := time.Duration(cfg.StaleAfterMin) * time.Minute
+	out := make([]LinesideTile, 0, len(levels))
+	for _, l := range levels {
+		if want != nil && !want[l.Station] {
+			continue
+		}
+		out = append(out, LinesideTile{
+			Station: l.Station, Node: l.Node, Payload: l.Payload,
+			Level: l.Level, ReportedAt: l.ReportedAt,
+			Low:   l.Level <= cfg.LowAt,
+			Stale: now.Sub(l.ReportedAt) > staleAfter,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Station != b.Station {
+			return a.Station < b.Station
+		}
+		if a.Node != b.Node {
+			return a.Node < b.Node
+		}
+		return a.Payload < b.Payload
+	})
+	return out
+}
+
+// scopeSet is a board's scope — its stations, or a lane map's groups — as a
+// set; nil is everything.
+func scopeSet(stations []string) map[string]bool {
+	if len(stations) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(stations))
+	for _, s := range stations {
+		set[s] = true
+	}
+	return set
+}
+
+// Lane slot states, as the lane map colours them.
+const (
+	SlotLoaded   = "loaded"    // a bin with a payload
+	SlotEmptyBin = "empty-bin" // a bin with nothing in it
+	SlotReserved = "reserved"  // no bin yet, claimed as an order's destination
+	SlotFree     = "free"      // no bin and no claim
+)
+
+// LaneSlot is one slot of a lane, mouth first.
+type LaneSlot struct {
+	Name    string `json:"name"`
+	Depth   int    `json:"depth"`
+	State   string `json:"state"`
+	Payload string `json:"payload,omitempty"`
+	UOP     int    `json:"uop,omitempty"`
+	// Claimed is a bin an order has claimed — on its way out of the lane.
+	Claimed bool `json:"claimed,omitempty"`
+}
+
+// LaneRow is one lane and its slots.
+type LaneRow struct {
+	Name  string     `json:"name"`
+	Slots []LaneSlot `json:"slots"`
+}
+
+// LaneGroup is one node group (NGRP) and its lanes, with the group's
+// occupancy: slots holding a bin, of all slots.
+type LaneGroup struct {
+	Name     string    `json:"name"`
+	Lanes    []LaneRow `json:"lanes"`
+	Occupied int       `json:"occupied"`
+	Slots    int       `json:"slots"`
+}
+
+// BuildLaneMap draws every NGRP's LANE children and their slots by depth, the
+// mouth first. groups limits it to the named groups; empty is every group.
+// Retired bins are not in a slot; a bin elsewhere is not on the map.
+func BuildLaneMap(nodes []*domain.Node, bins []*domain.Bin, groups []string) []LaneGroup {
+	want := scopeSet(groups)
+	binAt := make(map[string]*domain.Bin, len(bins))
+	for _, b := range bins {
+		if b.Status == "retired" || b.NodeName == "" {
+			continue
+		}
+		if _, seen := binAt[b.NodeName]; !seen {
+			binAt[b.NodeName] = b
+		}
+	}
+	children := make(map[string][]*domain.Node)
+	for _, n := range nodes {
+		if n.ParentName != "" {
+			children[n.ParentName] = append(children[n.ParentName], n)
+		}
+	}
+
+	var out []LaneGroup
+	for _, g := range nodes {
+		if g.NodeTypeCode != protocol.NodeClassNGRP || (want != nil && !want[g.Name]) {
+			continue
+		}
+		grp := LaneGroup{Name: g.Name, Lanes: []LaneRow{}}
+		for _, lane := range children[g.Name] {
+			if lane.NodeTypeCode != protocol.NodeClassLANE {
+				continue
+			}
+			row := LaneRow{Name: lane.Name, Slots: []LaneSlot{}}
+			for _, n := range children[lane.Name] {
+				slot := LaneSlot{Name: n.Name, State: SlotFree}
+				if n.Depth != nil {
+					slot.Depth = *n.Depth
+				}
+				switch b := binAt[n.Name]; {
+				case b != nil:
+					slot.State = SlotEmptyBin
+					if b.PayloadCode != "" {
+						slot.State, slot.Payload, slot.UOP = SlotLoaded, b.PayloadCode, b.UOPRemaining
+					}
+					slot.Claimed = b.ClaimedBy != nil
+					grp.Occupied++
+				case n.ClaimedBy != nil:
+					slot.State = SlotReserved
+				}
+				row.Slots = append(row.Slots, slot)
+				grp.Slots++
+			}
+			sort.SliceStable(row.Slots, func(i, j int) bool { return row.Slots[i].Depth < row.Slots[j].Depth })
+			grp.Lanes = append(grp.Lanes, row)
+		}
+		sort.Slice(grp.Lanes, func(i, j int) bool { return grp.Lanes[i].Name < grp.Lanes[j].Name })
+		out = append(out, grp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ProductionHour is one cell's parts in one hour against its plan.
+type ProductionHour struct {
+	Parts int64 `json:"parts"`
+	// Expected is the plan for the hour — pro-rated for the hour still
+	// running — and zero with no target.
+	Expected int64 `json:"expected"`
+	// Status is "met", "behind", or "" with no target to judge against.
+	Status string `json:"status"`
+}
+
+// ProductionRow is one cell's hours, oldest first.
+type ProductionRow struct {
+	Cell   string           `json:"cell"`
+	Name   string           `json:"name"`
+	Target int              `json:"target"`
+	Hours  []ProductionHour `json:"hours"`
+	Total  int64            `json:"total"`
+	// Planned sums the hours' Expected; zero with no target.
+	Planned int64 `json:"planned"`
+}
+
+// ProductionBoard is the production board: the hour labels and a row per cell.
+type ProductionBoard struct {
+	Hours []string        `json:"hours"`
+	Rows  []ProductionRow `json:"rows"`
+}
+
+// ProductionHours is the n clock hours ending with the one now is in, oldest
+// first. Hours are truncated on the absolute clock, as HourlyParts buckets
+// them.
+func ProductionHours(now time.Time, n int) []time.Time {
+	cur := now.Truncate(time.Hour)
+	out := make([]time.Time, n)
+	for i := range out {
+		out[i] = cur.Add(-time.Duration(n-1-i) * time.Hour)
+	}
+	return out
+}
+
+// BuildProductionBoard lays each cell's hourly parts against its target.
+// cells are the cells in scope with their display names; nil is every cell
+// that counted parts or has a target. The hour still running is judged
+// against the part of its target that has elapsed, so the board does not show
+// a line behind at ten past the hour.
+func BuildProductionBoard(hourly map[string]map[time.Time]int64, cfg service.ProductionBoardConfig, cells map[string]string, hours []time.Time, loc *time.Location, now time.Time) ProductionBoard {
+	board := ProductionBoard{Hours: make([]string, len(hours)), Rows: []ProductionRow{}}
+	for i, h := range hours {
+		board.Hours[i] = h.In(loc).Format("15:04")
+	}
+	names := cells
+	if names == nil {
+		names = make(map[string]string)
+		for c := range hourly {
+			names[c] = c
+		}
+		for c := range cfg.Targets {
+			names[c] = c
+		}
+	}
+	for cell, name := range names {
+		row := ProductionRow{Cell: cell, Name: name, Target: cfg.Targets[cell], Hours: make([]ProductionHour, len(hours))}
+		for i, h := range hours {
+			ph := ProductionHour{Parts: hourly[cell][h.UTC()]}
+			if row.Target > 0 {
+				frac := 1.0
+				if end := h.Add(time.Hour); now.Before(end) {
+					frac = float64(now.Sub(h)) / float64(time.Hour)
+				}
+				ph.Expected = int64(float64(row.Target) * frac)
+				ph.Status = "met"
+				if ph.Parts < ph.Expected {
+					ph.Status = "behind"
+				}
+			}
+			row.Total += ph.Parts
+			row.Planned += ph.Expected
+			row.Hours[i] = ph
+		}
+		board.Rows = append(board.Rows, row)
+	}
+	sort.Slice(board.Rows, func(i, j int) bool { return board.Rows[i].Cell < board.Rows[j].Cell })
+	return board
+}
+
+// FilterBoardAlerts keeps the unresolved alerts an alert board shows: at or
+// above its minimum severity, acknowledged ones only if it keeps them. Most
+// severe first, then newest.
+func FilterBoardAlerts(list []*service.Alert, cfg service.AlertBoardConfig) []*service.Alert {
+	min := alerting.Rank(cfg.MinSeverity)
+	out := make([]*service.Alert, 0, len(list))
+	for _, a := range list {
+		if a.ResolvedAt != nil || alerting.Rank(a.Severity) < min {
+			continue
+		}
+		if a.Status == service.AlertStatusAcknowledged && !cfg.ShowAcknowledged {
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := alerting.Rank(out[i].Severity), alerting.Rank(out[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return out[i].FirstSeen.After(out[j].FirstSeen)
+	})
+	return out
+}
+
+// FleetTile is one robot on the fleet board: the robot-update row plus the
+// board's flag.
+type FleetTile struct {
+	robotJSON
+	LowBattery bool `json:"low_battery"`
+}
+
+// FleetBoard is the fleet board: a tile per robot and the counts across them.
+type FleetBoard struct {
+	Robots       []FleetTile `json:"robots"`
+	Total        int         `json:"total"`
+	Available    int         `json:"available"`
+	Busy         int         `json:"busy"`
+	Charging     int         `json:"charging"`
+	Faulted      int         `json:"faulted"`
+	Disconnected int         `json:"disconnected"`
+	LowBattery   int         `json:"low_battery"`
+}
+
+// BuildFleetBoard tiles the cached robots by vehicle id. A status with no
+// vehicle id is not a robot and is dropped; disconnected robots are counted
+// either way, tiled only if the board keeps them, and never counted available
+// or busy — what the fleet last said about them is no longer news.
+func BuildFleetBoard(robots []fleet.RobotStatus, lines map[string]RobotOrderLine, cfg service.FleetBoardConfig) FleetBoard {
+	board := FleetBoard{Robots: []FleetTile{}}
+	rows := robotsJSON(robots, lines)
+	for i, r := range robots {
+		if r.VehicleID == "" {
+			continue
+		}
+		board.Total++
+		if !r.Connected {
+			board.Disconnected++
+			if !cfg.ShowDisconnected {
+				continue
+			}
+		}
+		low := r.BatteryLevel <= float64(cfg.LowBatteryPct)
+		switch {
+		case !r.Connected:
+		case r.IsError || r.Emergency:
+			board.Faulted++
+		case r.Busy:
+			board.Busy++
+		case r.Available:
+			board.Available++
+		}
+		if r.Charging {
+			board.Charging++
+		}
+		if low {
+			board.LowBattery++
+		}
+		board.Robots = append(board.Robots, FleetTile{robotJSON: rows[i], LowBattery: low})
+	}
+	sort.Slice(board.Robots, func(i, j int) bool { return board.Robots[i].VehicleID < board.Robots[j].VehicleID })
+	return board
+}
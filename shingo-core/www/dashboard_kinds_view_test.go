@@ -0,0 +1,148 @@
+package www
+
+import (
+	"testing"
+	"time"
+
+	"shingo/protocol"
+	"shingocore/domain"
+	"shingocore/fleet"
+	"shingocore/service"
+)
+
+// TestLinesideTilesScopeLowAndStale: only the board's stations are tiled; a
+// level at low_at is low; a report past stale_after_min is stale.
+func TestLinesideTilesScopeLowAndStale(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	levels := []service.LinesideLevel{
+		{Station: "plant-a", Node: "L1", Payload: "P1", Level: 2, ReportedAt: now.Add(-time.Minute)},
+		{Station: "plant-a", Node: "L2", Payload: "P2", Level: 9, ReportedAt: now.Add(-20 * time.Minute)},
+		{Station: "plant-b", Node: "L9", Payload: "P9", Level: 0, ReportedAt: now},
+	}
+	cfg := service.LinesideBoardConfig{LowAt: 2, StaleAfterMin: 15}
+	tiles := BuildLinesideTiles(levels, []string{"plant-a"}, cfg, now)
+	if len(tiles) != 2 {
+		t.Fatalf("tiles = %+v, want plant-a's two", tiles)
+	}
+	if !tiles[0].Low || tiles[0].Stale {
+		t.Errorf("L1 = %+v, want low and fresh", tiles[0])
+	}
+	if tiles[1].Low || !tiles[1].Stale {
+		t.Errorf("L2 = %+v, want not low and stale", tiles[1])
+	}
+}
+
+// TestLaneMapSlotStates: slots are drawn mouth first, and each of the four
+// states comes from the right evidence — a retired bin is no bin.
+func TestLaneMapSlotStates(t *testing.T) {
+	depth := func(d int) *int { return &d }
+	order := int64(41)
+	nodes := []*domain.Node{
+		{Name: "SMN", NodeTypeCode: protocol.NodeClassNGRP},
+		{Name: "SMN-L1", NodeTypeCode: protocol.NodeClassLANE, ParentName: "SMN"},
+		{Name: "S3", ParentName: "SMN-L1", Depth: depth(3)},
+		{Name: "S1", ParentName: "SMN-L1", Depth: depth(1)},
+		{Name: "S2", ParentName: "SMN-L1", Depth: depth(2), ClaimedBy: &order},
+		{Name: "S4", ParentName: "SMN-L1", Depth: depth(4)},
+		{Name: "OTHER", NodeTypeCode: protocol.NodeClassNGRP},
+	}
+	bins := []*domain.Bin{
+		{NodeName: "S1", PayloadCode: "P1", UOPRemaining: 12, ClaimedBy: &order},
+		{NodeName: "S3"},
+		{NodeName: "S4", PayloadCode: "P4", Status: "retired"},
+	}
+	groups := BuildLaneMap(nodes, bins, []string{"SMN"})
+	if len(groups) != 1 || len(groups[0].Lanes) != 1 {
+		t.Fatalf("groups = %+v, want SMN with one lane", groups)
+	}
+	slots := groups[0].Lanes[0].Slots
+	want := []struct{ name, state string }{{"S1", SlotLoaded}, {"S2", SlotReserved}, {"S3", SlotEmptyBin}, {"S4", SlotFree}}
+	for i, w := range want {
+		if slots[i].Name != w.name || slots[i].State != w.state {
+			t.Errorf("slot %d = %s %s, want %s %s", i, slots[i].Name, slots[i].State, w.name, w.state)
+		}
+	}
+	if !slots[0].Claimed || slots[0].UOP != 12 {
+		t.Errorf("S1 = %+v, want claimed with 12 UOP", slots[0])
+	}
+	if groups[0].Occupied != 2 || groups[0].Slots != 4 {
+		t.Errorf("occupancy = %d/%d, want 2/4", groups[0].Occupied, groups[0].Slots)
+	}
+}
+
+// TestProductionBoardProRatesTheRunningHour: a full hour is judged against the
+// whole target, the running one against the elapsed part of it, and a cell
+// with no target has no status. VERIFIED RED BY: dropping the pro-rating — the
+// running hour's 20 parts at quarter past read "behind" against 60.
+func TestProductionBoardProRatesTheRunningHour(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 15, 0, 0, time.UTC)
+	hours := ProductionHours(now, 2)
+	if !hours[1].Equal(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("hours = %v, want 08:00 and 09:00", hours)
+	}
+	hourly := map[string]map[time.Time]int64{
+		"SPR-1": {hours[0]: 50, hours[1]: 20},
+		"SPR-2": {hours[0]: 7},
+	}
+	cfg := service.ProductionBoardConfig{Hours: 2, Targets: map[string]int{"SPR-1": 60}}
+	board := BuildProductionBoard(hourly, cfg, nil, hours, time.UTC, now)
+	if len(board.Rows) != 2 || board.Hours[0] != "08:00" {
+		t.Fatalf("board = %+v, want two cells from 08:00", board)
+	}
+	spr1 := board.Rows[0]
+	if spr1.Hours[0].Status != "behind" || spr1.Hours[1].Status != "met" || spr1.Hours[1].Expected != 15 {
+		t.Errorf("SPR-1 hours = %+v, want behind then met against 15", spr1.Hours)
+	}
+	if spr1.Total != 70 || spr1.Planned != 75 {
+		t.Errorf("SPR-1 total = %d of %d, want 70 of 75", spr1.Total, spr1.Planned)
+	}
+	if board.Rows[1].Hours[0].Status != "" {
+		t.Errorf("SPR-2 = %+v, want no status without a target", board.Rows[1])
+	}
+}
+
+// TestBoardAlertsFilterAndOrder: below the minimum severity and acknowledged
+// alerts are hidden by default; critical sorts first.
+func TestBoardAlertsFilterAndOrder(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	list := []*service.Alert{
+		{ID: 1, Severity: "info", Status: service.AlertStatusOpen, FirstSeen: at},
+		{ID: 2, Severity: "warning", Status: service.AlertStatusOpen, FirstSeen: at},
+		{ID: 3, Severity: "critical", Status: service.AlertStatusOpen, FirstSeen: at.Add(-time.Hour)},
+		{ID: 4, Severity: "critical", Status: service.AlertStatusAcknowledged, FirstSeen: at},
+	}
+	got := FilterBoardAlerts(list, service.AlertBoardConfig{MinSeverity: "warning"})
+	if len(got) != 2 || got[0].ID != 3 || got[1].ID != 2 {
+		t.Errorf("ids = %v, want [3 2]", alertIDs(got))
+	}
+	got = FilterBoardAlerts(list, service.AlertBoardConfig{MinSeverity: "info", ShowAcknowledged: true})
+	if len(got) != 4 || got[0].ID != 4 {
+		t.Errorf("ids = %v, want all four, newest critical first", alertIDs(got))
+	}
+}
+
+func alertIDs(list []*service.Alert) []int64 {
+	ids := make([]int64, len(list))
+	for i, a := range list {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+// TestFleetBoardCountsAndHidesDisconnected: a disconnected robot is counted
+// but not tiled when the board hides it, and never counted available.
+func TestFleetBoardCountsAndHidesDisconnected(t *testing.T) {
+	robots := []fleet.RobotStatus{
+		{VehicleID: "AMR-2", Connected: true, Available: true, BatteryLevel: 15},
+		{VehicleID: "AMR-1", Connected: true, Busy: true, BatteryLevel: 80},
+		{VehicleID: "AMR-3", Connected: false, Available: true, BatteryLevel: 50},
+		{VehicleID: ""},
+	}
+	b := BuildFleetBoard(robots, nil, service.FleetBoardConfig{LowBatteryPct: 20})
+	if b.Total != 3 || b.Disconnected != 1 || b.Available != 1 || b.Busy != 1 || b.LowBattery != 1 {
+		t.Errorf("counts = %+v, want 3 total, 1 each of disconnected, available, busy, low battery", b)
+	}
+	if len(b.Robots) != 2 || b.Robots[0].VehicleID != "AMR-1" || !b.Robots[1].LowBattery {
+		t.Errorf("tiles = %+v, want AMR-1 then low-battery AMR-2", b.Robots)
+	}
+}
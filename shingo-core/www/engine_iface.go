@@ -22,7 +22,7 @@ import (
 // Phase 6.5 (2026-04-25) split this out of EngineAccess. The split
 // captures the architectural role distinction: most handlers do pure
 // CRUD through services and have no business reaching engine-level
-// orchestration. ServiceAccess gives those handlers a 49-method surface;
+// orchestration. ServiceAccess gives those handlers a 58-method surface;
 // orchestration handlers take EngineOrchestration explicitly via
 // h.orchestration.
 //
@@ -84,6 +84,15 @@ type ServiceAccess interface {
 	FootprintService() *service.FootprintService
 	PartsService() *service.PartsService
 	HeartbeatService() *service.HeartbeatService
+	QualityHoldService() *service.QualityHoldService
+	AlertService() *service.AlertService
+	ShiftReportService() *service.ShiftReportService
+	OEEService() *service.OEEService
+	StarvationService() *service.StarvationService
+	ReplayService() *service.ReplayService
+	DemandForecastService() *service.DemandForecastService
+	AnalyticsExportService() *service.AnalyticsExportService
+	RobotMaintenanceService() *service.RobotMaintenanceService
 
 	// ── Read-only state queries ────────────────────────────────────
 	// These look like orchestration verbs but are pure reads with no
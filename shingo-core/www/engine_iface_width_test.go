@@ -54,12 +54,13 @@ func assertInterfaceWidth(t *testing.T, name string, rt reflect.Type, want []str
 	}
 }
 
-// TestServiceAccessWidth pins Core's narrow surface at 49 methods. The
+// TestServiceAccessWidth pins Core's narrow surface at 58 methods. The
 // interface's own doc comment states the same number; keep them together.
 func TestServiceAccessWidth(t *testing.T) {
 	t.Parallel()
 	want := []string{
 		"AdminService",
+		"AlertService",
 		"AppConfig",
 		"AuditService",
 		"BinManifest",
@@ -98,11 +99,19 @@ func TestServiceAccessWidth(t *testing.T) {
 		"OrderService",
 		"PartsService",
 		"PayloadService",
+		"QualityHoldService",
 		"Reconciliation",
 		"Recovery",
 		"ReplenishmentHealth",
 		"RequestEdgeReregister",
 		"RobotGroups",
+		"ShiftReportService",
+		"OEEService",
+		"StarvationService",
+		"ReplayService",
+		"DemandForecastService",
+		"AnalyticsExportService",
+		"RobotMaintenanceService",
 		"SourceabilityEvents",
 		"SourceabilityPage",
 		"TestCommandService",
@@ -113,12 +122,13 @@ func TestServiceAccessWidth(t *testing.T) {
 	assertInterfaceWidth(t, "ServiceAccess", reflect.TypeOf(&iface).Elem(), want)
 }
 
-// TestEngineOrchestrationWidth pins Core's wide surface at 63 methods —
-// ServiceAccess's 49 embedded, plus 14 orchestration verbs of its own.
+// TestEngineOrchestrationWidth pins Core's wide surface at 72 methods —
+// ServiceAccess's 58 embedded, plus 14 orchestration verbs of its own.
 func TestEngineOrchestrationWidth(t *testing.T) {
 	t.Parallel()
 	want := []string{
 		"AdminService",
+		"AlertService",
 		"AppConfig",
 		"ApplyBatchCorrection",
 		"ApplyCorrection",
@@ -161,6 +171,7 @@ func TestEngineOrchestrationWidth(t *testing.T) {
 		"OrderService",
 		"PartsService",
 		"PayloadService",
+		"QualityHoldService",
 		"Reconciliation",
 		"ReconfigureDatabase",
 		"ReconfigureFleet",
@@ -172,6 +183,13 @@ func TestEngineOrchestrationWidth(t *testing.T) {
 		"RobotGroups",
 		"SceneSync",
 		"SendDataToEdge",
+		"ShiftReportService",
+		"OEEService",
+		"StarvationService",
+		"ReplayService",
+		"DemandForecastService",
+		"AnalyticsExportService",
+		"RobotMaintenanceService",
 		"SourceabilityEvents",
 		"SourceabilityPage",
 		"SyncScenePoints",
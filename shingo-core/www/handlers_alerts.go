@@ -0,0 +1,139 @@
+package www
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/service"
+)
+
+// The alerts surface: what the rules engine raised, and the acknowledgement
+// that stops one escalating.
+//
+// History is the point as much as the open list — "what fired during
+// Tuesday's night shift, and did anybody see it" is answered by the filters,
+// which the page and /api/alerts read the same way. Acknowledging is behind
+// sign-in and the actor is the signed-in user: an acknowledgement is a claim
+// that a named person saw the alert, and a name the caller could type would
+// make the history say nothing.
+
+// alertFilter reads ?status=&rule=&since=&until=&limit= into a filter. since
+// and until take a date (2026-10-14) or an RFC 3339 instant. status defaults
+// to unresolved; "all" lifts it.
+func alertFilter(r *http.Request) (service.AlertFilter, error) {
+	q := r.URL.Query()
+	f := service.AlertFilter{Status: service.AlertStatus(q.Get("status")), Rule: q.Get("rule")}
+	switch f.Status {
+	case "":
+		f.Status = "unresolved"
+	case "all":
+		f.Status = ""
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.ParseInLocation("2006-01-02", v, time.Local)
+		}
+		if err != nil {
+			return f, errors.New(p.name + " must be a date (2006-01-02) or an RFC 3339 time")
+		}
+		*p.dst = t
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return f, errors.New("limit must be a number")
+		}
+		f.Limit = n
+	}
+	return f, nil
+}
+
+// handleAlerts renders /alerts.
+func (h *Handlers) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	rules := make([]string, 0, len(cfg.Alerts.Rules))
+	for _, rule := range cfg.Alerts.Rules {
+		rules = append(rules, rule.Name)
+	}
+	enabled := cfg.Alerts.Enabled
+	cfg.Unlock()
+
+	q := r.URL.Query()
+	data := map[string]any{
+		"Page":     "alerts",
+		"Rules":    rules,
+		"Enabled":  enabled,
+		"Status":   q.Get("status"),
+		"Rule":     q.Get("rule"),
+		"Since":    q.Get("since"),
+		"Until":    q.Get("until"),
+		"Username": h.getUsername(r),
+	}
+	f, err := alertFilter(r)
+	if err == nil {
+		var list []*service.Alert
+		if list, err = h.engine.AlertService().List(f); err == nil {
+			data["Alerts"] = list
+		}
+	}
+	if err != nil {
+		// Shown, not swallowed: an empty table must mean nothing fired.
+		data["AlertError"] = err.Error()
+	}
+	h.render(w, r, "alerts.html", data)
+}
+
+// apiListAlerts returns alerts newest first, filtered as the page is.
+func (h *Handlers) apiListAlerts(w http.ResponseWriter, r *http.Request) {
+	f, err := alertFilter(r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list, err := h.engine.AlertService().List(f)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []*service.Alert{}
+	}
+	h.jsonOK(w, list)
+}
+
+// apiAcknowledgeAlert acknowledges one open alert as the signed-in user.
+func (h *Handlers) apiAcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.jsonError(w, "invalid alert id", http.StatusBadRequest)
+		return
+	}
+	a, err := h.engine.AlertService().Acknowledge(id, h.getUsername(r))
+	switch {
+	case errors.Is(err, service.ErrAlertNotFound):
+		h.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrAlertNotOpen):
+		h.jsonError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// The alert board redraws now rather than on the loop's next pass.
+	h.eventHub.Broadcast("alert-update", sseJSON(map[string]any{"id": a.ID, "status": a.Status}))
+	h.jsonOK(w, a)
+}
@@ -0,0 +1,106 @@
+package www
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"shingocore/service"
+	"shingocore/tabular"
+)
+
+// The analytics export: the datasets engineers pull into pandas or Power BI,
+// written as Parquet or CSV and partitioned by day. The schema is public, like
+// the mission reads it replaces; the download is behind auth beside the
+// inventory export, because it is the whole ledger and not a page of it.
+
+// analyticsExportInfo is what the Missions page's export panel is built from.
+type analyticsExportInfo struct {
+	SchemaVersion int                        `json:"schema_version"`
+	Formats       []string                   `json:"formats"`
+	MaxDays       int                        `json:"max_days"`
+	Datasets      []service.AnalyticsDataset `json:"datasets"`
+	Schedule      analyticsExportSchedule    `json:"schedule"`
+}
+
+type analyticsExportSchedule struct {
+	Enabled      bool     `json:"enabled"`
+	Formats      []string `json:"formats"`
+	Datasets     []string `json:"datasets"`
+	LookbackDays int      `json:"lookback_days"`
+	Storage      string   `json:"storage"`
+}
+
+// apiAnalyticsExport serves the dataset schema and the schedule's settings.
+func (h *Handlers) apiAnalyticsExport(w http.ResponseWriter, r *http.Request) {
+	svc := h.engine.AnalyticsExportService()
+	info := analyticsExportInfo{
+		SchemaVersion: service.AnalyticsSchemaVersion,
+		Formats:       tabular.Formats,
+		MaxDays:       service.MaxAnalyticsExportDays,
+		Datasets:      svc.Datasets(),
+	}
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	p := cfg.AnalyticsExport
+	info.Schedule = analyticsExportSchedule{
+		Enabled:      p.Enabled,
+		Formats:      append([]string(nil), p.Formats...),
+		Datasets:     append([]string(nil), p.Datasets...),
+		LookbackDays: p.LookbackDays,
+		Storage:      p.Storage,
+	}
+	cfg.Unlock()
+	h.jsonOK(w, info)
+}
+
+// apiAnalyticsExportDownload streams ?from= to ?to= (YYYY-MM-DD, both
+// inclusive, plant days) as a zip. ?datasets= and ?formats= are comma lists;
+// empty is every dataset, as Parquet.
+func (h *Handlers) apiAnalyticsExportDownload(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var req service.AnalyticsExportRequest
+	for name, dst := range map[string]*time.Time{"from": &req.From, "to": &req.To} {
+		d, err := time.Parse(time.DateOnly, q.Get(name))
+		if err != nil {
+			h.jsonError(w, fmt.Sprintf("%s: %q is not a YYYY-MM-DD date", name, q.Get(name)), http.StatusBadRequest)
+			return
+		}
+		*dst = d
+	}
+	req.To = req.To.AddDate(0, 0, 1)
+	req.Location = plantLocation
+	req.Datasets = splitList(q.Get("datasets"))
+	req.Formats = splitList(q.Get("formats"))
+
+	svc := h.engine.AnalyticsExportService()
+	if err := svc.Validate(req); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrAnalyticsExport) {
+			status = http.StatusBadRequest
+		}
+		h.jsonError(w, err.Error(), status)
+		return
+	}
+	filename := fmt.Sprintf("shingo-analytics-%s_%s.zip", req.From.Format(time.DateOnly), req.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	// From here on the status is sent; a failure can only cut the zip short,
+	// which leaves it without a central directory and unreadable.
+	if err := svc.WriteZip(r.Context(), w, req); err != nil {
+		log.Printf("analytics export %s: %v", filename, err)
+	}
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+//go:build docker
+
+package www
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// The download refuses a bad request with a 400 before it commits to a
+// streamed zip, and on an empty record still writes every file — header-only,
+// zero rows — so the day partitions are complete and the manifest says so.
+func TestApiAnalyticsExportDownload(t *testing.T) {
+	t.Parallel()
+	h, _ := testHandlers(t)
+
+	for _, q := range []string{
+		"",
+		"?from=2026-10-12",
+		"?from=2026-10-12&to=2026-10-11",
+		"?from=2026-10-12&to=2026-10-12&datasets=invoices",
+		"?from=2026-10-12&to=2026-10-12&formats=xlsx",
+		"?from=2025-01-01&to=2026-10-12",
+	} {
+		rec := httptest.NewRecorder()
+		h.apiAnalyticsExportDownload(rec, httptest.NewRequest(http.MethodGet, "/api/analytics-export/download"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.apiAnalyticsExportDownload(rec, httptest.NewRequest(http.MethodGet,
+		"/api/analytics-export/download?from=2026-10-11&to=2026-10-12&datasets=orders,downtime&formats=parquet,csv", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d; body=%s", rec.Code, rec.Body.String())
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{
+		"orders/date=2026-10-11/part-00000.parquet",
+		"orders/date=2026-10-12/part-00000.csv",
+		"downtime/date=2026-10-12/part-00000.parquet",
+		"manifest.json",
+	} {
+		if !names[want] {
+			t.Errorf("zip lacks %s; has %v", want, names)
+		}
+	}
+	mf, err := zr.Open("manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mf.Close()
+	var m struct {
+		SchemaVersion int `json:"schema_version"`
+		Files         []struct {
+			Path string `json:"path"`
+			Rows int64  `json:"rows"`
+		} `json:"files"`
+	}
+	if err := json.NewDecoder(mf).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m.SchemaVersion != 1 || len(m.Files) != 8 {
+		t.Fatalf("manifest: schema v%d, %d files; want v1, 8", m.SchemaVersion, len(m.Files))
+	}
+}
+
+// The schema endpoint serves every dataset with its columns documented.
+func TestApiAnalyticsExport_Schema(t *testing.T) {
+	t.Parallel()
+	h, _ := testHandlers(t)
+	rec := httptest.NewRecorder()
+	h.apiAnalyticsExport(rec, httptest.NewRequest(http.MethodGet, "/api/analytics-export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	var info analyticsExportInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Datasets) == 0 || len(info.Formats) != 2 {
+		t.Fatalf("info = %+v", info)
+	}
+	for _, d := range info.Datasets {
+		for _, c := range d.Columns {
+			if c.Doc == "" {
+				t.Errorf("%s.%s has no doc", d.Name, c.Name)
+			}
+		}
+	}
+}
@@ -56,7 +56,7 @@ func testHandlers(t *testing.T) (*Handlers, *store.DB) {
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
@@ -258,6 +258,25 @@ func TestExecuteBinAction_QualityHold_NoReason(t *testing.T) {
 	requireAudit(t, db, bin.ID, "status", "available", "quality_hold", "inspector-2")
 }
 
+// TestExecuteBinAction_ActivateRefusesHeldBin: a bin held by an open quality
+// hold comes off hold through the hold's disposition, not through Activate.
+func TestExecuteBinAction_ActivateRefusesHeldBin(t *testing.T) {
+	t.Parallel()
+	h, db, _, bin := setupBinForAction(t)
+
+	params := mustJSON(t, map[string]string{"reason": "burr on flange", "actor": "inspector-3"})
+	testutil.MustNoErr(t, h.executeBinAction(bin, "quality_hold", params), "quality_hold")
+
+	held, _ := db.GetBin(bin.ID)
+	err := h.executeBinAction(held, "activate", nil)
+	if err == nil || !strings.Contains(err.Error(), "QH-") {
+		t.Fatalf("activate on a held bin: err = %v, want a refusal naming the hold", err)
+	}
+	if got, _ := db.GetBin(bin.ID); got.Status != "quality_hold" {
+		t.Errorf("status after refused activate: got %q, want quality_hold", got.Status)
+	}
+}
+
 func TestExecuteBinAction_Lock(t *testing.T) {
 	t.Parallel()
 	h, db, _, bin := setupBinForAction(t)
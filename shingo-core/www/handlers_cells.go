@@ -134,7 +134,7 @@ func (h *Handlers) handleHeartbeatKiosk(w http.ResponseWriter, r *http.Request)
 			return
 		}
 	}
-	h.renderBare(w, "heartbeat.html", map[string]any{})
+	h.renderBare(w, r, "heartbeat.html", map[string]any{})
 }
 
 // GET /api/cells — every configured cell (Phase E, Q-025). Empty list on first
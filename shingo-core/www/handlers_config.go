@@ -6,9 +6,11 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"shingo/protocol/auth"
+	"shingocore/config"
 	"shingocore/notify"
 )
 
@@ -82,8 +84,16 @@ func (h *Handlers) handleConfigSave(w http.ResponseWriter, r *http.Request) {
 			}
 			brokers = append(brokers, host+":"+port)
 		}
-		cfg.Messaging.Kafka.Brokers = brokers
-		cfg.Messaging.Kafka.GroupID = r.FormValue("group_id")
+		kafka := cfg.Messaging.Kafka
+		kafka.Brokers = brokers
+		kafka.GroupID = r.FormValue("group_id")
+		applyKafkaSecurityForm(r, &kafka)
+		if err := kafka.Validate(); err != nil {
+			cfg.Unlock()
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cfg.Messaging.Kafka = kafka
 		cfg.Messaging.OrdersTopic = r.FormValue("orders_topic")
 		cfg.Messaging.DispatchTopic = r.FormValue("dispatch_topic")
 	case "fire_alarm":
@@ -140,6 +150,24 @@ func (h *Handlers) handleConfigSave(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/config?saved="+section, http.StatusSeeOther)
 }
 
+// applyKafkaSecurityForm reads the TLS and SASL fields of the services form.
+// The page never renders the saved SASL password, so a blank one keeps it.
+func applyKafkaSecurityForm(r *http.Request, k *config.KafkaConfig) {
+	k.TLS = config.KafkaTLSConfig{
+		Enabled:            r.FormValue("kafka_tls_enabled") == "on",
+		CAFile:             strings.TrimSpace(r.FormValue("kafka_tls_ca_file")),
+		CertFile:           strings.TrimSpace(r.FormValue("kafka_tls_cert_file")),
+		KeyFile:            strings.TrimSpace(r.FormValue("kafka_tls_key_file")),
+		ServerName:         strings.TrimSpace(r.FormValue("kafka_tls_server_name")),
+		InsecureSkipVerify: r.FormValue("kafka_tls_insecure_skip_verify") == "on",
+	}
+	k.SASL.Mechanism = r.FormValue("kafka_sasl_mechanism")
+	k.SASL.Username = strings.TrimSpace(r.FormValue("kafka_sasl_username"))
+	if v := r.FormValue("kafka_sasl_password"); v != "" {
+		k.SASL.Password = v
+	}
+}
+
 func (h *Handlers) handleConfigTestEmail(w http.ResponseWriter, r *http.Request) {
 	cfg := h.engine.AppConfig()
 	n := cfg.Notifications
@@ -267,6 +295,42 @@ func (h *Handlers) handleConfigTestAlert(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(map[string]any{"ok": true, "message": fmt.Sprintf("Test %s alert sent to %d recipient(s)", alertType, len(n.Recipients))})
 }
 
+// handleConfigTestChannel sends one test message on a configured notification
+// channel — the per-channel counterpart of handleConfigTestEmail. It sends
+// whether or not notifications are enabled and does not retry: the button is
+// how a channel is checked before anyone turns it on, and the first failure is
+// the one worth reading.
+func (h *Handlers) handleConfigTestChannel(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	cfg := h.engine.AppConfig()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	cfg.Lock()
+	var ch *config.NotificationChannel
+	for i := range cfg.Notifications.Channels {
+		if cfg.Notifications.Channels[i].Name == name {
+			c := cfg.Notifications.Channels[i]
+			ch = &c
+			break
+		}
+	}
+	cfg.Unlock()
+	if ch == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "message": fmt.Sprintf("no notification channel named %q", name)})
+		return
+	}
+
+	if err := notify.TestChannel(*ch); err != nil {
+		log.Printf("config: test channel %s failed: %v", name, err)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	log.Printf("config: test sent to %s channel %s", ch.Kind, name)
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "message": fmt.Sprintf("Test sent to %s channel %q", ch.Kind, name)})
+}
+
 // handleConfigPassword rotates the logged-in admin's password.
 //
 // Core had no password-change path of any kind until this landed: the only
@@ -67,7 +67,7 @@ func testHandlersWithConfigPath(t *testing.T) (*Handlers, *store.DB, string) {
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
@@ -219,6 +219,48 @@ func TestHandleConfigSave_MessagingSection(t *testing.T) {
 	}
 }
 
+// TestHandleConfigSave_MessagingSecurity checks the page's masked SASL
+// password: a blank field keeps the saved one, and an unusable setting is
+// refused before anything is written.
+func TestHandleConfigSave_MessagingSecurity(t *testing.T) {
+	t.Parallel()
+	h, _, _ := testHandlersWithConfigPath(t)
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	cfg.Messaging.Kafka.SASL = config.KafkaSASLConfig{Mechanism: config.KafkaSASLPlain, Username: "core", Password: "saved-pw"}
+	cfg.Unlock()
+
+	form := url.Values{}
+	form.Set("section", "messaging")
+	form.Set("kafka_host_0", "127.0.0.1")
+	form.Set("kafka_port_0", "9092")
+	form.Set("kafka_tls_enabled", "on")
+	form.Set("kafka_tls_server_name", "kafka.plant.local")
+	form.Set("kafka_sasl_mechanism", config.KafkaSASLSCRAMSHA512)
+	form.Set("kafka_sasl_username", "core")
+	form.Set("kafka_sasl_password", "")
+	rec := postForm(t, h.handleConfigSave, "/config/save", form)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status: got %d, want 303; body=%s", rec.Code, rec.Body.String())
+	}
+	k := cfg.Messaging.Kafka
+	if !k.TLS.Enabled || k.TLS.ServerName != "kafka.plant.local" || k.SASL.Mechanism != config.KafkaSASLSCRAMSHA512 {
+		t.Fatalf("kafka security after save: %+v", k)
+	}
+	if k.SASL.Password != "saved-pw" {
+		t.Errorf("blank password field replaced the saved password with %q", k.SASL.Password)
+	}
+
+	form.Set("kafka_sasl_mechanism", "gssapi")
+	rec = postForm(t, h.handleConfigSave, "/config/save", form)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown mechanism: got %d, want 400", rec.Code)
+	}
+	if cfg.Messaging.Kafka.SASL.Mechanism != config.KafkaSASLSCRAMSHA512 {
+		t.Errorf("refused save still changed the mechanism to %q", cfg.Messaging.Kafka.SASL.Mechanism)
+	}
+}
+
 func TestHandleConfigSave_FireAlarmSection(t *testing.T) {
 	t.Parallel()
 	h, _, _ := testHandlersWithConfigPath(t)
@@ -0,0 +1,168 @@
+package www
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/service"
+)
+
+// The data APIs of the five config-schema wall-display kinds
+// (service/dashboard_kinds.go), one per kind, each keyed by the dashboard so
+// the board's stations and options are applied server-side. Public, like
+// node-report's: the chromeless kiosk reads them. Each kiosk redraws on the
+// SSE topic that moves its data and re-fetches from here.
+
+// apiDashboardKinds lists every wall-display kind with its option defaults,
+// for the hub's create form.
+func (h *Handlers) apiDashboardKinds(w http.ResponseWriter, r *http.Request) {
+	h.jsonOK(w, service.DashboardKinds())
+}
+
+// dashboardOfKind loads /api/dashboards/{id} and checks it is a kind board,
+// writing the error itself when it is not.
+func (h *Handlers) dashboardOfKind(w http.ResponseWriter, r *http.Request, kind string) (*service.Dashboard, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.jsonError(w, "invalid id", http.StatusBadRequest)
+		return nil, false
+	}
+	d, err := h.engine.DashboardService().Get(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if d == nil {
+		h.jsonError(w, "dashboard not found", http.StatusNotFound)
+		return nil, false
+	}
+	if d.Kind != kind {
+		h.jsonError(w, "dashboard is not a "+kind+" board", http.StatusBadRequest)
+		return nil, false
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	return d, true
+}
+
+// apiDashboardLineside returns a lineside board's tiles.
+func (h *Handlers) apiDashboardLineside(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.dashboardOfKind(w, r, service.LinesideBoardKind)
+	if !ok {
+		return
+	}
+	cfg, err := service.ParseLinesideBoardConfig(d.Config)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	levels, err := h.engine.AlertService().LinesideLevels()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, BuildLinesideTiles(levels, d.Stations, cfg, time.Now()))
+}
+
+// apiDashboardLanes returns a lane map's groups.
+func (h *Handlers) apiDashboardLanes(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.dashboardOfKind(w, r, service.LaneMapKind)
+	if !ok {
+		return
+	}
+	cfg, err := service.ParseLaneMapConfig(d.Config)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	nodes, err := h.engine.NodeService().ListNodes()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	bins, err := h.engine.BinService().ListBins()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	groups := BuildLaneMap(nodes, bins, cfg.Groups)
+	if groups == nil {
+		groups = []LaneGroup{}
+	}
+	h.jsonOK(w, groups)
+}
+
+// apiDashboardProduction returns a production board's hours. A board scoped
+// to stations shows the heartbeat cells at them, under their display names.
+func (h *Handlers) apiDashboardProduction(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.dashboardOfKind(w, r, service.ProductionBoardKind)
+	if !ok {
+		return
+	}
+	cfg, err := service.ParseProductionBoardConfig(d.Config)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var cells map[string]string
+	if len(d.Stations) > 0 {
+		scoped, err := h.engine.HeartbeatService().DashboardCells(d.Stations, nil)
+		if err != nil {
+			h.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cells = make(map[string]string, len(scoped))
+		for _, c := range scoped {
+			name := c.DisplayName
+			if name == "" {
+				name = c.CellID
+			}
+			cells[c.CellID] = name
+		}
+	}
+	now := time.Now()
+	hours := ProductionHours(now, cfg.Hours)
+	hourly, err := h.engine.OEEService().HourlyParts(hours[0], now)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, BuildProductionBoard(hourly, cfg, cells, hours, plantLocation, now))
+}
+
+// apiDashboardAlerts returns an alert board's alerts.
+func (h *Handlers) apiDashboardAlerts(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.dashboardOfKind(w, r, service.AlertBoardKind)
+	if !ok {
+		return
+	}
+	cfg, err := service.ParseAlertBoardConfig(d.Config)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list, err := h.engine.AlertService().List(service.AlertFilter{Status: "unresolved"})
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, FilterBoardAlerts(list, cfg))
+}
+
+// apiDashboardFleet returns a fleet board's robots, from the engine's robot
+// cache — the same snapshot robot-update broadcasts.
+func (h *Handlers) apiDashboardFleet(w http.ResponseWriter, r *http.Request) {
+	d, ok := h.dashboardOfKind(w, r, service.FleetBoardKind)
+	if !ok {
+		return
+	}
+	cfg, err := service.ParseFleetBoardConfig(d.Config)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lines := robotOrderLines(h.engine.OrderService(), h.engine.AppConfig())
+	h.jsonOK(w, BuildFleetBoard(h.engine.GetAllCachedRobots(), lines, cfg))
+}
@@ -18,16 +18,21 @@ import (
 // is kind-agnostic; adding a kind means registering a renderer template here
 // (and a matching branch in the page JS).
 //
-// FOUR KINDS, and the map is the count. The comment here read "v1 ships one
-// kind" while the literal below it registered four — a line that was true when
-// written and has been wrong through three additions since, which is exactly
-// how a reader ends up trusting prose over the code beside it. Both plants run
-// three of the four today (Springfield also runs node-report).
+// The map is the count, and the count is not repeated here: the comment read
+// "v1 ships one kind" while the literal registered four, and "four" went
+// stale the same way. service.DashboardKinds lists the same kinds for the
+// hub; the five with a config schema (service/dashboard_kinds.go) draw from
+// their own data APIs in handlers_dashboard_kinds.go.
 var dashboardTemplates = map[string]string{
-	"task-board":  "dashboard-display.html",
-	"robot-map":   "dashboard-map.html",
-	"heartbeat":   "heartbeat.html",
-	"node-report": "dashboard-node-report.html",
+	"task-board":                "dashboard-display.html",
+	"robot-map":                 "dashboard-map.html",
+	"heartbeat":                 "heartbeat.html",
+	"node-report":               "dashboard-node-report.html",
+	service.LinesideBoardKind:   "dashboard-lineside.html",
+	service.LaneMapKind:         "dashboard-lanes.html",
+	service.ProductionBoardKind: "dashboard-production.html",
+	service.AlertBoardKind:      "dashboard-alerts.html",
+	service.FleetBoardKind:      "dashboard-fleet.html",
 }
 
 // handleWallDisplay renders one wall display. By default it renders INSIDE
@@ -39,8 +44,12 @@ var dashboardTemplates = map[string]string{
 //
 // Each kiosk template renders the display's own NAME in its header — see
 // dashboard-frame.html's comment, which records that the frame drops its title
-// precisely because all four kiosks carry theirs. A chromeless screen has to
+// precisely because every kiosk carries its own. A chromeless screen has to
 // say what it is; the framed one must not say it twice.
+//
+// ?replay=1 on a replay kind (replayKinds, handlers_replay.go) opens either
+// form in replay mode: the kiosk page mounts the replay bar in place of the
+// live feed, and the frame carries the flag through to its iframe.
 func (h *Handlers) handleWallDisplay(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
@@ -61,11 +70,14 @@ func (h *Handlers) handleWallDisplay(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unsupported dashboard kind: "+d.Kind, http.StatusNotImplemented)
 		return
 	}
+	replay := replayKinds[d.Kind] && r.URL.Query().Get("replay") == "1"
 	if r.URL.Query().Get("kiosk") == "1" {
-		h.renderBare(w, tmpl, map[string]any{"Dashboard": d})
+		h.renderBare(w, r, tmpl, map[string]any{"Dashboard": d, "Replay": replay})
 		return
 	}
-	h.render(w, r, "dashboard-frame.html", map[string]any{"Page": "dashboard", "Dashboard": d})
+	h.render(w, r, "dashboard-frame.html", map[string]any{
+		"Page": "dashboard", "Dashboard": d, "CanReplay": replayKinds[d.Kind], "Replay": replay,
+	})
 }
 
 // handleWallDisplayMoved answers the old /dashboard/{id} with a permanent
@@ -111,7 +111,7 @@ func testHandlersWithFireAlarmFleet(t *testing.T, enabled bool) (*Handlers, *sto
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
@@ -0,0 +1,54 @@
+package www
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"shingocore/config"
+	"shingocore/service"
+)
+
+// The demand forecast: a payload's consumption per style and report shift,
+// learned from the consume ticks, and the UOP threshold it calls for. The
+// inventory page's Forecast button reads it beside each loader threshold row;
+// nothing here writes a threshold.
+
+// apiDemandForecast forecasts ?payload= over ?days= (default 28) at
+// ?service_level= (default 0.95). ?lead_seconds= replaces the observed lead
+// time, for a payload with no completed orders to measure one from.
+func (h *Handlers) apiDemandForecast(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := service.DemandForecastRequest{PayloadCode: q.Get("payload")}
+	var err error
+	if v := q.Get("days"); v != "" {
+		if req.Days, err = strconv.Atoi(v); err != nil {
+			h.jsonError(w, fmt.Sprintf("days: %q is not a whole number", v), http.StatusBadRequest)
+			return
+		}
+	}
+	for name, dst := range map[string]*float64{"service_level": &req.ServiceLevel, "lead_seconds": &req.LeadSeconds} {
+		if v := q.Get(name); v != "" {
+			if *dst, err = strconv.ParseFloat(v, 64); err != nil {
+				h.jsonError(w, fmt.Sprintf("%s: %q is not a number", name, v), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	shifts := append([]config.ReportShift(nil), cfg.Reports.Shifts...)
+	cfg.Unlock()
+	fc, err := h.engine.DemandForecastService().Forecast(req, shifts, plantLocation, time.Now())
+	if errors.Is(err, service.ErrDemandForecast) {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, fc)
+}
@@ -0,0 +1,165 @@
+package www
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/config"
+	"shingocore/service"
+)
+
+// The OEE surface: availability, performance and quality per cell, per shift
+// of the report pattern (reports.shifts) and per plant-local day, computed on
+// read by service.OEEService. The writes are the two inputs nothing else
+// records — engineered cycle times and scrap reports — and sit behind auth.
+
+// oeeDay reads ?date= (default today, plant-local) and computes that day.
+func (h *Handlers) oeeDay(r *http.Request) (*service.OEEDay, error) {
+	day := r.URL.Query().Get("date")
+	if day == "" {
+		day = time.Now().In(plantLocation).Format("2006-01-02")
+	}
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	shifts := append([]config.ReportShift(nil), cfg.Reports.Shifts...)
+	cfg.Unlock()
+	return h.engine.OEEService().Day(day, shifts, plantLocation, time.Now())
+}
+
+// handleOEE renders /oee.
+func (h *Handlers) handleOEE(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{
+		"Page":     "oee",
+		"Timezone": plantLocation.String(),
+		"Username": h.getUsername(r),
+	}
+	day, err := h.oeeDay(r)
+	if err != nil {
+		// Shown, not swallowed into an empty table — see handleCycleTime.
+		data["LoadError"] = err.Error()
+		data["Date"] = r.URL.Query().Get("date")
+		h.render(w, r, "oee.html", data)
+		return
+	}
+	data["Date"] = day.Date
+	data["Windows"] = day.Windows
+	data["Rows"] = BuildOEERows(day)
+	if cycles, err := h.engine.OEEService().ListCycleTimes(); err == nil {
+		data["CycleTimes"] = cycles
+	} else {
+		data["CycleError"] = err.Error()
+	}
+	h.render(w, r, "oee.html", data)
+}
+
+// apiOEE returns one day's figures, per cell and shift.
+func (h *Handlers) apiOEE(w http.ResponseWriter, r *http.Request) {
+	day, err := h.oeeDay(r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if day.Cells == nil {
+		day.Cells = []service.OEECell{}
+	}
+	h.jsonOK(w, day)
+}
+
+// apiListOEEStandards returns every engineered cycle time.
+func (h *Handlers) apiListOEEStandards(w http.ResponseWriter, r *http.Request) {
+	list, err := h.engine.OEEService().ListCycleTimes()
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []service.OEECycleTime{}
+	}
+	h.jsonOK(w, list)
+}
+
+// apiSetOEEStandard creates or replaces the engineered cycle for
+// /api/oee/standards/{cell}/{style}. The body is {"cycle_ms": n}.
+func (h *Handlers) apiSetOEEStandard(w http.ResponseWriter, r *http.Request) {
+	cell, style, ok := h.oeeStandardKey(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		CycleMS int64 `json:"cycle_ms"`
+	}
+	if !h.parseJSON(w, r, &req) {
+		return
+	}
+	if req.CycleMS <= 0 {
+		h.jsonError(w, "cycle_ms must be positive", http.StatusBadRequest)
+		return
+	}
+	c := service.OEECycleTime{CellID: cell, StyleID: style, CycleMS: req.CycleMS, UpdatedBy: h.getUsername(r)}
+	if err := h.engine.OEEService().SetCycleTime(c); err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, map[string]string{"status": "ok"})
+}
+
+// apiDeleteOEEStandard removes an engineered cycle.
+func (h *Handlers) apiDeleteOEEStandard(w http.ResponseWriter, r *http.Request) {
+	cell, style, ok := h.oeeStandardKey(w, r)
+	if !ok {
+		return
+	}
+	err := h.engine.OEEService().DeleteCycleTime(cell, style)
+	switch {
+	case errors.Is(err, service.ErrOEECycleTimeNotFound):
+		h.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, map[string]string{"status": "ok"})
+}
+
+func (h *Handlers) oeeStandardKey(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	cell := strings.TrimSpace(chi.URLParam(r, "cell"))
+	style, err := strconv.ParseInt(chi.URLParam(r, "style"), 10, 64)
+	if cell == "" || err != nil {
+		h.jsonError(w, "cell and numeric style are required", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return cell, style, true
+}
+
+// apiRecordOEEScrap files a scrap report. A negative qty corrects an earlier
+// one; the reporter is the signed-in user.
+func (h *Handlers) apiRecordOEEScrap(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CellID  string `json:"cell_id"`
+		StyleID int64  `json:"style_id"`
+		Qty     int64  `json:"qty"`
+		Note    string `json:"note"`
+	}
+	if !h.parseJSON(w, r, &req) {
+		return
+	}
+	req.CellID = strings.TrimSpace(req.CellID)
+	if req.CellID == "" || req.Qty == 0 {
+		h.jsonError(w, "cell_id and a non-zero qty are required", http.StatusBadRequest)
+		return
+	}
+	id, err := h.engine.OEEService().RecordScrap(service.OEEScrapReport{
+		CellID: req.CellID, StyleID: req.StyleID, Qty: req.Qty,
+		ReportedBy: h.getUsername(r), Note: req.Note,
+	})
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, map[string]int64{"id": id})
+}
@@ -61,7 +61,7 @@ func testHandlersWithSim(t *testing.T, sim *simulator.SimulatorBackend) (*Handle
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
@@ -120,7 +120,7 @@ func testHandlersForPages(t *testing.T) (*Handlers, *store.DB) {
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
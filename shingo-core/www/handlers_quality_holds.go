@@ -0,0 +1,231 @@
+package www
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/service"
+)
+
+// The quality-hold surface: place a hold, see what it holds, and walk it to a
+// disposition.
+//
+// READS ARE PUBLIC, WRITES ARE NOT. A held lot is something the floor needs to
+// be able to look up — "why won't my parts come?" ends at this page — but
+// placing a hold stops material plant-wide and closing one puts it back into
+// production, so both sit behind sign-in, and the approval additionally goes
+// through QualityHoldService's approver rule.
+//
+// The actor on every write is the signed-in user, never a body field. The
+// approver rule compares names; an actor the caller could type would make the
+// two-person check a formality.
+
+// handleQualityHolds renders /quality-holds.
+func (h *Handlers) handleQualityHolds(w http.ResponseWriter, r *http.Request) {
+	svc := h.engine.QualityHoldService()
+	data := map[string]any{
+		"Page":        "quality-holds",
+		"ReasonCodes": svc.ReasonCodes(),
+		"Username":    h.getUsername(r),
+	}
+	holds, err := svc.List(r.URL.Query().Get("all") == "")
+	if err != nil {
+		// SHOWN, NOT SWALLOWED. "No open holds" is the page's most reassuring
+		// sentence; it must not be what a failed read looks like.
+		data["HoldError"] = err.Error()
+	} else {
+		data["Holds"] = holds
+	}
+	data["ShowAll"] = r.URL.Query().Get("all") != ""
+	h.render(w, r, "quality-holds.html", data)
+}
+
+// apiListQualityHolds lists holds, open only unless ?all=1.
+func (h *Handlers) apiListQualityHolds(w http.ResponseWriter, r *http.Request) {
+	holds, err := h.engine.QualityHoldService().List(r.URL.Query().Get("all") == "")
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if holds == nil {
+		holds = []service.QualityHold{}
+	}
+	h.jsonOK(w, holds)
+}
+
+// apiGetQualityHold returns one hold with the bins it has held and its trail.
+func (h *Handlers) apiGetQualityHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.holdIDParam(w, r)
+	if !ok {
+		return
+	}
+	svc := h.engine.QualityHoldService()
+	hold, err := svc.Get(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if hold == nil {
+		h.jsonError(w, "hold not found", http.StatusNotFound)
+		return
+	}
+	bins, err := svc.Bins(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events, err := svc.Events(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if bins == nil {
+		bins = []service.QualityHeldBin{}
+	}
+	if events == nil {
+		events = []service.QualityHoldEvent{}
+	}
+	h.jsonOK(w, map[string]any{
+		"hold":   hold,
+		"ref":    hold.Ref(),
+		"scope":  hold.ScopeLabel(),
+		"bins":   bins,
+		"events": events,
+	})
+}
+
+// apiPlaceQualityHold places a hold and holds every bin it matches.
+//
+// POST /api/quality-holds  {"reason_code": "supplier", "scope": "lot", "lot_code": "L-2207", "note": "..."}
+func (h *Handlers) apiPlaceQualityHold(w http.ResponseWriter, r *http.Request) {
+	var in service.QualityHoldInput
+	if !h.parseJSON(w, r, &in) {
+		return
+	}
+	if err := in.Validate(); err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, held, err := h.engine.QualityHoldService().Place(in, h.getUsername(r))
+	if err != nil {
+		// A placed hold whose propagation failed still exists and the sweep
+		// will finish it, so a non-zero id is reported alongside the error.
+		if id == 0 {
+			h.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("quality hold %d: propagate: %v", id, err)
+	}
+	h.jsonOK(w, map[string]any{"id": id, "held_bins": len(held)})
+}
+
+// apiRequestQualityDisposition asks for a hold to be closed.
+//
+// POST /api/quality-holds/{id}/request-disposition  {"disposition": "scrap"}
+func (h *Handlers) apiRequestQualityDisposition(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.holdIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Disposition service.QualityDisposition `json:"disposition"`
+	}
+	if !h.parseJSON(w, r, &req) {
+		return
+	}
+	switch req.Disposition {
+	case service.QualityDispRelease, service.QualityDispRework, service.QualityDispScrap:
+	default:
+		h.jsonError(w, "disposition must be release, rework or scrap", http.StatusBadRequest)
+		return
+	}
+	if err := h.engine.QualityHoldService().RequestDisposition(id, req.Disposition, h.getUsername(r)); err != nil {
+		h.jsonError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	h.jsonSuccess(w)
+}
+
+// apiApproveQualityDisposition closes a hold with its pending disposition.
+//
+// A scrap empties bins that may be sitting on a line node, and Edge is told the
+// way binClear tells it: a zero count at that node, not a release.
+func (h *Handlers) apiApproveQualityDisposition(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.holdIDParam(w, r)
+	if !ok {
+		return
+	}
+	actor := h.getUsername(r)
+	res, err := h.engine.QualityHoldService().Approve(id, actor)
+	if err != nil && res.Disposition == "" {
+		code := http.StatusConflict
+		if errors.Is(err, service.ErrNotApprover) {
+			code = http.StatusForbidden
+		}
+		h.jsonError(w, err.Error(), code)
+		return
+	}
+	for _, s := range res.Scrapped {
+		b, gerr := h.engine.BinService().GetBin(s.BinID)
+		if gerr != nil {
+			log.Printf("quality hold %d: scrapped bin %d: %v", id, s.BinID, gerr)
+			continue
+		}
+		h.emitBinUpdate(b, "cleared", "")
+		h.broadcastZeroCount(b.ID, b.NodeName, s.Epoch, actor, "quality_scrap")
+	}
+	for _, binID := range res.Released {
+		if b, gerr := h.engine.BinService().GetBin(binID); gerr == nil {
+			h.emitBinUpdate(b, "status_changed", "")
+		}
+	}
+	out := map[string]any{
+		"disposition": res.Disposition,
+		"released":    len(res.Released),
+		"scrapped":    len(res.Scrapped),
+		"still_held":  len(res.StillHeld),
+	}
+	if err != nil {
+		// The hold is closed; some bins did not clear. Say which, do not fail.
+		out["warning"] = err.Error()
+	}
+	h.jsonOK(w, out)
+}
+
+// apiRejectQualityDisposition sends a pending disposition back to active.
+//
+// POST /api/quality-holds/{id}/reject  {"reason": "..."}
+func (h *Handlers) apiRejectQualityDisposition(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.holdIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !h.parseJSON(w, r, &req) {
+		return
+	}
+	if err := h.engine.QualityHoldService().Reject(id, h.getUsername(r), req.Reason); err != nil {
+		code := http.StatusConflict
+		if errors.Is(err, service.ErrNotApprover) {
+			code = http.StatusForbidden
+		}
+		h.jsonError(w, err.Error(), code)
+		return
+	}
+	h.jsonSuccess(w)
+}
+
+func (h *Handlers) holdIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		h.jsonError(w, "invalid hold id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
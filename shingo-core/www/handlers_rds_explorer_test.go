@@ -74,7 +74,7 @@ func testHandlersWithVendorProxy(t *testing.T, baseURL string) (*Handlers, *stor
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
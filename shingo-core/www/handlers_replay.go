@@ -0,0 +1,77 @@
+package www
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/service"
+)
+
+// Historical replay for the task board and robot map: the kiosk opened with
+// ?replay=1 fetches one window's timeline from here and scrubs it in the
+// browser. Public, like /api/board/orders — the chromeless kiosk reads it.
+
+// replayKinds are the wall displays with a replay mode.
+var replayKinds = map[string]bool{"task-board": true, "robot-map": true}
+
+// apiDashboardReplay returns the timeline for ?from= to ?to= (RFC3339), scoped
+// to the dashboard's stations the way its live view is.
+func (h *Handlers) apiDashboardReplay(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.jsonError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	d, err := h.engine.DashboardService().Get(id)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if d == nil {
+		h.jsonError(w, "dashboard not found", http.StatusNotFound)
+		return
+	}
+	if !replayKinds[d.Kind] {
+		h.jsonError(w, "a "+d.Kind+" board has no replay", http.StatusBadRequest)
+		return
+	}
+	from, err := replayParam(r, "from")
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := replayParam(r, "to")
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tl, err := h.engine.ReplayService().Timeline(from, to, d.Stations)
+	if errors.Is(err, service.ErrReplayWindow) {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, tl)
+}
+
+// replayParam reads a required RFC3339 bound. Unlike atParam there is no
+// default: a replay of "now" is the live view.
+func replayParam(r *http.Request, name string) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("%s is required", name)
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %q is not an RFC3339 timestamp", name, raw)
+	}
+	return t, nil
+}
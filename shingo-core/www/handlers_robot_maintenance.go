@@ -0,0 +1,93 @@
+package www
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/service"
+)
+
+// Predictive robot maintenance on the robots page: every robot's indicators
+// with the days behind them, and the work items the engine filed. Reading is
+// public like the rest of the robots page; closing an item is behind auth
+// beside the other robot controls, because closing the last item that holds
+// a robot gives it back to dispatch.
+
+type robotMaintenanceView struct {
+	Enabled       bool                            `json:"enabled"`
+	HoldDueRobots bool                            `json:"hold_due_robots"`
+	Robots        []service.RobotHealth           `json:"robots"`
+	Items         []*service.RobotMaintenanceItem `json:"items"`
+}
+
+// apiRobotMaintenance serves the assessment and the open work items.
+// ?status= lists items in another status (done, dismissed) instead.
+func (h *Handlers) apiRobotMaintenance(w http.ResponseWriter, r *http.Request) {
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	p := cfg.RobotMaintenance
+	cfg.Unlock()
+
+	var vehicles []string
+	for _, rs := range h.engine.GetAllCachedRobots() {
+		vehicles = append(vehicles, rs.VehicleID)
+	}
+	svc := h.engine.RobotMaintenanceService()
+	robots, err := svc.Assess(p, vehicles, time.Now())
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = service.MaintenanceItemOpen
+	}
+	items, err := svc.List(service.RobotMaintenanceFilter{Status: status})
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []*service.RobotMaintenanceItem{}
+	}
+	h.jsonOK(w, robotMaintenanceView{Enabled: p.Enabled, HoldDueRobots: p.HoldDueRobots, Robots: robots, Items: items})
+}
+
+// apiRobotMaintenanceClose closes a work item as done or dismissed. When it
+// was the last item holding its robot, Close has made the robot available
+// again and released says so.
+func (h *Handlers) apiRobotMaintenanceClose(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.jsonError(w, "invalid maintenance item id", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if !h.parseJSON(w, r, &req) {
+		return
+	}
+	if req.Status != service.MaintenanceItemDone && req.Status != service.MaintenanceItemDismissed {
+		h.jsonError(w, "status must be done or dismissed", http.StatusBadRequest)
+		return
+	}
+	it, released, err := h.engine.RobotMaintenanceService().Close(id, req.Status, h.getUsername(r), req.Note, time.Now())
+	switch {
+	case errors.Is(err, service.ErrMaintenanceItemNotFound):
+		h.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrMaintenanceItemNotOpen):
+		h.jsonError(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, map[string]any{"item": it, "released": released})
+}
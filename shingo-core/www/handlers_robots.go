@@ -348,6 +348,30 @@ func (h *Handlers) apiLocalizationBoard(w http.ResponseWriter, r *http.Request)
 	h.jsonOK(w, board)
 }
 
+// apiMapFixes serves the ranked map-fix recommendations: the persistent
+// localization hotspots and what to change about each, evidence attached.
+//
+// Presets only. A hotspot is judged over days, and a custom range short enough
+// to be interesting is too short to call anything persistent.
+func (h *Handlers) apiMapFixes(w http.ResponseWriter, r *http.Request) {
+	label := r.URL.Query().Get("window")
+	if label == "" {
+		label = "7d"
+	}
+	days, ok := boardWindows[label]
+	if !ok {
+		h.jsonError(w, fmt.Sprintf("window: %q is not one of 7d, 30d", label),
+			http.StatusBadRequest)
+		return
+	}
+	fixes, err := h.engine.NodeService().MapFixesAt(days, time.Now())
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, fixes)
+}
+
 // apiLaneChange serves the change annotation for one lane's most recent edit.
 //
 // Its own endpoint rather than a field on the board payload: most lanes have
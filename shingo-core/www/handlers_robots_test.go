@@ -114,7 +114,7 @@ func testHandlersWithRobotFleet(t *testing.T) (*Handlers, *store.DB, *fakeRobotL
 	h := &Handlers{
 		engine:        eng,
 		orchestration: eng,
-		sessions:      newSessionStore("test-secret"),
+		sessions:      newSessionStore("test-secret", false),
 		tmpls:         make(map[string]*template.Template),
 		eventHub:      hub,
 		debugLog:      dbgLog,
@@ -157,3 +157,26 @@ func TestApiLocalizationBoard_TodayAsEndIsAllowed(t *testing.T) {
 		t.Fatalf("to=today: status %d, want 200; body=%s", rec.Code, rec.Body.String())
 	}
 }
+
+// The map fixes share the board's presets, and refuse what the board refuses.
+// On an empty record the answer is an empty report, not an error.
+func TestApiMapFixes_Presets(t *testing.T) {
+	t.Parallel()
+	h, _ := testHandlers(t)
+
+	for _, tc := range []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"?window=30d", http.StatusOK},
+		{"?window=24h", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/robots/map-fixes"+tc.query, nil)
+		rec := httptest.NewRecorder()
+		h.apiMapFixes(rec, req)
+		if rec.Code != tc.code {
+			t.Fatalf("%s: status %d, want %d; body=%s", tc.query, rec.Code, tc.code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package www
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"shingocore/service"
+)
+
+// The shift reports surface: the archive of end-of-shift operations reports
+// the engine files at each shift change (engine/engine_shift_reports.go), and
+// one report laid out to print.
+//
+// Nothing here builds a report. What a report says is what was known when its
+// shift ended, and a page that rebuilt it on view would quietly disagree with
+// the copy that went out by email.
+
+// shiftReportFilter reads ?shift=&since=&until=&limit= into a filter. since
+// and until are plant-local dates and bound the shift's start.
+func shiftReportFilter(r *http.Request) (service.ShiftReportFilter, error) {
+	q := r.URL.Query()
+	f := service.ShiftReportFilter{Shift: q.Get("shift")}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", v, plantLocation)
+		if err != nil {
+			return f, errors.New(p.name + " must be a date (2006-01-02)")
+		}
+		*p.dst = t
+	}
+	// until names the last day shown, so the bound is the midnight after it.
+	if !f.Until.IsZero() {
+		f.Until = f.Until.AddDate(0, 0, 1)
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return f, errors.New("limit must be a number")
+		}
+		f.Limit = n
+	}
+	return f, nil
+}
+
+// handleShiftReports renders /reports.
+func (h *Handlers) handleShiftReports(w http.ResponseWriter, r *http.Request) {
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	shifts := make([]string, 0, len(cfg.Reports.Shifts))
+	for _, s := range cfg.Reports.Shifts {
+		shifts = append(shifts, s.Name)
+	}
+	enabled := cfg.Reports.Enabled
+	cfg.Unlock()
+
+	q := r.URL.Query()
+	data := map[string]any{
+		"Page":     "reports",
+		"Shifts":   shifts,
+		"Enabled":  enabled,
+		"Timezone": plantLocation.String(),
+		"Shift":    q.Get("shift"),
+		"Since":    q.Get("since"),
+		"Until":    q.Get("until"),
+		"Username": h.getUsername(r),
+	}
+	f, err := shiftReportFilter(r)
+	if err == nil {
+		var list []*service.ShiftReport
+		if list, err = h.engine.ShiftReportService().List(f); err == nil {
+			data["Reports"] = list
+		}
+	}
+	if err != nil {
+		data["ReportError"] = err.Error()
+	}
+	h.render(w, r, "reports.html", data)
+}
+
+// handleShiftReport renders /reports/{id}, the printable report.
+func (h *Handlers) handleShiftReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid report id", http.StatusBadRequest)
+		return
+	}
+	rep, err := h.engine.ShiftReportService().Get(id)
+	if errors.Is(err, service.ErrShiftReportNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	loc, err := time.LoadLocation(rep.Timezone)
+	if err != nil {
+		loc = plantLocation
+	}
+	h.render(w, r, "report.html", map[string]any{
+		"Page":     "reports",
+		"Report":   rep,
+		"Totals":   rep.TotalOrders(),
+		"Window":   rep.Start.In(loc).Format("Mon Jan 2 15:04") + " – " + rep.End.In(loc).Format("Mon Jan 2 15:04") + " " + loc.String(),
+		"Username": h.getUsername(r),
+	})
+}
+
+// apiListShiftReports returns filed reports, latest shift first, filtered as
+// the page is.
+func (h *Handlers) apiListShiftReports(w http.ResponseWriter, r *http.Request) {
+	f, err := shiftReportFilter(r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list, err := h.engine.ShiftReportService().List(f)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []*service.ShiftReport{}
+	}
+	h.jsonOK(w, list)
+}
+
+// apiGetShiftReport returns one report.
+func (h *Handlers) apiGetShiftReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.jsonError(w, "invalid report id", http.StatusBadRequest)
+		return
+	}
+	rep, err := h.engine.ShiftReportService().Get(id)
+	switch {
+	case errors.Is(err, service.ErrShiftReportNotFound):
+		h.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		h.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.jsonOK(w, rep)
+}
@@ -0,0 +1,74 @@
+package www
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"shingocore/service"
+)
+
+// The starvation surface: each cell's downtime split by what the material it
+// was owed was waiting on, per plant-local ISO week, computed on read by
+// service.StarvationService. Read-only.
+
+// defaultStarvationWeeks is how many weeks /starvation shows without ?weeks=.
+const defaultStarvationWeeks = 8
+
+// starvationWeeks reads ?weeks= (default 8) and computes those weeks.
+func (h *Handlers) starvationWeeks(r *http.Request) ([]service.StarvationWeek, error) {
+	n := defaultStarvationWeeks
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil {
+			n = 0 // rejected by Weeks with the range in the message
+		}
+	}
+	return h.engine.StarvationService().Weeks(n, plantLocation, time.Now())
+}
+
+// handleStarvation renders /starvation for ?week= (default the current week).
+func (h *Handlers) handleStarvation(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{
+		"Page":        "starvation",
+		"Timezone":    plantLocation.String(),
+		"Causes":      starvationCauseLabels(),
+		"WeekChoices": []int{4, defaultStarvationWeeks, 13, service.MaxStarvationWeeks},
+		"Username":    h.getUsername(r),
+	}
+	weeks, err := h.starvationWeeks(r)
+	if err != nil {
+		// Shown, not swallowed into an empty table — see handleCycleTime.
+		data["LoadError"] = err.Error()
+		h.render(w, r, "starvation.html", data)
+		return
+	}
+	sel := &weeks[0]
+	if want := r.URL.Query().Get("week"); want != "" {
+		for i := range weeks {
+			if weeks[i].Week == want {
+				sel = &weeks[i]
+			}
+		}
+	}
+	data["Weeks"] = weeks
+	data["WeeksN"] = len(weeks)
+	data["Week"] = sel
+	data["Rows"] = BuildStarvationRows(sel)
+	h.render(w, r, "starvation.html", data)
+}
+
+// apiStarvation returns the last ?weeks= weeks, newest first, per cell.
+func (h *Handlers) apiStarvation(w http.ResponseWriter, r *http.Request) {
+	weeks, err := h.starvationWeeks(r)
+	if err != nil {
+		h.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range weeks {
+		if weeks[i].Cells == nil {
+			weeks[i].Cells = []service.StarvationCell{}
+		}
+	}
+	h.jsonOK(w, weeks)
+}
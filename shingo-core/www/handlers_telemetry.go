@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"shingocore/dispatch/eta"
 	"shingocore/domain"
 	"shingocore/service"
 )
@@ -522,6 +523,43 @@ func (h *Handlers) apiBinClear(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// transitRouteETA is one route's answer on /telemetry/transit-eta.
+type transitRouteETA struct {
+	Source     string `json:"source"`
+	Delivery   string `json:"delivery"`
+	Seconds    int64  `json:"seconds"`
+	RouteKnown bool   `json:"route_known"`
+}
+
+// apiTelemetryTransitETA returns Core's padded transit estimate for each
+// requested route — the same p70 the operator ETA pills are stamped from.
+// Edge's production-schedule planner sizes its pre-stage lead with it.
+// GET /api/telemetry/transit-eta?route=SOURCE,DELIVERY&route=...
+//
+// Registered with the engine's cache rather than reaching it through h.engine:
+// one read-only lookup is not worth widening ServiceAccess for. route_known is
+// false when the answer is the global fallback, so the planner can tell a
+// measured route from a guess.
+func (h *Handlers) apiTelemetryTransitETA(cache *eta.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		routes := r.URL.Query()["route"]
+		out := make([]transitRouteETA, 0, len(routes))
+		for _, route := range routes {
+			source, delivery, ok := strings.Cut(route, ",")
+			if !ok || source == "" || delivery == "" {
+				h.jsonError(w, fmt.Sprintf("route %q must be SOURCE,DELIVERY", route), http.StatusBadRequest)
+				return
+			}
+			d, known := cache.Lookup(source, delivery)
+			out = append(out, transitRouteETA{
+				Source: source, Delivery: delivery,
+				Seconds: int64(d / time.Second), RouteKnown: known,
+			})
+		}
+		h.jsonOK(w, out)
+	}
+}
+
 // ── E-Maint Robot Telemetry ──────────────────────────────────
 //
 // Generates an on-demand telemetry snapshot from the in-memory robot cache.
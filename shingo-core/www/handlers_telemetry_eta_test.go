@@ -0,0 +1,51 @@
+package www
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shingocore/dispatch/eta"
+)
+
+// A cold cache still answers every route — with the fallback, and saying so.
+// Edge sizes a pre-stage lead from this; a missing row would read as "no
+// transit time at all".
+func TestTelemetryTransitETA_ColdCacheFallsBack(t *testing.T) {
+	t.Parallel()
+	h := &Handlers{}
+	req := httptest.NewRequest(http.MethodGet, "/api/telemetry/transit-eta?route=SMKT-1,PRESS-4&route=SMKT-2,PRESS-5", nil)
+	rec := httptest.NewRecorder()
+	h.apiTelemetryTransitETA(eta.NewCache(nil)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var got []transitRouteETA
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d routes, want 2", len(got))
+	}
+	for _, r := range got {
+		if r.Seconds <= 0 || r.RouteKnown {
+			t.Errorf("route %s→%s = %+v, want a positive fallback with route_known=false", r.Source, r.Delivery, r)
+		}
+	}
+	if got[0].Source != "SMKT-1" || got[0].Delivery != "PRESS-4" {
+		t.Errorf("first route = %+v, want request order preserved", got[0])
+	}
+}
+
+func TestTelemetryTransitETA_RejectsMalformedRoute(t *testing.T) {
+	t.Parallel()
+	h := &Handlers{}
+	req := httptest.NewRequest(http.MethodGet, "/api/telemetry/transit-eta?route=PRESS-4", nil)
+	rec := httptest.NewRecorder()
+	h.apiTelemetryTransitETA(eta.NewCache(nil)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"shingo/protocol"
+	"shingo/protocol/i18n"
 	"shingo/shared"
 	"shingocore/dispatch"
 	"shingocore/domain"
@@ -171,6 +172,9 @@ func templateFuncs(namer stationNamer) template.FuncMap {
 		"cacheBust":  func() string { return fmt.Sprintf("%x", time.Now().UnixNano()) },
 		"iconSprite": func() template.HTML { return iconSpriteHTML },
 		"canCancel":  canCancelStatus,
+		"lang":       langFuncs(i18n.English)["lang"],
+		"t":          langFuncs(i18n.English)["t"],
+		"messages":   langFuncs(i18n.English)["messages"],
 
 		// stationName renders the operator's label for a station identity.
 		//
@@ -266,6 +270,12 @@ func templateFuncs(namer stationNamer) template.FuncMap {
 		"f1": func(f float64) string {
 			return fmt.Sprintf("%.1f", f)
 		},
+		// minSec renders a duration in seconds as m:ss — delivery times on the
+		// shift reports, where "7:42" reads faster than "462.0 s".
+		"minSec": func(sec float64) string {
+			s := int(sec + 0.5)
+			return fmt.Sprintf("%d:%02d", s/60, s%60)
+		},
 		// f2 prints a localization confidence exactly as the vendor publishes
 		// it. Two decimals and no rescaling to a percentage: the figure comes
 		// from an upstream system and an operator comparing this tile against
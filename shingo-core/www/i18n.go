@@ -0,0 +1,62 @@
+package www
+
+import (
+	"html/template"
+	"net/http"
+
+	"shingo/protocol/i18n"
+	"shingocore/locales"
+)
+
+// langFuncs are the template funcs whose output depends on the language.
+// templateFuncs carries the English set, so every page parses with them;
+// localizeTemplates rebinds them per language.
+func langFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"lang": func() string { return lang },
+		"t": func(key string, args ...any) string {
+			return locales.Catalog.T(lang, key, args...)
+		},
+		// messages is the whole catalog in lang, for the page to embed as
+		// #shingo-messages: static/i18n.js t() reads it for the text the scripts
+		// build.
+		"messages": func() map[string]string { return locales.Catalog.Messages(lang) },
+	}
+}
+
+// localizeTemplates clones every page once per catalog language other than
+// English. A clone shares the parse trees, so this costs the func binding and
+// nothing else.
+func localizeTemplates(tmpls map[string]*template.Template) map[string]map[string]*template.Template {
+	out := make(map[string]map[string]*template.Template)
+	for _, lang := range locales.Catalog.Languages() {
+		if lang == i18n.English {
+			continue
+		}
+		out[lang] = make(map[string]*template.Template, len(tmpls))
+		for name, tmpl := range tmpls {
+			out[lang][name] = template.Must(tmpl.Clone()).Funcs(langFuncs(lang))
+		}
+	}
+	return out
+}
+
+// requestLang is the language a page renders in: an explicit ?lang= — how a
+// kiosk URL is pinned to the crew that reads it — then the browser's
+// Accept-Language, then English.
+func requestLang(r *http.Request) string {
+	if lang := locales.Catalog.Match(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language")); lang != "" {
+		return lang
+	}
+	return i18n.English
+}
+
+// page returns name's template in lang, the English parse when lang has no
+// clones.
+func (h *Handlers) page(lang, name string) (*template.Template, bool) {
+	if tmpl, ok := h.localized[lang][name]; ok {
+		return tmpl, true
+	}
+	tmpl, ok := h.tmpls[name]
+	return tmpl, ok
+}
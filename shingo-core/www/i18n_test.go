@@ -0,0 +1,362 @@
+package www
+
+import (
+	"encoding/json"
+	"html/template"
+	"io/fs"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+
+	"shingo/protocol/i18n"
+	"shingocore/locales"
+)
+
+// kioskTemplates are the pages renderBare serves — the ones the floor reads,
+// and so the ones whose every string must go through the catalog.
+func kioskTemplates(t *testing.T) []string {
+	t.Helper()
+	pages, err := fs.Glob(templateFS, "templates/dashboard-*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, p := range pages {
+		if p != "templates/dashboard-frame.html" { // admin chrome around the kiosk page
+			out = append(out, p)
+		}
+	}
+	return append(out, "templates/heartbeat.html")
+}
+
+// chromeTemplates are the admin side's shared chrome: the nav every admin page
+// carries, the login form, and the frame around a wall display. The admin page
+// bodies inside that chrome are not translated yet, so they are not listed.
+var chromeTemplates = []string{
+	"templates/layout.html",
+	"templates/login.html",
+	"templates/dashboard-frame.html",
+}
+
+var (
+	templateKeyRef = regexp.MustCompile(`\{\{-?\s*t\s+"([^"]+)"`)
+	templateAction = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+	skippedBlock   = regexp.MustCompile(`(?is)<script.*?</script>|<style.*?</style>|<!--.*?-->`)
+	charEntity     = regexp.MustCompile(`&#?[0-9A-Za-z]+;`)
+	textNode       = regexp.MustCompile(`>([^<>]*)<`)
+	textAttr       = regexp.MustCompile(`\b(title|placeholder|aria-label|alt|data-live|data-offline)="([^"]*)"`)
+	hasLetter      = regexp.MustCompile(`\pL`)
+)
+
+// TestTemplateKeysExistInCatalog: every {{t "key"}} in any template names a
+// key en.json has; a missing one renders as the raw key.
+func TestTemplateKeysExistInCatalog(t *testing.T) {
+	err := fs.WalkDir(templateFS, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(templateFS, p)
+		if err != nil {
+			return err
+		}
+		for _, m := range templateKeyRef.FindAllStringSubmatch(string(body), -1) {
+			if !locales.Catalog.Has(i18n.English, m[1]) {
+				t.Errorf("%s: key %q is not in locales/en.json", p, m[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTemplatesHaveNoUntranslatedText fails on a kiosk or chrome string typed
+// straight into the markup: with the template actions taken out, no text node
+// and no human-read attribute may still contain a letter.
+func TestTemplatesHaveNoUntranslatedText(t *testing.T) {
+	for _, p := range append(kioskTemplates(t), chromeTemplates...) {
+		body, err := fs.ReadFile(templateFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := templateAction.ReplaceAllString(string(body), "")
+		src = skippedBlock.ReplaceAllString(src, "")
+		src = charEntity.ReplaceAllString(src, "")
+		for _, m := range textNode.FindAllStringSubmatch(src, -1) {
+			if text := strings.TrimSpace(m[1]); hasLetter.MatchString(text) {
+				t.Errorf("%s: text %q is not a catalog key — wrap it in {{t \"...\"}}", p, text)
+			}
+		}
+		for _, m := range textAttr.FindAllStringSubmatch(src, -1) {
+			if hasLetter.MatchString(m[2]) {
+				t.Errorf("%s: %s=%q is not a catalog key — wrap it in {{t \"...\"}}", p, m[1], m[2])
+			}
+		}
+	}
+}
+
+var (
+	kioskScriptRef = regexp.MustCompile(`src="/(static/[\w/-]+\.js)`)
+	importRef      = regexp.MustCompile(`(?m)^\s*(?:import|export)\b[^'"]*from\s+'/(static/[\w/-]+\.js)'`)
+)
+
+// kioskScripts are the scripts a kiosk page loads and every script they
+// import, shared/ aside: those helpers build no text a crew reads.
+func kioskScripts(t *testing.T) []string {
+	t.Helper()
+	seen := map[string]bool{}
+	var out, queue []string
+	for _, p := range kioskTemplates(t) {
+		body, err := fs.ReadFile(templateFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range kioskScriptRef.FindAllStringSubmatch(string(body), -1) {
+			queue = append(queue, m[1])
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if seen[p] || path.Dir(p) == "static/shared" {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+		body, err := fs.ReadFile(staticFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range importRef.FindAllStringSubmatch(string(body), -1) {
+			queue = append(queue, m[1])
+		}
+	}
+	if len(out) == 0 {
+		t.Fatal("no kiosk template loads a script — kioskScriptRef no longer matches")
+	}
+	return out
+}
+
+var (
+	scriptKeyRef = regexp.MustCompile(`\bt\('([^']+)'`)
+	htmlTag      = regexp.MustCompile(`<[^<>]*>|<[^<>]*$|^[^<>]*>`)
+	consoleCall  = regexp.MustCompile(`console\.\w+\(\s*$`)
+	wordEdgeTrim = "([{\"'`" + `)]}.,:;!?…`
+	englishWord  = regexp.MustCompile(`^\pL+(?:['’-]\pL+)*$`)
+)
+
+// scriptLiteralAllowed are the quoted strings in the kiosk scripts that read
+// as English but are not shown to anyone.
+var scriptLiteralAllowed = map[string]string{
+	"DOMContentLoaded": "an event name",
+	"Escape":           "a KeyboardEvent.key value",
+	"AbortError":       "an Error.name value",
+	"Enter":            "a KeyboardEvent.key value",
+	"Content-Type":     "a request header name",
+	"GET":              "an HTTP method",
+	"POST":             "an HTTP method",
+	"PUT":              "an HTTP method",
+	"DELETE":           "an HTTP method",
+	"xMidYMid meet":    "an SVG preserveAspectRatio value",
+	"ActionPoint":      "a scene point class from the fleet",
+	"ChargePoint":      "a scene point class from the fleet",
+	"ParkPoint":        "a scene point class from the fleet",
+	"LocationMark":     "a scene point class from the fleet",
+	"GeneralLocation":  "a scene point class from the fleet",
+	"HTTP ":            "prefixes a status code, the protocol's word",
+}
+
+// TestKioskScriptKeysExistInCatalog: every t('key') in a kiosk script names
+// a key en.json has.
+func TestKioskScriptKeysExistInCatalog(t *testing.T) {
+	for _, p := range kioskScripts(t) {
+		body, err := fs.ReadFile(staticFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range scriptKeyRef.FindAllStringSubmatch(string(body), -1) {
+			if !locales.Catalog.Has(i18n.English, m[1]) {
+				t.Errorf("%s: key %q is not in locales/en.json", p, m[1])
+			}
+		}
+	}
+}
+
+// TestKioskScriptsHaveNoUntranslatedText is the script-side twin of the
+// template test, and the same check as Edge's for its operator scripts: a
+// quoted string in a kiosk script that still reads as English once its markup
+// is taken out fails, unless it is a t() key. Reading as English is a
+// capitalised word ("Loaded", "Total") or two words in a row ("cells behind
+// plan"); class lists, CSS, URLs and status codes are single tokens and pass.
+// Console output is for whoever has the devtools open and passes too.
+func TestKioskScriptsHaveNoUntranslatedText(t *testing.T) {
+	for _, p := range kioskScripts(t) {
+		body, err := fs.ReadFile(staticFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, lit := range scriptLiterals(string(body)) {
+			if consoleCall.MatchString(lit.before) {
+				continue
+			}
+			if _, ok := scriptLiteralAllowed[lit.text]; ok {
+				continue
+			}
+			if readsAsEnglish(lit.text) {
+				t.Errorf("%s:%d: %q is not a catalog key — use t('...')", p, lit.line, lit.text)
+			}
+		}
+	}
+}
+
+// readsAsEnglish reports whether s, with its markup taken out, has a
+// capitalised word of two letters or more or two plain words in a row.
+func readsAsEnglish(s string) bool {
+	words := 0
+	for _, tok := range strings.Fields(htmlTag.ReplaceAllString(s, " ")) {
+		tok = strings.Trim(tok, wordEdgeTrim)
+		if englishWord.MatchString(tok) && len([]rune(tok)) > 1 && unicode.IsUpper([]rune(tok)[0]) {
+			return true
+		}
+		if tok == "" || strings.IndexFunc(tok, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			words = 0
+			continue
+		}
+		if words++; words == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+type scriptLiteral struct {
+	text   string
+	line   int
+	before string // the code on the line up to the opening quote
+}
+
+// scriptLiterals returns the string literals in a script, skipping comments
+// and regular expressions. A template literal's ${...} holes are left in its
+// text.
+func scriptLiterals(src string) []scriptLiteral {
+	var out []scriptLiteral
+	line, lineStart := 1, 0
+	prev := byte(0) // last significant code byte, to tell a regex from division
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line, lineStart = line+1, i+1
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return out
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += 2 + end + 1
+		case c == '/' && strings.IndexByte("(,=:[!&|?{};", prev) >= 0:
+			for i++; i < len(src) && src[i] != '/'; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '[' {
+					for i < len(src) && src[i] != ']' {
+						i++
+					}
+				}
+			}
+			prev = '/'
+		case c == '\'' || c == '"' || c == '`':
+			start, startLine, before := i+1, line, src[lineStart:i]
+			for i++; i < len(src) && src[i] != c; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '\n' {
+					line, lineStart = line+1, i+1
+				}
+			}
+			out = append(out, scriptLiteral{text: src[start:min(i, len(src))], line: startLine, before: before})
+			prev = c
+		case c != ' ' && c != '\t' && c != '\r':
+			prev = c
+		}
+	}
+	return out
+}
+
+// TestRenderBare_FollowsTheKioskLanguage renders the heartbeat the way a wall
+// monitor pinned to ?lang=ja asks for it, and the way a browser that says
+// nothing useful does.
+func TestRenderBare_FollowsTheKioskLanguage(t *testing.T) {
+	base := template.Must(template.New("").Funcs(templateFuncs(nil)).
+		ParseFS(templateFS, "templates/layout.html", "templates/partials/*.html"))
+	tmpls := map[string]*template.Template{
+		"heartbeat.html": template.Must(template.Must(base.Clone()).ParseFS(templateFS, "templates/heartbeat.html")),
+	}
+	h := &Handlers{tmpls: tmpls, localized: localizeTemplates(tmpls)}
+
+	cases := []struct{ url, accept, lang string }{
+		{"/?lang=ja", "es-MX,es;q=0.9", "ja"},
+		{"/", "es-MX,es;q=0.9", "es"},
+		{"/", "fr-FR", "en"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.url, nil)
+		req.Header.Set("Accept-Language", tc.accept)
+		rec := httptest.NewRecorder()
+		h.renderBare(rec, req, "heartbeat.html", map[string]any{})
+		body := rec.Body.String()
+		if !strings.Contains(body, `lang="`+tc.lang+`"`) {
+			t.Errorf("%s %q: page is not lang=%q", tc.url, tc.accept, tc.lang)
+		}
+		if want := template.HTMLEscapeString(locales.Catalog.T(tc.lang, "heartbeat.title")); !strings.Contains(body, want) {
+			t.Errorf("%s %q: page lacks %q", tc.url, tc.accept, want)
+		}
+	}
+}
+
+// TestRender_FollowsTheRequestLanguage: the admin chrome picks its language
+// the way a kiosk does, and every page embeds that language's catalog for
+// the scripts' t().
+func TestRender_FollowsTheRequestLanguage(t *testing.T) {
+	base := template.Must(template.New("").Funcs(templateFuncs(nil)).
+		ParseFS(templateFS, "templates/layout.html", "templates/partials/*.html"))
+	tmpls := map[string]*template.Template{
+		"login.html": template.Must(template.Must(base.Clone()).ParseFS(templateFS, "templates/login.html")),
+	}
+	h := &Handlers{tmpls: tmpls, localized: localizeTemplates(tmpls)}
+
+	for _, lang := range []string{"ja", "es", "en"} {
+		req := httptest.NewRequest("GET", "/login?lang="+lang, nil)
+		rec := httptest.NewRecorder()
+		h.render(rec, req, "login.html", map[string]any{"Page": "login", "Authenticated": false})
+		body := rec.Body.String()
+		if !strings.Contains(body, `lang="`+lang+`"`) {
+			t.Errorf("%s: page is not lang=%q", lang, lang)
+		}
+		if want := template.HTMLEscapeString(locales.Catalog.T(lang, "nav.dashboard")); !strings.Contains(body, want) {
+			t.Errorf("%s: nav lacks %q", lang, want)
+		}
+		m := embeddedCatalog.FindStringSubmatch(body)
+		if m == nil {
+			t.Fatalf("%s: no #shingo-messages in the page", lang)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal([]byte(m[1]), &messages); err != nil {
+			t.Fatalf("%s: #shingo-messages is not JSON: %v", lang, err)
+		}
+		if got, want := messages["common.cancel"], locales.Catalog.T(lang, "common.cancel"); got != want {
+			t.Errorf("%s: embedded common.cancel = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+var embeddedCatalog = regexp.MustCompile(`(?s)<script type="application/json" id="shingo-messages">(.*?)</script>`)
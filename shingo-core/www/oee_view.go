@@ -0,0 +1,85 @@
+package www
+
+import (
+	"fmt"
+
+	"shingocore/domain"
+	"shingocore/service"
+)
+
+// oee_view.go — /oee's rows. Every factor renders through the number
+// doctrine's Cell: a factor domain.ComputeOEE could not compute is a NoData
+// carrying its reason, never a 0% or a 100%.
+
+// OEERow is one cell's figures for one shift, or for the day.
+type OEERow struct {
+	CellID string
+	Shift  string
+	// IsDay marks the day total under a cell's shift rows.
+	IsDay bool
+
+	Planned Cell
+	Down    Cell
+	Starved Cell
+	Parts   Cell
+
+	Availability             Cell
+	AvailabilityExStarvation Cell
+	Performance              Cell
+	Quality                  Cell
+	AvailabilityPerformance  Cell
+	OEE                      Cell
+}
+
+// BuildOEERows lays out a day: each cell's shifts in order, then its day row.
+func BuildOEERows(day *service.OEEDay) []OEERow {
+	var rows []OEERow
+	for _, c := range day.Cells {
+		for _, s := range c.Shifts {
+			rows = append(rows, buildOEERow(c.CellID, s.Shift, false, s.OEEFigures))
+		}
+		rows = append(rows, buildOEERow(c.CellID, "Day", true, c.Day))
+	}
+	return rows
+}
+
+func buildOEERow(cell, shift string, isDay bool, f domain.OEEFigures) OEERow {
+	row := OEERow{
+		CellID:  cell,
+		Shift:   shift,
+		IsDay:   isDay,
+		Planned: Value(fmt.Sprintf("%.0f", f.PlannedMinutes)),
+		Down:    Value(fmt.Sprintf("%.0f", f.DowntimeMinutes)),
+		Starved: Value(fmt.Sprintf("%.0f", f.StarvedMinutes)),
+		Parts:   Value(FormatCount(int(f.Parts))),
+	}
+	if f.DowntimeMinutes > 0 {
+		row.Starved.Title = "downtime that overlapped a demand episode waiting on material at this cell"
+	}
+
+	noPlanned := "no planned time in this window"
+	row.Availability = oeePct(f.HaveAvailability, f.Availability, noPlanned)
+	row.AvailabilityExStarvation = oeePct(f.HaveAvailability, f.AvailabilityExStarvation, noPlanned)
+	row.Performance = oeePct(f.HavePerformance, f.Performance, f.PerformanceReason)
+	row.Quality = oeePct(f.HaveQuality, f.Quality, f.QualityReason)
+	row.AvailabilityPerformance = oeePct(f.HaveAvailabilityPerformance, f.AvailabilityPerformance,
+		"needs both availability and performance")
+
+	why := "needs availability, performance and quality"
+	switch {
+	case !f.HavePerformance:
+		why = "performance: " + f.PerformanceReason
+	case !f.HaveQuality:
+		why = "quality: " + f.QualityReason
+	}
+	row.OEE = oeePct(f.HaveOEE, f.OEE, why)
+	return row
+}
+
+// oeePct renders a ratio as a percentage, or the reason it is absent.
+func oeePct(have bool, v float64, why string) Cell {
+	if !have {
+		return NoData(why)
+	}
+	return Value(fmt.Sprintf("%.1f%%", v*100))
+}
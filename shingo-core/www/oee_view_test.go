@@ -0,0 +1,41 @@
+package www
+
+import (
+	"strings"
+	"testing"
+
+	"shingocore/domain"
+	"shingocore/service"
+)
+
+// TestOEERowsRenderAbsenceWithItsReason: a cell that reports no scrap gets
+// A×P as a value and OEE as a dash whose title says it was quality that was
+// missing — never 0% and never A×P passed off as OEE. The day row follows the
+// cell's shifts.
+func TestOEERowsRenderAbsenceWithItsReason(t *testing.T) {
+	f := domain.OEEFigures{
+		PlannedMinutes: 480, RunMinutes: 432,
+		HaveAvailability: true, Availability: 0.9, AvailabilityExStarvation: 0.95,
+		HavePerformance: true, Performance: 0.8,
+		HaveAvailabilityPerformance: true, AvailabilityPerformance: 0.72,
+		QualityReason: "no scrap reported for this cell in this window",
+	}
+	rows := BuildOEERows(&service.OEEDay{Cells: []service.OEECell{{
+		CellID: "SPR-1",
+		Shifts: []service.OEEShiftFigures{{Shift: "1st", OEEFigures: f}},
+		Day:    f,
+	}}})
+	if len(rows) != 2 || rows[0].Shift != "1st" || !rows[1].IsDay {
+		t.Fatalf("rows = %+v, want the shift then the day", rows)
+	}
+	r := rows[0]
+	if r.AvailabilityPerformance.Kind != CellValue || r.AvailabilityPerformance.Text != "72.0%" {
+		t.Errorf("A×P = %+v, want 72.0%%", r.AvailabilityPerformance)
+	}
+	if r.OEE.Kind != CellNoData || !strings.Contains(r.OEE.Title, "quality") {
+		t.Errorf("OEE = %+v, want no-data naming quality", r.OEE)
+	}
+	if r.Quality.Kind != CellNoData || r.Quality.Title != f.QualityReason {
+		t.Errorf("quality = %+v, want no-data with the domain's reason", r.Quality)
+	}
+}
@@ -2,32 +2,19 @@ package www
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
-	"os"
 	"time"
-)
 
-// plantLocation is the plant's IANA timezone, resolved once from the
-// PLANT_TIMEZONE env var (default America/Chicago). The dashboards follow a
-// plant-local-at-server convention (Q-004): timestamps are stored UTC, but
-// bare YYYY-MM-DD date filters from the URL resolve in THIS zone — so "Today"
-// means the plant's calendar day, not the server's (which runs UTC). Without
-// this, a CST plant on a UTC server saw "Today" start at 6pm the prior day.
-var plantLocation = loadPlantLocation()
+	"shingocore/config"
+)
 
-func loadPlantLocation() *time.Location {
-	name := os.Getenv("PLANT_TIMEZONE")
-	if name == "" {
-		name = "America/Chicago"
-	}
-	loc, err := time.LoadLocation(name)
-	if err != nil {
-		log.Printf("www: PLANT_TIMEZONE %q invalid (%v); falling back to UTC", name, err)
-		return time.UTC
-	}
-	return loc
-}
+// plantLocation is the plant's IANA timezone (config.PlantLocation, from the
+// PLANT_TIMEZONE env var). The dashboards follow a plant-local-at-server
+// convention (Q-004): timestamps are stored UTC, but bare YYYY-MM-DD date
+// filters from the URL resolve in THIS zone — so "Today" means the plant's
+// calendar day, not the server's (which runs UTC). Without this, a CST plant
+// on a UTC server saw "Today" start at 6pm the prior day.
+var plantLocation = config.PlantLocation()
 
 // plantDayStart truncates t to midnight in the plant timezone. parseMissionFilter
 // normalizes its date filters to UTC, so truncating in the raw (UTC) location
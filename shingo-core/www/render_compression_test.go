@@ -26,7 +26,7 @@ func renderCompressionHandlers(t *testing.T) *Handlers {
 			"page": template.Must(template.New("layout").Parse(body)),
 			"bare": template.Must(template.New("bare").Parse(body)),
 		},
-		sessions: newSessionStore("render-compression-test"),
+		sessions: newSessionStore("render-compression-test", false),
 	}
 }
 
@@ -63,7 +63,7 @@ func TestRenderPathsAreCompressed(t *testing.T) {
 			h.render(w, r, "page", map[string]any{})
 		}},
 		{"renderBare", func(w http.ResponseWriter, r *http.Request) {
-			h.renderBare(w, "bare", map[string]any{})
+			h.renderBare(w, r, "bare", map[string]any{})
 		}},
 	}
 
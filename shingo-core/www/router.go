@@ -12,6 +12,7 @@ import (
 	"github.com/gorilla/sessions"
 
 	"shingo/protocol/debuglog"
+	"shingo/protocol/webtls"
 	"shingo/shared"
 	"shingocore/engine"
 )
@@ -43,6 +44,7 @@ type Handlers struct {
 	orchestration EngineOrchestration
 	sessions      *sessions.CookieStore
 	tmpls         map[string]*template.Template
+	localized     map[string]map[string]*template.Template // language → page; see localizeTemplates
 	eventHub      *EventHub
 	debugLog      *debuglog.Logger
 }
@@ -70,7 +72,8 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 		hub.Broadcast("debug-log", sseJSON(e))
 	})
 
-	sessionStore := newSessionStore(eng.AppConfig().Web.SessionSecret)
+	web := eng.AppConfig().Web
+	sessionStore := newSessionStore(web.SessionSecret, web.TLS.Enabled)
 
 	// Parse layout + partials as a base template set. Each page is cloned separately
 	// to avoid the "last define wins" problem with {{define "content"}}.
@@ -99,6 +102,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 		orchestration: eng, // EngineOrchestration — wide surface for flow handlers
 		sessions:      sessionStore,
 		tmpls:         tmpls,
+		localized:     localizeTemplates(tmpls),
 		eventHub:      hub,
 		debugLog:      dbg,
 	}
@@ -107,6 +111,9 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 
 	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
+	if web.TLS.Enabled {
+		r.Use(webtls.HSTS)
+	}
 
 	// SSE — must be outside compression middleware. Compression buffers
 	// defeat streaming flushes and cause stale connection buildup when
@@ -159,6 +166,12 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 		r.Get("/orphans", h.handleOrphans)
 		// Phase 6 (5.10): cycle time from the applied-BinUOPDelta audit trail.
 		r.Get("/cycle-time", h.handleCycleTime)
+		// OEE per cell, shift and day, with starvation split out of
+		// availability. See handlers_oee.go.
+		r.Get("/oee", h.handleOEE)
+		// Starved downtime by cause, per cell and week. See
+		// handlers_starvation.go.
+		r.Get("/starvation", h.handleStarvation)
 		// Phase 6 (5.11): the two readings a starved cell can produce, kept
 		// apart — an open demand episode past its worry line, and a carrier
 		// whose binding ShinGo has held long enough for the count to have
@@ -278,6 +291,11 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 			// The change annotation for one lane, on demand -- most lanes have
 			// never been edited, so it is not folded into the board payload.
 			r.Get("/robots/lane-change", h.apiLaneChange)
+			// Where the fleet persistently loses its position, and the ranked
+			// map fixes for it.
+			r.Get("/robots/map-fixes", h.apiMapFixes)
+			// Predictive maintenance: indicators and open work items.
+			r.Get("/robots/maintenance", h.apiRobotMaintenance)
 
 			// Operations Overview (plant footprint)
 			r.Get("/footprint", h.apiFootprint)
@@ -325,7 +343,16 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 			r.Get("/dashboards", h.apiListDashboards)
 			r.Get("/dashboards/{id}", h.apiGetDashboard)
 			r.Get("/dashboards/{id}/cells", h.apiDashboardCells) // refactor #4: per-dashboard heartbeat cells
+			r.Get("/dashboards/kinds", h.apiDashboardKinds)
 			r.Get("/dashboards/{id}/node-report", h.apiDashboardNodeReport)
+			// The five config-schema kinds' board data (handlers_dashboard_kinds.go).
+			r.Get("/dashboards/{id}/lineside", h.apiDashboardLineside)
+			r.Get("/dashboards/{id}/lanes", h.apiDashboardLanes)
+			r.Get("/dashboards/{id}/production", h.apiDashboardProduction)
+			r.Get("/dashboards/{id}/alerts", h.apiDashboardAlerts)
+			r.Get("/dashboards/{id}/fleet", h.apiDashboardFleet)
+			// A past window of the task board or robot map, for ?replay=1.
+			r.Get("/dashboards/{id}/replay", h.apiDashboardReplay)
 
 			// Payloads & manifest
 			r.Get("/payloads/templates", h.apiListPayloads)
@@ -351,6 +378,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 			r.Post("/telemetry/bin-count", h.apiBinCount)
 			r.Get("/telemetry/e-maint", h.apiEMaintRobotTelemetry)
 			r.Get("/telemetry/e-maint/download", h.apiEMaintRobotTelemetryDownload)
+			r.Get("/telemetry/transit-eta", h.apiTelemetryTransitETA(eng.EtaCache()))
 
 			// Inventory & diagnostics
 			r.Get("/inventory", h.apiInventory)
@@ -380,6 +408,35 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 			// Demands
 			r.Get("/demands", h.apiListDemands)
 
+			// Quality holds — reads are public so the floor can look up why
+			// material is held; every write is in the auth group below.
+			r.Get("/quality-holds", h.apiListQualityHolds)
+			r.Get("/quality-holds/{id}", h.apiGetQualityHold)
+
+			// Alert history — public for the same reason; acknowledging is
+			// in the auth group below.
+			r.Get("/alerts", h.apiListAlerts)
+
+			// End-of-shift reports — read-only; the engine files them.
+			r.Get("/reports", h.apiListShiftReports)
+			r.Get("/reports/{id}", h.apiGetShiftReport)
+
+			// OEE — computed on read; the inputs are written in the auth
+			// group below.
+			r.Get("/oee", h.apiOEE)
+			r.Get("/oee/standards", h.apiListOEEStandards)
+
+			// Starvation root cause — computed on read, nothing to write.
+			r.Get("/starvation", h.apiStarvation)
+
+			// Demand forecast — threshold suggestions, computed on read. The
+			// threshold itself is written through /loader/set-* below.
+			r.Get("/demand-forecast", h.apiDemandForecast)
+
+			// Analytics export — the dataset schema; the download is in the
+			// auth group below.
+			r.Get("/analytics-export", h.apiAnalyticsExport)
+
 			// ── Protected API (auth required) ──────────────────
 			r.Group(func(r chi.Router) {
 				r.Use(h.requireAuth)
@@ -387,6 +444,9 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 				// Inventory export
 				r.Get("/inventory/export", h.apiInventoryExport)
 
+				// Analytics export — every day in the range, streamed as a zip
+				r.Get("/analytics-export/download", h.apiAnalyticsExportDownload)
+
 				// Cells — production-cell config (Phase E, Q-025)
 				r.Get("/cells/processes", h.apiCellProcesses)
 				r.Post("/cells", h.apiCellUpsert)
@@ -501,6 +561,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 				r.Post("/robots/retry", h.apiRobotRetryFailed)
 				r.Post("/robots/force-complete", h.apiRobotForceComplete)
 				r.Post("/robots/move", h.apiRobotMoveTo)
+				r.Post("/robots/maintenance/{id}/close", h.apiRobotMaintenanceClose)
 
 				// Orders
 				r.Post("/orders/terminate", h.apiTerminateOrder)
@@ -527,6 +588,23 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 				r.Post("/demands/{id}/clear", h.apiClearDemandProduced)
 				r.Post("/demands/clear-all", h.apiClearAllProduced)
 
+				// Quality holds (write). Approve and reject additionally pass
+				// QualityHoldService's approver rule.
+				r.Post("/quality-holds", h.apiPlaceQualityHold)
+				r.Post("/quality-holds/{id}/request-disposition", h.apiRequestQualityDisposition)
+				r.Post("/quality-holds/{id}/approve", h.apiApproveQualityDisposition)
+				r.Post("/quality-holds/{id}/reject", h.apiRejectQualityDisposition)
+
+				// Alerts (acknowledge). The actor is the signed-in user.
+				r.Post("/alerts/{id}/ack", h.apiAcknowledgeAlert)
+
+				// OEE inputs — engineered cycle times and scrap reports. The
+				// path says "standards", not "cycle": an engineered standard is
+				// not the measurement /cycle-time reports (cycle_naming_test.go).
+				r.Put("/oee/standards/{cell}/{style}", h.apiSetOEEStandard)
+				r.Delete("/oee/standards/{cell}/{style}", h.apiDeleteOEEStandard)
+				r.Post("/oee/scrap", h.apiRecordOEEScrap)
+
 				// Dashboards (write) — management CRUD behind auth. Reads
 				// live in the public API group above.
 				r.Post("/dashboards", h.apiCreateDashboard)
@@ -544,11 +622,16 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 			r.Get("/payloads", h.handlePayloadsPage)
 			r.Get("/sourcing", h.handleSourcing)
 			r.Get("/bins", h.handleBins)
+			r.Get("/quality-holds", h.handleQualityHolds)
+			r.Get("/alerts", h.handleAlerts)
+			r.Get("/reports", h.handleShiftReports)
+			r.Get("/reports/{id}", h.handleShiftReport)
 			r.Get("/diagnostics", h.handleDiagnostics)
 			r.Get("/config", h.handleConfig)
 			r.Post("/config/save", h.handleConfigSave)
 			r.Post("/config/test-email", h.handleConfigTestEmail)
 			r.Post("/config/test-alert", h.handleConfigTestAlert)
+			r.Post("/config/test-channel", h.handleConfigTestChannel)
 			r.Post("/config/password", h.handleConfigPassword)
 			r.Get("/fleet-explorer", h.handleFleetExplorer)
 			r.Get("/admin/cells", h.handleCellsAdmin)
@@ -585,7 +668,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger) (http.Handler, func(),
 }
 
 func (h *Handlers) render(w http.ResponseWriter, r *http.Request, name string, data map[string]any) {
-	tmpl, ok := h.tmpls[name]
+	tmpl, ok := h.page(requestLang(r), name)
 	if !ok {
 		log.Printf("render: template %q not found", name)
 		http.Error(w, "template not found", http.StatusInternalServerError)
@@ -613,8 +696,11 @@ func (h *Handlers) render(w http.ResponseWriter, r *http.Request, name string, d
 // kiosk/display surfaces — the dashboard displays — that must not carry the
 // admin nav. The template is a full <!DOCTYPE> document with no
 // {{define "content"}} wrapper.
-func (h *Handlers) renderBare(w http.ResponseWriter, name string, data map[string]any) {
-	tmpl, ok := h.tmpls[name]
+//
+// Like render, it picks the page's language per request (see requestLang);
+// ?lang= on the URL is how a wall monitor is pinned to its crew's language.
+func (h *Handlers) renderBare(w http.ResponseWriter, r *http.Request, name string, data map[string]any) {
+	tmpl, ok := h.page(requestLang(r), name)
 	if !ok {
 		log.Printf("renderBare: template %q not found", name)
 		http.Error(w, "template not found", http.StatusInternalServerError)
@@ -13,6 +13,7 @@ import (
 	"shingo/protocol/eventbus"
 	"shingocore/dispatch/eta"
 	"shingocore/engine"
+	"shingocore/fleet"
 )
 
 // serverInstance is a per-process identifier emitted on the SSE
@@ -388,54 +389,8 @@ func (h *EventHub) SetupEngineListeners(eng *engine.Engine) {
 
 	eventbus.SubscribeTyped(eng.Events, func(evt eventbus.TypedEvent[engine.EventType, engine.RobotsUpdatedEvent]) {
 		ev := evt.Payload
-		type robotJSON struct {
-			VehicleID      string  `json:"vehicle_id"`
-			State          string  `json:"state"`
-			IP             string  `json:"ip"`
-			Model          string  `json:"model"`
-			CurrentMap     string  `json:"map"`
-			Battery        string  `json:"battery"`
-			Charging       bool    `json:"charging"`
-			CurrentStation string  `json:"station"`
-			LastStation    string  `json:"last_station"`
-			Available      bool    `json:"available"`
-			Connected      bool    `json:"connected"`
-			Blocked        bool    `json:"blocked"`
-			Emergency      bool    `json:"emergency"`
-			Busy           bool    `json:"processing"`
-			IsError        bool    `json:"error"`
-			X              float64 `json:"x"`
-			Y              float64 `json:"y"`
-			Angle          float64 `json:"angle"`
-			// The order this robot is on. See RobotOrderLine — no alarms.
-			RobotOrderLine
-		}
 		// Once per broadcast, not once per robot.
-		orderLines := robotOrderLines(eng.OrderService(), eng.AppConfig())
-		out := make([]robotJSON, len(ev.Robots))
-		for i, r := range ev.Robots {
-			out[i] = robotJSON{
-				VehicleID:      r.VehicleID,
-				State:          r.State(),
-				IP:             r.IP,
-				Model:          r.Model,
-				CurrentMap:     r.CurrentMap,
-				Battery:        fmt.Sprintf("%.0f", r.BatteryLevel),
-				Charging:       r.Charging,
-				CurrentStation: r.CurrentStation,
-				LastStation:    r.LastStation,
-				Available:      r.Available,
-				Connected:      r.Connected,
-				Blocked:        r.Blocked,
-				Emergency:      r.Emergency,
-				Busy:           r.Busy,
-				IsError:        r.IsError,
-				X:              r.X,
-				Y:              r.Y,
-				Angle:          r.Angle,
-				RobotOrderLine: orderLines[r.VehicleID],
-			}
-		}
+		out := robotsJSON(ev.Robots, robotOrderLines(eng.OrderService(), eng.AppConfig()))
 		h.Broadcast("robot-update", sseJSON(out))
 	}, engine.EventRobotsUpdated)
 
@@ -458,6 +413,17 @@ func (h *EventHub) SetupEngineListeners(eng *engine.Engine) {
 			"ts": clock.Now().UTC().Format(time.RFC3339Nano),
 		}))
 	}, engine.EventCellTick)
+
+	// Wall displays: the lineside board redraws on each persisted report, the
+	// alert board on each change to what is unresolved.
+	eventbus.SubscribeTyped(eng.Events, func(evt eventbus.TypedEvent[engine.EventType, engine.LinesideReportedEvent]) {
+		h.Broadcast("lineside-update", sseJSON(map[string]any{
+			"station": evt.Payload.Station, "payloads": evt.Payload.Payloads,
+		}))
+	}, engine.EventLinesideReported)
+	eventbus.SubscribeTyped(eng.Events, func(evt eventbus.TypedEvent[engine.EventType, engine.AlertsChangedEvent]) {
+		h.Broadcast("alert-update", `{}`)
+	}, engine.EventAlertsChanged)
 }
 
 // SSEHandler serves the SSE endpoint.
@@ -532,3 +498,58 @@ func (h *EventHub) SSEHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// robotJSON is one robot as the robot-update SSE event carries it — and as
+// the fleet-status wall display's first paint reads it, so the kiosk renders
+// the fetch and the live event with one code path.
+type robotJSON struct {
+	VehicleID      string  `json:"vehicle_id"`
+	State          string  `json:"state"`
+	IP             string  `json:"ip"`
+	Model          string  `json:"model"`
+	CurrentMap     string  `json:"map"`
+	Battery        string  `json:"battery"`
+	Charging       bool    `json:"charging"`
+	CurrentStation string  `json:"station"`
+	LastStation    string  `json:"last_station"`
+	Available      bool    `json:"available"`
+	Connected      bool    `json:"connected"`
+	Blocked        bool    `json:"blocked"`
+	Emergency      bool    `json:"emergency"`
+	Busy           bool    `json:"processing"`
+	IsError        bool    `json:"error"`
+	X              float64 `json:"x"`
+	Y              float64 `json:"y"`
+	Angle          float64 `json:"angle"`
+	// The order this robot is on. See RobotOrderLine — no alarms.
+	RobotOrderLine
+}
+
+// robotsJSON renders the robot-update payload.
+func robotsJSON(robots []fleet.RobotStatus, orderLines map[string]RobotOrderLine) []robotJSON {
+	out := make([]robotJSON, len(robots))
+	for i, r := range robots {
+		out[i] = robotJSON{
+			VehicleID:      r.VehicleID,
+			State:          r.State(),
+			IP:             r.IP,
+			Model:          r.Model,
+			CurrentMap:     r.CurrentMap,
+			Battery:        fmt.Sprintf("%.0f", r.BatteryLevel),
+			Charging:       r.Charging,
+			CurrentStation: r.CurrentStation,
+			LastStation:    r.LastStation,
+			Available:      r.Available,
+			Connected:      r.Connected,
+			Blocked:        r.Blocked,
+			Emergency:      r.Emergency,
+			Busy:           r.Busy,
+			IsError:        r.IsError,
+			X:              r.X,
+			Y:              r.Y,
+			Angle:          r.Angle,
+			RobotOrderLine: orderLines[r.VehicleID],
+		}
+	}
+	return out
+}
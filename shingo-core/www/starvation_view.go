@@ -0,0 +1,68 @@
+package www
+
+import (
+	"fmt"
+
+	"shingocore/domain"
+	"shingocore/service"
+)
+
+// starvation_view.go — /starvation's table: one row per cell for the chosen
+// week, a column per cause in precedence order, and the plant total under
+// them. The chart is drawn client-side from /api/starvation.
+
+// StarvationRow is one cell's week, or the plant's.
+type StarvationRow struct {
+	CellID  string
+	IsPlant bool
+
+	Down        Cell
+	NotMaterial Cell
+	Starved     Cell
+	// Causes is one cell per domain.StarvationCauses(), in that order.
+	Causes []Cell
+	// Top is the cause with the most minutes, or NoData when nothing starved.
+	Top Cell
+}
+
+// BuildStarvationRows lays out a week: its cells, most starved first, then
+// the plant. A week with no downtime has no rows.
+func BuildStarvationRows(wk *service.StarvationWeek) []StarvationRow {
+	if len(wk.Cells) == 0 {
+		return nil
+	}
+	rows := make([]StarvationRow, 0, len(wk.Cells)+1)
+	for _, c := range wk.Cells {
+		rows = append(rows, buildStarvationRow(c, false))
+	}
+	return append(rows, buildStarvationRow(wk.Plant, true))
+}
+
+func buildStarvationRow(c service.StarvationCell, plant bool) StarvationRow {
+	row := StarvationRow{
+		CellID:      c.CellID,
+		IsPlant:     plant,
+		Down:        Value(fmt.Sprintf("%.0f", c.DowntimeMinutes)),
+		NotMaterial: Value(fmt.Sprintf("%.0f", c.NotMaterialMinutes)),
+		Starved:     Value(fmt.Sprintf("%.0f", c.StarvedMinutes)),
+		Top:         NoData("no downtime while material was owed"),
+	}
+	for _, cause := range domain.StarvationCauses() {
+		row.Causes = append(row.Causes, Value(fmt.Sprintf("%.0f", c.Minutes[cause])))
+	}
+	if len(c.Pareto) > 0 {
+		top := c.Pareto[0]
+		row.Top = Value(top.Label)
+		row.Top.Title = fmt.Sprintf("%.0f%% of starved minutes", top.Cumulative*100)
+	}
+	return row
+}
+
+// starvationCauseLabels is the table's cause headers, in column order.
+func starvationCauseLabels() []string {
+	var out []string
+	for _, c := range domain.StarvationCauses() {
+		out = append(out, c.Label())
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package www
+
+import (
+	"testing"
+
+	"shingocore/domain"
+	"shingocore/service"
+)
+
+// TestStarvationRowsPlantLastAndNoStarvationIsNoData: the plant row follows
+// the cells; a cell whose downtime was all its own has zero in every cause
+// column and its top cause is a dash with the reason, not a made-up winner.
+func TestStarvationRowsPlantLastAndNoStarvationIsNoData(t *testing.T) {
+	starved := domain.StarvationAttribution{DowntimeMinutes: 50, StarvedMinutes: 40, NotMaterialMinutes: 10,
+		Minutes: map[domain.StarvationCause]float64{domain.StarvationLaneBlocked: 30, domain.StarvationFleetBusy: 10}}
+	own := domain.StarvationAttribution{DowntimeMinutes: 20, NotMaterialMinutes: 20,
+		Minutes: map[domain.StarvationCause]float64{}}
+	wk := &service.StarvationWeek{
+		Cells: []service.StarvationCell{
+			{CellID: "SPR-1", StarvationAttribution: starved, Pareto: starved.Pareto()},
+			{CellID: "SPR-2", StarvationAttribution: own},
+		},
+		Plant: service.StarvationCell{CellID: "Plant", StarvationAttribution: starved, Pareto: starved.Pareto()},
+	}
+	rows := BuildStarvationRows(wk)
+	if len(rows) != 3 || !rows[2].IsPlant {
+		t.Fatalf("rows = %+v, want two cells then the plant", rows)
+	}
+	if rows[0].Top.Kind != CellValue || rows[0].Top.Text != "Lane blocked / dig" {
+		t.Errorf("SPR-1 top = %+v, want lane blocked", rows[0].Top)
+	}
+	if rows[1].Top.Kind != CellNoData {
+		t.Errorf("SPR-2 top = %+v, want no-data", rows[1].Top)
+	}
+	if len(rows[1].Causes) != len(domain.StarvationCauses()) {
+		t.Errorf("SPR-2 has %d cause columns, want one per cause", len(rows[1].Causes))
+	}
+}
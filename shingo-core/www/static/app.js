@@ -19,6 +19,7 @@
 //                       Prefer h`` for new code.
 
 import { delegateActions, installBackdropClose, installTableSort, onSSE } from '/static/shared/utils.js';
+import { t } from '/static/i18n.js';
 installBackdropClose();
 document.addEventListener('DOMContentLoaded', function() { installTableSort(); });
 export { delegateActions };
@@ -133,11 +134,11 @@ export function uiConfirm(message) {
     row.style.cssText = 'display:flex;gap:0.5rem;justify-content:flex-end';
     var cancelBtn = document.createElement('button');
     cancelBtn.className = 'btn';
-    cancelBtn.textContent = 'Cancel';
+    cancelBtn.textContent = t('common.cancel');
     cancelBtn.onclick = function() { overlay.remove(); resolve(false); };
     var okBtn = document.createElement('button');
     okBtn.className = 'btn btn-danger';
-    okBtn.textContent = 'Confirm';
+    okBtn.textContent = t('common.confirm');
     okBtn.onclick = function() { overlay.remove(); resolve(true); };
     row.appendChild(cancelBtn); row.appendChild(okBtn);
     box.appendChild(row);
@@ -171,11 +172,11 @@ export function uiPrompt(message, opts) {
     row.style.cssText = 'display:flex;gap:0.5rem;justify-content:flex-end';
     var cancelBtn = document.createElement('button');
     cancelBtn.className = 'btn';
-    cancelBtn.textContent = 'Cancel';
+    cancelBtn.textContent = t('common.cancel');
     cancelBtn.onclick = function() { done(null); };
     var okBtn = document.createElement('button');
     okBtn.className = 'btn btn-primary';
-    okBtn.textContent = 'OK';
+    okBtn.textContent = t('common.ok');
     okBtn.onclick = function() { done(input.value); };
     input.addEventListener('keydown', function(e) {
       if (e.key === 'Enter') done(input.value);
@@ -199,21 +200,21 @@ export function toast(message, level, opts) {
     container.style.cssText = 'position:fixed;top:1rem;right:1rem;display:flex;flex-direction:column;gap:0.5rem;z-index:var(--z-toast);pointer-events:none';
     document.body.appendChild(container);
   }
-  var t = document.createElement('div');
-  t.className = 'toast toast-' + level;
-  t.textContent = message;
+  var node = document.createElement('div');
+  node.className = 'toast toast-' + level;
+  node.textContent = message;
   var stripe = { success: 'var(--success)', error: 'var(--danger)', warning: 'var(--warning)', info: 'var(--info)' }[level] || 'var(--info)';
-  t.style.cssText = 'padding:0.6rem 1rem;border-radius:var(--radius);background:var(--surface);color:var(--text);border:1px solid var(--border);border-left:4px solid ' + stripe + ';box-shadow:var(--shadow-md);pointer-events:auto;min-width:12rem;max-width:24rem;font-size:0.9rem';
-  container.appendChild(t);
+  node.style.cssText = 'padding:0.6rem 1rem;border-radius:var(--radius);background:var(--surface);color:var(--text);border:1px solid var(--border);border-left:4px solid ' + stripe + ';box-shadow:var(--shadow-md);pointer-events:auto;min-width:12rem;max-width:24rem;font-size:0.9rem';
+  container.appendChild(node);
   if (!opts.sticky) {
     var dur = (level === 'error') ? 5000 : 3200;
-    setTimeout(function() { t.remove(); }, dur);
+    setTimeout(function() { node.remove(); }, dur);
   } else {
-    t.style.cursor = 'pointer';
-    t.title = 'Click to dismiss';
-    t.addEventListener('click', function() { t.remove(); });
+    node.style.cursor = 'pointer';
+    node.title = t('common.click_to_dismiss');
+    node.addEventListener('click', function() { node.remove(); });
   }
-  return t;
+  return node;
 }
 
 // Generic JSON request. Throws the server error string (or parsed object's
@@ -225,11 +226,11 @@ export function api(method, url, body) {
     opts.body = JSON.stringify(body);
   }
   return fetch(url, opts).then(function(r) {
-    if (!r.ok) return r.text().then(function(t) {
-      try { throw JSON.parse(t); }
+    if (!r.ok) return r.text().then(function(text) {
+      try { throw JSON.parse(text); }
       catch(e) {
         if (typeof e === 'object' && e.error) throw e.error;
-        throw t;
+        throw text;
       }
     });
     return r.json();
@@ -248,10 +249,10 @@ export function apiDelete(url)     { return api('DELETE', url); }
 export function timeAgo(ts) {
   if (!ts) return '-';
   var d = Date.now() - new Date(ts).getTime();
-  if (d < 60000) return 'just now';
-  if (d < 3600000) return Math.floor(d / 60000) + 'm ago';
-  if (d < 86400000) return Math.floor(d / 3600000) + 'h ago';
-  return Math.floor(d / 86400000) + 'd ago';
+  if (d < 60000) return t('time.just_now');
+  if (d < 3600000) return t('time.minutes_ago', { n: Math.floor(d / 60000) });
+  if (d < 86400000) return t('time.hours_ago', { n: Math.floor(d / 3600000) });
+  return t('time.days_ago', { n: Math.floor(d / 86400000) });
 }
 
 export function formatTime(ts, opts) {
@@ -378,7 +379,7 @@ export async function confirmDeleteForm(el, evt) {
   if (!el || !evt) return;
   if (el.dataset.confirmed === '1') return;       // resubmit path
   evt.preventDefault();
-  var msg = el.dataset.confirmMsg || 'Are you sure?';
+  var msg = el.dataset.confirmMsg || t('common.are_you_sure');
   if (!await uiConfirm(msg)) return;
   el.dataset.confirmed = '1';
   el.submit();                                    // bypasses the listener
@@ -10,6 +10,7 @@
 // plus a 60s idle auto-dismiss, matching DrillModal.
 
 import { el, h } from '/static/app.js';
+import { t } from '/static/i18n.js';
 
 let _active = null;
 const MAX_DOTS = 400; // cap per strip; a wider window samples down to this
@@ -22,9 +23,9 @@ export function openCellDrill(cellID) {
     box.innerHTML = h`
         <div class="modal-header flex flex-between">
           <h2 class="cell-drill__title">${cellID}</h2>
-          <button class="modal-close" title="Close">&times;</button>
+          <button class="modal-close" title="${t('common.close')}">&times;</button>
         </div>
-        <div class="cell-drill__body"><div class="dash-empty">Loading…</div></div>`;
+        <div class="cell-drill__body"><div class="dash-empty">${t('common.loading')}</div></div>`;
     overlay.appendChild(box);
     document.body.appendChild(overlay);
 
@@ -44,8 +45,9 @@ export function openCellDrill(cellID) {
         .then((data) => { if (_active === state) render(state, data); })
         .catch((e) => {
             if (e && e.name === 'AbortError') return;
-            if (_active === state) box.querySelector('.cell-drill__body').innerHTML =
-                '<div class="dash-empty">Failed to load: ' + e + '</div>';
+            if (_active !== state) return;
+            const msg = t('common.load_failed', { error: e });
+            box.querySelector('.cell-drill__body').innerHTML = h`<div class="dash-empty">${msg}</div>`;
         });
 }
 
@@ -75,11 +77,11 @@ function render(state, data) {
     const until = new Date(data.until).getTime();
     const span = until - since || 1;
     body.appendChild(el('div', { className: 'text-muted-sm cell-drill__window' },
-        'Window: ' + fmtRange(data.since, data.until)));
+        t('drill.window', { range: fmtRange(data.since, data.until) })));
 
     const procs = data.processes || [];
     if (!procs.length) {
-        body.appendChild(el('div', { className: 'dash-empty' }, 'No Processes configured for this cell.'));
+        body.appendChild(el('div', { className: 'dash-empty' }, t('drill.no_processes')));
         return;
     }
     // Subs above the primary so the sub→primary flow reads top-to-bottom.
@@ -89,29 +91,29 @@ function render(state, data) {
 
 function procRow(p, since, span) {
     const m = p.metrics || {};
-    const label = (p.primary ? 'Primary' : 'Sub') + ' · Process ' + p.process_id;
+    const label = t(p.primary ? 'drill.primary' : 'drill.sub', { id: p.process_id });
     const strip = el('div', { className: 'cell-drill__strip' });
     const events = sampleEvents(p.events || []);
     events.forEach((e) => {
-        const t = new Date(e.recorded_at).getTime();
-        const pct = Math.max(0, Math.min(100, ((t - since) / span) * 100));
+        const at = new Date(e.recorded_at).getTime();
+        const pct = Math.max(0, Math.min(100, ((at - since) / span) * 100));
         strip.appendChild(el('span', { className: 'cell-drill__pulse', style: { left: pct + '%' } }));
     });
-    if (!events.length) strip.appendChild(el('span', { className: 'text-muted-sm cell-drill__nodata' }, 'no fires in window'));
+    if (!events.length) strip.appendChild(el('span', { className: 'text-muted-sm cell-drill__nodata' }, t('drill.no_fires')));
 
     const stats = el('div', { className: 'cell-drill__stats text-muted-sm' }, [
-        stat('Parts', m.parts || 0),
-        stat('Stops', m.stop_count || 0),
-        stat('Downtime', fmtMin(m.total_downtime_ms)),
-        stat('MTBF', m.mtbf_minutes ? m.mtbf_minutes.toFixed(0) + 'm' : '—'),
-        stat('Eff/hr', m.effective_parts_per_hour ? m.effective_parts_per_hour.toFixed(0) : '—'),
-        stat('Lost', m.parts_lost || 0),
+        stat(t('drill.parts'), m.parts || 0),
+        stat(t('drill.stops'), m.stop_count || 0),
+        stat(t('drill.downtime'), fmtMin(m.total_downtime_ms)),
+        stat(t('drill.mtbf'), m.mtbf_minutes ? m.mtbf_minutes.toFixed(0) + 'm' : '—'),
+        stat(t('drill.eff_hr'), m.effective_parts_per_hour ? m.effective_parts_per_hour.toFixed(0) : '—'),
+        stat(t('drill.lost'), m.parts_lost || 0),
     ]);
 
     return el('div', { className: 'cell-drill__proc' + (p.primary ? ' cell-drill__proc--primary' : '') }, [
         el('div', { className: 'cell-drill__proc-head flex flex-between' }, [
             el('span', { className: 'cell-drill__proc-label' }, label),
-            el('span', { className: 'text-muted-sm' }, (events.length) + ' fires'),
+            el('span', { className: 'text-muted-sm' }, t('drill.fires', { n: events.length })),
         ]),
         strip,
         stats,
@@ -142,6 +144,9 @@ function fmtMin(ms) {
     return m < 60 ? m + 'm' : Math.floor(m / 60) + 'h ' + (m % 60) + 'm';
 }
 function fmtRange(a, b) {
-    try { return new Date(a).toLocaleString() + ' → ' + new Date(b).toLocaleTimeString(); }
+    try {
+        const lang = document.documentElement.lang || undefined;
+        return new Date(a).toLocaleString(lang) + ' → ' + new Date(b).toLocaleTimeString(lang);
+    }
     catch (_) { return ''; }
 }
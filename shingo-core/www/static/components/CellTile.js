@@ -14,6 +14,7 @@
 // does too) so the component stays surface-agnostic.
 
 import { el, h } from '/static/app.js';
+import { t } from '/static/i18n.js';
 
 const STATES = ['running', 'slowed', 'micro-stop', 'stopped', 'no-data'];
 
@@ -39,7 +40,7 @@ export function CellTile(cell) {
 function makeDot(processID, isPrimary) {
     const dot = el('span', { className: 'cell-dot cell-dot--no-data' + (isPrimary ? ' cell-dot--primary' : ' cell-dot--sub') });
     dot.dataset.proc = String(processID);
-    dot.title = (isPrimary ? 'Primary process ' : 'Process ') + processID;
+    dot.title = t(isPrimary ? 'cell.primary_process' : 'cell.process', { id: processID });
     return dot;
 }
 
@@ -96,10 +97,13 @@ function cycleLabel(ms) {
 }
 
 function stateSummary(primary) {
-    const label = { running: 'Running', slowed: 'Slowed', 'micro-stop': 'Micro-stop', stopped: 'Stopped', 'no-data': 'No data' };
-    const name = label[primary.state] || 'No data';
+    const label = {
+        running: t('cell.state_running'), slowed: t('cell.state_slowed'), 'micro-stop': t('cell.state_micro_stop'),
+        stopped: t('cell.state_stopped'), 'no-data': t('cell.state_no_data'),
+    };
+    const name = label[primary.state] || label['no-data'];
     if (primary.since_last_ms && primary.since_last_ms > 0) {
-        return name + ' · ' + agoLabel(primary.since_last_ms) + ' since last';
+        return t('cell.since_last', { state: name, ago: agoLabel(primary.since_last_ms) });
     }
     return name;
 }
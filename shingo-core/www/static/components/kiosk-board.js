@@ -0,0 +1,71 @@
+// kiosk-board.js — the plumbing every config-schema wall display shares: the
+// header clock, the live-connection dot, and a debounced re-fetch of the
+// board's data API on the SSE topics that move it. The board supplies only
+// the endpoint suffix and a render function.
+//
+// The four older kiosks carry this inline; these five share it because they
+// differ only in what they draw.
+
+import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
+
+// startKioskBoard fetches /api/dashboards/{id}/{endpoint} now, on every
+// reconnect, and 250ms after the last of a burst of events on topics.
+// render(data) draws the board and returns true when it drew nothing, which
+// shows the template's empty-state line instead. everyMs, if set, also
+// re-fetches on a timer, for a board whose figures age with no event — a
+// report going stale, an hour's plan growing.
+export function startKioskBoard({ endpoint, topics, render, everyMs }) {
+  const id = document.body.getAttribute('data-dashboard-id');
+
+  function tickClock() {
+    const el = document.getElementById('dash-clock');
+    if (el) el.textContent = new Date().toLocaleTimeString(document.documentElement.lang || undefined);
+  }
+  setInterval(tickClock, 1000);
+  tickClock();
+
+  function setConnected(ok) {
+    const el = document.getElementById('dash-conn');
+    if (el) el.className = 'dash-conn ' + (ok ? 'dash-conn-ok' : 'dash-conn-down');
+  }
+
+  async function load() {
+    try {
+      const r = await fetch('/api/dashboards/' + encodeURIComponent(id) + '/' + endpoint + '?t=' + Date.now());
+      if (!r.ok) throw new Error('HTTP ' + r.status);
+      const empty = render(await r.json());
+      const el = document.getElementById('kb-empty');
+      if (el) el.style.display = empty ? 'block' : 'none';
+    } catch (e) {
+      console.error(`${endpoint}: load failed:`, e);
+    }
+  }
+
+  let reloadTimer = null;
+  function scheduleReload() {
+    clearTimeout(reloadTimer);
+    reloadTimer = setTimeout(load, 250);
+  }
+
+  function init() {
+    setSSEReloadOnBuild(true);
+    load();
+    onSSE('connected', () => { setConnected(true); load(); });
+    onSSE('disconnected', () => setConnected(false));
+    for (const t of topics) onSSE(t, scheduleReload);
+    if (everyMs) setInterval(load, everyMs);
+  }
+
+  if (document.readyState === 'loading') {
+    document.addEventListener('DOMContentLoaded', init);
+  } else {
+    init();
+  }
+  return { reload: scheduleReload };
+}
+
+// setSummary writes the header's one-line summary.
+export function setSummary(text) {
+  const el = document.getElementById('kb-summary');
+  if (el) el.textContent = text;
+}
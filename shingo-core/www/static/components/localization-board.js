@@ -244,6 +244,39 @@ const VERDICT_LABEL = {
     nodata: 'no data one side'
 };
 
+// FIX_EFFORT names what a recommendation costs, in the order they rank on a
+// tie: a map edit undoes, an install is a work order.
+export const FIX_EFFORT = { map_edit: 'map edit', install: 'install' };
+
+function escText(s) {
+    return String(s === null || s === undefined ? '' : s)
+        .replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+
+// fixesHTML renders the ranked map fixes (/api/robots/map-fixes).
+//
+// The EVIDENCE IS THE POINT. A fix is a recommendation to change the plant,
+// and every one carries the sentences it rests on so the engineer checks it
+// rather than trusts it — so they are shown open, not behind a click.
+export function fixesHTML(report) {
+    const fixes = (report && report.fixes) || [];
+    if (!fixes.length) {
+        return '<p class="lb-empty">No persistent hotspots: nothing the fleet loses its position in ' +
+            'on most days, across more than one robot.</p>';
+    }
+    return '<ol class="lb-fix-list">' + fixes.map(function (f) {
+        return '<li class="lb-fix">' +
+            '<div class="lb-fix-title"><span class="lb-fix-rank">' + f.rank + '</span>' + escText(f.title) + '</div>' +
+            '<div class="lb-fix-meta">' + escText(FIX_EFFORT[f.effort] || f.effort) + ' · ' +
+            Number(f.impact || 0).toLocaleString() + ' weak readings' +
+            (f.diff_id ? ' · diff #' + f.diff_id : '') + '</div>' +
+            '<ul class="lb-fix-ev">' + (f.evidence || []).map(function (e) {
+                return '<li>' + escText(e) + '</li>';
+            }).join('') + '</ul>' +
+            '</li>';
+    }).join('') + '</ol>';
+}
+
 // histPath renders a distribution as an SVG polyline over a unit box.
 //
 // THE SHAPE IS THE FINDING, and it is the only mark on this page that can
@@ -280,6 +313,7 @@ export function createBoard(root, opts) {
         change: null,        // the selected lane's annotation, fetched on select
         robots: [],
         robot: '',            // vehicle_id filter; '' is the fleet view
+        fixes: null,          // the ranked map fixes; fleet-wide, fetched once
         // Viewport. scale/tx/ty are the screen transform; strokes divide by
         // scale so they hold their SCREEN size — a lane that thickened as you
         // zoomed would hide the geometry underneath it, and at 5× map zoomed
@@ -319,12 +353,15 @@ export function createBoard(root, opts) {
         '    <div class="lb-legend" id="lb-legend"></div>' +
         '  </section>' +
         '  <section class="lb-panel" id="lb-panel"></section>' +
-        '</div>';
+        '</div>' +
+        '<section class="lb-fixes"><div class="lb-hd">Map fixes, last 7 days</div>' +
+        '  <div id="lb-fixes-body"><p class="lb-empty">Loading.</p></div></section>';
 
     const map = root.querySelector('#lb-map');
     const panel = root.querySelector('#lb-panel');
     const railBody = root.querySelector('#lb-rail-body');
     const note = root.querySelector('#lb-note');
+    const fixesBody = root.querySelector('#lb-fixes-body');
 
     // ── the range picker ─────────────────────────────────────────────────
     //
@@ -540,7 +577,25 @@ export function createBoard(root, opts) {
     // endpoint answers without a label losing contact with its days.
 
     // ── data ─────────────────────────────────────────────────────────────
+    // loadFixes fetches the recommendations once. They are judged over whole
+    // days across the fleet, so neither the range picker nor the robot filter
+    // changes them — refetching on every pick would suggest otherwise.
+    function loadFixes() {
+        if (state.fixes) return;
+        state.fixes = {};
+        (o.fetchFixes ? o.fetchFixes() : fetch('/api/robots/map-fixes?window=7d').then(jsonOK))
+            .then(function (rep) {
+                state.fixes = rep || {};
+                fixesBody.innerHTML = fixesHTML(state.fixes);
+            })
+            .catch(function (err) {
+                state.fixes = null;
+                fixesBody.innerHTML = '<p class="lb-empty">Map fixes unavailable: ' + escText(err.message || err) + '</p>';
+            });
+    }
+
     async function load() {
+        loadFixes();
         // The robot param rides on the board URL only; the edges never change
         // with the filter, so they are not refetched. An empty robot is fleet.
         const robotParam = state.robot ? '&robot=' + encodeURIComponent(state.robot) : '';
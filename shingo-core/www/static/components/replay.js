@@ -0,0 +1,216 @@
+// replay.js — the replay bar the task board and robot map show when opened
+// with ?replay=1: pick a window, then play, scrub and jump through it.
+//
+// One fetch per window (/api/dashboards/{id}/replay); every frame after that
+// is computed here from the timeline — each track's last entry at or before
+// the playhead — so scrubbing never waits on the server. What the timeline
+// holds, and what in it is derived rather than recorded (slot holds), is
+// domain/replay.go's comment.
+//
+// The page supplies onFrame(state) and draws state the way it draws live
+// data: state.robots are robot-update rows, state.orders are board rows, and
+// state.bins / state.holds map a node name to the bin on it / the order
+// holding it. The bar owns the header clock while it is up: a wall clock on
+// a replay reads as the time of what is on screen.
+
+import { h } from '/static/shared/utils.js';
+import { t } from '/static/i18n.js';
+
+var SPEEDS = [1, 10, 60, 300];
+
+// lastAtOrBefore returns the index of the last item whose key is <= t, or -1.
+function lastAtOrBefore(items, key, t) {
+  var lo = 0, hi = items.length - 1, found = -1;
+  while (lo <= hi) {
+    var mid = (lo + hi) >> 1;
+    if (items[mid][key] <= t) { found = mid; lo = mid + 1; } else { hi = mid - 1; }
+  }
+  return found;
+}
+
+function ms(s) { return s ? Date.parse(s) : Infinity; }
+
+// prepare turns the timeline's timestamps into milliseconds once, so a frame
+// is comparisons only.
+function prepare(tl) {
+  (tl.robots || []).forEach(function (r) { r.poses.forEach(function (p) { p._t = ms(p.at); }); });
+  (tl.orders || []).forEach(function (o) {
+    o.spans.forEach(function (s) { s._t = ms(s.start); });
+  });
+  (tl.bins || []).forEach(function (b) {
+    b.spans.forEach(function (s) { s._t = ms(s.start); s._end = ms(s.end); });
+  });
+  (tl.holds || []).forEach(function (x) { x._t = ms(x.start); x._end = ms(x.end); });
+  (tl.events || []).forEach(function (e) { e._t = ms(e.at); });
+  tl._from = ms(tl.from);
+  tl._to = ms(tl.to);
+  return tl;
+}
+
+// stateAt is the plant at t.
+export function stateAt(tl, t) {
+  var robots = [];
+  tl.robots.forEach(function (r) {
+    var i = lastAtOrBefore(r.poses, '_t', t);
+    if (i < 0) return;
+    var p = r.poses[i];
+    robots.push({
+      vehicle_id: r.vehicle_id, x: p.x, y: p.y, angle: p.angle, station: p.station || '',
+      state: p.blocked ? 'error' : (p.order_id ? 'busy' : 'ready')
+    });
+  });
+  var orders = [];
+  tl.orders.forEach(function (o) {
+    var i = lastAtOrBefore(o.spans, '_t', t);
+    if (i < 0 || o.spans[i].terminal) return;
+    orders.push({
+      order_id: o.order_id, robot_id: o.robot_id, source_node: o.source_node,
+      payload_code: o.payload_code, delivery_node: o.delivery_node,
+      status: o.spans[i].status, station_id: o.station_id, created_at: o.created_at,
+      current_station: '', carrying: false
+    });
+  });
+  var bins = {};
+  tl.bins.forEach(function (b) {
+    var i = lastAtOrBefore(b.spans, '_t', t);
+    if (i < 0) return;
+    var s = b.spans[i];
+    if (s.node && t < s._end) bins[s.node] = { label: b.label, payload: s.payload || '' };
+  });
+  var holds = {};
+  tl.holds.forEach(function (x) {
+    if (x._t <= t && t < x._end) holds[x.node] = x.order_id;
+  });
+  return { at: new Date(t), robots: robots, orders: orders, bins: bins, holds: holds };
+}
+
+// localInput formats a Date for a datetime-local input.
+function localInput(d) {
+  function p2(n) { return (n < 10 ? '0' : '') + n; }
+  return d.getFullYear() + '-' + p2(d.getMonth() + 1) + '-' + p2(d.getDate()) +
+    'T' + p2(d.getHours()) + ':' + p2(d.getMinutes());
+}
+
+function eventText(e) {
+  var when = new Date(e._t).toLocaleTimeString(document.documentElement.lang || undefined);
+  if (e.kind === 'bin') {
+    return t('replay.bin_moved', { when: when, bin: e.label || e.bin_id, node: e.node || t('replay.no_node') });
+  }
+  return t('replay.order_event', { when: when, id: e.order_id, status: e.status }) + (e.label ? ' (' + e.label + ')' : '');
+}
+
+// startReplay mounts the bar into #rp-bar. ?from= and ?to= (RFC3339) on the
+// page URL load that window at once, and ?order= filters the event list to
+// one order — a link can open straight onto "when order X faulted".
+export function startReplay({ dashboardId, onFrame }) {
+  var host = document.getElementById('rp-bar');
+  if (!host) return;
+  var q = new URLSearchParams(location.search);
+  var now = new Date();
+  now.setSeconds(0, 0);
+  var to = q.get('to') ? new Date(q.get('to')) : now;
+  var from = q.get('from') ? new Date(q.get('from')) : new Date(to.getTime() - 3600000);
+
+  var speeds = SPEEDS.map(function (s) { return h`<option value="${s}">${s}x</option>`; });
+  host.innerHTML = h`
+    <span class="rp-tag">${t('replay.tag')}</span>
+    <label class="rp-field">${t('replay.from')} <input type="datetime-local" id="rp-from" value="${localInput(from)}"></label>
+    <label class="rp-field">${t('replay.to')} <input type="datetime-local" id="rp-to" value="${localInput(to)}"></label>
+    <button type="button" class="rp-btn" id="rp-load">${t('replay.load')}</button>
+    <button type="button" class="rp-btn" id="rp-play" disabled>${t('replay.play')}</button>
+    <select id="rp-speed" class="rp-select">${speeds}</select>
+    <input type="range" id="rp-scrub" class="rp-scrub" min="0" max="1000" value="0" disabled>
+    <span class="rp-time" id="rp-time"></span>
+    <select id="rp-events" class="rp-select rp-events" disabled><option value="">${t('replay.jump')}</option></select>
+    <input type="text" id="rp-order" class="rp-order" placeholder="${t('replay.order_placeholder')}" value="${q.get('order') || ''}">
+    <label class="rp-field"><input type="checkbox" id="rp-notable"> ${t('replay.faults_only')}</label>
+    <span class="rp-status" id="rp-status"></span>`;
+
+  var $ = function (id) { return document.getElementById(id); };
+  var tl = null, at = 0, playing = false, speed = SPEEDS[0], lastTick = 0;
+
+  function status(text) { $('rp-status').textContent = text || ''; }
+
+  function show() {
+    if (!tl) return;
+    var span = tl._to - tl._from;
+    $('rp-scrub').value = String(Math.round(((at - tl._from) / span) * 1000));
+    $('rp-time').textContent = new Date(at).toLocaleString(document.documentElement.lang || undefined);
+    var clock = document.getElementById('dash-clock');
+    if (clock) clock.textContent = new Date(at).toLocaleTimeString(document.documentElement.lang || undefined);
+    onFrame(stateAt(tl, at));
+  }
+
+  function seek(to) {
+    at = Math.max(tl._from, Math.min(tl._to, to));
+    show();
+  }
+
+  function tick(stamp) {
+    if (!playing) return;
+    if (lastTick) seek(at + (stamp - lastTick) * speed);
+    lastTick = stamp;
+    if (at >= tl._to) { setPlaying(false); return; }
+    requestAnimationFrame(tick);
+  }
+
+  function setPlaying(on) {
+    playing = on && !!tl;
+    $('rp-play').textContent = t(playing ? 'replay.pause' : 'replay.play');
+    lastTick = 0;
+    if (playing) {
+      if (at >= tl._to) at = tl._from;
+      requestAnimationFrame(tick);
+    }
+  }
+
+  function renderEvents() {
+    var sel = $('rp-events');
+    if (!tl) return;
+    var order = $('rp-order').value.trim();
+    var notable = $('rp-notable').checked;
+    var list = tl.events.filter(function (e) {
+      if (order && String(e.order_id) !== order) return false;
+      return !notable || e.notable;
+    });
+    sel.innerHTML = h`<option value="">${t('replay.jump_count', { n: list.length })}</option>` +
+      list.map(function (e) { return h`<option value="${e._t}">${eventText(e)}</option>`; }).join('');
+    sel.disabled = !list.length;
+  }
+
+  async function load() {
+    setPlaying(false);
+    var f = new Date($('rp-from').value), e = new Date($('rp-to').value);
+    if (isNaN(f.getTime()) || isNaN(e.getTime())) { status(t('replay.pick_window')); return; }
+    status(t('common.loading'));
+    try {
+      var r = await fetch('/api/dashboards/' + encodeURIComponent(dashboardId) + '/replay?from=' +
+        encodeURIComponent(f.toISOString()) + '&to=' + encodeURIComponent(e.toISOString()));
+      var body = await r.json();
+      if (!r.ok) throw new Error(body && body.error ? body.error : 'HTTP ' + r.status);
+      tl = prepare(body);
+    } catch (err) {
+      tl = null;
+      status(err.message);
+      return;
+    }
+    status(tl.robots.length ? '' : t('replay.no_positions'));
+    $('rp-play').disabled = false;
+    $('rp-scrub').disabled = false;
+    renderEvents();
+    seek(tl._from);
+  }
+
+  $('rp-load').addEventListener('click', load);
+  $('rp-play').addEventListener('click', function () { setPlaying(!playing); });
+  $('rp-speed').addEventListener('change', function () { speed = Number(this.value) || 1; });
+  $('rp-scrub').addEventListener('input', function () {
+    if (tl) seek(tl._from + (Number(this.value) / 1000) * (tl._to - tl._from));
+  });
+  $('rp-events').addEventListener('change', function () {
+    if (tl && this.value) { setPlaying(false); seek(Number(this.value)); }
+  });
+  $('rp-order').addEventListener('input', renderEvents);
+  $('rp-notable').addEventListener('change', renderEvents);
+  if (q.get('from') && q.get('to')) load();
+}
@@ -0,0 +1,24 @@
+// i18n.js — t(), the script side of the message catalog. The page embeds its
+// language's catalog as #shingo-messages; t looks a key up there. A module of
+// its own, with no side effects, so a kiosk script that must not load app.js
+// (dashboard.js in replay mode opens no SSE) can still translate.
+
+var messages = null;
+
+// t(key, args) renders a message in the language the page rendered in. args
+// fills {name} placeholders. A key the catalog lacks renders as the key, the
+// rule the server's catalog follows, so a typo shows on screen rather than a
+// blank. The catalog is read on the first call.
+export function t(key, args) {
+  if (messages === null) {
+    var node = document.getElementById('shingo-messages');
+    try { messages = node ? JSON.parse(node.textContent) : {}; } catch (e) { messages = {}; }
+  }
+  var text = Object.prototype.hasOwnProperty.call(messages, key) ? messages[key] : key;
+  if (args) {
+    text = text.replace(/\{([a-z][a-z0-9_]*)\}/g, function (m, name) {
+      return Object.prototype.hasOwnProperty.call(args, name) ? String(args[name]) : m;
+    });
+  }
+  return text;
+}
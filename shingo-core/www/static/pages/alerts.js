@@ -0,0 +1,24 @@
+// alerts.js — acknowledge an open alert.
+//
+// Filtering is a plain GET form; the only write is the acknowledgement, and
+// the actor is the signed-in user on the server side.
+
+import { apiPost, delegateActions, toast } from '/static/app.js';
+
+async function ack(btn) {
+  const tr = btn.closest('tr[data-id]');
+  if (!tr) return;
+  btn.disabled = true;
+  try {
+    await apiPost('/api/alerts/' + tr.dataset.id + '/ack', {});
+    toast('A-' + tr.dataset.id + ' acknowledged', 'success');
+    window.location.reload();
+  } catch (e) {
+    toast('Acknowledge failed: ' + e, 'error');
+    btn.disabled = false;
+  }
+}
+
+delegateActions(document.body, {
+  ack: (el) => ack(el),
+});
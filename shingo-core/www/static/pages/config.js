@@ -86,6 +86,28 @@ async function testNotifEmail() {
   }
 }
 
+// testNotifChannel test-sends one configured channel. Same result box as the
+// email test: one place on the page says what the last test did.
+async function testNotifChannel(btn) {
+  var el = document.getElementById('notif-test-result');
+  var name = btn.dataset.channel;
+  btn.disabled = true;
+  el.style.display = 'none';
+  try {
+    var res = await fetch('/config/test-channel?name=' + encodeURIComponent(name), { method: 'POST' });
+    var data = await res.json();
+    el.style.display = 'block';
+    el.className = data.ok ? 'alert alert-ok mb-1' : 'alert alert-err mb-1';
+    el.textContent = data.ok ? data.message : 'Error: ' + data.message;
+  } catch (err) {
+    el.style.display = 'block';
+    el.className = 'alert alert-err mb-1';
+    el.textContent = 'Error: ' + err.message;
+  } finally {
+    btn.disabled = false;
+  }
+}
+
 
 // ─── delegated event handlers ─────────────────────────
 // All page-level data-action verbs route through delegateActions
@@ -101,5 +123,6 @@ delegateActions(document.body, {
     addNotifRecipient,
     removeNotifRecipient,
     testNotifAlert,
+    testNotifChannel,
     testNotifEmail
 }, { events: ['click', 'change', 'input', 'blur', 'keydown', 'submit'] });
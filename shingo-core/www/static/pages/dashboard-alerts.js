@@ -0,0 +1,44 @@
+// dashboard-alerts.js — the alert wall display: the unresolved alerts at or
+// above the board's severity, most severe first. Redraws on alert-update,
+// which fires on a raise, a resolve, an escalation or an acknowledgement.
+
+import { h } from '/static/shared/utils.js';
+import { startKioskBoard, setSummary } from '/static/components/kiosk-board.js';
+import { t } from '/static/i18n.js';
+
+const SEVERITY_LABEL = {
+  info: t('alerts.severity_info'),
+  warning: t('alerts.severity_warning'),
+  critical: t('alerts.severity_critical'),
+};
+
+function since(ts) {
+  const min = Math.floor((Date.now() - new Date(ts).getTime()) / 60000);
+  if (min < 60) return t('board.minutes', { n: min });
+  return t('board.hours_minutes', { h: Math.floor(min / 60), m: min % 60 });
+}
+
+function row(a) {
+  const acked = a.status === 'acknowledged';
+  return h`<tr class="kb-sev-${a.severity}${acked ? ' kb-acked' : ''}">
+    <td class="kb-sev">${SEVERITY_LABEL[a.severity] || a.severity}</td>
+    <td>${a.subject}${a.occurrences > 1 && [h` <span class="kb-count">×${a.occurrences}</span>`]}</td>
+    <td class="kb-num">${since(a.first_seen)}</td>
+    <td>${acked ? t('alerts.acked', { by: a.acknowledged_by || '' }) : ''}</td>
+  </tr>`;
+}
+
+startKioskBoard({
+  endpoint: 'alerts',
+  topics: ['alert-update'],
+  everyMs: 60000,
+  render(list) {
+    document.getElementById('kb-alerts').innerHTML = list.length
+      ? h`<table class="kb-table"><thead><tr><th>${t('alerts.col_severity')}</th><th>${t('alerts.col_alert')}</th><th class="kb-num">${t('alerts.col_open')}</th><th></th></tr></thead>
+        <tbody>${list.map(row)}</tbody></table>`
+      : '';
+    const critical = list.filter((a) => a.severity === 'critical').length;
+    setSummary(t('alerts.summary', { critical, open: list.length }));
+    return list.length === 0;
+  },
+});
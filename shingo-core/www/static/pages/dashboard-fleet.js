@@ -0,0 +1,33 @@
+// dashboard-fleet.js — the fleet status wall display: a tile per robot with
+// its state, battery and current order. Redraws on every robot-update.
+
+import { h } from '/static/shared/utils.js';
+import { startKioskBoard, setSummary } from '/static/components/kiosk-board.js';
+import { t } from '/static/i18n.js';
+
+function tile(r) {
+  const cls = 'kb-robot' + (!r.connected ? ' kb-robot-off' : '') + (r.error || r.emergency ? ' kb-robot-fault' : '') +
+    (r.low_battery ? ' kb-low' : '');
+  const state = !r.connected ? t('fleet.disconnected') : r.state;
+  return h`<div class="${cls}">
+    <div class="kb-robot-id">${r.vehicle_id}</div>
+    <div class="kb-robot-state">${state}</div>
+    <div class="kb-robot-battery">${r.battery}%${r.charging ? ' · ' + t('fleet.charging') : ''}</div>
+    <div class="kb-robot-station">${r.station || r.last_station || ''}</div>
+    ${r.order_id > 0 && [h`<div class="kb-robot-order">O-${r.order_id} · ${r.order_status}</div>`]}
+  </div>`;
+}
+
+startKioskBoard({
+  endpoint: 'fleet',
+  topics: ['robot-update'],
+  render(board) {
+    const robots = board.robots || [];
+    document.getElementById('kb-fleet').innerHTML = h`<div class="kb-tile-grid">${robots.map(tile)}</div>`;
+    setSummary(t('fleet.summary', {
+      available: board.available, busy: board.busy, faulted: board.faulted,
+      offline: board.disconnected, low: board.low_battery,
+    }));
+    return robots.length === 0;
+  },
+});
@@ -15,14 +15,23 @@
 
 import { el, apiGet, apiPost, apiPut, apiDelete, toast, uiConfirm } from '/static/app.js';
 
-// Known kinds. A kind needs a renderer template + dashboard.js branch to display.
+// Known kinds — the same list as service.DashboardKinds, which also serves
+// each config-schema kind's option defaults at /api/dashboards/kinds. A kind
+// needs a renderer template (dashboardTemplates in handlers_dashboards.go) to
+// display. `scoped` is whether the station scope means anything to it.
 const KINDS = [
-    { value: 'heartbeat', label: 'Heartbeat' },
-    { value: 'task-board', label: 'Flight Board' },
-    { value: 'robot-map', label: 'Robot Map' },
-    { value: 'node-report', label: 'Node Report' },
+    { value: 'heartbeat', label: 'Heartbeat', scoped: true },
+    { value: 'task-board', label: 'Flight Board', scoped: true },
+    { value: 'robot-map', label: 'Robot Map', scoped: true },
+    { value: 'node-report', label: 'Node Report', scoped: false },
+    { value: 'lineside', label: 'Lineside', scoped: true },
+    { value: 'lane-map', label: 'Lane Map', scoped: false },
+    { value: 'production', label: 'Production vs Plan', scoped: true },
+    { value: 'alert-board', label: 'Alerts', scoped: false },
+    { value: 'fleet-status', label: 'Fleet Status', scoped: false },
 ];
 const kindLabel = (k) => (KINDS.find((x) => x.value === k) || {}).label || k;
+const isScoped = (k) => !!(KINDS.find((x) => x.value === k) || { scoped: true }).scoped;
 
 const canEdit = !!document.getElementById('dash-new');
 let dashboards = [];
@@ -231,6 +240,24 @@ function openModal(d) {
     const loaderSelect = el('select', { className: 'form-input' },
         [el('option', { value: '' }, 'Loading loaders\u2026')]);
     const loaderField = field('Loader', loaderSelect);
+
+    // Options — shown only for a kind with a config schema. Prefilled with the
+    // saved config when editing that kind, else the kind's defaults; the server
+    // validates it on save, so an unknown or out-of-range option is refused
+    // there with its name rather than becoming a blank wall screen.
+    const kindDefaults = {};
+    const optionsInput = el('textarea', { className: 'form-input', rows: '6', spellcheck: 'false', style: { fontFamily: 'monospace' } });
+    const optionsField = field('Options (JSON)', optionsInput);
+    function fillOptions() {
+        const kind = kindSelect.value;
+        const cfg = (d && d.kind === kind && d.config) ? d.config : kindDefaults[kind];
+        optionsInput.value = cfg ? JSON.stringify(cfg, null, 2) : '';
+    }
+    apiGet('/api/dashboards/kinds').then((list) => {
+        (list || []).forEach((k) => { if (k.defaults) kindDefaults[k.kind] = k.defaults; });
+        fillOptions();
+        syncKindUI();
+    }).catch(() => { /* the field stays hidden; the server's defaults apply */ });
     apiGet('/api/loader/list').then((resp) => {
         loaderSelect.innerHTML = '';
         const items = (resp && resp.loaders) ? resp.loaders : [];
@@ -254,6 +281,7 @@ function openModal(d) {
             field('Area \u2014 stations (none selected = whole plant)', pickerBox),
             loaderField,
             cellsField,
+            optionsField,
             el('label', { style: { display: 'flex', alignItems: 'center', gap: '0.5rem', marginBottom: '0.75rem' } }, [enabledInput, 'Enabled']),
             el('div', { style: { display: 'flex', gap: '0.5rem', justifyContent: 'flex-end', marginTop: '1rem' } }, [
                 el('button', { className: 'btn', onclick: close }, 'Cancel'),
@@ -266,10 +294,11 @@ function openModal(d) {
     function syncKindUI() {
         const isNodeReport = kindSelect.value === 'node-report';
         cellsField.style.display = kindSelect.value === 'heartbeat' ? '' : 'none';
-        pickerBox.parentElement.style.display = isNodeReport ? 'none' : '';
+        pickerBox.parentElement.style.display = isScoped(kindSelect.value) ? '' : 'none';
         loaderField.style.display = isNodeReport ? '' : 'none';
+        optionsField.style.display = kindDefaults[kindSelect.value] ? '' : 'none';
     }
-    kindSelect.addEventListener('change', syncKindUI);
+    kindSelect.addEventListener('change', () => { fillOptions(); syncKindUI(); });
     syncKindUI();
     setTimeout(() => nameInput.focus(), 0);
 
@@ -279,30 +308,20 @@ function openModal(d) {
         const name = nameInput.value.trim();
         if (!name) { toast('Name is required', 'error'); return; }
         const kind = kindSelect.value;
-        const payload = { name, kind, stations: kind !== 'node-report' ? Array.from(selected) : [], enabled: enabledInput.checked };
+        const payload = { name, kind, stations: isScoped(kind) ? Array.from(selected) : [], enabled: enabledInput.checked };
         if (kind === 'heartbeat') payload.config = buildConfig();
         if (kind === 'node-report') {
             const lid = parseInt(loaderSelect.value, 10);
             if (!lid) { toast('Select a loader', 'error'); return; }
             payload.config = { loader_id: lid };
         }
-        const req = isEdit ? apiPut('/api/dashboards/' + d.id, payload) : apiPost('/api/dashboards', payload);
-        req.then(() => { toast(isEdit ? 'Saved' : 'Created', 'success'); close(); load(); })
-            .catch((e) => toast('Save failed: ' + e, 'error'));
-    }
-
-    function close() { overlay.remove(); }
-
-    function doSave() {
-        const name = nameInput.value.trim();
-        if (!name) { toast('Name is required', 'error'); return; }
-        const kind = kindSelect.value;
-        const payload = { name, kind, stations: kind !== 'node-report' ? Array.from(selected) : [], enabled: enabledInput.checked };
-        if (kind === 'heartbeat') payload.config = buildConfig();
-        if (kind === 'node-report') {
-            const lid = parseInt(loaderSelect.value, 10);
-            if (!lid) { toast('Select a loader', 'error'); return; }
-            payload.config = { loader_id: lid };
+        if (kindDefaults[kind] && optionsInput.value.trim()) {
+            try {
+                payload.config = JSON.parse(optionsInput.value);
+            } catch (e) {
+                toast('Options are not valid JSON: ' + e.message, 'error');
+                return;
+            }
         }
         const req = isEdit ? apiPut('/api/dashboards/' + d.id, payload) : apiPost('/api/dashboards', payload);
         req.then(() => { toast(isEdit ? 'Saved' : 'Created', 'success'); close(); load(); })
@@ -447,7 +466,12 @@ function renderCoreHealth(h) {
     const build = document.querySelector('.cs-build');
     if (build && h.version) {
         build.textContent = h.version + ' · up ' + h.uptime;
-        build.title = h.commit || '';
+        // The certificate lives with the build stamp: both describe the
+        // running process, and the date is only news in its last month,
+        // when the verdict already says so.
+        build.title = (h.commit || '') + (h.cert_expires_at
+            ? `\nHTTPS certificate expires ${h.cert_expires_at.slice(0, 10)} (${h.cert_days_left} days)`
+            : '');
     }
 
     const goroVal = document.getElementById('cs-goro-val');
@@ -0,0 +1,49 @@
+// dashboard-lanes.js — the lane map wall display: each node group's lanes as
+// a row of slots, mouth first, coloured by what is in them. Redraws on every
+// bin or node change.
+
+import { h } from '/static/shared/utils.js';
+import { startKioskBoard, setSummary } from '/static/components/kiosk-board.js';
+import { t } from '/static/i18n.js';
+
+const STATE_LABEL = {
+  loaded: t('lanes.state_loaded'),
+  'empty-bin': t('lanes.state_empty_bin'),
+  reserved: t('lanes.state_reserved'),
+  free: t('lanes.state_free'),
+};
+
+function slot(s) {
+  const cls = 'kb-slot kb-slot-' + s.state + (s.claimed ? ' kb-slot-claimed' : '');
+  const title = s.name + ' — ' + STATE_LABEL[s.state] + (s.claimed ? ' ' + t('lanes.claimed') : '');
+  return h`<div class="${cls}" title="${title}">
+    <span class="kb-slot-payload">${s.payload || ''}</span>
+    ${s.uop > 0 && [h`<span class="kb-slot-uop">${s.uop}</span>`]}
+  </div>`;
+}
+
+function lane(l) {
+  return h`<div class="kb-lane"><span class="kb-lane-name">${l.name}</span>
+    <div class="kb-lane-slots">${l.slots.map(slot)}</div></div>`;
+}
+
+startKioskBoard({
+  endpoint: 'lanes',
+  topics: ['bin-update', 'node-update'],
+  render(groups) {
+    let occupied = 0;
+    let slots = 0;
+    const html = groups.map((g) => {
+      occupied += g.occupied;
+      slots += g.slots;
+      return h`<section class="kb-section"><h2 class="kb-section-title">${g.name}
+        <span class="kb-section-meta">${g.occupied} / ${g.slots}</span></h2>
+        ${g.lanes.map(lane)}</section>`;
+    });
+    const legend = h`<div class="kb-legend">${Object.keys(STATE_LABEL).map((k) =>
+      h`<span class="kb-legend-item"><span class="kb-slot kb-slot-${k} kb-legend-swatch"></span>${STATE_LABEL[k]}</span>`)}</div>`;
+    document.getElementById('kb-groups').innerHTML = groups.length ? legend + html.join('') : '';
+    setSummary(t('lanes.summary', { occupied, slots }));
+    return groups.length === 0;
+  },
+});
@@ -0,0 +1,47 @@
+// dashboard-lineside.js — the lineside wall display: a tile per node and
+// payload reported at the board's stations, low and stale ones marked. Redraws
+// on each persisted report (lineside-update) and each bin move.
+
+import { h } from '/static/shared/utils.js';
+import { startKioskBoard, setSummary } from '/static/components/kiosk-board.js';
+import { t } from '/static/i18n.js';
+
+function age(ts) {
+  const min = Math.floor((Date.now() - new Date(ts).getTime()) / 60000);
+  if (min < 1) return t('board.just_now');
+  if (min < 60) return t('board.minutes_ago', { n: min });
+  return t('board.hours_ago', { n: Math.floor(min / 60) });
+}
+
+function tile(x) {
+  const cls = 'kb-tile' + (x.low ? ' kb-low' : '') + (x.stale ? ' kb-stale' : '');
+  return h`<div class="${cls}">
+    <div class="kb-tile-payload">${x.payload}</div>
+    <div class="kb-tile-level">${x.level}</div>
+    <div class="kb-tile-node">${x.node}</div>
+    <div class="kb-tile-age">${x.stale ? t('lineside.stale') + ' · ' : ''}${age(x.reported_at)}</div>
+  </div>`;
+}
+
+startKioskBoard({
+  endpoint: 'lineside',
+  topics: ['lineside-update', 'bin-update'],
+  everyMs: 60000,
+  render(tiles) {
+    const byStation = new Map();
+    for (const x of tiles) {
+      if (!byStation.has(x.station)) byStation.set(x.station, []);
+      byStation.get(x.station).push(x);
+    }
+    const html = [];
+    for (const [station, list] of byStation) {
+      html.push(h`<section class="kb-section"><h2 class="kb-section-title">${station}</h2>
+        <div class="kb-tile-grid">${list.map(tile)}</div></section>`);
+    }
+    document.getElementById('kb-tiles').innerHTML = html.join('');
+    const low = tiles.filter((x) => x.low).length;
+    const stale = tiles.filter((x) => x.stale).length;
+    setSummary(t('lineside.summary', { low, stale, tiles: tiles.length }));
+    return tiles.length === 0;
+  },
+});
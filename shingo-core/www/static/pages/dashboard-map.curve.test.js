@@ -98,6 +98,7 @@ function load() {
         fetch() { return Promise.resolve({ ok: true, json() { return Promise.resolve([]); } }); },
         onSSE() {},
         setSSEReloadOnBuild() {},
+        t(key) { return key; },
     };
     let exported = null;
     ctx.__export = function (o) { exported = o; };
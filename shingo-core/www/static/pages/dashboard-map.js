@@ -43,6 +43,13 @@
 // handles at all, or an all-zero pair Core rejects before this file sees it.
 // Every class name in the scene therefore covers both shapes, and only the
 // geometry says which one a given lane is.
+//
+// REPLAY. With ?replay=1 the map opens no SSE: the replay bar
+// (components/replay.js) feeds robots and orders through the same merge and
+// order paths the live feed uses, plus each node's bin and slot hold, which
+// the live map does not draw. The network drawn is today's — /api/map/points
+// and /api/map/edges have no past — so a replay from before a scene change
+// shows the robots over lanes that have since moved.
 
 import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
 // The scene-drawing substrate — projection, cubic arithmetic, lane identity —
@@ -52,10 +59,13 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
 import {
   makeProjector, dist2, isCoord, cubicLength, cubicPathD, laneKey
 } from '/static/components/scene-geom.js';
+import { startReplay } from '/static/components/replay.js';
+import { t } from '/static/i18n.js';
 
 (function () {
   var body = document.body;
   var dashboardId = body.getAttribute('data-dashboard-id');
+  var replayMode = body.getAttribute('data-replay') === '1';
   var SVGNS = 'http://www.w3.org/2000/svg';
 
   // Read a CSS custom property off :root with a hex fallback, so the map's
@@ -138,6 +148,8 @@ import {
   var orders = [];          // scoped active orders
   var orderByRobot = {};    // robot_id -> order
   var hotNodes = {};        // lowercased node name -> status (highlight)
+  var replayBins = null;    // replay only: node name -> {label, payload} of the bin on it
+  var replayHolds = null;   // replay only: node name -> id of the order holding it
   var view = null;          // {minX, minY, w, h} screen-space bounding box
   var rotate90 = false;     // orient the plant's long axis along screen X
   var focusRobot = null;    // click-to-focus: id of the robot whose route is lit
@@ -201,9 +213,10 @@ import {
   // ── header chrome ──────────────────────────────────────────────────
   function tickClock() {
     var el = document.getElementById('dash-clock');
-    if (el) el.textContent = new Date().toLocaleTimeString();
+    if (el) el.textContent = new Date().toLocaleTimeString(document.documentElement.lang || undefined);
   }
-  setInterval(tickClock, 1000); tickClock();
+  // In replay the bar owns the clock: it shows the playhead.
+  if (!replayMode) { setInterval(tickClock, 1000); tickClock(); }
 
   function setConnected(ok) {
     var el = document.getElementById('dash-conn');
@@ -245,18 +258,19 @@ import {
       if (b) counts[b]++;
     });
     var items = [
-      ['in_transit', 'In transit', STATUS_COLOR.in_transit], ['staged', 'Staged', STATUS_COLOR.staged],
-      ['blocked', 'Blocked', STATUS_COLOR.blocked],
-      ['charging', 'Charging', DOCK_COLOR.charge], ['parked', 'Parked', DOCK_COLOR.park],
-      ['idle', 'Idle', STATE_COLOR.ready], ['error', 'Error', STATE_COLOR.error]
+      ['in_transit', t('map.legend_in_transit'), STATUS_COLOR.in_transit],
+      ['staged', t('map.legend_staged'), STATUS_COLOR.staged],
+      ['blocked', t('map.legend_blocked'), STATUS_COLOR.blocked],
+      ['charging', t('map.legend_charging'), DOCK_COLOR.charge], ['parked', t('map.legend_parked'), DOCK_COLOR.park],
+      ['idle', t('map.legend_idle'), STATE_COLOR.ready], ['error', t('map.legend_error'), STATE_COLOR.error]
     ];
     // Only active states show — a zero bucket is just clutter (Blocked sitting
     // there doing nothing). Exceptions (Blocked/Error) therefore appear only
     // when something is actually blocked/faulted, so they read as real signal.
     el.innerHTML = items.filter(function (it) { return counts[it[0]] > 0; }).map(function (it) {
       return '<span class="map-legend-item"><span class="map-legend-dot" style="background:' + it[2] +
-        '"></span>' + it[1] + '<span class="map-legend-count">' + counts[it[0]] + '</span></span>';
-    }).join('') || '<span class="map-legend-item map-legend-zero">No active robots</span>';
+        '"></span>' + escapeText(it[1]) + '<span class="map-legend-count">' + counts[it[0]] + '</span></span>';
+    }).join('') || '<span class="map-legend-item map-legend-zero">' + escapeText(t('map.no_active_robots')) + '</span>';
   }
 
   // ── robot normalization (handles SSE lowercase + REST PascalCase) ──
@@ -1304,6 +1318,39 @@ import {
       glyph.setAttribute('stroke-width', nodeR * 0.45);
       glyph.setAttribute('style', 'filter: drop-shadow(0 0 ' + (nodeR * 1.1).toFixed(2) + 'px ' + ACCENT_GLOW + ')');
     }
+    if (replayBins) drawReplayMark(svg, p, s, nodeR);
+  }
+
+  // drawReplayMark marks a node's bin and slot hold during replay: a filled
+  // square for a loaded bin, an outlined one for an empty bin, and a dashed
+  // accent ring for a slot held as an order's destination with no bin on it
+  // yet.
+  function drawReplayMark(svg, p, s, nodeR) {
+    var names = [p.point_name, p.label, p.instance_name].filter(Boolean);
+    var bin = null, hold = null;
+    names.forEach(function (n) {
+      if (!bin && replayBins[n]) bin = replayBins[n];
+      if (!hold && replayHolds[n]) hold = replayHolds[n];
+    });
+    var side = nodeR * 1.4;
+    if (bin) {
+      var sq = svgEl('rect', {
+        x: s[0] - side / 2, y: s[1] - side / 2, width: side, height: side,
+        fill: bin.payload ? STATUS_COLOR.delivered : 'none',
+        stroke: STATUS_COLOR.delivered, 'stroke-width': nodeR * 0.2
+      });
+      var tip = document.createElementNS(SVGNS, 'title');
+      tip.textContent = bin.payload
+        ? t('map.bin_payload', { bin: bin.label || t('map.bin'), payload: bin.payload })
+        : t('map.bin_empty', { bin: bin.label || t('map.bin') });
+      sq.appendChild(tip);
+      svg.appendChild(sq);
+    } else if (hold) {
+      svg.appendChild(svgEl('circle', {
+        cx: s[0], cy: s[1], r: nodeR * 1.3, fill: 'none', stroke: ACCENT,
+        'stroke-width': nodeR * 0.2, 'stroke-dasharray': (nodeR * 0.5) + ' ' + (nodeR * 0.35)
+      }));
+    }
   }
 
   function render() {
@@ -1656,10 +1703,10 @@ import {
     var have = {};
     points.forEach(function (p) { have[classOf(p)] = true; });
     var items = [];
-    if (have.LocationMark || have.GeneralLocation) items.push(legendSwatch(cssVar('--map-node', '#7e92b3'), 'dot', 'Travel node'));
-    if (have.ActionPoint) items.push(legendSwatch(NODE_ACTION_COLOR, 'dot', 'Action point'));
-    if (have.ChargePoint) items.push(legendSwatch(CHARGE_RING, 'ring', 'Charge point'));
-    if (have.ParkPoint) items.push(legendSwatch(cssVar('--map-node', '#7e92b3'), 'dot', 'Park point'));
+    if (have.LocationMark || have.GeneralLocation) items.push(legendSwatch(cssVar('--map-node', '#7e92b3'), 'dot', t('map.travel_node')));
+    if (have.ActionPoint) items.push(legendSwatch(NODE_ACTION_COLOR, 'dot', t('map.action_point')));
+    if (have.ChargePoint) items.push(legendSwatch(CHARGE_RING, 'ring', t('map.charge_point')));
+    if (have.ParkPoint) items.push(legendSwatch(cssVar('--map-node', '#7e92b3'), 'dot', t('map.park_point')));
     Object.keys(have).sort().forEach(function (n) {
       if (n === 'LocationMark' || n === 'GeneralLocation' || n === 'ActionPoint' ||
           n === 'ChargePoint' || n === 'ParkPoint') return;
@@ -1671,7 +1718,7 @@ import {
     // legend strip competing with the live status counts in the header.
     el.innerHTML =
       '<button type="button" class="map-key-toggle" aria-expanded="' + mapKeyOpen + '">' +
-        (mapKeyOpen ? '▾' : '▸') + ' Map key</button>' +
+        (mapKeyOpen ? '▾' : '▸') + ' ' + escapeText(t('map.key')) + '</button>' +
       '<div class="map-key-items"' + (mapKeyOpen ? '' : ' hidden') + '>' + items.join('') + '</div>';
     var btn = el.querySelector('.map-key-toggle');
     if (btn) btn.addEventListener('click', function () { mapKeyOpen = !mapKeyOpen; renderClassLegend(); });
@@ -1695,17 +1742,17 @@ import {
       newMap[key] = o;
       var prev = prevOrderMap[key];
       if (!prev) {
-        if (o.delivery_node) pushFeedEvent(o.delivery_node + ' called for parts');
+        if (o.delivery_node) pushFeedEvent(t('map.called_for_parts', { node: o.delivery_node }));
       } else if (prev.status !== o.status) {
         var st = o.status;
         if (st === 'dispatched' || st === 'in_transit') {
-          pushFeedEvent((o.robot_id || '?') + ' responding');
+          pushFeedEvent(t('map.responding', { robot: o.robot_id || '?' }));
         } else if (st === 'staged') {
-          pushFeedEvent((o.robot_id || '?') + ' staged · ' + (o.delivery_node || '?'));
+          pushFeedEvent(t('map.staged_at', { robot: o.robot_id || '?', node: o.delivery_node || '?' }));
         } else if (st === 'delivered' || st === 'confirmed') {
-          pushFeedEvent('Delivered to ' + (o.delivery_node || '?'));
+          pushFeedEvent(t('map.delivered_to', { node: o.delivery_node || '?' }));
         } else if (st === 'blocked' || st === 'faulted') {
-          pushFeedEvent((o.delivery_node || o.source_node || '?') + ' blocked', 'alert');
+          pushFeedEvent(t('map.blocked_at', { node: o.delivery_node || o.source_node || '?' }), 'alert');
         }
       }
     });
@@ -1732,12 +1779,12 @@ import {
     var overflow = active.length > 8 ? active.length - 8 : 0;
     var shown = active.slice(0, 8);
     var statusLabel = {
-      in_transit: 'moving', staged: 'staged', dispatched: 'dispatched',
-      blocked: 'BLOCKED', faulted: 'FAULTED', acknowledged: 'waiting',
-      queued: 'queued', pending: 'pending', reshuffling: 'reshuffling'
+      in_transit: t('map.status_moving'), staged: t('map.status_staged'), dispatched: t('map.status_dispatched'),
+      blocked: t('map.status_blocked'), faulted: t('map.status_faulted'), acknowledged: t('map.status_waiting'),
+      queued: t('map.status_queued'), pending: t('map.status_pending'), reshuffling: t('map.status_reshuffling')
     };
     if (!shown.length) {
-      motionEl.innerHTML = '<li class="rail-empty">No active orders</li>';
+      motionEl.innerHTML = '<li class="rail-empty">' + escapeText(t('map.no_active_orders')) + '</li>';
     } else {
       motionEl.innerHTML = shown.map(function (o) {
         var color = STATUS_COLOR[o.status] || '#888';
@@ -1748,7 +1795,7 @@ import {
           '<span class="rail-row-node">' + escapeText(o.delivery_node || '?') + '</span>' +
           '<span class="rail-row-status" style="color:' + color + '">' + escapeText(label) + '</span>' +
           '</li>';
-      }).join('') + (overflow ? '<li class="rail-empty">+' + overflow + ' more</li>' : '');
+      }).join('') + (overflow ? '<li class="rail-empty">' + escapeText(t('map.more', { n: overflow })) + '</li>' : '');
     }
 
     // ── Activity feed ─────────────────────────────────────────────────
@@ -1756,7 +1803,7 @@ import {
     activityFeed = activityFeed.filter(function (e) { return (now - e.ts) < FEED_MAX_AGE_MS; });
     var showing = activityFeed.slice(0, FEED_MAX_ITEMS);
     if (!showing.length) {
-      activityEl.innerHTML = '<li class="rail-empty">No recent activity</li>';
+      activityEl.innerHTML = '<li class="rail-empty">' + escapeText(t('map.no_recent_activity')) + '</li>';
     } else {
       activityEl.innerHTML = showing.map(function (e) {
         var ageFrac = (now - e.ts) / FEED_MAX_AGE_MS;
@@ -1815,19 +1862,39 @@ import {
     return fetch('/api/board/orders?dashboard=' + encodeURIComponent(dashboardId)).then(function (r) {
       if (!r.ok) throw new Error('HTTP ' + r.status);
       return r.json();
-    }).then(function (data) {
-      diffAndUpdateOrders(data || []); // diff against snapshot BEFORE updating `orders`
-      orders = data || [];
-      orderByRobot = {};
-      hotNodes = {};
-      var INACTIVE_HOT = { delivered: true, confirmed: true, cancelled: true };
-      orders.forEach(function (o) {
-        if (o.robot_id) orderByRobot[o.robot_id] = o;
-        if (INACTIVE_HOT[o.status]) return;
-        if (o.source_node) hotNodes[String(o.source_node).toLowerCase()] = o.status;
-        if (o.delivery_node) hotNodes[String(o.delivery_node).toLowerCase()] = o.status;
-      });
+    }).then(function (data) { setOrders(data || []); });
+  }
+
+  // setOrders adopts a board-order list, live or replayed.
+  function setOrders(list) {
+    diffAndUpdateOrders(list); // diff against snapshot BEFORE updating `orders`
+    orders = list;
+    orderByRobot = {};
+    hotNodes = {};
+    var INACTIVE_HOT = { delivered: true, confirmed: true, cancelled: true };
+    orders.forEach(function (o) {
+      if (o.robot_id) orderByRobot[o.robot_id] = o;
+      if (INACTIVE_HOT[o.status]) return;
+      if (o.source_node) hotNodes[String(o.source_node).toLowerCase()] = o.status;
+      if (o.delivery_node) hotNodes[String(o.delivery_node).toLowerCase()] = o.status;
+    });
+  }
+
+  // applyReplayFrame draws one replay frame. Robots with no pose yet at the
+  // playhead leave the floor; the rest merge as live updates do.
+  function applyReplayFrame(state) {
+    var present = {};
+    state.robots.forEach(function (raw) {
+      var rb = normRobot(raw);
+      if (!rb.id) return;
+      present[rb.id] = true;
+      mergeRobot(rb);
     });
+    Object.keys(robots).forEach(function (id) { if (!present[id]) delete robots[id]; });
+    setOrders(state.orders);
+    replayBins = state.bins;
+    replayHolds = state.holds;
+    scheduleRender();
   }
 
   var orderTimer = null;
@@ -1860,6 +1927,11 @@ import {
     renderLegend();
     wireViewControls(); // wheel-zoom + drag-pan + recenter on the map host
     startFeedTimer(); // starts the 10s interval that ages/expires feed events
+    if (replayMode) {
+      Promise.all([loadPoints().catch(noop), loadEdges().catch(noop)]).then(scheduleRender);
+      startReplay({ dashboardId: dashboardId, onFrame: applyReplayFrame });
+      return;
+    }
     // Initial paint from REST so the board isn't blank before the first SSE tick.
     refreshAll();
     setSSEReloadOnBuild(true);
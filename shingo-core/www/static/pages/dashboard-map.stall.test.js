@@ -94,9 +94,11 @@ function load() {
         requestAnimationFrame() { return 0; },
         cancelAnimationFrame() {},
         fetch() { return Promise.resolve({ ok: true, json() { return Promise.resolve([]); } }); },
-        // Injected in place of the stripped ES import.
+        // Injected in place of the stripped ES imports. t returns the key, as
+        // i18n.js does for a page with no catalog.
         onSSE() {},
         setSSEReloadOnBuild() {},
+        t(key) { return key; },
     };
     let exported = null;
     ctx.__export = function (o) { exported = o; };
@@ -1,4 +1,5 @@
 import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
+import { t } from '/static/i18n.js';
 
 (function () {
   var body = document.body;
@@ -7,7 +8,7 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
 
   function tickClock() {
     var el = document.getElementById('dash-clock');
-    if (el) el.textContent = new Date().toLocaleTimeString();
+    if (el) el.textContent = new Date().toLocaleTimeString(document.documentElement.lang || undefined);
   }
   setInterval(tickClock, 1000);
   tickClock();
@@ -25,17 +26,23 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
 
   function headerHTML(layout) {
     if (layout === 'shared_window') {
-      return '<th>Payload</th><th>Status</th><th>Node</th><th>UoP</th>';
+      return th('node_report.col_payload') + th('node_report.col_status') + th('node_report.col_node') +
+        th('node_report.col_uop');
     }
-    return '<th>Node</th><th>Node Group</th><th>Status</th><th>Payload</th><th>UoP</th>';
+    return th('node_report.col_node') + th('node_report.col_group') + th('node_report.col_status') +
+      th('node_report.col_payload') + th('node_report.col_uop');
+  }
+
+  function th(key) {
+    return '<th>' + esc(t(key)) + '</th>';
   }
 
   function rowHTML(r, layout) {
     var isShared = layout === 'shared_window';
     var statusHTML = r.occupied
-      ? '<span class="nr-dot nr-dot-filled"></span> FILLED'
-      : '<span class="nr-dot nr-dot-empty nr-dot-pulse"></span> EMPTY';
-    var uopText = r.uop_remaining ? r.uop_remaining + ' UoP' : '\u2014';
+      ? '<span class="nr-dot nr-dot-filled"></span> ' + esc(t('node_report.filled'))
+      : '<span class="nr-dot nr-dot-empty nr-dot-pulse"></span> ' + esc(t('node_report.empty_slot'));
+    var uopText = r.uop_remaining ? t('node_report.uop', { n: r.uop_remaining }) : '\u2014';
     var activeClass = r.is_active_style ? ' nr-row-active' : '';
 
     if (isShared) {
@@ -101,18 +108,18 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
     if (tbodies.length > 1) {
       var first = tbodies[0];
       first.style.overflowY = 'auto';
-      for (var t = 1; t < tbodies.length; t++) {
+      for (var k = 1; k < tbodies.length; k++) {
         (function (slave) {
           first.addEventListener('scroll', function () {
             slave.scrollTop = first.scrollTop;
           });
-        })(tbodies[t]);
+        })(tbodies[k]);
       }
     }
 
     if (stats) {
       stats.innerHTML =
-        '<span class="nr-stat-label">' + filled + ' / ' + rows.length + ' filled</span>';
+        '<span class="nr-stat-label">' + esc(t('node_report.filled_count', { filled: filled, total: rows.length })) + '</span>';
     }
   }
 
@@ -130,16 +137,16 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
       var r = rows[i];
       if (r.is_empty) {
         var src = r.source_node ? esc(r.source_node) + ' \u2192 ' : '';
-        parts.push('\u25c6 EMPTY ' + src + 'returning');
+        parts.push('\u25c6 ' + t('node_report.empty_returning', { from: src }));
       } else if (r.is_partial) {
         var src2 = r.source_node ? esc(r.source_node) + ' \u2192 ' : '';
-        parts.push('\u25c6 PARTIAL (' + r.uop_remaining + ' UoP) ' + src2 + 'returning');
+        parts.push('\u25c6 ' + t('node_report.partial_returning', { n: r.uop_remaining, from: src2 }));
       } else {
         var arrow = '\u2192 ' + esc(r.payload_code);
         if (r.dest_node) {
           arrow += ' \u2192 ' + esc(r.dest_node);
         } else {
-          arrow += ' in transit';
+          arrow += ' ' + esc(t('node_report.in_transit'));
         }
         parts.push(arrow);
       }
@@ -159,8 +166,10 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
         if (data.loader_name) {
           if (titleEl) titleEl.textContent = data.loader_name;
           var count = data.homes_count || data.payloads_count || 0;
-          var label = data.layout === 'shared_window' ? 'payload' : 'position';
-          if (subEl) subEl.textContent = data.layout + ' \u00b7 ' + count + ' ' + label + (count !== 1 ? 's' : '');
+          var key = data.layout === 'shared_window'
+            ? (count === 1 ? 'node_report.payloads_one' : 'node_report.payloads_other')
+            : (count === 1 ? 'node_report.positions_one' : 'node_report.positions_other');
+          if (subEl) subEl.textContent = t(key, { layout: data.layout, n: count });
         }
         render(data.layout, data.rows || []);
         renderTransit(data.transit || []);
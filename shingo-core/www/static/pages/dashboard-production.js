@@ -0,0 +1,37 @@
+// dashboard-production.js — the production-vs-plan wall display: each cell's
+// parts per hour against its hourly target. Redraws on every counted tick
+// (cell-heartbeat) and once a minute, since the running hour's plan grows.
+
+import { h } from '/static/shared/utils.js';
+import { startKioskBoard, setSummary } from '/static/components/kiosk-board.js';
+import { t } from '/static/i18n.js';
+
+function hourCell(hr) {
+  const cls = 'kb-num' + (hr.status ? ' kb-' + hr.status : '');
+  const title = hr.status ? t('production.plan', { n: hr.expected }) : t('production.no_target');
+  return h`<td class="${cls}" title="${title}">${hr.parts}</td>`;
+}
+
+function totalCell(row) {
+  if (!row.target) return h`<td class="kb-num" title="${t('production.no_target')}">${row.total}</td>`;
+  const cls = 'kb-num ' + (row.total >= row.planned ? 'kb-met' : 'kb-behind');
+  return h`<td class="${cls}">${row.total} / ${row.planned}</td>`;
+}
+
+startKioskBoard({
+  endpoint: 'production',
+  topics: ['cell-heartbeat'],
+  everyMs: 60000,
+  render(board) {
+    const rows = board.rows || [];
+    const head = h`<thead><tr><th>${t('production.col_cell')}</th><th class="kb-num">${t('production.col_target')}</th>
+      ${board.hours.map((l) => h`<th class="kb-num">${l}</th>`)}<th class="kb-num">${t('production.col_total')}</th></tr></thead>`;
+    const body = rows.map((r) => h`<tr><td>${r.name}</td>
+      <td class="kb-num">${r.target || '—'}</td>${r.hours.map(hourCell)}${[totalCell(r)]}</tr>`);
+    document.getElementById('kb-production').innerHTML =
+      rows.length ? h`<table class="kb-table">${[head]}<tbody>${body}</tbody></table>` : '';
+    const behind = rows.filter((r) => r.target && r.total < r.planned).length;
+    setSummary(t('production.summary', { behind, cells: rows.length }));
+    return rows.length === 0;
+  },
+});
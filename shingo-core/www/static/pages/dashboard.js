@@ -14,21 +14,30 @@
 //
 // Adding a new dashboard kind: branch on `kind` in init() and render into
 // #dash-main; register the kind's renderer template in handlers_dashboards.go.
+//
+// With ?replay=1 the board opens no SSE at all: the replay bar
+// (components/replay.js) drives render() with the orders as they stood at the
+// playhead, and owns the header clock.
 
 import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
+import { startReplay } from '/static/components/replay.js';
+import { t } from '/static/i18n.js';
 
 (function () {
   var body = document.body;
   var dashboardId = body.getAttribute('data-dashboard-id');
   var kind = body.getAttribute('data-dashboard-kind') || 'task-board';
+  var replayMode = body.getAttribute('data-replay') === '1';
 
   // ── Header chrome: clock + connection dot ──────────────────────────
   function tickClock() {
     var el = document.getElementById('dash-clock');
-    if (el) el.textContent = new Date().toLocaleTimeString();
+    if (el) el.textContent = new Date().toLocaleTimeString(document.documentElement.lang || undefined);
+  }
+  if (!replayMode) {
+    setInterval(tickClock, 1000);
+    tickClock();
   }
-  setInterval(tickClock, 1000);
-  tickClock();
 
   function setConnected(ok) {
     var el = document.getElementById('dash-conn');
@@ -47,7 +56,7 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
     var d = new Date(str);
     if (isNaN(d.getTime())) return '-';
     var diff = d - Date.now();
-    if (diff <= 0) return 'arriving';
+    if (diff <= 0) return t('board.arriving');
     var mins = Math.floor(diff / 60000);
     if (mins < 1) return '<1m';
     if (mins < 60) return mins + 'm';
@@ -56,9 +65,11 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
   }
 
   var STATUS_LABELS = {
-    pending: 'Pending', queued: 'Queued', acknowledged: 'ACK', staged: 'Staged',
-    dispatched: 'Dispatched', in_transit: 'In Transit', blocked: 'Blocked',
-    delivered: 'Delivered', completed: 'Done'
+    pending: t('board.status_pending'), queued: t('board.status_queued'),
+    acknowledged: t('board.status_acknowledged'), staged: t('board.status_staged'),
+    dispatched: t('board.status_dispatched'), in_transit: t('board.status_in_transit'),
+    blocked: t('board.status_blocked'), delivered: t('board.status_delivered'),
+    completed: t('board.status_completed')
   };
   function statusLabel(s) { return STATUS_LABELS[s] || s || '-'; }
   function statusClass(s) { return 'st-' + (s || 'unknown'); }
@@ -122,6 +133,10 @@ import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
       console.warn('dashboard: unsupported kind:', kind);
       return;
     }
+    if (replayMode) {
+      startReplay({ dashboardId: dashboardId, onFrame: function (s) { render(s.orders); } });
+      return;
+    }
     setSSEReloadOnBuild(true);
     onSSE('connected', function () { setConnected(true); load(); });
     onSSE('disconnected', function () { setConnected(false); });
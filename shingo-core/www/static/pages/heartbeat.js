@@ -17,6 +17,7 @@
 import { onSSE, setSSEReloadOnBuild } from '/static/shared/utils.js';
 import { CellTile, updateCellTile, pulseCellDot } from '/static/components/CellTile.js';
 import { openCellDrill } from '/static/components/CellDrill.js';
+import { t } from '/static/i18n.js';
 
 setSSEReloadOnBuild(true);
 
@@ -61,7 +62,7 @@ function loadCells() {
             // P4.4: a freshly-seeded board has no cells yet — render guidance,
             // not a dead end. (SCOPE_STATIONS may also be empty here, which is
             // fine: an unscoped board shows every cell once any exist.)
-            grid.appendChild(el('div', 'hb-empty', '0 cells configured. Set up cells at /admin/cells, then scope this board in Manage.'));
+            grid.appendChild(el('div', 'hb-empty', t('heartbeat.no_cells')));
             return;
         }
         cellList.forEach((c, i) => {
@@ -207,7 +208,7 @@ function stopLoop() {
 // ─── clock text (1Hz; separate from the rAF strip) ──────────────────────────
 function tickClock() {
     const elc = document.getElementById('hb-clock');
-    if (elc) elc.textContent = new Date(serverNow()).toLocaleTimeString();
+    if (elc) elc.textContent = new Date(serverNow()).toLocaleTimeString(document.documentElement.lang || undefined);
 }
 
 // ─── connection pill ────────────────────────────────────────────────────────
@@ -215,7 +216,9 @@ function setConnected(on) {
     const c = document.getElementById('hb-conn');
     if (!c) return;
     c.classList.toggle('is-live', !!on);
-    c.textContent = on ? 'live' : 'offline';
+    // The words come from the page, which the server rendered in the kiosk's
+    // language.
+    c.textContent = on ? (c.dataset.live || 'live') : (c.dataset.offline || 'offline');
 }
 
 // ─── boot ───────────────────────────────────────────────────────────────────
@@ -9,6 +9,8 @@
 //   /api/buckets — lineside buckets, now carrying updated_at for staleness.
 //   /api/nodes — node id -> name, for home-loader node labels.
 //   /api/parts/consumption?since&until — window consumption for the drill.
+//   /api/demand-forecast?payload — learned demand per style and shift, and the
+//     threshold it suggests (Forecast; suggestion only, like Calc).
 
 import {
   apiGet, apiPost, escapeHtml, delegateActions, toast, uiConfirm, timeAgo, debounce,
@@ -694,7 +696,8 @@ function thrRowHtml(c) {
     + '<td><code>' + escapeHtml(c.node) + '</code></td>'
     + '<td><span class="kind-pill">' + c.kind + '</span></td>'
     + '<td><input type="number" class="form-input thr-cycle" placeholder="cycle" ' + data + ' style="width:70px"></td>'
-    + '<td><button class="btn btn-sm" data-action="calcThr" ' + data + '>Calc</button></td>'
+    + '<td class="nowrap"><button class="btn btn-sm" data-action="calcThr" ' + data + '>Calc</button> '
+    + '<button class="btn btn-sm" data-action="forecastThr" ' + data + ' title="Suggest from the consumption the lines actually drew">Forecast</button></td>'
     + '<td><input type="number" class="form-input thr-value" value="' + c.thr + '" data-orig="' + c.thr + '" ' + data
     + ' data-action-input="onThrInput"></td>'
     + '<td><input type="number" class="form-input thr-ms" value="' + (c.ms || 0) + '"' + (c.kind === 'home' ? ' disabled title="Min stock applies to payload rows only"' : '') + ' style="width:70px"></td>'
@@ -857,6 +860,65 @@ function applyCalc(value, el) {
 }
 function dismissCalc(el) { closeCalcPop(el.closest('tr')); }
 
+// Forecast: suggest a threshold from the payload's learned demand — the
+// consume ticks per style and shift, with z-score (or Poisson, at low volume)
+// safety stock and the rate's confidence interval. Same contract as Calc:
+// Apply fills the field, Save is deliberate.
+const FORECAST_LEVELS = [0.9, 0.95, 0.99];
+function forecastThr(el) { runForecast(el.closest('tr'), 0.95); }
+function forecastAt(level, el) { runForecast(el.closest('tr'), Number(level)); }
+async function runForecast(tr, level) {
+  try {
+    const d = await apiGet('/api/demand-forecast?payload=' + encodeURIComponent(expanded) + '&service_level=' + level);
+    if (d && d.error) { toast(d.error, 'error'); return; }
+    showForecastPop(tr, d);
+  } catch (e) {
+    toast('Forecast failed: ' + (e.message || e), 'error');
+  }
+}
+function showForecastPop(tr, d) {
+  closeCalcPop(tr);
+  const groups = (d && d.groups) || [];
+  const pct = (v) => Math.round(v * 1000) / 10 + '%';
+  const levels = FORECAST_LEVELS.map((l) => l === d.service_level
+    ? '<b>' + pct(l) + '</b>'
+    : '<button class="btn btn-sm" data-action="forecastAt:' + l + '">' + pct(l) + '</button>').join(' ');
+  const lead = d.lead_seconds > 0
+    ? Math.round(d.lead_seconds / 60) + ' min lead' + (d.lead_observed ? ' (observed)' : '')
+    : 'no lead time observed';
+  let html = 'Forecast at ' + levels + ' service &nbsp;·&nbsp; ' + lead
+    + ' &nbsp;·&nbsp; ' + num(d.sample_hours) + ' h sampled';
+  if (!groups.length) {
+    html += '<div class="text-muted-sm mt-2">No consumption of this payload in the last '
+      + Math.round((new Date(d.until) - new Date(d.since)) / 86400000) + ' days — nothing to learn from.</div>';
+  } else {
+    const rows = groups.map((g) => {
+      const conf = String(g.confidence || '').toLowerCase();
+      return '<tr><td>' + (g.style_id ? 'Style ' + g.style_id : 'Unknown style') + '</td>'
+        + '<td>' + escapeHtml(g.shift || 'All hours') + '</td>'
+        + '<td class="rh-num">' + num(g.hours) + '</td>'
+        + '<td class="rh-num">' + g.mean_per_hour.toFixed(1) + ' &plusmn; ' + g.stddev_per_hour.toFixed(1) + '</td>'
+        + '<td>' + g.method + '</td>'
+        + '<td class="rh-num">' + num(g.safety_stock) + '</td>'
+        + '<td class="rh-num"><b>' + g.threshold + '</b> <span class="text-muted-sm">' + g.low + '–' + g.high + '</span></td>'
+        + '<td class="' + (conf === 'high' ? 'conf-high' : 'conf-low') + '">' + escapeHtml(g.confidence) + '</td>'
+        + '<td><button class="btn btn-sm" data-action="applyCalc:' + g.threshold + '">Apply</button></td></tr>';
+    }).join('');
+    html += '<table class="holding-table mt-2"><thead><tr><th>Style</th><th>Shift</th><th class="rh-num">Hours</th>'
+      + '<th class="rh-num">UoP / h</th><th>Method</th><th class="rh-num">Safety</th><th class="rh-num" title="The threshold at either end of the 95% confidence interval on the demand rate">Threshold (range)</th>'
+      + '<th>Confidence</th><th></th></tr></thead><tbody>' + rows + '</tbody></table>'
+      + '<div class="mt-2">Suggested UoP threshold: <b>' + d.suggested + '</b> — the heaviest style and shift with enough hours to trust.</div>'
+      + '<div class="text-muted-sm mt-2">Learned from ' + escapeHtml((d.stations || []).join(', ') || 'no station')
+      + '. The whole payload\'s demand; a payload split across loaders splits it. Nothing is saved until you Apply, then Save.</div>'
+      + '<button class="btn btn-sm btn-primary mt-2" data-action="applyCalc:' + d.suggested + '">Apply ' + d.suggested + '</button> ';
+  }
+  html += '<button class="btn btn-sm mt-2" data-action="dismissCalc">Dismiss</button>';
+  const pop = document.createElement('div');
+  pop.className = 'calc-pop calc-pop--wide';
+  pop.innerHTML = html;
+  tr.querySelector('.thr-value').closest('td').appendChild(pop);
+}
+
 async function deleteBucket(id) {
   if (!await uiConfirm('Delete this lineside bucket row? This clears a Core-only ghost record.')) return;
   try {
@@ -1003,6 +1065,7 @@ async function showRejectedDeltas() {
 delegateActions(document.body, {
   onSearch, onSearchKey, onFilter, refresh, exportInventory, scrollTo,
   toggleRow, onThrInput, saveThr, discardThr, calcThr, applyCalc, dismissCalc,
+  forecastThr, forecastAt,
   deleteBucket, openDrill, drillRange, showOnMap, showRejectedDeltas,
   'close-modal': closeDrill,
 }, { events: ['click', 'change', 'input', 'keydown'] });
@@ -41,7 +41,8 @@ function load() {
         'rangeProblem: rangeProblem, RANGE_MAX_DAYS: RANGE_MAX_DAYS, ' +
         'VERDICT_TOKEN: VERDICT_TOKEN, VERDICT_STROKE: VERDICT_STROKE, ' +
         'VERDICT_DASH: VERDICT_DASH, ' +
-        'BAND_STROKE: BAND_STROKE, BAND_TOKEN: BAND_TOKEN };', ctx);
+        'BAND_STROKE: BAND_STROKE, BAND_TOKEN: BAND_TOKEN, ' +
+        'fixesHTML: fixesHTML, FIX_EFFORT: FIX_EFFORT };', ctx);
     return ctx.__out;
 }
 
@@ -359,6 +360,24 @@ console.log('verdict marks');
         }), JSON.stringify(m.VERDICT_TOKEN));
 })();
 
+// --- the map fixes -------------------------------------------------------
+//
+// The evidence is server text about a map a plant engineer drew, zone names
+// included, so it is escaped; and no fixes is a sentence, not a blank.
+console.log('fixesHTML');
+(function () {
+    const html = m.fixesHTML({ fixes: [{
+        rank: 1, title: 'Switch zone <08> from ReflectorArea to LocConfigArea', effort: 'map_edit',
+        impact: 280, evidence: ['zone 08 holds no reflectors on the map']
+    }] });
+    check('escapes the title', html.indexOf('&lt;08&gt;') >= 0 && html.indexOf('<08>') < 0, html);
+    check('names the effort in words', html.indexOf('map edit') >= 0, html);
+    check('shows the evidence open', html.indexOf('holds no reflectors') >= 0, html);
+    check('an empty report says so',
+        m.fixesHTML({ fixes: [] }).indexOf('No persistent hotspots') >= 0 &&
+        m.fixesHTML(null).indexOf('No persistent hotspots') >= 0);
+})();
+
 if (failures) {
     console.error('\n' + failures + ' check(s) failed');
     process.exit(1);
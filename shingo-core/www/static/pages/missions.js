@@ -611,11 +611,70 @@ async function loadFilterOptions() {
     } catch (e) { /* non-fatal */ }
 }
 
+// ─── analytics export ───────────────────────────────────────────────────
+// The panel is built from /api/analytics-export, so a dataset added to the
+// contract shows up here without a page change. The download is a plain
+// navigation: the zip streams, and the browser's own download UI is the
+// progress bar.
+let exportInfo = null;
+
+function utcDay(d) { return d.toISOString().slice(0, 10); }
+
+function initExport() {
+    const from = document.getElementById('m-ax-from');
+    const to = document.getElementById('m-ax-to');
+    const btn = document.getElementById('m-ax-download');
+    if (!from || !to || !btn) return;
+    // Default: the last seven closed days.
+    const yesterday = new Date(Date.now() - 86400000);
+    to.value = utcDay(yesterday);
+    from.value = utcDay(new Date(yesterday.getTime() - 6 * 86400000));
+    btn.addEventListener('click', downloadExport);
+
+    apiGet('/api/analytics-export').then((info) => {
+        exportInfo = info;
+        const box = document.getElementById('m-ax-datasets');
+        if (box) {
+            box.innerHTML = (info.datasets || []).map((d) =>
+                '<label title="' + escapeAttr(d.doc + ' ' + d.columns.length + ' columns.') + '">'
+                + '<input type="checkbox" value="' + escapeAttr(d.name) + '" checked> '
+                + escapeText(d.name) + '</label>').join('');
+        }
+        const sched = document.getElementById('m-ax-schedule');
+        if (sched) {
+            const s = info.schedule || {};
+            sched.textContent = s.enabled
+                ? 'Scheduled daily to ' + (s.storage || 'filesystem') + ', ' + (s.formats || []).join(' + ')
+                  + ', last ' + s.lookback_days + ' days kept complete'
+                : 'Schedule off';
+        }
+    }).catch(() => {});
+}
+
+function downloadExport() {
+    const from = document.getElementById('m-ax-from').value;
+    const to = document.getElementById('m-ax-to').value;
+    if (!from || !to) { toast('Pick a From and To day', 'error'); return; }
+    if (to < from) { toast('To is before From', 'error'); return; }
+    const days = Math.round((Date.parse(to) - Date.parse(from)) / 86400000) + 1;
+    if (exportInfo && days > exportInfo.max_days) {
+        toast('At most ' + exportInfo.max_days + ' days per download', 'error');
+        return;
+    }
+    const sets = [...document.querySelectorAll('#m-ax-datasets input:checked')].map((c) => c.value);
+    if (exportInfo && sets.length === 0) { toast('Pick at least one dataset', 'error'); return; }
+    const p = new URLSearchParams({ from, to, formats: document.getElementById('m-ax-format').value });
+    // Every box ticked is the same as none named; keep the URL short.
+    if (exportInfo && sets.length < (exportInfo.datasets || []).length) p.set('datasets', sets.join(','));
+    window.location.href = '/api/analytics-export/download?' + p.toString();
+}
+
 // ─── boot ───────────────────────────────────────────────────────────────
 function init() {
     installChartThemeHook(); // for the Failure Pareto chart
     initFilterBar();
     loadFilterOptions();
+    initExport();
     updateSysPills(null);
     filters.subscribe(onFilterChange);
 
@@ -0,0 +1,59 @@
+// oee.js — the two OEE inputs: engineered cycle times and scrap reports.
+//
+// The figures themselves are server-rendered for the ?date= in the URL; every
+// write reloads the page so they are recomputed with it.
+
+import { apiPut, apiPost, apiDelete, delegateActions, toast, uiConfirm } from '/static/app.js';
+
+function cycleURL(cell, style) {
+  return '/api/oee/standards/' + encodeURIComponent(cell) + '/' + encodeURIComponent(style);
+}
+
+async function setCycle(ev) {
+  ev.preventDefault();
+  const f = ev.target;
+  const ms = Math.round(Number(f.seconds.value) * 1000);
+  try {
+    await apiPut(cycleURL(f.cell.value.trim(), f.style.value), { cycle_ms: ms });
+    toast('Cycle time set', 'success');
+    window.location.reload();
+  } catch (e) {
+    toast('Set cycle time failed: ' + e, 'error');
+  }
+}
+
+async function deleteCycle(btn) {
+  const tr = btn.closest('tr[data-cell]');
+  if (!tr) return;
+  if (!(await uiConfirm('Remove the cycle time for ' + tr.dataset.cell + ' style ' + tr.dataset.style + '? Performance will not be computed for that style.'))) return;
+  try {
+    await apiDelete(cycleURL(tr.dataset.cell, tr.dataset.style));
+    window.location.reload();
+  } catch (e) {
+    toast('Remove failed: ' + e, 'error');
+  }
+}
+
+async function reportScrap(ev) {
+  ev.preventDefault();
+  const f = ev.target;
+  try {
+    await apiPost('/api/oee/scrap', {
+      cell_id: f.cell.value.trim(),
+      style_id: Number(f.style.value) || 0,
+      qty: Number(f.qty.value),
+      note: f.note.value,
+    });
+    toast('Scrap reported', 'success');
+    window.location.reload();
+  } catch (e) {
+    toast('Report scrap failed: ' + e, 'error');
+  }
+}
+
+document.getElementById('oee-cycle-form')?.addEventListener('submit', setCycle);
+document.getElementById('oee-scrap-form')?.addEventListener('submit', reportScrap);
+
+delegateActions(document.body, {
+  'delete-cycle': (el) => deleteCycle(el),
+});
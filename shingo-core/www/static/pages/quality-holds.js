@@ -0,0 +1,168 @@
+// quality-holds.js — place a hold and walk it through its disposition.
+//
+// Every write goes to /api/quality-holds/…, and the actor is the signed-in
+// user on the server side — nothing here sends a name. The approve button is
+// shown to everyone on purpose: whether THIS user may approve is the server's
+// call (approver list, and not the person who requested it), and a button that
+// hides itself would have to duplicate that rule in the browser.
+
+import { apiGet, apiPost, delegateActions, escapeHtml, hideModal, showModal, toast, uiConfirm, uiPrompt } from '/static/app.js';
+
+const DISPOSITIONS = ['release', 'rework', 'scrap'];
+
+function holdID(el) {
+  const tr = el.closest('tr[data-id]');
+  return tr ? tr.dataset.id : '';
+}
+
+// showScopeFields shows only the inputs the selected scope reads.
+function showScopeFields() {
+  const scope = document.getElementById('qh-scope').value;
+  document.querySelectorAll('#place-modal [data-scope]').forEach((el) => {
+    el.hidden = !el.dataset.scope.split(' ').includes(scope);
+  });
+}
+
+function openPlace() {
+  showScopeFields();
+  showModal('place-modal');
+}
+
+function closeModal(el) {
+  hideModal(el.dataset.modal);
+}
+
+// localToISO turns a datetime-local value (browser local, no zone) into the
+// RFC 3339 instant the API takes.
+function localToISO(v) {
+  if (!v) return undefined;
+  const d = new Date(v);
+  return isNaN(d) ? undefined : d.toISOString();
+}
+
+async function placeHold(btn) {
+  const scope = document.getElementById('qh-scope').value;
+  const body = {
+    reason_code: document.getElementById('qh-reason').value,
+    note: document.getElementById('qh-note').value.trim(),
+    scope: scope,
+  };
+  const payload = document.getElementById('qh-payload').value.trim();
+  if (scope === 'bin') {
+    body.bin_id = parseInt(document.getElementById('qh-bin').value, 10) || 0;
+  } else if (scope === 'lot') {
+    body.lot_code = document.getElementById('qh-lot').value.trim();
+    if (payload) body.payload_code = payload;
+  } else if (scope === 'payload') {
+    body.payload_code = payload;
+  } else if (scope === 'loaded_window') {
+    body.loaded_from = localToISO(document.getElementById('qh-from').value);
+    body.loaded_to = localToISO(document.getElementById('qh-to').value);
+    if (payload) body.payload_code = payload;
+  }
+
+  btn.disabled = true;
+  try {
+    const res = await apiPost('/api/quality-holds', body);
+    toast('QH-' + res.id + ' placed — ' + res.held_bins + ' bin(s) held', 'success');
+    window.location.reload();
+  } catch (e) {
+    toast('Place failed: ' + e, 'error');
+    btn.disabled = false;
+  }
+}
+
+async function requestDisposition(btn) {
+  const id = holdID(btn);
+  const d = await uiPrompt('Disposition for QH-' + id + ' (release, rework or scrap):', { value: 'release' });
+  if (d === null) return;
+  const disposition = d.trim().toLowerCase();
+  if (!DISPOSITIONS.includes(disposition)) {
+    toast('Disposition must be release, rework or scrap', 'error');
+    return;
+  }
+  try {
+    await apiPost('/api/quality-holds/' + id + '/request-disposition', { disposition });
+    toast('Requested ' + disposition + ' — a different person must approve it', 'success');
+    window.location.reload();
+  } catch (e) {
+    toast('Request failed: ' + e, 'error');
+  }
+}
+
+async function approve(btn) {
+  const id = holdID(btn);
+  if (!await uiConfirm('Approve the requested disposition for QH-' + id + '? Held bins are released, flagged or emptied now.')) return;
+  btn.disabled = true;
+  try {
+    const res = await apiPost('/api/quality-holds/' + id + '/approve', {});
+    let msg = 'QH-' + id + ' closed (' + res.disposition + ')';
+    if (res.still_held) msg += ' — ' + res.still_held + ' bin(s) still held by another hold';
+    toast(msg, res.warning ? 'warning' : 'success');
+    if (res.warning) toast(res.warning, 'error');
+    window.location.reload();
+  } catch (e) {
+    toast('Approve failed: ' + e, 'error');
+    btn.disabled = false;
+  }
+}
+
+async function reject(btn) {
+  const id = holdID(btn);
+  const reason = await uiPrompt('Why is the disposition for QH-' + id + ' rejected?');
+  if (reason === null) return;
+  try {
+    await apiPost('/api/quality-holds/' + id + '/reject', { reason: reason.trim() });
+    toast('Disposition rejected; QH-' + id + ' is active again', 'success');
+    window.location.reload();
+  } catch (e) {
+    toast('Reject failed: ' + e, 'error');
+  }
+}
+
+async function showHold(link) {
+  const id = holdID(link);
+  try {
+    const d = await apiGet('/api/quality-holds/' + id);
+    document.getElementById('qh-detail-title').textContent = d.ref + ' — ' + d.scope;
+    let html = '<h3>Bins</h3>';
+    if (!d.bins.length) {
+      html += '<p class="muted">No bin has matched this hold.</p>';
+    } else {
+      html += '<table class="table"><thead><tr><th>Bin</th><th>Payload</th><th>Node</th><th>Was</th><th>Now</th><th>Outcome</th></tr></thead><tbody>';
+      d.bins.forEach((b) => {
+        html += '<tr><td>' + escapeHtml(b.label || String(b.bin_id)) + '</td>' +
+          '<td>' + escapeHtml(b.payload_code || '-') + '</td>' +
+          '<td>' + escapeHtml(b.node_name || '-') + '</td>' +
+          '<td>' + escapeHtml(b.prior_status) + '</td>' +
+          '<td>' + escapeHtml(b.bin_status) + '</td>' +
+          '<td>' + escapeHtml(b.outcome || (b.resolved_at ? '' : 'held')) + '</td></tr>';
+      });
+      html += '</tbody></table>';
+    }
+    html += '<h3>Trail</h3><table class="table"><tbody>';
+    d.events.forEach((e) => {
+      html += '<tr><td>' + escapeHtml(new Date(e.at).toLocaleString()) + '</td>' +
+        '<td>' + escapeHtml(e.action.replace(/_/g, ' ')) + '</td>' +
+        '<td>' + escapeHtml(e.actor) + '</td>' +
+        '<td>' + escapeHtml(e.detail || '') + '</td></tr>';
+    });
+    html += '</tbody></table>';
+    document.getElementById('qh-detail-body').innerHTML = html;
+    showModal('detail-modal');
+  } catch (e) {
+    toast('Could not load QH-' + id + ': ' + e, 'error');
+  }
+}
+
+delegateActions(document.body, {
+  approve: (el) => approve(el),
+  closeModal: (el) => closeModal(el),
+  openPlace: () => openPlace(),
+  placeHold: (el) => placeHold(el),
+  reject: (el) => reject(el),
+  requestDisposition: (el) => requestDisposition(el),
+  showHold: (el, evt) => { evt.preventDefault(); showHold(el); },
+});
+
+document.getElementById('qh-scope').addEventListener('change', showScopeFields);
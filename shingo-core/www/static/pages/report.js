@@ -0,0 +1,8 @@
+// report.js — the print button on a shift report. The page itself is server
+// rendered; printing (or the browser's "Save as PDF") uses the print styles.
+
+import { delegateActions } from '/static/app.js';
+
+delegateActions(document.body, {
+  print: () => window.print(),
+});
@@ -1,4 +1,4 @@
-import { api, debounce, delegateActions, el, hideModal, showModal, uiConfirm } from '/static/app.js';
+import { api, debounce, delegateActions, el, hideModal, showModal, toast, uiConfirm, uiPrompt } from '/static/app.js';
 import { installLiveDurations, reconcileList, onSSE } from '/static/shared/utils.js';
 import { createRobotTile, updateRobotTile } from '/static/components/RobotTile.js';
 import { createBoard } from '/static/components/localization-board.js';
@@ -96,6 +96,7 @@ document.addEventListener('keydown', function(e) {
 // so binding the map across every event type keeps the page wiring
 // single-source.
 delegateActions(document.body, {
+    closeMaintenanceItem,
     closeRobotModal,
     filterRobots,
     openRobotModal,
@@ -177,3 +178,84 @@ if (document.readyState === 'loading') {
 } else {
   bootLocalizationBoard();
 }
+
+
+// ── Maintenance ───────────────────────────────────────────────────────────
+//
+// The indicators for every robot and the open work items. Re-read every five
+// minutes: the record is written at that interval and assessed hourly, so
+// anything faster only re-draws the same numbers.
+var maintenanceIndicators = ['battery_cycles', 'capacity_fade', 'fault_rate', 'service_interval', 'localization'];
+
+function maintenanceBadge(status) {
+  return el('span', { className: 'rmx-st rmx-' + status }, status === 'no_data' ? 'no data' : status);
+}
+
+function maintenanceValue(ind) {
+  if (!ind) return el('span', { className: 'text-muted' }, 'off');
+  var text;
+  if (ind.status === 'no_data') {
+    text = 'no data';
+  } else if (ind.key === 'capacity_fade') {
+    text = (ind.value >= 0 ? '+' : '') + ind.value.toFixed(1) + '%';
+  } else if (ind.key === 'localization') {
+    text = (ind.value >= 0 ? '-' : '+') + Math.abs(ind.value).toFixed(2);
+  } else {
+    text = Math.round(ind.value) + (ind.unit === 'km' ? ' km' : '');
+  }
+  return el('span', { className: 'rmx-st rmx-' + ind.status, title: ind.detail || '' }, text);
+}
+
+function renderMaintenance(root, data) {
+  var canClose = root.dataset.auth === '1';
+  var items = document.getElementById('rmx-items');
+  var body = document.getElementById('rmx-robots');
+  var due = data.robots.filter(function (r) { return r.status === 'due'; }).length;
+  document.getElementById('rmx-summary').textContent = !data.enabled
+    ? 'Sampling is off (robot_maintenance.enabled)'
+    : data.items.length + ' open item' + (data.items.length === 1 ? '' : 's') + ', ' + due + ' robot' + (due === 1 ? '' : 's') + ' due' +
+      (data.hold_due_robots ? ' - due robots are held' : '');
+
+  items.replaceChildren.apply(items, data.items.map(function (it) {
+    return el('div', { className: 'rmx-item' }, [
+      el('strong', null, it.vehicle_id),
+      el('span', null, it.indicator.replace(/_/g, ' ')),
+      it.held ? el('span', { className: 'rmx-st rmx-due' }, 'held') : null,
+      el('span', { className: 'rmx-detail' }, it.detail),
+      el('span', { className: 'text-muted text-sm' }, new Date(it.opened_at).toLocaleString()),
+      canClose ? el('button', { className: 'btn btn-sm btn-primary', dataset: { action: 'closeMaintenanceItem:' + it.id + ':done' } }, 'Done') : null,
+      canClose ? el('button', { className: 'btn btn-sm', dataset: { action: 'closeMaintenanceItem:' + it.id + ':dismissed' } }, 'Dismiss') : null,
+    ]);
+  }));
+
+  body.replaceChildren.apply(body, data.robots.map(function (r) {
+    var byKey = {};
+    r.indicators.forEach(function (ind) { byKey[ind.key] = ind; });
+    return el('tr', null, [el('td', null, r.vehicle_id), el('td', null, maintenanceBadge(r.status))]
+      .concat(maintenanceIndicators.map(function (k) { return el('td', null, maintenanceValue(byKey[k])); })));
+  }));
+}
+
+function loadMaintenance() {
+  var root = document.getElementById('robot-maintenance');
+  if (!root) return;
+  api('GET', '/api/robots/maintenance').then(function (data) {
+    renderMaintenance(root, data);
+  }).catch(function (err) {
+    document.getElementById('rmx-summary').textContent = 'Could not load maintenance: ' + (err && err.message ? err.message : err);
+  });
+}
+
+async function closeMaintenanceItem(id, status) {
+  var note = await uiPrompt(status === 'done' ? 'What was done?' : 'Why dismiss it?');
+  if (note === null) return;
+  api('POST', '/api/robots/maintenance/' + id + '/close', { status: status, note: note }).then(function (res) {
+    toast(res.released ? res.item.vehicle_id + ' is available again' : 'Item closed', 'success');
+    loadMaintenance();
+  }).catch(function (err) {
+    toast(err && err.message ? err.message : String(err), 'error');
+  });
+}
+
+loadMaintenance();
+setInterval(loadMaintenance, 5 * 60 * 1000);
@@ -0,0 +1,56 @@
+// starvation.js — the Pareto on /starvation: starved minutes by cause for the
+// chosen week, for the plant or for the cell whose row was clicked.
+//
+// The table is server-rendered; this only draws the chart, from the same
+// weeks /api/starvation computes.
+
+import { apiGet, delegateActions } from '/static/app.js';
+import { makeChart, chartColors } from '/static/components/charts.js';
+
+const card = document.getElementById('starvation-pareto');
+let week = null;
+let chart = null;
+
+function render(cellID) {
+  const box = card.querySelector('.chart-box');
+  if (chart) { try { chart.destroy(); } catch (_) {} chart = null; }
+  const cell = cellID ? (week.cells || []).find((c) => c.cell_id === cellID) : week.plant;
+  document.getElementById('starvation-pareto-cell').textContent = cellID || 'Plant';
+  const bars = (cell && cell.pareto) || [];
+  if (!bars.length) { box.innerHTML = '<div class="dash-empty">No starved minutes in this week.</div>'; return; }
+  box.innerHTML = '<canvas></canvas>';
+  const c = chartColors();
+  chart = makeChart(box.querySelector('canvas'), {
+    type: 'bar',
+    data: {
+      labels: bars.map((b) => b.label),
+      datasets: [
+        { type: 'bar', label: 'Minutes', data: bars.map((b) => Math.round(b.minutes)), backgroundColor: c.info, yAxisID: 'y', order: 2 },
+        { type: 'line', label: 'Cumulative %', data: bars.map((b) => Math.round(b.cumulative * 1000) / 10), borderColor: c.warning, backgroundColor: c.warning, yAxisID: 'y1', tension: 0.2, pointRadius: 2, order: 1 },
+      ],
+    },
+    options: {
+      scales: {
+        y: { min: 0, ticks: { precision: 0 } },
+        y1: { position: 'right', min: 0, max: 100, grid: { drawOnChartArea: false }, ticks: { callback: (v) => v + '%' } },
+      },
+      plugins: { legend: { display: true, labels: { color: c.text, boxWidth: 12 } } },
+    },
+  });
+}
+
+if (card) {
+  apiGet('/api/starvation?weeks=' + encodeURIComponent(card.dataset.weeks))
+    .then((weeks) => {
+      week = (weeks || []).find((w) => w.week === card.dataset.week);
+      if (!week) throw new Error('week not returned');
+      render('');
+    })
+    .catch(() => {
+      card.querySelector('.chart-box').innerHTML = '<div class="dash-empty">Starvation data unavailable.</div>';
+    });
+
+  delegateActions(document.body, {
+    'select-cell': (el) => { if (week) render(el.dataset.cell); },
+  });
+}
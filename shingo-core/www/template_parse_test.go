@@ -15,8 +15,8 @@ import (
 // startup. The handler-test fixtures use an empty tmpls map, so without this
 // nothing exercises the real parse pipeline.
 //
-// It also smoke-executes the two chromeless dashboard templates, each by its
-// own file name via the renderBare path. This catches a bad field reference or
+// It also smoke-executes the chromeless dashboard templates, each by its own
+// file name via the renderBare path. This catches a bad field reference or
 // template function the parse step alone would not. Pages that render through
 // the shared "layout" are executed by phase6_pages_render_test.go's renderPage,
 // which is the closer mirror of what router.go does.
@@ -67,4 +67,23 @@ func TestTemplatesParse(t *testing.T) {
 	if !strings.Contains(buf.String(), "Plant Map") || !strings.Contains(buf.String(), `data-dashboard-kind="robot-map"`) {
 		t.Errorf("map output missing baked-in config; got:\n%s", buf.String())
 	}
+
+	// Every registered kind's kiosk, by the dashboardTemplates map itself, so
+	// a kind registered against a template that does not exist fails here.
+	for kind, name := range dashboardTemplates {
+		tm, ok := tmpls[name]
+		if !ok {
+			t.Errorf("%s: template %s not parsed", kind, name)
+			continue
+		}
+		buf.Reset()
+		dk := &dashboards.Dashboard{ID: 11, Name: "Kind Board", Kind: kind}
+		if err := tm.ExecuteTemplate(&buf, name, map[string]any{"Dashboard": dk}); err != nil {
+			t.Errorf("execute %s: %v", name, err)
+			continue
+		}
+		if !strings.Contains(buf.String(), `data-dashboard-id="11"`) {
+			t.Errorf("%s: output missing the dashboard id", name)
+		}
+	}
 }
@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"shingoedge/config"
+)
+
+// Archive keys are ed25519 keys in SSH format, so a plant makes them with
+// ssh-keygen and pastes public halves as authorized_keys lines, as it does
+// for the SFTP host key. Encryption to a public key converts it to X25519,
+// the same birational map age uses for ssh-ed25519 recipients.
+
+// signingKeyPath is where the edge's signing key lives.
+func signingKeyPath(configPath string, cfg config.BackupSigningConfig) string {
+	if p := strings.TrimSpace(cfg.KeyFile); p != "" {
+		return p
+	}
+	return filepath.Join(stateDir(configPath), "signing_key")
+}
+
+// loadSigningKey reads the edge's signing key. The default key is generated
+// on first use; a configured KeyFile that is missing is an error, since it
+// names a key the plant provisioned.
+func loadSigningKey(configPath string, cfg config.BackupSigningConfig) (ed25519.PrivateKey, error) {
+	path := signingKeyPath(configPath, cfg)
+	key, err := readEd25519PrivateKey(path)
+	if err == nil || !errors.Is(err, os.ErrNotExist) || strings.TrimSpace(cfg.KeyFile) != "" {
+		if err != nil {
+			return nil, fmt.Errorf("backup signing key: %w", err)
+		}
+		return key, nil
+	}
+	_, key, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate backup signing key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "shingo edge backup signing")
+	if err != nil {
+		return nil, fmt.Errorf("encode backup signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	if err := atomicWrite(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return nil, fmt.Errorf("write backup signing key: %w", err)
+	}
+	return key, nil
+}
+
+// SigningPublicKey returns the authorized_keys line for the edge's signing
+// key, or "" before the first backup has generated it. This is the line to
+// record for trusted_keys on the edge that will restore.
+func SigningPublicKey(configPath string, cfg config.BackupSigningConfig) string {
+	key, err := readEd25519PrivateKey(signingKeyPath(configPath, cfg))
+	if err != nil {
+		return ""
+	}
+	return authorizedKey(key.Public().(ed25519.PublicKey))
+}
+
+func authorizedKey(pub ed25519.PublicKey) string {
+	sshKey, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey)))
+}
+
+func readEd25519PrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ssh.ParseRawPrivateKey(data)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("%s is passphrase protected; archive keys must be stored without one", path)
+		}
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	switch k := raw.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ed25519.PrivateKey:
+		return *k, nil
+	}
+	return nil, fmt.Errorf("%s is not an ed25519 key", path)
+}
+
+func parseEd25519PublicKey(line string) (ed25519.PublicKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(line)))
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	cpk, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is %s, want ssh-ed25519", key.Type())
+	}
+	pub, ok := cpk.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is %s, want ssh-ed25519", key.Type())
+	}
+	return pub, nil
+}
+
+// trustPolicy decides whose signatures restore accepts.
+type trustPolicy struct {
+	keys          []ed25519.PublicKey
+	fingerprints  []string
+	allowUnsigned bool
+}
+
+// newTrustPolicy trusts the edge's own signing key, when it has one, and the
+// configured trusted keys.
+func newTrustPolicy(configPath string, cfg config.BackupSigningConfig) (*trustPolicy, error) {
+	tp := &trustPolicy{allowUnsigned: cfg.AllowUnsignedRestore}
+	if own, err := readEd25519PrivateKey(signingKeyPath(configPath, cfg)); err == nil {
+		tp.keys = append(tp.keys, own.Public().(ed25519.PublicKey))
+	}
+	for _, line := range cfg.TrustedKeys {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "SHA256:"):
+			tp.fingerprints = append(tp.fingerprints, line)
+		default:
+			pub, err := parseEd25519PublicKey(line)
+			if err != nil {
+				return nil, fmt.Errorf("trusted signing key: %w", err)
+			}
+			tp.keys = append(tp.keys, pub)
+		}
+	}
+	return tp, nil
+}
+
+func (tp *trustPolicy) trusts(pub ed25519.PublicKey) bool {
+	for _, k := range tp.keys {
+		if k.Equal(pub) {
+			return true
+		}
+	}
+	sshKey, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(tp.fingerprints, ssh.FingerprintSHA256(sshKey))
+}
+
+// sealingKey returns the key new archives are sealed to: the first one
+// configured.
+func sealingKey(cfg config.BackupEncryptionConfig) (config.BackupKeyConfig, error) {
+	if len(cfg.Keys) == 0 {
+		return config.BackupKeyConfig{}, fmt.Errorf("backup encryption is enabled but no keys are configured")
+	}
+	key := cfg.Keys[0]
+	if strings.TrimSpace(key.ID) == "" {
+		return key, fmt.Errorf("backup encryption key needs an id")
+	}
+	if key.Passphrase == "" && strings.TrimSpace(key.PublicKey) == "" {
+		return key, fmt.Errorf("backup encryption key %q needs a passphrase or a public key", key.ID)
+	}
+	return key, nil
+}
+
+// x25519Public converts an ed25519 public key to its X25519 form:
+// u = (1+y)/(1-y) mod 2^255-19.
+func x25519Public(pub ed25519.PublicKey) (*ecdh.PublicKey, error) {
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	le := slices.Clone([]byte(pub))
+	le[31] &= 0x7f
+	slices.Reverse(le)
+	y := new(big.Int).SetBytes(le)
+	den := new(big.Int).Sub(big.NewInt(1), y)
+	den.Mod(den, p)
+	if den.Sign() == 0 {
+		return nil, fmt.Errorf("public key has no X25519 form")
+	}
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, den.ModInverse(den, p))
+	u.Mod(u, p)
+	out := u.FillBytes(make([]byte, 32))
+	slices.Reverse(out)
+	return ecdh.X25519().NewPublicKey(out)
+}
+
+// x25519Private converts an ed25519 private key to the X25519 key whose
+// public half x25519Public gives: the clamped scalar is the first half of
+// SHA-512 of the seed, as ed25519 itself derives it.
+func x25519Private(priv ed25519.PrivateKey) (*ecdh.PrivateKey, error) {
+	h := sha512.Sum512(priv.Seed())
+	return ecdh.X25519().NewPrivateKey(h[:32])
+}
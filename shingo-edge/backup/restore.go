@@ -15,6 +15,9 @@ import (
 	"shingoedge/config"
 )
 
+// ApplyPendingRestore applies a staged restore, opening the archive with
+// the keys and trust in the current config. The override recorded when the
+// restore was staged carries over.
 func ApplyPendingRestore(configPath string, logf func(string, ...any)) error {
 	marker, err := loadRestoreMarker(configPath)
 	if err != nil {
@@ -26,6 +29,11 @@ func ApplyPendingRestore(configPath string, logf func(string, ...any)) error {
 	if logf != nil {
 		logf("backup: applying staged restore from %s", marker.Key)
 	}
+	backupCfg, err := currentBackupConfig(configPath)
+	if err != nil {
+		return err
+	}
+	backupCfg.Signing.AllowUnsignedRestore = backupCfg.Signing.AllowUnsignedRestore || marker.AllowUnsigned
 
 	tmpDir, err := os.MkdirTemp("", "shingoedge-restore-*")
 	if err != nil {
@@ -33,9 +41,13 @@ func ApplyPendingRestore(configPath string, logf func(string, ...any)) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	if err := restoreArchivePath(configPath, marker.Archive, marker.StationID); err != nil {
+	opened, err := restoreArchivePath(configPath, marker.Archive, marker.StationID, backupCfg)
+	if err != nil {
 		return err
 	}
+	if opened.Unverified != "" && logf != nil {
+		logf("backup: restored an unverified archive by override: %s", opened.Unverified)
+	}
 
 	if err := clearRestoreMarker(configPath); err != nil {
 		return err
@@ -46,7 +58,10 @@ func ApplyPendingRestore(configPath string, logf func(string, ...any)) error {
 	return nil
 }
 
-func StageRestoreArchive(configPath, key string, archive io.Reader, stationID string) error {
+// StageRestoreArchive stores the archive as it came from storage, still
+// sealed, to be opened and applied on the next start. allowUnsigned records
+// an operator's override of the signature check.
+func StageRestoreArchive(configPath, key string, archive io.Reader, stationID string, allowUnsigned bool) error {
 	stateDir := stateDir(configPath)
 	if err := os.MkdirAll(stateDir, 0o755); err != nil {
 		return fmt.Errorf("mkdir state dir: %w", err)
@@ -71,10 +86,11 @@ func StageRestoreArchive(configPath, key string, archive io.Reader, stationID st
 		return fmt.Errorf("activate staged restore archive: %w", err)
 	}
 	marker := RestoreMarker{
-		Key:       key,
-		StagedAt:  time.Now().UTC(),
-		Archive:   archivePath,
-		StationID: stationID,
+		Key:           key,
+		StagedAt:      time.Now().UTC(),
+		Archive:       archivePath,
+		StationID:     stationID,
+		AllowUnsigned: allowUnsigned,
 	}
 	data, err := json.MarshalIndent(marker, "", "  ")
 	if err != nil {
@@ -90,14 +106,16 @@ func PendingRestore(configPath string) (*RestoreMarker, error) {
 	return loadRestoreMarker(configPath)
 }
 
-func RestoreArchiveNow(configPath string, archive io.Reader, expectedStationID string) error {
+// RestoreArchiveNow opens the archive with backupCfg's keys and trust, and
+// restores it over the config and database at once.
+func RestoreArchiveNow(configPath string, backupCfg config.BackupConfig, archive io.Reader, expectedStationID string) error {
 	tmpDir, err := os.MkdirTemp("", "shingoedge-restore-direct-*")
 	if err != nil {
 		return fmt.Errorf("create direct restore temp dir: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	archivePath := filepath.Join(tmpDir, "restore.tar.gz")
+	archivePath := filepath.Join(tmpDir, "restore.archive")
 	f, err := os.Create(archivePath)
 	if err != nil {
 		return fmt.Errorf("create direct restore archive: %w", err)
@@ -109,7 +127,8 @@ func RestoreArchiveNow(configPath string, archive io.Reader, expectedStationID s
 	if err := f.Close(); err != nil {
 		return fmt.Errorf("close direct restore archive: %w", err)
 	}
-	return restoreArchivePath(configPath, archivePath, expectedStationID)
+	_, err = restoreArchivePath(configPath, archivePath, expectedStationID, backupCfg)
+	return err
 }
 
 func clearRestoreMarker(configPath string) error {
@@ -139,43 +158,92 @@ func loadRestoreMarker(configPath string) (*RestoreMarker, error) {
 	return &marker, nil
 }
 
-func restoreArchivePath(configPath, archivePath, expectedStationID string) error {
+func restoreArchivePath(configPath, archivePath, expectedStationID string, backupCfg config.BackupConfig) (*openResult, error) {
 	tmpDir, err := os.MkdirTemp("", "shingoedge-restore-*")
 	if err != nil {
-		return fmt.Errorf("create restore temp dir: %w", err)
+		return nil, fmt.Errorf("create restore temp dir: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	manifest, files, err := extractArchive(archivePath, tmpDir)
+	plainPath := filepath.Join(tmpDir, "opened.tar.gz")
+	opened, err := openArchiveFile(configPath, archivePath, plainPath, backupCfg)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	manifest, files, err := extractArchive(plainPath, tmpDir)
+	if err != nil {
+		return nil, err
 	}
 	if err := verifyManifest(manifest, files); err != nil {
-		return err
+		return nil, err
 	}
 	if expectedStationID != "" && manifest.StationID != expectedStationID {
-		return fmt.Errorf("backup station ID %q does not match expected station %q", manifest.StationID, expectedStationID)
+		return nil, fmt.Errorf("backup station ID %q does not match expected station %q", manifest.StationID, expectedStationID)
 	}
 
 	cfgData, err := os.ReadFile(files[ConfigEntryName])
 	if err != nil {
-		return fmt.Errorf("read restored config: %w", err)
+		return nil, fmt.Errorf("read restored config: %w", err)
 	}
 	restoredCfg := config.Defaults()
 	if err := yaml.Unmarshal(cfgData, restoredCfg); err != nil {
-		return fmt.Errorf("parse restored config: %w", err)
+		return nil, fmt.Errorf("parse restored config: %w", err)
 	}
 	dbData, err := os.ReadFile(files[DBEntryName])
 	if err != nil {
-		return fmt.Errorf("read restored db: %w", err)
+		return nil, fmt.Errorf("read restored db: %w", err)
 	}
 	if err := atomicWrite(configPath, cfgData, 0o644); err != nil {
-		return fmt.Errorf("write restored config: %w", err)
+		return nil, fmt.Errorf("write restored config: %w", err)
 	}
 	if err := atomicWrite(restoredCfg.DatabasePath, dbData, 0o644); err != nil {
-		return fmt.Errorf("write restored db: %w", err)
+		return nil, fmt.Errorf("write restored db: %w", err)
 	}
-	return nil
+	return opened, nil
+}
+
+// openArchiveFile verifies and decrypts the archive at src into a plain
+// tar.gz at dst.
+func openArchiveFile(configPath, src, dst string, backupCfg config.BackupConfig) (*openResult, error) {
+	tp, err := newTrustPolicy(configPath, backupCfg.Signing)
+	if err != nil {
+		return nil, err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("create opened archive: %w", err)
+	}
+	opened, err := openArchive(in, out, backupCfg.Encryption.Keys, tp)
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("close opened archive: %w", cerr)
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+		return nil, err
+	}
+	return opened, nil
+}
+
+// currentBackupConfig reads the backup section of the config at configPath,
+// or the defaults when there is no config yet.
+func currentBackupConfig(configPath string) (config.BackupConfig, error) {
+	cfg := config.Defaults()
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg.Backup, nil
+		}
+		return cfg.Backup, fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return cfg.Backup, fmt.Errorf("parse config: %w", err)
+	}
+	return cfg.Backup, nil
 }
 
 func extractArchive(path, destDir string) (*Manifest, map[string]string, error) {
@@ -17,7 +17,7 @@ func TestStageAndLoadRestoreMarker(t *testing.T) {
 	}
 
 	archive := []byte("archive-bytes")
-	testutil.MustNoErr(t, StageRestoreArchive(configPath, "station/backup.tar.gz", bytesReader(archive), "station-1"), "stage restore archive")
+	testutil.MustNoErr(t, StageRestoreArchive(configPath, "station/backup.tar.gz", bytesReader(archive), "station-1", false), "stage restore archive")
 
 	marker, err := PendingRestore(configPath)
 	if err != nil {
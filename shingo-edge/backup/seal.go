@@ -0,0 +1,465 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+
+	"shingoedge/config"
+)
+
+// A sealed archive wraps the tar.gz snapshot:
+//
+//	magic line
+//	uint32 header length, header JSON
+//	frames: flags byte, uint32 length, payload — until a frame flagged last
+//	ed25519 signature over SHA-256 of everything above
+//
+// Encrypted payloads are AES-256-GCM chunks under a random data key; the
+// header carries that key wrapped for the archive key. Each chunk's nonce is
+// its index plus the last flag, and the header digest is the additional data,
+// so chunks cannot be dropped, reordered or moved to another archive. Archives
+// with encryption off are framed the same way in the clear, and still signed.
+const (
+	sealMagic       = "SHINGO-EDGE-BACKUP-SEALED-1\n"
+	sealedSuffix    = ".sealed" // appended to the object key of sealed archives
+	sealChunkSize   = 64 << 10
+	sealMaxHeader   = 1 << 20
+	sealFrameLast   = 1
+	cipherNone      = "none"
+	cipherAESGCM    = "aes-256-gcm"
+	recipientPass   = "scrypt"
+	recipientX25519 = "x25519"
+	// scryptLogN is 2^15 iterations, about 100ms on edge hardware; archives
+	// record their own, and restore refuses more than scryptMaxLogN.
+	scryptLogN    = 15
+	scryptMaxLogN = 20
+)
+
+// ErrUnverified marks an archive whose signature does not prove it came from
+// a trusted edge: unsigned, signed by an unknown key, or failing
+// verification. Restore refuses these unless overridden.
+var ErrUnverified = errors.New("backup archive signature not verified")
+
+type sealHeader struct {
+	StationID  string          `json:"station_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Cipher     string          `json:"cipher"`
+	Recipients []sealRecipient `json:"recipients,omitempty"`
+	Signer     string          `json:"signer"` // authorized_keys line
+}
+
+// sealRecipient wraps the data key for one archive key.
+type sealRecipient struct {
+	KeyID     string `json:"key_id"`
+	Type      string `json:"type"`
+	Salt      []byte `json:"salt,omitempty"`
+	LogN      int    `json:"log_n,omitempty"`
+	Ephemeral []byte `json:"ephemeral,omitempty"`
+	Wrapped   []byte `json:"wrapped"`
+}
+
+// openResult describes an archive openArchive accepted.
+type openResult struct {
+	Sealed    bool
+	Encrypted bool
+	KeyID     string
+	Signer    string
+	// Unverified says why the signature did not check out, when the archive
+	// was let through by the override.
+	Unverified string
+}
+
+// sealArchive writes src to dst as a sealed archive, encrypted to the first
+// configured key when encryption is enabled, and signed with signer.
+func sealArchive(src io.Reader, dst io.Writer, hdr sealHeader, enc config.BackupEncryptionConfig, signer ed25519.PrivateKey) error {
+	hdr.Cipher = cipherNone
+	hdr.Signer = authorizedKey(signer.Public().(ed25519.PublicKey))
+	var aead cipher.AEAD
+	if enc.Enabled {
+		key, err := sealingKey(enc)
+		if err != nil {
+			return err
+		}
+		dataKey := make([]byte, 32)
+		if _, err := rand.Read(dataKey); err != nil {
+			return fmt.Errorf("generate data key: %w", err)
+		}
+		recipient, err := wrapDataKey(key, dataKey)
+		if err != nil {
+			return err
+		}
+		hdr.Cipher = cipherAESGCM
+		hdr.Recipients = []sealRecipient{recipient}
+		if aead, err = newGCM(dataKey); err != nil {
+			return err
+		}
+	}
+	hdrBytes, err := json.Marshal(hdr)
+	if err != nil {
+		return fmt.Errorf("marshal seal header: %w", err)
+	}
+
+	digest := sha256.New()
+	w := io.MultiWriter(dst, digest)
+	if _, err := io.WriteString(w, sealMagic); err != nil {
+		return fmt.Errorf("write sealed archive: %w", err)
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(hdrBytes))); err != nil {
+		return fmt.Errorf("write sealed archive: %w", err)
+	}
+	if _, err := w.Write(hdrBytes); err != nil {
+		return fmt.Errorf("write sealed archive: %w", err)
+	}
+	aad := digest.Sum(nil)
+
+	br := bufio.NewReaderSize(src, sealChunkSize)
+	buf := make([]byte, sealChunkSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		last := err != nil
+		if !last {
+			_, peekErr := br.Peek(1)
+			last = peekErr == io.EOF
+		}
+		payload := buf[:n]
+		if aead != nil {
+			payload = aead.Seal(nil, chunkNonce(index, last), payload, aad)
+		}
+		if err := writeFrame(w, payload, last); err != nil {
+			return err
+		}
+		if last {
+			break
+		}
+	}
+	if _, err := dst.Write(ed25519.Sign(signer, digest.Sum(nil))); err != nil {
+		return fmt.Errorf("write signature: %w", err)
+	}
+	return nil
+}
+
+func writeFrame(w io.Writer, payload []byte, last bool) error {
+	var head [5]byte
+	if last {
+		head[0] = sealFrameLast
+	}
+	binary.BigEndian.PutUint32(head[1:], uint32(len(payload)))
+	if _, err := w.Write(head[:]); err != nil {
+		return fmt.Errorf("write sealed archive: %w", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return fmt.Errorf("write sealed archive: %w", err)
+	}
+	return nil
+}
+
+// openArchive verifies and decrypts src into dst, trying every configured
+// key. An archive without the seal — one written before sealing existed — is
+// copied through only when the trust policy allows unsigned archives. dst
+// receives data before the signature is checked; on error the caller must
+// discard it.
+func openArchive(src io.Reader, dst io.Writer, keys []config.BackupKeyConfig, tp *trustPolicy) (*openResult, error) {
+	br := bufio.NewReaderSize(src, sealChunkSize)
+	if magic, _ := br.Peek(len(sealMagic)); string(magic) != sealMagic {
+		if !tp.allowUnsigned {
+			return nil, fmt.Errorf("%w: archive is unsigned", ErrUnverified)
+		}
+		if _, err := io.Copy(dst, br); err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		return &openResult{Unverified: "archive is unsigned"}, nil
+	}
+
+	digest := sha256.New()
+	r := io.TeeReader(br, digest)
+	hdr, err := readSealHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	res := &openResult{Sealed: true, Signer: hdr.Signer}
+	aad := digest.Sum(nil)
+
+	var aead cipher.AEAD
+	switch hdr.Cipher {
+	case cipherNone:
+	case cipherAESGCM:
+		dataKey, keyID, err := unwrapDataKey(hdr.Recipients, keys)
+		if err != nil {
+			return nil, err
+		}
+		res.Encrypted, res.KeyID = true, keyID
+		if aead, err = newGCM(dataKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("archive uses unknown cipher %q", hdr.Cipher)
+	}
+	if err := copyFrames(r, dst, aead, aad); err != nil {
+		return nil, err
+	}
+
+	why := verifySeal(br, digest, hdr.Signer, tp)
+	if why != "" {
+		if !tp.allowUnsigned {
+			return nil, fmt.Errorf("%w: %s", ErrUnverified, why)
+		}
+		res.Unverified = why
+	}
+	return res, nil
+}
+
+func readSealHeader(r io.Reader) (*sealHeader, error) {
+	if _, err := io.ReadFull(r, make([]byte, len(sealMagic))); err != nil {
+		return nil, fmt.Errorf("read seal header: %w", err)
+	}
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, fmt.Errorf("read seal header: %w", err)
+	}
+	if size > sealMaxHeader {
+		return nil, fmt.Errorf("seal header is %d bytes; archive is damaged", size)
+	}
+	hdrBytes := make([]byte, size)
+	if _, err := io.ReadFull(r, hdrBytes); err != nil {
+		return nil, fmt.Errorf("read seal header: %w", err)
+	}
+	var hdr sealHeader
+	if err := json.Unmarshal(hdrBytes, &hdr); err != nil {
+		return nil, fmt.Errorf("parse seal header: %w", err)
+	}
+	return &hdr, nil
+}
+
+// copyFrames writes frame payloads to dst, decrypting them when aead is set,
+// until the frame flagged last.
+func copyFrames(r io.Reader, dst io.Writer, aead cipher.AEAD, aad []byte) error {
+	limit := sealChunkSize
+	if aead != nil {
+		limit += aead.Overhead()
+	}
+	buf := make([]byte, limit)
+	for index := uint64(0); ; index++ {
+		var head [5]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			return fmt.Errorf("read sealed archive: archive is truncated: %w", err)
+		}
+		last := head[0] == sealFrameLast
+		size := int(binary.BigEndian.Uint32(head[1:]))
+		if size > limit {
+			return fmt.Errorf("read sealed archive: frame of %d bytes; archive is damaged", size)
+		}
+		payload := buf[:size]
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return fmt.Errorf("read sealed archive: archive is truncated: %w", err)
+		}
+		if aead != nil {
+			var err error
+			payload, err = aead.Open(payload[:0], chunkNonce(index, last), payload, aad)
+			if err != nil {
+				return fmt.Errorf("decrypt archive: archive is damaged or has been tampered with")
+			}
+		}
+		if _, err := dst.Write(payload); err != nil {
+			return fmt.Errorf("write opened archive: %w", err)
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// verifySeal checks the trailing signature over digest and the signer's
+// trust, returning why it fails or "".
+func verifySeal(r io.Reader, digest hash.Hash, signer string, tp *trustPolicy) string {
+	sig := make([]byte, ed25519.SignatureSize)
+	if _, err := io.ReadFull(r, sig); err != nil {
+		return "signature is missing"
+	}
+	if n, _ := r.Read(make([]byte, 1)); n != 0 {
+		return "archive has data after its signature"
+	}
+	pub, err := parseEd25519PublicKey(signer)
+	if err != nil {
+		return "signer key is unreadable"
+	}
+	if !ed25519.Verify(pub, digest.Sum(nil), sig) {
+		return "signature does not match; archive is damaged or has been tampered with"
+	}
+	if !tp.trusts(pub) {
+		return fmt.Sprintf("signed by %s, which is not a trusted signing key", fingerprint(signer))
+	}
+	return ""
+}
+
+func fingerprint(authorizedKeyLine string) string {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKeyLine))
+	if err != nil {
+		return "an unreadable key"
+	}
+	return ssh.FingerprintSHA256(key)
+}
+
+func wrapDataKey(key config.BackupKeyConfig, dataKey []byte) (sealRecipient, error) {
+	r := sealRecipient{KeyID: key.ID}
+	var kek []byte
+	if key.Passphrase != "" {
+		r.Type, r.LogN = recipientPass, scryptLogN
+		r.Salt = make([]byte, 16)
+		if _, err := rand.Read(r.Salt); err != nil {
+			return r, fmt.Errorf("generate salt: %w", err)
+		}
+		var err error
+		if kek, err = scrypt.Key([]byte(key.Passphrase), r.Salt, 1<<r.LogN, 8, 1, 32); err != nil {
+			return r, fmt.Errorf("derive key %q: %w", key.ID, err)
+		}
+	} else {
+		pub, err := parseEd25519PublicKey(key.PublicKey)
+		if err != nil {
+			return r, fmt.Errorf("backup encryption key %q: %w", key.ID, err)
+		}
+		recipient, err := x25519Public(pub)
+		if err != nil {
+			return r, fmt.Errorf("backup encryption key %q: %w", key.ID, err)
+		}
+		ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return r, fmt.Errorf("generate ephemeral key: %w", err)
+		}
+		r.Type, r.Ephemeral = recipientX25519, ephemeral.PublicKey().Bytes()
+		if kek, err = x25519KEK(ephemeral, recipient, r.Ephemeral); err != nil {
+			return r, err
+		}
+	}
+	aead, err := newGCM(kek)
+	if err != nil {
+		return r, err
+	}
+	// Each key-encryption key wraps exactly one data key, so a fixed nonce
+	// is safe.
+	r.Wrapped = aead.Seal(nil, make([]byte, aead.NonceSize()), dataKey, []byte(r.KeyID))
+	return r, nil
+}
+
+// unwrapDataKey finds a configured key that opens one of the recipients,
+// trying keys whose id matches the recipient first.
+func unwrapDataKey(recipients []sealRecipient, keys []config.BackupKeyConfig) ([]byte, string, error) {
+	ids := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		ids = append(ids, r.KeyID)
+		candidates := slices.Clone(keys)
+		slices.SortStableFunc(candidates, func(a, b config.BackupKeyConfig) int {
+			return boolRank(a.ID != r.KeyID) - boolRank(b.ID != r.KeyID)
+		})
+		for _, key := range candidates {
+			if dataKey := tryUnwrap(r, key); dataKey != nil {
+				return dataKey, r.KeyID, nil
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("archive is encrypted to key %s; no configured key opens it", strings.Join(quoteAll(ids), ", "))
+}
+
+func tryUnwrap(r sealRecipient, key config.BackupKeyConfig) []byte {
+	var kek []byte
+	switch {
+	case r.Type == recipientPass && key.Passphrase != "":
+		if r.LogN <= 0 || r.LogN > scryptMaxLogN {
+			return nil
+		}
+		var err error
+		if kek, err = scrypt.Key([]byte(key.Passphrase), r.Salt, 1<<r.LogN, 8, 1, 32); err != nil {
+			return nil
+		}
+	case r.Type == recipientX25519 && strings.TrimSpace(key.PrivateKeyFile) != "":
+		priv, err := readEd25519PrivateKey(key.PrivateKeyFile)
+		if err != nil {
+			return nil
+		}
+		xpriv, err := x25519Private(priv)
+		if err != nil {
+			return nil
+		}
+		ephemeral, err := ecdh.X25519().NewPublicKey(r.Ephemeral)
+		if err != nil {
+			return nil
+		}
+		if kek, err = x25519KEK(xpriv, ephemeral, r.Ephemeral); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil
+	}
+	dataKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), r.Wrapped, []byte(r.KeyID))
+	if err != nil {
+		return nil
+	}
+	return dataKey
+}
+
+// x25519KEK derives the key-encryption key from the shared secret between
+// priv and peer, salted with the ephemeral public key.
+func x25519KEK(priv *ecdh.PrivateKey, peer *ecdh.PublicKey, ephemeral []byte) ([]byte, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+	return hkdf.Key(sha256.New, shared, ephemeral, "shingo edge backup x25519", 32)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce is the chunk index, big-endian, followed by the last flag.
+func chunkNonce(index uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func quoteAll(items []string) []string {
+	out := make([]string, len(items))
+	for i, s := range items {
+		out[i] = fmt.Sprintf("%q", s)
+	}
+	return out
+}
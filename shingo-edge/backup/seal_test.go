@@ -0,0 +1,276 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"shingo/protocol/testutil"
+	"shingoedge/config"
+)
+
+// testKeyPair writes an ed25519 private key file in SSH format and returns
+// its path and authorized_keys line.
+func testKeyPair(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	testutil.MustNoErr(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600), "write key")
+	return path, authorizedKey(pub)
+}
+
+func seal(t *testing.T, plain []byte, enc config.BackupEncryptionConfig, signer ed25519.PrivateKey) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	hdr := sealHeader{StationID: "line-1", CreatedAt: time.Now().UTC()}
+	testutil.MustNoErr(t, sealArchive(bytes.NewReader(plain), &out, hdr, enc, signer), "seal")
+	return out.Bytes()
+}
+
+func open(sealed []byte, keys []config.BackupKeyConfig, tp *trustPolicy) ([]byte, *openResult, error) {
+	var out bytes.Buffer
+	res, err := openArchive(bytes.NewReader(sealed), &out, keys, tp)
+	return out.Bytes(), res, err
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	keyFile, pubLine := testKeyPair(t, dir, "plant")
+	_, signer, _ := ed25519.GenerateKey(rand.Reader)
+	trust := &trustPolicy{keys: []ed25519.PublicKey{signer.Public().(ed25519.PublicKey)}}
+
+	// Several chunks and a partial last one.
+	plain := make([]byte, 3*sealChunkSize+123)
+	_, _ = rand.Read(plain)
+	cases := map[string]struct {
+		seal config.BackupKeyConfig
+		open config.BackupKeyConfig
+	}{
+		"passphrase": {
+			seal: config.BackupKeyConfig{ID: "pw", Passphrase: "correct horse"},
+			open: config.BackupKeyConfig{ID: "pw", Passphrase: "correct horse"},
+		},
+		"public key": {
+			seal: config.BackupKeyConfig{ID: "pk", PublicKey: pubLine},
+			open: config.BackupKeyConfig{ID: "pk", PrivateKeyFile: keyFile},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			enc := config.BackupEncryptionConfig{Enabled: true, Keys: []config.BackupKeyConfig{tc.seal}}
+			sealed := seal(t, plain, enc, signer)
+			if bytes.Contains(sealed, plain[:64]) {
+				t.Fatal("plaintext visible in sealed archive")
+			}
+			got, res, err := open(sealed, []config.BackupKeyConfig{tc.open}, trust)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, plain) || !res.Encrypted || res.KeyID != tc.seal.ID || res.Unverified != "" {
+				t.Fatalf("opened %d bytes, %+v", len(got), res)
+			}
+		})
+	}
+
+	t.Run("signed only", func(t *testing.T) {
+		sealed := seal(t, plain, config.BackupEncryptionConfig{}, signer)
+		got, res, err := open(sealed, nil, trust)
+		if err != nil || !bytes.Equal(got, plain) || res.Encrypted {
+			t.Fatalf("opened %d bytes, %+v, %v", len(got), res, err)
+		}
+	})
+}
+
+func TestOpenRefusesUnverified(t *testing.T) {
+	t.Parallel()
+	_, signer, _ := ed25519.GenerateKey(rand.Reader)
+	plain := []byte(strings.Repeat("snapshot ", 1000))
+	signed := seal(t, plain, config.BackupEncryptionConfig{}, signer)
+	tampered := bytes.Clone(signed)
+	tampered[len(tampered)-ed25519.SignatureSize-10] ^= 1
+
+	strict := &trustPolicy{keys: []ed25519.PublicKey{signer.Public().(ed25519.PublicKey)}}
+	for name, archive := range map[string][]byte{"unsigned": plain, "tampered": tampered} {
+		if _, _, err := open(archive, nil, strict); !errors.Is(err, ErrUnverified) {
+			t.Fatalf("%s: %v, want ErrUnverified", name, err)
+		}
+	}
+	if _, _, err := open(signed, nil, &trustPolicy{}); err == nil || !strings.Contains(err.Error(), "not a trusted signing key") {
+		t.Fatalf("unknown signer: %v", err)
+	}
+
+	// A fingerprint is as good as the key.
+	sshKey, _ := ssh.NewPublicKey(signer.Public())
+	if _, _, err := open(signed, nil, &trustPolicy{fingerprints: []string{ssh.FingerprintSHA256(sshKey)}}); err != nil {
+		t.Fatalf("fingerprint trust: %v", err)
+	}
+
+	// The override lets them through and says why.
+	override := &trustPolicy{allowUnsigned: true}
+	got, res, err := open(plain, nil, override)
+	if err != nil || !bytes.Equal(got, plain) || res.Unverified == "" {
+		t.Fatalf("override unsigned: %+v, %v", res, err)
+	}
+	if _, res, err = open(tampered, nil, override); err != nil || !strings.Contains(res.Unverified, "tampered") {
+		t.Fatalf("override tampered: %+v, %v", res, err)
+	}
+
+	// Encrypted chunks fail authentication whatever the override.
+	enc := config.BackupEncryptionConfig{Enabled: true, Keys: []config.BackupKeyConfig{{ID: "pw", Passphrase: "pw"}}}
+	sealed := seal(t, plain, enc, signer)
+	sealed[len(sealed)-ed25519.SignatureSize-10] ^= 1
+	if _, _, err := open(sealed, enc.Keys, override); err == nil || errors.Is(err, ErrUnverified) {
+		t.Fatalf("tampered ciphertext: %v", err)
+	}
+}
+
+// TestKeyRotation seals with one key, rotates, and checks both generations
+// still open while both keys are listed.
+func TestKeyRotation(t *testing.T) {
+	t.Parallel()
+	_, signer, _ := ed25519.GenerateKey(rand.Reader)
+	trust := &trustPolicy{keys: []ed25519.PublicKey{signer.Public().(ed25519.PublicKey)}}
+	oldKey := config.BackupKeyConfig{ID: "2026-q3", Passphrase: "old"}
+	newKey := config.BackupKeyConfig{ID: "2026-q4", Passphrase: "new"}
+
+	before := seal(t, []byte("before"), config.BackupEncryptionConfig{Enabled: true, Keys: []config.BackupKeyConfig{oldKey}}, signer)
+	rotated := config.BackupEncryptionConfig{Enabled: true, Keys: []config.BackupKeyConfig{newKey, oldKey}}
+	after := seal(t, []byte("after"), rotated, signer)
+
+	for want, archive := range map[string][]byte{"before": before, "after": after} {
+		got, _, err := open(archive, rotated.Keys, trust)
+		if err != nil || string(got) != want {
+			t.Fatalf("open %s = %q, %v", want, got, err)
+		}
+	}
+	_, res, err := open(after, rotated.Keys, trust)
+	if err != nil || res.KeyID != "2026-q4" {
+		t.Fatalf("new archives seal to the first key: %+v, %v", res, err)
+	}
+	if _, _, err := open(before, []config.BackupKeyConfig{newKey}, trust); err == nil || !strings.Contains(err.Error(), `"2026-q3"`) {
+		t.Fatalf("retired key: %v, want the archive's key named", err)
+	}
+}
+
+func TestX25519ConversionMatches(t *testing.T) {
+	t.Parallel()
+	for range 20 {
+		pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+		xpub, err := x25519Public(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xpriv, err := x25519Private(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !xpriv.PublicKey().Equal(xpub) {
+			t.Fatal("converted public key does not match converted private key")
+		}
+	}
+}
+
+// writeTestArchive builds a snapshot archive holding config and db as
+// createSnapshotArchive would.
+func writeTestArchive(t *testing.T, stationID string, cfgData, dbData []byte) []byte {
+	t.Helper()
+	manifest := Manifest{FormatVersion: FormatVersion, StationID: stationID, CreatedAt: time.Now().UTC()}
+	entries := map[string][]byte{ConfigEntryName: cfgData, DBEntryName: dbData}
+	for _, name := range []string{ConfigEntryName, DBEntryName} {
+		path := filepath.Join(t.TempDir(), name)
+		testutil.MustNoErr(t, os.WriteFile(path, entries[name], 0o644), "write entry")
+		sum, err := fileSHA256(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{Name: name, Size: int64(len(entries[name])), SHA256: sum})
+	}
+	manifestBytes, _ := json.Marshal(manifest)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	testutil.MustNoErr(t, writeTarEntry(tw, ManifestName, manifestBytes, manifest.CreatedAt), "manifest")
+	for _, name := range []string{ConfigEntryName, DBEntryName} {
+		testutil.MustNoErr(t, writeTarEntry(tw, name, entries[name], manifest.CreatedAt), name)
+	}
+	testutil.MustNoErr(t, tw.Close(), "close tar")
+	testutil.MustNoErr(t, gz.Close(), "close gzip")
+	return buf.Bytes()
+}
+
+func TestApplyPendingRestoreChecksSeal(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "shingoedge.yaml")
+	dbPath := filepath.Join(dir, "edge.db")
+	current := "database_path: " + dbPath + "\nbackup:\n  encryption:\n    enabled: true\n    keys:\n      - id: plant\n        passphrase: s3cret\n"
+	testutil.MustNoErr(t, os.WriteFile(configPath, []byte(current), 0o644), "write config")
+	backupCfg, err := currentBackupConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The edge's own key signs, and is generated once.
+	signer, err := loadSigningKey(configPath, backupCfg.Signing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := loadSigningKey(configPath, backupCfg.Signing)
+	if err != nil || !again.Equal(signer) {
+		t.Fatalf("signing key not kept: %v", err)
+	}
+	if !strings.HasPrefix(SigningPublicKey(configPath, backupCfg.Signing), "ssh-ed25519 ") {
+		t.Fatal("signing public key not reported")
+	}
+
+	restoredCfg := []byte(current + "# restored\n")
+	archive := writeTestArchive(t, "line-1", restoredCfg, []byte("db bytes"))
+	sealed := seal(t, archive, backupCfg.Encryption, signer)
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	testutil.MustNoErr(t, StageRestoreArchive(configPath, "k", bytes.NewReader(tampered), "line-1", false), "stage tampered")
+	if err := ApplyPendingRestore(configPath, t.Logf); !errors.Is(err, ErrUnverified) {
+		t.Fatalf("tampered restore: %v, want ErrUnverified", err)
+	}
+	if got, _ := os.ReadFile(configPath); string(got) != current {
+		t.Fatal("refused restore changed the config")
+	}
+
+	testutil.MustNoErr(t, StageRestoreArchive(configPath, "k", bytes.NewReader(sealed), "line-1", false), "stage")
+	testutil.MustNoErr(t, ApplyPendingRestore(configPath, t.Logf), "apply")
+	if got, _ := os.ReadFile(configPath); !bytes.Equal(got, restoredCfg) {
+		t.Fatalf("config = %q", got)
+	}
+	if got, _ := os.ReadFile(dbPath); string(got) != "db bytes" {
+		t.Fatalf("db = %q", got)
+	}
+
+	// A pre-signing archive needs the override, recorded when staged.
+	testutil.MustNoErr(t, StageRestoreArchive(configPath, "k", bytes.NewReader(archive), "line-1", false), "stage legacy")
+	if err := ApplyPendingRestore(configPath, t.Logf); !errors.Is(err, ErrUnverified) {
+		t.Fatalf("legacy restore: %v, want ErrUnverified", err)
+	}
+	testutil.MustNoErr(t, StageRestoreArchive(configPath, "k", bytes.NewReader(archive), "line-1", true), "stage legacy override")
+	testutil.MustNoErr(t, ApplyPendingRestore(configPath, t.Logf), "apply legacy override")
+}
@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -28,7 +29,7 @@ type Service struct {
 	triggerCh      chan string
 	stopCh         chan struct{}
 	wg             sync.WaitGroup
-	storageFactory func(config.BackupS3Config) (Storage, error)
+	storageFactory func(config.BackupConfig) (Storage, error)
 	runFlag        atomic.Bool
 }
 
@@ -37,16 +38,14 @@ func NewService(db *store.DB, cfg *config.Config, configPath, appVersion string,
 		logf = log.Printf
 	}
 	svc := &Service{
-		db:         db,
-		cfg:        cfg,
-		configPath: configPath,
-		appVersion: appVersion,
-		logf:       logf,
-		triggerCh:  make(chan string, 64),
-		stopCh:     make(chan struct{}),
-		storageFactory: func(cfg config.BackupS3Config) (Storage, error) {
-			return NewS3Storage(cfg)
-		},
+		db:             db,
+		cfg:            cfg,
+		configPath:     configPath,
+		appVersion:     appVersion,
+		logf:           logf,
+		triggerCh:      make(chan string, 64),
+		stopCh:         make(chan struct{}),
+		storageFactory: NewStorage,
 	}
 	svc.refreshStaticStatus()
 	if marker, err := PendingRestore(configPath); err == nil && marker != nil {
@@ -113,23 +112,28 @@ func (s *Service) ListBackups(ctx context.Context) ([]SnapshotInfo, error) {
 	return out, nil
 }
 
-func (s *Service) TestConfig(ctx context.Context, s3Cfg config.BackupS3Config) error {
-	storage, err := s.storageFactory(s3Cfg)
+// TestConfig round-trips a test object through the storage backupCfg
+// describes; only its storage settings are read.
+func (s *Service) TestConfig(ctx context.Context, backupCfg config.BackupConfig) error {
+	storage, err := s.storageFactory(backupCfg)
 	if err != nil {
 		return err
 	}
 	s.cfg.RLock()
 	stationID := s.cfg.StationID()
 	s.cfg.RUnlock()
-	return storage.Test(ctx, stationID)
+	return TestStorage(ctx, storage, stationID)
 }
 
 func (s *Service) RunNow(ctx context.Context, reason string) error {
 	return s.runBackup(ctx, reason)
 }
 
-func (s *Service) StageRestore(ctx context.Context, key string) error {
-	storage, stationID, _, err := s.storageFromConfig()
+// StageRestore downloads an archive, checks it opens and belongs to this
+// station, and stages it for the next start. allowUnsigned overrides the
+// signature check for this restore only.
+func (s *Service) StageRestore(ctx context.Context, key string, allowUnsigned bool) error {
+	storage, stationID, backupCfg, err := s.storageFromConfig()
 	if err != nil {
 		return err
 	}
@@ -143,14 +147,27 @@ func (s *Service) StageRestore(ctx context.Context, key string) error {
 	if err != nil {
 		return fmt.Errorf("read backup archive: %w", err)
 	}
-	manifest, err := ReadManifestFromArchive(bytesReader(archiveData))
+	tp, err := newTrustPolicy(s.configPath, backupCfg.Signing)
+	if err != nil {
+		return err
+	}
+	tp.allowUnsigned = tp.allowUnsigned || allowUnsigned
+	var plain bytes.Buffer
+	opened, err := openArchive(bytesReader(archiveData), &plain, backupCfg.Encryption.Keys, tp)
+	if err != nil {
+		return err
+	}
+	if opened.Unverified != "" {
+		s.logf("backup: staging unverified archive %s by override: %s", key, opened.Unverified)
+	}
+	manifest, err := ReadManifestFromArchive(&plain)
 	if err != nil {
 		return err
 	}
 	if manifest.StationID != stationID {
 		return fmt.Errorf("backup station ID %q does not match current station %q", manifest.StationID, stationID)
 	}
-	if err := StageRestoreArchive(s.configPath, key, bytesReader(archiveData), stationID); err != nil {
+	if err := StageRestoreArchive(s.configPath, key, bytesReader(archiveData), stationID, allowUnsigned); err != nil {
 		return err
 	}
 	now := time.Now().UTC()
@@ -162,16 +179,18 @@ func (s *Service) StageRestore(ctx context.Context, key string) error {
 	return nil
 }
 
-func ListBackupsWithConfig(ctx context.Context, s3Cfg config.BackupS3Config, stationID string) ([]SnapshotInfo, error) {
-	storage, err := NewS3Storage(s3Cfg)
+func ListBackupsWithConfig(ctx context.Context, backupCfg config.BackupConfig, stationID string) ([]SnapshotInfo, error) {
+	storage, err := NewStorage(backupCfg)
 	if err != nil {
 		return nil, err
 	}
 	return listBackupsForStation(ctx, storage, stationID)
 }
 
-func RestoreNow(ctx context.Context, configPath string, s3Cfg config.BackupS3Config, stationID, key string) error {
-	storage, err := NewS3Storage(s3Cfg)
+// RestoreNow downloads and restores an archive at once, opening it with
+// backupCfg's encryption keys and signing trust.
+func RestoreNow(ctx context.Context, configPath string, backupCfg config.BackupConfig, stationID, key string) error {
+	storage, err := NewStorage(backupCfg)
 	if err != nil {
 		return err
 	}
@@ -180,7 +199,7 @@ func RestoreNow(ctx context.Context, configPath string, s3Cfg config.BackupS3Con
 		return err
 	}
 	defer rc.Close()
-	return RestoreArchiveNow(configPath, rc, stationID)
+	return RestoreArchiveNow(configPath, backupCfg, rc, stationID)
 }
 
 func (s *Service) loop() {
@@ -271,14 +290,19 @@ func (s *Service) runBackup(ctx context.Context, reason string) error {
 	s.markRunning(true, reason)
 	defer s.markRunning(false, "")
 
-	archivePath, manifest, archiveSize, cleanup, err := createSnapshotArchive(s.db, s.cfg, s.configPath, s.appVersion)
+	archivePath, manifest, _, cleanup, err := createSnapshotArchive(s.db, s.cfg, s.configPath, s.appVersion)
 	if err != nil {
 		s.markFailure(err)
 		return err
 	}
 	defer cleanup()
+	archivePath, archiveSize, err := sealSnapshot(s.configPath, archivePath, manifest, backupCfg)
+	if err != nil {
+		s.markFailure(err)
+		return err
+	}
 
-	key := archiveKey(stationID, manifest.CreatedAt)
+	key := archiveKey(stationID, manifest.CreatedAt) + sealedSuffix
 	f, err := os.Open(archivePath)
 	if err != nil {
 		s.markFailure(err)
@@ -332,7 +356,7 @@ func (s *Service) storageFromConfig() (Storage, string, config.BackupConfig, err
 	stationID := s.cfg.StationID()
 	backupCfg := s.cfg.Backup
 	s.cfg.RUnlock()
-	storage, err := s.storageFactory(backupCfg.S3)
+	storage, err := s.storageFactory(backupCfg)
 	if err != nil {
 		return nil, "", backupCfg, err
 	}
@@ -343,13 +367,18 @@ func (s *Service) refreshStaticStatus() {
 	s.cfg.RLock()
 	enabled := s.cfg.Backup.Enabled
 	interval := s.cfg.Backup.ScheduleInterval
+	encrypted := s.cfg.Backup.Encryption.Enabled
+	signing := s.cfg.Backup.Signing
 	s.cfg.RUnlock()
 	if interval <= 0 {
 		interval = time.Hour
 	}
+	signingKey := SigningPublicKey(s.configPath, signing)
 	next := time.Now().UTC().Add(interval)
 	s.mu.Lock()
 	s.status.Enabled = enabled
+	s.status.Encrypted = encrypted
+	s.status.SigningKey = signingKey
 	s.status.ScheduleInterval = interval.String()
 	if s.status.LastSuccessAt != nil {
 		t := s.status.LastSuccessAt.Add(interval)
@@ -464,6 +493,7 @@ func listBackupsForStation(ctx context.Context, storage Storage, stationID strin
 		if ts, ok := inferSnapshotTime(item.Key); ok {
 			snap.CreatedAt = &ts
 		}
+		snap.Sealed = strings.HasSuffix(item.Key, sealedSuffix)
 		out = append(out, snap)
 	}
 	sort.Slice(out, func(i, j int) bool {
@@ -477,7 +507,7 @@ func inferSnapshotTime(key string) (time.Time, bool) {
 	if idx := lastSlash(key); idx >= 0 {
 		base = key[idx+1:]
 	}
-	base = strings.TrimSuffix(base, ".tar.gz")
+	base = strings.TrimSuffix(strings.TrimSuffix(base, sealedSuffix), ".tar.gz")
 	ts, err := time.Parse("2006-01-02T15-04-05Z", base)
 	if err != nil {
 		return time.Time{}, false
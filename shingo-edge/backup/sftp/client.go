@@ -0,0 +1,607 @@
+// Package sftp is a minimal SFTP (protocol version 3) client over an SSH
+// session: open, read, write, stat, list, mkdir, remove and rename — what
+// the backup store needs to keep archives on a file server.
+//
+// It exists so a plant with a file server and no object storage can still
+// keep edge backups off the box. Requests go one at a time; backups are a few
+// archives an hour, not a bulk transfer.
+package sftp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Packet types (draft-ietf-secsh-filexfer-02).
+const (
+	pktInit    byte = 1
+	pktVersion byte = 2
+	pktOpen    byte = 3
+	pktClose   byte = 4
+	pktRead    byte = 5
+	pktWrite   byte = 6
+	pktOpendir byte = 11
+	pktReaddir byte = 12
+	pktRemove  byte = 13
+	pktMkdir   byte = 14
+	pktRmdir   byte = 15
+	pktStat    byte = 17
+	pktRename  byte = 18
+	pktStatus  byte = 101
+	pktHandle  byte = 102
+	pktData    byte = 103
+	pktName    byte = 104
+	pktAttrs   byte = 105
+)
+
+// Open flags.
+const (
+	flagRead  uint32 = 0x01
+	flagWrite uint32 = 0x02
+	flagCreat uint32 = 0x08
+	flagTrunc uint32 = 0x10
+)
+
+// Attribute flags.
+const (
+	attrSize        uint32 = 0x01
+	attrUIDGID      uint32 = 0x02
+	attrPermissions uint32 = 0x04
+	attrACModTime   uint32 = 0x08
+	attrExtended    uint32 = 0x80000000
+)
+
+// Status codes the client acts on.
+const (
+	statusOK         uint32 = 0
+	statusEOF        uint32 = 1
+	statusNoSuchFile uint32 = 2
+	statusPermission uint32 = 3
+)
+
+const (
+	protocolVersion uint32 = 3
+	maxPacket              = 256 * 1024
+	chunkSize              = 32 * 1024
+
+	// Permission bits carry the file type, as in stat(2).
+	modeTypeMask uint32 = 0o170000
+	modeDir      uint32 = 0o040000
+)
+
+// StatusError is a server's refusal of a request.
+type StatusError struct {
+	Code uint32
+	Msg  string
+}
+
+func (e *StatusError) Error() string {
+	if e.Msg != "" {
+		return fmt.Sprintf("sftp: %s (status %d)", e.Msg, e.Code)
+	}
+	return fmt.Sprintf("sftp: status %d", e.Code)
+}
+
+// Is makes errors.Is(err, os.ErrNotExist) and os.ErrPermission work.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case os.ErrNotExist:
+		return e.Code == statusNoSuchFile
+	case os.ErrPermission:
+		return e.Code == statusPermission
+	}
+	return false
+}
+
+// FileInfo is what stat and readdir report about one entry.
+type FileInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+}
+
+// Client is one SFTP session. It is safe for concurrent use; requests are
+// serialised.
+type Client struct {
+	conn    *ssh.Client // owned when the client dialled it
+	session *ssh.Session
+	w       io.WriteCloser
+	r       io.Reader
+
+	mu     sync.Mutex
+	nextID uint32
+}
+
+// Dial connects to addr (host:port) and starts the sftp subsystem.
+func Dial(addr string, cfg *ssh.ClientConfig) (*Client, error) {
+	conn, err := ssh.Dial("tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c.conn = conn
+	return c, nil
+}
+
+// NewClient starts the sftp subsystem on an existing SSH connection. Close
+// ends the session but leaves the connection open.
+func NewClient(conn *ssh.Client) (*Client, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("sftp subsystem: %w", err)
+	}
+	c := &Client{session: session, w: w, r: r}
+	if err := c.handshake(); err != nil {
+		session.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) handshake() error {
+	var b buf
+	b.u32(protocolVersion)
+	if err := writePacket(c.w, pktInit, b); err != nil {
+		return fmt.Errorf("sftp init: %w", err)
+	}
+	typ, body, err := readPacket(c.r)
+	if err != nil {
+		return fmt.Errorf("sftp version: %w", err)
+	}
+	if typ != pktVersion {
+		return fmt.Errorf("sftp: expected version packet, got type %d", typ)
+	}
+	v, _, err := takeU32(body)
+	if err != nil {
+		return err
+	}
+	if v < protocolVersion {
+		return fmt.Errorf("sftp: server speaks version %d, need %d", v, protocolVersion)
+	}
+	return nil
+}
+
+// Close ends the session, and the connection if Dial opened it.
+func (c *Client) Close() error {
+	err := c.session.Close()
+	if c.conn != nil {
+		if cerr := c.conn.Close(); err == nil || errors.Is(err, io.EOF) {
+			err = cerr
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// request sends one request and returns the response's type and payload
+// after the id.
+func (c *Client) request(typ byte, fill func(*buf)) (byte, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	id := c.nextID
+	var b buf
+	b.u32(id)
+	fill(&b)
+	if err := writePacket(c.w, typ, b); err != nil {
+		return 0, nil, err
+	}
+	rtyp, body, err := readPacket(c.r)
+	if err != nil {
+		return 0, nil, err
+	}
+	rid, body, err := takeU32(body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if rid != id {
+		return 0, nil, fmt.Errorf("sftp: response id %d for request %d", rid, id)
+	}
+	return rtyp, body, nil
+}
+
+// status runs a request whose only answer is a status.
+func (c *Client) status(typ byte, fill func(*buf)) error {
+	rtyp, body, err := c.request(typ, fill)
+	if err != nil {
+		return err
+	}
+	return expectOK(rtyp, body)
+}
+
+func expectOK(typ byte, body []byte) error {
+	if typ != pktStatus {
+		return fmt.Errorf("sftp: expected status, got type %d", typ)
+	}
+	return statusErr(body)
+}
+
+// statusErr decodes a status payload; OK is nil.
+func statusErr(body []byte) error {
+	code, rest, err := takeU32(body)
+	if err != nil {
+		return err
+	}
+	if code == statusOK {
+		return nil
+	}
+	msg, _, _ := takeString(rest)
+	return &StatusError{Code: code, Msg: string(msg)}
+}
+
+func (c *Client) handle(typ byte, fill func(*buf)) (string, error) {
+	rtyp, body, err := c.request(typ, fill)
+	if err != nil {
+		return "", err
+	}
+	if rtyp == pktStatus {
+		if err := statusErr(body); err != nil {
+			return "", err
+		}
+		return "", errors.New("sftp: status OK where a handle was expected")
+	}
+	if rtyp != pktHandle {
+		return "", fmt.Errorf("sftp: expected handle, got type %d", rtyp)
+	}
+	h, _, err := takeString(body)
+	return string(h), err
+}
+
+func (c *Client) closeHandle(h string) error {
+	return c.status(pktClose, func(b *buf) { b.str(h) })
+}
+
+// Stat reports on a path, following symlinks.
+func (c *Client) Stat(p string) (FileInfo, error) {
+	rtyp, body, err := c.request(pktStat, func(b *buf) { b.str(p) })
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if rtyp == pktStatus {
+		if err := statusErr(body); err != nil {
+			return FileInfo{}, err
+		}
+	}
+	if rtyp != pktAttrs {
+		return FileInfo{}, fmt.Errorf("sftp: expected attrs, got type %d", rtyp)
+	}
+	fi, _, err := takeAttrs(body)
+	fi.Name = path.Base(p)
+	return fi, err
+}
+
+// ReadDir lists a directory, without "." and "..".
+func (c *Client) ReadDir(dir string) ([]FileInfo, error) {
+	h, err := c.handle(pktOpendir, func(b *buf) { b.str(dir) })
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeHandle(h)
+	var out []FileInfo
+	for {
+		rtyp, body, err := c.request(pktReaddir, func(b *buf) { b.str(h) })
+		if err != nil {
+			return nil, err
+		}
+		if rtyp == pktStatus {
+			if err := statusErr(body); err != nil {
+				var se *StatusError
+				if errors.As(err, &se) && se.Code == statusEOF {
+					return out, nil
+				}
+				return nil, err
+			}
+			return out, nil
+		}
+		if rtyp != pktName {
+			return nil, fmt.Errorf("sftp: expected names, got type %d", rtyp)
+		}
+		names, err := takeNames(body)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range names {
+			if fi.Name != "." && fi.Name != ".." {
+				out = append(out, fi)
+			}
+		}
+	}
+}
+
+// Mkdir creates one directory.
+func (c *Client) Mkdir(p string) error {
+	return c.status(pktMkdir, func(b *buf) { b.str(p); b.u32(0) })
+}
+
+// MkdirAll creates a directory and any missing parents.
+func (c *Client) MkdirAll(p string) error {
+	if fi, err := c.Stat(p); err == nil {
+		if !fi.IsDir {
+			return fmt.Errorf("sftp: %s exists and is not a directory", p)
+		}
+		return nil
+	}
+	if parent := path.Dir(p); parent != p && parent != "." && parent != "/" {
+		if err := c.MkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	if err := c.Mkdir(p); err != nil {
+		// Lost a race with another writer, or the server refuses to say.
+		if fi, serr := c.Stat(p); serr == nil && fi.IsDir {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove deletes a file.
+func (c *Client) Remove(p string) error {
+	return c.status(pktRemove, func(b *buf) { b.str(p) })
+}
+
+// RemoveDir deletes an empty directory.
+func (c *Client) RemoveDir(p string) error {
+	return c.status(pktRmdir, func(b *buf) { b.str(p) })
+}
+
+// Rename moves a file. Version 3 servers refuse when newPath exists.
+func (c *Client) Rename(oldPath, newPath string) error {
+	return c.status(pktRename, func(b *buf) { b.str(oldPath); b.str(newPath) })
+}
+
+// WriteFile creates or truncates p and copies r into it.
+func (c *Client) WriteFile(p string, r io.Reader) (int64, error) {
+	h, err := c.handle(pktOpen, func(b *buf) {
+		b.str(p)
+		b.u32(flagWrite | flagCreat | flagTrunc)
+		b.u32(0)
+	})
+	if err != nil {
+		return 0, err
+	}
+	chunk := make([]byte, chunkSize)
+	var off int64
+	for {
+		n, rerr := r.Read(chunk)
+		if n > 0 {
+			data := chunk[:n]
+			err := c.status(pktWrite, func(b *buf) { b.str(h); b.u64(uint64(off)); b.bytes(data) })
+			if err != nil {
+				c.closeHandle(h)
+				return off, err
+			}
+			off += int64(n)
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			c.closeHandle(h)
+			return off, rerr
+		}
+	}
+	return off, c.closeHandle(h)
+}
+
+// Open opens p for reading.
+func (c *Client) Open(p string) (io.ReadCloser, error) {
+	h, err := c.handle(pktOpen, func(b *buf) { b.str(p); b.u32(flagRead); b.u32(0) })
+	if err != nil {
+		return nil, err
+	}
+	return &file{c: c, h: h}, nil
+}
+
+type file struct {
+	c      *Client
+	h      string
+	off    uint64
+	eof    bool
+	closed bool
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.eof {
+		return 0, io.EOF
+	}
+	if len(p) > chunkSize {
+		p = p[:chunkSize]
+	}
+	rtyp, body, err := f.c.request(pktRead, func(b *buf) { b.str(f.h); b.u64(f.off); b.u32(uint32(len(p))) })
+	if err != nil {
+		return 0, err
+	}
+	if rtyp == pktStatus {
+		err := statusErr(body)
+		var se *StatusError
+		if errors.As(err, &se) && se.Code == statusEOF {
+			f.eof = true
+			return 0, io.EOF
+		}
+		if err == nil {
+			err = errors.New("sftp: status OK where data was expected")
+		}
+		return 0, err
+	}
+	if rtyp != pktData {
+		return 0, fmt.Errorf("sftp: expected data, got type %d", rtyp)
+	}
+	data, _, err := takeString(body)
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p, data)
+	f.off += uint64(n)
+	return n, nil
+}
+
+func (f *file) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.c.closeHandle(f.h)
+}
+
+// ── wire encoding ───────────────────────────────────────────────────
+
+type buf []byte
+
+func (b *buf) u32(v uint32)   { *b = binary.BigEndian.AppendUint32(*b, v) }
+func (b *buf) u64(v uint64)   { *b = binary.BigEndian.AppendUint64(*b, v) }
+func (b *buf) str(s string)   { b.u32(uint32(len(s))); *b = append(*b, s...) }
+func (b *buf) bytes(p []byte) { b.u32(uint32(len(p))); *b = append(*b, p...) }
+
+func writePacket(w io.Writer, typ byte, payload buf) error {
+	out := make([]byte, 0, 5+len(payload))
+	out = binary.BigEndian.AppendUint32(out, uint32(1+len(payload)))
+	out = append(out, typ)
+	out = append(out, payload...)
+	_, err := w.Write(out)
+	return err
+}
+
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n == 0 || n > maxPacket {
+		return 0, nil, fmt.Errorf("sftp: bad packet length %d", n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return body[0], body[1:], nil
+}
+
+var errShort = errors.New("sftp: short packet")
+
+func takeU32(b []byte) (uint32, []byte, error) {
+	if len(b) < 4 {
+		return 0, nil, errShort
+	}
+	return binary.BigEndian.Uint32(b), b[4:], nil
+}
+
+func takeU64(b []byte) (uint64, []byte, error) {
+	if len(b) < 8 {
+		return 0, nil, errShort
+	}
+	return binary.BigEndian.Uint64(b), b[8:], nil
+}
+
+func takeString(b []byte) ([]byte, []byte, error) {
+	n, b, err := takeU32(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint32(len(b)) < n {
+		return nil, nil, errShort
+	}
+	return b[:n], b[n:], nil
+}
+
+func takeAttrs(b []byte) (FileInfo, []byte, error) {
+	var fi FileInfo
+	flags, b, err := takeU32(b)
+	if err != nil {
+		return fi, nil, err
+	}
+	if flags&attrSize != 0 {
+		var size uint64
+		if size, b, err = takeU64(b); err != nil {
+			return fi, nil, err
+		}
+		fi.Size = int64(size)
+	}
+	if flags&attrUIDGID != 0 {
+		if len(b) < 8 {
+			return fi, nil, errShort
+		}
+		b = b[8:]
+	}
+	if flags&attrPermissions != 0 {
+		var perm uint32
+		if perm, b, err = takeU32(b); err != nil {
+			return fi, nil, err
+		}
+		fi.IsDir = perm&modeTypeMask == modeDir
+	}
+	if flags&attrACModTime != 0 {
+		if len(b) < 8 {
+			return fi, nil, errShort
+		}
+		fi.ModTime = time.Unix(int64(binary.BigEndian.Uint32(b[4:8])), 0).UTC()
+		b = b[8:]
+	}
+	if flags&attrExtended != 0 {
+		var count uint32
+		if count, b, err = takeU32(b); err != nil {
+			return fi, nil, err
+		}
+		for i := uint32(0); i < 2*count; i++ {
+			if _, b, err = takeString(b); err != nil {
+				return fi, nil, err
+			}
+		}
+	}
+	return fi, b, nil
+}
+
+func takeNames(b []byte) ([]FileInfo, error) {
+	count, b, err := takeU32(b)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FileInfo, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var name []byte
+		if name, b, err = takeString(b); err != nil {
+			return nil, err
+		}
+		if _, b, err = takeString(b); err != nil { // longname
+			return nil, err
+		}
+		var fi FileInfo
+		if fi, b, err = takeAttrs(b); err != nil {
+			return nil, err
+		}
+		fi.Name = string(name)
+		out = append(out, fi)
+	}
+	return out, nil
+}
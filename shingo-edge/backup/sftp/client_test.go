@@ -0,0 +1,122 @@
+package sftp_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"shingoedge/backup/sftp"
+	"shingoedge/backup/sftp/sftptest"
+)
+
+func startServer(t *testing.T, srv *sftptest.Server, auth ssh.AuthMethod) *sftp.Client {
+	t.Helper()
+	addr, err := srv.Start()
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	c, err := sftp.Dial(addr, &ssh.ClientConfig{
+		User:            srv.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()),
+	})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestWriteReadListRemove(t *testing.T) {
+	root := t.TempDir()
+	srv := sftptest.New(root)
+	c := startServer(t, srv, ssh.Password(srv.Password))
+
+	if err := c.MkdirAll("/edge/2026/10"); err != nil {
+		t.Fatal(err)
+	}
+	// More than one write chunk, so offsets matter.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 5000)
+	if n, err := c.WriteFile("/edge/2026/10/a.part", bytes.NewReader(data)); err != nil || n != int64(len(data)) {
+		t.Fatalf("WriteFile = %d, %v", n, err)
+	}
+	if err := c.Rename("/edge/2026/10/a.part", "/edge/2026/10/a.tar.gz"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(filepath.Join(root, "edge/2026/10/a.tar.gz")); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("on disk: %d bytes, %v", len(got), err)
+	}
+
+	rc, err := c.Open("/edge/2026/10/a.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read back %d bytes, %v", len(got), err)
+	}
+
+	entries, err := c.ReadDir("/edge/2026")
+	if err != nil || len(entries) != 1 || entries[0].Name != "10" || !entries[0].IsDir {
+		t.Fatalf("ReadDir = %+v, %v", entries, err)
+	}
+	fi, err := c.Stat("/edge/2026/10/a.tar.gz")
+	if err != nil || fi.Size != int64(len(data)) || fi.IsDir || fi.ModTime.IsZero() {
+		t.Fatalf("Stat = %+v, %v", fi, err)
+	}
+
+	if err := c.Remove("/edge/2026/10/a.tar.gz"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Stat("/edge/2026/10/a.tar.gz"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Stat after remove = %v, want not-exist", err)
+	}
+	if err := c.RemoveDir("/edge/2026/10"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Open("/nope"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Open missing = %v, want not-exist", err)
+	}
+}
+
+func TestPublicKeyLoginAndHostKeyCheck(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := sftptest.New(t.TempDir())
+	srv.Password = ""
+	srv.AuthorizedKey = signer.PublicKey()
+	c := startServer(t, srv, ssh.PublicKeys(signer))
+	if _, err := c.ReadDir("/"); err != nil {
+		t.Fatal(err)
+	}
+
+	other := sftptest.New(t.TempDir())
+	addr, err := other.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(other.Close)
+	_, err = sftp.Dial(addr, &ssh.ClientConfig{
+		User:            other.User,
+		Auth:            []ssh.AuthMethod{ssh.Password(other.Password)},
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()),
+	})
+	if err == nil {
+		t.Fatal("dial accepted the wrong host key")
+	}
+}
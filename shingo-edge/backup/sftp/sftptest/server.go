@@ -0,0 +1,474 @@
+// Package sftptest is an in-process SSH server with an SFTP subsystem for
+// tests: password or public-key login, a fresh ed25519 host key, and the
+// version 3 requests the backup store makes, served out of a local
+// directory.
+package sftptest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Server is a fake backup host: an SSH server that accepts only the "sftp"
+// subsystem and speaks SFTP version 3 over Root. Login is User with Password
+// or AuthorizedKey, nothing else — no keyboard-interactive, no "none". The
+// requests the backup store makes (open, read, write, opendir, readdir,
+// stat, remove, mkdir, rmdir, rename) are served; any other answers
+// SSH_FX_OP_UNSUPPORTED, and rename refuses to overwrite as version 3 does.
+// The login fields are read per connection, so set them before Start.
+type Server struct {
+	// Root is the directory served as "/".
+	Root string
+	// User and Password are the accepted login. An empty Password turns
+	// password login off.
+	User     string
+	Password string
+	// AuthorizedKey, when set, is accepted for User.
+	AuthorizedKey ssh.PublicKey
+
+	hostKey  ssh.Signer
+	mu       sync.Mutex
+	requests int
+	ln       net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// New returns a server for root with login backup / secret.
+func New(root string) *Server {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		panic(err)
+	}
+	return &Server{Root: root, User: "backup", Password: "secret", hostKey: signer, conns: map[net.Conn]struct{}{}}
+}
+
+// HostKey is the key the server presents.
+func (s *Server) HostKey() ssh.PublicKey { return s.hostKey.PublicKey() }
+
+// Requests is how many SFTP requests the server has answered.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Start listens on a loopback port and returns its host:port.
+func (s *Server) Start() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.accept(ln)
+	return ln.Addr().String(), nil
+}
+
+// Close stops accepting SSH connections, cuts the open ones — an upload in
+// flight is left as a partial file under Root — and waits for them to end.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.ln != nil {
+		s.ln.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) config() *ssh.ServerConfig {
+	cfg := &ssh.ServerConfig{}
+	if s.Password != "" {
+		cfg.PasswordCallback = func(md ssh.ConnMetadata, pw []byte) (*ssh.Permissions, error) {
+			if md.User() == s.User && string(pw) == s.Password {
+				return nil, nil
+			}
+			return nil, errors.New("bad password")
+		}
+	}
+	if s.AuthorizedKey != nil {
+		want := s.AuthorizedKey.Marshal()
+		cfg.PublicKeyCallback = func(md ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if md.User() == s.User && string(key.Marshal()) == string(want) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		}
+	}
+	cfg.AddHostKey(s.hostKey)
+	return cfg
+}
+
+func (s *Server) accept(ln net.Listener) {
+	defer s.wg.Done()
+	cfg := s.config()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(c, cfg)
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+			c.Close()
+		}()
+	}
+}
+
+func (s *Server) serveConn(c net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(c, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, chReqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.session(ch, chReqs)
+		}()
+	}
+}
+
+// session waits for the sftp subsystem request and serves it.
+func (s *Server) session(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+		req.Reply(ok, nil)
+		if ok {
+			go ssh.DiscardRequests(reqs)
+			(&handler{s: s, handles: map[string]any{}}).serve(ch)
+			return
+		}
+	}
+}
+
+// ── SFTP ────────────────────────────────────────────────────────────
+
+const (
+	pktInit     = 1
+	pktVersion  = 2
+	pktOpen     = 3
+	pktClose    = 4
+	pktRead     = 5
+	pktWrite    = 6
+	pktOpendir  = 11
+	pktReaddir  = 12
+	pktRemove   = 13
+	pktMkdir    = 14
+	pktRmdir    = 15
+	pktStat     = 17
+	pktRename   = 18
+	pktStatus   = 101
+	pktHandle   = 102
+	pktData     = 103
+	pktName     = 104
+	pktAttrs    = 105
+	statusOK    = 0
+	statusEOF   = 1
+	statusNoent = 2
+	statusPerm  = 3
+	statusFail  = 4
+	statusBad   = 5
+	statusNoSup = 8
+)
+
+type handler struct {
+	s       *Server
+	handles map[string]any // *os.File or *dirList
+	next    int
+}
+
+type dirList struct {
+	entries []fs.DirEntry
+	sent    bool
+}
+
+func (h *handler) serve(rw io.ReadWriter) {
+	defer func() {
+		for _, v := range h.handles {
+			if f, ok := v.(*os.File); ok {
+				f.Close()
+			}
+		}
+	}()
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(rw, hdr[:]); err != nil {
+			return
+		}
+		body := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+		if len(body) == 0 {
+			return
+		}
+		if _, err := io.ReadFull(rw, body); err != nil {
+			return
+		}
+		if body[0] == pktInit {
+			writePacket(rw, pktVersion, be32(nil, 3))
+			continue
+		}
+		r := &reader{b: body[1:]}
+		id := r.u32()
+		typ, out := h.handle(body[0], r)
+		h.s.mu.Lock()
+		h.s.requests++
+		h.s.mu.Unlock()
+		if _, err := rw.Write(packet(typ, append(be32(nil, id), out...))); err != nil {
+			return
+		}
+	}
+}
+
+func (h *handler) handle(typ byte, r *reader) (byte, []byte) {
+	switch typ {
+	case pktOpen:
+		return h.open(r)
+	case pktClose:
+		hd := r.str()
+		v, ok := h.handles[hd]
+		if !ok {
+			return statusCode(statusBad, "no such handle")
+		}
+		delete(h.handles, hd)
+		if f, ok := v.(*os.File); ok {
+			return status(f.Close())
+		}
+		return status(nil)
+	case pktRead:
+		return h.read(r)
+	case pktWrite:
+		f, ok := h.handles[r.str()].(*os.File)
+		off, data := r.u64(), r.str()
+		if !ok {
+			return statusCode(statusBad, "no such handle")
+		}
+		_, err := f.WriteAt([]byte(data), int64(off))
+		return status(err)
+	case pktOpendir:
+		entries, err := os.ReadDir(h.local(r.str()))
+		if err != nil {
+			return status(err)
+		}
+		return h.newHandle(&dirList{entries: entries})
+	case pktReaddir:
+		return h.readdir(r.str())
+	case pktStat:
+		fi, err := os.Stat(h.local(r.str()))
+		if err != nil {
+			return status(err)
+		}
+		return pktAttrs, attrs(nil, fi)
+	case pktRemove:
+		p := h.local(r.str())
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+			return statusCode(statusFail, "is a directory")
+		}
+		return status(os.Remove(p))
+	case pktMkdir:
+		return status(os.Mkdir(h.local(r.str()), 0o755))
+	case pktRmdir:
+		return status(os.Remove(h.local(r.str())))
+	case pktRename:
+		from, to := h.local(r.str()), h.local(r.str())
+		if _, err := os.Stat(to); err == nil {
+			return statusCode(statusFail, "target exists") // version 3 rename
+		}
+		return status(os.Rename(from, to))
+	}
+	return statusCode(statusNoSup, "unsupported request "+strconv.Itoa(int(typ)))
+}
+
+func (h *handler) open(r *reader) (byte, []byte) {
+	p := h.local(r.str())
+	flags := r.u32()
+	mode := os.O_RDONLY
+	if flags&0x02 != 0 {
+		mode = os.O_WRONLY
+		if flags&0x01 != 0 {
+			mode = os.O_RDWR
+		}
+	}
+	if flags&0x08 != 0 {
+		mode |= os.O_CREATE
+	}
+	if flags&0x10 != 0 {
+		mode |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(p, mode, 0o644)
+	if err != nil {
+		return status(err)
+	}
+	return h.newHandle(f)
+}
+
+func (h *handler) read(r *reader) (byte, []byte) {
+	f, ok := h.handles[r.str()].(*os.File)
+	off, n := r.u64(), r.u32()
+	if !ok {
+		return statusCode(statusBad, "no such handle")
+	}
+	buf := make([]byte, n)
+	got, err := f.ReadAt(buf, int64(off))
+	if got == 0 && errors.Is(err, io.EOF) {
+		return statusCode(statusEOF, "EOF")
+	}
+	if got == 0 && err != nil {
+		return status(err)
+	}
+	return pktData, bestr(nil, buf[:got])
+}
+
+func (h *handler) readdir(hd string) (byte, []byte) {
+	d, ok := h.handles[hd].(*dirList)
+	if !ok {
+		return statusCode(statusBad, "no such handle")
+	}
+	if d.sent {
+		return statusCode(statusEOF, "EOF")
+	}
+	d.sent = true
+	out := be32(nil, uint32(len(d.entries)))
+	for _, e := range d.entries {
+		fi, err := e.Info()
+		if err != nil {
+			return status(err)
+		}
+		out = bestr(out, []byte(e.Name()))
+		out = bestr(out, []byte(e.Name()))
+		out = attrs(out, fi)
+	}
+	return pktName, out
+}
+
+func (h *handler) newHandle(v any) (byte, []byte) {
+	h.next++
+	hd := strconv.Itoa(h.next)
+	h.handles[hd] = v
+	return pktHandle, bestr(nil, []byte(hd))
+}
+
+// local maps an SFTP path into Root; ".." cannot climb out.
+func (h *handler) local(p string) string {
+	return filepath.Join(h.s.Root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+func status(err error) (byte, []byte) {
+	switch {
+	case err == nil:
+		return statusCode(statusOK, "")
+	case errors.Is(err, fs.ErrNotExist):
+		return statusCode(statusNoent, err.Error())
+	case errors.Is(err, fs.ErrPermission):
+		return statusCode(statusPerm, err.Error())
+	}
+	return statusCode(statusFail, err.Error())
+}
+
+func statusCode(code uint32, msg string) (byte, []byte) {
+	out := be32(nil, code)
+	out = bestr(out, []byte(msg))
+	return pktStatus, bestr(out, nil)
+}
+
+func attrs(b []byte, fi fs.FileInfo) []byte {
+	perm := uint32(fi.Mode().Perm())
+	if fi.IsDir() {
+		perm |= 0o040000
+	} else {
+		perm |= 0o100000
+	}
+	b = be32(b, 0x01|0x04|0x08)
+	b = binary.BigEndian.AppendUint64(b, uint64(fi.Size()))
+	b = be32(b, perm)
+	mtime := uint32(fi.ModTime().Unix())
+	b = be32(b, mtime)
+	return be32(b, mtime)
+}
+
+func be32(b []byte, v uint32) []byte { return binary.BigEndian.AppendUint32(b, v) }
+
+func bestr(b, s []byte) []byte { return append(be32(b, uint32(len(s))), s...) }
+
+func packet(typ byte, payload []byte) []byte {
+	out := be32(nil, uint32(1+len(payload)))
+	return append(append(out, typ), payload...)
+}
+
+func writePacket(w io.Writer, typ byte, payload []byte) {
+	w.Write(packet(typ, payload))
+}
+
+// reader decodes a request; a short packet reads as zeros and is answered
+// with whatever the zeros mean.
+type reader struct{ b []byte }
+
+func (r *reader) u32() uint32 {
+	if len(r.b) < 4 {
+		r.b = nil
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v
+}
+
+func (r *reader) u64() uint64 {
+	if len(r.b) < 8 {
+		r.b = nil
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func (r *reader) str() string {
+	n := r.u32()
+	if uint32(len(r.b)) < n {
+		r.b = nil
+		return ""
+	}
+	s := string(r.b[:n])
+	r.b = r.b[n:]
+	return s
+}
@@ -206,3 +206,35 @@ func writeTarFile(tw *tar.Writer, srcPath, entryName string, modTime time.Time)
 	}
 	return nil
 }
+
+// sealSnapshot signs the snapshot archive, and encrypts it when backupCfg
+// asks, into a file beside it. It returns the sealed file and its size.
+func sealSnapshot(configPath, archivePath string, manifest *Manifest, backupCfg config.BackupConfig) (string, int64, error) {
+	signer, err := loadSigningKey(configPath, backupCfg.Signing)
+	if err != nil {
+		return "", 0, err
+	}
+	in, err := os.Open(archivePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("open archive: %w", err)
+	}
+	defer in.Close()
+	sealedPath := archivePath + sealedSuffix
+	out, err := os.Create(sealedPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("create sealed archive: %w", err)
+	}
+	hdr := sealHeader{StationID: manifest.StationID, CreatedAt: manifest.CreatedAt}
+	if err := sealArchive(in, out, hdr, backupCfg.Encryption, signer); err != nil {
+		out.Close()
+		return "", 0, err
+	}
+	if err := out.Close(); err != nil {
+		return "", 0, fmt.Errorf("close sealed archive: %w", err)
+	}
+	info, err := os.Stat(sealedPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("stat sealed archive: %w", err)
+	}
+	return sealedPath, info.Size(), nil
+}
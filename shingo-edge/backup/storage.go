@@ -2,13 +2,38 @@ package backup
 
 import (
 	"context"
-	"io"
+	"strings"
+
+	"shingo/shared/backupstore"
+	"shingoedge/config"
 )
 
-type Storage interface {
-	Test(ctx context.Context, stationID string) error
-	Put(ctx context.Context, key string, body io.Reader, size int64, metadata map[string]string) error
-	Get(ctx context.Context, key string) (io.ReadCloser, error)
-	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
-	Delete(ctx context.Context, key string) error
+// Storage and its directory and S3 backends are shared with Core's backups
+// (shared/backupstore); this package adapts the edge's config to them and
+// adds SFTP, which only the edge offers.
+type Storage = backupstore.Storage
+
+type ObjectInfo = backupstore.ObjectInfo
+
+// NewStorage builds the backend cfg.Storage names. Empty is s3, which is
+// what every config written before the other backends existed meant.
+func NewStorage(cfg config.BackupConfig) (Storage, error) {
+	kind := strings.TrimSpace(cfg.Storage)
+	switch kind {
+	case "":
+		kind = config.BackupStorageS3
+	case config.BackupStorageSFTP:
+		return NewSFTPStorage(cfg.SFTP)
+	}
+	return backupstore.New(backupstore.Config{
+		Kind:       kind,
+		Filesystem: backupstore.FilesystemConfig(cfg.Filesystem),
+		S3:         backupstore.S3Config(cfg.S3),
+	})
+}
+
+// TestStorage round-trips a small object through storage under the
+// station's prefix.
+func TestStorage(ctx context.Context, storage Storage, stationID string) error {
+	return storage.Test(ctx, objectPrefix(stationID))
 }
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingo/shared/backupstore"
+	"shingoedge/backup/sftp/sftptest"
+	"shingoedge/config"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpBackend serves dir/backups over SFTP and returns the config for it.
+func sftpBackend(t *testing.T, dir string) config.BackupConfig {
+	t.Helper()
+	testutil.MustNoErr(t, os.MkdirAll(filepath.Join(dir, "backups"), 0o755), "mkdir")
+	srv := sftptest.New(dir)
+	addr, err := srv.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	return config.BackupConfig{Storage: config.BackupStorageSFTP, SFTP: config.BackupSFTPConfig{
+		Host: addr, User: srv.User, Password: srv.Password, Path: "/backups",
+		HostKey: string(ssh.MarshalAuthorizedKey(srv.HostKey())),
+	}}
+}
+
+// TestDirectoryBackends runs the same put, list, prune and get sequence
+// against the filesystem and SFTP backends, both of which land files under
+// dir/backups.
+func TestDirectoryBackends(t *testing.T) {
+	backends := map[string]func(t *testing.T, dir string) config.BackupConfig{
+		"filesystem": func(t *testing.T, dir string) config.BackupConfig {
+			testutil.MustNoErr(t, os.MkdirAll(filepath.Join(dir, "backups"), 0o755), "mkdir")
+			return config.BackupConfig{Storage: config.BackupStorageFilesystem, Filesystem: config.BackupFilesystemConfig{Path: filepath.Join(dir, "backups")}}
+		},
+		"sftp": sftpBackend,
+	}
+	for name, setup := range backends {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			storage, err := NewStorage(setup(t, dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			exerciseStorage(t, storage, filepath.Join(dir, "backups"))
+		})
+	}
+}
+
+func exerciseStorage(t *testing.T, storage Storage, root string) {
+	ctx := context.Background()
+	testutil.MustNoErr(t, TestStorage(ctx, storage, "line-1"), "test")
+
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var keys []string
+	for i := 0; i < 3; i++ {
+		at := base.Add(-time.Duration(i) * time.Hour)
+		key := archiveKey("line-1", at)
+		body := "archive-" + key
+		testutil.MustNoErr(t, storage.Put(ctx, key, strings.NewReader(body), int64(len(body)), nil), "put")
+		// Retention reads modification times, as it does S3's LastModified.
+		testutil.MustNoErr(t, os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), at, at), "chtimes")
+		keys = append(keys, key)
+	}
+	other := archiveKey("line-2", base)
+	testutil.MustNoErr(t, storage.Put(ctx, other, strings.NewReader("x"), 1, nil), "put other")
+	// An interrupted upload is never offered.
+	testutil.MustNoErr(t, os.WriteFile(filepath.Join(root, filepath.FromSlash(keys[0]))+backupstore.PartialSuffix, []byte("half"), 0o644), "partial")
+
+	snaps, err := listBackupsForStation(ctx, storage, "line-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 || snaps[0].Key != keys[0] || snaps[0].CreatedAt == nil || !snaps[0].CreatedAt.Equal(base) {
+		t.Fatalf("list = %+v", snaps)
+	}
+
+	// Retention keeps the newest only.
+	svc := &Service{logf: t.Logf}
+	testutil.MustNoErr(t, svc.prune(ctx, storage, config.BackupConfig{KeepHourly: 1}, "line-1"), "prune")
+	items, err := storage.List(ctx, objectPrefix("line-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(items))
+	for _, it := range items {
+		got = append(got, it.Key)
+	}
+	sort.Strings(got)
+	if len(got) != 1 || got[0] != keys[0] {
+		t.Fatalf("after prune: %v, want [%s]", got, keys[0])
+	}
+
+	rc, err := storage.Get(ctx, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(body) != "archive-"+keys[0] {
+		t.Fatalf("get = %q, %v", body, err)
+	}
+
+	testutil.MustNoErr(t, storage.Delete(ctx, other), "delete")
+	if _, err := os.Stat(filepath.Join(root, "line-2")); !os.IsNotExist(err) {
+		t.Fatalf("emptied station folder left behind: %v", err)
+	}
+	if _, err := storage.Get(ctx, "../escape"); err == nil {
+		t.Fatal("key outside the root accepted")
+	}
+}
+
+func TestSFTPStorageNamesUnpinnedHostKey(t *testing.T) {
+	t.Parallel()
+	cfg := sftpBackend(t, t.TempDir())
+	cfg.SFTP.HostKey = ""
+	storage, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = TestStorage(context.Background(), storage, "line-1")
+	if err == nil || !strings.Contains(err.Error(), "SHA256:") {
+		t.Fatalf("unpinned host key: %v, want the offered fingerprint", err)
+	}
+
+	cfg = sftpBackend(t, t.TempDir())
+	key, _, _, _, _ := ssh.ParseAuthorizedKey([]byte(cfg.SFTP.HostKey))
+	cfg.SFTP.HostKey = ssh.FingerprintSHA256(key)
+	storage, err = NewStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.MustNoErr(t, TestStorage(context.Background(), storage, "line-1"), "fingerprint pin")
+}
+
+func TestNewStorageDefaultsToS3(t *testing.T) {
+	t.Parallel()
+	if _, err := NewStorage(config.BackupConfig{}); err == nil || !strings.Contains(err.Error(), "endpoint") {
+		t.Fatalf("empty storage should build S3 and ask for an endpoint, got %v", err)
+	}
+	if _, err := NewStorage(config.BackupConfig{Storage: "tape"}); err == nil {
+		t.Fatal("unknown storage accepted")
+	}
+}
@@ -23,12 +23,6 @@ type ManifestFile struct {
 	SHA256 string `json:"sha256"`
 }
 
-type ObjectInfo struct {
-	Key          string     `json:"key"`
-	Size         int64      `json:"size"`
-	LastModified *time.Time `json:"last_modified,omitempty"`
-}
-
 type SnapshotInfo struct {
 	Key            string     `json:"key"`
 	Size           int64      `json:"size"`
@@ -36,6 +30,7 @@ type SnapshotInfo struct {
 	CreatedAt      *time.Time `json:"created_at,omitempty"`
 	StationID      string     `json:"station_id,omitempty"`
 	FormatVersion  int        `json:"format_version,omitempty"`
+	Sealed         bool       `json:"sealed"`
 	RestorePending bool       `json:"restore_pending"`
 }
 
@@ -57,11 +52,14 @@ type Status struct {
 	RestorePending     bool       `json:"restore_pending"`
 	PendingRestoreKey  string     `json:"pending_restore_key,omitempty"`
 	PendingRestoreTime *time.Time `json:"pending_restore_time,omitempty"`
+	Encrypted          bool       `json:"encrypted"`
+	SigningKey         string     `json:"signing_key,omitempty"`
 }
 
 type RestoreMarker struct {
-	Key       string    `json:"key"`
-	StagedAt  time.Time `json:"staged_at"`
-	Archive   string    `json:"archive"`
-	StationID string    `json:"station_id"`
+	Key           string    `json:"key"`
+	StagedAt      time.Time `json:"staged_at"`
+	Archive       string    `json:"archive"`
+	StationID     string    `json:"station_id"`
+	AllowUnsigned bool      `json:"allow_unsigned,omitempty"`
 }
@@ -21,9 +21,11 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -37,6 +39,7 @@ import (
 	"shingo/protocol"
 	"shingo/protocol/debuglog"
 	"shingo/protocol/router"
+	"shingo/protocol/webtls"
 	"shingoedge/backup"
 	"shingoedge/config"
 	"shingoedge/engine"
@@ -215,15 +218,51 @@ func mustOpenDatabase(path string) *store.DB {
 	return db
 }
 
-func startHTTPServer(addr string, handler http.Handler) *http.Server {
+// prepareWebTLS loads or generates the HMI certificate. Nil means plain HTTP.
+// A configured but unusable certificate is fatal rather than a silent
+// fallback to HTTP.
+func prepareWebTLS(t config.WebTLSConfig) *webtls.Certificate {
+	if !t.Enabled {
+		return nil
+	}
+	cert, err := webtls.Prepare(webtls.Options{
+		CertFile:  t.CertFile,
+		KeyFile:   t.KeyFile,
+		Generate:  t.SelfSigned,
+		Dir:       t.Dir,
+		CAFile:    t.CAFile,
+		CAKeyFile: t.CAKeyFile,
+		Hosts:     t.Hosts,
+	})
+	if err != nil {
+		log.Fatalf("web tls: %v", err)
+	}
+	if cert.Generated {
+		log.Printf("web tls: issued HMI certificate signed by plant CA %s — browsers must trust that file", cert.CAFile)
+	}
+	log.Printf("web tls: certificate valid until %s", cert.NotAfter.Format(time.DateOnly))
+	return cert
+}
+
+// startHTTPServer serves handler on addr, over TLS when cert is non-nil, and
+// adds the plain-HTTP redirect listener when redirectPort is set.
+func startHTTPServer(addr string, handler http.Handler, cert *webtls.Certificate, redirectPort int) *http.Server {
 	// IdleTimeout reaps stale keep-alive slots so SSE goroutines don't
 	// pile up on rapid tab navigation. WriteTimeout is intentionally
 	// unset because SSE responses are long-lived writes by design.
 	srv := &http.Server{Addr: addr, Handler: handler, IdleTimeout: 120 * time.Second}
+	serve := srv.ListenAndServe
+	if cert != nil {
+		srv.TLSConfig = cert.TLSConfig
+		serve = func() error { return srv.ListenAndServeTLS("", "") }
+		if redirectPort > 0 {
+			startRedirectServer(srv, redirectPort)
+		}
+	}
 	go func() {
-		log.Printf("ShinGo Edge listening on %s", addr)
+		log.Printf("ShinGo Edge listening on %s (tls=%v)", addr, cert != nil)
 		for {
-			err := srv.ListenAndServe()
+			err := serve()
 			if err == http.ErrServerClosed {
 				return
 			}
@@ -239,6 +278,23 @@ func startHTTPServer(addr string, handler http.Handler) *http.Server {
 	return srv
 }
 
+func startRedirectServer(srv *http.Server, redirectPort int) {
+	host, httpsPort, _ := net.SplitHostPort(srv.Addr)
+	port, _ := strconv.Atoi(httpsPort)
+	redirect := &http.Server{
+		Addr:              net.JoinHostPort(host, strconv.Itoa(redirectPort)),
+		Handler:           webtls.RedirectHandler(port),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv.RegisterOnShutdown(func() { redirect.Close() })
+	go func() {
+		log.Printf("ShinGo Edge redirecting http on %s to https", redirect.Addr)
+		if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("http redirect: %v", err)
+		}
+	}()
+}
+
 // setupKafkaSubscribers wires protocol ingestor, heartbeater, and all handler
 // callbacks that require a live Kafka connection. Called only when Connect succeeds.
 // plantClaimsPub hands the plant-claims publisher to the SubjectEdgeRegistered
@@ -936,7 +992,11 @@ func main() {
 	defer plantClaims.Stop()
 
 	addr := fmt.Sprintf("%s:%d", cfg.Web.Host, cfg.Web.Port)
-	srv := startHTTPServer(addr, router)
+	webCert := prepareWebTLS(cfg.Web.TLS)
+	if webCert != nil {
+		h.SetWebCertificate(webCert.NotAfter)
+	}
+	srv := startHTTPServer(addr, router, webCert, cfg.Web.TLS.RedirectPort)
 
 	awaitShutdown(srv, stopWeb)
 }
@@ -952,57 +1012,40 @@ func runInteractiveRestore(configPath string) error {
 	if err != nil {
 		return err
 	}
-	endpoint, err := promptNonEmpty(reader, "S3 Endpoint URL")
+	kind, err := promptWithDefault(reader, "Storage (s3, filesystem, sftp)", config.BackupStorageS3)
 	if err != nil {
 		return err
 	}
-	bucket, err := promptNonEmpty(reader, "Bucket")
-	if err != nil {
-		return err
-	}
-	region, err := promptWithDefault(reader, "Region", "us-east-1")
-	if err != nil {
-		return err
-	}
-	accessKey, err := promptNonEmpty(reader, "Access Key")
-	if err != nil {
-		return err
+	backupCfg := config.BackupConfig{Storage: kind}
+	switch kind {
+	case config.BackupStorageS3:
+		backupCfg.S3, err = promptS3Storage(reader)
+	case config.BackupStorageFilesystem:
+		backupCfg.Filesystem.Path, err = promptNonEmpty(reader, "Backup directory")
+	case config.BackupStorageSFTP:
+		backupCfg.SFTP, err = promptSFTPStorage(reader)
+	default:
+		return fmt.Errorf("unknown storage %q", kind)
 	}
-	secretKey, err := promptNonEmpty(reader, "Secret Key")
 	if err != nil {
 		return err
 	}
-	usePathStyle, err := promptYesNo(reader, "Use path-style S3", true)
-	if err != nil {
-		return err
-	}
-	insecureSkip, err := promptYesNo(reader, "Skip TLS verification", false)
-	if err != nil {
+	if backupCfg.Encryption, backupCfg.Signing, err = promptArchiveKeys(reader); err != nil {
 		return err
 	}
 
-	s3cfg := config.BackupS3Config{
-		Endpoint:              endpoint,
-		Bucket:                bucket,
-		Region:                region,
-		AccessKey:             accessKey,
-		SecretKey:             secretKey,
-		UsePathStyle:          usePathStyle,
-		InsecureSkipTLSVerify: insecureSkip,
-	}
-
-	storage, err := backup.NewS3Storage(s3cfg)
+	storage, err := backup.NewStorage(backupCfg)
 	if err != nil {
 		return err
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	if err := storage.Test(ctx, stationID); err != nil {
+	if err := backup.TestStorage(ctx, storage, stationID); err != nil {
 		return fmt.Errorf("storage test failed: %w", err)
 	}
 	fmt.Println("Connection test succeeded.")
 
-	backups, err := backup.ListBackupsWithConfig(ctx, s3cfg, stationID)
+	backups, err := backup.ListBackupsWithConfig(ctx, backupCfg, stationID)
 	if err != nil {
 		return err
 	}
@@ -1042,15 +1085,116 @@ func runInteractiveRestore(configPath string) error {
 		return fmt.Errorf("confirmation station ID did not match")
 	}
 
-	restoreCtx, restoreCancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer restoreCancel()
-	if err := backup.RestoreNow(restoreCtx, configPath, s3cfg, stationID, selected.Key); err != nil {
+	if err := restoreWithOverride(reader, configPath, backupCfg, stationID, selected.Key); err != nil {
 		return err
 	}
 	fmt.Println("Restore completed successfully. Launching ShinGo Edge...")
 	return nil
 }
 
+// promptArchiveKeys asks for what opens and verifies the archives: the
+// plant's passphrase or private key, and the signing key of the edge that
+// wrote them. All may be blank for archives that are neither.
+func promptArchiveKeys(reader *bufio.Reader) (config.BackupEncryptionConfig, config.BackupSigningConfig, error) {
+	var enc config.BackupEncryptionConfig
+	var signing config.BackupSigningConfig
+	passphrase, err := promptWithDefault(reader, "Archive passphrase (blank if none)", "")
+	if err != nil {
+		return enc, signing, err
+	}
+	if passphrase != "" {
+		enc.Keys = append(enc.Keys, config.BackupKeyConfig{ID: "restore-passphrase", Passphrase: passphrase})
+	}
+	keyFile, err := promptWithDefault(reader, "Archive private key file (blank if none)", "")
+	if err != nil {
+		return enc, signing, err
+	}
+	if keyFile != "" {
+		enc.Keys = append(enc.Keys, config.BackupKeyConfig{ID: "restore-key-file", PrivateKeyFile: keyFile})
+	}
+	trusted, err := promptWithDefault(reader, "Trusted signing key (ssh-ed25519 line or SHA256:... fingerprint)", "")
+	if err != nil {
+		return enc, signing, err
+	}
+	if trusted != "" {
+		signing.TrustedKeys = []string{trusted}
+	}
+	return enc, signing, nil
+}
+
+// restoreWithOverride restores the archive, and when its signature does not
+// verify, says why and offers to restore it anyway.
+func restoreWithOverride(reader *bufio.Reader, configPath string, backupCfg config.BackupConfig, stationID, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	err := backup.RestoreNow(ctx, configPath, backupCfg, stationID, key)
+	if !errors.Is(err, backup.ErrUnverified) {
+		return err
+	}
+	fmt.Printf("Refusing archive: %v\n", err)
+	anyway, perr := promptYesNo(reader, "Restore this unverified archive anyway", false)
+	if perr != nil {
+		return perr
+	}
+	if !anyway {
+		return err
+	}
+	backupCfg.Signing.AllowUnsignedRestore = true
+	return backup.RestoreNow(ctx, configPath, backupCfg, stationID, key)
+}
+
+func promptS3Storage(reader *bufio.Reader) (config.BackupS3Config, error) {
+	var cfg config.BackupS3Config
+	var err error
+	if cfg.Endpoint, err = promptNonEmpty(reader, "S3 Endpoint URL"); err != nil {
+		return cfg, err
+	}
+	if cfg.Bucket, err = promptNonEmpty(reader, "Bucket"); err != nil {
+		return cfg, err
+	}
+	if cfg.Region, err = promptWithDefault(reader, "Region", "us-east-1"); err != nil {
+		return cfg, err
+	}
+	if cfg.AccessKey, err = promptNonEmpty(reader, "Access Key"); err != nil {
+		return cfg, err
+	}
+	if cfg.SecretKey, err = promptNonEmpty(reader, "Secret Key"); err != nil {
+		return cfg, err
+	}
+	if cfg.UsePathStyle, err = promptYesNo(reader, "Use path-style S3", true); err != nil {
+		return cfg, err
+	}
+	cfg.InsecureSkipTLSVerify, err = promptYesNo(reader, "Skip TLS verification", false)
+	return cfg, err
+}
+
+// promptSFTPStorage asks for an SFTP target. An empty host key fails the
+// connection test with the key the server offered, which can then be
+// checked and entered on a second run.
+func promptSFTPStorage(reader *bufio.Reader) (config.BackupSFTPConfig, error) {
+	var cfg config.BackupSFTPConfig
+	var err error
+	if cfg.Host, err = promptNonEmpty(reader, "SFTP host[:port]"); err != nil {
+		return cfg, err
+	}
+	if cfg.User, err = promptNonEmpty(reader, "User"); err != nil {
+		return cfg, err
+	}
+	if cfg.PrivateKeyFile, err = promptWithDefault(reader, "Private key file (blank for password)", ""); err != nil {
+		return cfg, err
+	}
+	if cfg.PrivateKeyFile == "" {
+		if cfg.Password, err = promptNonEmpty(reader, "Password"); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.HostKey, err = promptWithDefault(reader, "Host key (SHA256:... fingerprint)", ""); err != nil {
+		return cfg, err
+	}
+	cfg.Path, err = promptNonEmpty(reader, "Remote directory")
+	return cfg, err
+}
+
 // ── Prompt helpers ──────────────────────────────────────────────────
 
 func promptNonEmpty(reader *bufio.Reader, label string) (string, error) {
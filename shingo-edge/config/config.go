@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -45,6 +46,7 @@ type Config struct {
 	PollRate     time.Duration `yaml:"poll_rate"`
 
 	Timezone string `yaml:"timezone"` // IANA timezone for shift/hourly bucketing (e.g. "America/Chicago")
+	Language string `yaml:"language"` // Operator HMI default language ("en", "es", "ja"); a station or user may override it
 
 	CoreAPI   string          `yaml:"core_api"` // Core HTTP base URL (e.g. "http://192.168.1.10:8080")
 	WarLink   WarLinkConfig   `yaml:"warlink"`
@@ -54,6 +56,26 @@ type Config struct {
 	Backup    BackupConfig    `yaml:"backup"`
 	Sim       SimConfig       `yaml:"sim"`
 
+	// CoreCAFile is a PEM bundle the Core API client trusts on top of the
+	// system roots — Core's plant CA when Core serves HTTPS with a generated
+	// certificate. Empty falls back to web.tls.ca_file, which is that same CA
+	// on an edge that signs its own certificate with it.
+	CoreCAFile string `yaml:"core_ca_file"`
+
+	// PLCSources are PLCs this edge talks to itself instead of through
+	// WarLink. Each shows up in the PLC list under its Name next to the ones
+	// WarLink reports, and a name listed here is never taken from WarLink.
+	PLCSources []PLCSourceConfig `yaml:"plc_sources"`
+
+	// PLCSignals drives stack lights and HMI bits from material state:
+	// request pending, robot en route, bin delivered, supply refused,
+	// changeover blocked.
+	PLCSignals PLCSignalsConfig `yaml:"plc_signals"`
+
+	// PLCTriggers let a pushbutton or machine-state bit stand in for an
+	// operator's tap: each fires one HMI action on one process node.
+	PLCTriggers []PLCTrigger `yaml:"plc_triggers"`
+
 	// LoadersMultiWindow — DEPRECATED. The setting moved onto the loader itself:
 	// Core's bin_loaders.funnel_windows, synced down and read by
 	// engine.multiWindowFor. A plant-wide key could only answer for every loader
@@ -88,6 +110,25 @@ type Config struct {
 	UOPAccumulatingCTAAfter time.Duration `yaml:"uop_accumulating_cta_after"`
 
 	Demand DemandConfig `yaml:"demand"`
+
+	// Schedule lets the production schedule start changeovers: pre-staging
+	// the next style's material ahead of the planned cut, and arming the
+	// changeover when the running style reaches its planned quantity.
+	Schedule ScheduleConfig `yaml:"schedule"`
+}
+
+// ScheduleConfig tunes the production-schedule planner. With Enabled false
+// the schedule is still imported, tracked and reported against — it just
+// never starts a changeover on its own.
+type ScheduleConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often each process's next run is re-planned.
+	Interval time.Duration `yaml:"interval"`
+	// PrestageMargin is added to Core's transit estimate for the slowest
+	// supply route: the time a robot takes to be assigned and pick up, which
+	// the transit figure does not include, plus slack for the cut arriving
+	// early.
+	PrestageMargin time.Duration `yaml:"prestage_margin"`
 }
 
 // DefaultUOPAccumulatingCTAAfter is the fallback for
@@ -177,6 +218,162 @@ type WarLinkConfig struct {
 	Mode     string        `yaml:"mode"        json:"mode"` // "sse" (default) or "poll"
 }
 
+// PLC source drivers.
+const (
+	PLCDriverOPCUA  = "opcua"
+	PLCDriverModbus = "modbus"
+)
+
+// PLCSourceConfig is one directly connected PLC.
+type PLCSourceConfig struct {
+	Name     string `yaml:"name"     json:"name"`
+	Driver   string `yaml:"driver"   json:"driver"`   // "opcua" or "modbus"
+	Endpoint string `yaml:"endpoint" json:"endpoint"` // e.g. "opc.tcp://10.0.4.20:4840", "10.0.4.21:502"
+	// Root is the node whose subtree holds the tags, e.g. "ns=2;s=Line1".
+	// Tags are named by browse path below it ("Press.Count"). Empty is the
+	// Objects folder. OPC UA only.
+	Root string `yaml:"root" json:"root"`
+	// PublishInterval is how often the PLC reports values: the OPC UA
+	// subscription's publishing interval, or the Modbus poll rate. Zero is
+	// DefaultPublishInterval.
+	PublishInterval time.Duration `yaml:"publish_interval" json:"publish_interval"`
+	// UnitID is the Modbus unit identifier. A device on the network
+	// directly mostly ignores it; behind a serial gateway it is the slave
+	// address. Modbus only.
+	UnitID uint8 `yaml:"unit_id" json:"unit_id"`
+	// Registers maps Modbus addresses to tags. Modbus has no names to
+	// browse, so these are the PLC's only tags: a reporting point on this
+	// PLC names one by Tag. Modbus only.
+	Registers []ModbusRegister `yaml:"registers" json:"registers"`
+}
+
+// Modbus register tables.
+const (
+	ModbusHolding  = "holding"  // 16-bit, read/write (function 3, 6, 16)
+	ModbusInput    = "input"    // 16-bit, read-only (function 4)
+	ModbusCoil     = "coil"     // 1-bit, read/write (function 1, 5)
+	ModbusDiscrete = "discrete" // 1-bit, read-only (function 2)
+)
+
+// ModbusRegister is one tag's place in a Modbus device.
+type ModbusRegister struct {
+	Tag string `yaml:"tag" json:"tag"`
+	// Table is ModbusHolding (the default), ModbusInput, ModbusCoil or
+	// ModbusDiscrete.
+	Table string `yaml:"table" json:"table"`
+	// Address is the zero-based protocol address. Manuals that number
+	// holding registers from 40001 are one-based and table-prefixed:
+	// 40001 is holding 0.
+	Address uint16 `yaml:"address" json:"address"`
+	// Width is 16, 32 or 64 bits across one, two or four registers; zero
+	// is 16. Bit tables ignore it.
+	Width int `yaml:"width" json:"width"`
+	// Signed reads the value as two's complement.
+	Signed bool `yaml:"signed" json:"signed"`
+	// WordOrder is "big" (the default: the first register holds the high
+	// word, per the Modbus convention) or "little" for devices that put
+	// the low word first.
+	WordOrder string `yaml:"word_order" json:"word_order"`
+	// Scale multiplies the raw value; zero is 1. A counter that counts
+	// pairs is scale 2. A whole-number scale keeps the value an integer.
+	Scale float64 `yaml:"scale" json:"scale"`
+	// Writable allows writes to a holding register or coil.
+	Writable bool `yaml:"writable" json:"writable"`
+}
+
+// DefaultPublishInterval is the subscription rate for a PLC source that does
+// not set one: half the counter poll rate, so a poll never reads a value more
+// than one publish old.
+const DefaultPublishInterval = 500 * time.Millisecond
+
+// Interval is the source's publishing interval with the default applied.
+func (s PLCSourceConfig) Interval() time.Duration {
+	if s.PublishInterval > 0 {
+		return s.PublishInterval
+	}
+	return DefaultPublishInterval
+}
+
+// PLCSignalsConfig binds the edge's material state to PLC tag writes.
+type PLCSignalsConfig struct {
+	// Interval is how often every binding is re-evaluated. Order and
+	// refusal events evaluate sooner; the interval is the backstop.
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	// Debounce is how long a new value must hold before it is written, so
+	// an order passing through dispatched on its way to in_transit does
+	// not blink the light. It also bounds how long a read-back may
+	// disagree with the commanded value before the write is repeated.
+	Debounce  time.Duration      `yaml:"debounce" json:"debounce"`
+	Heartbeat PLCHeartbeatConfig `yaml:"heartbeat" json:"heartbeat"`
+	Bindings  []PLCSignalBinding `yaml:"bindings" json:"bindings"`
+}
+
+// PLCHeartbeatConfig is a watchdog tag the edge toggles between 1 and 0 so
+// the PLC can tell a frozen light from a quiet line. Empty Tag is off.
+type PLCHeartbeatConfig struct {
+	PLC      string        `yaml:"plc" json:"plc"`
+	Tag      string        `yaml:"tag" json:"tag"`
+	Interval time.Duration `yaml:"interval" json:"interval"`
+}
+
+// PLCSignalBinding writes one signal for a process node, or for every node
+// of an operator station, to one tag. Set exactly one of Node and Station.
+type PLCSignalBinding struct {
+	Node    string `yaml:"node" json:"node"`       // process node name or core node name
+	Station string `yaml:"station" json:"station"` // operator station name
+	// Signal is "state", written through Values, or one of "requested",
+	// "en_route", "delivered", "refused" and "blocked", written as
+	// Active or Inactive.
+	Signal string `yaml:"signal" json:"signal"`
+	PLC    string `yaml:"plc" json:"plc"`
+	Tag    string `yaml:"tag" json:"tag"`
+	// Active and Inactive are a flag signal's values; nil is 1 and 0.
+	Active   any `yaml:"active" json:"active"`
+	Inactive any `yaml:"inactive" json:"inactive"`
+	// Values maps each state ("idle", "requested", "en_route",
+	// "delivered", "refused", "blocked") to its value; a state left out
+	// writes its position in that list, idle 0 to blocked 5.
+	Values map[string]any `yaml:"values" json:"values"`
+}
+
+// PLC trigger actions: the operator HMI's material buttons.
+const (
+	PLCActionRequest      = "request"       // request material (the REQUEST button)
+	PLCActionRelease      = "release"       // release the bin as empty
+	PLCActionRequestEmpty = "request_empty" // bring an empty to a produce node
+	PLCActionRequestFull  = "request_full"  // bring a full bin to a manual_swap node
+	PLCActionPushEmpty    = "push_empty"    // send an unloader's empty out
+)
+
+// PLC trigger modes.
+const (
+	PLCTriggerEdge  = "edge"  // fire once when the tag goes from zero to non-zero
+	PLCTriggerLevel = "level" // fire once the tag has stayed non-zero for Debounce
+	PLCTriggerCount = "count" // fire each time the tag has counted up by Count
+)
+
+// PLCTrigger binds one PLC tag to one operator action on one process node.
+type PLCTrigger struct {
+	Node   string `yaml:"node" json:"node"`     // process node name or core node name
+	Action string `yaml:"action" json:"action"` // one of the PLCAction constants
+	PLC    string `yaml:"plc" json:"plc"`
+	Tag    string `yaml:"tag" json:"tag"`
+	// Mode is PLCTriggerEdge (the default), PLCTriggerLevel or
+	// PLCTriggerCount.
+	Mode string `yaml:"mode" json:"mode"`
+	// Debounce is how long a level must hold before it fires; zero is 2s.
+	// A level that fired stays quiet until the tag drops; one an interlock
+	// refused tries again every Debounce while it holds.
+	Debounce time.Duration `yaml:"debounce" json:"debounce"`
+	// Count is how far a count-mode tag must advance per firing; zero is 1.
+	// A count an interlock refused keeps its parts and tries again every
+	// Debounce until the action goes through.
+	Count int64 `yaml:"count" json:"count"`
+	// PayloadCode is the part for request_empty and request_full; empty is
+	// the node's active claim's part.
+	PayloadCode string `yaml:"payload_code" json:"payload_code"`
+}
+
 // WebConfig defines the web server settings.
 type WebConfig struct {
 	Host string `yaml:"host"`
@@ -186,6 +383,27 @@ type WebConfig struct {
 	// credential into git and make the snapshot differ from itself every run.
 	SessionSecret string `yaml:"session_secret" snapshot:"secret"`
 	AutoConfirm   bool   `yaml:"auto_confirm"`
+	// TLS serves the HMI over HTTPS on Port. While it is on, the session
+	// cookie is marked Secure and every response carries HSTS.
+	TLS WebTLSConfig `yaml:"tls"`
+}
+
+// WebTLSConfig is where the HMI certificate comes from: CertFile/KeyFile from
+// the plant PKI, or SelfSigned to generate one under Dir (see
+// shingo/protocol/webtls). Point CAFile/CAKeyFile at a copy of Core's plant CA
+// and this edge's certificate is trusted wherever Core's already is.
+type WebTLSConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	CertFile   string   `yaml:"cert_file"`
+	KeyFile    string   `yaml:"key_file"`
+	SelfSigned bool     `yaml:"self_signed"`
+	Dir        string   `yaml:"dir"`
+	CAFile     string   `yaml:"ca_file"`
+	CAKeyFile  string   `yaml:"ca_key_file"`
+	Hosts      []string `yaml:"hosts"`
+	// RedirectPort, when non-zero, listens for plain HTTP there and
+	// redirects every request to HTTPS.
+	RedirectPort int `yaml:"redirect_port"`
 }
 
 // MessagingConfig defines the messaging backend.
@@ -228,6 +446,72 @@ type KafkaConfig struct {
 	// reader has to disprove, and because a rollback to a pre-v66 binary would
 	// re-arm it.
 	GroupID string `yaml:"-"`
+
+	// DialTimeout bounds one broker dial, TLS and SASL handshakes included —
+	// the writer's, the reader's and the config page's broker test. Zero
+	// means the 5s default. Core has the same setting.
+	DialTimeout time.Duration `yaml:"dial_timeout"`
+
+	// TLS and SASL apply to the writer, the reader and the broker test on the
+	// config page. Same shape as Core's (shingo-core/config KafkaConfig), so
+	// one broker listener serves both.
+	TLS  KafkaTLSConfig  `yaml:"tls"`
+	SASL KafkaSASLConfig `yaml:"sasl"`
+}
+
+// SASL mechanisms accepted in KafkaSASLConfig.Mechanism.
+const (
+	KafkaSASLPlain       = "plain"
+	KafkaSASLSCRAMSHA256 = "scram-sha-256"
+	KafkaSASLSCRAMSHA512 = "scram-sha-512"
+)
+
+// KafkaTLSConfig encrypts broker connections. CAFile empty means the system
+// roots; CertFile and KeyFile together are a client certificate.
+type KafkaTLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"` // overrides the name checked against the broker certificate
+	// InsecureSkipVerify accepts any broker certificate. Commissioning only.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// KafkaSASLConfig authenticates to the broker. Mechanism empty disables SASL.
+type KafkaSASLConfig struct {
+	Mechanism string `yaml:"mechanism"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password" snapshot:"secret"`
+}
+
+// DialTimeoutOr returns the effective dial timeout: the configured value, or
+// the 5s default when zero.
+func (k KafkaConfig) DialTimeoutOr() time.Duration {
+	if k.DialTimeout > 0 {
+		return k.DialTimeout
+	}
+	return 5 * time.Second
+}
+
+// Validate reports a TLS or SASL setting that cannot produce a working
+// connection.
+func (k KafkaConfig) Validate() error {
+	if k.TLS.Enabled && (k.TLS.CertFile == "") != (k.TLS.KeyFile == "") {
+		return fmt.Errorf("kafka tls: cert_file and key_file must be set together")
+	}
+	switch k.SASL.Mechanism {
+	case "":
+		return nil
+	case KafkaSASLPlain, KafkaSASLSCRAMSHA256, KafkaSASLSCRAMSHA512:
+	default:
+		return fmt.Errorf("kafka sasl: unknown mechanism %q (want %s, %s or %s)",
+			k.SASL.Mechanism, KafkaSASLPlain, KafkaSASLSCRAMSHA256, KafkaSASLSCRAMSHA512)
+	}
+	if k.SASL.Username == "" {
+		return fmt.Errorf("kafka sasl: username is required for %s", k.SASL.Mechanism)
+	}
+	return nil
 }
 
 // CounterConfig defines counter anomaly thresholds.
@@ -235,15 +519,29 @@ type CounterConfig struct {
 	JumpThreshold int64 `yaml:"jump_threshold"`
 }
 
+// Backup storage backends, chosen by BackupConfig.Storage.
+const (
+	BackupStorageS3         = "s3"
+	BackupStorageFilesystem = "filesystem"
+	BackupStorageSFTP       = "sftp"
+)
+
 // BackupConfig defines edge backup behavior and storage.
 type BackupConfig struct {
-	Enabled          bool           `yaml:"enabled" json:"enabled"`
-	ScheduleInterval time.Duration  `yaml:"schedule_interval" json:"schedule_interval"`
-	KeepHourly       int            `yaml:"keep_hourly" json:"keep_hourly"`
-	KeepDaily        int            `yaml:"keep_daily" json:"keep_daily"`
-	KeepWeekly       int            `yaml:"keep_weekly" json:"keep_weekly"`
-	KeepMonthly      int            `yaml:"keep_monthly" json:"keep_monthly"`
-	S3               BackupS3Config `yaml:"s3" json:"s3"`
+	Enabled          bool          `yaml:"enabled" json:"enabled"`
+	ScheduleInterval time.Duration `yaml:"schedule_interval" json:"schedule_interval"`
+	KeepHourly       int           `yaml:"keep_hourly" json:"keep_hourly"`
+	KeepDaily        int           `yaml:"keep_daily" json:"keep_daily"`
+	KeepWeekly       int           `yaml:"keep_weekly" json:"keep_weekly"`
+	KeepMonthly      int           `yaml:"keep_monthly" json:"keep_monthly"`
+	// Storage picks the backend: s3, filesystem or sftp. Empty is s3, which
+	// is what every config written before the other two existed meant.
+	Storage    string                 `yaml:"storage" json:"storage"`
+	S3         BackupS3Config         `yaml:"s3" json:"s3"`
+	Filesystem BackupFilesystemConfig `yaml:"filesystem" json:"filesystem"`
+	SFTP       BackupSFTPConfig       `yaml:"sftp" json:"sftp"`
+	Encryption BackupEncryptionConfig `yaml:"encryption" json:"encryption"`
+	Signing    BackupSigningConfig    `yaml:"signing" json:"signing"`
 }
 
 // BackupS3Config defines an S3-compatible storage target.
@@ -257,6 +555,59 @@ type BackupS3Config struct {
 	InsecureSkipTLSVerify bool   `yaml:"insecure_skip_tls_verify" json:"insecure_skip_tls_verify"`
 }
 
+// BackupFilesystemConfig defines a directory target: a local disk or an NFS
+// or SMB mount. The directory must already exist; it is never created, so an
+// unmounted share fails the backup instead of filling the root disk.
+type BackupFilesystemConfig struct {
+	Path string `yaml:"path" json:"path"`
+}
+
+// BackupSFTPConfig defines an SFTP target. HostKey pins the server's key,
+// either as an authorized_keys line or a SHA256: fingerprint. Left empty, the
+// connection test fails and names the key the server offered.
+type BackupSFTPConfig struct {
+	Host                  string `yaml:"host" json:"host"` // host or host:port, port 22 by default
+	User                  string `yaml:"user" json:"user"`
+	Password              string `yaml:"password" json:"password"`
+	PrivateKeyFile        string `yaml:"private_key_file" json:"private_key_file"`
+	HostKey               string `yaml:"host_key" json:"host_key"`
+	InsecureIgnoreHostKey bool   `yaml:"insecure_ignore_host_key" json:"insecure_ignore_host_key"`
+	Path                  string `yaml:"path" json:"path"` // remote directory; must exist
+}
+
+// BackupEncryptionConfig encrypts archives before they leave the edge. The
+// first key seals new archives; every key is tried on restore, so rotating
+// means putting the new key first and keeping the old one until the last
+// archive sealed to it has aged out of retention.
+type BackupEncryptionConfig struct {
+	Enabled bool              `yaml:"enabled" json:"enabled"`
+	Keys    []BackupKeyConfig `yaml:"keys" json:"keys"`
+}
+
+// BackupKeyConfig is one plant-managed archive key: a passphrase, or an
+// ed25519 key pair in SSH format. PublicKey is enough to seal; the private
+// key file only has to be on the edge that restores, so it can stay offline
+// until one is needed.
+type BackupKeyConfig struct {
+	ID             string `yaml:"id" json:"id"`
+	Passphrase     string `yaml:"passphrase" json:"passphrase" snapshot:"secret"`
+	PublicKey      string `yaml:"public_key" json:"public_key"` // authorized_keys line
+	PrivateKeyFile string `yaml:"private_key_file" json:"private_key_file"`
+}
+
+// BackupSigningConfig controls archive signatures. Every archive is signed
+// with the edge's ed25519 key; KeyFile empty means a key generated on first
+// backup under the config directory. Restore accepts archives signed by that
+// key or any in TrustedKeys (authorized_keys lines or SHA256: fingerprints),
+// which is where a replaced edge's key or a retired signing key goes.
+type BackupSigningConfig struct {
+	KeyFile     string   `yaml:"key_file" json:"key_file"`
+	TrustedKeys []string `yaml:"trusted_keys" json:"trusted_keys"`
+	// AllowUnsignedRestore restores archives that are unsigned, signed by an
+	// unknown key, or fail verification. Archives from before signing need it.
+	AllowUnsignedRestore bool `yaml:"allow_unsigned_restore" json:"allow_unsigned_restore"`
+}
+
 // SimConfig configures the local-dev production/operator simulation (edge side).
 // Sim code is behind //go:build sim AND requires SHINGO_ALLOW_SIM=1 at runtime;
 // this struct only carries the knobs. See implementation-brief.md.
@@ -271,6 +622,20 @@ type SimConfig struct {
 	Downtime   SimDowntimeConfig  `yaml:"downtime"`
 	Processes  []SimProcessConfig `yaml:"processes"`
 	Operators  SimOperatorsConfig `yaml:"operators"`
+	Tags       []SimTagConfig     `yaml:"tags"` // simulated pushbuttons and state bits for plc_triggers
+}
+
+// SimTagConfig is a simulated PLC tag on the fake WarLink: a pushbutton or a
+// machine-state bit for a plc_triggers entry to watch. Writes to it stick, so
+// POST /api/sim/tags (or a plc_signals binding) can set it too.
+type SimTagConfig struct {
+	PLCName string `yaml:"plc_name"`
+	TagName string `yaml:"tag_name"`
+	Value   int64  `yaml:"value"` // initial value
+	// Every, when set, pulses the tag to 1 for Hold (default 1s) once per
+	// Every on the sim clock — an operator pressing the button on a rhythm.
+	Every time.Duration `yaml:"every"`
+	Hold  time.Duration `yaml:"hold"`
 }
 
 // SimCalendarConfig defines the production calendar for the sim (G14).
@@ -366,6 +731,7 @@ func Defaults() *Config {
 			Host:          "0.0.0.0",
 			Port:          8081,
 			SessionSecret: generateSecret(),
+			TLS:           WebTLSConfig{Dir: "tls"},
 		},
 		Messaging: MessagingConfig{
 			DispatchTopic:       "shingo.dispatch",
@@ -385,11 +751,23 @@ func Defaults() *Config {
 			KeepDaily:        14,
 			KeepWeekly:       8,
 			KeepMonthly:      12,
+			Storage:          BackupStorageS3,
 			S3: BackupS3Config{
 				Region:       "us-east-1",
 				UsePathStyle: true,
 			},
 		},
+		Schedule: ScheduleConfig{
+			Interval:       30 * time.Second,
+			PrestageMargin: 5 * time.Minute,
+		},
+		PLCSignals: PLCSignalsConfig{
+			Interval: 2 * time.Second,
+			Debounce: time.Second,
+			Heartbeat: PLCHeartbeatConfig{
+				Interval: time.Second,
+			},
+		},
 		Sim: SimConfig{
 			// Enabled false by default. Sim operator timings default here so a dev
 			// YAML can enable sim without spelling out every knob; per-process
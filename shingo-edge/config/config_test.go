@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadKafkaSecurity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shingoedge.yaml")
+	yml := `messaging:
+  kafka:
+    brokers: [kafka.plant.local:9093]
+    tls:
+      enabled: true
+      ca_file: /etc/shingo/kafka-ca.pem
+      server_name: kafka.plant.local
+    sasl:
+      mechanism: scram-sha-256
+      username: line-1
+      password: hunter2
+`
+	if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	k := cfg.Messaging.Kafka
+	if want := (KafkaTLSConfig{Enabled: true, CAFile: "/etc/shingo/kafka-ca.pem", ServerName: "kafka.plant.local"}); k.TLS != want {
+		t.Errorf("tls = %+v, want %+v", k.TLS, want)
+	}
+	if want := (KafkaSASLConfig{Mechanism: KafkaSASLSCRAMSHA256, Username: "line-1", Password: "hunter2"}); k.SASL != want {
+		t.Errorf("sasl = %+v, want %+v", k.SASL, want)
+	}
+	if err := k.Validate(); err != nil {
+		t.Errorf("validate: %v", err)
+	}
+
+	k.SASL.Mechanism = "SCRAM-SHA-256"
+	if err := k.Validate(); err == nil || !strings.Contains(err.Error(), "unknown mechanism") {
+		t.Errorf("upper-case mechanism: %v", err)
+	}
+	k.SASL.Mechanism, k.SASL.Username = KafkaSASLPlain, ""
+	if err := k.Validate(); err == nil {
+		t.Error("SASL without a username validated")
+	}
+	k.SASL = KafkaSASLConfig{}
+	k.TLS.KeyFile = "/etc/shingo/edge.key"
+	if err := k.Validate(); err == nil {
+		t.Error("client key without a certificate validated")
+	}
+}
+
+func TestLoadWebTLSAndCoreCA(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shingoedge.yaml")
+	yml := `core_api: https://core.plant.local:8083
+core_ca_file: /etc/shingo/plant-ca.pem
+web:
+  tls:
+    enabled: true
+    self_signed: true
+    ca_file: /etc/shingo/plant-ca.pem
+    ca_key_file: /etc/shingo/plant-ca-key.pem
+    redirect_port: 80
+`
+	if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	w := cfg.Web.TLS
+	if !w.Enabled || !w.SelfSigned || w.CAFile != "/etc/shingo/plant-ca.pem" || w.RedirectPort != 80 {
+		t.Errorf("web tls = %+v", w)
+	}
+	if w.Dir != "tls" {
+		t.Errorf("dir = %q, want the default kept", w.Dir)
+	}
+	if cfg.CoreCAFile != "/etc/shingo/plant-ca.pem" {
+		t.Errorf("core_ca_file = %q", cfg.CoreCAFile)
+	}
+	if Defaults().Web.TLS.Enabled {
+		t.Error("web TLS must default off")
+	}
+}
@@ -93,6 +93,10 @@ func RenderDefaults() string {
 	// ask" and means the opposite.
 	b.WriteString(fmt.Sprintf("uop_accumulating_cta_after.resolved = %v\n",
 		cfg.UOPAccumulatingCTADelay()))
+	// No source ships, so the field dump shows an empty list; this is what a
+	// source that leaves publish_interval out gets.
+	b.WriteString(fmt.Sprintf("plc_sources.publish_interval.resolved = %v\n",
+		PLCSourceConfig{}.Interval()))
 	return b.String()
 }
 
@@ -1,6 +1,11 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"shingo/protocol/i18n"
+)
 
 // Changeover is one in-flight (or completed) style change for a
 // Process. Tracks who called it, the from/to styles, and the
@@ -123,11 +128,16 @@ func IsNodeTaskStateTerminal(state NodeTaskState, situation string) bool {
 // exists so the panel can distinguish "this will clear itself" from "this
 // needs you" once the gate grows a soft conjunct; today every blocker is hard.
 // Do not grow a second code path off it.
+//
+// Msg is the same sentence as a catalog message, so a screen in another
+// language renders it in that language. Reason stays the English rendering of
+// Msg — it is what the logs and the click-time error have always carried.
 type Blocker struct {
-	Reason   string `json:"reason"`
-	NodeName string `json:"node_name,omitempty"`
-	OrderID  int64  `json:"order_id,omitempty"`
-	Hard     bool   `json:"hard"`
+	Reason   string       `json:"reason"`
+	NodeName string       `json:"node_name,omitempty"`
+	OrderID  int64        `json:"order_id,omitempty"`
+	Hard     bool         `json:"hard"`
+	Msg      i18n.Message `json:"-"`
 }
 
 // BlockersToReasons projects blockers back to the flat sentence list the
@@ -149,3 +159,14 @@ func BlockersToReasons(blockers []Blocker) []string {
 	}
 	return reasons
 }
+
+// CutoverBlockedError is the refusal a cutover attempt gets while the gate has
+// blockers. Error() is the flat sentence BlockersToReasons has always produced;
+// the blockers ride along so an HMI can render the refusal in its own language.
+type CutoverBlockedError struct {
+	Blockers []Blocker
+}
+
+func (e *CutoverBlockedError) Error() string {
+	return "cannot cutover: " + strings.Join(BlockersToReasons(e.Blockers), "; ")
+}
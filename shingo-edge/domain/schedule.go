@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Schedule run statuses.
+const (
+	ScheduleRunPlanned = "planned"
+	ScheduleRunActive  = "active"
+	ScheduleRunDone    = "done"
+	ScheduleRunSkipped = "skipped"
+)
+
+// ScheduleRun is one row of a process's production schedule: a style the
+// process is planned to make, and what actually happened to it.
+type ScheduleRun struct {
+	ID           int64      `json:"id"`
+	ProcessID    int64      `json:"process_id"`
+	StyleID      int64      `json:"style_id"`
+	StyleName    string     `json:"style_name"`
+	Seq          int        `json:"seq"`
+	PlannedQty   int64      `json:"planned_qty"`
+	PlannedStart *time.Time `json:"planned_start,omitempty"`
+	PlannedEnd   *time.Time `json:"planned_end,omitempty"`
+	Status       string     `json:"status"`
+	Source       string     `json:"source"`
+	ExternalRef  string     `json:"external_ref,omitempty"`
+	ProducedQty  int64      `json:"produced_qty"`
+	ActualStart  *time.Time `json:"actual_start,omitempty"`
+	ActualEnd    *time.Time `json:"actual_end,omitempty"`
+	PrestagedAt  *time.Time `json:"prestaged_at,omitempty"`
+	ArmedAt      *time.Time `json:"armed_at,omitempty"`
+	ChangeoverID *int64     `json:"changeover_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// QuantityReached reports whether a quantity-planned run has made its number.
+// A run planned by time alone never reaches a quantity.
+func (r ScheduleRun) QuantityReached() bool {
+	return r.PlannedQty > 0 && r.ProducedQty >= r.PlannedQty
+}
+
+// ScheduleRunInput is one run as an importer hands it over.
+type ScheduleRunInput struct {
+	StyleID      int64
+	PlannedQty   int64
+	PlannedStart *time.Time
+	PlannedEnd   *time.Time
+	ExternalRef  string
+}
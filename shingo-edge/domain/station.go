@@ -16,6 +16,7 @@ type Station struct {
 	ControllerNodeID string     `json:"controller_node_id"`
 	DeviceMode       string     `json:"device_mode"`
 	Enabled          bool       `json:"enabled"`
+	Language         string     `json:"language"` // screen language; empty = plant default
 	HealthStatus     string     `json:"health_status"`
 	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
 	CreatedAt        time.Time  `json:"created_at"`
@@ -37,4 +38,5 @@ type StationInput struct {
 	ControllerNodeID string `json:"controller_node_id"`
 	DeviceMode       string `json:"device_mode"`
 	Enabled          bool   `json:"enabled"`
+	Language         string `json:"language"`
 }
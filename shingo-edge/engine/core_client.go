@@ -2,14 +2,18 @@ package engine
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"shingo/protocol/webtls"
 	"shingoedge/service"
 )
 
@@ -58,6 +62,45 @@ func NewCoreClient(baseURL string) *CoreClient {
 	}
 }
 
+// TrustCA verifies Core's HTTPS certificate against the PEM bundles in files
+// as well as the system roots — Core's plant CA when it serves a generated
+// certificate. Empty names are skipped, and with none left the client is
+// unchanged.
+func (c *CoreClient) TrustCA(files ...string) error {
+	if !slices.ContainsFunc(files, func(f string) bool { return f != "" }) {
+		return nil
+	}
+	pool, err := webtls.CertPool(files...)
+	if err != nil {
+		return err
+	}
+	c.http.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool},
+	}
+	return nil
+}
+
+// ProbeHealth GETs baseURL's /api/health with this client's trust, for the
+// config page's Test button: a URL typed into the form, not the saved one.
+// Nil-safe; a nil client probes with the default transport.
+func (c *CoreClient) ProbeHealth(ctx context.Context, baseURL string) (int, error) {
+	client := http.DefaultClient
+	if c != nil {
+		client = c.http
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/api/health", nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
 // SetBaseURL updates the Core API base URL (e.g. after config change).
 func (c *CoreClient) SetBaseURL(url string) {
 	c.baseURL = strings.TrimRight(url, "/")
@@ -642,3 +685,44 @@ func (c *CoreClient) ClearBin(nodeName, binTypeCode string) (*BinClearResponse,
 	}
 	return &result, nil
 }
+
+// TransitETA is one route's answer from Core's /api/telemetry/transit-eta:
+// the padded p70 transit time Core stamps operator ETAs with. RouteKnown is
+// false when Core fell back to its global figure for a route it has no
+// history on.
+type TransitETA struct {
+	Source     string `json:"source"`
+	Delivery   string `json:"delivery"`
+	Seconds    int64  `json:"seconds"`
+	RouteKnown bool   `json:"route_known"`
+}
+
+// FetchTransitETAs asks Core how long each (source, delivery) route takes a
+// robot. Unlike FetchUOPState this reports Core being unreachable as an
+// error: the schedule planner acts on the answer, and "no answer" must not
+// read as "no transit time".
+func (c *CoreClient) FetchTransitETAs(routes [][2]string) ([]TransitETA, error) {
+	if !c.Available() {
+		return nil, ErrCoreUnreachable
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	params := url.Values{}
+	for _, r := range routes {
+		params.Add("route", r[0]+","+r[1])
+	}
+	resp, err := c.http.Get(c.baseURL + "/api/telemetry/transit-eta?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("%w: fetch transit-eta: %w", ErrCoreUnreachable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: fetch transit-eta: HTTP %d", ErrCoreHTTPStatus, resp.StatusCode)
+	}
+	var out []TransitETA
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("%w: decode transit-eta: %w", ErrCoreUndecodable, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shingo/protocol/webtls"
+)
+
+// An edge pointed at Core's plant CA must reach an https Core whose
+// certificate the system roots have never heard of — and must still refuse it
+// without that CA.
+func TestCoreClient_TrustCA(t *testing.T) {
+	cert, err := webtls.Prepare(webtls.Options{Generate: true, Dir: t.TempDir(), Hosts: []string{"127.0.0.1"}})
+	if err != nil {
+		t.Fatalf("prepare certificate: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/health" {
+			http.NotFound(w, r)
+		}
+	}))
+	srv.TLS = cert.TLSConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	untrusting := NewCoreClient(srv.URL)
+	if _, err := untrusting.ProbeHealth(context.Background(), srv.URL); err == nil {
+		t.Fatal("a client without the plant CA accepted Core's certificate")
+	}
+
+	c := NewCoreClient(srv.URL)
+	if err := c.TrustCA("", cert.CAFile); err != nil {
+		t.Fatalf("TrustCA: %v", err)
+	}
+	status, err := c.ProbeHealth(context.Background(), srv.URL+"/")
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("ProbeHealth = %d, %v; want 200 through the plant CA", status, err)
+	}
+}
+
+func TestCoreClient_TrustCANoFilesKeepsDefaults(t *testing.T) {
+	c := NewCoreClient("")
+	if err := c.TrustCA("", ""); err != nil {
+		t.Fatalf("TrustCA with no files: %v", err)
+	}
+	if c.http.Transport != nil {
+		t.Error("TrustCA with no files replaced the default transport")
+	}
+	if err := c.TrustCA("/definitely/absent/ca.pem"); err == nil {
+		t.Error("TrustCA accepted a missing CA file")
+	}
+}
@@ -76,6 +76,9 @@ type Engine struct {
 	// Hopkinsville 2026-07-23). Nil until Start() (and in test fixtures that
 	// build Engine directly) — the guard nil-checks and stays inert.
 	catidMon *catidMonitor
+	// plcSignals writes material state onto stack-light and HMI tags per
+	// the plc_signals config. Nil until Start(), and when nothing is bound.
+	plcSignals *plcSignalWriter
 	// warlinkClient is the injected WarLink client (sim fake) carried from
 	// Config.Warlink to the NewManager call in Start(). Nil → real HTTP client.
 	warlinkClient plc.WarlinkClient
@@ -104,6 +107,7 @@ type Engine struct {
 	counterService    *service.CounterService
 	catalogService    *service.CatalogService
 	orderService      *service.OrderService
+	scheduleService   *service.ScheduleService
 
 	coreClient        *CoreClient
 	coreNodes         map[string]protocol.NodeInfo
@@ -141,6 +145,14 @@ type Engine struct {
 	// on load AND on the SSE-driven refresh. Empty/absent = no active alarm.
 	strandedAlarms sync.Map
 
+	// scheduleMu serialises the production-schedule planner's changeover
+	// starts against the counter path's arming, so the two cannot both start
+	// one. scheduleNotes remembers the last problem logged per run or route
+	// so a standing one is reported once, not every planner pass.
+	scheduleMu      sync.Mutex
+	scheduleNotesMu sync.Mutex
+	scheduleNotes   map[string]string
+
 	// loaderResv serializes the count→fire reservation per loader so concurrent
 	// writers (an HTTP RequestEmptyBin vs the push sweep) can't both read the
 	// same in-flight count and both fire empties —
@@ -238,6 +250,16 @@ func New(c Config) *Engine {
 		stopChan:      make(chan struct{}),
 	}
 	e.coreClient = NewCoreClient(c.AppConfig.CoreAPI)
+	coreCA := c.AppConfig.CoreCAFile
+	if coreCA == "" {
+		coreCA = c.AppConfig.Web.TLS.CAFile
+	}
+	if err := e.coreClient.TrustCA(coreCA); err != nil {
+		// Not fatal: the edge runs without Core's API, and an https Core
+		// will say "certificate signed by unknown authority" on every call
+		// — which names the fix.
+		logFn("core api: %v — verifying Core against the system roots only", err)
+	}
 	e.reconciliation = newReconciliationService(e.db)
 	e.coreSync = newCoreSyncService(e)
 	e.stationService = service.NewStationService(e.db)
@@ -257,10 +279,12 @@ func New(c Config) *Engine {
 	e.counterService = service.NewCounterService(e.db)
 	e.catalogService = service.NewCatalogService(e.db)
 	e.orderService = service.NewOrderService(e.db)
+	e.scheduleService = service.NewScheduleService(e.db)
 	e.preflightChecker = service.NewPreflightChecker(e.db, e.coreClient, e.cfg.StationID())
 	e.loaderStore = newLoaderStore(e)
 	e.homeConsolidations = make(map[string]homeConsolidation)
 	e.marketPullbacks = make(map[string]int64)
+	e.scheduleNotes = make(map[string]string)
 	return e
 }
 
@@ -308,10 +332,11 @@ func (e *Engine) Start() {
 	// the canonical empty-in path. Loaders no longer need a startup-time
 	// kick to begin pulling empties.
 
-	// Start WarLink poller and counter polling
+	// Start WarLink poller, native PLC sources and counter polling
 	if e.cfg.WarLink.Enabled {
 		e.plcMgr.StartWarLinkPoller()
 	}
+	e.plcMgr.StartSources()
 	e.plcMgr.StartPolling()
 
 	// Parked-ticks monitor (P2-C7): watches every consume node for
@@ -338,6 +363,20 @@ func (e *Engine) Start() {
 	// with a counter binding. No-op when the plant publishes no CATID tag.
 	e.startCatidMonitor()
 
+	// Material-state write-back: request pending, robot en route, bin
+	// delivered, supply refused, changeover blocked onto the PLC tags the
+	// plc_signals config binds, plus its watchdog heartbeat.
+	e.startPLCSignals()
+
+	// PLC-originated requests and releases: pushbuttons and machine-state
+	// bits bound by plc_triggers to the operator's material actions.
+	e.startPLCTriggers()
+
+	// Production-schedule planner: pre-stages the next scheduled style's
+	// material ahead of the planned cut. Arming at quantity rides the counter
+	// delta subscription instead; manual changeovers are untouched.
+	e.startSchedulePlanner()
+
 	e.startedAt = time.Now()
 	e.logFn("Engine started: namespace=%s line_id=%s", e.cfg.Namespace, e.cfg.LineID)
 }
@@ -501,6 +540,7 @@ func (e *Engine) ShiftService() *service.ShiftService           { return e.shift
 func (e *Engine) CounterService() *service.CounterService       { return e.counterService }
 func (e *Engine) CatalogService() *service.CatalogService       { return e.catalogService }
 func (e *Engine) OrderService() *service.OrderService           { return e.orderService }
+func (e *Engine) ScheduleService() *service.ScheduleService     { return e.scheduleService }
 
 // ── Core node sync ──────────────────────────────────────────────────
 
@@ -125,6 +125,13 @@ const (
 	// press reports a part matching style B or nothing). It never blocks beyond
 	// the existing request-path mismatch guard — it is a confirmation prompt.
 	EventChangeoverVerifyMismatch
+
+	// EventScheduleChangeover fires when the production schedule moves a
+	// process toward its next run: the planner started the changeover early
+	// to pre-stage material, or the running style reached its planned
+	// quantity and the changeover was armed. Notification only — cutover stays
+	// with the operator or the CATID monitor.
+	EventScheduleChangeover
 )
 
 // Event is the envelope emitted by the Engine's EventBus.
@@ -451,3 +458,18 @@ type UOPStrandedEvent struct {
 	PendingDelta int           `json:"pending_delta"`
 	Detail       string        `json:"detail"`
 }
+
+// ScheduleChangeoverEvent reports the schedule acting on a process. Reason is
+// "prestage" (started ahead of the planned cut) or "quantity" (the running run
+// made its number). ChangeoverID is zero when the changeover could not be
+// started and the operator has to.
+type ScheduleChangeoverEvent struct {
+	eventbus.PayloadBase
+	ProcessID    int64  `json:"process_id"`
+	RunID        int64  `json:"run_id"`
+	ToStyleID    int64  `json:"to_style_id"`
+	ToStyleName  string `json:"to_style_name"`
+	ChangeoverID int64  `json:"changeover_id,omitempty"`
+	Reason       string `json:"reason"`
+	Detail       string `json:"detail,omitempty"`
+}
@@ -13,10 +13,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"shingo/protocol"
+	"shingo/protocol/i18n"
 	"shingoedge/domain"
+	"shingoedge/locales"
 	"shingoedge/store/processes"
 )
 
@@ -149,10 +150,12 @@ func (e *Engine) canCompleteChangeover(changeoverID int64) (bool, []domain.Block
 	var blockers []domain.Blocker
 	for _, task := range tasks {
 		if !domain.IsNodeTaskStateTerminal(task.State, task.Situation) {
+			msg := i18n.Message{Key: "blocker.task", Args: []any{"node", task.NodeName, "state", task.State}}
 			blockers = append(blockers, domain.Blocker{
-				Reason:   fmt.Sprintf("task at node %s in %s", task.NodeName, task.State),
+				Reason:   locales.Catalog.Render(i18n.English, msg),
 				NodeName: task.NodeName,
 				Hard:     true,
+				Msg:      msg,
 			})
 		}
 	}
@@ -203,10 +206,12 @@ func (e *Engine) canCompleteChangeover(changeoverID int64) (bool, []domain.Block
 		if !e.orderGatesCutover(order.ID, participantNames) {
 			continue
 		}
+		msg := i18n.Message{Key: "blocker.order", Args: []any{"order", order.ID, "status", order.Status}}
 		blockers = append(blockers, domain.Blocker{
-			Reason:  fmt.Sprintf("order %d in %s", order.ID, order.Status),
+			Reason:  locales.Catalog.Render(i18n.English, msg),
 			OrderID: order.ID,
 			Hard:    true,
+			Msg:     msg,
 		})
 	}
 	if len(blockers) > 0 {
@@ -365,14 +370,16 @@ func (e *Engine) completeCutover(processID int64, triggeredBy string) error {
 	// to-style with an still-in-progress changeover row if the gate
 	// blocked. findActiveClaim resolves from process.ActiveStyleID, so
 	// that order is unrecoverable without operator intervention.
-	// BlockersToReasons keeps this message byte-identical to what it produced
-	// before blockers became structured — the 400 toast is a contract the
-	// floor reads, and the panel is additive to it, not a replacement.
+	// CutoverBlockedError.Error() keeps this message byte-identical to what it
+	// produced before blockers became structured — the 400 toast is a contract
+	// the floor reads, and the panel is additive to it, not a replacement. The
+	// handler renders the same blockers in the station's language when it is
+	// not English.
 	if ok, blockers, err := e.canCompleteChangeover(changeover.ID); err != nil || !ok {
 		if err != nil {
 			return err
 		}
-		return fmt.Errorf("cannot cutover: %s", strings.Join(domain.BlockersToReasons(blockers), "; "))
+		return &domain.CutoverBlockedError{Blockers: blockers}
 	}
 	toStyleID := changeover.ToStyleID
 	if err := e.db.SetActiveStyle(processID, &toStyleID); err != nil {
@@ -414,6 +421,9 @@ func (e *Engine) finalizeChangeoverRow(processID, changeoverID int64, triggeredB
 	// the press's live CATID still disagrees with the new active style, this
 	// changeover is flagged for operator confirmation on the station.
 	e.openPostCutoverVerify(processID, changeoverID)
+	// Close the finished schedule run and start the one just cut over to —
+	// for every completed changeover, so a manual one keeps the schedule true.
+	e.advanceScheduleOnCutover(processID, changeoverID)
 	return nil
 }
 
@@ -10,10 +10,10 @@
 package engine
 
 import (
-	"fmt"
 	"time"
 
 	"shingo/protocol"
+	"shingoedge/locales"
 	"shingoedge/store/processes"
 )
 
@@ -96,11 +96,11 @@ func (e *Engine) AckSupplyRefusal(processNodeID int64, loaderNode, payloadCode,
 	switch choice {
 	case protocol.SupplyRefusalChoiceWait, protocol.SupplyRefusalChoiceChangeover:
 	default:
-		return fmt.Errorf("unknown answer %q — a refusal is answered with wait or changeover", choice)
+		return locales.Catalog.Errorf("refusal.err_unknown_answer", "choice", choice)
 	}
 	node, err := e.db.GetProcessNode(processNodeID)
 	if err != nil || node == nil {
-		return fmt.Errorf("no such process node %d", processNodeID)
+		return locales.Catalog.Errorf("refusal.err_no_node", "id", processNodeID)
 	}
 	// The process NAME, matching the demand grain — the same identity the episode
 	// key carries, so the two join with no translation.
@@ -182,7 +182,9 @@ func (e *Engine) HandleSupplyRefusalState(st protocol.SupplyRefusalState) {
 	}
 	if err != nil {
 		e.logFn("supply_refusal: apply %s for %s/%s: %v", st.Action, st.LoaderNode, st.PayloadCode, err)
+		return
 	}
+	e.nudgePLCSignals()
 }
 
 // loaderCardNode resolves a process node to the loader window a card lives on,
@@ -196,20 +198,23 @@ func (e *Engine) HandleSupplyRefusalState(st protocol.SupplyRefusalState) {
 // renders on some station's board. A node with no operator_station_id renders
 // nowhere, so a refusal against it could never have been made by a person
 // looking at a card.
+//
+// Its errors are catalog errors (locales.Catalog.Errorf): the handler hands them
+// straight to the loader operator's toast, so they are written for a person and
+// rendered in the station's language. Error() is still the English sentence.
 func (e *Engine) loaderCardNode(processNodeID int64, payloadCode string) (*processes.Node, error) {
 	if payloadCode == "" {
-		return nil, fmt.Errorf("a refusal names a payload; none was given")
+		return nil, locales.Catalog.Errorf("refusal.err_no_payload")
 	}
 	node, err := e.db.GetProcessNode(processNodeID)
 	if err != nil || node == nil {
-		return nil, fmt.Errorf("no such process node %d", processNodeID)
+		return nil, locales.Catalog.Errorf("refusal.err_no_node", "id", processNodeID)
 	}
 	if node.CoreNodeName == "" {
-		return nil, fmt.Errorf("node %s has no core node name", node.Name)
+		return nil, locales.Catalog.Errorf("refusal.err_no_core_node", "node", node.Name)
 	}
 	if node.OperatorStationID == nil {
-		return nil, fmt.Errorf("node %s belongs to no operator station, so it renders no card",
-			node.Name)
+		return nil, locales.Catalog.Errorf("refusal.err_no_station", "node", node.Name)
 	}
 	// Engine.loadActiveNode, not the package-level one: it carries the
 	// Core-owned-loader fallback, synthesising a manual_swap claim for a window
@@ -218,10 +223,10 @@ func (e *Engine) loaderCardNode(processNodeID int64, payloadCode string) (*proce
 	// button would be dead on exactly the boards it was built for.
 	_, _, claim, cerr := e.loadActiveNode(processNodeID)
 	if cerr != nil || claim == nil {
-		return nil, fmt.Errorf("node %s has no active claim", node.Name)
+		return nil, locales.Catalog.Errorf("refusal.err_no_claim", "node", node.Name)
 	}
 	if claim.SwapMode != protocol.SwapModeManualSwap {
-		return nil, fmt.Errorf("node %s is not a loader window (swap mode %q)", node.Name, claim.SwapMode)
+		return nil, locales.Catalog.Errorf("refusal.err_not_loader", "node", node.Name, "mode", claim.SwapMode)
 	}
 	return node, nil
 }
@@ -0,0 +1,438 @@
+// plc_signals.go — material-state write-back: stack lights and HMI bits that
+// follow each process node's orders, supply refusals and changeover blockers.
+//
+// The writer is a poller, like the CATID and stranded monitors, rather than a
+// chain of event handlers. Each tick derives every node's state from the
+// database and writes whatever differs from what was last commanded, so a
+// missed event, an edge restart or a PLC that power-cycled and dropped its
+// outputs all converge on the next tick. Order and refusal events only make
+// the next tick come sooner.
+package engine
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"shingo/protocol"
+	"shingoedge/config"
+	"shingoedge/plc"
+	"shingoedge/store/processes"
+)
+
+// Material states, lowest precedence first. A binding's "state" signal writes
+// the highest one a node holds; the position here is its default value.
+const (
+	signalIdle      = "idle"
+	signalRequested = "requested"
+	signalEnRoute   = "en_route"
+	signalDelivered = "delivered"
+	signalRefused   = "refused"
+	signalBlocked   = "blocked"
+
+	// signalState is the composite signal: one tag carrying the top state.
+	signalState = "state"
+)
+
+var signalStates = []string{signalIdle, signalRequested, signalEnRoute, signalDelivered, signalRefused, signalBlocked}
+
+// nodeSignals is the set of states one node holds right now.
+type nodeSignals map[string]bool
+
+// orderSignal maps an active order's status to the state it puts its node in.
+// Delivered and staged are both "bin here, waiting on the operator to
+// release it".
+func orderSignal(s protocol.Status) string {
+	switch s {
+	case protocol.StatusPending, protocol.StatusSourcing, protocol.StatusQueued,
+		protocol.StatusSubmitted, protocol.StatusReshuffling:
+		return signalRequested
+	case protocol.StatusDispatched, protocol.StatusAcknowledged, protocol.StatusInTransit:
+		return signalEnRoute
+	case protocol.StatusDelivered, protocol.StatusStaged:
+		return signalDelivered
+	}
+	return ""
+}
+
+// topState is the highest-precedence state held, idle when none is.
+func (s nodeSignals) topState() string {
+	for i := len(signalStates) - 1; i > 0; i-- {
+		if s[signalStates[i]] {
+			return signalStates[i]
+		}
+	}
+	return signalIdle
+}
+
+// desiredValue is what a binding writes for the states held across its nodes.
+func desiredValue(b config.PLCSignalBinding, held nodeSignals) any {
+	if b.Signal == signalState {
+		st := held.topState()
+		if v, ok := b.Values[st]; ok {
+			return v
+		}
+		for i, name := range signalStates {
+			if name == st {
+				return i
+			}
+		}
+	}
+	if held[b.Signal] {
+		if b.Active != nil {
+			return b.Active
+		}
+		return 1
+	}
+	if b.Inactive != nil {
+		return b.Inactive
+	}
+	return 0
+}
+
+// validSignal reports whether a binding names a signal the writer knows.
+func validSignal(name string) bool {
+	if name == signalState {
+		return true
+	}
+	for _, s := range signalStates[1:] {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// signalOutput is one binding's write state.
+type signalOutput struct {
+	binding config.PLCSignalBinding
+	source  string
+
+	// pending is the latest desired value and since when it has held.
+	pending      any
+	pendingSince time.Time
+	seen         bool
+
+	// commanded is the value last written successfully.
+	commanded any
+	written   bool
+	failed    bool
+	lastErr   string
+
+	// mismatchSince is when the read-back first disagreed with commanded.
+	mismatchSince time.Time
+}
+
+// due takes this tick's desired value and read-back and reports whether to
+// write now. A new value waits out the debounce; the first value and a retry
+// after a failed write go at once. A read-back that disagrees with a written
+// value for the debounce — the PLC restarted, someone forced the bit — is
+// written again.
+func (o *signalOutput) due(want, readBack any, readErr error, now time.Time, debounce time.Duration) bool {
+	if !o.seen || !plc.SameValue(o.pending, want) {
+		o.pending, o.pendingSince, o.seen = want, now, true
+	}
+	if !o.written {
+		return true
+	}
+	if !plc.SameValue(o.commanded, o.pending) {
+		return o.failed || now.Sub(o.pendingSince) >= debounce
+	}
+	if readErr != nil || plc.SameValue(readBack, o.commanded) {
+		o.mismatchSince = time.Time{}
+		return o.failed
+	}
+	if o.mismatchSince.IsZero() {
+		o.mismatchSince = now
+	}
+	return now.Sub(o.mismatchSince) >= debounce
+}
+
+// wakeAt is when a pending value's debounce runs out, zero when nothing waits.
+func (o *signalOutput) wakeAt(debounce time.Duration) time.Time {
+	if o.written && !o.failed && !plc.SameValue(o.commanded, o.pending) {
+		return o.pendingSince.Add(debounce)
+	}
+	return time.Time{}
+}
+
+type plcSignalWriter struct {
+	eng     *Engine
+	cfg     config.PLCSignalsConfig
+	outputs []*signalOutput
+	nudgeCh chan struct{}
+
+	beat    bool
+	beatErr string
+}
+
+// startPLCSignals validates the bindings, enables publishing on their tags so
+// read-back works through WarLink, and starts the writer. A config with no
+// bindings and no heartbeat starts nothing.
+func (e *Engine) startPLCSignals() {
+	if e.plcMgr == nil {
+		return
+	}
+	cfg := e.cfg.PLCSignals
+	w := &plcSignalWriter{eng: e, cfg: cfg, nudgeCh: make(chan struct{}, 1)}
+	for _, b := range cfg.Bindings {
+		if msg := bindingProblem(b); msg != "" {
+			log.Printf("plc-signals: binding %s.%s skipped: %s", b.PLC, b.Tag, msg)
+			continue
+		}
+		src := "node " + b.Node
+		if b.Station != "" {
+			src = "station " + b.Station
+		}
+		w.outputs = append(w.outputs, &signalOutput{binding: b, source: src + " " + b.Signal})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := e.plcMgr.EnableTagPublishing(ctx, b.PLC, b.Tag); err != nil {
+			log.Printf("plc-signals: enable publish for %s.%s: %v (no read-back)", b.PLC, b.Tag, err)
+		}
+		cancel()
+	}
+	if len(w.outputs) == 0 && cfg.Heartbeat.Tag == "" {
+		return
+	}
+	e.plcSignals = w
+	e.Events.SubscribeTypes(func(Event) { w.nudge() },
+		EventOrderStatusChanged, EventOrderCompleted, EventOrderDelivered, EventOrderFailed)
+	go w.run()
+}
+
+func bindingProblem(b config.PLCSignalBinding) string {
+	switch {
+	case b.PLC == "" || b.Tag == "":
+		return "plc and tag are required"
+	case (b.Node == "") == (b.Station == ""):
+		return "set exactly one of node and station"
+	case !validSignal(b.Signal):
+		return "unknown signal " + b.Signal
+	}
+	return ""
+}
+
+// nudgePLCSignals asks for an evaluation now instead of at the next interval.
+// Safe before Start and with the writer off.
+func (e *Engine) nudgePLCSignals() {
+	if e.plcSignals != nil {
+		e.plcSignals.nudge()
+	}
+}
+
+func (w *plcSignalWriter) nudge() {
+	select {
+	case w.nudgeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *plcSignalWriter) run() {
+	ticker := time.NewTicker(positive(w.cfg.Interval, 2*time.Second))
+	defer ticker.Stop()
+	var beatC <-chan time.Time
+	if w.cfg.Heartbeat.Tag != "" {
+		beat := time.NewTicker(positive(w.cfg.Heartbeat.Interval, time.Second))
+		defer beat.Stop()
+		beatC = beat.C
+	}
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	wake := func() {
+		if next := w.tick(time.Now()); !next.IsZero() {
+			debounce.Reset(time.Until(next))
+		}
+	}
+	wake()
+	for {
+		select {
+		case <-w.eng.stopChan:
+			return
+		case <-ticker.C:
+			wake()
+		case <-w.nudgeCh:
+			wake()
+		case <-debounce.C:
+			wake()
+		case <-beatC:
+			w.heartbeat()
+		}
+	}
+}
+
+func positive(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// tick evaluates every binding, writes those due, and returns the earliest
+// moment a debounce runs out.
+func (w *plcSignalWriter) tick(now time.Time) time.Time {
+	if len(w.outputs) == 0 {
+		return time.Time{}
+	}
+	nodes, held, err := w.gather()
+	if err != nil {
+		log.Printf("plc-signals: %v", err)
+		return time.Time{}
+	}
+	debounce := w.cfg.Debounce
+	var next time.Time
+	for _, o := range w.outputs {
+		want := desiredValue(o.binding, boundSignals(o.binding, nodes, held))
+		rb, rerr := w.eng.plcMgr.ReadTag(o.binding.PLC, o.binding.Tag)
+		if o.due(want, rb, rerr, now, debounce) {
+			w.write(o)
+		}
+		if at := o.wakeAt(debounce); !at.IsZero() && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+	return next
+}
+
+func (w *plcSignalWriter) write(o *signalOutput) {
+	b := o.binding
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := w.eng.plcMgr.Command(ctx, b.PLC, b.Tag, o.source, o.pending)
+	o.mismatchSince = time.Time{}
+	if err != nil {
+		o.failed = true
+		if err.Error() != o.lastErr {
+			log.Printf("plc-signals: write %v to %s.%s (%s): %v", o.pending, b.PLC, b.Tag, o.source, err)
+		}
+		o.lastErr = err.Error()
+		return
+	}
+	if o.failed {
+		log.Printf("plc-signals: %s.%s writing again", b.PLC, b.Tag)
+	}
+	o.commanded, o.written, o.failed, o.lastErr = o.pending, true, false, ""
+}
+
+// heartbeat toggles the watchdog tag. A failure is logged once until the
+// next success; the PLC side is what notices a stopped heartbeat.
+func (w *plcSignalWriter) heartbeat() {
+	hb := w.cfg.Heartbeat
+	w.beat = !w.beat
+	v := 0
+	if w.beat {
+		v = 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := w.eng.plcMgr.Command(ctx, hb.PLC, hb.Tag, "heartbeat", v)
+	switch {
+	case err != nil && err.Error() != w.beatErr:
+		log.Printf("plc-signals: heartbeat %s.%s: %v", hb.PLC, hb.Tag, err)
+		w.beatErr = err.Error()
+	case err == nil:
+		w.beatErr = ""
+	}
+}
+
+// gather reads every node's held states: one read each of nodes, active
+// orders and open refusals, and one gate check per process.
+func (w *plcSignalWriter) gather() ([]processes.Node, map[int64]nodeSignals, error) {
+	db := w.eng.db
+	nodes, err := db.ListProcessNodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	held := make(map[int64]nodeSignals, len(nodes))
+	for _, n := range nodes {
+		held[n.ID] = nodeSignals{}
+	}
+	active, err := db.ListActiveOrders()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, o := range active {
+		if o.ProcessNodeID == nil || held[*o.ProcessNodeID] == nil {
+			continue
+		}
+		if s := orderSignal(o.Status); s != "" {
+			held[*o.ProcessNodeID][s] = true
+		}
+	}
+	w.markRefused(nodes, held)
+	w.markBlocked(nodes, held)
+	return nodes, held, nil
+}
+
+// markRefused flags a loader window holding an open refusal, and a cell with
+// an outstanding call for a part some window has refused — the same two
+// readings the station board gives the same rows.
+func (w *plcSignalWriter) markRefused(nodes []processes.Node, held map[int64]nodeSignals) {
+	open, err := w.eng.db.ListOpenSupplyRefusals()
+	if err != nil || len(open) == 0 {
+		return
+	}
+	loaders := map[string]bool{}
+	parts := map[string]bool{}
+	for _, r := range open {
+		loaders[r.LoaderNode] = true
+		parts[r.PayloadCode] = true
+	}
+	for _, n := range nodes {
+		s := held[n.ID]
+		if loaders[n.CoreNodeName] {
+			s[signalRefused] = true
+			continue
+		}
+		if !s[signalRequested] && !s[signalEnRoute] {
+			continue
+		}
+		if _, _, claim, err := w.eng.loadActiveNode(n.ID); err == nil && claim != nil && parts[claim.PayloadCode] {
+			s[signalRefused] = true
+		}
+	}
+}
+
+// markBlocked flags the nodes a changeover's gate is waiting on. A blocker
+// naming no node — a linked order — blocks the whole process.
+func (w *plcSignalWriter) markBlocked(nodes []processes.Node, held map[int64]nodeSignals) {
+	checked := map[int64]bool{}
+	for _, n := range nodes {
+		if checked[n.ProcessID] {
+			continue
+		}
+		checked[n.ProcessID] = true
+		ok, blockers, err := w.eng.ChangeoverGateStatus(n.ProcessID)
+		if err != nil || ok {
+			continue
+		}
+		for _, m := range nodes {
+			if m.ProcessID != n.ProcessID {
+				continue
+			}
+			for _, b := range blockers {
+				if b.NodeName == "" || b.NodeName == m.Name || b.NodeName == m.CoreNodeName {
+					held[m.ID][signalBlocked] = true
+				}
+			}
+		}
+	}
+}
+
+// boundSignals unions the states of every node a binding covers: the nodes
+// named Node (by name or core node name), or every node of Station.
+func boundSignals(b config.PLCSignalBinding, nodes []processes.Node, held map[int64]nodeSignals) nodeSignals {
+	out := nodeSignals{}
+	for _, n := range nodes {
+		match := b.Station != "" && n.StationName == b.Station ||
+			b.Node != "" && (n.Name == b.Node || n.CoreNodeName == b.Node)
+		if !match {
+			continue
+		}
+		for s, on := range held[n.ID] {
+			out[s] = out[s] || on
+		}
+	}
+	return out
+}
@@ -0,0 +1,162 @@
+// plc_signals_test.go — the material-state write-back: value mapping, the
+// debounce and re-assert state machine, and a tick end to end against a
+// Modbus PLC.
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"shingo/protocol"
+	"shingo/protocol/testutil"
+	"shingoedge/config"
+	"shingoedge/plc"
+	"shingoedge/plc/modbus/modbustest"
+)
+
+func TestDesiredValue(t *testing.T) {
+	t.Parallel()
+	state := config.PLCSignalBinding{Signal: signalState}
+	mapped := config.PLCSignalBinding{Signal: signalState, Values: map[string]any{signalBlocked: "RED", signalIdle: "OFF"}}
+	flag := config.PLCSignalBinding{Signal: signalEnRoute}
+	custom := config.PLCSignalBinding{Signal: signalRefused, Active: true, Inactive: false}
+
+	cases := []struct {
+		b    config.PLCSignalBinding
+		held nodeSignals
+		want any
+	}{
+		{state, nodeSignals{}, 0},
+		{state, nodeSignals{signalRequested: true, signalEnRoute: true}, 2},
+		{state, nodeSignals{signalDelivered: true, signalBlocked: true}, 5},
+		{mapped, nodeSignals{signalBlocked: true}, "RED"},
+		{mapped, nodeSignals{}, "OFF"},
+		{mapped, nodeSignals{signalRefused: true}, 4}, // unmapped state: its position
+		{flag, nodeSignals{signalEnRoute: true}, 1},
+		{flag, nodeSignals{signalRequested: true}, 0},
+		{custom, nodeSignals{signalRefused: true}, true},
+		{custom, nodeSignals{}, false},
+	}
+	for _, c := range cases {
+		if got := desiredValue(c.b, c.held); got != c.want {
+			t.Errorf("desiredValue(%s, %v) = %v, want %v", c.b.Signal, c.held, got, c.want)
+		}
+	}
+}
+
+// TestSignalOutputDue scripts one output: the first value goes at once, a
+// change waits out the debounce, a flicker never writes, a forced read-back
+// is re-asserted, and a failed write retries.
+func TestSignalOutputDue(t *testing.T) {
+	t.Parallel()
+	const debounce = time.Second
+	t0 := time.Unix(0, 0)
+	o := &signalOutput{}
+	wrote := func(v any) { o.commanded, o.written, o.failed = v, true, false }
+
+	if !o.due(1, nil, errors.New("no read-back"), t0, debounce) {
+		t.Fatal("first value must write at once")
+	}
+	wrote(1)
+	if o.due(1, 1, nil, t0.Add(time.Second), debounce) {
+		t.Fatal("unchanged value rewritten")
+	}
+
+	t1 := t0.Add(10 * time.Second)
+	if o.due(2, 1, nil, t1, debounce) {
+		t.Fatal("new value written before the debounce")
+	}
+	if at := o.wakeAt(debounce); !at.Equal(t1.Add(debounce)) {
+		t.Fatalf("wakeAt = %v, want %v", at, t1.Add(debounce))
+	}
+	if o.due(1, 1, nil, t1.Add(debounce/2), debounce) {
+		t.Fatal("flicker back to the commanded value wrote")
+	}
+	if !o.wakeAt(debounce).IsZero() {
+		t.Fatal("nothing pending, yet a wake is scheduled")
+	}
+	o.due(2, 1, nil, t1.Add(2*time.Second), debounce)
+	if !o.due(2, 1, nil, t1.Add(3*time.Second), debounce) {
+		t.Fatal("held value not written after the debounce")
+	}
+	wrote(2)
+
+	t2 := t1.Add(time.Minute)
+	if o.due(2, 0, nil, t2, debounce) {
+		t.Fatal("read-back mismatch re-asserted at once")
+	}
+	if !o.due(2, 0, nil, t2.Add(debounce), debounce) {
+		t.Fatal("read-back mismatch not re-asserted after the debounce")
+	}
+	o.mismatchSince = time.Time{}
+
+	o.failed = true
+	if !o.due(2, 2, nil, t2.Add(time.Minute), debounce) {
+		t.Fatal("failed write not retried")
+	}
+}
+
+// TestPLCSignalsWriteThroughModbus binds a node's en-route flag to a coil and
+// its state to a register, then walks the node from in transit to refused.
+func TestPLCSignalsWriteThroughModbus(t *testing.T) {
+	db := testEngineDB(t)
+	eng := testEngine(t, db)
+	_, nodeID, _, _ := seedProduceNode(t, db, "")
+
+	srv := modbustest.New()
+	addr, err := srv.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	eng.cfg.PLCSources = []config.PLCSourceConfig{{
+		Name: "cell", Driver: config.PLCDriverModbus, Endpoint: addr, PublishInterval: 20 * time.Millisecond,
+		Registers: []config.ModbusRegister{
+			{Tag: "Light", Address: 10, Writable: true},
+			{Tag: "Moving", Table: config.ModbusCoil, Address: 2, Writable: true},
+		},
+	}}
+	eng.plcMgr = plc.NewManager(db, eng.cfg, &plcEmitter{bus: eng.Events}, nil)
+	eng.plcMgr.StartSources()
+	t.Cleanup(eng.plcMgr.Stop)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return eng.plcMgr.IsConnected("cell") })
+
+	w := &plcSignalWriter{eng: eng, cfg: config.PLCSignalsConfig{Debounce: time.Second}}
+	for _, b := range []config.PLCSignalBinding{
+		{Node: "Produce Node", Signal: signalState, PLC: "cell", Tag: "Light"},
+		{Node: "PRODUCE-NODE", Signal: signalEnRoute, PLC: "cell", Tag: "Moving"},
+	} {
+		w.outputs = append(w.outputs, &signalOutput{binding: b, source: "node " + b.Node + " " + b.Signal})
+	}
+
+	orderID, err := db.CreateOrder("u-1", protocol.OrderTypeRetrieve, &nodeID, false, 1, "PRODUCE-NODE", "", "", "", false, "WIDGET-A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.MustNoErr(t, db.UpdateOrderStatus(orderID, string(protocol.StatusInTransit)), "in transit")
+
+	t0 := time.Now()
+	w.tick(t0)
+	if got := srv.Holding(10, 1)[0]; got != 2 || !srv.Coil(2) {
+		t.Fatalf("in transit: Light = %d, Moving = %v; want 2, true", got, srv.Coil(2))
+	}
+
+	testutil.MustNoErr(t, db.OpenSupplyRefusal("PRODUCE-NODE", "WIDGET-A", "loader"), "refuse")
+	w.tick(t0.Add(100 * time.Millisecond))
+	if got := srv.Holding(10, 1)[0]; got != 2 {
+		t.Fatalf("refusal written inside the debounce: Light = %d", got)
+	}
+	w.tick(t0.Add(2 * time.Second))
+	if got := srv.Holding(10, 1)[0]; got != 4 {
+		t.Fatalf("refused: Light = %d, want 4", got)
+	}
+
+	cmds := eng.plcMgr.Commands()
+	if len(cmds) != 2 || cmds[0].Tag != "Light" || cmds[0].Source != "node Produce Node state" || cmds[0].Value != 4 {
+		t.Fatalf("commands = %+v", cmds)
+	}
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool {
+		return eng.plcMgr.Commands()[0].Match
+	})
+}
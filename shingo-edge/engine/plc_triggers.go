@@ -0,0 +1,363 @@
+// plc_triggers.go — PLC-originated material requests and releases: a cell's
+// pushbutton or machine-state bit standing in for the operator's tap.
+//
+// Each plc_triggers entry watches one tag in the PLC cache and, when it
+// fires, runs the same engine action the HMI button does. Two things differ
+// from the button. An interlock refuses an action the screen would not offer
+// — a request with an order already in flight, a two-robot release with
+// nothing staged — because a stuck bit has no operator looking at a dialog.
+// And every order the action touches gets an order_history row naming the
+// PLC and tag, so "who asked for this bin" has an answer that is not "the
+// operator".
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"shingo/protocol"
+	"shingoedge/config"
+	"shingoedge/plc"
+	storeorders "shingoedge/store/orders"
+	"shingoedge/store/processes"
+)
+
+// defaultTriggerDebounce is how long a level-mode tag must hold when the
+// trigger sets no debounce: longer than a bit bouncing, shorter than an
+// operator would wait before pressing the button instead.
+const defaultTriggerDebounce = 2 * time.Second
+
+// triggerState is one trigger's edge detector.
+type triggerState struct {
+	seen bool
+	last int64 // previous value (edge) or the count baseline (count)
+
+	// level mode
+	high    bool
+	since   time.Time
+	lastTry time.Time
+	done    bool
+}
+
+// step takes a fresh tag value and reports whether the trigger fires.
+//
+// Edge and count modes take the first value as a baseline and never fire on
+// it: an edge restarting with the button already held, or a counter at 4117,
+// is not a fresh press. Level mode does fire on a level held since before the
+// restart — a cell still asking for material is still asking.
+func (s *triggerState) step(tr config.PLCTrigger, v int64, now time.Time) bool {
+	switch tr.Mode {
+	case config.PLCTriggerLevel:
+		return s.stepLevel(v, now, positive(tr.Debounce, defaultTriggerDebounce))
+	case config.PLCTriggerCount:
+		step := tr.Count
+		if step <= 0 {
+			step = 1
+		}
+		if !s.seen || v < s.last { // first read, or the counter was reset
+			*s = triggerState{seen: true, last: v}
+			return false
+		}
+		if v-s.last < step {
+			return false
+		}
+		// The baseline stays put until fired commits it, so parts counted
+		// toward a request the interlock refused are still owed. The retry
+		// is paced like a refused level's.
+		debounce := positive(tr.Debounce, defaultTriggerDebounce)
+		if !s.lastTry.IsZero() && now.Sub(s.lastTry) < debounce {
+			return false
+		}
+		s.lastTry = now
+		return true
+	}
+	prev, seen := s.last, s.seen
+	s.seen, s.last = true, v
+	return seen && prev == 0 && v != 0
+}
+
+// fired records that the action step asked for went through at value v: a
+// count moves its baseline to v, a level stays quiet until the tag drops.
+func (s *triggerState) fired(tr config.PLCTrigger, v int64) {
+	switch tr.Mode {
+	case config.PLCTriggerLevel:
+		s.done = true
+	case config.PLCTriggerCount:
+		s.last, s.lastTry = v, time.Time{}
+	}
+}
+
+func (s *triggerState) stepLevel(v int64, now time.Time, debounce time.Duration) bool {
+	if v == 0 {
+		*s = triggerState{}
+		return false
+	}
+	if !s.high {
+		s.high, s.since = true, now
+	}
+	if s.done || now.Sub(s.since) < debounce {
+		return false
+	}
+	if !s.lastTry.IsZero() && now.Sub(s.lastTry) < debounce {
+		return false
+	}
+	s.lastTry = now
+	return true
+}
+
+// tagInt reads a cached tag value as an integer: bits are 0 or 1, numbers
+// go through plc.ToInt64 so a trigger reads every width a source caches. The
+// second result is false for anything else.
+func tagInt(v any) (int64, bool) {
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+	return plc.ToInt64(v)
+}
+
+func validTriggerAction(a string) bool {
+	switch a {
+	case config.PLCActionRequest, config.PLCActionRelease, config.PLCActionRequestEmpty,
+		config.PLCActionRequestFull, config.PLCActionPushEmpty:
+		return true
+	}
+	return false
+}
+
+func triggerProblem(tr config.PLCTrigger) string {
+	switch {
+	case tr.Node == "" || tr.PLC == "" || tr.Tag == "":
+		return "node, plc and tag are required"
+	case !validTriggerAction(tr.Action):
+		return "unknown action " + tr.Action
+	}
+	switch tr.Mode {
+	case "", config.PLCTriggerEdge, config.PLCTriggerLevel, config.PLCTriggerCount:
+		return ""
+	}
+	return "unknown mode " + tr.Mode
+}
+
+// plcTrigger is one validated trigger and its detector.
+type plcTrigger struct {
+	cfg   config.PLCTrigger
+	state triggerState
+}
+
+// who names the trigger in audit rows and as the release's called_by.
+func (t *plcTrigger) who() string {
+	return "plc:" + t.cfg.PLC + "." + t.cfg.Tag
+}
+
+type plcTriggerMonitor struct {
+	eng      *Engine
+	triggers []*plcTrigger
+}
+
+// startPLCTriggers validates plc_triggers, enables publishing on each tag,
+// and starts the watcher. Nothing configured starts nothing.
+func (e *Engine) startPLCTriggers() {
+	if e.plcMgr == nil {
+		return
+	}
+	m := &plcTriggerMonitor{eng: e}
+	for _, tr := range e.cfg.PLCTriggers {
+		if msg := triggerProblem(tr); msg != "" {
+			log.Printf("plc-trigger: %s.%s skipped: %s", tr.PLC, tr.Tag, msg)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := e.plcMgr.EnableTagPublishing(ctx, tr.PLC, tr.Tag); err != nil {
+			log.Printf("plc-trigger: enable publish for %s.%s: %v", tr.PLC, tr.Tag, err)
+		}
+		cancel()
+		log.Printf("plc-trigger: %s on node %s from %s.%s (%s)", tr.Action, tr.Node, tr.PLC, tr.Tag, triggerMode(tr))
+		m.triggers = append(m.triggers, &plcTrigger{cfg: tr})
+	}
+	if len(m.triggers) == 0 {
+		return
+	}
+	go m.run()
+}
+
+func triggerMode(tr config.PLCTrigger) string {
+	if tr.Mode == "" {
+		return config.PLCTriggerEdge
+	}
+	return tr.Mode
+}
+
+func (m *plcTriggerMonitor) run() {
+	ticker := time.NewTicker(plcPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.eng.stopChan:
+			return
+		case <-ticker.C:
+			m.tick(time.Now())
+		}
+	}
+}
+
+// tick reads every trigger's tag from the cache and fires those whose
+// detector says so. A tag that cannot be read leaves its detector alone: a
+// PLC dropping and coming back is not a press.
+func (m *plcTriggerMonitor) tick(now time.Time) {
+	for _, t := range m.triggers {
+		raw, err := m.eng.plcMgr.ReadTag(t.cfg.PLC, t.cfg.Tag)
+		if err != nil {
+			continue
+		}
+		v, ok := tagInt(raw)
+		if !ok || !t.state.step(t.cfg, v, now) {
+			continue
+		}
+		if err := m.eng.firePLCTrigger(t); err != nil {
+			log.Printf("plc-trigger: %s on %s from %s refused: %v", t.cfg.Action, t.cfg.Node, t.who(), err)
+			continue
+		}
+		t.state.fired(t.cfg, v)
+	}
+}
+
+// firePLCTrigger runs a trigger's action on its node behind the interlock
+// and audits every order the action created or released.
+func (e *Engine) firePLCTrigger(t *plcTrigger) error {
+	node, err := e.triggerNode(t.cfg.Node)
+	if err != nil {
+		return err
+	}
+	_, _, claim, err := e.loadActiveNode(node.ID)
+	if err != nil {
+		return err
+	}
+	if claim == nil {
+		return fmt.Errorf("node %s has no active claim", node.Name)
+	}
+	before, err := e.db.ListActiveOrdersByProcessNode(node.ID)
+	if err != nil {
+		return err
+	}
+	twoRobot := claim.SwapMode.IsTwoRobot()
+	if msg := plcInterlock(t.cfg.Action, twoRobot, before); msg != "" {
+		return fmt.Errorf("interlock: %s", msg)
+	}
+	if err := e.runTriggerAction(t, node, claim, twoRobot); err != nil {
+		return err
+	}
+	log.Printf("plc-trigger: %s on %s by %s (%s)", t.cfg.Action, node.Name, t.who(), triggerMode(t.cfg))
+	e.auditPLCTrigger(t, node.ID, before)
+	return nil
+}
+
+func (e *Engine) runTriggerAction(t *plcTrigger, node *processes.Node, claim *processes.NodeClaim, twoRobot bool) error {
+	payload := t.cfg.PayloadCode
+	if payload == "" {
+		payload = claim.PayloadCode
+	}
+	var err error
+	switch t.cfg.Action {
+	case config.PLCActionRequest:
+		_, err = e.requestNodeMaterialFor(node.ID, 1, protocol.EpisodeTriggerPLC)
+	case config.PLCActionRelease:
+		// A PLC saying "bin empty" is RELEASE EMPTY: nothing pulled to
+		// lineside, manifest cleared at Core.
+		if twoRobot {
+			err = e.ReleaseStagedOrders(node.ID, ReleaseDisposition{Mode: DispositionCaptureLineside, CalledBy: t.who()})
+		} else {
+			_, err = e.ReleaseNodeWithRemainingUOP(node.ID, 1, 0)
+		}
+	case config.PLCActionRequestEmpty:
+		_, err = e.RequestEmptyBin(node.ID, payload)
+	case config.PLCActionRequestFull:
+		_, err = e.RequestFullBin(node.ID, payload)
+	case config.PLCActionPushEmpty:
+		err = e.PushEmptyOut(node.ID)
+	}
+	return err
+}
+
+// triggerNode resolves a trigger's node by name or core node name. A name
+// that matches more than one node is refused rather than guessed at.
+func (e *Engine) triggerNode(name string) (*processes.Node, error) {
+	nodes, err := e.db.ListProcessNodes()
+	if err != nil {
+		return nil, err
+	}
+	var found *processes.Node
+	for i := range nodes {
+		if nodes[i].Name != name && nodes[i].CoreNodeName != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("node %q matches more than one process node", name)
+		}
+		found = &nodes[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no process node %q", name)
+	}
+	return found, nil
+}
+
+// plcInterlock returns why an action must not run, or "". Everything but a
+// two-robot release wants the node quiet; a two-robot release wants
+// something staged to release.
+func plcInterlock(action string, twoRobot bool, active []storeorders.Order) string {
+	if action == config.PLCActionRelease && twoRobot {
+		for _, o := range active {
+			if o.Status == protocol.StatusStaged || o.Status == protocol.StatusDelivered {
+				return ""
+			}
+		}
+		return "nothing staged to release"
+	}
+	if len(active) > 0 {
+		return fmt.Sprintf("order %d already in flight (%s)", active[0].ID, active[0].Status)
+	}
+	return ""
+}
+
+// auditPLCTrigger writes an order_history row on each order the action
+// created, and for a release on each order that was active before it.
+//
+// A release is audited from before, not after: the orders it completes or
+// cancels have left the active set by now, and they are the ones the row is
+// for. Each row carries the status the order had and the one it has now.
+func (e *Engine) auditPLCTrigger(t *plcTrigger, nodeID int64, before []storeorders.Order) {
+	detail := fmt.Sprintf("%s by PLC %s.%s (%s trigger)", t.cfg.Action, t.cfg.PLC, t.cfg.Tag, triggerMode(t.cfg))
+	audit := func(id int64, oldStatus, newStatus protocol.Status) {
+		if err := e.db.InsertOrderHistory(id, string(oldStatus), string(newStatus), detail); err != nil {
+			log.Printf("plc-trigger: audit order %d: %v", id, err)
+		}
+	}
+	had := map[int64]bool{}
+	for _, o := range before {
+		had[o.ID] = true
+		if t.cfg.Action != config.PLCActionRelease {
+			continue
+		}
+		now, err := e.db.GetOrder(o.ID)
+		if err != nil {
+			log.Printf("plc-trigger: audit order %d: %v", o.ID, err)
+			continue
+		}
+		audit(o.ID, o.Status, now.Status)
+	}
+	after, err := e.db.ListActiveOrdersByProcessNode(nodeID)
+	if err != nil {
+		log.Printf("plc-trigger: audit %s: %v", t.who(), err)
+		return
+	}
+	for _, o := range after {
+		if !had[o.ID] {
+			audit(o.ID, o.Status, o.Status)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// plc_triggers_test.go — PLC-originated requests: the edge, level and count
+// detectors, the interlock, and a pushbutton end to end against a Modbus PLC.
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"shingo/protocol"
+	"shingo/protocol/testutil"
+	"shingoedge/config"
+	"shingoedge/plc"
+	"shingoedge/plc/modbus/modbustest"
+	storeorders "shingoedge/store/orders"
+	"shingoedge/store/processes"
+)
+
+func TestTriggerStateStep(t *testing.T) {
+	t.Parallel()
+	t0 := time.Unix(0, 0)
+	type read struct {
+		v    int64
+		at   time.Duration
+		fire bool
+	}
+	cases := []struct {
+		name  string
+		tr    config.PLCTrigger
+		reads []read
+	}{
+		{"edge ignores a held button at start", config.PLCTrigger{}, []read{
+			{1, 0, false}, {1, time.Second, false}, {0, 2 * time.Second, false}, {1, 3 * time.Second, true}, {1, 4 * time.Second, false},
+		}},
+		{"level waits out the debounce and retries", config.PLCTrigger{Mode: config.PLCTriggerLevel, Debounce: time.Second}, []read{
+			{1, 0, false}, {1, 500 * time.Millisecond, false}, {1, time.Second, true},
+			{1, 1500 * time.Millisecond, false}, {1, 2 * time.Second, true},
+		}},
+		{"level flicker never fires", config.PLCTrigger{Mode: config.PLCTriggerLevel, Debounce: time.Second}, []read{
+			{1, 0, false}, {0, 500 * time.Millisecond, false}, {1, time.Second, false}, {1, 1500 * time.Millisecond, false},
+		}},
+		{"count fires per step and rebases on reset", config.PLCTrigger{Mode: config.PLCTriggerCount, Count: 2}, []read{
+			{4117, 0, false}, {4118, time.Second, false}, {4119, 2 * time.Second, true},
+			{3, 3 * time.Second, false}, {5, 4 * time.Second, true},
+		}},
+	}
+	for _, c := range cases {
+		s := &triggerState{}
+		for i, r := range c.reads {
+			got := s.step(c.tr, r.v, t0.Add(r.at))
+			if got != r.fire {
+				t.Errorf("%s: read %d (%d at %v) fired = %v, want %v", c.name, i, r.v, r.at, got, r.fire)
+			}
+			if got && c.tr.Mode == config.PLCTriggerCount {
+				s.fired(c.tr, r.v)
+			}
+		}
+	}
+
+	// A level trigger that succeeded stays quiet until the bit drops.
+	s := &triggerState{}
+	lvl := config.PLCTrigger{Mode: config.PLCTriggerLevel, Debounce: time.Second}
+	s.step(lvl, 1, t0)
+	if !s.step(lvl, 1, t0.Add(time.Second)) {
+		t.Fatal("level did not fire")
+	}
+	s.fired(lvl, 1)
+	if s.step(lvl, 1, t0.Add(time.Minute)) {
+		t.Fatal("level fired again while still held after success")
+	}
+}
+
+// TestTriggerStateRefusedCountKeepsItsParts: a count whose action the
+// interlock refused does not move its baseline, so the parts it counted are
+// still owed — the next try comes a debounce later, not a whole Count later.
+func TestTriggerStateRefusedCountKeepsItsParts(t *testing.T) {
+	t.Parallel()
+	t0 := time.Unix(0, 0)
+	cnt := config.PLCTrigger{Mode: config.PLCTriggerCount, Count: 5, Debounce: time.Second}
+	s := &triggerState{}
+	s.step(cnt, 100, t0)
+	if !s.step(cnt, 105, t0.Add(time.Second)) {
+		t.Fatal("count did not fire at its step")
+	}
+	// Refused: nothing calls fired.
+	if s.step(cnt, 106, t0.Add(1500*time.Millisecond)) {
+		t.Fatal("refused count retried inside the debounce")
+	}
+	if !s.step(cnt, 106, t0.Add(2*time.Second)) {
+		t.Fatal("refused count dropped its parts: no retry until another full Count")
+	}
+	s.fired(cnt, 106)
+	if s.step(cnt, 110, t0.Add(5*time.Second)) {
+		t.Fatal("count fired before a full step past the committed baseline")
+	}
+	if !s.step(cnt, 111, t0.Add(6*time.Second)) {
+		t.Fatal("count did not fire a full step past the committed baseline")
+	}
+}
+
+// TestTagIntEveryWidth reads each type a PLC source caches — OPC UA's
+// Int16/SByte/Byte/UInt64 among them — so no tag type silently never fires.
+func TestTagIntEveryWidth(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		v    any
+		want int64
+	}{
+		{true, 1},
+		{false, 0},
+		{int(-5), -5},
+		{int8(-7), -7},
+		{int16(-300), -300},
+		{int32(70000), 70000},
+		{int64(1 << 40), 1 << 40},
+		{uint(9), 9},
+		{uint8(200), 200},
+		{uint16(65535), 65535},
+		{uint32(4000000000), 4000000000},
+		{uint64(12), 12},
+		{float32(2.9), 2},
+		{float64(-3.7), -3},
+	}
+	for _, c := range cases {
+		got, ok := tagInt(c.v)
+		if !ok || got != c.want {
+			t.Errorf("tagInt(%T %v) = %d, %v; want %d, true", c.v, c.v, got, ok, c.want)
+		}
+	}
+	if _, ok := tagInt("1"); ok {
+		t.Error("tagInt read a string as a number")
+	}
+}
+
+func TestPLCInterlock(t *testing.T) {
+	t.Parallel()
+	staged := []storeorders.Order{{ID: 7, Status: protocol.StatusStaged}}
+	moving := []storeorders.Order{{ID: 8, Status: protocol.StatusInTransit}}
+
+	if msg := plcInterlock(config.PLCActionRequest, false, nil); msg != "" {
+		t.Fatalf("quiet node refused: %s", msg)
+	}
+	if msg := plcInterlock(config.PLCActionRequest, false, moving); !strings.Contains(msg, "order 8 already in flight") {
+		t.Fatalf("request over an in-flight order: %q", msg)
+	}
+	if msg := plcInterlock(config.PLCActionRelease, true, moving); msg != "nothing staged to release" {
+		t.Fatalf("two-robot release with nothing staged: %q", msg)
+	}
+	if msg := plcInterlock(config.PLCActionRelease, true, staged); msg != "" {
+		t.Fatalf("two-robot release of a staged order refused: %s", msg)
+	}
+	if msg := plcInterlock(config.PLCActionRelease, false, staged); msg == "" {
+		t.Fatal("single-robot release allowed with an order in flight")
+	}
+}
+
+// TestPLCTriggerRequestThroughModbus presses a coil bound to a consume
+// node's request: the first press orders material with a PLC audit row, the
+// second is refused by the interlock.
+func TestPLCTriggerRequestThroughModbus(t *testing.T) {
+	db := testEngineDB(t)
+	eng := testEngine(t, db)
+	_, nodeID, styleID, _ := seedConsumeNode(t, db, consumeNodeConfig{PayloadCode: "WIDGET-A", UOPCapacity: 100})
+	_, err := upsertClaimLegacySimple(db, processes.NodeClaimInput{
+		StyleID: styleID, CoreNodeName: "CONSUME-NODE", Role: "consume", SwapMode: "simple",
+		PayloadCode: "WIDGET-A", UOPCapacity: 100, InboundSource: "MARKET",
+	})
+	testutil.MustNoErr(t, err, "inbound source")
+
+	srv := modbustest.New()
+	addr, err := srv.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	eng.cfg.PLCSources = []config.PLCSourceConfig{{
+		Name: "cell", Driver: config.PLCDriverModbus, Endpoint: addr, PublishInterval: 20 * time.Millisecond,
+		Registers: []config.ModbusRegister{{Tag: "Call", Table: config.ModbusCoil, Address: 3}},
+	}}
+	eng.plcMgr = plc.NewManager(db, eng.cfg, &plcEmitter{bus: eng.Events}, nil)
+	eng.plcMgr.StartSources()
+	t.Cleanup(eng.plcMgr.Stop)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return eng.plcMgr.IsConnected("cell") })
+
+	m := &plcTriggerMonitor{eng: eng, triggers: []*plcTrigger{{cfg: config.PLCTrigger{
+		Node: "CONSUME-NODE", Action: config.PLCActionRequest, PLC: "cell", Tag: "Call",
+	}}}}
+	readAs := func(want bool) {
+		testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool {
+			v, err := eng.plcMgr.ReadTag("cell", "Call")
+			return err == nil && v == want
+		})
+	}
+	press := func() {
+		srv.SetCoil(3, true)
+		readAs(true)
+		m.tick(time.Now())
+		srv.SetCoil(3, false)
+		readAs(false)
+		m.tick(time.Now())
+	}
+
+	readAs(false)
+	m.tick(time.Now())
+	press()
+	active, err := db.ListActiveOrdersByProcessNode(nodeID)
+	if err != nil || len(active) != 1 {
+		t.Fatalf("after press: %d active orders, err %v", len(active), err)
+	}
+	hist, err := db.ListOrderHistory(active[0].ID)
+	testutil.MustNoErr(t, err, "history")
+	audited := false
+	for _, h := range hist {
+		audited = audited || h.Detail == "request by PLC cell.Call (edge trigger)"
+	}
+	if !audited {
+		t.Fatalf("no PLC audit row in %+v", hist)
+	}
+
+	press()
+	if active, _ := db.ListActiveOrdersByProcessNode(nodeID); len(active) != 1 {
+		t.Fatalf("interlock let a second request through: %d active orders", len(active))
+	}
+}
+
+// TestAuditPLCReleaseCoversOrdersItFinished: a release that takes an order
+// out of the active set still audits it, with the status move it caused.
+func TestAuditPLCReleaseCoversOrdersItFinished(t *testing.T) {
+	db := testEngineDB(t)
+	eng := testEngine(t, db)
+	_, nodeID, _, _ := seedConsumeNode(t, db, consumeNodeConfig{PayloadCode: "WIDGET-A", UOPCapacity: 100})
+	orderID, err := db.CreateOrder("uuid-plc-release", protocol.OrderTypeRetrieve,
+		&nodeID, false, 1, "CONSUME-NODE", "", "", "", false, "WIDGET-A")
+	testutil.MustNoErr(t, err, "create order")
+	testutil.MustNoErr(t, db.UpdateOrderStatus(orderID, string(protocol.StatusStaged)), "stage")
+	before, err := db.ListActiveOrdersByProcessNode(nodeID)
+	if err != nil || len(before) != 1 {
+		t.Fatalf("before: %d active orders, err %v", len(before), err)
+	}
+
+	// What the release did: the staged order is finished and leaves the set.
+	testutil.MustNoErr(t, db.UpdateOrderStatus(orderID, string(protocol.StatusConfirmed)), "release")
+	tr := &plcTrigger{cfg: config.PLCTrigger{Node: "CONSUME-NODE", Action: config.PLCActionRelease, PLC: "cell", Tag: "Done"}}
+	eng.auditPLCTrigger(tr, nodeID, before)
+
+	hist, err := db.ListOrderHistory(orderID)
+	testutil.MustNoErr(t, err, "history")
+	for _, h := range hist {
+		if h.Detail == "release by PLC cell.Done (edge trigger)" {
+			if h.OldStatus != protocol.StatusStaged || h.NewStatus != protocol.StatusConfirmed {
+				t.Fatalf("audit row %s -> %s, want staged -> confirmed", h.OldStatus, h.NewStatus)
+			}
+			return
+		}
+	}
+	t.Fatalf("released order has no PLC audit row: %+v", hist)
+}
@@ -0,0 +1,425 @@
+// production_schedule.go — changeovers driven by the production schedule.
+//
+// Without a schedule a changeover starts when an operator presses Start, so the
+// next style's material leaves the supermarket at the moment the line needs
+// it and the press waits out the whole robot trip. The schedule says what runs
+// next and when, which is enough to start that trip early.
+//
+// Three pieces, each keyed on the process's schedule (store.ScheduleRun):
+//
+//   - The planner tick projects when the running run will end — its planned
+//     end, or its remaining quantity at the rate the counter is seeing — and
+//     starts the changeover into the next run once the cut is closer than the
+//     slowest supply route takes (Core's transit ETA) plus a margin. The
+//     changeover's orders park at their wait steps, so the material is staged
+//     at the line and nothing is released until the operator says so.
+//   - The counter path arms the changeover when the running run reaches its
+//     planned quantity: started if the planner had not, and announced either
+//     way.
+//   - The cutover hook closes the running run and makes the next one active
+//     whenever a changeover completes, however it was started. A manual
+//     changeover is the schedule's input, never in conflict with it.
+//
+// STARTING IS THE ONLY THING DONE AUTOMATICALLY, and only with schedule.enabled.
+// Release and cutover stay with the operator and the CATID monitor. A style
+// Core reports as unsourceable (red) is never started — the HK 2026-07-28
+// lesson is that starting dispatches robots, and nothing here can make the
+// material exist.
+package engine
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"shingoedge/engine/changeover"
+	"shingoedge/store"
+	"shingoedge/store/processes"
+)
+
+// schedulePlanHorizon bounds how far ahead the planner looks. A cut further
+// out than this is not previewed at all — planning a changeover reads the
+// claims and asks Core for transit times, and doing that every tick for a run
+// that ends this evening is waste.
+const schedulePlanHorizon = 2 * time.Hour
+
+// scheduleCalledBy is the changeover's called_by when the schedule starts it.
+const scheduleCalledBy = "schedule"
+
+// Schedule changeover reasons, carried on ScheduleChangeoverEvent.
+const (
+	scheduleReasonPrestage = "prestage"
+	scheduleReasonQuantity = "quantity"
+)
+
+// startSchedulePlanner spawns the planner goroutine.
+func (e *Engine) startSchedulePlanner() {
+	interval := e.cfg.Schedule.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stopChan:
+				return
+			case <-ticker.C:
+				e.planSchedules(time.Now())
+			}
+		}
+	}()
+}
+
+// planSchedules runs one planner pass over every process.
+func (e *Engine) planSchedules(now time.Time) {
+	procs, err := e.db.ListProcesses()
+	if err != nil {
+		log.Printf("schedule: list processes: %v", err)
+		return
+	}
+	for i := range procs {
+		e.planSchedule(&procs[i], now)
+	}
+}
+
+// planSchedule brings one process's schedule up to date and, when the next
+// run's cut is within its pre-stage lead, starts the changeover into it.
+func (e *Engine) planSchedule(proc *processes.Process, now time.Time) {
+	active, err := e.syncActiveScheduleRun(proc)
+	if err != nil {
+		log.Printf("schedule: process %s: %v", proc.Name, err)
+		return
+	}
+	next, err := e.db.NextScheduleRun(proc.ID)
+	if err != nil || !e.cfg.Schedule.Enabled || next.PrestagedAt != nil {
+		return
+	}
+	if e.changeoverOpen(proc.ID) {
+		return // one already running — manual or ours
+	}
+	cut, ok := projectScheduleCut(active, next, now)
+	if !ok || cut.Sub(now) > schedulePlanHorizon {
+		return
+	}
+	lead := e.schedulePrestageLead(proc.ID, next.StyleID)
+	if now.Before(cut.Add(-lead)) {
+		return
+	}
+	e.startScheduledChangeover(proc, next, scheduleReasonPrestage,
+		fmt.Sprintf("cut projected %s, lead %s", cut.Format(time.Kitchen), lead.Round(time.Second)))
+}
+
+// syncActiveScheduleRun returns the process's active run, first activating
+// the next planned run when the process is already running its style with no
+// changeover open — a schedule imported mid-shift, or the first run of the day.
+func (e *Engine) syncActiveScheduleRun(proc *processes.Process) (*store.ScheduleRun, error) {
+	active, err := e.db.ActiveScheduleRun(proc.ID)
+	if err == nil {
+		return active, nil
+	}
+	if !errors.Is(err, store.ErrNoScheduleRun) {
+		return nil, err
+	}
+	next, err := e.db.NextScheduleRun(proc.ID)
+	if err != nil || proc.ActiveStyleID == nil || *proc.ActiveStyleID != next.StyleID {
+		return nil, nil
+	}
+	if e.changeoverOpen(proc.ID) {
+		return nil, nil
+	}
+	if err := e.db.ActivateScheduleRun(next.ID); err != nil {
+		return nil, err
+	}
+	return e.db.GetScheduleRun(next.ID)
+}
+
+// changeoverOpen reports whether a process has a changeover in progress. A
+// failed read counts as open: the schedule only ever acts on a process it is
+// sure is idle.
+func (e *Engine) changeoverOpen(processID int64) bool {
+	_, err := e.db.GetActiveProcessChangeover(processID)
+	return !errors.Is(err, sql.ErrNoRows)
+}
+
+// projectScheduleCut estimates when the line will cut over to next. With a
+// run in progress that is the earlier of its planned end and the time its
+// remaining quantity takes at the rate produced so far; with none, it is the
+// next run's planned start. ok is false when there is nothing to go on — a
+// quantity run that has not produced anything yet has no rate.
+func projectScheduleCut(active, next *store.ScheduleRun, now time.Time) (time.Time, bool) {
+	if active == nil {
+		if next.PlannedStart == nil {
+			return time.Time{}, false
+		}
+		return *next.PlannedStart, true
+	}
+	var cut time.Time
+	if active.PlannedEnd != nil {
+		cut = *active.PlannedEnd
+	}
+	if active.PlannedQty > 0 && active.ActualStart != nil && active.ProducedQty > 0 {
+		elapsed := now.Sub(*active.ActualStart)
+		remaining := active.PlannedQty - active.ProducedQty
+		if remaining < 0 {
+			remaining = 0
+		}
+		byQty := now.Add(time.Duration(float64(elapsed) * float64(remaining) / float64(active.ProducedQty)))
+		if cut.IsZero() || byQty.Before(cut) {
+			cut = byQty
+		}
+	}
+	return cut, !cut.IsZero()
+}
+
+// schedulePrestageLead is how long before the cut the changeover into
+// styleID has to start: Core's transit estimate for the slowest supply route
+// in the changeover preview, plus schedule.prestage_margin. With Core
+// unreachable it is the margin alone, logged — a late pre-stage still beats
+// none.
+func (e *Engine) schedulePrestageLead(processID, styleID int64) time.Duration {
+	margin := e.cfg.Schedule.PrestageMargin
+	key := fmt.Sprintf("lead %d→%d", processID, styleID)
+	plan, err := e.PreviewChangeoverPlan(processID, styleID)
+	if err != nil {
+		if e.scheduleNote(key, err.Error()) {
+			log.Printf("schedule: preview changeover for process %d → style %d: %v", processID, styleID, err)
+		}
+		return margin
+	}
+	var routes [][2]string
+	for _, a := range plan.Actions {
+		if a.Err != nil || a.SupplyOrder == nil {
+			continue
+		}
+		if src, dst := supplyRoute(a.SupplyOrder.Complex, a.SupplyOrder.Retrieve); src != "" && dst != "" {
+			routes = append(routes, [2]string{src, dst})
+		}
+	}
+	if len(routes) == 0 {
+		return margin
+	}
+	etas, err := e.coreClient.FetchTransitETAs(routes)
+	if err != nil {
+		if e.scheduleNote(key, err.Error()) {
+			log.Printf("schedule: transit ETAs for process %d → style %d: %v (using margin only)", processID, styleID, err)
+		}
+		return margin
+	}
+	e.scheduleNote(key, "")
+	var slowest time.Duration
+	for _, t := range etas {
+		if d := time.Duration(t.Seconds) * time.Second; d > slowest {
+			slowest = d
+		}
+	}
+	return slowest + margin
+}
+
+// supplyRoute is the (source, delivery) pair a supply order's robot travels:
+// the first pickup of a complex order, or a retrieve's source. Empty when the
+// order leaves the source for Core to pick.
+func supplyRoute(cx *changeover.ComplexOrderSpec, rt *changeover.RetrieveOrderSpec) (string, string) {
+	switch {
+	case cx != nil:
+		for _, st := range cx.Steps {
+			if st.Action == "pickup" {
+				return st.Node, cx.DeliveryNode
+			}
+		}
+	case rt != nil:
+		return rt.SourceNode, rt.DeliveryNode
+	}
+	return "", ""
+}
+
+// startScheduledChangeover starts the changeover into run and records it on
+// the run. A refusal (an order still in flight at a participant, Core
+// reporting the style unsourceable) is logged once per run and reason, and
+// retried on the next pass.
+func (e *Engine) startScheduledChangeover(proc *processes.Process, run *store.ScheduleRun, reason, detail string) {
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+	// Re-checked under the lock: the planner and the counter path can both
+	// decide to start in the same moment, and only the first may.
+	if e.changeoverOpen(proc.ID) {
+		return
+	}
+
+	evt := ScheduleChangeoverEvent{
+		ProcessID: proc.ID, RunID: run.ID, ToStyleID: run.StyleID, ToStyleName: run.StyleName,
+		Reason: reason, Detail: detail,
+	}
+	if e.scheduleStyleUnsourceable(proc.Name, run) {
+		evt.Detail = "Core reports no stock for " + run.StyleName + "; start the changeover by hand once it is loaded"
+		e.noteScheduleRefusal(run.ID, reason, evt)
+		return
+	}
+	co, err := e.StartProcessChangeover(proc.ID, run.StyleID, scheduleCalledBy,
+		fmt.Sprintf("schedule run %d (%s): %s", run.ID, reason, detail))
+	if err != nil {
+		evt.Detail = err.Error()
+		e.noteScheduleRefusal(run.ID, reason, evt)
+		return
+	}
+	if err := e.db.MarkScheduleRunPrestaged(run.ID, co.ID); err != nil {
+		log.Printf("schedule: %v", err)
+	}
+	e.scheduleNote(fmt.Sprintf("run %d", run.ID), "")
+	evt.ChangeoverID = co.ID
+	e.logFn("schedule: process %s → %s changeover %d started (%s: %s)",
+		proc.Name, run.StyleName, co.ID, reason, detail)
+	e.Events.Emit(Event{Type: EventScheduleChangeover, Payload: evt})
+}
+
+// noteScheduleRefusal logs and announces a changeover the schedule could not
+// start, once per run and reason rather than on every pass.
+func (e *Engine) noteScheduleRefusal(runID int64, reason string, evt ScheduleChangeoverEvent) {
+	msg := reason + ": " + evt.Detail
+	if !e.scheduleNote(fmt.Sprintf("run %d", runID), msg) {
+		return
+	}
+	log.Printf("schedule: process %d → %s not started (%s)", evt.ProcessID, evt.ToStyleName, msg)
+	e.Events.Emit(Event{Type: EventScheduleChangeover, Payload: evt})
+}
+
+// scheduleNote records msg as the latest problem under key and reports
+// whether it differs from the last one — the planner runs every pass, and a
+// standing problem is worth one log line, not one per pass. An empty msg
+// clears the key.
+func (e *Engine) scheduleNote(key, msg string) bool {
+	e.scheduleNotesMu.Lock()
+	defer e.scheduleNotesMu.Unlock()
+	if msg == "" {
+		delete(e.scheduleNotes, key)
+		return false
+	}
+	if e.scheduleNotes[key] == msg {
+		return false
+	}
+	e.scheduleNotes[key] = msg
+	return true
+}
+
+// scheduleStyleUnsourceable reports whether Core's last sourceability verdict
+// for the run's style is red. No verdict is not a refusal.
+func (e *Engine) scheduleStyleUnsourceable(processName string, run *store.ScheduleRun) bool {
+	id := strconv.FormatInt(run.StyleID, 10)
+	for _, st := range e.SourcingStateForProcess(processName) {
+		if (st.StyleID == run.StyleName || st.StyleID == id) && st.Status == "red" {
+			return true
+		}
+	}
+	return false
+}
+
+// recordScheduleProduction counts a delta against the process's active run
+// and arms the changeover out of it when the run makes its quantity. Reset
+// artifacts are skipped, as the hourly tracker skips them.
+func (e *Engine) recordScheduleProduction(delta CounterDeltaEvent) {
+	if delta.ProcessID == 0 || delta.StyleID == 0 || delta.Delta <= 0 || delta.Anomaly == "reset" {
+		return
+	}
+	run, err := e.db.AddScheduleRunProduced(delta.ProcessID, delta.StyleID, delta.Delta)
+	if err != nil {
+		if !errors.Is(err, store.ErrNoScheduleRun) {
+			log.Printf("schedule: %v", err)
+		}
+		return
+	}
+	if !run.QuantityReached() {
+		return
+	}
+	armed, err := e.db.MarkScheduleRunArmed(run.ID)
+	if err != nil || !armed {
+		return
+	}
+	// Off the counter path: starting a changeover plans it and talks to Core.
+	go e.armScheduledChangeover(delta.ProcessID, run)
+}
+
+// armScheduledChangeover runs once when a run reaches its quantity. The
+// changeover into the next run is started unless the planner already did;
+// either way the station is told the run is done.
+func (e *Engine) armScheduledChangeover(processID int64, done *store.ScheduleRun) {
+	proc, err := e.db.GetProcess(processID)
+	if err != nil {
+		log.Printf("schedule: arm process %d: %v", processID, err)
+		return
+	}
+	next, err := e.db.NextScheduleRun(processID)
+	if err != nil {
+		e.logFn("schedule: process %s finished run %d (%d/%d) — nothing further scheduled",
+			proc.Name, done.ID, done.ProducedQty, done.PlannedQty)
+		return
+	}
+	detail := fmt.Sprintf("%s reached %d of %d", done.StyleName, done.ProducedQty, done.PlannedQty)
+	if co, err := e.db.GetActiveProcessChangeover(processID); err == nil {
+		e.Events.Emit(Event{Type: EventScheduleChangeover, Payload: ScheduleChangeoverEvent{
+			ProcessID: processID, RunID: next.ID, ToStyleID: next.StyleID, ToStyleName: next.StyleName,
+			ChangeoverID: co.ID, Reason: scheduleReasonQuantity, Detail: detail,
+		}})
+		return
+	}
+	if !e.cfg.Schedule.Enabled {
+		e.Events.Emit(Event{Type: EventScheduleChangeover, Payload: ScheduleChangeoverEvent{
+			ProcessID: processID, RunID: next.ID, ToStyleID: next.StyleID, ToStyleName: next.StyleName,
+			Reason: scheduleReasonQuantity, Detail: detail,
+		}})
+		return
+	}
+	e.startScheduledChangeover(proc, next, scheduleReasonQuantity, detail)
+}
+
+// advanceScheduleOnCutover closes the running run and activates the one the
+// line just changed over to. Called from finalizeChangeoverRow for every
+// completed changeover, scheduled or not.
+//
+// The new run is the earliest planned run for the new style. Planned runs
+// ahead of it were passed over and are marked skipped — the adherence view
+// should show the line went out of order, not pretend those runs are still
+// coming. A changeover to a style with no planned run leaves the process off
+// schedule until the line comes back to it.
+func (e *Engine) advanceScheduleOnCutover(processID, changeoverID int64) {
+	if active, err := e.db.ActiveScheduleRun(processID); err == nil {
+		if err := e.db.FinishScheduleRun(active.ID, store.ScheduleRunDone); err != nil {
+			log.Printf("schedule: %v", err)
+		}
+	}
+	styleID, err := e.db.GetActiveStyleID(processID)
+	if err != nil || styleID == nil {
+		return
+	}
+	runs, err := e.db.ListScheduleRuns(processID)
+	if err != nil {
+		log.Printf("schedule: %v", err)
+		return
+	}
+	var passed []int64
+	for _, r := range runs {
+		if r.Status != store.ScheduleRunPlanned {
+			continue
+		}
+		if r.StyleID != *styleID {
+			passed = append(passed, r.ID)
+			continue
+		}
+		for _, id := range passed {
+			if err := e.db.FinishScheduleRun(id, store.ScheduleRunSkipped); err != nil {
+				log.Printf("schedule: %v", err)
+			}
+		}
+		if err := e.db.ActivateScheduleRun(r.ID); err != nil {
+			log.Printf("schedule: %v", err)
+			return
+		}
+		if err := e.db.LinkScheduleRunChangeover(r.ID, changeoverID); err != nil {
+			log.Printf("schedule: %v", err)
+		}
+		return
+	}
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"shingoedge/store"
+)
+
+func TestProjectScheduleCut(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	at := func(h, m int) *time.Time {
+		v := time.Date(2026, 10, 18, h, m, 0, 0, time.UTC)
+		return &v
+	}
+
+	cases := []struct {
+		name   string
+		active *store.ScheduleRun
+		next   *store.ScheduleRun
+		want   *time.Time
+	}{
+		{
+			name: "nothing running: the next run's planned start",
+			next: &store.ScheduleRun{PlannedStart: at(11, 0)},
+			want: at(11, 0),
+		},
+		{
+			name: "nothing running, next has no time: no cut",
+			next: &store.ScheduleRun{PlannedQty: 100},
+		},
+		{
+			name:   "time-planned run: its planned end",
+			active: &store.ScheduleRun{PlannedEnd: at(12, 0)},
+			next:   &store.ScheduleRun{},
+			want:   at(12, 0),
+		},
+		{
+			// 50 of 100 in the hour since 09:00: another hour to go.
+			name:   "quantity-planned run: projected from the rate so far",
+			active: &store.ScheduleRun{PlannedQty: 100, ProducedQty: 50, ActualStart: at(9, 0)},
+			next:   &store.ScheduleRun{},
+			want:   at(11, 0),
+		},
+		{
+			name:   "both: whichever comes first",
+			active: &store.ScheduleRun{PlannedQty: 100, ProducedQty: 50, ActualStart: at(9, 0), PlannedEnd: at(10, 30)},
+			next:   &store.ScheduleRun{},
+			want:   at(10, 30),
+		},
+		{
+			name:   "quantity-planned, nothing made yet: no rate, no cut",
+			active: &store.ScheduleRun{PlannedQty: 100, ActualStart: at(9, 0)},
+			next:   &store.ScheduleRun{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := projectScheduleCut(tc.active, tc.next, now)
+			if tc.want == nil {
+				if ok {
+					t.Fatalf("got cut %v, want none", got)
+				}
+				return
+			}
+			if !ok || !got.Equal(*tc.want) {
+				t.Fatalf("got %v (ok=%v), want %v", got, ok, *tc.want)
+			}
+		})
+	}
+}
+
+// A cutover finishes the running run, starts the first planned run for the
+// style the process cut over to, and marks any planned runs it jumped past
+// as skipped — a manual changeover out of order still leaves the schedule
+// telling the truth.
+func TestAdvanceScheduleOnCutover_SkipsPassedRuns(t *testing.T) {
+	db := testEngineDB(t)
+	eng := testEngine(t, db)
+	pid, err := db.CreateProcess("PRESS-4", "", "active_production", "", "", false)
+	if err != nil {
+		t.Fatalf("create process: %v", err)
+	}
+	var styles [3]int64
+	for i, name := range []string{"A", "B", "C"} {
+		if styles[i], err = db.CreateStyle(name, "", pid); err != nil {
+			t.Fatalf("create style %s: %v", name, err)
+		}
+	}
+	ids, err := db.ReplacePlannedScheduleRuns(pid, "csv", []store.ScheduleRunInput{
+		{StyleID: styles[0], PlannedQty: 10},
+		{StyleID: styles[1], PlannedQty: 10},
+		{StyleID: styles[2], PlannedQty: 10},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if err := db.ActivateScheduleRun(ids[0]); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	res, err := db.Exec(`INSERT INTO process_changeovers (process_id, to_style_id, state) VALUES (?, ?, 'completed')`, pid, styles[2])
+	if err != nil {
+		t.Fatalf("insert changeover: %v", err)
+	}
+	coID, _ := res.LastInsertId()
+	if err := db.SetActiveStyle(pid, &styles[2]); err != nil {
+		t.Fatalf("set active style: %v", err)
+	}
+
+	eng.advanceScheduleOnCutover(pid, coID)
+
+	runs, err := db.ListScheduleRuns(pid)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []string{store.ScheduleRunDone, store.ScheduleRunSkipped, store.ScheduleRunActive}
+	for i, r := range runs {
+		if r.Status != want[i] {
+			t.Errorf("run %d (%s) status = %s, want %s", i+1, r.StyleName, r.Status, want[i])
+		}
+	}
+	if runs[2].ChangeoverID == nil || *runs[2].ChangeoverID != coID {
+		t.Errorf("run 3 changeover = %v, want %d", runs[2].ChangeoverID, coID)
+	}
+}
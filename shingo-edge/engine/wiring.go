@@ -43,6 +43,7 @@ func (e *Engine) wireEventHandlers() {
 	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, CounterDeltaEvent]) {
 		e.hourlyTracker.HandleDelta(evt.Payload)
 		e.handleCounterDelta(evt.Payload)
+		e.recordScheduleProduction(evt.Payload)
 	}, EventCounterDelta)
 
 	eventbus.SubscribeTyped(e.Events, func(evt eventbus.TypedEvent[EventType, OrderCompletedEvent]) {
@@ -0,0 +1,22 @@
+// Package locales is the Edge's message catalog: the operator HMI's template
+// text and the sentences the engine composes for an operator (changeover
+// blockers, supply-refusal errors), in every language the plants run.
+//
+// en.json is the reference and every key starts there. A new key added to
+// en.json alone still renders — the other languages fall back to English — but
+// TestCatalogComplete fails until es.json and ja.json carry it too, which is the
+// point: the fallback is for a running plant, not for a release.
+package locales
+
+import (
+	"embed"
+
+	"shingo/protocol/i18n"
+)
+
+//go:embed *.json
+var files embed.FS
+
+// Catalog is loaded once at init; a malformed or inconsistent file is a build
+// defect and panics rather than shipping a screen of raw keys.
+var Catalog = i18n.MustLoad(files)
@@ -43,6 +43,7 @@ type Client struct {
 	cfg        *config.MessagingConfig
 	kafkaW     *kafkago.Writer
 	kafkaR     *kafkago.Reader
+	dialer     *kafkago.Dialer // TLS and SASL for readers; set with the writer
 	stopChan   chan struct{}
 	SigningKey []byte // optional HMAC key; when set, outbound messages are signed
 
@@ -95,20 +96,41 @@ func (c *Client) Connect() error {
 	if len(c.cfg.Kafka.Brokers) == 0 {
 		return fmt.Errorf("no kafka brokers configured")
 	}
+	if err := c.openWriter(); err != nil {
+		return err
+	}
+	c.DebugLog.Log("connected to brokers %v", c.cfg.Kafka.Brokers)
+	return nil
+}
 
+// openWriter creates the writer and the reader dialer from the current
+// config, both carrying its TLS and SASL settings. Caller holds c.mu.
+func (c *Client) openWriter() error {
+	transport, err := newTransport(c.cfg.Kafka)
+	if err != nil {
+		return err
+	}
+	dialer, err := newDialer(c.cfg.Kafka)
+	if err != nil {
+		return err
+	}
 	c.kafkaW = &kafkago.Writer{
 		Addr:         kafkago.TCP(c.cfg.Kafka.Brokers...),
 		Balancer:     &kafkago.Hash{},
 		RequiredAcks: kafkago.RequireOne,
 		BatchTimeout: writerBatchTimeout,
+		Transport:    transport,
 	}
-	c.DebugLog.Log("connected to brokers %v", c.cfg.Kafka.Brokers)
+	c.dialer = dialer
 	return nil
 }
 
 // Reconnect closes the existing writer and creates a new one using the
 // current config values. This is needed after broker addresses are changed
-// at runtime because kafkago.TCP resolves the address at creation time.
+// at runtime because kafkago.TCP resolves the address at creation time, and
+// after TLS or SASL settings change because the transport is built with the
+// writer. The running reader keeps its dialer until its next read error, which
+// a broker that now demands the new settings will produce.
 func (c *Client) Reconnect() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -119,13 +141,10 @@ func (c *Client) Reconnect() error {
 
 	if c.kafkaW != nil {
 		c.kafkaW.Close()
+		c.kafkaW = nil
 	}
-
-	c.kafkaW = &kafkago.Writer{
-		Addr:         kafkago.TCP(c.cfg.Kafka.Brokers...),
-		Balancer:     &kafkago.Hash{},
-		RequiredAcks: kafkago.RequireOne,
-		BatchTimeout: writerBatchTimeout,
+	if err := c.openWriter(); err != nil {
+		return err
 	}
 
 	log.Printf("kafka writer reconnected to %v", c.cfg.Kafka.Brokers)
@@ -190,6 +209,7 @@ func (c *Client) Subscribe(topic string, handler func(payload []byte)) error {
 		Brokers: c.cfg.Kafka.Brokers,
 		Topic:   topic,
 		GroupID: c.cfg.Kafka.GroupID,
+		Dialer:  c.dialer,
 	})
 	c.DebugLog.Log("subscribed to topic=%s group=%s", topic, c.cfg.Kafka.GroupID)
 	go c.readLoop(topic, handler)
@@ -239,6 +259,7 @@ func (c *Client) readLoop(topic string, handler func(payload []byte)) {
 				Brokers: c.cfg.Kafka.Brokers,
 				Topic:   topic,
 				GroupID: c.cfg.Kafka.GroupID,
+				Dialer:  c.dialer,
 			})
 			c.mu.Unlock()
 			c.DebugLog.Log("reader reconnected for topic=%s", topic)
@@ -275,7 +296,7 @@ func (c *Client) readLoop(topic string, handler func(payload []byte)) {
 // reachability, and callers that want that must use LastPublish.
 //
 // Connect() performs no I/O — kafkago.TCP resolves lazily — so it cannot fail
-// except on an empty broker list, and this returns true from the first Connect
+// except on an empty broker list or unusable TLS/SASL settings, and this returns true from the first Connect
 // until Close regardless of whether the broker has been reachable since. The
 // drainer nonetheless keys its opening guard off this, and must: a false here
 // stops the drain entirely, so making it mean "reachable" would stop retrying
@@ -0,0 +1,55 @@
+package messaging
+
+import (
+	"context"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"shingo/shared/kafkasec"
+	"shingoedge/config"
+)
+
+// The writer, the reader and the broker test get their TLS and SASL from
+// shared/kafkasec, which Core uses too. This adapts the edge's KafkaConfig
+// to it.
+
+// kafkaSecurity validates k and returns its security settings.
+func kafkaSecurity(k config.KafkaConfig) (kafkasec.Config, error) {
+	if err := k.Validate(); err != nil {
+		return kafkasec.Config{}, err
+	}
+	return kafkasec.Config{TLS: kafkasec.TLSConfig(k.TLS), SASL: kafkasec.SASLConfig(k.SASL)}, nil
+}
+
+// newDialer returns the dialer for the reader and the broker test.
+func newDialer(k config.KafkaConfig) (*kafkago.Dialer, error) {
+	c, err := kafkaSecurity(k)
+	if err != nil {
+		return nil, err
+	}
+	return kafkasec.Dialer(c, k.DialTimeoutOr())
+}
+
+// newTransport returns the writer's transport, secured the same way.
+func newTransport(k config.KafkaConfig) (*kafkago.Transport, error) {
+	c, err := kafkaSecurity(k)
+	if err != nil {
+		return nil, err
+	}
+	return kafkasec.Transport(c, k.DialTimeoutOr())
+}
+
+// ProbeBroker dials broker with k's TLS and SASL settings, so a failed
+// handshake or a rejected password is reported by the config page's Test
+// button rather than discovered on the first publish.
+func ProbeBroker(ctx context.Context, k config.KafkaConfig, broker string) error {
+	dialer, err := newDialer(k)
+	if err != nil {
+		return err
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", broker)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
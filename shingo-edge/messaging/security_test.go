@@ -0,0 +1,86 @@
+package messaging
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"shingoedge/config"
+)
+
+func TestNewDialerAndTransportShareSecurity(t *testing.T) {
+	plain, err := newDialer(config.KafkaConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.TLS != nil || plain.SASLMechanism != nil {
+		t.Fatalf("plaintext config produced TLS=%v SASL=%v", plain.TLS, plain.SASLMechanism)
+	}
+	if plain.Timeout != 5*time.Second {
+		t.Errorf("dialer Timeout = %v, want the 5s default", plain.Timeout)
+	}
+
+	k := config.KafkaConfig{
+		TLS:  config.KafkaTLSConfig{Enabled: true, ServerName: "kafka.plant.local", InsecureSkipVerify: true},
+		SASL: config.KafkaSASLConfig{Mechanism: config.KafkaSASLPlain, Username: "line-1", Password: "pw"},
+	}
+	d, err := newDialer(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.TLS == nil || d.TLS.ServerName != "kafka.plant.local" || !d.TLS.InsecureSkipVerify || d.TLS.RootCAs != nil {
+		t.Fatalf("dialer TLS = %+v", d.TLS)
+	}
+	if d.SASLMechanism == nil || d.SASLMechanism.Name() != "PLAIN" {
+		t.Fatalf("dialer SASL = %v", d.SASLMechanism)
+	}
+	k.DialTimeout = time.Second
+	tr, err := newTransport(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.TLS == nil || tr.SASL == nil || tr.SASL.Name() != "PLAIN" {
+		t.Fatalf("transport TLS=%v SASL=%v — the writer must be secured like the reader", tr.TLS, tr.SASL)
+	}
+	if tr.DialTimeout != time.Second {
+		t.Errorf("transport DialTimeout = %v, want the configured 1s", tr.DialTimeout)
+	}
+
+	if _, err := newDialer(config.KafkaConfig{TLS: config.KafkaTLSConfig{Enabled: true, CAFile: "/nonexistent/ca.pem"}}); err == nil {
+		t.Error("missing CA bundle accepted")
+	}
+}
+
+// TestProbeBrokerNeedsTheHandshake checks the config page's Test button is
+// more than a TCP connect: a listener that accepts the connection but never
+// speaks TLS must fail the probe.
+func TestProbeBrokerNeedsTheHandshake(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	k := config.KafkaConfig{TLS: config.KafkaTLSConfig{Enabled: true}}
+	if err := ProbeBroker(ctx, k, ln.Addr().String()); err == nil {
+		t.Fatal("probe succeeded against a listener that never completed a TLS handshake")
+	}
+
+	k = config.KafkaConfig{SASL: config.KafkaSASLConfig{Mechanism: "gssapi", Username: "u"}}
+	if err := ProbeBroker(ctx, k, ln.Addr().String()); err == nil || !strings.Contains(err.Error(), "unknown mechanism") {
+		t.Fatalf("unusable settings: %v", err)
+	}
+}
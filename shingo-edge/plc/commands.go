@@ -0,0 +1,102 @@
+package plc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Command is the last value the edge commanded onto one tag, and what the
+// tag reads back now. Callers that drive outputs — stack lights, HMI bits,
+// watchdogs — write through Manager.Command so an operator can see what was
+// asked for next to what the PLC holds.
+type Command struct {
+	PLC    string    `json:"plc"`
+	Tag    string    `json:"tag"`
+	Source string    `json:"source"` // who commanded it, e.g. "node SNF2-IN state"
+	Value  any       `json:"value"`
+	At     time.Time `json:"at"`
+	// Error is the write's failure; empty when the PLC took the value.
+	Error string `json:"error,omitempty"`
+	// ReadBack is the tag's cached value, ReadBackError why there is none.
+	ReadBack      any    `json:"read_back"`
+	ReadBackError string `json:"read_back_error,omitempty"`
+	// Match is a successful write whose read-back equals it.
+	Match bool `json:"match"`
+}
+
+type commandKey struct{ plc, tag string }
+
+// commandLog has its own lock: a write takes as long as the PLC does, and
+// holding m.mu across it would stall every tag read.
+type commandLog struct {
+	mu    sync.Mutex
+	byKey map[commandKey]*Command
+}
+
+// Command writes value to a tag and records it as source's command. The
+// record is kept whether or not the write succeeds.
+func (m *Manager) Command(ctx context.Context, plcName, tagName, source string, value any) error {
+	err := m.WriteTagValue(ctx, plcName, tagName, value)
+	c := &Command{PLC: plcName, Tag: tagName, Source: source, Value: value, At: time.Now()}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	m.commands.mu.Lock()
+	m.commands.byKey[commandKey{plcName, tagName}] = c
+	m.commands.mu.Unlock()
+	return err
+}
+
+// Commands returns every recorded command with its read-back, by PLC then
+// tag.
+func (m *Manager) Commands() []Command {
+	m.commands.mu.Lock()
+	out := make([]Command, 0, len(m.commands.byKey))
+	for _, c := range m.commands.byKey {
+		out = append(out, *c)
+	}
+	m.commands.mu.Unlock()
+
+	for i := range out {
+		c := &out[i]
+		v, err := m.ReadTag(c.PLC, c.Tag)
+		if err != nil {
+			c.ReadBackError = err.Error()
+			continue
+		}
+		c.ReadBack = v
+		c.Match = c.Error == "" && SameValue(c.Value, v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PLC != out[j].PLC {
+			return out[i].PLC < out[j].PLC
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out
+}
+
+// SameValue reports whether a commanded value and a read-back agree. A PLC
+// hands back its own type — 1 written to a BOOL reads true, an int read
+// back from JSON is a float64 — so booleans and numbers compare as numbers
+// and everything else as text.
+func SameValue(a, b any) bool {
+	if x, ok := numeric(a); ok {
+		y, ok := numeric(b)
+		return ok && x == y
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func numeric(v any) (float64, bool) {
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+	return asFloat64(v)
+}
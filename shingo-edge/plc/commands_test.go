@@ -0,0 +1,61 @@
+package plc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingoedge/plc/modbus/modbustest"
+)
+
+func TestCommandsRecordWritesAndReadBack(t *testing.T) {
+	srv := modbustest.New()
+	mgr, _ := startModbusSource(t, srv, nil)
+	ctx := context.Background()
+
+	if err := mgr.Command(ctx, "press2", "Andon", "node A state", 1); err != nil {
+		t.Fatalf("command andon: %v", err)
+	}
+	if err := mgr.Command(ctx, "press2", "Count", "node B state", 3); err == nil {
+		t.Fatal("command to a read-only register succeeded")
+	}
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool {
+		cmds := mgr.Commands()
+		return len(cmds) == 2 && cmds[0].Tag == "Andon" && cmds[0].Match
+	})
+	cmds := mgr.Commands()
+	if cmds[0].ReadBack != true || cmds[0].Source != "node A state" {
+		t.Errorf("andon = %+v", cmds[0])
+	}
+	if c := cmds[1]; c.Tag != "Count" || c.Error == "" || c.Match {
+		t.Errorf("count = %+v; want a recorded failure", c)
+	}
+
+	// The PLC changing the coil behind the edge's back shows as a mismatch.
+	srv.SetCoil(5, false)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool {
+		return !mgr.Commands()[0].Match
+	})
+}
+
+func TestSameValue(t *testing.T) {
+	cases := []struct {
+		a, b any
+		want bool
+	}{
+		{1, true, true},
+		{0, false, true},
+		{2, float64(2), true},
+		{int64(4), 4, true},
+		{1, 2, false},
+		{"RED", "RED", true},
+		{"1", 1, true}, // a numeric string reads back as its number
+		{1, nil, false},
+	}
+	for _, c := range cases {
+		if got := SameValue(c.a, c.b); got != c.want {
+			t.Errorf("SameValue(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -42,3 +42,18 @@ func tryRollover(lastCount, newCount, jumpThreshold int64) int64 {
 	}
 	return 0
 }
+
+// CalculateDeltaWrap is CalculateDelta for a counter whose range is known: it
+// wraps to zero at wrap (65536 for an unsigned 16-bit register), so a backward
+// step is measured against that one width rather than whichever of 16 or 32
+// bits makes it plausible. A 32-bit counter dropping from 60000 to 10 is then
+// a reset, not a 16-bit rollover. A wrap of 0 is unknown: CalculateDelta.
+func CalculateDeltaWrap(lastCount, newCount, wrap, jumpThreshold int64) (delta int64, anomaly string) {
+	if wrap <= 0 || newCount >= lastCount {
+		return CalculateDelta(lastCount, newCount, jumpThreshold)
+	}
+	if d := wrap - lastCount + newCount; d > 0 && d <= jumpThreshold {
+		return d, ""
+	}
+	return newCount, "reset"
+}
@@ -88,6 +88,11 @@ type Manager struct {
 	emitter EventEmitter
 	wl      WarlinkClient
 	plcs    map[string]*ManagedPLC
+	// sources are the PLCs under plc_sources, by name. Written once by
+	// StartSources; their names are off limits to every WarLink path.
+	sources map[string]source
+	// commands is the last value each Command wrote, by PLC and tag.
+	commands commandLog
 
 	DebugLog DebugLogFunc
 
@@ -156,11 +161,13 @@ func NewManager(db *store.DB, cfg *config.Config, emitter EventEmitter, wl Warli
 		wl = NewWarlinkClient(fmt.Sprintf("http://%s:%d/api", cfg.WarLink.Host, cfg.WarLink.Port))
 	}
 	return &Manager{
-		db:      db,
-		cfg:     cfg,
-		emitter: emitter,
-		wl:      wl,
-		plcs:    make(map[string]*ManagedPLC),
+		db:       db,
+		cfg:      cfg,
+		emitter:  emitter,
+		wl:       wl,
+		plcs:     make(map[string]*ManagedPLC),
+		sources:  make(map[string]source),
+		commands: commandLog{byKey: make(map[commandKey]*Command)},
 
 		stopChan: make(chan struct{}),
 	}
@@ -224,7 +231,10 @@ func (m *Manager) StopWarLinkPoller() {
 		m.warlinkError = nil
 	}
 	var disconnectedPLCs []string
-	for _, mp := range m.plcs {
+	for name, mp := range m.plcs {
+		if _, sourced := m.sources[name]; sourced {
+			continue
+		}
 		mp.mu.Lock()
 		if mp.Status == "Connected" {
 			mp.Status = "Disconnected"
@@ -342,13 +352,10 @@ func (m *Manager) warlinkSync(ownsConnState bool) {
 	for _, p := range plcs {
 		seen[p.Name] = true
 
-		m.mu.Lock()
-		existing, exists := m.plcs[p.Name]
-		if !exists {
-			existing = newManagedPLC(p.Name)
-			m.plcs[p.Name] = existing
+		existing := m.warlinkPLC(p.Name)
+		if existing == nil {
+			continue // a native source owns this name
 		}
-		m.mu.Unlock()
 
 		effectiveStatus := p.Status
 		effectiveErr := p.Error
@@ -418,7 +425,7 @@ func (m *Manager) warlinkSync(ownsConnState bool) {
 	m.mu.Lock()
 	var evicted, disconnected []string
 	for name, mp := range m.plcs {
-		if seen[name] {
+		if _, sourced := m.sources[name]; seen[name] || sourced {
 			continue
 		}
 		delete(m.plcs, name)
@@ -683,14 +690,14 @@ func (m *Manager) pollReportingPoint(rp counters.ReportingPoint) {
 		return
 	}
 
-	newCount, ok := toInt64(val)
+	newCount, ok := ToInt64(val)
 	if !ok {
 		return
 	}
 
 	m.emitter.EmitCounterRead(rp.ID, rp.PLCName, rp.TagName, newCount)
 
-	delta, anomaly := CalculateDelta(rp.LastCount, newCount, m.cfg.Counter.JumpThreshold)
+	delta, anomaly := CalculateDeltaWrap(rp.LastCount, newCount, m.counterWrap(rp.PLCName, rp.TagName), m.cfg.Counter.JumpThreshold)
 	if delta == 0 && anomaly == "" {
 		return
 	}
@@ -807,7 +814,11 @@ func isConnectionLevelTagError(err string) bool {
 		strings.Contains(lower, "session") && strings.Contains(lower, "closed")
 }
 
-func toInt64(v any) (int64, bool) {
+// ToInt64 reads a numeric tag value as an integer, whatever width the source
+// cached it at — WarLink's JSON numbers, Modbus registers, and every OPC UA
+// integer type (Int16 arrives as int16, SByte as int8, and so on). Floats
+// truncate. Anything else, bool included, is not a number.
+func ToInt64(v any) (int64, bool) {
 	switch n := v.(type) {
 	case int64:
 		return n, true
@@ -815,14 +826,20 @@ func toInt64(v any) (int64, bool) {
 		return int64(n), true
 	case int16:
 		return int64(n), true
+	case int8:
+		return int64(n), true
 	case int:
 		return int64(n), true
+	case uint:
+		return int64(n), true
 	case uint64:
 		return int64(n), true
 	case uint32:
 		return int64(n), true
 	case uint16:
 		return int64(n), true
+	case uint8:
+		return int64(n), true
 	case float64:
 		return int64(n), true
 	case float32:
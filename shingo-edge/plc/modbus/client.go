@@ -0,0 +1,305 @@
+// Package modbus is a Modbus TCP client for the PLC manager: reads of the
+// four tables (coils, discrete inputs, holding and input registers) and
+// writes of single coils and register runs — what a counter or an andon
+// signal on an older press needs.
+//
+// It exists so a press that only speaks Modbus does not need a converter box
+// in front of WarLink. plc.Manager drives it; nothing here knows about tags,
+// word order or scaling.
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Function codes.
+const (
+	FuncReadCoils              byte = 1
+	FuncReadDiscreteInputs     byte = 2
+	FuncReadHoldingRegisters   byte = 3
+	FuncReadInputRegisters     byte = 4
+	FuncWriteSingleCoil        byte = 5
+	FuncWriteSingleRegister    byte = 6
+	FuncWriteMultipleRegisters byte = 16
+)
+
+// Protocol limits on one request's quantity.
+const (
+	MaxReadRegisters  = 125
+	MaxReadBits       = 2000
+	MaxWriteRegisters = 123
+)
+
+// DefaultPort is the Modbus TCP port.
+const DefaultPort = "502"
+
+// requestTimeout bounds a request whose context has no deadline. A device on
+// the same network answers in milliseconds; one that takes seconds is gone.
+const requestTimeout = 3 * time.Second
+
+// mbapLen is the Modbus application header: transaction id, protocol id,
+// length and unit id.
+const mbapLen = 7
+
+// ErrClosed is returned by calls on a closed or broken client.
+var ErrClosed = errors.New("modbus: connection closed")
+
+// Exception is a device's refusal of a request. The connection is still good.
+type Exception struct {
+	Function byte
+	Code     byte
+}
+
+// Exception codes.
+const (
+	ExceptionIllegalFunction    byte = 1
+	ExceptionIllegalDataAddress byte = 2
+	ExceptionIllegalDataValue   byte = 3
+	ExceptionDeviceFailure      byte = 4
+)
+
+func (e *Exception) Error() string {
+	var what string
+	switch e.Code {
+	case ExceptionIllegalFunction:
+		what = "illegal function"
+	case ExceptionIllegalDataAddress:
+		what = "illegal data address"
+	case ExceptionIllegalDataValue:
+		what = "illegal data value"
+	case ExceptionDeviceFailure:
+		what = "server device failure"
+	default:
+		what = fmt.Sprintf("exception %d", e.Code)
+	}
+	return fmt.Sprintf("modbus function %d: %s", e.Function, what)
+}
+
+// Client is one Modbus TCP connection. Requests go one at a time: plenty of
+// devices answer pipelined requests out of order or not at all. A transport
+// error breaks the client for good; the caller dials a new one.
+type Client struct {
+	conn net.Conn
+	unit byte
+
+	mu  sync.Mutex
+	tid uint16
+	err error
+}
+
+// Dial connects to host:port (port 502 when omitted) and addresses requests
+// to unit.
+func Dial(ctx context.Context, address string, unit byte) (*Client, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+	dctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	var d net.Dialer
+	nc, err := d.DialContext(dctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("modbus dial %s: %w", address, err)
+	}
+	return &Client{conn: nc, unit: unit}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = ErrClosed
+	}
+	return c.conn.Close()
+}
+
+// ReadHoldingRegisters reads qty holding registers from addr.
+func (c *Client) ReadHoldingRegisters(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(ctx, FuncReadHoldingRegisters, addr, qty)
+}
+
+// ReadInputRegisters reads qty input registers from addr.
+func (c *Client) ReadInputRegisters(ctx context.Context, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(ctx, FuncReadInputRegisters, addr, qty)
+}
+
+// ReadCoils reads qty coils from addr.
+func (c *Client) ReadCoils(ctx context.Context, addr, qty uint16) ([]bool, error) {
+	return c.readBits(ctx, FuncReadCoils, addr, qty)
+}
+
+// ReadDiscreteInputs reads qty discrete inputs from addr.
+func (c *Client) ReadDiscreteInputs(ctx context.Context, addr, qty uint16) ([]bool, error) {
+	return c.readBits(ctx, FuncReadDiscreteInputs, addr, qty)
+}
+
+func (c *Client) readRegisters(ctx context.Context, fn byte, addr, qty uint16) ([]uint16, error) {
+	if qty == 0 || qty > MaxReadRegisters {
+		return nil, fmt.Errorf("modbus: read of %d registers; 1..%d allowed", qty, MaxReadRegisters)
+	}
+	resp, err := c.do(ctx, fn, be16(addr, qty))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 1 || int(resp[0]) != 2*int(qty) || len(resp) != 1+2*int(qty) {
+		return nil, c.broken(fmt.Errorf("modbus function %d: %d-byte reply to a read of %d registers", fn, len(resp), qty))
+	}
+	out := make([]uint16, qty)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint16(resp[1+2*i:])
+	}
+	return out, nil
+}
+
+func (c *Client) readBits(ctx context.Context, fn byte, addr, qty uint16) ([]bool, error) {
+	if qty == 0 || qty > MaxReadBits {
+		return nil, fmt.Errorf("modbus: read of %d bits; 1..%d allowed", qty, MaxReadBits)
+	}
+	resp, err := c.do(ctx, fn, be16(addr, qty))
+	if err != nil {
+		return nil, err
+	}
+	n := (int(qty) + 7) / 8
+	if len(resp) < 1 || int(resp[0]) != n || len(resp) != 1+n {
+		return nil, c.broken(fmt.Errorf("modbus function %d: %d-byte reply to a read of %d bits", fn, len(resp), qty))
+	}
+	out := make([]bool, qty)
+	for i := range out {
+		out[i] = resp[1+i/8]&(1<<(i%8)) != 0
+	}
+	return out, nil
+}
+
+// WriteCoil sets one coil.
+func (c *Client) WriteCoil(ctx context.Context, addr uint16, on bool) error {
+	var v uint16
+	if on {
+		v = 0xFF00
+	}
+	req := be16(addr, v)
+	resp, err := c.do(ctx, FuncWriteSingleCoil, req)
+	if err != nil {
+		return err
+	}
+	if string(resp) != string(req) {
+		return c.broken(fmt.Errorf("modbus function %d: reply does not echo the request", FuncWriteSingleCoil))
+	}
+	return nil
+}
+
+// WriteRegisters writes a run of holding registers from addr. One register
+// goes as function 6, which some devices accept where they refuse 16.
+func (c *Client) WriteRegisters(ctx context.Context, addr uint16, values []uint16) error {
+	switch {
+	case len(values) == 0 || len(values) > MaxWriteRegisters:
+		return fmt.Errorf("modbus: write of %d registers; 1..%d allowed", len(values), MaxWriteRegisters)
+	case len(values) == 1:
+		req := be16(addr, values[0])
+		resp, err := c.do(ctx, FuncWriteSingleRegister, req)
+		if err != nil {
+			return err
+		}
+		if string(resp) != string(req) {
+			return c.broken(fmt.Errorf("modbus function %d: reply does not echo the request", FuncWriteSingleRegister))
+		}
+		return nil
+	}
+	qty := uint16(len(values))
+	req := append(be16(addr, qty), byte(2*qty))
+	req = append(req, be16(values...)...)
+	resp, err := c.do(ctx, FuncWriteMultipleRegisters, req)
+	if err != nil {
+		return err
+	}
+	if string(resp) != string(req[:4]) {
+		return c.broken(fmt.Errorf("modbus function %d: reply does not echo the address and quantity", FuncWriteMultipleRegisters))
+	}
+	return nil
+}
+
+// do sends one request PDU and returns the reply's data, or the device's
+// *Exception.
+func (c *Client) do(ctx context.Context, fn byte, data []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(requestTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, c.brokenLocked(err)
+	}
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c.tid++
+	frame := make([]byte, mbapLen, mbapLen+1+len(data))
+	binary.BigEndian.PutUint16(frame[0:], c.tid)
+	binary.BigEndian.PutUint16(frame[4:], uint16(2+len(data)))
+	frame[6] = c.unit
+	frame = append(frame, fn)
+	frame = append(frame, data...)
+	if _, err := c.conn.Write(frame); err != nil {
+		return nil, c.brokenLocked(err)
+	}
+
+	var hdr [mbapLen]byte
+	if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
+		return nil, c.brokenLocked(err)
+	}
+	n := int(binary.BigEndian.Uint16(hdr[4:]))
+	if n < 2 || n > 254 {
+		return nil, c.brokenLocked(fmt.Errorf("modbus: reply length %d", n))
+	}
+	pdu := make([]byte, n-1)
+	if _, err := io.ReadFull(c.conn, pdu); err != nil {
+		return nil, c.brokenLocked(err)
+	}
+	if tid := binary.BigEndian.Uint16(hdr[0:]); tid != c.tid {
+		return nil, c.brokenLocked(fmt.Errorf("modbus: reply to transaction %d, want %d", tid, c.tid))
+	}
+	switch pdu[0] {
+	case fn:
+		return pdu[1:], nil
+	case fn | 0x80:
+		if len(pdu) < 2 {
+			return nil, c.brokenLocked(fmt.Errorf("modbus function %d: truncated exception", fn))
+		}
+		return nil, &Exception{Function: fn, Code: pdu[1]}
+	}
+	return nil, c.brokenLocked(fmt.Errorf("modbus: reply for function %d to a request for %d", pdu[0], fn))
+}
+
+// broken marks the client unusable: after a malformed or lost reply the
+// stream cannot be trusted to be at a frame boundary.
+func (c *Client) broken(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.brokenLocked(err)
+}
+
+func (c *Client) brokenLocked(err error) error {
+	if c.err == nil {
+		c.err = err
+		c.conn.Close()
+	}
+	return err
+}
+
+func be16(vs ...uint16) []byte {
+	b := make([]byte, 2*len(vs))
+	for i, v := range vs {
+		binary.BigEndian.PutUint16(b[2*i:], v)
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package modbus_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"shingoedge/plc/modbus"
+	"shingoedge/plc/modbus/modbustest"
+)
+
+func startServer(t *testing.T, srv *modbustest.Server) *modbus.Client {
+	t.Helper()
+	addr, err := srv.Start()
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	c, err := modbus.Dial(context.Background(), addr, 1)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestReadAndWriteTables(t *testing.T) {
+	srv := modbustest.New()
+	srv.SetHolding(10, 1, 2, 3)
+	srv.SetInput(0, 0xBEEF)
+	srv.SetCoil(9, true)
+	srv.SetDiscrete(0, true)
+	c := startServer(t, srv)
+	ctx := context.Background()
+
+	if got, err := c.ReadHoldingRegisters(ctx, 10, 3); err != nil || !reflect.DeepEqual(got, []uint16{1, 2, 3}) {
+		t.Errorf("holding = %v, %v", got, err)
+	}
+	if got, err := c.ReadInputRegisters(ctx, 0, 1); err != nil || got[0] != 0xBEEF {
+		t.Errorf("input = %v, %v", got, err)
+	}
+	// Ten bits span two bytes; bit 9 is the second byte's bit 1.
+	if got, err := c.ReadCoils(ctx, 0, 10); err != nil || !got[9] || got[8] {
+		t.Errorf("coils = %v, %v", got, err)
+	}
+	if got, err := c.ReadDiscreteInputs(ctx, 0, 1); err != nil || !got[0] {
+		t.Errorf("discrete = %v, %v", got, err)
+	}
+
+	if err := c.WriteRegisters(ctx, 20, []uint16{7}); err != nil {
+		t.Fatalf("write one register: %v", err)
+	}
+	if err := c.WriteRegisters(ctx, 21, []uint16{8, 9}); err != nil {
+		t.Fatalf("write two registers: %v", err)
+	}
+	if got := srv.Holding(20, 3); !reflect.DeepEqual(got, []uint16{7, 8, 9}) {
+		t.Errorf("server holding = %v", got)
+	}
+	if err := c.WriteCoil(ctx, 3, true); err != nil || !srv.Coil(3) {
+		t.Errorf("write coil: %v, coil = %v", err, srv.Coil(3))
+	}
+}
+
+func TestExceptionLeavesConnectionUsable(t *testing.T) {
+	srv := modbustest.New()
+	srv.Limit = 100
+	c := startServer(t, srv)
+	ctx := context.Background()
+
+	_, err := c.ReadHoldingRegisters(ctx, 99, 2)
+	var exc *modbus.Exception
+	if !errors.As(err, &exc) || exc.Code != modbus.ExceptionIllegalDataAddress {
+		t.Fatalf("read past the limit = %v, want illegal data address", err)
+	}
+	if _, err := c.ReadHoldingRegisters(ctx, 0, 1); err != nil {
+		t.Errorf("read after an exception: %v", err)
+	}
+	if _, err := c.ReadHoldingRegisters(ctx, 0, 126); err == nil {
+		t.Error("a 126-register read was sent")
+	}
+}
+
+func TestDroppedConnectionBreaksClient(t *testing.T) {
+	srv := modbustest.New()
+	c := startServer(t, srv)
+	srv.DropConnections()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := c.ReadHoldingRegisters(ctx, 0, 1); err == nil {
+		t.Fatal("read on a dropped connection succeeded")
+	}
+	if _, err := c.ReadHoldingRegisters(ctx, 0, 1); err == nil {
+		t.Fatal("a broken client read again")
+	}
+}
@@ -0,0 +1,254 @@
+// Package modbustest is an in-process Modbus TCP server for tests: the four
+// tables, each 65536 entries, any unit id, and functions 1-6 and 16 — enough
+// to stand in for a press's counter registers and andon coils.
+package modbustest
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+)
+
+// Server is a fake Modbus TCP device with one flat register space shared by
+// every unit id. Functions other than 1-6 and 16 get exception 1 (illegal
+// function), an address past Limit exception 2, and a malformed quantity or
+// coil value exception 3 — the three a driver has to tell apart. Set Limit
+// before Start; the tables may be set at any time.
+type Server struct {
+	// Limit, when non-zero, makes every address at or above it illegal in
+	// all four tables, as an unmapped range on a real device is.
+	Limit int
+
+	mu       sync.Mutex
+	holding  [65536]uint16
+	input    [65536]uint16
+	coils    [65536]bool
+	discrete [65536]bool
+	requests int
+	ln       net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// New returns a server with every register and bit zero.
+func New() *Server {
+	return &Server{conns: map[net.Conn]struct{}{}}
+}
+
+// SetHolding writes holding registers from addr.
+func (s *Server) SetHolding(addr uint16, vs ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copy(s.holding[addr:], vs)
+}
+
+// Holding reads n holding registers from addr.
+func (s *Server) Holding(addr uint16, n int) []uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]uint16(nil), s.holding[addr:int(addr)+n]...)
+}
+
+// SetInput writes input registers from addr.
+func (s *Server) SetInput(addr uint16, vs ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copy(s.input[addr:], vs)
+}
+
+// SetCoil sets one coil.
+func (s *Server) SetCoil(addr uint16, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coils[addr] = on
+}
+
+// Coil reads one coil.
+func (s *Server) Coil(addr uint16) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coils[addr]
+}
+
+// SetDiscrete sets one discrete input.
+func (s *Server) SetDiscrete(addr uint16, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discrete[addr] = on
+}
+
+// Requests is how many requests the server has answered.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Start listens on a loopback port and returns its host:port.
+func (s *Server) Start() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.accept(ln)
+	return ln.Addr().String(), nil
+}
+
+func (s *Server) accept(ln net.Listener) {
+	defer s.wg.Done()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(c)
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+			c.Close()
+		}()
+	}
+}
+
+// Close stops accepting Modbus connections, closes the open ones mid-frame if
+// need be, and waits for their goroutines. Register and coil values are kept.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.ln != nil {
+		s.ln.Close()
+	}
+	s.mu.Unlock()
+	s.DropConnections()
+	s.wg.Wait()
+}
+
+// DropConnections closes every client connection, as a press power-cycle
+// would. The server keeps listening.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.Close()
+	}
+}
+
+func (s *Server) serve(c net.Conn) {
+	var hdr [7]byte
+	for {
+		if _, err := io.ReadFull(c, hdr[:]); err != nil {
+			return
+		}
+		n := int(binary.BigEndian.Uint16(hdr[4:]))
+		if n < 2 || n > 254 {
+			return
+		}
+		pdu := make([]byte, n-1)
+		if _, err := io.ReadFull(c, pdu); err != nil {
+			return
+		}
+		reply := s.handle(pdu)
+		out := make([]byte, 7, 7+len(reply))
+		copy(out, hdr[:4])
+		binary.BigEndian.PutUint16(out[4:], uint16(1+len(reply)))
+		out[6] = hdr[6]
+		if _, err := c.Write(append(out, reply...)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(pdu []byte) []byte {
+	fn := pdu[0]
+	fail := func(code byte) []byte { return []byte{fn | 0x80, code} }
+	if len(pdu) < 5 {
+		return fail(3)
+	}
+	addr := int(binary.BigEndian.Uint16(pdu[1:]))
+	arg := binary.BigEndian.Uint16(pdu[3:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	legal := func(qty int) bool {
+		end := addr + qty
+		return end <= 65536 && (s.Limit == 0 || end <= s.Limit)
+	}
+	switch fn {
+	case 1, 2:
+		qty := int(arg)
+		if qty < 1 || qty > 2000 {
+			return fail(3)
+		}
+		if !legal(qty) {
+			return fail(2)
+		}
+		table := s.coils[:]
+		if fn == 2 {
+			table = s.discrete[:]
+		}
+		out := []byte{fn, byte((qty + 7) / 8)}
+		out = append(out, make([]byte, (qty+7)/8)...)
+		for i := 0; i < qty; i++ {
+			if table[addr+i] {
+				out[2+i/8] |= 1 << (i % 8)
+			}
+		}
+		return out
+	case 3, 4:
+		qty := int(arg)
+		if qty < 1 || qty > 125 {
+			return fail(3)
+		}
+		if !legal(qty) {
+			return fail(2)
+		}
+		table := s.holding[:]
+		if fn == 4 {
+			table = s.input[:]
+		}
+		out := []byte{fn, byte(2 * qty)}
+		for i := 0; i < qty; i++ {
+			out = binary.BigEndian.AppendUint16(out, table[addr+i])
+		}
+		return out
+	case 5:
+		if arg != 0 && arg != 0xFF00 {
+			return fail(3)
+		}
+		if !legal(1) {
+			return fail(2)
+		}
+		s.coils[addr] = arg == 0xFF00
+		return pdu[:5]
+	case 6:
+		if !legal(1) {
+			return fail(2)
+		}
+		s.holding[addr] = arg
+		return pdu[:5]
+	case 16:
+		qty := int(arg)
+		if qty < 1 || qty > 123 || len(pdu) != 6+2*qty || int(pdu[5]) != 2*qty {
+			return fail(3)
+		}
+		if !legal(qty) {
+			return fail(2)
+		}
+		for i := 0; i < qty; i++ {
+			s.holding[addr+i] = binary.BigEndian.Uint16(pdu[6+2*i:])
+		}
+		return pdu[:5]
+	}
+	return fail(1)
+}
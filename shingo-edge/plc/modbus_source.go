@@ -0,0 +1,455 @@
+package plc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"shingoedge/config"
+	"shingoedge/plc/modbus"
+)
+
+// modbusDriver is a Modbus source's PLCHealth.Driver.
+const modbusDriver = "modbus"
+
+// modbusTag is one configured register, resolved.
+type modbusTag struct {
+	name     string
+	table    string
+	addr     uint16
+	words    int // registers spanned; 0 for a coil or discrete input
+	signed   bool
+	little   bool // low word first
+	scale    float64
+	writable bool
+}
+
+// bits is the tag's width, 1 for a bit table.
+func (t modbusTag) bits() int {
+	if t.words == 0 {
+		return 1
+	}
+	return 16 * t.words
+}
+
+// integral reports whether values stay integers: a whole-number scale.
+func (t modbusTag) integral() bool { return t.scale == math.Trunc(t.scale) }
+
+func (t modbusTag) typeStr() string {
+	switch {
+	case t.words == 0:
+		return "Boolean"
+	case !t.integral():
+		return "Double"
+	case t.signed:
+		return fmt.Sprintf("Int%d", t.bits())
+	}
+	return fmt.Sprintf("UInt%d", t.bits())
+}
+
+// decode turns the tag's registers into its value: int64 for a whole-number
+// scale, float64 otherwise.
+func (t modbusTag) decode(words []uint16) any {
+	var raw uint64
+	for i := range words {
+		w := words[i]
+		if t.little {
+			w = words[len(words)-1-i]
+		}
+		raw = raw<<16 | uint64(w)
+	}
+	n := int64(raw)
+	if bits := t.bits(); t.signed && bits < 64 {
+		n = int64(raw<<(64-bits)) >> (64 - bits) // sign-extend
+	}
+	if t.integral() {
+		return n * int64(t.scale)
+	}
+	return float64(n) * t.scale
+}
+
+// encode is decode's inverse for a write. The value divided by the scale must
+// be a whole number that fits the register.
+func (t modbusTag) encode(v any) ([]uint16, error) {
+	var n int64
+	if t.scale == 1 {
+		i, ok := asInt64(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot write %T %v to a %s register", v, v, t.typeStr())
+		}
+		n = i
+	} else {
+		f, ok := asFloat64(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot write %T %v to a %s register", v, v, t.typeStr())
+		}
+		raw := f / t.scale
+		if raw != math.Trunc(raw) || raw < math.MinInt64 || raw >= math.MaxInt64 {
+			return nil, fmt.Errorf("%v is not a multiple of the register's scale %g", v, t.scale)
+		}
+		n = int64(raw)
+	}
+	bits := t.bits()
+	lo, hi := int64(0), int64(math.MaxInt64)
+	switch {
+	case t.signed && bits < 64:
+		lo, hi = -1<<(bits-1), 1<<(bits-1)-1
+	case t.signed:
+		lo = math.MinInt64
+	case bits < 64:
+		hi = 1<<bits - 1
+	}
+	if n < lo || n > hi {
+		return nil, fmt.Errorf("%v is out of range for a %s register", v, t.typeStr())
+	}
+	words := make([]uint16, t.words)
+	raw := uint64(n)
+	for i := t.words - 1; i >= 0; i-- {
+		words[i] = uint16(raw)
+		raw >>= 16
+	}
+	if t.little {
+		for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+			words[i], words[j] = words[j], words[i]
+		}
+	}
+	return words, nil
+}
+
+// modbusSpan is one read covering adjacent tags in one table.
+type modbusSpan struct {
+	table string
+	addr  uint16
+	qty   uint16
+	tags  []modbusTag
+}
+
+// modbusSource polls one Modbus TCP device. Its tags are the configured
+// register map — Modbus has nothing to browse — read each interval in as few
+// requests as the map allows and written into the same cache the WarLink
+// stream feeds, where pollReportingPoint picks counters up.
+//
+// A device's refusal of a read (an exception: usually an address the device
+// does not map) marks the tags in that read bad and leaves the PLC connected;
+// only a transport failure disconnects it.
+type modbusSource struct {
+	m     *Manager
+	cfg   config.PLCSourceConfig
+	tags  map[string]modbusTag
+	spans []modbusSpan
+
+	mu     sync.Mutex
+	client *modbus.Client // nil while disconnected
+}
+
+// newModbusSource resolves the register map. A register that makes no sense
+// is logged and left out rather than failing the rest of the device.
+func newModbusSource(m *Manager, cfg config.PLCSourceConfig) *modbusSource {
+	s := &modbusSource{m: m, cfg: cfg, tags: map[string]modbusTag{}}
+	for _, r := range cfg.Registers {
+		t, err := resolveModbusRegister(r)
+		if err == nil {
+			if _, dup := s.tags[t.name]; dup {
+				err = errors.New("tag listed twice")
+			}
+		}
+		if err != nil {
+			log.Printf("plc source %q: register %q: %v; skipped", cfg.Name, r.Tag, err)
+			continue
+		}
+		s.tags[t.name] = t
+	}
+	s.spans = modbusSpans(s.tags)
+	return s
+}
+
+func resolveModbusRegister(r config.ModbusRegister) (modbusTag, error) {
+	t := modbusTag{name: r.Tag, table: r.Table, addr: r.Address, signed: r.Signed, scale: r.Scale, writable: r.Writable}
+	if t.name == "" {
+		return t, errors.New("no tag name")
+	}
+	if t.table == "" {
+		t.table = config.ModbusHolding
+	}
+	if t.scale == 0 {
+		t.scale = 1
+	}
+	switch t.table {
+	case config.ModbusHolding, config.ModbusInput:
+		switch r.Width {
+		case 0, 16:
+			t.words = 1
+		case 32:
+			t.words = 2
+		case 64:
+			t.words = 4
+		default:
+			return t, fmt.Errorf("width %d; want 16, 32 or 64", r.Width)
+		}
+	case config.ModbusCoil, config.ModbusDiscrete:
+	default:
+		return t, fmt.Errorf("table %q; want holding, input, coil or discrete", r.Table)
+	}
+	switch r.WordOrder {
+	case "", "big":
+	case "little":
+		t.little = true
+	default:
+		return t, fmt.Errorf("word order %q; want big or little", r.WordOrder)
+	}
+	if int(t.addr)+max(t.words, 1) > 1<<16 {
+		return t, fmt.Errorf("address %d runs past the end of the table", t.addr)
+	}
+	if t.writable && (t.table == config.ModbusInput || t.table == config.ModbusDiscrete) {
+		return t, fmt.Errorf("the %s table is read-only", t.table)
+	}
+	return t, nil
+}
+
+// modbusSpans groups tags into reads: per table, by address, a run of tags
+// that touch or overlap shares one request up to the protocol's per-request
+// limit. Gaps are never read across — a device may refuse an address it does
+// not map, and one refusal would take every tag in the read with it.
+func modbusSpans(tags map[string]modbusTag) []modbusSpan {
+	sorted := make([]modbusTag, 0, len(tags))
+	for _, t := range tags {
+		sorted = append(sorted, t)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].table != sorted[j].table {
+			return sorted[i].table < sorted[j].table
+		}
+		if sorted[i].addr != sorted[j].addr {
+			return sorted[i].addr < sorted[j].addr
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	var spans []modbusSpan
+	for _, t := range sorted {
+		limit, n := modbus.MaxReadRegisters, t.words
+		if t.words == 0 {
+			limit, n = modbus.MaxReadBits, 1
+		}
+		end := int(t.addr) + n
+		if k := len(spans) - 1; k >= 0 {
+			sp := &spans[k]
+			spEnd := int(sp.addr) + int(sp.qty)
+			if sp.table == t.table && int(t.addr) <= spEnd && max(end, spEnd)-int(sp.addr) <= limit {
+				sp.qty = uint16(max(end, spEnd) - int(sp.addr))
+				sp.tags = append(sp.tags, t)
+				continue
+			}
+		}
+		spans = append(spans, modbusSpan{table: t.table, addr: t.addr, qty: uint16(n), tags: []modbusTag{t}})
+	}
+	return spans
+}
+
+// run reconnects with the backoff the other sources use until the manager
+// stops.
+func (s *modbusSource) run() {
+	attempt := 0
+	for {
+		up, err := s.connect()
+		if err == nil {
+			return // stopping
+		}
+		log.Printf("PLC %s (Modbus %s): %v", s.cfg.Name, s.cfg.Endpoint, err)
+		s.m.applySourceStatus(s.cfg.Name, modbusDriver, false, err)
+		if up {
+			attempt = 0
+		}
+		attempt++
+		if !s.m.sourceBackoff(s.cfg.Name, attempt) {
+			return
+		}
+	}
+}
+
+// connect runs one connection: dial, poll until the transport fails or the
+// manager stops. The PLC is Connected once the first full poll is in the
+// cache, so a reporting point never reads a device that has not answered.
+func (s *modbusSource) connect() (up bool, err error) {
+	ctx, cancel := s.m.sourceContext()
+	defer cancel()
+
+	c, err := modbus.Dial(ctx, s.cfg.Endpoint, s.cfg.UnitID)
+	if err != nil {
+		return false, s.m.unlessStopping(err)
+	}
+	defer func() {
+		s.mu.Lock()
+		s.client = nil
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	s.m.mu.RLock()
+	mp := s.m.plcs[s.cfg.Name]
+	s.m.mu.RUnlock()
+	if err := s.poll(ctx, c, mp); err != nil {
+		return false, s.m.unlessStopping(err)
+	}
+	s.mu.Lock()
+	s.client = c
+	s.mu.Unlock()
+	s.m.applySourceStatus(s.cfg.Name, modbusDriver, true, nil)
+
+	ticker := time.NewTicker(s.cfg.Interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case <-ticker.C:
+			if err := s.poll(ctx, c, mp); err != nil {
+				return true, s.m.unlessStopping(err)
+			}
+		}
+	}
+}
+
+// poll reads every span once into the cache.
+func (s *modbusSource) poll(ctx context.Context, c *modbus.Client, mp *ManagedPLC) error {
+	for _, sp := range s.spans {
+		words, bits, err := readSpan(ctx, c, sp.table, sp.addr, sp.qty)
+		var exc *modbus.Exception
+		if errors.As(err, &exc) {
+			for _, t := range sp.tags {
+				s.m.applyValueChange(mp, TagValue{Name: t.name, TypeStr: t.typeStr(), Error: exc.Error()})
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, t := range sp.tags {
+			off := int(t.addr - sp.addr)
+			tv := TagValue{Name: t.name, TypeStr: t.typeStr()}
+			if t.words == 0 {
+				tv.Value = bits[off]
+			} else {
+				tv.Value = t.decode(words[off : off+t.words])
+			}
+			s.m.applyValueChange(mp, tv)
+		}
+	}
+	return nil
+}
+
+func readSpan(ctx context.Context, c *modbus.Client, table string, addr, qty uint16) ([]uint16, []bool, error) {
+	switch table {
+	case config.ModbusInput:
+		w, err := c.ReadInputRegisters(ctx, addr, qty)
+		return w, nil, err
+	case config.ModbusCoil:
+		b, err := c.ReadCoils(ctx, addr, qty)
+		return nil, b, err
+	case config.ModbusDiscrete:
+		b, err := c.ReadDiscreteInputs(ctx, addr, qty)
+		return nil, b, err
+	}
+	w, err := c.ReadHoldingRegisters(ctx, addr, qty)
+	return w, nil, err
+}
+
+func (s *modbusSource) lookup(tag string) (*modbus.Client, modbusTag, error) {
+	t, ok := s.tags[tag]
+	if !ok {
+		return nil, t, fmt.Errorf("tag %s not found on %s", tag, s.cfg.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		return nil, t, fmt.Errorf("PLC %s not connected", s.cfg.Name)
+	}
+	return s.client, t, nil
+}
+
+func (s *modbusSource) readTag(ctx context.Context, tag string) (any, error) {
+	c, t, err := s.lookup(tag)
+	if err != nil {
+		return nil, err
+	}
+	words, bits, err := readSpan(ctx, c, t.table, t.addr, uint16(max(t.words, 1)))
+	if err != nil {
+		return nil, fmt.Errorf("read %s/%s: %w", s.cfg.Name, tag, err)
+	}
+	if t.words == 0 {
+		return bits[0], nil
+	}
+	return t.decode(words), nil
+}
+
+// writeTag writes a coil or a holding register run. A coil takes a bool or
+// 0/1; a register takes a number that, divided by the scale, is a whole
+// number in the register's range — never truncated or wrapped.
+func (s *modbusSource) writeTag(ctx context.Context, tag string, value any) error {
+	c, t, err := s.lookup(tag)
+	if err != nil {
+		return err
+	}
+	if !t.writable {
+		return fmt.Errorf("tag %s on %s is not writable", tag, s.cfg.Name)
+	}
+	if t.table == config.ModbusCoil {
+		on, ok := value.(bool)
+		if n, isInt := asInt64(value); !ok && isInt && (n == 0 || n == 1) {
+			on, ok = n == 1, true
+		}
+		if !ok {
+			return fmt.Errorf("write %s/%s: cannot write %T %v to a coil", s.cfg.Name, tag, value, value)
+		}
+		if err := c.WriteCoil(ctx, t.addr, on); err != nil {
+			return fmt.Errorf("write %s/%s: %w", s.cfg.Name, tag, err)
+		}
+		return nil
+	}
+	words, err := t.encode(value)
+	if err != nil {
+		return fmt.Errorf("write %s/%s: %w", s.cfg.Name, tag, err)
+	}
+	if err := c.WriteRegisters(ctx, t.addr, words); err != nil {
+		return fmt.Errorf("write %s/%s: %w", s.cfg.Name, tag, err)
+	}
+	return nil
+}
+
+// listTags is the register map, with each tag's last polled value.
+func (s *modbusSource) listTags(ctx context.Context) ([]WarlinkTagInfo, error) {
+	s.m.mu.RLock()
+	mp := s.m.plcs[s.cfg.Name]
+	s.m.mu.RUnlock()
+
+	out := make([]WarlinkTagInfo, 0, len(s.tags))
+	mp.mu.RLock()
+	for name, t := range s.tags {
+		out = append(out, WarlinkTagInfo{
+			Name:       name,
+			Type:       t.typeStr(),
+			Configured: true,
+			Enabled:    true,
+			Writable:   t.writable,
+			Value:      mp.Values[name].Value,
+		})
+	}
+	mp.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// counterWrap is 2^width times the scale for a 16- or 32-bit register with a
+// whole-number scale. A 64-bit counter does not wrap in a plant's lifetime,
+// and a fractional scale is a measurement, not a count.
+func (s *modbusSource) counterWrap(tag string) int64 {
+	t, ok := s.tags[tag]
+	if !ok || t.words == 0 || t.words > 2 || !t.integral() {
+		return 0
+	}
+	return int64(1) << t.bits() * int64(t.scale)
+}
@@ -0,0 +1,194 @@
+package plc
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingoedge/config"
+	"shingoedge/internal/testdb"
+	"shingoedge/plc/modbus/modbustest"
+	"shingoedge/store"
+)
+
+var pressRegisters = []config.ModbusRegister{
+	{Tag: "Count", Address: 0},
+	{Tag: "Total", Address: 1, Width: 32, WordOrder: "little"},
+	{Tag: "Temp", Table: config.ModbusInput, Address: 0, Signed: true, Scale: 0.1},
+	{Tag: "Style", Address: 3, Writable: true},
+	{Tag: "Andon", Table: config.ModbusCoil, Address: 5, Writable: true},
+	{Tag: "Gone", Address: 200},
+}
+
+func startModbusSource(t *testing.T, srv *modbustest.Server, db *store.DB) (*Manager, *mockEmitter) {
+	t.Helper()
+	addr, err := srv.Start()
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	cfg := config.Defaults()
+	cfg.PLCSources = []config.PLCSourceConfig{{
+		Name:            "press2",
+		Driver:          config.PLCDriverModbus,
+		Endpoint:        addr,
+		PublishInterval: 20 * time.Millisecond,
+		Registers:       pressRegisters,
+	}}
+	emitter := &mockEmitter{}
+	mgr := NewManager(db, cfg, emitter, nil)
+	mgr.StartSources()
+	t.Cleanup(mgr.Stop)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return mgr.IsConnected("press2") })
+	return mgr, emitter
+}
+
+func TestModbusSourceDecodesRegisterMap(t *testing.T) {
+	srv := modbustest.New()
+	srv.Limit = 100                      // Gone is unmapped
+	srv.SetHolding(0, 7, 0x0002, 0x0001) // Total's low word first: 0x00010002
+	srv.SetInput(0, 0xFF9C)              // -100 tenths
+	mgr, _ := startModbusSource(t, srv, nil)
+
+	want := map[string]any{"Count": int64(7), "Total": int64(0x10002), "Temp": -10.0, "Andon": false}
+	for tag, v := range want {
+		if got, err := mgr.ReadTag("press2", tag); err != nil || got != v {
+			t.Errorf("%s = %v (%T), %v; want %v", tag, got, got, err, v)
+		}
+	}
+	if _, err := mgr.ReadTag("press2", "Gone"); err == nil {
+		t.Error("an unmapped register read without error")
+	}
+	if !mgr.IsConnected("press2") {
+		t.Error("one refused register disconnected the PLC")
+	}
+
+	tags, err := mgr.FetchAllTags(context.Background(), "press2")
+	if err != nil || len(tags) != len(pressRegisters) {
+		t.Fatalf("FetchAllTags = %d tags, %v", len(tags), err)
+	}
+	types := map[string]string{}
+	for _, tag := range tags {
+		types[tag.Name] = tag.Type
+	}
+	wantTypes := map[string]string{"Andon": "Boolean", "Count": "UInt16", "Gone": "UInt16", "Style": "UInt16", "Temp": "Double", "Total": "UInt32"}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("types = %v", types)
+	}
+	if h := mgr.GetPLCHealth("press2"); h == nil || !h.Online || h.Driver != "modbus" {
+		t.Errorf("health = %+v", h)
+	}
+}
+
+func TestModbusSourceWritesSignals(t *testing.T) {
+	srv := modbustest.New()
+	mgr, _ := startModbusSource(t, srv, nil)
+	ctx := context.Background()
+
+	if err := mgr.WriteTagValue(ctx, "press2", "Andon", 1); err != nil || !srv.Coil(5) {
+		t.Errorf("andon write: %v, coil = %v", err, srv.Coil(5))
+	}
+	if err := mgr.WriteTagValue(ctx, "press2", "Style", float64(42)); err != nil {
+		t.Fatalf("style write: %v", err)
+	}
+	if got := srv.Holding(3, 1)[0]; got != 42 {
+		t.Errorf("Style register = %d, want 42", got)
+	}
+	for _, bad := range []any{70000, -1, 1.5} {
+		if err := mgr.WriteTagValue(ctx, "press2", "Style", bad); err == nil {
+			t.Errorf("write %v to a UInt16 succeeded", bad)
+		}
+	}
+	if err := mgr.WriteTagValue(ctx, "press2", "Count", 0); err == nil {
+		t.Error("write to a read-only register succeeded")
+	}
+}
+
+// TestModbusCounterRollsOverAtItsWidth runs a 16-bit counter across its wrap
+// through pollReportingPoint: 65534 → 2 is four parts, not a reset.
+func TestModbusCounterRollsOverAtItsWidth(t *testing.T) {
+	db := testdb.Open(t)
+	srv := modbustest.New()
+	srv.SetHolding(0, 65534)
+	mgr, _ := startModbusSource(t, srv, db)
+
+	id, err := db.CreateReportingPoint("press2", "Count", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateReportingPointCounter(id, 65534); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetHolding(0, 2)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool {
+		v, _ := mgr.ReadTag("press2", "Count")
+		return v == int64(2)
+	})
+	rp, err := db.GetReportingPoint(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.pollReportingPoint(*rp)
+
+	var delta int64
+	var anomaly sql.NullString
+	err = db.QueryRow(`SELECT delta, anomaly FROM counter_snapshots WHERE reporting_point_id = ?`, id).Scan(&delta, &anomaly)
+	if err != nil || delta != 4 || anomaly.Valid {
+		t.Errorf("snapshot delta=%d anomaly=%v err=%v; want 4 and none", delta, anomaly, err)
+	}
+}
+
+func TestModbusSourceReconnectsAfterDrop(t *testing.T) {
+	srv := modbustest.New()
+	mgr, emitter := startModbusSource(t, srv, nil)
+	srv.DropConnections()
+	emitter.waitFor(t, "plc_disconnected:press2", 3*time.Second)
+	if h := mgr.GetPLCHealth("press2"); h == nil || h.Online {
+		t.Errorf("health after drop = %+v", h)
+	}
+	emitter.waitFor(t, "plc_health_recover:press2", 5*time.Second)
+}
+
+func TestCalculateDeltaWrap(t *testing.T) {
+	cases := []struct {
+		last, next, wrap int64
+		delta            int64
+		anomaly          string
+	}{
+		{65534, 2, 1 << 16, 4, ""},
+		{60000, 10, 1 << 32, 10, "reset"}, // a 32-bit counter does not wrap at 16 bits
+		{32767, -32768, 1 << 16, 1, ""},   // signed 16-bit
+		{131068, 4, 1 << 17, 8, ""},       // 16 bits at scale 2
+		{65534, 2, 0, 4, ""},              // unknown width: the 16-bit guess
+		{5, 9, 1 << 16, 4, ""},
+	}
+	for _, c := range cases {
+		d, a := CalculateDeltaWrap(c.last, c.next, c.wrap, 1000)
+		if d != c.delta || a != c.anomaly {
+			t.Errorf("CalculateDeltaWrap(%d, %d, %d) = %d %q; want %d %q", c.last, c.next, c.wrap, d, a, c.delta, c.anomaly)
+		}
+	}
+}
+
+func TestModbusSpansNeverReadAcrossGaps(t *testing.T) {
+	s := newModbusSource(nil, config.PLCSourceConfig{Registers: []config.ModbusRegister{
+		{Tag: "a", Address: 0, Width: 32},
+		{Tag: "b", Address: 2},
+		{Tag: "c", Address: 4},
+		{Tag: "d", Table: config.ModbusCoil, Address: 0},
+		{Tag: "e", Width: 24},
+		{Tag: "f", Table: config.ModbusInput, Writable: true},
+	}})
+	var got []string
+	for _, sp := range s.spans {
+		got = append(got, sp.table+":"+string(rune('0'+sp.addr))+"+"+string(rune('0'+sp.qty)))
+	}
+	want := []string{"coil:0+1", "holding:0+3", "holding:4+1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spans = %v, want %v (e and f rejected)", got, want)
+	}
+}
@@ -0,0 +1,590 @@
+// Package opcua is a native OPC UA client for the PLC manager: the subset of
+// the protocol a counter, style or andon tag needs — browse, read, write and
+// data-change subscriptions — over UA TCP with SecurityPolicy None and an
+// anonymous session.
+//
+// It exists so a line whose PLC speaks OPC UA does not need a WarLink
+// deployment in front of it. plc.Manager drives it; nothing here knows about
+// reporting points or tags by name.
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"shingoedge/plc/opcua/internal/ua"
+)
+
+// Re-exported protocol types. The protocol package is internal so it is not
+// mistaken for a general OPC UA library; these are the parts callers hold.
+type (
+	NodeID      = ua.NodeID
+	Variant     = ua.Variant
+	DataValue   = ua.DataValue
+	StatusCode  = ua.StatusCode
+	TypeID      = ua.TypeID
+	ReadValueID = ua.ReadValueID
+)
+
+// Attribute ids, node classes and access bits callers read.
+const (
+	AttrValue       = ua.AttrValue
+	AttrDataType    = ua.AttrDataType
+	AttrAccessLevel = ua.AttrAccessLevel
+	AccessWrite     = ua.AccessWrite
+
+	NodeClassObject   = ua.NodeClassObject
+	NodeClassVariable = ua.NodeClassVariable
+)
+
+// Built-in types callers coerce to.
+const (
+	TypeBoolean = ua.TypeBoolean
+	TypeSByte   = ua.TypeSByte
+	TypeByte    = ua.TypeByte
+	TypeInt16   = ua.TypeInt16
+	TypeUInt16  = ua.TypeUInt16
+	TypeInt32   = ua.TypeInt32
+	TypeUInt32  = ua.TypeUInt32
+	TypeInt64   = ua.TypeInt64
+	TypeUInt64  = ua.TypeUInt64
+	TypeFloat   = ua.TypeFloat
+	TypeDouble  = ua.TypeDouble
+	TypeString  = ua.TypeString
+)
+
+// Status codes callers branch on.
+const (
+	StatusBadNotWritable   = ua.StatusBadNotWritable
+	StatusBadTypeMismatch  = ua.StatusBadTypeMismatch
+	StatusBadNodeIDUnknown = ua.StatusBadNodeIDUnknown
+)
+
+// ObjectsFolder is the standard root of a server's object tree.
+var ObjectsFolder = ua.ObjectsFolder
+
+// ParseNodeID reads "ns=2;s=Line1.Count", "i=85" and the like.
+func ParseNodeID(s string) (NodeID, error) { return ua.ParseNodeID(s) }
+
+// VariantOf wraps a Go scalar in the Variant of its natural type.
+func VariantOf(v any) (Variant, error) { return ua.VariantOf(v) }
+
+const (
+	dialTimeout     = 10 * time.Second
+	requestTimeout  = 10 * time.Second
+	channelLifetime = time.Hour
+	sessionTimeout  = time.Minute
+	// keepAliveCount is how many empty publishing intervals the server lets
+	// pass before it sends a keep-alive.
+	keepAliveCount = 10
+)
+
+// ErrClosed is returned by calls on a client that was closed.
+var ErrClosed = errors.New("opc ua: connection closed")
+
+// Reference is one child a browse found.
+type Reference struct {
+	NodeID      NodeID
+	BrowseName  string
+	DisplayName string
+	NodeClass   uint32
+}
+
+// Client is one connection, secure channel and session to a server. A client
+// that loses its connection is finished — Done closes and Err says why — and
+// the caller dials a new one; nothing in here reconnects.
+type Client struct {
+	conn     *ua.Conn
+	endpoint string
+
+	mu         sync.Mutex
+	channelID  uint32
+	tokenID    uint32
+	authToken  NodeID
+	nextReq    uint32
+	pending    map[uint32]chan ua.Message
+	subscribed bool
+	err        error
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Dial connects to an opc.tcp:// endpoint, opens a secure channel with
+// SecurityPolicy None and activates an anonymous session.
+func Dial(ctx context.Context, endpoint string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "opc.tcp" || u.Host == "" {
+		return nil, fmt.Errorf("opc ua endpoint %q: want opc.tcp://host:port", endpoint)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4840")
+	}
+	dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	var d net.Dialer
+	nc, err := d.DialContext(dctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("opc ua dial %s: %w", host, err)
+	}
+	conn, err := ua.ClientHello(nc, endpoint, dialTimeout)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("opc ua hello %s: %w", endpoint, err)
+	}
+	c := &Client{
+		conn:     conn,
+		endpoint: endpoint,
+		pending:  make(map[uint32]chan ua.Message),
+		done:     make(chan struct{}),
+	}
+	c.wg.Add(1)
+	go c.readLoop()
+
+	if err := c.open(dctx); err != nil {
+		c.fail(err)
+		c.wg.Wait()
+		return nil, err
+	}
+	return c, nil
+}
+
+// open runs the handshake after HEL/ACK: channel, session, activation.
+func (c *Client) open(ctx context.Context) error {
+	lifetime, err := c.openChannel(ctx, ua.TokenIssue)
+	if err != nil {
+		return fmt.Errorf("opc ua open channel: %w", err)
+	}
+	cs, err := expect[*ua.CreateSessionResponse](c.call(ctx, &ua.CreateSessionRequest{
+		Client: ua.ApplicationDescription{
+			ApplicationURI:  "urn:shingo:edge",
+			ApplicationName: "Shingo Edge",
+			ApplicationType: 1,
+		},
+		EndpointURL:    c.endpoint,
+		SessionName:    "shingo-edge",
+		SessionTimeout: float64(sessionTimeout / time.Millisecond),
+	}))
+	if err != nil {
+		return fmt.Errorf("opc ua create session: %w", err)
+	}
+	c.mu.Lock()
+	c.authToken = cs.AuthenticationToken
+	c.mu.Unlock()
+
+	identity, err := ua.Wrap(&ua.AnonymousIdentityToken{PolicyID: anonymousPolicy(cs.Endpoints)})
+	if err != nil {
+		return err
+	}
+	if _, err := c.call(ctx, &ua.ActivateSessionRequest{Identity: identity}); err != nil {
+		return fmt.Errorf("opc ua activate session: %w", err)
+	}
+
+	c.wg.Add(1)
+	go c.renewLoop(lifetime)
+	return nil
+}
+
+// anonymousPolicy picks the server's policy id for anonymous logins. Servers
+// name it freely ("anonymous", "Anonymous", "open62541-anonymous-policy"),
+// and reject the wrong one.
+func anonymousPolicy(endpoints []ua.EndpointDescription) string {
+	for _, none := range []bool{true, false} {
+		for _, ep := range endpoints {
+			if none && ep.SecurityPolicyURI != ua.SecurityPolicyNone {
+				continue
+			}
+			for _, t := range ep.UserTokens {
+				if t.TokenType == ua.UserTokenAnonymous {
+					return t.PolicyID
+				}
+			}
+		}
+	}
+	return "anonymous"
+}
+
+// openChannel issues or renews the secure channel token and returns its
+// lifetime.
+func (c *Client) openChannel(ctx context.Context, reqType uint32) (time.Duration, error) {
+	resp, err := expect[*ua.OpenSecureChannelResponse](c.send(ctx, ua.MsgOpen, &ua.OpenSecureChannelRequest{
+		RequestType:       reqType,
+		RequestedLifetime: uint32(channelLifetime / time.Millisecond),
+	}))
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.channelID = resp.ChannelID
+	c.tokenID = resp.TokenID
+	c.mu.Unlock()
+	return time.Duration(resp.RevisedLifetime) * time.Millisecond, nil
+}
+
+// renewLoop renews the channel token at three quarters of its lifetime, as
+// Part 4 asks. A server drops a channel whose token expired.
+func (c *Client) renewLoop(lifetime time.Duration) {
+	defer c.wg.Done()
+	for {
+		if lifetime <= 0 {
+			lifetime = channelLifetime
+		}
+		t := time.NewTimer(lifetime * 3 / 4)
+		select {
+		case <-c.done:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		next, err := c.openChannel(ctx, ua.TokenRenew)
+		cancel()
+		if err != nil {
+			c.fail(fmt.Errorf("opc ua renew channel: %w", err))
+			return
+		}
+		lifetime = next
+	}
+}
+
+// Done is closed when the connection is gone, for whatever reason.
+func (c *Client) Done() <-chan struct{} { return c.done }
+
+// Err is why the connection went, once Done is closed.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close ends the session and the channel. Neither is waited on: a server
+// that does not answer is no reason to hold up shutdown.
+func (c *Client) Close() error {
+	select {
+	case <-c.done:
+	default:
+		c.mu.Lock()
+		c.nextReq++
+		closeSession := c.nextReq
+		c.nextReq++
+		closeChannel := c.nextReq
+		channelID, tokenID, auth := c.channelID, c.tokenID, c.authToken
+		c.mu.Unlock()
+		cs := &ua.CloseSessionRequest{DeleteSubscriptions: true}
+		cs.AuthenticationToken = auth
+		cs.RequestHandle = closeSession
+		if body, err := ua.EncodeMessage(cs); err == nil {
+			_ = c.conn.Send(ua.MsgSecure, channelID, tokenID, closeSession, body)
+		}
+		if body, err := ua.EncodeMessage(&ua.CloseSecureChannelRequest{}); err == nil {
+			_ = c.conn.Send(ua.MsgClose, channelID, tokenID, closeChannel, body)
+		}
+		c.fail(ErrClosed)
+	}
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Client) fail(err error) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// readLoop hands each response to the call waiting on its request id.
+func (c *Client) readLoop() {
+	defer c.wg.Done()
+	for {
+		f, err := c.conn.Receive()
+		if err != nil {
+			c.fail(fmt.Errorf("opc ua receive: %w", err))
+			return
+		}
+		var m ua.Message
+		if f.Abort != ua.StatusGood {
+			m = &ua.ServiceFault{ResponseHeader: ua.ResponseHeader{ServiceResult: f.Abort}}
+		} else if m, err = ua.DecodeMessage(f.Body); err != nil {
+			c.fail(fmt.Errorf("opc ua decode: %w", err))
+			return
+		}
+		c.mu.Lock()
+		ch := c.pending[f.RequestID]
+		delete(c.pending, f.RequestID)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- m
+		}
+	}
+}
+
+func (c *Client) call(ctx context.Context, req ua.Request) (ua.Message, error) {
+	return c.send(ctx, ua.MsgSecure, req)
+}
+
+// send writes a request and waits for its response, a fault, the context or
+// the connection, whichever comes first.
+func (c *Client) send(ctx context.Context, typ string, req ua.Request) (ua.Message, error) {
+	ch := make(chan ua.Message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.nextReq++
+	id := c.nextReq
+	c.pending[id] = ch
+	h := req.Header()
+	h.AuthenticationToken = c.authToken
+	h.RequestHandle = id
+	h.Timestamp = time.Now()
+	if dl, ok := ctx.Deadline(); ok {
+		h.TimeoutHint = uint32(time.Until(dl) / time.Millisecond)
+	}
+	channelID, tokenID := c.channelID, c.tokenID
+	c.mu.Unlock()
+
+	forget := func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}
+	body, err := ua.EncodeMessage(req)
+	if err != nil {
+		forget()
+		return nil, err
+	}
+	if err := c.conn.Send(typ, channelID, tokenID, id, body); err != nil {
+		c.fail(fmt.Errorf("opc ua send: %w", err))
+		return nil, c.Err()
+	}
+	select {
+	case m := <-ch:
+		return checkResponse(m)
+	case <-c.done:
+		return nil, c.Err()
+	case <-ctx.Done():
+		forget()
+		return nil, ctx.Err()
+	}
+}
+
+func checkResponse(m ua.Message) (ua.Message, error) {
+	r, ok := m.(ua.Response)
+	if !ok {
+		return nil, fmt.Errorf("opc ua: %T is not a response", m)
+	}
+	status := r.RespHeader().ServiceResult
+	if _, fault := m.(*ua.ServiceFault); fault && !status.IsBad() {
+		status = ua.StatusBadUnexpectedError
+	}
+	if status.IsBad() {
+		return nil, status
+	}
+	return m, nil
+}
+
+// expect narrows a response to the type the request promises.
+func expect[T ua.Message](m ua.Message, err error) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	t, ok := m.(T)
+	if !ok {
+		return zero, fmt.Errorf("opc ua: unexpected response %T", m)
+	}
+	return t, nil
+}
+
+// Browse lists a node's hierarchical children that are objects or
+// variables, following continuation points until the server has sent them
+// all.
+func (c *Client) Browse(ctx context.Context, node NodeID) ([]Reference, error) {
+	resp, err := expect[*ua.BrowseResponse](c.call(ctx, &ua.BrowseRequest{
+		Nodes: []ua.BrowseDescription{{
+			NodeID:          node,
+			ReferenceTypeID: ua.HierarchicalReferences,
+			IncludeSubtypes: true,
+			NodeClassMask:   ua.NodeClassObject | ua.NodeClassVariable,
+		}},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	results := resp.Results
+	var refs []Reference
+	for {
+		if len(results) != 1 {
+			return nil, fmt.Errorf("opc ua browse %s: %d results for 1 node", node, len(results))
+		}
+		r := results[0]
+		if r.Status.IsBad() {
+			return nil, fmt.Errorf("opc ua browse %s: %w", node, r.Status)
+		}
+		for _, ref := range r.References {
+			if ref.NodeID.ServerIndex != 0 {
+				continue // lives on another server
+			}
+			refs = append(refs, Reference{
+				NodeID:      ref.NodeID.NodeID,
+				BrowseName:  ref.BrowseName.Name,
+				DisplayName: ref.DisplayName.Text,
+				NodeClass:   ref.NodeClass,
+			})
+		}
+		if len(r.ContinuationPoint) == 0 {
+			return refs, nil
+		}
+		next, err := expect[*ua.BrowseNextResponse](c.call(ctx, &ua.BrowseNextRequest{
+			ContinuationPoints: [][]byte{r.ContinuationPoint},
+		}))
+		if err != nil {
+			return nil, err
+		}
+		results = next.Results
+	}
+}
+
+// Read reads attributes. Results line up with reads; a Bad result for one
+// attribute is in its DataValue's Status, not the error.
+func (c *Client) Read(ctx context.Context, reads ...ReadValueID) ([]*DataValue, error) {
+	resp, err := expect[*ua.ReadResponse](c.call(ctx, &ua.ReadRequest{
+		Timestamps: ua.TimestampsSource,
+		Nodes:      reads,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(reads) {
+		return nil, fmt.Errorf("opc ua read: %d results for %d nodes", len(resp.Results), len(reads))
+	}
+	return resp.Results, nil
+}
+
+// Write sets a node's value. The variant's type must be the node's data
+// type: servers do not convert, they answer BadTypeMismatch.
+func (c *Client) Write(ctx context.Context, node NodeID, v Variant) error {
+	resp, err := expect[*ua.WriteResponse](c.call(ctx, &ua.WriteRequest{
+		Nodes: []ua.WriteValue{{NodeID: node, AttributeID: ua.AttrValue, Value: &ua.DataValue{Value: &v}}},
+	}))
+	if err != nil {
+		return err
+	}
+	if len(resp.Results) != 1 {
+		return fmt.Errorf("opc ua write %s: %d results for 1 node", node, len(resp.Results))
+	}
+	if resp.Results[0].IsBad() {
+		return resp.Results[0]
+	}
+	return nil
+}
+
+// Subscribe monitors the nodes' values and calls fn with a node's index in
+// nodes and its new value on every change, starting with the current value.
+// fn runs on the client's publish goroutine, one call at a time.
+//
+// A client has at most one subscription: Publish requests are not addressed
+// to a subscription, so a second would share — and steal — the first's.
+func (c *Client) Subscribe(ctx context.Context, interval time.Duration, nodes []NodeID, fn func(i int, dv *DataValue)) error {
+	c.mu.Lock()
+	if c.subscribed {
+		c.mu.Unlock()
+		return errors.New("opc ua: client already has a subscription")
+	}
+	c.subscribed = true
+	c.mu.Unlock()
+
+	ms := float64(interval / time.Millisecond)
+	sub, err := expect[*ua.CreateSubscriptionResponse](c.call(ctx, &ua.CreateSubscriptionRequest{
+		PublishingInterval: ms,
+		LifetimeCount:      3 * keepAliveCount,
+		MaxKeepAliveCount:  keepAliveCount,
+	}))
+	if err != nil {
+		return fmt.Errorf("opc ua create subscription: %w", err)
+	}
+	items := make([]ua.MonitoredItemCreate, len(nodes))
+	for i, n := range nodes {
+		items[i] = ua.MonitoredItemCreate{
+			Item:             ua.ReadValueID{NodeID: n, AttributeID: ua.AttrValue},
+			ClientHandle:     uint32(i),
+			SamplingInterval: ms,
+			QueueSize:        1,
+		}
+	}
+	created, err := expect[*ua.CreateMonitoredItemsResponse](c.call(ctx, &ua.CreateMonitoredItemsRequest{
+		SubscriptionID: sub.SubscriptionID,
+		Items:          items,
+	}))
+	if err != nil {
+		return fmt.Errorf("opc ua create monitored items: %w", err)
+	}
+	for i, r := range created.Results {
+		if r.Status.IsBad() && i < len(nodes) {
+			return fmt.Errorf("opc ua monitor %s: %w", nodes[i], r.Status)
+		}
+	}
+
+	// A healthy subscription answers at least every keep-alive period.
+	// Twice that, plus a round trip, is a dead one — the same stall guard
+	// the WarLink SSE loop keeps.
+	period := time.Duration(sub.PublishingInterval*float64(sub.MaxKeepAliveCount)) * time.Millisecond
+	stall := 2*period + requestTimeout
+	c.wg.Add(1)
+	go c.publishLoop(stall, len(nodes), fn)
+	return nil
+}
+
+func (c *Client) publishLoop(stall time.Duration, n int, fn func(int, *DataValue)) {
+	defer c.wg.Done()
+	var acks []ua.Acknowledgement
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), stall)
+		resp, err := expect[*ua.PublishResponse](c.call(ctx, &ua.PublishRequest{Acks: acks}))
+		cancel()
+		if err != nil {
+			select {
+			case <-c.done:
+				return
+			default:
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("opc ua subscription stalled: nothing published for %s", stall)
+			}
+			c.fail(err)
+			return
+		}
+		acks = acks[:0]
+		if len(resp.Notifications) > 0 {
+			acks = append(acks, ua.Acknowledgement{SubscriptionID: resp.SubscriptionID, SequenceNumber: resp.SequenceNumber})
+		}
+		for _, x := range resp.Notifications {
+			m, _ := ua.Unwrap(x)
+			switch note := m.(type) {
+			case *ua.DataChangeNotification:
+				for _, it := range note.Items {
+					if int(it.ClientHandle) < n && it.Value != nil {
+						fn(int(it.ClientHandle), it.Value)
+					}
+				}
+			case *ua.StatusChangeNotification:
+				if note.Status.IsBad() {
+					c.fail(fmt.Errorf("opc ua subscription: %w", note.Status))
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package opcua_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"shingoedge/plc/opcua"
+	"shingoedge/plc/opcua/opcuatest"
+)
+
+func startServer(t *testing.T, srv *opcuatest.Server) string {
+	t.Helper()
+	endpoint, err := srv.Start()
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	return endpoint
+}
+
+func dial(t *testing.T, endpoint string) *opcua.Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := opcua.Dial(ctx, endpoint)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func mustNode(t *testing.T, path string) opcua.NodeID {
+	t.Helper()
+	id, err := opcua.ParseNodeID(opcuatest.NodeID(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestBrowseFollowsContinuationPoints(t *testing.T) {
+	srv := opcuatest.New()
+	srv.MaxReferences = 3
+	for i := 0; i < 8; i++ {
+		srv.AddVariable(fmt.Sprintf("Line1.Tag%d", i), int32(i), false)
+	}
+	c := dial(t, startServer(t, srv))
+	ctx := context.Background()
+
+	top, err := c.Browse(ctx, opcua.ObjectsFolder)
+	if err != nil {
+		t.Fatalf("browse objects: %v", err)
+	}
+	if len(top) != 1 || top[0].BrowseName != "Line1" || top[0].NodeClass != opcua.NodeClassObject {
+		t.Fatalf("objects = %+v, want the Line1 folder", top)
+	}
+	refs, err := c.Browse(ctx, top[0].NodeID)
+	if err != nil {
+		t.Fatalf("browse Line1: %v", err)
+	}
+	var names []string
+	for _, r := range refs {
+		names = append(names, r.BrowseName)
+	}
+	sort.Strings(names)
+	if len(names) != 8 || names[0] != "Tag0" || names[7] != "Tag7" {
+		t.Errorf("browsed %v, want Tag0..Tag7 across three pages", names)
+	}
+}
+
+func TestReadWriteAndTypeMismatch(t *testing.T) {
+	srv := opcuatest.New()
+	srv.AddVariable("Line1.Style", uint16(3), true)
+	srv.AddVariable("Line1.Count", int32(41), false)
+	c := dial(t, startServer(t, srv))
+	ctx := context.Background()
+	style, count := mustNode(t, "Line1.Style"), mustNode(t, "Line1.Count")
+
+	dvs, err := c.Read(ctx,
+		opcua.ReadValueID{NodeID: count, AttributeID: opcua.AttrValue},
+		opcua.ReadValueID{NodeID: style, AttributeID: opcua.AttrDataType},
+		opcua.ReadValueID{NodeID: style, AttributeID: opcua.AttrAccessLevel},
+	)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got := dvs[0].Value.Value; got != int32(41) {
+		t.Errorf("Count = %v (%T), want int32 41", got, got)
+	}
+	if got := dvs[1].Value.Value.(opcua.NodeID).Numeric; got != uint32(opcua.TypeUInt16) {
+		t.Errorf("Style data type = i=%d, want UInt16", got)
+	}
+	if got := dvs[2].Value.Value.(uint8); got&opcua.AccessWrite == 0 {
+		t.Errorf("Style access level = %#x, want writable", got)
+	}
+
+	if err := c.Write(ctx, style, opcua.Variant{Type: opcua.TypeUInt16, Value: uint16(7)}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := srv.Value("Line1.Style"); got != uint16(7) {
+		t.Errorf("server Style = %v, want 7", got)
+	}
+	err = c.Write(ctx, style, opcua.Variant{Type: opcua.TypeInt32, Value: int32(8)})
+	if !errors.Is(err, opcua.StatusBadTypeMismatch) {
+		t.Errorf("Int32 write to a UInt16 = %v, want BadTypeMismatch", err)
+	}
+	err = c.Write(ctx, count, opcua.Variant{Type: opcua.TypeInt32, Value: int32(0)})
+	if !errors.Is(err, opcua.StatusBadNotWritable) {
+		t.Errorf("write to a read-only tag = %v, want BadNotWritable", err)
+	}
+}
+
+// TestLargeMessagesAreChunked lowers the server's buffer to the protocol
+// minimum so a browse response and a write request both span chunks.
+func TestLargeMessagesAreChunked(t *testing.T) {
+	srv := opcuatest.New()
+	srv.ChunkSize = 8192
+	for i := 0; i < 400; i++ {
+		srv.AddVariable(fmt.Sprintf("Cell.A_Rather_Long_Tag_Name_For_Padding_%03d", i), false, false)
+	}
+	srv.AddVariable("Cell.Note", "", true)
+	c := dial(t, startServer(t, srv))
+	ctx := context.Background()
+
+	refs, err := c.Browse(ctx, mustNode(t, "Cell"))
+	if err != nil || len(refs) != 401 {
+		t.Fatalf("browse = %d refs, %v; want 401", len(refs), err)
+	}
+	long := make([]byte, 20000)
+	for i := range long {
+		long[i] = 'a' + byte(i%26)
+	}
+	if err := c.Write(ctx, mustNode(t, "Cell.Note"), opcua.Variant{Type: opcua.TypeString, Value: string(long)}); err != nil {
+		t.Fatalf("chunked write: %v", err)
+	}
+	if got := srv.Value("Cell.Note"); got != string(long) {
+		t.Errorf("server got %d bytes, want %d", len(got.(string)), len(long))
+	}
+}
+
+func TestSubscribeDeliversInitialAndChangedValues(t *testing.T) {
+	srv := opcuatest.New()
+	srv.AddVariable("Line1.Count", int32(10), false)
+	srv.AddVariable("Line1.Running", false, false)
+	c := dial(t, startServer(t, srv))
+
+	var mu sync.Mutex
+	seen := map[int][]any{}
+	got := make(chan struct{}, 16)
+	nodes := []opcua.NodeID{mustNode(t, "Line1.Count"), mustNode(t, "Line1.Running")}
+	err := c.Subscribe(context.Background(), 20*time.Millisecond, nodes, func(i int, dv *opcua.DataValue) {
+		mu.Lock()
+		seen[i] = append(seen[i], dv.Value.Value)
+		mu.Unlock()
+		got <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	waitFor(t, got, func() bool { return len(seen[0]) == 1 && len(seen[1]) == 1 }, &mu)
+
+	srv.Set("Line1.Count", int32(11))
+	waitFor(t, got, func() bool { return len(seen[0]) == 2 }, &mu)
+	mu.Lock()
+	defer mu.Unlock()
+	if seen[0][0] != int32(10) || seen[0][1] != int32(11) || seen[1][0] != false {
+		t.Errorf("notifications = %v", seen)
+	}
+}
+
+func waitFor(t *testing.T, tick <-chan struct{}, cond func() bool, mu *sync.Mutex) {
+	t.Helper()
+	deadline := time.After(3 * time.Second)
+	for {
+		mu.Lock()
+		ok := cond()
+		mu.Unlock()
+		if ok {
+			return
+		}
+		select {
+		case <-tick:
+		case <-deadline:
+			t.Fatal("timed out waiting for notifications")
+		}
+	}
+}
+
+func TestChannelIsRenewedBeforeItLapses(t *testing.T) {
+	srv := opcuatest.New()
+	srv.ChannelLifetime = 200 * time.Millisecond
+	srv.AddVariable("Line1.Count", int32(1), false)
+	c := dial(t, startServer(t, srv))
+
+	// Three lifetimes: without renewal the server drops the channel and
+	// the read fails.
+	time.Sleep(600 * time.Millisecond)
+	if _, err := c.Read(context.Background(), opcua.ReadValueID{NodeID: mustNode(t, "Line1.Count"), AttributeID: opcua.AttrValue}); err != nil {
+		t.Fatalf("read after three channel lifetimes: %v", err)
+	}
+}
+
+func TestDroppedConnectionEndsTheClient(t *testing.T) {
+	srv := opcuatest.New()
+	c := dial(t, startServer(t, srv))
+	srv.DropConnections()
+	select {
+	case <-c.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("client did not notice the dropped connection")
+	}
+	if c.Err() == nil {
+		t.Error("Err() is nil after a dropped connection")
+	}
+	if _, err := c.Browse(context.Background(), opcua.ObjectsFolder); err == nil {
+		t.Error("browse on a dead client succeeded")
+	}
+}
+
+func TestDialRejectsBadEndpoint(t *testing.T) {
+	for _, ep := range []string{"http://plc:4840", "opc.tcp://", "::"} {
+		if _, err := opcua.Dial(context.Background(), ep); err == nil {
+			t.Errorf("Dial(%q) succeeded", ep)
+		}
+	}
+}
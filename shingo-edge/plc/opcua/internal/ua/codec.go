@@ -0,0 +1,505 @@
+package ua
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxArray bounds a decoded array length. A corrupt length prefix would
+// otherwise ask for gigabytes before the short read is noticed.
+const maxArray = 1 << 20
+
+// errShort is a body that ended before its fields did.
+var errShort = errors.New("opc ua: message truncated")
+
+// Encoder appends OPC UA binary encoding to a buffer.
+type Encoder struct {
+	b   []byte
+	err error
+}
+
+// Bytes is what has been written.
+func (e *Encoder) Bytes() []byte { return e.b }
+
+// Err is the first value that could not be encoded.
+func (e *Encoder) Err() error { return e.err }
+
+func (e *Encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.b = append(e.b, 1)
+	} else {
+		e.b = append(e.b, 0)
+	}
+}
+
+func (e *Encoder) Byte(v byte)     { e.b = append(e.b, v) }
+func (e *Encoder) UInt16(v uint16) { e.b = binary.LittleEndian.AppendUint16(e.b, v) }
+func (e *Encoder) UInt32(v uint32) { e.b = binary.LittleEndian.AppendUint32(e.b, v) }
+func (e *Encoder) UInt64(v uint64) { e.b = binary.LittleEndian.AppendUint64(e.b, v) }
+func (e *Encoder) Int32(v int32)   { e.UInt32(uint32(v)) }
+func (e *Encoder) Int64(v int64)   { e.UInt64(uint64(v)) }
+func (e *Encoder) Double(v float64) {
+	e.UInt64(math.Float64bits(v))
+}
+
+// String writes a length-prefixed UTF-8 string. Empty is written as empty,
+// not null: a tag written "" should read back "".
+func (e *Encoder) String(s string) {
+	e.Int32(int32(len(s)))
+	e.b = append(e.b, s...)
+}
+
+// ByteString writes nil as the null ByteString.
+func (e *Encoder) ByteString(b []byte) {
+	if b == nil {
+		e.Int32(-1)
+		return
+	}
+	e.Int32(int32(len(b)))
+	e.b = append(e.b, b...)
+}
+
+func (e *Encoder) Time(t time.Time) { e.Int64(timeToTicks(t)) }
+
+// ArrayLen writes an array's length prefix.
+func (e *Encoder) ArrayLen(n int) { e.Int32(int32(n)) }
+
+// Strings writes an array of strings; nil is the null array.
+func (e *Encoder) Strings(ss []string) {
+	if ss == nil {
+		e.Int32(-1)
+		return
+	}
+	e.ArrayLen(len(ss))
+	for _, s := range ss {
+		e.String(s)
+	}
+}
+
+// NodeID picks the most compact encoding the id fits.
+func (e *Encoder) NodeID(n NodeID) { e.nodeID(n, 0) }
+
+func (e *Encoder) nodeID(n NodeID, flags byte) {
+	switch n.Kind {
+	case IDString:
+		e.Byte(0x03 | flags)
+		e.UInt16(n.Namespace)
+		e.String(n.Str)
+	case IDGuid:
+		g, err := guidBytes(n.Str)
+		if err != nil {
+			e.fail(err)
+		}
+		e.Byte(0x04 | flags)
+		e.UInt16(n.Namespace)
+		e.b = append(e.b, g[:]...)
+	case IDOpaque:
+		e.Byte(0x05 | flags)
+		e.UInt16(n.Namespace)
+		e.ByteString([]byte(n.Str))
+	default:
+		switch {
+		case n.Namespace == 0 && n.Numeric <= 0xFF:
+			e.Byte(0x00 | flags)
+			e.Byte(byte(n.Numeric))
+		case n.Namespace <= 0xFF && n.Numeric <= 0xFFFF:
+			e.Byte(0x01 | flags)
+			e.Byte(byte(n.Namespace))
+			e.UInt16(uint16(n.Numeric))
+		default:
+			e.Byte(0x02 | flags)
+			e.UInt16(n.Namespace)
+			e.UInt32(n.Numeric)
+		}
+	}
+}
+
+func (e *Encoder) ExpandedNodeID(n ExpandedNodeID) {
+	var flags byte
+	if n.NamespaceURI != "" {
+		flags |= 0x80
+	}
+	if n.ServerIndex != 0 {
+		flags |= 0x40
+	}
+	e.nodeID(n.NodeID, flags)
+	if n.NamespaceURI != "" {
+		e.String(n.NamespaceURI)
+	}
+	if n.ServerIndex != 0 {
+		e.UInt32(n.ServerIndex)
+	}
+}
+
+func (e *Encoder) QualifiedName(q QualifiedName) {
+	e.UInt16(q.Namespace)
+	e.String(q.Name)
+}
+
+func (e *Encoder) LocalizedText(l LocalizedText) {
+	var mask byte
+	if l.Locale != "" {
+		mask |= 0x01
+	}
+	if l.Text != "" {
+		mask |= 0x02
+	}
+	e.Byte(mask)
+	if l.Locale != "" {
+		e.String(l.Locale)
+	}
+	if l.Text != "" {
+		e.String(l.Text)
+	}
+}
+
+// ExtensionObject writes nil as the empty object.
+func (e *Encoder) ExtensionObject(x *ExtensionObject) {
+	if x == nil {
+		e.NodeID(NodeID{})
+		e.Byte(0)
+		return
+	}
+	e.ExpandedNodeID(x.TypeID)
+	e.Byte(x.Encoding)
+	if x.Encoding != 0 {
+		e.ByteString(x.Body)
+	}
+}
+
+func (e *Encoder) DiagnosticInfo(d *DiagnosticInfo) {
+	if d == nil {
+		e.Byte(0)
+		return
+	}
+	var mask byte = 0x01 | 0x02 | 0x04 | 0x08
+	if d.AdditionalInfo != "" {
+		mask |= 0x10
+	}
+	if d.InnerStatus != 0 {
+		mask |= 0x20
+	}
+	if d.Inner != nil {
+		mask |= 0x40
+	}
+	e.Byte(mask)
+	e.Int32(d.SymbolicID)
+	e.Int32(d.NamespaceURI)
+	e.Int32(d.Locale) // Locale precedes LocalizedText on the wire, unlike the mask bits
+	e.Int32(d.LocalizedText)
+	if mask&0x10 != 0 {
+		e.String(d.AdditionalInfo)
+	}
+	if mask&0x20 != 0 {
+		e.UInt32(uint32(d.InnerStatus))
+	}
+	if mask&0x40 != 0 {
+		e.DiagnosticInfo(d.Inner)
+	}
+}
+
+func (e *Encoder) DataValue(d *DataValue) {
+	if d == nil {
+		e.Byte(0)
+		return
+	}
+	var mask byte
+	if d.Value != nil {
+		mask |= 0x01
+	}
+	if d.Status != 0 {
+		mask |= 0x02
+	}
+	if !d.SourceTimestamp.IsZero() {
+		mask |= 0x04
+	}
+	if !d.ServerTimestamp.IsZero() {
+		mask |= 0x08
+	}
+	e.Byte(mask)
+	if d.Value != nil {
+		e.Variant(*d.Value)
+	}
+	if mask&0x02 != 0 {
+		e.UInt32(uint32(d.Status))
+	}
+	if mask&0x04 != 0 {
+		e.Time(d.SourceTimestamp)
+	}
+	if mask&0x08 != 0 {
+		e.Time(d.ServerTimestamp)
+	}
+}
+
+// Decoder reads OPC UA binary. The first failure sticks: every later read
+// returns a zero value, and Err reports what went wrong. Callers decode a
+// whole structure and check once.
+type Decoder struct {
+	b   []byte
+	off int
+	err error
+}
+
+// NewDecoder reads from b.
+func NewDecoder(b []byte) *Decoder { return &Decoder{b: b} }
+
+// Err is the first failure.
+func (d *Decoder) Err() error { return d.err }
+
+// Rest is what has not been read.
+func (d *Decoder) Rest() []byte { return d.b[d.off:] }
+
+func (d *Decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *Decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.off+n > len(d.b) {
+		d.fail(errShort)
+		return nil
+	}
+	p := d.b[d.off : d.off+n]
+	d.off += n
+	return p
+}
+
+func (d *Decoder) Bool() bool { return d.Byte() != 0 }
+
+func (d *Decoder) Byte() byte {
+	if p := d.take(1); p != nil {
+		return p[0]
+	}
+	return 0
+}
+
+func (d *Decoder) UInt16() uint16 {
+	if p := d.take(2); p != nil {
+		return binary.LittleEndian.Uint16(p)
+	}
+	return 0
+}
+
+func (d *Decoder) UInt32() uint32 {
+	if p := d.take(4); p != nil {
+		return binary.LittleEndian.Uint32(p)
+	}
+	return 0
+}
+
+func (d *Decoder) UInt64() uint64 {
+	if p := d.take(8); p != nil {
+		return binary.LittleEndian.Uint64(p)
+	}
+	return 0
+}
+
+func (d *Decoder) Int32() int32       { return int32(d.UInt32()) }
+func (d *Decoder) Int64() int64       { return int64(d.UInt64()) }
+func (d *Decoder) Double() float64    { return math.Float64frombits(d.UInt64()) }
+func (d *Decoder) Time() time.Time    { return ticksToTime(d.Int64()) }
+func (d *Decoder) String() string     { return string(d.ByteString()) }
+func (d *Decoder) Status() StatusCode { return StatusCode(d.UInt32()) }
+
+// ByteString returns nil for the null ByteString.
+func (d *Decoder) ByteString() []byte {
+	n := d.Int32()
+	if n < 0 {
+		return nil
+	}
+	p := d.take(int(n))
+	if p == nil {
+		return nil
+	}
+	return append([]byte(nil), p...)
+}
+
+// skipString reads past a String or ByteString without keeping it.
+func (d *Decoder) skipString() {
+	if n := d.Int32(); n > 0 {
+		d.take(int(n))
+	}
+}
+
+// ArrayLen reads an array's length prefix; the null array reads as 0.
+func (d *Decoder) ArrayLen() int {
+	n := d.Int32()
+	if n < 0 {
+		return 0
+	}
+	if n > maxArray || int(n) > len(d.b)-d.off {
+		// Every element is at least one byte, so a count past the bytes
+		// left is a corrupt prefix, not a big array.
+		d.fail(fmt.Errorf("opc ua: array length %d exceeds message", n))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *Decoder) Strings() []string {
+	n := d.ArrayLen()
+	out := make([]string, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		out = append(out, d.String())
+	}
+	return out
+}
+
+func (d *Decoder) Statuses() []StatusCode {
+	n := d.ArrayLen()
+	out := make([]StatusCode, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		out = append(out, d.Status())
+	}
+	return out
+}
+
+func (d *Decoder) DiagnosticInfos() {
+	n := d.ArrayLen()
+	for i := 0; i < n && d.err == nil; i++ {
+		d.DiagnosticInfo()
+	}
+}
+
+func (d *Decoder) NodeID() NodeID {
+	n, _ := d.nodeID()
+	return n
+}
+
+// nodeID returns the id and the ExpandedNodeID flag bits of its first byte.
+func (d *Decoder) nodeID() (NodeID, byte) {
+	var n NodeID
+	b := d.Byte()
+	switch b & 0x0F {
+	case 0x00:
+		n.Numeric = uint32(d.Byte())
+	case 0x01:
+		n.Namespace = uint16(d.Byte())
+		n.Numeric = uint32(d.UInt16())
+	case 0x02:
+		n.Namespace = d.UInt16()
+		n.Numeric = d.UInt32()
+	case 0x03:
+		n.Namespace, n.Kind = d.UInt16(), IDString
+		n.Str = d.String()
+	case 0x04:
+		n.Namespace, n.Kind = d.UInt16(), IDGuid
+		if p := d.take(16); p != nil {
+			n.Str = guidString(p)
+		}
+	case 0x05:
+		n.Namespace, n.Kind = d.UInt16(), IDOpaque
+		n.Str = string(d.ByteString())
+	default:
+		d.fail(fmt.Errorf("opc ua: node id encoding 0x%02x", b))
+	}
+	return n, b & 0xC0
+}
+
+func (d *Decoder) ExpandedNodeID() ExpandedNodeID {
+	n, flags := d.nodeID()
+	x := ExpandedNodeID{NodeID: n}
+	if flags&0x80 != 0 {
+		x.NamespaceURI = d.String()
+	}
+	if flags&0x40 != 0 {
+		x.ServerIndex = d.UInt32()
+	}
+	return x
+}
+
+func (d *Decoder) QualifiedName() QualifiedName {
+	return QualifiedName{Namespace: d.UInt16(), Name: d.String()}
+}
+
+func (d *Decoder) LocalizedText() LocalizedText {
+	var l LocalizedText
+	mask := d.Byte()
+	if mask&0x01 != 0 {
+		l.Locale = d.String()
+	}
+	if mask&0x02 != 0 {
+		l.Text = d.String()
+	}
+	return l
+}
+
+func (d *Decoder) ExtensionObject() *ExtensionObject {
+	x := &ExtensionObject{TypeID: d.ExpandedNodeID(), Encoding: d.Byte()}
+	switch x.Encoding {
+	case 0:
+	case 1, 2:
+		x.Body = d.ByteString()
+	default:
+		d.fail(fmt.Errorf("opc ua: extension object encoding %d", x.Encoding))
+	}
+	return x
+}
+
+func (d *Decoder) DiagnosticInfo() *DiagnosticInfo {
+	mask := d.Byte()
+	if mask == 0 {
+		return nil
+	}
+	di := &DiagnosticInfo{}
+	if mask&0x01 != 0 {
+		di.SymbolicID = d.Int32()
+	}
+	if mask&0x02 != 0 {
+		di.NamespaceURI = d.Int32()
+	}
+	if mask&0x08 != 0 {
+		di.Locale = d.Int32()
+	}
+	if mask&0x04 != 0 {
+		di.LocalizedText = d.Int32()
+	}
+	if mask&0x10 != 0 {
+		di.AdditionalInfo = d.String()
+	}
+	if mask&0x20 != 0 {
+		di.InnerStatus = d.Status()
+	}
+	if mask&0x40 != 0 {
+		di.Inner = d.DiagnosticInfo()
+	}
+	return di
+}
+
+func (d *Decoder) DataValue() *DataValue {
+	dv := &DataValue{}
+	mask := d.Byte()
+	if mask&0x01 != 0 {
+		v := d.Variant()
+		dv.Value = &v
+	}
+	if mask&0x02 != 0 {
+		dv.Status = d.Status()
+	}
+	if mask&0x04 != 0 {
+		dv.SourceTimestamp = d.Time()
+	}
+	if mask&0x10 != 0 {
+		d.UInt16() // source picoseconds
+	}
+	if mask&0x08 != 0 {
+		dv.ServerTimestamp = d.Time()
+	}
+	if mask&0x20 != 0 {
+		d.UInt16() // server picoseconds
+	}
+	return dv
+}
@@ -0,0 +1,159 @@
+package ua
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVariantRoundTrip(t *testing.T) {
+	ts := time.Date(2026, 10, 18, 6, 30, 0, 123400, time.UTC)
+	cases := []Variant{
+		{Type: TypeNull},
+		{Type: TypeBoolean, Value: true},
+		{Type: TypeSByte, Value: int8(-5)},
+		{Type: TypeByte, Value: uint8(200)},
+		{Type: TypeInt16, Value: int16(-30000)},
+		{Type: TypeUInt16, Value: uint16(60000)},
+		{Type: TypeInt32, Value: int32(-7)},
+		{Type: TypeUInt32, Value: uint32(4_000_000_000)},
+		{Type: TypeInt64, Value: int64(-1 << 40)},
+		{Type: TypeUInt64, Value: uint64(1 << 63)},
+		{Type: TypeFloat, Value: float32(1.5)},
+		{Type: TypeDouble, Value: 2.25},
+		{Type: TypeString, Value: ""},
+		{Type: TypeString, Value: "STYLE-42"},
+		{Type: TypeDateTime, Value: ts},
+		{Type: TypeGuid, Value: "72962b91-fa75-4ae6-8d28-b404dc7daf63"},
+		{Type: TypeByteString, Value: []byte{1, 2, 3}},
+		{Type: TypeNodeID, Value: NewStringNodeID(2, "Line1.Count")},
+		{Type: TypeStatusCode, Value: StatusBadTypeMismatch},
+		{Type: TypeQualifiedName, Value: QualifiedName{Namespace: 1, Name: "Count"}},
+		{Type: TypeLocalizedText, Value: LocalizedText{Locale: "en", Text: "Count"}},
+		{Type: TypeInt32, Array: true, Value: []any{int32(1), int32(2), int32(3)}},
+		{Type: TypeVariant, Value: Variant{Type: TypeUInt16, Value: uint16(9)}},
+	}
+	for _, want := range cases {
+		e := &Encoder{}
+		e.Variant(want)
+		if e.Err() != nil {
+			t.Fatalf("%s: encode: %v", want.Type, e.Err())
+		}
+		d := NewDecoder(e.Bytes())
+		got := d.Variant()
+		if d.Err() != nil {
+			t.Fatalf("%s: decode: %v", want.Type, d.Err())
+		}
+		if len(d.Rest()) != 0 {
+			t.Errorf("%s: %d bytes left over", want.Type, len(d.Rest()))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: round trip = %#v, want %#v", want.Type, got, want)
+		}
+	}
+}
+
+func TestVariantEncodeRejectsMismatchedValue(t *testing.T) {
+	e := &Encoder{}
+	e.Variant(Variant{Type: TypeInt16, Value: int32(5)})
+	if e.Err() == nil {
+		t.Fatal("an Int16 variant holding an int32 encoded without error")
+	}
+}
+
+func TestDecoderRejectsCorruptArrayLength(t *testing.T) {
+	e := &Encoder{}
+	e.Byte(byte(TypeInt32) | 0x80)
+	e.Int32(1 << 30) // a billion elements, none of them present
+	d := NewDecoder(e.Bytes())
+	d.Variant()
+	if d.Err() == nil {
+		t.Fatal("a length prefix past the message decoded without error")
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	e := &Encoder{}
+	e.String("Line1.Count")
+	d := NewDecoder(e.Bytes()[:6])
+	if s := d.String(); s != "" {
+		t.Errorf("truncated string = %q", s)
+	}
+	if !errors.Is(d.Err(), errShort) {
+		t.Errorf("err = %v, want errShort", d.Err())
+	}
+}
+
+func TestNodeIDText(t *testing.T) {
+	for _, s := range []string{
+		"i=85",
+		"ns=2;i=70000",
+		"ns=2;s=Line1.Count",
+		"ns=3;g=72962b91-fa75-4ae6-8d28-b404dc7daf63",
+		"ns=1;b=AQID",
+	} {
+		n, err := ParseNodeID(s)
+		if err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+		if n.String() != s {
+			t.Errorf("parse %q prints %q", s, n.String())
+		}
+		e := &Encoder{}
+		e.NodeID(n)
+		if got := NewDecoder(e.Bytes()).NodeID(); got != n {
+			t.Errorf("%q: binary round trip = %v", s, got)
+		}
+	}
+	for _, bad := range []string{"", "85", "ns=x;i=1", "ns=1", "q=1", "g=not-a-guid"} {
+		if _, err := ParseNodeID(bad); err == nil {
+			t.Errorf("parse %q: no error", bad)
+		}
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	v := Variant{Type: TypeInt32, Value: int32(12)}
+	want := &PublishResponse{
+		ResponseHeader: ResponseHeader{RequestHandle: 7},
+		SubscriptionID: 3,
+		SequenceNumber: 9,
+		AckResults:     []StatusCode{StatusGood},
+	}
+	note, err := Wrap(&DataChangeNotification{Items: []MonitoredItemNotification{{ClientHandle: 4, Value: &DataValue{Value: &v}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Notifications = []*ExtensionObject{note}
+
+	b, err := EncodeMessage(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := DecodeMessage(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := m.(*PublishResponse)
+	if !ok {
+		t.Fatalf("decoded %T", m)
+	}
+	if got.SubscriptionID != 3 || got.SequenceNumber != 9 || got.RequestHandle != 7 || len(got.Notifications) != 1 {
+		t.Fatalf("decoded %+v", got)
+	}
+	inner, ok := Unwrap(got.Notifications[0])
+	dc, isDC := inner.(*DataChangeNotification)
+	if !ok || !isDC || len(dc.Items) != 1 || dc.Items[0].ClientHandle != 4 || dc.Items[0].Value.Value.Value != int32(12) {
+		t.Fatalf("notification = %#v", inner)
+	}
+}
+
+func TestDecodeMessageUnknownType(t *testing.T) {
+	e := &Encoder{}
+	e.NodeID(NewNumericNodeID(0, 999))
+	_, err := DecodeMessage(e.Bytes())
+	if !errors.Is(err, StatusBadServiceUnsupported) {
+		t.Fatalf("err = %v, want BadServiceUnsupported", err)
+	}
+}
@@ -0,0 +1,1151 @@
+package ua
+
+import (
+	"fmt"
+	"time"
+)
+
+// Message is a service request or response, or a structure carried in an
+// ExtensionObject. TypeID is the numeric id of its DefaultBinary encoding
+// node in namespace 0.
+type Message interface {
+	TypeID() uint32
+	Encode(e *Encoder)
+	Decode(d *Decoder)
+}
+
+// Binary encoding ids of the messages below.
+const (
+	idServiceFault               uint32 = 397
+	idAnonymousIdentityToken     uint32 = 321
+	idOpenSecureChannelRequest   uint32 = 446
+	idOpenSecureChannelResponse  uint32 = 449
+	idCloseSecureChannelRequest  uint32 = 452
+	idCreateSessionRequest       uint32 = 461
+	idCreateSessionResponse      uint32 = 464
+	idActivateSessionRequest     uint32 = 467
+	idActivateSessionResponse    uint32 = 470
+	idCloseSessionRequest        uint32 = 473
+	idCloseSessionResponse       uint32 = 476
+	idBrowseRequest              uint32 = 527
+	idBrowseResponse             uint32 = 530
+	idBrowseNextRequest          uint32 = 533
+	idBrowseNextResponse         uint32 = 536
+	idReadRequest                uint32 = 631
+	idReadResponse               uint32 = 634
+	idWriteRequest               uint32 = 673
+	idWriteResponse              uint32 = 676
+	idCreateMonitoredItemsReq    uint32 = 751
+	idCreateMonitoredItemsResp   uint32 = 754
+	idCreateSubscriptionRequest  uint32 = 787
+	idCreateSubscriptionResponse uint32 = 790
+	idDataChangeNotification     uint32 = 811
+	idStatusChangeNotification   uint32 = 820
+	idPublishRequest             uint32 = 826
+	idPublishResponse            uint32 = 829
+)
+
+var registry = map[uint32]func() Message{
+	idServiceFault:               func() Message { return &ServiceFault{} },
+	idAnonymousIdentityToken:     func() Message { return &AnonymousIdentityToken{} },
+	idOpenSecureChannelRequest:   func() Message { return &OpenSecureChannelRequest{} },
+	idOpenSecureChannelResponse:  func() Message { return &OpenSecureChannelResponse{} },
+	idCloseSecureChannelRequest:  func() Message { return &CloseSecureChannelRequest{} },
+	idCreateSessionRequest:       func() Message { return &CreateSessionRequest{} },
+	idCreateSessionResponse:      func() Message { return &CreateSessionResponse{} },
+	idActivateSessionRequest:     func() Message { return &ActivateSessionRequest{} },
+	idActivateSessionResponse:    func() Message { return &ActivateSessionResponse{} },
+	idCloseSessionRequest:        func() Message { return &CloseSessionRequest{} },
+	idCloseSessionResponse:       func() Message { return &CloseSessionResponse{} },
+	idBrowseRequest:              func() Message { return &BrowseRequest{} },
+	idBrowseResponse:             func() Message { return &BrowseResponse{} },
+	idBrowseNextRequest:          func() Message { return &BrowseNextRequest{} },
+	idBrowseNextResponse:         func() Message { return &BrowseNextResponse{} },
+	idReadRequest:                func() Message { return &ReadRequest{} },
+	idReadResponse:               func() Message { return &ReadResponse{} },
+	idWriteRequest:               func() Message { return &WriteRequest{} },
+	idWriteResponse:              func() Message { return &WriteResponse{} },
+	idCreateMonitoredItemsReq:    func() Message { return &CreateMonitoredItemsRequest{} },
+	idCreateMonitoredItemsResp:   func() Message { return &CreateMonitoredItemsResponse{} },
+	idCreateSubscriptionRequest:  func() Message { return &CreateSubscriptionRequest{} },
+	idCreateSubscriptionResponse: func() Message { return &CreateSubscriptionResponse{} },
+	idDataChangeNotification:     func() Message { return &DataChangeNotification{} },
+	idStatusChangeNotification:   func() Message { return &StatusChangeNotification{} },
+	idPublishRequest:             func() Message { return &PublishRequest{} },
+	idPublishResponse:            func() Message { return &PublishResponse{} },
+}
+
+// EncodeMessage is a service body: the encoding id, then the message.
+func EncodeMessage(m Message) ([]byte, error) {
+	e := &Encoder{}
+	e.NodeID(NewNumericNodeID(0, m.TypeID()))
+	m.Encode(e)
+	return e.Bytes(), e.Err()
+}
+
+// DecodeMessage reads a service body EncodeMessage wrote.
+func DecodeMessage(b []byte) (Message, error) {
+	d := NewDecoder(b)
+	id := d.NodeID()
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	m, err := newMessage(id)
+	if err != nil {
+		return nil, err
+	}
+	m.Decode(d)
+	return m, d.Err()
+}
+
+func newMessage(id NodeID) (Message, error) {
+	if id.Namespace == 0 && id.Kind == IDNumeric {
+		if mk, ok := registry[id.Numeric]; ok {
+			return mk(), nil
+		}
+	}
+	return nil, fmt.Errorf("opc ua: unsupported message %s: %w", id, StatusBadServiceUnsupported)
+}
+
+// Wrap encodes a structure as a binary ExtensionObject.
+func Wrap(m Message) (*ExtensionObject, error) {
+	e := &Encoder{}
+	m.Encode(e)
+	return &ExtensionObject{
+		TypeID:   ExpandedNodeID{NodeID: NewNumericNodeID(0, m.TypeID())},
+		Encoding: 1,
+		Body:     e.Bytes(),
+	}, e.Err()
+}
+
+// Unwrap decodes a binary ExtensionObject holding one of the registered
+// structures. The second result is false for anything else.
+func Unwrap(x *ExtensionObject) (Message, bool) {
+	if x == nil || x.Encoding != 1 {
+		return nil, false
+	}
+	m, err := newMessage(x.TypeID.NodeID)
+	if err != nil {
+		return nil, false
+	}
+	d := NewDecoder(x.Body)
+	m.Decode(d)
+	return m, d.Err() == nil
+}
+
+// Request is a service request; every one starts with a RequestHeader.
+type Request interface {
+	Message
+	Header() *RequestHeader
+}
+
+// Response is a service response, including ServiceFault.
+type Response interface {
+	Message
+	RespHeader() *ResponseHeader
+}
+
+type RequestHeader struct {
+	AuthenticationToken NodeID
+	Timestamp           time.Time
+	RequestHandle       uint32
+	TimeoutHint         uint32
+}
+
+func (h *RequestHeader) Header() *RequestHeader { return h }
+
+func (h *RequestHeader) encode(e *Encoder) {
+	e.NodeID(h.AuthenticationToken)
+	e.Time(h.Timestamp)
+	e.UInt32(h.RequestHandle)
+	e.UInt32(0)  // return diagnostics
+	e.String("") // audit entry id
+	e.UInt32(h.TimeoutHint)
+	e.ExtensionObject(nil)
+}
+
+func (h *RequestHeader) decode(d *Decoder) {
+	h.AuthenticationToken = d.NodeID()
+	h.Timestamp = d.Time()
+	h.RequestHandle = d.UInt32()
+	d.UInt32()
+	d.skipString()
+	h.TimeoutHint = d.UInt32()
+	d.ExtensionObject()
+}
+
+type ResponseHeader struct {
+	Timestamp     time.Time
+	RequestHandle uint32
+	ServiceResult StatusCode
+}
+
+func (h *ResponseHeader) RespHeader() *ResponseHeader { return h }
+
+func (h *ResponseHeader) encode(e *Encoder) {
+	e.Time(h.Timestamp)
+	e.UInt32(h.RequestHandle)
+	e.UInt32(uint32(h.ServiceResult))
+	e.DiagnosticInfo(nil)
+	e.Strings(nil)
+	e.ExtensionObject(nil)
+}
+
+func (h *ResponseHeader) decode(d *Decoder) {
+	h.Timestamp = d.Time()
+	h.RequestHandle = d.UInt32()
+	h.ServiceResult = d.Status()
+	d.DiagnosticInfo()
+	d.Strings()
+	d.ExtensionObject()
+}
+
+// ServiceFault is the response to a request that failed as a whole.
+type ServiceFault struct{ ResponseHeader }
+
+func (*ServiceFault) TypeID() uint32      { return idServiceFault }
+func (m *ServiceFault) Encode(e *Encoder) { m.ResponseHeader.encode(e) }
+func (m *ServiceFault) Decode(d *Decoder) { m.ResponseHeader.decode(d) }
+
+// ── Secure channel ─────────────────────────────────────────────────────
+
+// SecurityTokenRequestType values.
+const (
+	TokenIssue uint32 = 0
+	TokenRenew uint32 = 1
+)
+
+// MessageSecurityModeNone is the only security mode spoken here.
+const MessageSecurityModeNone uint32 = 1
+
+type OpenSecureChannelRequest struct {
+	RequestHeader
+	RequestType       uint32
+	RequestedLifetime uint32
+}
+
+func (*OpenSecureChannelRequest) TypeID() uint32 { return idOpenSecureChannelRequest }
+
+func (m *OpenSecureChannelRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.UInt32(0) // client protocol version
+	e.UInt32(m.RequestType)
+	e.UInt32(MessageSecurityModeNone)
+	e.ByteString(nil) // client nonce
+	e.UInt32(m.RequestedLifetime)
+}
+
+func (m *OpenSecureChannelRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	d.UInt32()
+	m.RequestType = d.UInt32()
+	d.UInt32()
+	d.skipString()
+	m.RequestedLifetime = d.UInt32()
+}
+
+type OpenSecureChannelResponse struct {
+	ResponseHeader
+	ChannelID       uint32
+	TokenID         uint32
+	CreatedAt       time.Time
+	RevisedLifetime uint32
+}
+
+func (*OpenSecureChannelResponse) TypeID() uint32 { return idOpenSecureChannelResponse }
+
+func (m *OpenSecureChannelResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	e.UInt32(0) // server protocol version
+	e.UInt32(m.ChannelID)
+	e.UInt32(m.TokenID)
+	e.Time(m.CreatedAt)
+	e.UInt32(m.RevisedLifetime)
+	e.ByteString(nil) // server nonce
+}
+
+func (m *OpenSecureChannelResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	d.UInt32()
+	m.ChannelID = d.UInt32()
+	m.TokenID = d.UInt32()
+	m.CreatedAt = d.Time()
+	m.RevisedLifetime = d.UInt32()
+	d.skipString()
+}
+
+type CloseSecureChannelRequest struct{ RequestHeader }
+
+func (*CloseSecureChannelRequest) TypeID() uint32      { return idCloseSecureChannelRequest }
+func (m *CloseSecureChannelRequest) Encode(e *Encoder) { m.RequestHeader.encode(e) }
+func (m *CloseSecureChannelRequest) Decode(d *Decoder) { m.RequestHeader.decode(d) }
+
+// ── Session ────────────────────────────────────────────────────────────
+
+// UserTokenAnonymous is the UserTokenType of an anonymous login policy.
+const UserTokenAnonymous uint32 = 0
+
+// ApplicationDescription is written with the fields a server shows in its
+// session list; the rest are empty.
+type ApplicationDescription struct {
+	ApplicationURI  string
+	ApplicationName string
+	ApplicationType uint32 // 0 server, 1 client
+}
+
+func (a *ApplicationDescription) encode(e *Encoder) {
+	e.String(a.ApplicationURI)
+	e.String("") // product uri
+	e.LocalizedText(LocalizedText{Text: a.ApplicationName})
+	e.UInt32(a.ApplicationType)
+	e.String("") // gateway server uri
+	e.String("") // discovery profile uri
+	e.Strings(nil)
+}
+
+func (a *ApplicationDescription) decode(d *Decoder) {
+	a.ApplicationURI = d.String()
+	d.skipString()
+	a.ApplicationName = d.LocalizedText().Text
+	a.ApplicationType = d.UInt32()
+	d.skipString()
+	d.skipString()
+	d.Strings()
+}
+
+// UserTokenPolicy is one way an endpoint accepts a login.
+type UserTokenPolicy struct {
+	PolicyID  string
+	TokenType uint32
+}
+
+// EndpointDescription is kept to the parts a None-security client needs:
+// which login policies the server offers.
+type EndpointDescription struct {
+	EndpointURL       string
+	Server            ApplicationDescription
+	SecurityMode      uint32
+	SecurityPolicyURI string
+	UserTokens        []UserTokenPolicy
+}
+
+func (p *EndpointDescription) encode(e *Encoder) {
+	e.String(p.EndpointURL)
+	p.Server.encode(e)
+	e.ByteString(nil) // server certificate
+	e.UInt32(p.SecurityMode)
+	e.String(p.SecurityPolicyURI)
+	e.ArrayLen(len(p.UserTokens))
+	for _, t := range p.UserTokens {
+		e.String(t.PolicyID)
+		e.UInt32(t.TokenType)
+		e.String("") // issued token type
+		e.String("") // issuer endpoint url
+		e.String("") // security policy uri
+	}
+	e.String("") // transport profile uri
+	e.Byte(0)    // security level
+}
+
+func (p *EndpointDescription) decode(d *Decoder) {
+	p.EndpointURL = d.String()
+	p.Server.decode(d)
+	d.skipString()
+	p.SecurityMode = d.UInt32()
+	p.SecurityPolicyURI = d.String()
+	n := d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		t := UserTokenPolicy{PolicyID: d.String(), TokenType: d.UInt32()}
+		d.skipString()
+		d.skipString()
+		d.skipString()
+		p.UserTokens = append(p.UserTokens, t)
+	}
+	d.skipString()
+	d.Byte()
+}
+
+type CreateSessionRequest struct {
+	RequestHeader
+	Client          ApplicationDescription
+	EndpointURL     string
+	SessionName     string
+	SessionTimeout  float64 // milliseconds
+	MaxResponseSize uint32
+}
+
+func (*CreateSessionRequest) TypeID() uint32 { return idCreateSessionRequest }
+
+func (m *CreateSessionRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	m.Client.encode(e)
+	e.String("") // server uri
+	e.String(m.EndpointURL)
+	e.String(m.SessionName)
+	e.ByteString(nil) // client nonce
+	e.ByteString(nil) // client certificate
+	e.Double(m.SessionTimeout)
+	e.UInt32(m.MaxResponseSize)
+}
+
+func (m *CreateSessionRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	m.Client.decode(d)
+	d.skipString()
+	m.EndpointURL = d.String()
+	m.SessionName = d.String()
+	d.skipString()
+	d.skipString()
+	m.SessionTimeout = d.Double()
+	m.MaxResponseSize = d.UInt32()
+}
+
+type CreateSessionResponse struct {
+	ResponseHeader
+	SessionID           NodeID
+	AuthenticationToken NodeID
+	SessionTimeout      float64
+	Endpoints           []EndpointDescription
+}
+
+func (*CreateSessionResponse) TypeID() uint32 { return idCreateSessionResponse }
+
+func (m *CreateSessionResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	e.NodeID(m.SessionID)
+	e.NodeID(m.AuthenticationToken)
+	e.Double(m.SessionTimeout)
+	e.ByteString(nil) // server nonce
+	e.ByteString(nil) // server certificate
+	e.ArrayLen(len(m.Endpoints))
+	for i := range m.Endpoints {
+		m.Endpoints[i].encode(e)
+	}
+	e.ArrayLen(0)     // server software certificates
+	e.String("")      // signature algorithm
+	e.ByteString(nil) // signature
+	e.UInt32(0)       // max request message size
+}
+
+func (m *CreateSessionResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.SessionID = d.NodeID()
+	m.AuthenticationToken = d.NodeID()
+	m.SessionTimeout = d.Double()
+	d.skipString()
+	d.skipString()
+	n := d.ArrayLen()
+	m.Endpoints = make([]EndpointDescription, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		m.Endpoints[i].decode(d)
+	}
+	n = d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		d.skipString()
+		d.skipString()
+	}
+	d.skipString()
+	d.skipString()
+	d.UInt32()
+}
+
+// AnonymousIdentityToken is the login of a client without credentials.
+type AnonymousIdentityToken struct{ PolicyID string }
+
+func (*AnonymousIdentityToken) TypeID() uint32      { return idAnonymousIdentityToken }
+func (m *AnonymousIdentityToken) Encode(e *Encoder) { e.String(m.PolicyID) }
+func (m *AnonymousIdentityToken) Decode(d *Decoder) { m.PolicyID = d.String() }
+
+type ActivateSessionRequest struct {
+	RequestHeader
+	Identity *ExtensionObject
+}
+
+func (*ActivateSessionRequest) TypeID() uint32 { return idActivateSessionRequest }
+
+func (m *ActivateSessionRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.String("")      // client signature algorithm
+	e.ByteString(nil) // client signature
+	e.ArrayLen(0)     // client software certificates
+	e.Strings(nil)    // locale ids
+	e.ExtensionObject(m.Identity)
+	e.String("")      // user token signature algorithm
+	e.ByteString(nil) // user token signature
+}
+
+func (m *ActivateSessionRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	d.skipString()
+	d.skipString()
+	n := d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		d.skipString()
+		d.skipString()
+	}
+	d.Strings()
+	m.Identity = d.ExtensionObject()
+	d.skipString()
+	d.skipString()
+}
+
+type ActivateSessionResponse struct{ ResponseHeader }
+
+func (*ActivateSessionResponse) TypeID() uint32 { return idActivateSessionResponse }
+
+func (m *ActivateSessionResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	e.ByteString(nil) // server nonce
+	e.ArrayLen(0)     // results
+	e.ArrayLen(0)     // diagnostic infos
+}
+
+func (m *ActivateSessionResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	d.skipString()
+	d.Statuses()
+	d.DiagnosticInfos()
+}
+
+type CloseSessionRequest struct {
+	RequestHeader
+	DeleteSubscriptions bool
+}
+
+func (*CloseSessionRequest) TypeID() uint32 { return idCloseSessionRequest }
+
+func (m *CloseSessionRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.Bool(m.DeleteSubscriptions)
+}
+
+func (m *CloseSessionRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	m.DeleteSubscriptions = d.Bool()
+}
+
+type CloseSessionResponse struct{ ResponseHeader }
+
+func (*CloseSessionResponse) TypeID() uint32      { return idCloseSessionResponse }
+func (m *CloseSessionResponse) Encode(e *Encoder) { m.ResponseHeader.encode(e) }
+func (m *CloseSessionResponse) Decode(d *Decoder) { m.ResponseHeader.decode(d) }
+
+// ── Browse ─────────────────────────────────────────────────────────────
+
+// BrowseDescription asks for one node's forward references of a type (and,
+// with IncludeSubtypes, its subtypes).
+type BrowseDescription struct {
+	NodeID          NodeID
+	ReferenceTypeID NodeID
+	IncludeSubtypes bool
+	NodeClassMask   uint32
+}
+
+// browseResultMaskAll asks for every field of a ReferenceDescription.
+const browseResultMaskAll uint32 = 0x3F
+
+// ReferenceDescription is one reference a browse found.
+type ReferenceDescription struct {
+	ReferenceTypeID NodeID
+	IsForward       bool
+	NodeID          ExpandedNodeID
+	BrowseName      QualifiedName
+	DisplayName     LocalizedText
+	NodeClass       uint32
+	TypeDefinition  ExpandedNodeID
+}
+
+// BrowseResult is one node's references, or the first page of them with a
+// continuation point for BrowseNext.
+type BrowseResult struct {
+	Status            StatusCode
+	ContinuationPoint []byte
+	References        []ReferenceDescription
+}
+
+func (r *BrowseResult) encode(e *Encoder) {
+	e.UInt32(uint32(r.Status))
+	e.ByteString(r.ContinuationPoint)
+	e.ArrayLen(len(r.References))
+	for _, ref := range r.References {
+		e.NodeID(ref.ReferenceTypeID)
+		e.Bool(ref.IsForward)
+		e.ExpandedNodeID(ref.NodeID)
+		e.QualifiedName(ref.BrowseName)
+		e.LocalizedText(ref.DisplayName)
+		e.UInt32(ref.NodeClass)
+		e.ExpandedNodeID(ref.TypeDefinition)
+	}
+}
+
+func (r *BrowseResult) decode(d *Decoder) {
+	r.Status = d.Status()
+	r.ContinuationPoint = d.ByteString()
+	n := d.ArrayLen()
+	r.References = make([]ReferenceDescription, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		r.References = append(r.References, ReferenceDescription{
+			ReferenceTypeID: d.NodeID(),
+			IsForward:       d.Bool(),
+			NodeID:          d.ExpandedNodeID(),
+			BrowseName:      d.QualifiedName(),
+			DisplayName:     d.LocalizedText(),
+			NodeClass:       d.UInt32(),
+			TypeDefinition:  d.ExpandedNodeID(),
+		})
+	}
+}
+
+func encodeBrowseResults(e *Encoder, rs []BrowseResult) {
+	e.ArrayLen(len(rs))
+	for i := range rs {
+		rs[i].encode(e)
+	}
+	e.ArrayLen(0) // diagnostic infos
+}
+
+func decodeBrowseResults(d *Decoder) []BrowseResult {
+	n := d.ArrayLen()
+	rs := make([]BrowseResult, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		rs[i].decode(d)
+	}
+	d.DiagnosticInfos()
+	return rs
+}
+
+type BrowseRequest struct {
+	RequestHeader
+	MaxReferences uint32
+	Nodes         []BrowseDescription
+}
+
+func (*BrowseRequest) TypeID() uint32 { return idBrowseRequest }
+
+func (m *BrowseRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.NodeID(NodeID{}) // view id: the whole address space
+	e.Time(time.Time{})
+	e.UInt32(0) // view version
+	e.UInt32(m.MaxReferences)
+	e.ArrayLen(len(m.Nodes))
+	for _, n := range m.Nodes {
+		e.NodeID(n.NodeID)
+		e.UInt32(0) // browse direction: forward
+		e.NodeID(n.ReferenceTypeID)
+		e.Bool(n.IncludeSubtypes)
+		e.UInt32(n.NodeClassMask)
+		e.UInt32(browseResultMaskAll)
+	}
+}
+
+func (m *BrowseRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	d.NodeID()
+	d.Time()
+	d.UInt32()
+	m.MaxReferences = d.UInt32()
+	n := d.ArrayLen()
+	m.Nodes = make([]BrowseDescription, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		bd := BrowseDescription{NodeID: d.NodeID()}
+		d.UInt32()
+		bd.ReferenceTypeID = d.NodeID()
+		bd.IncludeSubtypes = d.Bool()
+		bd.NodeClassMask = d.UInt32()
+		d.UInt32()
+		m.Nodes = append(m.Nodes, bd)
+	}
+}
+
+type BrowseResponse struct {
+	ResponseHeader
+	Results []BrowseResult
+}
+
+func (*BrowseResponse) TypeID() uint32 { return idBrowseResponse }
+
+func (m *BrowseResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	encodeBrowseResults(e, m.Results)
+}
+
+func (m *BrowseResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.Results = decodeBrowseResults(d)
+}
+
+type BrowseNextRequest struct {
+	RequestHeader
+	Release            bool
+	ContinuationPoints [][]byte
+}
+
+func (*BrowseNextRequest) TypeID() uint32 { return idBrowseNextRequest }
+
+func (m *BrowseNextRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.Bool(m.Release)
+	e.ArrayLen(len(m.ContinuationPoints))
+	for _, cp := range m.ContinuationPoints {
+		e.ByteString(cp)
+	}
+}
+
+func (m *BrowseNextRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	m.Release = d.Bool()
+	n := d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		m.ContinuationPoints = append(m.ContinuationPoints, d.ByteString())
+	}
+}
+
+type BrowseNextResponse struct {
+	ResponseHeader
+	Results []BrowseResult
+}
+
+func (*BrowseNextResponse) TypeID() uint32 { return idBrowseNextResponse }
+
+func (m *BrowseNextResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	encodeBrowseResults(e, m.Results)
+}
+
+func (m *BrowseNextResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.Results = decodeBrowseResults(d)
+}
+
+// ── Read / Write ───────────────────────────────────────────────────────
+
+// TimestampsNeither asks a server to leave timestamps off values.
+const (
+	TimestampsSource  uint32 = 0
+	TimestampsNeither uint32 = 3
+)
+
+// ReadValueID names one attribute of one node.
+type ReadValueID struct {
+	NodeID      NodeID
+	AttributeID uint32
+}
+
+func (r ReadValueID) encode(e *Encoder) {
+	e.NodeID(r.NodeID)
+	e.UInt32(r.AttributeID)
+	e.String("")                     // index range
+	e.QualifiedName(QualifiedName{}) // data encoding
+}
+
+func (r *ReadValueID) decode(d *Decoder) {
+	r.NodeID = d.NodeID()
+	r.AttributeID = d.UInt32()
+	d.skipString()
+	d.QualifiedName()
+}
+
+func encodeDataValues(e *Encoder, vs []*DataValue) {
+	e.ArrayLen(len(vs))
+	for _, v := range vs {
+		e.DataValue(v)
+	}
+}
+
+func decodeDataValues(d *Decoder) []*DataValue {
+	n := d.ArrayLen()
+	vs := make([]*DataValue, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		vs = append(vs, d.DataValue())
+	}
+	return vs
+}
+
+func encodeStatuses(e *Encoder, ss []StatusCode) {
+	e.ArrayLen(len(ss))
+	for _, s := range ss {
+		e.UInt32(uint32(s))
+	}
+}
+
+type ReadRequest struct {
+	RequestHeader
+	Timestamps uint32
+	Nodes      []ReadValueID
+}
+
+func (*ReadRequest) TypeID() uint32 { return idReadRequest }
+
+func (m *ReadRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.Double(0) // max age: read the device, not a cache
+	e.UInt32(m.Timestamps)
+	e.ArrayLen(len(m.Nodes))
+	for _, n := range m.Nodes {
+		n.encode(e)
+	}
+}
+
+func (m *ReadRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	d.Double()
+	m.Timestamps = d.UInt32()
+	n := d.ArrayLen()
+	m.Nodes = make([]ReadValueID, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		m.Nodes[i].decode(d)
+	}
+}
+
+type ReadResponse struct {
+	ResponseHeader
+	Results []*DataValue
+}
+
+func (*ReadResponse) TypeID() uint32 { return idReadResponse }
+
+func (m *ReadResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	encodeDataValues(e, m.Results)
+	e.ArrayLen(0)
+}
+
+func (m *ReadResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.Results = decodeDataValues(d)
+	d.DiagnosticInfos()
+}
+
+// WriteValue sets one attribute of one node.
+type WriteValue struct {
+	NodeID      NodeID
+	AttributeID uint32
+	Value       *DataValue
+}
+
+type WriteRequest struct {
+	RequestHeader
+	Nodes []WriteValue
+}
+
+func (*WriteRequest) TypeID() uint32 { return idWriteRequest }
+
+func (m *WriteRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.ArrayLen(len(m.Nodes))
+	for _, n := range m.Nodes {
+		e.NodeID(n.NodeID)
+		e.UInt32(n.AttributeID)
+		e.String("")
+		e.DataValue(n.Value)
+	}
+}
+
+func (m *WriteRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	n := d.ArrayLen()
+	m.Nodes = make([]WriteValue, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		wv := WriteValue{NodeID: d.NodeID(), AttributeID: d.UInt32()}
+		d.skipString()
+		wv.Value = d.DataValue()
+		m.Nodes = append(m.Nodes, wv)
+	}
+}
+
+type WriteResponse struct {
+	ResponseHeader
+	Results []StatusCode
+}
+
+func (*WriteResponse) TypeID() uint32 { return idWriteResponse }
+
+func (m *WriteResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	encodeStatuses(e, m.Results)
+	e.ArrayLen(0)
+}
+
+func (m *WriteResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.Results = d.Statuses()
+	d.DiagnosticInfos()
+}
+
+// ── Subscriptions ──────────────────────────────────────────────────────
+
+type CreateSubscriptionRequest struct {
+	RequestHeader
+	PublishingInterval float64 // milliseconds
+	LifetimeCount      uint32
+	MaxKeepAliveCount  uint32
+}
+
+func (*CreateSubscriptionRequest) TypeID() uint32 { return idCreateSubscriptionRequest }
+
+func (m *CreateSubscriptionRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.Double(m.PublishingInterval)
+	e.UInt32(m.LifetimeCount)
+	e.UInt32(m.MaxKeepAliveCount)
+	e.UInt32(0) // max notifications per publish: no limit
+	e.Bool(true)
+	e.Byte(0) // priority
+}
+
+func (m *CreateSubscriptionRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	m.PublishingInterval = d.Double()
+	m.LifetimeCount = d.UInt32()
+	m.MaxKeepAliveCount = d.UInt32()
+	d.UInt32()
+	d.Bool()
+	d.Byte()
+}
+
+type CreateSubscriptionResponse struct {
+	ResponseHeader
+	SubscriptionID     uint32
+	PublishingInterval float64
+	LifetimeCount      uint32
+	MaxKeepAliveCount  uint32
+}
+
+func (*CreateSubscriptionResponse) TypeID() uint32 { return idCreateSubscriptionResponse }
+
+func (m *CreateSubscriptionResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	e.UInt32(m.SubscriptionID)
+	e.Double(m.PublishingInterval)
+	e.UInt32(m.LifetimeCount)
+	e.UInt32(m.MaxKeepAliveCount)
+}
+
+func (m *CreateSubscriptionResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.SubscriptionID = d.UInt32()
+	m.PublishingInterval = d.Double()
+	m.LifetimeCount = d.UInt32()
+	m.MaxKeepAliveCount = d.UInt32()
+}
+
+// MonitoringModeReporting samples and reports.
+const MonitoringModeReporting uint32 = 2
+
+// MonitoredItemCreate asks for one node's value to be reported, tagged with
+// the caller's ClientHandle.
+type MonitoredItemCreate struct {
+	Item             ReadValueID
+	ClientHandle     uint32
+	SamplingInterval float64
+	QueueSize        uint32
+}
+
+type CreateMonitoredItemsRequest struct {
+	RequestHeader
+	SubscriptionID uint32
+	Items          []MonitoredItemCreate
+}
+
+func (*CreateMonitoredItemsRequest) TypeID() uint32 { return idCreateMonitoredItemsReq }
+
+func (m *CreateMonitoredItemsRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.UInt32(m.SubscriptionID)
+	e.UInt32(TimestampsSource)
+	e.ArrayLen(len(m.Items))
+	for _, it := range m.Items {
+		it.Item.encode(e)
+		e.UInt32(MonitoringModeReporting)
+		e.UInt32(it.ClientHandle)
+		e.Double(it.SamplingInterval)
+		e.ExtensionObject(nil) // filter: the server's default data change filter
+		e.UInt32(it.QueueSize)
+		e.Bool(true) // discard oldest
+	}
+}
+
+func (m *CreateMonitoredItemsRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	m.SubscriptionID = d.UInt32()
+	d.UInt32()
+	n := d.ArrayLen()
+	m.Items = make([]MonitoredItemCreate, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		it := &m.Items[i]
+		it.Item.decode(d)
+		d.UInt32()
+		it.ClientHandle = d.UInt32()
+		it.SamplingInterval = d.Double()
+		d.ExtensionObject()
+		it.QueueSize = d.UInt32()
+		d.Bool()
+	}
+}
+
+// MonitoredItemResult is the server's answer for one MonitoredItemCreate.
+type MonitoredItemResult struct {
+	Status          StatusCode
+	MonitoredItemID uint32
+}
+
+type CreateMonitoredItemsResponse struct {
+	ResponseHeader
+	Results []MonitoredItemResult
+}
+
+func (*CreateMonitoredItemsResponse) TypeID() uint32 { return idCreateMonitoredItemsResp }
+
+func (m *CreateMonitoredItemsResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	e.ArrayLen(len(m.Results))
+	for _, r := range m.Results {
+		e.UInt32(uint32(r.Status))
+		e.UInt32(r.MonitoredItemID)
+		e.Double(0) // revised sampling interval
+		e.UInt32(1) // revised queue size
+		e.ExtensionObject(nil)
+	}
+	e.ArrayLen(0)
+}
+
+func (m *CreateMonitoredItemsResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	n := d.ArrayLen()
+	m.Results = make([]MonitoredItemResult, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		r := MonitoredItemResult{Status: d.Status(), MonitoredItemID: d.UInt32()}
+		d.Double()
+		d.UInt32()
+		d.ExtensionObject()
+		m.Results = append(m.Results, r)
+	}
+	d.DiagnosticInfos()
+}
+
+// Acknowledgement tells the server a notification arrived and need not be
+// kept for republish.
+type Acknowledgement struct {
+	SubscriptionID uint32
+	SequenceNumber uint32
+}
+
+type PublishRequest struct {
+	RequestHeader
+	Acks []Acknowledgement
+}
+
+func (*PublishRequest) TypeID() uint32 { return idPublishRequest }
+
+func (m *PublishRequest) Encode(e *Encoder) {
+	m.RequestHeader.encode(e)
+	e.ArrayLen(len(m.Acks))
+	for _, a := range m.Acks {
+		e.UInt32(a.SubscriptionID)
+		e.UInt32(a.SequenceNumber)
+	}
+}
+
+func (m *PublishRequest) Decode(d *Decoder) {
+	m.RequestHeader.decode(d)
+	n := d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		m.Acks = append(m.Acks, Acknowledgement{SubscriptionID: d.UInt32(), SequenceNumber: d.UInt32()})
+	}
+}
+
+// PublishResponse carries one NotificationMessage. A keep-alive has no
+// notifications and does not advance the sequence number.
+type PublishResponse struct {
+	ResponseHeader
+	SubscriptionID uint32
+	SequenceNumber uint32
+	PublishTime    time.Time
+	Notifications  []*ExtensionObject
+	AckResults     []StatusCode
+}
+
+func (*PublishResponse) TypeID() uint32 { return idPublishResponse }
+
+func (m *PublishResponse) Encode(e *Encoder) {
+	m.ResponseHeader.encode(e)
+	e.UInt32(m.SubscriptionID)
+	e.ArrayLen(0) // available sequence numbers
+	e.Bool(false) // more notifications
+	e.UInt32(m.SequenceNumber)
+	e.Time(m.PublishTime)
+	e.ArrayLen(len(m.Notifications))
+	for _, x := range m.Notifications {
+		e.ExtensionObject(x)
+	}
+	encodeStatuses(e, m.AckResults)
+	e.ArrayLen(0)
+}
+
+func (m *PublishResponse) Decode(d *Decoder) {
+	m.ResponseHeader.decode(d)
+	m.SubscriptionID = d.UInt32()
+	n := d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		d.UInt32()
+	}
+	d.Bool()
+	m.SequenceNumber = d.UInt32()
+	m.PublishTime = d.Time()
+	n = d.ArrayLen()
+	for i := 0; i < n && d.Err() == nil; i++ {
+		m.Notifications = append(m.Notifications, d.ExtensionObject())
+	}
+	m.AckResults = d.Statuses()
+	d.DiagnosticInfos()
+}
+
+// MonitoredItemNotification is a sampled value, keyed by the client handle
+// it was created with.
+type MonitoredItemNotification struct {
+	ClientHandle uint32
+	Value        *DataValue
+}
+
+type DataChangeNotification struct {
+	Items []MonitoredItemNotification
+}
+
+func (*DataChangeNotification) TypeID() uint32 { return idDataChangeNotification }
+
+func (m *DataChangeNotification) Encode(e *Encoder) {
+	e.ArrayLen(len(m.Items))
+	for _, it := range m.Items {
+		e.UInt32(it.ClientHandle)
+		e.DataValue(it.Value)
+	}
+	e.ArrayLen(0)
+}
+
+func (m *DataChangeNotification) Decode(d *Decoder) {
+	n := d.ArrayLen()
+	m.Items = make([]MonitoredItemNotification, 0, n)
+	for i := 0; i < n && d.Err() == nil; i++ {
+		m.Items = append(m.Items, MonitoredItemNotification{ClientHandle: d.UInt32(), Value: d.DataValue()})
+	}
+	d.DiagnosticInfos()
+}
+
+// StatusChangeNotification reports the subscription itself changing state,
+// e.g. timing out.
+type StatusChangeNotification struct {
+	Status StatusCode
+}
+
+func (*StatusChangeNotification) TypeID() uint32 { return idStatusChangeNotification }
+
+func (m *StatusChangeNotification) Encode(e *Encoder) {
+	e.UInt32(uint32(m.Status))
+	e.DiagnosticInfo(nil)
+}
+
+func (m *StatusChangeNotification) Decode(d *Decoder) {
+	m.Status = d.Status()
+	d.DiagnosticInfo()
+}
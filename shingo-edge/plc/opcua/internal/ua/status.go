@@ -0,0 +1,79 @@
+package ua
+
+import "fmt"
+
+// StatusCode is an OPC UA result. The top two bits are the severity; anything
+// with the high bit set is Bad.
+type StatusCode uint32
+
+// The codes this package produces or acts on. A code not listed still prints,
+// as its hex value.
+const (
+	StatusGood                    StatusCode = 0
+	StatusUncertain               StatusCode = 0x40000000
+	StatusBadUnexpectedError      StatusCode = 0x80010000
+	StatusBadInternalError        StatusCode = 0x80020000
+	StatusBadCommunicationError   StatusCode = 0x80050000
+	StatusBadEncodingError        StatusCode = 0x80060000
+	StatusBadDecodingError        StatusCode = 0x80070000
+	StatusBadTimeout              StatusCode = 0x800A0000
+	StatusBadServiceUnsupported   StatusCode = 0x800B0000
+	StatusBadNothingToDo          StatusCode = 0x800F0000
+	StatusBadTooManyOperations    StatusCode = 0x80100000
+	StatusBadUserAccessDenied     StatusCode = 0x801F0000
+	StatusBadIdentityTokenInvalid StatusCode = 0x80200000
+	StatusBadSecureChannelIDInval StatusCode = 0x80220000
+	StatusBadSessionIDInvalid     StatusCode = 0x80250000
+	StatusBadSubscriptionIDInval  StatusCode = 0x80280000
+	StatusBadNodeIDUnknown        StatusCode = 0x80340000
+	StatusBadAttributeIDInvalid   StatusCode = 0x80350000
+	StatusBadNotWritable          StatusCode = 0x803B0000
+	StatusBadOutOfRange           StatusCode = 0x803C0000
+	StatusBadSecurityPolicyReject StatusCode = 0x80550000
+	StatusBadTypeMismatch         StatusCode = 0x80740000
+	StatusBadNoSubscription       StatusCode = 0x80790000
+	StatusBadTCPMessageTypeInval  StatusCode = 0x807E0000
+	StatusBadConnectionClosed     StatusCode = 0x80AE0000
+)
+
+var statusNames = map[StatusCode]string{
+	StatusGood:                    "Good",
+	StatusUncertain:               "Uncertain",
+	StatusBadUnexpectedError:      "BadUnexpectedError",
+	StatusBadInternalError:        "BadInternalError",
+	StatusBadCommunicationError:   "BadCommunicationError",
+	StatusBadEncodingError:        "BadEncodingError",
+	StatusBadDecodingError:        "BadDecodingError",
+	StatusBadTimeout:              "BadTimeout",
+	StatusBadServiceUnsupported:   "BadServiceUnsupported",
+	StatusBadNothingToDo:          "BadNothingToDo",
+	StatusBadTooManyOperations:    "BadTooManyOperations",
+	StatusBadUserAccessDenied:     "BadUserAccessDenied",
+	StatusBadIdentityTokenInvalid: "BadIdentityTokenInvalid",
+	StatusBadSecureChannelIDInval: "BadSecureChannelIdInvalid",
+	StatusBadSessionIDInvalid:     "BadSessionIdInvalid",
+	StatusBadSubscriptionIDInval:  "BadSubscriptionIdInvalid",
+	StatusBadNodeIDUnknown:        "BadNodeIdUnknown",
+	StatusBadAttributeIDInvalid:   "BadAttributeIdInvalid",
+	StatusBadNotWritable:          "BadNotWritable",
+	StatusBadOutOfRange:           "BadOutOfRange",
+	StatusBadSecurityPolicyReject: "BadSecurityPolicyRejected",
+	StatusBadTypeMismatch:         "BadTypeMismatch",
+	StatusBadNoSubscription:       "BadNoSubscription",
+	StatusBadTCPMessageTypeInval:  "BadTcpMessageTypeInvalid",
+	StatusBadConnectionClosed:     "BadConnectionClosed",
+}
+
+// IsBad reports a failed result.
+func (s StatusCode) IsBad() bool { return s&0x80000000 != 0 }
+
+// String names the code, ignoring the info bits in the low word.
+func (s StatusCode) String() string {
+	if name, ok := statusNames[s&0xFFFF0000]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08X", uint32(s))
+}
+
+// Error lets a Bad code be returned as an error.
+func (s StatusCode) Error() string { return "opc ua: " + s.String() }
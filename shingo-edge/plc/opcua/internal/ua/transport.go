@@ -0,0 +1,309 @@
+package ua
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// SecurityPolicyNone is the only policy spoken here: no signing, no
+// encryption. Plant OPC UA on an isolated cell network commonly runs this
+// way; anything stricter belongs behind WarLink until this grows crypto.
+const SecurityPolicyNone = "http://opcfoundation.org/UA/SecurityPolicy#None"
+
+// UA TCP message types.
+const (
+	MsgHello  = "HEL"
+	MsgAck    = "ACK"
+	MsgError  = "ERR"
+	MsgOpen   = "OPN"
+	MsgSecure = "MSG"
+	MsgClose  = "CLO"
+)
+
+const (
+	headerLen = 8
+	// minChunk is the smallest buffer a peer may negotiate (Part 6 §7.1.2.3).
+	minChunk = 8192
+	// bufferSize is what this side offers for both directions.
+	bufferSize = 65535
+	// maxMessage bounds a reassembled message. A PLC's address space
+	// browse is the biggest thing the edge receives; 16 MiB is far past it.
+	maxMessage = 16 << 20
+)
+
+// Frame is one reassembled message.
+type Frame struct {
+	Type      string
+	ChannelID uint32
+	TokenID   uint32
+	RequestID uint32
+	Body      []byte
+	// Abort is set when the sender gave up on the message part-way; Body
+	// is then empty.
+	Abort StatusCode
+}
+
+// Conn is a UA TCP connection after the HEL/ACK handshake. Send may be called
+// from several goroutines; Receive from one.
+type Conn struct {
+	c     net.Conn
+	r     *bufio.Reader
+	wmu   sync.Mutex
+	seq   uint32
+	chunk int // largest chunk the peer will take
+
+	// chunks being reassembled, by request id. Receive's goroutine only.
+	partial map[uint32][]byte
+}
+
+func newConn(c net.Conn, peerReceive uint32) *Conn {
+	chunk := int(peerReceive)
+	if chunk > bufferSize || chunk == 0 {
+		chunk = bufferSize
+	}
+	if chunk < minChunk {
+		chunk = minChunk
+	}
+	return &Conn{c: c, r: bufio.NewReaderSize(c, bufferSize), chunk: chunk, partial: map[uint32][]byte{}}
+}
+
+// ClientHello sends HEL for the endpoint and waits for the server's ACK.
+func ClientHello(c net.Conn, endpoint string, timeout time.Duration) (*Conn, error) {
+	e := &Encoder{}
+	e.UInt32(0) // protocol version
+	e.UInt32(bufferSize)
+	e.UInt32(bufferSize)
+	e.UInt32(maxMessage)
+	e.UInt32(0) // max chunk count: no limit
+	e.String(endpoint)
+	if err := writeRaw(c, MsgHello, 'F', e.Bytes()); err != nil {
+		return nil, err
+	}
+	_ = c.SetReadDeadline(time.Now().Add(timeout))
+	defer func() { _ = c.SetReadDeadline(time.Time{}) }()
+	r := bufio.NewReaderSize(c, bufferSize)
+	typ, _, body, err := readRaw(r)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDecoder(body)
+	switch typ {
+	case MsgAck:
+		d.UInt32()
+		peerReceive := d.UInt32()
+		if d.Err() != nil {
+			return nil, d.Err()
+		}
+		conn := newConn(c, peerReceive)
+		conn.r = r
+		return conn, nil
+	case MsgError:
+		return nil, decodeErr(d)
+	}
+	return nil, fmt.Errorf("opc ua: %s in reply to HEL: %w", typ, StatusBadTCPMessageTypeInval)
+}
+
+// ServerHello reads the client's HEL and answers ACK. It returns the endpoint
+// URL the client asked for. chunk, when non-zero, is the receive buffer the
+// server offers — a test lowers it to force the client to chunk.
+func ServerHello(c net.Conn, chunk uint32) (*Conn, string, error) {
+	r := bufio.NewReaderSize(c, bufferSize)
+	typ, _, body, err := readRaw(r)
+	if err != nil {
+		return nil, "", err
+	}
+	if typ != MsgHello {
+		return nil, "", fmt.Errorf("opc ua: %s before HEL: %w", typ, StatusBadTCPMessageTypeInval)
+	}
+	d := NewDecoder(body)
+	d.UInt32()
+	peerReceive := d.UInt32()
+	d.UInt32()
+	d.UInt32()
+	d.UInt32()
+	endpoint := d.String()
+	if d.Err() != nil {
+		return nil, "", d.Err()
+	}
+	if chunk == 0 {
+		chunk = bufferSize
+	}
+	e := &Encoder{}
+	e.UInt32(0)
+	e.UInt32(chunk)
+	e.UInt32(chunk)
+	e.UInt32(maxMessage)
+	e.UInt32(0)
+	if err := writeRaw(c, MsgAck, 'F', e.Bytes()); err != nil {
+		return nil, "", err
+	}
+	conn := newConn(c, peerReceive)
+	conn.r = r
+	return conn, endpoint, nil
+}
+
+// Send writes a message, split into chunks the peer can take. OPN carries the
+// asymmetric security header; MSG and CLO the symmetric one with tokenID.
+func (c *Conn) Send(typ string, channelID, tokenID, requestID uint32, body []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	var sec []byte
+	{
+		e := &Encoder{}
+		e.UInt32(channelID)
+		if typ == MsgOpen {
+			e.String(SecurityPolicyNone)
+			e.ByteString(nil) // sender certificate
+			e.ByteString(nil) // receiver thumbprint
+		} else {
+			e.UInt32(tokenID)
+		}
+		sec = e.Bytes()
+	}
+	room := c.chunk - headerLen - len(sec) - 8
+	for {
+		n := len(body)
+		kind := byte('F')
+		if n > room {
+			n, kind = room, 'C'
+		}
+		c.seq++
+		e := &Encoder{b: append([]byte(nil), sec...)}
+		e.UInt32(c.seq)
+		e.UInt32(requestID)
+		e.b = append(e.b, body[:n]...)
+		if err := writeRaw(c.c, typ, kind, e.b); err != nil {
+			return err
+		}
+		body = body[n:]
+		if kind == 'F' {
+			return nil
+		}
+	}
+}
+
+// SendError writes ERR. The caller closes the connection after.
+func (c *Conn) SendError(code StatusCode, reason string) error {
+	e := &Encoder{}
+	e.UInt32(uint32(code))
+	e.String(reason)
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return writeRaw(c.c, MsgError, 'F', e.Bytes())
+}
+
+// Receive reads chunks until a message is complete, and returns it. An ERR
+// from the peer is returned as its status code.
+func (c *Conn) Receive() (Frame, error) {
+	for {
+		typ, kind, body, err := readRaw(c.r)
+		if err != nil {
+			return Frame{}, err
+		}
+		d := NewDecoder(body)
+		f := Frame{Type: typ}
+		switch typ {
+		case MsgError:
+			return f, decodeErr(d)
+		case MsgOpen:
+			f.ChannelID = d.UInt32()
+			if policy := d.String(); d.Err() == nil && policy != SecurityPolicyNone {
+				return f, fmt.Errorf("opc ua: security policy %q: %w", policy, StatusBadSecurityPolicyReject)
+			}
+			d.skipString()
+			d.skipString()
+		case MsgSecure, MsgClose:
+			f.ChannelID = d.UInt32()
+			f.TokenID = d.UInt32()
+		default:
+			return f, fmt.Errorf("opc ua: message type %q: %w", typ, StatusBadTCPMessageTypeInval)
+		}
+		d.UInt32() // sequence number
+		f.RequestID = d.UInt32()
+		if d.Err() != nil {
+			return f, d.Err()
+		}
+		rest := d.Rest()
+		switch kind {
+		case 'A':
+			delete(c.partial, f.RequestID)
+			ad := NewDecoder(rest)
+			f.Abort = ad.Status()
+			if f.Abort == StatusGood {
+				f.Abort = StatusBadCommunicationError
+			}
+			return f, nil
+		case 'C':
+			buf := append(c.partial[f.RequestID], rest...)
+			if len(buf) > maxMessage {
+				return f, fmt.Errorf("opc ua: message over %d bytes: %w", maxMessage, StatusBadEncodingError)
+			}
+			c.partial[f.RequestID] = buf
+			continue
+		case 'F':
+			if prev, ok := c.partial[f.RequestID]; ok {
+				rest = append(prev, rest...)
+				delete(c.partial, f.RequestID)
+			} else {
+				rest = append([]byte(nil), rest...)
+			}
+			f.Body = rest
+			return f, nil
+		}
+		return f, fmt.Errorf("opc ua: chunk type %q: %w", kind, StatusBadTCPMessageTypeInval)
+	}
+}
+
+// Close closes the socket.
+func (c *Conn) Close() error { return c.c.Close() }
+
+func decodeErr(d *Decoder) error {
+	code := d.Status()
+	reason := d.String()
+	if d.Err() != nil {
+		return d.Err()
+	}
+	if code == StatusGood {
+		code = StatusBadUnexpectedError
+	}
+	if reason == "" {
+		return code
+	}
+	return fmt.Errorf("opc ua: server error %q: %w", reason, code)
+}
+
+func writeRaw(w io.Writer, typ string, kind byte, body []byte) error {
+	b := make([]byte, headerLen, headerLen+len(body))
+	copy(b, typ)
+	b[3] = kind
+	binary.LittleEndian.PutUint32(b[4:], uint32(headerLen+len(body)))
+	b = append(b, body...)
+	_, err := w.Write(b)
+	return err
+}
+
+func readRaw(r *bufio.Reader) (string, byte, []byte, error) {
+	var h [headerLen]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return "", 0, nil, err
+	}
+	size := binary.LittleEndian.Uint32(h[4:])
+	if size < headerLen || size > bufferSize {
+		return "", 0, nil, fmt.Errorf("opc ua: chunk of %d bytes: %w", size, StatusBadTCPMessageTypeInval)
+	}
+	body := make([]byte, size-headerLen)
+	if _, err := io.ReadFull(r, body); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", 0, nil, err
+	}
+	return string(h[:3]), h[3], body, nil
+}
@@ -0,0 +1,241 @@
+// Package ua is the slice of the OPC UA binary protocol (Part 6) the edge
+// needs to be a client, and the test server needs to be a server: the
+// built-in types, the handful of services behind browse, read, write and
+// subscriptions, and UA TCP chunking over SecurityPolicy None.
+//
+// It is internal because it is not an OPC UA library and should not be
+// mistaken for one. Anything a PLC is not expected to send — structures inside
+// an ExtensionObject, matrices, XML bodies — is carried opaquely or refused,
+// never guessed at.
+package ua
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IDKind is how a NodeID's identifier is spelled.
+type IDKind byte
+
+const (
+	IDNumeric IDKind = iota
+	IDString
+	IDGuid
+	IDOpaque
+)
+
+// NodeID identifies a node in an address space. It is comparable, so it can
+// key a map: the identifier of a string, GUID or opaque id lives in Str (the
+// GUID in its canonical text form, an opaque id as its raw bytes).
+type NodeID struct {
+	Namespace uint16
+	Kind      IDKind
+	Numeric   uint32
+	Str       string
+}
+
+// NewNumericNodeID returns ns=<ns>;i=<id>.
+func NewNumericNodeID(ns uint16, id uint32) NodeID {
+	return NodeID{Namespace: ns, Kind: IDNumeric, Numeric: id}
+}
+
+// NewStringNodeID returns ns=<ns>;s=<id>.
+func NewStringNodeID(ns uint16, id string) NodeID {
+	return NodeID{Namespace: ns, Kind: IDString, Str: id}
+}
+
+// IsNull reports the null NodeID (ns=0;i=0).
+func (n NodeID) IsNull() bool {
+	return n.Namespace == 0 && n.Kind == IDNumeric && n.Numeric == 0
+}
+
+// String renders the standard text form, e.g. "ns=2;s=Press1.Count" or
+// "i=85". Namespace 0 is left off, as every OPC UA tool does.
+func (n NodeID) String() string {
+	var id string
+	switch n.Kind {
+	case IDString:
+		id = "s=" + n.Str
+	case IDGuid:
+		id = "g=" + n.Str
+	case IDOpaque:
+		id = "b=" + base64.StdEncoding.EncodeToString([]byte(n.Str))
+	default:
+		id = "i=" + strconv.FormatUint(uint64(n.Numeric), 10)
+	}
+	if n.Namespace == 0 {
+		return id
+	}
+	return "ns=" + strconv.Itoa(int(n.Namespace)) + ";" + id
+}
+
+// ParseNodeID reads the text form String writes. A bare "i=85" is namespace 0.
+func ParseNodeID(s string) (NodeID, error) {
+	var n NodeID
+	rest := strings.TrimSpace(s)
+	if strings.HasPrefix(rest, "ns=") {
+		semi := strings.IndexByte(rest, ';')
+		if semi < 0 {
+			return n, fmt.Errorf("node id %q: namespace without identifier", s)
+		}
+		ns, err := strconv.ParseUint(rest[3:semi], 10, 16)
+		if err != nil {
+			return n, fmt.Errorf("node id %q: namespace: %w", s, err)
+		}
+		n.Namespace = uint16(ns)
+		rest = rest[semi+1:]
+	}
+	if len(rest) < 2 || rest[1] != '=' {
+		return n, fmt.Errorf("node id %q: want i=, s=, g= or b=", s)
+	}
+	body := rest[2:]
+	switch rest[0] {
+	case 'i':
+		v, err := strconv.ParseUint(body, 10, 32)
+		if err != nil {
+			return n, fmt.Errorf("node id %q: %w", s, err)
+		}
+		n.Kind, n.Numeric = IDNumeric, uint32(v)
+	case 's':
+		n.Kind, n.Str = IDString, body
+	case 'g':
+		if _, err := guidBytes(body); err != nil {
+			return n, fmt.Errorf("node id %q: %w", s, err)
+		}
+		n.Kind, n.Str = IDGuid, strings.ToLower(body)
+	case 'b':
+		raw, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return n, fmt.Errorf("node id %q: %w", s, err)
+		}
+		n.Kind, n.Str = IDOpaque, string(raw)
+	default:
+		return n, fmt.Errorf("node id %q: want i=, s=, g= or b=", s)
+	}
+	return n, nil
+}
+
+// guidBytes converts "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx" to the wire order:
+// the first three groups little-endian, the last eight bytes as written.
+func guidBytes(s string) ([16]byte, error) {
+	var out [16]byte
+	raw, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(raw) != 16 {
+		return out, fmt.Errorf("bad guid %q", s)
+	}
+	binary.LittleEndian.PutUint32(out[0:], binary.BigEndian.Uint32(raw[0:]))
+	binary.LittleEndian.PutUint16(out[4:], binary.BigEndian.Uint16(raw[4:]))
+	binary.LittleEndian.PutUint16(out[6:], binary.BigEndian.Uint16(raw[6:]))
+	copy(out[8:], raw[8:])
+	return out, nil
+}
+
+func guidString(b []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b[0:]), binary.LittleEndian.Uint16(b[4:]),
+		binary.LittleEndian.Uint16(b[6:]), b[8:10], b[10:16])
+}
+
+// ExpandedNodeID is a NodeID that may name another server's namespace by URI.
+type ExpandedNodeID struct {
+	NodeID
+	NamespaceURI string
+	ServerIndex  uint32
+}
+
+// QualifiedName is a browse name: a namespace-scoped name.
+type QualifiedName struct {
+	Namespace uint16
+	Name      string
+}
+
+// LocalizedText is a display string with an optional locale.
+type LocalizedText struct {
+	Locale string
+	Text   string
+}
+
+// ExtensionObject carries an encoded structure. Body is the raw binary body;
+// nothing in this package looks inside one it did not write.
+type ExtensionObject struct {
+	TypeID   ExpandedNodeID
+	Encoding byte // 0 none, 1 binary, 2 XML
+	Body     []byte
+}
+
+// DiagnosticInfo is decoded so a response carrying one can be read past, and
+// otherwise ignored.
+type DiagnosticInfo struct {
+	SymbolicID     int32
+	NamespaceURI   int32
+	LocalizedText  int32
+	Locale         int32
+	AdditionalInfo string
+	InnerStatus    StatusCode
+	Inner          *DiagnosticInfo
+}
+
+// DataValue is a value with its quality and timestamps.
+type DataValue struct {
+	Value           *Variant
+	Status          StatusCode
+	SourceTimestamp time.Time
+	ServerTimestamp time.Time
+}
+
+// NodeClass values the edge browses for.
+const (
+	NodeClassObject   uint32 = 1
+	NodeClassVariable uint32 = 2
+)
+
+// Attribute ids read and written here.
+const (
+	AttrNodeClass   uint32 = 2
+	AttrBrowseName  uint32 = 3
+	AttrDisplayName uint32 = 4
+	AttrValue       uint32 = 13
+	AttrDataType    uint32 = 14
+	AttrAccessLevel uint32 = 17
+)
+
+// AccessLevel bits.
+const (
+	AccessRead  byte = 0x01
+	AccessWrite byte = 0x02
+)
+
+// Well-known namespace-0 nodes.
+var (
+	ObjectsFolder          = NewNumericNodeID(0, 85)
+	HierarchicalReferences = NewNumericNodeID(0, 33)
+	HasComponent           = NewNumericNodeID(0, 47)
+	Organizes              = NewNumericNodeID(0, 35)
+	FolderType             = NewNumericNodeID(0, 61)
+	BaseDataVariableType   = NewNumericNodeID(0, 63)
+)
+
+// unixEpochSeconds is 1970-01-01 in seconds since the OPC UA DateTime origin,
+// 1601-01-01. A DateTime is 100ns ticks since that origin; time.Duration
+// cannot span it, so conversion goes through Unix seconds.
+const unixEpochSeconds = 11644473600
+
+func ticksToTime(t int64) time.Time {
+	if t <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(t/1e7-unixEpochSeconds, (t%1e7)*100).UTC()
+}
+
+func timeToTicks(t time.Time) int64 {
+	secs := t.Unix() + unixEpochSeconds
+	if t.IsZero() || secs < 0 {
+		return 0
+	}
+	return secs*1e7 + int64(t.Nanosecond()/100)
+}
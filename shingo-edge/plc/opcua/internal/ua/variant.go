@@ -0,0 +1,334 @@
+package ua
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TypeID is a built-in data type, as it appears in a Variant's encoding byte.
+// For 1..15 it is also the numeric id of the type's DataType node.
+type TypeID byte
+
+const (
+	TypeNull            TypeID = 0
+	TypeBoolean         TypeID = 1
+	TypeSByte           TypeID = 2
+	TypeByte            TypeID = 3
+	TypeInt16           TypeID = 4
+	TypeUInt16          TypeID = 5
+	TypeInt32           TypeID = 6
+	TypeUInt32          TypeID = 7
+	TypeInt64           TypeID = 8
+	TypeUInt64          TypeID = 9
+	TypeFloat           TypeID = 10
+	TypeDouble          TypeID = 11
+	TypeString          TypeID = 12
+	TypeDateTime        TypeID = 13
+	TypeGuid            TypeID = 14
+	TypeByteString      TypeID = 15
+	TypeXMLElement      TypeID = 16
+	TypeNodeID          TypeID = 17
+	TypeExpandedNodeID  TypeID = 18
+	TypeStatusCode      TypeID = 19
+	TypeQualifiedName   TypeID = 20
+	TypeLocalizedText   TypeID = 21
+	TypeExtensionObject TypeID = 22
+	TypeDataValue       TypeID = 23
+	TypeVariant         TypeID = 24
+	TypeDiagnosticInfo  TypeID = 25
+)
+
+var typeNames = [...]string{
+	"Null", "Boolean", "SByte", "Byte", "Int16", "UInt16", "Int32", "UInt32",
+	"Int64", "UInt64", "Float", "Double", "String", "DateTime", "Guid",
+	"ByteString", "XmlElement", "NodeId", "ExpandedNodeId", "StatusCode",
+	"QualifiedName", "LocalizedText", "ExtensionObject", "DataValue",
+	"Variant", "DiagnosticInfo",
+}
+
+// String is the type's OPC UA name, e.g. "Int32".
+func (t TypeID) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return fmt.Sprintf("Type(%d)", byte(t))
+}
+
+// Variant is a typed value. Value holds the Go form of the type — bool, int8,
+// uint8, int16, uint16, int32, uint32, int64, uint64, float32, float64,
+// string, time.Time, []byte, NodeID, ExpandedNodeID, StatusCode,
+// QualifiedName, LocalizedText, *ExtensionObject, *DataValue, Variant or
+// *DiagnosticInfo (a Guid is its text form) — or, when Array is set, a []any
+// of those.
+type Variant struct {
+	Type  TypeID
+	Array bool
+	Value any
+}
+
+// VariantOf wraps a Go scalar in the Variant of its natural type.
+func VariantOf(v any) (Variant, error) {
+	switch v.(type) {
+	case bool:
+		return Variant{Type: TypeBoolean, Value: v}, nil
+	case int8:
+		return Variant{Type: TypeSByte, Value: v}, nil
+	case uint8:
+		return Variant{Type: TypeByte, Value: v}, nil
+	case int16:
+		return Variant{Type: TypeInt16, Value: v}, nil
+	case uint16:
+		return Variant{Type: TypeUInt16, Value: v}, nil
+	case int32:
+		return Variant{Type: TypeInt32, Value: v}, nil
+	case uint32:
+		return Variant{Type: TypeUInt32, Value: v}, nil
+	case int64:
+		return Variant{Type: TypeInt64, Value: v}, nil
+	case uint64:
+		return Variant{Type: TypeUInt64, Value: v}, nil
+	case float32:
+		return Variant{Type: TypeFloat, Value: v}, nil
+	case float64:
+		return Variant{Type: TypeDouble, Value: v}, nil
+	case string:
+		return Variant{Type: TypeString, Value: v}, nil
+	case time.Time:
+		return Variant{Type: TypeDateTime, Value: v}, nil
+	case []byte:
+		return Variant{Type: TypeByteString, Value: v}, nil
+	}
+	return Variant{}, fmt.Errorf("opc ua: no variant type for %T", v)
+}
+
+// Variant writes the value. A value whose Go type does not match Type is an
+// encoding error, reported through Err.
+func (e *Encoder) Variant(v Variant) {
+	if !v.Array {
+		e.Byte(byte(v.Type))
+		if v.Type != TypeNull {
+			e.scalar(v.Type, v.Value)
+		}
+		return
+	}
+	items, ok := v.Value.([]any)
+	if !ok {
+		e.fail(fmt.Errorf("opc ua: %s array holds %T", v.Type, v.Value))
+		return
+	}
+	e.Byte(byte(v.Type) | 0x80)
+	e.ArrayLen(len(items))
+	for _, it := range items {
+		e.scalar(v.Type, it)
+	}
+}
+
+func (e *Encoder) scalar(t TypeID, v any) {
+	ok := true
+	switch t {
+	case TypeBoolean:
+		var b bool
+		b, ok = v.(bool)
+		e.Bool(b)
+	case TypeSByte:
+		var n int8
+		n, ok = v.(int8)
+		e.Byte(byte(n))
+	case TypeByte:
+		var n uint8
+		n, ok = v.(uint8)
+		e.Byte(n)
+	case TypeInt16:
+		var n int16
+		n, ok = v.(int16)
+		e.UInt16(uint16(n))
+	case TypeUInt16:
+		var n uint16
+		n, ok = v.(uint16)
+		e.UInt16(n)
+	case TypeInt32:
+		var n int32
+		n, ok = v.(int32)
+		e.Int32(n)
+	case TypeUInt32:
+		var n uint32
+		n, ok = v.(uint32)
+		e.UInt32(n)
+	case TypeInt64:
+		var n int64
+		n, ok = v.(int64)
+		e.Int64(n)
+	case TypeUInt64:
+		var n uint64
+		n, ok = v.(uint64)
+		e.UInt64(n)
+	default:
+		ok = e.scalarWide(t, v)
+	}
+	if !ok {
+		e.fail(fmt.Errorf("opc ua: %s variant holds %T", t, v))
+	}
+}
+
+// scalarWide is scalar for the non-integer types.
+func (e *Encoder) scalarWide(t TypeID, v any) bool {
+	switch t {
+	case TypeFloat:
+		f, ok := v.(float32)
+		e.UInt32(math.Float32bits(f))
+		return ok
+	case TypeDouble:
+		f, ok := v.(float64)
+		e.Double(f)
+		return ok
+	case TypeString, TypeXMLElement:
+		s, ok := v.(string)
+		e.String(s)
+		return ok
+	case TypeDateTime:
+		tm, ok := v.(time.Time)
+		e.Time(tm)
+		return ok
+	case TypeGuid:
+		s, ok := v.(string)
+		g, err := guidBytes(s)
+		e.b = append(e.b, g[:]...)
+		return ok && err == nil
+	case TypeByteString:
+		b, ok := v.([]byte)
+		e.ByteString(b)
+		return ok
+	case TypeNodeID:
+		n, ok := v.(NodeID)
+		e.NodeID(n)
+		return ok
+	case TypeExpandedNodeID:
+		n, ok := v.(ExpandedNodeID)
+		e.ExpandedNodeID(n)
+		return ok
+	case TypeStatusCode:
+		s, ok := v.(StatusCode)
+		e.UInt32(uint32(s))
+		return ok
+	case TypeQualifiedName:
+		q, ok := v.(QualifiedName)
+		e.QualifiedName(q)
+		return ok
+	case TypeLocalizedText:
+		l, ok := v.(LocalizedText)
+		e.LocalizedText(l)
+		return ok
+	case TypeExtensionObject:
+		x, ok := v.(*ExtensionObject)
+		e.ExtensionObject(x)
+		return ok
+	case TypeDataValue:
+		dv, ok := v.(*DataValue)
+		e.DataValue(dv)
+		return ok
+	case TypeVariant:
+		inner, ok := v.(Variant)
+		e.Variant(inner)
+		return ok
+	case TypeDiagnosticInfo:
+		di, ok := v.(*DiagnosticInfo)
+		e.DiagnosticInfo(di)
+		return ok
+	}
+	return false
+}
+
+// Variant reads a value. A multi-dimensional array keeps its flat elements;
+// the dimensions are read and dropped.
+func (d *Decoder) Variant() Variant {
+	mask := d.Byte()
+	v := Variant{Type: TypeID(mask & 0x3F)}
+	if v.Type > TypeDiagnosticInfo {
+		d.fail(fmt.Errorf("opc ua: variant type %d", v.Type))
+		return v
+	}
+	if mask&0x80 == 0 {
+		if v.Type != TypeNull {
+			v.Value = d.scalar(v.Type)
+		}
+		return v
+	}
+	v.Array = true
+	n := d.ArrayLen()
+	items := make([]any, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		items = append(items, d.scalar(v.Type))
+	}
+	v.Value = items
+	if mask&0x40 != 0 {
+		dims := d.ArrayLen()
+		for i := 0; i < dims && d.err == nil; i++ {
+			d.Int32()
+		}
+	}
+	return v
+}
+
+func (d *Decoder) scalar(t TypeID) any {
+	switch t {
+	case TypeBoolean:
+		return d.Bool()
+	case TypeSByte:
+		return int8(d.Byte())
+	case TypeByte:
+		return d.Byte()
+	case TypeInt16:
+		return int16(d.UInt16())
+	case TypeUInt16:
+		return d.UInt16()
+	case TypeInt32:
+		return d.Int32()
+	case TypeUInt32:
+		return d.UInt32()
+	case TypeInt64:
+		return d.Int64()
+	case TypeUInt64:
+		return d.UInt64()
+	case TypeFloat:
+		return math.Float32frombits(d.UInt32())
+	case TypeDouble:
+		return d.Double()
+	case TypeString, TypeXMLElement:
+		return d.String()
+	case TypeDateTime:
+		return d.Time()
+	case TypeGuid:
+		if p := d.take(16); p != nil {
+			return guidString(p)
+		}
+		return ""
+	case TypeByteString:
+		return d.ByteString()
+	}
+	return d.scalarStructured(t)
+}
+
+func (d *Decoder) scalarStructured(t TypeID) any {
+	switch t {
+	case TypeNodeID:
+		return d.NodeID()
+	case TypeExpandedNodeID:
+		return d.ExpandedNodeID()
+	case TypeStatusCode:
+		return d.Status()
+	case TypeQualifiedName:
+		return d.QualifiedName()
+	case TypeLocalizedText:
+		return d.LocalizedText()
+	case TypeExtensionObject:
+		return d.ExtensionObject()
+	case TypeDataValue:
+		return d.DataValue()
+	case TypeVariant:
+		return d.Variant()
+	case TypeDiagnosticInfo:
+		return d.DiagnosticInfo()
+	}
+	return nil
+}
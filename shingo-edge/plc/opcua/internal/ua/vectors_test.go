@@ -0,0 +1,279 @@
+package ua
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Byte-exact vectors. The round-trip tests in codec_test.go pass for any
+// encoder that agrees with its own decoder; these pin the bytes a real
+// server sends and expects. Each is laid out field by field after the
+// OPC UA Part 6 tables (§5.2 built-in types, §6.7 secure conversation,
+// §7.1 UA TCP) and was cross-checked against gopcua v0.8.0's encoder for
+// the same values. Both directions are tested: this package must write
+// exactly the vector, and read it back to the values it was made from.
+
+// vectorTime is 2026-10-18 06:30:00 UTC: 100 ns ticks since 1601-01-01,
+// 0x01DD5ECA19C92400, written little-endian as 00 24 C9 19 CA 5E DD 01.
+var vectorTime = time.Date(2026, 10, 18, 6, 30, 0, 0, time.UTC)
+
+// unhex reads an annotated vector: hex digits, with whitespace and // comments
+// ignored.
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	var digits strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		digits.WriteString(strings.Join(strings.Fields(line), ""))
+	}
+	b, err := hex.DecodeString(digits.String())
+	if err != nil {
+		t.Fatalf("bad vector: %v", err)
+	}
+	return b
+}
+
+const helloVector = `
+48 45 4c 46              // "HEL", chunk type F
+32 00 00 00              // message size 50
+00 00 00 00              // ProtocolVersion 0
+ff ff 00 00              // ReceiveBufferSize 65535
+ff ff 00 00              // SendBufferSize 65535
+00 00 00 01              // MaxMessageSize 16 MiB
+00 00 00 00              // MaxChunkCount 0, no limit
+12 00 00 00              // EndpointUrl: String length 18
+6f 70 63 2e 74 63 70 3a 2f 2f 70 6c 63 3a 34 38 34 30 // "opc.tcp://plc:4840"
+`
+
+const ackVector = `
+41 43 4b 46              // "ACK", chunk type F
+1c 00 00 00              // message size 28
+00 00 00 00              // ProtocolVersion 0
+ff ff 00 00              // ReceiveBufferSize 65535
+ff ff 00 00              // SendBufferSize 65535
+00 00 00 01              // MaxMessageSize 16 MiB
+00 00 00 00              // MaxChunkCount 0
+`
+
+func TestHelloAckVectors(t *testing.T) {
+	hel, ack := unhex(t, helloVector), unhex(t, ackVector)
+
+	// Client side: ClientHello writes the HEL vector and takes the ACK.
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	got := make(chan []byte, 1)
+	go func() {
+		b := make([]byte, len(hel))
+		if _, err := io.ReadFull(server, b); err != nil {
+			got <- nil
+			return
+		}
+		got <- b
+		_, _ = server.Write(ack)
+	}()
+	conn, err := ClientHello(client, "opc.tcp://plc:4840", time.Second)
+	if err != nil {
+		t.Fatalf("ClientHello: %v", err)
+	}
+	if b := <-got; !bytes.Equal(b, hel) {
+		t.Errorf("HEL\n got % x\nwant % x", b, hel)
+	}
+	if conn.chunk != 65535 {
+		t.Errorf("chunk from ACK = %d, want 65535", conn.chunk)
+	}
+
+	// Server side: ServerHello reads the HEL vector and writes the ACK one.
+	client2, server2 := net.Pipe()
+	defer client2.Close()
+	defer server2.Close()
+	go func() {
+		_, _ = client2.Write(hel)
+		b := make([]byte, len(ack))
+		if _, err := io.ReadFull(client2, b); err != nil {
+			got <- nil
+			return
+		}
+		got <- b
+	}()
+	_, endpoint, err := ServerHello(server2, 0)
+	if err != nil {
+		t.Fatalf("ServerHello: %v", err)
+	}
+	if endpoint != "opc.tcp://plc:4840" {
+		t.Errorf("endpoint = %q", endpoint)
+	}
+	if b := <-got; !bytes.Equal(b, ack) {
+		t.Errorf("ACK\n got % x\nwant % x", b, ack)
+	}
+}
+
+const openVector = `
+4f 50 4e 46              // "OPN", chunk type F
+84 00 00 00              // message size 132
+00 00 00 00              // SecureChannelId 0, not yet issued
+// asymmetric security header
+2f 00 00 00              // SecurityPolicyUri: String length 47
+687474703a2f2f6f7063666f756e646174696f6e2e6f72672f55412f5365637572697479506f6c696379234e6f6e65
+ff ff ff ff              // SenderCertificate: null ByteString
+ff ff ff ff              // ReceiverCertificateThumbprint: null ByteString
+// sequence header
+01 00 00 00              // SequenceNumber 1
+01 00 00 00              // RequestId 1
+// body
+01 00 be 01              // type id: four-byte NodeId, ns=0;i=446 (OpenSecureChannelRequest_Encoding_DefaultBinary)
+00 00                    // RequestHeader.AuthenticationToken: two-byte NodeId i=0
+00 24 c9 19 ca 5e dd 01  // Timestamp (vectorTime)
+01 00 00 00              // RequestHandle 1
+00 00 00 00              // ReturnDiagnostics 0
+ff ff ff ff              // AuditEntryId: null String
+10 27 00 00              // TimeoutHint 10000
+00 00 00                 // AdditionalHeader: ExtensionObject, type i=0, no body
+00 00 00 00              // ClientProtocolVersion 0
+00 00 00 00              // RequestType Issue
+01 00 00 00              // SecurityMode None
+ff ff ff ff              // ClientNonce: null ByteString
+80 ee 36 00              // RequestedLifetime 3600000 ms
+`
+
+func TestOpenSecureChannelVector(t *testing.T) {
+	want := unhex(t, openVector)
+	// The request header's audit entry id goes out as the empty String, which
+	// gopcua writes null; Part 6 §5.2.2.4 lets a reader take either, so the
+	// vector keeps the reference's bytes and the encoder's four differ here.
+	const auditEntryAt = 8 + 4 + 4 + 47 + 4 + 4 + 4 + 4 + 4 + 2 + 8 + 4 + 4
+	wantOurs := append([]byte(nil), want...)
+	copy(wantOurs[auditEntryAt:], []byte{0, 0, 0, 0})
+
+	req := &OpenSecureChannelRequest{
+		RequestHeader:     RequestHeader{Timestamp: vectorTime, RequestHandle: 1, TimeoutHint: 10000},
+		RequestType:       TokenIssue,
+		RequestedLifetime: 3600000,
+	}
+	body, err := EncodeMessage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	got := make(chan []byte, 1)
+	go func() {
+		b := make([]byte, len(wantOurs))
+		_, _ = io.ReadFull(server, b)
+		got <- b
+	}()
+	if err := newConn(client, bufferSize).Send(MsgOpen, 0, 0, 1, body); err != nil {
+		t.Fatal(err)
+	}
+	if b := <-got; !bytes.Equal(b, wantOurs) {
+		t.Errorf("OPN\n got % x\nwant % x", b, wantOurs)
+	}
+
+	// The reference bytes, null audit entry id and all, read back.
+	go func() { _, _ = client.Write(want) }()
+	f, err := newConn(server, bufferSize).Receive()
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if f.Type != MsgOpen || f.ChannelID != 0 || f.RequestID != 1 {
+		t.Fatalf("frame = %+v", f)
+	}
+	m, err := DecodeMessage(f.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, req) {
+		t.Errorf("decoded %+v, want %+v", m, req)
+	}
+}
+
+const readResponseVector = `
+01 00 7a 02              // type id: four-byte NodeId, ns=0;i=634 (ReadResponse_Encoding_DefaultBinary)
+00 24 c9 19 ca 5e dd 01  // ResponseHeader.Timestamp (vectorTime)
+07 00 00 00              // RequestHandle 7
+00 00 00 00              // ServiceResult Good
+00                       // ServiceDiagnostics: DiagnosticInfo, empty mask
+ff ff ff ff              // StringTable: null array
+00 00 00                 // AdditionalHeader: ExtensionObject, type i=0, no body
+02 00 00 00              // Results: 2 DataValues
+05                       //   [0] mask: Value | SourceTimestamp
+06 2a 00 00 00           //       Variant Int32 42
+00 24 c9 19 ca 5e dd 01  //       SourceTimestamp (vectorTime)
+02                       //   [1] mask: StatusCode
+00 00 34 80              //       BadNodeIdUnknown 0x80340000
+00 00 00 00              // DiagnosticInfos: empty array
+`
+
+func TestReadResponseVector(t *testing.T) {
+	want := unhex(t, readResponseVector)
+	v := Variant{Type: TypeInt32, Value: int32(42)}
+	resp := &ReadResponse{
+		ResponseHeader: ResponseHeader{Timestamp: vectorTime, RequestHandle: 7},
+		Results: []*DataValue{
+			{Value: &v, SourceTimestamp: vectorTime},
+			{Status: StatusBadNodeIDUnknown},
+		},
+	}
+	b, err := EncodeMessage(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("ReadResponse\n got % x\nwant % x", b, want)
+	}
+	m, err := DecodeMessage(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, resp) {
+		t.Errorf("decoded %+v, want %+v", m, resp)
+	}
+}
+
+func TestVariantVectors(t *testing.T) {
+	cases := []struct {
+		hex string
+		v   Variant
+	}{
+		{"01 01", Variant{Type: TypeBoolean, Value: true}},
+		{"04 fe ff", Variant{Type: TypeInt16, Value: int16(-2)}},
+		{"07 00 28 6b ee", Variant{Type: TypeUInt32, Value: uint32(4_000_000_000)}},
+		{"08 00 00 00 00 00 ff ff ff", Variant{Type: TypeInt64, Value: int64(-1 << 40)}},
+		{"0a 00 00 c0 3f", Variant{Type: TypeFloat, Value: float32(1.5)}},
+		{"0b 00 00 00 00 00 00 02 40", Variant{Type: TypeDouble, Value: 2.25}},
+		{"0c 08 00 00 00 53 54 59 4c 45 2d 34 32", Variant{Type: TypeString, Value: "STYLE-42"}},
+		{"0d 00 24 c9 19 ca 5e dd 01", Variant{Type: TypeDateTime, Value: vectorTime}},
+		{"0f 03 00 00 00 01 02 03", Variant{Type: TypeByteString, Value: []byte{1, 2, 3}}},
+		// NodeId: string encoding 0x03, namespace 2, String "Line1.Count".
+		{"11 03 02 00 0b 00 00 00 4c 69 6e 65 31 2e 43 6f 75 6e 74", Variant{Type: TypeNodeID, Value: NewStringNodeID(2, "Line1.Count")}},
+		// Arrays: type byte with 0x80 set, Int32 length, then the elements.
+		{"86 03 00 00 00 01 00 00 00 02 00 00 00 03 00 00 00", Variant{Type: TypeInt32, Array: true, Value: []any{int32(1), int32(2), int32(3)}}},
+		{"8c 02 00 00 00 01 00 00 00 61 02 00 00 00 62 63", Variant{Type: TypeString, Array: true, Value: []any{"a", "bc"}}},
+		{"8b 01 00 00 00 00 00 00 00 00 00 e0 3f", Variant{Type: TypeDouble, Array: true, Value: []any{0.5}}},
+		{"81 00 00 00 00", Variant{Type: TypeBoolean, Array: true, Value: []any{}}},
+	}
+	for _, tc := range cases {
+		want := unhex(t, tc.hex)
+		e := &Encoder{}
+		e.Variant(tc.v)
+		if e.Err() != nil {
+			t.Fatalf("%s: encode: %v", tc.v.Type, e.Err())
+		}
+		if !bytes.Equal(e.Bytes(), want) {
+			t.Errorf("%s array=%v\n got % x\nwant % x", tc.v.Type, tc.v.Array, e.Bytes(), want)
+		}
+		d := NewDecoder(want)
+		if got := d.Variant(); d.Err() != nil || !reflect.DeepEqual(got, tc.v) {
+			t.Errorf("%s array=%v: decoded %#v (err %v), want %#v", tc.v.Type, tc.v.Array, got, d.Err(), tc.v)
+		}
+	}
+}
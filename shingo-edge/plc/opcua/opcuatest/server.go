@@ -0,0 +1,252 @@
+// Package opcuatest is an in-process OPC UA server for tests: an address
+// space of folders and variables addressed by dotted path, served over UA TCP
+// with SecurityPolicy None, and enough of the session, browse, read, write
+// and subscription services to stand in for a PLC.
+//
+// Node ids are ns=1;s=<path>. The server's anonymous login policy is
+// deliberately not named "anonymous", so a client that hard-codes the name
+// instead of reading it from the endpoints fails here rather than on a line.
+package opcuatest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"shingoedge/plc/opcua/internal/ua"
+)
+
+// AnonymousPolicyID is the policy id the server offers for anonymous logins.
+const AnonymousPolicyID = "anonymous-policy"
+
+type node struct {
+	name     string
+	class    uint32
+	children []ua.NodeID
+	value    ua.Variant
+	writable bool
+	version  uint64
+	changed  time.Time
+}
+
+// Server is a fake PLC's OPC UA endpoint. It answers OpenSecureChannel under
+// SecurityPolicy None only, an anonymous CreateSession/ActivateSession, and
+// Browse, BrowseNext, Read, Write and the subscription calls; any other
+// service gets BadServiceUnsupported, and any call outside an activated
+// session BadSessionIdInvalid. The tuning fields below are read at Start and
+// per connection, so set them first.
+type Server struct {
+	// MaxReferences caps the references one browse result carries; past it
+	// the server hands out continuation points. Zero is no cap.
+	MaxReferences int
+	// ChunkSize is the receive buffer the server offers, which bounds the
+	// client's chunks. Zero is the default 64 KiB.
+	ChunkSize uint32
+	// ChannelLifetime overrides the token lifetime granted to clients, so a
+	// test can watch a renewal. A channel whose token lapses is dropped.
+	ChannelLifetime time.Duration
+
+	mu       sync.Mutex
+	nodes    map[ua.NodeID]*node
+	ln       net.Listener
+	sessions map[*session]struct{}
+	nextID   uint32
+	writes   int
+	wg       sync.WaitGroup
+}
+
+// New returns a server holding only the Objects folder.
+func New() *Server {
+	return &Server{
+		nodes: map[ua.NodeID]*node{
+			ua.ObjectsFolder: {name: "Objects", class: ua.NodeClassObject},
+		},
+		sessions: map[*session]struct{}{},
+	}
+}
+
+// nodeID is the id of a dotted path.
+func nodeID(path string) ua.NodeID { return ua.NewStringNodeID(1, path) }
+
+// NodeID is the text form of a path's node id, e.g. "ns=1;s=Line1".
+func NodeID(path string) string { return nodeID(path).String() }
+
+// AddFolder adds a folder and any missing parents.
+func (s *Server) AddFolder(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addFolderLocked(path)
+}
+
+func (s *Server) addFolderLocked(path string) ua.NodeID {
+	if path == "" {
+		return ua.ObjectsFolder
+	}
+	id := nodeID(path)
+	if _, ok := s.nodes[id]; ok {
+		return id
+	}
+	parent, name := splitPath(path)
+	pid := s.addFolderLocked(parent)
+	s.nodes[id] = &node{name: name, class: ua.NodeClassObject}
+	s.nodes[pid].children = append(s.nodes[pid].children, id)
+	return id
+}
+
+// AddVariable adds a variable holding value, whose Go type fixes the
+// variable's data type: int32 is an Int32 and stays one. It panics on a type
+// the server cannot hold — that is a broken test, not a condition to handle.
+func (s *Server) AddVariable(path string, value any, writable bool) {
+	v, err := ua.VariantOf(value)
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parent, name := splitPath(path)
+	pid := s.addFolderLocked(parent)
+	id := nodeID(path)
+	if _, ok := s.nodes[id]; ok {
+		panic(fmt.Sprintf("opcuatest: %s already exists", path))
+	}
+	s.nodes[id] = &node{name: name, class: ua.NodeClassVariable, value: v, writable: writable, version: 1, changed: time.Now()}
+	s.nodes[pid].children = append(s.nodes[pid].children, id)
+}
+
+// Set changes a variable's value, as the PLC program would. Subscribers see
+// the change on their next publishing interval.
+func (s *Server) Set(path string, value any) {
+	v, err := ua.VariantOf(value)
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.variableLocked(path)
+	n.value = v
+	n.version++
+	n.changed = time.Now()
+}
+
+// Value is a variable's current value.
+func (s *Server) Value(path string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.variableLocked(path).value.Value
+}
+
+// Writes counts the values clients have written.
+func (s *Server) Writes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writes
+}
+
+func (s *Server) variableLocked(path string) *node {
+	n, ok := s.nodes[nodeID(path)]
+	if !ok || n.class != ua.NodeClassVariable {
+		panic(fmt.Sprintf("opcuatest: no variable %s", path))
+	}
+	return n
+}
+
+func splitPath(path string) (parent, name string) {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return "", path
+}
+
+// Start listens on a loopback port and returns the opc.tcp:// endpoint.
+func (s *Server) Start() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.accept(ln)
+	return "opc.tcp://" + ln.Addr().String(), nil
+}
+
+func (s *Server) accept(ln net.Listener) {
+	defer s.wg.Done()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(c)
+		}()
+	}
+}
+
+// Close stops accepting UA TCP connections, drops the open ones — their
+// sessions and subscriptions with them, queued Publish requests unanswered —
+// and waits for every connection goroutine to return. The address space
+// survives, so a test can still read Value after Close.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.ln != nil {
+		s.ln.Close()
+	}
+	s.mu.Unlock()
+	s.DropConnections()
+	s.wg.Wait()
+}
+
+// DropConnections closes every client connection, as a PLC reboot or a
+// pulled cable would. The server keeps listening.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sess := range s.sessions {
+		sess.conn.Close()
+	}
+}
+
+// Connections is how many clients are connected.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+func (s *Server) next() uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) serve(c net.Conn) {
+	conn, _, err := ua.ServerHello(c, s.ChunkSize)
+	if err != nil {
+		c.Close()
+		return
+	}
+	sess := &session{
+		srv:           s,
+		conn:          conn,
+		continuations: map[string][]ua.ReferenceDescription{},
+		done:          make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.sessions[sess] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		close(sess.done)
+		conn.Close()
+		s.mu.Lock()
+		delete(s.sessions, sess)
+		s.mu.Unlock()
+	}()
+	sess.run()
+}
@@ -0,0 +1,489 @@
+package opcuatest
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"shingoedge/plc/opcua/internal/ua"
+)
+
+// session is one client connection: its channel, its session and its
+// subscription. The server never shares a session across connections.
+type session struct {
+	srv  *Server
+	conn *ua.Conn
+
+	mu          sync.Mutex
+	channelID   uint32
+	tokenID     uint32
+	tokenExpiry time.Time
+	auth        ua.NodeID
+	activated   bool
+	sub         *subscription
+	publishes   []uint32 // queued Publish request ids
+	handles     map[uint32]uint32
+	// continuations holds the rest of a browse past MaxReferences.
+	continuations map[string][]ua.ReferenceDescription
+	nextCP        int
+
+	done chan struct{}
+}
+
+type monitored struct {
+	handle uint32
+	id     ua.NodeID
+	sent   uint64 // node version last published
+}
+
+type subscription struct {
+	id        uint32
+	interval  time.Duration
+	keepAlive uint32
+	items     []*monitored
+	seq       uint32
+	idle      uint32
+}
+
+func (s *session) run() {
+	for {
+		f, err := s.conn.Receive()
+		if err != nil || f.Type == ua.MsgClose {
+			return
+		}
+		if f.Type == ua.MsgSecure && !s.tokenValid(f.TokenID) {
+			_ = s.conn.SendError(ua.StatusBadSecureChannelIDInval, "secure channel token expired")
+			return
+		}
+		m, err := ua.DecodeMessage(f.Body)
+		if err != nil {
+			_ = s.conn.SendError(ua.StatusBadDecodingError, err.Error())
+			return
+		}
+		req, ok := m.(ua.Request)
+		if !ok {
+			_ = s.conn.SendError(ua.StatusBadDecodingError, "not a request")
+			return
+		}
+		if resp := s.handle(f.RequestID, req); resp != nil {
+			s.respond(f.Type, f.RequestID, req.Header().RequestHandle, resp)
+		}
+	}
+}
+
+// tokenValid accepts the current token, and the previous one while a renewal
+// is settling, until the current one lapses.
+func (s *session) tokenValid(id uint32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id != s.tokenID && id+1 != s.tokenID {
+		return false
+	}
+	return s.srv.ChannelLifetime == 0 || time.Now().Before(s.tokenExpiry)
+}
+
+func (s *session) respond(typ string, requestID, handle uint32, resp ua.Response) {
+	h := resp.RespHeader()
+	h.RequestHandle = handle
+	h.Timestamp = time.Now()
+	body, err := ua.EncodeMessage(resp)
+	if err != nil {
+		body, _ = ua.EncodeMessage(fault(ua.StatusBadEncodingError))
+	}
+	s.mu.Lock()
+	channelID, tokenID := s.channelID, s.tokenID
+	s.mu.Unlock()
+	_ = s.conn.Send(typ, channelID, tokenID, requestID, body)
+}
+
+func fault(code ua.StatusCode) *ua.ServiceFault {
+	return &ua.ServiceFault{ResponseHeader: ua.ResponseHeader{ServiceResult: code}}
+}
+
+// handle answers a request, or returns nil for a Publish it has queued.
+func (s *session) handle(requestID uint32, req ua.Request) ua.Response {
+	switch r := req.(type) {
+	case *ua.OpenSecureChannelRequest:
+		return s.openChannel(r)
+	case *ua.CreateSessionRequest:
+		return s.createSession()
+	case *ua.ActivateSessionRequest:
+		return s.activateSession(r)
+	}
+	s.mu.Lock()
+	ok := s.activated && req.Header().AuthenticationToken == s.auth
+	s.mu.Unlock()
+	if !ok {
+		return fault(ua.StatusBadSessionIDInvalid)
+	}
+	switch r := req.(type) {
+	case *ua.CloseSessionRequest:
+		s.mu.Lock()
+		s.activated = false
+		s.mu.Unlock()
+		return &ua.CloseSessionResponse{}
+	case *ua.BrowseRequest:
+		return &ua.BrowseResponse{Results: s.browse(r)}
+	case *ua.BrowseNextRequest:
+		return &ua.BrowseNextResponse{Results: s.browseNext(r)}
+	case *ua.ReadRequest:
+		return &ua.ReadResponse{Results: s.srv.read(r.Nodes)}
+	case *ua.WriteRequest:
+		return &ua.WriteResponse{Results: s.srv.write(r.Nodes)}
+	case *ua.CreateSubscriptionRequest:
+		return s.createSubscription(r)
+	case *ua.CreateMonitoredItemsRequest:
+		return s.createMonitoredItems(r)
+	case *ua.PublishRequest:
+		return s.queuePublish(requestID, r)
+	}
+	return fault(ua.StatusBadServiceUnsupported)
+}
+
+func (s *session) openChannel(r *ua.OpenSecureChannelRequest) ua.Response {
+	lifetime := time.Duration(r.RequestedLifetime) * time.Millisecond
+	if s.srv.ChannelLifetime > 0 {
+		lifetime = s.srv.ChannelLifetime
+	}
+	var id uint32
+	if r.RequestType == ua.TokenIssue {
+		id = s.srv.next()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.RequestType == ua.TokenIssue {
+		s.channelID, s.tokenID = id, 1
+	} else {
+		s.tokenID++
+	}
+	s.tokenExpiry = time.Now().Add(lifetime)
+	return &ua.OpenSecureChannelResponse{
+		ChannelID:       s.channelID,
+		TokenID:         s.tokenID,
+		CreatedAt:       time.Now(),
+		RevisedLifetime: uint32(lifetime / time.Millisecond),
+	}
+}
+
+func (s *session) createSession() ua.Response {
+	auth := ua.NewNumericNodeID(1, 1000+s.srv.next())
+	s.mu.Lock()
+	s.auth = auth
+	s.mu.Unlock()
+	return &ua.CreateSessionResponse{
+		SessionID:           ua.NewNumericNodeID(1, auth.Numeric+1000),
+		AuthenticationToken: auth,
+		SessionTimeout:      float64(time.Minute / time.Millisecond),
+		Endpoints: []ua.EndpointDescription{{
+			SecurityMode:      ua.MessageSecurityModeNone,
+			SecurityPolicyURI: ua.SecurityPolicyNone,
+			UserTokens:        []ua.UserTokenPolicy{{PolicyID: AnonymousPolicyID, TokenType: ua.UserTokenAnonymous}},
+		}},
+	}
+}
+
+func (s *session) activateSession(r *ua.ActivateSessionRequest) ua.Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.AuthenticationToken != s.auth {
+		return fault(ua.StatusBadSessionIDInvalid)
+	}
+	m, _ := ua.Unwrap(r.Identity)
+	if tok, ok := m.(*ua.AnonymousIdentityToken); !ok || tok.PolicyID != AnonymousPolicyID {
+		return fault(ua.StatusBadIdentityTokenInvalid)
+	}
+	s.activated = true
+	return &ua.ActivateSessionResponse{}
+}
+
+// ── Browse ─────────────────────────────────────────────────────────────
+
+func (s *session) browse(r *ua.BrowseRequest) []ua.BrowseResult {
+	limit := s.srv.MaxReferences
+	if r.MaxReferences > 0 && (limit == 0 || int(r.MaxReferences) < limit) {
+		limit = int(r.MaxReferences)
+	}
+	out := make([]ua.BrowseResult, len(r.Nodes))
+	for i, bd := range r.Nodes {
+		refs, status := s.srv.references(bd)
+		if status.IsBad() {
+			out[i].Status = status
+			continue
+		}
+		out[i] = s.page(refs, limit)
+	}
+	return out
+}
+
+func (s *session) browseNext(r *ua.BrowseNextRequest) []ua.BrowseResult {
+	out := make([]ua.BrowseResult, len(r.ContinuationPoints))
+	for i, cp := range r.ContinuationPoints {
+		s.mu.Lock()
+		rest, ok := s.continuations[string(cp)]
+		delete(s.continuations, string(cp))
+		s.mu.Unlock()
+		switch {
+		case !ok:
+			out[i].Status = ua.StatusBadUnexpectedError
+		case r.Release:
+		default:
+			out[i] = s.page(rest, s.srv.MaxReferences)
+		}
+	}
+	return out
+}
+
+// page returns up to limit references, parking the rest behind a
+// continuation point.
+func (s *session) page(refs []ua.ReferenceDescription, limit int) ua.BrowseResult {
+	if limit == 0 || len(refs) <= limit {
+		return ua.BrowseResult{References: refs}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextCP++
+	cp := "cp" + strconv.Itoa(s.nextCP)
+	s.continuations[cp] = refs[limit:]
+	return ua.BrowseResult{References: refs[:limit], ContinuationPoint: []byte(cp)}
+}
+
+func (s *Server) references(bd ua.BrowseDescription) ([]ua.ReferenceDescription, ua.StatusCode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nodes[bd.NodeID]
+	if !ok {
+		return nil, ua.StatusBadNodeIDUnknown
+	}
+	var refs []ua.ReferenceDescription
+	for _, cid := range n.children {
+		child := s.nodes[cid]
+		if bd.NodeClassMask != 0 && bd.NodeClassMask&child.class == 0 {
+			continue
+		}
+		refType, typeDef := ua.Organizes, ua.FolderType
+		if child.class == ua.NodeClassVariable {
+			refType, typeDef = ua.HasComponent, ua.BaseDataVariableType
+		}
+		if bd.ReferenceTypeID != refType && !(bd.ReferenceTypeID == ua.HierarchicalReferences && bd.IncludeSubtypes) {
+			continue
+		}
+		refs = append(refs, ua.ReferenceDescription{
+			ReferenceTypeID: refType,
+			IsForward:       true,
+			NodeID:          ua.ExpandedNodeID{NodeID: cid},
+			BrowseName:      ua.QualifiedName{Namespace: 1, Name: child.name},
+			DisplayName:     ua.LocalizedText{Text: child.name},
+			NodeClass:       child.class,
+			TypeDefinition:  ua.ExpandedNodeID{NodeID: typeDef},
+		})
+	}
+	return refs, ua.StatusGood
+}
+
+// ── Read / Write ───────────────────────────────────────────────────────
+
+func (s *Server) read(reads []ua.ReadValueID) []*ua.DataValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*ua.DataValue, len(reads))
+	for i, rv := range reads {
+		out[i] = s.attributeLocked(rv)
+	}
+	return out
+}
+
+func (s *Server) attributeLocked(rv ua.ReadValueID) *ua.DataValue {
+	n, ok := s.nodes[rv.NodeID]
+	if !ok {
+		return &ua.DataValue{Status: ua.StatusBadNodeIDUnknown}
+	}
+	value := func(t ua.TypeID, x any) *ua.DataValue {
+		return &ua.DataValue{Value: &ua.Variant{Type: t, Value: x}}
+	}
+	switch rv.AttributeID {
+	case ua.AttrNodeClass:
+		return value(ua.TypeInt32, int32(n.class))
+	case ua.AttrBrowseName:
+		return value(ua.TypeQualifiedName, ua.QualifiedName{Namespace: 1, Name: n.name})
+	case ua.AttrDisplayName:
+		return value(ua.TypeLocalizedText, ua.LocalizedText{Text: n.name})
+	}
+	if n.class != ua.NodeClassVariable {
+		return &ua.DataValue{Status: ua.StatusBadAttributeIDInvalid}
+	}
+	switch rv.AttributeID {
+	case ua.AttrValue:
+		v := n.value
+		return &ua.DataValue{Value: &v, SourceTimestamp: n.changed}
+	case ua.AttrDataType:
+		return value(ua.TypeNodeID, ua.NewNumericNodeID(0, uint32(n.value.Type)))
+	case ua.AttrAccessLevel:
+		level := ua.AccessRead
+		if n.writable {
+			level |= ua.AccessWrite
+		}
+		return value(ua.TypeByte, level)
+	}
+	return &ua.DataValue{Status: ua.StatusBadAttributeIDInvalid}
+}
+
+func (s *Server) write(writes []ua.WriteValue) []ua.StatusCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]ua.StatusCode, len(writes))
+	for i, wv := range writes {
+		n, ok := s.nodes[wv.NodeID]
+		switch {
+		case !ok:
+			out[i] = ua.StatusBadNodeIDUnknown
+		case n.class != ua.NodeClassVariable || wv.AttributeID != ua.AttrValue:
+			out[i] = ua.StatusBadAttributeIDInvalid
+		case !n.writable:
+			out[i] = ua.StatusBadNotWritable
+		case wv.Value == nil || wv.Value.Value == nil || wv.Value.Value.Array || wv.Value.Value.Type != n.value.Type:
+			// Like a real server: no conversion, the client sends the
+			// node's own type or nothing.
+			out[i] = ua.StatusBadTypeMismatch
+		default:
+			n.value = *wv.Value.Value
+			n.version++
+			n.changed = time.Now()
+			s.writes++
+		}
+	}
+	return out
+}
+
+// ── Subscriptions ──────────────────────────────────────────────────────
+
+func (s *session) createSubscription(r *ua.CreateSubscriptionRequest) ua.Response {
+	interval := time.Duration(r.PublishingInterval * float64(time.Millisecond))
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	keepAlive := r.MaxKeepAliveCount
+	if keepAlive == 0 {
+		keepAlive = 10
+	}
+	sub := &subscription{id: s.srv.next(), interval: interval, keepAlive: keepAlive}
+	s.mu.Lock()
+	if s.sub != nil {
+		s.mu.Unlock()
+		return fault(ua.StatusBadTooManyOperations)
+	}
+	s.sub = sub
+	s.mu.Unlock()
+	go s.publishLoop(sub)
+	return &ua.CreateSubscriptionResponse{
+		SubscriptionID:     sub.id,
+		PublishingInterval: float64(interval / time.Millisecond),
+		LifetimeCount:      r.LifetimeCount,
+		MaxKeepAliveCount:  keepAlive,
+	}
+}
+
+func (s *session) createMonitoredItems(r *ua.CreateMonitoredItemsRequest) ua.Response {
+	s.mu.Lock()
+	sub := s.sub
+	s.mu.Unlock()
+	if sub == nil || sub.id != r.SubscriptionID {
+		return fault(ua.StatusBadSubscriptionIDInval)
+	}
+	results := make([]ua.MonitoredItemResult, len(r.Items))
+	s.srv.mu.Lock()
+	var items []*monitored
+	for i, it := range r.Items {
+		n, ok := s.srv.nodes[it.Item.NodeID]
+		if !ok || n.class != ua.NodeClassVariable {
+			results[i].Status = ua.StatusBadNodeIDUnknown
+			continue
+		}
+		results[i].MonitoredItemID = uint32(i + 1)
+		items = append(items, &monitored{handle: it.ClientHandle, id: it.Item.NodeID})
+	}
+	s.srv.mu.Unlock()
+	s.mu.Lock()
+	sub.items = append(sub.items, items...)
+	s.mu.Unlock()
+	return &ua.CreateMonitoredItemsResponse{Results: results}
+}
+
+func (s *session) queuePublish(requestID uint32, r *ua.PublishRequest) ua.Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sub == nil {
+		return fault(ua.StatusBadNoSubscription)
+	}
+	s.publishes = append(s.publishes, requestID)
+	if s.handles == nil {
+		s.handles = map[uint32]uint32{}
+	}
+	s.handles[requestID] = r.RequestHandle
+	return nil
+}
+
+// publishLoop answers a queued Publish each interval that has changes, and
+// with a keep-alive after keepAlive intervals that have none.
+func (s *session) publishLoop(sub *subscription) {
+	t := time.NewTicker(sub.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		}
+		s.tick(sub)
+	}
+}
+
+func (s *session) tick(sub *subscription) {
+	s.srv.mu.Lock()
+	s.mu.Lock()
+	if len(s.publishes) == 0 {
+		s.mu.Unlock()
+		s.srv.mu.Unlock()
+		return
+	}
+	note := &ua.DataChangeNotification{}
+	for _, it := range sub.items {
+		n := s.srv.nodes[it.id]
+		if n.version == it.sent {
+			continue
+		}
+		it.sent = n.version
+		v := n.value
+		note.Items = append(note.Items, ua.MonitoredItemNotification{
+			ClientHandle: it.handle,
+			Value:        &ua.DataValue{Value: &v, SourceTimestamp: n.changed},
+		})
+	}
+	s.srv.mu.Unlock()
+
+	resp := &ua.PublishResponse{SubscriptionID: sub.id, PublishTime: time.Now()}
+	if len(note.Items) > 0 {
+		x, err := ua.Wrap(note)
+		if err != nil {
+			s.mu.Unlock()
+			return
+		}
+		sub.seq++
+		sub.idle = 0
+		resp.SequenceNumber = sub.seq
+		resp.Notifications = []*ua.ExtensionObject{x}
+	} else {
+		sub.idle++
+		if sub.idle < sub.keepAlive {
+			s.mu.Unlock()
+			return
+		}
+		sub.idle = 0
+		resp.SequenceNumber = sub.seq + 1 // a keep-alive names the next number
+	}
+	requestID := s.publishes[0]
+	s.publishes = s.publishes[1:]
+	handle := s.handles[requestID]
+	delete(s.handles, requestID)
+	s.mu.Unlock()
+	s.respond(ua.MsgSecure, requestID, handle, resp)
+}
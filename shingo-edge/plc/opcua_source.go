@@ -0,0 +1,442 @@
+package plc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+
+	"shingoedge/config"
+	"shingoedge/plc/opcua"
+)
+
+// opcuaDriver is an OPC UA source's PLCHealth.Driver.
+const opcuaDriver = "opcua"
+
+// opcuaMaxDepth bounds the browse below a source's root. PLC tag trees are a
+// few folders deep; the cap is for a server that reaches the same object
+// under two node ids, which the visited set cannot see through.
+const opcuaMaxDepth = 8
+
+// opcuaReadBatch is how many attributes one Read asks for. Servers cap
+// operations per request (MaxNodesPerRead), commonly at a few thousand;
+// staying well under keeps a big line from failing discovery outright.
+const opcuaReadBatch = 500
+
+// opcuaTag is what discovery learned about one variable.
+type opcuaTag struct {
+	id       opcua.NodeID
+	typ      opcua.TypeID
+	array    bool
+	writable bool
+}
+
+// opcuaSource keeps one OPC UA PLC connected. Its tags are the variables under
+// the configured root, named by browse path ("Press.Count"), all subscribed:
+// OPC UA has no WarLink-style publish switch, and a PLC's tag count is small
+// enough that monitoring every one costs nothing worth managing.
+//
+// Discovery runs once per connection. A tag added to the PLC program shows up
+// the next time the source connects.
+type opcuaSource struct {
+	m   *Manager
+	cfg config.PLCSourceConfig
+
+	mu     sync.RWMutex
+	client *opcua.Client // nil while disconnected
+	tags   map[string]opcuaTag
+}
+
+func newOPCUASource(m *Manager, cfg config.PLCSourceConfig) *opcuaSource {
+	return &opcuaSource{m: m, cfg: cfg}
+}
+
+// run reconnects with the same capped backoff the WarLink stream uses, until
+// the manager stops. A connection that made it to Connected resets the
+// backoff: a PLC that drops once a day should not wait 30s to come back.
+func (s *opcuaSource) run() {
+	attempt := 0
+	for {
+		up, err := s.connect()
+		if err == nil {
+			return // stopping
+		}
+		log.Printf("PLC %s (OPC UA %s): %v", s.cfg.Name, s.cfg.Endpoint, err)
+		s.m.applySourceStatus(s.cfg.Name, opcuaDriver, false, err)
+		if up {
+			attempt = 0
+		}
+		attempt++
+		if !s.m.sourceBackoff(s.cfg.Name, attempt) {
+			return
+		}
+	}
+}
+
+// connect runs one connection: dial, discover, seed the cache, subscribe,
+// then wait for it to end. It returns nil when the manager stops and
+// otherwise why the connection ended; up reports whether it reached
+// Connected.
+func (s *opcuaSource) connect() (up bool, err error) {
+	ctx, cancel := s.m.sourceContext()
+	defer cancel()
+	stopped := s.m.unlessStopping
+
+	c, err := opcua.Dial(ctx, s.cfg.Endpoint)
+	if err != nil {
+		return false, stopped(err)
+	}
+	defer func() {
+		s.mu.Lock()
+		s.client = nil
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	ids, err := s.browse(ctx, c)
+	if err != nil {
+		return false, stopped(err)
+	}
+	names := make([]string, 0, len(ids))
+	for name := range ids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tags, initial, err := s.describe(ctx, c, names, ids)
+	if err != nil {
+		return false, stopped(err)
+	}
+
+	s.m.mu.RLock()
+	mp := s.m.plcs[s.cfg.Name]
+	s.m.mu.RUnlock()
+	for i, name := range names {
+		s.m.applyValueChange(mp, opcuaTagValue(name, initial[i]))
+	}
+	nodes := make([]opcua.NodeID, len(names))
+	for i, name := range names {
+		nodes[i] = ids[name]
+	}
+	err = c.Subscribe(ctx, s.cfg.Interval(), nodes, func(i int, dv *opcua.DataValue) {
+		s.m.applyValueChange(mp, opcuaTagValue(names[i], dv))
+	})
+	if err != nil {
+		return false, stopped(err)
+	}
+
+	s.mu.Lock()
+	s.client, s.tags = c, tags
+	s.mu.Unlock()
+	s.m.applySourceStatus(s.cfg.Name, opcuaDriver, true, nil)
+
+	select {
+	case <-c.Done():
+		return true, stopped(c.Err())
+	case <-ctx.Done():
+		return true, nil
+	}
+}
+
+// browse walks the objects under the source's root and returns the variables
+// it finds, by browse path. Namespace 0 is the server's own — the Server
+// object and its diagnostics — and is never walked.
+func (s *opcuaSource) browse(ctx context.Context, c *opcua.Client) (map[string]opcua.NodeID, error) {
+	root := opcua.ObjectsFolder
+	if s.cfg.Root != "" {
+		id, err := opcua.ParseNodeID(s.cfg.Root)
+		if err != nil {
+			return nil, fmt.Errorf("root: %w", err)
+		}
+		root = id
+	}
+	found := map[string]opcua.NodeID{}
+	visited := map[opcua.NodeID]bool{root: true}
+	var walk func(id opcua.NodeID, prefix string, depth int) error
+	walk = func(id opcua.NodeID, prefix string, depth int) error {
+		refs, err := c.Browse(ctx, id)
+		if err != nil {
+			return fmt.Errorf("browse %s: %w", id, err)
+		}
+		for _, r := range refs {
+			if r.NodeID.Namespace == 0 || visited[r.NodeID] {
+				continue
+			}
+			visited[r.NodeID] = true
+			name := prefix + r.BrowseName
+			switch r.NodeClass {
+			case opcua.NodeClassVariable:
+				found[name] = r.NodeID
+			case opcua.NodeClassObject:
+				if depth < opcuaMaxDepth {
+					if err := walk(r.NodeID, name+".", depth+1); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	return found, walk(root, "", 0)
+}
+
+// describe reads each variable's value and access level: the value's type is
+// the type a write must carry, and the access level says whether it may.
+func (s *opcuaSource) describe(ctx context.Context, c *opcua.Client, names []string, ids map[string]opcua.NodeID) (map[string]opcuaTag, []*opcua.DataValue, error) {
+	reads := make([]opcua.ReadValueID, 0, 2*len(names))
+	for _, name := range names {
+		reads = append(reads,
+			opcua.ReadValueID{NodeID: ids[name], AttributeID: opcua.AttrValue},
+			opcua.ReadValueID{NodeID: ids[name], AttributeID: opcua.AttrAccessLevel})
+	}
+	dvs := make([]*opcua.DataValue, 0, len(reads))
+	for start := 0; start < len(reads); start += opcuaReadBatch {
+		got, err := c.Read(ctx, reads[start:min(start+opcuaReadBatch, len(reads))]...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read tags: %w", err)
+		}
+		dvs = append(dvs, got...)
+	}
+	tags := make(map[string]opcuaTag, len(names))
+	values := make([]*opcua.DataValue, len(names))
+	for i, name := range names {
+		val, access := dvs[2*i], dvs[2*i+1]
+		t := opcuaTag{id: ids[name]}
+		if val.Value != nil {
+			t.typ, t.array = val.Value.Type, val.Value.Array
+		}
+		if access.Value != nil {
+			level, _ := access.Value.Value.(uint8)
+			t.writable = level&opcua.AccessWrite != 0
+		}
+		tags[name] = t
+		values[i] = val
+	}
+	return tags, values, nil
+}
+
+func opcuaTagValue(name string, dv *opcua.DataValue) TagValue {
+	tv := TagValue{Name: name}
+	if dv.Value != nil {
+		tv.TypeStr = dv.Value.Type.String()
+		if dv.Value.Array {
+			tv.TypeStr += "[]"
+		}
+		tv.Value = dv.Value.Value
+	}
+	if dv.Status.IsBad() {
+		tv.Error = dv.Status.String()
+	}
+	return tv
+}
+
+func (s *opcuaSource) lookup(tag string) (*opcua.Client, opcuaTag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.client == nil {
+		return nil, opcuaTag{}, fmt.Errorf("PLC %s not connected", s.cfg.Name)
+	}
+	t, ok := s.tags[tag]
+	if !ok {
+		return nil, opcuaTag{}, fmt.Errorf("tag %s not found on %s", tag, s.cfg.Name)
+	}
+	return s.client, t, nil
+}
+
+func (s *opcuaSource) readTag(ctx context.Context, tag string) (any, error) {
+	c, t, err := s.lookup(tag)
+	if err != nil {
+		return nil, err
+	}
+	dvs, err := c.Read(ctx, opcua.ReadValueID{NodeID: t.id, AttributeID: opcua.AttrValue})
+	if err != nil {
+		return nil, fmt.Errorf("read %s/%s: %w", s.cfg.Name, tag, err)
+	}
+	if dvs[0].Status.IsBad() {
+		return nil, fmt.Errorf("read %s/%s: %w", s.cfg.Name, tag, dvs[0].Status)
+	}
+	if dvs[0].Value == nil {
+		return nil, nil
+	}
+	return dvs[0].Value.Value, nil
+}
+
+// writeTag writes value converted to the tag's own type. OPC UA servers do
+// not convert — an Int32 sent to an Int16 tag is refused — and callers hand
+// over whatever JSON or Go gave them, so the conversion happens here, with
+// range checks: a 70000 bound for an Int16 is an error, never a wrap.
+func (s *opcuaSource) writeTag(ctx context.Context, tag string, value any) error {
+	c, t, err := s.lookup(tag)
+	if err != nil {
+		return err
+	}
+	if !t.writable {
+		return fmt.Errorf("tag %s on %s is not writable", tag, s.cfg.Name)
+	}
+	if t.array {
+		return fmt.Errorf("tag %s on %s is an array; array writes are not supported", tag, s.cfg.Name)
+	}
+	v, err := coerceVariant(value, t.typ)
+	if err != nil {
+		return fmt.Errorf("write %s/%s: %w", s.cfg.Name, tag, err)
+	}
+	if err := c.Write(ctx, t.id, v); err != nil {
+		return fmt.Errorf("write %s/%s: %w", s.cfg.Name, tag, err)
+	}
+	return nil
+}
+
+// listTags is the discovered tag set. Every tag is configured and published:
+// see the note on opcuaSource.
+func (s *opcuaSource) listTags(ctx context.Context) ([]WarlinkTagInfo, error) {
+	s.mu.RLock()
+	connected := s.client != nil
+	tags := s.tags
+	s.mu.RUnlock()
+	if !connected {
+		return nil, fmt.Errorf("PLC %s not connected", s.cfg.Name)
+	}
+	s.m.mu.RLock()
+	mp := s.m.plcs[s.cfg.Name]
+	s.m.mu.RUnlock()
+
+	out := make([]WarlinkTagInfo, 0, len(tags))
+	mp.mu.RLock()
+	for name, t := range tags {
+		out = append(out, WarlinkTagInfo{
+			Name:       name,
+			Type:       mp.Values[name].TypeStr,
+			Configured: true,
+			Enabled:    true,
+			Writable:   t.writable,
+			Value:      mp.Values[name].Value,
+		})
+	}
+	mp.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// intRange is the span of each integer type, for write range checks.
+var intRange = map[opcua.TypeID][2]int64{
+	opcua.TypeSByte:  {math.MinInt8, math.MaxInt8},
+	opcua.TypeByte:   {0, math.MaxUint8},
+	opcua.TypeInt16:  {math.MinInt16, math.MaxInt16},
+	opcua.TypeUInt16: {0, math.MaxUint16},
+	opcua.TypeInt32:  {math.MinInt32, math.MaxInt32},
+	opcua.TypeUInt32: {0, math.MaxUint32},
+	opcua.TypeInt64:  {math.MinInt64, math.MaxInt64},
+	opcua.TypeUInt64: {0, math.MaxInt64},
+}
+
+// coerceVariant converts a Go or JSON-decoded value to the tag's type.
+func coerceVariant(v any, t opcua.TypeID) (opcua.Variant, error) {
+	out := opcua.Variant{Type: t}
+	if span, isInt := intRange[t]; isInt {
+		if u, ok := v.(uint64); ok && t == opcua.TypeUInt64 {
+			out.Value = u
+			return out, nil
+		}
+		n, ok := asInt64(v)
+		if !ok {
+			return out, fmt.Errorf("cannot write %T %v to a %s tag", v, v, t)
+		}
+		if n < span[0] || n > span[1] {
+			return out, fmt.Errorf("%d is out of range for a %s tag", n, t)
+		}
+		switch t {
+		case opcua.TypeSByte:
+			out.Value = int8(n)
+		case opcua.TypeByte:
+			out.Value = uint8(n)
+		case opcua.TypeInt16:
+			out.Value = int16(n)
+		case opcua.TypeUInt16:
+			out.Value = uint16(n)
+		case opcua.TypeInt32:
+			out.Value = int32(n)
+		case opcua.TypeUInt32:
+			out.Value = uint32(n)
+		case opcua.TypeInt64:
+			out.Value = n
+		case opcua.TypeUInt64:
+			out.Value = uint64(n)
+		}
+		return out, nil
+	}
+	switch t {
+	case opcua.TypeBoolean:
+		if b, ok := v.(bool); ok {
+			out.Value = b
+			return out, nil
+		}
+		// 0 and 1 are how a lot of HMI code spells a bit.
+		if n, ok := asInt64(v); ok && (n == 0 || n == 1) {
+			out.Value = n == 1
+			return out, nil
+		}
+	case opcua.TypeFloat:
+		if f, ok := asFloat64(v); ok {
+			if math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+				return out, fmt.Errorf("%g is out of range for a Float tag", f)
+			}
+			out.Value = float32(f)
+			return out, nil
+		}
+	case opcua.TypeDouble:
+		if f, ok := asFloat64(v); ok {
+			out.Value = f
+			return out, nil
+		}
+	case opcua.TypeString:
+		if s, ok := v.(string); ok {
+			out.Value = s
+			return out, nil
+		}
+	default:
+		return out, fmt.Errorf("writing a %s tag is not supported", t)
+	}
+	return out, fmt.Errorf("cannot write %T %v to a %s tag", v, v, t)
+}
+
+// asInt64 accepts any integer, and a float or JSON number only when it is a
+// whole number: 3.5 written to a counter is a caller bug to surface, not to
+// truncate.
+func asInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case uint:
+		return int64(n), uint64(n) <= math.MaxInt64
+	case float64:
+		return int64(n), n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64
+	case float32:
+		return asInt64(float64(n))
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		return 0, false
+	case bool:
+		return 0, false
+	}
+	return ToInt64(v)
+}
+
+func asFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	if n, ok := ToInt64(v); ok {
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,162 @@
+package plc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"shingo/protocol/testutil"
+	"shingoedge/config"
+	"shingoedge/plc/opcua"
+	"shingoedge/plc/opcua/opcuatest"
+)
+
+func startOPCUASource(t *testing.T, srv *opcuatest.Server) (*Manager, *mockEmitter) {
+	t.Helper()
+	endpoint, err := srv.Start()
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	cfg := config.Defaults()
+	cfg.PLCSources = []config.PLCSourceConfig{{
+		Name:            "press1",
+		Driver:          config.PLCDriverOPCUA,
+		Endpoint:        endpoint,
+		PublishInterval: 20 * time.Millisecond,
+	}}
+	emitter := &mockEmitter{}
+	mgr := NewManager(nil, cfg, emitter, nil)
+	mgr.StartSources()
+	t.Cleanup(mgr.Stop)
+	return mgr, emitter
+}
+
+func TestOPCUASourceFeedsCacheAndFollowsChanges(t *testing.T) {
+	srv := opcuatest.New()
+	srv.AddVariable("Press.Count", int32(10), false)
+	srv.AddVariable("Press.Die.Style", uint16(3), true)
+	mgr, emitter := startOPCUASource(t, srv)
+
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return mgr.IsConnected("press1") })
+	if v, err := mgr.ReadTag("press1", "Press.Count"); err != nil || v != int32(10) {
+		t.Fatalf("cached Count = %v, %v; want 10", v, err)
+	}
+	h := mgr.GetPLCHealth("press1")
+	if h == nil || !h.Online || h.Driver != "opcua" {
+		t.Errorf("health = %+v, want online opcua", h)
+	}
+	emitter.waitFor(t, "plc_connected:press1", time.Second)
+
+	srv.Set("Press.Count", int32(11))
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool {
+		v, _ := mgr.ReadTag("press1", "Press.Count")
+		return v == int32(11)
+	})
+
+	tags, err := mgr.DiscoverTags("press1")
+	if err != nil || len(tags) != 2 || tags[0].Name != "Press.Count" || tags[1].Name != "Press.Die.Style" {
+		t.Errorf("DiscoverTags = %+v, %v", tags, err)
+	}
+	all, err := mgr.FetchAllTags(context.Background(), "press1")
+	if err != nil || len(all) != 2 || all[0].Writable || !all[1].Writable || all[0].Type != "Int32" {
+		t.Errorf("FetchAllTags = %+v, %v", all, err)
+	}
+	if v, err := mgr.ReadTagValue(context.Background(), "press1", "Press.Count"); err != nil || v != int32(11) {
+		t.Errorf("live read = %v, %v; want 11", v, err)
+	}
+}
+
+func TestOPCUASourceWriteCoercesToTagType(t *testing.T) {
+	srv := opcuatest.New()
+	srv.AddVariable("Press.Style", uint16(3), true)
+	srv.AddVariable("Press.Count", int32(0), false)
+	mgr, _ := startOPCUASource(t, srv)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return mgr.IsConnected("press1") })
+	ctx := context.Background()
+
+	// A JSON body decodes numbers to float64; the tag is a UInt16.
+	if err := mgr.WriteTagValue(ctx, "press1", "Press.Style", float64(7)); err != nil {
+		t.Fatalf("write float64 7: %v", err)
+	}
+	if got := srv.Value("Press.Style"); got != uint16(7) {
+		t.Errorf("server Style = %v (%T), want uint16 7", got, got)
+	}
+	if err := mgr.WriteTagValue(ctx, "press1", "Press.Style", json.Number("9")); err != nil {
+		t.Fatalf("write json.Number: %v", err)
+	}
+	for _, bad := range []any{70000, -1, 2.5, "7"} {
+		if err := mgr.WriteTagValue(ctx, "press1", "Press.Style", bad); err == nil {
+			t.Errorf("write %T %v to a UInt16 succeeded", bad, bad)
+		}
+	}
+	if err := mgr.WriteTagValue(ctx, "press1", "Press.Count", 1); err == nil {
+		t.Error("write to a read-only tag succeeded")
+	}
+	if err := mgr.WriteTagValue(ctx, "press1", "Press.Missing", 1); err == nil {
+		t.Error("write to an unknown tag succeeded")
+	}
+	if got := srv.Value("Press.Style"); got != uint16(9) {
+		t.Errorf("server Style = %v after rejected writes, want 9", got)
+	}
+}
+
+func TestOPCUASourceReportsDropAndReconnects(t *testing.T) {
+	srv := opcuatest.New()
+	srv.AddVariable("Press.Count", int32(1), false)
+	mgr, emitter := startOPCUASource(t, srv)
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return mgr.IsConnected("press1") })
+
+	srv.DropConnections()
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return !mgr.IsConnected("press1") })
+	if h := mgr.GetPLCHealth("press1"); h == nil || h.Online || h.Error == "" {
+		t.Errorf("health after drop = %+v, want offline with an error", h)
+	}
+	if _, err := mgr.ReadTag("press1", "Press.Count"); err == nil {
+		t.Error("cached read on a disconnected source succeeded")
+	}
+	emitter.waitFor(t, "plc_disconnected:press1", time.Second)
+	emitter.waitFor(t, "plc_health_alert:press1", time.Second)
+
+	testutil.EventuallyWithInterval(t, 10*time.Millisecond, 5*time.Second, func() bool { return mgr.IsConnected("press1") })
+	emitter.waitFor(t, "plc_health_recover:press1", time.Second)
+}
+
+func TestCoerceVariant(t *testing.T) {
+	cases := []struct {
+		in   any
+		typ  opcua.TypeID
+		want any
+	}{
+		{int(1), opcua.TypeBoolean, true},
+		{false, opcua.TypeBoolean, false},
+		{float64(-3), opcua.TypeSByte, int8(-3)},
+		{int64(255), opcua.TypeByte, uint8(255)},
+		{uint64(1 << 63), opcua.TypeUInt64, uint64(1 << 63)},
+		{int(5), opcua.TypeFloat, float32(5)},
+		{json.Number("2.5"), opcua.TypeDouble, 2.5},
+		{"STYLE-42", opcua.TypeString, "STYLE-42"},
+	}
+	for _, c := range cases {
+		v, err := coerceVariant(c.in, c.typ)
+		if err != nil || v.Value != c.want || v.Type != c.typ {
+			t.Errorf("coerce %T %v to %s = %+v, %v; want %v", c.in, c.in, c.typ, v, err, c.want)
+		}
+	}
+	for _, c := range []struct {
+		in  any
+		typ opcua.TypeID
+	}{
+		{2, opcua.TypeBoolean},
+		{256, opcua.TypeByte},
+		{1e300, opcua.TypeFloat},
+		{true, opcua.TypeInt32},
+		{1, opcua.TypeString},
+	} {
+		if _, err := coerceVariant(c.in, c.typ); err == nil {
+			t.Errorf("coerce %T %v to %s: no error", c.in, c.in, c.typ)
+		}
+	}
+}
@@ -6,6 +6,9 @@
 // cache → pollReportingPoint → CalculateDelta → enqueueProductionTick. There is
 // no SSE implementation (S3) — dev config uses poll mode and OpenEventStream
 // returns a reader that never delivers.
+//
+// Beside the counters it serves sim.tags: simulated pushbuttons and state bits
+// that writes stick to, so plc_triggers can be exercised without a PLC.
 package simwarlink
 
 import (
@@ -33,11 +36,14 @@ type ReadinessFunc func(plcName string) bool
 // process list), each with counter tags that climb on a clock ticker — standing
 // in for presses/lines whose PLCs WarLink would normally poll.
 type FakeClient struct {
-	mu    sync.RWMutex
-	plcs  []string                    // distinct PLC names, sorted (stable output)
-	vals  map[string]map[string]int64 // plcName → tagName → counter
-	clk   clock.Clock
-	ready ReadinessFunc // nil = always ready (backward-compatible)
+	mu   sync.RWMutex
+	plcs []string                    // distinct PLC names, sorted (stable output)
+	vals map[string]map[string]int64 // plcName → tagName → counter
+	// simTags are the sim.tags entries, plcName → tagName. Only these take
+	// writes; a write to a counter is discarded as before.
+	simTags map[string]map[string]bool
+	clk     clock.Clock
+	ready   ReadinessFunc // nil = always ready (backward-compatible)
 }
 
 // NewFakeClient builds the fake from the sim process list and starts one ticker
@@ -54,16 +60,18 @@ func (f *FakeClient) SetReadinessFunc(fn ReadinessFunc) {
 }
 
 func NewFakeClient(ctx context.Context, cfg config.SimConfig, clk clock.Clock) *FakeClient {
-	f := &FakeClient{vals: make(map[string]map[string]int64), clk: clk}
-	seen := make(map[string]bool)
+	f := &FakeClient{vals: make(map[string]map[string]int64), simTags: make(map[string]map[string]bool), clk: clk}
 	for _, p := range cfg.Processes {
-		if f.vals[p.PLCName] == nil {
-			f.vals[p.PLCName] = make(map[string]int64)
+		f.addTag(p.PLCName, p.TagName, 0)
+	}
+	for _, st := range cfg.Tags {
+		f.addTag(st.PLCName, st.TagName, st.Value)
+		if f.simTags[st.PLCName] == nil {
+			f.simTags[st.PLCName] = make(map[string]bool)
 		}
-		f.vals[p.PLCName][p.TagName] = 0
-		if !seen[p.PLCName] {
-			seen[p.PLCName] = true
-			f.plcs = append(f.plcs, p.PLCName)
+		f.simTags[st.PLCName][st.TagName] = true
+		if st.Every > 0 {
+			go f.pulse(ctx, st, f.clk.NewTicker(st.Every))
 		}
 	}
 	sort.Strings(f.plcs)
@@ -82,6 +90,51 @@ func NewFakeClient(ctx context.Context, cfg config.SimConfig, clk clock.Clock) *
 	return f
 }
 
+// addTag adds a tag, and its PLC the first time the PLC is named.
+func (f *FakeClient) addTag(plcName, tagName string, v int64) {
+	if f.vals[plcName] == nil {
+		f.vals[plcName] = make(map[string]int64)
+		f.plcs = append(f.plcs, plcName)
+	}
+	f.vals[plcName][tagName] = v
+}
+
+// pulse presses a simulated button: 1 for Hold, then 0, every Every.
+func (f *FakeClient) pulse(ctx context.Context, st config.SimTagConfig, t clock.Ticker) {
+	hold := st.Hold
+	if hold <= 0 {
+		hold = time.Second
+	}
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+		}
+		release := f.clk.After(hold) // armed before the press is visible
+		f.SetTag(st.PLCName, st.TagName, 1)
+		select {
+		case <-ctx.Done():
+			return
+		case <-release:
+		}
+		f.SetTag(st.PLCName, st.TagName, 0)
+	}
+}
+
+// SetTag sets a simulated tag. It reports false for a tag that is not under
+// sim.tags.
+func (f *FakeClient) SetTag(plcName, tagName string, v int64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.simTags[plcName][tagName] {
+		return false
+	}
+	f.vals[plcName][tagName] = v
+	return true
+}
+
 func (f *FakeClient) tick(ctx context.Context, p config.SimProcessConfig, t clock.Ticker) {
 	per := p.UOPPerTick
 	if per <= 0 {
@@ -190,9 +243,33 @@ func (f *FakeClient) ReadTagValue(ctx context.Context, plcName, tagName string)
 	return v, nil
 }
 
-// WriteTagValue discards the write (Q4: zone lights / heartbeats have no sim
-// effect) and reports success.
+// WriteTagValue stores a write to a simulated tag, so a test or the sim
+// control strip can press a button through the normal write path. Any other
+// write is discarded (Q4: zone lights / heartbeats have no sim effect) and
+// reports success.
 func (f *FakeClient) WriteTagValue(ctx context.Context, plcName, tagName string, value any) error {
+	f.mu.RLock()
+	sim := f.simTags[plcName][tagName]
+	f.mu.RUnlock()
+	if !sim {
+		return nil
+	}
+	var v int64
+	switch n := value.(type) {
+	case bool:
+		if n {
+			v = 1
+		}
+	case int:
+		v = int64(n)
+	case int64:
+		v = n
+	case float64:
+		v = int64(n)
+	default:
+		return fmt.Errorf("simwarlink: cannot write %T to %s.%s", value, plcName, tagName)
+	}
+	f.SetTag(plcName, tagName, v)
 	return nil
 }
 
@@ -134,3 +134,35 @@ func keysOf(m map[string]plc.WarlinkTag) []string {
 	}
 	return ks
 }
+
+// TestFakeSimTags covers sim.tags: the tag is listed with its start value,
+// writes stick, and a pulsing tag goes high for Hold every Every.
+func TestFakeSimTags(t *testing.T) {
+	m := clock.NewManual(fakeStart)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := twoProcessCfg()
+	cfg.Tags = []config.SimTagConfig{
+		{PLCName: "PRESS-1", TagName: "NeedMaterial"},
+		{PLCName: "CELL-9", TagName: "BinEmpty", Value: 1},
+		{PLCName: "PRESS-1", TagName: "CallButton", Every: time.Minute, Hold: 2 * time.Second},
+	}
+	f := NewFakeClient(ctx, cfg, m)
+
+	waitForCounter(t, f, "CELL-9", "BinEmpty", 1)
+	if err := f.WriteTagValue(ctx, "PRESS-1", "NeedMaterial", true); err != nil {
+		t.Fatal(err)
+	}
+	waitForCounter(t, f, "PRESS-1", "NeedMaterial", 1)
+	if err := f.WriteTagValue(ctx, "PRESS-1", "NeedMaterial", "yes"); err == nil {
+		t.Fatal("string write to a sim tag should error")
+	}
+	if f.SetTag("PRESS-1", "PRESS-1_COUNTER", 5) {
+		t.Fatal("SetTag must refuse a counter")
+	}
+
+	m.Advance(time.Minute)
+	waitForCounter(t, f, "PRESS-1", "CallButton", 1)
+	m.Advance(2 * time.Second)
+	waitForCounter(t, f, "PRESS-1", "CallButton", 0)
+}
@@ -0,0 +1,207 @@
+package plc
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"shingoedge/config"
+)
+
+// source is a PLC the manager talks to itself rather than through WarLink,
+// one per plc_sources entry. It owns its ManagedPLC: WarLink's list, stream
+// and eviction never touch a sourced name.
+//
+// run keeps the PLC connected until the manager stops, feeding values through
+// applyValueChange and connection state through applySourceStatus — the same
+// cache and the same events a WarLink PLC produces, so nothing downstream can
+// tell the two apart.
+type source interface {
+	run()
+	readTag(ctx context.Context, tag string) (any, error)
+	writeTag(ctx context.Context, tag string, value any) error
+	listTags(ctx context.Context) ([]WarlinkTagInfo, error)
+}
+
+// StartSources connects every PLC under plc_sources. Each is listed at once as
+// Disconnected and stays in the list until Stop, connected or not.
+func (m *Manager) StartSources() {
+	m.cfg.RLock()
+	cfgs := append([]config.PLCSourceConfig(nil), m.cfg.PLCSources...)
+	m.cfg.RUnlock()
+
+	for _, sc := range cfgs {
+		var src source
+		switch sc.Driver {
+		case config.PLCDriverOPCUA:
+			src = newOPCUASource(m, sc)
+		case config.PLCDriverModbus:
+			src = newModbusSource(m, sc)
+		default:
+			log.Printf("plc source %q: unknown driver %q; skipped", sc.Name, sc.Driver)
+			continue
+		}
+		if sc.Name == "" {
+			log.Printf("plc source with endpoint %q has no name; skipped", sc.Endpoint)
+			continue
+		}
+		m.mu.Lock()
+		if _, dup := m.sources[sc.Name]; dup {
+			m.mu.Unlock()
+			log.Printf("plc source %q: listed twice; the second is skipped", sc.Name)
+			continue
+		}
+		m.sources[sc.Name] = src
+		// Replace, not reuse: an entry WarLink created for this name before
+		// the source existed must not keep WarLink's values or status.
+		m.plcs[sc.Name] = newManagedPLC(sc.Name)
+		m.mu.Unlock()
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			src.run()
+		}()
+	}
+}
+
+// sourceFor returns the source owning a PLC name, or nil for a WarLink PLC.
+func (m *Manager) sourceFor(name string) source {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sources[name]
+}
+
+// counterWrapper is a source that knows its counters' widths, so a rollover
+// is measured exactly instead of guessed (CalculateDeltaWrap).
+type counterWrapper interface {
+	counterWrap(tag string) int64
+}
+
+// counterWrap is where a tag's counter wraps, or 0 when that is not known —
+// always, for WarLink.
+func (m *Manager) counterWrap(plcName, tag string) int64 {
+	if w, ok := m.sourceFor(plcName).(counterWrapper); ok {
+		return w.counterWrap(tag)
+	}
+	return 0
+}
+
+// sourceContext is a context a source's connection runs under, cancelled when
+// the manager stops.
+func (m *Manager) sourceContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-m.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// unlessStopping returns err, or nil if the manager is stopping: a
+// connection torn down by Stop did not fail.
+func (m *Manager) unlessStopping(err error) error {
+	select {
+	case <-m.stopChan:
+		return nil
+	default:
+		return err
+	}
+}
+
+// applyValueChange is the value-change path the WarLink stream and the native
+// sources share: one tag's new value into its PLC's cache.
+func (m *Manager) applyValueChange(mp *ManagedPLC, tv TagValue) {
+	mp.mu.Lock()
+	mp.Values[tv.Name] = tv
+	mp.mu.Unlock()
+}
+
+// applySourceStatus records a native source connecting or dropping, as both
+// the PLC's status and its health, and emits the transitions a WarLink
+// status-change and health event would.
+//
+// A source that has never connected reports its first failure silently, the
+// way a first WarLink health report does: there is no outage to announce for
+// a PLC that was never up.
+func (m *Manager) applySourceStatus(name, driver string, up bool, cause error) {
+	m.mu.RLock()
+	mp, ok := m.plcs[name]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+	status, errMsg := "Disconnected", ""
+	if up {
+		status = "Connected"
+	} else if cause != nil {
+		errMsg = cause.Error()
+	}
+
+	mp.mu.Lock()
+	oldStatus := mp.Status
+	hadPriorHealth := mp.Health != nil
+	wasOnline := hadPriorHealth && mp.Health.Online
+	mp.Status = status
+	mp.Error = errMsg
+	if !up {
+		mp.Values = map[string]TagValue{}
+	}
+	mp.Health = &PLCHealth{
+		Online:    up,
+		Driver:    driver,
+		Status:    status,
+		Error:     errMsg,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	mp.mu.Unlock()
+
+	switch {
+	case up && oldStatus != "Connected":
+		m.DebugLog.Log("plc connected: %s (%s)", name, driver)
+		m.emitter.EmitPLCConnected(name)
+		if hadPriorHealth && !wasOnline {
+			m.emitter.EmitPLCHealthRecover(name)
+		}
+	case !up && oldStatus == "Connected":
+		m.DebugLog.Log("plc disconnected: %s (%s) err=%v", name, driver, cause)
+		m.emitter.EmitPLCDisconnected(name, cause)
+		m.emitter.EmitPLCHealthAlert(name, errMsg)
+	}
+}
+
+// sourceBackoff waits out a reconnect delay for a native source. Returns false
+// if the manager stopped during the wait.
+func (m *Manager) sourceBackoff(name string, attempt int) bool {
+	delay := backoffDelay(attempt)
+	log.Printf("PLC %s reconnecting in %v (attempt %d)", name, delay.Round(time.Millisecond), attempt)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-m.stopChan:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoffDelay is 1s doubling to a 30s cap, ±20% jitter.
+func backoffDelay(attempt int) time.Duration {
+	// Cap the exponent to avoid int64 overflow (1<<63 wraps to 0).
+	exp := attempt - 1
+	if exp > 5 { // 2^5 = 32s, already above the 30s cap
+		exp = 5
+	}
+	if exp < 0 {
+		exp = 0
+	}
+	base := time.Duration(1<<uint(exp)) * time.Second
+	if base > 30*time.Second {
+		base = 30 * time.Second
+	}
+	return time.Duration(float64(base) * (0.8 + 0.4*rand.Float64()))
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -98,7 +97,10 @@ func (m *Manager) sseLoop() {
 		}
 		var disconnected []string
 		if wasConnected {
-			for _, mp := range m.plcs {
+			for name, mp := range m.plcs {
+				if _, sourced := m.sources[name]; sourced {
+					continue // not WarLink's to mark down
+				}
 				mp.mu.Lock()
 				if mp.Status == "Connected" {
 					mp.Status = "Disconnected"
@@ -293,29 +295,39 @@ func (m *Manager) handleSSEValueChange(data string) {
 		return
 	}
 
-	// Single WLock check-and-insert. Pre-fix had RLock→Unlock→WLock
-	// which raced on concurrent first-event-for-new-PLC: two
-	// goroutines could both observe 'not in map' under RLock, both
-	// grab WLock, both insert — the loser's ManagedPLC was orphaned
-	// and subsequent writes to mp.Values landed on the orphan.
-	// Pattern mirrors handleSSEStatusChange:226-234 which already
-	// uses single-WLock.
-	m.mu.Lock()
-	mp, ok := m.plcs[change.PLC]
-	if !ok {
-		// SSE may report PLCs discovered after bootstrap
-		mp = newManagedPLC(change.PLC)
-		m.plcs[change.PLC] = mp
+	mp := m.warlinkPLC(change.PLC)
+	if mp == nil {
+		return
 	}
-	m.mu.Unlock()
-
-	mp.mu.Lock()
-	mp.Values[change.Tag] = TagValue{
+	m.applyValueChange(mp, TagValue{
 		Name:    change.Tag,
 		TypeStr: change.Type,
 		Value:   change.Value,
+	})
+}
+
+// warlinkPLC returns the entry for a PLC WarLink reported, creating it if
+// absent — SSE may report PLCs discovered after bootstrap. It returns nil for
+// a name a native source owns: WarLink must not write over a PLC the edge is
+// talking to itself.
+//
+// Single WLock check-and-insert. Pre-fix had RLock→Unlock→WLock which raced
+// on concurrent first-event-for-new-PLC: two goroutines could both observe
+// 'not in map' under RLock, both grab WLock, both insert — the loser's
+// ManagedPLC was orphaned and subsequent writes to mp.Values landed on the
+// orphan.
+func (m *Manager) warlinkPLC(name string) *ManagedPLC {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, sourced := m.sources[name]; sourced {
+		return nil
 	}
-	mp.mu.Unlock()
+	mp, ok := m.plcs[name]
+	if !ok {
+		mp = newManagedPLC(name)
+		m.plcs[name] = mp
+	}
+	return mp
 }
 
 func (m *Manager) handleSSEStatusChange(data string) {
@@ -341,13 +353,10 @@ func (m *Manager) handleSSEStatusChange(data string) {
 	// etc.) go under mp.mu.Lock() because readers reach them through
 	// mp.mu.RLock() (IsConnected, ReadTag, GetPLCHealth). Writing
 	// these under m.mu instead would race the readers.
-	m.mu.Lock()
-	mp, ok := m.plcs[status.PLC]
-	if !ok {
-		mp = newManagedPLC(status.PLC)
-		m.plcs[status.PLC] = mp
+	mp := m.warlinkPLC(status.PLC)
+	if mp == nil {
+		return
 	}
-	m.mu.Unlock()
 
 	mp.mu.Lock()
 	oldStatus := mp.Status
@@ -383,13 +392,10 @@ func (m *Manager) handleSSEHealth(data string) {
 	// Same cross-lock-domain pattern as handleSSEStatusChange above:
 	// m.mu only guards the map; mp.Health write goes under mp.mu
 	// because GetPLCHealth reads it under mp.mu.RLock().
-	m.mu.Lock()
-	mp, ok := m.plcs[health.PLC]
-	if !ok {
-		mp = newManagedPLC(health.PLC)
-		m.plcs[health.PLC] = mp
+	mp := m.warlinkPLC(health.PLC)
+	if mp == nil {
+		return
 	}
-	m.mu.Unlock()
 
 	mp.mu.Lock()
 	hadPriorHealth := mp.Health != nil
@@ -415,19 +421,7 @@ func (m *Manager) handleSSEHealth(data string) {
 // sseBackoff waits with capped exponential backoff + jitter.
 // Returns false if a stop signal was received during the wait.
 func (m *Manager) sseBackoff(attempt int) bool {
-	// Base delay: 1s * 2^(attempt-1), capped at 30s
-	// Cap the exponent to avoid int64 overflow (1<<63 wraps to 0).
-	exp := attempt - 1
-	if exp > 5 { // 2^5 = 32s, already above the 30s cap
-		exp = 5
-	}
-	base := time.Duration(1<<uint(exp)) * time.Second
-	if base > 30*time.Second {
-		base = 30 * time.Second
-	}
-
-	// Add ±20% jitter
-	jitter := time.Duration(float64(base) * (0.8 + 0.4*rand.Float64()))
+	jitter := backoffDelay(attempt)
 
 	log.Printf("WarLink SSE reconnecting in %v (attempt %d)", jitter.Round(time.Millisecond), attempt)
 
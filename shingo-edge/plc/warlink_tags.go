@@ -8,6 +8,9 @@ import "context"
 // caller — the CATID monitor alone reaches EnableTagPublishing on a
 // 500ms tick (engine/plc_catid_monitor.go:154). ManagedPLC's own mp.mu
 // is not involved here — the swap is on the Manager-level client pointer.
+//
+// A PLC under plc_sources is answered by its source instead, before the
+// read-lock: a source's network call must not hold up ReplaceClient.
 
 // ReadTagValue returns the current value of a single PLC tag via a live
 // WarLink read. Delegates to the underlying WarlinkClient; kept as a
@@ -17,6 +20,9 @@ import "context"
 // Named ReadTagValue (not ReadTag) to avoid collision with the cache-based
 // ReadTag(plcName, tagName) on this same type.
 func (m *Manager) ReadTagValue(ctx context.Context, plcName, tagName string) (any, error) {
+	if src := m.sourceFor(plcName); src != nil {
+		return src.readTag(ctx, tagName)
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.wl == nil {
@@ -30,6 +36,9 @@ func (m *Manager) ReadTagValue(ctx context.Context, plcName, tagName string) (an
 // PLC must be connected (HTTP 503 otherwise). Integer values auto-convert
 // to the tag's data type.
 func (m *Manager) WriteTagValue(ctx context.Context, plcName, tagName string, value any) error {
+	if src := m.sourceFor(plcName); src != nil {
+		return src.writeTag(ctx, tagName, value)
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.wl == nil {
@@ -38,8 +47,12 @@ func (m *Manager) WriteTagValue(ctx context.Context, plcName, tagName string, va
 	return m.wl.WriteTagValue(ctx, plcName, tagName, value)
 }
 
-// EnableTagPublishing tells WarLink to start publishing a tag.
+// EnableTagPublishing tells WarLink to start publishing a tag. A native
+// source publishes every tag already, so for one this is a no-op.
 func (m *Manager) EnableTagPublishing(ctx context.Context, plcName, tagName string) error {
+	if m.sourceFor(plcName) != nil {
+		return nil
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.wl == nil {
@@ -48,8 +61,12 @@ func (m *Manager) EnableTagPublishing(ctx context.Context, plcName, tagName stri
 	return m.wl.SetTagPublishing(ctx, plcName, tagName, true)
 }
 
-// DisableTagPublishing tells WarLink to stop publishing a tag.
+// DisableTagPublishing tells WarLink to stop publishing a tag. A no-op for a
+// native source.
 func (m *Manager) DisableTagPublishing(ctx context.Context, plcName, tagName string) error {
+	if m.sourceFor(plcName) != nil {
+		return nil
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.wl == nil {
@@ -60,6 +77,9 @@ func (m *Manager) DisableTagPublishing(ctx context.Context, plcName, tagName str
 
 // FetchAllTags retrieves ALL tags (published and unpublished) from WarLink.
 func (m *Manager) FetchAllTags(ctx context.Context, plcName string) ([]WarlinkTagInfo, error) {
+	if src := m.sourceFor(plcName); src != nil {
+		return src.listTags(ctx)
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.wl == nil {
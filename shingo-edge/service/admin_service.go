@@ -43,3 +43,9 @@ func (s *AdminService) Create(username, passwordHash string) (int64, error) {
 func (s *AdminService) UpdatePassword(username, passwordHash string) error {
 	return s.db.UpdateAdminPassword(username, passwordHash)
 }
+
+// UpdateLanguage sets an admin's page language. Empty clears it back to the
+// plant default.
+func (s *AdminService) UpdateLanguage(username, language string) error {
+	return s.db.UpdateAdminLanguage(username, language)
+}
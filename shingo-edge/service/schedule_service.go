@@ -0,0 +1,299 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"shingoedge/store"
+)
+
+// ScheduleService owns the production schedule: the ordered style runs each
+// process is planned to make, how they get in (CSV upload, MES push, the
+// admin page) and how the line did against them.
+//
+// Starting changeovers off the schedule is NOT here — that dispatches robots
+// and belongs to the engine's planner. This service only reads and writes the
+// plan and its actuals.
+type ScheduleService struct {
+	db *store.DB
+}
+
+// NewScheduleService constructs a ScheduleService wrapping the shared
+// *store.DB.
+func NewScheduleService(db *store.DB) *ScheduleService {
+	return &ScheduleService{db: db}
+}
+
+// Schedule import sources, recorded on each run.
+const (
+	ScheduleSourceManual = "manual"
+	ScheduleSourceCSV    = "csv"
+	ScheduleSourceMES    = "mes"
+)
+
+// ScheduleOnTimeTolerance is how far a run may start from its planned start
+// and still count as on time.
+const ScheduleOnTimeTolerance = 15 * time.Minute
+
+// List returns a process's schedule in run order.
+func (s *ScheduleService) List(processID int64) ([]store.ScheduleRun, error) {
+	return s.db.ListScheduleRuns(processID)
+}
+
+// Replace swaps a process's planned runs for runs. Active and finished runs
+// are kept.
+func (s *ScheduleService) Replace(processID int64, source string, runs []store.ScheduleRunInput) ([]int64, error) {
+	for i, r := range runs {
+		if err := s.validateRun(processID, r); err != nil {
+			return nil, fmt.Errorf("run %d: %w", i+1, err)
+		}
+	}
+	return s.db.ReplacePlannedScheduleRuns(processID, source, runs)
+}
+
+// ErrScheduleRunNotPlanned is returned by Delete for a run that has started,
+// finished or does not exist: only the plan can be edited, not the record.
+var ErrScheduleRunNotPlanned = errors.New("only a planned run can be deleted")
+
+// Delete removes a planned run.
+func (s *ScheduleService) Delete(runID int64) error {
+	err := s.db.DeleteScheduleRun(runID)
+	if errors.Is(err, store.ErrNoScheduleRun) {
+		return ErrScheduleRunNotPlanned
+	}
+	return err
+}
+
+func (s *ScheduleService) validateRun(processID int64, r store.ScheduleRunInput) error {
+	if r.PlannedQty < 0 {
+		return errors.New("planned quantity cannot be negative")
+	}
+	if r.PlannedQty == 0 && r.PlannedEnd == nil {
+		return errors.New("needs a planned quantity or a planned end")
+	}
+	if r.PlannedStart != nil && r.PlannedEnd != nil && !r.PlannedEnd.After(*r.PlannedStart) {
+		return errors.New("planned end must be after planned start")
+	}
+	style, err := s.db.GetStyle(r.StyleID)
+	if err != nil {
+		return fmt.Errorf("style %d: %w", r.StyleID, err)
+	}
+	if style.ProcessID != processID {
+		return fmt.Errorf("style %q does not belong to this process", style.Name)
+	}
+	return nil
+}
+
+// ScheduleCSVColumns are the headers ImportCSV reads. process and style are
+// names as they appear on the admin pages; the rest may be left blank.
+var ScheduleCSVColumns = []string{"process", "style", "planned_qty", "planned_start", "planned_end", "external_ref"}
+
+// ImportCSV reads a schedule file and replaces the planned runs of every
+// process it names, in file order. Times are RFC 3339 or "2006-01-02 15:04"
+// in loc. The file is applied all-or-nothing per the parse: any bad line
+// rejects the whole file, naming the line, before anything is written.
+// Returns the number of runs imported per process name.
+func (s *ScheduleService) ImportCSV(r io.Reader, loc *time.Location) (map[string]int, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("empty file")
+	}
+	col := make(map[string]int, len(rows[0]))
+	for i, h := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, need := range []string{"process", "style"} {
+		if _, ok := col[need]; !ok {
+			return nil, fmt.Errorf("missing %q column (want %s)", need, strings.Join(ScheduleCSVColumns, ","))
+		}
+	}
+	procs, err := s.db.ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+	procIDs := make(map[string]int64, len(procs))
+	for _, p := range procs {
+		procIDs[p.Name] = p.ID
+	}
+
+	var order []string
+	byProc := make(map[string][]store.ScheduleRunInput)
+	for n, row := range rows[1:] {
+		line := n + 2
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		procName := field("process")
+		procID, ok := procIDs[procName]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown process %q", line, procName)
+		}
+		in, err := s.parseCSVRun(procID, field, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if _, seen := byProc[procName]; !seen {
+			order = append(order, procName)
+		}
+		byProc[procName] = append(byProc[procName], in)
+	}
+
+	out := make(map[string]int, len(order))
+	for _, name := range order {
+		if _, err := s.db.ReplacePlannedScheduleRuns(procIDs[name], ScheduleSourceCSV, byProc[name]); err != nil {
+			return out, err
+		}
+		out[name] = len(byProc[name])
+	}
+	return out, nil
+}
+
+func (s *ScheduleService) parseCSVRun(processID int64, field func(string) string, loc *time.Location) (store.ScheduleRunInput, error) {
+	var in store.ScheduleRunInput
+	styles, err := s.db.ListStylesByProcess(processID)
+	if err != nil {
+		return in, err
+	}
+	name := field("style")
+	for _, st := range styles {
+		if st.Name == name {
+			in.StyleID = st.ID
+		}
+	}
+	if in.StyleID == 0 {
+		return in, fmt.Errorf("unknown style %q for this process", name)
+	}
+	if q := field("planned_qty"); q != "" {
+		if in.PlannedQty, err = strconv.ParseInt(q, 10, 64); err != nil {
+			return in, fmt.Errorf("planned_qty %q is not a whole number", q)
+		}
+	}
+	if in.PlannedStart, err = ParseScheduleTime(field("planned_start"), loc); err != nil {
+		return in, err
+	}
+	if in.PlannedEnd, err = ParseScheduleTime(field("planned_end"), loc); err != nil {
+		return in, err
+	}
+	in.ExternalRef = field("external_ref")
+	return in, s.validateRun(processID, in)
+}
+
+// ParseScheduleTime reads a planned time from an import: RFC 3339, or a
+// plant-local "2006-01-02 15:04" (seconds optional) in loc. Empty is nil.
+func ParseScheduleTime(v string, loc *time.Location) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("time %q is neither RFC 3339 nor YYYY-MM-DD HH:MM", v)
+}
+
+// ScheduleRunAdherence is one run with how it went against plan.
+type ScheduleRunAdherence struct {
+	store.ScheduleRun
+	// Attainment is produced over planned quantity, 0..1+; nil for a run
+	// planned by time alone.
+	Attainment *float64 `json:"attainment,omitempty"`
+	// StartDelayMinutes is actual start minus planned start; nil until the
+	// run starts, or when it had no planned start.
+	StartDelayMinutes *float64 `json:"start_delay_minutes,omitempty"`
+	OnTime            bool     `json:"on_time"`
+}
+
+// ProcessAdherence rolls one process's runs in the window up.
+type ProcessAdherence struct {
+	ProcessID   int64                  `json:"process_id"`
+	ProcessName string                 `json:"process_name"`
+	Planned     int                    `json:"planned"`
+	Completed   int                    `json:"completed"`
+	Skipped     int                    `json:"skipped"`
+	OnTime      int                    `json:"on_time"`
+	PlannedQty  int64                  `json:"planned_qty"`
+	ProducedQty int64                  `json:"produced_qty"`
+	Attainment  *float64               `json:"attainment,omitempty"`
+	Runs        []ScheduleRunAdherence `json:"runs"`
+}
+
+// Adherence reports every process's runs in [from, to) against plan.
+//
+// Attainment is summed over FINISHED runs only: a run still going has not
+// missed anything yet, and counting it would show every line behind plan for
+// the whole of its current run.
+func (s *ScheduleService) Adherence(from, to time.Time) ([]ProcessAdherence, error) {
+	runs, err := s.db.ListScheduleRunsBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	procs, err := s.db.ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(procs))
+	for _, p := range procs {
+		names[p.ID] = p.Name
+	}
+
+	var out []ProcessAdherence
+	for _, r := range runs {
+		if len(out) == 0 || out[len(out)-1].ProcessID != r.ProcessID {
+			out = append(out, ProcessAdherence{ProcessID: r.ProcessID, ProcessName: names[r.ProcessID]})
+		}
+		pa := &out[len(out)-1]
+		ra := runAdherence(r)
+		pa.Planned++
+		switch r.Status {
+		case store.ScheduleRunDone:
+			pa.Completed++
+			if r.PlannedQty > 0 {
+				pa.PlannedQty += r.PlannedQty
+				pa.ProducedQty += r.ProducedQty
+			}
+		case store.ScheduleRunSkipped:
+			pa.Skipped++
+		}
+		if ra.OnTime {
+			pa.OnTime++
+		}
+		pa.Runs = append(pa.Runs, ra)
+	}
+	for i := range out {
+		if out[i].PlannedQty > 0 {
+			a := float64(out[i].ProducedQty) / float64(out[i].PlannedQty)
+			out[i].Attainment = &a
+		}
+	}
+	return out, nil
+}
+
+func runAdherence(r store.ScheduleRun) ScheduleRunAdherence {
+	ra := ScheduleRunAdherence{ScheduleRun: r}
+	if r.PlannedQty > 0 {
+		a := float64(r.ProducedQty) / float64(r.PlannedQty)
+		ra.Attainment = &a
+	}
+	if r.PlannedStart != nil && r.ActualStart != nil {
+		d := r.ActualStart.Sub(*r.PlannedStart)
+		m := d.Minutes()
+		ra.StartDelayMinutes = &m
+		ra.OnTime = d <= ScheduleOnTimeTolerance && d >= -ScheduleOnTimeTolerance
+	}
+	return ra
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"shingoedge/internal/testdb"
+	"shingoedge/store"
+)
+
+func TestScheduleImportCSV_ReplacesPlannedRuns(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	pid, a := seedProcessStyle(t, db, "PRESS-4", "A")
+	if _, err := db.CreateStyle("B", "", pid); err != nil {
+		t.Fatalf("create style: %v", err)
+	}
+	svc := NewScheduleService(db)
+
+	csv := "process,style,planned_qty,planned_start,external_ref\n" +
+		"PRESS-4,A,100,2026-10-18 06:00,WO-1\n" +
+		"PRESS-4,B,50,2026-10-18T10:00:00Z,WO-2\n"
+	got, err := svc.ImportCSV(strings.NewReader(csv), time.UTC)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if got["PRESS-4"] != 2 {
+		t.Fatalf("imported = %v, want 2 runs for PRESS-4", got)
+	}
+	runs, err := svc.List(pid)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(runs) != 2 || runs[0].StyleID != a || runs[0].ExternalRef != "WO-1" || runs[0].Source != ScheduleSourceCSV {
+		t.Fatalf("runs = %+v, want A then B from the csv", runs)
+	}
+	want := time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)
+	if runs[0].PlannedStart == nil || !runs[0].PlannedStart.Equal(want) {
+		t.Errorf("planned start = %v, want %v", runs[0].PlannedStart, want)
+	}
+}
+
+// One bad line rejects the whole file before anything is written, and the
+// error names the line so the planner can fix the sheet.
+func TestScheduleImportCSV_BadLineRejectsFile(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	pid, _ := seedProcessStyle(t, db, "PRESS-4", "A")
+	svc := NewScheduleService(db)
+
+	csv := "process,style,planned_qty\nPRESS-4,A,100\nPRESS-4,NOPE,10\n"
+	_, err := svc.ImportCSV(strings.NewReader(csv), time.UTC)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("err = %v, want a line 3 error", err)
+	}
+	runs, _ := svc.List(pid)
+	if len(runs) != 0 {
+		t.Errorf("got %d runs written, want none", len(runs))
+	}
+}
+
+func TestScheduleAdherence_FinishedRunsOnly(t *testing.T) {
+	t.Parallel()
+	db := testdb.Open(t)
+	pid, a := seedProcessStyle(t, db, "PRESS-4", "A")
+	svc := NewScheduleService(db)
+
+	start := time.Now().UTC().Add(-time.Minute)
+	ids, err := svc.Replace(pid, ScheduleSourceManual, []store.ScheduleRunInput{
+		{StyleID: a, PlannedQty: 100, PlannedStart: &start},
+		{StyleID: a, PlannedQty: 100, PlannedStart: &start},
+	})
+	if err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := db.ActivateScheduleRun(ids[0]); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if _, err := db.AddScheduleRunProduced(pid, a, 80); err != nil {
+		t.Fatalf("produced: %v", err)
+	}
+	if err := db.FinishScheduleRun(ids[0], store.ScheduleRunDone); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if err := db.ActivateScheduleRun(ids[1]); err != nil {
+		t.Fatalf("activate second: %v", err)
+	}
+
+	out, err := svc.Adherence(start.Add(-time.Hour), start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("adherence: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("got %d processes, want 1", len(out))
+	}
+	pa := out[0]
+	if pa.Planned != 2 || pa.Completed != 1 || pa.OnTime != 2 {
+		t.Errorf("counts = %+v, want 2 planned, 1 completed, 2 on time", pa)
+	}
+	if pa.Attainment == nil || *pa.Attainment != 0.8 {
+		t.Errorf("attainment = %v, want 0.8 over the finished run only", pa.Attainment)
+	}
+}
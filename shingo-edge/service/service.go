@@ -21,6 +21,7 @@
 //	counter_service.go     — reporting points + counter snapshots + hourly counts
 //	order_service.go       — order queries (lifecycle stays on orders.Manager)
 //	process_service.go     — process + process_node + runtime CRUD
+//	schedule_service.go    — production schedule import + adherence
 //	shift_service.go       — production shift CRUD
 //	station_service.go     — operator station CRUD + cross-aggregate nodes/views
 //	style_service.go       — style + style_node_claim CRUD
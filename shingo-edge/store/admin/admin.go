@@ -18,6 +18,7 @@ type User struct {
 	ID           int64     `json:"id"`
 	Username     string    `json:"username"`
 	PasswordHash string    `json:"-"`
+	Language     string    `json:"language"` // pages' language; empty = plant default
 	CreatedAt    time.Time `json:"created_at"`
 }
 
@@ -25,8 +26,8 @@ type User struct {
 func Get(db *sql.DB, username string) (*User, error) {
 	u := &User{}
 	var createdAt string
-	err := db.QueryRow(`SELECT id, username, password_hash, created_at FROM admin_users WHERE username = ?`, username).
-		Scan(&u.ID, &u.Username, &u.PasswordHash, &createdAt)
+	err := db.QueryRow(`SELECT id, username, password_hash, language, created_at FROM admin_users WHERE username = ?`, username).
+		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Language, &createdAt)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +50,12 @@ func UpdatePassword(db *sql.DB, username, passwordHash string) error {
 	return err
 }
 
+// UpdateLanguage sets the language the given admin's pages render in.
+func UpdateLanguage(db *sql.DB, username, language string) error {
+	_, err := db.Exec(`UPDATE admin_users SET language = ? WHERE username = ?`, language, username)
+	return err
+}
+
 // AnyExists reports whether at least one admin_users row exists.
 func AnyExists(db *sql.DB) (bool, error) {
 	var count int
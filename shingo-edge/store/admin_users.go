@@ -21,6 +21,11 @@ func (db *DB) UpdateAdminPassword(username, passwordHash string) error {
 	return admin.UpdatePassword(db.DB, username, passwordHash)
 }
 
+// UpdateAdminLanguage sets the language the given admin's pages render in.
+func (db *DB) UpdateAdminLanguage(username, language string) error {
+	return admin.UpdateLanguage(db.DB, username, language)
+}
+
 // AdminUserExists reports whether at least one admin_users row exists.
 func (db *DB) AdminUserExists() (bool, error) {
 	return admin.AnyExists(db.DB)
@@ -719,6 +719,14 @@ func (db *DB) migrate() error {
 	// push. Empty = the fleet gave no reason, which is the common case.
 	db.Exec("ALTER TABLE orders ADD COLUMN fault_ref TEXT NOT NULL DEFAULT ''")
 
+	// v37 (2026-10-18, operator HMI languages): the language a station's
+	// screen and an admin's pages render in. Empty means "not chosen" and
+	// falls through to the plant default in config, which is why it is not
+	// defaulted to 'en' here: a plant that sets language: es should not find
+	// every existing station pinned to English by this migration.
+	db.Exec("ALTER TABLE operator_stations ADD COLUMN language TEXT NOT NULL DEFAULT ''")
+	db.Exec("ALTER TABLE admin_users ADD COLUMN language TEXT NOT NULL DEFAULT ''")
+
 	return nil
 }
 
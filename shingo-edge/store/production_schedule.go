@@ -0,0 +1,285 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shingoedge/domain"
+	"shingoedge/store/internal/helpers"
+)
+
+// production_schedule.go — the ordered list of style runs a process is planned
+// to make, and what actually happened to each.
+//
+// The plan arrives from outside (a CSV upload, or MES pushing the day's list);
+// the actuals are written here as the line runs it. A run is "active" from the
+// moment the process is running its style until the next changeover completes,
+// and produced_qty is the counter's good count against it over that window.
+//
+// Re-importing replaces the PLANNED rows only. MES routinely resends the whole
+// day, and a resend must not reset the run the press is in the middle of or
+// rewrite what the finished runs already did.
+
+// Schedule run statuses, re-exported from domain for store callers.
+const (
+	ScheduleRunPlanned = domain.ScheduleRunPlanned
+	ScheduleRunActive  = domain.ScheduleRunActive
+	ScheduleRunDone    = domain.ScheduleRunDone
+	ScheduleRunSkipped = domain.ScheduleRunSkipped
+)
+
+// ScheduleRun and ScheduleRunInput live in shingoedge/domain so www can
+// render and accept them without importing this package.
+type (
+	ScheduleRun      = domain.ScheduleRun
+	ScheduleRunInput = domain.ScheduleRunInput
+)
+
+// ErrNoScheduleRun is returned when a process has no run in the asked-for state.
+var ErrNoScheduleRun = errors.New("no schedule run")
+
+const scheduleRunCols = `r.id, r.process_id, r.style_id, COALESCE(s.name, ''), r.seq,
+	r.planned_qty, r.planned_start, r.planned_end, r.status, r.source, r.external_ref,
+	r.produced_qty, r.actual_start, r.actual_end, r.prestaged_at, r.armed_at,
+	r.changeover_id, r.created_at`
+
+const scheduleRunFrom = ` FROM production_schedule_runs r LEFT JOIN styles s ON s.id = r.style_id`
+
+func scanScheduleRun(sc interface{ Scan(...any) error }) (*ScheduleRun, error) {
+	var (
+		r                        ScheduleRun
+		plannedStart, plannedEnd sql.NullString
+		actualStart, actualEnd   sql.NullString
+		prestagedAt, armedAt     sql.NullString
+		changeoverID             sql.NullInt64
+		createdAt                string
+	)
+	if err := sc.Scan(&r.ID, &r.ProcessID, &r.StyleID, &r.StyleName, &r.Seq,
+		&r.PlannedQty, &plannedStart, &plannedEnd, &r.Status, &r.Source, &r.ExternalRef,
+		&r.ProducedQty, &actualStart, &actualEnd, &prestagedAt, &armedAt,
+		&changeoverID, &createdAt); err != nil {
+		return nil, err
+	}
+	r.PlannedStart = helpers.ScanTimePtr(plannedStart)
+	r.PlannedEnd = helpers.ScanTimePtr(plannedEnd)
+	r.ActualStart = helpers.ScanTimePtr(actualStart)
+	r.ActualEnd = helpers.ScanTimePtr(actualEnd)
+	r.PrestagedAt = helpers.ScanTimePtr(prestagedAt)
+	r.ArmedAt = helpers.ScanTimePtr(armedAt)
+	if changeoverID.Valid {
+		id := changeoverID.Int64
+		r.ChangeoverID = &id
+	}
+	r.CreatedAt = helpers.ScanTime(createdAt)
+	return &r, nil
+}
+
+// Every timestamp is written in the layout created_at's default uses, so the
+// adherence window can compare them as strings.
+func formatScheduleTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(helpers.TimeLayout)
+}
+
+func scheduleNow() string { return time.Now().UTC().Format(helpers.TimeLayout) }
+
+func (db *DB) queryScheduleRuns(where string, args ...any) ([]ScheduleRun, error) {
+	rows, err := db.Query(`SELECT `+scheduleRunCols+scheduleRunFrom+` WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ScheduleRun
+	for rows.Next() {
+		r, err := scanScheduleRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan schedule run: %w", err)
+		}
+		out = append(out, *r)
+	}
+	return out, rows.Err()
+}
+
+// ListScheduleRuns returns a process's whole schedule in run order, finished
+// runs included.
+func (db *DB) ListScheduleRuns(processID int64) ([]ScheduleRun, error) {
+	out, err := db.queryScheduleRuns(`r.process_id = ? ORDER BY r.seq, r.id`, processID)
+	if err != nil {
+		return nil, fmt.Errorf("list schedule runs for process %d: %w", processID, err)
+	}
+	return out, nil
+}
+
+// ListScheduleRunsBetween returns every process's runs that were planned or
+// ran inside [from, to) — the adherence view's window. A run with no times at
+// all (quantity-only, never started) has nothing to place it in a window and
+// is left out.
+func (db *DB) ListScheduleRunsBetween(from, to time.Time) ([]ScheduleRun, error) {
+	f, t := from.UTC().Format(helpers.TimeLayout), to.UTC().Format(helpers.TimeLayout)
+	out, err := db.queryScheduleRuns(
+		`COALESCE(r.actual_start, r.planned_start, r.planned_end) >= ?
+		   AND COALESCE(r.actual_start, r.planned_start, r.planned_end) < ?
+		 ORDER BY r.process_id, r.seq, r.id`, f, t)
+	if err != nil {
+		return nil, fmt.Errorf("list schedule runs %s..%s: %w", f, t, err)
+	}
+	return out, nil
+}
+
+// GetScheduleRun returns one run, or ErrNoScheduleRun.
+func (db *DB) GetScheduleRun(id int64) (*ScheduleRun, error) {
+	r, err := scanScheduleRun(db.QueryRow(`SELECT `+scheduleRunCols+scheduleRunFrom+` WHERE r.id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoScheduleRun
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get schedule run %d: %w", id, err)
+	}
+	return r, nil
+}
+
+// ActiveScheduleRun returns the run a process is making now, or
+// ErrNoScheduleRun.
+func (db *DB) ActiveScheduleRun(processID int64) (*ScheduleRun, error) {
+	return db.firstScheduleRun(processID, ScheduleRunActive)
+}
+
+// NextScheduleRun returns the earliest planned run for a process, or
+// ErrNoScheduleRun.
+func (db *DB) NextScheduleRun(processID int64) (*ScheduleRun, error) {
+	return db.firstScheduleRun(processID, ScheduleRunPlanned)
+}
+
+func (db *DB) firstScheduleRun(processID int64, status string) (*ScheduleRun, error) {
+	r, err := scanScheduleRun(db.QueryRow(`SELECT `+scheduleRunCols+scheduleRunFrom+`
+		WHERE r.process_id = ? AND r.status = ? ORDER BY r.seq, r.id LIMIT 1`, processID, status))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoScheduleRun
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s schedule run for process %d: %w", status, processID, err)
+	}
+	return r, nil
+}
+
+// ReplacePlannedScheduleRuns swaps a process's planned runs for runs, in the
+// order given, after any run already active or finished. Returns the new ids.
+func (db *DB) ReplacePlannedScheduleRuns(processID int64, source string, runs []ScheduleRunInput) ([]int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM production_schedule_runs WHERE process_id = ? AND status = ?`,
+		processID, ScheduleRunPlanned); err != nil {
+		return nil, fmt.Errorf("clear planned runs for process %d: %w", processID, err)
+	}
+	var seq int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM production_schedule_runs WHERE process_id = ?`,
+		processID).Scan(&seq); err != nil {
+		return nil, fmt.Errorf("schedule seq for process %d: %w", processID, err)
+	}
+	ids := make([]int64, 0, len(runs))
+	for _, in := range runs {
+		seq++
+		res, err := tx.Exec(`INSERT INTO production_schedule_runs
+			(process_id, style_id, seq, planned_qty, planned_start, planned_end, status, source, external_ref)
+			VALUES (?,?,?,?,?,?,?,?,?)`,
+			processID, in.StyleID, seq, in.PlannedQty, formatScheduleTime(in.PlannedStart),
+			formatScheduleTime(in.PlannedEnd), ScheduleRunPlanned, source, in.ExternalRef)
+		if err != nil {
+			return nil, fmt.Errorf("insert schedule run %d for process %d: %w", seq, processID, err)
+		}
+		id, _ := res.LastInsertId()
+		ids = append(ids, id)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ActivateScheduleRun marks a planned run as the one the process is making,
+// stamping its actual start. Guarded on status so a run is started once.
+func (db *DB) ActivateScheduleRun(id int64) error {
+	if _, err := db.Exec(`UPDATE production_schedule_runs SET status = ?, actual_start = ?
+		WHERE id = ? AND status = ?`, ScheduleRunActive, scheduleNow(), id, ScheduleRunPlanned); err != nil {
+		return fmt.Errorf("activate schedule run %d: %w", id, err)
+	}
+	return nil
+}
+
+// FinishScheduleRun closes an active run as done, or a planned one as skipped,
+// stamping its actual end.
+func (db *DB) FinishScheduleRun(id int64, status string) error {
+	if _, err := db.Exec(`UPDATE production_schedule_runs SET status = ?, actual_end = ?
+		WHERE id = ? AND status IN (?, ?)`, status, scheduleNow(), id, ScheduleRunPlanned, ScheduleRunActive); err != nil {
+		return fmt.Errorf("finish schedule run %d: %w", id, err)
+	}
+	return nil
+}
+
+// AddScheduleRunProduced adds delta to the active run for (process, style) and
+// returns the run as it now stands, or ErrNoScheduleRun when the process is not
+// running that style on the schedule.
+func (db *DB) AddScheduleRunProduced(processID, styleID, delta int64) (*ScheduleRun, error) {
+	res, err := db.Exec(`UPDATE production_schedule_runs SET produced_qty = produced_qty + ?
+		WHERE process_id = ? AND style_id = ? AND status = ?`, delta, processID, styleID, ScheduleRunActive)
+	if err != nil {
+		return nil, fmt.Errorf("add produced to schedule run for process %d: %w", processID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNoScheduleRun
+	}
+	return db.ActiveScheduleRun(processID)
+}
+
+// MarkScheduleRunPrestaged records that the planner started the changeover into
+// this run early to pre-stage its material.
+func (db *DB) MarkScheduleRunPrestaged(id, changeoverID int64) error {
+	if _, err := db.Exec(`UPDATE production_schedule_runs SET prestaged_at = ?, changeover_id = ?
+		WHERE id = ?`, scheduleNow(), changeoverID, id); err != nil {
+		return fmt.Errorf("mark schedule run %d prestaged: %w", id, err)
+	}
+	return nil
+}
+
+// MarkScheduleRunArmed records that this run reached its quantity and the
+// changeover out of it was armed. Returns false when it was already armed, so
+// the counter path arms exactly once per run however many ticks land after.
+func (db *DB) MarkScheduleRunArmed(id int64) (bool, error) {
+	res, err := db.Exec(`UPDATE production_schedule_runs SET armed_at = ?
+		WHERE id = ? AND armed_at IS NULL`, scheduleNow(), id)
+	if err != nil {
+		return false, fmt.Errorf("arm schedule run %d: %w", id, err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// LinkScheduleRunChangeover records the changeover that brought a run on.
+func (db *DB) LinkScheduleRunChangeover(id, changeoverID int64) error {
+	if _, err := db.Exec(`UPDATE production_schedule_runs SET changeover_id = ?
+		WHERE id = ? AND changeover_id IS NULL`, changeoverID, id); err != nil {
+		return fmt.Errorf("link schedule run %d to changeover %d: %w", id, changeoverID, err)
+	}
+	return nil
+}
+
+// DeleteScheduleRun removes a run that has not started. Active and finished
+// runs are history and stay.
+func (db *DB) DeleteScheduleRun(id int64) error {
+	res, err := db.Exec(`DELETE FROM production_schedule_runs WHERE id = ? AND status = ?`, id, ScheduleRunPlanned)
+	if err != nil {
+		return fmt.Errorf("delete schedule run %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNoScheduleRun
+	}
+	return nil
+}
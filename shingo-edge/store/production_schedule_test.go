@@ -0,0 +1,148 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func scheduleFixture(t *testing.T) (*DB, int64, int64, int64) {
+	t.Helper()
+	db := testDB(t)
+	pid, err := db.CreateProcess("PRESS-4", "", "active_production", "", "", false)
+	if err != nil {
+		t.Fatalf("create process: %v", err)
+	}
+	a, err := db.CreateStyle("A", "", pid)
+	if err != nil {
+		t.Fatalf("create style A: %v", err)
+	}
+	b, err := db.CreateStyle("B", "", pid)
+	if err != nil {
+		t.Fatalf("create style B: %v", err)
+	}
+	return db, pid, a, b
+}
+
+// A resend from MES replaces what has not started and leaves the running and
+// finished runs alone, appending after them.
+func TestReplacePlannedScheduleRuns_KeepsStartedRuns(t *testing.T) {
+	t.Parallel()
+	db, pid, a, b := scheduleFixture(t)
+
+	ids, err := db.ReplacePlannedScheduleRuns(pid, "csv", []ScheduleRunInput{
+		{StyleID: a, PlannedQty: 100},
+		{StyleID: b, PlannedQty: 50},
+	})
+	if err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	if err := db.ActivateScheduleRun(ids[0]); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+
+	if _, err := db.ReplacePlannedScheduleRuns(pid, "mes", []ScheduleRunInput{
+		{StyleID: b, PlannedQty: 70},
+		{StyleID: a, PlannedQty: 20},
+	}); err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+
+	runs, err := db.ListScheduleRuns(pid)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("got %d runs, want 3 (active kept + 2 replanned)", len(runs))
+	}
+	if runs[0].ID != ids[0] || runs[0].Status != ScheduleRunActive {
+		t.Errorf("first run = %+v, want the active run kept", runs[0])
+	}
+	if runs[1].PlannedQty != 70 || runs[1].Seq <= runs[0].Seq || runs[1].Source != "mes" {
+		t.Errorf("second run = %+v, want the resend's first run after the active one", runs[1])
+	}
+	if runs[1].StyleName != "B" {
+		t.Errorf("style name = %q, want B", runs[1].StyleName)
+	}
+}
+
+func TestAddScheduleRunProduced_OnlyActiveRunForStyle(t *testing.T) {
+	t.Parallel()
+	db, pid, a, b := scheduleFixture(t)
+	ids, err := db.ReplacePlannedScheduleRuns(pid, "csv", []ScheduleRunInput{{StyleID: a, PlannedQty: 10}})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if _, err := db.AddScheduleRunProduced(pid, a, 5); !errors.Is(err, ErrNoScheduleRun) {
+		t.Fatalf("produced before activation: err = %v, want ErrNoScheduleRun", err)
+	}
+	if err := db.ActivateScheduleRun(ids[0]); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if _, err := db.AddScheduleRunProduced(pid, b, 5); !errors.Is(err, ErrNoScheduleRun) {
+		t.Fatalf("produced for another style: err = %v, want ErrNoScheduleRun", err)
+	}
+	run, err := db.AddScheduleRunProduced(pid, a, 10)
+	if err != nil {
+		t.Fatalf("produced: %v", err)
+	}
+	if run.ProducedQty != 10 || !run.QuantityReached() {
+		t.Errorf("run = %+v, want 10 produced and quantity reached", run)
+	}
+
+	armed, err := db.MarkScheduleRunArmed(run.ID)
+	if err != nil || !armed {
+		t.Fatalf("first arm = %v, %v; want true", armed, err)
+	}
+	armed, err = db.MarkScheduleRunArmed(run.ID)
+	if err != nil || armed {
+		t.Fatalf("second arm = %v, %v; want false", armed, err)
+	}
+}
+
+func TestDeleteScheduleRun_PlannedOnly(t *testing.T) {
+	t.Parallel()
+	db, pid, a, _ := scheduleFixture(t)
+	ids, err := db.ReplacePlannedScheduleRuns(pid, "csv", []ScheduleRunInput{
+		{StyleID: a, PlannedQty: 10},
+		{StyleID: a, PlannedQty: 20},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if err := db.ActivateScheduleRun(ids[0]); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if err := db.DeleteScheduleRun(ids[0]); !errors.Is(err, ErrNoScheduleRun) {
+		t.Errorf("delete active run: err = %v, want ErrNoScheduleRun", err)
+	}
+	if err := db.DeleteScheduleRun(ids[1]); err != nil {
+		t.Errorf("delete planned run: %v", err)
+	}
+}
+
+func TestListScheduleRunsBetween_Window(t *testing.T) {
+	t.Parallel()
+	db, pid, a, b := scheduleFixture(t)
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	in := day.Add(8 * time.Hour)
+	out := day.Add(30 * time.Hour)
+	if _, err := db.ReplacePlannedScheduleRuns(pid, "csv", []ScheduleRunInput{
+		{StyleID: a, PlannedQty: 10, PlannedStart: &in},
+		{StyleID: b, PlannedQty: 10, PlannedStart: &out},
+		{StyleID: a, PlannedQty: 10},
+	}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	runs, err := db.ListScheduleRunsBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(runs) != 1 || runs[0].StyleID != a {
+		t.Fatalf("got %+v, want only the run planned inside the day", runs)
+	}
+	if runs[0].PlannedStart == nil || !runs[0].PlannedStart.Equal(in) {
+		t.Errorf("planned start = %v, want %v", runs[0].PlannedStart, in)
+	}
+}
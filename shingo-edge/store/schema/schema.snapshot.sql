@@ -40,6 +40,8 @@ CREATE UNIQUE INDEX idx_process_nodes_process_core_name
 				ON process_nodes(process_id, core_node_name)
 				WHERE core_node_name <> '' AND deleted_at IS NULL;
 
+CREATE INDEX idx_schedule_runs_process ON production_schedule_runs(process_id, seq);
+
 CREATE UNIQUE INDEX idx_styles_process_name_live
 			ON styles(process_id, name) WHERE deleted_at IS NULL;
 
@@ -47,7 +49,8 @@ CREATE TABLE admin_users (
     id            INTEGER PRIMARY KEY AUTOINCREMENT,
     username      TEXT NOT NULL UNIQUE,
     password_hash TEXT NOT NULL,
-    created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+    created_at    TEXT NOT NULL DEFAULT (datetime('now')),
+    language      TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE changeover_node_tasks (
@@ -250,6 +253,7 @@ CREATE TABLE operator_stations (
     enabled            INTEGER NOT NULL DEFAULT 1,
     health_status      TEXT NOT NULL DEFAULT 'offline',
     last_seen_at       TEXT,
+    language           TEXT NOT NULL DEFAULT '',
     created_at         TEXT NOT NULL DEFAULT (datetime('now')),
     updated_at         TEXT NOT NULL DEFAULT (datetime('now')),
     UNIQUE(process_id, code)
@@ -435,6 +439,30 @@ CREATE TABLE processes (
     created_at          TEXT NOT NULL DEFAULT (datetime('now'))
 );
 
+CREATE TABLE production_schedule_runs (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    process_id    INTEGER NOT NULL REFERENCES processes(id) ON DELETE CASCADE,
+    style_id      INTEGER NOT NULL REFERENCES styles(id) ON DELETE CASCADE,
+    seq           INTEGER NOT NULL,
+    planned_qty   INTEGER NOT NULL DEFAULT 0,
+    planned_start TEXT,
+    planned_end   TEXT,
+    status        TEXT NOT NULL DEFAULT 'planned',  -- planned | active | done | skipped
+    source        TEXT NOT NULL DEFAULT 'manual',   -- manual | csv | mes
+    external_ref  TEXT NOT NULL DEFAULT '',
+    produced_qty  INTEGER NOT NULL DEFAULT 0,
+    actual_start  TEXT,
+    actual_end    TEXT,
+    -- prestaged_at: the planner started this run's changeover early so its
+    -- material would be at the line before the cut. armed_at: the run before
+    -- it reached its quantity and the changeover was armed. Both NULL for a
+    -- run the operator changed over to by hand.
+    prestaged_at  TEXT,
+    armed_at      TEXT,
+    changeover_id INTEGER REFERENCES process_changeovers(id) ON DELETE SET NULL,
+    created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
 CREATE TABLE reporting_points (
     id              INTEGER PRIMARY KEY AUTOINCREMENT,
     style_id        INTEGER NOT NULL REFERENCES styles(id) ON DELETE CASCADE,
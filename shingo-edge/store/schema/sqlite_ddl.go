@@ -16,7 +16,8 @@ CREATE TABLE IF NOT EXISTS admin_users (
     id            INTEGER PRIMARY KEY AUTOINCREMENT,
     username      TEXT NOT NULL UNIQUE,
     password_hash TEXT NOT NULL,
-    created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+    created_at    TEXT NOT NULL DEFAULT (datetime('now')),
+    language      TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS processes (
@@ -294,6 +295,7 @@ CREATE TABLE IF NOT EXISTS operator_stations (
     enabled            INTEGER NOT NULL DEFAULT 1,
     health_status      TEXT NOT NULL DEFAULT 'offline',
     last_seen_at       TEXT,
+    language           TEXT NOT NULL DEFAULT '',
     created_at         TEXT NOT NULL DEFAULT (datetime('now')),
     updated_at         TEXT NOT NULL DEFAULT (datetime('now')),
     UNIQUE(process_id, code)
@@ -787,4 +789,41 @@ CREATE TABLE IF NOT EXISTS sourcing_state (
     synced_at   TEXT NOT NULL DEFAULT (datetime('now')),
     PRIMARY KEY (process_id, style_id)
 );
+
+-- production_schedule_runs — the ordered list of style runs a process is
+-- planned to make, imported from a CSV or pushed by MES.
+--
+-- ONE ROW PER RUN, KEPT AFTER IT ENDS. Unlike the open-state tables above, the
+-- finished rows ARE the product here: planned against actual is the schedule-
+-- adherence view, and Core has no copy of the plan to hold the history for it.
+--
+-- A run is planned by quantity (planned_qty), by time (planned_start /
+-- planned_end), or both. status walks planned → active → done, or to skipped
+-- when the line changed over to something else; a re-import replaces only the
+-- planned rows, so an active run's produced_qty survives the MES resending the
+-- day's plan.
+CREATE TABLE IF NOT EXISTS production_schedule_runs (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    process_id    INTEGER NOT NULL REFERENCES processes(id) ON DELETE CASCADE,
+    style_id      INTEGER NOT NULL REFERENCES styles(id) ON DELETE CASCADE,
+    seq           INTEGER NOT NULL,
+    planned_qty   INTEGER NOT NULL DEFAULT 0,
+    planned_start TEXT,
+    planned_end   TEXT,
+    status        TEXT NOT NULL DEFAULT 'planned',  -- planned | active | done | skipped
+    source        TEXT NOT NULL DEFAULT 'manual',   -- manual | csv | mes
+    external_ref  TEXT NOT NULL DEFAULT '',
+    produced_qty  INTEGER NOT NULL DEFAULT 0,
+    actual_start  TEXT,
+    actual_end    TEXT,
+    -- prestaged_at: the planner started this run's changeover early so its
+    -- material would be at the line before the cut. armed_at: the run before
+    -- it reached its quantity and the changeover was armed. Both NULL for a
+    -- run the operator changed over to by hand.
+    prestaged_at  TEXT,
+    armed_at      TEXT,
+    changeover_id INTEGER REFERENCES process_changeovers(id) ON DELETE SET NULL,
+    created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_process ON production_schedule_runs(process_id, seq);
 `
@@ -59,6 +59,7 @@ var requiredTables = []string{
 	// behind it, which is the silent-no-op failure this manifest exists to make
 	// loud.
 	"supply_refusals_open",
+	"production_schedule_runs",
 }
 
 // requiredColumn is one (table, column) pair added by an unconditional
@@ -89,6 +90,8 @@ var requiredColumns = []requiredColumn{
 	{"payload_catalog", "catid"},
 	{"changeover_node_tasks", "skip_note"},
 	{"process_node_runtime_states", "remaining_uop_cached"},
+	{"operator_stations", "language"},
+	{"admin_users", "language"},
 }
 
 // verifySchema reports every required table and column that is missing. It
@@ -34,7 +34,7 @@ type (
 )
 
 const stationSelect = `s.id, s.process_id, s.code, s.name, s.note, s.area_label, s.sequence,
-	s.controller_node_id, s.device_mode, s.enabled, s.health_status,
+	s.controller_node_id, s.device_mode, s.enabled, s.health_status, s.language,
 	COALESCE(s.last_seen_at, ''), s.created_at, s.updated_at, COALESCE(p.name, '')`
 
 const stationJoin = `FROM operator_stations s
@@ -45,7 +45,7 @@ func scanStation(scanner interface{ Scan(...any) error }) (Station, error) {
 	var lastSeen, createdAt, updatedAt string
 	err := scanner.Scan(
 		&s.ID, &s.ProcessID, &s.Code, &s.Name, &s.Note, &s.AreaLabel, &s.Sequence,
-		&s.ControllerNodeID, &s.DeviceMode, &s.Enabled, &s.HealthStatus,
+		&s.ControllerNodeID, &s.DeviceMode, &s.Enabled, &s.HealthStatus, &s.Language,
 		&lastSeen, &createdAt, &updatedAt, &s.ProcessName,
 	)
 	if err != nil {
@@ -122,9 +122,9 @@ func Create(db *sql.DB, in Input) (int64, error) {
 		in.Sequence = next
 	}
 	res, err := db.Exec(`INSERT INTO operator_stations (
-		process_id, code, name, note, area_label, sequence, controller_node_id, device_mode, enabled
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		in.ProcessID, in.Code, in.Name, in.Note, in.AreaLabel, in.Sequence, in.ControllerNodeID, in.DeviceMode, in.Enabled)
+		process_id, code, name, note, area_label, sequence, controller_node_id, device_mode, enabled, language
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		in.ProcessID, in.Code, in.Name, in.Note, in.AreaLabel, in.Sequence, in.ControllerNodeID, in.DeviceMode, in.Enabled, in.Language)
 	if err != nil {
 		return 0, err
 	}
@@ -150,9 +150,9 @@ func Update(db *sql.DB, id int64, in Input) error {
 		}
 	}
 	_, err := db.Exec(`UPDATE operator_stations SET
-		process_id=?, code=?, name=?, note=?, area_label=?, sequence=?, controller_node_id=?, device_mode=?, enabled=?, updated_at=datetime('now')
+		process_id=?, code=?, name=?, note=?, area_label=?, sequence=?, controller_node_id=?, device_mode=?, enabled=?, language=?, updated_at=datetime('now')
 		WHERE id=?`,
-		in.ProcessID, in.Code, in.Name, in.Note, in.AreaLabel, in.Sequence, in.ControllerNodeID, in.DeviceMode, in.Enabled, id)
+		in.ProcessID, in.Code, in.Name, in.Note, in.AreaLabel, in.Sequence, in.ControllerNodeID, in.DeviceMode, in.Enabled, in.Language, id)
 	return err
 }
 
@@ -134,6 +134,18 @@ func TestAdminUsers_CreateGetUpdateExists(t *testing.T) {
 	if got2.PasswordHash != "hash-v2" {
 		t.Errorf("password hash = %q, want hash-v2", got2.PasswordHash)
 	}
+	if got2.Language != "" {
+		t.Errorf("language = %q before any choice, want empty (plant default)", got2.Language)
+	}
+
+	testutil.MustNoErr(t, db.UpdateAdminLanguage("alice", "ja"), "update language")
+	got3, err := db.GetAdminUser("alice")
+	if err != nil {
+		t.Fatalf("get after language: %v", err)
+	}
+	if got3.Language != "ja" || got3.PasswordHash != "hash-v2" {
+		t.Errorf("after language update: %+v", got3)
+	}
 }
 
 func TestAdminUsers_GetMissingReturnsError(t *testing.T) {
@@ -1115,12 +1127,12 @@ func TestOperatorStations_CRUD(t *testing.T) {
 
 	// Update — empty Code + Sequence triggers preservation paths.
 	if err := db.UpdateOperatorStation(id, stations.Input{
-		ProcessID: pid, Name: "Main Renamed", AreaLabel: "A1",
+		ProcessID: pid, Name: "Main Renamed", AreaLabel: "A1", Language: "es",
 	}); err != nil {
 		t.Fatalf("update: %v", err)
 	}
 	gotU, _ := db.GetOperatorStation(id)
-	if gotU.Name != "Main Renamed" || gotU.AreaLabel != "A1" {
+	if gotU.Name != "Main Renamed" || gotU.AreaLabel != "A1" || gotU.Language != "es" {
 		t.Errorf("after update: %+v", gotU)
 	}
 	if gotU.Code != got.Code {
@@ -14,7 +14,10 @@ type sessionStore struct {
 	store *sessions.CookieStore
 }
 
-func newSessionStore(secret string) *sessionStore {
+// newSessionStore builds the cookie store. secure follows web.tls.enabled: a
+// Secure cookie is never sent back over plain HTTP, so it is only set when
+// the HMI is actually served over HTTPS.
+func newSessionStore(secret string, secure bool) *sessionStore {
 	var key []byte
 	if secret != "" {
 		key, _ = base64.StdEncoding.DecodeString(secret)
@@ -28,6 +31,7 @@ func newSessionStore(secret string) *sessionStore {
 		Path:     "/",
 		MaxAge:   7 * 24 * 60 * 60, // 7 days
 		HttpOnly: true,
+		Secure:   secure,
 		SameSite: http.SameSiteLaxMode,
 	}
 	return &sessionStore{store: cs}
@@ -54,9 +58,24 @@ func (s *sessionStore) setUser(w http.ResponseWriter, r *http.Request, username
 	sess.Save(r, w)
 }
 
+// getLang returns the signed-in admin's page language, "" when none was chosen.
+// It rides the session so resolving a page's language costs no DB read; the
+// admin_users row is where it persists across logins.
+func (s *sessionStore) getLang(r *http.Request) string {
+	lang, _ := s.get(r).Values["lang"].(string)
+	return lang
+}
+
+func (s *sessionStore) setLang(w http.ResponseWriter, r *http.Request, lang string) {
+	sess := s.get(r)
+	sess.Values["lang"] = lang
+	sess.Save(r, w)
+}
+
 func (s *sessionStore) clear(w http.ResponseWriter, r *http.Request) {
 	sess := s.get(r)
 	delete(sess.Values, "username")
+	delete(sess.Values, "lang")
 	sess.Options.MaxAge = -1
 	sess.Save(r, w)
 }
@@ -1,6 +1,7 @@
 package www
 
 import (
+	"math"
 	"net/http"
 	"time"
 )
@@ -48,6 +49,35 @@ type statusResponse struct {
 	KafkaLastPublishOK bool       `json:"kafka_last_publish_ok"`
 	KafkaLastPublishAt *time.Time `json:"kafka_last_publish_at,omitempty"`
 	StationID          string     `json:"station_id"`
+	// WebCert is the HMI's HTTPS certificate; absent on plain HTTP.
+	WebCert *webCertInfo `json:"web_cert,omitempty"`
+}
+
+// webCertInfo is the serving certificate's expiry, for /status and the
+// diagnostics page. Expiring is the last month: a generated certificate is
+// reissued at the next restart from then on, a supplied one needs the plant
+// PKI, and either way a browser refuses the whole HMI the day it lapses.
+type webCertInfo struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	DaysLeft  int       `json:"days_left"`
+	Expiring  bool      `json:"expiring"`
+}
+
+const webCertWarnDays = 30
+
+// webCert reports the certificate set by SetWebCertificate, nil on plain
+// HTTP. Wall clock: browsers check validity against real time, whatever the
+// sim clock says.
+func (h *Handlers) webCert(now time.Time) *webCertInfo {
+	if h.webCertNotAfter.IsZero() {
+		return nil
+	}
+	days := int(math.Floor(h.webCertNotAfter.Sub(now).Hours() / 24))
+	return &webCertInfo{
+		ExpiresAt: h.webCertNotAfter.UTC(),
+		DaysLeft:  days,
+		Expiring:  days < webCertWarnDays,
+	}
 }
 
 // statusEngine is the narrow interface the /status handler needs.
@@ -77,6 +107,7 @@ func (h *Handlers) apiStatus(w http.ResponseWriter, r *http.Request) {
 		KafkaConnected:   eng.KafkaConnected(),
 		SubscribersWired: eng.SubscribersWired(),
 		StationID:        eng.StationID(),
+		WebCert:          h.webCert(time.Now()),
 	}
 	if depth, err := eng.CountPendingOutbox(); err != nil {
 		resp.OutboxDepthError = err.Error()
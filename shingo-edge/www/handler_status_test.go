@@ -131,3 +131,22 @@ func TestStatus_NoPublishYetOmitsTimestamp(t *testing.T) {
 		t.Error("kafka_last_publish_ok = true before any publish attempt")
 	}
 }
+
+// TestStatus_WebCertificate: plain HTTP says nothing about a certificate; an
+// HTTPS HMI reports its expiry, flagged once inside the last month.
+func TestStatus_WebCertificate(t *testing.T) {
+	h, r := newTestHandlers(t)
+	if _, present := getStatus(t, h, r)["web_cert"]; present {
+		t.Error("web_cert present on a plain-HTTP edge")
+	}
+
+	h, r = newTestHandlers(t)
+	h.SetWebCertificate(time.Now().Add(10*24*time.Hour + time.Hour))
+	cert, ok := getStatus(t, h, r)["web_cert"].(map[string]any)
+	if !ok {
+		t.Fatal("web_cert missing after SetWebCertificate")
+	}
+	if cert["days_left"].(float64) != 10 || cert["expiring"] != true {
+		t.Errorf("web_cert = %v, want 10 days left and expiring", cert)
+	}
+}
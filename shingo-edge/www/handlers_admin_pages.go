@@ -10,6 +10,7 @@ import (
 	"shingo/protocol/auth"
 	"shingo/shared"
 	"shingoedge/domain"
+	"shingoedge/plc"
 )
 
 func (h *Handlers) handleConfig(w http.ResponseWriter, r *http.Request) {
@@ -18,9 +19,11 @@ func (h *Handlers) handleConfig(w http.ResponseWriter, r *http.Request) {
 
 	plcNames := mgr.PLCNames()
 	plcStatus := make(map[string]bool)
+	plcHealth := make(map[string]*plc.PLCHealth)
 	plcStatuses := mgr.PLCStatuses()
 	for _, name := range plcNames {
 		plcStatus[name] = plcStatuses[name] == "Connected"
+		plcHealth[name] = mgr.GetPLCHealth(name)
 	}
 
 	anomalies, rpMap := loadAnomalyData(h)
@@ -33,6 +36,7 @@ func (h *Handlers) handleConfig(w http.ResponseWriter, r *http.Request) {
 	data := map[string]any{
 		"Page":              "config",
 		"PLCStatus":         plcStatus,
+		"PLCHealth":         plcHealth,
 		"PLCStatuses":       plcStatuses,
 		"Config":            cfg,
 		"PLCNames":          plcNames,
@@ -40,6 +44,7 @@ func (h *Handlers) handleConfig(w http.ResponseWriter, r *http.Request) {
 		"ReportingPointMap": rpMap,
 		"WarLinkConnected":  mgr.IsWarLinkConnected(),
 		"ShiftsJSON":        template.JS(shiftsJSON),
+		"UserLanguage":      h.sessions.getLang(r),
 	}
 	h.renderTemplate(w, r, "config.html", data)
 }
@@ -191,6 +196,7 @@ func (h *Handlers) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.sessions.setUser(w, r, username)
+	h.sessions.setLang(w, r, user.Language)
 	http.Redirect(w, r, dest, http.StatusSeeOther)
 }
 
@@ -0,0 +1,29 @@
+package www
+
+import (
+	"net/http"
+)
+
+// handlePLCSignals renders the admin "PLC Signals" page: the plc_signals
+// bindings as configured, and every value the edge has commanded onto a PLC
+// next to what the tag reads back. A row whose read-back disagrees is a
+// light the line is not seeing.
+func (h *Handlers) handlePLCSignals(w http.ResponseWriter, r *http.Request) {
+	cfg := h.engine.AppConfig().PLCSignals
+	anomalies, rpMap := loadAnomalyData(h)
+	data := map[string]any{
+		"Page":              "plc-signals",
+		"Bindings":          cfg.Bindings,
+		"Heartbeat":         cfg.Heartbeat,
+		"Commands":          h.engine.PLCManager().Commands(),
+		"Anomalies":         anomalies,
+		"ReportingPointMap": rpMap,
+	}
+	h.renderTemplate(w, r, "plc-signals.html", data)
+}
+
+// apiPLCCommands returns the commanded values and their read-back; the PLC
+// Signals page polls it.
+func (h *Handlers) apiPLCCommands(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.engine.PLCManager().Commands())
+}
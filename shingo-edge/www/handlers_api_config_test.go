@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"shingo/protocol"
+	"shingoedge/config"
 	"shingoedge/store/catalog"
 	"shingoedge/store/counters"
 	"shingoedge/store/processes"
@@ -692,6 +693,37 @@ func TestApiConfig_UpdateMessaging(t *testing.T) {
 	assertJSONPath(t, resp, "status", "ok")
 }
 
+// The page never receives the saved SASL password, so a blank one in the
+// request must keep it; an unusable setting must be refused, not saved.
+func TestApiConfig_UpdateMessagingSecurity(t *testing.T) {
+	h, router := newAdminRouter(t)
+	cookie := authCookie(t, h)
+	cfg := h.engine.AppConfig()
+	cfg.Messaging.Kafka.SASL = config.KafkaSASLConfig{Mechanism: config.KafkaSASLPlain, Username: "line-1", Password: "saved-pw"}
+
+	body := map[string]any{
+		"kafka_brokers": []string{"broker1:9093"},
+		"kafka_tls":     map[string]any{"enabled": true, "server_name": "kafka.plant.local"},
+		"kafka_sasl":    map[string]any{"mechanism": "scram-sha-512", "username": "line-1", "password": ""},
+	}
+	resp := doRequest(t, router, "PUT", "/api/config/messaging", body, cookie)
+	assertStatus(t, resp, http.StatusOK)
+	k := cfg.Messaging.Kafka
+	if !k.TLS.Enabled || k.TLS.ServerName != "kafka.plant.local" || k.SASL.Mechanism != config.KafkaSASLSCRAMSHA512 {
+		t.Fatalf("kafka security after save: %+v", k)
+	}
+	if k.SASL.Password != "saved-pw" {
+		t.Errorf("blank password replaced the saved one with %q", k.SASL.Password)
+	}
+
+	body["kafka_sasl"] = map[string]any{"mechanism": "gssapi", "username": "line-1"}
+	resp = doRequest(t, router, "PUT", "/api/config/messaging", body, cookie)
+	assertStatus(t, resp, http.StatusBadRequest)
+	if cfg.Messaging.Kafka.SASL.Mechanism != config.KafkaSASLSCRAMSHA512 {
+		t.Errorf("refused save still changed the mechanism to %q", cfg.Messaging.Kafka.SASL.Mechanism)
+	}
+}
+
 func TestApiConfig_UpdateStationID(t *testing.T) {
 	h, router := newAdminRouter(t)
 	cookie := authCookie(t, h)
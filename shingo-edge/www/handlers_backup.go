@@ -3,10 +3,12 @@ package www
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"shingoedge/backup"
 	"shingoedge/config"
 )
 
@@ -40,21 +42,82 @@ func (h *Handlers) apiListBackups(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, items)
 }
 
+// backupStorageRequest is the storage half of the backup form, shared by
+// save and test. Field names are flat because the form is; S3 keeps its
+// original unprefixed names so older clients still post a valid request.
+type backupStorageRequest struct {
+	Storage               string `json:"storage"`
+	Endpoint              string `json:"endpoint"`
+	Bucket                string `json:"bucket"`
+	Region                string `json:"region"`
+	AccessKey             string `json:"access_key"`
+	SecretKey             string `json:"secret_key"`
+	UsePathStyle          bool   `json:"use_path_style"`
+	InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify"`
+	FSPath                string `json:"fs_path"`
+	SFTPHost              string `json:"sftp_host"`
+	SFTPUser              string `json:"sftp_user"`
+	SFTPPassword          string `json:"sftp_password"`
+	SFTPPrivateKeyFile    string `json:"sftp_private_key_file"`
+	SFTPHostKey           string `json:"sftp_host_key"`
+	SFTPInsecureHostKey   bool   `json:"sftp_insecure_ignore_host_key"`
+	SFTPPath              string `json:"sftp_path"`
+}
+
+// apply writes the storage settings into cfg, trimmed.
+func (req backupStorageRequest) apply(cfg *config.BackupConfig) {
+	cfg.Storage = strings.TrimSpace(req.Storage)
+	if cfg.Storage == "" {
+		cfg.Storage = config.BackupStorageS3
+	}
+	cfg.S3.Endpoint = strings.TrimSpace(req.Endpoint)
+	cfg.S3.Bucket = strings.TrimSpace(req.Bucket)
+	cfg.S3.Region = strings.TrimSpace(req.Region)
+	cfg.S3.AccessKey = strings.TrimSpace(req.AccessKey)
+	cfg.S3.SecretKey = strings.TrimSpace(req.SecretKey)
+	cfg.S3.UsePathStyle = req.UsePathStyle
+	cfg.S3.InsecureSkipTLSVerify = req.InsecureSkipTLSVerify
+	cfg.Filesystem.Path = strings.TrimSpace(req.FSPath)
+	cfg.SFTP.Host = strings.TrimSpace(req.SFTPHost)
+	cfg.SFTP.User = strings.TrimSpace(req.SFTPUser)
+	cfg.SFTP.Password = req.SFTPPassword
+	cfg.SFTP.PrivateKeyFile = strings.TrimSpace(req.SFTPPrivateKeyFile)
+	cfg.SFTP.HostKey = strings.TrimSpace(req.SFTPHostKey)
+	cfg.SFTP.InsecureIgnoreHostKey = req.SFTPInsecureHostKey
+	cfg.SFTP.Path = strings.TrimSpace(req.SFTPPath)
+}
+
+// missing names what an enabled backup still needs, or "".
+func (req backupStorageRequest) missing() string {
+	blank := func(v string) bool { return strings.TrimSpace(v) == "" }
+	switch strings.TrimSpace(req.Storage) {
+	case "", config.BackupStorageS3:
+		if blank(req.Endpoint) || blank(req.Bucket) || blank(req.AccessKey) || blank(req.SecretKey) {
+			return "endpoint, bucket, access key, and secret key are required to enable automatic backups"
+		}
+	case config.BackupStorageFilesystem:
+		if blank(req.FSPath) {
+			return "a backup directory is required to enable automatic backups"
+		}
+	case config.BackupStorageSFTP:
+		if blank(req.SFTPHost) || blank(req.SFTPUser) || blank(req.SFTPPath) || (req.SFTPPassword == "" && blank(req.SFTPPrivateKeyFile)) {
+			return "host, user, path, and a password or private key file are required to enable automatic backups"
+		}
+	default:
+		return "storage must be s3, filesystem, or sftp"
+	}
+	return ""
+}
+
 func (h *Handlers) apiUpdateBackupConfig(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Enabled               bool   `json:"enabled"`
-		ScheduleInterval      string `json:"schedule_interval"`
-		KeepHourly            int    `json:"keep_hourly"`
-		KeepDaily             int    `json:"keep_daily"`
-		KeepWeekly            int    `json:"keep_weekly"`
-		KeepMonthly           int    `json:"keep_monthly"`
-		Endpoint              string `json:"endpoint"`
-		Bucket                string `json:"bucket"`
-		Region                string `json:"region"`
-		AccessKey             string `json:"access_key"`
-		SecretKey             string `json:"secret_key"`
-		UsePathStyle          bool   `json:"use_path_style"`
-		InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify"`
+		backupStorageRequest
+		Enabled          bool   `json:"enabled"`
+		ScheduleInterval string `json:"schedule_interval"`
+		KeepHourly       int    `json:"keep_hourly"`
+		KeepDaily        int    `json:"keep_daily"`
+		KeepWeekly       int    `json:"keep_weekly"`
+		KeepMonthly      int    `json:"keep_monthly"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -70,8 +133,8 @@ func (h *Handlers) apiUpdateBackupConfig(w http.ResponseWriter, r *http.Request)
 		interval = d
 	}
 	if req.Enabled {
-		if strings.TrimSpace(req.Endpoint) == "" || strings.TrimSpace(req.Bucket) == "" || strings.TrimSpace(req.AccessKey) == "" || strings.TrimSpace(req.SecretKey) == "" {
-			writeError(w, http.StatusBadRequest, "endpoint, bucket, access key, and secret key are required to enable automatic backups")
+		if msg := req.missing(); msg != "" {
+			writeError(w, http.StatusBadRequest, msg)
 			return
 		}
 		if interval <= 0 {
@@ -88,13 +151,7 @@ func (h *Handlers) apiUpdateBackupConfig(w http.ResponseWriter, r *http.Request)
 	cfg.Backup.KeepDaily = req.KeepDaily
 	cfg.Backup.KeepWeekly = req.KeepWeekly
 	cfg.Backup.KeepMonthly = req.KeepMonthly
-	cfg.Backup.S3.Endpoint = strings.TrimSpace(req.Endpoint)
-	cfg.Backup.S3.Bucket = strings.TrimSpace(req.Bucket)
-	cfg.Backup.S3.Region = strings.TrimSpace(req.Region)
-	cfg.Backup.S3.AccessKey = strings.TrimSpace(req.AccessKey)
-	cfg.Backup.S3.SecretKey = strings.TrimSpace(req.SecretKey)
-	cfg.Backup.S3.UsePathStyle = req.UsePathStyle
-	cfg.Backup.S3.InsecureSkipTLSVerify = req.InsecureSkipTLSVerify
+	req.apply(&cfg.Backup)
 	cfg.Unlock()
 
 	if err := cfg.Save(h.engine.ConfigPath()); err != nil {
@@ -110,14 +167,16 @@ func (h *Handlers) apiTestBackupConfig(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotImplemented, "backup service unavailable")
 		return
 	}
-	var req config.BackupS3Config
+	var req backupStorageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	var backupCfg config.BackupConfig
+	req.apply(&backupCfg)
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
-	if err := h.backup.TestConfig(ctx, req); err != nil {
+	if err := h.backup.TestConfig(ctx, backupCfg); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -145,6 +204,9 @@ func (h *Handlers) apiStageBackupRestore(w http.ResponseWriter, r *http.Request)
 	}
 	var req struct {
 		Key string `json:"key"`
+		// AllowUnsigned overrides the signature check for this restore, after
+		// the operator has been told why the archive did not verify.
+		AllowUnsigned bool `json:"allow_unsigned"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -160,8 +222,12 @@ func (h *Handlers) apiStageBackupRestore(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, "station ID must be configured before staging a restore")
 		return
 	}
-	if err := h.backup.StageRestore(ctx, strings.TrimSpace(req.Key)); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if err := h.backup.StageRestore(ctx, strings.TrimSpace(req.Key), req.AllowUnsigned); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, backup.ErrUnverified) {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err.Error())
 		return
 	}
 	writeJSON(w, map[string]any{
@@ -259,7 +259,7 @@ func (h *Handlers) handleChangeoverPartial(w http.ResponseWriter, r *http.Reques
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.tmpl.ExecuteTemplate(w, "changeover-body", data); err != nil {
+	if err := h.page(h.lang(r)).ExecuteTemplate(w, "changeover-body", data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
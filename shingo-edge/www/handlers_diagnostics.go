@@ -28,6 +28,7 @@ func (h *Handlers) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
 		"ReportingPointMap": rpMap,
 		"ReconAnomalies":    reconAnomalies,
 		"Deadletters":       deadletters,
+		"WebCert":           h.webCert(time.Now()),
 	}
 	h.renderTemplate(w, r, "diagnostics.html", data)
 }
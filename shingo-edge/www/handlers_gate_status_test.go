@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"shingo/protocol/i18n"
 	"shingoedge/domain"
 )
 
@@ -141,3 +142,35 @@ func TestGateStatus_IsGetOnly(t *testing.T) {
 		t.Error("POST to gate-status returned 200; it is a read-only endpoint")
 	}
 }
+
+// TestGateStatus_ReasonsFollowThePageLanguage — the panel shows reason as
+// given, so the handler renders it in the language the station page sent.
+// A blocker without a catalog message keeps its Reason in any language.
+func TestGateStatus_ReasonsFollowThePageLanguage(t *testing.T) {
+	eng, r := newGateStatusRouter(t)
+	eng.gateBlockers = []domain.Blocker{
+		{Reason: "order 703 in in_transit", OrderID: 703, Hard: true,
+			Msg: i18n.Message{Key: "blocker.order", Args: []any{"order", int64(703), "status", "in_transit"}}},
+		{Reason: "legacy sentence", Hard: true},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/processes/1/changeover/gate-status", nil)
+	req.Header.Set(langHeader, "es")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	var body struct {
+		Blockers []domain.Blocker `json:"blockers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	if len(body.Blockers) != 2 {
+		t.Fatalf("blockers = %v, want 2", body.Blockers)
+	}
+	if got := body.Blockers[0].Reason; got != "pedido 703 en in_transit" {
+		t.Errorf("blocker[0].reason = %q, want the Spanish", got)
+	}
+	if got := body.Blockers[1].Reason; got != "legacy sentence" {
+		t.Errorf("blocker[1].reason = %q, want it unchanged", got)
+	}
+}
@@ -119,7 +119,7 @@ func (h *Handlers) handleMaterialPartial(w http.ResponseWriter, r *http.Request)
 		"StationViews": stationViews,
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.tmpl.ExecuteTemplate(w, "material-body", data); err != nil {
+	if err := h.page(h.lang(r)).ExecuteTemplate(w, "material-body", data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -249,7 +249,7 @@ func (h *Handlers) apiRefuseSupply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.orchestration.RefuseSupply(id, req.PayloadCode, req.RefusedBy); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, errorText(h.lang(r), err))
 		return
 	}
 	writeJSONWithTrigger(w, r, map[string]string{"status": "ok"}, "refreshMaterial")
@@ -269,7 +269,7 @@ func (h *Handlers) apiUndoSupplyRefusal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	if err := h.orchestration.UndoSupplyRefusal(id, req.PayloadCode); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, errorText(h.lang(r), err))
 		return
 	}
 	writeJSONWithTrigger(w, r, map[string]string{"status": "ok"}, "refreshMaterial")
@@ -297,7 +297,7 @@ func (h *Handlers) apiAckSupplyRefusal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.orchestration.AckSupplyRefusal(id, req.LoaderNode, req.PayloadCode, req.Choice); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, errorText(h.lang(r), err))
 		return
 	}
 	writeJSONWithTrigger(w, r, map[string]string{"status": "ok"}, "refreshMaterial")
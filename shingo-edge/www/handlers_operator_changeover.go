@@ -299,7 +299,7 @@ func (h *Handlers) apiChangeoverGateStatus(w http.ResponseWriter, r *http.Reques
 	}
 	writeJSON(w, map[string]any{
 		"can_complete": canComplete,
-		"blockers":     blockers,
+		"blockers":     localizeBlockers(h.lang(r), blockers),
 	})
 }
 
@@ -310,7 +310,7 @@ func (h *Handlers) apiCompleteProcessProductionCutover(w http.ResponseWriter, r
 		return
 	}
 	if err := h.orchestration.CompleteProcessProductionCutover(processID); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, errorText(h.lang(r), err))
 		return
 	}
 	h.eventHub.Broadcast(SSEEvent{Type: "changeover-update", Data: map[string]string{"action": "cutover-complete"}})
@@ -30,7 +30,7 @@ func (h *Handlers) handleOperatorStationDisplay(w http.ResponseWriter, r *http.R
 		"Page":    "operator-display",
 		"Station": station,
 	}
-	h.renderTemplate(w, r, "operator-display.html", data)
+	h.renderIn(w, r, h.lang(r, station.Language), "operator-display.html", data)
 }
 
 func (h *Handlers) apiGetOperatorStationView(w http.ResponseWriter, r *http.Request) {
@@ -94,7 +94,7 @@ func (h *Handlers) handleOrdersPartial(w http.ResponseWriter, r *http.Request) {
 		"ActiveOrders": activeOrders,
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := h.tmpl.ExecuteTemplate(w, "orders-body", data); err != nil {
+	if err := h.page(h.lang(r)).ExecuteTemplate(w, "orders-body", data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -13,8 +13,8 @@ import (
 //
 // Orders: handleOrders and handleOrdersPartial both render templates
 // (renderTemplate is a no-op in the test harness for the former; the
-// latter calls h.tmpl.ExecuteTemplate directly on a nil *template.Template
-// and panics). Only admin-gate-style coverage is achievable. In router.go
+// latter executes h.page directly on an unparsed template set and
+// panics). Only admin-gate-style coverage is achievable. In router.go
 // /orders and /orders/partial are public routes, so there is no
 // middleware gate to hit; the handlers are not exercised from tests.
 // Coverage of their DB call sites therefore lands through the process
@@ -269,3 +269,37 @@ func TestRequestBackup_NilServiceIsSafe(t *testing.T) {
 	// Must not panic.
 	h.requestBackup("unit-test")
 }
+
+func TestApiUpdateBackupConfig_DirectoryAndSFTPStorage(t *testing.T) {
+	h, router := newOrdersBackupRouter(t)
+
+	resp := doRequest(t, router, "PUT", "/api/backups/config", map[string]any{
+		"enabled":           true,
+		"schedule_interval": "1h",
+		"storage":           "sftp",
+		"sftp_host":         "files.plant.local",
+		"sftp_user":         "edge",
+		"sftp_path":         "/backups",
+	}, nil)
+	assertStatus(t, resp, http.StatusBadRequest) // neither password nor key
+
+	resp = doRequest(t, router, "PUT", "/api/backups/config", map[string]any{
+		"enabled":           true,
+		"schedule_interval": "1h",
+		"storage":           "filesystem",
+		"fs_path":           "  /mnt/backups  ",
+		"sftp_host":         "files.plant.local",
+	}, nil)
+	assertStatus(t, resp, http.StatusOK)
+
+	cfg := h.engine.AppConfig()
+	cfg.Lock()
+	defer cfg.Unlock()
+	if cfg.Backup.Storage != "filesystem" || cfg.Backup.Filesystem.Path != "/mnt/backups" {
+		t.Errorf("storage = %q, path = %q", cfg.Backup.Storage, cfg.Backup.Filesystem.Path)
+	}
+	// Every backend's fields are saved, so switching back loses nothing.
+	if cfg.Backup.SFTP.Host != "files.plant.local" {
+		t.Errorf("SFTP host = %q", cfg.Backup.SFTP.Host)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"time"
 
+	"shingoedge/plc"
+
 	"github.com/go-chi/chi/v5"
 )
 
@@ -19,9 +21,10 @@ import (
 func (h *Handlers) apiListPLCs(w http.ResponseWriter, r *http.Request) {
 	mgr := h.engine.PLCManager()
 	type plcInfo struct {
-		Name      string `json:"name"`
-		Status    string `json:"status"`
-		Connected bool   `json:"connected"`
+		Name      string         `json:"name"`
+		Status    string         `json:"status"`
+		Connected bool           `json:"connected"`
+		Health    *plc.PLCHealth `json:"health,omitempty"`
 	}
 	names := mgr.PLCNames()
 	result := make([]plcInfo, len(names))
@@ -31,7 +34,12 @@ func (h *Handlers) apiListPLCs(w http.ResponseWriter, r *http.Request) {
 		if mp != nil {
 			status = mp.Status
 		}
-		result[i] = plcInfo{Name: name, Status: status, Connected: mgr.IsConnected(name)}
+		result[i] = plcInfo{
+			Name:      name,
+			Status:    status,
+			Connected: mgr.IsConnected(name),
+			Health:    mgr.GetPLCHealth(name),
+		}
 	}
 	writeJSON(w, result)
 }
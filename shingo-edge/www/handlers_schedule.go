@@ -0,0 +1,188 @@
+// handlers_schedule.go — the production schedule: the adherence page, the
+// per-process schedule read, the MES push (PUT) and the CSV upload. Starting
+// changeovers off the schedule is the engine planner's job; nothing here
+// dispatches anything.
+
+package www
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"shingoedge/domain"
+	"shingoedge/engine"
+	"shingoedge/service"
+)
+
+// maxScheduleUpload caps a CSV upload. A week of runs for every process on
+// a plant is a few kilobytes.
+const maxScheduleUpload = 4 << 20
+
+func (h *Handlers) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	loc := engine.BucketLocation(h.engine.AppConfig().Timezone)
+	from, to, dateStr, err := scheduleWindow(r, loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	adherence, _ := h.schedule.Adherence(from, to)
+	if adherence == nil {
+		adherence = []service.ProcessAdherence{}
+	}
+	adherenceJSON, _ := json.Marshal(adherence)
+
+	anomalies, rpMap := loadAnomalyData(h)
+	h.renderTemplate(w, r, "schedule.html", map[string]any{
+		"Page":              "schedule",
+		"Date":              dateStr,
+		"AdherenceJSON":     template.JS(adherenceJSON),
+		"Enabled":           h.engine.AppConfig().Schedule.Enabled,
+		"CSVColumns":        service.ScheduleCSVColumns,
+		"Anomalies":         anomalies,
+		"ReportingPointMap": rpMap,
+	})
+}
+
+// scheduleWindow reads ?date=YYYY-MM-DD (default today) as one plant-local
+// day.
+func scheduleWindow(r *http.Request, loc *time.Location) (from, to time.Time, date string, err error) {
+	date = r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().In(loc).Format("2006-01-02")
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return from, to, date, errors.New("date must be YYYY-MM-DD")
+	}
+	return day, day.AddDate(0, 0, 1), date, nil
+}
+
+func (h *Handlers) apiScheduleAdherence(w http.ResponseWriter, r *http.Request) {
+	from, to, _, err := scheduleWindow(r, engine.BucketLocation(h.engine.AppConfig().Timezone))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	out, err := h.schedule.Adherence(from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if out == nil {
+		out = []service.ProcessAdherence{}
+	}
+	writeJSON(w, out)
+}
+
+func (h *Handlers) apiListProcessSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid ID")
+		return
+	}
+	runs, err := h.schedule.List(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if runs == nil {
+		runs = []domain.ScheduleRun{}
+	}
+	writeJSON(w, runs)
+}
+
+// apiReplaceProcessSchedule is the MES push: the body's runs replace the
+// process's planned runs, in order. Runs may name the style by ID or by
+// name; times are RFC 3339 or plant-local "YYYY-MM-DD HH:MM".
+func (h *Handlers) apiReplaceProcessSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid ID")
+		return
+	}
+	var req struct {
+		Source string `json:"source"`
+		Runs   []struct {
+			StyleID      int64  `json:"style_id"`
+			Style        string `json:"style"`
+			PlannedQty   int64  `json:"planned_qty"`
+			PlannedStart string `json:"planned_start"`
+			PlannedEnd   string `json:"planned_end"`
+			ExternalRef  string `json:"external_ref"`
+		} `json:"runs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Source == "" {
+		req.Source = service.ScheduleSourceMES
+	}
+	styles, err := h.engine.StyleService().ListByProcess(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	styleIDs := make(map[string]int64, len(styles))
+	for _, st := range styles {
+		styleIDs[st.Name] = st.ID
+	}
+	loc := engine.BucketLocation(h.engine.AppConfig().Timezone)
+	runs := make([]domain.ScheduleRunInput, 0, len(req.Runs))
+	for i, rr := range req.Runs {
+		in := domain.ScheduleRunInput{StyleID: rr.StyleID, PlannedQty: rr.PlannedQty, ExternalRef: rr.ExternalRef}
+		if in.StyleID == 0 {
+			in.StyleID = styleIDs[rr.Style]
+		}
+		if in.StyleID == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("run %d: unknown style %q", i+1, rr.Style))
+			return
+		}
+		if in.PlannedStart, err = service.ParseScheduleTime(rr.PlannedStart, loc); err == nil {
+			in.PlannedEnd, err = service.ParseScheduleTime(rr.PlannedEnd, loc)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("run %d: %v", i+1, err))
+			return
+		}
+		runs = append(runs, in)
+	}
+	ids, err := h.schedule.Replace(id, req.Source, runs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"ids": ids})
+}
+
+// apiImportScheduleCSV takes the schedule file as the raw request body.
+func (h *Handlers) apiImportScheduleCSV(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxScheduleUpload)
+	imported, err := h.schedule.ImportCSV(body, engine.BucketLocation(h.engine.AppConfig().Timezone))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"imported": imported})
+}
+
+func (h *Handlers) apiDeleteScheduleRun(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid ID")
+		return
+	}
+	if err := h.schedule.Delete(id); err != nil {
+		if errors.Is(err, service.ErrScheduleRunNotPlanned) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, map[string]string{"status": "ok"})
+}
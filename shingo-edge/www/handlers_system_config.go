@@ -10,11 +10,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"shingo/protocol/auth"
+	"shingoedge/config"
+	"shingoedge/messaging"
 )
 
 // --- Core API ---
@@ -52,25 +54,62 @@ func (h *Handlers) apiTestCoreAPI(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", req.CoreAPI+"/api/health", nil)
+	// Through the Core client, so an https Core is checked against the same
+	// plant CA the engine will trust once the URL is saved.
+	status, err := h.engine.CoreAPI().ProbeHealth(ctx, req.CoreAPI)
 	if err != nil {
 		writeJSON(w, map[string]any{"connected": false, "error": err.Error()})
 		return
 	}
-	resp, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		writeJSON(w, map[string]any{"connected": false, "error": err.Error()})
-		return
-	}
-	resp.Body.Close()
-	writeJSON(w, map[string]any{"connected": resp.StatusCode < 500})
+	writeJSON(w, map[string]any{"connected": status < 500})
 }
 
 // --- Config Admin ---
 
+// kafkaSecurityRequest is the TLS and SASL half of the messaging form, shared
+// by save and test so Test checks what Save would write. An absent object
+// leaves that layer as configured; a blank password keeps the saved one, since
+// the page never receives it.
+type kafkaSecurityRequest struct {
+	TLS *struct {
+		Enabled            bool   `json:"enabled"`
+		CAFile             string `json:"ca_file"`
+		CertFile           string `json:"cert_file"`
+		KeyFile            string `json:"key_file"`
+		ServerName         string `json:"server_name"`
+		InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	} `json:"kafka_tls"`
+	SASL *struct {
+		Mechanism string `json:"mechanism"`
+		Username  string `json:"username"`
+		Password  string `json:"password"`
+	} `json:"kafka_sasl"`
+}
+
+func (req kafkaSecurityRequest) apply(k *config.KafkaConfig) {
+	if t := req.TLS; t != nil {
+		k.TLS = config.KafkaTLSConfig{
+			Enabled:            t.Enabled,
+			CAFile:             strings.TrimSpace(t.CAFile),
+			CertFile:           strings.TrimSpace(t.CertFile),
+			KeyFile:            strings.TrimSpace(t.KeyFile),
+			ServerName:         strings.TrimSpace(t.ServerName),
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		}
+	}
+	if s := req.SASL; s != nil {
+		k.SASL.Mechanism = s.Mechanism
+		k.SASL.Username = strings.TrimSpace(s.Username)
+		if s.Password != "" {
+			k.SASL.Password = s.Password
+		}
+	}
+}
+
 func (h *Handlers) apiUpdateMessaging(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		KafkaBrokers []string `json:"kafka_brokers"`
+		kafkaSecurityRequest
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -79,7 +118,15 @@ func (h *Handlers) apiUpdateMessaging(w http.ResponseWriter, r *http.Request) {
 
 	cfg := h.engine.AppConfig()
 	cfg.Lock()
-	cfg.Messaging.Kafka.Brokers = req.KafkaBrokers
+	kafka := cfg.Messaging.Kafka
+	kafka.Brokers = req.KafkaBrokers
+	req.apply(&kafka)
+	if err := kafka.Validate(); err != nil {
+		cfg.Unlock()
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	cfg.Messaging.Kafka = kafka
 	cfg.Unlock()
 
 	if err := cfg.Save(h.engine.ConfigPath()); err != nil {
@@ -150,17 +197,24 @@ func (h *Handlers) apiUpdateStationID(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) apiTestKafka(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Broker string `json:"broker"`
+		kafkaSecurityRequest
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Broker == "" {
 		writeError(w, http.StatusBadRequest, "broker address required")
 		return
 	}
-	conn, err := net.DialTimeout("tcp", req.Broker, 5*time.Second)
-	if err != nil {
+	cfg := h.engine.AppConfig()
+	cfg.RLock()
+	kafka := cfg.Messaging.Kafka
+	cfg.RUnlock()
+	req.apply(&kafka)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	if err := messaging.ProbeBroker(ctx, kafka, req.Broker); err != nil {
 		writeJSON(w, map[string]any{"connected": false, "error": err.Error()})
 		return
 	}
-	conn.Close()
 	writeJSON(w, map[string]any{"connected": true})
 }
 
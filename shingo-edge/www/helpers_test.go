@@ -329,7 +329,7 @@ func newTestHandlers(t *testing.T) (*Handlers, *chi.Mux) {
 	h := &Handlers{
 		engine:        eng, // ServiceAccess
 		orchestration: eng, // EngineOrchestration
-		sessions:      newSessionStore(""),
+		sessions:      newSessionStore("", false),
 		eventHub:      NewEventHub(),
 		// Mirror NewRouter: the station-view handler coalesces through this, so
 		// a Handlers built without it panics on the first view request.
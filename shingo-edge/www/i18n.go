@@ -0,0 +1,164 @@
+package www
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"shingo/protocol/i18n"
+	"shingoedge/domain"
+	"shingoedge/locales"
+)
+
+// langHeader is what the operator station's scripts send with every request:
+// the language the page rendered in. It outranks the session and the plant
+// default because the station page resolved those already and may have landed
+// on the station's own language, which no API request could know otherwise.
+const langHeader = "X-Shingo-Lang"
+
+// lang resolves the language a request renders in: an explicit ?lang=, then
+// what the page's scripts say the page is in, then the signed-in admin's
+// choice, then the plant default from config, then the browser's
+// Accept-Language, then English. The plant default outranks the browser on
+// purpose: a shared floor PC's browser says en-US whatever the plant speaks.
+func (h *Handlers) lang(r *http.Request, prefer ...string) string {
+	cands := append([]string{r.URL.Query().Get("lang"), r.Header.Get(langHeader)}, prefer...)
+	cands = append(cands, h.sessions.getLang(r), h.engine.AppConfig().Language, r.Header.Get("Accept-Language"))
+	if lang := locales.Catalog.Match(cands...); lang != "" {
+		return lang
+	}
+	return i18n.English
+}
+
+// parseTemplates parses the template set once and clones it per language, each
+// clone binding the language-dependent funcs. Parsing is the expensive half and
+// happens once; a clone shares the parse trees.
+func (h *Handlers) parseTemplates() {
+	base := template.Must(template.New("").Funcs(templateFuncs()).Funcs(h.langFuncs(i18n.English)).
+		ParseFS(templatesFS, "templates/*.html", "templates/partials/*.html"))
+	h.tmpls = make(map[string]*template.Template)
+	for _, lang := range locales.Catalog.Languages() {
+		h.tmpls[lang] = template.Must(base.Clone()).Funcs(h.langFuncs(lang))
+	}
+}
+
+// page returns the template set for lang, English when lang has none.
+func (h *Handlers) page(lang string) *template.Template {
+	if t, ok := h.tmpls[lang]; ok {
+		return t
+	}
+	return h.tmpls[i18n.English]
+}
+
+// langFuncs are the template funcs whose output depends on the language.
+//
+// formatTime and formatTimePtr replace templateFuncs' UTC versions: the text is
+// the plant-local time written the language's way, and data-utc still carries
+// the instant, so utils.js's convertTimestamps re-renders it in the page's
+// language and plant zone without ever guessing which zone the text was in.
+func (h *Handlers) langFuncs(lang string) template.FuncMap {
+	formatTime := func(t time.Time) template.HTML {
+		if t.IsZero() {
+			return template.HTML("")
+		}
+		return template.HTML(`<time data-utc="` + t.UTC().Format(time.RFC3339) + `">` +
+			template.HTMLEscapeString(i18n.FormatTime(lang, t, h.plantLoc)) + `</time>`)
+	}
+	return template.FuncMap{
+		"lang": func() string { return lang },
+		"t": func(key string, args ...any) string {
+			return locales.Catalog.T(lang, key, args...)
+		},
+		"messages":  func() map[string]string { return locales.Catalog.Messages(lang) },
+		"languages": locales.Catalog.Languages,
+		"langName": func(code string) string {
+			return locales.Catalog.T(code, "lang.name")
+		},
+		"plantZone": func() string {
+			if h.plantLoc == nil || h.plantLoc == time.Local {
+				return ""
+			}
+			return h.plantLoc.String()
+		},
+		"formatNumber": func(v float64, decimals int) string {
+			return i18n.FormatNumber(lang, v, decimals)
+		},
+		"formatTime": formatTime,
+		"formatTimePtr": func(t *time.Time) template.HTML {
+			if t == nil {
+				return template.HTML("")
+			}
+			return formatTime(*t)
+		},
+		"blocker": func(b domain.Blocker) string { return blockerText(lang, b) },
+	}
+}
+
+// blockerText renders a cutover blocker in lang. A blocker built without a
+// catalog message renders its Reason as-is.
+func blockerText(lang string, b domain.Blocker) string {
+	if b.Msg.Key == "" {
+		return b.Reason
+	}
+	return locales.Catalog.Render(lang, b.Msg)
+}
+
+// localizeBlockers returns blockers with each Reason rendered in lang, for the
+// JSON the live panel polls.
+func localizeBlockers(lang string, blockers []domain.Blocker) []domain.Blocker {
+	out := make([]domain.Blocker, len(blockers))
+	for i, b := range blockers {
+		b.Reason = blockerText(lang, b)
+		out[i] = b
+	}
+	return out
+}
+
+// errorText is err as an operator reads it in lang: a catalog error rendered
+// in that language, a blocked cutover rebuilt from its blockers, and any other
+// error as its own text. English is the error's own text, wrapping included,
+// so an English station reads exactly what it always has.
+func errorText(lang string, err error) string {
+	if lang == i18n.English {
+		return err.Error()
+	}
+	var blocked *domain.CutoverBlockedError
+	if errors.As(err, &blocked) {
+		reasons := make([]string, len(blocked.Blockers))
+		for i, b := range blocked.Blockers {
+			reasons[i] = blockerText(lang, b)
+		}
+		return locales.Catalog.T(lang, "blocker.cannot_cutover", "reasons", strings.Join(reasons, "; "))
+	}
+	return locales.Catalog.Localize(lang, err)
+}
+
+// apiUpdateLanguage stores the signed-in admin's page language. Empty clears
+// it back to the plant default.
+func (h *Handlers) apiUpdateLanguage(w http.ResponseWriter, r *http.Request) {
+	username, ok := h.sessions.getUser(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "not logged in")
+		return
+	}
+	var req struct {
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Language != "" && !locales.Catalog.Supports(req.Language) {
+		writeError(w, http.StatusBadRequest, "unsupported language "+req.Language)
+		return
+	}
+	if err := h.engine.AdminService().UpdateLanguage(username, req.Language); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.sessions.setLang(w, r, req.Language)
+	writeJSON(w, map[string]string{"status": "ok"})
+}
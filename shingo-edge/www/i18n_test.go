@@ -0,0 +1,347 @@
+package www
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+
+	"shingo/protocol/i18n"
+	"shingoedge/domain"
+	"shingoedge/locales"
+)
+
+// The operator pages are the ones a Spanish- or Japanese-speaking operator
+// reads, and every string on them goes through the catalog. Two ways that
+// slips, and a test for each: a template names a key the catalog never got
+// (it renders as the raw key), or somebody types English straight into the
+// markup (it renders as English whatever the station's language).
+var operatorTemplates = []string{
+	"templates/header.html",
+	"templates/footer.html",
+	"templates/material.html",
+	"templates/partials/material-body.html",
+	"templates/orders.html",
+	"templates/partials/orders-body.html",
+	"templates/changeover.html",
+	"templates/partials/changeover-body.html",
+	"templates/partials/node-actions.html",
+	"templates/operator-display.html",
+}
+
+var (
+	templateKeyRef = regexp.MustCompile(`\{\{-?\s*t\s+"([^"]+)"`)
+	scriptKeyRef   = regexp.MustCompile(`\bt\('([^']+)'`)
+)
+
+// TestTemplateKeysExistInCatalog: every {{t "key"}} in any template and every
+// t('key') in the operator scripts names a key en.json has.
+func TestTemplateKeysExistInCatalog(t *testing.T) {
+	has := func(where, key string) {
+		if !locales.Catalog.Has(i18n.English, key) {
+			t.Errorf("%s: key %q is not in locales/en.json", where, key)
+		}
+	}
+	err := fs.WalkDir(templatesFS, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(templatesFS, p)
+		if err != nil {
+			return err
+		}
+		for _, m := range templateKeyRef.FindAllStringSubmatch(string(body), -1) {
+			has(p, m[1])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// shingoedge.js is shared with the engineering pages, so only its keys
+	// are checked here; its dialogs' buttons are what operators read of it.
+	for _, p := range append(operatorScripts(t), "static/js/shingoedge.js") {
+		body, err := fs.ReadFile(staticFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range scriptKeyRef.FindAllStringSubmatch(string(body), -1) {
+			has(p, m[1])
+		}
+	}
+}
+
+var (
+	templateAction = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+	skippedBlock   = regexp.MustCompile(`(?is)<script.*?</script>|<style.*?</style>|<!--.*?-->`)
+	textNode       = regexp.MustCompile(`>([^<>]*)<`)
+	textAttr       = regexp.MustCompile(`\b(title|placeholder|aria-label|alt|data-past)="([^"]*)"`)
+	charEntity     = regexp.MustCompile(`&#?[0-9A-Za-z]+;`) // &times;, &#x232B; — symbols, not words
+	hasLetter      = regexp.MustCompile(`\pL`)
+)
+
+// TestOperatorTemplatesHaveNoUntranslatedText is the failing test for a
+// template string that lacks a translation key: with the template actions
+// taken out, no text node and no human-read attribute on an operator page may
+// still contain a letter. Character entities are symbols and are let through.
+func TestOperatorTemplatesHaveNoUntranslatedText(t *testing.T) {
+	for _, p := range operatorTemplates {
+		body, err := fs.ReadFile(templatesFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := templateAction.ReplaceAllString(string(body), "")
+		src = skippedBlock.ReplaceAllString(src, "")
+		src = charEntity.ReplaceAllString(src, "")
+		for _, m := range textNode.FindAllStringSubmatch(src, -1) {
+			if text := strings.TrimSpace(m[1]); hasLetter.MatchString(text) {
+				t.Errorf("%s: text %q is not a catalog key — wrap it in {{t \"...\"}}", p, text)
+			}
+		}
+		for _, m := range textAttr.FindAllStringSubmatch(src, -1) {
+			if hasLetter.MatchString(m[2]) {
+				t.Errorf("%s: %s=%q is not a catalog key — wrap it in {{t \"...\"}}", p, m[1], m[2])
+			}
+		}
+	}
+}
+
+// scriptLiteralAllowed are the quoted strings in the operator scripts that read
+// as English but are not shown to an operator.
+var scriptLiteralAllowed = map[string]string{
+	"use strict":                "a directive",
+	"X-Shingo-Lang":             "a request header name",
+	"Content-Type":              "a request header name",
+	"POST":                      "an HTTP method",
+	"DELETE":                    "an HTTP method",
+	"HTTP":                      "matched against the fleet error text, not displayed",
+	"AbortError":                "an Error.name value",
+	"Record Count":              "matched against the alarm text the PLC sends, not displayed",
+	"Enter":                     "a KeyboardEvent.key value",
+	"Escape":                    "a KeyboardEvent.key value",
+	"Backspace":                 "a KeyboardEvent.key value",
+	"os-toast-msg error sticky": "a class list",
+	"corrective changeover from post-cutover part-id mismatch": "the audit note sent with the changeover, read by engineers",
+}
+
+var (
+	htmlTag      = regexp.MustCompile(`<[^<>]*>|<[^<>]*$|^[^<>]*>`)
+	consoleCall  = regexp.MustCompile(`console\.\w+\(\s*$`)
+	wordEdgeTrim = "([{\"'`" + `)]}.,:;!?…`
+	englishWord  = regexp.MustCompile(`^\pL+(?:['’-]\pL+)*$`) // "Auto-skipped", "that’s"; "x2" and "os-btn2" are not
+)
+
+// TestOperatorScriptsHaveNoUntranslatedText is the script-side twin of the
+// template test: a quoted string in an operator script that still reads as
+// English once its markup is taken out fails, unless it is a t() key. Reading
+// as English is a capitalised word ("No bin", "CANCEL") or two words in a row
+// ("bin at node"); class lists, CSS, URLs and status codes are single tokens
+// with a hyphen, colon or underscore in them and pass. Console output is for
+// whoever has the devtools open and passes too.
+func TestOperatorScriptsHaveNoUntranslatedText(t *testing.T) {
+	for _, p := range operatorScripts(t) {
+		body, err := fs.ReadFile(staticFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, lit := range scriptLiterals(string(body)) {
+			if consoleCall.MatchString(lit.before) {
+				continue
+			}
+			if _, ok := scriptLiteralAllowed[lit.text]; ok {
+				continue
+			}
+			if readsAsEnglish(lit.text) {
+				t.Errorf("%s:%d: %q is not a catalog key — use t('...')", p, lit.line, lit.text)
+			}
+		}
+	}
+}
+
+// pageScriptRef is a page script an operator template loads.
+var pageScriptRef = regexp.MustCompile(`src="/(static/js/pages/[\w-]+\.js)`)
+
+// operatorScripts are the scripts whose strings an operator reads: the station
+// scripts, and every static/js/pages script an operator template loads. The
+// rest of static/js/pages is the engineering pages, whose templates are not
+// on the catalog either.
+func operatorScripts(t *testing.T) []string {
+	t.Helper()
+	scripts, err := fs.Glob(staticFS, "static/operator-station/*.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, p := range scripts {
+		if !strings.HasSuffix(p, ".test.js") {
+			out = append(out, p)
+		}
+	}
+	for _, p := range operatorTemplates {
+		body, err := fs.ReadFile(templatesFS, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range pageScriptRef.FindAllStringSubmatch(string(body), -1) {
+			out = append(out, m[1])
+		}
+	}
+	if len(out) == len(scripts) {
+		t.Fatal("no operator template loads a static/js/pages script — pageScriptRef no longer matches")
+	}
+	return out
+}
+
+// readsAsEnglish reports whether s, with its markup taken out, has a
+// capitalised word of two letters or more or two plain words in a row.
+func readsAsEnglish(s string) bool {
+	words := 0
+	for _, tok := range strings.Fields(htmlTag.ReplaceAllString(s, " ")) {
+		tok = strings.Trim(tok, wordEdgeTrim)
+		if englishWord.MatchString(tok) && len([]rune(tok)) > 1 && unicode.IsUpper([]rune(tok)[0]) {
+			return true
+		}
+		if tok == "" || strings.IndexFunc(tok, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+			words = 0
+			continue
+		}
+		if words++; words == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+type scriptLiteral struct {
+	text   string
+	line   int
+	before string // the code on the line up to the opening quote
+}
+
+// scriptLiterals returns the string literals in a script, skipping comments
+// and regular expressions. A template literal's ${...} holes are left in its
+// text; none of the operator scripts nest quotes inside one.
+func scriptLiterals(src string) []scriptLiteral {
+	var out []scriptLiteral
+	line, lineStart := 1, 0
+	prev := byte(0) // last significant code byte, to tell a regex from division
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line, lineStart = line+1, i+1
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return out
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += 2 + end + 1
+		case c == '/' && strings.IndexByte("(,=:[!&|?{};", prev) >= 0:
+			for i++; i < len(src) && src[i] != '/'; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '[' {
+					for i < len(src) && src[i] != ']' {
+						i++
+					}
+				}
+			}
+			prev = '/'
+		case c == '\'' || c == '"' || c == '`':
+			start, startLine, before := i+1, line, src[lineStart:i]
+			for i++; i < len(src) && src[i] != c; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '\n' {
+					line, lineStart = line+1, i+1
+				}
+			}
+			out = append(out, scriptLiteral{text: src[start:min(i, len(src))], line: startLine, before: before})
+			prev = c
+		case c != ' ' && c != '\t' && c != '\r':
+			prev = c
+		}
+	}
+	return out
+}
+
+// TestLangPrecedence: an explicit ?lang= beats everything, the station's
+// language beats the plant default, and the plant default beats the browser,
+// whose Accept-Language on a shared floor PC says nothing about the operator.
+func TestLangPrecedence(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	h.engine.AppConfig().Language = "es"
+
+	cases := []struct {
+		name, url, accept, station, want string
+	}{
+		{"plant default over browser", "/", "ja", "", "es"},
+		{"station over plant default", "/", "", "ja", "ja"},
+		{"query over station", "/?lang=en", "", "ja", "en"},
+		{"unknown station language falls through", "/", "", "fr", "es"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.url, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept-Language", tc.accept)
+		}
+		if got := h.lang(req, tc.station); got != tc.want {
+			t.Errorf("%s: lang = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestOperatorPageRendersInLanguage executes the shared header and footer in
+// each language: the lang attribute comes from the clone for that language,
+// not the English parse the clones were made from.
+func TestOperatorPageRendersInLanguage(t *testing.T) {
+	h, _ := newTestHandlers(t)
+	h.parseTemplates()
+	for _, lang := range locales.Catalog.Languages() {
+		var b strings.Builder
+		if err := h.page(lang).ExecuteTemplate(&b, "footer", nil); err != nil {
+			t.Fatalf("%s: %v", lang, err)
+		}
+		if err := h.page(lang).ExecuteTemplate(&b, "header", map[string]any{"Page": "material"}); err != nil {
+			t.Fatalf("%s: %v", lang, err)
+		}
+		if !strings.Contains(b.String(), fmt.Sprintf(`lang="%s"`, lang)) {
+			t.Errorf("%s: header does not carry lang=%q", lang, lang)
+		}
+	}
+}
+
+// TestErrorText_LocalizesOperatorErrors: the supply-refusal errors and the
+// cutover refusal come back in the station's language, and English is the
+// engine's text unchanged.
+func TestErrorText_LocalizesOperatorErrors(t *testing.T) {
+	refusal := fmt.Errorf("refuse: %w", locales.Catalog.Errorf("refusal.err_no_payload"))
+	if got, want := errorText("es", refusal), locales.Catalog.T("es", "refusal.err_no_payload"); got != want {
+		t.Errorf("refusal in es = %q, want %q", got, want)
+	}
+	if got := errorText("en", refusal); got != refusal.Error() {
+		t.Errorf("refusal in en = %q, want the engine's %q", got, refusal.Error())
+	}
+
+	blocked := fmt.Errorf("cutover: %w", &domain.CutoverBlockedError{Blockers: []domain.Blocker{{
+		Reason: "order 703 in in_transit",
+		Msg:    i18n.Message{Key: "blocker.order", Args: []any{"order", 703, "status", "in_transit"}},
+	}}})
+	if got, want := errorText("es", blocked), "no se puede hacer el corte: pedido 703 en in_transit"; got != want {
+		t.Errorf("cutover in es = %q, want %q", got, want)
+	}
+	if got := errorText("ja", errors.New("plain")); got != "plain" {
+		t.Errorf("plain error in ja = %q, want it unchanged", got)
+	}
+}
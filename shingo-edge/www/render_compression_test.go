@@ -11,6 +11,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"shingoedge/config"
 )
 
 // TestRenderTemplateIsCompressed is the regression guard for the missing
@@ -29,8 +31,9 @@ func TestRenderTemplateIsCompressed(t *testing.T) {
 	// tens to hundreds of KB.
 	body := strings.Repeat("<tr><td>row</td></tr>", 2000)
 	h := &Handlers{
-		tmpl:     template.Must(template.New("page").Parse(body)),
-		sessions: newSessionStore("render-compression-test"),
+		engine:   &stubEngine{cfg: config.Defaults()},
+		tmpls:    map[string]*template.Template{"en": template.Must(template.New("page").Parse(body))},
+		sessions: newSessionStore("render-compression-test", false),
 	}
 
 	r := chi.NewRouter()
@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"shingo/protocol/debuglog"
+	"shingo/protocol/webtls"
 	"shingo/shared"
 	"shingoedge/backup"
 	"shingoedge/engine"
+	"shingoedge/service"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -42,7 +44,6 @@ type Handlers struct {
 	orchestration EngineOrchestration
 	backup        *backup.Service
 	sessions      *sessionStore
-	tmpl          *template.Template
 	eventHub      *EventHub
 	debugLog      *debuglog.Logger
 
@@ -73,6 +74,21 @@ type Handlers struct {
 	// rather than once per edit. Optional; nil when the publisher is not
 	// wired (e.g. tests), in which case the loop simply does nothing.
 	onPlantSpecChange func()
+
+	// webCertNotAfter is the HMI certificate's expiry, set by main when
+	// web.tls is on. Zero means plain HTTP.
+	webCertNotAfter time.Time
+
+	// schedule is the production-schedule service. Held directly rather
+	// than through ServiceAccess so that surface does not widen for one
+	// page.
+	schedule *service.ScheduleService
+
+	// tmpls is the template set per catalog language (see parseTemplates);
+	// plantLoc is the zone the templates write times in, resolved once from
+	// config the way the hourly tracker resolves it.
+	tmpls    map[string]*template.Template
+	plantLoc *time.Location
 }
 
 // NewRouter registers all HTTP endpoints for shingo-edge.
@@ -91,21 +107,23 @@ type Handlers struct {
 // Auth boundary: h.adminMiddleware. Public = shop floor operator access (no login).
 // Handlers live in handlers_*.go files grouped by domain.
 func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Service) (*Handlers, http.Handler, func()) {
+	web := eng.AppConfig().Web
 	h := &Handlers{
 		engine:         eng, // ServiceAccess — narrow surface for CRUD handlers
 		orchestration:  eng, // EngineOrchestration — wide surface for flow handlers
 		backup:         backupSvc,
-		sessions:       newSessionStore(eng.AppConfig().Web.SessionSecret),
+		sessions:       newSessionStore(web.SessionSecret, web.TLS.Enabled),
 		eventHub:       NewEventHub(),
 		debugLog:       dbg,
 		stationViews:   newStationViewGroup(),
 		specChangeCh:   make(chan struct{}, 1),
 		specChangeStop: make(chan struct{}),
+		schedule:       eng.ScheduleService(),
+		plantLoc:       engine.BucketLocation(eng.AppConfig().Timezone),
 	}
 	go h.specChangeLoop()
 
-	funcMap := templateFuncs()
-	h.tmpl = template.Must(template.New("").Funcs(funcMap).ParseFS(templatesFS, "templates/*.html", "templates/partials/*.html"))
+	h.parseTemplates()
 
 	h.eventHub.Start()
 	// Phase 6.0c: SSE wiring uses the local *engine.Engine parameter
@@ -120,6 +138,9 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 
 	r := chi.NewRouter()
 	r.Use(middleware.Recoverer)
+	if web.TLS.Enabled {
+		r.Use(webtls.HSTS)
+	}
 
 	// SSE — must be outside compression middleware. Compression buffers
 	// defeat streaming flushes, fill the per-client send queue, and cause
@@ -190,6 +211,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 			http.Redirect(w, req, target, http.StatusMovedPermanently)
 		})
 		r.Get("/production", h.handleProduction)
+		r.Get("/schedule", h.handleSchedule)
 		r.Get("/changeover", h.handleChangeover)
 		r.Get("/changeover/partial", h.handleChangeoverPartial)
 		r.Get("/orders/partial", h.handleOrdersPartial)
@@ -212,6 +234,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 			r.Get("/manual-message", h.handleManualMessage)
 			r.Get("/diagnostics", h.handleDiagnostics)
 			r.Get("/lineside-buckets", h.handleLinesideBuckets)
+			r.Get("/plc-signals", h.handlePLCSignals)
 			r.Get("/replenishment", h.handleReplenishment)
 		})
 
@@ -291,6 +314,8 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 			r.Get("/payload/{code}/manifest", h.apiPayloadManifest)
 			r.Get("/hourly-counts", h.apiGetHourlyCounts)
 			r.Get("/daily-counts", h.apiGetDailyCounts)
+			r.Get("/schedule/adherence", h.apiScheduleAdherence)
+			r.Get("/processes/{id}/schedule", h.apiListProcessSchedule)
 			r.Get("/core-nodes", h.apiGetCoreNodes)
 			r.Get("/payload-catalog", h.apiListPayloadCatalog)
 
@@ -327,6 +352,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 
 				// PLCs / WarLink
 				r.Get("/plcs", h.apiListPLCs)
+				r.Get("/plcs/commands", h.apiPLCCommands)
 				r.Get("/plcs/tags/{name}", h.apiPLCTags)
 				r.Get("/plcs/all-tags/{name}", h.apiPLCAllTags)
 				r.Post("/plcs/read-tag", h.apiReadTag)
@@ -355,6 +381,11 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 				r.Put("/processes/{id}/active-style", h.apiSetActiveStyle)
 				r.Get("/processes/{id}/styles", h.apiListProcessStyles)
 
+				// Production schedule (MES push, CSV upload, plan edits)
+				r.Put("/processes/{id}/schedule", h.apiReplaceProcessSchedule)
+				r.Post("/schedule/import", h.apiImportScheduleCSV)
+				r.Delete("/schedule/runs/{id}", h.apiDeleteScheduleRun)
+
 				// Styles & node claims
 				r.Get("/styles", h.apiListStyles)
 				r.Post("/styles", h.apiCreateStyle)
@@ -400,6 +431,7 @@ func NewRouter(eng *engine.Engine, dbg *debuglog.Logger, backupSvc *backup.Servi
 				r.Post("/config/kafka/test", h.apiTestKafka)
 				r.Put("/config/auto-confirm", h.apiUpdateAutoConfirm)
 				r.Post("/config/password", h.apiChangePassword)
+				r.Put("/config/language", h.apiUpdateLanguage)
 				r.Get("/backups", h.apiListBackups)
 				r.Get("/backups/status", h.apiBackupStatus)
 				r.Put("/backups/config", h.apiUpdateBackupConfig)
@@ -436,6 +468,12 @@ func (h *Handlers) SetPlantSpecChangeHook(fn func()) {
 	h.onPlantSpecChange = fn
 }
 
+// SetWebCertificate records the HMI certificate's expiry for /status and the
+// diagnostics page. Optional; main calls it only when web.tls is enabled.
+func (h *Handlers) SetWebCertificate(notAfter time.Time) {
+	h.webCertNotAfter = notAfter
+}
+
 // specChangeLoop owns plant-claims re-publishing. Multiple concurrent admin
 // style/claim edits collapse into one channel send (capacity 1 with a
 // non-blocking sender); this loop drains the channel and publishes
@@ -540,6 +578,12 @@ func (h *Handlers) adminMiddleware(next http.Handler) http.Handler {
 }
 
 func (h *Handlers) renderTemplate(w http.ResponseWriter, r *http.Request, name string, data any) {
+	h.renderIn(w, r, h.lang(r), name, data)
+}
+
+// renderIn is renderTemplate in a language the caller already resolved — the
+// operator station page, whose station may carry its own.
+func (h *Handlers) renderIn(w http.ResponseWriter, r *http.Request, lang, name string, data any) {
 	if m, ok := data.(map[string]any); ok {
 		_, isAuth := h.sessions.getUser(r)
 		m["Authenticated"] = isAuth
@@ -547,7 +591,7 @@ func (h *Handlers) renderTemplate(w http.ResponseWriter, r *http.Request, name s
 	// Before the first write: the compression middleware reads Content-Type at
 	// WriteHeader and skips compression when it is empty. See shared.SetHTMLContentType.
 	shared.SetHTMLContentType(w)
-	if err := h.tmpl.ExecuteTemplate(w, name, data); err != nil {
+	if err := h.page(lang).ExecuteTemplate(w, name, data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
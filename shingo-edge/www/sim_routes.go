@@ -16,10 +16,34 @@ import (
 // registerSimRoutes adds the dev-only sim control endpoints (the live speed
 // toggle) on the edge — the edge owns its own SimClock, which paces the fake
 // PLC counters, so the dev top-strip changes both core and edge speed. Compiled
-// only into -tags sim builds; the non-sim stub is a no-op.
+// only into -tags sim builds; the non-sim stub is a no-op. /sim/tags presses
+// the simulated pushbuttons under sim.tags.
 func (h *Handlers) registerSimRoutes(r chi.Router) {
 	r.Get("/sim/status", h.apiSimStatus)
 	r.Post("/sim/speed", h.apiSimSetSpeed)
+	r.Post("/sim/tags", h.apiSimSetTag)
+}
+
+// apiSimSetTag sets a simulated tag ({"plc", "tag", "value"}) through the
+// normal PLC write path, so a plc_triggers binding sees it exactly as it
+// would a pushbutton. Writes to anything not under sim.tags are discarded by
+// the fake client.
+func (h *Handlers) apiSimSetTag(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		PLC   string `json:"plc"`
+		Tag   string `json:"tag"`
+		Value int64  `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.PLC == "" || body.Tag == "" {
+		http.Error(w, "plc, tag and value are required", http.StatusBadRequest)
+		return
+	}
+	if err := h.engine.PLCManager().WriteTagValue(r.Context(), body.PLC, body.Tag, body.Value); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "plc": body.PLC, "tag": body.Tag, "value": body.Value})
 }
 
 // apiSimStatus reports the edge sim-clock speed + simulated time.
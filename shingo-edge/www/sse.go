@@ -390,6 +390,13 @@ func (h *EventHub) SetupEngineListeners(eng *engine.Engine) {
 			if p, ok := evt.Payload.(engine.CATIDVerifyMismatchEvent); ok {
 				sseEvt = SSEEvent{Type: "changeover-verify-mismatch", Data: p}
 			}
+		case engine.EventScheduleChangeover:
+			// The schedule planner started (or refused to start) a changeover.
+			// The schedule page refreshes its adherence table; the changeover
+			// page picks the new changeover up from its own partial.
+			if p, ok := evt.Payload.(engine.ScheduleChangeoverEvent); ok {
+				sseEvt = SSEEvent{Type: "schedule-changeover", Data: p}
+			}
 		default:
 			return
 		}
@@ -1,4 +1,4 @@
-import { api, confirm, delegateActions, escapeHtml, navigateToProcess, toast } from '/static/js/shingoedge.js';
+import { api, confirm, delegateActions, escapeHtml, navigateToProcess, t, toast } from '/static/js/shingoedge.js';
 
 var processID = parseInt(document.getElementById('page-data').dataset.processId || '0', 10);
 
@@ -10,7 +10,7 @@ var processID = parseInt(document.getElementById('page-data').dataset.processId
 async function previewProcessChangeover() {
     var toStyleID = parseInt(document.getElementById('co-to-style').value || '0', 10);
     if (!toStyleID) {
-        toast('Select a target style', 'warning');
+        toast(t('changeover.select_target'), 'warning');
         return;
     }
     try {
@@ -19,7 +19,7 @@ async function previewProcessChangeover() {
         });
         renderChangeoverPreview(resp);
     } catch (e) {
-        toast('Preview failed: ' + e, 'error');
+        toast(t('changeover.preview_failed', { error: e }), 'error');
     }
 }
 
@@ -29,19 +29,19 @@ function renderChangeoverPreview(plan) {
     if (!body || !panel) return;
     var actions = (plan && plan.actions) || [];
     if (actions.length === 0) {
-        body.innerHTML = '<p style="color:var(--text-muted)">No node changes — target style matches current claims.</p>';
+        body.innerHTML = '<p style="color:var(--text-muted)">' + escapeHtml(t('changeover.no_changes')) + '</p>';
     } else {
         var esc = escapeHtml;
         var rows = actions.map(function(a) {
             var orderCell = function(spec) {
                 if (!spec) return '<span style="color:var(--text-muted)">&mdash;</span>';
                 if (spec.kind === 'complex') {
-                    var dest = spec.delivery_node || '(in-place)';
+                    var dest = spec.delivery_node || t('changeover.in_place');
                     var stepCount = Number(spec.step_count) || 0;
-                    return '<span class="mono">complex &rarr; ' + esc(dest) + '</span> <span style="color:var(--text-muted);font-size:0.8rem">(' + stepCount + ' steps' + (spec.auto_confirm ? ', auto' : '') + ')</span>';
+                    return '<span class="mono">' + esc(t('changeover.plan_complex', { node: dest })) + '</span> <span style="color:var(--text-muted);font-size:0.8rem">' + esc(t(spec.auto_confirm ? 'changeover.plan_steps_auto' : 'changeover.plan_steps', { n: stepCount })) + '</span>';
                 }
                 if (spec.kind === 'retrieve') {
-                    return '<span class="mono">retrieve ' + esc(spec.payload_code || '') + ' &rarr; ' + esc(spec.delivery_node || '') + '</span>';
+                    return '<span class="mono">' + esc(t('changeover.plan_retrieve', { payload: spec.payload_code || '', node: spec.delivery_node || '' })) + '</span>';
                 }
                 return '';
             };
@@ -54,7 +54,11 @@ function renderChangeoverPreview(plan) {
                 '<td>' + orderCell(a.evac_order) + '</td>' +
                 '</tr>';
         }).join('');
-        body.innerHTML = '<table class="table"><thead><tr><th>Node</th><th>Situation</th><th>Plan</th><th>Supply</th><th>Evac</th></tr></thead><tbody>' + rows + '</tbody></table>';
+        body.innerHTML = '<table class="table"><thead><tr>' +
+            ['common.node', 'changeover.situation', 'changeover.plan', 'changeover.supply', 'changeover.evac'].map(function(k) {
+                return '<th>' + esc(t(k)) + '</th>';
+            }).join('') +
+            '</tr></thead><tbody>' + rows + '</tbody></table>';
     }
     panel.style.display = '';
 }
@@ -62,7 +66,7 @@ function renderChangeoverPreview(plan) {
 async function startProcessChangeover() {
     var toStyleID = parseInt(document.getElementById('co-to-style').value || '0', 10);
     if (!toStyleID) {
-        toast('Select a target style', 'warning');
+        toast(t('changeover.select_target'), 'warning');
         return;
     }
     try {
@@ -72,22 +76,21 @@ async function startProcessChangeover() {
             notes: ''
         });
         if (co && co.awaiting_stock && co.awaiting_stock.length) {
-            toast('Changeover started — awaiting stock for: ' + co.awaiting_stock.join(', ') +
-                '. These supply orders will dispatch automatically once the bins are loaded and manifest-confirmed.', 'warning');
+            toast(t('changeover.awaiting_stock', { payloads: co.awaiting_stock.join(', ') }), 'warning');
         }
         htmx.trigger(document.body, 'refreshChangeover');
     } catch (e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
 async function cancelProcessChangeover() {
-    if (!await confirm('Cancel the active process changeover?')) return;
+    if (!await confirm(t('changeover.cancel_confirm'))) return;
     try {
         await api.post('/api/processes/' + processID + '/changeover/cancel', {});
         htmx.trigger(document.body, 'refreshChangeover');
     } catch (e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
@@ -96,7 +99,7 @@ async function completeCutover() {
         await api.post('/api/processes/' + processID + '/changeover/cutover', {});
         htmx.trigger(document.body, 'refreshChangeover');
     } catch (e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
@@ -105,7 +108,7 @@ async function switchStation(stationID) {
         await api.post('/api/processes/' + processID + '/changeover/switch-station/' + stationID, {});
         htmx.trigger(document.body, 'refreshChangeover');
     } catch (e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
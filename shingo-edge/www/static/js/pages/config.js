@@ -1,4 +1,4 @@
-import { api, delegateActions, escapeHtml, getFormData, prompt, toast } from '/static/js/shingoedge.js';
+import { api, confirm, delegateActions, escapeHtml, getFormData, prompt, toast } from '/static/js/shingoedge.js';
 
 function collectBrokers() {
     return Array.from(document.querySelectorAll('.broker-row')).map(function(row) {
@@ -9,6 +9,27 @@ function collectBrokers() {
     }).filter(Boolean);
 }
 
+// kafkaSecurity is the TLS and SASL half of the messaging form. The saved
+// SASL password is never sent to the page; a blank one keeps it.
+function kafkaSecurity() {
+    const data = getFormData('kafka-security-form');
+    return {
+        kafka_tls: {
+            enabled: data.tls_enabled,
+            ca_file: data.tls_ca_file,
+            cert_file: data.tls_cert_file,
+            key_file: data.tls_key_file,
+            server_name: data.tls_server_name,
+            insecure_skip_verify: data.tls_insecure_skip_verify
+        },
+        kafka_sasl: {
+            mechanism: data.sasl_mechanism,
+            username: data.sasl_username,
+            password: data.sasl_password
+        }
+    };
+}
+
 function addBrokerRow() {
     const row = document.createElement('div');
     row.className = 'broker-row';
@@ -43,7 +64,7 @@ async function testBroker(button) {
     }
     status.textContent = 'Testing...';
     try {
-        const res = await api.post('/api/config/kafka/test', { broker: host + ':' + port });
+        const res = await api.post('/api/config/kafka/test', Object.assign({ broker: host + ':' + port }, kafkaSecurity()));
         status.textContent = res.connected ? 'Connected' : (res.error || 'Failed');
     } catch (e) {
         status.textContent = String(e);
@@ -81,7 +102,7 @@ async function saveWarLink() {
 async function saveMessaging() {
     try {
         await Promise.all([
-            api.put('/api/config/messaging', { kafka_brokers: collectBrokers() }),
+            api.put('/api/config/messaging', Object.assign({ kafka_brokers: collectBrokers() }, kafkaSecurity())),
             api.put('/api/config/auto-confirm', { auto_confirm: document.getElementById('auto-confirm').checked })
         ]);
         toast('Messaging config saved', 'success');
@@ -94,16 +115,23 @@ function backupFormData() {
     return getFormData('backup-form');
 }
 
+// backupFingerprint covers every storage field, so changing any of them
+// (including switching backend) needs a fresh Test Connection.
 function backupFingerprint() {
     const data = backupFormData();
-    return JSON.stringify({
-        endpoint: data.endpoint || '',
-        bucket: data.bucket || '',
-        region: data.region || '',
-        access_key: data.access_key || '',
-        secret_key: data.secret_key || '',
-        use_path_style: !!data.use_path_style,
-        insecure_skip_tls_verify: !!data.insecure_skip_tls_verify
+    delete data.enabled;
+    delete data.schedule_interval;
+    delete data.keep_hourly;
+    delete data.keep_daily;
+    delete data.keep_weekly;
+    delete data.keep_monthly;
+    return JSON.stringify(data);
+}
+
+function showBackupStorage() {
+    const kind = document.getElementById('backup-storage').value;
+    document.querySelectorAll('[data-backup-storage]').forEach(function (el) {
+        el.style.display = el.dataset.backupStorage === kind ? 'grid' : 'none';
     });
 }
 
@@ -193,6 +221,8 @@ async function loadBackupStatus() {
         if (status.last_success_at) lines.push('<div><strong>Last Success:</strong> ' + formatMaybeDate(status.last_success_at) + '</div>');
         if (status.last_failure_at) lines.push('<div><strong>Last Failure:</strong> ' + formatMaybeDate(status.last_failure_at) + '</div>');
         if (status.next_scheduled_at) lines.push('<div><strong>Next Scheduled Run:</strong> ' + formatMaybeDate(status.next_scheduled_at) + '</div>');
+        lines.push('<div><strong>Archives:</strong> ' + (status.encrypted ? 'Encrypted and signed' : 'Signed, not encrypted') + '</div>');
+        if (status.signing_key) lines.push('<div><strong>Signing Key:</strong> <code>' + escapeHtml(status.signing_key) + '</code></div>');
         document.getElementById('backup-status').innerHTML = lines.join('');
     } catch (e) {
         document.getElementById('backup-status').textContent = 'Backup status unavailable: ' + e;
@@ -207,7 +237,18 @@ async function stageRestore(key) {
     }
     try {
         setBackupOperationStatus('Downloading and staging restore archive...', 'busy');
-        await api.post('/api/backups/restore', { key: key });
+        try {
+            await api.post('/api/backups/restore', { key: key });
+        } catch (e) {
+            // Unsigned, unknown-signer or tampered archives are refused
+            // until the operator overrides for this one restore.
+            if (String(e).indexOf('signature not verified') < 0) throw e;
+            if (!await confirm(String(e) + '. Restore it anyway?')) {
+                setBackupOperationStatus('Restore cancelled: ' + e, 'error');
+                return;
+            }
+            await api.post('/api/backups/restore', { key: key, allow_unsigned: true });
+        }
         setBackupOperationStatus('Restore staged successfully. Restart shingo-edge to apply it.', 'ok');
         toast('Restore staged. Restart shingo-edge to apply it.', 'warning');
         await loadBackupStatus();
@@ -269,6 +310,19 @@ async function changePassword() {
     }
 }
 
+// saveLanguage stores the signed-in admin's page language and reloads, since
+// every string on the page was rendered server-side in the old one.
+async function saveLanguage() {
+    try {
+        await api.put('/api/config/language', {
+            language: document.getElementById('user-language').value
+        });
+        location.reload();
+    } catch (e) {
+        toast('Error: ' + e, 'error');
+    }
+}
+
 // --- Core API ---
 
 async function saveCoreAPI() {
@@ -331,6 +385,7 @@ async function syncPayloadCatalog() {
     setTimeout(function () { btn.disabled = false; }, 1500);
 }
 
+showBackupStorage();
 loadBackupStatus();
 loadBackups();
 
@@ -356,10 +411,12 @@ delegateActions(document.body, {
     saveBackupConfig,
     saveCoreAPI,
     saveIdentity,
+    saveLanguage,
     saveMessaging,
     saveWarLink,
     setBackupConnectionStatus,
     setBackupOperationStatus,
+    showBackupStorage,
     stageRestore,
     syncCoreNodes,
     syncPayloadCatalog,
@@ -1,4 +1,4 @@
-import { api, delegateActions, escapeHtml, hideModal, navigateToProcess, prompt, showModal, toast } from '/static/js/shingoedge.js';
+import { api, delegateActions, escapeHtml, hideModal, navigateToProcess, prompt, showModal, t, toast } from '/static/js/shingoedge.js';
 
 // Material page — operator-facing actions for the per-process node grid.
 //
@@ -16,27 +16,24 @@ import { api, delegateActions, escapeHtml, hideModal, navigateToProcess, prompt,
 // → manifest preserved with that count.
 async function releaseNodeWithPrompt(nodeID) {
     var input = await prompt(
-        'How many parts remain in this bin?\n\n' +
-        'Enter 0 (or leave blank) to release as EMPTY (manifest cleared).\n' +
-        'Enter a positive number to release as PARTIAL (manifest preserved\n' +
-        'with that count).',
+        t('release.remaining_prompt'),
         { type: 'number', min: 0 }
     );
     if (input === null) return; // operator cancelled
     var trimmed = String(input).trim();
     var partial = trimmed === '' ? 0 : Number(trimmed);
     if (!Number.isInteger(partial) || partial < 0) {
-        toast('Invalid count: enter 0, blank, or a positive whole number', 'error');
+        toast(t('release.invalid_count'), 'error');
         return;
     }
     try {
         await api.post('/api/process-nodes/' + nodeID + '/release-empty',
             { partial_count: partial });
         toast(partial > 0
-            ? 'Released — partial (' + partial + ' parts preserved)'
-            : 'Released — empty (manifest cleared)', 'success');
+            ? t('material.released_partial', { n: partial })
+            : t('material.released_empty'), 'success');
     } catch(e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
@@ -46,20 +43,20 @@ function viewBinContents() {
     var binState = {};
     try { binState = JSON.parse(this.dataset.binState || '{}') || {}; }
     catch (e) { binState = {}; }
-    document.getElementById('view-bin-title').textContent = 'Bin: ' + (binState.bin_label || 'Unknown');
+    document.getElementById('view-bin-title').textContent = t('material.bin_title', { bin: binState.bin_label || t('common.unknown') });
     var body = document.getElementById('view-bin-body');
     var html = '<div style="display:grid;grid-template-columns:1fr 1fr;gap:0.5rem;margin-bottom:1rem">';
-    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">Payload</div><strong>' + escapeHtml(binState.payload_code || 'empty') + '</strong></div>';
-    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">UoP Remaining</div><strong>' + (binState.uop_remaining || 0) + '</strong></div>';
-    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">Bin Type</div>' + escapeHtml(binState.bin_type_code || '-') + '</div>';
-    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">Confirmed</div>' + (binState.manifest_confirmed ? 'Yes' : 'No') + '</div>';
+    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">' + escapeHtml(t('common.payload')) + '</div><strong>' + escapeHtml(binState.payload_code || t('material.empty')) + '</strong></div>';
+    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">' + escapeHtml(t('material.uop_remaining')) + '</div><strong>' + (binState.uop_remaining || 0) + '</strong></div>';
+    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">' + escapeHtml(t('material.bin_type')) + '</div>' + escapeHtml(binState.bin_type_code || '-') + '</div>';
+    html += '<div><div style="color:var(--text-muted);font-size:0.8rem">' + escapeHtml(t('material.confirmed')) + '</div>' + escapeHtml(t(binState.manifest_confirmed ? 'common.yes' : 'common.no')) + '</div>';
     html += '</div>';
     if (binState.manifest) {
         try {
             var manifest = typeof binState.manifest === 'string' ? JSON.parse(binState.manifest) : binState.manifest;
             var items = manifest.items || [];
             if (items.length > 0) {
-                html += '<table class="table" style="font-size:0.85rem"><thead><tr><th>Part</th><th>Qty</th></tr></thead><tbody>';
+                html += '<table class="table" style="font-size:0.85rem"><thead><tr><th>' + escapeHtml(t('material.part')) + '</th><th>' + escapeHtml(t('material.qty')) + '</th></tr></thead><tbody>';
                 items.forEach(function(item) {
                     html += '<tr><td>' + escapeHtml(item.catid || item.part_number || '') + '</td><td>' + (item.qty || item.quantity || 0) + '</td></tr>';
                 });
@@ -94,13 +91,13 @@ function openRequestEmptyModal() {
 async function submitRequestEmpty() {
     var nodeID = parseInt(document.getElementById('re-node-id').value, 10);
     var payloadCode = document.getElementById('re-payload').value;
-    if (!payloadCode) { toast('Select a payload', 'warning'); return; }
+    if (!payloadCode) { toast(t('loadbin.select_payload'), 'warning'); return; }
     try {
         await api.post('/api/process-nodes/' + nodeID + '/request-empty', {payload_code: payloadCode});
         hideModal('request-empty-modal');
-        toast('Empty bin requested', 'success');
+        toast(t('material.empty_requested'), 'success');
     } catch(e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
@@ -129,7 +126,7 @@ async function openLoadBinModal() {
     document.getElementById('rb-payload-code').value = '';
     var catalog = await ensureLoadBinCatalog();
     var sel = document.getElementById('rb-payload');
-    sel.innerHTML = '<option value="">-- Select payload --</option>';
+    sel.innerHTML = '<option value="">' + escapeHtml(t('material.select_payload_option')) + '</option>';
     (allowedCodes || []).forEach(function(code) {
         var entry = catalog.find(function(p) { return p.code === code; });
         var opt = document.createElement('option');
@@ -137,7 +134,7 @@ async function openLoadBinModal() {
         opt.textContent = code + (entry && entry.name ? ' — ' + entry.name : '');
         sel.appendChild(opt);
     });
-    document.getElementById('rb-manifest-rows').innerHTML = '<div style="color:var(--text-muted);font-style:italic;padding:0.5rem 0">Select a payload to see its manifest.</div>';
+    document.getElementById('rb-manifest-rows').innerHTML = '<div style="color:var(--text-muted);font-style:italic;padding:0.5rem 0">' + escapeHtml(t('material.select_payload_manifest')) + '</div>';
     showModal('load-bin-modal');
 }
 
@@ -146,22 +143,22 @@ async function onLoadPayloadChanged() {
     document.getElementById('rb-payload-code').value = code;
     var rows = document.getElementById('rb-manifest-rows');
     if (!code) {
-        rows.innerHTML = '<div style="color:var(--text-muted);font-style:italic;padding:0.5rem 0">Select a payload to see its manifest.</div>';
+        rows.innerHTML = '<div style="color:var(--text-muted);font-style:italic;padding:0.5rem 0">' + escapeHtml(t('material.select_payload_manifest')) + '</div>';
         return;
     }
-    rows.innerHTML = '<div style="color:var(--text-muted);padding:0.5rem 0">Loading manifest...</div>';
+    rows.innerHTML = '<div style="color:var(--text-muted);padding:0.5rem 0">' + escapeHtml(t('loadbin.loading_manifest')) + '</div>';
     try {
         var data = await api.get('/api/payload/' + encodeURIComponent(code) + '/manifest');
         var items = (data && data.items) || [];
         var uopCapacity = (data && data.uop_capacity) || 0;
         rows.innerHTML = '';
         if (items.length === 0) {
-            rows.innerHTML = '<div style="color:var(--text-muted);font-style:italic;padding:0.5rem 0">No manifest template for this payload.</div>';
+            rows.innerHTML = '<div style="color:var(--text-muted);font-style:italic;padding:0.5rem 0">' + escapeHtml(t('loadbin.no_manifest_template')) + '</div>';
             return;
         }
         var uopRow = document.createElement('div');
         uopRow.style.cssText = 'display:grid;grid-template-columns:1fr 80px;gap:0.5rem;align-items:center;margin-bottom:0.75rem;padding:0.5rem;border:2px solid var(--primary, #4a9);border-radius:4px';
-        uopRow.innerHTML = '<div style="font-weight:600">UoP Count</div>' +
+        uopRow.innerHTML = '<div style="font-weight:600">' + escapeHtml(t('loadbin.uop_count')) + '</div>' +
             '<input type="number" id="rb-uop-count" class="form-input" value="' + uopCapacity + '" style="text-align:center;font-weight:600">';
         rows.appendChild(uopRow);
         items.forEach(function(item) {
@@ -176,7 +173,7 @@ async function onLoadPayloadChanged() {
             rows.appendChild(row);
         });
     } catch(e) {
-        rows.innerHTML = '<div style="color:var(--danger, red);padding:0.5rem 0">Failed to load manifest.</div>';
+        rows.innerHTML = '<div style="color:var(--danger, red);padding:0.5rem 0">' + escapeHtml(t('material.manifest_failed')) + '</div>';
     }
 }
 
@@ -187,20 +184,20 @@ function closeLoadBinModal() {
 async function submitLoadBin() {
     var nodeID = parseInt(document.getElementById('rb-node-id').value, 10);
     var payloadCode = document.getElementById('rb-payload-code').value;
-    if (!payloadCode) { toast('Select a payload first', 'warning'); return; }
+    if (!payloadCode) { toast(t('loadbin.select_payload'), 'warning'); return; }
     var manifest = [];
     document.querySelectorAll('.rb-manifest-qty').forEach(function(input) {
         var qty = parseInt(input.value, 10) || 0;
         if (qty > 0) manifest.push({part_number: input.dataset.part, quantity: qty, description: input.dataset.desc || ''});
     });
-    if (manifest.length === 0) { toast('Enter at least one quantity', 'warning'); return; }
+    if (manifest.length === 0) { toast(t('material.enter_quantity'), 'warning'); return; }
     try {
         var uopCount = parseInt((document.getElementById('rb-uop-count') || {}).value || '0', 10);
         await api.post('/api/process-nodes/' + nodeID + '/load-bin', {payload_code: payloadCode, uop_count: uopCount, manifest: manifest});
         closeLoadBinModal();
-        toast('Bin loaded', 'success');
+        toast(t('loadbin.loaded'), 'success');
     } catch(e) {
-        toast('Error: ' + e, 'error');
+        toast(t('common.error', { error: e }), 'error');
     }
 }
 
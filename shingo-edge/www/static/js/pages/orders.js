@@ -1,4 +1,4 @@
-import { api, confirm, delegateActions, navigateToProcessOrOrders, prompt, toast } from '/static/js/shingoedge.js';
+import { api, confirm, delegateActions, navigateToProcessOrOrders, prompt, t, toast } from '/static/js/shingoedge.js';
 
 // Order actions that need JSON bodies or confirm dialogs.
 // SSE auto-refresh and HX-Trigger-based refresh are handled by htmx.
@@ -11,17 +11,17 @@ async function confirmDelivery(orderID, qty) {
     var n = parseInt(qty, 10) || 0;
     try {
         await api.post('/api/confirm-delivery/' + orderID, { final_count: n });
-        toast('Delivery confirmed', 'success');
+        toast(t('orders.delivery_confirmed'), 'success');
         htmx.trigger(document.body, 'refreshOrders');
-    } catch (e) { toast('Error: ' + e, 'error'); }
+    } catch (e) { toast(t('common.error', { error: e }), 'error'); }
 }
 
 async function submitOrder(orderID) {
     try {
         await api.post('/api/orders/' + orderID + '/submit', {});
-        toast('Order submitted', 'success');
+        toast(t('orders.submitted'), 'success');
         htmx.trigger(document.body, 'refreshOrders');
-    } catch (e) { toast('Error: ' + e, 'error'); }
+    } catch (e) { toast(t('common.error', { error: e }), 'error'); }
 }
 
 async function releaseOrder(orderID) {
@@ -46,19 +46,14 @@ async function releaseOrder(orderID) {
     // record per-part quantities — this prompt only handles the bin's
     // remaining total, not per-part captures.
     const input = await prompt(
-        'How many parts remain in this bin?\n\n' +
-        'Enter 0 (or leave blank) to release as EMPTY (manifest cleared).\n' +
-        'Enter a positive number to release as PARTIAL (manifest preserved\n' +
-        'with that count).\n\n' +
-        'If you pulled parts to lineside during the swap, cancel and use\n' +
-        'the operator station to record per-part captures.',
+        t('release.remaining_prompt') + '\n\n' + t('release.remaining_prompt_lineside'),
         { type: 'number', min: 0 }
     );
     if (input === null) return; // operator cancelled
     const trimmed = String(input).trim();
     const partial = trimmed === '' ? 0 : Number(trimmed);
     if (!Number.isInteger(partial) || partial < 0) {
-        toast('Invalid count: enter 0, blank, or a positive whole number', 'error');
+        toast(t('release.invalid_count'), 'error');
         return;
     }
     const body = partial > 0
@@ -67,19 +62,19 @@ async function releaseOrder(orderID) {
     try {
         await api.post('/api/orders/' + orderID + '/release', body);
         toast(partial > 0
-            ? 'Order released — partial (' + partial + ' parts preserved)'
-            : 'Order released — empty (manifest cleared)', 'success');
+            ? t('orders.released_partial', { n: partial })
+            : t('orders.released_empty'), 'success');
         htmx.trigger(document.body, 'refreshOrders');
-    } catch (e) { toast('Error: ' + e, 'error'); }
+    } catch (e) { toast(t('common.error', { error: e }), 'error'); }
 }
 
 async function abortOrder(orderID) {
-    if (!await confirm('Abort this order?')) return;
+    if (!await confirm(t('orders.abort_confirm'))) return;
     try {
         await api.post('/api/orders/' + orderID + '/abort', {});
-        toast('Order aborted', 'success');
+        toast(t('orders.aborted'), 'success');
         htmx.trigger(document.body, 'refreshOrders');
-    } catch (e) { toast('Error: ' + e, 'error'); }
+    } catch (e) { toast(t('common.error', { error: e }), 'error'); }
 }
 
 // Staged-expiry and fault clocks tick from shared installLiveDurations
@@ -206,6 +206,7 @@ function buildDOM() {
     add('station-id', { tag: 'input', type: 'hidden' });
     add('station-name', { tag: 'input' });
     add('station-note', { tag: 'textarea' });
+    add('station-language', { tag: 'select', value: '' });
     add('station-enabled', { tag: 'input', type: 'checkbox' });
     add('station-modal-title');
 
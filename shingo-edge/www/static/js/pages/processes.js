@@ -1304,6 +1304,7 @@ function resetStationForm() {
     document.getElementById('station-id').value = '';
     document.getElementById('station-name').value = '';
     document.getElementById('station-note').value = '';
+    document.getElementById('station-language').value = '';
     document.getElementById('station-enabled').checked = true;
     resetNodePicker([]);
 }
@@ -1356,6 +1357,7 @@ async function editStation() {
     document.getElementById('station-id').value = station.id;
     document.getElementById('station-name').value = station.name || '';
     document.getElementById('station-note').value = station.note || '';
+    document.getElementById('station-language').value = station.language || '';
     document.getElementById('station-enabled').checked = !!station.enabled;
     // Load claimed nodes for this station
     try {
@@ -1381,7 +1383,8 @@ async function saveStation() {
         sequence: 0,
         controller_node_id: '',
         enabled: document.getElementById('station-enabled').checked,
-        device_mode: 'fixed_hmi'
+        device_mode: 'fixed_hmi',
+        language: document.getElementById('station-language').value
     };
     if (!payload.name) {
         toast('Station name is required', 'warning');
@@ -0,0 +1,153 @@
+import { createSSE, delegateActions, escapeHtml, toast } from '/static/js/shingoedge.js';
+
+var _pd = document.getElementById('page-data').dataset;
+var _adherence = JSON.parse(_pd.adherence);
+var _authenticated = _pd.authenticated === 'true';
+var _currentDate = _pd.date;
+
+function fmtTime(s) {
+    if (!s) return '';
+    var d = new Date(s);
+    return String(d.getHours()).padStart(2, '0') + ':' + String(d.getMinutes()).padStart(2, '0');
+}
+
+function fmtPct(v) {
+    if (v === undefined || v === null) return '';
+    return Math.round(v * 100) + '%';
+}
+
+function renderRun(run) {
+    var planned = run.planned_qty ? run.planned_qty : '';
+    var window = fmtTime(run.planned_start) + (run.planned_end ? ' – ' + fmtTime(run.planned_end) : '');
+    var delay = '';
+    if (run.start_delay_minutes !== undefined && run.start_delay_minutes !== null) {
+        var m = Math.round(run.start_delay_minutes);
+        delay = (m > 0 ? '+' : '') + m + ' min';
+    }
+    var html = '<tr>';
+    html += '<td>' + run.seq + '</td>';
+    html += '<td><strong>' + escapeHtml(run.style_name) + '</strong>';
+    if (run.external_ref) html += '<br><span class="mono" style="font-size:0.75rem">' + escapeHtml(run.external_ref) + '</span>';
+    html += '</td>';
+    html += '<td><span class="status-badge">' + escapeHtml(run.status) + '</span></td>';
+    html += '<td>' + escapeHtml(window) + '</td>';
+    html += '<td>' + escapeHtml(fmtTime(run.actual_start)) + '</td>';
+    html += '<td style="text-align:right">' + (run.on_time ? '' : '<span style="color:var(--danger)">') + escapeHtml(delay) + (run.on_time ? '' : '</span>') + '</td>';
+    html += '<td style="text-align:right">' + run.produced_qty + (planned ? ' / ' + planned : '') + '</td>';
+    html += '<td style="text-align:right">' + fmtPct(run.attainment) + '</td>';
+    html += '<td>';
+    if (_authenticated && run.status === 'planned') {
+        html += '<button class="btn btn-sm" data-action="deleteRun:' + run.id + '">Remove</button>';
+    }
+    html += '</td></tr>';
+    return html;
+}
+
+function renderAdherence() {
+    var root = document.getElementById('schedule-processes');
+    if (!_adherence || _adherence.length === 0) {
+        root.innerHTML = '<div class="card"><div class="card-body"><p class="empty-cell">No scheduled runs on this day.</p></div></div>';
+        return;
+    }
+    var html = '';
+    for (var i = 0; i < _adherence.length; i++) {
+        var p = _adherence[i];
+        html += '<div class="card" style="margin-bottom:1rem">';
+        html += '<div class="card-header"><strong>' + escapeHtml(p.process_name) + '</strong>';
+        html += '<span style="float:right;font-size:0.85rem">';
+        html += p.completed + ' of ' + p.planned + ' runs done';
+        if (p.skipped) html += ', ' + p.skipped + ' skipped';
+        html += ' · ' + p.on_time + ' on time';
+        if (p.attainment !== undefined && p.attainment !== null) html += ' · attainment ' + fmtPct(p.attainment);
+        html += '</span></div>';
+        html += '<div class="card-body" style="overflow-x:auto"><table class="table"><thead><tr>';
+        html += '<th>#</th><th>Style</th><th>Status</th><th>Planned</th><th>Started</th>';
+        html += '<th style="text-align:right">Start delay</th><th style="text-align:right">Produced</th><th style="text-align:right">Attainment</th><th></th>';
+        html += '</tr></thead><tbody>';
+        for (var j = 0; j < p.runs.length; j++) html += renderRun(p.runs[j]);
+        html += '</tbody></table></div></div>';
+    }
+    root.innerHTML = html;
+}
+
+function refresh() {
+    fetch('/api/schedule/adherence?date=' + encodeURIComponent(_currentDate))
+        .then(function(res) { return res.json(); })
+        .then(function(data) {
+            _adherence = data;
+            renderAdherence();
+        });
+}
+
+function onScheduleDateChange() {
+    window.location = '/schedule?date=' + document.getElementById('schedule-date').value;
+}
+
+function changeDate(offset) {
+    var d = new Date(_currentDate + 'T00:00:00');
+    d.setDate(d.getDate() + Number(offset));
+    var yyyy = d.getFullYear();
+    var mm = String(d.getMonth() + 1).padStart(2, '0');
+    var dd = String(d.getDate()).padStart(2, '0');
+    window.location = '/schedule?date=' + yyyy + '-' + mm + '-' + dd;
+}
+
+// The CSV goes up as the raw request body; the handler reads it as-is.
+function importSchedule() {
+    var input = document.getElementById('schedule-file');
+    if (!input.files || input.files.length === 0) {
+        toast('Choose a CSV file first', 'error');
+        return;
+    }
+    fetch('/api/schedule/import', {
+        method: 'POST',
+        headers: { 'Content-Type': 'text/csv' },
+        body: input.files[0]
+    }).then(function(res) {
+        return res.json().then(function(body) {
+            if (!res.ok) throw body.error || res.statusText;
+            return body;
+        });
+    }).then(function(body) {
+        var parts = [];
+        for (var name in body.imported) parts.push(name + ': ' + body.imported[name]);
+        toast('Imported ' + (parts.join(', ') || 'nothing'), 'success');
+        input.value = '';
+        refresh();
+    }).catch(function(err) {
+        toast('Import failed: ' + err, 'error');
+    });
+}
+
+function deleteRun(id) {
+    fetch('/api/schedule/runs/' + id, { method: 'DELETE' })
+        .then(function(res) {
+            if (!res.ok) return res.json().then(function(body) { throw body.error; });
+            refresh();
+        })
+        .catch(function(err) { toast('Remove failed: ' + err, 'error'); });
+}
+
+renderAdherence();
+
+createSSE('/events', {
+    onScheduleChangeover: function(data) {
+        if (data.changeover_id) {
+            toast('Schedule started changeover to ' + data.to_style_name, 'info');
+        } else {
+            toast('Schedule could not start ' + data.to_style_name + ': ' + (data.detail || data.reason), 'error');
+        }
+        refresh();
+    }
+});
+
+// Counts and cutovers move the table without a schedule event of their own;
+// a slow poll keeps produced quantities and statuses current.
+setInterval(refresh, 60000);
+
+delegateActions(document.body, {
+    changeDate: changeDate,
+    onScheduleDateChange: onScheduleDateChange,
+    importSchedule: importSchedule,
+    deleteRun: deleteRun
+}, { events: ['click', 'change'] });
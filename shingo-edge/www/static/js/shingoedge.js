@@ -27,6 +27,7 @@ import {
     installTableSort,
     convertTimestamps,
 } from '/static/shared/utils.js';
+import { t } from '/static/operator-station/operator-util.js';
 installBackdropClose();
 installHtmxTimestampConversion();
 // Live elapsed/countdown spans, re-armed after htmx swaps. The interval only
@@ -154,10 +155,10 @@ export function confirm(message) {
         box.innerHTML = '<p>' + escapeHtml(message) + '</p>';
         var cancelBtn = document.createElement('button');
         cancelBtn.className = 'btn';
-        cancelBtn.textContent = 'Cancel';
+        cancelBtn.textContent = t('action.cancel');
         var confirmBtn = document.createElement('button');
         confirmBtn.className = 'btn btn-danger';
-        confirmBtn.textContent = 'Confirm';
+        confirmBtn.textContent = t('action.confirm');
         box.appendChild(cancelBtn);
         box.appendChild(confirmBtn);
         overlay.appendChild(box);
@@ -189,10 +190,10 @@ export function prompt(message, opts) {
         box.appendChild(input);
         var cancelBtn = document.createElement('button');
         cancelBtn.className = 'btn';
-        cancelBtn.textContent = 'Cancel';
+        cancelBtn.textContent = t('action.cancel');
         var okBtn = document.createElement('button');
         okBtn.className = 'btn btn-primary';
-        okBtn.textContent = 'OK';
+        okBtn.textContent = t('action.ok');
         box.appendChild(cancelBtn);
         box.appendChild(okBtn);
         overlay.appendChild(box);
@@ -458,6 +459,11 @@ export function navigateToProcessOrOrders(el) {
 // '/static/js/shingoedge.js'` sites don't need to change paths.
 export { delegateActions } from '/static/shared/utils.js';
 
+// --- t: the station catalog ---
+// t renders a catalog message in the page's language; header.html embeds
+// the catalog as #os-messages. Same function the operator station uses.
+export { t };
+
 // --- window.ShingoEdge for non-module consumers ---
 // One remaining non-module consumer still reaches for these as bare
 // globals on the window:
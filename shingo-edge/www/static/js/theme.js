@@ -19,15 +19,18 @@
     var btn = document.querySelector('.theme-toggle');
     if (!btn) return;
     var stored = getStoredTheme();
+    // The titles come from the button's data-title-* attributes, which the
+    // server rendered in the page's language; the English is for a page
+    // that sets none.
     if (stored === 'dark') {
       btn.textContent = '\u263D'; // moon
-      btn.title = 'Theme: dark (click for system)';
+      btn.title = btn.dataset.titleDark || 'Theme: dark (click for system)';
     } else if (stored === 'light') {
       btn.textContent = '\u2600'; // sun
-      btn.title = 'Theme: light (click for dark)';
+      btn.title = btn.dataset.titleLight || 'Theme: light (click for dark)';
     } else {
       btn.textContent = '\u25D0'; // half-circle (system)
-      btn.title = 'Theme: system (click for light)';
+      btn.title = btn.dataset.titleSystem || 'Theme: system (click for light)';
     }
   }
 
@@ -1,4 +1,4 @@
-import { postAction } from './operator-util.js';
+import { postAction, t } from './operator-util.js';
 
 const keypadModal = document.getElementById('keypad-modal');
 const keypadDisplay = document.getElementById('keypad-display');
@@ -15,7 +15,7 @@ export function openKeypad(nodeID, remaining, opts) {
     onKeypadOk = opts.onOk || null;
     onKeypadCancel = opts.onCancel || null;
     if (keypadTitle) {
-        keypadTitle.textContent = opts.title || 'Enter Remaining Quantity';
+        keypadTitle.textContent = opts.title || t('operator.keypad_title');
     }
     keypadDisplay.textContent = initial;
     keypadModal.classList.add('active');
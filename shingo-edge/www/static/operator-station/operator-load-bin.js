@@ -1,4 +1,4 @@
-import { esc, postAction, showToast, fetchWithTimeout } from './operator-util.js';
+import { esc, postAction, showToast, fetchWithTimeout, t } from './operator-util.js';
 import { openKeypad } from './operator-keypad.js';
 import { findNodeByID } from './operator-state.js';
 import {
@@ -22,7 +22,7 @@ export function openLoadBin(nodeID, allowedCodes, defaultCapacity) {
     const payloadEl = document.getElementById('load-bin-payload');
     payloadEl.innerHTML = '';
     const rows = document.getElementById('load-bin-rows');
-    rows.innerHTML = '<div style="color:#999;text-align:center;padding:12px">Select a payload above</div>';
+    rows.innerHTML = '<div style="color:#999;text-align:center;padding:12px">' + esc(t('loadbin.select_payload_above')) + '</div>';
     (allowedCodes || []).forEach(function(code) {
         const btn = document.createElement('button');
         btn.type = 'button';
@@ -77,7 +77,7 @@ async function selectLoadPayload(code) {
     });
     syncRefusalControl();
     const rows = document.getElementById('load-bin-rows');
-    rows.innerHTML = '<div style="color:#999;text-align:center;padding:12px">Loading manifest...</div>';
+    rows.innerHTML = '<div style="color:#999;text-align:center;padding:12px">' + esc(t('loadbin.loading_manifest')) + '</div>';
     // Bound the fetch (fetchWithTimeout): a browser fetch has no default timeout,
     // so a silently severed connection (edge restart mid-request, Wi-Fi blip,
     // half-open TCP, Core unreachable during a reboot) would leave the modal stuck
@@ -106,7 +106,7 @@ async function selectLoadPayload(code) {
             };
         });
         if (items.length === 0) {
-            rows.innerHTML = '<div style="color:#f66;padding:8px">No manifest template for this payload</div>';
+            rows.innerHTML = '<div style="color:#f66;padding:8px">' + esc(t('loadbin.no_manifest_template')) + '</div>';
             return;
         }
         renderRows();
@@ -114,9 +114,9 @@ async function selectLoadPayload(code) {
         console.error('selectLoadPayload manifest fetch', err);
         if (!loadBinState || loadBinState.payloadCode !== code) return;
         const msg = err && err.name === 'AbortError'
-            ? 'Manifest timed out — tap the payload again to retry'
-            : 'Failed to load manifest — tap the payload again to retry';
-        rows.innerHTML = '<div style="color:#f66;padding:8px">' + msg + '</div>';
+            ? t('loadbin.manifest_timeout')
+            : t('loadbin.manifest_failed');
+        rows.innerHTML = '<div style="color:#f66;padding:8px">' + esc(msg) + '</div>';
     }
 }
 
@@ -131,7 +131,7 @@ function renderRows() {
     const uopRow = document.createElement('div');
     uopRow.style.cssText = 'display:grid;grid-template-columns:1fr auto;gap:12px;align-items:center;margin-bottom:12px;padding:10px;background:#1a2a1a;border-radius:6px;border:1px solid #2a4a2a';
     uopRow.innerHTML =
-        '<div style="font-size:16px;font-weight:600;color:#fff">UoP Count</div>' +
+        '<div style="font-size:16px;font-weight:600;color:#fff">' + esc(t('loadbin.uop_count')) + '</div>' +
         '<button type="button" id="os-load-uop-display" ' +
             'style="min-width:120px;background:#0f141a;border:1px solid var(--os-border);' +
             'border-radius:8px;padding:10px 16px;color:#fff;font-size:24px;font-weight:700;' +
@@ -164,7 +164,7 @@ function openUopKeypad() {
     const state = loadBinState;
     if (!state || state.submitting) return;
     openKeypad(0, state.uopCount, {
-        title: 'UoP Count',
+        title: t('loadbin.uop_count'),
         onOk: function(_nodeID, qty) {
             state.uopCount = qty > 0 ? qty : 0;
             renderRows();
@@ -183,7 +183,7 @@ function setSubmittingUI(submitting) {
     const cancel = document.getElementById('load-bin-cancel');
     if (submit) {
         submit.disabled = submitting;
-        submit.textContent = submitting ? 'LOADING...' : 'CONFIRM LOAD';
+        submit.textContent = submitting ? t('loadbin.loading_caps') : t('operator.confirm_load_caps');
         submit.style.opacity = submitting ? '0.6' : '';
     }
     if (cancel) cancel.disabled = submitting;
@@ -195,15 +195,15 @@ async function submitLoadBin() {
     const state = loadBinState;
     if (!state || state.submitting) return;
     if (!state.payloadCode) {
-        showToast('Select a payload first', 'error');
+        showToast(t('loadbin.select_payload'), 'error');
         return;
     }
     if (state.manifest.length === 0) {
-        showToast('No manifest loaded', 'error');
+        showToast(t('loadbin.no_manifest'), 'error');
         return;
     }
     if (state.uopCount <= 0) {
-        showToast('Set UoP Count first', 'error');
+        showToast(t('loadbin.set_uop'), 'error');
         return;
     }
     const body = {
@@ -215,7 +215,7 @@ async function submitLoadBin() {
     setSubmittingUI(true);
     const ok = await postAction('/api/process-nodes/' + nodeID + '/load-bin', body, loadViewRef);
     if (ok) {
-        showToast('Bin loaded', 'success');
+        showToast(t('loadbin.loaded'), 'success');
         closeLoadBin();
     } else {
         // postAction already toasted the server error — re-enable so the
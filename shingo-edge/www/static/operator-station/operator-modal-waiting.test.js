@@ -32,7 +32,7 @@ function eq(got, want, label) {
 // Extract a top-level `function NAME(...) {...}` block by brace matching, so the
 // test exercises the SHIPPING source rather than a copy that can drift.
 // operator-modal.js can't be loaded wholesale — it imports and touches the DOM
-// at module scope — but waitingLabel is pure apart from formatETA.
+// at module scope — but waitingLabel is pure apart from formatETA and t.
 function extractFn(src, name) {
     const start = src.indexOf('function ' + name + '(');
     if (start < 0) throw new Error('function ' + name + ' not found');
@@ -57,9 +57,15 @@ const here = __dirname;
 const utilSrc = fs.readFileSync(path.join(here, 'operator-util.js'), 'utf8')
     .replace(/^export\s+/gm, '');
 const modalSrc = fs.readFileSync(path.join(here, 'operator-modal.js'), 'utf8');
+// The English catalog the page would embed as #os-messages, so t() reads as the
+// station does in English.
+const catalog = fs.readFileSync(path.join(here, '..', '..', '..', 'locales', 'en.json'), 'utf8');
 
 const ctx = vm.createContext({
-    document: { body: { dataset: { stationId: '1' } }, getElementById: () => null },
+    document: {
+        body: { dataset: { stationId: '1' } },
+        getElementById: (id) => (id === 'os-messages' ? { textContent: catalog } : null),
+    },
     console: console,
 });
 vm.runInContext(utilSrc, ctx);
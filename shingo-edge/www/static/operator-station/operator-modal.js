@@ -1,4 +1,4 @@
-import { esc, fillColor, postAction, formatETA } from './operator-util.js';
+import { esc, fillColor, postAction, formatETA, t } from './operator-util.js';
 import {
     confirmRefuseSupply, confirmUndoSupplyRefusal, REFUSE_LABEL, UNDO_LABEL,
 } from './operator-supply-refusal.js';
@@ -126,17 +126,17 @@ export function renderModal(entry) {
 
     if (claim && claim.swap_mode === 'manual_swap') {
         const binState = entry.bin_state;
-        const binLabel = binState && binState.bin_label ? binState.bin_label : 'No bin';
+        const binLabel = binState && binState.bin_label ? binState.bin_label : t('modal.no_bin');
         const binPayload = binState && binState.payload_code ? binState.payload_code : '';
-        const roleLabel = claim.role === 'produce' ? 'Loader' : 'Unloader';
-        html += '<div class="modal-payload">' + roleLabel + ' - Bin: ' + esc(binLabel) + (binPayload ? ' (' + esc(binPayload) + ')' : '') + '</div>';
+        const roleLabel = claim.role === 'produce' ? t('modal.loader') : t('modal.unloader');
+        html += '<div class="modal-payload">' + esc(t('modal.role_bin', { role: roleLabel, bin: binLabel })) + (binPayload ? ' (' + esc(binPayload) + ')' : '') + '</div>';
         html += '<div class="modal-fill-row">';
-        html += '<div class="modal-fill-text" style="font-size:18px;font-weight:600">' + (remaining > 0 ? 'LOADED (' + remaining + ' UoP)' : 'EMPTY') + '</div>';
+        html += '<div class="modal-fill-text" style="font-size:18px;font-weight:600">' + esc(remaining > 0 ? t('modal.loaded_uop', { n: remaining }) : t('window.empty')) + '</div>';
         html += '</div>';
     } else {
         const binState = entry.bin_state;
-        const binLabel = binState && binState.bin_label ? ' - Bin: ' + esc(binState.bin_label) : '';
-        html += '<div class="modal-payload">' + esc(claim ? claim.payload_code || 'Unassigned' : 'No claim') + binLabel + '</div>';
+        const binLabel = binState && binState.bin_label ? esc(t('modal.bin_suffix', { bin: binState.bin_label })) : '';
+        html += '<div class="modal-payload">' + esc(claim ? claim.payload_code || t('modal.unassigned') : t('material.no_claim')) + binLabel + '</div>';
         html += '<div class="modal-fill-row">';
         html += '<div class="modal-fill-bar"><div class="modal-fill-level" style="width:' + Math.round(pct * 100) + '%;background:' + fillColor(pct, remaining) + '"></div></div>';
         html += '<div class="modal-fill-text">' + remaining + ' / ' + capacity + '</div>';
@@ -146,7 +146,7 @@ export function renderModal(entry) {
         const activeBuckets = entry.lineside_active || [];
         if (activeBuckets.length > 0) {
             html += '<div class="os-lineside-active-row">';
-            html += '<div class="os-lineside-label">Lineside</div>';
+            html += '<div class="os-lineside-label">' + esc(t('modal.lineside')) + '</div>';
             html += '<div class="os-lineside-chips">';
             activeBuckets.forEach(function(b) {
                 html += '<span class="os-lineside-chip active">' +
@@ -159,7 +159,7 @@ export function renderModal(entry) {
         const strandedBuckets = entry.lineside_inactive || [];
         if (strandedBuckets.length > 0) {
             html += '<div class="os-lineside-stranded-row">';
-            html += '<div class="os-lineside-label stranded">Stranded</div>';
+            html += '<div class="os-lineside-label stranded">' + esc(t('modal.stranded')) + '</div>';
             html += '<div class="os-lineside-chips">';
             strandedBuckets.forEach(function(b) {
                 html += '<button type="button" class="os-lineside-chip stranded" ' +
@@ -174,14 +174,15 @@ export function renderModal(entry) {
         const activeOrders = (entry.orders || []).filter(o => isActive(o.status));
         const statusText = activeOrders.length > 0
             ? activeOrders.map(o => o.order_type + ': ' + o.status).join(', ')
-            : 'Order in progress';
-        html += '<div class="modal-status">[REP] ' + esc(statusText) + '</div>';
+            : t('modal.order_in_progress');
+        html += '<div class="modal-status">' + esc(t('modal.rep_tag')) + ' ' + esc(statusText) + '</div>';
     } else {
-        html += '<div class="modal-status">No active orders</div>';
+        html += '<div class="modal-status">' + esc(t('orders.none_active')) + '</div>';
     }
 
     if (task) {
-        html += '<div class="modal-co-info">[CO] Changeover: ' + esc(task.situation) + ' - ' + esc(task.state) + '</div>';
+        html += '<div class="modal-co-info">' + esc(t('modal.co_tag')) + ' ' +
+            esc(t('modal.changeover_info', { situation: task.situation, state: task.state })) + '</div>';
     }
     html += '</div>'; // close header
 
@@ -195,7 +196,7 @@ export function renderModal(entry) {
             'margin:8px 0;padding:10px 14px;border-radius:6px;' +
             'background:#3a1f1a;color:#ffb3a8;border:1px solid #6a3028;' +
             'font-size:13px;line-height:1.4">' +
-            '<strong>Release error:</strong> ' + esc(entry.last_release_error) +
+            '<strong>' + esc(t('modal.release_error')) + '</strong> ' + esc(entry.last_release_error) +
             '</div>';
     }
 
@@ -237,8 +238,8 @@ export function renderModal(entry) {
             'margin:8px 0;padding:10px 14px;border-radius:6px;' +
             'background:#2a2410;color:#f5d97a;border:1px solid #5a4a1a;' +
             'font-size:13px;line-height:1.4">' +
-            '<strong>Auto-skipped:</strong> ' + esc(skipNote) +
-            ' — recover manually if needed.' +
+            '<strong>' + esc(t('modal.auto_skipped')) + '</strong> ' + esc(skipNote) +
+            ' ' + esc(t('modal.recover_manually')) +
             '</div>';
     }
 
@@ -257,10 +258,9 @@ export function renderModal(entry) {
     // to be invisible and got fork-trucked) but not actionable.
     if (entry.child_of_node) {
         html += '<div style="padding:12px 16px;border-radius:8px;background:#1a1a1a;border:1px solid #444;color:#aab;font-size:14px;line-height:1.5">' +
-            'Indexed-over position of <strong>' + esc(entry.child_of_node) + '</strong>.' +
+            t('modal.indexed_over', { node: '<strong>' + esc(entry.child_of_node) + '</strong>' }) +
             '<div style="color:#888;font-size:12px;margin-top:4px">' +
-            'The changeover moves a bin through this seat. There is nothing to release here — ' +
-            'work it from ' + esc(entry.child_of_node) + '.</div></div>';
+            esc(t('modal.indexed_detail', { node: entry.child_of_node })) + '</div></div>';
     } else if (claim) {
         if (claim.swap_mode === 'manual_swap') {
             const binState = entry.bin_state;
@@ -301,27 +301,28 @@ export function renderModal(entry) {
 
             html += '<div class="os-demand-queue">';
             html += '<div style="font-size:13px;color:#999;margin-bottom:8px;text-transform:uppercase;letter-spacing:1px">';
-            html += claim.role === 'produce' ? 'Load Queue' : 'Unload Queue';
+            html += esc(claim.role === 'produce' ? t('modal.load_queue') : t('modal.unload_queue'));
             html += '</div>';
 
             if (delivered) {
                 html += '<div style="background:#1a3a1a;border:1px solid #2a5a2a;border-radius:6px;padding:10px;margin-bottom:10px;display:flex;align-items:center;gap:8px">';
-                html += '<span style="font-size:14px;font-weight:700;color:#6f6">[READY]</span>';
-                html += '<span style="color:#6f6;font-weight:600">Bin delivered - ready for ' + (claim.role === 'produce' ? 'loading' : 'unloading') + '</span>';
+                html += '<span style="font-size:14px;font-weight:700;color:#6f6">[' + esc(t('modal.ready')) + ']</span>';
+                html += '<span style="color:#6f6;font-weight:600">' + esc(claim.role === 'produce' ? t('modal.ready_loading') : t('modal.ready_unloading')) + '</span>';
                 html += '</div>';
             } else if (inTransit) {
                 html += '<div style="background:#2a2a1a;border:1px solid #5a5a2a;border-radius:6px;padding:10px;margin-bottom:10px;display:flex;align-items:center;gap:8px">';
-                html += '<span style="font-size:14px;font-weight:700;color:#ff6">[IN TRANSIT]</span>';
-                html += '<span style="color:#ff6;font-weight:600">Robot in transit</span>';
+                html += '<span style="font-size:14px;font-weight:700;color:#ff6">[' + esc(t('window.in_transit')) + ']</span>';
+                html += '<span style="color:#ff6;font-weight:600">' + esc(t('modal.robot_in_transit')) + '</span>';
                 html += '</div>';
             } else if (acknowledged) {
                 html += '<div style="background:#1a2a4a;border:1px solid #3a5a8a;border-radius:6px;padding:10px;margin-bottom:10px;display:flex;align-items:center;gap:8px">';
-                html += '<span style="font-size:14px;font-weight:700;color:#8af">[ACKNOWLEDGED]</span>';
-                html += '<span style="color:#8af;font-weight:600">Order accepted - awaiting dispatch</span>';
+                html += '<span style="font-size:14px;font-weight:700;color:#8af">[' + esc(t('window.acknowledged')) + ']</span>';
+                html += '<span style="color:#8af;font-weight:600">' + esc(t('modal.order_accepted')) + '</span>';
                 html += '</div>';
             }
             if (queued.length > 0) {
-                html += '<div style="color:#999;font-size:12px;margin-bottom:10px">' + queued.length + ' order' + (queued.length > 1 ? 's' : '') + ' queued</div>';
+                html += '<div style="color:#999;font-size:12px;margin-bottom:10px">' +
+                    esc(queued.length > 1 ? t('modal.queued_many', { n: queued.length }) : t('modal.queued_one')) + '</div>';
             }
 
             var queuePos = 1;
@@ -418,30 +419,30 @@ export function renderModal(entry) {
                     : '#555';
                 html += '<div style="font-size:12px;color:' + labelColor + '">';
                 if (payloadDelivered) {
-                    html += 'DELIVERED';
+                    html += esc(t('window.delivered'));
                 } else if (parkedEmptyAtLoader) {
-                    html += 'TAP TO LOAD';
+                    html += esc(t('modal.tap_to_load'));
                 } else if (parkedFullThisCode) {
-                    html += 'BIN READY';
+                    html += esc(t('modal.bin_ready'));
                 } else if (payloadInTransit) {
-                    html += 'IN TRANSIT';
+                    html += esc(t('window.in_transit'));
                 } else if (payloadAcknowledged) {
-                    html += 'ACKNOWLEDGED';
+                    html += esc(t('window.acknowledged'));
                 } else if (payloadQueued) {
-                    html += 'QUEUED';
+                    html += esc(t('window.queued'));
                 } else if (isActive) {
-                    html += 'active demand';
+                    html += esc(t('modal.active_demand'));
                 } else if (canRequest) {
-                    html += claim.role === 'produce' ? 'TAP TO REQUEST EMPTY' : 'TAP TO REQUEST FULL';
+                    html += esc(claim.role === 'produce' ? t('modal.tap_request_empty') : t('modal.tap_request_full'));
                 } else {
-                    html += 'no demand';
+                    html += esc(t('modal.no_demand'));
                 }
                 html += '</div>';
                 html += '</div>';
             });
 
             if (allowed.length === 0) {
-                html += '<div style="color:#666;font-style:italic;padding:12px">No payloads configured</div>';
+                html += '<div style="color:#666;font-style:italic;padding:12px">' + esc(t('modal.no_payloads')) + '</div>';
             }
 
             html += '</div>'; // close demand queue
@@ -454,15 +455,15 @@ export function renderModal(entry) {
                 // a useless "404 page not found" toast. Render the button
                 // disabled so the operator refreshes instead of hammering.
                 if (Number.isInteger(delivered.id) && delivered.id > 0) {
-                    html += actionBtn('CONFIRM DELIVERY', 'request', true,
+                    html += actionBtn(t('modal.confirm_delivery'), 'request', true,
                         '/api/confirm-delivery/' + delivered.id);
                 } else {
-                    html += actionBtn('CONFIRM (refresh)', 'close', false, '');
+                    html += actionBtn(t('modal.confirm_refresh'), 'close', false, '');
                 }
             }
 
             if (hasBin && remaining > 0) {
-                html += actionBtn('CLEAR BIN', 'empty-tools', true,
+                html += actionBtn(t('modal.clear_bin'), 'empty-tools', true,
                     '/api/process-nodes/' + entry.node.id + '/clear-bin');
             }
         } else {
@@ -477,7 +478,7 @@ export function renderModal(entry) {
                 // One click releases both legs unconditionally regardless of
                 // Order A's state. swap_ready is the single gate (see
                 // store/station_views.go ComputeSwapReady).
-                html += actionBtn('RELEASE', 'request', true,
+                html += actionBtn(t('modal.release'), 'request', true,
                     'release-prompt:/api/process-nodes/' + entry.node.id + '/release-staged');
             } else if (claim && claim.swap_mode === 'two_robot' && swapPair(active).length >= 2) {
                 // Two-robot swap in progress with BOTH legs still alive but
@@ -506,17 +507,19 @@ export function renderModal(entry) {
                 html += actionBtn(waitingLabel(blocker), 'close', false, '');
             } else if (staged) {
                 // Sequential / single-robot — single staged, single release.
-                html += actionBtn('RELEASE', 'request', true,
+                html += actionBtn(t('modal.release'), 'request', true,
                     'release-prompt:/api/orders/' + staged.id + '/release');
             } else if (delivered) {
-                var confirmLabel = 'CONFIRM';
+                var confirmLabel = t('modal.confirm');
                 var binState = entry.bin_state;
                 if (binState && binState.manifest) {
                     try {
                         var mf = JSON.parse(binState.manifest);
                         if (Array.isArray(mf) && mf.length > 0) {
                             var totalQty = mf.reduce(function(sum, item) { return sum + (item.quantity || 0); }, 0);
-                            confirmLabel = 'CONFIRM: ' + mf.length + (mf.length === 1 ? ' part' : ' parts') + ', qty ' + totalQty;
+                            confirmLabel = mf.length === 1
+                                ? t('modal.confirm_manifest_one', { qty: totalQty })
+                                : t('modal.confirm_manifest_many', { n: mf.length, qty: totalQty });
                         }
                     } catch (err) {
                         console.error('renderModal manifest parse', err);
@@ -530,7 +533,7 @@ export function renderModal(entry) {
                     // half-built complex order can carry a delivered status
                     // with a missing/zero ID. Render disabled so the operator
                     // refreshes rather than hits the chi 404 path.
-                    html += actionBtn('CONFIRM (refresh)', 'close', false, '');
+                    html += actionBtn(t('modal.confirm_refresh'), 'close', false, '');
                 }
             } else if (inFlight) {
                 // Disabled button when any non-staged/non-delivered active
@@ -547,28 +550,28 @@ export function renderModal(entry) {
                 // and stored on the edge order row; show it when available.
                 if (inFlight.status === 'queued') {
                     var queueLabel = inFlight.queue_reason
-                        ? 'IN QUEUE: ' + inFlight.queue_reason
-                        : 'IN QUEUE';
+                        ? t('modal.in_queue_reason', { reason: inFlight.queue_reason })
+                        : t('modal.in_queue');
                     html += actionBtn(queueLabel, 'close', false, '');
                 } else if (inFlight.status === 'acknowledged') {
                     // acknowledged is Core's intake ack, pre-sourcing — not a
                     // moving robot. Show its own label instead of pretending a
                     // robot is in transit.
-                    html += actionBtn('ACKNOWLEDGED', 'close', false, '');
+                    html += actionBtn(t('window.acknowledged'), 'close', false, '');
                 } else if (inFlight.status === 'sourcing') {
                     // Core is acquiring reservations/confirmations — same
                     // pre-fleet family as queued; surface the queue_reason when
                     // Core sent one.
                     var sourceLabel = inFlight.queue_reason
-                        ? 'SOURCING: ' + inFlight.queue_reason
-                        : 'SOURCING';
+                        ? t('modal.sourcing_reason', { reason: inFlight.queue_reason })
+                        : t('modal.sourcing');
                     html += actionBtn(sourceLabel, 'close', false, '');
                 } else {
-                    html += actionBtn('ROBOT IN TRANSIT', 'close', false, '');
+                    html += actionBtn(t('modal.robot_in_transit_caps'), 'close', false, '');
                 }
             } else {
                 if (claim.role === 'produce' && remaining > 0) {
-                    html += actionBtn('REQUEST SWAP', 'finalize', true,
+                    html += actionBtn(t('modal.request_swap'), 'finalize', true,
                         '/api/process-nodes/' + entry.node.id + '/finalize');
                 } else if (claim.role === 'produce') {
                     // remaining=0: operator brings an empty bin to the press.
@@ -576,11 +579,11 @@ export function renderModal(entry) {
                     // compatible with one of the allowed payloads.
                     var allowed = claim.allowed_payload_codes || (claim.payload_code ? [claim.payload_code] : []);
                     if (allowed.length > 0) {
-                        html += actionBtn('REQUEST EMPTY', 'request', true,
+                        html += actionBtn(t('modal.request_empty'), 'request', true,
                             '/api/process-nodes/' + entry.node.id + '/request-empty|' + allowed[0]);
                     }
                 } else {
-                    html += actionBtn('REQUEST MATERIAL', 'request', true,
+                    html += actionBtn(t('modal.request_material'), 'request', true,
                         '/api/process-nodes/' + entry.node.id + '/request');
                 }
                 // RELEASE EMPTY and RELEASE PARTIAL removed from operator HMI;
@@ -616,19 +619,19 @@ export function renderModal(entry) {
 
         html += '<div class="modal-divider"></div>';
 
-        html += actionBtn('STAGE NEXT MATERIAL', 'stage',
+        html += actionBtn(t('modal.stage_next'), 'stage',
             task.state === 'pending' && hasTarget,
             '/api/processes/' + pid + '/changeover/stage-node/' + nid);
 
-        html += actionBtn('EMPTY FOR TOOL CHANGE', 'empty-tools',
+        html += actionBtn(t('modal.empty_tool_change'), 'empty-tools',
             task.state === 'staging_requested',
             '/api/processes/' + pid + '/changeover/evacuate-node/' + nid);
 
-        html += actionBtn('RELEASE INTO PRODUCTION', 'release-production',
+        html += actionBtn(t('modal.release_production'), 'release-production',
             task.state === 'empty_requested',
             '/api/processes/' + pid + '/changeover/deliver-material/' + nid);
 
-        html += actionBtn('SWITCH TO TARGET', 'switch-target',
+        html += actionBtn(t('modal.switch_target'), 'switch-target',
             task.state === 'release_requested' || task.state === 'released',
             '/api/processes/' + pid + '/changeover/switch-node/' + nid);
     }
@@ -636,7 +639,7 @@ export function renderModal(entry) {
     html += '</div>'; // close actions
 
     html += '<div class="modal-actions" style="margin-top:12px">';
-    html += '<button type="button" class="os-action-btn close" data-action="close">CLOSE</button>';
+    html += '<button type="button" class="os-action-btn close" data-action="close">' + esc(t('operator.close_caps')) + '</button>';
     html += '</div>';
 
     // THE SUPPLY REFUSAL, BELOW EVERYTHING ELSE AND BEHIND A RULE.
@@ -751,21 +754,21 @@ function isStationReleasable(o) {
 }
 
 function waitingLabel(blocker) {
-    const base = 'WAITING FOR OTHER ROBOT';
+    const base = t('modal.waiting_other');
     if (!blocker) return base;
     if (blocker.queue_reason) return base + ' — ' + blocker.queue_reason;
     switch (blocker.status) {
         case 'faulted':
-            return base + ' — faulted, recovering';
+            return base + ' — ' + t('modal.why_faulted');
         case 'queued':
-            return base + ' — queued';
+            return base + ' — ' + t('modal.why_queued');
         case 'sourcing':
-            return base + ' — sourcing';
+            return base + ' — ' + t('modal.why_sourcing');
         case 'acknowledged':
-            return base + ' — acknowledged, not yet dispatched';
+            return base + ' — ' + t('modal.why_acknowledged');
         case 'in_transit': {
             const eta = formatETA(blocker.eta);
-            return eta.empty ? base + ' — in transit' : base + ' — ' + eta.text;
+            return base + ' — ' + (eta.empty ? t('modal.why_in_transit') : eta.text);
         }
         default:
             return base;
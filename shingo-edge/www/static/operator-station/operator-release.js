@@ -1,4 +1,4 @@
-import { esc, postAction } from './operator-util.js';
+import { esc, postAction, t } from './operator-util.js';
 import { getView, getSelectedNodeID, findNodeByID } from './operator-state.js';
 import { openKeypad } from './operator-keypad.js';
 
@@ -123,12 +123,12 @@ function renderReleasePromptStep1() {
 
     let html = '';
     html += '<div class="modal-header">';
-    html += '<div class="modal-node-name">Release</div>';
-    html += '<div class="modal-payload">Anything pulled to lineside during the swap?</div>';
+    html += '<div class="modal-node-name">' + esc(t('release.title')) + '</div>';
+    html += '<div class="modal-payload">' + esc(t('release.question')) + '</div>';
     html += '</div>';
 
     if (state.payloads.length === 0) {
-        html += '<div class="os-release-prompt"><div style="color:#999;padding:12px 0;font-size:14px">No allowed payloads on this node.</div></div>';
+        html += '<div class="os-release-prompt"><div style="color:#999;padding:12px 0;font-size:14px">' + esc(t('release.no_payloads')) + '</div></div>';
     } else {
         // Primary group: the chip grid and the PULL PARTS button visually
         // belong together — the chips show what's about to be captured and
@@ -136,7 +136,7 @@ function renderReleasePromptStep1() {
         // here first; the partial/empty escape hatch and CANCEL sit below
         // in a quieter row.
         html += '<div class="os-release-primary">';
-        html += '<div class="os-release-primary-label">Anything pulled to lineside? Tap a part to edit qty.</div>';
+        html += '<div class="os-release-primary-label">' + esc(t('release.pick_hint')) + '</div>';
         html += '<div class="os-release-part-grid">';
         state.payloads.forEach(function(code) {
             const qty = state.selected[code] != null ? state.selected[code] : 0;
@@ -147,7 +147,7 @@ function renderReleasePromptStep1() {
         });
         html += '</div>';
         html += '<button type="button" class="os-action-btn request"' +
-            ' data-action="release-submit-parts">PULL PARTS LINESIDE, RELEASE</button>';
+            ' data-action="release-submit-parts">' + esc(t('release.pull_parts')) + '</button>';
         html += '</div>';
     }
 
@@ -159,9 +159,9 @@ function renderReleasePromptStep1() {
     const partialCount = state.partialCount != null ? state.partialCount : remainingUOP;
     if (remainingUOP > 0) {
         html += '<div class="os-release-partial-count">';
-        html += '<div class="os-release-primary-label">Bin returning to supermarket with:</div>';
+        html += '<div class="os-release-primary-label">' + esc(t('release.returning_with')) + '</div>';
         html += '<button type="button" class="os-release-qty-display"' +
-            ' data-action="release-partial-edit">' + partialCount + ' UoP</button>';
+            ' data-action="release-partial-edit">' + esc(t('release.uop_qty', { n: partialCount })) + '</button>';
         html += '</div>';
     }
 
@@ -169,14 +169,12 @@ function renderReleasePromptStep1() {
     // Label reflects the actual action: bin still has UoP → returns as-is
     // (partial); bin is at zero → manifest cleared (empty). Same wire
     // disposition mapping as before; just makes it visible to the operator.
-    const submitLabel = remainingUOP > 0 ? 'RELEASE PARTIAL' : 'RELEASE EMPTY';
-    const submitTitle = remainingUOP > 0
-        ? 'No parts pulled to lineside. Bin returns to the supermarket with its current UoP intact.'
-        : 'No parts pulled to lineside. Bin is empty — manifest cleared.';
+    const submitLabel = remainingUOP > 0 ? t('release.partial') : t('release.empty');
+    const submitTitle = remainingUOP > 0 ? t('release.partial_title') : t('release.empty_title');
     html += '<button type="button" class="os-action-btn release-empty"' +
         ' data-action="release-submit"' +
         ' title="' + esc(submitTitle) + '">' +
-        submitLabel + '</button>';
+        esc(submitLabel) + '</button>';
     // Underpack release: bin physically empty before count reaches zero.
     // Only offered when the system still thinks the bin has UoP — otherwise
     // RELEASE EMPTY is the right path. Routes through a confirmation
@@ -185,10 +183,10 @@ function renderReleasePromptStep1() {
     if (remainingUOP > 0) {
         html += '<button type="button" class="os-action-btn release-empty"' +
             ' data-action="release-underpack-confirm"' +
-            ' title="Bin is physically empty, but the system still shows UoP remaining. Records the gap as missing inventory.">' +
-            'BIN EMPTY (UNDER COUNT)</button>';
+            ' title="' + esc(t('release.underpack_title')) + '">' +
+            esc(t('release.underpack')) + '</button>';
     }
-    html += '<button type="button" class="os-action-btn close" data-action="release-cancel">CANCEL</button>';
+    html += '<button type="button" class="os-action-btn close" data-action="release-cancel">' + esc(t('operator.cancel_caps')) + '</button>';
     html += '</div>';
 
     nodeModalContent.innerHTML = html;
@@ -208,19 +206,19 @@ function renderReleasePromptStep1() {
 function renderReleasePromptProduce() {
     let html = '';
     html += '<div class="modal-header">';
-    html += '<div class="modal-node-name">Release</div>';
-    html += '<div class="modal-payload">Send the full bin out?</div>';
+    html += '<div class="modal-node-name">' + esc(t('release.title')) + '</div>';
+    html += '<div class="modal-payload">' + esc(t('release.full_question')) + '</div>';
     html += '</div>';
 
     html += '<div class="os-release-prompt">';
     html += '<div class="os-release-primary-label" style="padding:12px 0">';
-    html += 'The finished bin is released to its outbound destination and the next empty is brought in.';
+    html += esc(t('release.full_detail'));
     html += '</div>';
     html += '</div>';
 
     html += '<div class="modal-actions">';
-    html += '<button type="button" class="os-action-btn request" data-action="release-submit-produce">RELEASE FULL</button>';
-    html += '<button type="button" class="os-action-btn close" data-action="release-cancel">CANCEL</button>';
+    html += '<button type="button" class="os-action-btn request" data-action="release-submit-produce">' + esc(t('release.full')) + '</button>';
+    html += '<button type="button" class="os-action-btn close" data-action="release-cancel">' + esc(t('operator.cancel_caps')) + '</button>';
     html += '</div>';
 
     nodeModalContent.innerHTML = html;
@@ -243,21 +241,24 @@ function renderReleasePromptUnderpackConfirm() {
 
     let html = '';
     html += '<div class="modal-header">';
-    html += '<div class="modal-node-name">Declare bin empty?</div>';
-    html += '<div class="modal-payload">Confirm: bin is physically empty, system shows UoP remaining.</div>';
+    html += '<div class="modal-node-name">' + esc(t('release.declare_question')) + '</div>';
+    html += '<div class="modal-payload">' + esc(t('release.declare_detail')) + '</div>';
     html += '</div>';
 
     html += '<div class="os-release-prompt">';
     html += '<div class="os-release-primary-label">';
-    html += 'System shows <strong>' + esc(String(remainingUOP)) + ' UoP</strong> remaining.<br>';
-    html += 'This will record <strong>' + esc(String(remainingUOP)) + ' units</strong> as missing inventory.';
+    // The amounts go in bold, so the sentences are escaped around them rather
+    // than whole: the catalog is ours, and only the numbers come from the view.
+    const units = t(remainingUOP === 1 ? 'release.units_one' : 'release.units_many', { n: remainingUOP });
+    html += t('release.system_shows', { qty: '<strong>' + esc(t('release.uop_qty', { n: remainingUOP })) + '</strong>' }) + '<br>';
+    html += t('release.will_record', { qty: '<strong>' + esc(units) + '</strong>' });
     html += '</div>';
     html += '</div>';
 
     html += '<div class="modal-actions">';
-    html += '<button type="button" class="os-action-btn close" data-action="release-back">BACK</button>';
+    html += '<button type="button" class="os-action-btn close" data-action="release-back">' + esc(t('operator.back_caps')) + '</button>';
     html += '<button type="button" class="os-action-btn release-empty"' +
-        ' data-action="release-submit-underpack">DECLARE EMPTY</button>';
+        ' data-action="release-submit-underpack">' + esc(t('release.declare_empty')) + '</button>';
     html += '</div>';
 
     nodeModalContent.innerHTML = html;
@@ -277,8 +278,8 @@ function renderReleasePromptStep2(code) {
 
     let html = '';
     html += '<div class="modal-header">';
-    html += '<div class="modal-node-name">Lineside qty: ' + esc(code) + '</div>';
-    html += '<div class="modal-payload">Tap the number to change it.</div>';
+    html += '<div class="modal-node-name">' + esc(t('release.lineside_qty', { code: code })) + '</div>';
+    html += '<div class="modal-payload">' + esc(t('release.tap_number')) + '</div>';
     html += '</div>';
 
     html += '<div class="os-release-prompt">';
@@ -286,18 +287,17 @@ function renderReleasePromptStep2(code) {
         esc(code) + '">' + qty + '</button>';
     if (showWarn) {
         html += '<div class="os-release-softcap-warn">';
-        html += 'Typo check: this is more than 2\u00D7 the configured lineside soft cap (' +
-            softCap + '). Release anyway if that\u2019s right.';
+        html += esc(t('release.softcap_warn', { cap: softCap }));
         html += '</div>';
     }
     html += '</div>';
 
     html += '<div class="modal-actions">';
-    html += '<button type="button" class="os-action-btn close" data-action="release-back">BACK</button>';
+    html += '<button type="button" class="os-action-btn close" data-action="release-back">' + esc(t('operator.back_caps')) + '</button>';
     const okDisabled = !(qty > 0);
     html += '<button type="button" class="os-action-btn request"' +
         (okDisabled ? ' disabled' : '') +
-        ' data-action="release-qty-ok:' + esc(code) + '">OK</button>';
+        ' data-action="release-qty-ok:' + esc(code) + '">' + esc(t('action.ok')) + '</button>';
     html += '</div>';
 
     nodeModalContent.innerHTML = html;
@@ -344,7 +344,7 @@ async function handleReleasePromptAction(evt) {
         const code = action.slice('release-qty-edit:'.length);
         const current = state.selected[code] || 0;
         openKeypad(0, current, {
-            title: 'Lineside qty: ' + code,
+            title: t('release.lineside_qty', { code: code }),
             onOk: function(_nodeID, qty) {
                 // Always store a numeric qty so the chip's "(N)" suffix
                 // stays consistent — pre-population now seeds every chip
@@ -363,7 +363,7 @@ async function handleReleasePromptAction(evt) {
     if (action === 'release-partial-edit') {
         const current = state.partialCount != null ? state.partialCount : 0;
         openKeypad(0, current, {
-            title: 'Bin remaining (UoP)',
+            title: t('release.bin_remaining'),
             onOk: function(_nodeID, qty) {
                 state.partialCount = qty > 0 ? qty : 0;
                 renderReleasePromptStep1();
@@ -488,15 +488,17 @@ function closeReleasePrompt() {
 export function openStrandedStub(bucket, handleModalAction) {
     let html = '';
     html += '<div class="modal-header">';
-    html += '<div class="modal-node-name">Stranded at lineside</div>';
-    html += '<div class="modal-payload">' + esc(bucket.part_number) + ' — ' + (bucket.qty || 0) + ' unit' + ((bucket.qty || 0) === 1 ? '' : 's') + '</div>';
+    const qty = bucket.qty || 0;
+    html += '<div class="modal-node-name">' + esc(t('release.stranded_title')) + '</div>';
+    html += '<div class="modal-payload">' + esc(bucket.part_number) + ' — ' +
+        esc(t(qty === 1 ? 'release.units_one' : 'release.units_many', { n: qty })) + '</div>';
     html += '</div>';
     html += '<div style="padding:12px 0;color:#bbb;font-size:14px;line-height:1.4">';
-    html += 'These parts were captured during a previous changeover and are not counting toward the active style.<br><br>';
-    html += '<strong>Scrap / repack / recall actions will land in a later phase.</strong>';
+    html += esc(t('release.stranded_detail')) + '<br><br>';
+    html += '<strong>' + esc(t('release.stranded_later')) + '</strong>';
     html += '</div>';
     html += '<div class="modal-actions">';
-    html += '<button type="button" class="os-action-btn close" data-action="close">CLOSE</button>';
+    html += '<button type="button" class="os-action-btn close" data-action="close">' + esc(t('operator.close_caps')) + '</button>';
     html += '</div>';
     nodeModalContent.innerHTML = html;
     nodeModalContent.querySelectorAll('[data-action]').forEach(function(btn) {
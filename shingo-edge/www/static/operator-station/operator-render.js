@@ -1,4 +1,4 @@
-import { el, esc, fillColor, postAction, showToast, fetchWithTimeout, formatETA } from './operator-util.js';
+import { el, esc, fillColor, postAction, showToast, fetchWithTimeout, formatETA, t } from './operator-util.js';
 import { getView, claimedNodes, isReplenishing } from './operator-state.js';
 import { isActive } from './order-status.js';
 import { cardModel, headerModel, nodeFacts, ROLE_WORDS } from './operator-window-state.js';
@@ -23,7 +23,7 @@ export function setRenderRefs(refs) {
 
 export function renderHeader() {
     const view = getView();
-    const style = view.current_style ? view.current_style.name : 'No Style';
+    const style = view.current_style ? view.current_style.name : t('board.no_style');
     const target = view.target_style ? (' \u2192 ' + view.target_style.name) : '';
     headerInfo.textContent = view.process.name + ' - ' + style + target;
 
@@ -36,10 +36,10 @@ export function renderHeader() {
 
     // Active style chip — sits next to the changeover button so the operator
     // can see which style is running. During changeover shows "current → target".
-    const styleName = view.current_style ? view.current_style.name : 'No Style';
+    const styleName = view.current_style ? view.current_style.name : t('board.no_style');
     const targetName = view.target_style ? view.target_style.name : null;
     const styleChip = el('div', { className: 'os-header-style' + (targetName ? ' changing' : '') });
-    styleChip.appendChild(el('span', { className: 'os-header-style-label', textContent: 'STYLE' }));
+    styleChip.appendChild(el('span', { className: 'os-header-style-label', textContent: t('board.style_caps') }));
     const styleValue = el('span', { className: 'os-header-style-value' });
     styleValue.textContent = targetName ? styleName + ' \u2192 ' + targetName : styleName;
     styleChip.appendChild(styleValue);
@@ -71,10 +71,10 @@ export function renderHeader() {
         // is consolidating homes, not a changeover. Surface the buffer chain here
         // in place of CHANGEOVER so it's discoverable instead of hidden behind a
         // per-card condition.
-        headerActions.appendChild(headerBtn('PULL FULL FROM HOME', 'pull-full-home', openPullFullFromHomePicker));
+        headerActions.appendChild(headerBtn(t('board.pull_full_home'), 'pull-full-home', openPullFullFromHomePicker));
     } else if (!isBoardMode()) {
         if (view.active_changeover) {
-            headerActions.appendChild(headerBtn('CUTOVER', 'cutover', confirmCutover));
+            headerActions.appendChild(headerBtn(t('board.cutover_caps'), 'cutover', confirmCutover));
             // CANCEL during active changeover: aborts every in-flight evac+
             // supply order on the process's node tasks, marks the changeover
             // row cancelled, and resets the process back to active_production
@@ -82,13 +82,13 @@ export function renderHeader() {
             // already mid-route (queued → disappears, loaded robot → store-
             // order rerouted to a safe drop). Operator wraps this in a
             // confirmation modal because the action is destructive.
-            headerActions.appendChild(headerBtn('CANCEL', 'cancel-changeover', confirmCancelChangeover));
+            headerActions.appendChild(headerBtn(t('operator.cancel_caps'), 'cancel-changeover', confirmCancelChangeover));
         } else {
-            headerActions.appendChild(headerBtn('CHANGEOVER', 'changeover', openChangeoverPicker));
+            headerActions.appendChild(headerBtn(t('board.changeover_caps'), 'changeover', openChangeoverPicker));
         }
     }
 
-    headerActions.appendChild(headerBtn('REFRESH', 'refresh', loadViewRef));
+    headerActions.appendChild(headerBtn(t('board.refresh_caps'), 'refresh', loadViewRef));
 }
 
 function isBoardMode() {
@@ -122,9 +122,9 @@ function pickerVerdictLabel(src) {
     if (!src) return '';
     var head;
     switch (src.code) {
-        case 'green': head = 'PARTS AVAILABLE'; break;
-        case 'red': head = 'NO PARTS AVAILABLE'; break;
-        case 'yellow': head = 'RUNNING LOW'; break;
+        case 'green': head = t('board.parts_available'); break;
+        case 'red': head = t('refusal.refuse_label'); break;
+        case 'yellow': head = t('board.running_low'); break;
         // Core's own words for the unverdicted cases — "not set up" / the
         // unrecognised-verdict sentence. Better than inventing a phrase here.
         default: head = src.status || '';
@@ -141,13 +141,13 @@ function openChangeoverPicker() {
     const currentID = view.current_style ? view.current_style.id : null;
     const others = styles.filter(s => s.id !== currentID);
     if (others.length === 0) {
-        showToast('No other styles available', 'error');
+        showToast(t('board.no_other_styles'), 'error');
         return;
     }
 
     const overlay = el('div', { className: 'os-co-picker-overlay' });
     const panel = el('div', { className: 'os-co-picker' });
-    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: 'Change over to:' }));
+    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: t('board.change_over_to') }));
 
     const sourcing = view.sourcing_by_style || {};
     for (const s of others) {
@@ -171,7 +171,7 @@ function openChangeoverPicker() {
         panel.appendChild(btn);
     }
 
-    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', () => overlay.remove());
     panel.appendChild(cancel);
 
@@ -188,7 +188,7 @@ async function startChangeover(toStyleID, styleName) {
         called_by: (view.station.name && view.station.name.trim()) || 'operator',
         notes: ''
     }, loadViewRef);
-    if (ok) showToast('Changeover to ' + styleName + ' started', 'success');
+    if (ok) showToast(t('board.changeover_started', { style: styleName }), 'success');
 }
 
 
@@ -199,19 +199,19 @@ async function confirmCancelChangeover() {
     const overlay = el('div', { className: 'os-co-picker-overlay' });
     const panel = el('div', { className: 'os-co-picker' });
     panel.appendChild(el('div', { className: 'os-co-picker-title',
-        textContent: 'Cancel changeover to ' + (co.to_style_name || 'target') + '?' }));
+        textContent: t('board.cancel_changeover_q', { style: co.to_style_name || t('board.target') }) }));
     panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-        textContent: 'In-flight robots will be recalled. Loaded bins are routed to safe storage.' }));
+        textContent: t('board.cancel_changeover_detail') }));
 
-    const confirm = el('button', { className: 'os-co-picker-btn danger', textContent: 'CANCEL CHANGEOVER' });
+    const confirm = el('button', { className: 'os-co-picker-btn danger', textContent: t('board.cancel_changeover_caps') });
     confirm.addEventListener('click', async () => {
         overlay.remove();
         const ok = await postAction('/api/processes/' + pid + '/changeover/cancel', {}, loadViewRef);
-        if (ok) showToast('Changeover cancelled', 'success');
+        if (ok) showToast(t('board.changeover_cancelled'), 'success');
     });
     panel.appendChild(confirm);
 
-    const dismiss = el('button', { className: 'os-co-picker-btn cancel', textContent: 'KEEP CHANGEOVER' });
+    const dismiss = el('button', { className: 'os-co-picker-btn cancel', textContent: t('board.keep_changeover_caps') });
     dismiss.addEventListener('click', () => overlay.remove());
     panel.appendChild(dismiss);
 
@@ -227,17 +227,17 @@ async function confirmCutover() {
     const panel = el('div', { className: 'os-co-picker' });
     const co = view.active_changeover;
     panel.appendChild(el('div', { className: 'os-co-picker-title',
-        textContent: 'Complete cutover to ' + (co.to_style_name || 'target') + '?' }));
+        textContent: t('board.complete_cutover_q', { style: co.to_style_name || t('board.target') }) }));
 
-    const confirm = el('button', { className: 'os-co-picker-btn', textContent: 'CONFIRM CUTOVER' });
+    const confirm = el('button', { className: 'os-co-picker-btn', textContent: t('board.confirm_cutover_caps') });
     confirm.addEventListener('click', async () => {
         overlay.remove();
         const ok = await postAction('/api/processes/' + pid + '/changeover/cutover', undefined, loadViewRef);
-        if (ok) showToast('Cutover complete', 'success');
+        if (ok) showToast(t('board.cutover_complete'), 'success');
     });
     panel.appendChild(confirm);
 
-    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', () => overlay.remove());
     panel.appendChild(cancel);
 
@@ -271,7 +271,7 @@ export function renderGrid() {
         document.body.classList.remove('os-board-mode-active');
         grid.style.removeProperty('--os-cols');
         grid.style.removeProperty('--os-rows');
-        const empty = el('div', { id: 'os-grid-empty', textContent: 'No claimed nodes' });
+        const empty = el('div', { id: 'os-grid-empty', textContent: t('board.no_claimed_nodes') });
         grid.appendChild(empty);
         return;
     }
@@ -366,9 +366,9 @@ function confirmUnloadSwap(nodeID, allowedPayloadCodes) {
 
     const overlay = el('div', { className: 'os-co-picker-overlay' });
     const panel = el('div', { className: 'os-co-picker' });
-    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: 'Full pulled, empty filled?' }));
+    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: t('board.unload_swap_q') }));
     panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-        textContent: 'Confirms the bin is unloaded. The empty returns to the supermarket and the next full is requested.' }));
+        textContent: t('board.unload_swap_detail') }));
 
     const binTypes = binTypeCodes.length > 0 ? binTypeCodes : null;
     // Single-type stations auto-fill: skip the picker and send the code
@@ -378,7 +378,7 @@ function confirmUnloadSwap(nodeID, allowedPayloadCodes) {
     if (binTypes && !autoCode) {
         // Two or more types: show the picker so the operator selects one.
         panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-            textContent: 'Select dunnage type for this carrier:' }));
+            textContent: t('board.select_dunnage') }));
         binTypes.forEach(function(code) {
             const btn = el('button', { className: 'os-co-picker-btn', textContent: code });
             btn.addEventListener('click', function() {
@@ -390,7 +390,7 @@ function confirmUnloadSwap(nodeID, allowedPayloadCodes) {
         });
     } else {
         // No catalog / exactly one type (auto-fill): one button.
-        const confirm = el('button', { className: 'os-co-picker-btn', textContent: 'CONFIRM SWAP' });
+        const confirm = el('button', { className: 'os-co-picker-btn', textContent: t('board.confirm_swap_caps') });
         confirm.addEventListener('click', function() {
             overlay.remove();
             postAction('/api/process-nodes/' + nodeID + '/clear-bin',
@@ -399,7 +399,7 @@ function confirmUnloadSwap(nodeID, allowedPayloadCodes) {
         panel.appendChild(confirm);
     }
 
-    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', () => overlay.remove());
     panel.appendChild(cancel);
 
@@ -414,18 +414,18 @@ function confirmUnloadSwap(nodeID, allowedPayloadCodes) {
 function confirmPushEmpty(nodeID) {
     const overlay = el('div', { className: 'os-co-picker-overlay' });
     const panel = el('div', { className: 'os-co-picker' });
-    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: 'Empty bin in slot' }));
+    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: t('board.empty_in_slot') }));
     panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-        textContent: 'Send the empty carrier to the supermarket so a full bin can be requested.' }));
+        textContent: t('board.push_empty_detail') }));
 
-    const push = el('button', { className: 'os-co-picker-btn', textContent: 'PUSH EMPTY' });
+    const push = el('button', { className: 'os-co-picker-btn', textContent: t('board.push_empty_caps') });
     push.addEventListener('click', function() {
         overlay.remove();
         postAction('/api/process-nodes/' + nodeID + '/push-empty', undefined, loadViewRef);
     });
     panel.appendChild(push);
 
-    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', () => overlay.remove());
     panel.appendChild(cancel);
 
@@ -441,18 +441,18 @@ function confirmPushEmpty(nodeID) {
 function confirmClearLoaderHome(nodeID) {
     const overlay = el('div', { className: 'os-co-picker-overlay' });
     const panel = el('div', { className: 'os-co-picker' });
-    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: 'Clear this home bin?' }));
+    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: t('board.clear_home_q') }));
     panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-        textContent: 'Declares this bin empty. Shingo will move the empty carrier to the buffer, then pull the buffer partial to this home.' }));
+        textContent: t('board.clear_home_detail') }));
 
-    const confirm = el('button', { className: 'os-co-picker-btn', textContent: 'CONFIRM CLEAR' });
+    const confirm = el('button', { className: 'os-co-picker-btn', textContent: t('board.confirm_clear_caps') });
     confirm.addEventListener('click', function() {
         overlay.remove();
         postAction('/api/process-nodes/' + nodeID + '/clear-loader-home', undefined, loadViewRef);
     });
     panel.appendChild(confirm);
 
-    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', () => overlay.remove());
     panel.appendChild(cancel);
 
@@ -486,13 +486,13 @@ function openPullFullFromHomePicker() {
 
     var overlay = el('div', { className: 'os-co-picker-overlay' });
     var panel = el('div', { className: 'os-co-picker' });
-    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: 'Pull full from home' }));
+    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: t('board.pull_full_home_title') }));
     panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-        textContent: 'Pick a full home. Shingo sends its empty carrier to the buffer, then pulls the matching buffer partial into the home.' }));
+        textContent: t('board.pull_full_home_detail') }));
 
     if (eligible.length === 0) {
         panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-            textContent: 'No home has a matching buffer partial ready right now.' }));
+            textContent: t('board.no_home_ready') }));
     }
     eligible.forEach(function(n) {
         var bs = n.bin_state || {};
@@ -509,7 +509,7 @@ function openPullFullFromHomePicker() {
         panel.appendChild(btn);
     });
 
-    var cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    var cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', function() { overlay.remove(); });
     panel.appendChild(cancel);
 
@@ -525,15 +525,15 @@ function openPullFullFromHomePicker() {
 function showPullFromMarketPicker(nodeID) {
     const overlay = el('div', { className: 'os-co-picker-overlay' });
     const panel = el('div', { className: 'os-co-picker' });
-    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: 'Pull bin from market' }));
+    panel.appendChild(el('div', { className: 'os-co-picker-title', textContent: t('board.pull_market_title') }));
     panel.appendChild(el('div', { className: 'os-co-picker-subtitle',
-        textContent: 'Select a bin in the outbound market to pull back to this loader window.' }));
+        textContent: t('board.pull_market_detail') }));
 
     const listDiv = el('div', { style: 'margin:8px 0 4px;display:flex;flex-direction:column;gap:10px;' });
-    listDiv.textContent = 'Loading…';
+    listDiv.textContent = t('common.loading');
     panel.appendChild(listDiv);
 
-    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: 'CANCEL' });
+    const cancel = el('button', { className: 'os-co-picker-btn cancel', textContent: t('operator.cancel_caps') });
     cancel.addEventListener('click', () => overlay.remove());
     panel.appendChild(cancel);
 
@@ -548,7 +548,7 @@ function showPullFromMarketPicker(nodeID) {
         .then(function(bins) {
             listDiv.textContent = '';
             if (!bins || bins.length === 0) {
-                listDiv.textContent = 'No bins available in the market.';
+                listDiv.textContent = t('board.no_market_bins');
                 return;
             }
             bins.forEach(function(b) {
@@ -564,7 +564,7 @@ function showPullFromMarketPicker(nodeID) {
                 var payloadEl = el('div', { textContent: b.payload_code });
                 payloadEl.style.cssText = 'font-size:17px;font-weight:600;margin-top:5px;';
 
-                var uopEl = el('div', { textContent: 'UoP: ' + b.uop_remaining });
+                var uopEl = el('div', { textContent: t('board.uop_n', { n: b.uop_remaining }) });
                 uopEl.style.cssText = 'font-size:13px;margin-top:3px;opacity:0.7;';
 
                 btn.appendChild(nodeEl);
@@ -580,7 +580,7 @@ function showPullFromMarketPicker(nodeID) {
             });
         })
         .catch(function() {
-            listDiv.textContent = 'Could not load market bins.';
+            listDiv.textContent = t('board.market_load_failed');
         });
 }
 
@@ -594,14 +594,13 @@ function showPullFromMarketPicker(nodeID) {
 // so a bad value degrades to the bare word "Waiting" instead of "Waiting -3m".
 function waitedLabel(created) {
     if (!created) return '';
-    var t = Date.parse(created);
-    if (isNaN(t)) return '';
-    var mins = Math.floor((Date.now() - t) / 60000);
+    var at = Date.parse(created);
+    if (isNaN(at)) return '';
+    var mins = Math.floor((Date.now() - at) / 60000);
     if (mins < 0) return '';
-    if (mins < 1) return 'under a minute';
-    if (mins < 60) return mins + 'm';
-    var hrs = Math.floor(mins / 60);
-    return hrs + 'h' + (mins % 60) + 'm';
+    if (mins < 1) return t('board.waited_under_minute');
+    if (mins < 60) return t('board.waited_minutes', { m: mins });
+    return t('board.waited_hours', { h: Math.floor(mins / 60), m: mins % 60 });
 }
 
 // buildLoaderCard renders ONE (position × payload) card — the atomic unit of the
@@ -633,9 +632,9 @@ function buildLoaderCard(entry, code, counters, opts) {
     // idle card show the coverage meaning instead.
     if (entry.operator_driven && cs.cls === 'os-board-nodemand') {
         if (isActiveStylePayload) {
-            cs.statusText = 'ACTIVE'; cs.statusClass = 'os-board-tag-lineside'; cs.detail = '';
+            cs.statusText = t('board.active_caps'); cs.statusClass = 'os-board-tag-lineside'; cs.detail = '';
         } else {
-            cs.statusText = 'PRELOAD'; cs.statusClass = 'os-board-tag-preload'; cs.detail = 'Available to stage';
+            cs.statusText = t('board.preload_caps'); cs.statusClass = 'os-board-tag-preload'; cs.detail = t('board.available_to_stage');
         }
     }
 
@@ -663,7 +662,7 @@ function buildLoaderCard(entry, code, counters, opts) {
     if (isActiveStylePayload || entry.operator_driven) {
         card.appendChild(el('span', {
             className: 'os-board-cov ' + (isActiveStylePayload ? 'os-board-cov-active' : 'os-board-cov-preload'),
-            textContent: isActiveStylePayload ? 'ACTIVE' : 'PRELOAD',
+            textContent: isActiveStylePayload ? t('board.active_caps') : t('board.preload_caps'),
         }));
     }
     // The coverage TINT stays operator_driven-only. It exists to make a transitional
@@ -697,7 +696,7 @@ function buildLoaderCard(entry, code, counters, opts) {
         var starved = (entry.starved_payloads || {})[code] === true;
         card.appendChild(el('div', {
             className: 'os-board-lineside' + (starved ? ' os-board-lineside--starved' : ''),
-            textContent: 'Lineside ' + lsUOP + ' UoP' + (starved ? ' — PRELOAD' : ''),
+            textContent: t('board.lineside_uop', { n: lsUOP }) + (starved ? ' — ' + t('board.preload_caps') : ''),
         }));
         if (starved) card.classList.add('os-board-card--starved');
     }
@@ -716,7 +715,7 @@ function buildLoaderCard(entry, code, counters, opts) {
         var waited = waitedLabel(cs.waitingSince);
         card.appendChild(el('div', {
             className: 'os-board-downtime',
-            textContent: 'QUEUED' + (waited ? ' ' + waited : '') +
+            textContent: t('window.queued') + (waited ? ' ' + waited : '') +
                 (cs.queueReason ? ' — ' + cs.queueReason : ''),
         }));
     }
@@ -743,9 +742,11 @@ function buildLoaderCard(entry, code, counters, opts) {
         // loader" rather than inventing a name is the honest granularity.
         card.appendChild(el('div', {
             className: 'os-board-downtime',
-            textContent: 'NO PARTS AVAILABLE' +
+            textContent: t('refusal.refuse_label') +
                 (refusal.refused_by ? ' — ' + refusal.refused_by : '') +
-                (refusal.answered ? ' · cell chose to ' + (refusal.ack_choice || 'wait') : ' · awaiting the cell'),
+                ' · ' + (!refusal.answered ? t('board.awaiting_cell')
+                    : refusal.ack_choice === 'changeover' ? t('board.cell_chose_changeover')
+                        : t('board.cell_chose_wait')),
         }));
     }
 
@@ -822,9 +823,9 @@ function renderPayloadBoard(entry) {
     const remaining = runtime.remaining_uop_cached != null ? runtime.remaining_uop_cached : 0;
     const binState = entry.bin_state;
     const hasBin = binState && binState.occupied;
-    const binLabel = binState && binState.bin_label ? binState.bin_label : 'No bin';
+    const binLabel = binState && binState.bin_label ? binState.bin_label : t('modal.no_bin');
     const binPayload = binState && binState.payload_code ? binState.payload_code : '';
-    const roleLabel = claim.role === 'produce' ? 'Loader' : 'Unloader';
+    const roleLabel = claim.role === 'produce' ? t('modal.loader') : t('modal.unloader');
 
     // Header status badge from the shared model — same facts the cards read, so the
     // badge can't contradict a card. Worded by role (loader awaits a BIN, unloader a FULL).
@@ -833,15 +834,15 @@ function renderPayloadBoard(entry) {
     var infoBar = el('div', { className: 'os-board-header' });
     infoBar.innerHTML =
         '<div>' +
-            '<div style="font-size:42px;font-weight:700;color:#fff">' + esc(entry.node.name) + ' - ' + roleLabel + '</div>' +
-            '<div style="font-size:20px;color:#aab;margin-top:6px">Manual Swap | ' +
-                (claim.allowed_payload_codes ? claim.allowed_payload_codes.length : 0) + ' payloads configured</div>' +
+            '<div style="font-size:42px;font-weight:700;color:#fff">' + esc(t('board.node_role', { node: entry.node.name, role: roleLabel })) + '</div>' +
+            '<div style="font-size:20px;color:#aab;margin-top:6px">' + esc(t('material.manual_swap')) + ' | ' +
+                esc(t('board.payloads_configured', { n: claim.allowed_payload_codes ? claim.allowed_payload_codes.length : 0 })) + '</div>' +
         '</div>' +
         '<div style="text-align:right">' +
-            '<div style="font-size:28px;font-weight:600;color:#fff">Bin: ' + esc(binLabel) + '</div>' +
-            (binPayload ? '<div style="font-size:20px;color:#aab;margin-top:4px">' + esc(binPayload) + ' | UoP: ' + remaining + '</div>' : '') +
+            '<div style="font-size:28px;font-weight:600;color:#fff">' + esc(t('board.bin_label', { bin: binLabel })) + '</div>' +
+            (binPayload ? '<div style="font-size:20px;color:#aab;margin-top:4px">' + esc(binPayload) + ' | ' + esc(t('board.uop_n', { n: remaining })) + '</div>' : '') +
             '<div style="display:inline-block;font-size:22px;font-weight:700;padding:8px 20px;border-radius:6px;margin-top:8px;' +
-                hb.color + '">' + hb.text +
+                hb.color + '">' + esc(hb.text) +
             '</div>' +
         '</div>';
     grid.appendChild(infoBar);
@@ -894,8 +895,8 @@ function renderPayloadBoard(entry) {
     // to ask for.
     if (isProduce || requestPayload) {
         var reqBar = el('div', { className: 'os-board-reqbar' });
-        var reqLabel = isProduce ? 'REQUEST EMPTY' : 'REQUEST FULL';
-        var reqReason = hasBin ? 'bin at node' : (facts.hasDemand ? 'bin already inbound' : '');
+        var reqLabel = isProduce ? t('modal.request_empty') : t('board.request_full');
+        var reqReason = hasBin ? t('board.bin_at_node') : (facts.hasDemand ? t('board.bin_inbound') : '');
         var reqBtn = el('button', {
             className: 'os-board-request-btn' + (canRequest ? '' : ' disabled'),
             textContent: canRequest ? reqLabel : reqLabel + ' — ' + reqReason,
@@ -920,7 +921,7 @@ function renderPayloadBoard(entry) {
         if (isProduce && claim.outbound_destination) {
             var pullBtn = el('button', {
                 className: 'os-board-request-btn',
-                textContent: 'PULL FROM MARKET',
+                textContent: t('board.pull_from_market'),
             });
             pullBtn.addEventListener('click', function() {
                 showPullFromMarketPicker(entry.node.id);
@@ -954,7 +955,7 @@ function renderPayloadBoard(entry) {
             // allowed empty → nothing configured; allowed non-empty but nothing
             // rendered → a normal loader with no active demand (all idle cards
             // filtered). Distinct copy so the operator knows which it is.
-            textContent: allowed.length === 0 ? 'No payloads configured' : 'No active demand'
+            textContent: allowed.length === 0 ? t('modal.no_payloads') : t('board.no_active_demand')
         }));
     }
 
@@ -972,8 +973,8 @@ function renderHomeLocationBoard(nodes) {
     // (dedicated finished-goods exits). Title reflects whichever this station is.
     var roles = {};
     nodes.forEach(function(n) { if (n.active_claim) roles[n.active_claim.role] = true; });
-    var title = (roles.produce && roles.consume) ? 'Stations'
-        : roles.consume ? 'Unloader' : 'Bin Loader';
+    var title = (roles.produce && roles.consume) ? t('board.stations')
+        : roles.consume ? t('modal.unloader') : t('board.bin_loader');
 
     // Cards are built BEFORE the header so the subtitle can report how many homes
     // were filtered out. Hidden cards must stay counted and visible as a number:
@@ -991,7 +992,7 @@ function renderHomeLocationBoard(nodes) {
             // Home label (physical position) + its own bin state, prepended so a
             // wall of cards stays scannable by home.
             var bs = node.bin_state || {};
-            var binTxt = bs.occupied ? (bs.payload_code ? 'LOADED' : 'EMPTY') : 'AWAITING';
+            var binTxt = bs.occupied ? (bs.payload_code ? t('window.loaded') : t('window.empty')) : t('board.awaiting_caps');
             card.insertBefore(el('div', {
                 className: 'os-board-home',
                 textContent: esc(node.node.name) + ' · ' + binTxt,
@@ -1005,7 +1006,7 @@ function renderHomeLocationBoard(nodes) {
                 card.classList.add('os-board-card--ready');
                 var clearBtn = el('button', {
                     className: 'os-co-picker-btn',
-                    textContent: 'CLEAR BIN',
+                    textContent: t('modal.clear_bin'),
                 });
                 clearBtn.style.cssText = 'margin-top:12px;width:100%;font-size:18px;';
                 clearBtn.addEventListener('click', function(evt) {
@@ -1027,16 +1028,16 @@ function renderHomeLocationBoard(nodes) {
             // Distinguish "nothing set up" from "set up, nothing to do right now" —
             // on a home board the second is the normal resting state and must not
             // look like a broken configuration.
-            textContent: idle > 0 ? 'All homes stocked — nothing to load' : 'No homes configured',
+            textContent: idle > 0 ? t('board.all_homes_stocked') : t('board.no_homes'),
         }));
     }
 
     var header = el('div', { className: 'os-board-header' });
     header.innerHTML =
-        '<div><div style="font-size:42px;font-weight:700;color:#fff">' + title + ' — Home Locations</div>' +
+        '<div><div style="font-size:42px;font-weight:700;color:#fff">' + esc(t('board.home_locations', { title: title })) + '</div>' +
         '<div style="font-size:20px;color:#aab;margin-top:6px">' +
-        nodes.length + ' dedicated position' + (nodes.length === 1 ? '' : 's') +
-        (idle > 0 ? ' · ' + idle + ' idle hidden' : '') +
+        esc(nodes.length === 1 ? t('board.positions_one') : t('board.positions_many', { n: nodes.length })) +
+        (idle > 0 ? ' · ' + esc(t('board.idle_hidden', { n: idle })) : '') +
         '</div></div>';
     grid.appendChild(header);
     grid.appendChild(cardGrid);
@@ -1127,11 +1128,11 @@ function createNodeButton(entry) {
     if (refusedForMe && refusedForMe.answered) {
         const chip = el('span', {
             className: 'os-node-alarm',
-            textContent: 'NO ' + refusedForMe.payload_code,
+            textContent: t('board.no_payload_chip', { code: refusedForMe.payload_code }),
         });
         chip.style.cssText = 'position:absolute;bottom:4px;right:4px;font-size:11px;' +
             'font-weight:700;color:#1a1204;background:#ffd98a;padding:2px 6px;border-radius:4px';
-        chip.title = refusedForMe.loader_node + ' cannot supply ' + refusedForMe.payload_code +
+        chip.title = t('board.cannot_supply', { node: refusedForMe.loader_node, code: refusedForMe.payload_code }) +
             (refusedForMe.refused_by ? ' — ' + refusedForMe.refused_by : '');
         btn.appendChild(chip);
     }
@@ -1147,7 +1148,7 @@ function createNodeButton(entry) {
         const asking = entry.stranded_alarm.indexOf('Record Count') !== -1;
         const alarm = el('span', {
             className: 'os-node-alarm',
-            textContent: asking ? '⚠ NOT BOUND' : 'UOP ACCUM',
+            textContent: asking ? t('board.not_bound') : t('board.uop_accum'),
         });
         alarm.style.cssText = 'position:absolute;bottom:4px;left:4px;font-size:11px;' +
             'font-weight:700;padding:2px 6px;border-radius:4px;' +
@@ -1159,17 +1160,17 @@ function createNodeButton(entry) {
     // Banner label for the priority states. The full-tile background
     // already signals "something is up"; the label says what.
     if (releaseReady && !drain) {
-        btn.appendChild(el('span', { className: 'os-node-banner', textContent: 'RELEASE READY' }));
+        btn.appendChild(el('span', { className: 'os-node-banner', textContent: t('board.release_ready') }));
     } else if (inChangeover && !drain) {
-        btn.appendChild(el('span', { className: 'os-node-banner', textContent: 'CHANGEOVER' }));
+        btn.appendChild(el('span', { className: 'os-node-banner', textContent: t('board.changeover_caps') }));
     }
 
     // [REP] corner badge stays for replenishing (different signal — bin
     // move in flight). [CO] badge is suppressed because the full-tile
     // CHANGEOVER banner makes it redundant.
     const icon = statusIcon(entry);
-    if (icon && icon === '[REP]') {
-        btn.appendChild(el('span', { className: 'os-node-icon', textContent: icon }));
+    if (icon === 'modal.rep_tag') {
+        btn.appendChild(el('span', { className: 'os-node-icon', textContent: t(icon) }));
     }
 
     if (claim && claim.swap_mode === 'manual_swap') {
@@ -1187,15 +1188,15 @@ function createNodeButton(entry) {
             // mask a present full — the green tile + tap-to-confirm already say "act".
             statusText = binPayload;
         } else if (hasActiveOrder) {
-            statusText = 'AWAITING ' + ((ROLE_WORDS[claim.role] && ROLE_WORDS[claim.role].awaiting) || 'STOCK');
+            statusText = t(ROLE_WORDS[claim.role] ? ROLE_WORDS[claim.role].awaiting : 'window.awaiting_stock');
         } else if (binPayload) {
             statusText = binPayload;
         } else if (remaining > 0) {
-            statusText = 'LOADED';
+            statusText = t('window.loaded');
         } else if (binState && binState.occupied) {
-            statusText = 'EMPTY';
+            statusText = t('window.empty');
         } else {
-            statusText = 'NO BIN';
+            statusText = t('board.no_bin_caps');
         }
         btn.appendChild(el('span', { className: 'os-node-remaining', textContent: statusText }));
         if (binLabel) {
@@ -1203,7 +1204,7 @@ function createNodeButton(entry) {
             labelEl.style.cssText = 'font-size:14px;font-weight:600;color:#fff';
             btn.appendChild(labelEl);
         } else {
-            btn.appendChild(el('span', { className: 'os-node-payload', textContent: 'Manual Swap' }));
+            btn.appendChild(el('span', { className: 'os-node-payload', textContent: t('material.manual_swap') }));
         }
     } else {
         btn.appendChild(el('span', {
@@ -1216,7 +1217,7 @@ function createNodeButton(entry) {
                 textContent: '/ ' + capacity
             }));
         }
-        const payloadText = claim ? (claim.payload_code || 'Unassigned') : '';
+        const payloadText = claim ? (claim.payload_code || t('modal.unassigned')) : '';
         appendETAPills(btn, inboundOrders, entry.bin_state);
         btn.appendChild(el('span', { className: 'os-node-payload', textContent: payloadText }));
     }
@@ -1258,7 +1259,7 @@ function appendETAPills(btn, inboundOrders, binState) {
     const pills = [];
     inboundOrders.forEach(o => {
         if (o.status === 'staged') {
-            pills.push({ text: 'Arrived', overdue: false });
+            pills.push({ text: t('board.arrived'), overdue: false });
         } else if (o.status === 'in_transit' && !binAtNode) {
             const d = formatETA(o.eta);
             if (!d.empty) pills.push(d);
@@ -1351,9 +1352,9 @@ function nodeColorClass(entry) {
 
 function statusIcon(entry) {
     if (entry.changeover_task && entry.changeover_task.state !== 'switched' && entry.changeover_task.state !== 'verified') {
-        return '[CO]';
+        return 'modal.co_tag';
     }
-    if (isReplenishing(entry)) return '[REP]';
+    if (isReplenishing(entry)) return 'modal.rep_tag';
     return null;
 }
 
@@ -1368,13 +1369,17 @@ export function renderFooter() {
         const nodes = claimedNodes();
         const coNodes = nodes.filter(n => n.changeover_task);
         const done = coNodes.filter(n => n.changeover_task.state === 'switched' || n.changeover_task.state === 'verified').length;
-        footerStatus.textContent = co.from_style_name + ' \u2192 ' + co.to_style_name +
-            ' [' + done + '/' + coNodes.length + ' nodes]';
+        footerStatus.textContent = t('board.co_nodes', {
+            from: co.from_style_name, to: co.to_style_name, done: done, total: coNodes.length,
+        });
     } else {
-        footerStatus.textContent = 'Operator Station Ready';
+        footerStatus.textContent = t('board.station_ready');
     }
 
-    footerBadge.textContent = state.replace(/_/g, ' ');
+    // The two states the engine sets are worded; anything else shows as stored.
+    footerBadge.textContent = state === 'active_production' ? t('board.state_active_production')
+        : state === 'changeover_active' ? t('board.state_changeover_active')
+            : state.replace(/_/g, ' ');
     footerBadge.className = 'os-footer-badge';
     if (state === 'active_production') footerBadge.classList.add('producing');
     if (state === 'changeover_active') footerBadge.classList.add('changeover');
@@ -1424,13 +1429,13 @@ function maybeShowRefusalModal() {
     // timed.
     panel.appendChild(el('div', {
         className: 'os-co-picker-title',
-        textContent: refusal.loader_node + ' CANNOT SUPPLY ' + refusal.payload_code,
+        textContent: t('board.cannot_supply_caps', { node: refusal.loader_node, code: refusal.payload_code }),
     }));
     panel.appendChild(el('div', {
         className: 'os-co-picker-verdict',
-        textContent: (refusal.refused_by || 'Loader operator') +
-            (refusal.refused_at ? ', ' + shortTime(refusal.refused_at) : '') +
-            '. Your request is still queued.',
+        textContent: refusal.refused_at
+            ? t('board.refused_by_at', { who: refusal.refused_by || t('board.loader_operator'), time: shortTime(refusal.refused_at) })
+            : t('board.refused_by', { who: refusal.refused_by || t('board.loader_operator') }),
     }));
 
     function answer(choice, then) {
@@ -1444,7 +1449,7 @@ function maybeShowRefusalModal() {
         });
     }
 
-    const wait = el('button', { className: 'os-co-picker-btn', textContent: 'WAIT' });
+    const wait = el('button', { className: 'os-co-picker-btn', textContent: t('board.wait_caps') });
     wait.addEventListener('click', function () { answer('wait'); });
     panel.appendChild(wait);
 
@@ -1452,7 +1457,7 @@ function maybeShowRefusalModal() {
     // still has to say which style. The ack is the decision; the picker is the
     // destination. The cancel of the outstanding order is NOT special-cased here:
     // StartProcessChangeover cancels pre-dispatch orders as a general property.
-    const co = el('button', { className: 'os-co-picker-btn danger', textContent: 'CHANGE OVER' });
+    const co = el('button', { className: 'os-co-picker-btn danger', textContent: t('board.change_over_caps') });
     co.addEventListener('click', function () { answer('changeover', openChangeoverPicker); });
     panel.appendChild(co);
 
@@ -15,7 +15,7 @@
 // they actually share is the DECISION, so that is what lives here; each modal
 // keeps only the four lines of its own idiom.
 
-import { postAction, showToast } from './operator-util.js';
+import { postAction, showToast, t, langHeaders } from './operator-util.js';
 
 // askConfirm is the HMI's own confirmation, not the browser's.
 //
@@ -36,10 +36,10 @@ function askConfirm(title, detail, confirmLabel, onConfirm) {
     const panel = document.createElement('div');
     panel.className = 'os-co-picker';
 
-    const t = document.createElement('div');
-    t.className = 'os-co-picker-title';
-    t.textContent = title;
-    panel.appendChild(t);
+    const head = document.createElement('div');
+    head.className = 'os-co-picker-title';
+    head.textContent = title;
+    panel.appendChild(head);
 
     if (detail) {
         const d = document.createElement('div');
@@ -60,7 +60,7 @@ function askConfirm(title, detail, confirmLabel, onConfirm) {
     // to be free.
     const cancel = document.createElement('button');
     cancel.className = 'os-co-picker-btn';
-    cancel.textContent = 'CANCEL';
+    cancel.textContent = t('operator.cancel_caps');
     cancel.addEventListener('click', function () { overlay.remove(); });
     panel.appendChild(cancel);
 
@@ -84,11 +84,11 @@ function refusalURL(nodeID) {
 // surprises a cell that has already acted on being told. Either one is a single
 // tap on a screen being read from a forklift seat.
 export function confirmRefuseSupply(nodeID, code, onDone) {
-    if (!code) { showToast('Pick a part first', 'error'); return; }
+    if (!code) { showToast(t('refusal.pick_part'), 'error'); return; }
     askConfirm(
-        'NO ' + code + ' AVAILABLE?',
-        'The cell waiting on this part will be told, and asked whether to keep waiting or change over.',
-        'YES — TELL THEM',
+        t('refusal.confirm_title', { code: code }),
+        t('refusal.confirm_detail'),
+        t('refusal.confirm_yes'),
         function () { postAction(refusalURL(nodeID), { payload_code: code }, onDone); },
     );
 }
@@ -96,9 +96,9 @@ export function confirmRefuseSupply(nodeID, code, onDone) {
 export function confirmUndoSupplyRefusal(nodeID, code, onDone) {
     if (!code) return;
     askConfirm(
-        'WITHDRAW THE REFUSAL FOR ' + code + '?',
-        'The cell may already have acted on being told the part was not coming.',
-        'YES — I CAN SUPPLY',
+        t('refusal.withdraw_title', { code: code }),
+        t('refusal.withdraw_detail'),
+        t('refusal.withdraw_yes'),
         function () { doUndo(nodeID, code, onDone); },
     );
 }
@@ -109,12 +109,12 @@ function doUndo(nodeID, code, onDone) {
     // slash in them.
     fetch(refusalURL(nodeID), {
         method: 'DELETE',
-        headers: { 'Content-Type': 'application/json' },
+        headers: langHeaders({ 'Content-Type': 'application/json' }),
         body: JSON.stringify({ payload_code: code }),
     }).then(function (res) {
-        if (!res.ok) { showToast('Could not withdraw the refusal', 'error'); return; }
+        if (!res.ok) { showToast(t('refusal.withdraw_failed'), 'error'); return; }
         if (onDone) onDone();
-    }).catch(function () { showToast('Could not withdraw the refusal', 'error'); });
+    }).catch(function () { showToast(t('refusal.withdraw_failed'), 'error'); });
 }
 
 // standingRefusalFor answers "is this card already refused" from the board data
@@ -128,5 +128,5 @@ export function standingRefusalFor(entry, code) {
 // The wording, in one place, so the two modals cannot label the same action
 // differently. REPORT is an action; NO PARTS AVAILABLE is a state, and the
 // state belongs on the card once the report has been made.
-export const REFUSE_LABEL = 'NO PARTS AVAILABLE';
-export const UNDO_LABEL = 'UNDO — I CAN SUPPLY';
+export const REFUSE_LABEL = t('refusal.refuse_label');
+export const UNDO_LABEL = t('refusal.undo_label');
@@ -2,6 +2,33 @@
 
 export const stationID = parseInt(document.body.dataset.stationId, 10);
 
+// t(key, args) renders a message from the station's catalog, which the page
+// embeds as #os-messages in the station's language. args fills {name}
+// placeholders. A key the catalog lacks renders as the key, the same rule the
+// server's catalog follows, so a typo shows on screen rather than as a blank.
+// Read lazily: the Node test harnesses load this module with no such element.
+let messages = null;
+export function t(key, args) {
+    if (messages === null) {
+        const node = document.getElementById('os-messages');
+        try { messages = node ? JSON.parse(node.textContent) : {}; } catch { messages = {}; }
+    }
+    let text = Object.prototype.hasOwnProperty.call(messages, key) ? messages[key] : key;
+    if (args) {
+        text = text.replace(/\{([a-z][a-z0-9_]*)\}/g, function (m, name) {
+            return Object.prototype.hasOwnProperty.call(args, name) ? String(args[name]) : m;
+        });
+    }
+    return text;
+}
+
+// langHeaders adds the page's language to a request's headers, so an error the
+// server composes comes back in the language the screen is showing — which is
+// the station's, and only the page knows that.
+export function langHeaders(headers) {
+    return Object.assign({ 'X-Shingo-Lang': document.documentElement.lang || 'en' }, headers || {});
+}
+
 // el(tag, props, children) — DOM builder aligned with the shared
 // signature in /static/shared/utils.js. Two-arg callers
 // (`el('div', { className, textContent })`) keep working because
@@ -59,7 +86,7 @@ export function fillColor(pct, remaining) {
 //   2. "{json}"                → returns json.msg if present
 //   3. anything else           → returns the raw string
 export function friendlyOrderError(detail) {
-    if (!detail) return 'Order failed';
+    if (!detail) return t('operator.order_failed');
     let s = String(detail);
     const jsonStart = s.indexOf(': {');
     if (jsonStart !== -1 && s.slice(0, jsonStart).indexOf('HTTP') !== -1) {
@@ -160,7 +187,10 @@ export async function fetchWithTimeout(url, opts, ms) {
     const ctrl = new AbortController();
     const timer = setTimeout(function() { ctrl.abort(); }, ms || 8000);
     try {
-        return await fetch(url, Object.assign({}, opts, { signal: ctrl.signal }));
+        return await fetch(url, Object.assign({}, opts, {
+            headers: langHeaders(opts && opts.headers),
+            signal: ctrl.signal,
+        }));
     } finally {
         clearTimeout(timer);
     }
@@ -173,7 +203,7 @@ export async function postAction(url, body, loadView) {
     try {
         const res = await fetch(url, {
             method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
+            headers: langHeaders({ 'Content-Type': 'application/json' }),
             body: JSON.stringify(body || {})
         });
         if (!res.ok) {
@@ -186,7 +216,7 @@ export async function postAction(url, body, loadView) {
             // missing/zero param (e.g. confirm-delivery/0 from a half-built
             // complex order). Map it to an actionable message instead.
             if (res.status === 404) {
-                msg = 'Order not found — refresh and try again';
+                msg = t('operator.order_not_found');
             }
             // A refusal may carry an inline exit (e.g. an armed changeover
             // blocking a material request). Offer it as a button: taking it
@@ -207,7 +237,7 @@ export async function postAction(url, body, loadView) {
         return true;
     } catch (err) {
         console.error('postAction', url, err);
-        showToast('Network error', 'error');
+        showToast(t('operator.network_error'), 'error');
         return false;
     }
 }
@@ -235,14 +265,14 @@ export function formatETA(etaStr) {
     const remainingSec = (etaMs - Date.now()) / 1000;
     const graceSec = 60;
     if (remainingSec < -graceSec) {
-        return { text: 'Running late', overdue: true };
+        return { text: t('eta.late'), overdue: true };
     }
     if (remainingSec < 45) {
-        return { text: 'Arriving', overdue: false };
+        return { text: t('eta.arriving'), overdue: false };
     }
     if (remainingSec < 90) {
-        return { text: 'ETA: ~1 min', overdue: false };
+        return { text: t('eta.minutes', { n: 1 }), overdue: false };
     }
     const mins = Math.round(remainingSec / 60);
-    return { text: 'ETA: ~' + mins + ' min', overdue: false };
+    return { text: t('eta.minutes', { n: mins }), overdue: false };
 }
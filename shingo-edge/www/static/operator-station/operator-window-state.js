@@ -7,8 +7,9 @@
 // through a precedence ladder. This is the refactor of the old cardState ladder in
 // operator-render.js (the "subtle word differences" lived there).
 //
-// Pure + import-free on purpose: it unit-tests under plain Node (the test strips
-// `export` and runs it in a vm — see operator-window-state.test.js), and it keeps
+// Pure on purpose, importing nothing but t: it unit-tests under plain Node (the
+// test strips the import and `export` and runs it in a vm beside the real t — see
+// operator-window-state.test.js), and it keeps
 // rendering concerns (DOM, transitional coverage badges, idle-card hiding) in
 // operator-render.js, which consumes this.
 //
@@ -20,6 +21,8 @@
 
 'use strict';
 
+import { t } from './operator-util.js';
+
 // WINDOW_ACTIVE_STATUSES is the set of order statuses still in the live
 // lifecycle (= !terminal), for the window-card active filter. This mirrors
 // order-status.js isActive exactly; the Go drift test
@@ -45,10 +48,17 @@ export function isActiveStatus(s) {
 
 // ROLE_WORDS centralizes every loader↔unloader wording difference. A loader awaits an
 // EMPTY bin to fill; an unloader awaits a FULL bin to pull. This table is the one
-// place those words differ — change a label here, both the header and the cards follow.
+// place those words differ — it names catalog keys, so change a key here and both the
+// header and the cards follow.
 export const ROLE_WORDS = {
-    produce: { awaiting: 'BIN', present: 'LOADED', actVerb: 'load' },
-    consume: { awaiting: 'FULL', present: 'FULL', actVerb: 'unload' },
+    produce: {
+        awaiting: 'window.awaiting_bin', arrived: 'window.bin_arrived', arriving: 'window.bin_arriving',
+        present: 'window.loaded', tapTo: 'window.tap_to_load',
+    },
+    consume: {
+        awaiting: 'window.awaiting_full', arrived: 'window.full_arrived', arriving: 'window.full_arriving',
+        present: 'window.full', tapTo: 'window.tap_to_unload',
+    },
 };
 
 function words(role) { return ROLE_WORDS[role] || ROLE_WORDS.produce; }
@@ -119,27 +129,27 @@ export function cardModel(entry, code) {
     // Status tag (precedence-ordered; the order encodes the incident fixes — a consume
     // empty must be caught as SWAP before the produce LOAD fallback, plant 2026-06-02).
     let cls, statusText, statusClass;
-    if (payloadDelivered) { cls = 'os-board-delivered'; statusText = 'DELIVERED'; statusClass = 'os-board-tag-delivered'; }
-    else if (canClearThisPayload) { cls = 'os-board-delivered'; statusText = 'SWAP'; statusClass = 'os-board-tag-delivered'; }
-    else if (f.canSwapEmpty) { cls = 'os-board-delivered'; statusText = 'SWAP'; statusClass = 'os-board-tag-delivered'; }
-    else if (payloadInTransit) { cls = 'os-board-transit'; statusText = 'IN TRANSIT'; statusClass = 'os-board-tag-transit'; }
-    else if (payloadAcknowledged) { cls = 'os-board-queued'; statusText = 'ACKNOWLEDGED'; statusClass = 'os-board-tag-queued'; }
-    else if (loadNow) { cls = 'os-board-queued'; statusText = 'LOAD'; statusClass = 'os-board-tag-queued'; }
-    else if (hasPayloadDemand) { cls = 'os-board-queued'; statusText = 'QUEUED'; statusClass = 'os-board-tag-queued'; waitingOnRobot = true; }
-    else if (f.canLoadEmpty) { cls = 'os-board-queued'; statusText = 'LOAD'; statusClass = 'os-board-tag-queued'; }
-    else { cls = 'os-board-nodemand'; statusText = 'NO DEMAND'; statusClass = 'os-board-tag-nodemand'; }
+    if (payloadDelivered) { cls = 'os-board-delivered'; statusText = t('window.delivered'); statusClass = 'os-board-tag-delivered'; }
+    else if (canClearThisPayload) { cls = 'os-board-delivered'; statusText = t('window.swap'); statusClass = 'os-board-tag-delivered'; }
+    else if (f.canSwapEmpty) { cls = 'os-board-delivered'; statusText = t('window.swap'); statusClass = 'os-board-tag-delivered'; }
+    else if (payloadInTransit) { cls = 'os-board-transit'; statusText = t('window.in_transit'); statusClass = 'os-board-tag-transit'; }
+    else if (payloadAcknowledged) { cls = 'os-board-queued'; statusText = t('window.acknowledged'); statusClass = 'os-board-tag-queued'; }
+    else if (loadNow) { cls = 'os-board-queued'; statusText = t('window.load'); statusClass = 'os-board-tag-queued'; }
+    else if (hasPayloadDemand) { cls = 'os-board-queued'; statusText = t('window.queued'); statusClass = 'os-board-tag-queued'; waitingOnRobot = true; }
+    else if (f.canLoadEmpty) { cls = 'os-board-queued'; statusText = t('window.load'); statusClass = 'os-board-tag-queued'; }
+    else { cls = 'os-board-nodemand'; statusText = t('window.no_demand'); statusClass = 'os-board-tag-nodemand'; }
 
     // Detail line. The role-specific verbs come from ROLE_WORDS, not inline ternaries.
     let detail;
-    if (payloadDelivered) detail = 'Tap to ' + w.actVerb;
-    else if (canClearThisPayload) detail = 'Loaded bin parked — tap to swap';
-    else if (f.canSwapEmpty) detail = 'Empty bin parked — tap to swap';
-    else if (f.binEmpty && (payloadInTransit || hasPayloadDemand)) detail = 'Empty bin at node — tap to load';
-    else if (payloadInTransit) detail = 'Robot en route';
-    else if (payloadAcknowledged) detail = 'Order accepted — awaiting dispatch';
-    else if (hasPayloadDemand) detail = 'Waiting for robot';
-    else if (f.canLoadEmpty) detail = 'Empty bin parked — tap to load';
-    else detail = 'No kanban signal';
+    if (payloadDelivered) detail = t(w.tapTo);
+    else if (canClearThisPayload) detail = t('window.loaded_parked');
+    else if (f.canSwapEmpty) detail = t('window.empty_parked_swap');
+    else if (f.binEmpty && (payloadInTransit || hasPayloadDemand)) detail = t('window.empty_at_node');
+    else if (payloadInTransit) detail = t('window.robot_en_route');
+    else if (payloadAcknowledged) detail = t('window.order_accepted');
+    else if (hasPayloadDemand) detail = t('window.waiting_robot');
+    else if (f.canLoadEmpty) detail = t('window.empty_parked_load');
+    else detail = t('window.no_kanban');
 
     // Action (what the tap does), role-gated. A LOADER acts on a delivered empty
     // directly — the tap IS the receipt confirmation — so it must not also require
@@ -203,18 +213,18 @@ export function headerModel(entry) {
     const f = nodeFacts(entry);
     const w = words(f.role);
     if (f.binPresent) {
-        if (f.binLoaded) return { text: w.present, color: 'background:#1a3a1a;color:#6f6' };
-        return { text: 'EMPTY', color: 'background:#3a1a1a;color:#f88' };
+        if (f.binLoaded) return { text: t(w.present), color: 'background:#1a3a1a;color:#6f6' };
+        return { text: t('window.empty'), color: 'background:#3a1a1a;color:#f88' };
     }
     if (f.activeOrders.some(function (o) { return o.status === 'delivered'; })) {
-        return { text: w.awaiting + ' ARRIVED', color: 'background:#2a3a1a;color:#cf6' };
+        return { text: t(w.arrived), color: 'background:#2a3a1a;color:#cf6' };
     }
     // A robot actually en route (in_transit) is "ARRIVING"; an acknowledged
     // order (fleet accepted, not yet moving) is NOT arriving, so it falls
     // through to the "AWAITING" default rather than pretending a bin is on its
     // way.
     if (f.activeOrders.some(function (o) { return o.status === 'in_transit'; })) {
-        return { text: w.awaiting + ' ARRIVING', color: 'background:#1a2a3a;color:#6cf' };
+        return { text: t(w.arriving), color: 'background:#1a2a3a;color:#6cf' };
     }
-    return { text: 'AWAITING ' + w.awaiting, color: 'background:#2a2a1a;color:#ff6' };
+    return { text: t(w.awaiting), color: 'background:#2a2a1a;color:#ff6' };
 }
@@ -19,11 +19,24 @@ function eq(got, want, label) {
     console.error('FAIL: ' + label + '\n   got:  ' + JSON.stringify(got) + '\n   want: ' + JSON.stringify(want));
 }
 
-// ── Load the import-free module by stripping `export` and running it in a vm ──
+// ── Load the module by stripping its import and `export` and running it in a vm ──
+// beside the real t from operator-util.js, reading the English catalog the page
+// would embed, so the assertions below read as the board does in English.
+const catalog = fs.readFileSync(path.join(__dirname, '..', '..', '..', 'locales', 'en.json'), 'utf8');
+const utilSrc = fs.readFileSync(path.join(__dirname, 'operator-util.js'), 'utf8')
+    .replace(/^export\s+/gm, '');
 const src = fs.readFileSync(path.join(__dirname, 'operator-window-state.js'), 'utf8')
+    .replace(/^import\s.*$/gm, '')
     .replace(/export\s+function\s+/g, 'function ')
     .replace(/export\s+const\s+/g, 'const ');
-const ctx = vm.createContext({});
+const ctx = vm.createContext({
+    document: {
+        body: { dataset: { stationId: '1' } },
+        getElementById: (id) => (id === 'os-messages' ? { textContent: catalog } : null),
+    },
+    console: console,
+});
+vm.runInContext(utilSrc, ctx);
 vm.runInContext(src + '\nthis.cardModel = cardModel; this.headerModel = headerModel; this.nodeFacts = nodeFacts;', ctx);
 const cardModel = ctx.cardModel, headerModel = ctx.headerModel, nodeFacts = ctx.nodeFacts;
 
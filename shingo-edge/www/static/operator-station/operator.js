@@ -2,7 +2,7 @@
 // Entry module wires SSE → loadView, refreshes the view, and bootstraps
 // the render / modal / load-bin / release / keypad sub-modules.
 
-import { stationID, showToast, friendlyOrderError, postAction, el, fetchWithTimeout } from './operator-util.js';
+import { stationID, showToast, friendlyOrderError, postAction, el, fetchWithTimeout, t } from './operator-util.js';
 import {
     getView, setView, getSelectedNodeID,
     getLastViewJSON, setLastViewJSON,
@@ -120,7 +120,7 @@ async function doLoadView() {
         // timeout made the pile-up strictly worse — every retry added an orphan
         // build and removed none.
         const res = await fetchWithTimeout('/api/operator-stations/' + stationID + '/view', undefined, 30000);
-        if (!res.ok) { showToast('Connection error: ' + res.status, 'error'); return; }
+        if (!res.ok) { showToast(t('operator.connection_error', { status: res.status }), 'error'); return; }
         const text = await res.text();
         if (text === getLastViewJSON()) return;
         setLastViewJSON(text);
@@ -128,7 +128,7 @@ async function doLoadView() {
         renderAll();
     } catch (err) {
         console.error('loadView', err);
-        showToast('Network error', 'error');
+        showToast(t('operator.network_error'), 'error');
     }
 }
 
@@ -185,7 +185,7 @@ function scheduleRefresh() {
 function handleOrderFailed(data) {
     scheduleRefresh();
     const reason = data && (data.reason || data.Reason || data.detail || data.Detail);
-    let msg = friendlyOrderError(reason) || 'Order failed';
+    let msg = friendlyOrderError(reason) || t('operator.order_failed');
     if (data && data.order_type) {
         msg = data.order_type + ': ' + msg;
     }
@@ -262,7 +262,7 @@ function renderPostCutoverFlag(pid, flag) {
     // Resolution 1 — one-tap corrective changeover to the mapped style.
     if (flag.has_mapped) {
         const fixStyle = Object.assign({}, btnStyle, { background: '#2f7a2f', borderColor: '#2f7a2f' });
-        const fix = el('button', { type: 'button', style: fixStyle, textContent: 'Change over to ' + flag.mapped_style_name });
+        const fix = el('button', { type: 'button', style: fixStyle, textContent: t('operator.change_over_to', { style: flag.mapped_style_name }) });
         fix.addEventListener('click', async () => {
             fix.disabled = true;
             const ok = await postAction('/api/processes/' + pid + '/changeover/start', {
@@ -270,14 +270,14 @@ function renderPostCutoverFlag(pid, flag) {
                 called_by: 'post-cutover-correction',
                 notes: 'corrective changeover from post-cutover part-id mismatch',
             }, loadView);
-            if (ok) { showToast('Corrective changeover to ' + flag.mapped_style_name + ' started', 'success'); removePostCutoverBanner(); }
+            if (ok) { showToast(t('operator.corrective_started', { style: flag.mapped_style_name }), 'success'); removePostCutoverBanner(); }
         });
         actions.appendChild(fix);
     }
     // Resolution 2 — review the style's expected part-id config.
-    actions.appendChild(el('a', { href: '/processes', target: '_blank', style: btnStyle, textContent: 'Review part-id config' }));
+    actions.appendChild(el('a', { href: '/processes', target: '_blank', style: btnStyle, textContent: t('operator.review_part_id') }));
     // Confirm the press is correct → clear the flag.
-    const confirmBtn = el('button', { type: 'button', style: btnStyle, textContent: 'Confirm — press is correct' });
+    const confirmBtn = el('button', { type: 'button', style: btnStyle, textContent: t('operator.press_correct') });
     confirmBtn.addEventListener('click', async () => {
         confirmBtn.disabled = true;
         const ok = await postAction('/api/processes/' + pid + '/post-cutover-flag/confirm', {}, loadView);